@@ -0,0 +1,90 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/app"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/usecase"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/observability"
+	"go.uber.org/zap"
+)
+
+func main() {
+	bucket := flag.String("bucket", os.Getenv("BACKFILL_BUCKET"), "bucket holding the videos (default $BACKFILL_BUCKET)")
+	prefix := flag.String("prefix", os.Getenv("BACKFILL_PREFIX"), "key prefix to backfill (default $BACKFILL_PREFIX)")
+	queue := flag.String("queue", os.Getenv("QUEUE_INPUT"), "input queue URL (default $QUEUE_INPUT)")
+	rawOptions := flag.String("options", getEnv("BACKFILL_OPTIONS", "{}"), `message options for every job, e.g. '{"output_type":"sprite"}' (default $BACKFILL_OPTIONS)`)
+	concurrency := flag.Int("concurrency", 8, "messages sent in parallel")
+	flag.Parse()
+
+	if err := observability.InitLogger(getEnv("ENVIRONMENT", "development")); err != nil {
+		fmt.Printf("Failed to initialize logger: %v\n", err)
+		os.Exit(1)
+	}
+	defer observability.Sync()
+	logger := observability.GetLogger()
+
+	if *bucket == "" || *queue == "" {
+		logger.Fatal("both --bucket and --queue are required")
+	}
+	var options map[string]interface{}
+	if err := json.Unmarshal([]byte(*rawOptions), &options); err != nil {
+		logger.Fatal("--options must be a JSON object", zap.Error(err))
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Same storage and transport variables as the worker (S3_*, MESSAGE_TRANSPORT)
+	appConfig, err := app.ConfigFromEnv()
+	if err == nil {
+		err = appConfig.Validate()
+	}
+	if err != nil {
+		logger.Fatal("invalid configuration", zap.Error(err))
+	}
+	// The input queue is a subscription with Pub/Sub, it cannot be published to
+	if appConfig.MessageTransport == app.TransportPubSub {
+		logger.Fatal("backfill is not supported when MESSAGE_TRANSPORT=pubsub")
+	}
+	container := app.New(appConfig)
+	defer container.Close()
+
+	storagePort, err := container.Storage(ctx)
+	if err != nil {
+		logger.Fatal("failed to configure storage", zap.Error(err))
+	}
+	messagePort, err := container.Messages(ctx)
+	if err != nil {
+		logger.Fatal("failed to configure messaging", zap.Error(err))
+	}
+
+	backfill := usecase.NewBackfillUseCase(storagePort, messagePort, *queue)
+
+	report, err := backfill.Run(ctx, *bucket, *prefix, options, *concurrency)
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(report)
+
+	if err != nil {
+		logger.Error("backfill stopped", zap.Error(err))
+		os.Exit(1)
+	}
+	if report.Failed > 0 {
+		os.Exit(1)
+	}
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
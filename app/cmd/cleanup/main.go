@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/app"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/usecase"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/observability"
+	"go.uber.org/zap"
+)
+
+func main() {
+	bucket := flag.String("bucket", getEnv("CLEANUP_BUCKET", os.Getenv("STORAGE_OUTPUT")), "bucket holding the outputs (default $CLEANUP_BUCKET, then $STORAGE_OUTPUT)")
+	prefix := flag.String("prefix", os.Getenv("CLEANUP_PREFIX"), "key prefix to clean up, e.g. processed/ (default $CLEANUP_PREFIX)")
+	tenant := flag.String("tenant", os.Getenv("CLEANUP_TENANT"), "clean up every allowed_outputs location of the tenant in $TENANT_CONFIG instead of --bucket/--prefix (default $CLEANUP_TENANT)")
+	days := flag.Int("days", getEnvInt("CLEANUP_DAYS", 0), "delete outputs last modified more than this many days ago (default $CLEANUP_DAYS)")
+	dryRun := flag.Bool("dry-run", os.Getenv("CLEANUP_DRY_RUN") == "true", "only report what would be deleted (default $CLEANUP_DRY_RUN)")
+	expired := flag.Bool("expired", os.Getenv("CLEANUP_EXPIRED") == "true", "delete the outputs whose tenant retention is over, as scheduled by the workers in --bucket, instead of the outputs older than --days (default $CLEANUP_EXPIRED)")
+	flag.Parse()
+
+	if err := observability.InitLogger(getEnv("ENVIRONMENT", "development")); err != nil {
+		fmt.Printf("Failed to initialize logger: %v\n", err)
+		os.Exit(1)
+	}
+	defer observability.Sync()
+	logger := observability.GetLogger()
+
+	var locations []domain.OutputLocation
+	if *expired {
+		if *bucket == "" {
+			logger.Fatal("--bucket is required with --expired")
+		}
+	} else {
+		if *days < 1 {
+			logger.Fatal("--days must be at least 1")
+		}
+		var err error
+		locations, err = cleanupLocations(*tenant, *bucket, *prefix)
+		if err != nil {
+			logger.Fatal("invalid cleanup target", zap.Error(err))
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	// The locations of a tenant are reached with its output role, as its jobs reach them
+	ctx = observability.WithTenant(ctx, *tenant)
+
+	// Same S3_* variables as the worker, for MinIO and other compatible stores
+	appConfig, err := app.ConfigFromEnv()
+	if err != nil {
+		logger.Fatal("invalid configuration", zap.Error(err))
+	}
+	container := app.New(appConfig)
+	defer container.Close()
+
+	storagePort, err := container.Storage(ctx)
+	if err != nil {
+		logger.Fatal("failed to configure storage", zap.Error(err))
+	}
+
+	cleanup := usecase.NewCleanupOutputsUseCase(storagePort)
+	if *expired {
+		report, err := cleanup.RunExpired(ctx, *bucket, time.Now(), *dryRun)
+		if err != nil {
+			logger.Error("cleanup stopped", zap.String("bucket", *bucket), zap.Error(err))
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.Encode([]domain.CleanupReport{report})
+		if err != nil || report.Failed > 0 {
+			os.Exit(1)
+		}
+		return
+	}
+
+	before := time.Now().AddDate(0, 0, -*days)
+
+	reports := make([]domain.CleanupReport, 0, len(locations))
+	failed := false
+	for _, location := range locations {
+		report, err := cleanup.Run(ctx, location, before, *dryRun)
+		reports = append(reports, report)
+		if err != nil {
+			logger.Error("cleanup stopped", zap.String("bucket", location.Bucket), zap.String("prefix", location.Prefix), zap.Error(err))
+			failed = true
+		}
+		if report.Failed > 0 {
+			failed = true
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(reports)
+
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// cleanupLocations is the location given by --bucket/--prefix or, for a tenant, each location
+// its jobs may write outputs to
+func cleanupLocations(tenant, bucket, prefix string) ([]domain.OutputLocation, error) {
+	if tenant == "" {
+		if bucket == "" || prefix == "" {
+			return nil, fmt.Errorf("either --tenant or both --bucket and --prefix are required")
+		}
+		return []domain.OutputLocation{{Bucket: bucket, Prefix: prefix}}, nil
+	}
+
+	tenants, err := domain.ParseTenantRegistry([]byte(os.Getenv("TENANT_CONFIG")))
+	if err != nil {
+		return nil, err
+	}
+	config, ok := tenants[tenant]
+	if !ok || len(config.AllowedOutputs) == 0 {
+		return nil, fmt.Errorf("tenant %s has no allowed_outputs in TENANT_CONFIG", tenant)
+	}
+	locations := make([]domain.OutputLocation, 0, len(config.AllowedOutputs))
+	for _, rule := range config.AllowedOutputs {
+		locations = append(locations, domain.OutputLocation{Bucket: rule.Bucket, Prefix: rule.Prefix})
+	}
+	return locations, nil
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func getEnvInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/app"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/buildinfo"
+)
+
+const usage = `Usage: cli process --input video.mp4 --output frames.zip [options]
+
+Processes a local video with the same FFmpeg pipeline as the worker, without any queue or bucket.
+
+Commands:
+  process   extract frames (or a sprite sheet) from a local video into a zip
+  version   print the build information
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	switch os.Args[1] {
+	case "process":
+		if err := runProcess(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+	case "version", "--version":
+		fmt.Println(buildinfo.Get(""))
+	case "help", "-h", "--help":
+		fmt.Print(usage)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", os.Args[1], usage)
+		os.Exit(2)
+	}
+}
+
+// runProcess parses the process flags, runs the processor and moves the zip parts to --output
+func runProcess(args []string) error {
+	flags := flag.NewFlagSet("process", flag.ExitOnError)
+	input := flags.String("input", "", "path to the local video (required)")
+	output := flags.String("output", "", "path of the output zip (required)")
+	outputType := flags.String("type", domain.OutputTypeFrames, "output type: frames or sprite")
+	fps := flags.Float64("fps", 1, "frames sampled per second")
+	maxFrames := flags.Int("max-frames", 0, "caps the number of frames, lowering the rate when needed; 0 means no cap")
+	imageFormat := flags.String("image-format", "", "frames image format: png, jpeg or webp")
+	quality := flags.Int("quality", 0, "jpeg/webp quality (1-100)")
+	toneMap := flags.String("tone-map", "", "tonemap algorithm applied to HDR videos")
+	noAutoRotate := flags.Bool("no-auto-rotate", false, "keep frames as encoded, ignoring the rotation metadata")
+	timeout := flags.Duration("timeout", 30*time.Minute, "maximum processing time")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *input == "" || *output == "" {
+		flags.Usage()
+		return errors.New("--input and --output are required")
+	}
+	if _, err := os.Stat(*input); err != nil {
+		return fmt.Errorf("failed to read input: %w", err)
+	}
+
+	options := domain.FrameOptions{
+		ProcessID:  strings.TrimSuffix(filepath.Base(*input), filepath.Ext(*input)),
+		AutoRotate: !*noAutoRotate,
+		ToneMap:    *toneMap,
+		FPS:        *fps,
+		MaxFrames:  *maxFrames,
+		Image:      domain.ImageOptions{Format: *imageFormat, Quality: *quality},
+	}
+	if err := validateOptions(*outputType, options); err != nil {
+		return err
+	}
+
+	tempDir, err := os.MkdirTemp("", "soat-cli-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	// The worker processor settings (zip, frame names, OCR), always local and in tempDir
+	config, err := app.ConfigFromEnv()
+	if err != nil {
+		return err
+	}
+	config.TempDir, config.MemoryDir, config.RemoteBackend = tempDir, "", ""
+	config.FFmpegPath, config.FFprobePath = getEnv("FFMPEG_PATH", "ffmpeg"), getEnv("FFPROBE_PATH", "ffprobe")
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	container := app.New(config)
+	defer container.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	processor, err := container.VideoProcessor(ctx)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, *timeout)
+	defer cancel()
+
+	started := time.Now()
+	request := domain.ProcessingRequest{JobID: options.ProcessID, VideoPath: *input, Options: options, Sprite: domain.DefaultSpriteOptions}
+	var result domain.ProcessingResult
+	if *outputType == domain.OutputTypeSprite {
+		result, err = processor.GenerateSpriteSheet(ctx, request)
+	} else {
+		result, err = processor.ProcessVideo(ctx, request)
+	}
+	if err != nil {
+		return err
+	}
+
+	written, err := moveOutputs(result.Artifacts, *output)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("processed %s: %d %s in %s\n", *input, result.FrameCount, *outputType, time.Since(started).Round(time.Millisecond))
+	for _, path := range written {
+		fmt.Println(path)
+	}
+	return nil
+}
+
+// validateOptions applies the same checks the worker runs on queue messages
+func validateOptions(outputType string, options domain.FrameOptions) error {
+	if outputType != domain.OutputTypeFrames && outputType != domain.OutputTypeSprite {
+		return fmt.Errorf("unsupported type %q: use frames or sprite", outputType)
+	}
+	if err := domain.ValidateFPS(options.FPS); err != nil {
+		return err
+	}
+	if err := domain.ValidateMaxFrames(options.MaxFrames); err != nil {
+		return err
+	}
+	if err := domain.ValidateToneMap(options.ToneMap); err != nil {
+		return err
+	}
+	return options.Image.Validate()
+}
+
+// moveOutputs copies the zip parts to output, naming them output.partN.zip when the zip was split
+func moveOutputs(zipPaths []string, output string) ([]string, error) {
+	if err := os.MkdirAll(filepath.Dir(output), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output dir: %w", err)
+	}
+
+	written := make([]string, 0, len(zipPaths))
+	for i, zipPath := range zipPaths {
+		target := output
+		if len(zipPaths) > 1 {
+			target = fmt.Sprintf("%s.part%d.zip", strings.TrimSuffix(output, ".zip"), i+1)
+		}
+		if err := copyFile(zipPath, target); err != nil {
+			return written, err
+		}
+		written = append(written, target)
+	}
+	return written, nil
+}
+
+// copyFile copies instead of renaming, as the temp dir may live on another filesystem
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", src, err)
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", dst, err)
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return fmt.Errorf("failed to write %s: %w", dst, err)
+	}
+	return out.Close()
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
@@ -0,0 +1,380 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/observability"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/signing"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"go.uber.org/zap"
+)
+
+// sqsMaxBatch is the most messages SQS receives, sends or deletes in one call
+const sqsMaxBatch = 10
+
+// signatureAttribute carries the HMAC of the body on authenticated input queues
+const signatureAttribute = "signature"
+
+// queueClient is the part of the SQS client the replay uses, so tests can fake it
+type queueClient interface {
+	ReceiveMessage(ctx context.Context, params *sqs.ReceiveMessageInput, optFns ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error)
+	SendMessageBatch(ctx context.Context, params *sqs.SendMessageBatchInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageBatchOutput, error)
+	DeleteMessageBatch(ctx context.Context, params *sqs.DeleteMessageBatchInput, optFns ...func(*sqs.Options)) (*sqs.DeleteMessageBatchOutput, error)
+}
+
+// replayer moves messages from the DLQ back to the input queue, optionally patching them
+type replayer struct {
+	client    queueClient
+	source    string
+	target    string
+	batchSize int32
+	limit     int
+	mutations map[string]json.RawMessage
+	limiter   *rateLimiter
+
+	// signer re-signs mutated bodies, whose original signature no longer matches; nil refuses
+	// to mutate signed messages
+	signer *signing.HMACSigner
+
+	// failed holds the messages left in the DLQ, which reappear once their visibility timeout ends
+	failed map[string]bool
+}
+
+// replaySummary is the report printed when the replay ends
+type replaySummary struct {
+	Received int
+	Replayed int
+	Failed   int
+}
+
+// setFlags collects repeated --set key=value mutations
+type setFlags map[string]json.RawMessage
+
+func (s setFlags) String() string {
+	pairs := make([]string, 0, len(s))
+	for key, value := range s {
+		pairs = append(pairs, key+"="+string(value))
+	}
+	return strings.Join(pairs, ",")
+}
+
+// Set keeps JSON values (numbers, booleans, objects) as they are and quotes anything else
+func (s setFlags) Set(value string) error {
+	key, raw, ok := strings.Cut(value, "=")
+	if !ok || key == "" {
+		return fmt.Errorf("expected key=value, got %q", value)
+	}
+	if json.Valid([]byte(raw)) {
+		s[key] = json.RawMessage(raw)
+		return nil
+	}
+	quoted, err := json.Marshal(raw)
+	if err != nil {
+		return err
+	}
+	s[key] = quoted
+	return nil
+}
+
+func main() {
+	mutations := setFlags{}
+	source := flag.String("dlq", os.Getenv("QUEUE_DLQ"), "dead-letter queue URL to replay from (default $QUEUE_DLQ)")
+	target := flag.String("queue", os.Getenv("QUEUE_INPUT"), "input queue URL to replay to (default $QUEUE_INPUT)")
+	batchSize := flag.Int("batch", sqsMaxBatch, "messages per receive/send batch (1-10)")
+	rate := flag.Float64("rate", 5, "maximum messages replayed per second; 0 disables the limit")
+	limit := flag.Int("max", 0, "stop after this many messages; 0 drains the DLQ")
+	flag.Var(mutations, "set", "override a top-level message field, e.g. --set output_type=sprite or --set max_frames=100 (repeatable)")
+	signingSecret := flag.String("signing-secret", os.Getenv("INPUT_SIGNING_SECRET"), "secret that re-signs mutated messages; for producer messages, the producer's secret (default $INPUT_SIGNING_SECRET)")
+	flag.Parse()
+
+	if err := observability.InitLogger(getEnv("ENVIRONMENT", "development")); err != nil {
+		fmt.Printf("Failed to initialize logger: %v\n", err)
+		os.Exit(1)
+	}
+	defer observability.Sync()
+	logger := observability.GetLogger()
+
+	if *source == "" || *target == "" {
+		logger.Fatal("both --dlq and --queue are required")
+	}
+	if *batchSize < 1 || *batchSize > sqsMaxBatch {
+		logger.Fatal("--batch must be between 1 and 10", zap.Int("batch", *batchSize))
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		logger.Fatal("failed to load AWS config", zap.Error(err))
+	}
+
+	var signer *signing.HMACSigner
+	if *signingSecret != "" {
+		if signer, err = signing.NewHMACSigner([]byte(*signingSecret), ""); err != nil {
+			logger.Fatal("invalid --signing-secret", zap.Error(err))
+		}
+	}
+
+	r := &replayer{
+		client:    sqs.NewFromConfig(cfg),
+		source:    *source,
+		target:    *target,
+		batchSize: int32(*batchSize),
+		limit:     *limit,
+		mutations: mutations,
+		limiter:   newRateLimiter(*rate),
+		signer:    signer,
+		failed:    map[string]bool{},
+	}
+
+	summary, err := r.run(ctx)
+	fmt.Printf("received=%d replayed=%d failed=%d\n", summary.Received, summary.Replayed, summary.Failed)
+	if err != nil && !errors.Is(err, context.Canceled) {
+		logger.Error("replay stopped", zap.Error(err))
+		os.Exit(1)
+	}
+	if summary.Failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// run replays batch after batch until the DLQ is empty, the limit is reached or ctx is canceled.
+// A message is deleted from the DLQ only after the input queue accepted it, so a failed
+// send leaves it there for the next run
+func (r *replayer) run(ctx context.Context) (replaySummary, error) {
+	logger := observability.GetLogger()
+	var summary replaySummary
+
+	for r.limit == 0 || summary.Received < r.limit {
+		size := r.batchSize
+		if r.limit > 0 && r.limit-summary.Received < int(size) {
+			size = int32(r.limit - summary.Received)
+		}
+
+		res, err := r.client.ReceiveMessage(ctx, &sqs.ReceiveMessageInput{
+			QueueUrl:              aws.String(r.source),
+			MaxNumberOfMessages:   size,
+			WaitTimeSeconds:       2,
+			MessageAttributeNames: []string{"All"},
+		})
+		if err != nil {
+			return summary, fmt.Errorf("failed to receive from DLQ: %w", err)
+		}
+		messages := r.unseen(res.Messages)
+		if len(messages) == 0 {
+			// Empty DLQ, or only messages that already failed in this run
+			return summary, nil
+		}
+		summary.Received += len(messages)
+
+		if err := r.limiter.wait(ctx, len(messages)); err != nil {
+			return summary, err
+		}
+
+		replayed, failed := r.replayBatch(ctx, messages)
+		summary.Replayed += replayed
+		summary.Failed += failed
+		logger.Info("batch replayed", zap.Int("replayed", replayed), zap.Int("failed", failed))
+	}
+	return summary, nil
+}
+
+// unseen drops the messages that already failed in this run
+func (r *replayer) unseen(messages []types.Message) []types.Message {
+	fresh := make([]types.Message, 0, len(messages))
+	for _, msg := range messages {
+		if !r.failed[aws.ToString(msg.MessageId)] {
+			fresh = append(fresh, msg)
+		}
+	}
+	return fresh
+}
+
+// replayBatch sends the messages to the input queue and deletes the accepted ones from the DLQ
+func (r *replayer) replayBatch(ctx context.Context, messages []types.Message) (int, int) {
+	logger := observability.GetLogger()
+	failed := 0
+
+	byID := make(map[string]types.Message, len(messages))
+	entries := make([]types.SendMessageBatchRequestEntry, 0, len(messages))
+	for i, msg := range messages {
+		body, err := mutateBody(aws.ToString(msg.Body), r.mutations)
+		var attributes map[string]types.MessageAttributeValue
+		if err == nil {
+			attributes, err = r.replayAttributes(ctx, msg, body)
+		}
+		if err != nil {
+			logger.Warn("message left in the DLQ", zap.String("message_id", aws.ToString(msg.MessageId)), zap.Error(err))
+			r.failed[aws.ToString(msg.MessageId)] = true
+			failed++
+			continue
+		}
+
+		id := strconv.Itoa(i)
+		byID[id] = msg
+		entries = append(entries, types.SendMessageBatchRequestEntry{
+			Id:                aws.String(id),
+			MessageBody:       aws.String(body),
+			MessageAttributes: attributes,
+		})
+	}
+	if len(entries) == 0 {
+		return 0, failed
+	}
+
+	res, err := r.client.SendMessageBatch(ctx, &sqs.SendMessageBatchInput{
+		QueueUrl: aws.String(r.target),
+		Entries:  entries,
+	})
+	if err != nil {
+		logger.Warn("failed to send batch to the input queue", zap.Error(err))
+		for _, msg := range byID {
+			r.failed[aws.ToString(msg.MessageId)] = true
+		}
+		return 0, failed + len(entries)
+	}
+	for _, entry := range res.Failed {
+		r.failed[aws.ToString(byID[aws.ToString(entry.Id)].MessageId)] = true
+		logger.Warn("message rejected by the input queue",
+			zap.String("message_id", aws.ToString(byID[aws.ToString(entry.Id)].MessageId)),
+			zap.String("code", aws.ToString(entry.Code)),
+			zap.String("reason", aws.ToString(entry.Message)),
+		)
+	}
+	failed += len(res.Failed)
+
+	deletes := make([]types.DeleteMessageBatchRequestEntry, 0, len(res.Successful))
+	for _, entry := range res.Successful {
+		deletes = append(deletes, types.DeleteMessageBatchRequestEntry{
+			Id:            entry.Id,
+			ReceiptHandle: byID[aws.ToString(entry.Id)].ReceiptHandle,
+		})
+	}
+	if len(deletes) > 0 {
+		// A failed delete only means the message is replayed again by a later run
+		if _, err := r.client.DeleteMessageBatch(ctx, &sqs.DeleteMessageBatchInput{
+			QueueUrl: aws.String(r.source),
+			Entries:  deletes,
+		}); err != nil {
+			logger.Warn("failed to delete replayed messages from the DLQ", zap.Error(err))
+		}
+	}
+	return len(res.Successful), failed
+}
+
+// replayAttributes drops the rejection reason added by the worker and, once the body is
+// mutated, replaces the signature that no longer matches it
+func (r *replayer) replayAttributes(ctx context.Context, msg types.Message, body string) (map[string]types.MessageAttributeValue, error) {
+	attributes := make(map[string]types.MessageAttributeValue, len(msg.MessageAttributes))
+	for name, value := range msg.MessageAttributes {
+		if name != domain.RejectionReasonAttribute {
+			attributes[name] = value
+		}
+	}
+	if len(r.mutations) == 0 {
+		return attributes, nil
+	}
+
+	if r.signer == nil {
+		if _, signed := attributes[signatureAttribute]; signed {
+			return nil, fmt.Errorf("signed messages can only be mutated with --signing-secret")
+		}
+		return attributes, nil
+	}
+	signature, err := r.signer.Sign(ctx, []byte(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign message: %w", err)
+	}
+	attributes[signatureAttribute] = types.MessageAttributeValue{
+		DataType:    aws.String("String"),
+		StringValue: aws.String(signature),
+	}
+	return attributes, nil
+}
+
+// mutateBody overrides top-level fields of the request. Offloaded payloads live in S3 and
+// can only be replayed unchanged
+func mutateBody(body string, mutations map[string]json.RawMessage) (string, error) {
+	if len(mutations) == 0 {
+		return body, nil
+	}
+	if _, ok := domain.DecodePayloadPointer(body); ok {
+		return "", fmt.Errorf("offloaded payloads cannot be mutated")
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(body), &fields); err != nil {
+		return "", fmt.Errorf("failed to parse message: %w", err)
+	}
+	for key, value := range mutations {
+		fields[key] = value
+	}
+
+	mutated, err := json.Marshal(fields)
+	if err != nil {
+		return "", err
+	}
+	return string(mutated), nil
+}
+
+// rateLimiter spaces out replays so a large DLQ does not flood the workers
+type rateLimiter struct {
+	interval time.Duration
+	next     time.Time
+}
+
+// newRateLimiter allows perSecond messages per second; zero or less never waits
+func newRateLimiter(perSecond float64) *rateLimiter {
+	if perSecond <= 0 {
+		return &rateLimiter{}
+	}
+	return &rateLimiter{interval: time.Duration(float64(time.Second) / perSecond)}
+}
+
+// wait blocks until n more messages fit in the rate
+func (l *rateLimiter) wait(ctx context.Context, n int) error {
+	if l.interval == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	if l.next.Before(now) {
+		l.next = now
+	}
+	delay := l.next.Sub(now)
+	l.next = l.next.Add(time.Duration(n) * l.interval)
+	if delay == 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
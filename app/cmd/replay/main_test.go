@@ -0,0 +1,313 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/observability"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/signing"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+// fakeQueues keeps the DLQ in memory and records what reaches the input queue
+type fakeQueues struct {
+	dlq      []types.Message
+	sent     []string
+	attrs    []map[string]types.MessageAttributeValue
+	deleted  []string
+	rejectID string
+	sendErr  error
+}
+
+func (f *fakeQueues) ReceiveMessage(ctx context.Context, params *sqs.ReceiveMessageInput, optFns ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error) {
+	n := min(int(params.MaxNumberOfMessages), len(f.dlq))
+	messages := f.dlq[:n]
+	// Received messages go to the back, as if their visibility timeout had already ended
+	f.dlq = append(append([]types.Message(nil), f.dlq[n:]...), messages...)
+	return &sqs.ReceiveMessageOutput{Messages: messages}, nil
+}
+
+func (f *fakeQueues) SendMessageBatch(ctx context.Context, params *sqs.SendMessageBatchInput, optFns ...func(*sqs.Options)) (*sqs.SendMessageBatchOutput, error) {
+	if f.sendErr != nil {
+		return nil, f.sendErr
+	}
+	out := &sqs.SendMessageBatchOutput{}
+	for _, entry := range params.Entries {
+		if aws.ToString(entry.MessageBody) == f.rejectID {
+			out.Failed = append(out.Failed, types.BatchResultErrorEntry{Id: entry.Id, Code: aws.String("InvalidMessageContents")})
+			continue
+		}
+		f.sent = append(f.sent, aws.ToString(entry.MessageBody))
+		f.attrs = append(f.attrs, entry.MessageAttributes)
+		out.Successful = append(out.Successful, types.SendMessageBatchResultEntry{Id: entry.Id})
+	}
+	return out, nil
+}
+
+func (f *fakeQueues) DeleteMessageBatch(ctx context.Context, params *sqs.DeleteMessageBatchInput, optFns ...func(*sqs.Options)) (*sqs.DeleteMessageBatchOutput, error) {
+	for _, entry := range params.Entries {
+		handle := aws.ToString(entry.ReceiptHandle)
+		f.deleted = append(f.deleted, handle)
+		for i, msg := range f.dlq {
+			if aws.ToString(msg.ReceiptHandle) == handle {
+				f.dlq = append(f.dlq[:i], f.dlq[i+1:]...)
+				break
+			}
+		}
+	}
+	return &sqs.DeleteMessageBatchOutput{}, nil
+}
+
+func dlqMessages(bodies ...string) []types.Message {
+	messages := make([]types.Message, len(bodies))
+	for i, body := range bodies {
+		messages[i] = types.Message{
+			MessageId:     aws.String(fmt.Sprintf("id-%d", i)),
+			ReceiptHandle: aws.String(fmt.Sprintf("handle-%d", i)),
+			Body:          aws.String(body),
+		}
+	}
+	return messages
+}
+
+func newTestReplayer(queues *fakeQueues, batchSize int32, limit int) *replayer {
+	return &replayer{
+		client:    queues,
+		source:    "dlq",
+		target:    "input",
+		batchSize: batchSize,
+		limit:     limit,
+		limiter:   newRateLimiter(0),
+		failed:    map[string]bool{},
+	}
+}
+
+func TestReplayer_DrainsInBatches(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	queues := &fakeQueues{dlq: dlqMessages(`{"process_id":"1"}`, `{"process_id":"2"}`, `{"process_id":"3"}`)}
+	summary, err := newTestReplayer(queues, 2, 0).run(context.Background())
+	if err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+
+	if summary != (replaySummary{Received: 3, Replayed: 3}) {
+		t.Errorf("Unexpected summary %+v", summary)
+	}
+	if len(queues.sent) != 3 || len(queues.dlq) != 0 {
+		t.Errorf("Expected every message moved, got %d sent and %d left", len(queues.sent), len(queues.dlq))
+	}
+}
+
+func TestReplayer_Limit(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	queues := &fakeQueues{dlq: dlqMessages(`{"process_id":"1"}`, `{"process_id":"2"}`, `{"process_id":"3"}`)}
+	summary, err := newTestReplayer(queues, 10, 2).run(context.Background())
+	if err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+
+	if summary.Replayed != 2 || len(queues.dlq) != 1 {
+		t.Errorf("Expected 2 replayed and 1 left, got %+v and %d left", summary, len(queues.dlq))
+	}
+}
+
+func TestReplayer_KeepsRejectedMessages(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	queues := &fakeQueues{
+		dlq:      dlqMessages(`{"process_id":"1"}`, `{"process_id":"bad"}`),
+		rejectID: `{"process_id":"bad"}`,
+	}
+	summary, err := newTestReplayer(queues, 1, 0).run(context.Background())
+	if err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+
+	if summary != (replaySummary{Received: 2, Replayed: 1, Failed: 1}) {
+		t.Errorf("Unexpected summary %+v", summary)
+	}
+	if len(queues.dlq) != 1 || aws.ToString(queues.dlq[0].Body) != `{"process_id":"bad"}` {
+		t.Errorf("Expected the rejected message kept in the DLQ, got %v", queues.dlq)
+	}
+}
+
+func TestReplayer_SendFailure(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	queues := &fakeQueues{dlq: dlqMessages(`{"process_id":"1"}`), sendErr: errors.New("throttled")}
+	summary, err := newTestReplayer(queues, 10, 0).run(context.Background())
+	if err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+
+	if summary.Failed != 1 || len(queues.deleted) != 0 {
+		t.Errorf("Expected the message kept after a failed send, got %+v and %v deleted", summary, queues.deleted)
+	}
+}
+
+func TestReplayer_Mutations(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	mutations := setFlags{}
+	for _, value := range []string{"output_type=sprite", "max_frames=100"} {
+		if err := mutations.Set(value); err != nil {
+			t.Fatalf("Set(%q) failed: %v", value, err)
+		}
+	}
+
+	queues := &fakeQueues{dlq: dlqMessages(`{"process_id":"1","output_type":"frames"}`)}
+	r := newTestReplayer(queues, 10, 0)
+	r.mutations = mutations
+	if _, err := r.run(context.Background()); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+
+	if len(queues.sent) != 1 {
+		t.Fatalf("Expected 1 message sent, got %d", len(queues.sent))
+	}
+	var sent map[string]interface{}
+	if err := json.Unmarshal([]byte(queues.sent[0]), &sent); err != nil {
+		t.Fatalf("Invalid replayed body: %v", err)
+	}
+	if sent["process_id"] != "1" || sent["output_type"] != "sprite" || sent["max_frames"] != float64(100) {
+		t.Errorf("Unexpected replayed body %v", sent)
+	}
+}
+
+// signedDLQMessage is a rejected message signed with secret
+func signedDLQMessage(t *testing.T, secret, body string) types.Message {
+	t.Helper()
+	signer, err := signing.NewHMACSigner([]byte(secret), "")
+	if err != nil {
+		t.Fatalf("Failed to create signer: %v", err)
+	}
+	signature, _ := signer.Sign(context.Background(), []byte(body))
+
+	msg := dlqMessages(body)[0]
+	msg.MessageAttributes = map[string]types.MessageAttributeValue{
+		signatureAttribute:              {DataType: aws.String("String"), StringValue: aws.String(signature)},
+		domain.RejectionReasonAttribute: {DataType: aws.String("String"), StringValue: aws.String("invalid signature")},
+	}
+	return msg
+}
+
+func TestReplayer_ResignsMutations(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	queues := &fakeQueues{dlq: []types.Message{signedDLQMessage(t, "secret", `{"process_id":"1"}`)}}
+	r := newTestReplayer(queues, 10, 0)
+	r.mutations = setFlags{"dry_run": json.RawMessage("true")}
+	r.signer, _ = signing.NewHMACSigner([]byte("secret"), "")
+	if _, err := r.run(context.Background()); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+
+	if len(queues.sent) != 1 {
+		t.Fatalf("Expected 1 message sent, got %d", len(queues.sent))
+	}
+	if _, ok := queues.attrs[0][domain.RejectionReasonAttribute]; ok {
+		t.Error("Expected the rejection reason dropped")
+	}
+	signature := aws.ToString(queues.attrs[0][signatureAttribute].StringValue)
+	if !r.signer.Verify([]byte(queues.sent[0]), signature) {
+		t.Errorf("Expected the mutated body re-signed, got signature %q", signature)
+	}
+}
+
+func TestReplayer_RefusesSignedMutationsWithoutSecret(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	queues := &fakeQueues{dlq: []types.Message{signedDLQMessage(t, "secret", `{"process_id":"1"}`)}}
+	r := newTestReplayer(queues, 10, 0)
+	r.mutations = setFlags{"dry_run": json.RawMessage("true")}
+	summary, err := r.run(context.Background())
+	if err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+
+	if summary.Failed != 1 || len(queues.sent) != 0 || len(queues.deleted) != 0 {
+		t.Errorf("Expected the signed message left in the DLQ, got %+v and %d sent", summary, len(queues.sent))
+	}
+}
+
+func TestReplayer_KeepsSignatureWithoutMutations(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	msg := signedDLQMessage(t, "secret", `{"process_id":"1"}`)
+	queues := &fakeQueues{dlq: []types.Message{msg}}
+	if _, err := newTestReplayer(queues, 10, 0).run(context.Background()); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+
+	if len(queues.sent) != 1 {
+		t.Fatalf("Expected 1 message sent, got %d", len(queues.sent))
+	}
+	if _, ok := queues.attrs[0][domain.RejectionReasonAttribute]; ok {
+		t.Error("Expected the rejection reason dropped")
+	}
+	if got := aws.ToString(queues.attrs[0][signatureAttribute].StringValue); got != aws.ToString(msg.MessageAttributes[signatureAttribute].StringValue) {
+		t.Errorf("Expected the original signature kept, got %q", got)
+	}
+}
+
+func TestMutateBody_OffloadedPayload(t *testing.T) {
+	pointer := `["software.amazon.payloadoffloading.PayloadS3Pointer",{"s3BucketName":"bucket","s3Key":"payloads/a.json"}]`
+
+	if body, err := mutateBody(pointer, nil); err != nil || body != pointer {
+		t.Errorf("Expected pointer replayed unchanged, got %q, %v", body, err)
+	}
+	if _, err := mutateBody(pointer, map[string]json.RawMessage{"dry_run": json.RawMessage("true")}); err == nil {
+		t.Error("Expected error when mutating an offloaded payload")
+	}
+}
+
+func TestSetFlags_Invalid(t *testing.T) {
+	if err := (setFlags{}).Set("output_type"); err == nil {
+		t.Error("Expected error without '='")
+	}
+}
+
+func TestRateLimiter(t *testing.T) {
+	limiter := newRateLimiter(100)
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := limiter.wait(context.Background(), 2); err != nil {
+			t.Fatalf("wait failed: %v", err)
+		}
+	}
+	// The third batch waits for the four messages before it at 10ms each
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("Expected at least 40ms, got %v", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := limiter.wait(ctx, 1); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
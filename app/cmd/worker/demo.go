@@ -0,0 +1,163 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/app"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/usecase"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/consumer"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/observability"
+	"go.uber.org/zap"
+)
+
+// Folders of the demo directory
+const (
+	demoInbox     = "inbox"
+	demoSubmitted = "submitted"
+	demoResults   = "results"
+	demoBuckets   = "buckets"
+)
+
+// demoSettleTime is how long a file must stay unchanged in the inbox before it is submitted,
+// so a video still being copied is not picked up
+const demoSettleTime = time.Second
+
+// configureDemo runs the worker without cloud services: the queues are in memory and the
+// buckets are folders under dir/buckets. The queues and buckets of config are replaced
+func configureDemo(config *app.Config, dir string) {
+	config.StorageDir = filepath.Join(dir, demoBuckets)
+	config.MessageTransport = app.TransportMemory
+	worker := &config.Worker
+	worker.DemoDir = dir
+	worker.InputQueue = "input"
+	worker.OutputQueue = "results"
+	worker.OutputBucket = "output"
+	worker.JobsBucket = "input"
+	// The in-memory queue only carries the demo's own jobs, which are submitted anonymously
+	worker.InputSigningSecret = ""
+	worker.InputProducers = nil
+}
+
+// startDemo submits the videos dropped in the inbox of the demo directory and saves the results
+// of the output queue in its results folder. It returns a function that stops both
+func startDemo(ctx context.Context, container *app.Container) (func(), error) {
+	worker := container.Config().Worker
+	dir := worker.DemoDir
+	for _, folder := range []string{demoInbox, demoSubmitted, demoResults} {
+		if err := os.MkdirAll(filepath.Join(dir, folder), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create demo folder: %w", err)
+		}
+	}
+	submitter, err := container.JobSubmitter(ctx)
+	if err != nil {
+		return nil, err
+	}
+	queues, err := container.Queues(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	results := consumer.NewMemoryConsumer(queues.Memory, consumer.MemoryConfig{Queue: worker.OutputQueue}, func(ctx context.Context, msg consumer.Message) error {
+		return saveDemoResult(ctx, dir, msg)
+	})
+	if err := results.Start(ctx); err != nil {
+		return nil, err
+	}
+
+	watchCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(demoSettleTime)
+		defer ticker.Stop()
+		for {
+			submitInbox(watchCtx, dir, submitter, time.Now())
+			select {
+			case <-watchCtx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	absolute, _ := filepath.Abs(dir)
+	observability.GetLogger().Info("demo mode enabled, drop videos in the inbox folder",
+		zap.String("inbox", filepath.Join(absolute, demoInbox)),
+		zap.String("results", filepath.Join(absolute, demoResults)),
+		zap.String("buckets", filepath.Join(absolute, demoBuckets)),
+	)
+	return func() {
+		cancel()
+		<-done
+		results.Stop()
+	}, nil
+}
+
+// submitInbox submits each video of dir/inbox that settled before now as a job and moves it
+// to dir/submitted; a video that fails stays in the inbox and is tried again
+func submitInbox(ctx context.Context, dir string, submitter *usecase.SubmitJobUseCase, now time.Time) {
+	logger := observability.GetLogger()
+
+	entries, err := os.ReadDir(filepath.Join(dir, demoInbox))
+	if err != nil {
+		logger.Warn("failed to read demo inbox", zap.Error(err))
+		return
+	}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil || !info.Mode().IsRegular() || !domain.IsVideoKey(entry.Name()) || now.Sub(info.ModTime()) < demoSettleTime {
+			continue
+		}
+
+		videoPath := filepath.Join(dir, demoInbox, entry.Name())
+		job, err := submitVideo(ctx, submitter, videoPath)
+		if err != nil {
+			logger.Error("failed to submit demo video", zap.String("file", entry.Name()), zap.Error(err))
+			continue
+		}
+		if err := os.Rename(videoPath, filepath.Join(dir, demoSubmitted, job.ProcessID+"_"+entry.Name())); err != nil {
+			logger.Warn("failed to move submitted demo video", zap.String("file", entry.Name()), zap.Error(err))
+		}
+		logger.Info("demo video submitted", zap.String("file", entry.Name()), zap.String("process_id", job.ProcessID))
+	}
+}
+
+func submitVideo(ctx context.Context, submitter *usecase.SubmitJobUseCase, videoPath string) (domain.JobSubmission, error) {
+	video, err := os.Open(videoPath)
+	if err != nil {
+		return domain.JobSubmission{}, err
+	}
+	defer video.Close()
+	return submitter.Submit(ctx, domain.JobProducer{}, video, filepath.Base(videoPath), nil)
+}
+
+// saveDemoResult writes a result message to dir/results, named after its process_id
+func saveDemoResult(ctx context.Context, dir string, msg consumer.Message) error {
+	var result struct {
+		ProcessID string `json:"process_id"`
+		Status    string `json:"status"`
+	}
+	json.Unmarshal([]byte(msg.Body), &result)
+	name := result.ProcessID
+	if name == "" {
+		name = msg.ID
+	}
+
+	resultPath := filepath.Join(dir, demoResults, filepath.Base(name)+".json")
+	if err := os.WriteFile(resultPath, []byte(msg.Body), 0644); err != nil {
+		observability.GetLogger().Error("failed to save demo result", zap.String("process_id", result.ProcessID), zap.Error(err))
+		return err
+	}
+	observability.GetLogger().Info("demo result saved",
+		zap.String("process_id", result.ProcessID),
+		zap.String("status", result.Status),
+		zap.String("file", resultPath),
+	)
+	return nil
+}
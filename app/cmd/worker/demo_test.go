@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/adapter"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/usecase"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/consumer"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/message"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/observability"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/storage"
+)
+
+func TestSubmitInbox(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	dir := t.TempDir()
+	for _, folder := range []string{demoInbox, demoSubmitted} {
+		os.MkdirAll(filepath.Join(dir, folder), 0755)
+	}
+	now := time.Now()
+	for name, age := range map[string]time.Duration{"clip.mp4": time.Minute, "copying.mp4": 0, "notes.txt": time.Minute} {
+		videoPath := filepath.Join(dir, demoInbox, name)
+		os.WriteFile(videoPath, []byte("video"), 0644)
+		os.Chtimes(videoPath, now.Add(-age), now.Add(-age))
+	}
+
+	queue := message.NewMemoryQueue()
+	storagePort := adapter.NewStorageAdapter(storage.NewFileClient(filepath.Join(dir, demoBuckets)))
+	submitter := usecase.NewSubmitJobUseCase(storagePort, adapter.NewMessageAdapter(queue), "input", "input")
+
+	submitInbox(context.Background(), dir, submitter, now)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	msg, err := queue.Receive(ctx, "input")
+	if err != nil {
+		t.Fatalf("Expected the settled video enqueued: %v", err)
+	}
+	var job struct {
+		ProcessID   string `json:"process_id"`
+		VideoBucket string `json:"video_bucket"`
+		VideoKey    string `json:"video_key"`
+	}
+	json.Unmarshal([]byte(msg.Body), &job)
+	if job.VideoBucket != "input" || filepath.Base(job.VideoKey) != "clip.mp4" {
+		t.Errorf("Unexpected job message %s", msg.Body)
+	}
+	if _, err := storagePort.GetObject(context.Background(), job.VideoBucket, job.VideoKey); err != nil {
+		t.Errorf("Expected the video staged in the input bucket: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, demoSubmitted, job.ProcessID+"_clip.mp4")); err != nil {
+		t.Errorf("Expected the video moved to the submitted folder: %v", err)
+	}
+	// A video still being copied and other files stay in the inbox
+	for _, name := range []string{"copying.mp4", "notes.txt"} {
+		if _, err := os.Stat(filepath.Join(dir, demoInbox, name)); err != nil {
+			t.Errorf("Expected %s to stay in the inbox: %v", name, err)
+		}
+	}
+}
+
+func TestSaveDemoResult(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, demoResults), 0755)
+
+	body := `{"process_id":"p1","status":"success"}`
+	if err := saveDemoResult(context.Background(), dir, consumer.Message{ID: "7", Body: body}); err != nil {
+		t.Fatalf("saveDemoResult failed: %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, demoResults, "p1.json")); err != nil || string(data) != body {
+		t.Errorf("Expected the result saved by process id, got %q (%v)", data, err)
+	}
+
+	// Results that are not JSON (e.g. Avro) are named after the message
+	if err := saveDemoResult(context.Background(), dir, consumer.Message{ID: "8", Body: "\x00avro"}); err != nil {
+		t.Fatalf("saveDemoResult failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, demoResults, "8.json")); err != nil {
+		t.Errorf("Expected the result saved by message id: %v", err)
+	}
+}
@@ -2,48 +2,73 @@ package main
 
 import (
 	"context"
-	"encoding/json"
+	"flag"
 	"fmt"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
 	"github.com/SOAT-Project/hackaton-soat-processor/internal/adapter"
-	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/app"
 	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/usecase"
-	"github.com/SOAT-Project/hackaton-soat-processor/pkg/message"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/consumer"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/buildinfo"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/ffmpeg"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/logsink"
 	"github.com/SOAT-Project/hackaton-soat-processor/pkg/observability"
-	"github.com/SOAT-Project/hackaton-soat-processor/pkg/storage"
-	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
-	"github.com/aws/aws-sdk-go-v2/service/sqs"
-	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
 	"go.uber.org/zap"
 )
 
-var (
-	inputQueueURL  = os.Getenv("QUEUE_INPUT")
-	outputQueueURL = os.Getenv("QUEUE_OUTPUT")
-	outputBucket   = os.Getenv("STORAGE_OUTPUT")
-	region         = os.Getenv("AWS_REGION")
-)
-
 func main() {
+	showVersion := flag.Bool("version", false, "print the build information and exit")
+	demo := flag.Bool("demo", false, "run without cloud services: in-memory queues, buckets under -demo-dir and the videos dropped in its inbox folder")
+	demoPath := flag.String("demo-dir", "demo", "directory of the demo mode")
+	flag.Parse()
+	if *showVersion {
+		fmt.Println(buildinfo.Get(installedFFmpegVersion()))
+		return
+	}
+
+	// The environment is read even when the rest of the configuration does not parse
+	appConfig, configErr := app.ConfigFromEnv()
+	worker := appConfig.Worker
+
 	// Initialize logger
-	environment := getEnv("ENVIRONMENT", "development")
-	if err := observability.InitLogger(environment); err != nil {
+	if err := observability.InitLogger(worker.Environment); err != nil {
 		fmt.Printf("Failed to initialize logger: %v\n", err)
 		os.Exit(1)
 	}
 	defer observability.Sync()
 
 	logger := observability.GetLogger()
+	if configErr != nil {
+		logger.Fatal("invalid configuration", zap.Error(configErr))
+	}
+	closeLogSinks, err := startLogSinks(appConfig.Region, worker.Logs)
+	if err != nil {
+		logger.Fatal("failed to configure log forwarding", zap.Error(err))
+	}
+	defer closeLogSinks()
+	observability.WithFields(
+		zap.String("worker_hostname", worker.Identity.Hostname),
+		zap.String("worker_pod", worker.Identity.Pod),
+		zap.String("version", worker.Identity.Version),
+		zap.String("commit", worker.Identity.Commit),
+	)
+
+	logger = observability.GetLogger()
 	logger.Info("starting video processor worker",
-		zap.String("environment", environment),
-		zap.String("version", "1.0.0"),
+		zap.String("environment", worker.Environment),
 	)
 
+	// The tenant label is bounded before any metric is recorded
+	observability.ConfigureTenantLabels(worker.MetricsMaxTenants, worker.MetricsTenants)
+	observability.ConfigureSLO(worker.SLOObjective)
+
 	// Start metrics server
 	metricsPort := 8080
 	metricsServer := observability.NewMetricsServer(metricsPort)
@@ -51,191 +76,311 @@ func main() {
 		logger.Fatal("failed to start metrics server", zap.Error(err))
 	}
 
-	// Validate environment variables
-	if err := validateEnvVars(); err != nil {
+	if *demo {
+		configureDemo(&appConfig, *demoPath)
+	}
+	if err := appConfig.ValidateWorker(); err != nil {
 		logger.Fatal("environment validation failed", zap.Error(err))
 	}
+	if appConfig.Region == "" {
+		appConfig.Region = app.DefaultRegion
+		logger.Warn("AWS_REGION not set, using default", zap.String("region", appConfig.Region))
+	}
+	worker = appConfig.Worker
 
 	logger.Info("configuration loaded",
-		zap.String("input_queue", inputQueueURL),
-		zap.String("output_queue", outputQueueURL),
-		zap.String("output_bucket", outputBucket),
-		zap.String("region", region),
+		zap.String("input_queue", worker.InputQueue),
+		zap.String("output_queue", worker.OutputQueue),
+		zap.String("output_bucket", worker.OutputBucket),
+		zap.String("region", appConfig.Region),
+		zap.String("storage_class", worker.StorageClass),
+		zap.Int("tenants", len(worker.Tenants)),
+		zap.Int("allowed_sources", len(worker.Sources)),
+		zap.Strings("video_extensions", worker.Extensions),
+		zap.Int("allowed_result_destinations", len(worker.ResultDestinations)),
 		zap.Int("metrics_port", metricsPort),
 	)
 
-	// Configure AWS
 	ctx := context.Background()
-	cfg, err := config.LoadDefaultConfig(ctx, config.WithRegion(region))
-	if err != nil {
-		logger.Fatal("failed to load AWS config", zap.Error(err))
+
+	// Locate ffmpeg/ffprobe and check they meet the pipeline requirements
+	installation, ffmpegErr := discoverFFmpeg(ctx, worker.FFmpeg)
+	if ffmpegErr != nil {
+		logger.Error("ffmpeg requirements not met, worker will stay not ready", zap.Error(ffmpegErr))
+	} else {
+		logger.Info("ffmpeg discovered",
+			zap.String("ffmpeg_path", installation.FFmpegPath),
+			zap.String("ffprobe_path", installation.FFprobePath),
+			zap.String("ffmpeg_version", installation.Version.String()),
+			zap.Int("encoders", len(installation.Encoders)),
+			zap.Int("filters", len(installation.Filters)),
+			zap.Int("decoders", len(installation.Decoders)),
+		)
+	}
+	if installation != nil {
+		metricsServer.SetVersion(buildinfo.Get(installation.Version.String()))
+		appConfig.FFmpegPath, appConfig.FFprobePath, appConfig.Decoders = installation.FFmpegPath, installation.FFprobePath, installation.Decoders
 	}
 
-	// Initialize services and adapters
-	storageService := storage.NewS3Client(cfg)
-	storagePort := adapter.NewStorageAdapter(storageService)
+	// Build the adapters and use cases from the configuration
+	container := app.New(appConfig)
+	defer container.Close()
 
-	messageService := message.NewSQSClient(cfg)
-	messagePort := adapter.NewMessageAdapter(messageService)
+	// Run a sample video through the local pipeline before taking traffic
+	if worker.SelfTest && ffmpegErr == nil {
+		videoProcessor, err := container.VideoProcessor(ctx)
+		if err != nil {
+			logger.Fatal("failed to configure video processor", zap.Error(err))
+		}
+		start := time.Now()
+		if ffmpegErr = runSelfTest(ctx, appConfig.FFmpegPath, videoProcessor, worker.SelfTestTimeout); ffmpegErr != nil {
+			logger.Error("startup self-test failed, worker will stay not ready", zap.Error(ffmpegErr))
+		} else {
+			logger.Info("startup self-test passed", zap.Duration("duration", time.Since(start)))
+		}
+	}
 
-	// Use /tmp which always has write permission for all users
-	videoProcessor := adapter.NewFFmpegVideoProcessor("/tmp/video-processor")
+	if _, err := container.ProcessVideoUseCase(ctx); err != nil {
+		logger.Fatal("failed to configure video processing", zap.Error(err))
+	}
 
-	// Initialize use case
-	processVideoUseCase := usecase.NewProcessVideoUseCase(
-		storagePort,
-		messagePort,
-		videoProcessor,
-		outputBucket,
-		outputQueueURL,
-	)
+	stopOutbox, err := startOutboxDispatcher(ctx, container)
+	if err != nil {
+		logger.Fatal("failed to start result outbox", zap.Error(err))
+	}
 
-	// Initialize SQS client for message consumption
-	sqsClient := sqs.NewFromConfig(cfg)
+	jobsServer, err := startJobsServer(ctx, container)
+	if err != nil {
+		logger.Fatal("failed to start jobs server", zap.Error(err))
+	}
 
-	// Channel for graceful shutdown
-	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	stopConfirmations, err := startConfirmationConsumer(ctx, container)
+	if err != nil {
+		logger.Fatal("failed to start confirmation consumer", zap.Error(err))
+	}
 
-	logger.Info("worker initialized successfully")
+	// Graceful shutdown on SIGINT/SIGTERM
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	// Mark server as ready to receive traffic
-	metricsServer.SetReady(true)
-	logger.Info("ready to process messages")
+	logger.Info("worker initialized successfully")
 
-	// Main processing loop
-	running := true
-	for running {
-		select {
-		case <-sigChan:
-			logger.Info("shutdown signal received, stopping worker")
-			running = false
-			continue
-		default:
-		}
+	if ffmpegErr != nil {
+		// Stay alive but not ready so the failure is visible without consuming messages
+		<-ctx.Done()
+		logger.Info("shutdown signal received, stopping worker")
+		stopConfirmations()
+		stopOutbox()
+		shutdown(metricsServer, jobsServer)
+		return
+	}
 
-		// Receive messages from queue
-		res, err := sqsClient.ReceiveMessage(ctx, &sqs.ReceiveMessageInput{
-			QueueUrl:            aws.String(inputQueueURL),
-			MaxNumberOfMessages: 1,
-			WaitTimeSeconds:     10,
-			VisibilityTimeout:   300, // 5 minutos para processar
-		})
+	inputConsumers, runConfig, err := container.InputConsumers(ctx)
+	if err != nil {
+		logger.Fatal("failed to create input consumers", zap.Error(err))
+	}
+	runConfig.Readiness = metricsServer
 
+	stopDemo := func() {}
+	if worker.DemoDir != "" {
+		stopDemo, err = startDemo(ctx, container)
 		if err != nil {
-			logger.Warn("error receiving message", zap.Error(err))
-			observability.RecordSQSOperation("receive", false)
-			time.Sleep(5 * time.Second)
-			continue
+			logger.Fatal("failed to start demo mode", zap.Error(err))
 		}
+	}
 
-		observability.RecordSQSOperation("receive", true)
-
-		// Process each message
-		for _, msg := range res.Messages {
-			if err := processMessage(ctx, processVideoUseCase, sqsClient, msg); err != nil {
-				logger.Error("error processing message", zap.Error(err))
-				observability.RecordMessageProcessed(false)
-			} else {
-				observability.RecordMessageProcessed(true)
-			}
-		}
+	queues, err := container.Queues(ctx)
+	if err != nil {
+		logger.Fatal("failed to configure queue consumers", zap.Error(err))
 	}
+	metricsServer.SetQueueDepth(queues.QueueDepth(worker.InputQueue))
 
-	// Graceful shutdown
-	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+	// Mark server as ready to receive traffic
+	metricsServer.SetReady(true)
+	logger.Info("ready to process messages")
 
-	if err := metricsServer.Stop(shutdownCtx); err != nil {
-		logger.Error("error stopping metrics server", zap.Error(err))
+	if err := consumer.Run(ctx, inputConsumers, runConfig); err != nil {
+		// The replica exits; with a lease its restart comes back on standby
+		logger.Error("input consumption stopped, stopping worker", zap.Error(err))
+	} else {
+		logger.Info("shutdown signal received, stopping worker")
 	}
 
-	logger.Info("worker stopped gracefully")
+	stopDemo()
+	stopConfirmations()
+	stopOutbox()
+	shutdown(metricsServer, jobsServer)
 }
 
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
+// startOutboxDispatcher retries the result messages left in the outbox every
+// OUTBOX_DISPATCH_INTERVAL. It returns a function that stops the dispatcher, which does
+// nothing without an outbox, when results are sent directly
+func startOutboxDispatcher(ctx context.Context, container *app.Container) (func(), error) {
+	dispatcher, err := container.OutboxDispatcher(ctx)
+	if err != nil || dispatcher == nil {
+		return func() {}, err
 	}
-	return defaultValue
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		dispatcher.Run(ctx, container.Config().Worker.OutboxInterval)
+	}()
+	return func() {
+		cancel()
+		<-done
+	}, nil
 }
 
-func validateEnvVars() error {
-	logger := observability.GetLogger()
+// startConfirmationConsumer reads the deletion confirmations sent by the consumer on
+// CONFIRM_QUEUE and deletes (or keeps) the original videos. It returns a function that stops
+// the consumer, which does nothing when the two-phase deletion is disabled
+func startConfirmationConsumer(ctx context.Context, container *app.Container) (func(), error) {
+	worker := container.Config().Worker
+	if worker.ConfirmQueue == "" {
+		return func() {}, nil
+	}
 
-	if inputQueueURL == "" {
-		return fmt.Errorf("QUEUE_INPUT environment variable is required")
+	storagePort, err := container.Storage(ctx)
+	if err != nil {
+		return nil, err
 	}
-	if outputQueueURL == "" {
-		return fmt.Errorf("QUEUE_OUTPUT environment variable is required")
+	queues, err := container.Queues(ctx)
+	if err != nil {
+		return nil, err
 	}
-	if outputBucket == "" {
-		return fmt.Errorf("STORAGE_OUTPUT environment variable is required")
+	errorReporter, err := container.ErrorReporter()
+	if err != nil {
+		return nil, err
 	}
-	if region == "" {
-		region = "us-east-1" // Default
-		logger.Warn("AWS_REGION not set, using default", zap.String("region", region))
+	confirmDeletion := usecase.NewConfirmDeletionUseCase(storagePort, worker.OutputBucket)
+	confirmations, err := queues.NewConsumer(consumer.SQSConfig{
+		QueueURL:            worker.ConfirmQueue,
+		MaxNumberOfMessages: 10,
+		WaitTimeSeconds:     10,
+	}, consumer.Chain(consumer.ConfirmMessage(confirmDeletion), consumer.Recovery(errorReporter)))
+	if err != nil {
+		return nil, err
+	}
+	if err := confirmations.Start(context.Background()); err != nil {
+		return nil, err
 	}
-	return nil
-}
 
-func processMessage(ctx context.Context, useCase *usecase.ProcessVideoUseCase, sqsClient *sqs.Client, msg types.Message) error {
-	logger := observability.GetLogger().With(zap.String("message_id", *msg.MessageId))
-	logger.Info("received message from queue")
+	observability.GetLogger().Info("two-phase deletion enabled, original videos wait for confirmation",
+		zap.String("queue", worker.ConfirmQueue),
+	)
+	return confirmations.Stop, nil
+}
 
-	// Parse message
-	var request struct {
-		ProcessID   string `json:"process_id"`
-		VideoBucket string `json:"video_bucket"`
-		VideoKey    string `json:"video_key"`
+// startJobsServer serves POST /processor/jobs and GET /processor/jobs/{id}/timeline on
+// JOBS_HTTP_PORT, returning nil when the HTTP mode is disabled. It has its own listener because uploads outlast the metrics
+// server timeouts
+func startJobsServer(ctx context.Context, container *app.Container) (*http.Server, error) {
+	worker := container.Config().Worker
+	if worker.JobsPort == "" {
+		return nil, nil
 	}
 
-	if err := json.Unmarshal([]byte(*msg.Body), &request); err != nil {
-		logger.Error("failed to parse message", zap.Error(err))
-		// Delete invalid message from queue
-		deleteMessage(ctx, sqsClient, msg)
-		return err
+	submitter, err := container.JobSubmitter(ctx)
+	if err != nil {
+		return nil, err
 	}
-
-	logger.Info("message parsed successfully",
-		zap.String("process_id", request.ProcessID),
-		zap.String("video_bucket", request.VideoBucket),
-		zap.String("video_key", request.VideoKey),
-	)
-
-	// Create domain object
-	videoProcess := domain.VideoProcess{
-		ProcessID:   request.ProcessID,
-		VideoBucket: request.VideoBucket,
-		VideoKey:    request.VideoKey,
-		CreatedAt:   time.Now(),
+	storagePort, err := container.Storage(ctx)
+	if err != nil {
+		return nil, err
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/processor/jobs", adapter.NewJobsHandler(submitter, "/tmp/video-processor/uploads", worker.JobsMaxUploadBytes, worker.JobsURLHosts))
+	mux.Handle(adapter.JobTimelinePattern, adapter.NewJobTimelineHandler(usecase.NewGetJobTimelineUseCase(storagePort, worker.OutputBucket)))
+
+	server := &http.Server{
+		Addr:              ":" + worker.JobsPort,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+		IdleTimeout:       120 * time.Second,
 	}
 
-	// Execute use case
-	err := useCase.Execute(ctx, videoProcess)
+	logger := observability.GetLogger()
+	go func() {
+		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logger.Error("jobs server error", zap.Error(err))
+		}
+	}()
+
+	logger.Info("jobs endpoint enabled",
+		zap.String("port", worker.JobsPort),
+		zap.String("input_bucket", worker.JobsBucket),
+		zap.Int64("max_upload_bytes", worker.JobsMaxUploadBytes),
+		zap.Strings("url_allowed_hosts", worker.JobsURLHosts),
+	)
+	return server, nil
+}
 
-	// Delete message from queue (both on success and error, since we already sent notification)
-	deleteMessage(ctx, sqsClient, msg)
+// discoverFFmpeg locates ffmpeg/ffprobe and checks they meet the requirements of the pipeline
+func discoverFFmpeg(ctx context.Context, req ffmpeg.Requirements) (*ffmpeg.Installation, error) {
+	probeCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
 
-	return err
+	return ffmpeg.Discover(probeCtx, req)
 }
 
-func deleteMessage(ctx context.Context, sqsClient *sqs.Client, msg types.Message) {
+// shutdown gracefully stops the metrics server
+func shutdown(metricsServer *observability.MetricsServer, jobsServer *http.Server) {
 	logger := observability.GetLogger()
 
-	_, err := sqsClient.DeleteMessage(ctx, &sqs.DeleteMessageInput{
-		QueueUrl:      aws.String(inputQueueURL),
-		ReceiptHandle: msg.ReceiptHandle,
-	})
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
 
+	if jobsServer != nil {
+		if err := jobsServer.Shutdown(shutdownCtx); err != nil {
+			logger.Error("error stopping jobs server", zap.Error(err))
+		}
+	}
+
+	if err := metricsServer.Stop(shutdownCtx); err != nil {
+		logger.Error("error stopping metrics server", zap.Error(err))
+	}
+
+	logger.Info("worker stopped gracefully")
+}
+
+// installedFFmpegVersion reads the ffmpeg version for --version, empty when ffmpeg is missing
+func installedFFmpegVersion() string {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	version, err := ffmpeg.InstalledVersion(ctx, os.Getenv("FFMPEG_PATH"))
 	if err != nil {
-		logger.Warn("failed to delete message from queue",
-			zap.String("message_id", *msg.MessageId),
-			zap.Error(err),
-		)
-		observability.RecordSQSOperation("delete", false)
-	} else {
-		logger.Debug("message deleted from queue", zap.String("message_id", *msg.MessageId))
-		observability.RecordSQSOperation("delete", true)
+		return ""
+	}
+	return version.String()
+}
+
+// startLogSinks forwards the logs to CloudWatch Logs (LOG_CLOUDWATCH_GROUP) and to Loki
+// (LOG_LOKI_URL) besides stdout, for environments without a node-level log shipper; the
+// returned func flushes them
+func startLogSinks(region string, logs app.LogSinksConfig) (func(), error) {
+	var writers []*logsink.BatchWriter
+	if logs.CloudWatchGroup != "" {
+		cfg, err := config.LoadDefaultConfig(context.Background(), config.WithRegion(region))
+		if err != nil {
+			return nil, fmt.Errorf("failed to load AWS config: %w", err)
+		}
+		sink := logsink.NewCloudWatchSink(cloudwatchlogs.NewFromConfig(cfg), logs.CloudWatchGroup, logs.CloudWatchStream)
+		writers = append(writers, logsink.NewBatchWriter(sink, logs.Batching))
+	}
+	if logs.LokiURL != "" {
+		sink := logsink.NewLokiSink(&http.Client{Timeout: 10 * time.Second}, logs.LokiURL, logs.LokiLabels, logs.LokiTenant)
+		writers = append(writers, logsink.NewBatchWriter(sink, logs.Batching))
 	}
+
+	for _, writer := range writers {
+		observability.AddLogSink(writer)
+	}
+	return func() {
+		for _, writer := range writers {
+			writer.Close()
+		}
+	}, nil
 }
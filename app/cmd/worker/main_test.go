@@ -1,10 +1,12 @@
 package main
 
-import "testing"
+import (
+	"testing"
+)
 
 func TestMainFunctionality(t *testing.T) {
     // Add test cases to validate the behavior of the worker's main functionality
     t.Run("Test Case 1", func(t *testing.T) {
         // Your test logic here
     })
-}
\ No newline at end of file
+}
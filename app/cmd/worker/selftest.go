@@ -0,0 +1,85 @@
+package main
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/port"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/ffmpeg"
+)
+
+// selfTestJobID keys the directories and zip of the self-test, apart from any real job
+const selfTestJobID = "self-test"
+
+// selfTestSampleArgs renders a 2s 160x120 test pattern with ffmpeg's own source and mpeg4
+// encoder, so the sample needs no file in the image nor an optional encoder
+var selfTestSampleArgs = []string{
+	"-nostdin", "-loglevel", "error",
+	"-f", "lavfi", "-i", "testsrc=duration=2:size=160x120:rate=10",
+	"-c:v", "mpeg4", "-pix_fmt", "yuv420p", "-y",
+}
+
+// runSelfTest runs a sample video through the local pipeline (probe, frame extraction,
+// post-processing and zip), with no queue or bucket involved. A broken ffmpeg image fails
+// here instead of on the first real job
+func runSelfTest(ctx context.Context, ffmpegPath string, processor port.VideoProcessorPort, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	dir, err := os.MkdirTemp("", "processor-self-test")
+	if err != nil {
+		return fmt.Errorf("failed to create self-test directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	if ffmpegPath == "" {
+		ffmpegPath = "ffmpeg"
+	}
+	samplePath := filepath.Join(dir, "sample.mp4")
+	if _, err := ffmpeg.CombinedOutput(ctx, ffmpeg.DefaultKillGrace, ffmpegPath, append(selfTestSampleArgs, samplePath)...); err != nil {
+		return fmt.Errorf("failed to render self-test sample: %w", err)
+	}
+
+	result, err := processor.ProcessVideo(ctx, domain.ProcessingRequest{
+		JobID:     selfTestJobID,
+		VideoPath: samplePath,
+		Options:   domain.FrameOptions{ProcessID: selfTestJobID},
+	})
+	defer func() {
+		for _, zipPath := range result.Artifacts {
+			os.Remove(zipPath)
+		}
+	}()
+	if err != nil {
+		return fmt.Errorf("failed to process self-test sample: %w", err)
+	}
+	if result.FrameCount == 0 {
+		return fmt.Errorf("self-test sample produced no frames")
+	}
+	return checkSelfTestZips(result.Artifacts, result.FrameCount)
+}
+
+// checkSelfTestZips verifies the zips open and hold at least the extracted frames
+func checkSelfTestZips(zipPaths []string, frameCount int) error {
+	if len(zipPaths) == 0 {
+		return fmt.Errorf("self-test produced no zip")
+	}
+	entries := 0
+	for _, zipPath := range zipPaths {
+		reader, err := zip.OpenReader(zipPath)
+		if err != nil {
+			return fmt.Errorf("failed to open self-test zip: %w", err)
+		}
+		entries += len(reader.File)
+		reader.Close()
+	}
+	if entries < frameCount {
+		return fmt.Errorf("self-test zip has %d entries, expected at least %d frames", entries, frameCount)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"archive/zip"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/adapter"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/observability"
+)
+
+func writeTestZip(t *testing.T, path string, names ...string) {
+	t.Helper()
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create zip: %v", err)
+	}
+	defer file.Close()
+	writer := zip.NewWriter(file)
+	for _, name := range names {
+		if _, err := writer.Create(name); err != nil {
+			t.Fatalf("Failed to add zip entry: %v", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Failed to close zip: %v", err)
+	}
+}
+
+func TestCheckSelfTestZips(t *testing.T) {
+	dir := t.TempDir()
+	complete := filepath.Join(dir, "complete.zip")
+	writeTestZip(t, complete, "frame_0001.png", "frame_0002.png")
+	empty := filepath.Join(dir, "empty.zip")
+	writeTestZip(t, empty)
+	corrupt := filepath.Join(dir, "corrupt.zip")
+	os.WriteFile(corrupt, []byte("not a zip"), 0644)
+
+	tests := []struct {
+		name    string
+		paths   []string
+		wantErr bool
+	}{
+		{"complete", []string{complete}, false},
+		{"parts", []string{complete, empty}, false},
+		{"no zip", nil, true},
+		{"missing frames", []string{empty}, true},
+		{"corrupt", []string{corrupt}, true},
+	}
+	for _, tt := range tests {
+		err := checkSelfTestZips(tt.paths, 2)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: expected error %v, got %v", tt.name, tt.wantErr, err)
+		}
+	}
+}
+
+func TestRunSelfTest_MissingFFmpeg(t *testing.T) {
+	processor := adapter.NewFFmpegVideoProcessor(t.TempDir())
+
+	err := runSelfTest(context.Background(), filepath.Join(t.TempDir(), "ffmpeg"), processor, time.Minute)
+	if err == nil {
+		t.Error("Expected error without ffmpeg, got nil")
+	}
+}
+
+func TestRunSelfTest(t *testing.T) {
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		t.Skip("FFmpeg not found, skipping integration test")
+	}
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	tempDir := t.TempDir()
+	processor := adapter.NewFFmpegVideoProcessor(tempDir)
+
+	if err := runSelfTest(context.Background(), "", processor, time.Minute); err != nil {
+		t.Fatalf("Expected self-test to pass, got %v", err)
+	}
+	if zips, _ := filepath.Glob(filepath.Join(tempDir, "*.zip")); len(zips) != 0 {
+		t.Errorf("Expected self-test zips removed, got %v", zips)
+	}
+}
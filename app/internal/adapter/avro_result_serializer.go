@@ -0,0 +1,309 @@
+package adapter
+
+import (
+	_ "embed"
+	"encoding/binary"
+	"maps"
+	"math"
+	"slices"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/port"
+)
+
+// AvroResultSchema is the Avro schema of the result messages, registered in the schema
+// registry when one is configured
+//
+//go:embed schemas/result.avsc
+var AvroResultSchema string
+
+// avroKinds are the symbol positions of the Kind enum in schemas/result.avsc
+var avroKinds = map[string]int64{
+	domain.ResultKindSuccess: 0,
+	domain.ResultKindError:   1,
+	domain.ResultKindDryRun:  2,
+}
+
+// AvroResultSerializer encodes results in the Avro binary encoding of schemas/result.avsc,
+// written by hand in the schema field order. With a schema id the message is framed in the
+// Confluent wire format (magic byte 0 and the big-endian id) so registry-aware consumers can
+// resolve the schema
+type AvroResultSerializer struct {
+	schemaID int
+}
+
+// NewAvroResultSerializer builds the serializer; schemaID zero sends bare Avro, for consumers
+// that read with the schema file
+func NewAvroResultSerializer(schemaID int) port.ResultSerializerPort {
+	return &AvroResultSerializer{schemaID: schemaID}
+}
+
+func (s *AvroResultSerializer) Serialize(result *domain.ProcessResult) ([]byte, error) {
+	var b []byte
+	if s.schemaID > 0 {
+		b = append(b, 0)
+		b = binary.BigEndian.AppendUint32(b, uint32(s.schemaID))
+	}
+
+	kind := result.Kind()
+	var fileBucket, fileKey, errorMessage, errorCode string
+	if kind == domain.ResultKindSuccess {
+		fileBucket, fileKey = result.FileBucket, result.FileKey
+	}
+	if kind == domain.ResultKindError {
+		errorMessage, errorCode = result.ErrorMessage(), domain.ErrorCode(result.Error)
+	}
+
+	b = appendAvroString(b, result.ProcessID)
+	b = appendAvroLong(b, avroKinds[kind])
+	b = appendAvroOptionalString(b, fileBucket)
+	b = appendAvroOptionalString(b, fileKey)
+
+	if len(result.FileKeys) > 0 {
+		b = appendAvroLong(b, int64(len(result.FileKeys)))
+		for _, key := range result.FileKeys {
+			b = appendAvroString(b, key)
+		}
+	}
+	b = appendAvroLong(b, 0)
+
+	b = appendAvroOptionalString(b, result.OutputType)
+	b = appendAvroBoolean(b, result.ConfirmRequired)
+	b = appendAvroOptionalString(b, errorMessage)
+	b = appendAvroOptionalString(b, errorCode)
+
+	if estimate := result.Estimate; estimate != nil {
+		b = appendAvroLong(b, 1)
+		b = binary.LittleEndian.AppendUint64(b, math.Float64bits(estimate.DurationSeconds))
+		b = appendAvroLong(b, int64(estimate.Width))
+		b = appendAvroLong(b, int64(estimate.Height))
+		b = appendAvroLong(b, int64(estimate.Count))
+		b = appendAvroLong(b, estimate.Bytes)
+	} else {
+		b = appendAvroLong(b, 0)
+	}
+
+	if worker := result.Worker; worker != nil {
+		b = appendAvroLong(b, 1)
+		b = appendAvroString(b, worker.Hostname)
+		b = appendAvroString(b, worker.Pod)
+		b = appendAvroString(b, worker.Version)
+		b = appendAvroString(b, worker.Commit)
+	} else {
+		b = appendAvroLong(b, 0)
+	}
+
+	if len(result.Metadata) > 0 {
+		b = appendAvroLong(b, int64(len(result.Metadata)))
+		for _, key := range slices.Sorted(maps.Keys(result.Metadata)) {
+			b = appendAvroString(b, key)
+			b = appendAvroString(b, string(result.Metadata[key]))
+		}
+	}
+	b = appendAvroLong(b, 0)
+
+	if retry := result.Retry; retry != nil && kind == domain.ResultKindError {
+		b = appendAvroLong(b, 1)
+		b = appendAvroLong(b, int64(retry.Attempt))
+		b = appendAvroLong(b, int64(retry.MaxAttempts))
+		b = appendAvroBoolean(b, retry.WillRetry)
+	} else {
+		b = appendAvroLong(b, 0)
+	}
+	b = appendAvroBoolean(b, result.SLABreached)
+
+	if usage := result.Usage; usage != nil {
+		b = appendAvroLong(b, 1)
+		b = appendAvroLong(b, usage.S3GetRequests)
+		b = appendAvroLong(b, usage.S3PutRequests)
+		b = appendAvroLong(b, usage.BytesDownloaded)
+		b = appendAvroLong(b, usage.BytesUploaded)
+		b = binary.LittleEndian.AppendUint64(b, math.Float64bits(usage.CPUSeconds))
+		b = binary.LittleEndian.AppendUint64(b, math.Float64bits(usage.EstimatedCost))
+	} else {
+		b = appendAvroLong(b, 0)
+	}
+
+	if len(result.Items) > 0 {
+		b = appendAvroLong(b, int64(len(result.Items)))
+		for _, item := range result.Items {
+			b = appendAvroString(b, item.VideoKey)
+			b = appendAvroBoolean(b, item.Success)
+			b = appendAvroOptionalString(b, item.FileKey)
+			if len(item.FileKeys) > 0 {
+				b = appendAvroLong(b, int64(len(item.FileKeys)))
+				for _, key := range item.FileKeys {
+					b = appendAvroString(b, key)
+				}
+			}
+			b = appendAvroLong(b, 0)
+			b = appendAvroLong(b, int64(item.Frames))
+			b = appendAvroOptionalString(b, item.Error)
+			b = appendAvroOptionalString(b, item.ErrorCode)
+		}
+	}
+	b = appendAvroLong(b, 0)
+	b = appendAvroOptionalString(b, result.BatchStatus)
+
+	if loudness := result.Loudness; loudness != nil {
+		b = appendAvroLong(b, 1)
+		for _, value := range []float64{
+			loudness.InputIntegrated, loudness.InputTruePeak, loudness.InputRange, loudness.InputThreshold,
+			loudness.OutputIntegrated, loudness.OutputTruePeak, loudness.OutputRange, loudness.TargetOffset,
+		} {
+			b = binary.LittleEndian.AppendUint64(b, math.Float64bits(value))
+		}
+	} else {
+		b = appendAvroLong(b, 0)
+	}
+
+	if qc := result.QC; qc != nil {
+		b = appendAvroLong(b, 1)
+		b = binary.LittleEndian.AppendUint64(b, math.Float64bits(qc.DurationSeconds))
+		b = appendAvroBoolean(b, qc.HasAudio)
+		b = appendAvroTimeWindows(b, qc.BlackIntervals)
+		b = appendAvroTimeWindows(b, qc.SilenceIntervals)
+		b = appendAvroBoolean(b, qc.Truncated)
+	} else {
+		b = appendAvroLong(b, 0)
+	}
+
+	if result.Barcodes != nil {
+		b = appendAvroLong(b, 1)
+		if len(result.Barcodes) > 0 {
+			b = appendAvroLong(b, int64(len(result.Barcodes)))
+			for _, barcode := range result.Barcodes {
+				b = appendAvroString(b, barcode.Format)
+				b = appendAvroString(b, barcode.Value)
+				if len(barcode.Timestamps) > 0 {
+					b = appendAvroLong(b, int64(len(barcode.Timestamps)))
+					for _, timestamp := range barcode.Timestamps {
+						b = binary.LittleEndian.AppendUint64(b, math.Float64bits(timestamp))
+					}
+				}
+				b = appendAvroLong(b, 0)
+			}
+		}
+		b = appendAvroLong(b, 0)
+	} else {
+		b = appendAvroLong(b, 0)
+	}
+
+	if report := result.Vision; report != nil {
+		b = appendAvroLong(b, 1)
+		b = appendAvroBoolean(b, report.Flagged)
+		if len(report.Frames) > 0 {
+			b = appendAvroLong(b, int64(len(report.Frames)))
+			for _, frame := range report.Frames {
+				b = binary.LittleEndian.AppendUint64(b, math.Float64bits(frame.Time))
+				b = appendAvroVisionLabels(b, frame.Labels)
+				b = appendAvroVisionLabels(b, frame.Moderation)
+			}
+		}
+		b = appendAvroLong(b, 0)
+	} else {
+		b = appendAvroLong(b, 0)
+	}
+	b = appendAvroOptionalString(b, result.VisionKey)
+
+	if transcription := result.Transcription; transcription != nil {
+		b = appendAvroLong(b, 1)
+		b = appendAvroString(b, transcription.Provider)
+		b = appendAvroString(b, transcription.JobName)
+		b = appendAvroString(b, transcription.TranscriptKey)
+		b = appendAvroOptionalString(b, transcription.MessageID)
+	} else {
+		b = appendAvroLong(b, 0)
+	}
+
+	if source := result.Source; source != nil {
+		b = appendAvroLong(b, 1)
+		b = appendAvroLong(b, source.SizeBytes)
+		b = appendAvroOptionalString(b, source.ETag)
+		b = appendAvroOptionalString(b, source.ContentType)
+		if len(source.Metadata) > 0 {
+			b = appendAvroLong(b, int64(len(source.Metadata)))
+			for _, key := range slices.Sorted(maps.Keys(source.Metadata)) {
+				b = appendAvroString(b, key)
+				b = appendAvroString(b, source.Metadata[key])
+			}
+		}
+		b = appendAvroLong(b, 0)
+		b = appendAvroOptionalString(b, source.VersionID)
+	} else {
+		b = appendAvroLong(b, 0)
+	}
+	b = appendAvroOptionalString(b, result.ProcessingBackend)
+
+	if negotiated := result.Negotiated; negotiated != nil {
+		b = appendAvroLong(b, 1)
+		b = appendAvroOptionalString(b, negotiated.Encoding)
+		b = appendAvroOptionalString(b, negotiated.ImageFormat)
+	} else {
+		b = appendAvroLong(b, 0)
+	}
+
+	if quarantine := result.Quarantine; quarantine != nil {
+		b = appendAvroLong(b, 1)
+		b = appendAvroString(b, quarantine.Bucket)
+		b = appendAvroString(b, quarantine.Key)
+	} else {
+		b = appendAvroLong(b, 0)
+	}
+	return b, nil
+}
+
+func (s *AvroResultSerializer) ContentType() string {
+	return domain.ContentTypeAvro
+}
+
+// appendAvroLong writes int and long values as zigzag varints
+func appendAvroLong(b []byte, value int64) []byte {
+	return binary.AppendUvarint(b, uint64(value<<1)^uint64(value>>63))
+}
+
+func appendAvroString(b []byte, value string) []byte {
+	b = appendAvroLong(b, int64(len(value)))
+	return append(b, value...)
+}
+
+func appendAvroBoolean(b []byte, value bool) []byte {
+	if value {
+		return append(b, 1)
+	}
+	return append(b, 0)
+}
+
+// appendAvroOptionalString writes a ["null", "string"] union, empty strings as null
+func appendAvroOptionalString(b []byte, value string) []byte {
+	if value == "" {
+		return appendAvroLong(b, 0)
+	}
+	b = appendAvroLong(b, 1)
+	return appendAvroString(b, value)
+}
+
+// appendAvroVisionLabels writes an array of VisionLabel records in a single block
+func appendAvroVisionLabels(b []byte, labels []domain.VisionLabel) []byte {
+	if len(labels) > 0 {
+		b = appendAvroLong(b, int64(len(labels)))
+		for _, label := range labels {
+			b = appendAvroString(b, label.Name)
+			b = appendAvroOptionalString(b, label.Parent)
+			b = binary.LittleEndian.AppendUint64(b, math.Float64bits(label.Confidence))
+		}
+	}
+	return appendAvroLong(b, 0)
+}
+
+// appendAvroTimeWindows writes an array of TimeWindow records in a single block
+func appendAvroTimeWindows(b []byte, windows []domain.TimeWindow) []byte {
+	if len(windows) > 0 {
+		b = appendAvroLong(b, int64(len(windows)))
+		for _, window := range windows {
+			b = binary.LittleEndian.AppendUint64(b, math.Float64bits(window.Start))
+			b = binary.LittleEndian.AppendUint64(b, math.Float64bits(window.End))
+		}
+	}
+	return appendAvroLong(b, 0)
+}
@@ -0,0 +1,412 @@
+package adapter
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+)
+
+// avroReader reads back the types written by AvroResultSerializer
+type avroReader struct {
+	t *testing.T
+	r *bytes.Reader
+}
+
+func (a avroReader) long() int64 {
+	v, err := binary.ReadUvarint(a.r)
+	if err != nil {
+		a.t.Fatalf("Failed to read long: %v", err)
+	}
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+func (a avroReader) str() string {
+	b := make([]byte, a.long())
+	if _, err := a.r.Read(b); err != nil && len(b) > 0 {
+		a.t.Fatalf("Failed to read string: %v", err)
+	}
+	return string(b)
+}
+
+func (a avroReader) optionalStr() string {
+	if a.long() == 0 {
+		return ""
+	}
+	return a.str()
+}
+
+func (a avroReader) boolean() bool {
+	b, _ := a.r.ReadByte()
+	return b == 1
+}
+
+func (a avroReader) double() float64 {
+	var bits uint64
+	binary.Read(a.r, binary.LittleEndian, &bits)
+	return math.Float64frombits(bits)
+}
+
+func TestAvroResultSchema(t *testing.T) {
+	var schema struct {
+		Name   string `json:"name"`
+		Fields []struct {
+			Name string `json:"name"`
+		} `json:"fields"`
+	}
+	if err := json.Unmarshal([]byte(AvroResultSchema), &schema); err != nil {
+		t.Fatalf("Expected the embedded schema to be JSON: %v", err)
+	}
+	if schema.Name != "ProcessResult" || len(schema.Fields) != 27 {
+		t.Errorf("Unexpected schema %s with %d fields", schema.Name, len(schema.Fields))
+	}
+}
+
+func TestAvroResultSerializer_Success(t *testing.T) {
+	result := &domain.ProcessResult{
+		ProcessID:   "123",
+		Success:     true,
+		FileBucket:  "output",
+		FileKey:     "processed/frames_123.zip",
+		OutputType:  domain.OutputTypeFrames,
+		Worker:      &domain.WorkerIdentity{Hostname: "host", Version: "v1.0.0", Commit: "abc"},
+		Metadata:    map[string]json.RawMessage{"order": json.RawMessage(`{"id":-1}`)},
+		Usage:       &domain.ResourceUsage{S3GetRequests: 1, S3PutRequests: 2, BytesDownloaded: 300, BytesUploaded: 40, CPUSeconds: 1.5, EstimatedCost: 0.25},
+		Items:       []domain.BatchItemResult{{VideoKey: "a.mp4", Success: true, FileKey: "processed/frames_123_1.zip", Frames: 12}},
+		BatchStatus: domain.BatchStatusSucceeded,
+		Loudness:    &domain.LoudnessStats{InputIntegrated: -27.5, OutputIntegrated: -23, TargetOffset: 0.5},
+	}
+
+	body, err := NewAvroResultSerializer(0).Serialize(result)
+	if err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	r := avroReader{t, bytes.NewReader(body)}
+
+	if r.str() != "123" || r.long() != 0 {
+		t.Fatal("Unexpected process_id or kind")
+	}
+	if r.optionalStr() != "output" || r.optionalStr() != result.FileKey || r.long() != 0 {
+		t.Fatal("Unexpected file fields")
+	}
+	if r.optionalStr() != "frames" || r.boolean() {
+		t.Fatal("Unexpected output_type or confirm_required")
+	}
+	if r.optionalStr() != "" || r.optionalStr() != "" || r.long() != 0 {
+		t.Fatal("Expected no error fields nor estimate on success")
+	}
+	if r.long() != 1 || r.str() != "host" || r.str() != "" || r.str() != "v1.0.0" || r.str() != "abc" {
+		t.Fatal("Unexpected worker")
+	}
+	if r.long() != 1 || r.str() != "order" || r.str() != `{"id":-1}` || r.long() != 0 {
+		t.Fatal("Unexpected metadata")
+	}
+	if r.long() != 0 {
+		t.Fatal("Expected no retry on success")
+	}
+	if r.boolean() {
+		t.Fatal("Expected the SLA met")
+	}
+	if r.long() != 1 || r.long() != 1 || r.long() != 2 || r.long() != 300 || r.long() != 40 || r.double() != 1.5 || r.double() != 0.25 {
+		t.Fatal("Unexpected usage")
+	}
+	if r.long() != 1 || r.str() != "a.mp4" || !r.boolean() || r.optionalStr() != "processed/frames_123_1.zip" || r.long() != 0 || r.long() != 12 || r.optionalStr() != "" || r.optionalStr() != "" || r.long() != 0 {
+		t.Fatal("Unexpected batch items")
+	}
+	if r.optionalStr() != domain.BatchStatusSucceeded {
+		t.Fatal("Unexpected batch status")
+	}
+	if r.long() != 1 || r.double() != -27.5 || r.double() != 0 || r.double() != 0 || r.double() != 0 || r.double() != -23 || r.double() != 0 || r.double() != 0 || r.double() != 0.5 {
+		t.Fatal("Unexpected loudness")
+	}
+	if r.long() != 0 || r.long() != 0 || r.long() != 0 || r.optionalStr() != "" || r.long() != 0 || r.long() != 0 || r.optionalStr() != "" || r.long() != 0 || r.long() != 0 {
+		t.Fatal("Expected no qc report, barcodes, vision, transcription, source, processing backend, negotiated output nor quarantine")
+	}
+	if r.r.Len() != 0 {
+		t.Errorf("Expected the whole message read, %d bytes left", r.r.Len())
+	}
+}
+
+func TestAvroResultSerializer_ErrorWithSchemaID(t *testing.T) {
+	serializer := NewAvroResultSerializer(7)
+	if serializer.ContentType() != domain.ContentTypeAvro {
+		t.Errorf("Expected %s, got %s", domain.ContentTypeAvro, serializer.ContentType())
+	}
+
+	body, _ := serializer.Serialize(&domain.ProcessResult{
+		ProcessID:   "123",
+		Error:       errors.New("boom"),
+		Retry:       &domain.RetryInfo{Attempt: 2, MaxAttempts: 3, WillRetry: true},
+		SLABreached: true,
+	})
+	if !bytes.HasPrefix(body, []byte{0, 0, 0, 0, 7}) {
+		t.Fatalf("Expected the Confluent wire format header, got % x", body[:5])
+	}
+
+	r := avroReader{t, bytes.NewReader(body[5:])}
+	if r.str() != "123" || r.long() != 1 {
+		t.Fatal("Unexpected process_id or kind")
+	}
+	r.optionalStr()
+	r.optionalStr()
+	r.long()
+	r.optionalStr()
+	r.boolean()
+	if r.optionalStr() != "boom" || r.optionalStr() != "" {
+		t.Error("Unexpected error fields")
+	}
+	r.long()
+	r.long()
+	r.long()
+	if r.long() != 1 || r.long() != 2 || r.long() != 3 || !r.boolean() {
+		t.Error("Unexpected retry")
+	}
+	if !r.boolean() || r.long() != 0 {
+		t.Error("Expected the SLA breach and no usage")
+	}
+	if r.long() != 0 || r.optionalStr() != "" || r.long() != 0 || r.long() != 0 || r.long() != 0 || r.long() != 0 || r.optionalStr() != "" || r.long() != 0 || r.long() != 0 || r.optionalStr() != "" || r.long() != 0 || r.long() != 0 {
+		t.Error("Expected no batch fields, loudness, qc report, barcodes, vision, transcription, source, processing backend, negotiated output nor quarantine")
+	}
+	if r.r.Len() != 0 {
+		t.Errorf("Expected the whole message read, %d bytes left", r.r.Len())
+	}
+}
+
+func TestAvroResultSerializer_DryRun(t *testing.T) {
+	body, _ := NewAvroResultSerializer(0).Serialize(&domain.ProcessResult{
+		ProcessID: "123",
+		Success:   true,
+		Estimate:  &domain.OutputEstimate{DurationSeconds: 12.5, Width: 1280, Height: 720, Count: 13, Bytes: 1 << 33},
+	})
+
+	r := avroReader{t, bytes.NewReader(body)}
+	if r.str() != "123" || r.long() != 2 {
+		t.Fatal("Unexpected process_id or kind")
+	}
+	r.optionalStr()
+	r.optionalStr()
+	r.long()
+	r.optionalStr()
+	r.boolean()
+	r.optionalStr()
+	r.optionalStr()
+	if r.long() != 1 || r.double() != 12.5 || r.long() != 1280 || r.long() != 720 || r.long() != 13 || r.long() != 1<<33 {
+		t.Error("Unexpected estimate")
+	}
+}
+
+func TestAvroResultSerializer_QC(t *testing.T) {
+	body, _ := NewAvroResultSerializer(0).Serialize(&domain.ProcessResult{
+		ProcessID:  "123",
+		Success:    true,
+		OutputType: domain.OutputTypeQC,
+		QC: &domain.QCReport{
+			DurationSeconds:  60,
+			HasAudio:         true,
+			BlackIntervals:   []domain.TimeWindow{{Start: 0, End: 2.5}},
+			SilenceIntervals: []domain.TimeWindow{},
+			Truncated:        true,
+		},
+	})
+
+	r := avroReader{t, bytes.NewReader(body)}
+	if r.str() != "123" || r.long() != 0 || r.optionalStr() != "" || r.optionalStr() != "" || r.long() != 0 || r.optionalStr() != domain.OutputTypeQC {
+		t.Fatal("Expected a success without file")
+	}
+	// confirm_required, error fields, estimate, worker, metadata, retry, SLA, usage, items,
+	// batch status and loudness
+	r.boolean()
+	r.optionalStr()
+	r.optionalStr()
+	for range 4 {
+		r.long()
+	}
+	r.boolean()
+	r.long()
+	r.long()
+	r.optionalStr()
+	r.long()
+	if r.long() != 1 || r.double() != 60 || !r.boolean() {
+		t.Fatal("Unexpected qc duration or audio")
+	}
+	if r.long() != 1 || r.double() != 0 || r.double() != 2.5 || r.long() != 0 {
+		t.Fatal("Unexpected black intervals")
+	}
+	if r.long() != 0 || !r.boolean() {
+		t.Fatal("Expected no silence and a truncated report")
+	}
+	r.long()
+	r.long()
+	r.optionalStr()
+	r.long()
+	r.long()
+	r.optionalStr()
+	r.long()
+	r.long()
+	if r.r.Len() != 0 {
+		t.Errorf("Expected the whole message read, %d bytes left", r.r.Len())
+	}
+}
+
+func TestAvroResultSerializer_Barcodes(t *testing.T) {
+	result := &domain.ProcessResult{ProcessID: "123", Success: true}
+	withoutScan, _ := NewAvroResultSerializer(0).Serialize(result)
+
+	// barcodes is followed by the vision, transcription, source, processing backend, negotiated and quarantine fields, null here: the null
+	// branch of its union becomes the array branch
+	result.Barcodes = []domain.BarcodeDetection{{Format: "qr_code", Value: "ticket-42", Timestamps: []float64{0, 2}}}
+	body, _ := NewAvroResultSerializer(0).Serialize(result)
+	if !bytes.HasPrefix(body, withoutScan[:len(withoutScan)-8]) {
+		t.Fatal("Expected the other fields unchanged")
+	}
+
+	r := avroReader{t, bytes.NewReader(body[len(withoutScan)-8:])}
+	if r.long() != 1 || r.long() != 1 || r.str() != "qr_code" || r.str() != "ticket-42" {
+		t.Fatal("Unexpected barcode")
+	}
+	if r.long() != 2 || r.double() != 0 || r.double() != 2 || r.long() != 0 || r.long() != 0 {
+		t.Fatal("Unexpected timestamps")
+	}
+	if r.long() != 0 || r.optionalStr() != "" || r.long() != 0 || r.long() != 0 || r.optionalStr() != "" || r.long() != 0 || r.long() != 0 {
+		t.Fatal("Expected no vision, transcription, source, processing backend, negotiated output nor quarantine")
+	}
+	if r.r.Len() != 0 {
+		t.Errorf("Expected the whole message read, %d bytes left", r.r.Len())
+	}
+
+	result.Barcodes = []domain.BarcodeDetection{}
+	body, _ = NewAvroResultSerializer(0).Serialize(result)
+	if !bytes.HasSuffix(body, []byte{2, 0, 0, 0, 0, 0, 0, 0, 0}) || len(body) != len(withoutScan)+1 {
+		t.Errorf("Expected an empty array when the scan found nothing, got % x", body)
+	}
+}
+
+func TestAvroResultSerializer_Vision(t *testing.T) {
+	result := &domain.ProcessResult{ProcessID: "123", Success: true}
+	withoutVision, _ := NewAvroResultSerializer(0).Serialize(result)
+
+	// vision and vision_key are followed by the transcription, source, processing backend, negotiated output and quarantine, null here
+	result.Vision = &domain.VisionReport{Flagged: true, Frames: []domain.VisionFrame{
+		{Time: 10, Labels: []domain.VisionLabel{{Name: "Car", Parent: "Vehicle", Confidence: 98.5}}, Moderation: []domain.VisionLabel{{Name: "Weapons", Confidence: 80}}},
+	}}
+	body, _ := NewAvroResultSerializer(0).Serialize(result)
+	if !bytes.HasPrefix(body, withoutVision[:len(withoutVision)-7]) {
+		t.Fatal("Expected the other fields unchanged")
+	}
+
+	r := avroReader{t, bytes.NewReader(body[len(withoutVision)-7:])}
+	if r.long() != 1 || !r.boolean() || r.long() != 1 || r.double() != 10 {
+		t.Fatal("Unexpected vision frame")
+	}
+	if r.long() != 1 || r.str() != "Car" || r.optionalStr() != "Vehicle" || r.double() != 98.5 || r.long() != 0 {
+		t.Fatal("Unexpected labels")
+	}
+	if r.long() != 1 || r.str() != "Weapons" || r.optionalStr() != "" || r.double() != 80 || r.long() != 0 {
+		t.Fatal("Unexpected moderation labels")
+	}
+	if r.long() != 0 || r.optionalStr() != "" || r.long() != 0 || r.long() != 0 || r.optionalStr() != "" || r.long() != 0 || r.long() != 0 {
+		t.Fatal("Expected the end of the frames, no vision_key, transcription, source, processing backend, negotiated output nor quarantine")
+	}
+	if r.r.Len() != 0 {
+		t.Errorf("Expected the whole message read, %d bytes left", r.r.Len())
+	}
+
+	result.Vision = nil
+	result.VisionKey = "processed/vision_123.json"
+	body, _ = NewAvroResultSerializer(0).Serialize(result)
+	if !bytes.HasSuffix(body, append(append([]byte{2, 50}, "processed/vision_123.json"...), 0, 0, 0, 0, 0)) {
+		t.Errorf("Expected the vision_key before the transcription, source, processing backend, negotiated output and quarantine, got % x", body)
+	}
+}
+
+func TestAvroResultSerializer_Transcription(t *testing.T) {
+	result := &domain.ProcessResult{ProcessID: "123", Success: true}
+	withoutTranscription, _ := NewAvroResultSerializer(0).Serialize(result)
+
+	// transcription is followed by the source, the processing backend, the negotiated output and the quarantine, null here
+	result.Transcription = &domain.TranscriptionRef{Provider: "queue", JobName: "123_abc", TranscriptKey: "processed/transcript_123.json", MessageID: "msg-1"}
+	body, _ := NewAvroResultSerializer(0).Serialize(result)
+	if !bytes.HasPrefix(body, withoutTranscription[:len(withoutTranscription)-5]) {
+		t.Fatal("Expected the other fields unchanged")
+	}
+
+	r := avroReader{t, bytes.NewReader(body[len(withoutTranscription)-5:])}
+	if r.long() != 1 || r.str() != "queue" || r.str() != "123_abc" || r.str() != "processed/transcript_123.json" || r.optionalStr() != "msg-1" {
+		t.Fatal("Unexpected transcription")
+	}
+	if r.long() != 0 || r.optionalStr() != "" || r.long() != 0 || r.long() != 0 {
+		t.Fatal("Expected no source, processing backend, negotiated output nor quarantine")
+	}
+	if r.r.Len() != 0 {
+		t.Errorf("Expected the whole message read, %d bytes left", r.r.Len())
+	}
+}
+
+func TestAvroResultSerializer_Source(t *testing.T) {
+	result := &domain.ProcessResult{ProcessID: "123", Success: true}
+	withoutSource, _ := NewAvroResultSerializer(0).Serialize(result)
+
+	// source is followed by the processing backend, the negotiated output and the quarantine, null here
+	result.Source = &domain.ObjectInfo{SizeBytes: 2048, ETag: "abc", VersionID: "v2", Metadata: map[string]string{"camera": "a7"}}
+	body, _ := NewAvroResultSerializer(0).Serialize(result)
+	if !bytes.HasPrefix(body, withoutSource[:len(withoutSource)-4]) {
+		t.Fatal("Expected the other fields unchanged")
+	}
+
+	r := avroReader{t, bytes.NewReader(body[len(withoutSource)-4:])}
+	if r.long() != 1 || r.long() != 2048 || r.optionalStr() != "abc" || r.optionalStr() != "" {
+		t.Fatal("Unexpected source")
+	}
+	if r.long() != 1 || r.str() != "camera" || r.str() != "a7" || r.long() != 0 {
+		t.Fatal("Unexpected source metadata")
+	}
+	if r.optionalStr() != "v2" || r.optionalStr() != "" || r.long() != 0 || r.long() != 0 {
+		t.Fatal("Unexpected source version")
+	}
+	if r.r.Len() != 0 {
+		t.Errorf("Expected the whole message read, %d bytes left", r.r.Len())
+	}
+}
+
+func TestAvroResultSerializer_ProcessingBackend(t *testing.T) {
+	result := &domain.ProcessResult{ProcessID: "123", Success: true}
+	withoutBackend, _ := NewAvroResultSerializer(0).Serialize(result)
+
+	// processing_backend is followed by negotiated and quarantine, null here
+	result.ProcessingBackend = domain.ProcessingBackendRemote
+	body, _ := NewAvroResultSerializer(0).Serialize(result)
+	prefix := withoutBackend[: len(withoutBackend)-3 : len(withoutBackend)-3]
+	if !bytes.Equal(body, append(append(append(prefix, 2, 12), "remote"...), 0, 0)) {
+		t.Errorf("Expected the processing backend before the end, got % x", body)
+	}
+}
+
+func TestAvroResultSerializer_Negotiated(t *testing.T) {
+	result := &domain.ProcessResult{ProcessID: "123", Success: true}
+	withoutNegotiated, _ := NewAvroResultSerializer(0).Serialize(result)
+
+	// negotiated comes right before quarantine, the last field, with the image format left unset
+	result.Negotiated = &domain.NegotiatedOutput{Encoding: domain.ArchiveEncodingTarGz}
+	body, _ := NewAvroResultSerializer(0).Serialize(result)
+	prefix := withoutNegotiated[: len(withoutNegotiated)-2 : len(withoutNegotiated)-2]
+	if !bytes.Equal(body, append(append(append(prefix, 2, 2, 12), "tar.gz"...), 0, 0)) {
+		t.Errorf("Expected the negotiated output before the end, got % x", body)
+	}
+}
+
+func TestAvroResultSerializer_Quarantine(t *testing.T) {
+	result := &domain.ProcessResult{ProcessID: "123", Error: errors.New("video is empty")}
+	withoutQuarantine, _ := NewAvroResultSerializer(0).Serialize(result)
+
+	// quarantine is the last field
+	result.Quarantine = &domain.ObjectRef{Bucket: "q", Key: "quarantine/123/video.mp4"}
+	body, _ := NewAvroResultSerializer(0).Serialize(result)
+	prefix := withoutQuarantine[: len(withoutQuarantine)-1 : len(withoutQuarantine)-1]
+	if !bytes.Equal(body, append(append(prefix, 2, 2, 'q', 48), "quarantine/123/video.mp4"...)) {
+		t.Errorf("Expected the quarantine location at the end, got % x", body)
+	}
+}
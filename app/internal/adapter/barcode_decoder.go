@@ -0,0 +1,69 @@
+package adapter
+
+import (
+	"image"
+	"strings"
+
+	"github.com/makiuchi-d/gozxing"
+	"github.com/makiuchi-d/gozxing/datamatrix"
+	multiqrcode "github.com/makiuchi-d/gozxing/multi/qrcode"
+	"github.com/makiuchi-d/gozxing/oned"
+)
+
+// decodedBarcode is a value read from one frame
+type decodedBarcode struct {
+	Format string
+	Value  string
+}
+
+// barcodeHints trades speed for recall, as frames are small and often blurred or skewed
+var barcodeHints = map[gozxing.DecodeHintType]interface{}{
+	gozxing.DecodeHintType_TRY_HARDER: true,
+}
+
+// singleBarcodeReaders find at most one code each per frame; QR codes go through the multi
+// reader, as a frame often shows several of them
+func singleBarcodeReaders() []gozxing.Reader {
+	return []gozxing.Reader{
+		datamatrix.NewDataMatrixReader(),
+		oned.NewMultiFormatUPCEANReader(nil),
+		oned.NewCode128Reader(),
+		oned.NewCode39Reader(),
+		oned.NewCode93Reader(),
+	}
+}
+
+// decodeBarcodes returns the distinct codes found in a frame, with the format in lower case as
+// reported in the result, e.g. qr_code
+func decodeBarcodes(img image.Image) []decodedBarcode {
+	bitmap, err := gozxing.NewBinaryBitmapFromImage(img)
+	if err != nil {
+		return nil
+	}
+
+	var results []*gozxing.Result
+	// Decoders return an error when they find nothing, which is the common case
+	if found, err := multiqrcode.NewQRCodeMultiReader().DecodeMultiple(bitmap, barcodeHints); err == nil {
+		results = append(results, found...)
+	}
+	for _, reader := range singleBarcodeReaders() {
+		if result, err := reader.Decode(bitmap, barcodeHints); err == nil {
+			results = append(results, result)
+		}
+	}
+
+	seen := map[decodedBarcode]bool{}
+	var barcodes []decodedBarcode
+	for _, result := range results {
+		barcode := decodedBarcode{
+			Format: strings.ToLower(result.GetBarcodeFormat().String()),
+			Value:  result.GetText(),
+		}
+		if barcode.Value == "" || seen[barcode] {
+			continue
+		}
+		seen[barcode] = true
+		barcodes = append(barcodes, barcode)
+	}
+	return barcodes
+}
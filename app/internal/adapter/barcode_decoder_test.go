@@ -0,0 +1,61 @@
+package adapter
+
+import (
+	"image"
+	"image/draw"
+	"testing"
+
+	"github.com/makiuchi-d/gozxing"
+	"github.com/makiuchi-d/gozxing/oned"
+	"github.com/makiuchi-d/gozxing/qrcode"
+)
+
+func qrImage(t *testing.T, content string) image.Image {
+	t.Helper()
+	matrix, err := qrcode.NewQRCodeWriter().Encode(content, gozxing.BarcodeFormat_QR_CODE, 200, 200, nil)
+	if err != nil {
+		t.Fatalf("Failed to encode QR code: %v", err)
+	}
+	return matrix
+}
+
+func code128Image(t *testing.T, content string) image.Image {
+	t.Helper()
+	matrix, err := oned.NewCode128Writer().Encode(content, gozxing.BarcodeFormat_CODE_128, 300, 80, nil)
+	if err != nil {
+		t.Fatalf("Failed to encode Code 128: %v", err)
+	}
+	return matrix
+}
+
+// sideBySide draws both images next to each other on a white frame
+func sideBySide(left, right image.Image) image.Image {
+	width := left.Bounds().Dx() + right.Bounds().Dx() + 40
+	height := max(left.Bounds().Dy(), right.Bounds().Dy()) + 40
+	frame := image.NewGray(image.Rect(0, 0, width, height))
+	draw.Draw(frame, frame.Bounds(), image.White, image.Point{}, draw.Src)
+	draw.Draw(frame, left.Bounds().Add(image.Pt(10, 20)), left, left.Bounds().Min, draw.Src)
+	draw.Draw(frame, right.Bounds().Add(image.Pt(left.Bounds().Dx()+30, 20)), right, right.Bounds().Min, draw.Src)
+	return frame
+}
+
+func TestDecodeBarcodes(t *testing.T) {
+	barcodes := decodeBarcodes(qrImage(t, "https://example.com/a"))
+	if len(barcodes) != 1 || barcodes[0] != (decodedBarcode{Format: "qr_code", Value: "https://example.com/a"}) {
+		t.Errorf("Expected the QR code, got %+v", barcodes)
+	}
+
+	barcodes = decodeBarcodes(code128Image(t, "SKU-12345"))
+	if len(barcodes) != 1 || barcodes[0] != (decodedBarcode{Format: "code_128", Value: "SKU-12345"}) {
+		t.Errorf("Expected the Code 128 barcode, got %+v", barcodes)
+	}
+
+	barcodes = decodeBarcodes(sideBySide(qrImage(t, "left"), code128Image(t, "RIGHT-1")))
+	if len(barcodes) != 2 {
+		t.Errorf("Expected both codes of the frame, got %+v", barcodes)
+	}
+
+	if barcodes := decodeBarcodes(checkerboardImage()); len(barcodes) != 0 {
+		t.Errorf("Expected no code in a checkerboard, got %+v", barcodes)
+	}
+}
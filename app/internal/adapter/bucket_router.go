@@ -0,0 +1,85 @@
+package adapter
+
+import (
+	"context"
+	"io"
+	"slices"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/port"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/observability"
+)
+
+// BucketRoute is the storage of a bucket owned by Tenants, e.g. a client with their role
+type BucketRoute struct {
+	Storage port.StoragePort
+	Tenants []string
+}
+
+// BucketRouter sends the operations on a routed bucket to its own storage when they belong to
+// a job of a tenant owning it (observability.TenantFromContext), and every other operation to
+// the worker's storage, so a job never borrows the role of another tenant
+type BucketRouter struct {
+	fallback port.StoragePort
+	buckets  map[string]BucketRoute
+}
+
+func NewBucketRouter(fallback port.StoragePort, buckets map[string]BucketRoute) port.StoragePort {
+	return &BucketRouter{
+		fallback: fallback,
+		buckets:  buckets,
+	}
+}
+
+func (r *BucketRouter) storage(ctx context.Context, bucket string) port.StoragePort {
+	tenant := observability.TenantFromContext(ctx)
+	if route, ok := r.buckets[bucket]; ok && tenant != "" && slices.Contains(route.Tenants, tenant) {
+		return route.Storage
+	}
+	return r.fallback
+}
+
+func (r *BucketRouter) GetObject(ctx context.Context, bucket, key string, options ...domain.GetOption) (io.ReadCloser, error) {
+	return r.storage(ctx, bucket).GetObject(ctx, bucket, key, options...)
+}
+
+func (r *BucketRouter) HeadObject(ctx context.Context, bucket, key string) (domain.ObjectInfo, error) {
+	return r.storage(ctx, bucket).HeadObject(ctx, bucket, key)
+}
+
+func (r *BucketRouter) GetObjectIf(ctx context.Context, bucket, key string, condition domain.ObjectCondition, options ...domain.GetOption) (io.ReadCloser, error) {
+	return r.storage(ctx, bucket).GetObjectIf(ctx, bucket, key, condition, options...)
+}
+
+func (r *BucketRouter) PutObject(ctx context.Context, bucket, key string, body io.Reader, options ...domain.PutOption) (string, error) {
+	return r.storage(ctx, bucket).PutObject(ctx, bucket, key, body, options...)
+}
+
+// CopyObject is sent to the storage of the destination, whose role must also read the source
+func (r *BucketRouter) CopyObject(ctx context.Context, srcBucket, srcKey, srcVersionID, dstBucket, dstKey string, options ...domain.PutOption) error {
+	return r.storage(ctx, dstBucket).CopyObject(ctx, srcBucket, srcKey, srcVersionID, dstBucket, dstKey, options...)
+}
+
+func (r *BucketRouter) DeleteObject(ctx context.Context, bucket, key string) error {
+	return r.storage(ctx, bucket).DeleteObject(ctx, bucket, key)
+}
+
+func (r *BucketRouter) DeleteObjectVersion(ctx context.Context, bucket, key, versionID string) error {
+	return r.storage(ctx, bucket).DeleteObjectVersion(ctx, bucket, key, versionID)
+}
+
+func (r *BucketRouter) ListObjects(ctx context.Context, bucket, prefix string) ([]string, error) {
+	return r.storage(ctx, bucket).ListObjects(ctx, bucket, prefix)
+}
+
+func (r *BucketRouter) ListObjectPages(ctx context.Context, bucket, prefix string, fn func(page []domain.ObjectSummary) error) error {
+	return r.storage(ctx, bucket).ListObjectPages(ctx, bucket, prefix, fn)
+}
+
+func (r *BucketRouter) DeleteObjects(ctx context.Context, bucket string, keys []string) (map[string]error, error) {
+	return r.storage(ctx, bucket).DeleteObjects(ctx, bucket, keys)
+}
+
+func (r *BucketRouter) AbortMultipartUploads(ctx context.Context, bucket, prefix string) (int, error) {
+	return r.storage(ctx, bucket).AbortMultipartUploads(ctx, bucket, prefix)
+}
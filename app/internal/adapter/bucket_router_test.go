@@ -0,0 +1,111 @@
+package adapter
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/observability"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/storage"
+)
+
+func TestBucketRouter(t *testing.T) {
+	workerDir, tenantDir := t.TempDir(), t.TempDir()
+	router := NewBucketRouter(
+		NewStorageAdapter(storage.NewFileClient(workerDir)),
+		map[string]BucketRoute{"acme-frames": {Storage: NewStorageAdapter(storage.NewFileClient(tenantDir)), Tenants: []string{"acme"}}},
+	)
+	ctx := observability.WithTenant(context.Background(), "acme")
+
+	if _, err := router.PutObject(ctx, "worker-frames", "processed/a.zip", strings.NewReader("worker")); err != nil {
+		t.Fatalf("PutObject failed: %v", err)
+	}
+	if _, err := router.PutObject(ctx, "acme-frames", "processed/b.zip", strings.NewReader("tenant")); err != nil {
+		t.Fatalf("PutObject failed: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(workerDir, "worker-frames", "processed", "a.zip")); err != nil {
+		t.Errorf("Expected the worker bucket in the worker storage: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tenantDir, "acme-frames", "processed", "b.zip")); err != nil {
+		t.Errorf("Expected the tenant bucket in the tenant storage: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(workerDir, "acme-frames")); !os.IsNotExist(err) {
+		t.Errorf("Expected nothing of the tenant bucket in the worker storage, got %v", err)
+	}
+
+	keys, err := router.ListObjects(ctx, "acme-frames", "processed/")
+	if err != nil || len(keys) != 1 || keys[0] != "processed/b.zip" {
+		t.Errorf("Expected the tenant bucket listed from the tenant storage, got %v (%v)", keys, err)
+	}
+	body, err := router.GetObject(ctx, "acme-frames", "processed/b.zip")
+	if err != nil {
+		t.Fatalf("GetObject failed: %v", err)
+	}
+	data, _ := io.ReadAll(body)
+	body.Close()
+	if string(data) != "tenant" {
+		t.Errorf("Expected tenant, got %s", data)
+	}
+
+	if err := router.DeleteObject(ctx, "acme-frames", "processed/b.zip"); err != nil {
+		t.Fatalf("DeleteObject failed: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tenantDir, "acme-frames", "processed", "b.zip")); !os.IsNotExist(err) {
+		t.Errorf("Expected the tenant object deleted, got %v", err)
+	}
+}
+
+func TestBucketRouter_CopyObjectUsesDestination(t *testing.T) {
+	var copied []string
+	service := func(name string) *mockStorageService {
+		return &mockStorageService{
+			copyObjectFunc: func(ctx context.Context, srcBucket, srcKey, srcVersionID, dstBucket, dstKey string, options storage.PutOptions) error {
+				copied = append(copied, name+":"+srcBucket+"->"+dstBucket)
+				return nil
+			},
+		}
+	}
+	router := NewBucketRouter(
+		NewStorageAdapter(service("worker")),
+		map[string]BucketRoute{"acme-archive": {Storage: NewStorageAdapter(service("acme")), Tenants: []string{"acme"}}},
+	)
+
+	ctx := observability.WithTenant(context.Background(), "acme")
+	if err := router.CopyObject(ctx, "uploads", "a.mp4", "", "acme-archive", "a.mp4"); err != nil {
+		t.Fatalf("CopyObject failed: %v", err)
+	}
+	if err := router.CopyObject(ctx, "acme-archive", "a.mp4", "", "quarantine", "a.mp4"); err != nil {
+		t.Fatalf("CopyObject failed: %v", err)
+	}
+
+	want := []string{"acme:uploads->acme-archive", "worker:acme-archive->quarantine"}
+	if strings.Join(copied, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected copies %v, got %v", want, copied)
+	}
+}
+
+func TestBucketRouter_OtherTenant(t *testing.T) {
+	workerDir, tenantDir := t.TempDir(), t.TempDir()
+	router := NewBucketRouter(
+		NewStorageAdapter(storage.NewFileClient(workerDir)),
+		map[string]BucketRoute{"acme-frames": {Storage: NewStorageAdapter(storage.NewFileClient(tenantDir)), Tenants: []string{"acme"}}},
+	)
+
+	// Neither another tenant nor a message without one gets the role of acme
+	for _, ctx := range []context.Context{observability.WithTenant(context.Background(), "globex"), context.Background()} {
+		if _, err := router.PutObject(ctx, "acme-frames", "processed/a.zip", strings.NewReader("other")); err != nil {
+			t.Fatalf("PutObject failed: %v", err)
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(tenantDir, "acme-frames")); !os.IsNotExist(err) {
+		t.Errorf("Expected nothing written with the tenant storage, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(workerDir, "acme-frames", "processed", "a.zip")); err != nil {
+		t.Errorf("Expected the other jobs sent to the worker storage: %v", err)
+	}
+}
@@ -0,0 +1,35 @@
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/port"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/encryption"
+)
+
+type EncryptorAdapter struct {
+	encryptor encryption.Encryptor
+}
+
+func NewEncryptorAdapter(encryptor encryption.Encryptor) port.EncryptorPort {
+	return &EncryptorAdapter{
+		encryptor: encryptor,
+	}
+}
+
+// Encrypt returns the JSON-encoded envelope that replaces the plaintext message body
+func (a *EncryptorAdapter) Encrypt(ctx context.Context, plaintext []byte) ([]byte, error) {
+	envelope, err := a.encryptor.Encrypt(ctx, plaintext)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := json.Marshal(envelope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal envelope: %w", err)
+	}
+
+	return body, nil
+}
@@ -0,0 +1,60 @@
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/encryption"
+)
+
+func TestNewEncryptorAdapter(t *testing.T) {
+	adapter := NewEncryptorAdapter(&encryption.MockEncryptor{})
+
+	if adapter == nil {
+		t.Fatal("NewEncryptorAdapter returned nil")
+	}
+}
+
+func TestEncryptorAdapter_Encrypt(t *testing.T) {
+	mock := &encryption.MockEncryptor{
+		EncryptFunc: func(ctx context.Context, plaintext []byte) (*encryption.Envelope, error) {
+			return &encryption.Envelope{
+				Algorithm:  encryption.AlgorithmAES256GCM,
+				KeyID:      "alias/results",
+				Ciphertext: []byte("cipher"),
+			}, nil
+		},
+	}
+
+	adapter := NewEncryptorAdapter(mock)
+
+	body, err := adapter.Encrypt(context.Background(), []byte("plain"))
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v", err)
+	}
+
+	var envelope encryption.Envelope
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		t.Fatalf("Expected JSON envelope, got %s", body)
+	}
+	if envelope.KeyID != "alias/results" || string(envelope.Ciphertext) != "cipher" {
+		t.Errorf("Unexpected envelope %+v", envelope)
+	}
+}
+
+func TestEncryptorAdapter_EncryptError(t *testing.T) {
+	expectedError := errors.New("kms unavailable")
+	mock := &encryption.MockEncryptor{
+		EncryptFunc: func(ctx context.Context, plaintext []byte) (*encryption.Envelope, error) {
+			return nil, expectedError
+		},
+	}
+
+	adapter := NewEncryptorAdapter(mock)
+
+	if _, err := adapter.Encrypt(context.Background(), []byte("plain")); err != expectedError {
+		t.Errorf("Expected error %v, got %v", expectedError, err)
+	}
+}
@@ -0,0 +1,125 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/port"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/ffmpeg"
+)
+
+// barcodeMaxWidth bounds the width of scanned frames; codes readable in a 4K frame stay
+// readable at 1080p, at a fraction of the decoding time
+const barcodeMaxWidth = 1920
+
+type FFmpegBarcodeScanner struct {
+	tempDir    string
+	ffmpegPath string
+}
+
+// NewFFmpegBarcodeScanner samples frames with ffmpeg and decodes them in Go; an empty binary path
+// falls back to PATH
+func NewFFmpegBarcodeScanner(tempDir, ffmpegPath string) port.BarcodeScannerPort {
+	if tempDir == "" {
+		tempDir = "temp"
+	}
+
+	os.MkdirAll(tempDir, 0777)
+	return &FFmpegBarcodeScanner{
+		tempDir:    tempDir,
+		ffmpegPath: ffmpegPath,
+	}
+}
+
+func (s *FFmpegBarcodeScanner) ffmpegBinary() string {
+	if s.ffmpegPath == "" {
+		return "ffmpeg"
+	}
+	return s.ffmpegPath
+}
+
+// ScanBarcodes samples one frame every IntervalSeconds into barcodes_{jobID}, removed on return,
+// and returns the distinct codes in the order they first appear, with the times of every frame
+// showing them
+func (s *FFmpegBarcodeScanner) ScanBarcodes(ctx context.Context, jobID, videoPath string, options domain.BarcodeOptions) ([]domain.BarcodeDetection, error) {
+	jobID = sanitizeJobID(jobID)
+	if jobID == "" {
+		return nil, fmt.Errorf("job id is required")
+	}
+
+	frameDir := filepath.Join(s.tempDir, "barcodes_"+jobID)
+	if err := os.MkdirAll(frameDir, 0777); err != nil {
+		return nil, fmt.Errorf("failed to create frame directory: %w", err)
+	}
+	defer os.RemoveAll(frameDir)
+
+	output, err := ffmpeg.CombinedOutput(ctx, ffmpeg.DefaultKillGrace, s.ffmpegBinary(), barcodeArgs(videoPath, frameDir, options)...)
+	if err != nil {
+		return nil, fmt.Errorf("ffmpeg error: %w, output: %s", err, string(output))
+	}
+
+	frames, err := filepath.Glob(filepath.Join(frameDir, "frame_*.png"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(frames)
+
+	detections := []domain.BarcodeDetection{}
+	index := map[decodedBarcode]int{}
+	for i, frame := range frames {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		barcodes, err := frameBarcodes(frame)
+		if err != nil {
+			return nil, err
+		}
+		// The fps filter emits the frame of time i*interval as the i-th one
+		timestamp := math.Round(float64(i)*options.Interval()*1000) / 1000
+		for _, barcode := range barcodes {
+			position, ok := index[barcode]
+			if !ok {
+				position = len(detections)
+				index[barcode] = position
+				detections = append(detections, domain.BarcodeDetection{Format: barcode.Format, Value: barcode.Value})
+			}
+			detections[position].Timestamps = append(detections[position].Timestamps, timestamp)
+		}
+	}
+	return detections, nil
+}
+
+// barcodeArgs samples one grayscale frame per interval of the first video stream, at most
+// barcodeMaxWidth wide, as frame_00001.png and on
+func barcodeArgs(videoPath, frameDir string, options domain.BarcodeOptions) []string {
+	return []string{
+		"-hide_banner", "-nostats",
+		"-i", videoPath,
+		"-map", "0:v:0",
+		"-vf", fmt.Sprintf("fps=1/%s,scale='min(iw,%d)':-2,format=gray", formatSeconds(options.Interval()), barcodeMaxWidth),
+		"-frames:v", strconv.Itoa(domain.MaxBarcodeFrames),
+		"-y",
+		filepath.Join(frameDir, "frame_%05d.png"),
+	}
+}
+
+func frameBarcodes(path string) ([]decodedBarcode, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode frame %s: %w", filepath.Base(path), err)
+	}
+	return decodeBarcodes(img), nil
+}
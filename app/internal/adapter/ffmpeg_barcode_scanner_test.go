@@ -0,0 +1,73 @@
+package adapter
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+)
+
+func TestBarcodeArgs(t *testing.T) {
+	args := barcodeArgs("video.mp4", "frames", domain.BarcodeOptions{Scan: true, IntervalSeconds: 0.5})
+
+	if filter := argValue(args, "-vf"); filter != "fps=1/0.5,scale='min(iw,1920)':-2,format=gray" {
+		t.Errorf("Unexpected filter: %s", filter)
+	}
+	if argValue(args, "-frames:v") != "3600" || args[len(args)-1] != filepath.Join("frames", "frame_%05d.png") {
+		t.Errorf("Unexpected args: %v", args)
+	}
+}
+
+func TestFFmpegBarcodeScanner_ScanBarcodes(t *testing.T) {
+	// Frames at 0s, 2s and 4s: a QR code, nothing, then the same QR code next to a barcode
+	source := t.TempDir()
+	writePNG(t, filepath.Join(source, "frame_00001.png"), qrImage(t, "ticket-42"))
+	writePNG(t, filepath.Join(source, "frame_00002.png"), flatImage())
+	writePNG(t, filepath.Join(source, "frame_00003.png"), sideBySide(qrImage(t, "ticket-42"), code128Image(t, "SKU-1")))
+	// Copies the frames into the directory of the output pattern, the last argument
+	ffmpeg := writeScript(t, "ffmpeg", "for last; do :; done\ncp "+source+"/*.png \"$(dirname \"$last\")\"\n")
+
+	tempDir := t.TempDir()
+	scanner := NewFFmpegBarcodeScanner(tempDir, ffmpeg)
+	detections, err := scanner.ScanBarcodes(context.Background(), "job-1", "video.mp4", domain.BarcodeOptions{Scan: true, IntervalSeconds: 2})
+	if err != nil {
+		t.Fatalf("ScanBarcodes failed: %v", err)
+	}
+
+	if len(detections) != 2 {
+		t.Fatalf("Expected 2 detections, got %+v", detections)
+	}
+	if detections[0].Format != "qr_code" || detections[0].Value != "ticket-42" || len(detections[0].Timestamps) != 2 || detections[0].Timestamps[1] != 4 {
+		t.Errorf("Expected the QR code at 0s and 4s, got %+v", detections[0])
+	}
+	if detections[1].Format != "code_128" || detections[1].Value != "SKU-1" || len(detections[1].Timestamps) != 1 {
+		t.Errorf("Expected the barcode at 4s, got %+v", detections[1])
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "barcodes_job-1")); !os.IsNotExist(err) {
+		t.Error("Expected frames to be removed")
+	}
+}
+
+func TestFFmpegBarcodeScanner_ScanBarcodes_NothingFound(t *testing.T) {
+	ffmpeg := writeScript(t, "ffmpeg", "exit 0\n")
+
+	detections, err := NewFFmpegBarcodeScanner(t.TempDir(), ffmpeg).ScanBarcodes(context.Background(), "job-1", "video.mp4", domain.BarcodeOptions{Scan: true})
+	if err != nil {
+		t.Fatalf("ScanBarcodes failed: %v", err)
+	}
+	if detections == nil || len(detections) != 0 {
+		t.Errorf("Expected an empty list, got %#v", detections)
+	}
+}
+
+func TestFFmpegBarcodeScanner_ScanBarcodes_FFmpegError(t *testing.T) {
+	ffmpeg := writeScript(t, "ffmpeg", "echo broken >&2\nexit 1\n")
+
+	_, err := NewFFmpegBarcodeScanner(t.TempDir(), ffmpeg).ScanBarcodes(context.Background(), "job-1", "video.mp4", domain.BarcodeOptions{Scan: true})
+	if err == nil || !strings.Contains(err.Error(), "broken") {
+		t.Errorf("Expected ffmpeg output in the error, got %v", err)
+	}
+}
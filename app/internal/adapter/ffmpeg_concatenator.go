@@ -0,0 +1,202 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/port"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/ffmpeg"
+)
+
+type FFmpegConcatenator struct {
+	tempDir     string
+	ffmpegPath  string
+	ffprobePath string
+}
+
+// NewFFmpegConcatenator joins clips into one MP4; empty binary paths fall back to PATH
+func NewFFmpegConcatenator(tempDir, ffmpegPath, ffprobePath string) port.VideoConcatenatorPort {
+	if tempDir == "" {
+		tempDir = "temp"
+	}
+
+	os.MkdirAll(tempDir, 0777)
+	return &FFmpegConcatenator{
+		tempDir:     tempDir,
+		ffmpegPath:  ffmpegPath,
+		ffprobePath: ffprobePath,
+	}
+}
+
+func (c *FFmpegConcatenator) ffmpegBinary() string {
+	if c.ffmpegPath == "" {
+		return "ffmpeg"
+	}
+	return c.ffmpegPath
+}
+
+func (c *FFmpegConcatenator) ffprobeBinary() string {
+	if c.ffprobePath == "" {
+		return "ffprobe"
+	}
+	return c.ffprobePath
+}
+
+// Concat joins the clips in order into concat_{jobID}.mp4, which the caller uploads and
+// removes. The copy mode fails on clips whose streams differ, as ffmpeg would write a file
+// players cannot decode past the first clip
+func (c *FFmpegConcatenator) Concat(ctx context.Context, jobID string, clipPaths []string, options domain.ConcatOptions) (string, error) {
+	jobID = sanitizeJobID(jobID)
+	if jobID == "" {
+		return "", fmt.Errorf("job id is required")
+	}
+	if len(clipPaths) < 2 {
+		return "", fmt.Errorf("at least 2 clips are required, got %d", len(clipPaths))
+	}
+	options = options.WithDefaults()
+
+	probes := make([]*ffmpeg.ProbeResult, len(clipPaths))
+	for i, clipPath := range clipPaths {
+		probe, err := ffmpeg.Probe(ctx, c.ffprobeBinary(), clipPath)
+		if err != nil {
+			return "", fmt.Errorf("failed to probe clip %d: %w", i+1, err)
+		}
+		probes[i] = probe
+	}
+
+	outputPath := filepath.Join(c.tempDir, "concat_"+jobID+".mp4")
+	var args []string
+	if options.Mode == domain.EditModeReencode {
+		args = reencodeConcatArgs(clipPaths, probes, outputPath, options)
+	} else {
+		if err := compatibleClips(probes); err != nil {
+			return "", err
+		}
+		listPath := filepath.Join(c.tempDir, "concat_"+jobID+".txt")
+		if err := writeConcatList(listPath, clipPaths); err != nil {
+			return "", err
+		}
+		defer os.Remove(listPath)
+		args = copyConcatArgs(listPath, outputPath)
+	}
+
+	output, err := ffmpeg.CombinedOutput(ctx, ffmpeg.DefaultKillGrace, c.ffmpegBinary(), args...)
+	if err != nil {
+		os.Remove(outputPath)
+		return "", fmt.Errorf("ffmpeg error: %w, output: %s", err, string(output))
+	}
+
+	if stat, err := os.Stat(outputPath); err != nil || stat.Size() == 0 {
+		os.Remove(outputPath)
+		return "", fmt.Errorf("concatenated video not generated")
+	}
+	return outputPath, nil
+}
+
+// compatibleClips requires every clip to carry the video codec and dimensions of the first
+// one, and the same audio codec or no audio at all, which the concat demuxer copies as is
+func compatibleClips(probes []*ffmpeg.ProbeResult) error {
+	first := clipSignature(probes[0])
+	for i, probe := range probes[1:] {
+		if signature := clipSignature(probe); signature != first {
+			return fmt.Errorf("clip %d (%s) does not match the first clip (%s): use the %s mode", i+2, signature, first, domain.EditModeReencode)
+		}
+	}
+	return nil
+}
+
+// clipSignature describes the streams the copy mode requires to match, e.g. h264 1920x1080, aac
+func clipSignature(probe *ffmpeg.ProbeResult) string {
+	signature := "no video"
+	if video, ok := probe.VideoStream(); ok {
+		signature = fmt.Sprintf("%s %dx%d", video.CodecName, video.Width, video.Height)
+	}
+	if audio := probe.StreamsOfType(ffmpeg.CodecTypeAudio); len(audio) > 0 {
+		return signature + ", " + audio[0].CodecName
+	}
+	return signature + ", no audio"
+}
+
+// writeConcatList writes the input of the concat demuxer, quoting the paths as its parser expects
+func writeConcatList(listPath string, clipPaths []string) error {
+	var list strings.Builder
+	for _, clipPath := range clipPaths {
+		absPath, err := filepath.Abs(clipPath)
+		if err != nil {
+			return fmt.Errorf("failed to resolve clip path: %w", err)
+		}
+		fmt.Fprintf(&list, "file '%s'\n", strings.ReplaceAll(absPath, "'", `'\''`))
+	}
+	if err := os.WriteFile(listPath, []byte(list.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write concat list: %w", err)
+	}
+	return nil
+}
+
+// copyConcatArgs joins the clips with the concat demuxer without re-encoding; faststart moves
+// the index to the front so players can start before the download ends
+func copyConcatArgs(listPath, outputPath string) []string {
+	return []string{
+		"-f", "concat",
+		"-safe", "0",
+		"-i", listPath,
+		"-map", "0:v:0",
+		"-map", "0:a:0?",
+		"-c", "copy",
+		"-movflags", "+faststart",
+		"-y",
+		outputPath,
+	}
+}
+
+// reencodeConcatArgs letterboxes every clip into the output frame at ConcatFPS and joins them
+// with the concat filter. When some clips have audio, the silent ones get silence for their
+// duration so audio and video stay in sync
+func reencodeConcatArgs(clipPaths []string, probes []*ffmpeg.ProbeResult, outputPath string, options domain.ConcatOptions) []string {
+	audio := false
+	for _, probe := range probes {
+		if len(probe.StreamsOfType(ffmpeg.CodecTypeAudio)) > 0 {
+			audio = true
+		}
+	}
+
+	var args []string
+	for _, clipPath := range clipPaths {
+		args = append(args, "-i", clipPath)
+	}
+
+	var filter, inputs strings.Builder
+	for i, probe := range probes {
+		fmt.Fprintf(&filter, "[%d:v:0]scale=%d:%d:force_original_aspect_ratio=decrease,pad=%d:%d:(ow-iw)/2:(oh-ih)/2,setsar=1,fps=%d[v%d];",
+			i, options.Width, options.Height, options.Width, options.Height, domain.ConcatFPS, i)
+		fmt.Fprintf(&inputs, "[v%d]", i)
+		if !audio {
+			continue
+		}
+		if len(probe.StreamsOfType(ffmpeg.CodecTypeAudio)) > 0 {
+			fmt.Fprintf(&filter, "[%d:a:0]aresample=48000,aformat=channel_layouts=stereo[a%d];", i, i)
+		} else {
+			fmt.Fprintf(&filter, "anullsrc=r=48000:cl=stereo,atrim=duration=%s[a%d];", formatSeconds(probe.Duration()), i)
+		}
+		fmt.Fprintf(&inputs, "[a%d]", i)
+	}
+
+	audioStreams := 0
+	if audio {
+		audioStreams = 1
+	}
+	fmt.Fprintf(&filter, "%sconcat=n=%d:v=1:a=%d[v]", inputs.String(), len(clipPaths), audioStreams)
+	if audio {
+		filter.WriteString("[a]")
+	}
+
+	args = append(args, "-filter_complex", filter.String(), "-map", "[v]", "-c:v", "libx264")
+	if audio {
+		args = append(args, "-map", "[a]", "-c:a", "aac")
+	}
+	return append(args, "-movflags", "+faststart", "-y", outputPath)
+}
@@ -0,0 +1,134 @@
+package adapter
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/ffmpeg"
+)
+
+func probeOf(t *testing.T, output string) *ffmpeg.ProbeResult {
+	t.Helper()
+	probe, err := ffmpeg.ParseProbe([]byte(output))
+	if err != nil {
+		t.Fatalf("Failed to parse probe: %v", err)
+	}
+	return probe
+}
+
+func TestCompatibleClips(t *testing.T) {
+	h264 := `{"streams":[{"codec_type":"video","codec_name":"h264","width":1920,"height":1080},{"codec_type":"audio","codec_name":"aac"}]}`
+	hevc := `{"streams":[{"codec_type":"video","codec_name":"hevc","width":1920,"height":1080},{"codec_type":"audio","codec_name":"aac"}]}`
+	silent := `{"streams":[{"codec_type":"video","codec_name":"h264","width":1920,"height":1080}]}`
+
+	if err := compatibleClips([]*ffmpeg.ProbeResult{probeOf(t, h264), probeOf(t, h264)}); err != nil {
+		t.Errorf("Expected matching clips to be compatible, got %v", err)
+	}
+
+	err := compatibleClips([]*ffmpeg.ProbeResult{probeOf(t, h264), probeOf(t, hevc)})
+	if err == nil || !strings.Contains(err.Error(), "clip 2 (hevc 1920x1080, aac)") {
+		t.Errorf("Expected codec mismatch on clip 2, got %v", err)
+	}
+
+	if err := compatibleClips([]*ffmpeg.ProbeResult{probeOf(t, h264), probeOf(t, silent)}); err == nil {
+		t.Error("Expected a clip without audio to be incompatible")
+	}
+}
+
+func TestWriteConcatList(t *testing.T) {
+	listPath := filepath.Join(t.TempDir(), "list.txt")
+	if err := writeConcatList(listPath, []string{"/tmp/a.mp4", "/tmp/it's.mp4"}); err != nil {
+		t.Fatalf("writeConcatList failed: %v", err)
+	}
+
+	content, _ := os.ReadFile(listPath)
+	expected := "file '/tmp/a.mp4'\nfile '/tmp/it'\\''s.mp4'\n"
+	if string(content) != expected {
+		t.Errorf("Expected %q, got %q", expected, string(content))
+	}
+}
+
+func TestReencodeConcatArgs(t *testing.T) {
+	withAudio := probeOf(t, `{"streams":[{"codec_type":"video"},{"codec_type":"audio"}],"format":{"duration":"3.0"}}`)
+	silent := probeOf(t, `{"streams":[{"codec_type":"video"}],"format":{"duration":"2.5"}}`)
+	options := domain.ConcatOptions{Mode: domain.EditModeReencode, Width: 640, Height: 360}
+
+	args := reencodeConcatArgs([]string{"a.mp4", "b.mp4"}, []*ffmpeg.ProbeResult{withAudio, silent}, "out.mp4", options)
+	filter := argValue(args, "-filter_complex")
+
+	if !strings.Contains(filter, "[0:v:0]scale=640:360:force_original_aspect_ratio=decrease,pad=640:360:(ow-iw)/2:(oh-ih)/2,setsar=1,fps=30[v0]") {
+		t.Errorf("Expected letterboxed video, got %s", filter)
+	}
+	if !strings.Contains(filter, "anullsrc=r=48000:cl=stereo,atrim=duration=2.5[a1]") {
+		t.Errorf("Expected silence for the clip without audio, got %s", filter)
+	}
+	if !strings.HasSuffix(filter, "[v0][a0][v1][a1]concat=n=2:v=1:a=1[v][a]") {
+		t.Errorf("Expected interleaved concat inputs, got %s", filter)
+	}
+	if argValue(args, "-c:a") != "aac" || args[len(args)-1] != "out.mp4" {
+		t.Errorf("Unexpected args: %v", args)
+	}
+
+	args = reencodeConcatArgs([]string{"a.mp4", "b.mp4"}, []*ffmpeg.ProbeResult{silent, silent}, "out.mp4", options)
+	if filter := argValue(args, "-filter_complex"); !strings.HasSuffix(filter, "[v0][v1]concat=n=2:v=1:a=0[v]") {
+		t.Errorf("Expected video-only concat, got %s", filter)
+	}
+	if strings.Contains(strings.Join(args, " "), "[a]") {
+		t.Error("Expected no audio mapping for silent clips")
+	}
+}
+
+func TestFFmpegConcatenator_Concat(t *testing.T) {
+	tempDir := t.TempDir()
+	ffprobe := writeScript(t, "ffprobe", "echo '{\"streams\":[{\"codec_type\":\"video\",\"codec_name\":\"h264\",\"width\":640,\"height\":360}]}'\n")
+	// Copies the concat list given after -i to the output, the last argument
+	ffmpeg := writeScript(t, "ffmpeg", "for last; do :; done\nwhile [ \"$1\" != \"-i\" ]; do shift; done\ncp \"$2\" \"$last\"\n")
+
+	concatenator := NewFFmpegConcatenator(tempDir, ffmpeg, ffprobe)
+	videoPath, err := concatenator.Concat(context.Background(), "job-1", []string{"a.mp4", "b.mp4"}, domain.ConcatOptions{})
+	if err != nil {
+		t.Fatalf("Concat failed: %v", err)
+	}
+
+	if videoPath != filepath.Join(tempDir, "concat_job-1.mp4") {
+		t.Errorf("Expected video keyed by job id, got %s", videoPath)
+	}
+	content, _ := os.ReadFile(videoPath)
+	if !strings.Contains(string(content), "a.mp4'\nfile '") || !strings.HasSuffix(string(content), "b.mp4'\n") {
+		t.Errorf("Expected both clips in order in the concat list, got %q", string(content))
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "concat_job-1.txt")); !os.IsNotExist(err) {
+		t.Error("Expected concat list to be removed")
+	}
+}
+
+func TestFFmpegConcatenator_Concat_IncompatibleClips(t *testing.T) {
+	// The first call reports h264 and the next ones hevc
+	counter := filepath.Join(t.TempDir(), "calls")
+	ffprobe := writeScript(t, "ffprobe", "if [ -f "+counter+" ]; then codec=hevc; else codec=h264; touch "+counter+"; fi\necho \"{\\\"streams\\\":[{\\\"codec_type\\\":\\\"video\\\",\\\"codec_name\\\":\\\"$codec\\\"}]}\"\n")
+	ffmpeg := writeScript(t, "ffmpeg", "exit 1\n")
+
+	concatenator := NewFFmpegConcatenator(t.TempDir(), ffmpeg, ffprobe)
+	_, err := concatenator.Concat(context.Background(), "job-1", []string{"a.mp4", "b.mp4"}, domain.ConcatOptions{Mode: domain.EditModeCopy})
+	if err == nil || !strings.Contains(err.Error(), "use the reencode mode") {
+		t.Errorf("Expected incompatible clips error, got %v", err)
+	}
+}
+
+func TestFFmpegConcatenator_Concat_FFmpegError(t *testing.T) {
+	tempDir := t.TempDir()
+	ffprobe := writeScript(t, "ffprobe", "echo '{\"streams\":[]}'\n")
+	ffmpeg := writeScript(t, "ffmpeg", "for last; do :; done\necho partial > \"$last\"\nexit 1\n")
+
+	concatenator := NewFFmpegConcatenator(tempDir, ffmpeg, ffprobe)
+	if _, err := concatenator.Concat(context.Background(), "job-1", []string{"a.mp4", "b.mp4"}, domain.ConcatOptions{Mode: domain.EditModeReencode}); err == nil {
+		t.Fatal("Expected error when ffmpeg fails")
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "concat_job-1.mp4")); !os.IsNotExist(err) {
+		t.Error("Expected partial output to be removed")
+	}
+}
@@ -0,0 +1,147 @@
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/port"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/ffmpeg"
+)
+
+// fingerprintVersion changes when the hashes of the same video would change, so consumers only
+// compare fingerprints of one version
+const fingerprintVersion = 1
+
+// fingerprintDocument is the JSON artifact of the fingerprint output
+type fingerprintDocument struct {
+	Version         int                `json:"version"`
+	IntervalSeconds float64            `json:"interval_seconds"`
+	DurationSeconds float64            `json:"duration_seconds"`
+	Frames          []fingerprintFrame `json:"frames"`
+}
+
+type fingerprintFrame struct {
+	Time  float64 `json:"time"`
+	PHash string  `json:"phash"`
+	DHash string  `json:"dhash"`
+}
+
+type FFmpegFingerprinter struct {
+	tempDir     string
+	ffmpegPath  string
+	ffprobePath string
+}
+
+// NewFFmpegFingerprinter hashes sampled frames with ffmpeg; empty binary paths fall back to PATH
+func NewFFmpegFingerprinter(tempDir, ffmpegPath, ffprobePath string) port.FingerprinterPort {
+	if tempDir == "" {
+		tempDir = "temp"
+	}
+
+	os.MkdirAll(tempDir, 0777)
+	return &FFmpegFingerprinter{
+		tempDir:     tempDir,
+		ffmpegPath:  ffmpegPath,
+		ffprobePath: ffprobePath,
+	}
+}
+
+func (f *FFmpegFingerprinter) ffmpegBinary() string {
+	if f.ffmpegPath == "" {
+		return "ffmpeg"
+	}
+	return f.ffmpegPath
+}
+
+func (f *FFmpegFingerprinter) ffprobeBinary() string {
+	if f.ffprobePath == "" {
+		return "ffprobe"
+	}
+	return f.ffprobePath
+}
+
+// Fingerprint writes fingerprint_{jobID}.json, which the caller uploads and removes, with the
+// pHash and dHash of one frame every IntervalSeconds. ffmpeg decodes the frames straight to
+// 32x32 grayscale, so no image is written
+func (f *FFmpegFingerprinter) Fingerprint(ctx context.Context, jobID, videoPath string, options domain.FingerprintOptions) (string, error) {
+	jobID = sanitizeJobID(jobID)
+	if jobID == "" {
+		return "", fmt.Errorf("job id is required")
+	}
+	options = options.WithDefaults()
+
+	probe, err := ffmpeg.Probe(ctx, f.ffprobeBinary(), videoPath)
+	if err != nil {
+		return "", err
+	}
+	if _, ok := probe.VideoStream(); !ok {
+		return "", fmt.Errorf("video has no video stream")
+	}
+
+	rawPath := filepath.Join(f.tempDir, "fingerprint_"+jobID+".gray")
+	defer os.Remove(rawPath)
+	output, err := ffmpeg.CombinedOutput(ctx, ffmpeg.DefaultKillGrace, f.ffmpegBinary(), fingerprintArgs(videoPath, rawPath, options)...)
+	if err != nil {
+		return "", fmt.Errorf("ffmpeg error: %w, output: %s", err, string(output))
+	}
+	pixels, err := os.ReadFile(rawPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read sampled frames: %w", err)
+	}
+
+	frames := fingerprintFrames(pixels, options.IntervalSeconds)
+	if len(frames) == 0 {
+		return "", fmt.Errorf("no frames sampled from the video")
+	}
+
+	data, err := json.Marshal(fingerprintDocument{
+		Version:         fingerprintVersion,
+		IntervalSeconds: options.IntervalSeconds,
+		DurationSeconds: probe.Duration(),
+		Frames:          frames,
+	})
+	if err != nil {
+		return "", err
+	}
+	fingerprintPath := filepath.Join(f.tempDir, "fingerprint_"+jobID+".json")
+	if err := os.WriteFile(fingerprintPath, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write fingerprint: %w", err)
+	}
+	return fingerprintPath, nil
+}
+
+// fingerprintArgs samples one frame per interval of the first video stream into raw 8-bit
+// grayscale frames of hashSize x hashSize
+func fingerprintArgs(videoPath, rawPath string, options domain.FingerprintOptions) []string {
+	return []string{
+		"-hide_banner", "-nostats",
+		"-i", videoPath,
+		"-map", "0:v:0",
+		"-vf", fmt.Sprintf("fps=1/%s,scale=%d:%d:flags=area,format=gray", formatSeconds(options.IntervalSeconds), hashSize, hashSize),
+		"-frames:v", strconv.Itoa(domain.MaxFingerprintFrames),
+		"-f", "rawvideo",
+		"-y",
+		rawPath,
+	}
+}
+
+// fingerprintFrames hashes every complete frame of the raw output; the fps filter emits the
+// frame of time i*interval as the i-th one, rounded here to the millisecond
+func fingerprintFrames(pixels []byte, interval float64) []fingerprintFrame {
+	frames := []fingerprintFrame{}
+	for i := 0; (i+1)*hashSize*hashSize <= len(pixels); i++ {
+		frame := pixels[i*hashSize*hashSize : (i+1)*hashSize*hashSize]
+		frames = append(frames, fingerprintFrame{
+			Time:  math.Round(float64(i)*interval*1000) / 1000,
+			PHash: formatHash(pHash(frame)),
+			DHash: formatHash(dHash(frame)),
+		})
+	}
+	return frames
+}
@@ -0,0 +1,86 @@
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+)
+
+func TestFingerprintArgs(t *testing.T) {
+	args := fingerprintArgs("video.mp4", "out.gray", domain.FingerprintOptions{IntervalSeconds: 0.5})
+
+	if filter := argValue(args, "-vf"); filter != "fps=1/0.5,scale=32:32:flags=area,format=gray" {
+		t.Errorf("Unexpected filter: %s", filter)
+	}
+	if argValue(args, "-frames:v") != "7200" || argValue(args, "-f") != "rawvideo" || args[len(args)-1] != "out.gray" {
+		t.Errorf("Unexpected args: %v", args)
+	}
+}
+
+func TestFingerprintFrames(t *testing.T) {
+	// Two complete frames and a partial one, which is ignored
+	pixels := make([]byte, 2*hashSize*hashSize+10)
+	frames := fingerprintFrames(pixels, 0.1)
+
+	if len(frames) != 2 {
+		t.Fatalf("Expected 2 frames, got %d", len(frames))
+	}
+	if frames[1].Time != 0.1 || frames[1].PHash != "0000000000000000" || len(frames[1].DHash) != 16 {
+		t.Errorf("Unexpected frame %+v", frames[1])
+	}
+}
+
+func TestFFmpegFingerprinter_Fingerprint(t *testing.T) {
+	tempDir := t.TempDir()
+	ffprobe := writeScript(t, "ffprobe", "echo '{\"streams\":[{\"codec_type\":\"video\"}],\"format\":{\"duration\":\"3.0\"}}'\n")
+	// Writes three black 32x32 frames to the output, the last argument
+	ffmpeg := writeScript(t, "ffmpeg", "for last; do :; done\nhead -c 3072 /dev/zero > \"$last\"\n")
+
+	fingerprinter := NewFFmpegFingerprinter(tempDir, ffmpeg, ffprobe)
+	fingerprintPath, err := fingerprinter.Fingerprint(context.Background(), "job-1", "video.mp4", domain.FingerprintOptions{})
+	if err != nil {
+		t.Fatalf("Fingerprint failed: %v", err)
+	}
+
+	if fingerprintPath != filepath.Join(tempDir, "fingerprint_job-1.json") {
+		t.Errorf("Expected fingerprint keyed by job id, got %s", fingerprintPath)
+	}
+	data, _ := os.ReadFile(fingerprintPath)
+	var document fingerprintDocument
+	if err := json.Unmarshal(data, &document); err != nil {
+		t.Fatalf("Failed to decode fingerprint: %v", err)
+	}
+	if document.Version != 1 || document.IntervalSeconds != 1 || document.DurationSeconds != 3 || len(document.Frames) != 3 {
+		t.Errorf("Unexpected fingerprint %s", data)
+	}
+	if document.Frames[2].Time != 2 {
+		t.Errorf("Expected the third frame at 2s, got %v", document.Frames[2].Time)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "fingerprint_job-1.gray")); !os.IsNotExist(err) {
+		t.Error("Expected raw frames to be removed")
+	}
+}
+
+func TestFFmpegFingerprinter_Fingerprint_NoFrames(t *testing.T) {
+	ffprobe := writeScript(t, "ffprobe", "echo '{\"streams\":[{\"codec_type\":\"video\"}]}'\n")
+	ffmpeg := writeScript(t, "ffmpeg", "for last; do :; done\n: > \"$last\"\n")
+
+	_, err := NewFFmpegFingerprinter(t.TempDir(), ffmpeg, ffprobe).Fingerprint(context.Background(), "job-1", "video.mp4", domain.FingerprintOptions{})
+	if err == nil || !strings.Contains(err.Error(), "no frames") {
+		t.Errorf("Expected no frames error, got %v", err)
+	}
+}
+
+func TestFFmpegFingerprinter_Fingerprint_NoVideo(t *testing.T) {
+	ffprobe := writeScript(t, "ffprobe", "echo '{\"streams\":[{\"codec_type\":\"audio\"}]}'\n")
+	ffmpeg := writeScript(t, "ffmpeg", "exit 1\n")
+
+	if _, err := NewFFmpegFingerprinter(t.TempDir(), ffmpeg, ffprobe).Fingerprint(context.Background(), "job-1", "video.mp4", domain.FingerprintOptions{}); err == nil {
+		t.Error("Expected error for a video without video stream")
+	}
+}
@@ -0,0 +1,188 @@
+package adapter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/port"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/ffmpeg"
+)
+
+// normalizedAudioArgs encode the normalized audio back at 48kHz; loudnorm upsamples to 192kHz
+var normalizedAudioArgs = []string{"-c:a", "aac", "-b:a", "192k", "-ar", "48000"}
+
+type FFmpegNormalizer struct {
+	tempDir     string
+	ffmpegPath  string
+	ffprobePath string
+}
+
+// NewFFmpegNormalizer normalizes the audio of videos with loudnorm; empty binary paths fall back to PATH
+func NewFFmpegNormalizer(tempDir, ffmpegPath, ffprobePath string) port.AudioNormalizerPort {
+	if tempDir == "" {
+		tempDir = "temp"
+	}
+
+	os.MkdirAll(tempDir, 0777)
+	return &FFmpegNormalizer{
+		tempDir:     tempDir,
+		ffmpegPath:  ffmpegPath,
+		ffprobePath: ffprobePath,
+	}
+}
+
+func (n *FFmpegNormalizer) ffmpegBinary() string {
+	if n.ffmpegPath == "" {
+		return "ffmpeg"
+	}
+	return n.ffmpegPath
+}
+
+func (n *FFmpegNormalizer) ffprobeBinary() string {
+	if n.ffprobePath == "" {
+		return "ffprobe"
+	}
+	return n.ffprobePath
+}
+
+// Normalize runs loudnorm in two passes: the first measures the audio and the second applies a
+// linear gain from those measures, which keeps the dynamics where a single pass would compress
+// them. The video is copied into loudnorm_{jobID}.mp4, which the caller uploads and removes
+func (n *FFmpegNormalizer) Normalize(ctx context.Context, jobID, videoPath string, options domain.LoudnessOptions) (string, domain.LoudnessStats, error) {
+	jobID = sanitizeJobID(jobID)
+	if jobID == "" {
+		return "", domain.LoudnessStats{}, fmt.Errorf("job id is required")
+	}
+	options = options.WithDefaults()
+
+	probe, err := ffmpeg.Probe(ctx, n.ffprobeBinary(), videoPath)
+	if err != nil {
+		return "", domain.LoudnessStats{}, err
+	}
+	if len(probe.StreamsOfType(ffmpeg.CodecTypeAudio)) == 0 {
+		return "", domain.LoudnessStats{}, fmt.Errorf("video has no audio to normalize")
+	}
+
+	output, err := ffmpeg.CombinedOutput(ctx, ffmpeg.DefaultKillGrace, n.ffmpegBinary(), measureLoudnessArgs(videoPath, options)...)
+	if err != nil {
+		return "", domain.LoudnessStats{}, fmt.Errorf("ffmpeg error: %w, output: %s", err, string(output))
+	}
+	measured, err := parseLoudnorm(output)
+	if err != nil {
+		return "", domain.LoudnessStats{}, fmt.Errorf("failed to measure loudness: %w", err)
+	}
+	// A silent track measures -inf, which loudnorm cannot take as the input of the second pass
+	if math.IsInf(measured.InputIntegrated, 0) || math.IsInf(measured.InputThreshold, 0) {
+		return "", domain.LoudnessStats{}, fmt.Errorf("audio is silent, there is no loudness to normalize")
+	}
+
+	outputPath := filepath.Join(n.tempDir, "loudnorm_"+jobID+".mp4")
+	output, err = ffmpeg.CombinedOutput(ctx, ffmpeg.DefaultKillGrace, n.ffmpegBinary(), normalizeLoudnessArgs(videoPath, outputPath, options, measured)...)
+	if err != nil {
+		os.Remove(outputPath)
+		return "", domain.LoudnessStats{}, fmt.Errorf("ffmpeg error: %w, output: %s", err, string(output))
+	}
+	normalized, err := parseLoudnorm(output)
+	if err != nil {
+		os.Remove(outputPath)
+		return "", domain.LoudnessStats{}, fmt.Errorf("failed to read normalized loudness: %w", err)
+	}
+
+	stats := measured
+	stats.OutputIntegrated = normalized.OutputIntegrated
+	stats.OutputTruePeak = normalized.OutputTruePeak
+	stats.OutputRange = normalized.OutputRange
+	return outputPath, stats, nil
+}
+
+// loudnormFilter is the loudnorm filter with the targets, followed by the extra parameters
+func loudnormFilter(options domain.LoudnessOptions, extra string) string {
+	return fmt.Sprintf("loudnorm=I=%s:TP=%s:LRA=%s:%sprint_format=json",
+		formatSeconds(options.Integrated), formatSeconds(options.TruePeak), formatSeconds(options.Range), extra)
+}
+
+// measureLoudnessArgs decode only the first audio track and discard the output
+func measureLoudnessArgs(videoPath string, options domain.LoudnessOptions) []string {
+	return []string{
+		"-hide_banner", "-nostats",
+		"-i", videoPath,
+		"-map", "0:a:0",
+		"-af", loudnormFilter(options, ""),
+		"-f", "null",
+		"-",
+	}
+}
+
+// normalizeLoudnessArgs copy the video and encode the first audio track with the measures of
+// the first pass
+func normalizeLoudnessArgs(videoPath, outputPath string, options domain.LoudnessOptions, measured domain.LoudnessStats) []string {
+	extra := fmt.Sprintf("measured_I=%s:measured_TP=%s:measured_LRA=%s:measured_thresh=%s:offset=%s:linear=true:",
+		formatSeconds(measured.InputIntegrated), formatSeconds(measured.InputTruePeak), formatSeconds(measured.InputRange),
+		formatSeconds(measured.InputThreshold), formatSeconds(measured.TargetOffset))
+
+	args := []string{
+		"-hide_banner", "-nostats",
+		"-i", videoPath,
+		"-map", "0:v:0?",
+		"-map", "0:a:0",
+		"-c:v", "copy",
+		"-af", loudnormFilter(options, extra),
+	}
+	args = append(args, normalizedAudioArgs...)
+	return append(args, "-movflags", "+faststart", "-y", outputPath)
+}
+
+// loudnormReport is the JSON loudnorm prints at the end of its output, with every value as a string
+type loudnormReport struct {
+	InputI       string `json:"input_i"`
+	InputTP      string `json:"input_tp"`
+	InputLRA     string `json:"input_lra"`
+	InputThresh  string `json:"input_thresh"`
+	OutputI      string `json:"output_i"`
+	OutputTP     string `json:"output_tp"`
+	OutputLRA    string `json:"output_lra"`
+	TargetOffset string `json:"target_offset"`
+}
+
+// parseLoudnorm reads the last JSON object of the ffmpeg output, the loudnorm report
+func parseLoudnorm(output []byte) (domain.LoudnessStats, error) {
+	start, end := bytes.LastIndexByte(output, '{'), bytes.LastIndexByte(output, '}')
+	if start < 0 || end < start {
+		return domain.LoudnessStats{}, fmt.Errorf("loudnorm report not found")
+	}
+
+	var report loudnormReport
+	if err := json.Unmarshal(output[start:end+1], &report); err != nil {
+		return domain.LoudnessStats{}, fmt.Errorf("invalid loudnorm report: %w", err)
+	}
+
+	var stats domain.LoudnessStats
+	fields := []struct {
+		value  string
+		target *float64
+	}{
+		{report.InputI, &stats.InputIntegrated},
+		{report.InputTP, &stats.InputTruePeak},
+		{report.InputLRA, &stats.InputRange},
+		{report.InputThresh, &stats.InputThreshold},
+		{report.OutputI, &stats.OutputIntegrated},
+		{report.OutputTP, &stats.OutputTruePeak},
+		{report.OutputLRA, &stats.OutputRange},
+		{report.TargetOffset, &stats.TargetOffset},
+	}
+	for _, field := range fields {
+		value, err := strconv.ParseFloat(field.value, 64)
+		if err != nil {
+			return domain.LoudnessStats{}, fmt.Errorf("invalid loudnorm value %q: %w", field.value, err)
+		}
+		*field.target = value
+	}
+	return stats, nil
+}
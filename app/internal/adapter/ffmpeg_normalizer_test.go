@@ -0,0 +1,110 @@
+package adapter
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+)
+
+const loudnormOutput = `[Parsed_loudnorm_0 @ 0x5581] 
+{
+	"input_i" : "-27.61",
+	"input_tp" : "-4.47",
+	"input_lra" : "18.06",
+	"input_thresh" : "-39.20",
+	"output_i" : "-23.02",
+	"output_tp" : "-1.00",
+	"output_lra" : "7.00",
+	"output_thresh" : "-34.26",
+	"normalization_type" : "dynamic",
+	"target_offset" : "0.02"
+}
+`
+
+func TestParseLoudnorm(t *testing.T) {
+	stats, err := parseLoudnorm([]byte("Input #0, mov,mp4 {not json}\n" + loudnormOutput))
+	if err != nil {
+		t.Fatalf("parseLoudnorm failed: %v", err)
+	}
+
+	expected := domain.LoudnessStats{
+		InputIntegrated:  -27.61,
+		InputTruePeak:    -4.47,
+		InputRange:       18.06,
+		InputThreshold:   -39.2,
+		OutputIntegrated: -23.02,
+		OutputTruePeak:   -1,
+		OutputRange:      7,
+		TargetOffset:     0.02,
+	}
+	if stats != expected {
+		t.Errorf("Expected %+v, got %+v", expected, stats)
+	}
+
+	if _, err := parseLoudnorm([]byte("no report")); err == nil {
+		t.Error("Expected error without a report")
+	}
+	if _, err := parseLoudnorm([]byte(`{"input_i" : "n/a"}`)); err == nil {
+		t.Error("Expected error for a value that is not a number")
+	}
+}
+
+func TestNormalizeLoudnessArgs(t *testing.T) {
+	measured := domain.LoudnessStats{InputIntegrated: -27.61, InputTruePeak: -4.47, InputRange: 18.06, InputThreshold: -39.2, TargetOffset: 0.02}
+	args := normalizeLoudnessArgs("video.mp4", "out.mp4", domain.DefaultLoudnessOptions, measured)
+
+	expected := "loudnorm=I=-23:TP=-1:LRA=7:measured_I=-27.61:measured_TP=-4.47:measured_LRA=18.06:measured_thresh=-39.2:offset=0.02:linear=true:print_format=json"
+	if filter := argValue(args, "-af"); filter != expected {
+		t.Errorf("Expected %s, got %s", expected, filter)
+	}
+	if argValue(args, "-c:v") != "copy" || argValue(args, "-ar") != "48000" {
+		t.Errorf("Expected the video copied and the audio back at 48kHz, got %v", args)
+	}
+
+	args = measureLoudnessArgs("video.mp4", domain.LoudnessOptions{Integrated: -16, TruePeak: -1.5, Range: 11})
+	if argValue(args, "-af") != "loudnorm=I=-16:TP=-1.5:LRA=11:print_format=json" || args[len(args)-1] != "-" {
+		t.Errorf("Unexpected measure args: %v", args)
+	}
+}
+
+func TestFFmpegNormalizer_Normalize(t *testing.T) {
+	tempDir := t.TempDir()
+	ffprobe := writeScript(t, "ffprobe", "echo '{\"streams\":[{\"codec_type\":\"video\"},{\"codec_type\":\"audio\"}]}'\n")
+	// Prints the report on both passes and writes the output of the second one
+	report := filepath.Join(t.TempDir(), "report")
+	if err := os.WriteFile(report, []byte(loudnormOutput), 0644); err != nil {
+		t.Fatalf("Failed to write report: %v", err)
+	}
+	ffmpeg := writeScript(t, "ffmpeg", "for last; do :; done\ncat "+report+" >&2\nif [ \"$last\" != \"-\" ]; then echo normalized > \"$last\"; fi\n")
+
+	normalizer := NewFFmpegNormalizer(tempDir, ffmpeg, ffprobe)
+	videoPath, stats, err := normalizer.Normalize(context.Background(), "job-1", "video.mp4", domain.LoudnessOptions{})
+	if err != nil {
+		t.Fatalf("Normalize failed: %v", err)
+	}
+	if videoPath != filepath.Join(tempDir, "loudnorm_job-1.mp4") {
+		t.Errorf("Expected video keyed by job id, got %s", videoPath)
+	}
+	if stats.InputIntegrated != -27.61 || stats.OutputIntegrated != -23.02 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+}
+
+func TestFFmpegNormalizer_Normalize_Errors(t *testing.T) {
+	silentVideo := writeScript(t, "ffprobe", "echo '{\"streams\":[{\"codec_type\":\"video\"}]}'\n")
+	normalizer := NewFFmpegNormalizer(t.TempDir(), writeScript(t, "ffmpeg", "exit 1\n"), silentVideo)
+	if _, _, err := normalizer.Normalize(context.Background(), "job-1", "video.mp4", domain.LoudnessOptions{}); err == nil || !strings.Contains(err.Error(), "no audio") {
+		t.Errorf("Expected error for a video without audio, got %v", err)
+	}
+
+	withAudio := writeScript(t, "ffprobe", "echo '{\"streams\":[{\"codec_type\":\"audio\"}]}'\n")
+	silence := writeScript(t, "ffmpeg", "echo '{\"input_i\":\"-inf\",\"input_tp\":\"-inf\",\"input_lra\":\"0.00\",\"input_thresh\":\"-inf\",\"output_i\":\"-inf\",\"output_tp\":\"-inf\",\"output_lra\":\"0.00\",\"target_offset\":\"inf\"}' >&2\n")
+	normalizer = NewFFmpegNormalizer(t.TempDir(), silence, withAudio)
+	if _, _, err := normalizer.Normalize(context.Background(), "job-1", "video.mp4", domain.LoudnessOptions{}); err == nil || !strings.Contains(err.Error(), "silent") {
+		t.Errorf("Expected error for a silent track, got %v", err)
+	}
+}
@@ -0,0 +1,178 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/port"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/ffmpeg"
+)
+
+type FFmpegPackager struct {
+	tempDir     string
+	ffmpegPath  string
+	ffprobePath string
+}
+
+// NewFFmpegPackager packages videos as HLS or DASH; empty binary paths fall back to PATH
+func NewFFmpegPackager(tempDir, ffmpegPath, ffprobePath string) port.PackagerPort {
+	if tempDir == "" {
+		tempDir = "temp"
+	}
+
+	os.MkdirAll(tempDir, 0777)
+	return &FFmpegPackager{
+		tempDir:     tempDir,
+		ffmpegPath:  ffmpegPath,
+		ffprobePath: ffprobePath,
+	}
+}
+
+func (p *FFmpegPackager) ffmpegBinary() string {
+	if p.ffmpegPath == "" {
+		return "ffmpeg"
+	}
+	return p.ffmpegPath
+}
+
+func (p *FFmpegPackager) ffprobeBinary() string {
+	if p.ffprobePath == "" {
+		return "ffprobe"
+	}
+	return p.ffprobePath
+}
+
+// Package encodes every rendition and writes the segments plus the master playlist
+// (or DASH manifest) into a directory the caller uploads and removes. Extracted
+// subtitles go to its subtitles/ folder
+func (p *FFmpegPackager) Package(ctx context.Context, jobID, videoPath, outputType string, options domain.PackagingOptions, frameOptions domain.FrameOptions) (string, error) {
+	if !domain.IsPackagingOutput(outputType) {
+		return "", fmt.Errorf("unsupported packaging output: %s", outputType)
+	}
+	jobID = sanitizeJobID(jobID)
+	if jobID == "" {
+		return "", fmt.Errorf("job id is required")
+	}
+	options = options.WithDefaults()
+
+	// Mapping a missing audio stream makes the HLS/DASH muxers fail, so probe first
+	probe, err := ffmpeg.Probe(ctx, p.ffprobeBinary(), videoPath)
+	if err != nil {
+		return "", err
+	}
+	audio := len(probe.StreamsOfType(ffmpeg.CodecTypeAudio)) > 0
+	subtitleStreams := probe.StreamsOfType(ffmpeg.CodecTypeSubtitle)
+
+	source, err := videoSource(videoPath, probe, frameOptions)
+	if err != nil {
+		return "", err
+	}
+
+	outputDir := filepath.Join(p.tempDir, outputType+"_"+jobID)
+	if err := os.MkdirAll(outputDir, 0777); err != nil {
+		return "", fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	args := packagingArgs(videoPath, outputDir, outputType, options, audio, source)
+	output, err := ffmpeg.CombinedOutput(ctx, ffmpeg.DefaultKillGrace, p.ffmpegBinary(), args...)
+	if err != nil {
+		os.RemoveAll(outputDir)
+		return "", fmt.Errorf("ffmpeg error: %w, output: %s", err, string(output))
+	}
+
+	if _, err := os.Stat(filepath.Join(outputDir, domain.PackagingManifest(outputType))); err != nil {
+		os.RemoveAll(outputDir)
+		return "", fmt.Errorf("manifest not generated: %w", err)
+	}
+
+	if frameOptions.Subtitles.Extract {
+		subtitleDir := filepath.Join(outputDir, "subtitles")
+		if err := os.MkdirAll(subtitleDir, 0777); err != nil {
+			os.RemoveAll(outputDir)
+			return "", fmt.Errorf("failed to create subtitles directory: %w", err)
+		}
+		if _, err := extractSubtitles(ctx, p.ffmpegBinary(), videoPath, subtitleStreams, frameOptions.Subtitles.ExtractFormat(), subtitleDir); err != nil {
+			os.RemoveAll(outputDir)
+			return "", err
+		}
+	}
+
+	return outputDir, nil
+}
+
+// packagingArgs builds one scaled H.264 stream per rendition (with a copy of the AAC audio
+// when present) and the muxer flags for the requested output type. source is the optional
+// chain from videoSource applied before the split (rotation, subtitle burn-in)
+func packagingArgs(videoPath, outputDir, outputType string, options domain.PackagingOptions, audio bool, source string) []string {
+	renditions := options.Renditions
+
+	var filter strings.Builder
+	if source == "" {
+		filter.WriteString("[0:v]")
+	} else {
+		filter.WriteString(source + ",")
+	}
+	fmt.Fprintf(&filter, "split=%d", len(renditions))
+	for i := range renditions {
+		fmt.Fprintf(&filter, "[v%d]", i)
+	}
+	for i, rendition := range renditions {
+		fmt.Fprintf(&filter, ";[v%d]scale=-2:%d[v%dout]", i, rendition.Height, i)
+	}
+
+	args := append(inputArgs(videoPath), "-filter_complex", filter.String())
+	for i, rendition := range renditions {
+		args = append(args,
+			"-map", fmt.Sprintf("[v%dout]", i),
+			fmt.Sprintf("-c:v:%d", i), "libx264",
+			fmt.Sprintf("-b:v:%d", i), fmt.Sprintf("%dk", rendition.BitrateKbps),
+		)
+	}
+	// Keyframes aligned to the segment boundaries so every rendition switches cleanly
+	args = append(args, "-force_key_frames", fmt.Sprintf("expr:gte(t,n_forced*%d)", options.SegmentSeconds))
+
+	if outputType == domain.OutputTypeDASH {
+		adaptationSets := "id=0,streams=v"
+		if audio {
+			args = append(args, "-map", "0:a:0", "-c:a", "aac")
+			adaptationSets += " id=1,streams=a"
+		}
+		return append(args,
+			"-f", "dash",
+			"-seg_duration", fmt.Sprint(options.SegmentSeconds),
+			"-use_template", "1",
+			"-use_timeline", "1",
+			"-adaptation_sets", adaptationSets,
+			"-y",
+			filepath.Join(outputDir, domain.DASHManifest),
+		)
+	}
+
+	streamMap := make([]string, len(renditions))
+	for i := range renditions {
+		streamMap[i] = fmt.Sprintf("v:%d", i)
+		if audio {
+			// HLS needs the audio muxed into every variant playlist
+			args = append(args, "-map", "0:a:0")
+			streamMap[i] += fmt.Sprintf(",a:%d", i)
+		}
+	}
+	if audio {
+		args = append(args, "-c:a", "aac")
+	}
+
+	return append(args,
+		"-f", "hls",
+		"-hls_time", fmt.Sprint(options.SegmentSeconds),
+		"-hls_playlist_type", "vod",
+		"-hls_segment_filename", filepath.Join(outputDir, "stream_%v", "segment_%03d.ts"),
+		"-master_pl_name", domain.HLSMasterPlaylist,
+		"-var_stream_map", strings.Join(streamMap, " "),
+		"-y",
+		filepath.Join(outputDir, "stream_%v", "playlist.m3u8"),
+	)
+}
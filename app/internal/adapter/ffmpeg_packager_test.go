@@ -0,0 +1,140 @@
+package adapter
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+)
+
+func argValue(args []string, flag string) string {
+	for i := 0; i < len(args)-1; i++ {
+		if args[i] == flag {
+			return args[i+1]
+		}
+	}
+	return ""
+}
+
+func TestPackagingArgs_HLSWithAudio(t *testing.T) {
+	options := domain.DefaultPackagingOptions
+	args := packagingArgs("video.mp4", "out", domain.OutputTypeHLS, options, true, "")
+	joined := strings.Join(args, " ")
+
+	if filter := argValue(args, "-filter_complex"); filter != "[0:v]split=2[v0][v1];[v0]scale=-2:360[v0out];[v1]scale=-2:720[v1out]" {
+		t.Errorf("Unexpected filter graph: %s", filter)
+	}
+	if !strings.Contains(joined, "-b:v:0 800k") || !strings.Contains(joined, "-b:v:1 2800k") {
+		t.Errorf("Expected per-rendition bitrates, got %s", joined)
+	}
+	if streamMap := argValue(args, "-var_stream_map"); streamMap != "v:0,a:0 v:1,a:1" {
+		t.Errorf("Expected audio in every variant, got %s", streamMap)
+	}
+	if argValue(args, "-master_pl_name") != domain.HLSMasterPlaylist {
+		t.Errorf("Expected master playlist name, got %s", joined)
+	}
+	if args[len(args)-1] != filepath.Join("out", "stream_%v", "playlist.m3u8") {
+		t.Errorf("Unexpected output pattern: %s", args[len(args)-1])
+	}
+}
+
+func TestPackagingArgs_HLSWithoutAudio(t *testing.T) {
+	args := packagingArgs("video.mp4", "out", domain.OutputTypeHLS, domain.DefaultPackagingOptions, false, "")
+
+	if streamMap := argValue(args, "-var_stream_map"); streamMap != "v:0 v:1" {
+		t.Errorf("Expected video-only variants, got %s", streamMap)
+	}
+	if strings.Contains(strings.Join(args, " "), "0:a:0") {
+		t.Error("Expected no audio mapping for silent video")
+	}
+}
+
+func TestPackagingArgs_DASH(t *testing.T) {
+	options := domain.PackagingOptions{SegmentSeconds: 4, Renditions: []domain.Rendition{{Height: 480, BitrateKbps: 1200}}}
+	args := packagingArgs("video.mp4", "out", domain.OutputTypeDASH, options, true, "")
+
+	if argValue(args, "-f") != "dash" {
+		t.Errorf("Expected dash muxer, got %v", args)
+	}
+	if argValue(args, "-seg_duration") != "4" {
+		t.Errorf("Expected segment duration 4, got %s", argValue(args, "-seg_duration"))
+	}
+	if argValue(args, "-adaptation_sets") != "id=0,streams=v id=1,streams=a" {
+		t.Errorf("Unexpected adaptation sets: %s", argValue(args, "-adaptation_sets"))
+	}
+	if args[len(args)-1] != filepath.Join("out", domain.DASHManifest) {
+		t.Errorf("Unexpected manifest path: %s", args[len(args)-1])
+	}
+}
+
+// writeScript creates an executable shell script for fake ffmpeg/ffprobe binaries
+func writeScript(t *testing.T, name, content string) string {
+	t.Helper()
+	script := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(script, []byte("#!/bin/sh\n"+content), 0755); err != nil {
+		t.Fatalf("Failed to write %s: %v", name, err)
+	}
+	return script
+}
+
+func TestFFmpegPackager_Package(t *testing.T) {
+	tempDir := t.TempDir()
+	ffprobe := writeScript(t, "ffprobe", "echo '{\"streams\":[{\"index\":0,\"codec_type\":\"audio\"}]}'\n")
+	// Writes the master playlist next to the variant pattern given as the last argument
+	ffmpeg := writeScript(t, "ffmpeg", "for last; do :; done\ndir=$(dirname \"$(dirname \"$last\")\")\necho '#EXTM3U' > \"$dir/master.m3u8\"\n")
+
+	packager := NewFFmpegPackager(tempDir, ffmpeg, ffprobe)
+	outputDir, err := packager.Package(context.Background(), "job-1", "video.mp4", domain.OutputTypeHLS, domain.PackagingOptions{}, domain.FrameOptions{})
+	if err != nil {
+		t.Fatalf("Package failed: %v", err)
+	}
+
+	if outputDir != filepath.Join(tempDir, "hls_job-1") {
+		t.Errorf("Expected output dir keyed by job id, got %s", outputDir)
+	}
+	if _, err := os.Stat(filepath.Join(outputDir, domain.HLSMasterPlaylist)); err != nil {
+		t.Errorf("Expected master playlist in output dir: %v", err)
+	}
+}
+
+func TestFFmpegPackager_Package_MissingManifest(t *testing.T) {
+	tempDir := t.TempDir()
+	ffprobe := writeScript(t, "ffprobe", "echo '{\"streams\":[]}'\n")
+	ffmpeg := writeScript(t, "ffmpeg", "exit 0\n")
+
+	packager := NewFFmpegPackager(tempDir, ffmpeg, ffprobe)
+	if _, err := packager.Package(context.Background(), "job-1", "video.mp4", domain.OutputTypeDASH, domain.PackagingOptions{}, domain.FrameOptions{}); err == nil {
+		t.Fatal("Expected error when ffmpeg does not write the manifest")
+	}
+
+	if _, err := os.Stat(filepath.Join(tempDir, "dash_job-1")); !os.IsNotExist(err) {
+		t.Error("Expected output dir to be removed on failure")
+	}
+}
+
+func TestFFmpegPackager_Package_UnsupportedOutput(t *testing.T) {
+	packager := NewFFmpegPackager(t.TempDir(), "", "")
+
+	if _, err := packager.Package(context.Background(), "job-1", "video.mp4", domain.OutputTypeFrames, domain.PackagingOptions{}, domain.FrameOptions{}); err == nil {
+		t.Error("Expected error for non-packaging output type")
+	}
+}
+
+func TestFFmpegPackager_Package_FFprobeError(t *testing.T) {
+	packager := NewFFmpegPackager(t.TempDir(), "", "/nonexistent/ffprobe")
+
+	if _, err := packager.Package(context.Background(), "job-1", "video.mp4", domain.OutputTypeHLS, domain.PackagingOptions{}, domain.FrameOptions{}); err == nil {
+		t.Error("Expected error for missing ffprobe binary")
+	}
+}
+
+func TestPackagingArgs_BurnIn(t *testing.T) {
+	args := packagingArgs("video.mp4", "out", domain.OutputTypeHLS, domain.DefaultPackagingOptions, false, "[0:v][0:s:0]overlay")
+
+	if filter := argValue(args, "-filter_complex"); !strings.HasPrefix(filter, "[0:v][0:s:0]overlay,split=2[v0][v1];") {
+		t.Errorf("Expected burn-in before the split, got %s", filter)
+	}
+}
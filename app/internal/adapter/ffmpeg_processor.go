@@ -1,89 +1,677 @@
 package adapter
 
 import (
+	"archive/tar"
 	"archive/zip"
+	"compress/flate"
+	"compress/gzip"
 	"context"
 	"fmt"
 	"io"
+	"math"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
 	"github.com/SOAT-Project/hackaton-soat-processor/internal/port"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/ffmpeg"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/observability"
 )
 
 type FFmpegVideoProcessor struct {
-	tempDir string
+	tempDir     string
+	ffmpegPath  string
+	ffprobePath string
+
+	postProcessors []port.FramePostProcessorPort
+	analyzer       port.FrameAnalyzerPort
+	zip64          bool
+	zipPartSize    int64
+	archive        domain.ArchiveOptions
+	frameName      domain.FrameNameTemplate
+	// decoders are the codecs the installed ffmpeg decodes; nil skips the check
+	decoders map[string]bool
+	// archiveFiles creates the archives; nil creates them in tempDir
+	archiveFiles func(jobID, name string, sizeBytes int64) (port.VideoFile, error)
+}
+
+// ProcessorOption configures an FFmpegVideoProcessor
+type ProcessorOption func(*FFmpegVideoProcessor)
+
+// WithPostProcessors runs the post-processors in order on the extracted frames before zipping
+func WithPostProcessors(postProcessors ...port.FramePostProcessorPort) ProcessorOption {
+	return func(p *FFmpegVideoProcessor) {
+		p.postProcessors = append(p.postProcessors, postProcessors...)
+	}
+}
+
+// WithFrameAnalyzer reads the text of the frames when the request enables OCR; without it such
+// requests fail
+func WithFrameAnalyzer(analyzer port.FrameAnalyzerPort) ProcessorOption {
+	return func(p *FFmpegVideoProcessor) {
+		p.analyzer = analyzer
+	}
+}
+
+// WithoutZip64 restricts the zips to the classic format for consumers that cannot read Zip64;
+// outputs above its limits fail with domain.ErrArchiveLimit
+func WithoutZip64() ProcessorOption {
+	return func(p *FFmpegVideoProcessor) {
+		p.zip64 = false
+	}
+}
+
+// WithZipPartSize splits zips into name.part1.zip, name.part2.zip, ... whenever the files
+// add up to more than size bytes; zero keeps a single zip
+func WithZipPartSize(size int64) ProcessorOption {
+	return func(p *FFmpegVideoProcessor) {
+		p.zipPartSize = size
+	}
+}
+
+// WithZipCompression sets the zip method and level used when the request does not choose one
+func WithZipCompression(archive domain.ArchiveOptions) ProcessorOption {
+	return func(p *FFmpegVideoProcessor) {
+		p.archive = archive
+	}
+}
+
+// WithFrameNameTemplate names the frames in the zip when the request does not choose a template
+func WithFrameNameTemplate(template domain.FrameNameTemplate) ProcessorOption {
+	return func(p *FFmpegVideoProcessor) {
+		p.frameName = template
+	}
+}
+
+// WithDecoders rejects, before extracting anything, the videos whose codec is not among the
+// decoders of the installed ffmpeg (ffmpeg.Installation.Decoders), with unsupported_codec
+func WithDecoders(decoders map[string]bool) ProcessorOption {
+	return func(p *FFmpegVideoProcessor) {
+		p.decoders = decoders
+	}
+}
+
+// WithArchiveFiles creates the archives of a job through create, given the expected size of
+// the archive, such as MemoryWorkDir.CreateArtifact; the archive ends up wherever the file is
+func WithArchiveFiles(create func(jobID, name string, sizeBytes int64) (port.VideoFile, error)) ProcessorOption {
+	return func(p *FFmpegVideoProcessor) {
+		p.archiveFiles = create
+	}
 }
 
 func NewFFmpegVideoProcessor(tempDir string) port.VideoProcessorPort {
+	return NewFFmpegVideoProcessorWithBinaries(tempDir, "", "")
+}
+
+// NewFFmpegVideoProcessorWithBinaries uses the given ffmpeg/ffprobe paths (usually from
+// ffmpeg.Discover); empty paths fall back to the binaries found in PATH
+func NewFFmpegVideoProcessorWithBinaries(tempDir, ffmpegPath, ffprobePath string, options ...ProcessorOption) port.VideoProcessorPort {
 	if tempDir == "" {
 		tempDir = "temp"
 	}
 
 	os.MkdirAll(tempDir, 0777)
-	return &FFmpegVideoProcessor{
-		tempDir: tempDir,
+	processor := &FFmpegVideoProcessor{
+		tempDir:     tempDir,
+		ffmpegPath:  ffmpegPath,
+		ffprobePath: ffprobePath,
+		zip64:       true,
+	}
+	for _, option := range options {
+		option(processor)
+	}
+	return processor
+}
+
+func (p *FFmpegVideoProcessor) ffmpegBinary() string {
+	if p.ffmpegPath == "" {
+		return "ffmpeg"
+	}
+	return p.ffmpegPath
+}
+
+func (p *FFmpegVideoProcessor) ffprobeBinary() string {
+	if p.ffprobePath == "" {
+		return "ffprobe"
 	}
+	return p.ffprobePath
 }
 
-func (p *FFmpegVideoProcessor) ProcessVideo(ctx context.Context, videoPath string) (string, int, error) {
-	processDir := filepath.Join(p.tempDir, fmt.Sprintf("process_%d", os.Getpid()))
+// ProcessVideo extracts the frames into a directory keyed by jobID, so concurrent
+// jobs in the same process never share frames or zip files. Extracted subtitles and the
+// frames manifest (sharpness, colors and text) are zipped with the frames
+func (p *FFmpegVideoProcessor) ProcessVideo(ctx context.Context, request domain.ProcessingRequest) (domain.ProcessingResult, error) {
+	jobID, videoPath, options := sanitizeJobID(request.JobID), request.VideoPath, request.Options
+	if jobID == "" {
+		return domain.ProcessingResult{}, fmt.Errorf("job id is required")
+	}
+	if options.OCR.Enabled && p.analyzer == nil {
+		return domain.ProcessingResult{}, fmt.Errorf("ocr is not enabled")
+	}
+
+	processDir := filepath.Join(p.tempDir, "process_"+jobID)
 	if err := os.MkdirAll(processDir, 0777); err != nil {
-		return "", 0, fmt.Errorf("failed to create process directory: %w", err)
+		return domain.ProcessingResult{}, fmt.Errorf("failed to create process directory: %w", err)
 	}
 	defer os.RemoveAll(processDir)
 
-	framePattern := filepath.Join(processDir, "frame_%04d.png")
-	cmd := exec.CommandContext(ctx, "ffmpeg",
-		"-i", videoPath,
-		"-vf", "fps=1",
-		"-y",
-		framePattern,
-	)
+	probe, err := p.probe(ctx, videoPath, options)
+	if err != nil {
+		return domain.ProcessingResult{}, err
+	}
 
-	output, err := cmd.CombinedOutput()
+	source, err := videoSource(videoPath, probe, options)
 	if err != nil {
-		return "", 0, fmt.Errorf("ffmpeg error: %w, output: %s", err, string(output))
+		return domain.ProcessingResult{}, err
 	}
 
-	frames, err := filepath.Glob(filepath.Join(processDir, "*.png"))
+	var subtitleFiles []string
+	if options.Subtitles.Extract {
+		subtitleFiles, err = extractSubtitles(ctx, p.ffmpegBinary(), videoPath, probe.StreamsOfType(ffmpeg.CodecTypeSubtitle), options.Subtitles.ExtractFormat(), processDir)
+		if err != nil {
+			return domain.ProcessingResult{}, err
+		}
+	}
+
+	groups, err := p.extractImages(ctx, videoPath, source, frameRateFilter(probe, options), filepath.Join(processDir, "frame_%04d.png"), options.Windows)
 	if err != nil {
-		return "", 0, fmt.Errorf("failed to list video frames: %w", err)
+		return domain.ProcessingResult{}, err
+	}
+
+	var frames []string
+	for _, group := range groups {
+		frames = append(frames, group...)
 	}
+	timestamps := frameTimestamps(groups, options.Windows, sampleRate(probe, options))
 
 	if len(frames) == 0 {
-		return "", 0, fmt.Errorf("no frames extracted from video")
+		return domain.ProcessingResult{}, fmt.Errorf("no frames extracted from video")
+	}
+	// The fps filter may round up by a frame; the cap is a hard limit
+	if options.MaxFrames > 0 && len(frames) > options.MaxFrames {
+		frames = frames[:options.MaxFrames]
+	}
+	request.Report(domain.ProcessingStepExtract, len(frames))
+
+	var manifest *framesManifest
+	if options.Sharpness.Enabled() {
+		frames, manifest, err = scoreFrames(frames, options.Sharpness)
+		if err != nil {
+			return domain.ProcessingResult{}, err
+		}
+	}
+
+	for _, postProcessor := range p.postProcessors {
+		if err := postProcessor.PostProcess(ctx, frames, options); err != nil {
+			return domain.ProcessingResult{}, fmt.Errorf("frame post-processing failed: %w", err)
+		}
+	}
+
+	// The colors and the text are read after the post-processing, as zipped (blurred regions
+	// are not read), but before the optimization, whose formats may not be decoded
+	if options.Colors.Enabled() {
+		if manifest == nil {
+			manifest = newFramesManifest(frames)
+		}
+		if err := manifest.addColors(frames, options.Colors); err != nil {
+			return domain.ProcessingResult{}, err
+		}
+	}
+	if options.OCR.Enabled {
+		if manifest == nil {
+			manifest = newFramesManifest(frames)
+		}
+		if err := manifest.addText(ctx, p.analyzer, frames, timestamps, options.OCR); err != nil {
+			return domain.ProcessingResult{}, err
+		}
+	}
+
+	if options.Image.Enabled() {
+		var before, after int64
+		frames, before, after, err = optimizeFrames(ctx, p.ffmpegBinary(), frames, options.Image)
+		if err != nil {
+			return domain.ProcessingResult{}, err
+		}
+		observability.RecordFrameOptimization(options.Image.Format, before, after)
+	}
+
+	request.Report(domain.ProcessingStepPostProcess, len(frames))
+
+	frames, err = nameFrames(frames, timestamps, options.FrameName.Or(p.frameName), options.ProcessID, filepath.Join(processDir, "named"))
+	if err != nil {
+		return domain.ProcessingResult{}, err
+	}
+
+	extraFiles := subtitleFiles
+	if manifest != nil {
+		manifestPath, err := manifest.write(frames, processDir)
+		if err != nil {
+			return domain.ProcessingResult{}, err
+		}
+		extraFiles = append(extraFiles, manifestPath)
+	}
+
+	archive := options.Archive.Or(p.archive)
+	zipPaths, err := p.createZipParts(request.JobID, append(frames, extraFiles...), filepath.Join(p.tempDir, "frames_"+jobID+archive.Extension()), archive)
+	if err != nil {
+		return domain.ProcessingResult{}, fmt.Errorf("failed to create zip: %w", err)
+	}
+	request.Report(domain.ProcessingStepArchive, len(frames))
+
+	return domain.ProcessingResult{Artifacts: zipPaths, FrameCount: len(frames), Video: videoInfo(probe)}, nil
+}
+
+// frameRateFilter samples at the requested rate unless that would exceed MaxFrames, in which
+// case the rate drops so MaxFrames are spread evenly over the processed duration
+func frameRateFilter(probe *ffmpeg.ProbeResult, options domain.FrameOptions) string {
+	if seconds, capped := cappedSeconds(probe, options); capped {
+		return fmt.Sprintf("fps=%d/%s", options.MaxFrames, formatSeconds(seconds))
+	}
+	return "fps=" + strconv.FormatFloat(options.FrameRate(), 'f', -1, 64)
+}
+
+// sampleRate is the rate set by frameRateFilter, in frames per second
+func sampleRate(probe *ffmpeg.ProbeResult, options domain.FrameOptions) float64 {
+	if seconds, capped := cappedSeconds(probe, options); capped {
+		return float64(options.MaxFrames) / seconds
+	}
+	return options.FrameRate()
+}
+
+// cappedSeconds returns the processed duration when sampling it at the requested rate would
+// exceed MaxFrames
+func cappedSeconds(probe *ffmpeg.ProbeResult, options domain.FrameOptions) (float64, bool) {
+	if options.MaxFrames <= 0 || probe == nil {
+		return 0, false
 	}
+	seconds := domain.ProcessedSeconds(probe.Duration(), options.Windows)
+	return seconds, seconds*options.FrameRate() > float64(options.MaxFrames)
+}
+
+// frameTimestamps returns the position in the video, in milliseconds, of each extracted frame
+// keyed by its path without extension, which survives the image format conversion. The fps
+// filter outputs the n-th frame of a window at n/rate seconds from the window start
+func frameTimestamps(groups [][]string, windows []domain.TimeWindow, rate float64) map[string]int64 {
+	timestamps := make(map[string]int64)
+	for i, group := range groups {
+		var start float64
+		if i < len(windows) {
+			start = windows[i].Start
+		}
+		for n, frame := range group {
+			seconds := start + float64(n)/rate
+			timestamps[strings.TrimSuffix(frame, filepath.Ext(frame))] = int64(math.Round(seconds * 1000))
+		}
+	}
+	return timestamps
+}
 
-	zipPath := filepath.Join(p.tempDir, fmt.Sprintf("frames_%d.zip", os.Getpid()))
-	if err := p.createZipFile(frames, zipPath); err != nil {
-		return "", 0, fmt.Errorf("failed to create zip: %w", err)
+// nameFrames moves the frames into dir named by template, keeping their extension. The
+// default template keeps the names given by ffmpeg
+func nameFrames(frames []string, timestamps map[string]int64, template domain.FrameNameTemplate, processID, dir string) ([]string, error) {
+	if template == "" || template == domain.DefaultFrameName {
+		return frames, nil
 	}
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return nil, fmt.Errorf("failed to create frames directory: %w", err)
+	}
+
+	named := make([]string, 0, len(frames))
+	seen := make(map[string]bool, len(frames))
+	for i, frame := range frames {
+		ext := filepath.Ext(frame)
+		name := template.Name(processID, i+1, timestamps[strings.TrimSuffix(frame, ext)], ext)
+		if seen[name] {
+			return nil, fmt.Errorf("frame name template gives two frames the name %s", name)
+		}
+		seen[name] = true
 
-	return zipPath, len(frames), nil
+		target := filepath.Join(dir, name)
+		if err := os.Rename(frame, target); err != nil {
+			return nil, fmt.Errorf("failed to rename frame %s: %w", filepath.Base(frame), err)
+		}
+		named = append(named, target)
+	}
+	return named, nil
 }
 
-func (p *FFmpegVideoProcessor) createZipFile(files []string, zipPath string) error {
+// extractImages runs ffmpeg once per time window (or once for the whole video) writing
+// images to pattern, numbered sequentially across runs, and returns them grouped by window
+func (p *FFmpegVideoProcessor) extractImages(ctx context.Context, videoPath, source, filters, pattern string, windows []domain.TimeWindow) ([][]string, error) {
+	if len(windows) == 0 {
+		windows = []domain.TimeWindow{{}}
+	}
+	glob := strings.SplitN(pattern, "%", 2)[0] + "*" + filepath.Ext(pattern)
+
+	var groups [][]string
+	extracted := 0
+	for _, window := range windows {
+		args := append(windowArgs(window), inputArgs(videoPath)...)
+		args = append(args, filterArgs(source, filters)...)
+		args = append(args, "-start_number", strconv.Itoa(extracted+1), "-y", pattern)
+
+		// ffmpeg runs in its own process group so cancellation also stops its children
+		output, err := ffmpeg.CombinedOutput(ctx, ffmpeg.DefaultKillGrace, p.ffmpegBinary(), args...)
+		if err != nil {
+			return nil, fmt.Errorf("ffmpeg error: %w, output: %s", err, string(output))
+		}
+
+		images, err := filepath.Glob(glob)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list extracted images: %w", err)
+		}
+		sort.Strings(images)
+
+		groups = append(groups, images[extracted:])
+		extracted = len(images)
+	}
+
+	return groups, nil
+}
+
+// probe runs ffprobe only when the frame options depend on the video streams
+func (p *FFmpegVideoProcessor) probe(ctx context.Context, videoPath string, options domain.FrameOptions) (*ffmpeg.ProbeResult, error) {
+	if !needsProbe(options) && p.decoders == nil {
+		return nil, nil
+	}
+	probe, err := ffmpeg.Probe(ctx, p.ffprobeBinary(), videoPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.checkDecoder(probe); err != nil {
+		return nil, err
+	}
+	return probe, nil
+}
+
+// checkDecoder fails a video whose codec the installed ffmpeg cannot decode, instead of
+// letting the extraction fail with a decoder error that does not say what to do
+func (p *FFmpegVideoProcessor) checkDecoder(probe *ffmpeg.ProbeResult) error {
+	stream, ok := probe.VideoStream()
+	if p.decoders == nil || !ok || stream.CodecName == "" || p.decoders[stream.CodecName] {
+		return nil
+	}
+	err := fmt.Errorf("video codec %s is not supported by this worker: re-encode the video to H.264 or deploy an ffmpeg build with a %s decoder", stream.CodecName, stream.CodecName)
+	return domain.NewCodedError(domain.ErrorCodeUnsupportedCodec, &ffmpeg.Failure{Reason: ffmpeg.FailureUnsupportedCodec, Err: err})
+}
+
+// ProbeVideo reads the duration, size, rotation and codec of the video, which the dry run estimates from
+func (p *FFmpegVideoProcessor) ProbeVideo(ctx context.Context, videoPath string) (domain.VideoInfo, error) {
+	probe, err := ffmpeg.Probe(ctx, p.ffprobeBinary(), videoPath)
+	if err != nil {
+		return domain.VideoInfo{}, err
+	}
+
+	return videoInfo(probe), nil
+}
+
+// videoInfo describes the probed video; nil, when the options did not need a probe, is the
+// zero VideoInfo
+func videoInfo(probe *ffmpeg.ProbeResult) domain.VideoInfo {
+	if probe == nil {
+		return domain.VideoInfo{}
+	}
+	info := domain.VideoInfo{DurationSeconds: probe.Duration()}
+	if stream, ok := probe.VideoStream(); ok {
+		info.Width, info.Height, info.Rotation = stream.Width, stream.Height, stream.Rotation()
+		info.Codec = stream.CodecName
+	}
+	return info
+}
+
+// sanitizeJobID keeps only characters that are safe in a file name, since the
+// job id is derived from the process_id received in the message
+func sanitizeJobID(jobID string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, jobID)
+}
+
+// createZipParts writes the files of jobID into zipPath, or into numbered parts next to it
+// when they exceed the part size. A file larger than the part size gets a part of its own. The
+// archives are zips, or gzipped tarballs with the tar.gz encoding. It returns where the
+// archives were written, which WithArchiveFiles may place in another directory
+func (p *FFmpegVideoProcessor) createZipParts(jobID string, files []string, zipPath string, archive domain.ArchiveOptions) ([]string, error) {
+	groups, err := p.zipPartGroups(files)
+	if err != nil {
+		return nil, err
+	}
+	if len(groups) == 1 {
+		path, err := p.createArchive(jobID, files, zipPath, archive)
+		if err != nil {
+			return nil, err
+		}
+		return []string{path}, nil
+	}
+
+	extension := archive.Extension()
+	base := strings.TrimSuffix(zipPath, extension)
+	parts := make([]string, 0, len(groups))
+	for i, group := range groups {
+		path, err := p.createArchive(jobID, group, fmt.Sprintf("%s.part%d%s", base, i+1, extension), archive)
+		if err != nil {
+			for _, part := range parts {
+				os.Remove(part)
+			}
+			return nil, err
+		}
+		parts = append(parts, path)
+	}
+	return parts, nil
+}
+
+// archiveEntryOverhead bounds the headers an archive adds for each file: the local and
+// central zip records, or the tar header and its padding
+const archiveEntryOverhead = 1024
+
+// createArchive writes the files into an archive named after path and returns where it is. A
+// failed archive is removed
+func (p *FFmpegVideoProcessor) createArchive(jobID string, files []string, path string, archive domain.ArchiveOptions) (string, error) {
+	var file port.VideoFile
+	var err error
+	if p.archiveFiles != nil {
+		// Compression can only shrink the frames, so their size bounds the archive
+		size := int64(archiveEntryOverhead * (len(files) + 1))
+		for _, name := range files {
+			info, err := os.Stat(name)
+			if err != nil {
+				return "", err
+			}
+			size += info.Size()
+		}
+		file, err = p.archiveFiles(jobID, filepath.Base(path), size)
+	} else {
+		file, err = os.Create(path)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	write := p.writeZip
+	if archive.TarGz() {
+		write = writeTarGz
+	}
+	if err := closeArchive(file, write(files, file, archive)); err != nil {
+		return "", err
+	}
+	return file.Name(), nil
+}
+
+// closeArchive closes an archive written with err, removing it unless it is complete
+func closeArchive(file port.VideoFile, err error) error {
+	if err == nil {
+		err = file.Close()
+	} else {
+		file.Close()
+	}
+	if err != nil {
+		os.Remove(file.Name())
+	}
+	return err
+}
+
+// zipPartGroups splits the files in order so the uncompressed sizes of each group stay under
+// the part size, which also bounds the compressed part
+func (p *FFmpegVideoProcessor) zipPartGroups(files []string) ([][]string, error) {
+	if p.zipPartSize <= 0 {
+		return [][]string{files}, nil
+	}
+
+	var groups [][]string
+	var current []string
+	var currentSize int64
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return nil, err
+		}
+		if len(current) > 0 && currentSize+info.Size() > p.zipPartSize {
+			groups = append(groups, current)
+			current, currentSize = nil, 0
+		}
+		current = append(current, file)
+		currentSize += info.Size()
+	}
+	return append(groups, current), nil
+}
+
+// createZipFile writes the files into a zip at zipPath
+func (p *FFmpegVideoProcessor) createZipFile(files []string, zipPath string, archive domain.ArchiveOptions) error {
+	if !p.zip64 {
+		if err := checkZipLimits(files); err != nil {
+			return err
+		}
+	}
+
 	zipFile, err := os.Create(zipPath)
 	if err != nil {
 		return err
 	}
-	defer zipFile.Close()
+	return closeArchive(zipFile, p.writeZip(files, zipFile, archive))
+}
 
-	zipWriter := zip.NewWriter(zipFile)
-	defer zipWriter.Close()
+// writeZip writes the files as a zip into out. archive/zip switches to Zip64 records by itself
+// past the classic limits; without Zip64 those outputs are rejected instead. The central
+// directory is only written on Close, so its error is what tells a complete archive apart
+func (p *FFmpegVideoProcessor) writeZip(files []string, out io.Writer, archive domain.ArchiveOptions) error {
+	if !p.zip64 {
+		if err := checkZipLimits(files); err != nil {
+			return err
+		}
+	}
 
+	counter := &countingWriter{writer: out}
+	zipWriter := zip.NewWriter(counter)
+	if archive.Level != 0 {
+		zipWriter.RegisterCompressor(zip.Deflate, func(out io.Writer) (io.WriteCloser, error) {
+			return flate.NewWriter(out, archive.Level)
+		})
+	}
 	for _, file := range files {
-		if err := p.addFileToZip(zipWriter, file); err != nil {
+		if err := p.addFileToZip(zipWriter, file, archive); err != nil {
+			zipWriter.Close()
 			return err
 		}
 	}
+	if err := zipWriter.Close(); err != nil {
+		return fmt.Errorf("failed to finish zip: %w", err)
+	}
 
+	// Compression may still leave the archive past 4GB even when every file fits
+	if !p.zip64 && counter.written > domain.ZipMaxSize {
+		return fmt.Errorf("%w: zip is %d bytes, above the %d bytes allowed without Zip64", domain.ErrArchiveLimit, counter.written, int64(domain.ZipMaxSize))
+	}
 	return nil
 }
 
-func (p *FFmpegVideoProcessor) addFileToZip(zipWriter *zip.Writer, filename string) error {
+// countingWriter counts the bytes written through it
+type countingWriter struct {
+	writer  io.Writer
+	written int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	n, err := w.writer.Write(p)
+	w.written += int64(n)
+	return n, err
+}
+
+// writeTarGz writes the files as a gzipped tarball into out, at the gzip level of the archive.
+// A tarball has no entry or size limits to check
+func writeTarGz(files []string, out io.Writer, archive domain.ArchiveOptions) error {
+	level := gzip.DefaultCompression
+	if archive.Level != 0 {
+		level = archive.Level
+	}
+	gzipWriter, err := gzip.NewWriterLevel(out, level)
+	if err != nil {
+		return err
+	}
+	tarWriter := tar.NewWriter(gzipWriter)
+	for _, file := range files {
+		if err := addFileToTar(tarWriter, file); err != nil {
+			return err
+		}
+	}
+	if err := tarWriter.Close(); err != nil {
+		return fmt.Errorf("failed to finish tarball: %w", err)
+	}
+	if err := gzipWriter.Close(); err != nil {
+		return fmt.Errorf("failed to finish tarball: %w", err)
+	}
+	return nil
+}
+
+func addFileToTar(tarWriter *tar.Writer, filename string) error {
+	file, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	header, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return err
+	}
+	header.Name = filepath.Base(filename)
+	if err := tarWriter.WriteHeader(header); err != nil {
+		return err
+	}
+	_, err = io.Copy(tarWriter, file)
+	return err
+}
+
+// checkZipLimits rejects inputs that cannot fit a zip without Zip64: too many entries or a
+// file above 4GB. The archive size itself is checked once written
+func checkZipLimits(files []string) error {
+	if len(files) > domain.ZipMaxEntries {
+		return fmt.Errorf("%w: %d files, above the %d entries allowed without Zip64", domain.ErrArchiveLimit, len(files), domain.ZipMaxEntries)
+	}
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return err
+		}
+		if info.Size() > domain.ZipMaxSize {
+			return fmt.Errorf("%w: %s is %d bytes, above the %d bytes allowed without Zip64", domain.ErrArchiveLimit, filepath.Base(file), info.Size(), int64(domain.ZipMaxSize))
+		}
+	}
+	return nil
+}
+
+func (p *FFmpegVideoProcessor) addFileToZip(zipWriter *zip.Writer, filename string, archive domain.ArchiveOptions) error {
 	file, err := os.Open(filename)
 	if err != nil {
 		return err
@@ -101,7 +689,10 @@ func (p *FFmpegVideoProcessor) addFileToZip(zipWriter *zip.Writer, filename stri
 	}
 
 	header.Name = filepath.Base(filename)
-	header.Method = zip.Deflate
+	header.Method = zip.Store
+	if archive.Deflate(header.Name) {
+		header.Method = zip.Deflate
+	}
 
 	writer, err := zipWriter.CreateHeader(header)
 	if err != nil {
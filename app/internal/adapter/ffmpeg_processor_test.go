@@ -1,10 +1,19 @@
 package adapter
 
 import (
+	"archive/zip"
 	"context"
+	"errors"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/ffmpeg"
 )
 
 func TestNewFFmpegVideoProcessor(t *testing.T) {
@@ -37,7 +46,7 @@ func TestFFmpegVideoProcessor_ProcessVideo_InvalidPath(t *testing.T) {
 	defer os.RemoveAll("test_temp")
 
 	ctx := context.Background()
-	_, _, err := processor.(*FFmpegVideoProcessor).ProcessVideo(ctx, "/nonexistent/video.mp4")
+	_, err := processor.(*FFmpegVideoProcessor).ProcessVideo(ctx, domain.ProcessingRequest{JobID: "job-1", VideoPath: "/nonexistent/video.mp4"})
 	if err == nil {
 		t.Error("Expected error for nonexistent video file")
 	}
@@ -60,7 +69,7 @@ func TestFFmpegVideoProcessor_CreateZipFile(t *testing.T) {
 	zipPath := filepath.Join(tempDir, "test.zip")
 	files := []string{testFile1, testFile2}
 
-	err := processor.createZipFile(files, zipPath)
+	err := processor.createZipFile(files, zipPath, domain.ArchiveOptions{})
 	if err != nil {
 		t.Fatalf("createZipFile failed: %v", err)
 	}
@@ -75,7 +84,7 @@ func TestFFmpegVideoProcessor_CreateZipFile_InvalidPath(t *testing.T) {
 	processor := &FFmpegVideoProcessor{tempDir: "test_temp"}
 	defer os.RemoveAll("test_temp")
 
-	err := processor.createZipFile([]string{}, "/invalid/path/test.zip")
+	err := processor.createZipFile([]string{}, "/invalid/path/test.zip", domain.ArchiveOptions{})
 	if err == nil {
 		t.Error("Expected error for invalid zip path")
 	}
@@ -91,7 +100,7 @@ func TestFFmpegVideoProcessor_CreateZipFile_NonexistentFile(t *testing.T) {
 	zipPath := filepath.Join(tempDir, "test.zip")
 	files := []string{"/nonexistent/file.txt"}
 
-	err := processor.createZipFile(files, zipPath)
+	err := processor.createZipFile(files, zipPath, domain.ArchiveOptions{})
 	if err == nil {
 		t.Error("Expected error for nonexistent file")
 	}
@@ -110,7 +119,7 @@ func TestFFmpegVideoProcessor_AddFileToZip(t *testing.T) {
 
 	// Create zip and test addFileToZip
 	zipPath := filepath.Join(tempDir, "test.zip")
-	err := processor.createZipFile([]string{testFile}, zipPath)
+	err := processor.createZipFile([]string{testFile}, zipPath, domain.ArchiveOptions{})
 	if err != nil {
 		t.Fatalf("createZipFile failed: %v", err)
 	}
@@ -161,7 +170,7 @@ func TestFFmpegVideoProcessor_Integration(t *testing.T) {
 	// Note: This will fail without a real video
 	// but it tests the code path
 	ctx := context.Background()
-	_, _, err := processor.(*FFmpegVideoProcessor).ProcessVideo(ctx, testVideo)
+	_, err := processor.(*FFmpegVideoProcessor).ProcessVideo(ctx, domain.ProcessingRequest{JobID: "job-1", VideoPath: testVideo})
 
 	// We expect this to fail since we don't have a real video
 	if err == nil {
@@ -209,7 +218,7 @@ func TestFFmpegVideoProcessor_ProcessVideo_NoFrames(t *testing.T) {
 
 	// Test with invalid video that won't produce frames
 	ctx := context.Background()
-	_, _, err := processor.ProcessVideo(ctx, "/invalid/path.mp4")
+	_, err := processor.ProcessVideo(ctx, domain.ProcessingRequest{JobID: "job-1", VideoPath: "/invalid/path.mp4"})
 
 	if err == nil {
 		t.Error("Expected error for invalid video path")
@@ -226,7 +235,7 @@ func TestFFmpegVideoProcessor_CreateZipFile_EmptyFiles(t *testing.T) {
 	zipPath := filepath.Join(tempDir, "empty.zip")
 
 	// Create with empty file list
-	err := processor.createZipFile([]string{}, zipPath)
+	err := processor.createZipFile([]string{}, zipPath, domain.ArchiveOptions{})
 	if err != nil {
 		t.Fatalf("createZipFile with empty list failed: %v", err)
 	}
@@ -242,9 +251,304 @@ func TestFFmpegVideoProcessor_ProcessVideo_CreateDirError(t *testing.T) {
 	processor := &FFmpegVideoProcessor{tempDir: "/nonexistent/invalid/path"}
 
 	ctx := context.Background()
-	_, _, err := processor.ProcessVideo(ctx, "video.mp4")
+	_, err := processor.ProcessVideo(ctx, domain.ProcessingRequest{JobID: "job-1", VideoPath: "video.mp4"})
 
 	if err == nil {
 		t.Error("Expected error for invalid temp directory")
 	}
 }
+
+func TestNewFFmpegVideoProcessorWithBinaries(t *testing.T) {
+	tempDir := t.TempDir()
+
+	processor := NewFFmpegVideoProcessorWithBinaries(tempDir, "/opt/ffmpeg/bin/ffmpeg", "/opt/ffmpeg/bin/ffprobe").(*FFmpegVideoProcessor)
+
+	if processor.ffmpegBinary() != "/opt/ffmpeg/bin/ffmpeg" {
+		t.Errorf("Expected configured ffmpeg path, got %s", processor.ffmpegBinary())
+	}
+	if processor.ffprobePath != "/opt/ffmpeg/bin/ffprobe" {
+		t.Errorf("Expected configured ffprobe path, got %s", processor.ffprobePath)
+	}
+
+	// Invalid binary path must fail instead of falling back to PATH
+	_, err := processor.ProcessVideo(context.Background(), domain.ProcessingRequest{JobID: "job-1", VideoPath: "video.mp4"})
+	if err == nil {
+		t.Error("Expected error for missing ffmpeg binary")
+	}
+}
+
+func TestFFmpegVideoProcessor_DefaultBinary(t *testing.T) {
+	processor := &FFmpegVideoProcessor{tempDir: t.TempDir()}
+
+	if processor.ffmpegBinary() != "ffmpeg" {
+		t.Errorf("Expected default ffmpeg binary, got %s", processor.ffmpegBinary())
+	}
+}
+
+// writeFakeFFmpeg creates a script that writes two frames following the output pattern
+func writeFakeFFmpeg(t *testing.T) string {
+	t.Helper()
+	script := filepath.Join(t.TempDir(), "ffmpeg")
+	content := "#!/bin/sh\nfor last; do :; done\nfor i in 1 2; do printf 'frame' > \"$(printf \"$last\" \"$i\")\"; done\n"
+	if err := os.WriteFile(script, []byte(content), 0755); err != nil {
+		t.Fatalf("Failed to write fake ffmpeg: %v", err)
+	}
+	return script
+}
+
+func TestFFmpegVideoProcessor_ProcessVideo_ConcurrentJobs(t *testing.T) {
+	tempDir := t.TempDir()
+	processor := NewFFmpegVideoProcessorWithBinaries(tempDir, writeFakeFFmpeg(t), "")
+
+	jobs := []string{"process-1_aa", "process-1_bb", "process-2_cc"}
+	zipPaths := make([]string, len(jobs))
+	errs := make([]error, len(jobs))
+
+	var wg sync.WaitGroup
+	for i, jobID := range jobs {
+		wg.Add(1)
+		go func(i int, jobID string) {
+			defer wg.Done()
+			var result domain.ProcessingResult
+			result, errs[i] = processor.ProcessVideo(context.Background(), domain.ProcessingRequest{JobID: jobID, VideoPath: "video.mp4"})
+			if len(result.Artifacts) == 1 {
+				zipPaths[i] = result.Artifacts[0]
+			}
+		}(i, jobID)
+	}
+	wg.Wait()
+
+	seen := make(map[string]bool)
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("ProcessVideo for %s failed: %v", jobs[i], err)
+		}
+		if seen[zipPaths[i]] {
+			t.Errorf("Expected unique zip path per job, got duplicate %s", zipPaths[i])
+		}
+		seen[zipPaths[i]] = true
+	}
+
+	if zipPaths[0] != filepath.Join(tempDir, "frames_process-1_aa.zip") {
+		t.Errorf("Expected zip keyed by job id, got %s", zipPaths[0])
+	}
+}
+
+func TestFFmpegVideoProcessor_ProcessVideo_FrameNameTemplate(t *testing.T) {
+	tempDir := t.TempDir()
+	processor := NewFFmpegVideoProcessorWithBinaries(tempDir, writeFakeFFmpeg(t), "", WithFrameNameTemplate("frame_{index}"))
+
+	options := domain.FrameOptions{ProcessID: "123", FPS: 2, FrameName: "{process_id}_{ts_ms}.png"}
+	result, err := processor.ProcessVideo(context.Background(), domain.ProcessingRequest{JobID: "123_ab", VideoPath: "video.mp4", Options: options})
+	if err != nil {
+		t.Fatalf("ProcessVideo failed: %v", err)
+	}
+	if result.FrameCount != 2 || len(result.Artifacts) != 1 {
+		t.Fatalf("Expected 2 frames in one zip, got %d in %v", result.FrameCount, result.Artifacts)
+	}
+
+	reader, err := zip.OpenReader(result.Artifacts[0])
+	if err != nil {
+		t.Fatalf("Failed to open zip: %v", err)
+	}
+	defer reader.Close()
+
+	var names []string
+	for _, file := range reader.File {
+		names = append(names, file.Name)
+	}
+	if strings.Join(names, ",") != "123_0.png,123_500.png" {
+		t.Errorf("Expected frames named by timestamp, got %v", names)
+	}
+}
+
+func TestFrameTimestamps(t *testing.T) {
+	groups := [][]string{{"dir/frame_0001.png", "dir/frame_0002.png"}, {"dir/frame_0003.png"}}
+	windows := []domain.TimeWindow{{Start: 10, End: 20}, {Start: 30.25, End: 40}}
+
+	timestamps := frameTimestamps(groups, windows, 4)
+	expected := map[string]int64{"dir/frame_0001": 10000, "dir/frame_0002": 10250, "dir/frame_0003": 30250}
+	for frame, ts := range expected {
+		if timestamps[frame] != ts {
+			t.Errorf("Expected %s at %dms, got %d", frame, ts, timestamps[frame])
+		}
+	}
+}
+
+func TestFFmpegVideoProcessor_ProcessVideo_EmptyJobID(t *testing.T) {
+	processor := &FFmpegVideoProcessor{tempDir: t.TempDir()}
+
+	if _, err := processor.ProcessVideo(context.Background(), domain.ProcessingRequest{JobID: "", VideoPath: "video.mp4"}); err == nil {
+		t.Error("Expected error for empty job id")
+	}
+}
+
+func TestSanitizeJobID(t *testing.T) {
+	if got := sanitizeJobID("../tenant/process 1_ab"); got != "___tenant_process_1_ab" {
+		t.Errorf("Expected unsafe characters replaced, got %s", got)
+	}
+}
+
+func TestFrameRateFilter(t *testing.T) {
+	probe := &ffmpeg.ProbeResult{Format: ffmpeg.Format{Duration: "3600"}}
+
+	tests := []struct {
+		name    string
+		probe   *ffmpeg.ProbeResult
+		options domain.FrameOptions
+		want    string
+	}{
+		{"no cap", probe, domain.FrameOptions{}, "fps=1"},
+		{"cap above duration", probe, domain.FrameOptions{MaxFrames: 5000}, "fps=1"},
+		{"cap spreads frames", probe, domain.FrameOptions{MaxFrames: 100}, "fps=100/3600"},
+		{"cap over windows", probe, domain.FrameOptions{MaxFrames: 10, Windows: []domain.TimeWindow{{Start: 0, End: 60}, {Start: 3560}}}, "fps=10/100"},
+		{"unknown duration", &ffmpeg.ProbeResult{}, domain.FrameOptions{MaxFrames: 10}, "fps=1"},
+		{"custom rate", probe, domain.FrameOptions{FPS: 2.5}, "fps=2.5"},
+		{"custom rate over the cap", probe, domain.FrameOptions{FPS: 2, MaxFrames: 5000}, "fps=5000/3600"},
+	}
+	for _, tt := range tests {
+		if got := frameRateFilter(tt.probe, tt.options); got != tt.want {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestFFmpegVideoProcessor_ProcessVideo_MaxFramesTrimsExtra(t *testing.T) {
+	tempDir := t.TempDir()
+	ffmpegPath := writeFakeFFmpeg(t)
+	ffprobePath := filepath.Join(t.TempDir(), "ffprobe")
+	script := "#!/bin/sh\necho '{\"streams\":[],\"format\":{\"duration\":\"2\"}}'\n"
+	if err := os.WriteFile(ffprobePath, []byte(script), 0755); err != nil {
+		t.Fatalf("Failed to write fake ffprobe: %v", err)
+	}
+	processor := NewFFmpegVideoProcessorWithBinaries(tempDir, ffmpegPath, ffprobePath)
+
+	result, err := processor.ProcessVideo(context.Background(), domain.ProcessingRequest{JobID: "job-1", VideoPath: "video.mp4", Options: domain.FrameOptions{MaxFrames: 1}})
+	if err != nil {
+		t.Fatalf("ProcessVideo failed: %v", err)
+	}
+	if result.FrameCount != 1 {
+		t.Errorf("Expected frames capped at 1, got %d", result.FrameCount)
+	}
+}
+
+func TestFFmpegVideoProcessor_ProcessVideo_Result(t *testing.T) {
+	ffprobePath := filepath.Join(t.TempDir(), "ffprobe")
+	script := "#!/bin/sh\necho '{\"streams\":[{\"codec_type\":\"video\",\"codec_name\":\"h264\",\"width\":640,\"height\":360}],\"format\":{\"duration\":\"2\"}}'\n"
+	if err := os.WriteFile(ffprobePath, []byte(script), 0755); err != nil {
+		t.Fatalf("Failed to write fake ffprobe: %v", err)
+	}
+	processor := NewFFmpegVideoProcessorWithBinaries(t.TempDir(), writeFakeFFmpeg(t), ffprobePath)
+
+	var steps []string
+	result, err := processor.ProcessVideo(context.Background(), domain.ProcessingRequest{
+		JobID:     "job-1",
+		VideoPath: "video.mp4",
+		Options:   domain.FrameOptions{MaxFrames: 1},
+		OnProgress: func(progress domain.ProcessingProgress) {
+			steps = append(steps, fmt.Sprintf("%s:%d", progress.Step, progress.Frames))
+		},
+	})
+	if err != nil {
+		t.Fatalf("ProcessVideo failed: %v", err)
+	}
+	if strings.Join(steps, ",") != "extract:1,post_process:1,archive:1" {
+		t.Errorf("Expected every step reported with 1 frame, got %v", steps)
+	}
+	want := domain.VideoInfo{DurationSeconds: 2, Width: 640, Height: 360, Codec: "h264"}
+	if result.Video != want {
+		t.Errorf("Expected video %+v, got %+v", want, result.Video)
+	}
+}
+
+func TestFFmpegVideoProcessor_ProcessVideo_Decoders(t *testing.T) {
+	ffprobePath := filepath.Join(t.TempDir(), "ffprobe")
+	script := "#!/bin/sh\necho '{\"streams\":[{\"codec_type\":\"video\",\"codec_name\":\"prores\"}],\"format\":{\"duration\":\"2\"}}'\n"
+	if err := os.WriteFile(ffprobePath, []byte(script), 0755); err != nil {
+		t.Fatalf("Failed to write fake ffprobe: %v", err)
+	}
+
+	processor := NewFFmpegVideoProcessorWithBinaries(t.TempDir(), writeFakeFFmpeg(t), ffprobePath, WithDecoders(map[string]bool{"h264": true}))
+	_, err := processor.ProcessVideo(context.Background(), domain.ProcessingRequest{JobID: "job-1", VideoPath: "video.mov"})
+	if domain.ErrorCode(err) != domain.ErrorCodeUnsupportedCodec {
+		t.Fatalf("Expected unsupported_codec, got %v", err)
+	}
+	var failure *ffmpeg.Failure
+	if !strings.Contains(err.Error(), "prores") || !errors.As(err, &failure) || failure.Reason != ffmpeg.FailureUnsupportedCodec {
+		t.Errorf("Expected error naming the codec with reason unsupported_codec, got %v", err)
+	}
+
+	processor = NewFFmpegVideoProcessorWithBinaries(t.TempDir(), writeFakeFFmpeg(t), ffprobePath, WithDecoders(map[string]bool{"prores": true}))
+	if _, err := processor.ProcessVideo(context.Background(), domain.ProcessingRequest{JobID: "job-1", VideoPath: "video.mov"}); err != nil {
+		t.Errorf("Expected supported codec to be processed, got %v", err)
+	}
+}
+
+type recordingPostProcessor struct {
+	frames []string
+}
+
+func (r *recordingPostProcessor) PostProcess(ctx context.Context, framePaths []string, options domain.FrameOptions) error {
+	r.frames = append(r.frames, framePaths...)
+	return nil
+}
+
+func TestFFmpegVideoProcessor_ProcessVideo_PostProcessors(t *testing.T) {
+	postProcessor := &recordingPostProcessor{}
+	processor := NewFFmpegVideoProcessorWithBinaries(t.TempDir(), writeFakeFFmpeg(t), "", WithPostProcessors(postProcessor))
+
+	result, err := processor.ProcessVideo(context.Background(), domain.ProcessingRequest{JobID: "job-1", VideoPath: "video.mp4"})
+	if err != nil {
+		t.Fatalf("ProcessVideo failed: %v", err)
+	}
+	if len(postProcessor.frames) != result.FrameCount {
+		t.Errorf("Expected the post-processor to see %d frames, got %v", result.FrameCount, postProcessor.frames)
+	}
+}
+
+func TestFFmpegVideoProcessor_ProcessVideo_OCR(t *testing.T) {
+	options := domain.FrameOptions{FPS: 2, OCR: domain.OCROptions{Enabled: true}}
+	if _, err := NewFFmpegVideoProcessorWithBinaries(t.TempDir(), writeFakeFFmpeg(t), "").ProcessVideo(context.Background(), domain.ProcessingRequest{JobID: "job-1", VideoPath: "video.mp4", Options: options}); err == nil || !strings.Contains(err.Error(), "ocr is not enabled") {
+		t.Errorf("Expected an error without a frame analyzer, got %v", err)
+	}
+
+	analyzer := &stubFrameAnalyzer{texts: map[string]string{"frame_0002.png": "Chapter 2"}}
+	processor := NewFFmpegVideoProcessorWithBinaries(t.TempDir(), writeFakeFFmpeg(t), "", WithFrameAnalyzer(analyzer))
+	result, err := processor.ProcessVideo(context.Background(), domain.ProcessingRequest{JobID: "job-1", VideoPath: "video.mp4", Options: options})
+	if err != nil {
+		t.Fatalf("ProcessVideo failed: %v", err)
+	}
+
+	reader, err := zip.OpenReader(result.Artifacts[0])
+	if err != nil {
+		t.Fatalf("Failed to open zip: %v", err)
+	}
+	defer reader.Close()
+	for _, file := range reader.File {
+		if file.Name != domain.FramesManifest {
+			continue
+		}
+		content, _ := file.Open()
+		data, _ := io.ReadAll(content)
+		content.Close()
+		if !strings.Contains(string(data), `"time": 0.5,`) || !strings.Contains(string(data), `"text": "Chapter 2"`) {
+			t.Errorf("Expected the text of the second frame at 0.5s, got %s", data)
+		}
+		return
+	}
+	t.Error("Expected a frames manifest in the zip")
+}
+
+func TestFFmpegVideoProcessor_ProbeVideo(t *testing.T) {
+	ffprobe := writeScript(t, "ffprobe", `echo '{"streams":[{"index":0,"codec_type":"video","width":1920,"height":1080,"tags":{"rotate":"90"}}],"format":{"duration":"12.5"}}'`+"\n")
+	processor := NewFFmpegVideoProcessorWithBinaries(t.TempDir(), "", ffprobe)
+
+	info, err := processor.ProbeVideo(context.Background(), "video.mp4")
+	if err != nil {
+		t.Fatalf("ProbeVideo failed: %v", err)
+	}
+	want := domain.VideoInfo{DurationSeconds: 12.5, Width: 1920, Height: 1080, Rotation: 90}
+	if info != want {
+		t.Errorf("Expected %+v, got %+v", want, info)
+	}
+}
@@ -0,0 +1,131 @@
+package adapter
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/port"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/ffmpeg"
+)
+
+var (
+	blackIntervalPattern = regexp.MustCompile(`black_start:\s*([\d.]+)\s+black_end:\s*([\d.]+)`)
+	silenceStartPattern  = regexp.MustCompile(`silence_start:\s*(-?[\d.]+)`)
+	silenceEndPattern    = regexp.MustCompile(`silence_end:\s*([\d.]+)`)
+)
+
+type FFmpegQualityAnalyzer struct {
+	ffmpegPath  string
+	ffprobePath string
+}
+
+// NewFFmpegQualityAnalyzer detects black frames and silence with ffmpeg; empty binary paths
+// fall back to PATH
+func NewFFmpegQualityAnalyzer(ffmpegPath, ffprobePath string) port.QualityAnalyzerPort {
+	return &FFmpegQualityAnalyzer{
+		ffmpegPath:  ffmpegPath,
+		ffprobePath: ffprobePath,
+	}
+}
+
+func (a *FFmpegQualityAnalyzer) ffmpegBinary() string {
+	if a.ffmpegPath == "" {
+		return "ffmpeg"
+	}
+	return a.ffmpegPath
+}
+
+func (a *FFmpegQualityAnalyzer) ffprobeBinary() string {
+	if a.ffprobePath == "" {
+		return "ffprobe"
+	}
+	return a.ffprobePath
+}
+
+// AnalyzeQuality decodes the video once with blackdetect and silencedetect, discarding the
+// output. Only the filters of the streams the video has are run
+func (a *FFmpegQualityAnalyzer) AnalyzeQuality(ctx context.Context, videoPath string, options domain.QCOptions) (domain.QCReport, error) {
+	options = options.WithDefaults()
+
+	probe, err := ffmpeg.Probe(ctx, a.ffprobeBinary(), videoPath)
+	if err != nil {
+		return domain.QCReport{}, err
+	}
+	_, video := probe.VideoStream()
+	report := domain.QCReport{
+		DurationSeconds: probe.Duration(),
+		HasAudio:        len(probe.StreamsOfType(ffmpeg.CodecTypeAudio)) > 0,
+	}
+	if !video && !report.HasAudio {
+		return domain.QCReport{}, fmt.Errorf("video has no video or audio stream to analyze")
+	}
+
+	output, err := ffmpeg.CombinedOutput(ctx, ffmpeg.DefaultKillGrace, a.ffmpegBinary(), qualityArgs(videoPath, options, video, report.HasAudio)...)
+	if err != nil {
+		return domain.QCReport{}, fmt.Errorf("ffmpeg error: %w, output: %s", err, string(output))
+	}
+
+	report.BlackIntervals, report.SilenceIntervals = parseQualityIntervals(output, report.DurationSeconds)
+	if len(report.BlackIntervals) > domain.MaxQCIntervals {
+		report.BlackIntervals = report.BlackIntervals[:domain.MaxQCIntervals]
+		report.Truncated = true
+	}
+	if len(report.SilenceIntervals) > domain.MaxQCIntervals {
+		report.SilenceIntervals = report.SilenceIntervals[:domain.MaxQCIntervals]
+		report.Truncated = true
+	}
+	return report, nil
+}
+
+// qualityArgs run the detection filters into the null muxer, which logs the intervals
+func qualityArgs(videoPath string, options domain.QCOptions, video, audio bool) []string {
+	args := []string{"-hide_banner", "-nostats", "-i", videoPath}
+	if video {
+		args = append(args, "-map", "0:v:0", "-vf", fmt.Sprintf("blackdetect=d=%s:pix_th=%s",
+			formatSeconds(options.BlackMinSeconds), formatSeconds(options.BlackPixelThreshold)))
+	}
+	if audio {
+		args = append(args, "-map", "0:a:0", "-af", fmt.Sprintf("silencedetect=n=%sdB:d=%s",
+			formatSeconds(options.SilenceNoiseDB), formatSeconds(options.SilenceMinSeconds)))
+	}
+	return append(args, "-f", "null", "-")
+}
+
+// parseQualityIntervals reads the blackdetect and silencedetect lines of the ffmpeg output. A
+// silence still open at the end of the video ends with it
+func parseQualityIntervals(output []byte, duration float64) (black, silence []domain.TimeWindow) {
+	black, silence = []domain.TimeWindow{}, []domain.TimeWindow{}
+	silenceStart := -1.0
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if match := blackIntervalPattern.FindStringSubmatch(line); match != nil {
+			black = append(black, domain.TimeWindow{Start: parseSeconds(match[1]), End: parseSeconds(match[2])})
+			continue
+		}
+		if match := silenceStartPattern.FindStringSubmatch(line); match != nil {
+			// silencedetect reports a silence at the start slightly before zero
+			silenceStart = max(parseSeconds(match[1]), 0)
+			continue
+		}
+		if match := silenceEndPattern.FindStringSubmatch(line); match != nil && silenceStart >= 0 {
+			silence = append(silence, domain.TimeWindow{Start: silenceStart, End: parseSeconds(match[1])})
+			silenceStart = -1
+		}
+	}
+	if silenceStart >= 0 && duration > silenceStart {
+		silence = append(silence, domain.TimeWindow{Start: silenceStart, End: duration})
+	}
+	return black, silence
+}
+
+func parseSeconds(value string) float64 {
+	seconds, _ := strconv.ParseFloat(value, 64)
+	return seconds
+}
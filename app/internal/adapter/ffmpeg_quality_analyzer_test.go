@@ -0,0 +1,103 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+)
+
+const qualityOutput = `Input #0, mov,mp4,m4a,3gp,3g2,mj2, from 'video.mp4':
+[blackdetect @ 0x55d1] black_start:0 black_end:2.5 black_duration:2.5
+[silencedetect @ 0x55d2] silence_start: -0.00133333
+[silencedetect @ 0x55d2] silence_end: 3.2 | silence_duration: 3.20133
+[blackdetect @ 0x55d1] black_start:40.04 black_end:43.1 black_duration:3.06
+[silencedetect @ 0x55d2] silence_start: 55.5
+`
+
+func TestParseQualityIntervals(t *testing.T) {
+	black, silence := parseQualityIntervals([]byte(qualityOutput), 60)
+
+	expectedBlack := []domain.TimeWindow{{Start: 0, End: 2.5}, {Start: 40.04, End: 43.1}}
+	if !reflect.DeepEqual(black, expectedBlack) {
+		t.Errorf("Expected %v, got %v", expectedBlack, black)
+	}
+	// The silence still open at the end of the output lasts until the end of the video
+	expectedSilence := []domain.TimeWindow{{Start: 0, End: 3.2}, {Start: 55.5, End: 60}}
+	if !reflect.DeepEqual(silence, expectedSilence) {
+		t.Errorf("Expected %v, got %v", expectedSilence, silence)
+	}
+
+	black, silence = parseQualityIntervals([]byte("no intervals\n"), 60)
+	if black == nil || silence == nil || len(black)+len(silence) != 0 {
+		t.Errorf("Expected empty lists, got %v and %v", black, silence)
+	}
+}
+
+func TestQualityArgs(t *testing.T) {
+	args := qualityArgs("video.mp4", domain.DefaultQCOptions, true, true)
+	if argValue(args, "-vf") != "blackdetect=d=2:pix_th=0.1" {
+		t.Errorf("Unexpected video filter: %v", args)
+	}
+	if argValue(args, "-af") != "silencedetect=n=-50dB:d=2" {
+		t.Errorf("Unexpected audio filter: %v", args)
+	}
+	if argValue(args, "-f") != "null" || args[len(args)-1] != "-" {
+		t.Errorf("Expected the null muxer, got %v", args)
+	}
+
+	args = qualityArgs("video.mp4", domain.DefaultQCOptions, true, false)
+	if strings.Contains(strings.Join(args, " "), "silencedetect") {
+		t.Errorf("Expected no silence detection without audio, got %v", args)
+	}
+}
+
+func TestFFmpegQualityAnalyzer_AnalyzeQuality(t *testing.T) {
+	ffprobe := writeScript(t, "ffprobe", "echo '{\"streams\":[{\"codec_type\":\"video\"},{\"codec_type\":\"audio\"}],\"format\":{\"duration\":\"60.0\"}}'\n")
+	ffmpeg := writeScript(t, "ffmpeg", "cat >&2 <<'EOF'\n"+qualityOutput+"EOF\n")
+
+	analyzer := NewFFmpegQualityAnalyzer(ffmpeg, ffprobe)
+	report, err := analyzer.AnalyzeQuality(context.Background(), "video.mp4", domain.QCOptions{})
+	if err != nil {
+		t.Fatalf("AnalyzeQuality failed: %v", err)
+	}
+
+	if report.DurationSeconds != 60 || !report.HasAudio {
+		t.Errorf("Expected the probed duration and audio, got %+v", report)
+	}
+	if len(report.BlackIntervals) != 2 || len(report.SilenceIntervals) != 2 || report.Truncated {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+}
+
+func TestFFmpegQualityAnalyzer_AnalyzeQuality_Truncated(t *testing.T) {
+	ffprobe := writeScript(t, "ffprobe", "echo '{\"streams\":[{\"codec_type\":\"video\"}],\"format\":{\"duration\":\"3600.0\"}}'\n")
+	var lines strings.Builder
+	for i := 0; i <= domain.MaxQCIntervals; i++ {
+		fmt.Fprintf(&lines, "black_start:%d black_end:%d.5\n", i*2, i*2)
+	}
+	ffmpeg := writeScript(t, "ffmpeg", "cat >&2 <<'EOF'\n"+lines.String()+"EOF\n")
+
+	report, err := NewFFmpegQualityAnalyzer(ffmpeg, ffprobe).AnalyzeQuality(context.Background(), "video.mp4", domain.QCOptions{})
+	if err != nil {
+		t.Fatalf("AnalyzeQuality failed: %v", err)
+	}
+	if len(report.BlackIntervals) != domain.MaxQCIntervals || !report.Truncated {
+		t.Errorf("Expected %d intervals and a truncated report, got %d", domain.MaxQCIntervals, len(report.BlackIntervals))
+	}
+	if report.HasAudio || len(report.SilenceIntervals) != 0 {
+		t.Errorf("Expected no audio, got %+v", report.SilenceIntervals)
+	}
+}
+
+func TestFFmpegQualityAnalyzer_AnalyzeQuality_NoStreams(t *testing.T) {
+	ffprobe := writeScript(t, "ffprobe", "echo '{\"streams\":[]}'\n")
+	ffmpeg := writeScript(t, "ffmpeg", "exit 0\n")
+
+	if _, err := NewFFmpegQualityAnalyzer(ffmpeg, ffprobe).AnalyzeQuality(context.Background(), "video.mp4", domain.QCOptions{}); err == nil {
+		t.Error("Expected error for a video without streams")
+	}
+}
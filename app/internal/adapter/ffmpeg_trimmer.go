@@ -0,0 +1,78 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/port"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/ffmpeg"
+)
+
+type FFmpegTrimmer struct {
+	tempDir    string
+	ffmpegPath string
+}
+
+// NewFFmpegTrimmer cuts a range of a video into an MP4; an empty binary path falls back to PATH
+func NewFFmpegTrimmer(tempDir, ffmpegPath string) port.VideoTrimmerPort {
+	if tempDir == "" {
+		tempDir = "temp"
+	}
+
+	os.MkdirAll(tempDir, 0777)
+	return &FFmpegTrimmer{
+		tempDir:    tempDir,
+		ffmpegPath: ffmpegPath,
+	}
+}
+
+func (t *FFmpegTrimmer) ffmpegBinary() string {
+	if t.ffmpegPath == "" {
+		return "ffmpeg"
+	}
+	return t.ffmpegPath
+}
+
+// Trim writes the range of the video into trim_{jobID}.mp4, which the caller uploads and
+// removes. A range starting after the end of the video leaves ffmpeg with nothing to write,
+// which is reported as an error
+func (t *FFmpegTrimmer) Trim(ctx context.Context, jobID, videoPath string, options domain.TrimOptions) (string, error) {
+	jobID = sanitizeJobID(jobID)
+	if jobID == "" {
+		return "", fmt.Errorf("job id is required")
+	}
+
+	outputPath := filepath.Join(t.tempDir, "trim_"+jobID+".mp4")
+	output, err := ffmpeg.CombinedOutput(ctx, ffmpeg.DefaultKillGrace, t.ffmpegBinary(), trimArgs(videoPath, outputPath, options)...)
+	if err != nil {
+		os.Remove(outputPath)
+		return "", fmt.Errorf("ffmpeg error: %w, output: %s", err, string(output))
+	}
+
+	if stat, err := os.Stat(outputPath); err != nil || stat.Size() == 0 {
+		os.Remove(outputPath)
+		return "", fmt.Errorf("trimmed video is empty, the range may start after the end of the video")
+	}
+	return outputPath, nil
+}
+
+// trimArgs seeks on the input side, so only the range is read, and keeps the first video and
+// audio streams. The copy mode shifts the timestamps to start at zero, as the cut starts on
+// a keyframe before the requested start
+func trimArgs(videoPath, outputPath string, options domain.TrimOptions) []string {
+	args := []string{"-ss", formatSeconds(options.Start), "-i", videoPath}
+	if options.End > 0 {
+		args = append(args, "-t", formatSeconds(options.End-options.Start))
+	}
+	args = append(args, "-map", "0:v:0", "-map", "0:a:0?")
+
+	if options.Mode == domain.EditModeReencode {
+		args = append(args, "-c:v", "libx264", "-c:a", "aac")
+	} else {
+		args = append(args, "-c", "copy", "-avoid_negative_ts", "make_zero")
+	}
+	return append(args, "-movflags", "+faststart", "-y", outputPath)
+}
@@ -0,0 +1,62 @@
+package adapter
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+)
+
+func TestTrimArgs(t *testing.T) {
+	args := trimArgs("video.mp4", "out.mp4", domain.TrimOptions{Start: 10, End: 25.5})
+	joined := strings.Join(args, " ")
+
+	if !strings.HasPrefix(joined, "-ss 10 -i video.mp4 -t 15.5 ") {
+		t.Errorf("Expected an input seek with the range duration, got %s", joined)
+	}
+	if argValue(args, "-c") != "copy" || argValue(args, "-avoid_negative_ts") != "make_zero" {
+		t.Errorf("Expected stream copy by default, got %s", joined)
+	}
+	if args[len(args)-1] != "out.mp4" {
+		t.Errorf("Unexpected output path: %s", args[len(args)-1])
+	}
+
+	args = trimArgs("video.mp4", "out.mp4", domain.TrimOptions{Start: 10, Mode: domain.EditModeReencode})
+	if argValue(args, "-t") != "" {
+		t.Errorf("Expected no duration for an open range, got %v", args)
+	}
+	if argValue(args, "-c:v") != "libx264" || argValue(args, "-c") != "" {
+		t.Errorf("Expected re-encoding, got %v", args)
+	}
+}
+
+func TestFFmpegTrimmer_Trim(t *testing.T) {
+	tempDir := t.TempDir()
+	ffmpeg := writeScript(t, "ffmpeg", "for last; do :; done\necho trimmed > \"$last\"\n")
+
+	trimmer := NewFFmpegTrimmer(tempDir, ffmpeg)
+	videoPath, err := trimmer.Trim(context.Background(), "job-1", "video.mp4", domain.TrimOptions{Start: 1})
+	if err != nil {
+		t.Fatalf("Trim failed: %v", err)
+	}
+	if videoPath != filepath.Join(tempDir, "trim_job-1.mp4") {
+		t.Errorf("Expected video keyed by job id, got %s", videoPath)
+	}
+}
+
+func TestFFmpegTrimmer_Trim_EmptyOutput(t *testing.T) {
+	tempDir := t.TempDir()
+	// ffmpeg exits cleanly without writing frames when the start is past the end
+	ffmpeg := writeScript(t, "ffmpeg", "for last; do :; done\n: > \"$last\"\n")
+
+	trimmer := NewFFmpegTrimmer(tempDir, ffmpeg)
+	if _, err := trimmer.Trim(context.Background(), "job-1", "video.mp4", domain.TrimOptions{Start: 3600}); err == nil {
+		t.Fatal("Expected error for an empty trimmed video")
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "trim_job-1.mp4")); !os.IsNotExist(err) {
+		t.Error("Expected empty output to be removed")
+	}
+}
@@ -0,0 +1,134 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/port"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/ffmpeg"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/vision"
+)
+
+// visionMaxWidth bounds the width of the frames sent to the vision API, keeping each JPEG far
+// below the 5MB accepted inline
+const visionMaxWidth = 1280
+
+type FFmpegVisionAnalyzer struct {
+	tempDir    string
+	ffmpegPath string
+	service    vision.VisionService
+}
+
+// NewFFmpegVisionAnalyzer samples frames with ffmpeg and sends them to service; an empty binary
+// path falls back to PATH
+func NewFFmpegVisionAnalyzer(tempDir, ffmpegPath string, service vision.VisionService) port.VisionAnalyzerPort {
+	if tempDir == "" {
+		tempDir = "temp"
+	}
+
+	os.MkdirAll(tempDir, 0777)
+	return &FFmpegVisionAnalyzer{
+		tempDir:    tempDir,
+		ffmpegPath: ffmpegPath,
+		service:    service,
+	}
+}
+
+func (a *FFmpegVisionAnalyzer) ffmpegBinary() string {
+	if a.ffmpegPath == "" {
+		return "ffmpeg"
+	}
+	return a.ffmpegPath
+}
+
+// AnalyzeVision samples one frame every IntervalSeconds, up to MaxFrames, into vision_{jobID},
+// removed on return, and sends each frame once per requested feature
+func (a *FFmpegVisionAnalyzer) AnalyzeVision(ctx context.Context, jobID, videoPath string, config domain.VisionConfig) (domain.VisionReport, error) {
+	jobID = sanitizeJobID(jobID)
+	if jobID == "" {
+		return domain.VisionReport{}, fmt.Errorf("job id is required")
+	}
+	config = config.WithDefaults()
+
+	frameDir := filepath.Join(a.tempDir, "vision_"+jobID)
+	if err := os.MkdirAll(frameDir, 0777); err != nil {
+		return domain.VisionReport{}, fmt.Errorf("failed to create frame directory: %w", err)
+	}
+	defer os.RemoveAll(frameDir)
+
+	output, err := ffmpeg.CombinedOutput(ctx, ffmpeg.DefaultKillGrace, a.ffmpegBinary(), visionArgs(videoPath, frameDir, config)...)
+	if err != nil {
+		return domain.VisionReport{}, fmt.Errorf("ffmpeg error: %w, output: %s", err, string(output))
+	}
+
+	frames, err := filepath.Glob(filepath.Join(frameDir, "frame_*.jpg"))
+	if err != nil {
+		return domain.VisionReport{}, err
+	}
+	if len(frames) == 0 {
+		return domain.VisionReport{}, fmt.Errorf("no frames sampled from the video")
+	}
+	sort.Strings(frames)
+
+	report := domain.VisionReport{Frames: make([]domain.VisionFrame, 0, len(frames))}
+	for i, frame := range frames {
+		image, err := os.ReadFile(frame)
+		if err != nil {
+			return domain.VisionReport{}, fmt.Errorf("failed to read frame %s: %w", filepath.Base(frame), err)
+		}
+		// The fps filter emits the frame of time i*interval as the i-th one
+		result := domain.VisionFrame{Time: math.Round(float64(i)*config.IntervalSeconds*1000) / 1000}
+
+		if config.HasFeature(domain.VisionFeatureLabels) {
+			labels, err := a.service.DetectLabels(ctx, image, config.MinConfidence)
+			if err != nil {
+				return domain.VisionReport{}, err
+			}
+			result.Labels = visionLabels(labels)
+		}
+		if config.HasFeature(domain.VisionFeatureModeration) {
+			labels, err := a.service.DetectModeration(ctx, image, config.MinConfidence)
+			if err != nil {
+				return domain.VisionReport{}, err
+			}
+			result.Moderation = visionLabels(labels)
+			report.Flagged = report.Flagged || len(labels) > 0
+		}
+		report.Frames = append(report.Frames, result)
+	}
+	return report, nil
+}
+
+// visionArgs samples one JPEG per interval of the first video stream, at most visionMaxWidth
+// wide, as frame_0001.jpg and on
+func visionArgs(videoPath, frameDir string, config domain.VisionConfig) []string {
+	return []string{
+		"-hide_banner", "-nostats",
+		"-i", videoPath,
+		"-map", "0:v:0",
+		"-vf", fmt.Sprintf("fps=1/%s,scale='min(iw,%d)':-2", formatSeconds(config.IntervalSeconds), visionMaxWidth),
+		"-frames:v", strconv.Itoa(config.MaxFrames),
+		"-q:v", "3",
+		"-y",
+		filepath.Join(frameDir, "frame_%04d.jpg"),
+	}
+}
+
+// visionLabels keeps the confidence to two decimals, as the API returns float32 noise past it
+func visionLabels(labels []vision.Label) []domain.VisionLabel {
+	result := make([]domain.VisionLabel, len(labels))
+	for i, label := range labels {
+		result[i] = domain.VisionLabel{
+			Name:       label.Name,
+			Parent:     label.Parent,
+			Confidence: math.Round(label.Confidence*100) / 100,
+		}
+	}
+	return result
+}
@@ -0,0 +1,105 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/vision"
+)
+
+// writeFakeVisionFFmpeg writes count JPEG files named by the output pattern, the last argument
+func writeFakeVisionFFmpeg(t *testing.T, count int) string {
+	t.Helper()
+	return writeScript(t, "ffmpeg", "for last; do :; done\ni=1\nwhile [ $i -le "+strconv.Itoa(count)+" ]; do printf 'jpeg%d' $i > \"$(printf \"$last\" $i)\"; i=$((i+1)); done\n")
+}
+
+func TestVisionArgs(t *testing.T) {
+	args := visionArgs("video.mp4", "frames", domain.VisionConfig{IntervalSeconds: 2.5, MaxFrames: 8})
+
+	if filter := argValue(args, "-vf"); filter != "fps=1/2.5,scale='min(iw,1280)':-2" {
+		t.Errorf("Unexpected filter: %s", filter)
+	}
+	if argValue(args, "-frames:v") != "8" || args[len(args)-1] != filepath.Join("frames", "frame_%04d.jpg") {
+		t.Errorf("Unexpected args: %v", args)
+	}
+}
+
+func TestFFmpegVisionAnalyzer_AnalyzeVision(t *testing.T) {
+	var images []string
+	service := &vision.MockVisionService{
+		DetectLabelsFunc: func(ctx context.Context, image []byte, minConfidence float64) ([]vision.Label, error) {
+			images = append(images, string(image))
+			return []vision.Label{{Name: "Car", Parent: "Vehicle", Confidence: 98.123456}}, nil
+		},
+		DetectModerationFunc: func(ctx context.Context, image []byte, minConfidence float64) ([]vision.Label, error) {
+			if string(image) == "jpeg2" {
+				return []vision.Label{{Name: "Weapons", Confidence: 80}}, nil
+			}
+			return nil, nil
+		},
+	}
+
+	tempDir := t.TempDir()
+	analyzer := NewFFmpegVisionAnalyzer(tempDir, writeFakeVisionFFmpeg(t, 3), service)
+	config := domain.VisionConfig{Features: []string{domain.VisionFeatureLabels, domain.VisionFeatureModeration}}
+	report, err := analyzer.AnalyzeVision(context.Background(), "job-1", "video.mp4", config)
+	if err != nil {
+		t.Fatalf("AnalyzeVision failed: %v", err)
+	}
+
+	if strings.Join(images, ",") != "jpeg1,jpeg2,jpeg3" {
+		t.Errorf("Expected every frame sent in order, got %v", images)
+	}
+	if !report.Flagged || len(report.Frames) != 3 {
+		t.Fatalf("Expected 3 frames flagged by moderation, got %+v", report)
+	}
+	if report.Frames[1].Time != 10 || report.Frames[1].Labels[0] != (domain.VisionLabel{Name: "Car", Parent: "Vehicle", Confidence: 98.12}) {
+		t.Errorf("Unexpected second frame %+v", report.Frames[1])
+	}
+	if len(report.Frames[1].Moderation) != 1 || len(report.Frames[0].Moderation) != 0 {
+		t.Errorf("Expected moderation labels on the second frame only, got %+v", report.Frames)
+	}
+	if _, err := os.Stat(filepath.Join(tempDir, "vision_job-1")); !os.IsNotExist(err) {
+		t.Error("Expected frames to be removed")
+	}
+}
+
+func TestFFmpegVisionAnalyzer_AnalyzeVision_LabelsOnly(t *testing.T) {
+	service := &vision.MockVisionService{
+		DetectModerationFunc: func(ctx context.Context, image []byte, minConfidence float64) ([]vision.Label, error) {
+			t.Error("Expected no moderation call without the feature")
+			return nil, nil
+		},
+	}
+
+	report, err := NewFFmpegVisionAnalyzer(t.TempDir(), writeFakeVisionFFmpeg(t, 1), service).AnalyzeVision(context.Background(), "job-1", "video.mp4", domain.VisionConfig{Features: []string{domain.VisionFeatureLabels}})
+	if err != nil {
+		t.Fatalf("AnalyzeVision failed: %v", err)
+	}
+	if report.Flagged || len(report.Frames) != 1 {
+		t.Errorf("Unexpected report %+v", report)
+	}
+}
+
+func TestFFmpegVisionAnalyzer_AnalyzeVision_Errors(t *testing.T) {
+	config := domain.VisionConfig{Features: []string{domain.VisionFeatureLabels}}
+
+	if _, err := NewFFmpegVisionAnalyzer(t.TempDir(), writeScript(t, "ffmpeg", "exit 0\n"), &vision.MockVisionService{}).AnalyzeVision(context.Background(), "job-1", "video.mp4", config); err == nil || !strings.Contains(err.Error(), "no frames") {
+		t.Errorf("Expected no frames error, got %v", err)
+	}
+
+	service := &vision.MockVisionService{
+		DetectLabelsFunc: func(ctx context.Context, image []byte, minConfidence float64) ([]vision.Label, error) {
+			return nil, errors.New("throttled")
+		},
+	}
+	if _, err := NewFFmpegVisionAnalyzer(t.TempDir(), writeFakeVisionFFmpeg(t, 1), service).AnalyzeVision(context.Background(), "job-1", "video.mp4", config); err == nil || !strings.Contains(err.Error(), "throttled") {
+		t.Errorf("Expected the vision API error, got %v", err)
+	}
+}
@@ -0,0 +1,116 @@
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/port"
+)
+
+// FileOutbox keeps each pending result message as a JSON file in dir. For entries to outlive
+// the pod, dir must be on a persistent volume
+type FileOutbox struct {
+	dir string
+}
+
+func NewFileOutbox(dir string) port.OutboxPort {
+	return &FileOutbox{
+		dir: dir,
+	}
+}
+
+// Save writes the entry to a temp file and renames it over the previous version, so a crash
+// never leaves a half written entry behind
+func (o *FileOutbox) Save(ctx context.Context, entry domain.OutboxEntry) error {
+	entryPath, err := o.entryPath(entry.ID)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(o.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create outbox directory: %w", err)
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode outbox entry: %w", err)
+	}
+
+	file, err := os.CreateTemp(o.dir, ".entry_*")
+	if err != nil {
+		return fmt.Errorf("failed to create outbox entry: %w", err)
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write outbox entry: %w", err)
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to sync outbox entry: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write outbox entry: %w", err)
+	}
+
+	if err := os.Rename(file.Name(), entryPath); err != nil {
+		return fmt.Errorf("failed to save outbox entry: %w", err)
+	}
+	return nil
+}
+
+// Pending returns every saved entry, oldest first
+func (o *FileOutbox) Pending(ctx context.Context) ([]domain.OutboxEntry, error) {
+	paths, err := filepath.Glob(filepath.Join(o.dir, "*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list outbox: %w", err)
+	}
+
+	entries := make([]domain.OutboxEntry, 0, len(paths))
+	for _, entryPath := range paths {
+		data, err := os.ReadFile(entryPath)
+		if errors.Is(err, os.ErrNotExist) {
+			// Delivered and deleted since the listing
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read outbox entry: %w", err)
+		}
+
+		var entry domain.OutboxEntry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			return nil, fmt.Errorf("invalid outbox entry %s: %w", filepath.Base(entryPath), err)
+		}
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].CreatedAt.Before(entries[j].CreatedAt)
+	})
+	return entries, nil
+}
+
+func (o *FileOutbox) Delete(ctx context.Context, id string) error {
+	entryPath, err := o.entryPath(id)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(entryPath); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to delete outbox entry: %w", err)
+	}
+	return nil
+}
+
+func (o *FileOutbox) entryPath(id string) (string, error) {
+	if id == "" || strings.ContainsAny(id, `/\`) || strings.HasPrefix(id, ".") {
+		return "", fmt.Errorf("invalid outbox entry id %q", id)
+	}
+	return filepath.Join(o.dir, id+".json"), nil
+}
@@ -0,0 +1,82 @@
+package adapter
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+)
+
+func TestFileOutbox_SavePendingDelete(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "outbox")
+	outbox := NewFileOutbox(dir)
+	ctx := context.Background()
+	now := time.Now()
+
+	newer := domain.OutboxEntry{ID: "b", ProcessID: "p2", Body: `{"process_id":"p2"}`, CreatedAt: now}
+	older := domain.OutboxEntry{
+		ID:         "a",
+		ProcessID:  "p1",
+		Body:       `{"process_id":"p1"}`,
+		Attributes: map[string]string{"signature": "sig"},
+		CreatedAt:  now.Add(-time.Minute),
+	}
+	for _, entry := range []domain.OutboxEntry{newer, older} {
+		if err := outbox.Save(ctx, entry); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+	}
+
+	// Saving again replaces the entry
+	older.Attempts = 3
+	if err := outbox.Save(ctx, older); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	entries, err := outbox.Pending(ctx)
+	if err != nil {
+		t.Fatalf("Pending failed: %v", err)
+	}
+	if len(entries) != 2 || entries[0].ID != "a" || entries[1].ID != "b" {
+		t.Fatalf("Expected entries oldest first, got %+v", entries)
+	}
+	if entries[0].Attempts != 3 || entries[0].Attributes["signature"] != "sig" {
+		t.Errorf("Unexpected entry %+v", entries[0])
+	}
+
+	if err := outbox.Delete(ctx, "a"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if err := outbox.Delete(ctx, "a"); err != nil {
+		t.Errorf("Expected deleting a missing entry to succeed, got %v", err)
+	}
+
+	entries, err = outbox.Pending(ctx)
+	if err != nil || len(entries) != 1 || entries[0].ID != "b" {
+		t.Errorf("Expected only entry b left, got %+v, %v", entries, err)
+	}
+
+	files, _ := os.ReadDir(dir)
+	if len(files) != 1 {
+		t.Errorf("Expected no temp files left behind, got %d files", len(files))
+	}
+}
+
+func TestFileOutbox_EmptyDir(t *testing.T) {
+	entries, err := NewFileOutbox(filepath.Join(t.TempDir(), "missing")).Pending(context.Background())
+	if err != nil || len(entries) != 0 {
+		t.Errorf("Expected no entries, got %+v, %v", entries, err)
+	}
+}
+
+func TestFileOutbox_InvalidID(t *testing.T) {
+	outbox := NewFileOutbox(t.TempDir())
+	for _, id := range []string{"", "../escape", ".hidden"} {
+		if err := outbox.Save(context.Background(), domain.OutboxEntry{ID: id}); err == nil {
+			t.Errorf("Expected error for id %q", id)
+		}
+	}
+}
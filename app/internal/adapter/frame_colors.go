@@ -0,0 +1,184 @@
+package adapter
+
+import (
+	"cmp"
+	"fmt"
+	"image"
+	"image/draw"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+)
+
+const (
+	// histogramBins is the number of bins of each channel histogram
+	histogramBins = 16
+
+	// colorSamples bounds the pixels read per frame; larger frames are sampled on a grid
+	colorSamples = 1 << 16
+
+	// dominantBits is the precision of each channel when grouping pixels into colors, so close
+	// shades count as one dominant color
+	dominantBits = 3
+)
+
+// colorSummary is the color analysis of a frame or of all the frames
+type colorSummary struct {
+	Histogram colorHistogram  `json:"histogram"`
+	Dominant  []dominantColor `json:"dominant_colors"`
+}
+
+// colorHistogram holds the share of the pixels in each bin of each channel
+type colorHistogram struct {
+	R []float64 `json:"r"`
+	G []float64 `json:"g"`
+	B []float64 `json:"b"`
+}
+
+type dominantColor struct {
+	Color string  `json:"color"`
+	Share float64 `json:"share"`
+}
+
+// colorCounts accumulates the pixels of one or more frames
+type colorCounts struct {
+	pixels   int
+	channels [3][histogramBins]int
+	groups   [1 << (3 * dominantBits)]colorGroup
+}
+
+// colorGroup sums the pixels of one dominant color candidate, whose color is their mean
+type colorGroup struct {
+	count   int
+	r, g, b int
+}
+
+// addColors analyzes the frames into the manifest, one summary per frame entry or a single one
+// for all of them
+func (m *framesManifest) addColors(frames []string, options domain.ColorOptions) error {
+	var total colorCounts
+	for i, frame := range frames {
+		var counts colorCounts
+		if err := counts.addFrame(frame); err != nil {
+			return err
+		}
+		if options.Scope == domain.ColorScopeFrame {
+			m.Frames[i].Colors = counts.summary(options.DominantCount())
+		} else {
+			total.merge(&counts)
+		}
+	}
+	if options.Scope == domain.ColorScopeAggregate {
+		m.Colors = total.summary(options.DominantCount())
+	}
+	return nil
+}
+
+func (c *colorCounts) addFrame(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return fmt.Errorf("failed to decode frame %s: %w", filepath.Base(path), err)
+	}
+	c.add(img)
+	return nil
+}
+
+// add counts the pixels of img, one every step in both directions when it is larger than
+// colorSamples
+func (c *colorCounts) add(img image.Image) {
+	bounds := img.Bounds()
+	rgba := image.NewRGBA(bounds)
+	draw.Draw(rgba, bounds, img, bounds.Min, draw.Src)
+
+	width, height := bounds.Dx(), bounds.Dy()
+	step := max(1, int(math.Ceil(math.Sqrt(float64(width*height)/colorSamples))))
+	for y := 0; y < height; y += step {
+		for x := 0; x < width; x += step {
+			i := y*rgba.Stride + x*4
+			r, g, b := int(rgba.Pix[i]), int(rgba.Pix[i+1]), int(rgba.Pix[i+2])
+
+			c.pixels++
+			c.channels[0][r*histogramBins/256]++
+			c.channels[1][g*histogramBins/256]++
+			c.channels[2][b*histogramBins/256]++
+
+			shift := 8 - dominantBits
+			group := &c.groups[(r>>shift)<<(2*dominantBits)|(g>>shift)<<dominantBits|b>>shift]
+			group.count++
+			group.r += r
+			group.g += g
+			group.b += b
+		}
+	}
+}
+
+func (c *colorCounts) merge(other *colorCounts) {
+	c.pixels += other.pixels
+	for channel := range c.channels {
+		for bin := range c.channels[channel] {
+			c.channels[channel][bin] += other.channels[channel][bin]
+		}
+	}
+	for i := range c.groups {
+		c.groups[i].count += other.groups[i].count
+		c.groups[i].r += other.groups[i].r
+		c.groups[i].g += other.groups[i].g
+		c.groups[i].b += other.groups[i].b
+	}
+}
+
+// summary normalizes the counts into shares and picks the dominant colors, the most common
+// groups first
+func (c *colorCounts) summary(dominant int) *colorSummary {
+	summary := &colorSummary{
+		Histogram: colorHistogram{
+			R: c.shares(c.channels[0][:]),
+			G: c.shares(c.channels[1][:]),
+			B: c.shares(c.channels[2][:]),
+		},
+		Dominant: []dominantColor{},
+	}
+
+	groups := make([]colorGroup, 0, len(c.groups))
+	for _, group := range c.groups {
+		if group.count > 0 {
+			groups = append(groups, group)
+		}
+	}
+	// Stable, so ties keep the order of the groups and the output is deterministic
+	slices.SortStableFunc(groups, func(a, b colorGroup) int {
+		return cmp.Compare(b.count, a.count)
+	})
+	for _, group := range groups[:min(dominant, len(groups))] {
+		summary.Dominant = append(summary.Dominant, dominantColor{
+			Color: fmt.Sprintf("#%02x%02x%02x", group.r/group.count, group.g/group.count, group.b/group.count),
+			Share: c.share(group.count),
+		})
+	}
+	return summary
+}
+
+func (c *colorCounts) shares(counts []int) []float64 {
+	shares := make([]float64, len(counts))
+	for i, count := range counts {
+		shares[i] = c.share(count)
+	}
+	return shares
+}
+
+// share is the fraction of the pixels counted, rounded to 4 decimals
+func (c *colorCounts) share(count int) float64 {
+	if c.pixels == 0 {
+		return 0
+	}
+	return math.Round(float64(count)/float64(c.pixels)*10000) / 10000
+}
@@ -0,0 +1,109 @@
+package adapter
+
+import (
+	"encoding/json"
+	"image"
+	"image/color"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+)
+
+// splitImage is red on its left three quarters and blue on the rest
+func splitImage() image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, 16, 16))
+	for y := 0; y < 16; y++ {
+		for x := 0; x < 16; x++ {
+			if x < 12 {
+				img.Set(x, y, color.RGBA{R: 250, A: 255})
+			} else {
+				img.Set(x, y, color.RGBA{B: 200, A: 255})
+			}
+		}
+	}
+	return img
+}
+
+func TestColorCounts_Summary(t *testing.T) {
+	var counts colorCounts
+	counts.add(splitImage())
+	summary := counts.summary(5)
+
+	if len(summary.Dominant) != 2 {
+		t.Fatalf("Expected 2 dominant colors, got %+v", summary.Dominant)
+	}
+	if summary.Dominant[0] != (dominantColor{Color: "#fa0000", Share: 0.75}) || summary.Dominant[1] != (dominantColor{Color: "#0000c8", Share: 0.25}) {
+		t.Errorf("Unexpected dominant colors %+v", summary.Dominant)
+	}
+	if len(summary.Histogram.R) != histogramBins || summary.Histogram.R[15] != 0.75 || summary.Histogram.R[0] != 0.25 || summary.Histogram.B[12] != 0.25 {
+		t.Errorf("Unexpected histogram %+v", summary.Histogram)
+	}
+
+	if dominant := counts.summary(1).Dominant; len(dominant) != 1 || dominant[0].Color != "#fa0000" {
+		t.Errorf("Expected only the most common color, got %+v", dominant)
+	}
+}
+
+func TestColorCounts_Add_Sampled(t *testing.T) {
+	var counts colorCounts
+	counts.add(image.NewRGBA(image.Rect(0, 0, 1024, 512)))
+
+	if counts.pixels > colorSamples {
+		t.Errorf("Expected at most %d samples, got %d", colorSamples, counts.pixels)
+	}
+	if summary := counts.summary(5); summary.Dominant[0] != (dominantColor{Color: "#000000", Share: 1}) {
+		t.Errorf("Unexpected dominant colors %+v", summary.Dominant)
+	}
+}
+
+func TestFramesManifest_AddColors(t *testing.T) {
+	dir := t.TempDir()
+	red := filepath.Join(dir, "frame_0001.png")
+	gray := filepath.Join(dir, "frame_0002.png")
+	writePNG(t, red, splitImage())
+	writePNG(t, gray, flatImage())
+	frames := []string{red, gray}
+
+	manifest := newFramesManifest(frames)
+	if err := manifest.addColors(frames, domain.ColorOptions{Scope: domain.ColorScopeFrame, Dominant: 1}); err != nil {
+		t.Fatalf("addColors failed: %v", err)
+	}
+	if manifest.Colors != nil || manifest.Frames[0].Colors.Dominant[0].Color != "#fa0000" || manifest.Frames[1].Colors.Dominant[0].Color != "#808080" {
+		t.Errorf("Unexpected per-frame colors %+v", manifest)
+	}
+
+	manifestPath, err := manifest.write(frames, dir)
+	if err != nil {
+		t.Fatalf("Failed to write manifest: %v", err)
+	}
+	content := readFile(t, manifestPath)
+	if strings.Contains(content, "sharpness") || strings.Contains(content, `"mode"`) {
+		t.Errorf("Expected no sharpness fields without scoring, got %s", content)
+	}
+
+	manifest = newFramesManifest(frames)
+	if err := manifest.addColors(frames, domain.ColorOptions{Scope: domain.ColorScopeAggregate}); err != nil {
+		t.Fatalf("addColors failed: %v", err)
+	}
+	if manifest.Frames[0].Colors != nil || len(manifest.Colors.Dominant) != 3 {
+		t.Fatalf("Expected a single summary of 3 colors, got %+v", manifest.Colors)
+	}
+	// The gray frame is half of the pixels
+	if manifest.Colors.Dominant[0] != (dominantColor{Color: "#808080", Share: 0.5}) {
+		t.Errorf("Unexpected aggregate colors %+v", manifest.Colors.Dominant)
+	}
+	if _, err := json.Marshal(manifest); err != nil {
+		t.Errorf("Failed to encode manifest: %v", err)
+	}
+}
+
+func TestFramesManifest_AddColors_InvalidFrame(t *testing.T) {
+	frame := filepath.Join(t.TempDir(), "frame_0001.png")
+	manifest := newFramesManifest([]string{frame})
+
+	if err := manifest.addColors([]string{frame}, domain.ColorOptions{Scope: domain.ColorScopeFrame}); err == nil {
+		t.Error("Expected error for a missing frame")
+	}
+}
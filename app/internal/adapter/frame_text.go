@@ -0,0 +1,30 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/port"
+)
+
+// addText sets the time and the text read by analyzer of every frame, so consumers can search
+// the text shown on screen and seek to it. timestamps are keyed as by frameTimestamps
+func (m *framesManifest) addText(ctx context.Context, analyzer port.FrameAnalyzerPort, frames []string, timestamps map[string]int64, options domain.OCROptions) error {
+	for i, frame := range frames {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		text, err := analyzer.ExtractText(ctx, frame, options)
+		if err != nil {
+			return fmt.Errorf("failed to read the text of frame %s: %w", filepath.Base(frame), err)
+		}
+
+		seconds := float64(timestamps[strings.TrimSuffix(frame, filepath.Ext(frame))]) / 1000
+		m.Frames[i].Time = &seconds
+		m.Frames[i].Text = &text
+	}
+	return nil
+}
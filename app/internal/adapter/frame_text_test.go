@@ -0,0 +1,57 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+)
+
+// stubFrameAnalyzer reads the text of a frame from texts, keyed by file name
+type stubFrameAnalyzer struct {
+	texts map[string]string
+	err   error
+}
+
+func (s *stubFrameAnalyzer) ExtractText(ctx context.Context, framePath string, options domain.OCROptions) (string, error) {
+	return s.texts[filepath.Base(framePath)], s.err
+}
+
+func TestFramesManifest_AddText(t *testing.T) {
+	frames := []string{"dir/frame_0001.png", "dir/frame_0002.png"}
+	timestamps := map[string]int64{"dir/frame_0001": 0, "dir/frame_0002": 1500}
+	analyzer := &stubFrameAnalyzer{texts: map[string]string{"frame_0002.png": "BREAKING NEWS"}}
+
+	manifest := newFramesManifest(frames)
+	if err := manifest.addText(context.Background(), analyzer, frames, timestamps, domain.OCROptions{Enabled: true}); err != nil {
+		t.Fatalf("addText failed: %v", err)
+	}
+	if *manifest.Frames[0].Time != 0 || *manifest.Frames[0].Text != "" {
+		t.Errorf("Expected an empty text at 0s, got %+v", manifest.Frames[0])
+	}
+	if *manifest.Frames[1].Time != 1.5 || *manifest.Frames[1].Text != "BREAKING NEWS" {
+		t.Errorf("Expected the text at 1.5s, got %+v", manifest.Frames[1])
+	}
+
+	manifestPath, err := manifest.write(frames, t.TempDir())
+	if err != nil {
+		t.Fatalf("Failed to write manifest: %v", err)
+	}
+	content := readFile(t, manifestPath)
+	if !strings.Contains(content, `"time": 0,`) || !strings.Contains(content, `"text": ""`) {
+		t.Errorf("Expected the time and text of frames without text, got %s", content)
+	}
+}
+
+func TestFramesManifest_AddText_AnalyzerError(t *testing.T) {
+	frames := []string{"dir/frame_0001.png"}
+	manifest := newFramesManifest(frames)
+
+	err := manifest.addText(context.Background(), &stubFrameAnalyzer{err: errors.New("no language data")}, frames, nil, domain.OCROptions{Enabled: true})
+	if err == nil || !strings.Contains(err.Error(), "frame_0001.png: no language data") {
+		t.Errorf("Expected the frame in the error, got %v", err)
+	}
+}
@@ -0,0 +1,57 @@
+package adapter
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+)
+
+// framesManifest describes the frames zip when sharpness scoring, the color analysis or the OCR
+// is on
+type framesManifest struct {
+	Mode      string        `json:"mode,omitempty"`
+	Threshold float64       `json:"threshold,omitempty"`
+	Dropped   int           `json:"dropped"`
+	Colors    *colorSummary `json:"colors,omitempty"`
+	Frames    []scoredFrame `json:"frames"`
+}
+
+type scoredFrame struct {
+	File      string        `json:"file"`
+	Time      *float64      `json:"time,omitempty"`
+	Sharpness *float64      `json:"sharpness,omitempty"`
+	Colors    *colorSummary `json:"colors,omitempty"`
+	// Text is empty on frames without text, and absent without OCR
+	Text *string `json:"text,omitempty"`
+}
+
+// newFramesManifest starts a manifest with one entry per frame, for the analyses that keep
+// every frame
+func newFramesManifest(frames []string) *framesManifest {
+	manifest := &framesManifest{Frames: make([]scoredFrame, len(frames))}
+	for i, frame := range frames {
+		manifest.Frames[i].File = filepath.Base(frame)
+	}
+	return manifest
+}
+
+// write names the entries after the final frame files (optimization may change the
+// extension) and writes the manifest into dir, returning its path
+func (m *framesManifest) write(frames []string, dir string) (string, error) {
+	for i := range m.Frames {
+		m.Frames[i].File = filepath.Base(frames[i])
+	}
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	manifestPath := filepath.Join(dir, domain.FramesManifest)
+	if err := os.WriteFile(manifestPath, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write frames manifest: %w", err)
+	}
+	return manifestPath, nil
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package adapter
+
+import "errors"
+
+// freeBytes is not available without statfs, so every video goes to the disk
+func freeBytes(dir string) (int64, error) {
+	return 0, errors.New("free space not available on this platform")
+}
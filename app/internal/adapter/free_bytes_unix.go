@@ -0,0 +1,14 @@
+//go:build unix
+
+package adapter
+
+import "golang.org/x/sys/unix"
+
+// freeBytes reports the bytes available to the worker in the filesystem of dir
+func freeBytes(dir string) (int64, error) {
+	var stat unix.Statfs_t
+	if err := unix.Statfs(dir, &stat); err != nil {
+		return 0, err
+	}
+	return int64(stat.Bavail) * int64(stat.Bsize), nil
+}
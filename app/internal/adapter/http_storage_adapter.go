@@ -0,0 +1,183 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/port"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/observability"
+	"go.uber.org/zap"
+)
+
+// maxDownloadResumes bounds how many times a dropped download is resumed
+const maxDownloadResumes = 3
+
+// HTTPStorageAdapter reads source videos from presigned or public URLs, for producers that
+// cannot grant the worker access to their bucket
+type HTTPStorageAdapter struct {
+	// allowedHosts are the hosts a video_url may point to, so the worker cannot be used to
+	// reach internal addresses
+	allowedHosts map[string]bool
+	client       *http.Client
+}
+
+// NewHTTPStorageAdapter builds the adapter; only https URLs on allowedHosts are read
+func NewHTTPStorageAdapter(allowedHosts []string) port.URLSourcePort {
+	hosts := make(map[string]bool, len(allowedHosts))
+	for _, host := range allowedHosts {
+		hosts[strings.ToLower(host)] = true
+	}
+	a := &HTTPStorageAdapter{allowedHosts: hosts}
+	a.client = &http.Client{
+		// A redirect is held to the same rules as the URL itself
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if !a.allowed(req.URL) {
+				return fmt.Errorf("redirect to %s is not allowed", req.URL.Hostname())
+			}
+			return nil
+		},
+	}
+	return a
+}
+
+func (a *HTTPStorageAdapter) Allows(videoURL string) bool {
+	parsed, err := url.Parse(videoURL)
+	return err == nil && a.allowed(parsed)
+}
+
+func (a *HTTPStorageAdapter) allowed(videoURL *url.URL) bool {
+	return videoURL.Scheme == "https" && a.allowedHosts[strings.ToLower(videoURL.Hostname())]
+}
+
+// GetURL downloads videoURL. When the server supports range requests, a connection dropped
+// mid-transfer is resumed from the last byte read instead of starting over
+func (a *HTTPStorageAdapter) GetURL(ctx context.Context, videoURL string) (io.ReadCloser, error) {
+	if !a.Allows(videoURL) {
+		return nil, fmt.Errorf("video_url host is not allowed")
+	}
+
+	resp, err := a.get(ctx, videoURL, "", 0)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, statusError(resp.StatusCode)
+	}
+
+	return &resumableBody{
+		ctx:       ctx,
+		adapter:   a,
+		url:       videoURL,
+		body:      resp.Body,
+		resumable: resp.Header.Get("Accept-Ranges") == "bytes",
+		validator: rangeValidator(resp.Header),
+	}, nil
+}
+
+// get requests videoURL from offset; validator makes the server send the whole object
+// instead of a range when it changed since the first request
+func (a *HTTPStorageAdapter) get(ctx context.Context, videoURL, validator string, offset int64) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, videoURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build video_url request: %w", err)
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+		if validator != "" {
+			req.Header.Set("If-Range", validator)
+		}
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get video_url: %w", err)
+	}
+	return resp, nil
+}
+
+func statusError(status int) error {
+	if status == http.StatusNotFound {
+		return fmt.Errorf("%w: video_url returned status %d", domain.ErrObjectNotFound, status)
+	}
+	return fmt.Errorf("video_url returned status %d", status)
+}
+
+// rangeValidator prefers the ETag, as Last-Modified only has one-second precision
+func rangeValidator(header http.Header) string {
+	if etag := header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		return etag
+	}
+	return header.Get("Last-Modified")
+}
+
+// resumableBody reads the response body and, when it fails mid-stream, requests the rest
+// with a Range header
+type resumableBody struct {
+	ctx       context.Context
+	adapter   *HTTPStorageAdapter
+	url       string
+	body      io.ReadCloser
+	resumable bool
+	validator string
+	offset    int64
+	resumes   int
+}
+
+func (b *resumableBody) Read(p []byte) (int, error) {
+	n, err := b.body.Read(p)
+	b.offset += int64(n)
+	if err == nil || errors.Is(err, io.EOF) {
+		return n, err
+	}
+	if !b.resumable || b.validator == "" || b.resumes >= maxDownloadResumes || b.ctx.Err() != nil {
+		return n, err
+	}
+
+	observability.FromContext(b.ctx).Warn("video_url download interrupted, resuming",
+		zap.Int64("offset", b.offset),
+		zap.Int("resume", b.resumes+1),
+		zap.Error(err),
+	)
+	if resumeErr := b.resume(); resumeErr != nil {
+		return n, fmt.Errorf("%v (resume failed: %w)", err, resumeErr)
+	}
+	// The bytes read before the failure are still valid; the next Read continues from the new body
+	return n, nil
+}
+
+func (b *resumableBody) resume() error {
+	b.resumes++
+	b.body.Close()
+	b.body = http.NoBody
+
+	resp, err := b.adapter.get(b.ctx, b.url, b.validator, b.offset)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusPartialContent {
+		// A 200 means the object changed and the server sent it whole; the bytes already
+		// written belong to the old version
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusOK {
+			return errors.New("video_url changed during the download")
+		}
+		return statusError(resp.StatusCode)
+	}
+	if !strings.HasPrefix(resp.Header.Get("Content-Range"), fmt.Sprintf("bytes %d-", b.offset)) {
+		resp.Body.Close()
+		return fmt.Errorf("video_url answered the wrong range %q", resp.Header.Get("Content-Range"))
+	}
+	b.body = resp.Body
+	return nil
+}
+
+func (b *resumableBody) Close() error {
+	return b.body.Close()
+}
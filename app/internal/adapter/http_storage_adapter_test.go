@@ -0,0 +1,154 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/observability"
+)
+
+const testVideo = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+// newVideoServer serves testVideo with range support; the first response is cut after cutAt bytes
+func newVideoServer(t *testing.T, cutAt int, etag func(request int) string) (*httptest.Server, *[]string) {
+	t.Helper()
+	var ranges []string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ranges = append(ranges, r.Header.Get("Range"))
+		tag := etag(len(ranges))
+		w.Header().Set("ETag", tag)
+		w.Header().Set("Accept-Ranges", "bytes")
+
+		if r.Header.Get("Range") != "" && r.Header.Get("If-Range") == tag {
+			var start int
+			fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-", &start)
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, len(testVideo)-1, len(testVideo)))
+			w.WriteHeader(http.StatusPartialContent)
+			io.WriteString(w, testVideo[start:])
+			return
+		}
+
+		w.Header().Set("Content-Length", fmt.Sprint(len(testVideo)))
+		if len(ranges) == 1 && cutAt > 0 {
+			io.WriteString(w, testVideo[:cutAt])
+			w.(http.Flusher).Flush()
+			// Dropping the connection leaves the body short of its Content-Length
+			conn, _, _ := w.(http.Hijacker).Hijack()
+			conn.Close()
+			return
+		}
+		io.WriteString(w, testVideo)
+	}))
+	t.Cleanup(server.Close)
+	return server, &ranges
+}
+
+func newTestHTTPStorage(server *httptest.Server) *HTTPStorageAdapter {
+	source := NewHTTPStorageAdapter([]string{"127.0.0.1"}).(*HTTPStorageAdapter)
+	source.client.Transport = server.Client().Transport
+	return source
+}
+
+func TestHTTPStorageAdapter_Allows(t *testing.T) {
+	source := NewHTTPStorageAdapter([]string{"Videos.example.com"})
+
+	tests := map[string]bool{
+		"https://videos.example.com/video.mp4?X-Amz-Signature=abc": true,
+		"http://videos.example.com/video.mp4":                      false,
+		"https://169.254.169.254/latest/meta-data":                 false,
+		"https://videos.example.com.evil.com/video.mp4":            false,
+		"://invalid": false,
+	}
+	for videoURL, want := range tests {
+		if got := source.Allows(videoURL); got != want {
+			t.Errorf("Allows(%q): expected %v, got %v", videoURL, want, got)
+		}
+	}
+}
+
+func TestHTTPStorageAdapter_GetURL(t *testing.T) {
+	server, ranges := newVideoServer(t, 0, func(int) string { return `"v1"` })
+
+	body, err := newTestHTTPStorage(server).GetURL(context.Background(), server.URL+"/video.mp4")
+	if err != nil {
+		t.Fatalf("GetURL failed: %v", err)
+	}
+	defer body.Close()
+
+	content, err := io.ReadAll(body)
+	if err != nil || string(content) != testVideo {
+		t.Errorf("Expected the whole video, got %q (%v)", content, err)
+	}
+	if len(*ranges) != 1 {
+		t.Errorf("Expected a single request, got %v", *ranges)
+	}
+}
+
+func TestHTTPStorageAdapter_ResumesDroppedDownload(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	server, ranges := newVideoServer(t, 10, func(int) string { return `"v1"` })
+
+	body, err := newTestHTTPStorage(server).GetURL(context.Background(), server.URL+"/video.mp4")
+	if err != nil {
+		t.Fatalf("GetURL failed: %v", err)
+	}
+	defer body.Close()
+
+	content, err := io.ReadAll(body)
+	if err != nil || string(content) != testVideo {
+		t.Errorf("Expected the whole video after resuming, got %q (%v)", content, err)
+	}
+	if len(*ranges) != 2 || (*ranges)[1] != "bytes=10-" {
+		t.Errorf("Expected the download resumed from byte 10, got %v", *ranges)
+	}
+}
+
+func TestHTTPStorageAdapter_ChangedVideoNotResumed(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	server, _ := newVideoServer(t, 10, func(request int) string { return fmt.Sprintf(`"v%d"`, request) })
+
+	body, err := newTestHTTPStorage(server).GetURL(context.Background(), server.URL+"/video.mp4")
+	if err != nil {
+		t.Fatalf("GetURL failed: %v", err)
+	}
+	defer body.Close()
+
+	if _, err := io.ReadAll(body); err == nil || !strings.Contains(err.Error(), "changed") {
+		t.Errorf("Expected the download to fail when the video changed, got %v", err)
+	}
+}
+
+func TestHTTPStorageAdapter_Errors(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, "https://169.254.169.254/latest/meta-data", http.StatusFound)
+			return
+		}
+		http.NotFound(w, r)
+	}))
+	defer server.Close()
+	source := newTestHTTPStorage(server)
+
+	if _, err := source.GetURL(context.Background(), server.URL+"/missing.mp4"); !errors.Is(err, domain.ErrObjectNotFound) {
+		t.Errorf("Expected ErrObjectNotFound, got %v", err)
+	}
+	if _, err := source.GetURL(context.Background(), server.URL+"/redirect"); err == nil || !strings.Contains(err.Error(), "not allowed") {
+		t.Errorf("Expected the redirect to a disallowed host refused, got %v", err)
+	}
+	if _, err := source.GetURL(context.Background(), "https://videos.example.com/video.mp4"); err == nil {
+		t.Error("Expected a disallowed host refused")
+	}
+}
@@ -0,0 +1,142 @@
+package adapter
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/ffmpeg"
+)
+
+// optimizeFrames re-encodes the PNG frames in the requested format, replacing each file and
+// returning the new paths plus the total size before and after. The tEXt fields written by
+// the metadata scrubber are kept (as a JPEG comment for jpeg); webp goes through ffmpeg
+func optimizeFrames(ctx context.Context, ffmpegBinary string, frames []string, options domain.ImageOptions) ([]string, int64, int64, error) {
+	var before, after int64
+	optimized := make([]string, 0, len(frames))
+
+	for _, frame := range frames {
+		if err := ctx.Err(); err != nil {
+			return nil, 0, 0, err
+		}
+
+		data, err := os.ReadFile(frame)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+
+		target := strings.TrimSuffix(frame, filepath.Ext(frame)) + "." + imageExtension(options.Format)
+		var encoded []byte
+		if options.Format == domain.ImageFormatWebP {
+			encoded, err = encodeWebP(ctx, ffmpegBinary, frame, target, options.EncodeQuality())
+		} else {
+			encoded, err = encodeImage(data, options)
+		}
+		if err != nil {
+			return nil, 0, 0, fmt.Errorf("failed to optimize %s: %w", filepath.Base(frame), err)
+		}
+
+		if err := os.WriteFile(target, encoded, 0644); err != nil {
+			return nil, 0, 0, err
+		}
+		if target != frame {
+			os.Remove(frame)
+		}
+
+		before += int64(len(data))
+		after += int64(len(encoded))
+		optimized = append(optimized, target)
+	}
+	return optimized, before, after, nil
+}
+
+func imageExtension(format string) string {
+	if format == domain.ImageFormatJPEG {
+		return "jpg"
+	}
+	return format
+}
+
+// encodeImage decodes a PNG frame and encodes it again as png (best compression) or jpeg
+func encodeImage(data []byte, options domain.ImageOptions) ([]byte, error) {
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	fields := pngTextFields(data)
+
+	var out bytes.Buffer
+	if options.Format == domain.ImageFormatJPEG {
+		if err := jpeg.Encode(&out, img, &jpeg.Options{Quality: options.EncodeQuality()}); err != nil {
+			return nil, err
+		}
+		return withJPEGComment(out.Bytes(), fields), nil
+	}
+
+	encoder := png.Encoder{CompressionLevel: png.BestCompression}
+	if err := encoder.Encode(&out, img); err != nil {
+		return nil, err
+	}
+	if len(fields) == 0 {
+		return out.Bytes(), nil
+	}
+	return scrubPNG(out.Bytes(), fields)
+}
+
+// encodeWebP converts with libwebp, which the Go standard library has no encoder for
+func encodeWebP(ctx context.Context, ffmpegBinary, frame, target string, quality int) ([]byte, error) {
+	args := []string{"-i", frame, "-map_metadata", "-1", "-c:v", "libwebp", "-quality", strconv.Itoa(quality), "-y", target}
+	output, err := ffmpeg.CombinedOutput(ctx, ffmpeg.DefaultKillGrace, ffmpegBinary, args...)
+	if err != nil {
+		return nil, fmt.Errorf("ffmpeg error: %w, output: %s", err, string(output))
+	}
+	return os.ReadFile(target)
+}
+
+// pngTextFields returns the key/value pairs of the tEXt chunks of a PNG
+func pngTextFields(data []byte) [][2]string {
+	var fields [][2]string
+	rest := data[len(pngSignature):]
+	for len(rest) >= 12 {
+		length := binary.BigEndian.Uint32(rest[:4])
+		if uint64(length)+12 > uint64(len(rest)) {
+			break
+		}
+		if string(rest[4:8]) == "tEXt" {
+			if key, value, ok := strings.Cut(string(rest[8:8+length]), "\x00"); ok {
+				fields = append(fields, [2]string{key, value})
+			}
+		}
+		rest = rest[12+length:]
+	}
+	return fields
+}
+
+// withJPEGComment inserts the fields as a COM segment right after the SOI marker
+func withJPEGComment(data []byte, fields [][2]string) []byte {
+	if len(fields) == 0 {
+		return data
+	}
+
+	pairs := make([]string, len(fields))
+	for i, field := range fields {
+		pairs[i] = field[0] + "=" + field[1]
+	}
+	comment := []byte(strings.Join(pairs, ";"))
+
+	var out bytes.Buffer
+	out.Write(data[:2])
+	out.Write([]byte{0xFF, 0xFE})
+	binary.Write(&out, binary.BigEndian, uint16(len(comment)+2))
+	out.Write(comment)
+	out.Write(data[2:])
+	return out.Bytes()
+}
@@ -0,0 +1,96 @@
+package adapter
+
+import (
+	"bytes"
+	"context"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+)
+
+// scrubbedFrame writes a PNG frame carrying the fields the metadata scrubber writes
+func scrubbedFrame(t *testing.T, dir string) string {
+	t.Helper()
+	var encoded bytes.Buffer
+	if err := png.Encode(&encoded, checkerboardImage()); err != nil {
+		t.Fatalf("Failed to encode png: %v", err)
+	}
+	data, err := scrubPNG(encoded.Bytes(), [][2]string{{"process_id", "proc-1"}})
+	if err != nil {
+		t.Fatalf("scrubPNG failed: %v", err)
+	}
+
+	path := filepath.Join(dir, "frame_0001.png")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatalf("Failed to write frame: %v", err)
+	}
+	return path
+}
+
+func TestOptimizeFrames_JPEG(t *testing.T) {
+	frame := scrubbedFrame(t, t.TempDir())
+
+	frames, before, after, err := optimizeFrames(context.Background(), "", []string{frame}, domain.ImageOptions{Format: domain.ImageFormatJPEG, Quality: 50})
+	if err != nil {
+		t.Fatalf("optimizeFrames failed: %v", err)
+	}
+
+	if len(frames) != 1 || filepath.Base(frames[0]) != "frame_0001.jpg" {
+		t.Fatalf("Expected the frame renamed to .jpg, got %v", frames)
+	}
+	if _, err := os.Stat(frame); !os.IsNotExist(err) {
+		t.Error("Expected the original png to be removed")
+	}
+	if before <= 0 || after <= 0 {
+		t.Errorf("Expected sizes to be measured, got %d and %d", before, after)
+	}
+
+	data := readFile(t, frames[0])
+	if _, err := jpeg.Decode(strings.NewReader(data)); err != nil {
+		t.Errorf("Expected a valid jpeg, got %v", err)
+	}
+	if !strings.Contains(data, "process_id=proc-1") {
+		t.Error("Expected the controlled fields kept as a jpeg comment")
+	}
+}
+
+func TestOptimizeFrames_PNGKeepsFields(t *testing.T) {
+	frame := scrubbedFrame(t, t.TempDir())
+
+	frames, _, _, err := optimizeFrames(context.Background(), "", []string{frame}, domain.ImageOptions{Format: domain.ImageFormatPNG})
+	if err != nil {
+		t.Fatalf("optimizeFrames failed: %v", err)
+	}
+	if frames[0] != frame {
+		t.Errorf("Expected the png replaced in place, got %s", frames[0])
+	}
+
+	data := []byte(readFile(t, frame))
+	if fields := pngTextFields(data); len(fields) != 1 || fields[0] != [2]string{"process_id", "proc-1"} {
+		t.Errorf("Expected the process id kept, got %v", fields)
+	}
+	if _, err := png.Decode(bytes.NewReader(data)); err != nil {
+		t.Errorf("Expected a valid png, got %v", err)
+	}
+}
+
+func TestOptimizeFrames_WebP(t *testing.T) {
+	ffmpegPath, argsFile := writeRecordingFFmpeg(t)
+	frame := scrubbedFrame(t, t.TempDir())
+
+	frames, _, _, err := optimizeFrames(context.Background(), ffmpegPath, []string{frame}, domain.ImageOptions{Format: domain.ImageFormatWebP, Quality: 70})
+	if err != nil {
+		t.Fatalf("optimizeFrames failed: %v", err)
+	}
+	if filepath.Base(frames[0]) != "frame_0001.webp" {
+		t.Errorf("Expected the frame renamed to .webp, got %s", frames[0])
+	}
+	if args := readFile(t, argsFile); !strings.Contains(args, "-map_metadata -1 -c:v libwebp -quality 70") {
+		t.Errorf("Unexpected ffmpeg args: %s", args)
+	}
+}
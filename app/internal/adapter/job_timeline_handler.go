@@ -0,0 +1,45 @@
+package adapter
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/port"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/observability"
+	"go.uber.org/zap"
+)
+
+// JobTimelinePattern routes GET /processor/jobs/{id}/timeline to JobTimelineHandler
+const JobTimelinePattern = "GET /processor/jobs/{id}/timeline"
+
+// JobTimelineHandler serves the processing timeline of a job: its stage transitions and the
+// time spent in each stage, as recorded by the workers with JOB_TIMELINE enabled
+type JobTimelineHandler struct {
+	timeline port.JobTimelinePort
+}
+
+func NewJobTimelineHandler(timeline port.JobTimelinePort) *JobTimelineHandler {
+	return &JobTimelineHandler{timeline: timeline}
+}
+
+func (h *JobTimelineHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	timeline, err := h.timeline.Timeline(r.Context(), r.PathValue("id"))
+	switch {
+	case errors.Is(err, domain.ErrObjectNotFound):
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(map[string]string{"error": "no timeline for this job"})
+		return
+	case err != nil:
+		// Storage errors stay in the logs
+		observability.FromContext(r.Context()).Error("failed to read job timeline", zap.Error(err))
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(map[string]string{"error": "failed to read job timeline"})
+		return
+	}
+
+	json.NewEncoder(w).Encode(timeline)
+}
@@ -0,0 +1,88 @@
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/observability"
+)
+
+// fakeTimeline serves the timeline of job-1 only
+type fakeTimeline struct {
+	err error
+}
+
+func (f *fakeTimeline) Timeline(ctx context.Context, processID string) (domain.JobTimeline, error) {
+	if f.err != nil {
+		return domain.JobTimeline{}, f.err
+	}
+	if processID != "job-1" {
+		return domain.JobTimeline{}, fmt.Errorf("no events: %w", domain.ErrObjectNotFound)
+	}
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	return domain.NewJobTimeline(processID, []domain.JobEvent{
+		{ProcessID: processID, Stage: domain.JobStageDownload, Status: domain.JobEventStarted, Timestamp: start},
+		{ProcessID: processID, Stage: domain.JobStageDownload, Status: domain.JobEventCompleted, Timestamp: start.Add(5 * time.Second), Bytes: 2048},
+	}), nil
+}
+
+func TestJobTimelineHandler(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(JobTimelinePattern, NewJobTimelineHandler(&fakeTimeline{}))
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/processor/jobs/job-1/timeline", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var timeline domain.JobTimeline
+	if err := json.Unmarshal(rec.Body.Bytes(), &timeline); err != nil {
+		t.Fatalf("Expected a JSON timeline, got %s", rec.Body.String())
+	}
+	if timeline.ProcessID != "job-1" || len(timeline.Events) != 2 || len(timeline.Stages) != 1 || timeline.Stages[0].DurationSeconds != 5 {
+		t.Errorf("Unexpected timeline %s", rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/processor/jobs/job-2/timeline", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for a job without events, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/processor/jobs/job-1/timeline", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 for a POST, got %d", rec.Code)
+	}
+}
+
+func TestJobTimelineHandler_StorageError(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(JobTimelinePattern, NewJobTimelineHandler(&fakeTimeline{err: errors.New("access denied")}))
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/processor/jobs/job-1/timeline", nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Fatalf("Expected 500, got %d", rec.Code)
+	}
+	var body map[string]string
+	json.Unmarshal(rec.Body.Bytes(), &body)
+	if body["error"] != "failed to read job timeline" {
+		t.Errorf("Expected the storage error kept out of the response, got %v", body)
+	}
+}
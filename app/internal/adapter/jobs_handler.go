@@ -0,0 +1,279 @@
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/port"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/observability"
+	"go.uber.org/zap"
+)
+
+// maxJobOptionsBytes bounds the options JSON; the video is bounded separately
+const maxJobOptionsBytes = 64 * 1024
+
+const (
+	// JobAPIKeyHeader carries the api_key of the producer a job is submitted as
+	JobAPIKeyHeader = "X-Api-Key"
+
+	// JobTenantHeader carries the tenant_id of the job, one of the tenants of its producer
+	JobTenantHeader = "X-Tenant-Id"
+)
+
+var (
+	// errVideoTooLarge is returned when the upload or download passes maxUploadBytes
+	errVideoTooLarge = errors.New("video exceeds the maximum upload size")
+
+	// errVideoDownload is returned when video_url cannot be fetched
+	errVideoDownload = errors.New("failed to download video_url")
+)
+
+// JobsHandler serves POST /processor/jobs. Clients either upload the video as the "video" part
+// of a multipart form (with an optional "options" JSON part) or send {"video_url", "options"}
+// as JSON, and get back the process_id to match against the result message. Callers are
+// authorized by the submitter before the video is read
+type JobsHandler struct {
+	submitter      port.JobSubmitterPort
+	tempDir        string
+	maxUploadBytes int64
+
+	// allowedURLHosts are the hosts video_url may point to; empty disables video_url
+	allowedURLHosts map[string]bool
+	client          *http.Client
+}
+
+// NewJobsHandler builds the handler; videos are spooled to tempDir before staging, since S3
+// needs a seekable body
+func NewJobsHandler(submitter port.JobSubmitterPort, tempDir string, maxUploadBytes int64, allowedURLHosts []string) *JobsHandler {
+	hosts := make(map[string]bool, len(allowedURLHosts))
+	for _, host := range allowedURLHosts {
+		hosts[strings.ToLower(host)] = true
+	}
+	h := &JobsHandler{
+		submitter:       submitter,
+		tempDir:         tempDir,
+		maxUploadBytes:  maxUploadBytes,
+		allowedURLHosts: hosts,
+	}
+	h.client = &http.Client{
+		Timeout: 30 * time.Minute,
+		// A redirect is held to the same rules as video_url itself
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if !h.allowedURL(req.URL) {
+				return fmt.Errorf("redirect to %s is not allowed", req.URL.Hostname())
+			}
+			return nil
+		},
+	}
+	return h
+}
+
+func (h *JobsHandler) allowedURL(videoURL *url.URL) bool {
+	return videoURL.Scheme == "https" && h.allowedURLHosts[strings.ToLower(videoURL.Hostname())]
+}
+
+func (h *JobsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		writeJobError(r.Context(), w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return
+	}
+
+	producer, err := h.submitter.Authorize(domain.JobCredentials{
+		APIKey:   r.Header.Get(JobAPIKeyHeader),
+		TenantID: r.Header.Get(JobTenantHeader),
+	})
+	if err != nil {
+		writeJobError(r.Context(), w, jobErrorStatus(err), err)
+		return
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+	var video *os.File
+	var fileName string
+	var options map[string]interface{}
+	switch mediaType {
+	case "multipart/form-data":
+		video, fileName, options, err = h.readUpload(w, r)
+	case "application/json":
+		video, fileName, options, err = h.readURL(r)
+	default:
+		err = fmt.Errorf("%w: content type must be multipart/form-data or application/json", domain.ErrInvalidJob)
+	}
+	if video != nil {
+		defer os.Remove(video.Name())
+		defer video.Close()
+	}
+	if err != nil {
+		writeJobError(r.Context(), w, jobErrorStatus(err), err)
+		return
+	}
+
+	job, err := h.submitter.Submit(r.Context(), producer, video, fileName, options)
+	if err != nil {
+		writeJobError(r.Context(), w, jobErrorStatus(err), err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(job)
+}
+
+// readUpload streams the multipart parts, spooling the video without holding it in memory
+func (h *JobsHandler) readUpload(w http.ResponseWriter, r *http.Request) (*os.File, string, map[string]interface{}, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, h.maxUploadBytes+maxJobOptionsBytes)
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("%w: %v", domain.ErrInvalidJob, err)
+	}
+
+	var video *os.File
+	var fileName string
+	var options map[string]interface{}
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return video, "", nil, uploadError(err)
+		}
+
+		switch part.FormName() {
+		case "video":
+			if video != nil {
+				return video, "", nil, fmt.Errorf("%w: only one video per job", domain.ErrInvalidJob)
+			}
+			fileName = part.FileName()
+			video, err = h.spool(part)
+		case "options":
+			options, err = decodeJobOptions(part)
+		}
+		part.Close()
+		if err != nil {
+			return video, "", nil, uploadError(err)
+		}
+	}
+
+	if video == nil {
+		return nil, "", nil, fmt.Errorf("%w: the video part is required", domain.ErrInvalidJob)
+	}
+	return video, fileName, options, nil
+}
+
+// readURL downloads video_url, which must be https and on an allowed host so the worker
+// cannot be used to reach internal addresses
+func (h *JobsHandler) readURL(r *http.Request) (*os.File, string, map[string]interface{}, error) {
+	var request struct {
+		VideoURL string                 `json:"video_url"`
+		Options  map[string]interface{} `json:"options"`
+	}
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxJobOptionsBytes)).Decode(&request); err != nil {
+		return nil, "", nil, fmt.Errorf("%w: %v", domain.ErrInvalidJob, err)
+	}
+	if len(h.allowedURLHosts) == 0 {
+		return nil, "", nil, fmt.Errorf("%w: video_url is not enabled", domain.ErrInvalidJob)
+	}
+
+	videoURL, err := url.Parse(request.VideoURL)
+	if err != nil || !h.allowedURL(videoURL) {
+		return nil, "", nil, fmt.Errorf("%w: video_url must be an https URL on an allowed host", domain.ErrInvalidJob)
+	}
+
+	req, err := http.NewRequestWithContext(r.Context(), http.MethodGet, videoURL.String(), nil)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("%w: %v", domain.ErrInvalidJob, err)
+	}
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, "", nil, fmt.Errorf("%w: %v", errVideoDownload, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", nil, fmt.Errorf("%w: status %d", errVideoDownload, resp.StatusCode)
+	}
+
+	video, err := h.spool(resp.Body)
+	return video, path.Base(videoURL.Path), request.Options, err
+}
+
+// spool copies the video to a temp file, failing once it passes maxUploadBytes
+func (h *JobsHandler) spool(body io.Reader) (*os.File, error) {
+	if err := os.MkdirAll(h.tempDir, 0777); err != nil {
+		return nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	file, err := os.CreateTemp(h.tempDir, "upload_*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp file: %w", err)
+	}
+
+	written, err := io.Copy(file, io.LimitReader(body, h.maxUploadBytes+1))
+	if err == nil && written > h.maxUploadBytes {
+		err = errVideoTooLarge
+	}
+	if err == nil {
+		_, err = file.Seek(0, io.SeekStart)
+	}
+	return file, err
+}
+
+func decodeJobOptions(body io.Reader) (map[string]interface{}, error) {
+	var options map[string]interface{}
+	if err := json.NewDecoder(io.LimitReader(body, maxJobOptionsBytes)).Decode(&options); err != nil {
+		return nil, fmt.Errorf("%w: options must be a JSON object: %v", domain.ErrInvalidJob, err)
+	}
+	return options, nil
+}
+
+// uploadError reports a body cut by MaxBytesReader as too large rather than malformed
+func uploadError(err error) error {
+	var maxBytes *http.MaxBytesError
+	if errors.As(err, &maxBytes) {
+		return errVideoTooLarge
+	}
+	return err
+}
+
+func jobErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, errVideoTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, domain.ErrInvalidJob):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrUnauthorizedJob):
+		return http.StatusUnauthorized
+	case errors.Is(err, domain.ErrForbiddenJob):
+		return http.StatusForbidden
+	case errors.Is(err, errVideoDownload):
+		return http.StatusBadGateway
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func writeJobError(ctx context.Context, w http.ResponseWriter, status int, err error) {
+	message := err.Error()
+	if status >= http.StatusInternalServerError {
+		observability.FromContext(ctx).Error("job submission failed", zap.Error(err))
+	}
+	// Storage and queue errors stay in the logs
+	if status == http.StatusInternalServerError {
+		message = "job submission failed"
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
@@ -0,0 +1,258 @@
+package adapter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/observability"
+)
+
+// fakeSubmitter records the submitted job instead of staging it
+type fakeSubmitter struct {
+	video       string
+	fileName    string
+	options     map[string]interface{}
+	credentials domain.JobCredentials
+	producer    domain.JobProducer
+	submitted   bool
+	authErr     error
+	err         error
+}
+
+func (f *fakeSubmitter) Authorize(credentials domain.JobCredentials) (domain.JobProducer, error) {
+	f.credentials = credentials
+	if f.authErr != nil {
+		return domain.JobProducer{}, f.authErr
+	}
+	return domain.JobProducer{Name: "team-a", APIKey: credentials.APIKey}, nil
+}
+
+func (f *fakeSubmitter) Submit(ctx context.Context, producer domain.JobProducer, video io.Reader, fileName string, options map[string]interface{}) (domain.JobSubmission, error) {
+	f.submitted = true
+	f.producer = producer
+	if f.err != nil {
+		return domain.JobSubmission{}, f.err
+	}
+	data, err := io.ReadAll(video)
+	if err != nil {
+		return domain.JobSubmission{}, err
+	}
+	f.video, f.fileName, f.options = string(data), fileName, options
+	return domain.JobSubmission{ProcessID: "job-1", VideoBucket: "input-bucket", VideoKey: "uploads/job-1/" + fileName}, nil
+}
+
+func multipartUpload(t *testing.T, video, options string) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	if options != "" {
+		if err := writer.WriteField("options", options); err != nil {
+			t.Fatalf("Failed to write options: %v", err)
+		}
+	}
+	if video != "" {
+		part, err := writer.CreateFormFile("video", "clip.mp4")
+		if err != nil {
+			t.Fatalf("Failed to create video part: %v", err)
+		}
+		part.Write([]byte(video))
+	}
+	writer.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/processor/jobs", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+func TestJobsHandler_Upload(t *testing.T) {
+	submitter := &fakeSubmitter{}
+	handler := NewJobsHandler(submitter, t.TempDir(), 1024, nil)
+
+	req := multipartUpload(t, "video bytes", `{"output_type":"sprite"}`)
+	req.Header.Set(JobAPIKeyHeader, "key-a")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("Expected 202, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var job domain.JobSubmission
+	if err := json.Unmarshal(rec.Body.Bytes(), &job); err != nil || job.ProcessID != "job-1" {
+		t.Errorf("Expected the process_id in the response, got %s", rec.Body.String())
+	}
+	if submitter.video != "video bytes" || submitter.fileName != "clip.mp4" || submitter.options["output_type"] != "sprite" {
+		t.Errorf("Unexpected submission %+v", submitter)
+	}
+	if submitter.credentials.APIKey != "key-a" || submitter.producer.Name != "team-a" {
+		t.Errorf("Expected the job submitted as the authorized producer, got %+v", submitter.producer)
+	}
+}
+
+func TestJobsHandler_Unauthorized(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	submitter := &fakeSubmitter{authErr: fmt.Errorf("%w: a producer api key is required", domain.ErrUnauthorizedJob)}
+	handler := NewJobsHandler(submitter, t.TempDir(), 1024, nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, multipartUpload(t, "video bytes", ""))
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if submitter.submitted {
+		t.Error("Expected nothing submitted without a producer api key")
+	}
+}
+
+func TestJobsHandler_ForbiddenTenant(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	submitter := &fakeSubmitter{authErr: fmt.Errorf("%w: producer team-a may not send jobs for tenant globex", domain.ErrForbiddenJob)}
+	handler := NewJobsHandler(submitter, t.TempDir(), 1024, nil)
+	req := multipartUpload(t, "video bytes", "")
+	req.Header.Set(JobAPIKeyHeader, "key-a")
+	req.Header.Set(JobTenantHeader, "globex")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("Expected 403, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if submitter.credentials.TenantID != "globex" {
+		t.Errorf("Expected the tenant header passed to Authorize, got %+v", submitter.credentials)
+	}
+	if submitter.submitted {
+		t.Error("Expected nothing submitted for a forbidden tenant")
+	}
+}
+
+func TestJobsHandler_Errors(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		request   func(t *testing.T) *http.Request
+		submitErr error
+		want      int
+	}{
+		{"wrong method", func(t *testing.T) *http.Request {
+			return httptest.NewRequest(http.MethodGet, "/processor/jobs", nil)
+		}, nil, http.StatusMethodNotAllowed},
+		{"wrong content type", func(t *testing.T) *http.Request {
+			req := httptest.NewRequest(http.MethodPost, "/processor/jobs", strings.NewReader("video"))
+			req.Header.Set("Content-Type", "video/mp4")
+			return req
+		}, nil, http.StatusBadRequest},
+		{"missing video", func(t *testing.T) *http.Request {
+			return multipartUpload(t, "", `{"output_type":"sprite"}`)
+		}, nil, http.StatusBadRequest},
+		{"invalid options", func(t *testing.T) *http.Request {
+			return multipartUpload(t, "video", `[1,2]`)
+		}, nil, http.StatusBadRequest},
+		{"video too large", func(t *testing.T) *http.Request {
+			return multipartUpload(t, strings.Repeat("x", 2048), "")
+		}, nil, http.StatusRequestEntityTooLarge},
+		{"rejected options", func(t *testing.T) *http.Request {
+			return multipartUpload(t, "video", "")
+		}, fmt.Errorf("%w: video_key cannot be set", domain.ErrInvalidJob), http.StatusBadRequest},
+		{"storage failure", func(t *testing.T) *http.Request {
+			return multipartUpload(t, "video", "")
+		}, errors.New("access denied"), http.StatusInternalServerError},
+		{"video_url disabled", func(t *testing.T) *http.Request {
+			req := httptest.NewRequest(http.MethodPost, "/processor/jobs", strings.NewReader(`{"video_url":"https://videos.example.com/a.mp4"}`))
+			req.Header.Set("Content-Type", "application/json")
+			return req
+		}, nil, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewJobsHandler(&fakeSubmitter{err: tt.submitErr}, t.TempDir(), 1024, nil)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, tt.request(t))
+			if rec.Code != tt.want {
+				t.Errorf("Expected %d, got %d: %s", tt.want, rec.Code, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestJobsHandler_StorageErrorsStayInLogs(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	handler := NewJobsHandler(&fakeSubmitter{err: errors.New("bucket secret-bucket access denied")}, t.TempDir(), 1024, nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, multipartUpload(t, "video", ""))
+
+	if strings.Contains(rec.Body.String(), "secret-bucket") {
+		t.Errorf("Expected internal details hidden, got %s", rec.Body.String())
+	}
+}
+
+func TestJobsHandler_VideoURL(t *testing.T) {
+	source := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/videos/a.mp4":
+			w.Write([]byte("remote video"))
+		case "/redirect":
+			http.Redirect(w, r, "https://internal.example.com/secret.mp4", http.StatusFound)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer source.Close()
+	sourceURL, _ := url.Parse(source.URL)
+
+	submit := func(t *testing.T, videoURL string) (*fakeSubmitter, *httptest.ResponseRecorder) {
+		submitter := &fakeSubmitter{}
+		handler := NewJobsHandler(submitter, t.TempDir(), 1024, []string{sourceURL.Hostname()})
+		handler.client.Transport = source.Client().Transport
+
+		body := fmt.Sprintf(`{"video_url":%q,"options":{"max_frames":10}}`, videoURL)
+		req := httptest.NewRequest(http.MethodPost, "/processor/jobs", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return submitter, rec
+	}
+
+	submitter, rec := submit(t, source.URL+"/videos/a.mp4")
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("Expected 202, got %d: %s", rec.Code, rec.Body.String())
+	}
+	if submitter.video != "remote video" || submitter.fileName != "a.mp4" || submitter.options["max_frames"] != float64(10) {
+		t.Errorf("Unexpected submission %+v", submitter)
+	}
+
+	if _, rec := submit(t, "https://other.example.com/a.mp4"); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for a host outside the allowlist, got %d", rec.Code)
+	}
+	if _, rec := submit(t, strings.Replace(source.URL, "https", "http", 1)+"/videos/a.mp4"); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for plain http, got %d", rec.Code)
+	}
+	if _, rec := submit(t, source.URL+"/redirect"); rec.Code != http.StatusBadGateway {
+		t.Errorf("Expected 502 for a redirect off the allowlist, got %d", rec.Code)
+	}
+	if _, rec := submit(t, source.URL+"/missing.mp4"); rec.Code != http.StatusBadGateway {
+		t.Errorf("Expected 502 for a failed download, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,23 @@
+package adapter
+
+import (
+	"encoding/json"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/port"
+)
+
+// JSONResultSerializer writes the result messages documented in the README, the default format
+type JSONResultSerializer struct{}
+
+func NewJSONResultSerializer() port.ResultSerializerPort {
+	return &JSONResultSerializer{}
+}
+
+func (s *JSONResultSerializer) Serialize(result *domain.ProcessResult) ([]byte, error) {
+	return json.Marshal(result.ToMessage())
+}
+
+func (s *JSONResultSerializer) ContentType() string {
+	return domain.ContentTypeJSON
+}
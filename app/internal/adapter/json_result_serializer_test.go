@@ -0,0 +1,29 @@
+package adapter
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+)
+
+func TestJSONResultSerializer(t *testing.T) {
+	serializer := NewJSONResultSerializer()
+	if serializer.ContentType() != domain.ContentTypeJSON {
+		t.Errorf("Expected %s, got %s", domain.ContentTypeJSON, serializer.ContentType())
+	}
+
+	body, err := serializer.Serialize(&domain.ProcessResult{ProcessID: "123", Error: errors.New("boom")})
+	if err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+
+	var msg map[string]interface{}
+	if err := json.Unmarshal(body, &msg); err != nil {
+		t.Fatalf("Expected JSON, got %s", body)
+	}
+	if msg["process_id"] != "123" || msg["error_message"] != "boom" {
+		t.Errorf("Unexpected message %s", body)
+	}
+}
@@ -0,0 +1,318 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/port"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/observability"
+	"go.uber.org/zap"
+)
+
+// defaultMemoryHeadroom is how many times the video size the frames of a job take in the
+// memory directory
+const defaultMemoryHeadroom = 10
+
+// Budgets of the processing stages, named apart from the files of the job
+const (
+	framesBudget  = "frames"
+	spritesBudget = "sprites"
+)
+
+// MemoryWorkDir keeps the small videos in a memory-backed directory (tmpfs, such as /dev/shm
+// or an emptyDir with medium Memory), so their frames and archives never touch the container
+// disk. Each artifact of a job reserves its expected size where it is created: the video its
+// size, the frames the video size times the headroom, an archive the size of its files. A
+// reservation is admitted when the free space, plus what the jobs in progress already wrote,
+// covers every reservation; the artifacts that do not fit, or outgrow their reservation, go to
+// the disk. The reservations of a job are held until it is released
+type MemoryWorkDir struct {
+	dir      string
+	diskDir  string
+	maxBytes int64
+	headroom int64
+	// free reports the bytes available in a directory
+	free func(dir string) (int64, error)
+
+	mu sync.Mutex
+	// reserved is the budget of each artifact of the jobs in memory, by job id and artifact
+	reserved map[string]map[string]int64
+}
+
+// NewMemoryWorkDir places videos up to maxBytes in dir while it has room for them, and the
+// others in diskDir
+func NewMemoryWorkDir(dir, diskDir string, maxBytes int64) *MemoryWorkDir {
+	return &MemoryWorkDir{
+		dir:      dir,
+		diskDir:  diskDir,
+		maxBytes: maxBytes,
+		headroom: defaultMemoryHeadroom,
+		free:     freeBytes,
+		reserved: map[string]map[string]int64{},
+	}
+}
+
+// WithHeadroom sets how many times the video size the frames of a job reserve in memory;
+// values <= 0 keep the default of 10
+func (w *MemoryWorkDir) WithHeadroom(headroom int64) *MemoryWorkDir {
+	if headroom > 0 {
+		w.headroom = headroom
+	}
+	return w
+}
+
+// CreateVideo creates the file the video of jobID is downloaded to. A video up to maxBytes
+// goes to the memory directory when its size fits in it, the others to the disk. A video of
+// unknown size (zero) starts in memory with the reservation of the largest video and is moved
+// to the disk if it grows past maxBytes. A new file of the same name replaces its reservation
+func (w *MemoryWorkDir) CreateVideo(jobID, name string, sizeBytes int64) (port.VideoFile, error) {
+	if sizeBytes <= 0 {
+		sizeBytes = w.maxBytes
+	}
+	if sizeBytes > w.maxBytes {
+		w.release(jobID, name)
+		return create(w.diskDir, name)
+	}
+	return w.CreateArtifact(jobID, name, sizeBytes)
+}
+
+// CreateArtifact creates a file of jobID expected to take sizeBytes, such as an archive, in
+// the memory directory when it fits and on the disk otherwise. A file in memory that grows
+// past sizeBytes is moved to the disk
+func (w *MemoryWorkDir) CreateArtifact(jobID, name string, sizeBytes int64) (port.VideoFile, error) {
+	if !w.reserve(jobID, name, sizeBytes) {
+		w.release(jobID, name)
+		return create(w.diskDir, name)
+	}
+	file, err := create(w.dir, name)
+	if err != nil {
+		w.release(jobID, name)
+		return nil, err
+	}
+	return &spillFile{file: file, workDir: w, jobID: jobID, artifact: name, limit: sizeBytes}, nil
+}
+
+// Release frees the reservations of jobID and of its clips (jobID_1, jobID_2...), once the job
+// is done with its files
+func (w *MemoryWorkDir) Release(jobID string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for id := range w.reserved {
+		if id == jobID || strings.HasPrefix(id, jobID+"_") {
+			delete(w.reserved, id)
+		}
+	}
+	w.recordUsage()
+}
+
+// Reserved is the memory reserved by the jobs in progress
+func (w *MemoryWorkDir) Reserved() int64 {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.total()
+}
+
+// reserve admits sizeBytes more for the artifact of jobID, replacing its previous reservation.
+// The free space already leaves out the bytes the jobs wrote, so those are added back before
+// the reservations are taken from it
+func (w *MemoryWorkDir) reserve(jobID, artifact string, sizeBytes int64) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	free, err := w.free(w.dir)
+	if err != nil || free+usedBytes(w.dir) < w.total()-w.reserved[jobID][artifact]+sizeBytes {
+		return false
+	}
+	if w.reserved[jobID] == nil {
+		w.reserved[jobID] = map[string]int64{}
+	}
+	w.reserved[jobID][artifact] = sizeBytes
+	w.recordUsage()
+	return true
+}
+
+func (w *MemoryWorkDir) release(jobID, artifact string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, ok := w.reserved[jobID][artifact]; ok {
+		delete(w.reserved[jobID], artifact)
+		if len(w.reserved[jobID]) == 0 {
+			delete(w.reserved, jobID)
+		}
+		w.recordUsage()
+	}
+}
+
+// settle shrinks the reservation of a complete artifact to the bytes it took
+func (w *MemoryWorkDir) settle(jobID, artifact string, sizeBytes int64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if reserved, ok := w.reserved[jobID][artifact]; ok && sizeBytes < reserved {
+		w.reserved[jobID][artifact] = sizeBytes
+		w.recordUsage()
+	}
+}
+
+// total sums the reservations of the jobs
+func (w *MemoryWorkDir) total() int64 {
+	var total int64
+	for _, artifacts := range w.reserved {
+		for _, budget := range artifacts {
+			total += budget
+		}
+	}
+	return total
+}
+
+// recordUsage exports the bytes the files in the memory directory take and the reservations
+func (w *MemoryWorkDir) recordUsage() {
+	observability.SetMemoryBufferBytes(usedBytes(w.dir))
+	observability.SetMemoryBufferReservedBytes(w.total())
+}
+
+// usedBytes sums the files in dir; the files removed while it is walked are skipped
+func usedBytes(dir string) int64 {
+	var used int64
+	filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return nil
+		}
+		if info, err := entry.Info(); err == nil {
+			used += info.Size()
+		}
+		return nil
+	})
+	return used
+}
+
+func create(dir, name string) (*os.File, error) {
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	file, err := os.Create(filepath.Join(dir, name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	return file, nil
+}
+
+// spillFile is an artifact in the memory directory that moves to the disk once it is larger
+// than its reservation, releasing it. It does not embed the file so io.Copy goes through Write
+type spillFile struct {
+	file     *os.File
+	workDir  *MemoryWorkDir
+	jobID    string
+	artifact string
+	limit    int64
+	written  int64
+	spilled  bool
+}
+
+func (f *spillFile) Write(p []byte) (int, error) {
+	if !f.spilled && f.written+int64(len(p)) > f.limit {
+		if err := f.spill(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.written += int64(n)
+	return n, err
+}
+
+// spill copies what was written so far to the disk and continues there
+func (f *spillFile) spill() error {
+	disk, err := create(f.workDir.diskDir, filepath.Base(f.file.Name()))
+	if err != nil {
+		return err
+	}
+	if _, err = f.file.Seek(0, io.SeekStart); err == nil {
+		_, err = io.Copy(disk, f.file)
+	}
+	if err != nil {
+		disk.Close()
+		os.Remove(disk.Name())
+		return fmt.Errorf("failed to move %s to disk: %w", f.artifact, err)
+	}
+
+	f.file.Close()
+	os.Remove(f.file.Name())
+	f.file, f.spilled = disk, true
+	f.workDir.release(f.jobID, f.artifact)
+	observability.GetLogger().Debug("file larger than its memory reservation moved to disk",
+		zap.String("path", disk.Name()),
+		zap.Int64("reserved_bytes", f.limit),
+	)
+	return nil
+}
+
+// Close keeps only the bytes written reserved for a file left in memory
+func (f *spillFile) Close() error {
+	if !f.spilled {
+		f.workDir.settle(f.jobID, f.artifact, f.written)
+	}
+	return f.file.Close()
+}
+
+// Name is where the file is, in memory or on disk once spilled
+func (f *spillFile) Name() string {
+	return f.file.Name()
+}
+
+// Processor routes the videos placed in the memory directory to memory, a processor working
+// in that directory, while their frames fit in it; the others go to disk
+func (w *MemoryWorkDir) Processor(memory, disk port.VideoProcessorPort) port.VideoProcessorPort {
+	return &memoryRoutedProcessor{workDir: w, dir: filepath.Clean(w.dir), memory: memory, disk: disk}
+}
+
+type memoryRoutedProcessor struct {
+	workDir *MemoryWorkDir
+	dir     string
+	memory  port.VideoProcessorPort
+	disk    port.VideoProcessorPort
+}
+
+func (p *memoryRoutedProcessor) inMemory(videoPath string) bool {
+	return strings.HasPrefix(filepath.Clean(videoPath), p.dir+string(filepath.Separator))
+}
+
+// route reserves the frames of a video in memory for a stage of jobID, returning the processor
+// to run it with and the release of the reservation
+func (p *memoryRoutedProcessor) route(jobID, stage, videoPath string) (port.VideoProcessorPort, func()) {
+	if !p.inMemory(videoPath) {
+		return p.disk, func() {}
+	}
+	info, err := os.Stat(videoPath)
+	if err != nil || !p.workDir.reserve(jobID, stage, info.Size()*p.workDir.headroom) {
+		observability.GetLogger().Debug("no room for the frames in memory, extracting on disk",
+			zap.String("job_id", jobID),
+			zap.String("stage", stage),
+		)
+		return p.disk, func() {}
+	}
+	return p.memory, func() { p.workDir.release(jobID, stage) }
+}
+
+func (p *memoryRoutedProcessor) ProcessVideo(ctx context.Context, request domain.ProcessingRequest) (domain.ProcessingResult, error) {
+	processor, release := p.route(request.JobID, framesBudget, request.VideoPath)
+	defer release()
+	return processor.ProcessVideo(ctx, request)
+}
+
+func (p *memoryRoutedProcessor) GenerateSpriteSheet(ctx context.Context, request domain.ProcessingRequest) (domain.ProcessingResult, error) {
+	processor, release := p.route(request.JobID, spritesBudget, request.VideoPath)
+	defer release()
+	return processor.GenerateSpriteSheet(ctx, request)
+}
+
+func (p *memoryRoutedProcessor) ProbeVideo(ctx context.Context, videoPath string) (domain.VideoInfo, error) {
+	if p.inMemory(videoPath) {
+		return p.memory.ProbeVideo(ctx, videoPath)
+	}
+	return p.disk.ProbeVideo(ctx, videoPath)
+}
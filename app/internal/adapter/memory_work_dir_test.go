@@ -0,0 +1,296 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/port"
+)
+
+func TestMemoryWorkDir_CreateVideo(t *testing.T) {
+	memoryDir, diskDir := filepath.Join(t.TempDir(), "memory"), filepath.Join(t.TempDir(), "disk")
+	workDir := NewMemoryWorkDir(memoryDir, diskDir, 1000)
+	workDir.free = func(dir string) (int64, error) { return 1500, nil }
+
+	tests := []struct {
+		name  string
+		jobID string
+		size  int64
+		want  string
+	}{
+		{"small", "job1", 400, memoryDir},
+		{"over threshold", "job2", 1001, diskDir},
+		// 400 of the 1500 free bytes are reserved by job1
+		{"no room left", "job3", 1200, diskDir},
+		{"unknown size", "job4", 0, memoryDir},
+		{"replaces the reservation of the file", "job1", 500, memoryDir},
+	}
+	var files []port.VideoFile
+	for _, tt := range tests {
+		file, err := workDir.CreateVideo(tt.jobID, "video_"+tt.jobID+".mp4", tt.size)
+		if err != nil {
+			t.Fatalf("%s: CreateVideo failed: %v", tt.name, err)
+		}
+		files = append(files, file)
+		if got := filepath.Dir(file.Name()); got != tt.want {
+			t.Errorf("%s: expected %s, got %s", tt.name, tt.want, got)
+		}
+	}
+	if reserved := workDir.Reserved(); reserved != 500+1000 {
+		t.Errorf("Expected the videos of job1 and job4 reserved, got %d", reserved)
+	}
+	for _, file := range files {
+		file.Close()
+	}
+	if reserved := workDir.Reserved(); reserved != 0 {
+		t.Errorf("Expected the reservations of the empty videos settled to nothing, got %d", reserved)
+	}
+
+	workDir.free = func(dir string) (int64, error) { return 0, errors.New("statfs failed") }
+	file, err := workDir.CreateVideo("job5", "video_job5.mp4", 10)
+	if err != nil {
+		t.Fatalf("CreateVideo failed: %v", err)
+	}
+	file.Close()
+	if filepath.Dir(file.Name()) != diskDir {
+		t.Errorf("Expected disk when free space is unknown, got %s", file.Name())
+	}
+}
+
+func TestMemoryWorkDir_CountsWrittenBytesOnce(t *testing.T) {
+	memoryDir, diskDir := filepath.Join(t.TempDir(), "memory"), filepath.Join(t.TempDir(), "disk")
+	workDir := NewMemoryWorkDir(memoryDir, diskDir, 1000)
+	// The written bytes leave the free space, as on a tmpfs
+	workDir.free = func(dir string) (int64, error) { return 2000 - usedBytes(memoryDir), nil }
+
+	first, err := workDir.CreateVideo("job1", "video_job1.mp4", 1000)
+	if err != nil {
+		t.Fatalf("CreateVideo failed: %v", err)
+	}
+	defer first.Close()
+	if _, err := first.Write(make([]byte, 1000)); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	// 1000 bytes are free and job1 has nothing left to write
+	second, err := workDir.CreateVideo("job2", "video_job2.mp4", 1000)
+	if err != nil {
+		t.Fatalf("CreateVideo failed: %v", err)
+	}
+	defer second.Close()
+	if filepath.Dir(second.Name()) != memoryDir {
+		t.Errorf("Expected the written video counted once against the free space, got %s", second.Name())
+	}
+
+	third, err := workDir.CreateVideo("job3", "video_job3.mp4", 1)
+	if err != nil {
+		t.Fatalf("CreateVideo failed: %v", err)
+	}
+	defer third.Close()
+	if filepath.Dir(third.Name()) != diskDir {
+		t.Errorf("Expected disk once the reservations take the free space, got %s", third.Name())
+	}
+}
+
+func TestMemoryWorkDir_Spill(t *testing.T) {
+	memoryDir, diskDir := filepath.Join(t.TempDir(), "memory"), filepath.Join(t.TempDir(), "disk")
+	workDir := NewMemoryWorkDir(memoryDir, diskDir, 10)
+	workDir.free = func(dir string) (int64, error) { return 1000, nil }
+
+	file, err := workDir.CreateVideo("job", "video_job.mp4", 0)
+	if err != nil {
+		t.Fatalf("CreateVideo failed: %v", err)
+	}
+	if workDir.Reserved() != 10 {
+		t.Errorf("Expected the largest video reserved, got %d", workDir.Reserved())
+	}
+
+	if _, err := io.Copy(file, strings.NewReader("0123456789")); err != nil {
+		t.Fatalf("Copy failed: %v", err)
+	}
+	if filepath.Dir(file.Name()) != memoryDir {
+		t.Errorf("Expected a video up to the threshold in memory, got %s", file.Name())
+	}
+
+	memoryPath := file.Name()
+	if _, err := file.Write([]byte("abc")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	file.Close()
+
+	if filepath.Dir(file.Name()) != diskDir {
+		t.Errorf("Expected the video past the threshold moved to disk, got %s", file.Name())
+	}
+	if data, _ := os.ReadFile(file.Name()); string(data) != "0123456789abc" {
+		t.Errorf("Expected the whole video on disk, got %q", data)
+	}
+	if _, err := os.Stat(memoryPath); !os.IsNotExist(err) {
+		t.Errorf("Expected the memory copy removed, got %v", err)
+	}
+	if workDir.Reserved() != 0 {
+		t.Errorf("Expected the reservation released once spilled, got %d", workDir.Reserved())
+	}
+}
+
+func TestMemoryWorkDir_CreateArtifact(t *testing.T) {
+	memoryDir, diskDir := filepath.Join(t.TempDir(), "memory"), filepath.Join(t.TempDir(), "disk")
+	workDir := NewMemoryWorkDir(memoryDir, diskDir, 10)
+	workDir.free = func(dir string) (int64, error) { return 100, nil }
+
+	archive, err := workDir.CreateArtifact("job", "frames_job.zip", 80)
+	if err != nil {
+		t.Fatalf("CreateArtifact failed: %v", err)
+	}
+	if filepath.Dir(archive.Name()) != memoryDir {
+		t.Errorf("Expected an archive that fits in memory, got %s", archive.Name())
+	}
+	if _, err := archive.Write(make([]byte, 30)); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	archive.Close()
+	if reserved := workDir.Reserved(); reserved != 30 {
+		t.Errorf("Expected the reservation settled to the archive size, got %d", reserved)
+	}
+
+	// The 100 free bytes stand for 130, 30 of them written, against the 30 reserved
+	large, err := workDir.CreateArtifact("job", "sprites_job.zip", 101)
+	if err != nil {
+		t.Fatalf("CreateArtifact failed: %v", err)
+	}
+	large.Close()
+	if filepath.Dir(large.Name()) != diskDir {
+		t.Errorf("Expected an archive that does not fit on disk, got %s", large.Name())
+	}
+
+	grown, err := workDir.CreateArtifact("job", "frames_job.part2.zip", 5)
+	if err != nil {
+		t.Fatalf("CreateArtifact failed: %v", err)
+	}
+	if _, err := grown.Write(make([]byte, 6)); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	grown.Close()
+	if filepath.Dir(grown.Name()) != diskDir {
+		t.Errorf("Expected an archive past its reservation moved to disk, got %s", grown.Name())
+	}
+	if reserved := workDir.Reserved(); reserved != 30 {
+		t.Errorf("Expected the reservation of the moved archive released, got %d", reserved)
+	}
+}
+
+func TestMemoryWorkDir_SpillFails(t *testing.T) {
+	memoryDir, diskDir := filepath.Join(t.TempDir(), "memory"), filepath.Join(t.TempDir(), "disk")
+	workDir := NewMemoryWorkDir(memoryDir, diskDir, 10)
+	workDir.free = func(dir string) (int64, error) { return 1000, nil }
+
+	file, err := workDir.CreateVideo("job", "video_job.mp4", 0)
+	if err != nil {
+		t.Fatalf("CreateVideo failed: %v", err)
+	}
+	if _, err := file.Write([]byte("0123456789")); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	// The memory copy can no longer be read back
+	file.(*spillFile).file.Close()
+
+	if _, err := file.Write([]byte("abc")); err == nil {
+		t.Fatal("Expected an error when the video cannot be moved to disk")
+	}
+	if _, err := os.Stat(filepath.Join(diskDir, "video_job.mp4")); !os.IsNotExist(err) {
+		t.Errorf("Expected the partial disk copy removed, got %v", err)
+	}
+}
+
+func TestMemoryWorkDir_ReleaseClips(t *testing.T) {
+	workDir := NewMemoryWorkDir(filepath.Join(t.TempDir(), "memory"), t.TempDir(), 100)
+	workDir.free = func(dir string) (int64, error) { return 1 << 20, nil }
+
+	for _, jobID := range []string{"job_1", "job_2", "job2_1"} {
+		file, err := workDir.CreateVideo(jobID, "video_"+jobID+".mp4", 50)
+		if err != nil {
+			t.Fatalf("CreateVideo failed: %v", err)
+		}
+		defer file.Close()
+	}
+
+	workDir.Release("job")
+	if reserved := workDir.Reserved(); reserved != 50 {
+		t.Errorf("Expected only the clips of the job released, got %d reserved", reserved)
+	}
+}
+
+func TestMemoryWorkDir_FreeBytes(t *testing.T) {
+	free, err := freeBytes(t.TempDir())
+	if err != nil {
+		t.Skipf("free space not available: %v", err)
+	}
+	if free <= 0 {
+		t.Errorf("Expected free space in the temp directory, got %d", free)
+	}
+}
+
+// recordingProcessor records which processor handled each call and what was reserved then
+type recordingProcessor struct {
+	port.VideoProcessorPort
+	name     string
+	calls    *[]string
+	workDir  *MemoryWorkDir
+	reserved *[]int64
+}
+
+func (p recordingProcessor) ProcessVideo(ctx context.Context, request domain.ProcessingRequest) (domain.ProcessingResult, error) {
+	*p.calls = append(*p.calls, p.name)
+	*p.reserved = append(*p.reserved, p.workDir.Reserved())
+	return domain.ProcessingResult{}, nil
+}
+
+func (p recordingProcessor) ProbeVideo(ctx context.Context, videoPath string) (domain.VideoInfo, error) {
+	*p.calls = append(*p.calls, p.name)
+	return domain.VideoInfo{}, nil
+}
+
+func TestMemoryWorkDir_Processor(t *testing.T) {
+	memoryDir, diskDir := filepath.Join(t.TempDir(), "memory"), filepath.Join(t.TempDir(), "disk")
+	workDir := NewMemoryWorkDir(memoryDir, diskDir, 1000).WithHeadroom(6)
+	workDir.free = func(dir string) (int64, error) { return 100, nil }
+
+	var calls []string
+	var reserved []int64
+	processor := workDir.Processor(
+		recordingProcessor{name: "memory", calls: &calls, workDir: workDir, reserved: &reserved},
+		recordingProcessor{name: "disk", calls: &calls, workDir: workDir, reserved: &reserved},
+	)
+
+	small, large := filepath.Join(memoryDir, "video_job.mp4"), filepath.Join(memoryDir, "video_job2.mp4")
+	for path, size := range map[string]int{small: 20, large: 30} {
+		if err := os.MkdirAll(memoryDir, 0777); err != nil {
+			t.Fatalf("MkdirAll failed: %v", err)
+		}
+		if err := os.WriteFile(path, make([]byte, size), 0644); err != nil {
+			t.Fatalf("Failed to write video: %v", err)
+		}
+	}
+
+	ctx := context.Background()
+	processor.ProcessVideo(ctx, domain.ProcessingRequest{JobID: "job", VideoPath: small})
+	// The frames of 30 bytes times 6 do not fit in the 150 bytes free and written
+	processor.ProcessVideo(ctx, domain.ProcessingRequest{JobID: "job2", VideoPath: large})
+	processor.ProcessVideo(ctx, domain.ProcessingRequest{JobID: "job3", VideoPath: filepath.Join(diskDir, "video_job3.mp4")})
+	processor.ProbeVideo(ctx, memoryDir+"-other/video_job.mp4")
+
+	if len(calls) != 4 || calls[0] != "memory" || calls[1] != "disk" || calls[2] != "disk" || calls[3] != "disk" {
+		t.Errorf("Expected memory, disk, disk, disk, got %v", calls)
+	}
+	if len(reserved) != 3 || reserved[0] != 120 || reserved[1] != 0 {
+		t.Errorf("Expected the frames reserved only while extracted in memory, got %v", reserved)
+	}
+	if workDir.Reserved() != 0 {
+		t.Errorf("Expected the frames released after the stage, got %d", workDir.Reserved())
+	}
+}
@@ -20,3 +20,7 @@ func NewMessageAdapter(service message.MessageService) port.MessagePort {
 func (a *MessageAdapter) SendMessage(ctx context.Context, queueURL string, messageBody string) (string, error) {
 	return a.service.SendMessage(ctx, queueURL, messageBody)
 }
+
+func (a *MessageAdapter) SendMessageWithAttributes(ctx context.Context, queueURL string, messageBody string, attributes map[string]string) (string, error) {
+	return a.service.SendMessageWithAttributes(ctx, queueURL, messageBody, attributes)
+}
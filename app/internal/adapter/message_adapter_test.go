@@ -9,7 +9,8 @@ import (
 
 // Mock MessageService
 type mockMessageService struct {
-	sendMessageFunc func(ctx context.Context, queueURL string, messageBody string) (string, error)
+	sendMessageFunc               func(ctx context.Context, queueURL string, messageBody string) (string, error)
+	sendMessageWithAttributesFunc func(ctx context.Context, queueURL string, messageBody string, attributes map[string]string) (string, error)
 }
 
 func (m *mockMessageService) SendMessage(ctx context.Context, queueURL string, messageBody string) (string, error) {
@@ -19,6 +20,13 @@ func (m *mockMessageService) SendMessage(ctx context.Context, queueURL string, m
 	return "", nil
 }
 
+func (m *mockMessageService) SendMessageWithAttributes(ctx context.Context, queueURL string, messageBody string, attributes map[string]string) (string, error) {
+	if m.sendMessageWithAttributesFunc != nil {
+		return m.sendMessageWithAttributesFunc(ctx, queueURL, messageBody, attributes)
+	}
+	return "", nil
+}
+
 func TestNewMessageAdapter(t *testing.T) {
 	mock := &mockMessageService{}
 
@@ -208,3 +216,27 @@ func TestMessageAdapter_SendMessage_LargeBody(t *testing.T) {
 		t.Error("Large body was not received correctly")
 	}
 }
+
+func TestMessageAdapter_SendMessageWithAttributes(t *testing.T) {
+	var receivedAttributes map[string]string
+	mock := &mockMessageService{
+		sendMessageWithAttributesFunc: func(ctx context.Context, queueURL string, messageBody string, attributes map[string]string) (string, error) {
+			receivedAttributes = attributes
+			return "msg-attr", nil
+		},
+	}
+
+	adapter := NewMessageAdapter(mock)
+	ctx := context.Background()
+
+	messageID, err := adapter.SendMessageWithAttributes(ctx, "queue-url", "body", map[string]string{"signature": "abc"})
+	if err != nil {
+		t.Fatalf("SendMessageWithAttributes failed: %v", err)
+	}
+	if messageID != "msg-attr" {
+		t.Errorf("Expected msg-attr, got %s", messageID)
+	}
+	if receivedAttributes["signature"] != "abc" {
+		t.Errorf("Expected signature attribute to be forwarded, got %v", receivedAttributes)
+	}
+}
@@ -0,0 +1,121 @@
+package adapter
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/port"
+)
+
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+// Chunks needed to decode and color the image; everything else (text, EXIF, timestamps,
+// ICC profile names) can carry details about the source and is dropped
+var pngKeptChunks = map[string]bool{
+	"IHDR": true,
+	"PLTE": true,
+	"tRNS": true,
+	"gAMA": true,
+	"cHRM": true,
+	"sRGB": true,
+	"IDAT": true,
+	"IEND": true,
+}
+
+// MetadataScrubber strips the embedded metadata of PNG frames and writes only controlled
+// fields: the process id and the processing time. Other formats are left untouched
+type MetadataScrubber struct {
+	now func() time.Time
+}
+
+func NewMetadataScrubber() port.FramePostProcessorPort {
+	return &MetadataScrubber{now: time.Now}
+}
+
+func (s *MetadataScrubber) PostProcess(ctx context.Context, framePaths []string, options domain.FrameOptions) error {
+	fields := [][2]string{{"process_id", options.ProcessID}, {"timestamp", s.now().UTC().Format(time.RFC3339)}}
+
+	for _, frame := range framePaths {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if !strings.EqualFold(filepath.Ext(frame), ".png") {
+			continue
+		}
+
+		data, err := os.ReadFile(frame)
+		if err != nil {
+			return err
+		}
+		scrubbed, err := scrubPNG(data, fields)
+		if err != nil {
+			return fmt.Errorf("failed to scrub %s: %w", filepath.Base(frame), err)
+		}
+		if err := os.WriteFile(frame, scrubbed, 0644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// scrubPNG rewrites the chunk list keeping only pngKeptChunks and adds a tEXt chunk per
+// non-empty field right after IHDR. Pixel data is copied as is
+func scrubPNG(data []byte, fields [][2]string) ([]byte, error) {
+	if !bytes.HasPrefix(data, pngSignature) {
+		return nil, fmt.Errorf("not a png file")
+	}
+
+	var out bytes.Buffer
+	out.Write(pngSignature)
+
+	for rest := data[len(pngSignature):]; len(rest) > 0; {
+		if len(rest) < 12 {
+			return nil, fmt.Errorf("truncated png chunk")
+		}
+		length := binary.BigEndian.Uint32(rest[:4])
+		if uint64(length)+12 > uint64(len(rest)) {
+			return nil, fmt.Errorf("truncated png chunk")
+		}
+		chunk := rest[:12+length]
+		chunkType := string(chunk[4:8])
+		rest = rest[12+length:]
+
+		if !pngKeptChunks[chunkType] {
+			continue
+		}
+		out.Write(chunk)
+
+		if chunkType == "IHDR" {
+			for _, field := range fields {
+				if field[1] != "" {
+					writePNGChunk(&out, "tEXt", []byte(field[0]+"\x00"+field[1]))
+				}
+			}
+		}
+		if chunkType == "IEND" {
+			break
+		}
+	}
+	return out.Bytes(), nil
+}
+
+func writePNGChunk(out *bytes.Buffer, chunkType string, data []byte) {
+	var header [8]byte
+	binary.BigEndian.PutUint32(header[:4], uint32(len(data)))
+	copy(header[4:], chunkType)
+	out.Write(header[:])
+	out.Write(data)
+
+	crc := crc32.NewIEEE()
+	crc.Write(header[4:])
+	crc.Write(data)
+	binary.Write(out, binary.BigEndian, crc.Sum32())
+}
@@ -0,0 +1,86 @@
+package adapter
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+)
+
+// pngWithMetadata encodes a small PNG and inserts source metadata chunks after IHDR
+func pngWithMetadata(t *testing.T) []byte {
+	t.Helper()
+	var encoded bytes.Buffer
+	if err := png.Encode(&encoded, image.NewGray(image.Rect(0, 0, 4, 4))); err != nil {
+		t.Fatalf("Failed to encode png: %v", err)
+	}
+
+	data := encoded.Bytes()
+	ihdrEnd := len(pngSignature) + 12 + 13
+	var out bytes.Buffer
+	out.Write(data[:ihdrEnd])
+	writePNGChunk(&out, "tEXt", []byte("Comment\x00recorded on camera XYZ at home"))
+	writePNGChunk(&out, "eXIf", []byte("MM\x00*gps"))
+	out.Write(data[ihdrEnd:])
+	return out.Bytes()
+}
+
+func TestScrubPNG(t *testing.T) {
+	scrubbed, err := scrubPNG(pngWithMetadata(t), [][2]string{{"process_id", "proc-1"}, {"timestamp", ""}})
+	if err != nil {
+		t.Fatalf("scrubPNG failed: %v", err)
+	}
+
+	if bytes.Contains(scrubbed, []byte("camera XYZ")) || bytes.Contains(scrubbed, []byte("eXIf")) {
+		t.Error("Expected source metadata to be removed")
+	}
+	if !bytes.Contains(scrubbed, []byte("process_id\x00proc-1")) {
+		t.Error("Expected the process id to be written")
+	}
+	if bytes.Contains(scrubbed, []byte("timestamp")) {
+		t.Error("Expected empty fields to be skipped")
+	}
+	if _, err := png.Decode(bytes.NewReader(scrubbed)); err != nil {
+		t.Errorf("Expected a valid png after scrubbing, got %v", err)
+	}
+}
+
+func TestScrubPNG_Invalid(t *testing.T) {
+	if _, err := scrubPNG([]byte("frame"), nil); err == nil {
+		t.Error("Expected error for a non-png file")
+	}
+	if _, err := scrubPNG(append(append([]byte{}, pngSignature...), 0, 0, 0, 99), nil); err == nil {
+		t.Error("Expected error for a truncated chunk")
+	}
+}
+
+func TestMetadataScrubber_PostProcess(t *testing.T) {
+	dir := t.TempDir()
+	frame := filepath.Join(dir, "frame_0001.png")
+	if err := os.WriteFile(frame, pngWithMetadata(t), 0644); err != nil {
+		t.Fatalf("Failed to write frame: %v", err)
+	}
+	other := filepath.Join(dir, "subtitle_0.vtt")
+	if err := os.WriteFile(other, []byte("WEBVTT"), 0644); err != nil {
+		t.Fatalf("Failed to write subtitle: %v", err)
+	}
+
+	scrubber := &MetadataScrubber{now: func() time.Time { return time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC) }}
+	if err := scrubber.PostProcess(context.Background(), []string{frame, other}, domain.FrameOptions{ProcessID: "proc-1"}); err != nil {
+		t.Fatalf("PostProcess failed: %v", err)
+	}
+
+	data := readFile(t, frame)
+	if !bytes.Contains([]byte(data), []byte("timestamp\x002024-05-01T12:00:00Z")) {
+		t.Error("Expected the processing timestamp to be written")
+	}
+	if readFile(t, other) != "WEBVTT" {
+		t.Error("Expected non-png files to be left untouched")
+	}
+}
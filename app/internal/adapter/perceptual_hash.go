@@ -0,0 +1,95 @@
+package adapter
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// hashSize is the side of the grayscale frames the perceptual hashes are computed from
+const hashSize = 32
+
+// dctCosines[u][x] is the DCT-II basis cos((2x+1)uπ/2N) for the 8 lowest frequencies
+var dctCosines = func() [8][hashSize]float64 {
+	var table [8][hashSize]float64
+	for u := range table {
+		for x := range hashSize {
+			table[u][x] = math.Cos(float64(2*x+1) * float64(u) * math.Pi / (2 * hashSize))
+		}
+	}
+	return table
+}()
+
+// pHash is the 64-bit DCT hash of a hashSize x hashSize grayscale frame: each of the 8x8 lowest
+// frequencies sets its bit when above their median. The DC term is left out of the median, so
+// brightness changes keep the hash
+func pHash(pixels []byte) uint64 {
+	// Rows first, then columns, only for the frequencies kept
+	var rows [hashSize][8]float64
+	for y := range hashSize {
+		for u := range 8 {
+			var sum float64
+			for x := range hashSize {
+				sum += float64(pixels[y*hashSize+x]) * dctCosines[u][x]
+			}
+			rows[y][u] = sum
+		}
+	}
+	var coefficients [64]float64
+	for v := range 8 {
+		for u := range 8 {
+			var sum float64
+			for y := range hashSize {
+				sum += rows[y][u] * dctCosines[v][y]
+			}
+			coefficients[v*8+u] = sum
+		}
+	}
+
+	sorted := make([]float64, 63)
+	copy(sorted, coefficients[1:])
+	sort.Float64s(sorted)
+	median := (sorted[31] + sorted[32]) / 2
+
+	var hash uint64
+	for i, coefficient := range coefficients {
+		if coefficient > median {
+			hash |= 1 << (63 - i)
+		}
+	}
+	return hash
+}
+
+// dHash is the 64-bit gradient hash of a hashSize x hashSize grayscale frame: the frame is
+// shrunk to 9x8 and each bit is set when a cell is brighter than its right neighbour
+func dHash(pixels []byte) uint64 {
+	var cells [8][9]float64
+	for row := range 8 {
+		y0, y1 := row*hashSize/8, (row+1)*hashSize/8
+		for col := range 9 {
+			x0, x1 := col*hashSize/9, (col+1)*hashSize/9
+			var sum float64
+			for y := y0; y < y1; y++ {
+				for x := x0; x < x1; x++ {
+					sum += float64(pixels[y*hashSize+x])
+				}
+			}
+			cells[row][col] = sum / float64((y1-y0)*(x1-x0))
+		}
+	}
+
+	var hash uint64
+	for row := range 8 {
+		for col := range 8 {
+			if cells[row][col] > cells[row][col+1] {
+				hash |= 1 << (63 - (row*8 + col))
+			}
+		}
+	}
+	return hash
+}
+
+// formatHash writes a hash as 16 hex digits, the form compared downstream by Hamming distance
+func formatHash(hash uint64) string {
+	return fmt.Sprintf("%016x", hash)
+}
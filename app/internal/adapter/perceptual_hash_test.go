@@ -0,0 +1,51 @@
+package adapter
+
+import (
+	"math/bits"
+	"testing"
+)
+
+// gradientFrame is a hashSize x hashSize frame whose brightness follows value(x, y)
+func gradientFrame(value func(x, y int) int) []byte {
+	pixels := make([]byte, hashSize*hashSize)
+	for y := range hashSize {
+		for x := range hashSize {
+			pixels[y*hashSize+x] = byte(value(x, y))
+		}
+	}
+	return pixels
+}
+
+func TestDHash(t *testing.T) {
+	brightening := gradientFrame(func(x, y int) int { return x * 8 })
+	darkening := gradientFrame(func(x, y int) int { return 255 - x*8 })
+
+	if hash := dHash(brightening); hash != 0 {
+		t.Errorf("Expected no bit set for a brightening frame, got %016x", hash)
+	}
+	if hash := dHash(darkening); hash != ^uint64(0) {
+		t.Errorf("Expected every bit set for a darkening frame, got %016x", hash)
+	}
+}
+
+func TestPHash(t *testing.T) {
+	frame := gradientFrame(func(x, y int) int { return (x*x + y*3) % 200 })
+	brighter := gradientFrame(func(x, y int) int { return (x*x+y*3)%200 + 40 })
+	other := gradientFrame(func(x, y int) int { return (y*y + x*5) % 200 })
+
+	if pHash(frame) != pHash(brighter) {
+		t.Errorf("Expected a brightness change to keep the hash, got %016x and %016x", pHash(frame), pHash(brighter))
+	}
+	if distance := bits.OnesCount64(pHash(frame) ^ pHash(other)); distance < 10 {
+		t.Errorf("Expected distinct frames to be far apart, got a distance of %d", distance)
+	}
+	if hash := pHash(make([]byte, hashSize*hashSize)); hash != 0 {
+		t.Errorf("Expected a flat frame to have no bit set, got %016x", hash)
+	}
+}
+
+func TestFormatHash(t *testing.T) {
+	if hash := formatHash(0xab); hash != "00000000000000ab" {
+		t.Errorf("Expected 16 hex digits, got %s", hash)
+	}
+}
@@ -0,0 +1,98 @@
+package adapter
+
+import (
+	"context"
+	"os"
+	"sync/atomic"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/port"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/observability"
+	"go.uber.org/zap"
+)
+
+// RoutingPolicy decides which videos ProcessorRouter offloads; a zero value disables a criterion
+type RoutingPolicy struct {
+	// MinBytes offloads the videos of at least this size
+	MinBytes int64
+
+	// MinSeconds offloads the videos at least this long, read with ProbeVideo
+	MinSeconds float64
+
+	// MaxLocalJobs offloads any video while this many are being processed locally
+	MaxLocalJobs int
+}
+
+// ProcessorRouter sends each video to the local processor or to the remote one, by its size,
+// its duration and the jobs already running locally. The backend chosen is counted in
+// worker_processing_backend_total and recorded in the job usage for the result message
+type ProcessorRouter struct {
+	local     port.VideoProcessorPort
+	remote    port.VideoProcessorPort
+	policy    RoutingPolicy
+	localJobs atomic.Int64
+}
+
+func NewProcessorRouter(local, remote port.VideoProcessorPort, policy RoutingPolicy) port.VideoProcessorPort {
+	return &ProcessorRouter{
+		local:  local,
+		remote: remote,
+		policy: policy,
+	}
+}
+
+// route picks the backend of a video and the reason. Options the remote service cannot apply
+// keep the video local whatever its size
+func (r *ProcessorRouter) route(ctx context.Context, videoPath string, options domain.FrameOptions) (string, string) {
+	if !remoteSupported(options) {
+		return domain.ProcessingBackendLocal, domain.RoutingReasonUnsupported
+	}
+	if r.policy.MinBytes > 0 {
+		if info, err := os.Stat(videoPath); err == nil && info.Size() >= r.policy.MinBytes {
+			return domain.ProcessingBackendRemote, domain.RoutingReasonSize
+		}
+	}
+	// A video ffprobe cannot read is left to the local processor, which reports why
+	if r.policy.MinSeconds > 0 {
+		if info, err := r.local.ProbeVideo(ctx, videoPath); err == nil && info.DurationSeconds >= r.policy.MinSeconds {
+			return domain.ProcessingBackendRemote, domain.RoutingReasonDuration
+		}
+	}
+	if r.policy.MaxLocalJobs > 0 && r.localJobs.Load() >= int64(r.policy.MaxLocalJobs) {
+		return domain.ProcessingBackendRemote, domain.RoutingReasonLoad
+	}
+	return domain.ProcessingBackendLocal, domain.RoutingReasonDefault
+}
+
+func (r *ProcessorRouter) ProcessVideo(ctx context.Context, request domain.ProcessingRequest) (domain.ProcessingResult, error) {
+	backend, reason := r.route(ctx, request.VideoPath, request.Options)
+	r.record(ctx, backend, reason)
+	if backend == domain.ProcessingBackendRemote {
+		return r.remote.ProcessVideo(ctx, request)
+	}
+
+	r.localJobs.Add(1)
+	defer r.localJobs.Add(-1)
+	return r.local.ProcessVideo(ctx, request)
+}
+
+// GenerateSpriteSheet always runs locally: the remote service only captures frames
+func (r *ProcessorRouter) GenerateSpriteSheet(ctx context.Context, request domain.ProcessingRequest) (domain.ProcessingResult, error) {
+	r.record(ctx, domain.ProcessingBackendLocal, domain.RoutingReasonUnsupported)
+	r.localJobs.Add(1)
+	defer r.localJobs.Add(-1)
+	return r.local.GenerateSpriteSheet(ctx, request)
+}
+
+func (r *ProcessorRouter) ProbeVideo(ctx context.Context, videoPath string) (domain.VideoInfo, error) {
+	return r.local.ProbeVideo(ctx, videoPath)
+}
+
+func (r *ProcessorRouter) record(ctx context.Context, backend, reason string) {
+	observability.RecordProcessingBackend(backend, reason)
+	observability.UsageFromContext(ctx).SetBackend(backend)
+	observability.FromContext(ctx).Info("processing backend chosen",
+		zap.String("backend", backend),
+		zap.String("reason", reason),
+	)
+}
@@ -0,0 +1,138 @@
+package adapter
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/observability"
+)
+
+// routedProcessor records the videos it processes and reports duration for every video
+type routedProcessor struct {
+	mu       sync.Mutex
+	videos   []string
+	duration float64
+	probeErr error
+	// started is signalled and block holds ProcessVideo until it is closed, when set
+	started chan struct{}
+	block   chan struct{}
+}
+
+func (p *routedProcessor) ProcessVideo(ctx context.Context, request domain.ProcessingRequest) (domain.ProcessingResult, error) {
+	p.mu.Lock()
+	p.videos = append(p.videos, request.JobID)
+	p.mu.Unlock()
+	if p.block != nil {
+		p.started <- struct{}{}
+		<-p.block
+	}
+	return domain.ProcessingResult{Artifacts: []string{"frames.zip"}, FrameCount: 1}, nil
+}
+
+func (p *routedProcessor) GenerateSpriteSheet(ctx context.Context, request domain.ProcessingRequest) (domain.ProcessingResult, error) {
+	return p.ProcessVideo(ctx, request)
+}
+
+func (p *routedProcessor) ProbeVideo(ctx context.Context, videoPath string) (domain.VideoInfo, error) {
+	return domain.VideoInfo{DurationSeconds: p.duration}, p.probeErr
+}
+
+func (p *routedProcessor) processed() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]string(nil), p.videos...)
+}
+
+func writeVideo(t *testing.T, size int) string {
+	path := filepath.Join(t.TempDir(), "video.mp4")
+	if err := os.WriteFile(path, bytes.Repeat([]byte{0}, size), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestProcessorRouter_ProcessVideo(t *testing.T) {
+	observability.InitLogger("test")
+	tests := []struct {
+		name     string
+		size     int
+		duration float64
+		probeErr error
+		options  domain.FrameOptions
+		want     string
+	}{
+		{"small and short", 10, 60, nil, domain.FrameOptions{}, domain.ProcessingBackendLocal},
+		{"large", 100, 60, nil, domain.FrameOptions{}, domain.ProcessingBackendRemote},
+		{"long", 10, 600, nil, domain.FrameOptions{}, domain.ProcessingBackendRemote},
+		{"unreadable", 10, 600, errors.New("invalid data"), domain.FrameOptions{}, domain.ProcessingBackendLocal},
+		{"large with unsupported options", 100, 600, nil, domain.FrameOptions{ToneMap: "hable"}, domain.ProcessingBackendLocal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			local := &routedProcessor{duration: tt.duration, probeErr: tt.probeErr}
+			remote := &routedProcessor{}
+			router := NewProcessorRouter(local, remote, RoutingPolicy{MinBytes: 100, MinSeconds: 300})
+
+			usage := &observability.JobUsage{}
+			ctx := observability.WithJobUsage(context.Background(), usage)
+			if _, err := router.ProcessVideo(ctx, domain.ProcessingRequest{JobID: "job", VideoPath: writeVideo(t, tt.size), Options: tt.options}); err != nil {
+				t.Fatalf("ProcessVideo failed: %v", err)
+			}
+
+			if usage.Backend() != tt.want {
+				t.Errorf("Expected backend %s, got %s", tt.want, usage.Backend())
+			}
+			if got := len(remote.processed()) == 1; got != (tt.want == domain.ProcessingBackendRemote) {
+				t.Errorf("Expected the video processed by the %s backend, local %v and remote %v", tt.want, local.processed(), remote.processed())
+			}
+		})
+	}
+}
+
+func TestProcessorRouter_Load(t *testing.T) {
+	observability.InitLogger("test")
+	local := &routedProcessor{started: make(chan struct{}), block: make(chan struct{})}
+	remote := &routedProcessor{}
+	router := NewProcessorRouter(local, remote, RoutingPolicy{MaxLocalJobs: 1})
+	videoPath := writeVideo(t, 10)
+
+	done := make(chan struct{})
+	go func() {
+		router.ProcessVideo(context.Background(), domain.ProcessingRequest{JobID: "first", VideoPath: videoPath})
+		close(done)
+	}()
+	<-local.started
+
+	router.ProcessVideo(context.Background(), domain.ProcessingRequest{JobID: "second", VideoPath: videoPath})
+	if got := remote.processed(); len(got) != 1 || got[0] != "second" {
+		t.Errorf("Expected the second video offloaded while the first runs locally, got %v", got)
+	}
+
+	close(local.block)
+	<-done
+	local.block = nil
+	router.ProcessVideo(context.Background(), domain.ProcessingRequest{JobID: "third", VideoPath: videoPath})
+	if got := local.processed(); len(got) != 2 || got[1] != "third" {
+		t.Errorf("Expected the third video local once the first finished, got %v", got)
+	}
+}
+
+func TestProcessorRouter_SpriteSheet(t *testing.T) {
+	observability.InitLogger("test")
+	local := &routedProcessor{}
+	remote := &routedProcessor{}
+	router := NewProcessorRouter(local, remote, RoutingPolicy{MinBytes: 1})
+
+	usage := &observability.JobUsage{}
+	ctx := observability.WithJobUsage(context.Background(), usage)
+	router.GenerateSpriteSheet(ctx, domain.ProcessingRequest{JobID: "sprite", VideoPath: writeVideo(t, 10)})
+	if len(local.processed()) != 1 || usage.Backend() != domain.ProcessingBackendLocal {
+		t.Errorf("Expected sprite sheets generated locally, got backend %s", usage.Backend())
+	}
+}
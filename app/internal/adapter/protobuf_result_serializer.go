@@ -0,0 +1,299 @@
+package adapter
+
+import (
+	"maps"
+	"math"
+	"slices"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/port"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// protobufKinds are the ProcessResult.Kind values of schemas/result.proto
+var protobufKinds = map[string]uint64{
+	domain.ResultKindSuccess: 1,
+	domain.ResultKindError:   2,
+	domain.ResultKindDryRun:  3,
+}
+
+// ProtobufResultSerializer encodes results as the ProcessResult message of schemas/result.proto.
+// The encoding is written by hand with protowire, so the build needs no generated code; field
+// numbers must follow the schema file
+type ProtobufResultSerializer struct{}
+
+func NewProtobufResultSerializer() port.ResultSerializerPort {
+	return &ProtobufResultSerializer{}
+}
+
+func (s *ProtobufResultSerializer) Serialize(result *domain.ProcessResult) ([]byte, error) {
+	var b []byte
+	b = appendProtoString(b, 1, result.ProcessID)
+	b = appendProtoVarint(b, 2, protobufKinds[result.Kind()])
+	if result.Kind() == domain.ResultKindSuccess {
+		b = appendProtoString(b, 3, result.FileBucket)
+		b = appendProtoString(b, 4, result.FileKey)
+	}
+	for _, key := range result.FileKeys {
+		b = protowire.AppendTag(b, 5, protowire.BytesType)
+		b = protowire.AppendString(b, key)
+	}
+	b = appendProtoString(b, 6, result.OutputType)
+	if result.ConfirmRequired {
+		b = appendProtoVarint(b, 7, 1)
+	}
+	if result.Kind() == domain.ResultKindError {
+		b = appendProtoString(b, 8, result.ErrorMessage())
+		b = appendProtoString(b, 9, domain.ErrorCode(result.Error))
+	}
+
+	if estimate := result.Estimate; estimate != nil {
+		var e []byte
+		if estimate.DurationSeconds != 0 {
+			e = protowire.AppendTag(e, 1, protowire.Fixed64Type)
+			e = protowire.AppendFixed64(e, math.Float64bits(estimate.DurationSeconds))
+		}
+		e = appendProtoVarint(e, 2, uint64(int64(estimate.Width)))
+		e = appendProtoVarint(e, 3, uint64(int64(estimate.Height)))
+		e = appendProtoVarint(e, 4, uint64(int64(estimate.Count)))
+		e = appendProtoVarint(e, 5, uint64(estimate.Bytes))
+		b = protowire.AppendTag(b, 10, protowire.BytesType)
+		b = protowire.AppendBytes(b, e)
+	}
+
+	if worker := result.Worker; worker != nil {
+		var w []byte
+		w = appendProtoString(w, 1, worker.Hostname)
+		w = appendProtoString(w, 2, worker.Pod)
+		w = appendProtoString(w, 3, worker.Version)
+		w = appendProtoString(w, 4, worker.Commit)
+		b = protowire.AppendTag(b, 11, protowire.BytesType)
+		b = protowire.AppendBytes(b, w)
+	}
+
+	if retry := result.Retry; retry != nil && result.Kind() == domain.ResultKindError {
+		var r []byte
+		r = appendProtoVarint(r, 1, uint64(int64(retry.Attempt)))
+		r = appendProtoVarint(r, 2, uint64(int64(retry.MaxAttempts)))
+		if retry.WillRetry {
+			r = appendProtoVarint(r, 3, 1)
+		}
+		b = protowire.AppendTag(b, 13, protowire.BytesType)
+		b = protowire.AppendBytes(b, r)
+	}
+	if result.SLABreached {
+		b = appendProtoVarint(b, 14, 1)
+	}
+
+	if usage := result.Usage; usage != nil {
+		var u []byte
+		u = appendProtoVarint(u, 1, uint64(usage.S3GetRequests))
+		u = appendProtoVarint(u, 2, uint64(usage.S3PutRequests))
+		u = appendProtoVarint(u, 3, uint64(usage.BytesDownloaded))
+		u = appendProtoVarint(u, 4, uint64(usage.BytesUploaded))
+		u = appendProtoDouble(u, 5, usage.CPUSeconds)
+		u = appendProtoDouble(u, 6, usage.EstimatedCost)
+		b = protowire.AppendTag(b, 15, protowire.BytesType)
+		b = protowire.AppendBytes(b, u)
+	}
+
+	for _, item := range result.Items {
+		var i []byte
+		i = appendProtoString(i, 1, item.VideoKey)
+		if item.Success {
+			i = appendProtoVarint(i, 2, 1)
+		}
+		i = appendProtoString(i, 3, item.FileKey)
+		for _, key := range item.FileKeys {
+			i = protowire.AppendTag(i, 4, protowire.BytesType)
+			i = protowire.AppendString(i, key)
+		}
+		i = appendProtoVarint(i, 5, uint64(int64(item.Frames)))
+		i = appendProtoString(i, 6, item.Error)
+		i = appendProtoString(i, 7, item.ErrorCode)
+		b = protowire.AppendTag(b, 16, protowire.BytesType)
+		b = protowire.AppendBytes(b, i)
+	}
+	b = appendProtoString(b, 17, result.BatchStatus)
+
+	if loudness := result.Loudness; loudness != nil {
+		var l []byte
+		l = appendProtoDouble(l, 1, loudness.InputIntegrated)
+		l = appendProtoDouble(l, 2, loudness.InputTruePeak)
+		l = appendProtoDouble(l, 3, loudness.InputRange)
+		l = appendProtoDouble(l, 4, loudness.InputThreshold)
+		l = appendProtoDouble(l, 5, loudness.OutputIntegrated)
+		l = appendProtoDouble(l, 6, loudness.OutputTruePeak)
+		l = appendProtoDouble(l, 7, loudness.OutputRange)
+		l = appendProtoDouble(l, 8, loudness.TargetOffset)
+		b = protowire.AppendTag(b, 18, protowire.BytesType)
+		b = protowire.AppendBytes(b, l)
+	}
+
+	if qc := result.QC; qc != nil {
+		var q []byte
+		q = appendProtoDouble(q, 1, qc.DurationSeconds)
+		if qc.HasAudio {
+			q = appendProtoVarint(q, 2, 1)
+		}
+		q = appendProtoTimeWindows(q, 3, qc.BlackIntervals)
+		q = appendProtoTimeWindows(q, 4, qc.SilenceIntervals)
+		if qc.Truncated {
+			q = appendProtoVarint(q, 5, 1)
+		}
+		b = protowire.AppendTag(b, 19, protowire.BytesType)
+		b = protowire.AppendBytes(b, q)
+	}
+
+	for _, barcode := range result.Barcodes {
+		var d []byte
+		d = appendProtoString(d, 1, barcode.Format)
+		d = appendProtoString(d, 2, barcode.Value)
+		// Repeated scalars are packed in proto3
+		if len(barcode.Timestamps) > 0 {
+			var packed []byte
+			for _, timestamp := range barcode.Timestamps {
+				packed = protowire.AppendFixed64(packed, math.Float64bits(timestamp))
+			}
+			d = protowire.AppendTag(d, 3, protowire.BytesType)
+			d = protowire.AppendBytes(d, packed)
+		}
+		b = protowire.AppendTag(b, 20, protowire.BytesType)
+		b = protowire.AppendBytes(b, d)
+	}
+
+	if report := result.Vision; report != nil {
+		var v []byte
+		if report.Flagged {
+			v = appendProtoVarint(v, 1, 1)
+		}
+		for _, frame := range report.Frames {
+			var f []byte
+			f = appendProtoDouble(f, 1, frame.Time)
+			f = appendProtoVisionLabels(f, 2, frame.Labels)
+			f = appendProtoVisionLabels(f, 3, frame.Moderation)
+			v = protowire.AppendTag(v, 2, protowire.BytesType)
+			v = protowire.AppendBytes(v, f)
+		}
+		b = protowire.AppendTag(b, 21, protowire.BytesType)
+		b = protowire.AppendBytes(b, v)
+	}
+	b = appendProtoString(b, 22, result.VisionKey)
+
+	if transcription := result.Transcription; transcription != nil {
+		var t []byte
+		t = appendProtoString(t, 1, transcription.Provider)
+		t = appendProtoString(t, 2, transcription.JobName)
+		t = appendProtoString(t, 3, transcription.TranscriptKey)
+		t = appendProtoString(t, 4, transcription.MessageID)
+		b = protowire.AppendTag(b, 23, protowire.BytesType)
+		b = protowire.AppendBytes(b, t)
+	}
+
+	if source := result.Source; source != nil {
+		var s []byte
+		s = appendProtoVarint(s, 1, uint64(source.SizeBytes))
+		s = appendProtoString(s, 2, source.ETag)
+		s = appendProtoString(s, 3, source.ContentType)
+		s = appendProtoStringMap(s, 4, source.Metadata)
+		s = appendProtoString(s, 5, source.VersionID)
+		b = protowire.AppendTag(b, 24, protowire.BytesType)
+		b = protowire.AppendBytes(b, s)
+	}
+	b = appendProtoString(b, 25, result.ProcessingBackend)
+
+	if negotiated := result.Negotiated; negotiated != nil {
+		var n []byte
+		n = appendProtoString(n, 1, negotiated.Encoding)
+		n = appendProtoString(n, 2, negotiated.ImageFormat)
+		b = protowire.AppendTag(b, 26, protowire.BytesType)
+		b = protowire.AppendBytes(b, n)
+	}
+
+	if quarantine := result.Quarantine; quarantine != nil {
+		var q []byte
+		q = appendProtoString(q, 1, quarantine.Bucket)
+		q = appendProtoString(q, 2, quarantine.Key)
+		b = protowire.AppendTag(b, 27, protowire.BytesType)
+		b = protowire.AppendBytes(b, q)
+	}
+
+	// A map field is a repeated entry message with the key as field 1 and the value as field 2
+	for _, key := range slices.Sorted(maps.Keys(result.Metadata)) {
+		var entry []byte
+		entry = appendProtoString(entry, 1, key)
+		entry = appendProtoString(entry, 2, string(result.Metadata[key]))
+		b = protowire.AppendTag(b, 12, protowire.BytesType)
+		b = protowire.AppendBytes(b, entry)
+	}
+	return b, nil
+}
+
+func (s *ProtobufResultSerializer) ContentType() string {
+	return domain.ContentTypeProtobuf
+}
+
+// appendProtoString skips empty strings, which proto3 does not put on the wire
+func appendProtoString(b []byte, number protowire.Number, value string) []byte {
+	if value == "" {
+		return b
+	}
+	b = protowire.AppendTag(b, number, protowire.BytesType)
+	return protowire.AppendString(b, value)
+}
+
+// appendProtoDouble skips zero, which proto3 does not put on the wire
+func appendProtoDouble(b []byte, number protowire.Number, value float64) []byte {
+	if value == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, number, protowire.Fixed64Type)
+	return protowire.AppendFixed64(b, math.Float64bits(value))
+}
+
+// appendProtoVarint skips zero, which proto3 does not put on the wire
+func appendProtoVarint(b []byte, number protowire.Number, value uint64) []byte {
+	if value == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, number, protowire.VarintType)
+	return protowire.AppendVarint(b, value)
+}
+
+// appendProtoStringMap writes a map<string, string> field, a repeated entry message with the
+// key as field 1 and the value as field 2, in key order
+func appendProtoStringMap(b []byte, number protowire.Number, values map[string]string) []byte {
+	for _, key := range slices.Sorted(maps.Keys(values)) {
+		var entry []byte
+		entry = appendProtoString(entry, 1, key)
+		entry = appendProtoString(entry, 2, values[key])
+		b = protowire.AppendTag(b, number, protowire.BytesType)
+		b = protowire.AppendBytes(b, entry)
+	}
+	return b
+}
+
+// appendProtoVisionLabels writes a repeated VisionLabel field
+func appendProtoVisionLabels(b []byte, number protowire.Number, labels []domain.VisionLabel) []byte {
+	for _, label := range labels {
+		var l []byte
+		l = appendProtoString(l, 1, label.Name)
+		l = appendProtoString(l, 2, label.Parent)
+		l = appendProtoDouble(l, 3, label.Confidence)
+		b = protowire.AppendTag(b, number, protowire.BytesType)
+		b = protowire.AppendBytes(b, l)
+	}
+	return b
+}
+
+// appendProtoTimeWindows writes a repeated TimeWindow field
+func appendProtoTimeWindows(b []byte, number protowire.Number, windows []domain.TimeWindow) []byte {
+	for _, window := range windows {
+		var w []byte
+		w = appendProtoDouble(w, 1, window.Start)
+		w = appendProtoDouble(w, 2, window.End)
+		b = protowire.AppendTag(b, number, protowire.BytesType)
+		b = protowire.AppendBytes(b, w)
+	}
+	return b
+}
@@ -0,0 +1,334 @@
+package adapter
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// decodeProto splits a message into its fields, keeping the raw value of each occurrence
+func decodeProto(t *testing.T, b []byte) map[protowire.Number][][]byte {
+	t.Helper()
+	fields := map[protowire.Number][][]byte{}
+	for len(b) > 0 {
+		number, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			t.Fatalf("Invalid tag: %v", protowire.ParseError(n))
+		}
+		b = b[n:]
+
+		var value []byte
+		switch typ {
+		case protowire.VarintType:
+			v, m := protowire.ConsumeVarint(b)
+			value, n = protowire.AppendVarint(nil, v), m
+		case protowire.Fixed64Type:
+			_, n = protowire.ConsumeFixed64(b)
+			value = b[:n]
+		case protowire.BytesType:
+			value, n = protowire.ConsumeBytes(b)
+		default:
+			t.Fatalf("Unexpected wire type %d", typ)
+		}
+		if n < 0 {
+			t.Fatalf("Invalid field %d: %v", number, protowire.ParseError(n))
+		}
+		fields[number] = append(fields[number], value)
+		b = b[n:]
+	}
+	return fields
+}
+
+func protoVarint(value []byte) uint64 {
+	v, _ := protowire.ConsumeVarint(value)
+	return v
+}
+
+func TestProtobufResultSerializer_Success(t *testing.T) {
+	result := &domain.ProcessResult{
+		ProcessID:       "123",
+		Success:         true,
+		FileBucket:      "output",
+		FileKey:         "processed/frames_123.part1.zip",
+		FileKeys:        []string{"processed/frames_123.part1.zip", "processed/frames_123.part2.zip"},
+		OutputType:      domain.OutputTypeFrames,
+		ConfirmRequired: true,
+		Worker:          &domain.WorkerIdentity{Hostname: "host", Version: "v1.0.0", Commit: "abc"},
+		Metadata:        map[string]json.RawMessage{"b": json.RawMessage(`2`), "a": json.RawMessage(`"x"`)},
+		Usage:           &domain.ResourceUsage{S3GetRequests: 1, BytesDownloaded: 300, CPUSeconds: 1.5},
+		Items: []domain.BatchItemResult{
+			{VideoKey: "a.mp4", Success: true, FileKey: "processed/frames_123_1.zip", Frames: 12},
+			{VideoKey: "b.mp4", Error: "boom"},
+		},
+		BatchStatus: domain.BatchStatusPartial,
+		Loudness:    &domain.LoudnessStats{InputIntegrated: -27.5, OutputIntegrated: -23},
+	}
+
+	body, err := NewProtobufResultSerializer().Serialize(result)
+	if err != nil {
+		t.Fatalf("Serialize failed: %v", err)
+	}
+	fields := decodeProto(t, body)
+
+	if string(fields[1][0]) != "123" || protoVarint(fields[2][0]) != 1 {
+		t.Errorf("Unexpected process_id/kind %q %v", fields[1], fields[2])
+	}
+	if string(fields[3][0]) != "output" || string(fields[4][0]) != result.FileKey || len(fields[5]) != 2 {
+		t.Errorf("Unexpected file fields %q %q %q", fields[3], fields[4], fields[5])
+	}
+	if string(fields[6][0]) != "frames" || protoVarint(fields[7][0]) != 1 {
+		t.Errorf("Unexpected output_type/confirm_required %q %v", fields[6], fields[7])
+	}
+	if _, ok := fields[8]; ok {
+		t.Error("Expected no error_message on success")
+	}
+
+	worker := decodeProto(t, fields[11][0])
+	if string(worker[1][0]) != "host" || worker[2] != nil || string(worker[4][0]) != "abc" {
+		t.Errorf("Unexpected worker %q", worker)
+	}
+
+	if len(fields[12]) != 2 {
+		t.Fatalf("Expected two metadata entries, got %d", len(fields[12]))
+	}
+	first := decodeProto(t, fields[12][0])
+	if string(first[1][0]) != "a" || string(first[2][0]) != `"x"` {
+		t.Errorf("Expected sorted metadata with raw JSON values, got %q", first)
+	}
+
+	usage := decodeProto(t, fields[15][0])
+	cpuSeconds, _ := protowire.ConsumeFixed64(usage[5][0])
+	if protoVarint(usage[1][0]) != 1 || usage[2] != nil || protoVarint(usage[3][0]) != 300 || math.Float64frombits(cpuSeconds) != 1.5 || usage[6] != nil {
+		t.Errorf("Unexpected usage %q", usage)
+	}
+
+	if len(fields[16]) != 2 || string(fields[17][0]) != domain.BatchStatusPartial {
+		t.Fatalf("Expected two batch items and the batch status, got %q %q", fields[16], fields[17])
+	}
+	item := decodeProto(t, fields[16][0])
+	if string(item[1][0]) != "a.mp4" || protoVarint(item[2][0]) != 1 || string(item[3][0]) != "processed/frames_123_1.zip" || protoVarint(item[5][0]) != 12 {
+		t.Errorf("Unexpected first item %q", item)
+	}
+	if item = decodeProto(t, fields[16][1]); item[2] != nil || string(item[6][0]) != "boom" {
+		t.Errorf("Unexpected failed item %q", item)
+	}
+
+	loudness := decodeProto(t, fields[18][0])
+	inputIntegrated, _ := protowire.ConsumeFixed64(loudness[1][0])
+	if math.Float64frombits(inputIntegrated) != -27.5 || loudness[2] != nil || loudness[5] == nil {
+		t.Errorf("Unexpected loudness %q", loudness)
+	}
+}
+
+func TestProtobufResultSerializer_ErrorAndDryRun(t *testing.T) {
+	serializer := NewProtobufResultSerializer()
+	if serializer.ContentType() != domain.ContentTypeProtobuf {
+		t.Errorf("Expected %s, got %s", domain.ContentTypeProtobuf, serializer.ContentType())
+	}
+
+	body, _ := serializer.Serialize(&domain.ProcessResult{
+		ProcessID:   "123",
+		Error:       domain.NewCodedError(domain.ErrorCodeMalwareDetected, errors.New("malware detected: Eicar")),
+		Retry:       &domain.RetryInfo{Attempt: 3, MaxAttempts: 3},
+		SLABreached: true,
+	})
+	fields := decodeProto(t, body)
+	if protoVarint(fields[2][0]) != 2 || string(fields[8][0]) != "malware detected: Eicar" || string(fields[9][0]) != "malware_detected" {
+		t.Errorf("Unexpected error fields %q", fields)
+	}
+	retry := decodeProto(t, fields[13][0])
+	if protoVarint(retry[1][0]) != 3 || protoVarint(retry[2][0]) != 3 || len(retry[3]) != 0 {
+		t.Errorf("Unexpected retry %q", retry)
+	}
+	if len(fields[14]) != 1 || protoVarint(fields[14][0]) != 1 {
+		t.Errorf("Expected the SLA breach, got %q", fields[14])
+	}
+
+	body, _ = serializer.Serialize(&domain.ProcessResult{
+		ProcessID: "123",
+		Success:   true,
+		Estimate:  &domain.OutputEstimate{DurationSeconds: 12.5, Width: 1280, Height: 720, Count: 13, Bytes: 1 << 33},
+	})
+	fields = decodeProto(t, body)
+	if protoVarint(fields[2][0]) != 3 {
+		t.Errorf("Expected the dry run kind, got %v", fields[2])
+	}
+	estimate := decodeProto(t, fields[10][0])
+	duration, _ := protowire.ConsumeFixed64(estimate[1][0])
+	if math.Float64frombits(duration) != 12.5 || protoVarint(estimate[2][0]) != 1280 || protoVarint(estimate[5][0]) != 1<<33 {
+		t.Errorf("Unexpected estimate %q", estimate)
+	}
+}
+
+func TestProtobufResultSerializer_QC(t *testing.T) {
+	body, _ := NewProtobufResultSerializer().Serialize(&domain.ProcessResult{
+		ProcessID:  "123",
+		Success:    true,
+		OutputType: domain.OutputTypeQC,
+		QC: &domain.QCReport{
+			DurationSeconds:  60,
+			HasAudio:         true,
+			BlackIntervals:   []domain.TimeWindow{{Start: 0, End: 2.5}, {Start: 40, End: 43}},
+			SilenceIntervals: []domain.TimeWindow{},
+		},
+	})
+	fields := decodeProto(t, body)
+	if fields[3] != nil || fields[4] != nil {
+		t.Errorf("Expected no file fields, got %q %q", fields[3], fields[4])
+	}
+
+	qc := decodeProto(t, fields[19][0])
+	duration, _ := protowire.ConsumeFixed64(qc[1][0])
+	if math.Float64frombits(duration) != 60 || protoVarint(qc[2][0]) != 1 || qc[4] != nil || qc[5] != nil {
+		t.Errorf("Unexpected qc %q", qc)
+	}
+	if len(qc[3]) != 2 {
+		t.Fatalf("Expected two black intervals, got %d", len(qc[3]))
+	}
+	// A start at zero is not on the wire
+	first := decodeProto(t, qc[3][0])
+	end, _ := protowire.ConsumeFixed64(first[2][0])
+	if first[1] != nil || math.Float64frombits(end) != 2.5 {
+		t.Errorf("Unexpected first interval %q", first)
+	}
+}
+
+func TestProtobufResultSerializer_Barcodes(t *testing.T) {
+	body, _ := NewProtobufResultSerializer().Serialize(&domain.ProcessResult{
+		ProcessID: "123",
+		Success:   true,
+		Barcodes: []domain.BarcodeDetection{
+			{Format: "qr_code", Value: "ticket-42", Timestamps: []float64{0, 2.5}},
+			{Format: "code_128", Value: "SKU-1", Timestamps: []float64{4}},
+		},
+	})
+	fields := decodeProto(t, body)
+	if len(fields[20]) != 2 {
+		t.Fatalf("Expected two barcodes, got %d", len(fields[20]))
+	}
+
+	barcode := decodeProto(t, fields[20][0])
+	if string(barcode[1][0]) != "qr_code" || string(barcode[2][0]) != "ticket-42" {
+		t.Errorf("Unexpected barcode %q", barcode)
+	}
+	// The timestamps are packed into a single field
+	packed := barcode[3][0]
+	if len(barcode[3]) != 1 || len(packed) != 16 {
+		t.Fatalf("Expected two packed timestamps, got %q", barcode[3])
+	}
+	second, _ := protowire.ConsumeFixed64(packed[8:])
+	if math.Float64frombits(second) != 2.5 {
+		t.Errorf("Expected the second timestamp at 2.5s, got %v", math.Float64frombits(second))
+	}
+}
+
+func TestProtobufResultSerializer_Vision(t *testing.T) {
+	body, _ := NewProtobufResultSerializer().Serialize(&domain.ProcessResult{
+		ProcessID: "123",
+		Success:   true,
+		Vision: &domain.VisionReport{Flagged: true, Frames: []domain.VisionFrame{
+			{Time: 0, Labels: []domain.VisionLabel{{Name: "Car", Parent: "Vehicle", Confidence: 98.5}}},
+			{Time: 10, Moderation: []domain.VisionLabel{{Name: "Weapons", Confidence: 80}}},
+		}},
+		VisionKey: "processed/vision_123.json",
+	})
+	fields := decodeProto(t, body)
+	if string(fields[22][0]) != "processed/vision_123.json" {
+		t.Errorf("Unexpected vision_key %q", fields[22])
+	}
+
+	report := decodeProto(t, fields[21][0])
+	if protoVarint(report[1][0]) != 1 || len(report[2]) != 2 {
+		t.Fatalf("Unexpected vision report %q", report)
+	}
+	// A time at zero is not on the wire
+	first := decodeProto(t, report[2][0])
+	label := decodeProto(t, first[2][0])
+	confidence, _ := protowire.ConsumeFixed64(label[3][0])
+	if first[1] != nil || string(label[1][0]) != "Car" || string(label[2][0]) != "Vehicle" || math.Float64frombits(confidence) != 98.5 {
+		t.Errorf("Unexpected first frame %q", first)
+	}
+	second := decodeProto(t, report[2][1])
+	if second[2] != nil || len(second[3]) != 1 {
+		t.Errorf("Expected only moderation labels on the second frame, got %q", second)
+	}
+}
+
+func TestProtobufResultSerializer_Transcription(t *testing.T) {
+	body, _ := NewProtobufResultSerializer().Serialize(&domain.ProcessResult{
+		ProcessID:     "123",
+		Success:       true,
+		Transcription: &domain.TranscriptionRef{Provider: "transcribe", JobName: "123_abc", TranscriptKey: "processed/transcript_123.json"},
+	})
+	transcription := decodeProto(t, decodeProto(t, body)[23][0])
+	if string(transcription[1][0]) != "transcribe" || string(transcription[2][0]) != "123_abc" || string(transcription[3][0]) != "processed/transcript_123.json" {
+		t.Errorf("Unexpected transcription %q", transcription)
+	}
+	// Transcribe jobs have no message id
+	if transcription[4] != nil {
+		t.Errorf("Expected no message_id, got %q", transcription[4])
+	}
+}
+
+func TestProtobufResultSerializer_Source(t *testing.T) {
+	body, _ := NewProtobufResultSerializer().Serialize(&domain.ProcessResult{
+		ProcessID: "123",
+		Success:   true,
+		Source:    &domain.ObjectInfo{SizeBytes: 2048, ETag: "abc", ContentType: "video/mp4", VersionID: "v2", Metadata: map[string]string{"camera": "a7"}},
+	})
+	source := decodeProto(t, decodeProto(t, body)[24][0])
+	if protoVarint(source[1][0]) != 2048 || string(source[2][0]) != "abc" || string(source[3][0]) != "video/mp4" || string(source[5][0]) != "v2" {
+		t.Errorf("Unexpected source %q", source)
+	}
+	entry := decodeProto(t, source[4][0])
+	if string(entry[1][0]) != "camera" || string(entry[2][0]) != "a7" {
+		t.Errorf("Unexpected source metadata %q", entry)
+	}
+}
+
+func TestProtobufResultSerializer_ProcessingBackend(t *testing.T) {
+	body, _ := NewProtobufResultSerializer().Serialize(&domain.ProcessResult{
+		ProcessID:         "123",
+		Success:           true,
+		ProcessingBackend: domain.ProcessingBackendLocal,
+	})
+	if backend := decodeProto(t, body)[25]; len(backend) != 1 || string(backend[0]) != "local" {
+		t.Errorf("Unexpected processing backend %q", backend)
+	}
+}
+
+func TestProtobufResultSerializer_Negotiated(t *testing.T) {
+	body, _ := NewProtobufResultSerializer().Serialize(&domain.ProcessResult{
+		ProcessID:  "123",
+		Success:    true,
+		Negotiated: &domain.NegotiatedOutput{Encoding: domain.ArchiveEncodingZip, ImageFormat: domain.ImageFormatJPEG},
+	})
+	fields := decodeProto(t, body)
+	if len(fields[26]) != 1 {
+		t.Fatalf("Expected the negotiated output, got %q", fields[26])
+	}
+	negotiated := decodeProto(t, fields[26][0])
+	if string(negotiated[1][0]) != "zip" || string(negotiated[2][0]) != "jpeg" {
+		t.Errorf("Unexpected negotiated output %q", negotiated)
+	}
+}
+
+func TestProtobufResultSerializer_Quarantine(t *testing.T) {
+	body, _ := NewProtobufResultSerializer().Serialize(&domain.ProcessResult{
+		ProcessID:  "123",
+		Error:      domain.NewCodedError(domain.ErrorCodeEmptyVideo, errors.New("video is empty")),
+		Quarantine: &domain.ObjectRef{Bucket: "q", Key: "quarantine/123/video.mp4"},
+	})
+	fields := decodeProto(t, body)
+	if len(fields[27]) != 1 {
+		t.Fatalf("Expected the quarantine location, got %q", fields[27])
+	}
+	quarantine := decodeProto(t, fields[27][0])
+	if string(quarantine[1][0]) != "q" || string(quarantine[2][0]) != "quarantine/123/video.mp4" {
+		t.Errorf("Unexpected quarantine location %q", quarantine)
+	}
+}
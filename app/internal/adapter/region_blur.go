@@ -0,0 +1,87 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/port"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/ffmpeg"
+)
+
+// FFmpegRegionBlur anonymizes frames by box blurring the regions supplied in the request
+type FFmpegRegionBlur struct {
+	ffmpegPath string
+}
+
+// NewFFmpegRegionBlur blurs with the given ffmpeg binary; an empty path falls back to PATH
+func NewFFmpegRegionBlur(ffmpegPath string) port.FramePostProcessorPort {
+	return &FFmpegRegionBlur{ffmpegPath: ffmpegPath}
+}
+
+func (b *FFmpegRegionBlur) ffmpegBinary() string {
+	if b.ffmpegPath == "" {
+		return "ffmpeg"
+	}
+	return b.ffmpegPath
+}
+
+// PostProcess rewrites every frame in place with the regions blurred
+func (b *FFmpegRegionBlur) PostProcess(ctx context.Context, framePaths []string, options domain.FrameOptions) error {
+	if !options.Anonymize.Enabled() {
+		return nil
+	}
+
+	graph, output := regionBlurGraph(options.Anonymize)
+	for _, frame := range framePaths {
+		// Write next to the frame with the same extension so the image2 muxer keeps the format
+		blurred := filepath.Join(filepath.Dir(frame), "blurred_"+filepath.Base(frame))
+		args := []string{"-i", frame, "-filter_complex", graph, "-map", output, "-y", blurred}
+
+		result, err := ffmpeg.CombinedOutput(ctx, ffmpeg.DefaultKillGrace, b.ffmpegBinary(), args...)
+		if err != nil {
+			os.Remove(blurred)
+			return fmt.Errorf("ffmpeg error: %w, output: %s", err, string(result))
+		}
+		if err := os.Rename(blurred, frame); err != nil {
+			return fmt.Errorf("failed to replace frame %s: %w", filepath.Base(frame), err)
+		}
+	}
+	return nil
+}
+
+// regionBlurGraph chains one crop/boxblur/overlay step per region and returns the graph and
+// its output label. The radius is clamped to what boxblur accepts for small regions
+func regionBlurGraph(options domain.AnonymizeOptions) (string, string) {
+	strength := options.BlurStrength()
+
+	var graph strings.Builder
+	input := "[0:v]"
+	for i, region := range options.Regions {
+		x, y := formatFraction(region.X), formatFraction(region.Y)
+		width, height := formatFraction(region.Width), formatFraction(region.Height)
+		output := fmt.Sprintf("[blurred%d]", i)
+
+		if i > 0 {
+			graph.WriteString(";")
+		}
+		fmt.Fprintf(&graph,
+			"%ssplit[base%d][region%d];[region%d]crop=iw*%s:ih*%s:iw*%s:ih*%s,"+
+				"boxblur=lr='min(%d,min(w,h)/2)':cr='min(%d,min(cw,ch)/2)'[blur%d];"+
+				"[base%d][blur%d]overlay=W*%s:H*%s%s",
+			input, i, i, i, width, height, x, y,
+			strength, strength, i,
+			i, i, x, y, output,
+		)
+		input = output
+	}
+	return graph.String(), input
+}
+
+func formatFraction(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
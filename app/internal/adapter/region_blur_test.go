@@ -0,0 +1,67 @@
+package adapter
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+)
+
+func TestRegionBlurGraph(t *testing.T) {
+	options := domain.AnonymizeOptions{
+		Regions: []domain.BlurRegion{
+			{X: 0.1, Y: 0.2, Width: 0.3, Height: 0.4},
+			{X: 0.5, Y: 0.5, Width: 0.5, Height: 0.5},
+		},
+		Strength: 8,
+	}
+
+	graph, output := regionBlurGraph(options)
+	if output != "[blurred1]" {
+		t.Errorf("Expected the last region label as output, got %s", output)
+	}
+
+	want := "[0:v]split[base0][region0];[region0]crop=iw*0.3:ih*0.4:iw*0.1:ih*0.2," +
+		"boxblur=lr='min(8,min(w,h)/2)':cr='min(8,min(cw,ch)/2)'[blur0];" +
+		"[base0][blur0]overlay=W*0.1:H*0.2[blurred0];" +
+		"[blurred0]split[base1][region1]"
+	if !strings.HasPrefix(graph, want) {
+		t.Errorf("Unexpected graph:\n%s", graph)
+	}
+}
+
+func TestFFmpegRegionBlur_PostProcess(t *testing.T) {
+	ffmpegPath, argsFile := writeRecordingFFmpeg(t)
+	blur := NewFFmpegRegionBlur(ffmpegPath)
+
+	dir := t.TempDir()
+	frame := filepath.Join(dir, "frame_0001.png")
+	if err := os.WriteFile(frame, []byte("original"), 0644); err != nil {
+		t.Fatalf("Failed to write frame: %v", err)
+	}
+
+	options := domain.FrameOptions{Anonymize: domain.AnonymizeOptions{Regions: []domain.BlurRegion{{Width: 0.5, Height: 0.5}}}}
+	if err := blur.PostProcess(context.Background(), []string{frame}, options); err != nil {
+		t.Fatalf("PostProcess failed: %v", err)
+	}
+
+	if content := readFile(t, frame); content == "original" {
+		t.Error("Expected the frame to be replaced by the blurred output")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "blurred_frame_0001.png")); !os.IsNotExist(err) {
+		t.Error("Expected the temporary blurred file to be renamed over the frame")
+	}
+	if args := readFile(t, argsFile); !strings.Contains(args, "-map [blurred0]") {
+		t.Errorf("Expected the blurred output mapped, got %s", args)
+	}
+}
+
+func TestFFmpegRegionBlur_Disabled(t *testing.T) {
+	blur := NewFFmpegRegionBlur(filepath.Join(t.TempDir(), "missing-ffmpeg"))
+	if err := blur.PostProcess(context.Background(), []string{"frame_0001.png"}, domain.FrameOptions{}); err != nil {
+		t.Errorf("Expected no-op without regions, got %v", err)
+	}
+}
@@ -0,0 +1,293 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/port"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/ffmpeg"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/framecapture"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/observability"
+	"go.uber.org/zap"
+)
+
+// remotePollInterval is how often the status of a remote job is read
+const remotePollInterval = 5 * time.Second
+
+// RemoteVideoProcessor offloads the frame extraction to a frame capture service (AWS
+// Elemental MediaConvert), so the pod uploads the video and downloads the frames but never
+// decodes it. Sprite sheets and the requests using options the service cannot apply are
+// processed locally with ffmpeg; ProcessorRouter decides which videos are worth offloading
+type RemoteVideoProcessor struct {
+	service framecapture.FrameCaptureService
+	storage port.StoragePort
+	bucket  string
+	prefix  string
+	local   *FFmpegVideoProcessor
+	// pollInterval is how often the job status is read
+	pollInterval time.Duration
+}
+
+// NewRemoteVideoProcessor stages the videos under prefix in bucket, which the service must be
+// able to read and write; the local processor is built from tempDir, the ffmpeg binaries and
+// the options, which also apply to the frames extracted remotely
+func NewRemoteVideoProcessor(service framecapture.FrameCaptureService, storage port.StoragePort, bucket, prefix, tempDir, ffmpegPath, ffprobePath string, options ...ProcessorOption) port.VideoProcessorPort {
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	return &RemoteVideoProcessor{
+		service:      service,
+		storage:      storage,
+		bucket:       bucket,
+		prefix:       prefix,
+		local:        NewFFmpegVideoProcessorWithBinaries(tempDir, ffmpegPath, ffprobePath, options...).(*FFmpegVideoProcessor),
+		pollInterval: remotePollInterval,
+	}
+}
+
+// remoteSupported reports whether the service can extract the frames for options: it only
+// samples the whole video at a rate, so windows, subtitles, tone mapping and the frame
+// analyses and conversions, which need the local frames in PNG, stay local
+func remoteSupported(options domain.FrameOptions) bool {
+	return len(options.Windows) == 0 &&
+		!options.Subtitles.Enabled() &&
+		options.ToneMap == "" &&
+		!options.Sharpness.Enabled() &&
+		!options.Colors.Enabled() &&
+		!options.OCR.Enabled &&
+		!options.Image.Enabled()
+}
+
+// ProcessVideo extracts the frames remotely, as JPEG, and then anonymizes, names and zips them
+// like the local processor
+func (p *RemoteVideoProcessor) ProcessVideo(ctx context.Context, request domain.ProcessingRequest) (domain.ProcessingResult, error) {
+	if !remoteSupported(request.Options) {
+		return p.local.ProcessVideo(ctx, request)
+	}
+
+	jobID, videoPath, options := sanitizeJobID(request.JobID), request.VideoPath, request.Options
+	if jobID == "" {
+		return domain.ProcessingResult{}, fmt.Errorf("job id is required")
+	}
+
+	rate := options.FrameRate()
+	var probe *ffmpeg.ProbeResult
+	if options.MaxFrames > 0 {
+		var err error
+		probe, err = ffmpeg.Probe(ctx, p.local.ffprobeBinary(), videoPath)
+		if err != nil {
+			return domain.ProcessingResult{}, err
+		}
+		rate = sampleRate(probe, options)
+	}
+
+	processDir := filepath.Join(p.local.tempDir, "process_"+jobID)
+	if err := os.MkdirAll(processDir, 0777); err != nil {
+		return domain.ProcessingResult{}, fmt.Errorf("failed to create process directory: %w", err)
+	}
+	defer os.RemoveAll(processDir)
+
+	stagingPrefix := p.prefix + jobID + "/"
+	defer p.cleanup(ctx, stagingPrefix)
+
+	inputKey := stagingPrefix + "input" + filepath.Ext(videoPath)
+	if err := p.upload(ctx, videoPath, inputKey); err != nil {
+		return domain.ProcessingResult{}, err
+	}
+
+	numerator, denominator := rationalRate(rate)
+	remoteID, err := p.service.Submit(ctx, framecapture.Job{
+		Name:            jobID,
+		InputBucket:     p.bucket,
+		InputKey:        inputKey,
+		OutputBucket:    p.bucket,
+		OutputPrefix:    stagingPrefix + "frames/",
+		RateNumerator:   numerator,
+		RateDenominator: denominator,
+		MaxCaptures:     options.MaxFrames,
+		AutoRotate:      options.AutoRotate,
+	})
+	if err != nil {
+		return domain.ProcessingResult{}, err
+	}
+	observability.FromContext(ctx).Info("remote frame extraction started",
+		zap.String("job_id", jobID),
+		zap.String("remote_job_id", remoteID),
+	)
+	if err := p.wait(ctx, remoteID); err != nil {
+		return domain.ProcessingResult{}, err
+	}
+
+	frames, err := p.downloadFrames(ctx, stagingPrefix+"frames/", processDir)
+	if err != nil {
+		return domain.ProcessingResult{}, err
+	}
+	if len(frames) == 0 {
+		return domain.ProcessingResult{}, fmt.Errorf("no frames extracted from video")
+	}
+	if options.MaxFrames > 0 && len(frames) > options.MaxFrames {
+		frames = frames[:options.MaxFrames]
+	}
+	timestamps := frameTimestamps([][]string{frames}, nil, rate)
+	request.Report(domain.ProcessingStepExtract, len(frames))
+
+	for _, postProcessor := range p.local.postProcessors {
+		if err := postProcessor.PostProcess(ctx, frames, options); err != nil {
+			return domain.ProcessingResult{}, fmt.Errorf("frame post-processing failed: %w", err)
+		}
+	}
+
+	request.Report(domain.ProcessingStepPostProcess, len(frames))
+
+	frames, err = nameFrames(frames, timestamps, options.FrameName.Or(p.local.frameName), options.ProcessID, filepath.Join(processDir, "named"))
+	if err != nil {
+		return domain.ProcessingResult{}, err
+	}
+
+	archive := options.Archive.Or(p.local.archive)
+	zipPaths, err := p.local.createZipParts(request.JobID, frames, filepath.Join(p.local.tempDir, "frames_"+jobID+archive.Extension()), archive)
+	if err != nil {
+		return domain.ProcessingResult{}, fmt.Errorf("failed to create zip: %w", err)
+	}
+	request.Report(domain.ProcessingStepArchive, len(frames))
+	return domain.ProcessingResult{Artifacts: zipPaths, FrameCount: len(frames), Video: videoInfo(probe)}, nil
+}
+
+func (p *RemoteVideoProcessor) GenerateSpriteSheet(ctx context.Context, request domain.ProcessingRequest) (domain.ProcessingResult, error) {
+	return p.local.GenerateSpriteSheet(ctx, request)
+}
+
+func (p *RemoteVideoProcessor) ProbeVideo(ctx context.Context, videoPath string) (domain.VideoInfo, error) {
+	return p.local.ProbeVideo(ctx, videoPath)
+}
+
+func (p *RemoteVideoProcessor) upload(ctx context.Context, videoPath, key string) error {
+	file, err := os.Open(videoPath)
+	if err != nil {
+		return fmt.Errorf("failed to open video: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := p.storage.PutObject(ctx, p.bucket, key, file); err != nil {
+		return fmt.Errorf("failed to stage video for remote processing: %w", err)
+	}
+	return nil
+}
+
+// wait polls the remote job until it ends, cancelling it when ctx is cancelled so it does not
+// keep running for a job that was abandoned
+func (p *RemoteVideoProcessor) wait(ctx context.Context, remoteID string) error {
+	ticker := time.NewTicker(p.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			cancelCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+			defer cancel()
+			if err := p.service.Cancel(cancelCtx, remoteID); err != nil {
+				observability.FromContext(ctx).Warn("failed to cancel remote frame extraction",
+					zap.String("remote_job_id", remoteID),
+					zap.Error(err),
+				)
+			}
+			return ctx.Err()
+		case <-ticker.C:
+		}
+
+		status, err := p.service.Status(ctx, remoteID)
+		if err != nil {
+			return err
+		}
+		switch status.State {
+		case framecapture.StateComplete:
+			return nil
+		case framecapture.StateFailed:
+			return fmt.Errorf("remote frame extraction failed: %s", status.Message)
+		case framecapture.StateCancelled:
+			return errors.New("remote frame extraction was cancelled")
+		}
+	}
+}
+
+// downloadFrames downloads the frames written under prefix, in the order of their names, as
+// frame_0001.jpg, frame_0002.jpg, ... like the frames extracted locally
+func (p *RemoteVideoProcessor) downloadFrames(ctx context.Context, prefix, dir string) ([]string, error) {
+	keys, err := p.storage.ListObjects(ctx, p.bucket, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list remote frames: %w", err)
+	}
+	sort.Strings(keys)
+
+	var frames []string
+	for _, key := range keys {
+		if !strings.EqualFold(path.Ext(key), ".jpg") {
+			continue
+		}
+		frame := filepath.Join(dir, fmt.Sprintf("frame_%04d.jpg", len(frames)+1))
+		if err := p.download(ctx, key, frame); err != nil {
+			return nil, err
+		}
+		frames = append(frames, frame)
+	}
+	return frames, nil
+}
+
+func (p *RemoteVideoProcessor) download(ctx context.Context, key, target string) error {
+	body, err := p.storage.GetObject(ctx, p.bucket, key)
+	if err != nil {
+		return fmt.Errorf("failed to download remote frame %s: %w", key, err)
+	}
+	defer body.Close()
+
+	file, err := os.Create(target)
+	if err != nil {
+		return fmt.Errorf("failed to create frame file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(file, body); err != nil {
+		return fmt.Errorf("failed to download remote frame %s: %w", key, err)
+	}
+	return nil
+}
+
+// cleanup deletes the staged video and frames, also after a cancellation
+func (p *RemoteVideoProcessor) cleanup(ctx context.Context, prefix string) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+	defer cancel()
+
+	keys, err := p.storage.ListObjects(ctx, p.bucket, prefix)
+	if err == nil && len(keys) > 0 {
+		_, err = p.storage.DeleteObjects(ctx, p.bucket, keys)
+	}
+	if err != nil {
+		observability.FromContext(ctx).Warn("failed to delete remote processing files",
+			zap.String("prefix", prefix),
+			zap.Error(err),
+		)
+	}
+}
+
+// rationalRate turns a sampling rate into the fraction the service takes, in thousandths
+func rationalRate(rate float64) (int32, int32) {
+	numerator, denominator := int64(math.Round(rate*1000)), int64(1000)
+	if numerator < 1 {
+		numerator = 1
+	}
+	a, b := numerator, denominator
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return int32(numerator / a), int32(denominator / a)
+}
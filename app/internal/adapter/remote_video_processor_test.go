@@ -0,0 +1,196 @@
+package adapter
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	"image/jpeg"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/port"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/framecapture"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/observability"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/storage"
+)
+
+// newTestRemoteProcessor returns a remote processor staging in a file-backed bucket, with a
+// fake video
+func newTestRemoteProcessor(t *testing.T, service framecapture.FrameCaptureService) (*RemoteVideoProcessor, port.StoragePort, string) {
+	observability.InitLogger("test")
+	dir := t.TempDir()
+	storagePort := NewStorageAdapter(storage.NewFileClient(filepath.Join(dir, "buckets")))
+	processor := NewRemoteVideoProcessor(service, storagePort, "staging", "remote", filepath.Join(dir, "work"), "", "").(*RemoteVideoProcessor)
+	processor.pollInterval = time.Millisecond
+
+	videoPath := filepath.Join(dir, "video.mp4")
+	if err := os.WriteFile(videoPath, bytes.Repeat([]byte{0}, 100), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return processor, storagePort, videoPath
+}
+
+// captureFrames writes count JPEG frames where MediaConvert would, named like its outputs
+func captureFrames(t *testing.T, storagePort port.StoragePort, job framecapture.Job, count int) {
+	var encoded bytes.Buffer
+	if err := jpeg.Encode(&encoded, image.NewGray(image.Rect(0, 0, 4, 4)), nil); err != nil {
+		t.Fatal(err)
+	}
+	for i := count - 1; i >= 0; i-- {
+		key := job.OutputPrefix + "frame." + strings.Repeat("0", 6) + string(rune('0'+i)) + ".jpg"
+		if _, err := storagePort.PutObject(context.Background(), job.OutputBucket, key, bytes.NewReader(encoded.Bytes())); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestRemoteVideoProcessor_ProcessVideo(t *testing.T) {
+	var submitted framecapture.Job
+	var storagePort port.StoragePort
+	polls := 0
+	service := &framecapture.MockFrameCaptureService{
+		SubmitFunc: func(ctx context.Context, job framecapture.Job) (string, error) {
+			submitted = job
+			if _, err := storagePort.HeadObject(ctx, job.InputBucket, job.InputKey); err != nil {
+				t.Errorf("Expected video staged before the submission, got %v", err)
+			}
+			captureFrames(t, storagePort, job, 3)
+			return "remote-1", nil
+		},
+		StatusFunc: func(ctx context.Context, jobID string) (framecapture.Status, error) {
+			polls++
+			if polls < 2 {
+				return framecapture.Status{State: framecapture.StateRunning}, nil
+			}
+			return framecapture.Status{State: framecapture.StateComplete}, nil
+		},
+	}
+	processor, sp, videoPath := newTestRemoteProcessor(t, service)
+	storagePort = sp
+
+	options := domain.FrameOptions{FPS: 0.5, AutoRotate: true, FrameName: "{index}_{ts_ms}"}
+	result, err := processor.ProcessVideo(context.Background(), domain.ProcessingRequest{JobID: "job/1", VideoPath: videoPath, Options: options})
+	if err != nil {
+		t.Fatalf("ProcessVideo failed: %v", err)
+	}
+
+	if submitted.InputBucket != "staging" || submitted.InputKey != "remote/job_1/input.mp4" || submitted.OutputPrefix != "remote/job_1/frames/" {
+		t.Errorf("Unexpected staging locations %+v", submitted)
+	}
+	if submitted.RateNumerator != 1 || submitted.RateDenominator != 2 || !submitted.AutoRotate {
+		t.Errorf("Expected 1/2 fps with autorotation, got %+v", submitted)
+	}
+	if result.FrameCount != 3 || len(result.Artifacts) != 1 {
+		t.Fatalf("Expected 3 frames in 1 zip, got %d frames in %v", result.FrameCount, result.Artifacts)
+	}
+
+	reader, err := zip.OpenReader(result.Artifacts[0])
+	if err != nil {
+		t.Fatalf("Failed to open zip: %v", err)
+	}
+	defer reader.Close()
+	var names []string
+	for _, file := range reader.File {
+		names = append(names, file.Name)
+	}
+	if strings.Join(names, ",") != "0001_0.jpg,0002_2000.jpg,0003_4000.jpg" {
+		t.Errorf("Expected frames named in video order, got %v", names)
+	}
+
+	if keys, _ := storagePort.ListObjects(context.Background(), "staging", "remote/"); len(keys) != 0 {
+		t.Errorf("Expected staging objects deleted, got %v", keys)
+	}
+}
+
+func TestRemoteVideoProcessor_ProcessVideo_Failed(t *testing.T) {
+	service := &framecapture.MockFrameCaptureService{
+		StatusFunc: func(ctx context.Context, jobID string) (framecapture.Status, error) {
+			return framecapture.Status{State: framecapture.StateFailed, Message: "unsupported input"}, nil
+		},
+	}
+	processor, _, videoPath := newTestRemoteProcessor(t, service)
+
+	_, err := processor.ProcessVideo(context.Background(), domain.ProcessingRequest{JobID: "job-1", VideoPath: videoPath})
+	if err == nil || !strings.Contains(err.Error(), "unsupported input") {
+		t.Errorf("Expected the remote failure message, got %v", err)
+	}
+}
+
+func TestRemoteVideoProcessor_ProcessVideo_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var cancelled string
+	service := &framecapture.MockFrameCaptureService{
+		StatusFunc: func(ctx context.Context, jobID string) (framecapture.Status, error) {
+			cancel()
+			return framecapture.Status{State: framecapture.StateRunning}, nil
+		},
+		CancelFunc: func(ctx context.Context, jobID string) error {
+			cancelled = jobID
+			return ctx.Err()
+		},
+	}
+	processor, _, videoPath := newTestRemoteProcessor(t, service)
+
+	_, err := processor.ProcessVideo(ctx, domain.ProcessingRequest{JobID: "job-1", VideoPath: videoPath})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if cancelled != "job-id" {
+		t.Errorf("Expected the remote job cancelled with a live context, got %q", cancelled)
+	}
+}
+
+func TestRemoteVideoProcessor_ProcessVideo_Local(t *testing.T) {
+	tests := []struct {
+		name    string
+		options domain.FrameOptions
+	}{
+		{"windows", domain.FrameOptions{Windows: []domain.TimeWindow{{Start: 1, End: 2}}}},
+		{"tone mapping", domain.FrameOptions{ToneMap: "hable"}},
+		{"sharpness", domain.FrameOptions{Sharpness: domain.SharpnessOptions{Mode: "annotate"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			submitted := false
+			service := &framecapture.MockFrameCaptureService{
+				SubmitFunc: func(ctx context.Context, job framecapture.Job) (string, error) {
+					submitted = true
+					return "", errors.New("unexpected submission")
+				},
+			}
+			processor, _, videoPath := newTestRemoteProcessor(t, service)
+
+			// The fake video is not decodable: the local processor fails on it
+			if _, err := processor.ProcessVideo(context.Background(), domain.ProcessingRequest{JobID: "job-1", VideoPath: videoPath, Options: tt.options}); err == nil {
+				t.Error("Expected the local processor to fail on the fake video")
+			}
+			if submitted {
+				t.Error("Expected the video processed locally")
+			}
+		})
+	}
+}
+
+func TestRationalRate(t *testing.T) {
+	tests := []struct {
+		rate             float64
+		wantNum, wantDen int32
+	}{
+		{1, 1, 1},
+		{0.5, 1, 2},
+		{2.5, 5, 2},
+		{100.0 / 3600, 7, 250},
+		{0.0001, 1, 1000},
+	}
+	for _, tt := range tests {
+		if num, den := rationalRate(tt.rate); num != tt.wantNum || den != tt.wantDen {
+			t.Errorf("rationalRate(%v): expected %d/%d, got %d/%d", tt.rate, tt.wantNum, tt.wantDen, num, den)
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/port"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/scanner"
+)
+
+type ScannerAdapter struct {
+	service scanner.ScannerService
+}
+
+func NewScannerAdapter(service scanner.ScannerService) port.ScannerPort {
+	return &ScannerAdapter{
+		service: service,
+	}
+}
+
+func (a *ScannerAdapter) ScanFile(ctx context.Context, path string) (bool, string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return false, "", fmt.Errorf("failed to open file to scan: %w", err)
+	}
+	defer file.Close()
+
+	result, err := a.service.Scan(ctx, file)
+	if err != nil {
+		return false, "", err
+	}
+
+	return result.Infected, result.Signature, nil
+}
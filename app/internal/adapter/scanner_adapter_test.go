@@ -0,0 +1,75 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/scanner"
+)
+
+func TestNewScannerAdapter(t *testing.T) {
+	adapter := NewScannerAdapter(&scanner.MockScannerService{})
+
+	if adapter == nil {
+		t.Fatal("NewScannerAdapter returned nil")
+	}
+}
+
+func TestScannerAdapter_ScanFile(t *testing.T) {
+	tempDir := t.TempDir()
+	videoPath := filepath.Join(tempDir, "video.mp4")
+	os.WriteFile(videoPath, []byte("video content"), 0644)
+
+	var scanned string
+	mock := &scanner.MockScannerService{
+		ScanFunc: func(ctx context.Context, content io.Reader) (scanner.ScanResult, error) {
+			data, _ := io.ReadAll(content)
+			scanned = string(data)
+			return scanner.ScanResult{Infected: true, Signature: "Eicar-Test-Signature"}, nil
+		},
+	}
+
+	adapter := NewScannerAdapter(mock)
+
+	infected, signature, err := adapter.ScanFile(context.Background(), videoPath)
+	if err != nil {
+		t.Fatalf("ScanFile failed: %v", err)
+	}
+	if !infected || signature != "Eicar-Test-Signature" {
+		t.Errorf("Expected infected result, got infected=%v signature=%s", infected, signature)
+	}
+	if scanned != "video content" {
+		t.Errorf("Expected file content to be scanned, got %q", scanned)
+	}
+}
+
+func TestScannerAdapter_ScanFile_MissingFile(t *testing.T) {
+	adapter := NewScannerAdapter(&scanner.MockScannerService{})
+
+	if _, _, err := adapter.ScanFile(context.Background(), "/nonexistent/video.mp4"); err == nil {
+		t.Error("Expected error for missing file")
+	}
+}
+
+func TestScannerAdapter_ScanFile_Error(t *testing.T) {
+	tempDir := t.TempDir()
+	videoPath := filepath.Join(tempDir, "video.mp4")
+	os.WriteFile(videoPath, []byte("video content"), 0644)
+
+	expectedError := errors.New("clamd unavailable")
+	mock := &scanner.MockScannerService{
+		ScanFunc: func(ctx context.Context, content io.Reader) (scanner.ScanResult, error) {
+			return scanner.ScanResult{}, expectedError
+		},
+	}
+
+	adapter := NewScannerAdapter(mock)
+
+	if _, _, err := adapter.ScanFile(context.Background(), videoPath); err != expectedError {
+		t.Errorf("Expected error %v, got %v", expectedError, err)
+	}
+}
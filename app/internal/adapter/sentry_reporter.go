@@ -0,0 +1,71 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/ffmpeg"
+	"github.com/getsentry/sentry-go"
+)
+
+// sentryMaxOutput is how much of the end of the ffmpeg output goes with an event; the cause
+// of a failure is at the end and Sentry truncates large contexts
+const sentryMaxOutput = 8 * 1024
+
+// SentryReporter sends job failures and panics to Sentry, or to any service accepting a
+// Sentry DSN
+type SentryReporter struct {
+	hub *sentry.Hub
+}
+
+// NewSentryReporter creates a reporter with its own client, leaving the global hub untouched
+func NewSentryReporter(options sentry.ClientOptions) (*SentryReporter, error) {
+	client, err := sentry.NewClient(options)
+	if err != nil {
+		return nil, err
+	}
+	return &SentryReporter{hub: sentry.NewHub(client, sentry.NewScope())}, nil
+}
+
+// ReportFailure captures the failure tagged with its job and stage, with the output of the
+// ffmpeg run that caused it, when there is one
+func (r *SentryReporter) ReportFailure(ctx context.Context, failure domain.JobFailure) {
+	r.hub.WithScope(func(scope *sentry.Scope) {
+		scope.SetTag("process_id", failure.ProcessID)
+		scope.SetTag("stage", failure.Stage)
+		if failure.TenantID != "" {
+			scope.SetTag("tenant_id", failure.TenantID)
+		}
+		if code := domain.ErrorCode(failure.Err); code != "" {
+			scope.SetTag("error_code", code)
+		}
+
+		var run *ffmpeg.Failure
+		if errors.As(failure.Err, &run) {
+			output := run.Output
+			if len(output) > sentryMaxOutput {
+				output = output[len(output)-sentryMaxOutput:]
+			}
+			scope.SetTag("failure_reason", run.Reason)
+			scope.SetContext("ffmpeg", sentry.Context{"output": string(output)})
+		}
+		r.hub.CaptureException(failure.Err)
+	})
+}
+
+// ReportPanic captures a recovered panic of a message handler, with the stack where it happened
+func (r *SentryReporter) ReportPanic(ctx context.Context, recovered any, stack []byte, messageID string) {
+	r.hub.WithScope(func(scope *sentry.Scope) {
+		scope.SetTag("message_id", messageID)
+		scope.SetLevel(sentry.LevelFatal)
+		scope.SetContext("panic", sentry.Context{"stack": string(stack)})
+		r.hub.RecoverWithContext(ctx, recovered)
+	})
+}
+
+// Flush waits up to timeout for the events to be sent, before the worker exits
+func (r *SentryReporter) Flush(timeout time.Duration) bool {
+	return r.hub.Flush(timeout)
+}
@@ -0,0 +1,67 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/ffmpeg"
+	"github.com/getsentry/sentry-go"
+)
+
+// newCapturingSentryReporter keeps the events instead of sending them
+func newCapturingSentryReporter(t *testing.T) (*SentryReporter, *[]*sentry.Event) {
+	var events []*sentry.Event
+	reporter, err := NewSentryReporter(sentry.ClientOptions{
+		BeforeSend: func(event *sentry.Event, hint *sentry.EventHint) *sentry.Event {
+			events = append(events, event)
+			return nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewSentryReporter failed: %v", err)
+	}
+	return reporter, &events
+}
+
+func TestSentryReporter_ReportFailure(t *testing.T) {
+	reporter, events := newCapturingSentryReporter(t)
+
+	output := strings.Repeat("frame=1\n", 2000) + "moov atom not found"
+	run := &ffmpeg.Failure{Reason: ffmpeg.FailureCorruptFile, Output: []byte(output), Err: errors.New("exit status 1")}
+	reporter.ReportFailure(context.Background(), domain.JobFailure{
+		ProcessID: "process-1",
+		TenantID:  "acme",
+		Stage:     domain.JobStageProcessing,
+		Err:       fmt.Errorf("ffmpeg error: %w", run),
+	})
+
+	if len(*events) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(*events))
+	}
+	event := (*events)[0]
+	if event.Tags["process_id"] != "process-1" || event.Tags["stage"] != "processing" || event.Tags["tenant_id"] != "acme" || event.Tags["failure_reason"] != "corrupt_file" {
+		t.Errorf("Unexpected tags %v", event.Tags)
+	}
+	attached, _ := event.Contexts["ffmpeg"]["output"].(string)
+	if len(attached) != sentryMaxOutput || !strings.HasSuffix(attached, "moov atom not found") {
+		t.Errorf("Expected the end of the ffmpeg output attached, got %d bytes", len(attached))
+	}
+}
+
+func TestSentryReporter_ReportPanic(t *testing.T) {
+	reporter, events := newCapturingSentryReporter(t)
+
+	reporter.ReportPanic(context.Background(), "nil frame", []byte("goroutine 1"), "msg-1")
+
+	if len(*events) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(*events))
+	}
+	event := (*events)[0]
+	if event.Level != sentry.LevelFatal || event.Tags["message_id"] != "msg-1" || event.Contexts["panic"]["stack"] != "goroutine 1" {
+		t.Errorf("Unexpected event %+v", event)
+	}
+}
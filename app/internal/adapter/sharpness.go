@@ -0,0 +1,80 @@
+package adapter
+
+import (
+	"fmt"
+	"image"
+	"image/draw"
+	"os"
+	"path/filepath"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+)
+
+// scoreFrames computes the sharpness of every frame and drops the ones below the threshold in
+// drop mode, returning the kept frames and their manifest (one entry per kept frame, in order)
+func scoreFrames(frames []string, options domain.SharpnessOptions) ([]string, *framesManifest, error) {
+	manifest := framesManifest{Mode: options.Mode, Threshold: options.Threshold}
+
+	var kept []string
+	for _, frame := range frames {
+		score, err := frameSharpness(frame)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if options.Mode == domain.SharpnessModeDrop && score < options.Threshold {
+			manifest.Dropped++
+			continue
+		}
+		kept = append(kept, frame)
+		manifest.Frames = append(manifest.Frames, scoredFrame{File: filepath.Base(frame), Sharpness: &score})
+	}
+
+	if len(kept) == 0 {
+		return nil, nil, fmt.Errorf("no frames above the sharpness threshold %v", options.Threshold)
+	}
+	return kept, &manifest, nil
+}
+
+func frameSharpness(path string) (float64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	img, _, err := image.Decode(file)
+	if err != nil {
+		return 0, fmt.Errorf("failed to decode frame %s: %w", filepath.Base(path), err)
+	}
+	return laplacianVariance(img), nil
+}
+
+// laplacianVariance convolves the luma with the 4-neighbour Laplacian kernel and returns the
+// variance of the response: sharp edges give large responses, blur flattens them
+func laplacianVariance(img image.Image) float64 {
+	bounds := img.Bounds()
+	gray := image.NewGray(bounds)
+	draw.Draw(gray, bounds, img, bounds.Min, draw.Src)
+
+	width, height := bounds.Dx(), bounds.Dy()
+	if width < 3 || height < 3 {
+		return 0
+	}
+
+	var sum, sumSquares float64
+	for y := 1; y < height-1; y++ {
+		row := y * gray.Stride
+		for x := 1; x < width-1; x++ {
+			i := row + x
+			response := float64(gray.Pix[i-gray.Stride]) + float64(gray.Pix[i+gray.Stride]) +
+				float64(gray.Pix[i-1]) + float64(gray.Pix[i+1]) - 4*float64(gray.Pix[i])
+			sum += response
+			sumSquares += response * response
+		}
+	}
+
+	n := float64((width - 2) * (height - 2))
+	mean := sum / n
+	return sumSquares/n - mean*mean
+}
@@ -0,0 +1,111 @@
+package adapter
+
+import (
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+)
+
+func flatImage() image.Image {
+	img := image.NewGray(image.Rect(0, 0, 16, 16))
+	for i := range img.Pix {
+		img.Pix[i] = 128
+	}
+	return img
+}
+
+func checkerboardImage() image.Image {
+	img := image.NewGray(image.Rect(0, 0, 16, 16))
+	for y := 0; y < 16; y++ {
+		for x := 0; x < 16; x++ {
+			if (x+y)%2 == 0 {
+				img.SetGray(x, y, color.Gray{Y: 255})
+			}
+		}
+	}
+	return img
+}
+
+func writePNG(t *testing.T, path string, img image.Image) {
+	t.Helper()
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("Failed to create %s: %v", path, err)
+	}
+	defer file.Close()
+	if err := png.Encode(file, img); err != nil {
+		t.Fatalf("Failed to encode %s: %v", path, err)
+	}
+}
+
+func TestLaplacianVariance(t *testing.T) {
+	if score := laplacianVariance(flatImage()); score != 0 {
+		t.Errorf("Expected zero for a flat image, got %v", score)
+	}
+	if score := laplacianVariance(checkerboardImage()); score <= 0 {
+		t.Errorf("Expected a positive score for sharp edges, got %v", score)
+	}
+	if score := laplacianVariance(image.NewGray(image.Rect(0, 0, 2, 2))); score != 0 {
+		t.Errorf("Expected zero for an image too small to convolve, got %v", score)
+	}
+}
+
+func TestScoreFrames_Drop(t *testing.T) {
+	dir := t.TempDir()
+	sharp := filepath.Join(dir, "frame_0001.png")
+	blurry := filepath.Join(dir, "frame_0002.png")
+	writePNG(t, sharp, checkerboardImage())
+	writePNG(t, blurry, flatImage())
+
+	options := domain.SharpnessOptions{Mode: domain.SharpnessModeDrop, Threshold: 100}
+	kept, scored, err := scoreFrames([]string{sharp, blurry}, options)
+	if err != nil {
+		t.Fatalf("scoreFrames failed: %v", err)
+	}
+	manifestPath, err := scored.write([]string{filepath.Join(dir, "frame_0001.jpg")}, dir)
+	if err != nil {
+		t.Fatalf("Failed to write manifest: %v", err)
+	}
+	if len(kept) != 1 || kept[0] != sharp {
+		t.Errorf("Expected only the sharp frame kept, got %v", kept)
+	}
+
+	var manifest framesManifest
+	if err := json.Unmarshal([]byte(readFile(t, manifestPath)), &manifest); err != nil {
+		t.Fatalf("Invalid manifest: %v", err)
+	}
+	if manifest.Dropped != 1 || len(manifest.Frames) != 1 || manifest.Frames[0].File != "frame_0001.jpg" {
+		t.Errorf("Unexpected manifest: %+v", manifest)
+	}
+}
+
+func TestScoreFrames_Annotate(t *testing.T) {
+	dir := t.TempDir()
+	blurry := filepath.Join(dir, "frame_0001.png")
+	writePNG(t, blurry, flatImage())
+
+	kept, _, err := scoreFrames([]string{blurry}, domain.SharpnessOptions{Mode: domain.SharpnessModeAnnotate, Threshold: 100})
+	if err != nil {
+		t.Fatalf("scoreFrames failed: %v", err)
+	}
+	if len(kept) != 1 {
+		t.Errorf("Expected annotate mode to keep every frame, got %v", kept)
+	}
+}
+
+func TestScoreFrames_AllDropped(t *testing.T) {
+	dir := t.TempDir()
+	blurry := filepath.Join(dir, "frame_0001.png")
+	writePNG(t, blurry, flatImage())
+
+	_, _, err := scoreFrames([]string{blurry}, domain.SharpnessOptions{Mode: domain.SharpnessModeDrop, Threshold: 1})
+	if err == nil {
+		t.Error("Expected error when every frame is dropped")
+	}
+}
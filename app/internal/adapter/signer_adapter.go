@@ -0,0 +1,30 @@
+package adapter
+
+import (
+	"context"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/port"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/signing"
+)
+
+type SignerAdapter struct {
+	signer signing.Signer
+}
+
+func NewSignerAdapter(signer signing.Signer) port.SignerPort {
+	return &SignerAdapter{
+		signer: signer,
+	}
+}
+
+func (a *SignerAdapter) Sign(ctx context.Context, payload []byte) (string, error) {
+	return a.signer.Sign(ctx, payload)
+}
+
+func (a *SignerAdapter) Algorithm() string {
+	return a.signer.Algorithm()
+}
+
+func (a *SignerAdapter) KeyID() string {
+	return a.signer.KeyID()
+}
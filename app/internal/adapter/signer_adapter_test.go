@@ -0,0 +1,58 @@
+package adapter
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/signing"
+)
+
+func TestNewSignerAdapter(t *testing.T) {
+	adapter := NewSignerAdapter(&signing.MockSigner{})
+
+	if adapter == nil {
+		t.Fatal("NewSignerAdapter returned nil")
+	}
+}
+
+func TestSignerAdapter_Sign(t *testing.T) {
+	mock := &signing.MockSigner{
+		SignFunc: func(ctx context.Context, payload []byte) (string, error) {
+			return "sig-" + string(payload), nil
+		},
+		AlgorithmValue: "HMAC-SHA256",
+		KeyIDValue:     "key-1",
+	}
+
+	adapter := NewSignerAdapter(mock)
+
+	signature, err := adapter.Sign(context.Background(), []byte("body"))
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+	if signature != "sig-body" {
+		t.Errorf("Expected sig-body, got %s", signature)
+	}
+	if adapter.Algorithm() != "HMAC-SHA256" {
+		t.Errorf("Expected algorithm HMAC-SHA256, got %s", adapter.Algorithm())
+	}
+	if adapter.KeyID() != "key-1" {
+		t.Errorf("Expected key ID key-1, got %s", adapter.KeyID())
+	}
+}
+
+func TestSignerAdapter_SignError(t *testing.T) {
+	expectedError := errors.New("kms unavailable")
+	mock := &signing.MockSigner{
+		SignFunc: func(ctx context.Context, payload []byte) (string, error) {
+			return "", expectedError
+		},
+	}
+
+	adapter := NewSignerAdapter(mock)
+
+	if _, err := adapter.Sign(context.Background(), []byte("body")); err != expectedError {
+		t.Errorf("Expected error %v, got %v", expectedError, err)
+	}
+}
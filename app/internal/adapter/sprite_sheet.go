@@ -0,0 +1,189 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	_ "image/png"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+)
+
+const (
+	spriteJPEGQuality  = 80
+	spriteVTTFileName  = "thumbnails.vtt"
+	spriteSheetPattern = "sprite_%03d.jpg"
+)
+
+// GenerateSpriteSheet samples one thumbnail every request.Sprite.IntervalSeconds, tiles them
+// into sprite sheets of Columns x Rows and zips the sheets with a WebVTT
+// thumbnails file pointing each time range to its tile
+func (p *FFmpegVideoProcessor) GenerateSpriteSheet(ctx context.Context, request domain.ProcessingRequest) (domain.ProcessingResult, error) {
+	jobID, videoPath, options, frameOptions := sanitizeJobID(request.JobID), request.VideoPath, request.Sprite, request.Options
+	if jobID == "" {
+		return domain.ProcessingResult{}, fmt.Errorf("job id is required")
+	}
+	options = options.WithDefaults()
+
+	probe, err := p.probe(ctx, videoPath, frameOptions)
+	if err != nil {
+		return domain.ProcessingResult{}, err
+	}
+
+	source, err := videoSource(videoPath, probe, frameOptions)
+	if err != nil {
+		return domain.ProcessingResult{}, err
+	}
+
+	processDir := filepath.Join(p.tempDir, "sprite_"+jobID)
+	if err := os.MkdirAll(processDir, 0777); err != nil {
+		return domain.ProcessingResult{}, fmt.Errorf("failed to create process directory: %w", err)
+	}
+	defer os.RemoveAll(processDir)
+
+	// Scale and pad every thumbnail to the same size so the tile offsets in the VTT are exact
+	filter := fmt.Sprintf("fps=1/%s,scale=%d:%d:force_original_aspect_ratio=decrease,pad=%d:%d:(ow-iw)/2:(oh-ih)/2",
+		strconv.FormatFloat(options.IntervalSeconds, 'f', -1, 64),
+		options.Width, options.Height, options.Width, options.Height,
+	)
+	groups, err := p.extractImages(ctx, videoPath, source, filter, filepath.Join(processDir, "thumb_%05d.png"), frameOptions.Windows)
+	if err != nil {
+		return domain.ProcessingResult{}, err
+	}
+
+	thumbs := spriteThumbs(groups, frameOptions.Windows, options.IntervalSeconds)
+	if len(thumbs) == 0 {
+		return domain.ProcessingResult{}, fmt.Errorf("no thumbnails extracted from video")
+	}
+	request.Report(domain.ProcessingStepExtract, len(thumbs))
+
+	files, err := buildSpriteSheets(thumbs, options, processDir)
+	if err != nil {
+		return domain.ProcessingResult{}, fmt.Errorf("failed to build sprite sheets: %w", err)
+	}
+
+	archive := frameOptions.Archive.Or(p.archive)
+	zipPaths, err := p.createZipParts(request.JobID, files, filepath.Join(p.tempDir, "sprites_"+jobID+archive.Extension()), archive)
+	if err != nil {
+		return domain.ProcessingResult{}, fmt.Errorf("failed to create zip: %w", err)
+	}
+
+	// The last file is the VTT, everything else is a sheet
+	request.Report(domain.ProcessingStepArchive, len(files)-1)
+	return domain.ProcessingResult{Artifacts: zipPaths, FrameCount: len(files) - 1, Video: videoInfo(probe)}, nil
+}
+
+// spriteThumb is an extracted thumbnail and the video time it starts showing
+type spriteThumb struct {
+	path  string
+	start time.Duration
+}
+
+// spriteThumbs timestamps the thumbnails: each window starts at its own offset and the
+// thumbnails inside it are one interval apart
+func spriteThumbs(groups [][]string, windows []domain.TimeWindow, intervalSeconds float64) []spriteThumb {
+	interval := time.Duration(intervalSeconds * float64(time.Second))
+
+	var thumbs []spriteThumb
+	for i, group := range groups {
+		var offset time.Duration
+		if i < len(windows) {
+			offset = time.Duration(windows[i].Start * float64(time.Second))
+		}
+		for j, path := range group {
+			thumbs = append(thumbs, spriteThumb{path: path, start: offset + time.Duration(j)*interval})
+		}
+	}
+	return thumbs
+}
+
+// buildSpriteSheets tiles the thumbnails in order and writes the sheets plus the VTT file
+// into dir, returning their paths with the VTT last
+func buildSpriteSheets(thumbs []spriteThumb, options domain.SpriteOptions, dir string) ([]string, error) {
+	perSheet := options.Columns * options.Rows
+	interval := time.Duration(options.IntervalSeconds * float64(time.Second))
+
+	var files []string
+	var vtt strings.Builder
+	vtt.WriteString("WEBVTT\n")
+
+	for start := 0; start < len(thumbs); start += perSheet {
+		end := start + perSheet
+		if end > len(thumbs) {
+			end = len(thumbs)
+		}
+
+		sheetName := fmt.Sprintf(spriteSheetPattern, len(files)+1)
+		sheet := image.NewRGBA(image.Rect(0, 0, options.Columns*options.Width, options.Rows*options.Height))
+
+		for i, thumb := range thumbs[start:end] {
+			x := (i % options.Columns) * options.Width
+			y := (i / options.Columns) * options.Height
+			if err := drawThumbnail(sheet, thumb.path, x, y, options); err != nil {
+				return nil, err
+			}
+
+			fmt.Fprintf(&vtt, "\n%s --> %s\n%s#xywh=%d,%d,%d,%d\n",
+				formatVTTTimestamp(thumb.start),
+				formatVTTTimestamp(thumb.start+interval),
+				sheetName, x, y, options.Width, options.Height,
+			)
+		}
+
+		sheetPath := filepath.Join(dir, sheetName)
+		if err := writeJPEG(sheetPath, sheet); err != nil {
+			return nil, err
+		}
+		files = append(files, sheetPath)
+	}
+
+	vttPath := filepath.Join(dir, spriteVTTFileName)
+	if err := os.WriteFile(vttPath, []byte(vtt.String()), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write thumbnails vtt: %w", err)
+	}
+
+	return append(files, vttPath), nil
+}
+
+func drawThumbnail(sheet draw.Image, path string, x, y int, options domain.SpriteOptions) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	thumb, _, err := image.Decode(file)
+	if err != nil {
+		return fmt.Errorf("failed to decode thumbnail %s: %w", filepath.Base(path), err)
+	}
+
+	target := image.Rect(x, y, x+options.Width, y+options.Height)
+	draw.Draw(sheet, target, thumb, thumb.Bounds().Min, draw.Src)
+	return nil
+}
+
+func writeJPEG(path string, img image.Image) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := jpeg.Encode(file, img, &jpeg.Options{Quality: spriteJPEGQuality}); err != nil {
+		return fmt.Errorf("failed to encode sprite sheet: %w", err)
+	}
+	return file.Close()
+}
+
+// formatVTTTimestamp formats a duration as HH:MM:SS.mmm
+func formatVTTTimestamp(d time.Duration) string {
+	ms := d.Milliseconds()
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", ms/3600000, ms/60000%60, ms/1000%60, ms%1000)
+}
@@ -0,0 +1,125 @@
+package adapter
+
+import (
+	"context"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+)
+
+func writeTestThumbnails(t *testing.T, dir string, count, width, height int) []string {
+	t.Helper()
+	var thumbs []string
+	for i := 0; i < count; i++ {
+		img := image.NewRGBA(image.Rect(0, 0, width, height))
+		img.Set(0, 0, color.RGBA{R: uint8(i), A: 255})
+
+		path := filepath.Join(dir, "thumb_"+string(rune('a'+i))+".png")
+		file, err := os.Create(path)
+		if err != nil {
+			t.Fatalf("Failed to create thumbnail: %v", err)
+		}
+		if err := png.Encode(file, img); err != nil {
+			t.Fatalf("Failed to encode thumbnail: %v", err)
+		}
+		file.Close()
+		thumbs = append(thumbs, path)
+	}
+	return thumbs
+}
+
+func TestBuildSpriteSheets(t *testing.T) {
+	dir := t.TempDir()
+	options := domain.SpriteOptions{Columns: 2, Rows: 2, IntervalSeconds: 5, Width: 16, Height: 9}
+	thumbs := writeTestThumbnails(t, dir, 5, 16, 9)
+
+	files, err := buildSpriteSheets(spriteThumbs([][]string{thumbs}, nil, options.IntervalSeconds), options, dir)
+	if err != nil {
+		t.Fatalf("buildSpriteSheets failed: %v", err)
+	}
+
+	if len(files) != 3 {
+		t.Fatalf("Expected 2 sheets and 1 vtt, got %v", files)
+	}
+	if filepath.Base(files[2]) != spriteVTTFileName {
+		t.Errorf("Expected vtt as last file, got %s", files[2])
+	}
+
+	sheet, err := os.Open(files[0])
+	if err != nil {
+		t.Fatalf("Failed to open sheet: %v", err)
+	}
+	defer sheet.Close()
+	config, err := jpeg.DecodeConfig(sheet)
+	if err != nil {
+		t.Fatalf("Failed to decode sheet: %v", err)
+	}
+	if config.Width != 32 || config.Height != 18 {
+		t.Errorf("Expected sheet 32x18, got %dx%d", config.Width, config.Height)
+	}
+
+	vtt, _ := os.ReadFile(files[2])
+	content := string(vtt)
+	if !strings.HasPrefix(content, "WEBVTT\n") {
+		t.Errorf("Expected WEBVTT header, got %q", content)
+	}
+	if !strings.Contains(content, "00:00:15.000 --> 00:00:20.000\nsprite_001.jpg#xywh=16,9,16,9") {
+		t.Errorf("Expected fourth tile cue, got %q", content)
+	}
+	if !strings.Contains(content, "00:00:20.000 --> 00:00:25.000\nsprite_002.jpg#xywh=0,0,16,9") {
+		t.Errorf("Expected fifth tile on second sheet, got %q", content)
+	}
+}
+
+func TestBuildSpriteSheets_InvalidThumbnail(t *testing.T) {
+	dir := t.TempDir()
+	invalid := filepath.Join(dir, "thumb_00001.png")
+	os.WriteFile(invalid, []byte("not a png"), 0644)
+
+	if _, err := buildSpriteSheets([]spriteThumb{{path: invalid}}, domain.DefaultSpriteOptions, dir); err == nil {
+		t.Error("Expected error for invalid thumbnail")
+	}
+}
+
+func TestSpriteThumbs_Windows(t *testing.T) {
+	groups := [][]string{{"a.png", "b.png"}, {"c.png"}}
+	windows := []domain.TimeWindow{{Start: 10, End: 20}, {Start: 60, End: 70}}
+
+	thumbs := spriteThumbs(groups, windows, 5)
+
+	expected := []time.Duration{10 * time.Second, 15 * time.Second, 60 * time.Second}
+	if len(thumbs) != len(expected) {
+		t.Fatalf("Expected %d thumbs, got %d", len(expected), len(thumbs))
+	}
+	for i, thumb := range thumbs {
+		if thumb.start != expected[i] {
+			t.Errorf("Expected thumb %d at %v, got %v", i, expected[i], thumb.start)
+		}
+	}
+	if thumbs[2].path != "c.png" {
+		t.Errorf("Expected thumbs in window order, got %s", thumbs[2].path)
+	}
+}
+
+func TestFormatVTTTimestamp(t *testing.T) {
+	got := formatVTTTimestamp(time.Hour + 2*time.Minute + 3*time.Second + 450*time.Millisecond)
+	if got != "01:02:03.450" {
+		t.Errorf("Expected 01:02:03.450, got %s", got)
+	}
+}
+
+func TestFFmpegVideoProcessor_GenerateSpriteSheet_FFmpegError(t *testing.T) {
+	processor := NewFFmpegVideoProcessorWithBinaries(t.TempDir(), "/nonexistent/ffmpeg", "")
+
+	if _, err := processor.GenerateSpriteSheet(context.Background(), domain.ProcessingRequest{JobID: "job-1", VideoPath: "video.mp4"}); err == nil {
+		t.Error("Expected error for missing ffmpeg binary")
+	}
+}
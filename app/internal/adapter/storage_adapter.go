@@ -2,8 +2,11 @@ package adapter
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"io"
 
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
 	"github.com/SOAT-Project/hackaton-soat-processor/internal/port"
 	"github.com/SOAT-Project/hackaton-soat-processor/pkg/storage"
 )
@@ -18,14 +21,101 @@ func NewStorageAdapter(service storage.StorageService) port.StoragePort {
 	}
 }
 
-func (a *StorageAdapter) GetObject(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
-	return a.service.GetObject(ctx, bucket, key)
+func (a *StorageAdapter) GetObject(ctx context.Context, bucket, key string, options ...domain.GetOption) (io.ReadCloser, error) {
+	body, err := a.service.GetObject(ctx, bucket, key, storageGetOptions(options)...)
+	if errors.Is(err, storage.ErrObjectNotFound) {
+		return nil, fmt.Errorf("%w: %v", domain.ErrObjectNotFound, err)
+	}
+	return body, err
+}
+
+func (a *StorageAdapter) HeadObject(ctx context.Context, bucket, key string) (domain.ObjectInfo, error) {
+	info, err := a.service.HeadObject(ctx, bucket, key)
+	if errors.Is(err, storage.ErrObjectNotFound) {
+		return domain.ObjectInfo{}, fmt.Errorf("%w: %v", domain.ErrObjectNotFound, err)
+	}
+	if err != nil {
+		return domain.ObjectInfo{}, err
+	}
+	return domain.ObjectInfo{
+		SizeBytes:   info.Size,
+		ETag:        info.ETag,
+		VersionID:   info.VersionID,
+		ContentType: info.ContentType,
+		Metadata:    info.Metadata,
+	}, nil
+}
+
+func (a *StorageAdapter) GetObjectIf(ctx context.Context, bucket, key string, condition domain.ObjectCondition, options ...domain.GetOption) (io.ReadCloser, error) {
+	body, err := a.service.GetObjectIf(ctx, bucket, key, storage.ObjectCondition{ETag: condition.ETag, VersionID: condition.VersionID}, storageGetOptions(options)...)
+	switch {
+	case errors.Is(err, storage.ErrObjectNotFound):
+		return nil, fmt.Errorf("%w: %v", domain.ErrObjectNotFound, err)
+	case errors.Is(err, storage.ErrObjectModified):
+		return nil, fmt.Errorf("%w: %v", domain.ErrObjectModified, err)
+	}
+	return body, err
+}
+
+func (a *StorageAdapter) PutObject(ctx context.Context, bucket, key string, body io.Reader, options ...domain.PutOption) (string, error) {
+	return a.service.PutObject(ctx, bucket, key, body, storagePutOptions(options)...)
 }
 
-func (a *StorageAdapter) PutObject(ctx context.Context, bucket, key string, body io.Reader) (string, error) {
-	return a.service.PutObject(ctx, bucket, key, body)
+func (a *StorageAdapter) CopyObject(ctx context.Context, srcBucket, srcKey, srcVersionID, dstBucket, dstKey string, options ...domain.PutOption) error {
+	err := a.service.CopyObject(ctx, srcBucket, srcKey, srcVersionID, dstBucket, dstKey, storagePutOptions(options)...)
+	if errors.Is(err, storage.ErrObjectNotFound) {
+		return fmt.Errorf("%w: %v", domain.ErrObjectNotFound, err)
+	}
+	return err
 }
 
 func (a *StorageAdapter) DeleteObject(ctx context.Context, bucket, key string) error {
 	return a.service.DeleteObject(ctx, bucket, key)
 }
+
+func (a *StorageAdapter) DeleteObjectVersion(ctx context.Context, bucket, key, versionID string) error {
+	return a.service.DeleteObjectVersion(ctx, bucket, key, versionID)
+}
+
+func (a *StorageAdapter) ListObjects(ctx context.Context, bucket, prefix string) ([]string, error) {
+	return a.service.ListObjects(ctx, bucket, prefix)
+}
+
+func (a *StorageAdapter) ListObjectPages(ctx context.Context, bucket, prefix string, fn func(page []domain.ObjectSummary) error) error {
+	return a.service.ListObjectPages(ctx, bucket, prefix, func(page []storage.ObjectSummary) error {
+		summaries := make([]domain.ObjectSummary, 0, len(page))
+		for _, object := range page {
+			summaries = append(summaries, domain.ObjectSummary{Key: object.Key, SizeBytes: object.Size, LastModified: object.LastModified})
+		}
+		return fn(summaries)
+	})
+}
+
+func (a *StorageAdapter) DeleteObjects(ctx context.Context, bucket string, keys []string) (map[string]error, error) {
+	return a.service.DeleteObjects(ctx, bucket, keys)
+}
+
+func (a *StorageAdapter) AbortMultipartUploads(ctx context.Context, bucket, prefix string) (int, error) {
+	return a.service.AbortMultipartUploads(ctx, bucket, prefix)
+}
+
+// storageGetOptions translates the domain options into the storage client's
+func storageGetOptions(options []domain.GetOption) []storage.GetOption {
+	var getOptions []storage.GetOption
+	if o := domain.NewGetOptions(options...); o.Ranged() {
+		getOptions = append(getOptions, storage.WithRange(o.RangeStart, o.RangeLength))
+	}
+	return getOptions
+}
+
+// storagePutOptions translates the domain options into the storage client's
+func storagePutOptions(options []domain.PutOption) []storage.PutOption {
+	o := domain.NewPutOptions(options...)
+	return []storage.PutOption{
+		storage.WithStorageClass(o.StorageClass),
+		storage.WithContentType(o.ContentType),
+		storage.WithMetadata(o.Metadata),
+		storage.WithServerSideEncryption(o.ServerSideEncryption, o.KMSKeyID),
+		storage.WithTags(o.Tags),
+	}
+}
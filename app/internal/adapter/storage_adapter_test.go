@@ -3,32 +3,67 @@ package adapter
 import (
 	"context"
 	"errors"
+	"fmt"
 	"io"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/storage"
 )
 
 // Mock StorageService
 type mockStorageService struct {
-	getObjectFunc    func(ctx context.Context, bucket, key string) (io.ReadCloser, error)
-	putObjectFunc    func(ctx context.Context, bucket, key string, body io.Reader) (string, error)
+	getObjectFunc    func(ctx context.Context, bucket, key string, options storage.GetOptions) (io.ReadCloser, error)
+	headObjectFunc   func(ctx context.Context, bucket, key string) (storage.ObjectInfo, error)
+	getObjectIfFunc  func(ctx context.Context, bucket, key string, condition storage.ObjectCondition, options storage.GetOptions) (io.ReadCloser, error)
+	putObjectFunc    func(ctx context.Context, bucket, key string, body io.Reader, options storage.PutOptions) (string, error)
 	deleteObjectFunc func(ctx context.Context, bucket, key string) error
+	listObjectsFunc  func(ctx context.Context, bucket, prefix string) ([]string, error)
+
+	deleteObjectVersionFunc   func(ctx context.Context, bucket, key, versionID string) error
+	copyObjectFunc            func(ctx context.Context, srcBucket, srcKey, srcVersionID, dstBucket, dstKey string, options storage.PutOptions) error
+	listObjectPagesFunc       func(ctx context.Context, bucket, prefix string, fn func(page []storage.ObjectSummary) error) error
+	deleteObjectsFunc         func(ctx context.Context, bucket string, keys []string) (map[string]error, error)
+	abortMultipartUploadsFunc func(ctx context.Context, bucket, prefix string) (int, error)
 }
 
-func (m *mockStorageService) GetObject(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
+func (m *mockStorageService) GetObject(ctx context.Context, bucket, key string, options ...storage.GetOption) (io.ReadCloser, error) {
 	if m.getObjectFunc != nil {
-		return m.getObjectFunc(ctx, bucket, key)
+		return m.getObjectFunc(ctx, bucket, key, storage.NewGetOptions(options...))
+	}
+	return nil, nil
+}
+
+func (m *mockStorageService) HeadObject(ctx context.Context, bucket, key string) (storage.ObjectInfo, error) {
+	if m.headObjectFunc != nil {
+		return m.headObjectFunc(ctx, bucket, key)
+	}
+	return storage.ObjectInfo{}, nil
+}
+
+func (m *mockStorageService) GetObjectIf(ctx context.Context, bucket, key string, condition storage.ObjectCondition, options ...storage.GetOption) (io.ReadCloser, error) {
+	if m.getObjectIfFunc != nil {
+		return m.getObjectIfFunc(ctx, bucket, key, condition, storage.NewGetOptions(options...))
 	}
 	return nil, nil
 }
 
-func (m *mockStorageService) PutObject(ctx context.Context, bucket, key string, body io.Reader) (string, error) {
+func (m *mockStorageService) PutObject(ctx context.Context, bucket, key string, body io.Reader, options ...storage.PutOption) (string, error) {
 	if m.putObjectFunc != nil {
-		return m.putObjectFunc(ctx, bucket, key, body)
+		return m.putObjectFunc(ctx, bucket, key, body, storage.NewPutOptions(options...))
 	}
 	return "", nil
 }
 
+func (m *mockStorageService) CopyObject(ctx context.Context, srcBucket, srcKey, srcVersionID, dstBucket, dstKey string, options ...storage.PutOption) error {
+	if m.copyObjectFunc != nil {
+		return m.copyObjectFunc(ctx, srcBucket, srcKey, srcVersionID, dstBucket, dstKey, storage.NewPutOptions(options...))
+	}
+	return nil
+}
+
 func (m *mockStorageService) DeleteObject(ctx context.Context, bucket, key string) error {
 	if m.deleteObjectFunc != nil {
 		return m.deleteObjectFunc(ctx, bucket, key)
@@ -36,6 +71,41 @@ func (m *mockStorageService) DeleteObject(ctx context.Context, bucket, key strin
 	return nil
 }
 
+func (m *mockStorageService) DeleteObjectVersion(ctx context.Context, bucket, key, versionID string) error {
+	if m.deleteObjectVersionFunc != nil {
+		return m.deleteObjectVersionFunc(ctx, bucket, key, versionID)
+	}
+	return nil
+}
+
+func (m *mockStorageService) ListObjects(ctx context.Context, bucket, prefix string) ([]string, error) {
+	if m.listObjectsFunc != nil {
+		return m.listObjectsFunc(ctx, bucket, prefix)
+	}
+	return nil, nil
+}
+
+func (m *mockStorageService) ListObjectPages(ctx context.Context, bucket, prefix string, fn func(page []storage.ObjectSummary) error) error {
+	if m.listObjectPagesFunc != nil {
+		return m.listObjectPagesFunc(ctx, bucket, prefix, fn)
+	}
+	return nil
+}
+
+func (m *mockStorageService) DeleteObjects(ctx context.Context, bucket string, keys []string) (map[string]error, error) {
+	if m.deleteObjectsFunc != nil {
+		return m.deleteObjectsFunc(ctx, bucket, keys)
+	}
+	return nil, nil
+}
+
+func (m *mockStorageService) AbortMultipartUploads(ctx context.Context, bucket, prefix string) (int, error) {
+	if m.abortMultipartUploadsFunc != nil {
+		return m.abortMultipartUploadsFunc(ctx, bucket, prefix)
+	}
+	return 0, nil
+}
+
 func TestNewStorageAdapter(t *testing.T) {
 	mock := &mockStorageService{}
 	adapter := NewStorageAdapter(mock)
@@ -47,7 +117,7 @@ func TestNewStorageAdapter(t *testing.T) {
 
 func TestStorageAdapter_GetObject_Success(t *testing.T) {
 	mock := &mockStorageService{
-		getObjectFunc: func(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
+		getObjectFunc: func(ctx context.Context, bucket, key string, options storage.GetOptions) (io.ReadCloser, error) {
 			return io.NopCloser(strings.NewReader("test content")), nil
 		},
 	}
@@ -76,7 +146,7 @@ func TestStorageAdapter_GetObject_Success(t *testing.T) {
 func TestStorageAdapter_GetObject_Error(t *testing.T) {
 	expectedError := errors.New("storage error")
 	mock := &mockStorageService{
-		getObjectFunc: func(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
+		getObjectFunc: func(ctx context.Context, bucket, key string, options storage.GetOptions) (io.ReadCloser, error) {
 			return nil, expectedError
 		},
 	}
@@ -93,7 +163,7 @@ func TestStorageAdapter_GetObject_Error(t *testing.T) {
 func TestStorageAdapter_PutObject_Success(t *testing.T) {
 	expectedLocation := "s3://bucket/key"
 	mock := &mockStorageService{
-		putObjectFunc: func(ctx context.Context, bucket, key string, body io.Reader) (string, error) {
+		putObjectFunc: func(ctx context.Context, bucket, key string, body io.Reader, options storage.PutOptions) (string, error) {
 			return expectedLocation, nil
 		},
 	}
@@ -111,10 +181,49 @@ func TestStorageAdapter_PutObject_Success(t *testing.T) {
 	}
 }
 
+func TestStorageAdapter_Options(t *testing.T) {
+	var put storage.PutOptions
+	var get storage.GetOptions
+	mock := &mockStorageService{
+		putObjectFunc: func(ctx context.Context, bucket, key string, body io.Reader, options storage.PutOptions) (string, error) {
+			put = options
+			return key, nil
+		},
+		getObjectFunc: func(ctx context.Context, bucket, key string, options storage.GetOptions) (io.ReadCloser, error) {
+			get = options
+			return io.NopCloser(strings.NewReader("")), nil
+		},
+	}
+	adapter := NewStorageAdapter(mock)
+	ctx := context.Background()
+
+	_, err := adapter.PutObject(ctx, "bucket", "frames.zip", strings.NewReader("zip"),
+		domain.WithStorageClass("GLACIER_IR"),
+		domain.WithContentType("application/zip"),
+		domain.WithMetadata(map[string]string{"process-id": "123"}),
+		domain.WithServerSideEncryption("aws:kms", "key-1"),
+		domain.WithTags(map[string]string{"expires-at": "2024-01-08"}),
+	)
+	if err != nil {
+		t.Fatalf("PutObject failed: %v", err)
+	}
+	if put.StorageClass != "GLACIER_IR" || put.ContentType != "application/zip" || put.Metadata["process-id"] != "123" ||
+		put.ServerSideEncryption != "aws:kms" || put.KMSKeyID != "key-1" || put.Tags["expires-at"] != "2024-01-08" {
+		t.Errorf("Expected every put option passed to the service, got %+v", put)
+	}
+
+	if _, err := adapter.GetObject(ctx, "bucket", "video.mp4", domain.WithRange(10, 20)); err != nil {
+		t.Fatalf("GetObject failed: %v", err)
+	}
+	if get.RangeStart != 10 || get.RangeLength != 20 {
+		t.Errorf("Expected the range passed to the service, got %+v", get)
+	}
+}
+
 func TestStorageAdapter_PutObject_Error(t *testing.T) {
 	expectedError := errors.New("upload error")
 	mock := &mockStorageService{
-		putObjectFunc: func(ctx context.Context, bucket, key string, body io.Reader) (string, error) {
+		putObjectFunc: func(ctx context.Context, bucket, key string, body io.Reader, options storage.PutOptions) (string, error) {
 			return "", expectedError
 		},
 	}
@@ -162,15 +271,186 @@ func TestStorageAdapter_DeleteObject_Error(t *testing.T) {
 	}
 }
 
+func TestStorageAdapter_CopyObject(t *testing.T) {
+	var copied string
+	mock := &mockStorageService{
+		copyObjectFunc: func(ctx context.Context, srcBucket, srcKey, srcVersionID, dstBucket, dstKey string, options storage.PutOptions) error {
+			if srcKey == "missing.mp4" {
+				return fmt.Errorf("%w: %s", storage.ErrObjectNotFound, srcKey)
+			}
+			copied = srcBucket + "/" + srcKey + "@" + srcVersionID + " -> " + dstBucket + "/" + dstKey
+			if reason := options.Tags["quarantine-reason"]; reason != "" {
+				copied += " #" + reason
+			}
+			return nil
+		},
+	}
+
+	adapter := NewStorageAdapter(mock)
+	if err := adapter.CopyObject(context.Background(), "input", "video.mp4", "v2", "archive", "2025/video.mp4"); err != nil {
+		t.Fatalf("CopyObject failed: %v", err)
+	}
+	if copied != "input/video.mp4@v2 -> archive/2025/video.mp4" {
+		t.Errorf("Expected the server-side copy, got %s", copied)
+	}
+	err := adapter.CopyObject(context.Background(), "input", "video.mp4", "", "quarantine", "1/video.mp4",
+		domain.WithTags(map[string]string{"quarantine-reason": "malware_detected"}))
+	if err != nil {
+		t.Fatalf("CopyObject failed: %v", err)
+	}
+	if copied != "input/video.mp4@ -> quarantine/1/video.mp4 #malware_detected" {
+		t.Errorf("Expected the copy tagged, got %s", copied)
+	}
+	if err := adapter.CopyObject(context.Background(), "input", "missing.mp4", "", "archive", "missing.mp4"); !errors.Is(err, domain.ErrObjectNotFound) {
+		t.Errorf("Expected domain.ErrObjectNotFound, got %v", err)
+	}
+}
+
+func TestStorageAdapter_DeleteObjectVersion(t *testing.T) {
+	var deleted string
+	mock := &mockStorageService{
+		deleteObjectVersionFunc: func(ctx context.Context, bucket, key, versionID string) error {
+			deleted = bucket + "/" + key + "@" + versionID
+			return nil
+		},
+	}
+
+	adapter := NewStorageAdapter(mock)
+	if err := adapter.DeleteObjectVersion(context.Background(), "test-bucket", "test-key", "v2"); err != nil {
+		t.Fatalf("DeleteObjectVersion failed: %v", err)
+	}
+	if deleted != "test-bucket/test-key@v2" {
+		t.Errorf("Expected the version deleted, got %s", deleted)
+	}
+}
+
+func TestStorageAdapter_ListObjects(t *testing.T) {
+	mock := &mockStorageService{
+		listObjectsFunc: func(ctx context.Context, bucket, prefix string) ([]string, error) {
+			if bucket != "test-bucket" || prefix != "videos/" {
+				t.Errorf("Unexpected listing of s3://%s/%s", bucket, prefix)
+			}
+			return []string{"videos/a.mp4", "videos/b.mp4"}, nil
+		},
+	}
+
+	keys, err := NewStorageAdapter(mock).ListObjects(context.Background(), "test-bucket", "videos/")
+	if err != nil {
+		t.Fatalf("ListObjects failed: %v", err)
+	}
+	if len(keys) != 2 || keys[0] != "videos/a.mp4" {
+		t.Errorf("Unexpected keys %v", keys)
+	}
+}
+
+func TestStorageAdapter_GetObjectNotFound(t *testing.T) {
+	mock := &mockStorageService{
+		getObjectFunc: func(ctx context.Context, bucket, key string, options storage.GetOptions) (io.ReadCloser, error) {
+			return nil, fmt.Errorf("%w: %s", storage.ErrObjectNotFound, key)
+		},
+	}
+
+	_, err := NewStorageAdapter(mock).GetObject(context.Background(), "test-bucket", "missing.json")
+	if !errors.Is(err, domain.ErrObjectNotFound) {
+		t.Errorf("Expected domain.ErrObjectNotFound, got %v", err)
+	}
+}
+
+func TestStorageAdapter_HeadObject(t *testing.T) {
+	mock := &mockStorageService{
+		headObjectFunc: func(ctx context.Context, bucket, key string) (storage.ObjectInfo, error) {
+			if key == "missing.mp4" {
+				return storage.ObjectInfo{}, fmt.Errorf("%w: %s", storage.ErrObjectNotFound, key)
+			}
+			return storage.ObjectInfo{Size: 1024, ETag: "abc", ContentType: "video/mp4", Metadata: map[string]string{"camera": "a7"}}, nil
+		},
+	}
+	adapter := NewStorageAdapter(mock)
+
+	info, err := adapter.HeadObject(context.Background(), "test-bucket", "video.mp4")
+	if err != nil {
+		t.Fatalf("HeadObject failed: %v", err)
+	}
+	if info.SizeBytes != 1024 || info.ETag != "abc" || info.ContentType != "video/mp4" || info.Metadata["camera"] != "a7" {
+		t.Errorf("Unexpected object info %+v", info)
+	}
+	if _, err := adapter.HeadObject(context.Background(), "test-bucket", "missing.mp4"); !errors.Is(err, domain.ErrObjectNotFound) {
+		t.Errorf("Expected domain.ErrObjectNotFound, got %v", err)
+	}
+}
+
+func TestStorageAdapter_GetObjectIf(t *testing.T) {
+	var got storage.ObjectCondition
+	var gotOptions storage.GetOptions
+	mock := &mockStorageService{
+		getObjectIfFunc: func(ctx context.Context, bucket, key string, condition storage.ObjectCondition, options storage.GetOptions) (io.ReadCloser, error) {
+			got, gotOptions = condition, options
+			return nil, fmt.Errorf("%w: %s", storage.ErrObjectModified, key)
+		},
+	}
+
+	_, err := NewStorageAdapter(mock).GetObjectIf(context.Background(), "test-bucket", "video.mp4", domain.ObjectCondition{ETag: "abc", VersionID: "v2"}, domain.WithRange(100, 0))
+	if !errors.Is(err, domain.ErrObjectModified) {
+		t.Errorf("Expected domain.ErrObjectModified, got %v", err)
+	}
+	if got.ETag != "abc" || got.VersionID != "v2" {
+		t.Errorf("Expected the condition passed on, got %+v", got)
+	}
+	if gotOptions.RangeStart != 100 || gotOptions.RangeLength != 0 {
+		t.Errorf("Expected the range passed on, got %+v", gotOptions)
+	}
+}
+
+func TestStorageAdapter_ListObjectPages(t *testing.T) {
+	modified := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	mock := &mockStorageService{
+		listObjectPagesFunc: func(ctx context.Context, bucket, prefix string, fn func(page []storage.ObjectSummary) error) error {
+			if err := fn([]storage.ObjectSummary{{Key: prefix + "a.zip", Size: 3, LastModified: modified}}); err != nil {
+				return err
+			}
+			return fn([]storage.ObjectSummary{{Key: prefix + "b.zip", Size: 5, LastModified: modified}})
+		},
+	}
+
+	adapter := NewStorageAdapter(mock)
+	var listed []domain.ObjectSummary
+	err := adapter.ListObjectPages(context.Background(), "test-bucket", "processed/", func(page []domain.ObjectSummary) error {
+		listed = append(listed, page...)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ListObjectPages failed: %v", err)
+	}
+	if len(listed) != 2 || listed[1].Key != "processed/b.zip" || listed[1].SizeBytes != 5 || !listed[1].LastModified.Equal(modified) {
+		t.Errorf("Expected both pages mapped, got %+v", listed)
+	}
+}
+
+func TestStorageAdapter_AbortMultipartUploads(t *testing.T) {
+	mock := &mockStorageService{
+		abortMultipartUploadsFunc: func(ctx context.Context, bucket, prefix string) (int, error) {
+			if bucket != "test-bucket" || prefix != "processed/frames_1.zip" {
+				t.Errorf("Unexpected abort under s3://%s/%s", bucket, prefix)
+			}
+			return 2, nil
+		},
+	}
+
+	aborted, err := NewStorageAdapter(mock).AbortMultipartUploads(context.Background(), "test-bucket", "processed/frames_1.zip")
+	if err != nil || aborted != 2 {
+		t.Errorf("Expected 2 aborted, got %d, %v", aborted, err)
+	}
+}
+
 func TestStorageAdapter_AllOperations(t *testing.T) {
 	mock := &mockStorageService{
-		getObjectFunc: func(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
+		getObjectFunc: func(ctx context.Context, bucket, key string, options storage.GetOptions) (io.ReadCloser, error) {
 			if bucket == "" || key == "" {
 				return nil, errors.New("invalid parameters")
 			}
 			return io.NopCloser(strings.NewReader("data")), nil
 		},
-		putObjectFunc: func(ctx context.Context, bucket, key string, body io.Reader) (string, error) {
+		putObjectFunc: func(ctx context.Context, bucket, key string, body io.Reader, options storage.PutOptions) (string, error) {
 			if bucket == "" || key == "" {
 				return "", errors.New("invalid parameters")
 			}
@@ -0,0 +1,116 @@
+package adapter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/port"
+)
+
+// StorageOutbox keeps each pending result message as a JSON object under bucket/prefix, so
+// the entries outlive the pod that saved them and are retried by any replica. Replicas share
+// the prefix, so an entry may be sent more than once, as with any SQS delivery
+type StorageOutbox struct {
+	storage port.StoragePort
+	bucket  string
+	prefix  string
+}
+
+func NewStorageOutbox(storage port.StoragePort, bucket, prefix string) port.OutboxPort {
+	return &StorageOutbox{
+		storage: storage,
+		bucket:  bucket,
+		prefix:  strings.Trim(prefix, "/"),
+	}
+}
+
+// Save overwrites the previous version of the entry; a put is atomic, so a crash never leaves
+// a half written entry behind
+func (o *StorageOutbox) Save(ctx context.Context, entry domain.OutboxEntry) error {
+	key, err := o.entryKey(entry.ID)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode outbox entry: %w", err)
+	}
+	if _, err := o.storage.PutObject(ctx, o.bucket, key, bytes.NewReader(data)); err != nil {
+		return fmt.Errorf("failed to save outbox entry: %w", err)
+	}
+	return nil
+}
+
+// Pending returns every saved entry, oldest first
+func (o *StorageOutbox) Pending(ctx context.Context) ([]domain.OutboxEntry, error) {
+	keys, err := o.storage.ListObjects(ctx, o.bucket, o.prefix+"/")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list outbox: %w", err)
+	}
+
+	entries := make([]domain.OutboxEntry, 0, len(keys))
+	for _, key := range keys {
+		if path.Ext(key) != ".json" {
+			continue
+		}
+		entry, err := o.read(ctx, key)
+		if errors.Is(err, domain.ErrObjectNotFound) {
+			// Delivered and deleted since the listing
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].CreatedAt.Before(entries[j].CreatedAt)
+	})
+	return entries, nil
+}
+
+func (o *StorageOutbox) read(ctx context.Context, key string) (domain.OutboxEntry, error) {
+	body, err := o.storage.GetObject(ctx, o.bucket, key)
+	if err != nil {
+		return domain.OutboxEntry{}, fmt.Errorf("failed to read outbox entry: %w", err)
+	}
+	defer body.Close()
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return domain.OutboxEntry{}, fmt.Errorf("failed to read outbox entry: %w", err)
+	}
+	var entry domain.OutboxEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return domain.OutboxEntry{}, fmt.Errorf("invalid outbox entry %s: %w", path.Base(key), err)
+	}
+	return entry, nil
+}
+
+func (o *StorageOutbox) Delete(ctx context.Context, id string) error {
+	key, err := o.entryKey(id)
+	if err != nil {
+		return err
+	}
+	if err := o.storage.DeleteObject(ctx, o.bucket, key); err != nil && !errors.Is(err, domain.ErrObjectNotFound) {
+		return fmt.Errorf("failed to delete outbox entry: %w", err)
+	}
+	return nil
+}
+
+func (o *StorageOutbox) entryKey(id string) (string, error) {
+	if id == "" || strings.ContainsAny(id, `/\`) || strings.HasPrefix(id, ".") {
+		return "", fmt.Errorf("invalid outbox entry id %q", id)
+	}
+	return path.Join(o.prefix, id+".json"), nil
+}
@@ -0,0 +1,63 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/ffmpeg"
+)
+
+// Image-based subtitles can be overlaid on the video but not converted to text
+var bitmapSubtitleCodecs = map[string]bool{
+	"hdmv_pgs_subtitle": true,
+	"dvd_subtitle":      true,
+	"dvb_subtitle":      true,
+	"xsub":              true,
+}
+
+var subtitleCodecs = map[string]string{
+	domain.SubtitleFormatSRT: "srt",
+	domain.SubtitleFormatVTT: "webvtt",
+}
+
+// extractSubtitles converts every text subtitle stream to the requested format in a single
+// ffmpeg run, returning the written files; bitmap streams are skipped
+func extractSubtitles(ctx context.Context, ffmpegBinary, videoPath string, streams []ffmpeg.Stream, format, dir string) ([]string, error) {
+	args := []string{"-i", videoPath}
+	var files []string
+	for i, stream := range streams {
+		if bitmapSubtitleCodecs[stream.CodecName] {
+			continue
+		}
+
+		name := fmt.Sprintf("subtitle_%d", i)
+		if language := sanitizeJobID(stream.Language()); language != "" {
+			name += "_" + language
+		}
+		path := filepath.Join(dir, name+"."+format)
+
+		args = append(args, "-map", fmt.Sprintf("0:s:%d", i), "-c:s", subtitleCodecs[format], "-y", path)
+		files = append(files, path)
+	}
+
+	if len(files) == 0 {
+		return nil, nil
+	}
+
+	output, err := ffmpeg.CombinedOutput(ctx, ffmpeg.DefaultKillGrace, ffmpegBinary, args...)
+	if err != nil {
+		return nil, fmt.Errorf("subtitle extraction error: %w, output: %s", err, string(output))
+	}
+	return files, nil
+}
+
+// escapeFilterValue escapes a value for a filter option and then for the filter graph,
+// the two levels ffmpeg unescapes when parsing -filter_complex
+func escapeFilterValue(value string) string {
+	option := strings.NewReplacer(`\`, `\\`, `'`, `\'`, `:`, `\:`)
+	graph := strings.NewReplacer(`\`, `\\`, `'`, `\'`, `[`, `\[`, `]`, `\]`, `,`, `\,`, `;`, `\;`)
+	return graph.Replace(option.Replace(value))
+}
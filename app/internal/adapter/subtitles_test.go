@@ -0,0 +1,128 @@
+package adapter
+
+import (
+	"archive/zip"
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/ffmpeg"
+)
+
+const subtitleProbeOutput = `{"streams":[
+{"index":0,"codec_type":"video","codec_name":"h264"},
+{"index":1,"codec_type":"subtitle","codec_name":"subrip","tags":{"language":"por"}},
+{"index":2,"codec_type":"subtitle","codec_name":"hdmv_pgs_subtitle"},
+{"index":3,"codec_type":"subtitle","codec_name":"ass","tags":{"language":"eng"}}
+]}`
+
+var testSubtitleStreams = []ffmpeg.Stream{
+	{Index: 1, CodecType: ffmpeg.CodecTypeSubtitle, CodecName: "subrip", Tags: map[string]string{"language": "por"}},
+	{Index: 2, CodecType: ffmpeg.CodecTypeSubtitle, CodecName: "hdmv_pgs_subtitle"},
+}
+
+// writeRecordingFFmpeg creates a fake ffmpeg that logs its arguments and writes every
+// output given after -y (expanding the image pattern with -start_number)
+func writeRecordingFFmpeg(t *testing.T) (string, string) {
+	t.Helper()
+	argsFile := filepath.Join(t.TempDir(), "args")
+	script := writeScript(t, "ffmpeg", "echo \"$@\" >> "+argsFile+"\n"+
+		"prev=''\n"+
+		"number=1\n"+
+		"for arg; do\n"+
+		"  if [ \"$prev\" = '-start_number' ]; then number=\"$arg\"; fi\n"+
+		"  if [ \"$prev\" = '-y' ]; then\n"+
+		"    case \"$arg\" in\n"+
+		"      *%0*) printf frame > \"$(printf \"$arg\" \"$number\")\" ;;\n"+
+		"      *) printf output > \"$arg\" ;;\n"+
+		"    esac\n"+
+		"  fi\n"+
+		"  prev=\"$arg\"\n"+
+		"done\n")
+	return script, argsFile
+}
+
+func TestEscapeFilterValue(t *testing.T) {
+	got := escapeFilterValue(`/tmp/it's:a,b.mp4`)
+	if got != `/tmp/it\\\'s\\:a\,b.mp4` {
+		t.Errorf("Unexpected escaped value: %s", got)
+	}
+}
+
+func TestExtractSubtitles(t *testing.T) {
+	ffmpegPath, argsFile := writeRecordingFFmpeg(t)
+	dir := t.TempDir()
+
+	files, err := extractSubtitles(context.Background(), ffmpegPath, "video.mkv", testSubtitleStreams, domain.SubtitleFormatSRT, dir)
+	if err != nil {
+		t.Fatalf("extractSubtitles failed: %v", err)
+	}
+
+	if len(files) != 1 || filepath.Base(files[0]) != "subtitle_0_por.srt" {
+		t.Fatalf("Expected only the text subtitle, got %v", files)
+	}
+	if _, err := os.Stat(files[0]); err != nil {
+		t.Errorf("Expected subtitle file to be written: %v", err)
+	}
+
+	args, _ := os.ReadFile(argsFile)
+	if !strings.Contains(string(args), "-map 0:s:0 -c:s srt") {
+		t.Errorf("Expected srt conversion of the first subtitle stream, got %s", args)
+	}
+}
+
+func TestExtractSubtitles_OnlyBitmap(t *testing.T) {
+	files, err := extractSubtitles(context.Background(), "/nonexistent/ffmpeg", "video.mkv", testSubtitleStreams[1:], domain.SubtitleFormatVTT, t.TempDir())
+	if err != nil {
+		t.Fatalf("Expected no ffmpeg run without text subtitles, got %v", err)
+	}
+	if len(files) != 0 {
+		t.Errorf("Expected no files, got %v", files)
+	}
+}
+
+func TestFFmpegVideoProcessor_ProcessVideo_Subtitles(t *testing.T) {
+	tempDir := t.TempDir()
+	ffmpegPath, argsFile := writeRecordingFFmpeg(t)
+	probeFile := filepath.Join(t.TempDir(), "probe.json")
+	os.WriteFile(probeFile, []byte(subtitleProbeOutput), 0644)
+	ffprobePath := writeScript(t, "ffprobe", "cat "+probeFile+"\n")
+
+	processor := NewFFmpegVideoProcessorWithBinaries(tempDir, ffmpegPath, ffprobePath)
+	track := 0
+	result, err := processor.ProcessVideo(context.Background(), domain.ProcessingRequest{JobID: "job-1", VideoPath: "video.mkv", Options: domain.FrameOptions{
+		Subtitles: domain.SubtitleOptions{Extract: true, BurnTrack: &track},
+	}})
+	if err != nil {
+		t.Fatalf("ProcessVideo failed: %v", err)
+	}
+
+	if result.FrameCount != 1 {
+		t.Errorf("Expected 1 frame, got %d", result.FrameCount)
+	}
+
+	reader, err := zip.OpenReader(result.Artifacts[0])
+	if err != nil {
+		t.Fatalf("Failed to open zip: %v", err)
+	}
+	defer reader.Close()
+
+	var names []string
+	for _, file := range reader.File {
+		names = append(names, file.Name)
+	}
+	sort.Strings(names)
+	expected := []string{"frame_0001.png", "subtitle_0_por.vtt", "subtitle_2_eng.vtt"}
+	if strings.Join(names, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected zip entries %v, got %v", expected, names)
+	}
+
+	args, _ := os.ReadFile(argsFile)
+	if !strings.Contains(string(args), "-noautorotate -i video.mkv -filter_complex [0:v]subtitles=filename=video.mkv:si=0,fps=1") {
+		t.Errorf("Expected burn-in filter before fps, got %s", args)
+	}
+}
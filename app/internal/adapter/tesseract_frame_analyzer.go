@@ -0,0 +1,71 @@
+package adapter
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/port"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/ffmpeg"
+)
+
+// tesseractSparseText is the page segmentation mode for text scattered over the image, as
+// captions and overlays are, instead of a page of paragraphs
+const tesseractSparseText = "11"
+
+type TesseractFrameAnalyzer struct {
+	tesseractPath string
+}
+
+// NewTesseractFrameAnalyzer reads the text of frames with the tesseract CLI; an empty path falls
+// back to PATH
+func NewTesseractFrameAnalyzer(tesseractPath string) port.FrameAnalyzerPort {
+	return &TesseractFrameAnalyzer{tesseractPath: tesseractPath}
+}
+
+func (a *TesseractFrameAnalyzer) tesseractBinary() string {
+	if a.tesseractPath == "" {
+		return "tesseract"
+	}
+	return a.tesseractPath
+}
+
+// ExtractText returns the lines of text found on the frame. tesseract writes them next to the
+// frame, as its warnings go to stderr, and the file is removed once read
+func (a *TesseractFrameAnalyzer) ExtractText(ctx context.Context, framePath string, options domain.OCROptions) (string, error) {
+	outputBase := strings.TrimSuffix(framePath, filepath.Ext(framePath)) + "_ocr"
+	defer os.Remove(outputBase + ".txt")
+
+	output, err := ffmpeg.CombinedOutput(ctx, ffmpeg.DefaultKillGrace, a.tesseractBinary(), tesseractArgs(framePath, outputBase, options)...)
+	if err != nil {
+		return "", fmt.Errorf("tesseract error: %w, output: %s", err, string(output))
+	}
+	text, err := os.ReadFile(outputBase + ".txt")
+	if err != nil {
+		return "", fmt.Errorf("failed to read recognized text: %w", err)
+	}
+	return normalizeText(string(text)), nil
+}
+
+func tesseractArgs(framePath, outputBase string, options domain.OCROptions) []string {
+	return []string{
+		framePath,
+		outputBase,
+		"-l", strings.Join(options.LanguageCodes(), "+"),
+		"--psm", tesseractSparseText,
+	}
+}
+
+// normalizeText trims the lines and drops the blank ones and the form feed ending each page
+func normalizeText(text string) string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(strings.ReplaceAll(line, "\f", "")); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
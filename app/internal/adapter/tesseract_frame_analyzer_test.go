@@ -0,0 +1,57 @@
+package adapter
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+)
+
+func TestTesseractArgs(t *testing.T) {
+	args := tesseractArgs("frame.png", "frame_ocr", domain.OCROptions{Enabled: true, Languages: []string{"por", "eng"}})
+
+	if strings.Join(args, " ") != "frame.png frame_ocr -l por+eng --psm 11" {
+		t.Errorf("Unexpected args: %v", args)
+	}
+	if argValue(tesseractArgs("frame.png", "frame_ocr", domain.OCROptions{Enabled: true}), "-l") != "eng" {
+		t.Error("Expected eng by default")
+	}
+}
+
+func TestNormalizeText(t *testing.T) {
+	if text := normalizeText("  BREAKING NEWS \n\n Live  \n\f"); text != "BREAKING NEWS\nLive" {
+		t.Errorf("Unexpected text %q", text)
+	}
+	if text := normalizeText("\f"); text != "" {
+		t.Errorf("Expected no text, got %q", text)
+	}
+}
+
+func TestTesseractFrameAnalyzer_ExtractText(t *testing.T) {
+	// Writes the text to the output base given as second argument, and a warning to stderr
+	tesseract := writeScript(t, "tesseract", "echo 'Estimating resolution' >&2\nprintf 'SALE 50%%\\n\\n\\f' > \"$2.txt\"\n")
+	framePath := filepath.Join(t.TempDir(), "frame_0001.png")
+
+	text, err := NewTesseractFrameAnalyzer(tesseract).ExtractText(context.Background(), framePath, domain.OCROptions{Enabled: true})
+	if err != nil {
+		t.Fatalf("ExtractText failed: %v", err)
+	}
+	if text != "SALE 50%" {
+		t.Errorf("Expected the text without the warning, got %q", text)
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(framePath), "frame_0001_ocr.txt")); !os.IsNotExist(err) {
+		t.Error("Expected the text file to be removed")
+	}
+}
+
+func TestTesseractFrameAnalyzer_ExtractText_Error(t *testing.T) {
+	tesseract := writeScript(t, "tesseract", "echo 'Failed loading language por' >&2\nexit 1\n")
+
+	_, err := NewTesseractFrameAnalyzer(tesseract).ExtractText(context.Background(), "frame.png", domain.OCROptions{Enabled: true})
+	if err == nil || !strings.Contains(err.Error(), "Failed loading language por") {
+		t.Errorf("Expected tesseract output in the error, got %v", err)
+	}
+}
@@ -0,0 +1,86 @@
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/port"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/transcription"
+)
+
+// TranscribeAdapter starts an AWS Transcribe job, which writes the transcript itself
+type TranscribeAdapter struct {
+	service transcription.TranscriptionService
+}
+
+func NewTranscribeAdapter(service transcription.TranscriptionService) port.TranscriptionPort {
+	return &TranscribeAdapter{
+		service: service,
+	}
+}
+
+func (a *TranscribeAdapter) StartTranscription(ctx context.Context, request domain.TranscriptionRequest) (domain.TranscriptionRef, error) {
+	err := a.service.StartJob(ctx, transcription.Job{
+		Name:         request.JobName,
+		MediaBucket:  request.MediaBucket,
+		MediaKey:     request.MediaKey,
+		LanguageCode: request.Language,
+		OutputBucket: request.TranscriptBucket,
+		OutputKey:    request.TranscriptKey,
+	})
+	if err != nil {
+		return domain.TranscriptionRef{}, err
+	}
+
+	return domain.TranscriptionRef{
+		Provider:      domain.TranscriptionProviderTranscribe,
+		JobName:       request.JobName,
+		TranscriptKey: request.TranscriptKey,
+	}, nil
+}
+
+// QueueTranscriptionAdapter publishes the transcription to a queue, leaving the transcription
+// and the transcript to whatever service consumes it
+type QueueTranscriptionAdapter struct {
+	message  port.MessagePort
+	queueURL string
+}
+
+func NewQueueTranscriptionAdapter(message port.MessagePort, queueURL string) port.TranscriptionPort {
+	return &QueueTranscriptionAdapter{
+		message:  message,
+		queueURL: queueURL,
+	}
+}
+
+// transcriptionMessage is the body published to the transcription queue
+type transcriptionMessage struct {
+	JobName          string `json:"job_name"`
+	ProcessID        string `json:"process_id"`
+	MediaBucket      string `json:"media_bucket"`
+	MediaKey         string `json:"media_key"`
+	Language         string `json:"language,omitempty"`
+	TranscriptBucket string `json:"transcript_bucket"`
+	TranscriptKey    string `json:"transcript_key"`
+}
+
+func (a *QueueTranscriptionAdapter) StartTranscription(ctx context.Context, request domain.TranscriptionRequest) (domain.TranscriptionRef, error) {
+	body, err := json.Marshal(transcriptionMessage(request))
+	if err != nil {
+		return domain.TranscriptionRef{}, fmt.Errorf("failed to encode transcription message: %w", err)
+	}
+
+	messageID, err := a.message.SendMessage(ctx, a.queueURL, string(body))
+	if err != nil {
+		return domain.TranscriptionRef{}, fmt.Errorf("failed to publish transcription message: %w", err)
+	}
+
+	return domain.TranscriptionRef{
+		Provider:      domain.TranscriptionProviderQueue,
+		JobName:       request.JobName,
+		TranscriptKey: request.TranscriptKey,
+		MessageID:     messageID,
+	}, nil
+}
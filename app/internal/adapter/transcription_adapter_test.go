@@ -0,0 +1,105 @@
+package adapter
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/message"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/transcription"
+)
+
+var transcriptionRequest = domain.TranscriptionRequest{
+	JobName:          "p1_abc",
+	ProcessID:        "p1",
+	MediaBucket:      "outputs",
+	MediaKey:         "processed/loudnorm_p1.mp4",
+	Language:         "pt-BR",
+	TranscriptBucket: "outputs",
+	TranscriptKey:    "processed/transcript_p1.json",
+}
+
+func TestTranscribeAdapter_StartTranscription(t *testing.T) {
+	var started transcription.Job
+	adapter := NewTranscribeAdapter(&transcription.MockTranscriptionService{
+		StartJobFunc: func(ctx context.Context, job transcription.Job) error {
+			started = job
+			return nil
+		},
+	})
+
+	ref, err := adapter.StartTranscription(context.Background(), transcriptionRequest)
+	if err != nil {
+		t.Fatalf("StartTranscription failed: %v", err)
+	}
+
+	expected := transcription.Job{
+		Name:         "p1_abc",
+		MediaBucket:  "outputs",
+		MediaKey:     "processed/loudnorm_p1.mp4",
+		LanguageCode: "pt-BR",
+		OutputBucket: "outputs",
+		OutputKey:    "processed/transcript_p1.json",
+	}
+	if started != expected {
+		t.Errorf("Expected job %+v, got %+v", expected, started)
+	}
+	if ref != (domain.TranscriptionRef{Provider: "transcribe", JobName: "p1_abc", TranscriptKey: "processed/transcript_p1.json"}) {
+		t.Errorf("Unexpected reference %+v", ref)
+	}
+}
+
+func TestTranscribeAdapter_StartTranscription_Error(t *testing.T) {
+	adapter := NewTranscribeAdapter(&transcription.MockTranscriptionService{
+		StartJobFunc: func(ctx context.Context, job transcription.Job) error {
+			return errors.New("throttled")
+		},
+	})
+
+	if _, err := adapter.StartTranscription(context.Background(), transcriptionRequest); err == nil {
+		t.Error("Expected error when the job cannot be started")
+	}
+}
+
+func TestQueueTranscriptionAdapter_StartTranscription(t *testing.T) {
+	var queueURL, body string
+	adapter := NewQueueTranscriptionAdapter(&message.MockMessageService{
+		SendMessageFunc: func(ctx context.Context, url, messageBody string) (string, error) {
+			queueURL, body = url, messageBody
+			return "msg-1", nil
+		},
+	}, "https://sqs/transcriptions")
+
+	ref, err := adapter.StartTranscription(context.Background(), transcriptionRequest)
+	if err != nil {
+		t.Fatalf("StartTranscription failed: %v", err)
+	}
+
+	if queueURL != "https://sqs/transcriptions" {
+		t.Errorf("Expected the transcription queue, got %s", queueURL)
+	}
+	var published map[string]string
+	if err := json.Unmarshal([]byte(body), &published); err != nil {
+		t.Fatalf("Failed to decode message: %v", err)
+	}
+	if published["job_name"] != "p1_abc" || published["media_key"] != "processed/loudnorm_p1.mp4" || published["language"] != "pt-BR" || published["transcript_key"] != "processed/transcript_p1.json" {
+		t.Errorf("Unexpected message %s", body)
+	}
+	if ref != (domain.TranscriptionRef{Provider: "queue", JobName: "p1_abc", TranscriptKey: "processed/transcript_p1.json", MessageID: "msg-1"}) {
+		t.Errorf("Unexpected reference %+v", ref)
+	}
+}
+
+func TestQueueTranscriptionAdapter_StartTranscription_Error(t *testing.T) {
+	adapter := NewQueueTranscriptionAdapter(&message.MockMessageService{
+		SendMessageFunc: func(ctx context.Context, url, messageBody string) (string, error) {
+			return "", errors.New("queue unavailable")
+		},
+	}, "https://sqs/transcriptions")
+
+	if _, err := adapter.StartTranscription(context.Background(), transcriptionRequest); err == nil {
+		t.Error("Expected error when the message cannot be published")
+	}
+}
@@ -0,0 +1,112 @@
+package adapter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/ffmpeg"
+)
+
+// needsProbe reports whether the frame options depend on the streams reported by ffprobe
+func needsProbe(options domain.FrameOptions) bool {
+	return options.AutoRotate || options.ToneMap != "" || options.MaxFrames > 0 || options.Subtitles.Enabled()
+}
+
+// inputArgs turns off ffmpeg's own autorotation: orientation is applied by videoSource,
+// or deliberately left as stored when AutoRotate is disabled
+func inputArgs(videoPath string) []string {
+	return []string{"-noautorotate", "-i", videoPath}
+}
+
+// windowArgs seeks on the input side so ffmpeg only decodes the window; -copyts keeps the
+// original timestamps, which the subtitle burn-in relies on. The zero window is the whole video
+func windowArgs(window domain.TimeWindow) []string {
+	if window == (domain.TimeWindow{}) {
+		return nil
+	}
+
+	args := []string{"-ss", formatSeconds(window.Start)}
+	if window.End > 0 {
+		args = append(args, "-t", formatSeconds(window.End-window.Start))
+	}
+	return append(args, "-copyts")
+}
+
+func formatSeconds(seconds float64) string {
+	return strconv.FormatFloat(seconds, 'f', -1, 64)
+}
+
+// videoSource builds the filter chain that decodes the video tone mapped, upright and with
+// the burned-in subtitle, empty when no filter is needed. Callers append their own filters after a comma
+func videoSource(videoPath string, probe *ffmpeg.ProbeResult, options domain.FrameOptions) (string, error) {
+	if probe == nil {
+		return "", nil
+	}
+
+	var filters []string
+	if stream, ok := probe.VideoStream(); ok {
+		// Tone mapping first so rotation and subtitles work on SDR pixels
+		if options.ToneMap != "" && stream.IsHDR() {
+			filters = append(filters, toneMapFilter(options.ToneMap))
+		}
+		if options.AutoRotate {
+			if filter := rotationFilter(stream.Rotation()); filter != "" {
+				filters = append(filters, filter)
+			}
+		}
+	}
+
+	if options.Subtitles.BurnTrack != nil {
+		track := *options.Subtitles.BurnTrack
+		streams := probe.StreamsOfType(ffmpeg.CodecTypeSubtitle)
+		if track >= len(streams) {
+			return "", fmt.Errorf("subtitle track %d not found, video has %d subtitle streams", track, len(streams))
+		}
+
+		// Image-based subtitles are a second input of the overlay, after the rotation
+		if bitmapSubtitleCodecs[streams[track].CodecName] {
+			if len(filters) == 0 {
+				return fmt.Sprintf("[0:v][0:s:%d]overlay", track), nil
+			}
+			return fmt.Sprintf("[0:v]%s[upright];[upright][0:s:%d]overlay", strings.Join(filters, ","), track), nil
+		}
+		filters = append(filters, fmt.Sprintf("subtitles=filename=%s:si=%d", escapeFilterValue(videoPath), track))
+	}
+
+	if len(filters) == 0 {
+		return "", nil
+	}
+	return "[0:v]" + strings.Join(filters, ","), nil
+}
+
+// rotationFilter returns the filters that turn a video rotated by degrees (clockwise) upright
+func rotationFilter(degrees int) string {
+	switch degrees {
+	case 90:
+		return "transpose=clock"
+	case 180:
+		return "hflip,vflip"
+	case 270:
+		return "transpose=cclock"
+	default:
+		return ""
+	}
+}
+
+// toneMapFilter converts HDR (PQ/HLG) to BT.709 SDR: linearize with zscale, tone map in
+// float RGB, then convert back to BT.709 limited range
+func toneMapFilter(algorithm string) string {
+	return "zscale=t=linear:npl=100,format=gbrpf32le,zscale=p=bt709," +
+		"tonemap=tonemap=" + algorithm + ":desat=0," +
+		"zscale=t=bt709:m=bt709:r=tv,format=yuv420p"
+}
+
+// filterArgs applies filters after the video source, using a simple -vf when no source chain is needed
+func filterArgs(source, filters string) []string {
+	if source == "" {
+		return []string{"-vf", filters}
+	}
+	return []string{"-filter_complex", source + "," + filters}
+}
@@ -0,0 +1,190 @@
+package adapter
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/ffmpeg"
+)
+
+func rotatedProbe(rotation float64, subtitles ...ffmpeg.Stream) *ffmpeg.ProbeResult {
+	video := ffmpeg.Stream{
+		CodecType:    ffmpeg.CodecTypeVideo,
+		SideDataList: []ffmpeg.SideData{{SideDataType: "Display Matrix", Rotation: rotation}},
+	}
+	return &ffmpeg.ProbeResult{Streams: append([]ffmpeg.Stream{video}, subtitles...)}
+}
+
+func TestVideoSource(t *testing.T) {
+	textTrack, bitmapTrack := 0, 1
+
+	tests := []struct {
+		name     string
+		probe    *ffmpeg.ProbeResult
+		options  domain.FrameOptions
+		expected string
+	}{
+		{"no probe", nil, domain.FrameOptions{AutoRotate: true}, ""},
+		{"upright video", rotatedProbe(0), domain.FrameOptions{AutoRotate: true}, ""},
+		{"portrait phone video", rotatedProbe(-90), domain.FrameOptions{AutoRotate: true}, "[0:v]transpose=clock"},
+		{"upside down", rotatedProbe(180), domain.FrameOptions{AutoRotate: true}, "[0:v]hflip,vflip"},
+		{"counter clockwise", rotatedProbe(90), domain.FrameOptions{AutoRotate: true}, "[0:v]transpose=cclock"},
+		{"rotation disabled", rotatedProbe(-90), domain.FrameOptions{}, ""},
+		{
+			"text subtitle after rotation",
+			rotatedProbe(-90, testSubtitleStreams...),
+			domain.FrameOptions{AutoRotate: true, Subtitles: domain.SubtitleOptions{BurnTrack: &textTrack}},
+			"[0:v]transpose=clock,subtitles=filename=/tmp/video_1.mp4:si=0",
+		},
+		{
+			"bitmap subtitle",
+			rotatedProbe(0, testSubtitleStreams...),
+			domain.FrameOptions{AutoRotate: true, Subtitles: domain.SubtitleOptions{BurnTrack: &bitmapTrack}},
+			"[0:v][0:s:1]overlay",
+		},
+		{
+			"bitmap subtitle after rotation",
+			rotatedProbe(-90, testSubtitleStreams...),
+			domain.FrameOptions{AutoRotate: true, Subtitles: domain.SubtitleOptions{BurnTrack: &bitmapTrack}},
+			"[0:v]transpose=clock[upright];[upright][0:s:1]overlay",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source, err := videoSource("/tmp/video_1.mp4", tt.probe, tt.options)
+			if err != nil {
+				t.Fatalf("videoSource failed: %v", err)
+			}
+			if source != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, source)
+			}
+		})
+	}
+}
+
+func TestVideoSource_ToneMap(t *testing.T) {
+	hdr := &ffmpeg.ProbeResult{Streams: []ffmpeg.Stream{{
+		CodecType:     ffmpeg.CodecTypeVideo,
+		ColorTransfer: ffmpeg.ColorTransferPQ,
+		Tags:          map[string]string{"rotate": "90"},
+	}}}
+
+	source, err := videoSource("video.mp4", hdr, domain.FrameOptions{AutoRotate: true, ToneMap: domain.ToneMapMobius})
+	if err != nil {
+		t.Fatalf("videoSource failed: %v", err)
+	}
+	expected := "[0:v]" + toneMapFilter(domain.ToneMapMobius) + ",transpose=clock"
+	if source != expected {
+		t.Errorf("Expected tone mapping before rotation %q, got %q", expected, source)
+	}
+	if !strings.Contains(source, "tonemap=tonemap=mobius") {
+		t.Errorf("Expected requested algorithm, got %q", source)
+	}
+
+	source, _ = videoSource("video.mp4", hdr, domain.FrameOptions{})
+	if source != "" {
+		t.Errorf("Expected HDR left as is when tone mapping is off, got %q", source)
+	}
+
+	sdr := &ffmpeg.ProbeResult{Streams: []ffmpeg.Stream{{CodecType: ffmpeg.CodecTypeVideo, ColorTransfer: "bt709"}}}
+	source, _ = videoSource("video.mp4", sdr, domain.FrameOptions{ToneMap: domain.ToneMapHable})
+	if source != "" {
+		t.Errorf("Expected no tone mapping for SDR video, got %q", source)
+	}
+}
+
+func TestNeedsProbe(t *testing.T) {
+	if needsProbe(domain.FrameOptions{}) {
+		t.Error("Expected no probe without options")
+	}
+	if !needsProbe(domain.FrameOptions{ToneMap: domain.ToneMapHable}) {
+		t.Error("Expected probe for tone mapping")
+	}
+}
+
+func TestVideoSource_MissingSubtitleTrack(t *testing.T) {
+	track := 2
+	options := domain.FrameOptions{Subtitles: domain.SubtitleOptions{BurnTrack: &track}}
+
+	if _, err := videoSource("video.mp4", rotatedProbe(0, testSubtitleStreams...), options); err == nil {
+		t.Error("Expected error for missing subtitle track")
+	}
+}
+
+func TestFilterArgs(t *testing.T) {
+	if args := filterArgs("", "fps=1"); strings.Join(args, " ") != "-vf fps=1" {
+		t.Errorf("Expected -vf without source, got %v", args)
+	}
+	if args := filterArgs("[0:v]transpose=clock", "fps=1"); strings.Join(args, " ") != "-filter_complex [0:v]transpose=clock,fps=1" {
+		t.Errorf("Expected -filter_complex with source, got %v", args)
+	}
+}
+
+func TestFFmpegVideoProcessor_ProcessVideo_AutoRotate(t *testing.T) {
+	ffmpegPath, argsFile := writeRecordingFFmpeg(t)
+	probeFile := filepath.Join(t.TempDir(), "probe.json")
+	os.WriteFile(probeFile, []byte(`{"streams":[{"index":0,"codec_type":"video","tags":{"rotate":"90"}}]}`), 0644)
+	ffprobePath := writeScript(t, "ffprobe", "cat "+probeFile+"\n")
+
+	processor := NewFFmpegVideoProcessorWithBinaries(t.TempDir(), ffmpegPath, ffprobePath)
+	if _, err := processor.ProcessVideo(context.Background(), domain.ProcessingRequest{JobID: "job-1", VideoPath: "video.mp4", Options: domain.FrameOptions{AutoRotate: true}}); err != nil {
+		t.Fatalf("ProcessVideo failed: %v", err)
+	}
+
+	args, _ := os.ReadFile(argsFile)
+	if !strings.Contains(string(args), "-noautorotate -i video.mp4 -filter_complex [0:v]transpose=clock,fps=1") {
+		t.Errorf("Expected transpose before fps, got %s", args)
+	}
+}
+
+func TestWindowArgs(t *testing.T) {
+	if args := windowArgs(domain.TimeWindow{}); args != nil {
+		t.Errorf("Expected no args for the whole video, got %v", args)
+	}
+	if args := strings.Join(windowArgs(domain.TimeWindow{Start: 12.5, End: 20}), " "); args != "-ss 12.5 -t 7.5 -copyts" {
+		t.Errorf("Unexpected window args: %s", args)
+	}
+	if args := strings.Join(windowArgs(domain.TimeWindow{Start: 30}), " "); args != "-ss 30 -copyts" {
+		t.Errorf("Unexpected open-ended window args: %s", args)
+	}
+}
+
+func TestFFmpegVideoProcessor_ProcessVideo_Windows(t *testing.T) {
+	ffmpegPath, argsFile := writeRecordingFFmpeg(t)
+	processor := NewFFmpegVideoProcessorWithBinaries(t.TempDir(), ffmpegPath, "")
+
+	options := domain.FrameOptions{Windows: []domain.TimeWindow{{Start: 10, End: 20}, {Start: 60}}}
+	result, err := processor.ProcessVideo(context.Background(), domain.ProcessingRequest{JobID: "job-1", VideoPath: "video.mp4", Options: options})
+	if err != nil {
+		t.Fatalf("ProcessVideo failed: %v", err)
+	}
+
+	if result.FrameCount != 2 {
+		t.Errorf("Expected one frame per window run, got %d", result.FrameCount)
+	}
+
+	runs := strings.Split(strings.TrimSpace(readFile(t, argsFile)), "\n")
+	if len(runs) != 2 {
+		t.Fatalf("Expected one ffmpeg run per window, got %v", runs)
+	}
+	if !strings.HasPrefix(runs[0], "-ss 10 -t 10 -copyts -noautorotate -i video.mp4") || !strings.Contains(runs[0], "-start_number 1") {
+		t.Errorf("Unexpected first run: %s", runs[0])
+	}
+	if !strings.HasPrefix(runs[1], "-ss 60 -copyts") || !strings.Contains(runs[1], "-start_number 2") {
+		t.Errorf("Unexpected second run: %s", runs[1])
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", path, err)
+	}
+	return string(data)
+}
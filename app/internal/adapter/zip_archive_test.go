@@ -0,0 +1,310 @@
+package adapter
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+)
+
+// manyEntries repeats one small file past the classic zip entry limit
+func manyEntries(t *testing.T) []string {
+	t.Helper()
+	frame := filepath.Join(t.TempDir(), "frame.png")
+	if err := os.WriteFile(frame, []byte("frame"), 0644); err != nil {
+		t.Fatalf("Failed to write frame: %v", err)
+	}
+
+	files := make([]string, domain.ZipMaxEntries+2)
+	for i := range files {
+		files[i] = frame
+	}
+	return files
+}
+
+func TestCreateZipFile_Zip64Entries(t *testing.T) {
+	if testing.Short() {
+		t.Skip("writes more than 65535 zip entries")
+	}
+
+	processor := NewFFmpegVideoProcessorWithBinaries(t.TempDir(), "", "").(*FFmpegVideoProcessor)
+	files := manyEntries(t)
+	zipPath := filepath.Join(t.TempDir(), "frames.zip")
+
+	if err := processor.createZipFile(files, zipPath, domain.ArchiveOptions{}); err != nil {
+		t.Fatalf("createZipFile failed: %v", err)
+	}
+
+	reader, err := zip.OpenReader(zipPath)
+	if err != nil {
+		t.Fatalf("Failed to open zip: %v", err)
+	}
+	defer reader.Close()
+
+	if len(reader.File) != len(files) {
+		t.Errorf("Expected %d entries read back through the Zip64 directory, got %d", len(files), len(reader.File))
+	}
+}
+
+func TestCreateZipFile_WithoutZip64(t *testing.T) {
+	processor := NewFFmpegVideoProcessorWithBinaries(t.TempDir(), "", "", WithoutZip64()).(*FFmpegVideoProcessor)
+	zipPath := filepath.Join(t.TempDir(), "frames.zip")
+
+	err := processor.createZipFile(manyEntries(t), zipPath, domain.ArchiveOptions{})
+	if !errors.Is(err, domain.ErrArchiveLimit) {
+		t.Fatalf("Expected ErrArchiveLimit, got %v", err)
+	}
+	if _, err := os.Stat(zipPath); !os.IsNotExist(err) {
+		t.Error("Expected no zip to be written when the limits are exceeded")
+	}
+}
+
+func TestCreateZipParts_RemovesFailedZip(t *testing.T) {
+	processor := NewFFmpegVideoProcessorWithBinaries(t.TempDir(), "", "").(*FFmpegVideoProcessor)
+	dir := t.TempDir()
+	frame := filepath.Join(dir, "frame_0001.png")
+	if err := os.WriteFile(frame, []byte("frame"), 0644); err != nil {
+		t.Fatalf("Failed to write frame: %v", err)
+	}
+	zipPath := filepath.Join(dir, "frames_job-1.zip")
+
+	// The second frame is gone by the time it is zipped
+	parts, err := processor.createZipParts("job-1", []string{frame, filepath.Join(dir, "frame_0002.png")}, zipPath, domain.ArchiveOptions{})
+	if err == nil || parts != nil {
+		t.Fatalf("Expected an error and no parts, got %v and %v", err, parts)
+	}
+	if _, err := os.Stat(zipPath); !os.IsNotExist(err) {
+		t.Error("Expected the partial zip removed")
+	}
+}
+
+func TestCheckZipLimits(t *testing.T) {
+	frame := filepath.Join(t.TempDir(), "frame.png")
+	if err := os.WriteFile(frame, []byte("frame"), 0644); err != nil {
+		t.Fatalf("Failed to write frame: %v", err)
+	}
+
+	if err := checkZipLimits([]string{frame}); err != nil {
+		t.Errorf("Expected small input to fit, got %v", err)
+	}
+
+	// A sparse file reports a size above 4GB without using the disk
+	large := filepath.Join(t.TempDir(), "large.bin")
+	file, err := os.Create(large)
+	if err != nil {
+		t.Fatalf("Failed to create file: %v", err)
+	}
+	if err := file.Truncate(domain.ZipMaxSize + 1); err != nil {
+		file.Close()
+		t.Skipf("sparse files not supported: %v", err)
+	}
+	file.Close()
+
+	if err := checkZipLimits([]string{large}); !errors.Is(err, domain.ErrArchiveLimit) {
+		t.Errorf("Expected ErrArchiveLimit for a file above 4GB, got %v", err)
+	}
+}
+
+func TestCreateZipParts(t *testing.T) {
+	dir := t.TempDir()
+	var files []string
+	for i := 0; i < 5; i++ {
+		file := filepath.Join(dir, fmt.Sprintf("frame_%04d.png", i+1))
+		if err := os.WriteFile(file, make([]byte, 100), 0644); err != nil {
+			t.Fatalf("Failed to write frame: %v", err)
+		}
+		files = append(files, file)
+	}
+
+	processor := NewFFmpegVideoProcessorWithBinaries(t.TempDir(), "", "", WithZipPartSize(250)).(*FFmpegVideoProcessor)
+	parts, err := processor.createZipParts("job-1", files, filepath.Join(dir, "frames_job-1.zip"), domain.ArchiveOptions{})
+	if err != nil {
+		t.Fatalf("createZipParts failed: %v", err)
+	}
+
+	if len(parts) != 3 || filepath.Base(parts[0]) != "frames_job-1.part1.zip" || filepath.Base(parts[2]) != "frames_job-1.part3.zip" {
+		t.Fatalf("Expected 3 numbered parts, got %v", parts)
+	}
+
+	entries := 0
+	for _, part := range parts {
+		reader, err := zip.OpenReader(part)
+		if err != nil {
+			t.Fatalf("Failed to open %s: %v", part, err)
+		}
+		entries += len(reader.File)
+		reader.Close()
+	}
+	if entries != len(files) {
+		t.Errorf("Expected every file in exactly one part, got %d entries", entries)
+	}
+}
+
+func TestCreateZipParts_SinglePart(t *testing.T) {
+	dir := t.TempDir()
+	frame := filepath.Join(dir, "frame_0001.png")
+	if err := os.WriteFile(frame, []byte("frame"), 0644); err != nil {
+		t.Fatalf("Failed to write frame: %v", err)
+	}
+
+	processor := NewFFmpegVideoProcessorWithBinaries(t.TempDir(), "", "", WithZipPartSize(1<<20)).(*FFmpegVideoProcessor)
+	zipPath := filepath.Join(dir, "frames_job-1.zip")
+	parts, err := processor.createZipParts("job-1", []string{frame}, zipPath, domain.ArchiveOptions{})
+	if err != nil {
+		t.Fatalf("createZipParts failed: %v", err)
+	}
+	if len(parts) != 1 || parts[0] != zipPath {
+		t.Errorf("Expected the unsplit zip name below the part size, got %v", parts)
+	}
+}
+
+func TestCreateZipParts_ArchiveFiles(t *testing.T) {
+	dir := t.TempDir()
+	frame := filepath.Join(dir, "frame_0001.png")
+	if err := os.WriteFile(frame, make([]byte, 100), 0644); err != nil {
+		t.Fatalf("Failed to write frame: %v", err)
+	}
+
+	memoryDir, diskDir := filepath.Join(t.TempDir(), "memory"), filepath.Join(t.TempDir(), "disk")
+	workDir := NewMemoryWorkDir(memoryDir, diskDir, 1000)
+	workDir.free = func(dir string) (int64, error) { return 1 << 20, nil }
+	processor := NewFFmpegVideoProcessorWithBinaries(memoryDir, "", "", WithArchiveFiles(workDir.CreateArtifact)).(*FFmpegVideoProcessor)
+
+	parts, err := processor.createZipParts("job-1", []string{frame}, filepath.Join(memoryDir, "frames_job-1.zip"), domain.ArchiveOptions{})
+	if err != nil {
+		t.Fatalf("createZipParts failed: %v", err)
+	}
+	info, err := os.Stat(parts[0])
+	if err != nil || filepath.Dir(parts[0]) != memoryDir {
+		t.Fatalf("Expected the zip in memory, got %v (%v)", parts, err)
+	}
+	if reserved := workDir.Reserved(); reserved != info.Size() {
+		t.Errorf("Expected the zip reserved at its size %d, got %d", info.Size(), reserved)
+	}
+
+	// Without room the zip is written to disk, where the processor returns it from
+	workDir.Release("job-1")
+	workDir.free = func(dir string) (int64, error) { return 0, nil }
+	parts, err = processor.createZipParts("job-1", []string{frame}, filepath.Join(memoryDir, "frames_job-1.zip"), domain.ArchiveOptions{})
+	if err != nil {
+		t.Fatalf("createZipParts failed: %v", err)
+	}
+	if filepath.Dir(parts[0]) != diskDir {
+		t.Errorf("Expected the zip on disk, got %v", parts)
+	}
+	if reader, err := zip.OpenReader(parts[0]); err != nil {
+		t.Errorf("Expected a readable zip: %v", err)
+	} else {
+		reader.Close()
+	}
+}
+
+func TestCreateZipParts_TarGz(t *testing.T) {
+	dir := t.TempDir()
+	var files []string
+	for i := 0; i < 3; i++ {
+		file := filepath.Join(dir, fmt.Sprintf("frame_%04d.png", i+1))
+		if err := os.WriteFile(file, []byte(fmt.Sprintf("frame %d", i+1)), 0644); err != nil {
+			t.Fatalf("Failed to write frame: %v", err)
+		}
+		files = append(files, file)
+	}
+
+	processor := NewFFmpegVideoProcessorWithBinaries(t.TempDir(), "", "", WithZipPartSize(16)).(*FFmpegVideoProcessor)
+	archive := domain.ArchiveOptions{Encoding: domain.ArchiveEncodingTarGz, Level: 9}
+	parts, err := processor.createZipParts("job-1", files, filepath.Join(dir, "frames_job-1"+archive.Extension()), archive)
+	if err != nil {
+		t.Fatalf("createZipParts failed: %v", err)
+	}
+	if len(parts) != 2 || filepath.Base(parts[0]) != "frames_job-1.part1.tar.gz" || filepath.Base(parts[1]) != "frames_job-1.part2.tar.gz" {
+		t.Fatalf("Expected 2 numbered tarballs, got %v", parts)
+	}
+
+	var names []string
+	for _, part := range parts {
+		file, err := os.Open(part)
+		if err != nil {
+			t.Fatalf("Failed to open %s: %v", part, err)
+		}
+		gzipReader, err := gzip.NewReader(file)
+		if err != nil {
+			t.Fatalf("Expected %s gzipped: %v", part, err)
+		}
+		tarReader := tar.NewReader(gzipReader)
+		for {
+			header, err := tarReader.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("Failed to read %s: %v", part, err)
+			}
+			data, _ := io.ReadAll(tarReader)
+			if want := "frame " + header.Name[len("frame_000"):len("frame_0001")]; string(data) != want {
+				t.Errorf("Expected %q in %s, got %q", want, header.Name, data)
+			}
+			names = append(names, header.Name)
+		}
+		file.Close()
+	}
+	if len(names) != len(files) || names[0] != "frame_0001.png" || names[2] != "frame_0003.png" {
+		t.Errorf("Expected every frame once, in order, got %v", names)
+	}
+}
+
+func TestCreateZipFile_Methods(t *testing.T) {
+	dir := t.TempDir()
+	frame := filepath.Join(dir, "frame_0001.png")
+	vtt := filepath.Join(dir, "thumbnails.vtt")
+	for _, file := range []string{frame, vtt} {
+		if err := os.WriteFile(file, bytes.Repeat([]byte("data "), 100), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", file, err)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		archive   domain.ArchiveOptions
+		wantFrame uint16
+		wantVTT   uint16
+	}{
+		{"auto", domain.ArchiveOptions{Method: domain.ZipMethodAuto}, zip.Store, zip.Deflate},
+		{"store", domain.ArchiveOptions{Method: domain.ZipMethodStore}, zip.Store, zip.Store},
+		{"deflate level 1", domain.ArchiveOptions{Method: domain.ZipMethodDeflate, Level: 1}, zip.Deflate, zip.Deflate},
+	}
+	processor := NewFFmpegVideoProcessorWithBinaries(t.TempDir(), "", "").(*FFmpegVideoProcessor)
+	for _, tt := range tests {
+		zipPath := filepath.Join(t.TempDir(), "frames.zip")
+		if err := processor.createZipFile([]string{frame, vtt}, zipPath, tt.archive); err != nil {
+			t.Fatalf("%s: createZipFile failed: %v", tt.name, err)
+		}
+
+		reader, err := zip.OpenReader(zipPath)
+		if err != nil {
+			t.Fatalf("%s: failed to open zip: %v", tt.name, err)
+		}
+		if reader.File[0].Method != tt.wantFrame || reader.File[1].Method != tt.wantVTT {
+			t.Errorf("%s: expected methods %d/%d, got %d/%d", tt.name, tt.wantFrame, tt.wantVTT, reader.File[0].Method, reader.File[1].Method)
+		}
+		for _, file := range reader.File {
+			content, err := file.Open()
+			if err != nil {
+				t.Fatalf("%s: failed to read %s: %v", tt.name, file.Name, err)
+			}
+			data, _ := io.ReadAll(content)
+			content.Close()
+			if len(data) != 500 {
+				t.Errorf("%s: expected %s to round-trip, got %d bytes", tt.name, file.Name, len(data))
+			}
+		}
+		reader.Close()
+	}
+}
@@ -0,0 +1,110 @@
+package adapter
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/port"
+)
+
+// ZipMerger combines the zips of the videos of a batch into a single zip with a folder per
+// video. Entries are copied still compressed, so merging costs no recompression
+type ZipMerger struct {
+	tempDir string
+	zip64   bool
+}
+
+// NewZipMerger writes the merged zips to tempDir; without zip64, merges past the classic zip
+// limits fail with domain.ErrArchiveLimit, as the processor's own zips do
+func NewZipMerger(tempDir string, zip64 bool) port.ArchiveMergerPort {
+	return &ZipMerger{tempDir: tempDir, zip64: zip64}
+}
+
+func (m *ZipMerger) MergeArchives(ctx context.Context, jobID string, groups []domain.ArchiveGroup) (string, error) {
+	jobID = sanitizeJobID(jobID)
+	if jobID == "" {
+		return "", fmt.Errorf("job id is required")
+	}
+	if err := os.MkdirAll(m.tempDir, 0777); err != nil {
+		return "", fmt.Errorf("failed to create temp directory: %w", err)
+	}
+
+	zipPath := filepath.Join(m.tempDir, "batch_"+jobID+".zip")
+	if err := m.merge(ctx, zipPath, groups); err != nil {
+		os.Remove(zipPath)
+		return "", err
+	}
+	return zipPath, nil
+}
+
+func (m *ZipMerger) merge(ctx context.Context, zipPath string, groups []domain.ArchiveGroup) error {
+	out, err := os.Create(zipPath)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	writer := zip.NewWriter(out)
+	entries := 0
+	for _, group := range groups {
+		for _, source := range group.Zips {
+			if err := ctx.Err(); err != nil {
+				writer.Close()
+				return err
+			}
+			copied, err := copyZipEntries(writer, source, group.Dir)
+			if err != nil {
+				writer.Close()
+				return fmt.Errorf("failed to merge %s: %w", filepath.Base(source), err)
+			}
+			entries += copied
+		}
+	}
+	if !m.zip64 && entries > domain.ZipMaxEntries {
+		writer.Close()
+		return fmt.Errorf("%w: %d files, above the %d entries allowed without Zip64", domain.ErrArchiveLimit, entries, domain.ZipMaxEntries)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("failed to finish zip: %w", err)
+	}
+
+	if !m.zip64 {
+		if info, err := out.Stat(); err == nil && info.Size() > domain.ZipMaxSize {
+			return fmt.Errorf("%w: zip is %d bytes, above the %d bytes allowed without Zip64", domain.ErrArchiveLimit, info.Size(), int64(domain.ZipMaxSize))
+		}
+	}
+	return out.Close()
+}
+
+// copyZipEntries copies the raw entries of the zip at source under dir, returning how many
+func copyZipEntries(writer *zip.Writer, source, dir string) (int, error) {
+	reader, err := zip.OpenReader(source)
+	if err != nil {
+		return 0, err
+	}
+	defer reader.Close()
+
+	for _, file := range reader.File {
+		header := file.FileHeader
+		header.Name = path.Join(dir, file.Name)
+
+		raw, err := file.OpenRaw()
+		if err != nil {
+			return 0, err
+		}
+		entry, err := writer.CreateRaw(&header)
+		if err != nil {
+			return 0, err
+		}
+		if _, err := io.Copy(entry, raw); err != nil {
+			return 0, err
+		}
+	}
+	return len(reader.File), nil
+}
@@ -0,0 +1,88 @@
+package adapter
+
+import (
+	"archive/zip"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+)
+
+func writeTestZip(t *testing.T, zipPath string, files map[string]string) {
+	t.Helper()
+	out, err := os.Create(zipPath)
+	if err != nil {
+		t.Fatalf("Failed to create zip: %v", err)
+	}
+	defer out.Close()
+
+	writer := zip.NewWriter(out)
+	for name, content := range files {
+		entry, err := writer.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate})
+		if err != nil {
+			t.Fatalf("Failed to add %s: %v", name, err)
+		}
+		entry.Write([]byte(content))
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Failed to finish zip: %v", err)
+	}
+}
+
+func TestZipMerger_MergeArchives(t *testing.T) {
+	dir := t.TempDir()
+	first, second := filepath.Join(dir, "first.zip"), filepath.Join(dir, "second.zip")
+	writeTestZip(t, first, map[string]string{"frame_0001.png": "first frame"})
+	writeTestZip(t, second, map[string]string{"frame_0001.png": "second frame"})
+
+	merger := NewZipMerger(filepath.Join(dir, "out"), true)
+	zipPath, err := merger.MergeArchives(context.Background(), "123_ab", []domain.ArchiveGroup{
+		{Dir: "01_intro", Zips: []string{first}},
+		{Dir: "02_outro", Zips: []string{second}},
+	})
+	if err != nil {
+		t.Fatalf("MergeArchives failed: %v", err)
+	}
+
+	reader, err := zip.OpenReader(zipPath)
+	if err != nil {
+		t.Fatalf("Failed to open merged zip: %v", err)
+	}
+	defer reader.Close()
+
+	contents := make(map[string]string)
+	for _, file := range reader.File {
+		entry, err := file.Open()
+		if err != nil {
+			t.Fatalf("Failed to read %s: %v", file.Name, err)
+		}
+		data, _ := io.ReadAll(entry)
+		entry.Close()
+		contents[file.Name] = string(data)
+	}
+	if contents["01_intro/frame_0001.png"] != "first frame" || contents["02_outro/frame_0001.png"] != "second frame" || len(contents) != 2 {
+		t.Errorf("Expected a folder per video, got %v", contents)
+	}
+}
+
+func TestZipMerger_MergeArchives_Errors(t *testing.T) {
+	dir := t.TempDir()
+	merger := NewZipMerger(dir, true)
+
+	_, err := merger.MergeArchives(context.Background(), "123_ab", []domain.ArchiveGroup{{Dir: "01_intro", Zips: []string{filepath.Join(dir, "missing.zip")}}})
+	if err == nil || !strings.Contains(err.Error(), "missing.zip") {
+		t.Errorf("Expected an error naming the missing zip, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "batch_123_ab.zip")); !errors.Is(err, os.ErrNotExist) {
+		t.Error("Expected the partial zip to be removed")
+	}
+
+	if _, err := merger.MergeArchives(context.Background(), "", nil); err == nil {
+		t.Error("Expected error for empty job id")
+	}
+}
@@ -0,0 +1,225 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/adapter"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/storage"
+)
+
+// Transports of the queues selected by MESSAGE_TRANSPORT
+const (
+	TransportSQS        = "sqs"
+	TransportPubSub     = "pubsub"
+	TransportServiceBus = "servicebus"
+	// TransportMemory keeps the queues in memory, for running without cloud services
+	TransportMemory = "memory"
+)
+
+// RemoteBackendMediaConvert offloads the frame extraction to AWS Elemental MediaConvert
+const RemoteBackendMediaConvert = "mediaconvert"
+
+// DefaultTempDir is where the worker processes its videos
+const DefaultTempDir = "/tmp/video-processor"
+
+// Config is what the Container builds the adapters from. ConfigFromEnv reads it from the
+// worker variables; an entrypoint may then override fields, e.g. its own TempDir
+type Config struct {
+	// Region is the AWS region; empty uses the SDK default chain
+	Region string
+
+	// S3 points the S3 client at a compatible store (MinIO)
+	S3 storage.S3Options
+
+	// StorageDir, when set, keeps the buckets as folders under it instead of S3
+	StorageDir string
+
+	// OutputRoles are the roles assumed for the buckets the tenants own, read from the
+	// output_role of TENANT_CONFIG, in the jobs of those tenants only; the other buckets and
+	// tenants use the worker's credentials
+	OutputRoles map[string]domain.OutputRoute
+
+	MessageTransport     string
+	PubSubProject        string
+	ServiceBusNamespace  string
+	ServiceBusConnection string
+
+	// TempDir is where the videos are processed
+	TempDir     string
+	FFmpegPath  string
+	FFprobePath string
+
+	// Decoders are the video decoders of the installed ffmpeg; nil does not check the codec
+	Decoders map[string]bool
+
+	Zip64Disabled  bool
+	ZipPartSize    int64
+	ZipCompression domain.ArchiveOptions
+	FrameName      domain.FrameNameTemplate
+	OCREngine      string
+	TesseractPath  string
+
+	// MemoryDir, when set, is a memory-backed directory the videos up to MemoryMaxBytes are
+	// processed in, their frames reserving MemoryHeadroom times the video size
+	MemoryDir      string
+	MemoryMaxBytes int64
+	MemoryHeadroom int64
+
+	// RemoteBackend, when set, offloads the videos RemotePolicy selects, staged under
+	// RemotePrefix in RemoteBucket
+	RemoteBackend     string
+	RemoteBucket      string
+	RemotePrefix      string
+	MediaConvertRole  string
+	MediaConvertQueue string
+	RemotePolicy      adapter.RoutingPolicy
+
+	// Worker is what only the worker reads, validated by ValidateWorker
+	Worker WorkerConfig
+}
+
+// ConfigFromEnv reads the Config from the environment, failing on values that do not parse
+func ConfigFromEnv() (Config, error) {
+	config := Config{
+		Region: os.Getenv("AWS_REGION"),
+		S3: storage.S3Options{
+			Endpoint:           os.Getenv("S3_ENDPOINT"),
+			UsePathStyle:       os.Getenv("S3_USE_PATH_STYLE") == "true",
+			CABundle:           os.Getenv("S3_CA_BUNDLE"),
+			InsecureSkipVerify: os.Getenv("S3_INSECURE_SKIP_VERIFY") == "true",
+		},
+		MessageTransport:     getEnv("MESSAGE_TRANSPORT", TransportSQS),
+		PubSubProject:        os.Getenv("PUBSUB_PROJECT"),
+		ServiceBusNamespace:  os.Getenv("SERVICEBUS_NAMESPACE"),
+		ServiceBusConnection: os.Getenv("SERVICEBUS_CONNECTION_STRING"),
+		TempDir:              DefaultTempDir,
+		FFmpegPath:           os.Getenv("FFMPEG_PATH"),
+		FFprobePath:          os.Getenv("FFPROBE_PATH"),
+		Zip64Disabled:        os.Getenv("ZIP64_ENABLED") == "false",
+		FrameName:            domain.FrameNameTemplate(os.Getenv("FRAME_NAME_TEMPLATE")),
+		OCREngine:            os.Getenv("OCR_ENGINE"),
+		TesseractPath:        os.Getenv("TESSERACT_PATH"),
+		MemoryDir:            os.Getenv("MEMORY_DIR"),
+		RemoteBackend:        os.Getenv("REMOTE_PROCESSING"),
+		RemoteBucket:         os.Getenv("REMOTE_STAGING_BUCKET"),
+		RemotePrefix:         getEnv("REMOTE_STAGING_PREFIX", "remote-processing/"),
+		MediaConvertRole:     os.Getenv("MEDIACONVERT_ROLE_ARN"),
+		MediaConvertQueue:    os.Getenv("MEDIACONVERT_QUEUE"),
+	}
+
+	var err error
+	config.ZipPartSize, err = strconv.ParseInt(getEnv("ZIP_PART_MAX_BYTES", "0"), 10, 64)
+	if err != nil {
+		return config, fmt.Errorf("invalid ZIP_PART_MAX_BYTES: %w", err)
+	}
+	config.ZipCompression, err = zipCompression(os.Getenv("ZIP_METHOD"), os.Getenv("ZIP_LEVEL"))
+	if err != nil {
+		return config, fmt.Errorf("ZIP_METHOD/ZIP_LEVEL: %w", err)
+	}
+	if config.MemoryDir != "" {
+		config.MemoryMaxBytes, err = strconv.ParseInt(getEnv("MEMORY_MAX_VIDEO_BYTES", "67108864"), 10, 64)
+		if err != nil || config.MemoryMaxBytes <= 0 {
+			return config, fmt.Errorf("invalid MEMORY_MAX_VIDEO_BYTES %q", os.Getenv("MEMORY_MAX_VIDEO_BYTES"))
+		}
+		config.MemoryHeadroom, err = strconv.ParseInt(getEnv("MEMORY_HEADROOM", "10"), 10, 64)
+		if err != nil || config.MemoryHeadroom <= 0 {
+			return config, fmt.Errorf("invalid MEMORY_HEADROOM %q", os.Getenv("MEMORY_HEADROOM"))
+		}
+	}
+	tenants, err := domain.ParseTenantRegistry([]byte(os.Getenv("TENANT_CONFIG")))
+	if err != nil {
+		return config, fmt.Errorf("TENANT_CONFIG: %w", err)
+	}
+	config.OutputRoles, err = tenants.OutputRoles()
+	if err != nil {
+		return config, fmt.Errorf("TENANT_CONFIG: %w", err)
+	}
+	if config.RemoteBackend != "" {
+		config.RemotePolicy, err = remoteRoutingPolicy()
+		if err != nil {
+			return config, fmt.Errorf("invalid remote processing routing: %w", err)
+		}
+	}
+	config.Worker, err = workerConfigFromEnv(tenants)
+	return config, err
+}
+
+// Validate checks the combinations ConfigFromEnv cannot: unknown transports, backends and
+// engines, and the settings each of them requires
+func (c Config) Validate() error {
+	switch c.MessageTransport {
+	case TransportSQS, TransportPubSub, TransportServiceBus, TransportMemory:
+	default:
+		return fmt.Errorf("MESSAGE_TRANSPORT: unsupported transport %s", c.MessageTransport)
+	}
+	if c.MessageTransport == TransportPubSub && c.PubSubProject == "" {
+		return fmt.Errorf("PUBSUB_PROJECT is required when MESSAGE_TRANSPORT=pubsub")
+	}
+	if c.MessageTransport == TransportServiceBus && c.ServiceBusNamespace == "" && c.ServiceBusConnection == "" {
+		return fmt.Errorf("SERVICEBUS_NAMESPACE or SERVICEBUS_CONNECTION_STRING is required when MESSAGE_TRANSPORT=servicebus")
+	}
+	if err := c.ZipCompression.Validate(); err != nil {
+		return fmt.Errorf("ZIP_METHOD/ZIP_LEVEL: %w", err)
+	}
+	if err := c.FrameName.Validate(); err != nil {
+		return fmt.Errorf("FRAME_NAME_TEMPLATE: %w", err)
+	}
+	if c.RemoteBackend != "" && c.RemoteBackend != RemoteBackendMediaConvert {
+		return fmt.Errorf("REMOTE_PROCESSING: unsupported backend %s", c.RemoteBackend)
+	}
+	if c.RemoteBackend != "" && (c.RemoteBucket == "" || c.MediaConvertRole == "") {
+		return fmt.Errorf("REMOTE_STAGING_BUCKET and MEDIACONVERT_ROLE_ARN are required when REMOTE_PROCESSING=mediaconvert")
+	}
+	if c.OCREngine != "" && c.OCREngine != "tesseract" {
+		return fmt.Errorf("OCR_ENGINE: unsupported engine %s", c.OCREngine)
+	}
+	return nil
+}
+
+// zipCompression reads the zip method and level; auto (stored images, deflated text) is the default
+func zipCompression(method, level string) (domain.ArchiveOptions, error) {
+	options := domain.ArchiveOptions{Method: domain.ZipMethodAuto}
+	if method != "" {
+		options.Method = method
+	}
+	if level != "" {
+		parsed, err := strconv.Atoi(level)
+		if err != nil {
+			return options, err
+		}
+		options.Level = parsed
+	}
+	return options, options.Validate()
+}
+
+// remoteRoutingPolicy reads when videos are offloaded: from REMOTE_MIN_VIDEO_BYTES (1 GiB by
+// default), from REMOTE_MIN_DURATION and while REMOTE_MAX_LOCAL_JOBS run locally; zero
+// disables a criterion
+func remoteRoutingPolicy() (adapter.RoutingPolicy, error) {
+	var policy adapter.RoutingPolicy
+	minBytes, err := strconv.ParseInt(getEnv("REMOTE_MIN_VIDEO_BYTES", "1073741824"), 10, 64)
+	if err != nil || minBytes < 0 {
+		return policy, fmt.Errorf("REMOTE_MIN_VIDEO_BYTES: invalid size %q", os.Getenv("REMOTE_MIN_VIDEO_BYTES"))
+	}
+	minDuration, err := time.ParseDuration(getEnv("REMOTE_MIN_DURATION", "0s"))
+	if err != nil || minDuration < 0 {
+		return policy, fmt.Errorf("REMOTE_MIN_DURATION: invalid duration %q", os.Getenv("REMOTE_MIN_DURATION"))
+	}
+	maxLocalJobs, err := strconv.Atoi(getEnv("REMOTE_MAX_LOCAL_JOBS", "0"))
+	if err != nil || maxLocalJobs < 0 {
+		return policy, fmt.Errorf("REMOTE_MAX_LOCAL_JOBS: invalid count %q", os.Getenv("REMOTE_MAX_LOCAL_JOBS"))
+	}
+	policy.MinBytes, policy.MinSeconds, policy.MaxLocalJobs = minBytes, minDuration.Seconds(), maxLocalJobs
+	return policy, nil
+}
+
+func getEnv(key, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
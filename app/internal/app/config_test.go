@@ -0,0 +1,97 @@
+package app
+
+import (
+	"testing"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+)
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("MESSAGE_TRANSPORT", "")
+	t.Setenv("ZIP_METHOD", "deflate")
+	t.Setenv("ZIP_LEVEL", "6")
+	t.Setenv("ZIP64_ENABLED", "false")
+	t.Setenv("MEMORY_DIR", "/dev/shm/videos")
+	t.Setenv("MEMORY_MAX_VIDEO_BYTES", "")
+	t.Setenv("MEMORY_HEADROOM", "")
+	t.Setenv("REMOTE_PROCESSING", "mediaconvert")
+	t.Setenv("REMOTE_MIN_DURATION", "10m")
+	t.Setenv("TENANT_CONFIG", `{"acme":{"allowed_outputs":["acme-frames"],"output_role":{"role_arn":"arn:aws:iam::123:role/frames-writer"}}}`)
+
+	config, err := ConfigFromEnv()
+	if err != nil {
+		t.Fatalf("ConfigFromEnv failed: %v", err)
+	}
+	if config.MessageTransport != TransportSQS {
+		t.Errorf("Expected transport %s by default, got %s", TransportSQS, config.MessageTransport)
+	}
+	if config.TempDir != DefaultTempDir {
+		t.Errorf("Expected temp dir %s, got %s", DefaultTempDir, config.TempDir)
+	}
+	if config.ZipCompression != (domain.ArchiveOptions{Method: "deflate", Level: 6}) || !config.Zip64Disabled {
+		t.Errorf("Unexpected zip settings %+v (zip64 disabled: %v)", config.ZipCompression, config.Zip64Disabled)
+	}
+	if config.MemoryMaxBytes != 67108864 || config.MemoryHeadroom != 10 {
+		t.Errorf("Expected the default memory limits, got %d and headroom %d", config.MemoryMaxBytes, config.MemoryHeadroom)
+	}
+	if config.RemotePolicy.MinBytes != 1073741824 || config.RemotePolicy.MinSeconds != 600 {
+		t.Errorf("Unexpected routing policy %+v", config.RemotePolicy)
+	}
+	if config.RemotePrefix != "remote-processing/" {
+		t.Errorf("Expected the default staging prefix, got %s", config.RemotePrefix)
+	}
+	if role := config.OutputRoles["acme-frames"]; len(config.OutputRoles) != 1 || role.RoleARN != "arn:aws:iam::123:role/frames-writer" {
+		t.Errorf("Expected the tenant output role, got %v", config.OutputRoles)
+	}
+}
+
+func TestConfigFromEnv_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"ZIP_PART_MAX_BYTES":     "big",
+		"ZIP_LEVEL":              "high",
+		"MEMORY_MAX_VIDEO_BYTES": "0",
+		"MEMORY_HEADROOM":        "none",
+		"REMOTE_MIN_DURATION":    "soon",
+		"TENANT_CONFIG":          `{"acme":{"output_role":{"role_arn":"arn:aws:iam::123:role/frames-writer"}}}`,
+	}
+	for env, value := range tests {
+		t.Run(env, func(t *testing.T) {
+			t.Setenv("MEMORY_DIR", "/dev/shm/videos")
+			t.Setenv("REMOTE_PROCESSING", "mediaconvert")
+			t.Setenv(env, value)
+			if _, err := ConfigFromEnv(); err == nil {
+				t.Errorf("Expected %s=%s rejected", env, value)
+			}
+		})
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	valid := Config{MessageTransport: TransportSQS, ZipCompression: domain.ArchiveOptions{Method: domain.ZipMethodAuto}}
+	if err := valid.Validate(); err != nil {
+		t.Fatalf("Expected a valid configuration, got %v", err)
+	}
+
+	tests := map[string]func(*Config){
+		"unknown transport":      func(c *Config) { c.MessageTransport = "kafka" },
+		"pubsub without project": func(c *Config) { c.MessageTransport = TransportPubSub },
+		"servicebus without namespace": func(c *Config) {
+			c.MessageTransport = TransportServiceBus
+		},
+		"unknown zip method": func(c *Config) { c.ZipCompression.Method = "rar" },
+		"unknown backend":    func(c *Config) { c.RemoteBackend = "transcoder" },
+		"mediaconvert without role": func(c *Config) {
+			c.RemoteBackend, c.RemoteBucket = RemoteBackendMediaConvert, "staging"
+		},
+		"unknown ocr engine": func(c *Config) { c.OCREngine = "easyocr" },
+	}
+	for name, change := range tests {
+		t.Run(name, func(t *testing.T) {
+			config := valid
+			change(&config)
+			if err := config.Validate(); err == nil {
+				t.Errorf("Expected %s rejected", name)
+			}
+		})
+	}
+}
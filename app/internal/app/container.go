@@ -0,0 +1,388 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+
+	"cloud.google.com/go/pubsub/v2"
+	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/messaging/azservicebus"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/adapter"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/usecase"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/consumer"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/port"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/framecapture"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/message"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/observability"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/storage"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	sqstypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"go.uber.org/zap"
+)
+
+// Queues holds the clients the queues are consumed with; only the one of the transport
+// besides SQS is set
+type Queues struct {
+	SQS        consumer.SQSAPI
+	PubSub     *pubsub.Client
+	ServiceBus *azservicebus.Client
+	Memory     *message.MemoryQueue
+}
+
+// NewConsumer consumes the queue in config with the MESSAGE_TRANSPORT. Pub/Sub and Service Bus
+// keep extending the ack deadline (or lock) of the messages being handled instead of using the
+// SQS visibility timeout, and only the batch size of the polling fields applies to them
+func (q Queues) NewConsumer(config consumer.SQSConfig, handler consumer.Handler) (consumer.Consumer, error) {
+	switch {
+	case q.PubSub != nil:
+		return consumer.NewPubSubConsumer(q.PubSub.Subscriber(config.QueueURL), consumer.PubSubConfig{
+			Subscription:           config.QueueURL,
+			MaxOutstandingMessages: int(config.MaxNumberOfMessages),
+		}, handler), nil
+	case q.ServiceBus != nil:
+		receiver, err := q.ServiceBus.NewReceiverForQueue(config.QueueURL, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Service Bus receiver: %w", err)
+		}
+		return consumer.NewServiceBusConsumer(receiver, consumer.ServiceBusConfig{
+			Entity:      config.QueueURL,
+			MaxMessages: int(config.MaxNumberOfMessages),
+		}, handler), nil
+	case q.Memory != nil:
+		return consumer.NewMemoryConsumer(q.Memory, consumer.MemoryConfig{Queue: config.QueueURL}, handler), nil
+	}
+	return consumer.NewSQSConsumer(q.SQS, config, handler), nil
+}
+
+// sqsAttributesAPI reads the approximate counts of an SQS queue
+type sqsAttributesAPI interface {
+	GetQueueAttributes(ctx context.Context, params *sqs.GetQueueAttributesInput, optFns ...func(*sqs.Options)) (*sqs.GetQueueAttributesOutput, error)
+}
+
+// QueueDepth returns how /processor/stats reads the messages waiting in queue, or nil when the
+// transport does not report it (Pub/Sub and Service Bus need their admin APIs)
+func (q Queues) QueueDepth(queue string) func(ctx context.Context) (int64, error) {
+	switch {
+	case queue == "" || q.PubSub != nil || q.ServiceBus != nil:
+		return nil
+	case q.Memory != nil:
+		return func(ctx context.Context) (int64, error) {
+			return int64(q.Memory.Len(queue)), nil
+		}
+	}
+	client, ok := q.SQS.(sqsAttributesAPI)
+	if !ok {
+		return nil
+	}
+	return func(ctx context.Context) (int64, error) {
+		output, err := client.GetQueueAttributes(ctx, &sqs.GetQueueAttributesInput{
+			QueueUrl:       aws.String(queue),
+			AttributeNames: []sqstypes.QueueAttributeName{sqstypes.QueueAttributeNameApproximateNumberOfMessages},
+		})
+		if err != nil {
+			return 0, err
+		}
+		return strconv.ParseInt(output.Attributes[string(sqstypes.QueueAttributeNameApproximateNumberOfMessages)], 10, 64)
+	}
+}
+
+// Container builds the adapters of an entrypoint from a Config, each once and on first use,
+// so the worker, the CLI and the batch commands share the same wiring and only build what
+// they use (the CLI never loads the AWS configuration). It is safe for concurrent use
+type Container struct {
+	config Config
+
+	mu            sync.Mutex
+	aws           *aws.Config
+	storage       port.StoragePort
+	messages      port.MessagePort
+	queues        Queues
+	processor     port.VideoProcessorPort
+	memoryWorkDir *adapter.MemoryWorkDir
+	errorReporter port.ErrorReporter
+	outbox        port.OutboxPort
+	useCase       *usecase.ProcessVideoUseCase
+	router        *usecase.MessageRouterUseCase
+	// closers release the clients, run in reverse order by Close
+	closers []func()
+}
+
+func New(config Config) *Container {
+	return &Container{config: config}
+}
+
+// Config returns the configuration the container was built with
+func (c *Container) Config() Config {
+	return c.config
+}
+
+// AWS loads the AWS configuration for Config.Region
+func (c *Container) AWS(ctx context.Context) (aws.Config, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.awsConfig(ctx)
+}
+
+func (c *Container) awsConfig(ctx context.Context) (aws.Config, error) {
+	if c.aws != nil {
+		return *c.aws, nil
+	}
+	var options []func(*awsconfig.LoadOptions) error
+	if c.config.Region != "" {
+		options = append(options, awsconfig.WithRegion(c.config.Region))
+	}
+	cfg, err := awsconfig.LoadDefaultConfig(ctx, options...)
+	if err != nil {
+		return aws.Config{}, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	c.aws = &cfg
+	return cfg, nil
+}
+
+// Storage is S3, or the folders under Config.StorageDir. The buckets of Config.OutputRoles are
+// reached on S3 with their role in the jobs of the tenants owning them
+func (c *Container) Storage(ctx context.Context) (port.StoragePort, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.storagePort(ctx)
+}
+
+func (c *Container) storagePort(ctx context.Context) (port.StoragePort, error) {
+	if c.storage != nil {
+		return c.storage, nil
+	}
+	if c.config.StorageDir != "" {
+		c.storage = adapter.NewStorageAdapter(storage.NewFileClient(c.config.StorageDir))
+		return c.storage, nil
+	}
+
+	cfg, err := c.awsConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s3Client, err := storage.NewS3ClientWithOptions(cfg, c.config.S3)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure S3 client: %w", err)
+	}
+	c.storage = adapter.NewStorageAdapter(s3Client)
+	if len(c.config.OutputRoles) == 0 {
+		return c.storage, nil
+	}
+
+	// Each role gets a single client, shared by the buckets it is assumed for
+	clients := map[domain.OutputRoleConfig]port.StoragePort{}
+	buckets := make(map[string]adapter.BucketRoute, len(c.config.OutputRoles))
+	for bucket, route := range c.config.OutputRoles {
+		role := route.OutputRoleConfig
+		if _, ok := clients[role]; !ok {
+			roleClient, err := storage.NewS3ClientWithRole(cfg, c.config.S3, role.RoleARN, role.ExternalID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to configure S3 client for role %s: %w", role.RoleARN, err)
+			}
+			clients[role] = adapter.NewStorageAdapter(roleClient)
+		}
+		buckets[bucket] = adapter.BucketRoute{Storage: clients[role], Tenants: route.Tenants}
+	}
+	c.storage = adapter.NewBucketRouter(c.storage, buckets)
+	observability.GetLogger().Info("tenant output roles enabled", zap.Int("buckets", len(buckets)), zap.Int("roles", len(clients)))
+	return c.storage, nil
+}
+
+// Messages publishes with Config.MessageTransport
+func (c *Container) Messages(ctx context.Context) (port.MessagePort, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.messaging(ctx); err != nil {
+		return nil, err
+	}
+	return c.messages, nil
+}
+
+// Queues returns the clients to consume the queues of Config.MessageTransport with
+func (c *Container) Queues(ctx context.Context) (Queues, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.messaging(ctx); err != nil {
+		return Queues{}, err
+	}
+	return c.queues, nil
+}
+
+// messaging builds the publisher and the queue clients together, as they share the
+// Pub/Sub and Service Bus connections
+func (c *Container) messaging(ctx context.Context) error {
+	if c.messages != nil {
+		return nil
+	}
+
+	var messageService message.MessageService
+	var queues Queues
+	switch c.config.MessageTransport {
+	case TransportPubSub:
+		// The input and confirmation queues are subscriptions and the other queues are topics
+		pubsubClient, err := pubsub.NewClient(ctx, c.config.PubSubProject)
+		if err != nil {
+			return fmt.Errorf("failed to create Pub/Sub client: %w", err)
+		}
+		publisher := message.NewPubSubClient(pubsubClient)
+		c.closers = append(c.closers, func() { pubsubClient.Close() }, publisher.Stop)
+		messageService, queues.PubSub = publisher, pubsubClient
+		observability.GetLogger().Info("pubsub transport enabled", zap.String("project", c.config.PubSubProject))
+	case TransportServiceBus:
+		// Every queue is a Service Bus queue; the result queues may also be topics
+		serviceBusClient, err := c.serviceBusClient()
+		if err != nil {
+			return fmt.Errorf("failed to create Service Bus client: %w", err)
+		}
+		c.closers = append(c.closers, func() { serviceBusClient.Close(context.Background()) })
+		messageService, queues.ServiceBus = message.NewServiceBusClient(serviceBusClient), serviceBusClient
+		observability.GetLogger().Info("service bus transport enabled", zap.String("namespace", c.config.ServiceBusNamespace))
+	case TransportMemory:
+		memoryQueue := message.NewMemoryQueue()
+		messageService, queues.Memory = memoryQueue, memoryQueue
+	}
+
+	// SQS also backs the other transports for the SQS result destinations
+	if c.config.MessageTransport != TransportMemory {
+		cfg, err := c.awsConfig(ctx)
+		if err != nil {
+			return err
+		}
+		queues.SQS = sqs.NewFromConfig(cfg)
+		if messageService == nil {
+			messageService = message.NewSQSClient(cfg)
+		}
+	}
+
+	c.messages = adapter.NewMessageAdapter(messageService)
+	c.queues = queues
+	return nil
+}
+
+// serviceBusClient connects with the connection string, or to the namespace with the default
+// Azure credential (e.g. a workload or managed identity)
+func (c *Container) serviceBusClient() (*azservicebus.Client, error) {
+	if c.config.ServiceBusConnection != "" {
+		return azservicebus.NewClientFromConnectionString(c.config.ServiceBusConnection, nil)
+	}
+	credential, err := azidentity.NewDefaultAzureCredential(nil)
+	if err != nil {
+		return nil, err
+	}
+	return azservicebus.NewClient(c.config.ServiceBusNamespace, credential, nil)
+}
+
+// VideoProcessor is the ffmpeg processor with the worker post-processors and zip settings,
+// routing small videos to Config.MemoryDir and offloading videos to Config.RemoteBackend
+// when they are set
+func (c *Container) VideoProcessor(ctx context.Context) (port.VideoProcessorPort, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.videoProcessor(ctx)
+}
+
+func (c *Container) videoProcessor(ctx context.Context) (port.VideoProcessorPort, error) {
+	if c.processor != nil {
+		return c.processor, nil
+	}
+
+	config := c.config
+	options := c.processorOptions()
+	processor := adapter.NewFFmpegVideoProcessorWithBinaries(config.TempDir, config.FFmpegPath, config.FFprobePath, options...)
+
+	// Small videos are extracted in a memory-backed directory, off the container disk
+	if config.MemoryDir != "" {
+		if err := os.MkdirAll(config.MemoryDir, 0777); err != nil {
+			return nil, fmt.Errorf("failed to create memory directory: %w", err)
+		}
+		c.memoryWorkDir = adapter.NewMemoryWorkDir(config.MemoryDir, config.TempDir, config.MemoryMaxBytes).WithHeadroom(config.MemoryHeadroom)
+		// The archives are budgeted like the videos, and written to disk when they do not fit
+		memoryOptions := append(c.processorOptions(), adapter.WithArchiveFiles(c.memoryWorkDir.CreateArtifact))
+		processor = c.memoryWorkDir.Processor(adapter.NewFFmpegVideoProcessorWithBinaries(config.MemoryDir, config.FFmpegPath, config.FFprobePath, memoryOptions...), processor)
+		observability.GetLogger().Info("in-memory processing enabled", zap.String("dir", config.MemoryDir), zap.Int64("max_video_bytes", config.MemoryMaxBytes), zap.Int64("headroom", config.MemoryHeadroom))
+	}
+
+	// Large or long videos, and any video while the pod is busy, are sent to MediaConvert,
+	// which extracts the frames off the pod
+	if config.RemoteBackend == RemoteBackendMediaConvert {
+		cfg, err := c.awsConfig(ctx)
+		if err != nil {
+			return nil, err
+		}
+		storagePort, err := c.storagePort(ctx)
+		if err != nil {
+			return nil, err
+		}
+		mediaConvert := framecapture.NewMediaConvertClient(cfg, config.MediaConvertRole, config.MediaConvertQueue)
+		remote := adapter.NewRemoteVideoProcessor(mediaConvert, storagePort, config.RemoteBucket, config.RemotePrefix, config.TempDir, config.FFmpegPath, config.FFprobePath, options...)
+		processor = adapter.NewProcessorRouter(processor, remote, config.RemotePolicy)
+		observability.GetLogger().Info("remote processing enabled",
+			zap.String("backend", config.RemoteBackend),
+			zap.String("staging_bucket", config.RemoteBucket),
+			zap.Int64("min_video_bytes", config.RemotePolicy.MinBytes),
+			zap.Float64("min_duration_seconds", config.RemotePolicy.MinSeconds),
+			zap.Int("max_local_jobs", config.RemotePolicy.MaxLocalJobs),
+		)
+	}
+
+	c.processor = processor
+	return processor, nil
+}
+
+func (c *Container) processorOptions() []adapter.ProcessorOption {
+	config := c.config
+	options := []adapter.ProcessorOption{
+		adapter.WithPostProcessors(adapter.NewFFmpegRegionBlur(config.FFmpegPath), adapter.NewMetadataScrubber()),
+		adapter.WithZipPartSize(config.ZipPartSize),
+		adapter.WithZipCompression(config.ZipCompression),
+		adapter.WithFrameNameTemplate(config.FrameName),
+	}
+	if config.Decoders != nil {
+		options = append(options, adapter.WithDecoders(config.Decoders))
+	}
+	if config.Zip64Disabled {
+		options = append(options, adapter.WithoutZip64())
+	}
+	if config.OCREngine == "tesseract" {
+		options = append(options, adapter.WithFrameAnalyzer(adapter.NewTesseractFrameAnalyzer(config.TesseractPath)))
+	}
+	return options
+}
+
+// MemoryWorkDir is the memory-backed directory the video processor uses, nil without
+// Config.MemoryDir or before VideoProcessor
+func (c *Container) MemoryWorkDir() *adapter.MemoryWorkDir {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.memoryWorkDir
+}
+
+// Close releases the clients the container opened
+func (c *Container) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i := len(c.closers) - 1; i >= 0; i-- {
+		c.closers[i]()
+	}
+	c.closers = nil
+}
+
+type containerKey struct{}
+
+// NewContext returns a copy of ctx carrying the container, for handlers that only receive a
+// context (e.g. a Lambda or gRPC handler)
+func NewContext(ctx context.Context, c *Container) context.Context {
+	return context.WithValue(ctx, containerKey{}, c)
+}
+
+// FromContext returns the container of ctx, nil when there is none
+func FromContext(ctx context.Context) *Container {
+	c, _ := ctx.Value(containerKey{}).(*Container)
+	return c
+}
@@ -0,0 +1,138 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/adapter"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/observability"
+)
+
+// localConfig builds everything without cloud services
+func localConfig(t *testing.T) Config {
+	return Config{
+		StorageDir:       t.TempDir(),
+		MessageTransport: TransportMemory,
+		TempDir:          t.TempDir(),
+		MemoryDir:        t.TempDir(),
+		MemoryMaxBytes:   1024,
+		ZipCompression:   domain.ArchiveOptions{Method: domain.ZipMethodAuto},
+	}
+}
+
+func TestContainer_Storage(t *testing.T) {
+	container := New(localConfig(t))
+	defer container.Close()
+
+	storagePort, err := container.Storage(context.Background())
+	if err != nil {
+		t.Fatalf("Storage failed: %v", err)
+	}
+	if _, err := storagePort.PutObject(context.Background(), "output", "a.txt", bytes.NewReader([]byte("frames"))); err != nil {
+		t.Fatalf("PutObject failed: %v", err)
+	}
+	body, err := storagePort.GetObject(context.Background(), "output", "a.txt")
+	if err != nil {
+		t.Fatalf("GetObject failed: %v", err)
+	}
+	defer body.Close()
+	if data, _ := io.ReadAll(body); string(data) != "frames" {
+		t.Errorf("Expected frames, got %q", data)
+	}
+
+	// Built once and shared
+	again, _ := container.Storage(context.Background())
+	if again != storagePort {
+		t.Error("Expected the same storage on every call")
+	}
+}
+
+func TestContainer_StorageOutputRoles(t *testing.T) {
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+	role := domain.OutputRoute{OutputRoleConfig: domain.OutputRoleConfig{RoleARN: "arn:aws:iam::123:role/frames-writer"}, Tenants: []string{"acme"}}
+	container := New(Config{
+		Region:      "us-east-1",
+		OutputRoles: map[string]domain.OutputRoute{"acme-frames": role, "acme-archive": role},
+	})
+	defer container.Close()
+
+	storagePort, err := container.Storage(context.Background())
+	if err != nil {
+		t.Fatalf("Storage failed: %v", err)
+	}
+	if _, ok := storagePort.(*adapter.BucketRouter); !ok {
+		t.Errorf("Expected the tenant buckets routed to their role, got %T", storagePort)
+	}
+
+	// The folders have no roles to assume
+	local := localConfig(t)
+	local.OutputRoles = map[string]domain.OutputRoute{"acme-frames": role}
+	localContainer := New(local)
+	defer localContainer.Close()
+	localStorage, err := localContainer.Storage(context.Background())
+	if err != nil {
+		t.Fatalf("Storage failed: %v", err)
+	}
+	if _, ok := localStorage.(*adapter.BucketRouter); ok {
+		t.Error("Expected no routing on the storage folders")
+	}
+}
+
+func TestContainer_Messages(t *testing.T) {
+	container := New(localConfig(t))
+	defer container.Close()
+
+	messagePort, err := container.Messages(context.Background())
+	if err != nil {
+		t.Fatalf("Messages failed: %v", err)
+	}
+	queues, err := container.Queues(context.Background())
+	if err != nil {
+		t.Fatalf("Queues failed: %v", err)
+	}
+	if queues.Memory == nil || queues.SQS != nil {
+		t.Fatalf("Expected only the memory queue, got %+v", queues)
+	}
+
+	if _, err := messagePort.SendMessage(context.Background(), "results", `{"status":"success"}`); err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+	if queues.Memory.Len("results") != 1 {
+		t.Errorf("Expected the message on the memory queue, got %d", queues.Memory.Len("results"))
+	}
+}
+
+func TestContainer_VideoProcessor(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	container := New(localConfig(t))
+	defer container.Close()
+
+	if container.MemoryWorkDir() != nil {
+		t.Error("Expected no memory directory before the processor is built")
+	}
+	processor, err := container.VideoProcessor(context.Background())
+	if err != nil {
+		t.Fatalf("VideoProcessor failed: %v", err)
+	}
+	if processor == nil || container.MemoryWorkDir() == nil {
+		t.Error("Expected the processor wrapped in the memory directory")
+	}
+}
+
+func TestContainer_Context(t *testing.T) {
+	container := New(localConfig(t))
+	ctx := NewContext(context.Background(), container)
+	if FromContext(ctx) != container {
+		t.Error("Expected the container of the context")
+	}
+	if FromContext(context.Background()) != nil {
+		t.Error("Expected no container without NewContext")
+	}
+}
@@ -0,0 +1,94 @@
+package app
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/consumer"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/signing"
+)
+
+// signatureAttribute carries the HMAC signature of input messages, with INPUT_SIGNING_SECRET or
+// the secret of their producer
+const signatureAttribute = "signature"
+
+// inputAuthenticator checks the input messages of a shared queue: a message with the producer
+// attribute against the credentials and tenants of that producer, and one without it against
+// the shared INPUT_SIGNING_SECRET
+type inputAuthenticator struct {
+	shared    *signing.HMACSigner
+	signers   map[string]*signing.HMACSigner
+	apiKeys   map[string]string
+	producers domain.ProducerRegistry
+}
+
+// newInputAuthenticator returns nil when neither a shared secret nor producers are configured
+func newInputAuthenticator(sharedSecret string, producers domain.ProducerRegistry) (*inputAuthenticator, error) {
+	if sharedSecret == "" && len(producers) == 0 {
+		return nil, nil
+	}
+
+	a := &inputAuthenticator{signers: map[string]*signing.HMACSigner{}, apiKeys: map[string]string{}, producers: producers}
+	if sharedSecret != "" {
+		shared, err := signing.NewHMACSigner([]byte(sharedSecret), "")
+		if err != nil {
+			return nil, err
+		}
+		a.shared = shared
+	}
+	for producer, credentials := range producers {
+		if credentials.SigningSecret != "" {
+			signer, err := signing.NewHMACSigner([]byte(credentials.SigningSecret), producer)
+			if err != nil {
+				return nil, fmt.Errorf("producer %s: %w", producer, err)
+			}
+			a.signers[producer] = signer
+		}
+		if credentials.APIKey != "" {
+			a.apiKeys[producer] = credentials.APIKey
+		}
+	}
+	return a, nil
+}
+
+func (a *inputAuthenticator) authenticate(ctx context.Context, msg consumer.Message) error {
+	producer := msg.Attributes[domain.ProducerAttribute]
+	if producer == "" {
+		if a.shared == nil {
+			return fmt.Errorf("missing %s attribute", domain.ProducerAttribute)
+		}
+		return verifyInputSignature(a.shared, msg)
+	}
+
+	signer, signed := a.signers[producer]
+	apiKey, keyed := a.apiKeys[producer]
+	if !signed && !keyed {
+		return fmt.Errorf("unknown producer %q", producer)
+	}
+	if signed {
+		if err := verifyInputSignature(signer, msg); err != nil {
+			return fmt.Errorf("producer %s: %w", producer, err)
+		}
+	}
+	if keyed && subtle.ConstantTimeCompare([]byte(msg.Attributes[domain.APIKeyAttribute]), []byte(apiKey)) != 1 {
+		return fmt.Errorf("producer %s: invalid %s attribute", producer, domain.APIKeyAttribute)
+	}
+	if err := a.producers[producer].CheckTenant(msg.Body, msg.Attributes); err != nil {
+		return fmt.Errorf("producer %s: %w", producer, err)
+	}
+	return nil
+}
+
+// verifyInputSignature checks the HMAC-SHA256 signature attribute over the raw message body
+func verifyInputSignature(verifier *signing.HMACSigner, msg consumer.Message) error {
+	signature, ok := msg.Attributes[signatureAttribute]
+	if !ok {
+		return fmt.Errorf("missing %s attribute", signatureAttribute)
+	}
+	if !verifier.Verify([]byte(msg.Body), signature) {
+		return fmt.Errorf("invalid signature")
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package app
+
+import (
+	"context"
+	"testing"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/consumer"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/signing"
+)
+
+func TestVerifyInputSignature(t *testing.T) {
+	signer, _ := signing.NewHMACSigner([]byte("secret"), "")
+	signature, _ := signer.Sign(context.Background(), []byte(`{"process_id":"123"}`))
+
+	if err := verifyInputSignature(signer, consumer.Message{Body: `{"process_id":"123"}`, Attributes: map[string]string{"signature": signature}}); err != nil {
+		t.Errorf("Expected a valid signature accepted, got %v", err)
+	}
+	if err := verifyInputSignature(signer, consumer.Message{Body: `{"process_id":"456"}`, Attributes: map[string]string{"signature": signature}}); err == nil {
+		t.Error("Expected a signature over another body rejected")
+	}
+	if err := verifyInputSignature(signer, consumer.Message{Body: `{"process_id":"123"}`}); err == nil {
+		t.Error("Expected an unsigned message rejected")
+	}
+}
+
+func TestInputAuthenticator(t *testing.T) {
+	authenticator, err := newInputAuthenticator("shared", domain.ProducerRegistry{
+		"team-a": {SigningSecret: "secret-a"},
+		"team-b": {APIKey: "key-b"},
+	})
+	if err != nil {
+		t.Fatalf("newInputAuthenticator failed: %v", err)
+	}
+
+	body := `{"process_id":"123"}`
+	sign := func(secret string) string {
+		signer, _ := signing.NewHMACSigner([]byte(secret), "")
+		signature, _ := signer.Sign(context.Background(), []byte(body))
+		return signature
+	}
+
+	tests := []struct {
+		name       string
+		attributes map[string]string
+		wantErr    bool
+	}{
+		{"shared secret", map[string]string{"signature": sign("shared")}, false},
+		{"producer secret", map[string]string{"producer": "team-a", "signature": sign("secret-a")}, false},
+		{"producer api key", map[string]string{"producer": "team-b", "api_key": "key-b"}, false},
+		{"another producer secret", map[string]string{"producer": "team-a", "signature": sign("shared")}, true},
+		{"wrong api key", map[string]string{"producer": "team-b", "api_key": "key-a"}, true},
+		{"missing api key", map[string]string{"producer": "team-b"}, true},
+		{"unknown producer", map[string]string{"producer": "team-c", "signature": sign("shared")}, true},
+		{"unsigned", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := authenticator.authenticate(context.Background(), consumer.Message{Body: body, Attributes: tt.attributes})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	// Without the shared secret only the producers are accepted
+	producersOnly, _ := newInputAuthenticator("", domain.ProducerRegistry{"team-b": {APIKey: "key-b"}})
+	if err := producersOnly.authenticate(context.Background(), consumer.Message{Body: body, Attributes: map[string]string{"signature": sign("shared")}}); err == nil {
+		t.Error("Expected a message without producer rejected")
+	}
+	if disabled, _ := newInputAuthenticator("", nil); disabled != nil {
+		t.Error("Expected no authenticator without secrets")
+	}
+
+	// A producer bound to tenants only sends jobs for them
+	bound, _ := newInputAuthenticator("", domain.ProducerRegistry{"team-b": {APIKey: "key-b", Tenants: []string{"acme"}}})
+	keyed := map[string]string{"producer": "team-b", "api_key": "key-b"}
+	if err := bound.authenticate(context.Background(), consumer.Message{Body: `{"process_id":"123","tenant_id":"acme"}`, Attributes: keyed}); err != nil {
+		t.Errorf("Expected a job of an allowed tenant accepted, got %v", err)
+	}
+	if err := bound.authenticate(context.Background(), consumer.Message{Body: `{"process_id":"123","tenant_id":"globex"}`, Attributes: keyed}); err == nil {
+		t.Error("Expected a job of another tenant rejected")
+	}
+}
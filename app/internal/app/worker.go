@@ -0,0 +1,586 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/adapter"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/usecase"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/consumer"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/port"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/encryption"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/lease"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/message"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/observability"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/scanner"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/schemaregistry"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/signing"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/transcription"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/transfer"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/vision"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/getsentry/sentry-go"
+	"go.temporal.io/sdk/client"
+	"go.uber.org/zap"
+)
+
+// ErrorReporter reports failures and panics to the Sentry (or compatible) project of
+// WorkerConfig.SentryDSN, nil without it. Close sends the pending events
+func (c *Container) ErrorReporter() (port.ErrorReporter, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.errorReporterPort()
+}
+
+func (c *Container) errorReporterPort() (port.ErrorReporter, error) {
+	worker := c.config.Worker
+	if c.errorReporter != nil || worker.SentryDSN == "" {
+		return c.errorReporter, nil
+	}
+	reporter, err := adapter.NewSentryReporter(sentry.ClientOptions{
+		Dsn:         worker.SentryDSN,
+		Environment: worker.SentryEnvironment,
+		Release:     worker.Identity.Version,
+		ServerName:  worker.Identity.Hostname,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid SENTRY_DSN: %w", err)
+	}
+	c.errorReporter = reporter
+	c.closers = append(c.closers, func() { reporter.Flush(5 * time.Second) })
+	observability.GetLogger().Info("error tracking enabled")
+	return reporter, nil
+}
+
+// ProcessVideoUseCase is the video pipeline of the worker, with the processing features of
+// the WorkerConfig: result signing, format, offload and encryption, timeouts, retries, usage,
+// scanning, vision, transcription and the outbox
+func (c *Container) ProcessVideoUseCase(ctx context.Context) (*usecase.ProcessVideoUseCase, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.processVideoUseCase(ctx)
+}
+
+func (c *Container) processVideoUseCase(ctx context.Context) (*usecase.ProcessVideoUseCase, error) {
+	if c.useCase != nil {
+		return c.useCase, nil
+	}
+
+	config, worker := c.config, c.config.Worker
+	cfg, err := c.awsConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	storagePort, err := c.storagePort(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.messaging(ctx); err != nil {
+		return nil, err
+	}
+	processor, err := c.videoProcessor(ctx)
+	if err != nil {
+		return nil, err
+	}
+	signer, err := c.resultSigner(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure result signing: %w", err)
+	}
+	serializer, err := c.resultSerializer(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure result format: %w", err)
+	}
+	errorReporter, err := c.errorReporterPort()
+	if err != nil {
+		return nil, err
+	}
+	outbox, err := c.outboxPort(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tempDir, ffmpegPath, ffprobePath := config.TempDir, config.FFmpegPath, config.FFprobePath
+	useCase := usecase.NewProcessVideoUseCase(
+		storagePort,
+		c.messages,
+		processor,
+		worker.OutputBucket,
+		worker.OutputQueue,
+	).WithStorageClass(worker.StorageClass).WithTenantRegistry(worker.Tenants).WithSourceAllowlist(worker.Sources).WithOutputAllowlist(worker.Outputs).WithVideoExtensions(worker.Extensions, worker.SniffVideos).WithMaxVideoSize(worker.MaxVideoBytes).WithDownloadResumes(worker.DownloadResumes).WithVersionedSources(worker.VersionedSources).WithPackager(adapter.NewFFmpegPackager(tempDir, ffmpegPath, ffprobePath)).WithArchiveMerger(adapter.NewZipMerger(tempDir, !config.Zip64Disabled)).WithConcatenator(adapter.NewFFmpegConcatenator(tempDir, ffmpegPath, ffprobePath)).WithTrimmer(adapter.NewFFmpegTrimmer(tempDir, ffmpegPath)).WithAudioNormalizer(adapter.NewFFmpegNormalizer(tempDir, ffmpegPath, ffprobePath)).WithQualityAnalyzer(adapter.NewFFmpegQualityAnalyzer(ffmpegPath, ffprobePath)).WithFingerprinter(adapter.NewFFmpegFingerprinter(tempDir, ffmpegPath, ffprobePath)).WithBarcodeScanner(adapter.NewFFmpegBarcodeScanner(tempDir, ffmpegPath)).WithWorkerIdentity(worker.Identity).WithDryRun(worker.DryRun).WithDeleteOnConfirm(worker.ConfirmQueue != "").WithResultSerializer(serializer)
+
+	logger := observability.GetLogger()
+	if c.memoryWorkDir != nil {
+		useCase.WithWorkDir(c.memoryWorkDir)
+	}
+	if errorReporter != nil {
+		useCase.WithErrorReporter(errorReporter)
+	}
+	if worker.DryRun {
+		logger.Warn("dry run enabled: jobs are only estimated, nothing is uploaded or deleted")
+	}
+	if signer != nil {
+		useCase.WithSigner(adapter.NewSignerAdapter(signer))
+		logger.Info("result signing enabled",
+			zap.String("algorithm", signer.Algorithm()),
+			zap.String("key_id", signer.KeyID()),
+		)
+	}
+	c.configureProcessing(useCase, cfg)
+	if outbox != nil {
+		useCase.WithOutbox(outbox)
+	}
+	if worker.TemporalTaskQueue != "" {
+		useCase.WithProgressReporter(consumer.NewTemporalProgress())
+	}
+
+	c.useCase = useCase
+	return useCase, nil
+}
+
+// configureProcessing applies the optional processing features of the WorkerConfig: result
+// offload and compression, timeouts, retries, SLA, usage, transfers, sources, scanning,
+// vision, transcription and encryption
+func (c *Container) configureProcessing(useCase *usecase.ProcessVideoUseCase, cfg aws.Config) {
+	logger := observability.GetLogger()
+	worker := c.config.Worker
+
+	payloadBucket := worker.PayloadBucket
+	if payloadBucket == "" {
+		payloadBucket = worker.OutputBucket
+	}
+	useCase.WithPayloadOffload(payloadBucket, worker.PayloadThreshold)
+	if worker.CompressResults {
+		// Results over the limit are gzipped first and only offloaded if still too large
+		useCase.WithCompression(worker.PayloadThreshold)
+		logger.Info("result compression enabled", zap.Int("threshold_bytes", worker.PayloadThreshold))
+	}
+
+	useCase.WithStageTimeouts(worker.Timeouts)
+	useCase.WithRetries(worker.MaxAttempts)
+	useCase.WithSLA(worker.SLA)
+	useCase.WithUsage(worker.CostModel, worker.ResultUsage)
+	useCase.WithTimeline(worker.JobTimeline)
+	if worker.JobRecords {
+		useCase.WithJobRecords(worker.ArchiveBucket, worker.ArchivePrefix)
+	}
+
+	if worker.BandwidthLimit > 0 {
+		// One limiter for the worker, so the cap holds whatever the number of transfers
+		useCase.WithBandwidthLimit(transfer.NewLimiter(worker.BandwidthLimit))
+		logger.Info("transfer bandwidth limited", zap.Int64("bytes_per_second", worker.BandwidthLimit))
+	}
+
+	if worker.ProgressQueue != "" {
+		useCase.WithProgress(worker.ProgressQueue, worker.ProgressInterval)
+		logger.Info("transfer progress messages enabled",
+			zap.String("queue", worker.ProgressQueue),
+			zap.Duration("interval", worker.ProgressInterval),
+		)
+	}
+
+	useCase.WithResultDestinations(resultTransports(cfg, c.messages), worker.ResultDestinations)
+
+	if len(worker.VideoURLHosts) > 0 {
+		// Messages may then carry a presigned or public video_url instead of bucket/key
+		useCase.WithURLSource(adapter.NewHTTPStorageAdapter(worker.VideoURLHosts))
+		logger.Info("video_url sources enabled", zap.Strings("hosts", worker.VideoURLHosts))
+	}
+
+	quarantineBucket := worker.QuarantineBucket
+	if quarantineBucket == "" {
+		quarantineBucket = worker.OutputBucket
+	}
+	if worker.QuarantineInvalid {
+		useCase.WithInputQuarantine(quarantineBucket, worker.QuarantinePrefix)
+		logger.Info("invalid input quarantine enabled",
+			zap.String("quarantine_bucket", quarantineBucket),
+			zap.String("quarantine_prefix", worker.QuarantinePrefix),
+		)
+	}
+
+	if worker.ClamAVAddress != "" {
+		scannerPort := adapter.NewScannerAdapter(scanner.NewClamAVClient(worker.ClamAVAddress, 0))
+		useCase.WithScanner(scannerPort, quarantineBucket, worker.QuarantinePrefix)
+		logger.Info("malware scanning enabled",
+			zap.String("clamav_address", worker.ClamAVAddress),
+			zap.String("quarantine_bucket", quarantineBucket),
+			zap.String("quarantine_prefix", worker.QuarantinePrefix),
+		)
+	}
+
+	// Only tenants configured with vision send frames to Rekognition
+	if worker.Tenants.HasVision() {
+		useCase.WithVisionAnalyzer(adapter.NewFFmpegVisionAnalyzer(c.config.TempDir, c.config.FFmpegPath, vision.NewRekognitionClient(cfg)))
+		logger.Info("vision analysis enabled")
+	}
+
+	switch worker.TranscriptionProvider {
+	case domain.TranscriptionProviderTranscribe:
+		useCase.WithTranscription(adapter.NewTranscribeAdapter(transcription.NewTranscribeClient(cfg)))
+		logger.Info("transcription enabled", zap.String("provider", worker.TranscriptionProvider))
+	case domain.TranscriptionProviderQueue:
+		useCase.WithTranscription(adapter.NewQueueTranscriptionAdapter(c.messages, worker.TranscriptionQueue))
+		logger.Info("transcription enabled", zap.String("provider", worker.TranscriptionProvider), zap.String("queue", worker.TranscriptionQueue))
+	}
+
+	if worker.EncryptResults {
+		encryptor := encryption.NewKMSEnvelopeEncryptor(cfg, worker.EncryptionKeyID)
+		useCase.WithEncryptor(adapter.NewEncryptorAdapter(encryptor))
+		logger.Info("result encryption enabled", zap.String("key_id", worker.EncryptionKeyID))
+	}
+}
+
+// resultTransports sends results to the destinations of each type, besides the output queue
+func resultTransports(cfg aws.Config, messagePort port.MessagePort) map[string]port.MessagePort {
+	webhookClient := &http.Client{
+		Timeout: 10 * time.Second,
+		// Only the configured URL receives results; a redirect fails the delivery
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	return map[string]port.MessagePort{
+		domain.ResultDestinationSQS:     messagePort,
+		domain.ResultDestinationSNS:     adapter.NewMessageAdapter(message.NewSNSClient(cfg)),
+		domain.ResultDestinationWebhook: adapter.NewMessageAdapter(message.NewWebhookClient(webhookClient)),
+	}
+}
+
+// resultSigner builds the signer of WorkerConfig.SigningMode, or nil when signing is disabled
+func (c *Container) resultSigner(cfg aws.Config) (signing.Signer, error) {
+	worker := c.config.Worker
+	switch worker.SigningMode {
+	case "":
+		return nil, nil
+	case "hmac":
+		return signing.NewHMACSigner([]byte(worker.SigningSecret), worker.SigningKeyID)
+	case "kms":
+		if worker.SigningKeyID == "" {
+			return nil, fmt.Errorf("RESULT_SIGNING_KEY_ID is required for kms signing")
+		}
+		return signing.NewKMSSigner(cfg, worker.SigningKeyID), nil
+	default:
+		return nil, fmt.Errorf("unsupported RESULT_SIGNING mode: %s", worker.SigningMode)
+	}
+}
+
+// resultSerializer builds the serializer of WorkerConfig.ResultFormat. With a schema registry
+// the Avro schema is registered at startup and its id framed into every message
+func (c *Container) resultSerializer(ctx context.Context) (port.ResultSerializerPort, error) {
+	worker := c.config.Worker
+	switch worker.ResultFormat {
+	case "", domain.ResultFormatJSON:
+		return adapter.NewJSONResultSerializer(), nil
+	case domain.ResultFormatProtobuf:
+		return adapter.NewProtobufResultSerializer(), nil
+	case domain.ResultFormatAvro:
+		if worker.SchemaRegistryURL == "" {
+			return adapter.NewAvroResultSerializer(0), nil
+		}
+		schemaID, err := schemaregistry.NewClient(worker.SchemaRegistryURL).Register(ctx, worker.SchemaSubject, adapter.AvroResultSchema)
+		if err != nil {
+			return nil, fmt.Errorf("failed to register result schema: %w", err)
+		}
+		observability.GetLogger().Info("result schema registered",
+			zap.String("subject", worker.SchemaSubject),
+			zap.Int("schema_id", schemaID),
+		)
+		return adapter.NewAvroResultSerializer(schemaID), nil
+	default:
+		return nil, fmt.Errorf("unsupported RESULT_FORMAT: %s", worker.ResultFormat)
+	}
+}
+
+// outboxPort persists the result messages under WorkerConfig.OutboxBucket, or in OutboxDir,
+// and is nil when neither is set and results are sent directly
+func (c *Container) outboxPort(ctx context.Context) (port.OutboxPort, error) {
+	worker := c.config.Worker
+	if c.outbox != nil || (worker.OutboxBucket == "" && worker.OutboxDir == "") {
+		return c.outbox, nil
+	}
+
+	location := worker.OutboxDir
+	if worker.OutboxBucket != "" {
+		storagePort, err := c.storagePort(ctx)
+		if err != nil {
+			return nil, err
+		}
+		c.outbox = adapter.NewStorageOutbox(storagePort, worker.OutboxBucket, worker.OutboxPrefix)
+		location = "s3://" + worker.OutboxBucket + "/" + worker.OutboxPrefix
+	} else {
+		c.outbox = adapter.NewFileOutbox(worker.OutboxDir)
+	}
+	observability.GetLogger().Info("result outbox enabled",
+		zap.String("location", location),
+		zap.Duration("dispatch_interval", worker.OutboxInterval),
+	)
+	return c.outbox, nil
+}
+
+// OutboxDispatcher retries the result messages the ProcessVideoUseCase left in the outbox, nil
+// without an outbox
+func (c *Container) OutboxDispatcher(ctx context.Context) (*usecase.DispatchOutboxUseCase, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	outbox, err := c.outboxPort(ctx)
+	if err != nil || outbox == nil {
+		return nil, err
+	}
+	storagePort, err := c.storagePort(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.messaging(ctx); err != nil {
+		return nil, err
+	}
+	return usecase.NewDispatchOutboxUseCase(outbox, storagePort, c.messages, c.config.Worker.OutputQueue), nil
+}
+
+// MessageRouter routes the message types of the input queue to the ProcessVideoUseCase, the
+// cancellations and, with WorkerConfig.JobRecords, the reprocessing; the other types go to
+// WorkerConfig.DeadLetterQueue
+func (c *Container) MessageRouter(ctx context.Context) (*usecase.MessageRouterUseCase, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.messageRouter(ctx)
+}
+
+func (c *Container) messageRouter(ctx context.Context) (*usecase.MessageRouterUseCase, error) {
+	if c.router != nil {
+		return c.router, nil
+	}
+	useCase, err := c.processVideoUseCase(ctx)
+	if err != nil {
+		return nil, err
+	}
+	storagePort, err := c.storagePort(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	worker := c.config.Worker
+	cancelJob := usecase.NewCancelJobUseCase(storagePort, worker.OutputBucket)
+	useCase.WithCancellations(true)
+
+	router := usecase.NewMessageRouterUseCase(c.messages, worker.DeadLetterQueue).
+		Register(domain.MessageTypeVideoProcess, consumer.ProcessMessage(useCase)).
+		Register(domain.MessageTypeVideoCancel, consumer.CancelMessage(cancelJob)).
+		Register(domain.MessageTypePing, consumer.PingMessage)
+
+	// Without JOB_RECORDS there is nothing to reprocess from, and video.reprocess is unknown
+	if worker.JobRecords {
+		reprocess := usecase.NewReprocessVideoUseCase(storagePort, worker.OutputBucket, useCase)
+		router.Register(domain.MessageTypeVideoReprocess, consumer.ReprocessMessage(useCase, reprocess))
+	}
+	c.router = router
+	return router, nil
+}
+
+// InputConsumers consumes WorkerConfig.InputQueue with the MessageRouter behind the input
+// middlewares and, with WorkerConfig.TemporalTaskQueue, runs the jobs as Temporal activities.
+// The RunConfig pauses them on the kill switch, the maintenance and the input lease; each call
+// builds new consumers
+func (c *Container) InputConsumers(ctx context.Context) ([]consumer.Consumer, consumer.RunConfig, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	worker := c.config.Worker
+	run := consumer.RunConfig{
+		Maintenance:         &consumer.Maintenance{},
+		MaintenanceActive:   worker.Maintenance.Active,
+		MaintenanceInterval: worker.Maintenance.Interval,
+	}
+	router, err := c.messageRouter(ctx)
+	if err != nil {
+		return nil, run, err
+	}
+	storagePort, err := c.storagePort(ctx)
+	if err != nil {
+		return nil, run, err
+	}
+	errorReporter, err := c.errorReporterPort()
+	if err != nil {
+		return nil, run, err
+	}
+	middlewares, err := inputMiddlewares(worker, errorReporter, router)
+	if err != nil {
+		return nil, run, fmt.Errorf("failed to configure input middlewares: %w", err)
+	}
+	// Messages received during the maintenance are returned before any other middleware
+	middlewares = append([]consumer.Middleware{run.Maintenance.Middleware()}, middlewares...)
+	if run.KillSwitch = newKillSwitch(worker.KillSwitch); run.KillSwitch != nil {
+		middlewares = append(middlewares, run.KillSwitch.Middleware())
+	}
+	handler := consumer.Route(router, storagePort)
+
+	var inputs []consumer.Consumer
+	if worker.InputQueue != "" {
+		input, err := c.queues.NewConsumer(consumer.SQSConfig{
+			QueueURL:            worker.InputQueue,
+			MaxNumberOfMessages: 1,
+			WaitTimeSeconds:     10,
+			VisibilityTimeout:   300, // 5 minutos para processar
+			AttributeNames:      []string{domain.MessageTypeAttribute, signatureAttribute, domain.ProducerAttribute, domain.APIKeyAttribute, consumer.TraceIDAttribute, consumer.TraceparentAttribute},
+		}, consumer.Chain(handler, middlewares...))
+		if err != nil {
+			return nil, run, err
+		}
+		inputs = append(inputs, input)
+	}
+
+	if worker.TemporalTaskQueue != "" {
+		temporalClient, err := client.Dial(client.Options{HostPort: worker.TemporalAddress, Namespace: worker.TemporalNamespace})
+		if err != nil {
+			return nil, run, fmt.Errorf("failed to connect to Temporal: %w", err)
+		}
+		c.closers = append(c.closers, temporalClient.Close)
+		// Temporal authenticates its clients and does not redeliver a completed activity, so
+		// signatures and deduplication do not apply
+		inputs = append(inputs, consumer.NewTemporalConsumer(temporalClient, consumer.TemporalConfig{
+			TaskQueue: worker.TemporalTaskQueue,
+		}, consumer.Chain(handler, consumer.Tracing(), consumer.Logging(), consumer.Metrics(), consumer.Recovery(errorReporter))))
+		observability.GetLogger().Info("temporal activity enabled",
+			zap.String("address", worker.TemporalAddress),
+			zap.String("namespace", worker.TemporalNamespace),
+			zap.String("task_queue", worker.TemporalTaskQueue),
+			zap.String("activity", consumer.DefaultTemporalActivity),
+		)
+	}
+
+	elector, err := c.leaseElector(ctx)
+	if err != nil {
+		return nil, run, fmt.Errorf("failed to configure input lease: %w", err)
+	}
+	if elector != nil {
+		run.Elector = elector
+	}
+	return inputs, run, nil
+}
+
+// inputMiddlewares builds the chain around the input queue handler: tracing, logging and
+// metrics always, authentication with INPUT_SIGNING_SECRET or INPUT_PRODUCERS, rejecting the
+// unauthenticated messages through the router, and deduplication of redelivered messages for
+// MESSAGE_DEDUP_TTL (0 disables it)
+func inputMiddlewares(worker WorkerConfig, errorReporter port.ErrorReporter, router *usecase.MessageRouterUseCase) ([]consumer.Middleware, error) {
+	middlewares := []consumer.Middleware{
+		consumer.Tracing(),
+		consumer.Logging(),
+		consumer.Metrics(),
+		consumer.Recovery(errorReporter),
+	}
+
+	authenticator, err := newInputAuthenticator(worker.InputSigningSecret, worker.InputProducers)
+	if err != nil {
+		return nil, err
+	}
+	if authenticator != nil {
+		middlewares = append(middlewares, consumer.Auth(authenticator.authenticate, func(ctx context.Context, msg consumer.Message, reason string) error {
+			return router.Reject(ctx, msg.Body, reason)
+		}))
+		observability.GetLogger().Info("input authentication enabled",
+			zap.Bool("shared_secret", worker.InputSigningSecret != ""),
+			zap.Int("producers", len(worker.InputProducers)),
+		)
+	}
+
+	if worker.DedupTTL > 0 {
+		middlewares = append(middlewares, consumer.Idempotency(worker.DedupTTL))
+	}
+	return middlewares, nil
+}
+
+// newKillSwitch builds the kill switch that pauses the consumption once the failure rate of
+// config is reached, or nil when it is disabled
+func newKillSwitch(config consumer.KillSwitchConfig) *consumer.KillSwitch {
+	if config.FailureRate == 0 {
+		return nil
+	}
+	observability.GetLogger().Info("kill switch enabled",
+		zap.Float64("failure_rate", config.FailureRate),
+		zap.Duration("window", config.Window),
+		zap.Int("min_messages", config.MinMessages),
+	)
+	return consumer.NewKillSwitch(config)
+}
+
+// leaseElector makes the replicas compete for a lease so only the holder consumes the input;
+// without WorkerConfig.Lease.Backend the elector is nil and every replica consumes
+func (c *Container) leaseElector(ctx context.Context) (*lease.Elector, error) {
+	worker := c.config.Worker
+	var store lease.Store
+	switch worker.Lease.Backend {
+	case "":
+		return nil, nil
+	case LeaseDynamoDB:
+		cfg, err := c.awsConfig(ctx)
+		if err != nil {
+			return nil, err
+		}
+		store = lease.NewDynamoDBStore(dynamodb.NewFromConfig(cfg), worker.Lease.DynamoDBTable, worker.Lease.Name)
+	case LeaseKubernetes:
+		kubernetesStore, err := lease.NewInClusterKubernetesStore(worker.Lease.Name)
+		if err != nil {
+			return nil, err
+		}
+		store = kubernetesStore
+	default:
+		return nil, fmt.Errorf("invalid LEASE_BACKEND %q, expected dynamodb or kubernetes", worker.Lease.Backend)
+	}
+
+	holder := worker.Identity.Pod
+	if holder == "" {
+		holder = worker.Identity.Hostname
+	}
+	logger := observability.GetLogger()
+	logger.Info("input lease enabled", zap.String("backend", worker.Lease.Backend))
+	return lease.NewElector(store, holder, lease.Config{
+		Duration: worker.Lease.Duration,
+		OnError: func(err error) {
+			logger.Warn("lease store error", zap.Error(err))
+		},
+	}), nil
+}
+
+// JobSubmitter stages videos in WorkerConfig.JobsBucket and enqueues them on InputQueue. With
+// input authentication, callers send the api_key of a producer of INPUT_PRODUCERS and the job
+// is enqueued as that producer; the shared INPUT_SIGNING_SECRET is never used, so the endpoint
+// cannot mint messages other producers would be trusted with
+func (c *Container) JobSubmitter(ctx context.Context) (*usecase.SubmitJobUseCase, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	storagePort, err := c.storagePort(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := c.messaging(ctx); err != nil {
+		return nil, err
+	}
+
+	worker := c.config.Worker
+	submitter := usecase.NewSubmitJobUseCase(storagePort, c.messages, worker.JobsBucket, worker.InputQueue)
+	if worker.InputSigningSecret == "" && len(worker.InputProducers) == 0 {
+		return submitter, nil
+	}
+
+	signers := map[string]port.SignerPort{}
+	keyed := false
+	for producer, credentials := range worker.InputProducers {
+		keyed = keyed || credentials.APIKey != ""
+		if credentials.SigningSecret == "" {
+			continue
+		}
+		signer, err := signing.NewHMACSigner([]byte(credentials.SigningSecret), producer)
+		if err != nil {
+			return nil, fmt.Errorf("producer %s: %w", producer, err)
+		}
+		signers[producer] = adapter.NewSignerAdapter(signer)
+	}
+	if !keyed {
+		return nil, fmt.Errorf("input authentication is enabled, so the jobs endpoint needs a producer with an api_key in INPUT_PRODUCERS")
+	}
+	return submitter.WithProducers(worker.InputProducers, signers), nil
+}
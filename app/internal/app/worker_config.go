@@ -0,0 +1,557 @@
+package app
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/consumer"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/buildinfo"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/ffmpeg"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/lease"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/logsink"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/observability"
+	"go.temporal.io/sdk/client"
+)
+
+// DefaultRegion is the AWS region of the worker when AWS_REGION is not set
+const DefaultRegion = "us-east-1"
+
+// s3MaxPutObjectBytes is the largest object a single PutObject accepts, the default upload limit
+const s3MaxPutObjectBytes = 5 * 1024 * 1024 * 1024
+
+// sqsMaxPayloadBytes is the SQS message size limit; larger results are offloaded to S3
+const sqsMaxPayloadBytes = 256 * 1024
+
+// Backends of the input lease selected by LEASE_BACKEND
+const (
+	LeaseDynamoDB   = "dynamodb"
+	LeaseKubernetes = "kubernetes"
+)
+
+// WorkerConfig is what the worker runs with besides the adapters: its queues and buckets, the
+// processing features and how the input is consumed. ConfigFromEnv reads it with the Config
+type WorkerConfig struct {
+	Environment string
+	Identity    domain.WorkerIdentity
+
+	InputQueue      string
+	OutputQueue     string
+	DeadLetterQueue string
+	OutputBucket    string
+	StorageClass    string
+
+	Tenants            domain.TenantRegistry
+	Sources            domain.SourceAllowlist
+	Outputs            domain.SourceAllowlist
+	Extensions         domain.VideoExtensions
+	SniffVideos        bool
+	VersionedSources   bool
+	VideoURLHosts      []string
+	MaxVideoBytes      int64
+	DownloadResumes    int
+	Timeouts           domain.StageTimeouts
+	MaxAttempts        int
+	SLA                time.Duration
+	BandwidthLimit     int64
+	DryRun             bool
+	SelfTest           bool
+	SelfTestTimeout    time.Duration
+	ResultDestinations domain.ResultDestinations
+
+	// Results: format, signature, encryption, offload and compression; an empty PayloadBucket
+	// is the OutputBucket
+	ResultFormat      string
+	SchemaRegistryURL string
+	SchemaSubject     string
+	SigningMode       string
+	SigningSecret     string
+	SigningKeyID      string
+	EncryptResults    bool
+	EncryptionKeyID   string
+	PayloadBucket     string
+	PayloadThreshold  int
+	CompressResults   bool
+
+	// Usage, timeline and job records of the results
+	CostModel     domain.CostModel
+	ResultUsage   bool
+	JobTimeline   bool
+	JobRecords    bool
+	ArchiveBucket string
+	ArchivePrefix string
+
+	// Scanning and quarantine of the invalid inputs; an empty QuarantineBucket is the OutputBucket
+	ClamAVAddress     string
+	QuarantineInvalid bool
+	QuarantineBucket  string
+	QuarantinePrefix  string
+
+	TranscriptionProvider string
+	TranscriptionQueue    string
+
+	ProgressQueue    string
+	ProgressInterval time.Duration
+
+	// ConfirmQueue enables the two-phase deletion of the original videos
+	ConfirmQueue string
+
+	// Outbox persists the results under OutboxBucket, or in OutboxDir, until they are sent
+	OutboxDir      string
+	OutboxBucket   string
+	OutboxPrefix   string
+	OutboxInterval time.Duration
+
+	// Jobs endpoint, enabled by JobsPort
+	JobsPort           string
+	JobsBucket         string
+	JobsMaxUploadBytes int64
+	JobsURLHosts       []string
+
+	// Input authentication, deduplication and consumption controls
+	InputSigningSecret string
+	InputProducers     domain.ProducerRegistry
+	DedupTTL           time.Duration
+	KillSwitch         consumer.KillSwitchConfig
+	Maintenance        MaintenanceConfig
+	Lease              LeaseConfig
+
+	TemporalAddress   string
+	TemporalNamespace string
+	TemporalTaskQueue string
+
+	// Observability of the worker
+	MetricsMaxTenants int
+	MetricsTenants    []string
+	SLOObjective      float64
+	SentryDSN         string
+	SentryEnvironment string
+	Logs              LogSinksConfig
+	FFmpeg            ffmpeg.Requirements
+
+	// DemoDir is set by --demo, the only mode the in-memory transport runs in
+	DemoDir string
+}
+
+// MaintenanceConfig is the maintenance flag: Enabled, or File existing, checked every Interval;
+// without File the flag never changes and Interval is zero
+type MaintenanceConfig struct {
+	Enabled  bool
+	File     string
+	Interval time.Duration
+}
+
+// Active reports whether the maintenance is on
+func (c MaintenanceConfig) Active() bool {
+	if c.Enabled {
+		return true
+	}
+	if c.File == "" {
+		return false
+	}
+	_, err := os.Stat(c.File)
+	return err == nil
+}
+
+// LeaseConfig makes the replicas compete for a lease so only the holder consumes the input;
+// without Backend every replica consumes
+type LeaseConfig struct {
+	Backend       string
+	Name          string
+	DynamoDBTable string
+	Duration      time.Duration
+}
+
+// LogSinksConfig forwards the logs to CloudWatch Logs and to Loki besides stdout, for
+// environments without a node-level log shipper
+type LogSinksConfig struct {
+	Batching         logsink.Config
+	CloudWatchGroup  string
+	CloudWatchStream string
+	LokiURL          string
+	LokiLabels       map[string]string
+	LokiTenant       string
+}
+
+// workerConfigFromEnv reads the WorkerConfig, failing on values that do not parse
+func workerConfigFromEnv(tenants domain.TenantRegistry) (WorkerConfig, error) {
+	hostname, _ := os.Hostname()
+	config := WorkerConfig{
+		Environment: getEnv("ENVIRONMENT", "development"),
+		Identity: domain.WorkerIdentity{
+			Hostname: hostname,
+			// POD_NAME comes from the Kubernetes Downward API
+			Pod:     os.Getenv("POD_NAME"),
+			Version: buildinfo.Version,
+			Commit:  buildinfo.GitCommit(),
+		},
+		InputQueue:            os.Getenv("QUEUE_INPUT"),
+		OutputQueue:           os.Getenv("QUEUE_OUTPUT"),
+		DeadLetterQueue:       os.Getenv("QUEUE_DLQ"),
+		OutputBucket:          os.Getenv("STORAGE_OUTPUT"),
+		StorageClass:          os.Getenv("STORAGE_CLASS"),
+		Tenants:               tenants,
+		SniffVideos:           os.Getenv("VIDEO_CONTENT_SNIFFING") == "true",
+		VersionedSources:      os.Getenv("VERSIONED_SOURCES") == "true",
+		VideoURLHosts:         splitList(os.Getenv("VIDEO_URL_ALLOWED_HOSTS")),
+		DryRun:                os.Getenv("DRY_RUN") == "true",
+		SelfTest:              os.Getenv("SELF_TEST") == "true",
+		ResultFormat:          os.Getenv("RESULT_FORMAT"),
+		SchemaRegistryURL:     os.Getenv("RESULT_SCHEMA_REGISTRY_URL"),
+		SchemaSubject:         getEnv("RESULT_SCHEMA_SUBJECT", "processor-result-value"),
+		SigningMode:           os.Getenv("RESULT_SIGNING"),
+		SigningSecret:         os.Getenv("RESULT_SIGNING_SECRET"),
+		SigningKeyID:          os.Getenv("RESULT_SIGNING_KEY_ID"),
+		EncryptResults:        os.Getenv("ENCRYPT_RESULTS") == "true",
+		EncryptionKeyID:       os.Getenv("ENCRYPT_RESULTS_KMS_KEY_ID"),
+		PayloadBucket:         os.Getenv("PAYLOAD_OFFLOAD_BUCKET"),
+		CompressResults:       os.Getenv("COMPRESS_RESULTS") == "true",
+		ResultUsage:           os.Getenv("RESULT_USAGE") == "true",
+		JobTimeline:           os.Getenv("JOB_TIMELINE") == "true",
+		JobRecords:            os.Getenv("JOB_RECORDS") == "true",
+		ArchiveBucket:         os.Getenv("SOURCE_ARCHIVE_BUCKET"),
+		ArchivePrefix:         getEnv("SOURCE_ARCHIVE_PREFIX", "originals"),
+		ClamAVAddress:         os.Getenv("CLAMAV_ADDRESS"),
+		QuarantineInvalid:     os.Getenv("QUARANTINE_INVALID_INPUTS") == "true",
+		QuarantineBucket:      os.Getenv("QUARANTINE_BUCKET"),
+		QuarantinePrefix:      getEnv("QUARANTINE_PREFIX", "quarantine"),
+		TranscriptionProvider: os.Getenv("TRANSCRIPTION_PROVIDER"),
+		TranscriptionQueue:    os.Getenv("TRANSCRIPTION_QUEUE"),
+		ProgressQueue:         os.Getenv("PROGRESS_QUEUE"),
+		ConfirmQueue:          os.Getenv("CONFIRM_QUEUE"),
+		OutboxDir:             os.Getenv("OUTBOX_DIR"),
+		OutboxBucket:          os.Getenv("OUTBOX_BUCKET"),
+		OutboxPrefix:          getEnv("OUTBOX_PREFIX", "outbox"),
+		JobsPort:              os.Getenv("JOBS_HTTP_PORT"),
+		JobsBucket:            os.Getenv("JOBS_INPUT_BUCKET"),
+		JobsURLHosts:          splitList(os.Getenv("JOBS_URL_ALLOWED_HOSTS")),
+		InputSigningSecret:    os.Getenv("INPUT_SIGNING_SECRET"),
+		TemporalAddress:       getEnv("TEMPORAL_ADDRESS", client.DefaultHostPort),
+		TemporalNamespace:     getEnv("TEMPORAL_NAMESPACE", client.DefaultNamespace),
+		TemporalTaskQueue:     os.Getenv("TEMPORAL_TASK_QUEUE"),
+		MetricsTenants:        splitList(os.Getenv("METRICS_TENANTS")),
+		SentryDSN:             os.Getenv("SENTRY_DSN"),
+		Maintenance: MaintenanceConfig{
+			Enabled: os.Getenv("MAINTENANCE_MODE") == "true",
+			File:    os.Getenv("MAINTENANCE_FILE"),
+		},
+		Lease: LeaseConfig{
+			Backend:       os.Getenv("LEASE_BACKEND"),
+			Name:          getEnv("LEASE_NAME", "hackaton-soat-processor"),
+			DynamoDBTable: os.Getenv("LEASE_DYNAMODB_TABLE"),
+		},
+		Logs: LogSinksConfig{
+			CloudWatchGroup:  os.Getenv("LOG_CLOUDWATCH_GROUP"),
+			CloudWatchStream: getEnv("LOG_CLOUDWATCH_STREAM", hostname),
+			LokiURL:          os.Getenv("LOG_LOKI_URL"),
+			LokiTenant:       os.Getenv("LOG_LOKI_TENANT"),
+		},
+	}
+	config.SentryEnvironment = getEnv("SENTRY_ENVIRONMENT", config.Environment)
+
+	var err error
+	if config.Sources, err = domain.ParseSourceAllowlist(os.Getenv("ALLOWED_SOURCES")); err != nil {
+		return config, fmt.Errorf("invalid ALLOWED_SOURCES: %w", err)
+	}
+	if config.Outputs, err = domain.ParseSourceAllowlist(os.Getenv("ALLOWED_OUTPUTS")); err != nil {
+		return config, fmt.Errorf("invalid ALLOWED_OUTPUTS: %w", err)
+	}
+	if config.Extensions, err = domain.ParseVideoExtensions(os.Getenv("VIDEO_EXTENSIONS")); err != nil {
+		return config, fmt.Errorf("invalid VIDEO_EXTENSIONS: %w", err)
+	}
+	if config.ResultDestinations, err = domain.ParseResultDestinations(os.Getenv("RESULT_DESTINATIONS_ALLOWED")); err != nil {
+		return config, fmt.Errorf("invalid RESULT_DESTINATIONS_ALLOWED: %w", err)
+	}
+	if config.InputProducers, err = domain.ParseProducerRegistry([]byte(os.Getenv("INPUT_PRODUCERS"))); err != nil {
+		return config, fmt.Errorf("INPUT_PRODUCERS: %w", err)
+	}
+
+	config.MaxVideoBytes, err = strconv.ParseInt(getEnv("MAX_VIDEO_BYTES", "0"), 10, 64)
+	if err != nil || config.MaxVideoBytes < 0 {
+		return config, fmt.Errorf("invalid MAX_VIDEO_BYTES %q", os.Getenv("MAX_VIDEO_BYTES"))
+	}
+	config.DownloadResumes, err = strconv.Atoi(getEnv("DOWNLOAD_MAX_RESUMES", "3"))
+	if err != nil || config.DownloadResumes < 0 {
+		return config, fmt.Errorf("invalid DOWNLOAD_MAX_RESUMES %q", os.Getenv("DOWNLOAD_MAX_RESUMES"))
+	}
+	if config.Timeouts, err = stageTimeouts(); err != nil {
+		return config, fmt.Errorf("invalid stage timeouts: %w", err)
+	}
+	config.MaxAttempts, err = strconv.Atoi(getEnv("JOB_MAX_ATTEMPTS", "1"))
+	if err != nil || config.MaxAttempts < 1 {
+		return config, fmt.Errorf("invalid JOB_MAX_ATTEMPTS %q", os.Getenv("JOB_MAX_ATTEMPTS"))
+	}
+	config.SLA, err = time.ParseDuration(getEnv("JOB_SLA", "0"))
+	if err != nil || config.SLA < 0 {
+		return config, fmt.Errorf("invalid JOB_SLA %q", os.Getenv("JOB_SLA"))
+	}
+	config.BandwidthLimit, err = strconv.ParseInt(getEnv("TRANSFER_BANDWIDTH_LIMIT", "0"), 10, 64)
+	if err != nil || config.BandwidthLimit < 0 {
+		return config, fmt.Errorf("invalid TRANSFER_BANDWIDTH_LIMIT %q", os.Getenv("TRANSFER_BANDWIDTH_LIMIT"))
+	}
+	config.SelfTestTimeout, err = time.ParseDuration(getEnv("SELF_TEST_TIMEOUT", "60s"))
+	if err != nil || config.SelfTestTimeout <= 0 {
+		return config, fmt.Errorf("invalid SELF_TEST_TIMEOUT %q", os.Getenv("SELF_TEST_TIMEOUT"))
+	}
+	config.PayloadThreshold, err = strconv.Atoi(getEnv("PAYLOAD_OFFLOAD_THRESHOLD", strconv.Itoa(sqsMaxPayloadBytes)))
+	if err != nil {
+		return config, fmt.Errorf("invalid PAYLOAD_OFFLOAD_THRESHOLD: %w", err)
+	}
+	if config.CostModel, err = costModelFromEnv(); err != nil {
+		return config, fmt.Errorf("invalid cost model: %w", err)
+	}
+	config.ProgressInterval, err = time.ParseDuration(getEnv("PROGRESS_INTERVAL", "10s"))
+	if err != nil || config.ProgressInterval <= 0 {
+		return config, fmt.Errorf("invalid PROGRESS_INTERVAL %q", os.Getenv("PROGRESS_INTERVAL"))
+	}
+	config.OutboxInterval, err = time.ParseDuration(getEnv("OUTBOX_DISPATCH_INTERVAL", "10s"))
+	if err != nil || config.OutboxInterval <= 0 {
+		return config, fmt.Errorf("invalid OUTBOX_DISPATCH_INTERVAL %q", os.Getenv("OUTBOX_DISPATCH_INTERVAL"))
+	}
+	config.JobsMaxUploadBytes, err = strconv.ParseInt(getEnv("JOBS_MAX_UPLOAD_BYTES", strconv.Itoa(s3MaxPutObjectBytes)), 10, 64)
+	if err != nil || config.JobsMaxUploadBytes <= 0 {
+		return config, fmt.Errorf("invalid JOBS_MAX_UPLOAD_BYTES %q", os.Getenv("JOBS_MAX_UPLOAD_BYTES"))
+	}
+	config.DedupTTL, err = time.ParseDuration(getEnv("MESSAGE_DEDUP_TTL", "1h"))
+	if err != nil || config.DedupTTL < 0 {
+		return config, fmt.Errorf("invalid MESSAGE_DEDUP_TTL %q", os.Getenv("MESSAGE_DEDUP_TTL"))
+	}
+	if config.KillSwitch, err = killSwitchConfig(); err != nil {
+		return config, err
+	}
+	interval, err := time.ParseDuration(getEnv("MAINTENANCE_CHECK_INTERVAL", "5s"))
+	if err != nil || interval <= 0 {
+		return config, fmt.Errorf("invalid MAINTENANCE_CHECK_INTERVAL %q", os.Getenv("MAINTENANCE_CHECK_INTERVAL"))
+	}
+	if config.Maintenance.File != "" {
+		config.Maintenance.Interval = interval
+	}
+	config.Lease.Duration, err = time.ParseDuration(getEnv("LEASE_DURATION", lease.DefaultDuration.String()))
+	if err != nil || config.Lease.Duration <= 0 {
+		return config, fmt.Errorf("invalid LEASE_DURATION %q", os.Getenv("LEASE_DURATION"))
+	}
+
+	// The tenant label is bounded before any metric is recorded
+	config.MetricsMaxTenants, err = strconv.Atoi(getEnv("METRICS_MAX_TENANTS", strconv.Itoa(observability.DefaultMaxTenantLabels)))
+	if err != nil || config.MetricsMaxTenants < 0 {
+		return config, fmt.Errorf("invalid METRICS_MAX_TENANTS %q", os.Getenv("METRICS_MAX_TENANTS"))
+	}
+	config.SLOObjective, err = strconv.ParseFloat(getEnv("SLO_OBJECTIVE", strconv.FormatFloat(observability.DefaultSLOObjective, 'f', -1, 64)), 64)
+	if err != nil || config.SLOObjective <= 0 || config.SLOObjective >= 1 {
+		return config, fmt.Errorf("invalid SLO_OBJECTIVE %q, expected a share between 0 and 1", os.Getenv("SLO_OBJECTIVE"))
+	}
+	if config.Logs, err = logSinksConfig(config.Logs); err != nil {
+		return config, err
+	}
+	if config.FFmpeg, err = ffmpegRequirements(); err != nil {
+		return config, err
+	}
+	return config, nil
+}
+
+// ValidateWorker checks what the worker requires on top of Validate: its queues and buckets,
+// and the settings each enabled feature needs
+func (c Config) ValidateWorker() error {
+	w := c.Worker
+	if w.InputQueue == "" && w.TemporalTaskQueue == "" {
+		return fmt.Errorf("QUEUE_INPUT or TEMPORAL_TASK_QUEUE environment variable is required")
+	}
+	if w.JobsPort != "" && w.InputQueue == "" {
+		return fmt.Errorf("QUEUE_INPUT is required when JOBS_HTTP_PORT is set")
+	}
+	if w.OutputQueue == "" {
+		return fmt.Errorf("QUEUE_OUTPUT environment variable is required")
+	}
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	if c.MessageTransport == TransportMemory && w.DemoDir == "" {
+		return fmt.Errorf("MESSAGE_TRANSPORT=memory is only available with --demo")
+	}
+	// The jobs server publishes to QUEUE_INPUT, a subscription with Pub/Sub
+	if c.MessageTransport == TransportPubSub && w.JobsPort != "" {
+		return fmt.Errorf("JOBS_HTTP_PORT is not supported when MESSAGE_TRANSPORT=pubsub")
+	}
+	if w.OutputBucket == "" {
+		return fmt.Errorf("STORAGE_OUTPUT environment variable is required")
+	}
+	if w.EncryptResults && w.EncryptionKeyID == "" {
+		return fmt.Errorf("ENCRYPT_RESULTS_KMS_KEY_ID is required when ENCRYPT_RESULTS=true")
+	}
+	if err := domain.ValidateStorageClass(w.StorageClass); err != nil {
+		return fmt.Errorf("STORAGE_CLASS: %w", err)
+	}
+	if w.JobsPort != "" && w.JobsBucket == "" {
+		return fmt.Errorf("JOBS_INPUT_BUCKET is required when JOBS_HTTP_PORT is set")
+	}
+	if err := domain.ValidateTranscriptionProvider(w.TranscriptionProvider); err != nil {
+		return fmt.Errorf("TRANSCRIPTION_PROVIDER: %w", err)
+	}
+	if w.TranscriptionProvider == domain.TranscriptionProviderQueue && w.TranscriptionQueue == "" {
+		return fmt.Errorf("TRANSCRIPTION_QUEUE is required when TRANSCRIPTION_PROVIDER=queue")
+	}
+	if err := domain.ValidateResultFormat(w.ResultFormat); err != nil {
+		return fmt.Errorf("RESULT_FORMAT: %w", err)
+	}
+	switch w.SigningMode {
+	case "", "hmac":
+	case "kms":
+		if w.SigningKeyID == "" {
+			return fmt.Errorf("RESULT_SIGNING_KEY_ID is required for kms signing")
+		}
+	default:
+		return fmt.Errorf("unsupported RESULT_SIGNING mode: %s", w.SigningMode)
+	}
+	switch w.Lease.Backend {
+	case "", LeaseKubernetes:
+	case LeaseDynamoDB:
+		if w.Lease.DynamoDBTable == "" {
+			return fmt.Errorf("LEASE_DYNAMODB_TABLE is required with LEASE_BACKEND=dynamodb")
+		}
+	default:
+		return fmt.Errorf("invalid LEASE_BACKEND %q, expected dynamodb or kubernetes", w.Lease.Backend)
+	}
+
+	// The worker's own buckets stay on its role, whatever a tenant maps
+	for _, bucket := range []string{w.OutputBucket, w.PayloadBucket, w.JobsBucket, c.RemoteBucket} {
+		if _, ok := c.OutputRoles[bucket]; ok && bucket != "" {
+			return fmt.Errorf("tenant output role maps the worker bucket %s", bucket)
+		}
+	}
+	// Any tenant may write to ALLOWED_OUTPUTS, so a bucket there cannot be owned by one
+	for bucket := range c.OutputRoles {
+		if w.Outputs.HasBucket(bucket) {
+			return fmt.Errorf("tenant output role maps the bucket %s of ALLOWED_OUTPUTS", bucket)
+		}
+	}
+	return nil
+}
+
+// stageTimeouts reads DOWNLOAD_TIMEOUT, PROCESSING_TIMEOUT and UPLOAD_TIMEOUT; 0 disables one
+func stageTimeouts() (domain.StageTimeouts, error) {
+	var timeouts domain.StageTimeouts
+	for _, stage := range []struct {
+		env          string
+		defaultValue string
+		timeout      *time.Duration
+	}{
+		{"DOWNLOAD_TIMEOUT", "10m", &timeouts.Download},
+		{"PROCESSING_TIMEOUT", "30m", &timeouts.Processing},
+		{"UPLOAD_TIMEOUT", "10m", &timeouts.Upload},
+	} {
+		timeout, err := time.ParseDuration(getEnv(stage.env, stage.defaultValue))
+		if err != nil {
+			return domain.StageTimeouts{}, fmt.Errorf("invalid %s: %w", stage.env, err)
+		}
+		*stage.timeout = timeout
+	}
+	return timeouts, timeouts.Validate()
+}
+
+// costModelFromEnv reads the COST_* prices used to estimate the cost of each job; unset ones are zero
+func costModelFromEnv() (domain.CostModel, error) {
+	var model domain.CostModel
+	for _, price := range []struct {
+		env   string
+		value *float64
+	}{
+		{"COST_S3_GET_PER_1000", &model.S3GetPer1000},
+		{"COST_S3_PUT_PER_1000", &model.S3PutPer1000},
+		{"COST_TRANSFER_PER_GB", &model.TransferPerGB},
+		{"COST_CPU_PER_HOUR", &model.CPUPerHour},
+	} {
+		value, err := strconv.ParseFloat(getEnv(price.env, "0"), 64)
+		if err != nil {
+			return domain.CostModel{}, fmt.Errorf("invalid %s: %w", price.env, err)
+		}
+		*price.value = value
+	}
+	return model, model.Validate()
+}
+
+// killSwitchConfig reads when the kill switch pauses the consumption: KILL_SWITCH_FAILURE_RATE
+// of the messages handled within KILL_SWITCH_WINDOW (5m by default) fail, once at least
+// KILL_SWITCH_MIN_MESSAGES (10 by default) were handled. Without the rate it is disabled and
+// the config is zero
+func killSwitchConfig() (consumer.KillSwitchConfig, error) {
+	var config consumer.KillSwitchConfig
+	rate := os.Getenv("KILL_SWITCH_FAILURE_RATE")
+	if rate == "" {
+		return config, nil
+	}
+
+	var err error
+	config.FailureRate, err = strconv.ParseFloat(rate, 64)
+	if err != nil || config.FailureRate <= 0 || config.FailureRate > 1 {
+		return config, fmt.Errorf("invalid KILL_SWITCH_FAILURE_RATE %q: must be over 0 and up to 1", rate)
+	}
+	config.Window, err = time.ParseDuration(getEnv("KILL_SWITCH_WINDOW", "5m"))
+	if err != nil || config.Window <= 0 {
+		return config, fmt.Errorf("invalid KILL_SWITCH_WINDOW %q", os.Getenv("KILL_SWITCH_WINDOW"))
+	}
+	config.MinMessages, err = strconv.Atoi(getEnv("KILL_SWITCH_MIN_MESSAGES", "10"))
+	if err != nil || config.MinMessages < 1 {
+		return config, fmt.Errorf("invalid KILL_SWITCH_MIN_MESSAGES %q", os.Getenv("KILL_SWITCH_MIN_MESSAGES"))
+	}
+	return config, nil
+}
+
+// logSinksConfig reads the batching of the log sinks (LOG_BATCH_SIZE, LOG_FLUSH_INTERVAL) and
+// the Loki labels (LOG_LOKI_LABELS)
+func logSinksConfig(config LogSinksConfig) (LogSinksConfig, error) {
+	batchSize, err := strconv.Atoi(getEnv("LOG_BATCH_SIZE", strconv.Itoa(logsink.DefaultBatchSize)))
+	if err != nil {
+		return config, fmt.Errorf("invalid LOG_BATCH_SIZE: %w", err)
+	}
+	flushInterval, err := time.ParseDuration(getEnv("LOG_FLUSH_INTERVAL", logsink.DefaultFlushInterval.String()))
+	if err != nil {
+		return config, fmt.Errorf("invalid LOG_FLUSH_INTERVAL: %w", err)
+	}
+	config.Batching = logsink.Config{BatchSize: batchSize, FlushInterval: flushInterval}
+	if config.LokiURL != "" {
+		config.LokiLabels, err = logsink.ParseLabels(getEnv("LOG_LOKI_LABELS", "job=video-processor"))
+		if err != nil {
+			return config, fmt.Errorf("invalid LOG_LOKI_LABELS: %w", err)
+		}
+	}
+	return config, nil
+}
+
+// ffmpegRequirements reads where ffmpeg/ffprobe are (FFMPEG_PATH/FFPROBE_PATH, or PATH), the
+// version range (FFMPEG_MIN_VERSION/FFMPEG_MAX_VERSION) and the required encoders and filters
+func ffmpegRequirements() (ffmpeg.Requirements, error) {
+	minVersion, err := ffmpeg.ParseVersionString(getEnv("FFMPEG_MIN_VERSION", "4.0"))
+	if err != nil {
+		return ffmpeg.Requirements{}, fmt.Errorf("invalid FFMPEG_MIN_VERSION: %w", err)
+	}
+
+	req := ffmpeg.Requirements{
+		FFmpegPath:  os.Getenv("FFMPEG_PATH"),
+		FFprobePath: os.Getenv("FFPROBE_PATH"),
+		MinVersion:  minVersion,
+		Encoders:    splitList(getEnv("FFMPEG_REQUIRED_ENCODERS", "png")),
+		Filters:     splitList(getEnv("FFMPEG_REQUIRED_FILTERS", "fps,scale,pad,transpose")),
+	}
+	if value := os.Getenv("FFMPEG_MAX_VERSION"); value != "" {
+		maxVersion, err := ffmpeg.ParseVersionString(value)
+		if err != nil {
+			return ffmpeg.Requirements{}, fmt.Errorf("invalid FFMPEG_MAX_VERSION: %w", err)
+		}
+		req.MaxVersion = &maxVersion
+	}
+	return req, nil
+}
+
+// splitList parses a comma-separated list, ignoring blanks
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
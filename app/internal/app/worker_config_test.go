@@ -0,0 +1,197 @@
+package app
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+)
+
+func TestWorkerConfigFromEnv(t *testing.T) {
+	t.Setenv("QUEUE_INPUT", "input")
+	t.Setenv("STORAGE_OUTPUT", "output")
+	t.Setenv("PAYLOAD_OFFLOAD_BUCKET", "")
+	t.Setenv("VIDEO_URL_ALLOWED_HOSTS", " cdn.example.com, ,media.example.com")
+	t.Setenv("MAINTENANCE_FILE", "")
+	t.Setenv("KILL_SWITCH_FAILURE_RATE", "")
+	t.Setenv("INPUT_PRODUCERS", `{"team-a":{"api_key":"key-a"}}`)
+
+	config, err := ConfigFromEnv()
+	if err != nil {
+		t.Fatalf("ConfigFromEnv failed: %v", err)
+	}
+	worker := config.Worker
+	if worker.InputQueue != "input" || worker.OutputBucket != "output" {
+		t.Errorf("Unexpected queues and buckets %+v", worker)
+	}
+	if worker.PayloadBucket != "" {
+		t.Errorf("Expected no payload bucket, got %s", worker.PayloadBucket)
+	}
+	if len(worker.VideoURLHosts) != 2 || worker.VideoURLHosts[0] != "cdn.example.com" || worker.VideoURLHosts[1] != "media.example.com" {
+		t.Errorf("Unexpected video_url hosts %v", worker.VideoURLHosts)
+	}
+	if worker.MaxAttempts != 1 || worker.DedupTTL != time.Hour || worker.PayloadThreshold != sqsMaxPayloadBytes {
+		t.Errorf("Expected the defaults, got %d attempts, dedup %v and threshold %d", worker.MaxAttempts, worker.DedupTTL, worker.PayloadThreshold)
+	}
+	if worker.KillSwitch.FailureRate != 0 {
+		t.Errorf("Expected the kill switch disabled, got %+v", worker.KillSwitch)
+	}
+	if worker.Maintenance.Interval != 0 || worker.Maintenance.Active() {
+		t.Errorf("Expected a fixed maintenance off, got %+v", worker.Maintenance)
+	}
+	if len(worker.InputProducers) != 1 || worker.InputProducers["team-a"].APIKey != "key-a" {
+		t.Errorf("Unexpected producers %v", worker.InputProducers)
+	}
+	if worker.FFmpeg.MinVersion.String() == "" || len(worker.FFmpeg.Filters) != 4 {
+		t.Errorf("Unexpected ffmpeg requirements %+v", worker.FFmpeg)
+	}
+}
+
+func TestWorkerConfigFromEnv_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"ALLOWED_SOURCES":            "/videos/",
+		"MAX_VIDEO_BYTES":            "-1",
+		"JOB_MAX_ATTEMPTS":           "0",
+		"JOB_SLA":                    "soon",
+		"PROCESSING_TIMEOUT":         "long",
+		"COST_CPU_PER_HOUR":          "free",
+		"PROGRESS_INTERVAL":          "0s",
+		"MESSAGE_DEDUP_TTL":          "-1h",
+		"MAINTENANCE_CHECK_INTERVAL": "soon",
+		"LEASE_DURATION":             "0s",
+		"METRICS_MAX_TENANTS":        "-1",
+		"SLO_OBJECTIVE":              "1",
+		"LOG_BATCH_SIZE":             "many",
+		"FFMPEG_MIN_VERSION":         "latest",
+		"INPUT_PRODUCERS":            "{",
+	}
+	for env, value := range tests {
+		t.Run(env, func(t *testing.T) {
+			t.Setenv(env, value)
+			if _, err := ConfigFromEnv(); err == nil {
+				t.Errorf("Expected %s=%s rejected", env, value)
+			}
+		})
+	}
+}
+
+func TestKillSwitchConfig(t *testing.T) {
+	t.Setenv("KILL_SWITCH_FAILURE_RATE", "")
+	if config, err := killSwitchConfig(); config.FailureRate != 0 || err != nil {
+		t.Errorf("Expected no kill switch without a rate, got %+v (%v)", config, err)
+	}
+
+	t.Setenv("KILL_SWITCH_FAILURE_RATE", "0.8")
+	config, err := killSwitchConfig()
+	if err != nil {
+		t.Fatalf("Expected a kill switch with the defaults, got %v", err)
+	}
+	if config.FailureRate != 0.8 || config.Window != 5*time.Minute || config.MinMessages != 10 {
+		t.Errorf("Unexpected kill switch %+v", config)
+	}
+
+	for env, value := range map[string]string{
+		"KILL_SWITCH_FAILURE_RATE": "80%",
+		"KILL_SWITCH_WINDOW":       "0s",
+		"KILL_SWITCH_MIN_MESSAGES": "0",
+	} {
+		t.Run(env, func(t *testing.T) {
+			t.Setenv(env, value)
+			if _, err := killSwitchConfig(); err == nil {
+				t.Errorf("Expected %s=%s rejected", env, value)
+			}
+		})
+	}
+}
+
+func TestMaintenanceConfig(t *testing.T) {
+	flag := filepath.Join(t.TempDir(), "maintenance")
+	t.Setenv("MAINTENANCE_MODE", "")
+	t.Setenv("MAINTENANCE_FILE", flag)
+	t.Setenv("MAINTENANCE_CHECK_INTERVAL", "")
+
+	config, err := ConfigFromEnv()
+	if err != nil {
+		t.Fatalf("ConfigFromEnv failed: %v", err)
+	}
+	maintenance := config.Worker.Maintenance
+	if maintenance.Interval != 5*time.Second {
+		t.Errorf("Expected the flag file checked every 5s, got %v", maintenance.Interval)
+	}
+	if maintenance.Active() {
+		t.Error("Expected no maintenance without the flag file")
+	}
+	if err := os.WriteFile(flag, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if !maintenance.Active() {
+		t.Error("Expected maintenance while the flag file exists")
+	}
+
+	t.Setenv("MAINTENANCE_MODE", "true")
+	t.Setenv("MAINTENANCE_FILE", "")
+	config, _ = ConfigFromEnv()
+	if maintenance := config.Worker.Maintenance; !maintenance.Active() || maintenance.Interval != 0 {
+		t.Error("Expected a fixed maintenance without checks")
+	}
+}
+
+func TestConfig_ValidateWorker(t *testing.T) {
+	valid := Config{
+		MessageTransport: TransportSQS,
+		ZipCompression:   domain.ArchiveOptions{Method: domain.ZipMethodAuto},
+		OutputRoles:      map[string]domain.OutputRoute{"acme-frames": {}},
+		Worker:           WorkerConfig{InputQueue: "input", OutputQueue: "results", OutputBucket: "output"},
+	}
+	if err := valid.ValidateWorker(); err != nil {
+		t.Fatalf("Expected a valid configuration, got %v", err)
+	}
+
+	tests := map[string]func(*Config){
+		"no input":               func(c *Config) { c.Worker.InputQueue = "" },
+		"no output queue":        func(c *Config) { c.Worker.OutputQueue = "" },
+		"no output bucket":       func(c *Config) { c.Worker.OutputBucket = "" },
+		"invalid config":         func(c *Config) { c.MessageTransport = "kafka" },
+		"memory without demo":    func(c *Config) { c.MessageTransport = TransportMemory },
+		"jobs without bucket":    func(c *Config) { c.Worker.JobsPort = "8081" },
+		"encryption without key": func(c *Config) { c.Worker.EncryptResults = true },
+		"kms without key":        func(c *Config) { c.Worker.SigningMode = "kms" },
+		"unknown lease backend":  func(c *Config) { c.Worker.Lease.Backend = "etcd" },
+		"dynamodb without table": func(c *Config) { c.Worker.Lease.Backend = LeaseDynamoDB },
+		"worker bucket of a tenant": func(c *Config) {
+			c.Worker.PayloadBucket = "acme-frames"
+		},
+		"allowed output of a tenant": func(c *Config) {
+			c.Worker.Outputs, _ = domain.ParseSourceAllowlist("acme-frames")
+		},
+	}
+	for name, change := range tests {
+		t.Run(name, func(t *testing.T) {
+			config := valid
+			change(&config)
+			if err := config.ValidateWorker(); err == nil {
+				t.Errorf("Expected %s rejected", name)
+			}
+		})
+	}
+
+	// The demo runs on the in-memory transport
+	demo := valid
+	demo.MessageTransport, demo.Worker.DemoDir = TransportMemory, "demo"
+	if err := demo.ValidateWorker(); err != nil {
+		t.Errorf("Expected the demo accepted, got %v", err)
+	}
+}
+
+func TestSplitList(t *testing.T) {
+	items := splitList(" png, mjpeg ,,fps ")
+
+	if len(items) != 3 || items[0] != "png" || items[1] != "mjpeg" || items[2] != "fps" {
+		t.Errorf("Unexpected items %v", items)
+	}
+	if items := splitList(""); len(items) != 0 {
+		t.Errorf("Expected no items, got %v", items)
+	}
+}
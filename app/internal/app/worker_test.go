@@ -0,0 +1,129 @@
+package app
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/consumer"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/observability"
+)
+
+// localWorkerConfig runs a worker without cloud services
+func localWorkerConfig(t *testing.T) Config {
+	config := localConfig(t)
+	config.Region = "us-east-1"
+	config.Worker = WorkerConfig{
+		InputQueue:   "input",
+		OutputQueue:  "results",
+		OutputBucket: "output",
+		MaxAttempts:  1,
+		DedupTTL:     time.Hour,
+	}
+	return config
+}
+
+func TestContainer_ProcessVideoUseCase(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	config := localWorkerConfig(t)
+	config.Worker.OutboxDir = t.TempDir()
+	container := New(config)
+	defer container.Close()
+
+	useCase, err := container.ProcessVideoUseCase(context.Background())
+	if err != nil {
+		t.Fatalf("ProcessVideoUseCase failed: %v", err)
+	}
+	if again, _ := container.ProcessVideoUseCase(context.Background()); again != useCase {
+		t.Error("Expected the same use case on every call")
+	}
+	if dispatcher, err := container.OutboxDispatcher(context.Background()); dispatcher == nil || err != nil {
+		t.Errorf("Expected the outbox dispatched, got %v", err)
+	}
+
+	// A result format the worker does not know fails the wiring
+	invalid := localWorkerConfig(t)
+	invalid.Worker.ResultFormat = "xml"
+	invalidContainer := New(invalid)
+	defer invalidContainer.Close()
+	if _, err := invalidContainer.ProcessVideoUseCase(context.Background()); err == nil {
+		t.Error("Expected an unknown result format rejected")
+	}
+}
+
+func TestContainer_MessageRouter(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	container := New(localWorkerConfig(t))
+	defer container.Close()
+
+	router, err := container.MessageRouter(context.Background())
+	if err != nil {
+		t.Fatalf("MessageRouter failed: %v", err)
+	}
+	if again, _ := container.MessageRouter(context.Background()); again != router {
+		t.Error("Expected the same router on every call")
+	}
+	if err := router.Route(context.Background(), `{"type":"ping"}`, map[string]string{domain.MessageTypeAttribute: domain.MessageTypePing}); err != nil {
+		t.Errorf("Expected the ping handled, got %v", err)
+	}
+}
+
+func TestContainer_InputConsumers(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	config := localWorkerConfig(t)
+	config.Worker.KillSwitch = consumer.KillSwitchConfig{FailureRate: 0.5, Window: time.Minute, MinMessages: 10}
+	config.Worker.Maintenance = MaintenanceConfig{Enabled: true}
+	container := New(config)
+	defer container.Close()
+
+	inputs, run, err := container.InputConsumers(context.Background())
+	if err != nil {
+		t.Fatalf("InputConsumers failed: %v", err)
+	}
+	if len(inputs) != 1 {
+		t.Fatalf("Expected the input queue consumed, got %d consumers", len(inputs))
+	}
+	if run.KillSwitch == nil || run.Maintenance == nil || run.Elector != nil {
+		t.Errorf("Unexpected run config %+v", run)
+	}
+	if !run.MaintenanceActive() {
+		t.Error("Expected the maintenance of the config")
+	}
+
+	// Without a rate the kill switch is disabled
+	disabled := New(localWorkerConfig(t))
+	defer disabled.Close()
+	if _, run, err := disabled.InputConsumers(context.Background()); err != nil || run.KillSwitch != nil {
+		t.Errorf("Expected no kill switch, got %v (%v)", run.KillSwitch, err)
+	}
+}
+
+func TestContainer_JobSubmitter(t *testing.T) {
+	config := localWorkerConfig(t)
+	config.Worker.JobsBucket = "input"
+	container := New(config)
+	defer container.Close()
+	if submitter, err := container.JobSubmitter(context.Background()); submitter == nil || err != nil {
+		t.Errorf("Expected an anonymous submitter, got %v", err)
+	}
+
+	// With input authentication the endpoint needs a producer with an api_key
+	signed := localWorkerConfig(t)
+	signed.Worker.InputSigningSecret = "secret"
+	signed.Worker.InputProducers = domain.ProducerRegistry{"team-a": {SigningSecret: "secret-a"}}
+	signedContainer := New(signed)
+	defer signedContainer.Close()
+	if _, err := signedContainer.JobSubmitter(context.Background()); err == nil {
+		t.Error("Expected the jobs endpoint rejected without an api_key")
+	}
+}
@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// SupportedArchiveEncodings are the archive encodings the worker produces, in its order of
+// preference
+var SupportedArchiveEncodings = []string{ArchiveEncodingZip, ArchiveEncodingTarGz}
+
+// SupportedImageFormats are the frame formats the worker encodes
+var SupportedImageFormats = []string{ImageFormatPNG, ImageFormatJPEG, ImageFormatWebP}
+
+// AcceptOptions are the archive encodings and image formats the requester can read, in its
+// order of preference; an empty list accepts whatever the request otherwise selects
+type AcceptOptions struct {
+	Encodings    []string `json:"encodings,omitempty"`
+	ImageFormats []string `json:"image_formats,omitempty"`
+}
+
+func (a AcceptOptions) IsZero() bool {
+	return len(a.Encodings) == 0 && len(a.ImageFormats) == 0
+}
+
+// NegotiatedOutput records the encoding and image format picked from AcceptOptions
+type NegotiatedOutput struct {
+	Encoding    string `json:"encoding,omitempty"`
+	ImageFormat string `json:"image_format,omitempty"`
+}
+
+// Negotiate picks the first accepted encoding among encodings and the first accepted image
+// format among imageFormats. A list with no overlap fails with not_acceptable
+func (a AcceptOptions) Negotiate(encodings, imageFormats []string) (NegotiatedOutput, error) {
+	var negotiated NegotiatedOutput
+	if len(a.Encodings) > 0 {
+		negotiated.Encoding = firstAccepted(a.Encodings, encodings)
+		if negotiated.Encoding == "" {
+			return negotiated, NewCodedError(ErrorCodeNotAcceptable,
+				fmt.Errorf("none of the accepted encodings %s is supported, expected one of %s", strings.Join(a.Encodings, ", "), strings.Join(encodings, ", ")))
+		}
+	}
+	if len(a.ImageFormats) > 0 {
+		negotiated.ImageFormat = firstAccepted(a.ImageFormats, imageFormats)
+		if negotiated.ImageFormat == "" {
+			return negotiated, NewCodedError(ErrorCodeNotAcceptable,
+				fmt.Errorf("none of the accepted image formats %s is supported, expected one of %s", strings.Join(a.ImageFormats, ", "), strings.Join(imageFormats, ", ")))
+		}
+	}
+	return negotiated, nil
+}
+
+func firstAccepted(accepted, supported []string) string {
+	for _, value := range accepted {
+		if slices.Contains(supported, strings.ToLower(strings.TrimSpace(value))) {
+			return strings.ToLower(strings.TrimSpace(value))
+		}
+	}
+	return ""
+}
@@ -0,0 +1,33 @@
+package domain
+
+import "testing"
+
+func TestAcceptOptions_Negotiate(t *testing.T) {
+	accept := AcceptOptions{Encodings: []string{"none", "TAR.GZ", "zip"}, ImageFormats: []string{"avif", "webp"}}
+	negotiated, err := accept.Negotiate(SupportedArchiveEncodings, SupportedImageFormats)
+	if err != nil {
+		t.Fatalf("Negotiate failed: %v", err)
+	}
+	if negotiated.Encoding != ArchiveEncodingTarGz || negotiated.ImageFormat != ImageFormatWebP {
+		t.Errorf("Expected the first supported values, got %+v", negotiated)
+	}
+
+	// A list left empty accepts anything and is not negotiated
+	negotiated, err = AcceptOptions{ImageFormats: []string{"png"}}.Negotiate(SupportedArchiveEncodings, SupportedImageFormats)
+	if err != nil || negotiated != (NegotiatedOutput{ImageFormat: ImageFormatPNG}) {
+		t.Errorf("Expected only the image format negotiated, got %+v (%v)", negotiated, err)
+	}
+}
+
+func TestAcceptOptions_NegotiateNoOverlap(t *testing.T) {
+	tests := map[string]AcceptOptions{
+		"encodings":     {Encodings: []string{ArchiveEncodingNone}},
+		"image formats": {Encodings: []string{ArchiveEncodingZip}, ImageFormats: []string{"avif"}},
+	}
+	for name, accept := range tests {
+		_, err := accept.Negotiate(SupportedArchiveEncodings, SupportedImageFormats)
+		if ErrorCode(err) != ErrorCodeNotAcceptable {
+			t.Errorf("%s: expected %s, got %v", name, ErrorCodeNotAcceptable, err)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package domain
+
+import "fmt"
+
+const (
+	DefaultBlurStrength = 20
+	MaxBlurStrength     = 100
+	MaxBlurRegions      = 20
+)
+
+// BlurRegion is a rectangle in fractions of the frame size (0 to 1), so the same region
+// works for any resolution
+type BlurRegion struct {
+	X      float64 `json:"x"`
+	Y      float64 `json:"y"`
+	Width  float64 `json:"width"`
+	Height float64 `json:"height"`
+}
+
+// AnonymizeOptions lists the regions blurred on every frame before zipping, e.g. where
+// faces or plates appear
+type AnonymizeOptions struct {
+	Regions  []BlurRegion `json:"regions"`
+	Strength int          `json:"strength"`
+}
+
+func (o AnonymizeOptions) Enabled() bool {
+	return len(o.Regions) > 0
+}
+
+// BlurStrength returns the blur radius in pixels, defaulting to DefaultBlurStrength
+func (o AnonymizeOptions) BlurStrength() int {
+	if o.Strength == 0 {
+		return DefaultBlurStrength
+	}
+	return o.Strength
+}
+
+func (o AnonymizeOptions) Validate() error {
+	if len(o.Regions) > MaxBlurRegions {
+		return fmt.Errorf("at most %d blur regions are allowed", MaxBlurRegions)
+	}
+	if o.Strength < 0 || o.Strength > MaxBlurStrength {
+		return fmt.Errorf("blur strength must be between 1 and %d", MaxBlurStrength)
+	}
+	for i, region := range o.Regions {
+		if region.X < 0 || region.Y < 0 || region.Width <= 0 || region.Height <= 0 ||
+			region.X+region.Width > 1 || region.Y+region.Height > 1 {
+			return fmt.Errorf("invalid blur region %d: must fit within the frame (fractions from 0 to 1)", i)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,34 @@
+package domain
+
+import "testing"
+
+func TestAnonymizeOptions_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		options AnonymizeOptions
+		wantErr bool
+	}{
+		{"disabled", AnonymizeOptions{}, false},
+		{"valid region", AnonymizeOptions{Regions: []BlurRegion{{X: 0.1, Y: 0.2, Width: 0.3, Height: 0.4}}, Strength: 10}, false},
+		{"full frame", AnonymizeOptions{Regions: []BlurRegion{{Width: 1, Height: 1}}}, false},
+		{"outside the frame", AnonymizeOptions{Regions: []BlurRegion{{X: 0.8, Width: 0.3, Height: 0.1}}}, true},
+		{"empty region", AnonymizeOptions{Regions: []BlurRegion{{X: 0.1, Y: 0.1}}}, true},
+		{"negative origin", AnonymizeOptions{Regions: []BlurRegion{{X: -0.1, Width: 0.2, Height: 0.2}}}, true},
+		{"strength too high", AnonymizeOptions{Strength: MaxBlurStrength + 1}, true},
+		{"too many regions", AnonymizeOptions{Regions: make([]BlurRegion, MaxBlurRegions+1)}, true},
+	}
+	for _, tt := range tests {
+		if err := tt.options.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("%s: expected error %v, got %v", tt.name, tt.wantErr, err)
+		}
+	}
+}
+
+func TestAnonymizeOptions_BlurStrength(t *testing.T) {
+	if strength := (AnonymizeOptions{}).BlurStrength(); strength != DefaultBlurStrength {
+		t.Errorf("Expected default strength %d, got %d", DefaultBlurStrength, strength)
+	}
+	if strength := (AnonymizeOptions{Strength: 5}).BlurStrength(); strength != 5 {
+		t.Errorf("Expected strength 5, got %d", strength)
+	}
+}
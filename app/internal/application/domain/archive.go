@@ -0,0 +1,12 @@
+package domain
+
+import "errors"
+
+const (
+	// ZipMaxEntries and ZipMaxSize are the limits of a zip without Zip64 records
+	ZipMaxEntries = 1<<16 - 1
+	ZipMaxSize    = 1<<32 - 1
+)
+
+// ErrArchiveLimit means the output does not fit the configured archive format
+var ErrArchiveLimit = errors.New("output exceeds the archive format limits")
@@ -0,0 +1,103 @@
+package domain
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	ZipMethodAuto    = "auto"
+	ZipMethodStore   = "store"
+	ZipMethodDeflate = "deflate"
+)
+
+// Encodings of the output archives. none stands for the files uploaded one by one, which a
+// requester may accept but the worker does not produce
+const (
+	ArchiveEncodingZip   = "zip"
+	ArchiveEncodingTarGz = "tar.gz"
+	ArchiveEncodingNone  = "none"
+)
+
+// Already compressed formats gain nothing from Deflate, only CPU time
+var compressedExtensions = map[string]bool{
+	".png":  true,
+	".jpg":  true,
+	".jpeg": true,
+	".webp": true,
+}
+
+// ArchiveOptions selects how files are stored in the output zips. auto stores compressed
+// images and deflates the rest (VTT, JSON); Level (1-9) applies to deflated entries, zero
+// meaning the default level. Encoding tar.gz writes gzipped tarballs instead, where the
+// method does not apply and Level is the gzip level; empty means zip
+type ArchiveOptions struct {
+	Method   string `json:"method"`
+	Level    int    `json:"level"`
+	Encoding string `json:"encoding,omitempty"`
+}
+
+// Or returns the options, taking the method of fallback when no method was requested and its
+// level when no level was either. A requested level falls back to auto rather than store, which
+// would ignore it. The requested encoding is kept either way
+func (o ArchiveOptions) Or(fallback ArchiveOptions) ArchiveOptions {
+	if o.Method == "" {
+		o.Method = fallback.Method
+		if o.Level == 0 {
+			o.Level = fallback.Level
+		} else if o.Method == ZipMethodStore {
+			o.Method = ZipMethodAuto
+		}
+	}
+	if o.Encoding == "" {
+		o.Encoding = fallback.Encoding
+	}
+	return o
+}
+
+// TarGz reports whether the archives are gzipped tarballs
+func (o ArchiveOptions) TarGz() bool {
+	return o.Encoding == ArchiveEncodingTarGz
+}
+
+// Extension is the file extension of the archives, with the leading dot
+func (o ArchiveOptions) Extension() string {
+	if o.TarGz() {
+		return ".tar.gz"
+	}
+	return ".zip"
+}
+
+// Deflate reports whether the file should be deflated
+func (o ArchiveOptions) Deflate(name string) bool {
+	switch o.Method {
+	case ZipMethodStore:
+		return false
+	case ZipMethodDeflate:
+		return true
+	default:
+		return !compressedExtensions[strings.ToLower(filepath.Ext(name))]
+	}
+}
+
+func (o ArchiveOptions) Validate() error {
+	switch o.Encoding {
+	case "", ArchiveEncodingZip, ArchiveEncodingTarGz:
+	default:
+		return fmt.Errorf("unsupported archive encoding: %s", o.Encoding)
+	}
+	switch o.Method {
+	case "", ZipMethodAuto, ZipMethodDeflate:
+	case ZipMethodStore:
+		if o.Level != 0 {
+			return fmt.Errorf("zip level requires the deflate or auto method")
+		}
+	default:
+		return fmt.Errorf("unsupported zip method: %s", o.Method)
+	}
+	if o.Level < 0 || o.Level > 9 {
+		return fmt.Errorf("zip level must be between 1 and 9")
+	}
+	return nil
+}
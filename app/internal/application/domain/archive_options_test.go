@@ -0,0 +1,77 @@
+package domain
+
+import "testing"
+
+func TestArchiveOptions_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		options ArchiveOptions
+		wantErr bool
+	}{
+		{"default", ArchiveOptions{}, false},
+		{"store", ArchiveOptions{Method: ZipMethodStore}, false},
+		{"deflate with level", ArchiveOptions{Method: ZipMethodDeflate, Level: 1}, false},
+		{"auto with level", ArchiveOptions{Method: ZipMethodAuto, Level: 9}, false},
+		{"store with level", ArchiveOptions{Method: ZipMethodStore, Level: 5}, true},
+		{"level too high", ArchiveOptions{Method: ZipMethodDeflate, Level: 10}, true},
+		{"unknown method", ArchiveOptions{Method: "bzip2"}, true},
+	}
+	for _, tt := range tests {
+		if err := tt.options.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("%s: expected error %v, got %v", tt.name, tt.wantErr, err)
+		}
+	}
+}
+
+func TestArchiveOptions_Deflate(t *testing.T) {
+	auto := ArchiveOptions{Method: ZipMethodAuto}
+	if auto.Deflate("frame_0001.PNG") || auto.Deflate("frame_0001.jpg") {
+		t.Error("Expected auto to store compressed images")
+	}
+	if !auto.Deflate("thumbnails.vtt") || !(ArchiveOptions{}).Deflate("manifest.json") {
+		t.Error("Expected auto to deflate text files")
+	}
+	if (ArchiveOptions{Method: ZipMethodStore}).Deflate("manifest.json") {
+		t.Error("Expected store to never deflate")
+	}
+	if !(ArchiveOptions{Method: ZipMethodDeflate}).Deflate("frame_0001.png") {
+		t.Error("Expected deflate to always deflate")
+	}
+}
+
+func TestArchiveOptions_Or(t *testing.T) {
+	fallback := ArchiveOptions{Method: ZipMethodStore}
+	if got := (ArchiveOptions{}).Or(fallback); got != fallback {
+		t.Errorf("Expected fallback, got %+v", got)
+	}
+	requested := ArchiveOptions{Method: ZipMethodDeflate, Level: 1}
+	if got := requested.Or(fallback); got != requested {
+		t.Errorf("Expected requested options, got %+v", got)
+	}
+
+	// A level requested without a method is kept
+	if got := (ArchiveOptions{Level: 9}).Or(ArchiveOptions{Method: ZipMethodDeflate, Level: 3}); got != (ArchiveOptions{Method: ZipMethodDeflate, Level: 9}) {
+		t.Errorf("Expected the requested level with the worker method, got %+v", got)
+	}
+	if got := (ArchiveOptions{Level: 9}).Or(fallback); got != (ArchiveOptions{Method: ZipMethodAuto, Level: 9}) {
+		t.Errorf("Expected auto instead of store for a requested level, got %+v", got)
+	}
+}
+
+func TestArchiveOptions_Encoding(t *testing.T) {
+	if err := (ArchiveOptions{Encoding: ArchiveEncodingTarGz, Level: 9}).Validate(); err != nil {
+		t.Errorf("Expected tar.gz valid, got %v", err)
+	}
+	if err := (ArchiveOptions{Encoding: "rar"}).Validate(); err == nil {
+		t.Error("Expected an unknown encoding rejected")
+	}
+	if (ArchiveOptions{}).Extension() != ".zip" || (ArchiveOptions{Encoding: ArchiveEncodingTarGz}).Extension() != ".tar.gz" {
+		t.Error("Unexpected extensions")
+	}
+
+	// The worker compression applies to a request that only chose the encoding
+	got := (ArchiveOptions{Encoding: ArchiveEncodingTarGz}).Or(ArchiveOptions{Method: ZipMethodDeflate, Level: 3})
+	if got != (ArchiveOptions{Method: ZipMethodDeflate, Level: 3, Encoding: ArchiveEncodingTarGz}) {
+		t.Errorf("Expected the requested encoding kept, got %+v", got)
+	}
+}
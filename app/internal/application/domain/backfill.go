@@ -0,0 +1,19 @@
+package domain
+
+// BackfillFailure is a video the backfill could not enqueue
+type BackfillFailure struct {
+	Key   string `json:"key"`
+	Error string `json:"error"`
+}
+
+// BackfillReport summarizes a backfill run
+type BackfillReport struct {
+	Bucket          string            `json:"bucket"`
+	Prefix          string            `json:"prefix"`
+	Listed          int               `json:"listed"`
+	Skipped         int               `json:"skipped"`
+	Enqueued        int               `json:"enqueued"`
+	Failed          int               `json:"failed"`
+	Failures        []BackfillFailure `json:"failures,omitempty"`
+	DurationSeconds float64           `json:"duration_seconds"`
+}
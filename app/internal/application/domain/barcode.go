@@ -0,0 +1,64 @@
+package domain
+
+import "fmt"
+
+const (
+	// MaxBarcodeFrames bounds the frames scanned for barcodes; longer videos are scanned up to it
+	MaxBarcodeFrames = 3600
+
+	maxBarcodeInterval = 60
+)
+
+// BarcodeOptions scans frames sampled from the video for QR codes and barcodes, reported in
+// the result of the frames output
+type BarcodeOptions struct {
+	Scan bool `json:"scan"`
+
+	// IntervalSeconds is the time between two scanned frames; zero means one second
+	IntervalSeconds float64 `json:"interval_seconds"`
+}
+
+// Interval returns the time between two scanned frames
+func (o BarcodeOptions) Interval() float64 {
+	if o.IntervalSeconds == 0 {
+		return 1
+	}
+	return o.IntervalSeconds
+}
+
+func (o BarcodeOptions) Validate() error {
+	if !o.Scan && o.IntervalSeconds != 0 {
+		return fmt.Errorf("barcodes.interval_seconds requires barcodes.scan")
+	}
+	if o.IntervalSeconds < 0 || o.IntervalSeconds > maxBarcodeInterval {
+		return fmt.Errorf("barcodes interval must be between 0 and %d seconds, got %v", maxBarcodeInterval, o.IntervalSeconds)
+	}
+	return nil
+}
+
+// ValidateBarcodes checks the barcode fields of a request; the scan runs on a single video of
+// the frames output
+func (v VideoProcess) ValidateBarcodes() error {
+	if err := v.Barcodes.Validate(); err != nil {
+		return err
+	}
+	if !v.Barcodes.Scan {
+		return nil
+	}
+	if v.OutputType != "" && v.OutputType != OutputTypeFrames {
+		return fmt.Errorf("barcode scanning is not supported for %s output", v.OutputType)
+	}
+	if len(v.VideoKeys) > 0 {
+		return fmt.Errorf("barcode scanning is not supported for batches")
+	}
+	return nil
+}
+
+// BarcodeDetection is a value decoded from the video, with the times in seconds of the frames
+// it was found on
+type BarcodeDetection struct {
+	// Format is the symbology in lower case, e.g. qr_code, code_128 or ean_13
+	Format     string    `json:"format"`
+	Value      string    `json:"value"`
+	Timestamps []float64 `json:"timestamps"`
+}
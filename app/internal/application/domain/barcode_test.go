@@ -0,0 +1,39 @@
+package domain
+
+import "testing"
+
+func TestBarcodeOptions_Interval(t *testing.T) {
+	if interval := (BarcodeOptions{Scan: true}).Interval(); interval != 1 {
+		t.Errorf("Expected a 1s interval by default, got %v", interval)
+	}
+	if interval := (BarcodeOptions{Scan: true, IntervalSeconds: 0.5}).Interval(); interval != 0.5 {
+		t.Errorf("Expected the interval kept, got %v", interval)
+	}
+}
+
+func TestVideoProcess_ValidateBarcodes(t *testing.T) {
+	tests := []struct {
+		name    string
+		request VideoProcess
+		valid   bool
+	}{
+		{"disabled", VideoProcess{}, true},
+		{"frames", VideoProcess{Barcodes: BarcodeOptions{Scan: true}}, true},
+		{"explicit frames with interval", VideoProcess{OutputType: OutputTypeFrames, Barcodes: BarcodeOptions{Scan: true, IntervalSeconds: 2}}, true},
+		{"interval without scan", VideoProcess{Barcodes: BarcodeOptions{IntervalSeconds: 2}}, false},
+		{"negative interval", VideoProcess{Barcodes: BarcodeOptions{Scan: true, IntervalSeconds: -1}}, false},
+		{"interval too long", VideoProcess{Barcodes: BarcodeOptions{Scan: true, IntervalSeconds: 90}}, false},
+		{"sprite", VideoProcess{OutputType: OutputTypeSprite, Barcodes: BarcodeOptions{Scan: true}}, false},
+		{"batch", VideoProcess{VideoKeys: []string{"a.mp4", "b.mp4"}, Barcodes: BarcodeOptions{Scan: true}}, false},
+	}
+
+	for _, tt := range tests {
+		err := tt.request.ValidateBarcodes()
+		if tt.valid && err != nil {
+			t.Errorf("%s: expected valid, got %v", tt.name, err)
+		}
+		if !tt.valid && err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package domain
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// Outputs of a batch request
+const (
+	// BatchOutputSeparate uploads one zip per video, frames_{process_id}_{n}.zip
+	BatchOutputSeparate = "separate"
+
+	// BatchOutputCombined uploads a single frames_{process_id}.zip with a folder per video
+	BatchOutputCombined = "combined"
+)
+
+// Aggregate status of a batch in its result
+const (
+	BatchStatusSucceeded = "succeeded"
+	BatchStatusPartial   = "partial"
+	BatchStatusFailed    = "failed"
+)
+
+// MaxBatchVideos bounds the videos of a batch, which are processed one after the other within
+// the message visibility
+const MaxBatchVideos = 20
+
+// BatchItemResult is the outcome of one video of a batch
+type BatchItemResult struct {
+	VideoKey  string   `json:"video_key"`
+	Success   bool     `json:"success"`
+	FileKey   string   `json:"file_key,omitempty"`
+	FileKeys  []string `json:"file_keys,omitempty"`
+	Frames    int      `json:"frames,omitempty"`
+	Error     string   `json:"error_message,omitempty"`
+	ErrorCode string   `json:"error_code,omitempty"`
+}
+
+// ArchiveGroup places the entries of the zips of one video under Dir in a combined archive
+type ArchiveGroup struct {
+	Dir  string
+	Zips []string
+}
+
+// IsBatch reports a request for several videos, listed in VideoKeys, processed one by one;
+// the concat output lists its clips there too
+func (v VideoProcess) IsBatch() bool {
+	return len(v.VideoKeys) > 0 && !v.IsConcat()
+}
+
+// BatchItems returns one request per video of the batch (or clip of a concat output), sharing
+// the process_id and options
+func (v VideoProcess) BatchItems() []VideoProcess {
+	items := make([]VideoProcess, len(v.VideoKeys))
+	for i, key := range v.VideoKeys {
+		items[i] = v
+		items[i].VideoKey = key
+		items[i].VideoKeys = nil
+	}
+	return items
+}
+
+// ValidateBatch checks the batch fields of a request; the video source of each item is checked
+// like a single video
+func (v VideoProcess) ValidateBatch() error {
+	if !v.IsBatch() {
+		if v.BatchOutput != "" {
+			return fmt.Errorf("batch_output requires video_keys")
+		}
+		return nil
+	}
+	if v.VideoKey != "" || v.VideoURL != "" {
+		return fmt.Errorf("video_keys cannot be combined with video_key or video_url")
+	}
+	if len(v.VideoKeys) > MaxBatchVideos {
+		return fmt.Errorf("a batch accepts at most %d videos, got %d", MaxBatchVideos, len(v.VideoKeys))
+	}
+	seen := make(map[string]bool, len(v.VideoKeys))
+	for _, key := range v.VideoKeys {
+		if key == "" {
+			return fmt.Errorf("video_keys cannot contain empty keys")
+		}
+		if seen[key] {
+			return fmt.Errorf("video_keys lists %s twice", key)
+		}
+		seen[key] = true
+	}
+	switch v.BatchOutput {
+	case "", BatchOutputSeparate, BatchOutputCombined:
+	default:
+		return fmt.Errorf("invalid batch_output %q: must be %s or %s", v.BatchOutput, BatchOutputSeparate, BatchOutputCombined)
+	}
+	// Only the zip outputs can be combined or reported per video
+	switch v.OutputType {
+	case "", OutputTypeFrames, OutputTypeSprite:
+	default:
+		return fmt.Errorf("%s output is not supported for batches", v.OutputType)
+	}
+	return nil
+}
+
+// BatchStatus aggregates the outcome of the videos of a batch
+func BatchStatus(items []BatchItemResult) string {
+	succeeded := 0
+	for _, item := range items {
+		if item.Success {
+			succeeded++
+		}
+	}
+	switch succeeded {
+	case len(items):
+		return BatchStatusSucceeded
+	case 0:
+		return BatchStatusFailed
+	default:
+		return BatchStatusPartial
+	}
+}
+
+// BatchItemDir names the folder of the index-th video (from 0) in a combined archive, e.g.
+// 01_intro for uploads/intro.mp4
+func BatchItemDir(index int, videoKey string) string {
+	name := path.Base(videoKey)
+	return fmt.Sprintf("%02d_%s", index+1, safeFileName(strings.TrimSuffix(name, path.Ext(name))))
+}
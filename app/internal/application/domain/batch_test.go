@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestVideoProcess_ValidateBatch(t *testing.T) {
+	keys := []string{"uploads/a.mp4", "uploads/b.mp4"}
+	tooMany := make([]string, MaxBatchVideos+1)
+	for i := range tooMany {
+		tooMany[i] = strings.Repeat("v", i+1)
+	}
+
+	tests := []struct {
+		name    string
+		request VideoProcess
+		valid   bool
+	}{
+		{"single video", VideoProcess{VideoKey: "uploads/a.mp4"}, true},
+		{"separate by default", VideoProcess{VideoKeys: keys}, true},
+		{"combined", VideoProcess{VideoKeys: keys, BatchOutput: BatchOutputCombined}, true},
+		{"sprites", VideoProcess{VideoKeys: keys, OutputType: OutputTypeSprite}, true},
+		{"batch output without keys", VideoProcess{VideoKey: "uploads/a.mp4", BatchOutput: BatchOutputCombined}, false},
+		{"with video_key", VideoProcess{VideoKey: "uploads/a.mp4", VideoKeys: keys}, false},
+		{"too many", VideoProcess{VideoKeys: tooMany}, false},
+		{"duplicate", VideoProcess{VideoKeys: []string{"a.mp4", "a.mp4"}}, false},
+		{"empty key", VideoProcess{VideoKeys: []string{"a.mp4", ""}}, false},
+		{"unknown output", VideoProcess{VideoKeys: keys, BatchOutput: "merged"}, false},
+		{"packaging", VideoProcess{VideoKeys: keys, OutputType: OutputTypeHLS}, false},
+		{"edited video", VideoProcess{VideoKeys: keys, OutputType: OutputTypeTrim}, false},
+	}
+
+	for _, tt := range tests {
+		err := tt.request.ValidateBatch()
+		if tt.valid && err != nil {
+			t.Errorf("%s: expected valid, got %v", tt.name, err)
+		}
+		if !tt.valid && err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
+
+func TestVideoProcess_BatchItems(t *testing.T) {
+	request := VideoProcess{ProcessID: "123", VideoBucket: "uploads", VideoKeys: []string{"a.mp4", "b.mp4"}, FPS: 2}
+
+	items := request.BatchItems()
+	if len(items) != 2 || items[1].VideoKey != "b.mp4" || items[1].ProcessID != "123" || items[1].FPS != 2 {
+		t.Fatalf("Unexpected items %+v", items)
+	}
+	if items[0].IsBatch() {
+		t.Error("Expected the items to be single videos")
+	}
+}
+
+func TestBatchStatus(t *testing.T) {
+	ok, failed := BatchItemResult{Success: true}, BatchItemResult{Error: "boom"}
+
+	if status := BatchStatus([]BatchItemResult{ok, ok}); status != BatchStatusSucceeded {
+		t.Errorf("Expected %s, got %s", BatchStatusSucceeded, status)
+	}
+	if status := BatchStatus([]BatchItemResult{ok, failed}); status != BatchStatusPartial {
+		t.Errorf("Expected %s, got %s", BatchStatusPartial, status)
+	}
+	if status := BatchStatus([]BatchItemResult{failed}); status != BatchStatusFailed {
+		t.Errorf("Expected %s, got %s", BatchStatusFailed, status)
+	}
+}
+
+func TestBatchItemDir(t *testing.T) {
+	if dir := BatchItemDir(0, "uploads/intro clip.mp4"); dir != "01_intro_clip" {
+		t.Errorf("Expected 01_intro_clip, got %s", dir)
+	}
+}
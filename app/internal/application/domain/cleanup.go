@@ -0,0 +1,26 @@
+package domain
+
+import "time"
+
+// CleanupFailure is an output the cleanup could not delete
+type CleanupFailure struct {
+	Key   string `json:"key"`
+	Error string `json:"error"`
+}
+
+// CleanupReport summarizes the removal of old outputs under a location
+type CleanupReport struct {
+	Bucket string `json:"bucket"`
+	Prefix string `json:"prefix"`
+	// Before is the cutoff: objects last modified before it are deleted
+	Before time.Time `json:"before"`
+	DryRun bool      `json:"dry_run,omitempty"`
+	Listed int       `json:"listed"`
+	// Expired are the objects older than the cutoff; in a dry run none of them is deleted
+	Expired         int              `json:"expired"`
+	Deleted         int              `json:"deleted"`
+	DeletedBytes    int64            `json:"deleted_bytes"`
+	Failed          int              `json:"failed"`
+	Failures        []CleanupFailure `json:"failures,omitempty"`
+	DurationSeconds float64          `json:"duration_seconds"`
+}
@@ -0,0 +1,52 @@
+package domain
+
+import "fmt"
+
+const (
+	ColorScopeFrame     = "frame"
+	ColorScopeAggregate = "aggregate"
+
+	// DefaultDominantColors is the number of dominant colors reported when none is requested
+	DefaultDominantColors = 5
+
+	MaxDominantColors = 16
+)
+
+// ColorOptions adds the color histogram and the dominant colors of the frames output to the
+// frames manifest
+type ColorOptions struct {
+	// Scope is frame (one summary per frame) or aggregate (one summary for all the frames);
+	// empty disables the analysis
+	Scope string `json:"scope"`
+
+	// Dominant is the number of dominant colors reported; zero means DefaultDominantColors
+	Dominant int `json:"dominant"`
+}
+
+func (o ColorOptions) Enabled() bool {
+	return o.Scope != ""
+}
+
+// DominantCount returns the number of dominant colors reported
+func (o ColorOptions) DominantCount() int {
+	if o.Dominant == 0 {
+		return DefaultDominantColors
+	}
+	return o.Dominant
+}
+
+func (o ColorOptions) Validate() error {
+	switch o.Scope {
+	case "":
+		if o.Dominant != 0 {
+			return fmt.Errorf("colors.dominant requires a colors.scope")
+		}
+	case ColorScopeFrame, ColorScopeAggregate:
+	default:
+		return fmt.Errorf("unsupported colors scope: %s", o.Scope)
+	}
+	if o.Dominant < 0 || o.Dominant > MaxDominantColors {
+		return fmt.Errorf("colors.dominant must be between 1 and %d", MaxDominantColors)
+	}
+	return nil
+}
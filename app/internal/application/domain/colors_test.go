@@ -0,0 +1,33 @@
+package domain
+
+import "testing"
+
+func TestColorOptions_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		options ColorOptions
+		wantErr bool
+	}{
+		{"disabled", ColorOptions{}, false},
+		{"frame", ColorOptions{Scope: ColorScopeFrame}, false},
+		{"aggregate with dominant", ColorOptions{Scope: ColorScopeAggregate, Dominant: 8}, false},
+		{"dominant without scope", ColorOptions{Dominant: 3}, true},
+		{"too many colors", ColorOptions{Scope: ColorScopeFrame, Dominant: 17}, true},
+		{"negative dominant", ColorOptions{Scope: ColorScopeFrame, Dominant: -1}, true},
+		{"unknown scope", ColorOptions{Scope: "video"}, true},
+	}
+	for _, tt := range tests {
+		if err := tt.options.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("%s: expected error %v, got %v", tt.name, tt.wantErr, err)
+		}
+	}
+}
+
+func TestColorOptions_DominantCount(t *testing.T) {
+	if count := (ColorOptions{Scope: ColorScopeFrame}).DominantCount(); count != DefaultDominantColors {
+		t.Errorf("Expected %d colors by default, got %d", DefaultDominantColors, count)
+	}
+	if count := (ColorOptions{Scope: ColorScopeFrame, Dominant: 3}).DominantCount(); count != 3 {
+		t.Errorf("Expected 3 colors, got %d", count)
+	}
+}
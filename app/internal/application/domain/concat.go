@@ -0,0 +1,97 @@
+package domain
+
+import "fmt"
+
+const (
+	// MaxConcatClips bounds the clips of a concat output, all downloaded before ffmpeg runs
+	MaxConcatClips = 20
+
+	// ConcatFPS is the frame rate of a re-encoded concat output
+	ConcatFPS = 30
+
+	maxConcatWidth  = 3840
+	maxConcatHeight = 2160
+)
+
+// ConcatOptions configures the concat output; zero values fall back to DefaultConcatOptions
+type ConcatOptions struct {
+	// Mode is EditModeCopy or EditModeReencode, which scales every clip to the same frame
+	Mode string `json:"mode"`
+
+	// Width and Height are the frame of a re-encoded output; clips with another aspect ratio
+	// are letterboxed
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+var DefaultConcatOptions = ConcatOptions{
+	Mode:   EditModeCopy,
+	Width:  1280,
+	Height: 720,
+}
+
+// WithDefaults fills the unset fields with DefaultConcatOptions
+func (o ConcatOptions) WithDefaults() ConcatOptions {
+	if o.Mode == "" {
+		o.Mode = DefaultConcatOptions.Mode
+	}
+	if o.Width == 0 {
+		o.Width = DefaultConcatOptions.Width
+	}
+	if o.Height == 0 {
+		o.Height = DefaultConcatOptions.Height
+	}
+	return o
+}
+
+// Validate rejects unknown modes and frames libx264 cannot encode
+func (o ConcatOptions) Validate() error {
+	if err := validateEditMode(OutputTypeConcat, o.Mode); err != nil {
+		return err
+	}
+	if (o.Width != 0 || o.Height != 0) && o.Mode != EditModeReencode {
+		return fmt.Errorf("concat width and height require the %s mode", EditModeReencode)
+	}
+	// libx264 requires even dimensions
+	if o.Width < 0 || o.Width > maxConcatWidth || o.Width%2 != 0 {
+		return fmt.Errorf("invalid concat width: %d", o.Width)
+	}
+	if o.Height < 0 || o.Height > maxConcatHeight || o.Height%2 != 0 {
+		return fmt.Errorf("invalid concat height: %d", o.Height)
+	}
+	return nil
+}
+
+// IsConcat reports a request joining the videos listed in VideoKeys into one
+func (v VideoProcess) IsConcat() bool {
+	return v.OutputType == OutputTypeConcat
+}
+
+// ValidateConcat checks the concat fields of a request; each clip is checked like a single
+// video source
+func (v VideoProcess) ValidateConcat() error {
+	if !v.IsConcat() {
+		if v.Concat != (ConcatOptions{}) {
+			return fmt.Errorf("concat options require the %s output", OutputTypeConcat)
+		}
+		return nil
+	}
+	if v.VideoKey != "" || v.VideoURL != "" {
+		return fmt.Errorf("%s output takes its clips from video_keys, not video_key or video_url", OutputTypeConcat)
+	}
+	if len(v.VideoKeys) < 2 || len(v.VideoKeys) > MaxConcatClips {
+		return fmt.Errorf("%s output requires between 2 and %d video_keys, got %d", OutputTypeConcat, MaxConcatClips, len(v.VideoKeys))
+	}
+	for _, key := range v.VideoKeys {
+		if key == "" {
+			return fmt.Errorf("video_keys cannot contain empty keys")
+		}
+	}
+	if v.BatchOutput != "" {
+		return fmt.Errorf("batch_output is not supported for %s output", OutputTypeConcat)
+	}
+	if len(v.Windows) > 0 {
+		return fmt.Errorf("time windows are not supported for %s output", OutputTypeConcat)
+	}
+	return v.Concat.Validate()
+}
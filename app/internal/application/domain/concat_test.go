@@ -0,0 +1,78 @@
+package domain
+
+import "testing"
+
+func TestConcatOptions_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		options ConcatOptions
+		valid   bool
+	}{
+		{"defaults", ConcatOptions{}, true},
+		{"copy", ConcatOptions{Mode: EditModeCopy}, true},
+		{"reencode", ConcatOptions{Mode: EditModeReencode, Width: 1920, Height: 1080}, true},
+		{"unknown mode", ConcatOptions{Mode: "remux"}, false},
+		{"size with copy", ConcatOptions{Width: 640, Height: 360}, false},
+		{"odd width", ConcatOptions{Mode: EditModeReencode, Width: 641}, false},
+		{"negative height", ConcatOptions{Mode: EditModeReencode, Height: -2}, false},
+		{"too large", ConcatOptions{Mode: EditModeReencode, Width: 7680, Height: 4320}, false},
+	}
+
+	for _, tt := range tests {
+		err := tt.options.Validate()
+		if tt.valid && err != nil {
+			t.Errorf("%s: expected valid, got %v", tt.name, err)
+		}
+		if !tt.valid && err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
+
+func TestConcatOptions_WithDefaults(t *testing.T) {
+	options := ConcatOptions{Mode: EditModeReencode, Height: 480}.WithDefaults()
+
+	if options.Mode != EditModeReencode || options.Width != 1280 || options.Height != 480 {
+		t.Errorf("Expected only the unset fields filled, got %+v", options)
+	}
+}
+
+func TestVideoProcess_ValidateConcat(t *testing.T) {
+	clips := []string{"uploads/a.mp4", "uploads/b.mp4"}
+	tooMany := make([]string, MaxConcatClips+1)
+	for i := range tooMany {
+		tooMany[i] = "uploads/a.mp4"
+	}
+
+	tests := []struct {
+		name    string
+		request VideoProcess
+		valid   bool
+	}{
+		{"not concat", VideoProcess{VideoKey: "uploads/a.mp4"}, true},
+		{"clips", VideoProcess{OutputType: OutputTypeConcat, VideoKeys: clips}, true},
+		{"repeated clip", VideoProcess{OutputType: OutputTypeConcat, VideoKeys: []string{"a.mp4", "b.mp4", "a.mp4"}}, true},
+		{"options without concat", VideoProcess{VideoKey: "uploads/a.mp4", Concat: ConcatOptions{Mode: EditModeReencode}}, false},
+		{"single clip", VideoProcess{OutputType: OutputTypeConcat, VideoKeys: clips[:1]}, false},
+		{"too many", VideoProcess{OutputType: OutputTypeConcat, VideoKeys: tooMany}, false},
+		{"with video_key", VideoProcess{OutputType: OutputTypeConcat, VideoKey: "uploads/a.mp4", VideoKeys: clips}, false},
+		{"empty key", VideoProcess{OutputType: OutputTypeConcat, VideoKeys: []string{"a.mp4", ""}}, false},
+		{"batch output", VideoProcess{OutputType: OutputTypeConcat, VideoKeys: clips, BatchOutput: BatchOutputCombined}, false},
+		{"windows", VideoProcess{OutputType: OutputTypeConcat, VideoKeys: clips, Windows: []TimeWindow{{Start: 1}}}, false},
+		{"invalid options", VideoProcess{OutputType: OutputTypeConcat, VideoKeys: clips, Concat: ConcatOptions{Mode: "remux"}}, false},
+	}
+
+	for _, tt := range tests {
+		err := tt.request.ValidateConcat()
+		if tt.valid && err != nil {
+			t.Errorf("%s: expected valid, got %v", tt.name, err)
+		}
+		if !tt.valid && err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+
+	if (VideoProcess{OutputType: OutputTypeConcat, VideoKeys: clips}).IsBatch() {
+		t.Error("Expected the clips of a concat output not to make a batch")
+	}
+}
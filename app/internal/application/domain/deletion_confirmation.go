@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// DeletionConfirmed releases the original video for deletion
+	DeletionConfirmed = "confirmed"
+
+	// DeletionRejected keeps the original video, e.g. because the consumer rejected the output
+	DeletionRejected = "rejected"
+)
+
+// pendingDeletionPrefix holds one marker per video waiting for a confirmation, in the output
+// bucket so that any worker can act on the confirmation
+const pendingDeletionPrefix = "pending-deletions/"
+
+// PendingDeletion records the original video of a process whose deletion waits for the
+// consumer's confirmation
+type PendingDeletion struct {
+	ProcessID   string `json:"process_id"`
+	VideoBucket string `json:"video_bucket"`
+	VideoKey    string `json:"video_key"`
+	// VideoVersionID is the version processed in a versioned bucket, the one deleted
+	VideoVersionID string    `json:"video_version_id,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// PendingDeletionKey is the key of the marker for processID
+func PendingDeletionKey(processID string) string {
+	return pendingDeletionPrefix + processID + ".json"
+}
+
+// DeletionConfirmation is the control message a consumer sends after validating the output.
+// It only names the process; the video to delete comes from the worker's own marker
+type DeletionConfirmation struct {
+	ProcessID string `json:"process_id"`
+	Status    string `json:"status"`
+}
+
+func (c DeletionConfirmation) Validate() error {
+	if err := ValidateProcessID(c.ProcessID); err != nil {
+		return err
+	}
+	if c.Status != DeletionConfirmed && c.Status != DeletionRejected {
+		return fmt.Errorf("invalid status %q: must be %s or %s", c.Status, DeletionConfirmed, DeletionRejected)
+	}
+	return nil
+}
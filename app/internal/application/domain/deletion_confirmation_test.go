@@ -0,0 +1,32 @@
+package domain
+
+import "testing"
+
+func TestDeletionConfirmation_Validate(t *testing.T) {
+	tests := []struct {
+		name         string
+		confirmation DeletionConfirmation
+		wantErr      bool
+	}{
+		{"confirmed", DeletionConfirmation{ProcessID: "p1", Status: DeletionConfirmed}, false},
+		{"rejected", DeletionConfirmation{ProcessID: "p1", Status: DeletionRejected}, false},
+		{"missing process_id", DeletionConfirmation{Status: DeletionConfirmed}, true},
+		{"missing status", DeletionConfirmation{ProcessID: "p1"}, true},
+		{"unknown status", DeletionConfirmation{ProcessID: "p1", Status: "ok"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.confirmation.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPendingDeletionKey(t *testing.T) {
+	if key := PendingDeletionKey("process-123"); key != "pending-deletions/process-123.json" {
+		t.Errorf("Expected pending-deletions/process-123.json, got %s", key)
+	}
+}
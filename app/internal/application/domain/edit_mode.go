@@ -0,0 +1,21 @@
+package domain
+
+import "fmt"
+
+// How the outputs that write a video (concat, trim) handle its streams
+const (
+	// EditModeCopy copies the streams as they are: fast and lossless, but cuts land on keyframes
+	// and joined clips must share codecs and dimensions
+	EditModeCopy = "copy"
+
+	// EditModeReencode decodes and encodes H.264/AAC, with exact cuts and any clips
+	EditModeReencode = "reencode"
+)
+
+func validateEditMode(output, mode string) error {
+	switch mode {
+	case "", EditModeCopy, EditModeReencode:
+		return nil
+	}
+	return fmt.Errorf("invalid %s mode %q: must be %s or %s", output, mode, EditModeCopy, EditModeReencode)
+}
@@ -0,0 +1,53 @@
+package domain
+
+import "errors"
+
+const (
+	ErrorCodeMalwareDetected   = "malware_detected"
+	ErrorCodeSourceNotAllowed  = "source_not_allowed"
+	ErrorCodeOutputNotAllowed  = "output_not_allowed"
+	ErrorCodeCancelled         = "cancelled"
+	ErrorCodeTimeout           = "timeout"
+	ErrorCodeUnsupportedFormat = "unsupported_format"
+	ErrorCodeEmptyVideo        = "empty_video"
+	ErrorCodeTruncatedVideo    = "truncated_video"
+	ErrorCodeVideoTooLarge     = "video_too_large"
+	ErrorCodeSourceModified    = "source_modified"
+	ErrorCodeUnsupportedCodec  = "unsupported_codec"
+	ErrorCodeNotAcceptable     = "not_acceptable"
+	ErrorCodeJobNotFound       = "job_not_found"
+)
+
+// ErrObjectNotFound is returned by the storage when the requested object does not exist
+var ErrObjectNotFound = errors.New("object not found")
+
+// ErrObjectModified is returned by the storage when the object is no longer the version a
+// conditional read asked for
+var ErrObjectModified = errors.New("object modified")
+
+// CodedError attaches a machine-readable code to an error reported in the result message
+type CodedError struct {
+	Code string
+	Err  error
+}
+
+func NewCodedError(code string, err error) error {
+	return &CodedError{Code: code, Err: err}
+}
+
+func (e *CodedError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *CodedError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorCode returns the code of the first CodedError in the chain, or "" when there is none
+func ErrorCode(err error) string {
+	var coded *CodedError
+	if errors.As(err, &coded) {
+		return coded.Code
+	}
+	return ""
+}
@@ -0,0 +1,54 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestCodedError(t *testing.T) {
+	cause := errors.New("malware detected: Eicar")
+	err := NewCodedError(ErrorCodeMalwareDetected, cause)
+
+	if err.Error() != cause.Error() {
+		t.Errorf("Expected message %q, got %q", cause.Error(), err.Error())
+	}
+	if !errors.Is(err, cause) {
+		t.Error("Expected CodedError to unwrap to its cause")
+	}
+}
+
+func TestErrorCode(t *testing.T) {
+	wrapped := fmt.Errorf("scan: %w", NewCodedError(ErrorCodeMalwareDetected, errors.New("infected")))
+
+	if code := ErrorCode(wrapped); code != ErrorCodeMalwareDetected {
+		t.Errorf("Expected code %s, got %s", ErrorCodeMalwareDetected, code)
+	}
+	if code := ErrorCode(errors.New("plain")); code != "" {
+		t.Errorf("Expected empty code, got %s", code)
+	}
+	if code := ErrorCode(nil); code != "" {
+		t.Errorf("Expected empty code for nil, got %s", code)
+	}
+}
+
+func TestProcessResult_ToErrorMessage_WithCode(t *testing.T) {
+	result := ProcessResult{
+		ProcessID: "process-789",
+		Error:     NewCodedError(ErrorCodeMalwareDetected, errors.New("malware detected: Eicar")),
+	}
+
+	msg := result.ToErrorMessage()
+
+	if msg["error_code"] != ErrorCodeMalwareDetected {
+		t.Errorf("Expected error_code %s, got %v", ErrorCodeMalwareDetected, msg["error_code"])
+	}
+	if _, ok := msg["quarantine"]; ok {
+		t.Error("Expected no quarantine for a video left in place")
+	}
+
+	result.Quarantine = &ObjectRef{Bucket: "quarantine", Key: "q/process-789/video.mp4"}
+	if location, ok := result.ToErrorMessage()["quarantine"].(*ObjectRef); !ok || location.Key != "q/process-789/video.mp4" {
+		t.Errorf("Expected the quarantine location, got %v", location)
+	}
+}
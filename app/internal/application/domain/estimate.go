@@ -0,0 +1,75 @@
+package domain
+
+import "math"
+
+// VideoInfo is what ffprobe reports about the source video, enough to estimate the output
+type VideoInfo struct {
+	DurationSeconds float64
+	Width           int
+	Height          int
+
+	// Rotation is the clockwise rotation (0, 90, 180 or 270) that makes the video upright
+	Rotation int
+
+	// Codec is the ffprobe name of the video codec, e.g. h264
+	Codec string
+}
+
+// OutputEstimate is the dry-run answer: what the job would produce without producing it.
+// Count is frames for the frames output and sheets for the sprite output; dropped blurry
+// frames and zip compression make the real output smaller, never larger
+type OutputEstimate struct {
+	DurationSeconds float64 `json:"duration_seconds"`
+	Width           int     `json:"width"`
+	Height          int     `json:"height"`
+	Count           int     `json:"count"`
+	Bytes           int64   `json:"bytes"`
+}
+
+// bytesPerPixel are rough compressed sizes of a natural video frame per image format
+var bytesPerPixel = map[string]float64{
+	ImageFormatPNG:  1.5,
+	ImageFormatJPEG: 0.2,
+	ImageFormatWebP: 0.12,
+}
+
+// EstimateOutput predicts the count and size of the output the request would produce
+func EstimateOutput(info VideoInfo, request VideoProcess) OutputEstimate {
+	options := request.FrameOptions()
+	width, height := info.Width, info.Height
+	if options.AutoRotate && (info.Rotation == 90 || info.Rotation == 270) {
+		width, height = height, width
+	}
+
+	estimate := OutputEstimate{
+		DurationSeconds: info.DurationSeconds,
+		Width:           width,
+		Height:          height,
+	}
+
+	seconds := ProcessedSeconds(info.DurationSeconds, options.Windows)
+	switch request.OutputType {
+	case OutputTypeSprite:
+		sprite := request.Sprite.WithDefaults()
+		thumbs := int(math.Ceil(seconds / sprite.IntervalSeconds))
+		perSheet := sprite.Columns * sprite.Rows
+		estimate.Count = (thumbs + perSheet - 1) / perSheet
+		sheetPixels := float64(perSheet * sprite.Width * sprite.Height)
+		estimate.Bytes = int64(float64(estimate.Count) * sheetPixels * bytesPerPixel[ImageFormatJPEG])
+	case OutputTypeHLS, OutputTypeDASH:
+		for _, rendition := range request.Packaging.WithDefaults().Renditions {
+			estimate.Bytes += int64(float64(rendition.BitrateKbps) * 1000 / 8 * seconds)
+		}
+	default:
+		estimate.Count = int(math.Round(seconds * options.FrameRate()))
+		if options.MaxFrames > 0 && estimate.Count > options.MaxFrames {
+			estimate.Count = options.MaxFrames
+		}
+		format := options.Image.Format
+		if format == "" {
+			format = ImageFormatPNG
+		}
+		estimate.Bytes = int64(float64(estimate.Count) * float64(width*height) * bytesPerPixel[format])
+	}
+	return estimate
+}
@@ -0,0 +1,70 @@
+package domain
+
+import "testing"
+
+func TestEstimateOutput_Frames(t *testing.T) {
+	info := VideoInfo{DurationSeconds: 120, Width: 1920, Height: 1080}
+
+	estimate := EstimateOutput(info, VideoProcess{})
+	if estimate.Count != 120 {
+		t.Errorf("Expected 120 frames, got %d", estimate.Count)
+	}
+	if want := int64(120 * 1920 * 1080 * 1.5); estimate.Bytes != want {
+		t.Errorf("Expected %d bytes, got %d", want, estimate.Bytes)
+	}
+
+	estimate = EstimateOutput(info, VideoProcess{MaxFrames: 50, Image: ImageOptions{Format: ImageFormatJPEG}})
+	if estimate.Count != 50 {
+		t.Errorf("Expected the cap of 50 frames, got %d", estimate.Count)
+	}
+	if want := int64(50 * 1920 * 1080 * 0.2); estimate.Bytes != want {
+		t.Errorf("Expected %d bytes, got %d", want, estimate.Bytes)
+	}
+
+	estimate = EstimateOutput(info, VideoProcess{Windows: []TimeWindow{{Start: 10, End: 40}}})
+	if estimate.Count != 30 {
+		t.Errorf("Expected 30 frames inside the window, got %d", estimate.Count)
+	}
+}
+
+func TestEstimateOutput_Rotation(t *testing.T) {
+	info := VideoInfo{DurationSeconds: 10, Width: 1920, Height: 1080, Rotation: 90}
+
+	estimate := EstimateOutput(info, VideoProcess{})
+	if estimate.Width != 1080 || estimate.Height != 1920 {
+		t.Errorf("Expected upright 1080x1920, got %dx%d", estimate.Width, estimate.Height)
+	}
+
+	estimate = EstimateOutput(info, VideoProcess{DisableAutoRotate: true})
+	if estimate.Width != 1920 || estimate.Height != 1080 {
+		t.Errorf("Expected stored 1920x1080, got %dx%d", estimate.Width, estimate.Height)
+	}
+}
+
+func TestEstimateOutput_Sprite(t *testing.T) {
+	info := VideoInfo{DurationSeconds: 600, Width: 1920, Height: 1080}
+
+	// 60 thumbnails on 5x5 sheets
+	estimate := EstimateOutput(info, VideoProcess{OutputType: OutputTypeSprite})
+	if estimate.Count != 3 {
+		t.Errorf("Expected 3 sheets, got %d", estimate.Count)
+	}
+	if estimate.Bytes <= 0 {
+		t.Errorf("Expected a positive size, got %d", estimate.Bytes)
+	}
+}
+
+func TestEstimateOutput_Packaging(t *testing.T) {
+	info := VideoInfo{DurationSeconds: 10, Width: 1920, Height: 1080}
+
+	estimate := EstimateOutput(info, VideoProcess{
+		OutputType: OutputTypeHLS,
+		Packaging:  PackagingOptions{Renditions: []Rendition{{Height: 720, BitrateKbps: 800}}},
+	})
+	if estimate.Count != 0 {
+		t.Errorf("Expected no count for packaging, got %d", estimate.Count)
+	}
+	if estimate.Bytes != 1000000 {
+		t.Errorf("Expected 1000000 bytes, got %d", estimate.Bytes)
+	}
+}
@@ -0,0 +1,52 @@
+package domain
+
+import "fmt"
+
+const (
+	// MaxFingerprintFrames bounds the frames hashed by the fingerprint output; longer videos are
+	// sampled up to it
+	MaxFingerprintFrames = 7200
+
+	maxFingerprintInterval = 60
+)
+
+// FingerprintOptions configures the fingerprint output; zero values fall back to
+// DefaultFingerprintOptions
+type FingerprintOptions struct {
+	// IntervalSeconds is the time between two hashed frames
+	IntervalSeconds float64 `json:"interval_seconds"`
+}
+
+var DefaultFingerprintOptions = FingerprintOptions{
+	IntervalSeconds: 1,
+}
+
+// WithDefaults fills the unset fields with DefaultFingerprintOptions
+func (o FingerprintOptions) WithDefaults() FingerprintOptions {
+	if o.IntervalSeconds == 0 {
+		o.IntervalSeconds = DefaultFingerprintOptions.IntervalSeconds
+	}
+	return o
+}
+
+// Validate rejects sampling intervals outside (0, 60] seconds
+func (o FingerprintOptions) Validate() error {
+	if o.IntervalSeconds < 0 || o.IntervalSeconds > maxFingerprintInterval {
+		return fmt.Errorf("fingerprint interval must be between 0 and %d seconds, got %v", maxFingerprintInterval, o.IntervalSeconds)
+	}
+	return nil
+}
+
+// ValidateFingerprint checks the fingerprint fields of a request
+func (v VideoProcess) ValidateFingerprint() error {
+	if v.OutputType != OutputTypeFingerprint {
+		if v.Fingerprint != (FingerprintOptions{}) {
+			return fmt.Errorf("fingerprint options require the %s output", OutputTypeFingerprint)
+		}
+		return nil
+	}
+	if len(v.Windows) > 0 {
+		return fmt.Errorf("time windows are not supported for %s output", OutputTypeFingerprint)
+	}
+	return v.Fingerprint.Validate()
+}
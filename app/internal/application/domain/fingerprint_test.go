@@ -0,0 +1,38 @@
+package domain
+
+import "testing"
+
+func TestFingerprintOptions_WithDefaults(t *testing.T) {
+	if options := (FingerprintOptions{}).WithDefaults(); options.IntervalSeconds != 1 {
+		t.Errorf("Expected a 1s interval, got %v", options.IntervalSeconds)
+	}
+	if options := (FingerprintOptions{IntervalSeconds: 0.5}).WithDefaults(); options.IntervalSeconds != 0.5 {
+		t.Errorf("Expected the interval kept, got %v", options.IntervalSeconds)
+	}
+}
+
+func TestVideoProcess_ValidateFingerprint(t *testing.T) {
+	tests := []struct {
+		name    string
+		request VideoProcess
+		valid   bool
+	}{
+		{"not fingerprint", VideoProcess{}, true},
+		{"defaults", VideoProcess{OutputType: OutputTypeFingerprint}, true},
+		{"custom interval", VideoProcess{OutputType: OutputTypeFingerprint, Fingerprint: FingerprintOptions{IntervalSeconds: 0.25}}, true},
+		{"negative interval", VideoProcess{OutputType: OutputTypeFingerprint, Fingerprint: FingerprintOptions{IntervalSeconds: -1}}, false},
+		{"interval too long", VideoProcess{OutputType: OutputTypeFingerprint, Fingerprint: FingerprintOptions{IntervalSeconds: 120}}, false},
+		{"windows", VideoProcess{OutputType: OutputTypeFingerprint, Windows: []TimeWindow{{Start: 1}}}, false},
+		{"options without fingerprint", VideoProcess{Fingerprint: FingerprintOptions{IntervalSeconds: 2}}, false},
+	}
+
+	for _, tt := range tests {
+		err := tt.request.ValidateFingerprint()
+		if tt.valid && err != nil {
+			t.Errorf("%s: expected valid, got %v", tt.name, err)
+		}
+		if !tt.valid && err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
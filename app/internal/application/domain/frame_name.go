@@ -0,0 +1,83 @@
+package domain
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Placeholders of a frame name template
+const (
+	FrameNameProcessID = "{process_id}"
+	FrameNameIndex     = "{index}"
+	FrameNameTimestamp = "{ts_ms}"
+)
+
+// DefaultFrameName keeps the frame_0001.png naming of the frames output
+const DefaultFrameName FrameNameTemplate = "frame_" + FrameNameIndex
+
+var (
+	frameNamePlaceholder = regexp.MustCompile(`\{[^}]*\}`)
+	frameNameLiteral     = regexp.MustCompile(`^[A-Za-z0-9._-]*$`)
+)
+
+// FrameNameTemplate names the frames in the output zip, e.g. {process_id}_{ts_ms}.png.
+// {index} is the frame number from 1, zero padded to 4 digits, and {ts_ms} the position of
+// the frame in the video in milliseconds. The extension always follows the image format, so
+// one given in the template is replaced
+type FrameNameTemplate string
+
+// Or returns the template, or fallback when none was requested
+func (t FrameNameTemplate) Or(fallback FrameNameTemplate) FrameNameTemplate {
+	if t == "" {
+		return fallback
+	}
+	return t
+}
+
+func (t FrameNameTemplate) Validate() error {
+	if t == "" {
+		return nil
+	}
+	template := string(t)
+	if !strings.Contains(template, FrameNameIndex) && !strings.Contains(template, FrameNameTimestamp) {
+		return fmt.Errorf("frame name template needs %s or %s to tell the frames apart", FrameNameIndex, FrameNameTimestamp)
+	}
+	for _, placeholder := range frameNamePlaceholder.FindAllString(template, -1) {
+		switch placeholder {
+		case FrameNameProcessID, FrameNameIndex, FrameNameTimestamp:
+		default:
+			return fmt.Errorf("unknown frame name placeholder %s", placeholder)
+		}
+	}
+	// The rest becomes part of a file name, inside the zip and on disk
+	if !frameNameLiteral.MatchString(frameNamePlaceholder.ReplaceAllString(template, "")) {
+		return fmt.Errorf("frame name template may only contain letters, digits, '.', '_' and '-' besides the placeholders")
+	}
+	return nil
+}
+
+// Name renders the name of a frame with extension ext (".png"); processID is written with
+// the characters a template accepts, others replaced by '_'
+func (t FrameNameTemplate) Name(processID string, index int, timestampMs int64, ext string) string {
+	template := strings.TrimSuffix(string(t), filepath.Ext(string(t)))
+	name := strings.NewReplacer(
+		FrameNameProcessID, safeFileName(processID),
+		FrameNameIndex, fmt.Sprintf("%04d", index),
+		FrameNameTimestamp, strconv.FormatInt(timestampMs, 10),
+	).Replace(template)
+	return name + ext
+}
+
+func safeFileName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
@@ -0,0 +1,55 @@
+package domain
+
+import "testing"
+
+func TestFrameNameTemplate_Validate(t *testing.T) {
+	tests := []struct {
+		template FrameNameTemplate
+		valid    bool
+	}{
+		{"", true},
+		{DefaultFrameName, true},
+		{"{process_id}_{ts_ms}.png", true},
+		{"shot-{index}", true},
+		{"{process_id}", false},
+		{"frame_{frame}", false},
+		{"../{index}", false},
+		{"frame {index}", false},
+	}
+
+	for _, tt := range tests {
+		err := tt.template.Validate()
+		if tt.valid && err != nil {
+			t.Errorf("Expected %q to be valid, got %v", tt.template, err)
+		}
+		if !tt.valid && err == nil {
+			t.Errorf("Expected %q to be invalid", tt.template)
+		}
+	}
+}
+
+func TestFrameNameTemplate_Name(t *testing.T) {
+	tests := []struct {
+		template  FrameNameTemplate
+		processID string
+		expected  string
+	}{
+		{DefaultFrameName, "123", "frame_0007.png"},
+		{"{process_id}_{ts_ms}.png", "123", "123_6500.webp"},
+		{"{process_id}_{index}", "tenant/../123", "tenant_.._123_0007.webp"},
+	}
+
+	for _, tt := range tests {
+		ext := ".webp"
+		if tt.template == DefaultFrameName {
+			ext = ".png"
+		}
+		if name := tt.template.Name(tt.processID, 7, 6500, ext); name != tt.expected {
+			t.Errorf("Expected %s, got %s", tt.expected, name)
+		}
+	}
+
+	if (FrameNameTemplate("")).Or(DefaultFrameName) != DefaultFrameName || FrameNameTemplate("{index}").Or(DefaultFrameName) != "{index}" {
+		t.Error("Expected Or to fall back only without a template")
+	}
+}
@@ -0,0 +1,100 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+)
+
+// MaxFramesLimit bounds max_frames so a single zip stays manageable
+const MaxFramesLimit = 10000
+
+// FrameOptions are the decoding settings shared by every output that renders video frames
+type FrameOptions struct {
+	// ProcessID is the only source identifier written into the output image metadata
+	ProcessID string
+
+	Subtitles SubtitleOptions
+
+	// AutoRotate applies the rotation metadata (phone videos) so frames come out upright
+	AutoRotate bool
+
+	// ToneMap is the tonemap algorithm applied to HDR videos; empty leaves HDR as is
+	ToneMap string
+
+	// Windows restricts decoding to these sorted, non-overlapping ranges; empty means the whole video
+	Windows []TimeWindow
+
+	// FPS is the frames output sampling rate; zero means one frame per second
+	FPS float64
+
+	// MaxFrames caps the frames output, lowering the sampling rate when needed; zero means no cap
+	MaxFrames int
+
+	// Sharpness scores the frames output and optionally drops the blurry ones
+	Sharpness SharpnessOptions
+
+	// Colors adds color histograms and dominant colors of the frames output to the manifest
+	Colors ColorOptions
+
+	// OCR adds the text visible on each frame of the frames output to the manifest
+	OCR OCROptions
+
+	// Anonymize blurs the given regions on the frames output
+	Anonymize AnonymizeOptions
+
+	// Image converts or recompresses the frames output before zipping
+	Image ImageOptions
+
+	// Archive overrides the worker's zip method and level
+	Archive ArchiveOptions
+
+	// FrameName overrides the worker's naming of the frames in the zip
+	FrameName FrameNameTemplate
+}
+
+// MaxFPS bounds the frames output sampling rate
+const MaxFPS = 60
+
+// ValidateFPS checks the frames sampling rate; zero keeps the default of one frame per second
+func ValidateFPS(fps float64) error {
+	if math.IsNaN(fps) || math.IsInf(fps, 0) || fps < 0 || fps > MaxFPS {
+		return fmt.Errorf("fps must be between 0 and %d", MaxFPS)
+	}
+	return nil
+}
+
+// FrameRate returns the frames sampling rate, defaulting to one frame per second
+func (o FrameOptions) FrameRate() float64 {
+	if o.FPS == 0 {
+		return 1
+	}
+	return o.FPS
+}
+
+// ValidateMaxFrames checks the frames cap; zero disables it
+func ValidateMaxFrames(maxFrames int) error {
+	if maxFrames < 0 || maxFrames > MaxFramesLimit {
+		return fmt.Errorf("max_frames must be between 1 and %d", MaxFramesLimit)
+	}
+	return nil
+}
+
+// FrameOptions builds the decoding settings requested by the message
+func (v VideoProcess) FrameOptions() FrameOptions {
+	return FrameOptions{
+		ProcessID:  v.ProcessID,
+		Subtitles:  v.Subtitles,
+		AutoRotate: !v.DisableAutoRotate,
+		ToneMap:    ToneMapAlgorithm(v.ToneMap),
+		Windows:    MergeTimeWindows(v.Windows),
+		FPS:        v.FPS,
+		MaxFrames:  v.MaxFrames,
+		Sharpness:  v.Sharpness,
+		Colors:     v.Colors,
+		OCR:        v.OCR,
+		Anonymize:  v.Anonymize,
+		Image:      v.Image,
+		Archive:    v.Archive,
+		FrameName:  v.FrameName,
+	}
+}
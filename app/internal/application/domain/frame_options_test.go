@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"math"
+	"testing"
+)
+
+func TestVideoProcess_FrameOptions(t *testing.T) {
+	options := VideoProcess{Subtitles: SubtitleOptions{Extract: true}}.FrameOptions()
+	if !options.AutoRotate {
+		t.Error("Expected auto rotate enabled by default")
+	}
+	if !options.Subtitles.Extract {
+		t.Error("Expected subtitle options to be carried over")
+	}
+
+	if options.ToneMap != "" {
+		t.Errorf("Expected tone mapping disabled by default, got %s", options.ToneMap)
+	}
+
+	if (VideoProcess{ToneMap: ToneMapAuto}).FrameOptions().ToneMap != ToneMapHable {
+		t.Error("Expected auto tone map to resolve to hable")
+	}
+
+	windows := VideoProcess{Windows: []TimeWindow{{20, 30}, {0, 10}}}.FrameOptions().Windows
+	if len(windows) != 2 || windows[0].Start != 0 {
+		t.Errorf("Expected sorted windows, got %v", windows)
+	}
+
+	if (VideoProcess{DisableAutoRotate: true}).FrameOptions().AutoRotate {
+		t.Error("Expected auto rotate disabled by the request")
+	}
+
+	if rate := (VideoProcess{FPS: 2}).FrameOptions().FrameRate(); rate != 2 {
+		t.Errorf("Expected the requested fps, got %v", rate)
+	}
+}
+
+func TestValidateMaxFrames(t *testing.T) {
+	if err := ValidateMaxFrames(0); err != nil {
+		t.Errorf("Expected zero to disable the cap, got %v", err)
+	}
+	if err := ValidateMaxFrames(100); err != nil {
+		t.Errorf("Expected 100 to be valid, got %v", err)
+	}
+	if err := ValidateMaxFrames(-1); err == nil {
+		t.Error("Expected error for negative max_frames")
+	}
+	if err := ValidateMaxFrames(MaxFramesLimit + 1); err == nil {
+		t.Error("Expected error above the limit")
+	}
+}
+
+func TestValidateFPS(t *testing.T) {
+	if err := ValidateFPS(0); err != nil {
+		t.Errorf("Expected zero to keep the default, got %v", err)
+	}
+	if err := ValidateFPS(2.5); err != nil {
+		t.Errorf("Expected 2.5 to be valid, got %v", err)
+	}
+	if err := ValidateFPS(-1); err == nil {
+		t.Error("Expected error for negative fps")
+	}
+	if err := ValidateFPS(MaxFPS + 1); err == nil {
+		t.Error("Expected error above the limit")
+	}
+	for _, fps := range []float64{math.NaN(), math.Inf(1)} {
+		if err := ValidateFPS(fps); err == nil {
+			t.Errorf("Expected error for %v", fps)
+		}
+	}
+}
+
+func TestFrameOptions_FrameRate(t *testing.T) {
+	if rate := (FrameOptions{}).FrameRate(); rate != 1 {
+		t.Errorf("Expected default rate 1, got %v", rate)
+	}
+	if rate := (FrameOptions{FPS: 0.5}).FrameRate(); rate != 0.5 {
+		t.Errorf("Expected rate 0.5, got %v", rate)
+	}
+}
@@ -0,0 +1,43 @@
+package domain
+
+import "fmt"
+
+const (
+	ImageFormatPNG  = "png"
+	ImageFormatJPEG = "jpeg"
+	ImageFormatWebP = "webp"
+
+	DefaultImageQuality = 85
+)
+
+// ImageOptions selects how the frames are optimized before zipping. png keeps the pixels
+// and only recompresses; jpeg and webp are lossy at Quality (1-100). Empty Format keeps
+// the frames as extracted
+type ImageOptions struct {
+	Format  string `json:"format"`
+	Quality int    `json:"quality"`
+}
+
+func (o ImageOptions) Enabled() bool {
+	return o.Format != ""
+}
+
+// EncodeQuality returns the quality, defaulting to DefaultImageQuality
+func (o ImageOptions) EncodeQuality() int {
+	if o.Quality == 0 {
+		return DefaultImageQuality
+	}
+	return o.Quality
+}
+
+func (o ImageOptions) Validate() error {
+	switch o.Format {
+	case "", ImageFormatPNG, ImageFormatJPEG, ImageFormatWebP:
+	default:
+		return fmt.Errorf("unsupported image format: %s", o.Format)
+	}
+	if o.Quality < 0 || o.Quality > 100 {
+		return fmt.Errorf("image quality must be between 1 and 100")
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package domain
+
+import "testing"
+
+func TestImageOptions_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		options ImageOptions
+		wantErr bool
+	}{
+		{"disabled", ImageOptions{}, false},
+		{"png", ImageOptions{Format: ImageFormatPNG}, false},
+		{"jpeg with quality", ImageOptions{Format: ImageFormatJPEG, Quality: 70}, false},
+		{"webp", ImageOptions{Format: ImageFormatWebP}, false},
+		{"unknown format", ImageOptions{Format: "gif"}, true},
+		{"quality too high", ImageOptions{Format: ImageFormatJPEG, Quality: 101}, true},
+		{"negative quality", ImageOptions{Format: ImageFormatJPEG, Quality: -1}, true},
+	}
+	for _, tt := range tests {
+		if err := tt.options.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("%s: expected error %v, got %v", tt.name, tt.wantErr, err)
+		}
+	}
+}
+
+func TestImageOptions_EncodeQuality(t *testing.T) {
+	if quality := (ImageOptions{}).EncodeQuality(); quality != DefaultImageQuality {
+		t.Errorf("Expected default quality %d, got %d", DefaultImageQuality, quality)
+	}
+	if quality := (ImageOptions{Quality: 60}).EncodeQuality(); quality != 60 {
+		t.Errorf("Expected quality 60, got %d", quality)
+	}
+}
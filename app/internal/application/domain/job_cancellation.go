@@ -0,0 +1,22 @@
+package domain
+
+import "time"
+
+// cancellationPrefix holds one marker per cancelled job, in the output bucket so that the
+// worker receiving the job sees the cancellation sent to any other
+const cancellationPrefix = "cancellations/"
+
+// JobCancellation is the video.cancel message, and the marker it leaves until the job is received
+type JobCancellation struct {
+	ProcessID string    `json:"process_id"`
+	CreatedAt time.Time `json:"created_at,omitempty"`
+}
+
+// CancellationKey is the key of the marker for processID
+func CancellationKey(processID string) string {
+	return cancellationPrefix + processID + ".json"
+}
+
+func (c JobCancellation) Validate() error {
+	return ValidateProcessID(c.ProcessID)
+}
@@ -0,0 +1,18 @@
+package domain
+
+import "testing"
+
+func TestCancellationKey(t *testing.T) {
+	if key := CancellationKey("123"); key != "cancellations/123.json" {
+		t.Errorf("Expected cancellations/123.json, got %s", key)
+	}
+}
+
+func TestJobCancellation_Validate(t *testing.T) {
+	if err := (JobCancellation{ProcessID: "123"}).Validate(); err != nil {
+		t.Errorf("Expected valid cancellation, got %v", err)
+	}
+	if err := (JobCancellation{}).Validate(); err == nil {
+		t.Error("Expected error without process_id")
+	}
+}
@@ -0,0 +1,116 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// timelinePrefix holds the events of each job, one object per event in the output bucket, so
+// the timeline of a job can be read from any worker whatever the replica that processed it
+const timelinePrefix = "timelines/"
+
+// Stages of a job in its timeline. Received and result are instants; the others have a
+// started event followed by a completed or failed one
+const (
+	JobStageReceived   = "received"
+	JobStageDownload   = "download"
+	JobStageScan       = "scan"
+	JobStageProcessing = "processing"
+	JobStageUpload     = "upload"
+	JobStageResult     = "result"
+)
+
+const (
+	JobEventStarted   = "started"
+	JobEventCompleted = "completed"
+	JobEventFailed    = "failed"
+)
+
+// JobEvent is a stage transition of a job
+type JobEvent struct {
+	ProcessID string    `json:"process_id"`
+	Stage     string    `json:"stage"`
+	Status    string    `json:"status"`
+	Timestamp time.Time `json:"timestamp"`
+	// Attempt is set on the received event when the delivery count is known
+	Attempt int `json:"attempt,omitempty"`
+	// Bytes are the bytes the stage moved or produced, on completed events
+	Bytes int64  `json:"bytes,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// NewJobEvent builds the end of a stage: completed, or failed with err
+func NewJobEvent(processID, stage string, bytes int64, err error) JobEvent {
+	event := JobEvent{ProcessID: processID, Stage: stage, Status: JobEventCompleted, Bytes: bytes}
+	if err != nil {
+		event.Status = JobEventFailed
+		event.Error = err.Error()
+	}
+	return event
+}
+
+// TimelinePrefix is the prefix of the events of processID
+func TimelinePrefix(processID string) string {
+	return timelinePrefix + processID + "/"
+}
+
+// Key is the object key of the event; the zero padded timestamp lists a job's events in order
+func (e JobEvent) Key() string {
+	return fmt.Sprintf("%s%020d-%s-%s.json", TimelinePrefix(e.ProcessID), e.Timestamp.UnixNano(), e.Stage, e.Status)
+}
+
+// StageSpan is the time a job spent in one run of a stage. A stage still running, or whose
+// end event was lost, has no EndedAt
+type StageSpan struct {
+	Stage           string     `json:"stage"`
+	Status          string     `json:"status"`
+	StartedAt       time.Time  `json:"started_at"`
+	EndedAt         *time.Time `json:"ended_at,omitempty"`
+	DurationSeconds float64    `json:"duration_seconds,omitempty"`
+	Bytes           int64      `json:"bytes,omitempty"`
+	Error           string     `json:"error,omitempty"`
+}
+
+// JobTimeline is the processing history of a job, across all its attempts
+type JobTimeline struct {
+	ProcessID string      `json:"process_id"`
+	Events    []JobEvent  `json:"events"`
+	Stages    []StageSpan `json:"stages"`
+}
+
+// NewJobTimeline orders the events and pairs each started event with the next end of the
+// same stage; a retried job shows one span per attempt of each stage
+func NewJobTimeline(processID string, events []JobEvent) JobTimeline {
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Timestamp.Before(events[j].Timestamp)
+	})
+
+	timeline := JobTimeline{ProcessID: processID, Events: events, Stages: []StageSpan{}}
+	open := make(map[string]int)
+	for _, event := range events {
+		if event.Status == JobEventStarted {
+			open[event.Stage] = len(timeline.Stages)
+			timeline.Stages = append(timeline.Stages, StageSpan{
+				Stage:     event.Stage,
+				Status:    JobEventStarted,
+				StartedAt: event.Timestamp,
+			})
+			continue
+		}
+
+		i, ok := open[event.Stage]
+		if !ok {
+			continue
+		}
+		delete(open, event.Stage)
+		endedAt := event.Timestamp
+		span := &timeline.Stages[i]
+		span.Status = event.Status
+		span.EndedAt = &endedAt
+		span.DurationSeconds = endedAt.Sub(span.StartedAt).Seconds()
+		span.Bytes = event.Bytes
+		span.Error = event.Error
+	}
+	return timeline
+}
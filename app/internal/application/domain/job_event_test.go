@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNewJobEvent(t *testing.T) {
+	event := NewJobEvent("123", JobStageDownload, 2048, nil)
+	if event.Status != JobEventCompleted || event.Bytes != 2048 || event.Error != "" {
+		t.Errorf("Expected a completed event, got %+v", event)
+	}
+
+	event = NewJobEvent("123", JobStageDownload, 0, errors.New("connection reset"))
+	if event.Status != JobEventFailed || event.Error != "connection reset" {
+		t.Errorf("Expected a failed event, got %+v", event)
+	}
+}
+
+func TestJobEvent_Key(t *testing.T) {
+	earlier := JobEvent{ProcessID: "123", Stage: JobStageDownload, Status: JobEventStarted, Timestamp: time.Unix(9, 0)}
+	later := JobEvent{ProcessID: "123", Stage: JobStageDownload, Status: JobEventCompleted, Timestamp: time.Unix(10, 0)}
+
+	if !strings.HasPrefix(earlier.Key(), TimelinePrefix("123")) || !strings.HasSuffix(earlier.Key(), "-download-started.json") {
+		t.Errorf("Unexpected key %s", earlier.Key())
+	}
+	if earlier.Key() >= later.Key() {
+		t.Errorf("Expected keys listed in time order, got %s and %s", earlier.Key(), later.Key())
+	}
+}
+
+func TestNewJobTimeline(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	at := func(seconds int) time.Time { return start.Add(time.Duration(seconds) * time.Second) }
+
+	// Listed out of order: a failed first attempt, then a second one still processing
+	events := []JobEvent{
+		{Stage: JobStageDownload, Status: JobEventCompleted, Timestamp: at(40), Bytes: 2048},
+		{Stage: JobStageReceived, Status: JobEventCompleted, Timestamp: at(0), Attempt: 1},
+		{Stage: JobStageDownload, Status: JobEventStarted, Timestamp: at(1)},
+		{Stage: JobStageDownload, Status: JobEventFailed, Timestamp: at(11), Error: "connection reset"},
+		{Stage: JobStageReceived, Status: JobEventCompleted, Timestamp: at(30), Attempt: 2},
+		{Stage: JobStageDownload, Status: JobEventStarted, Timestamp: at(31)},
+		{Stage: JobStageProcessing, Status: JobEventStarted, Timestamp: at(41)},
+	}
+
+	timeline := NewJobTimeline("123", events)
+	if timeline.ProcessID != "123" || len(timeline.Events) != 7 || timeline.Events[0].Attempt != 1 {
+		t.Fatalf("Expected the events in time order, got %+v", timeline.Events)
+	}
+	if len(timeline.Stages) != 3 {
+		t.Fatalf("Expected 3 stage spans, got %+v", timeline.Stages)
+	}
+
+	failed, retried, running := timeline.Stages[0], timeline.Stages[1], timeline.Stages[2]
+	if failed.Status != JobEventFailed || failed.DurationSeconds != 10 || failed.Error != "connection reset" {
+		t.Errorf("Unexpected first download %+v", failed)
+	}
+	if retried.Status != JobEventCompleted || retried.DurationSeconds != 9 || retried.Bytes != 2048 || !retried.EndedAt.Equal(at(40)) {
+		t.Errorf("Unexpected second download %+v", retried)
+	}
+	if running.Stage != JobStageProcessing || running.Status != JobEventStarted || running.EndedAt != nil {
+		t.Errorf("Expected processing still running, got %+v", running)
+	}
+}
@@ -0,0 +1,9 @@
+package domain
+
+// JobFailure is a stage of a job that failed, as sent to the error tracker
+type JobFailure struct {
+	ProcessID string
+	TenantID  string
+	Stage     string
+	Err       error
+}
@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"fmt"
+	"path"
+	"time"
+)
+
+// jobRecordPrefix holds one record per completed job, in the output bucket so that any worker
+// can reprocess the job whatever the replica that ran it
+const jobRecordPrefix = "jobs/"
+
+// JobRecord is what the worker keeps of a completed job to run it again with other options,
+// without the producer sending the source video again
+type JobRecord struct {
+	ProcessID string `json:"process_id"`
+	TenantID  string `json:"tenant_id,omitempty"`
+
+	// Video is the source video the first run read
+	Video ObjectRef `json:"video"`
+
+	// Archive is the copy of Video kept before the worker deleted it; nil when the original
+	// was kept or no archive is configured
+	Archive *ObjectRef `json:"archive,omitempty"`
+
+	// Version is the last version of the outputs handed out, 1 for the first run
+	Version int `json:"version"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// JobRecordKey is the key of the record of processID
+func JobRecordKey(processID string) string {
+	return jobRecordPrefix + processID + ".json"
+}
+
+// Source is the video a reprocess reads: the archived copy when there is one, the original
+// otherwise
+func (r JobRecord) Source() ObjectRef {
+	if r.Archive != nil {
+		return *r.Archive
+	}
+	return r.Video
+}
+
+// ArchiveKey keys the copy of the original video of processID under prefix, keeping the video
+// name and so its extension, which the worker relies on
+func ArchiveKey(prefix, processID, videoKey string) string {
+	return joinKey(prefix, processID, path.Base(videoKey))
+}
+
+// ValidateReprocess checks a video.reprocess request: it names the process to run again and
+// takes the source from its record, so none of the source fields can be set
+func ValidateReprocess(request VideoProcess) error {
+	if err := ValidateProcessID(request.ProcessID); err != nil {
+		return err
+	}
+	if request.VideoBucket != "" || request.VideoKey != "" || request.VideoURL != "" || len(request.VideoKeys) > 0 || !request.Source.IsZero() {
+		return fmt.Errorf("the source of a reprocess is the one of process %s: video_bucket, video_key, video_keys, video_url, video_etag and video_version_id cannot be set", request.ProcessID)
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package domain
+
+import "testing"
+
+func TestJobRecord_Source(t *testing.T) {
+	record := JobRecord{ProcessID: "123", Video: ObjectRef{Bucket: "input", Key: "uploads/video.mp4", VersionID: "v1"}}
+	if source := record.Source(); source != record.Video {
+		t.Errorf("Expected the original video, got %+v", source)
+	}
+
+	record.Archive = &ObjectRef{Bucket: "archive", Key: ArchiveKey("originals", "123", record.Video.Key)}
+	if source := record.Source(); source.Bucket != "archive" || source.Key != "originals/123/video.mp4" {
+		t.Errorf("Expected the archived copy, got %+v", source)
+	}
+	if key := JobRecordKey("123"); key != "jobs/123.json" {
+		t.Errorf("Expected jobs/123.json, got %s", key)
+	}
+}
+
+func TestValidateReprocess(t *testing.T) {
+	if err := ValidateReprocess(VideoProcess{ProcessID: "123", MaxFrames: 10}); err != nil {
+		t.Errorf("Expected a valid reprocess, got %v", err)
+	}
+
+	tests := map[string]VideoProcess{
+		"no process_id":    {},
+		"video_bucket set": {ProcessID: "123", VideoBucket: "input"},
+		"video_url set":    {ProcessID: "123", VideoURL: "https://example.com/video.mp4"},
+		"video_keys set":   {ProcessID: "123", VideoKeys: []string{"a.mp4"}},
+		"source pinned":    {ProcessID: "123", Source: ObjectCondition{VersionID: "v1"}},
+	}
+	for name, request := range tests {
+		t.Run(name, func(t *testing.T) {
+			if err := ValidateReprocess(request); err == nil {
+				t.Errorf("Expected %s rejected", name)
+			}
+		})
+	}
+}
+
+func TestVideoProcess_OutputID(t *testing.T) {
+	if id := (VideoProcess{ProcessID: "123"}).OutputID(); id != "123" {
+		t.Errorf("Expected 123, got %s", id)
+	}
+	if id := (VideoProcess{ProcessID: "123", Version: 2}).OutputID(); id != "123_v2" {
+		t.Errorf("Expected 123_v2, got %s", id)
+	}
+}
@@ -0,0 +1,106 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// ErrInvalidJob marks submissions rejected before anything is staged or enqueued
+var ErrInvalidJob = errors.New("invalid job")
+
+// ErrUnauthorizedJob marks submissions without the api_key of a known producer
+var ErrUnauthorizedJob = errors.New("unauthorized job")
+
+// ErrForbiddenJob marks submissions for a tenant their producer may not send jobs for
+var ErrForbiddenJob = errors.New("forbidden job")
+
+// jobOptionFields are the processing options a client may set. Everything else, from the
+// video and its tenant to where the outputs and the result go, is owned by the submission, so
+// a client cannot point the worker at objects or destinations it chose
+var jobOptionFields = map[string]bool{
+	"output_type":         true,
+	"storage_class":       true,
+	"sprite":              true,
+	"packaging":           true,
+	"trim":                true,
+	"loudness":            true,
+	"transcription":       true,
+	"qc":                  true,
+	"fingerprint":         true,
+	"subtitles":           true,
+	"disable_auto_rotate": true,
+	"tone_map":            true,
+	"windows":             true,
+	"max_frames":          true,
+	"sharpness":           true,
+	"colors":              true,
+	"barcodes":            true,
+	"ocr":                 true,
+	"anonymize":           true,
+	"image":               true,
+	"archive":             true,
+	"frame_name":          true,
+	"accept":              true,
+	"dry_run":             true,
+	"options":             true,
+	"metadata":            true,
+}
+
+// JobSubmission is the reply to an HTTP job: where the video was staged and the id to track it by
+type JobSubmission struct {
+	ProcessID   string `json:"process_id"`
+	VideoBucket string `json:"video_bucket"`
+	VideoKey    string `json:"video_key"`
+}
+
+// JobCredentials are presented with an HTTP job to submit it as a producer, for TenantID
+type JobCredentials struct {
+	APIKey   string
+	TenantID string
+}
+
+// JobProducer is the producer a job is submitted as; its message carries the producer and
+// api_key attributes, so the input authentication checks it like any message of that
+// producer, and the tenant_id of TenantID. The zero value submits anonymous jobs, without a
+// tenant, for workers without input authentication
+type JobProducer struct {
+	Name     string
+	APIKey   string
+	TenantID string
+}
+
+// ValidateJobOptions rejects options other than the processing options, in a stable order
+func ValidateJobOptions(options map[string]interface{}) error {
+	fields := make([]string, 0, len(options))
+	for field := range options {
+		if !jobOptionFields[field] {
+			fields = append(fields, field)
+		}
+	}
+	if len(fields) > 0 {
+		sort.Strings(fields)
+		return fmt.Errorf("%w: %s cannot be set in options", ErrInvalidJob, strings.Join(fields, ", "))
+	}
+	return nil
+}
+
+// UploadKey keys a staged video under uploads/{process_id}/, keeping only the characters of
+// the client file name that are safe in a key, and its extension, which the worker relies on
+func UploadKey(processID, fileName string) string {
+	name := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		default:
+			return '_'
+		}
+	}, path.Base(strings.ReplaceAll(fileName, "\\", "/")))
+
+	if strings.Trim(name, "._") == "" {
+		name = "video"
+	}
+	return path.Join("uploads", processID, name)
+}
@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateJobOptions(t *testing.T) {
+	if err := ValidateJobOptions(nil); err != nil {
+		t.Errorf("Expected no options to be valid, got %v", err)
+	}
+	if err := ValidateJobOptions(map[string]interface{}{"output_type": "sprite"}); err != nil {
+		t.Errorf("Expected regular options to be valid, got %v", err)
+	}
+	for _, field := range []string{
+		"process_id", "video_bucket", "video_key", "video_keys", "video_url", "tenant_id",
+		"result_destinations", "output_bucket", "output_prefix", "keep_original", "unknown",
+	} {
+		err := ValidateJobOptions(map[string]interface{}{field: "x"})
+		if !errors.Is(err, ErrInvalidJob) {
+			t.Errorf("Expected ErrInvalidJob for %s, got %v", field, err)
+		}
+	}
+}
+
+func TestUploadKey(t *testing.T) {
+	tests := []struct {
+		fileName string
+		want     string
+	}{
+		{"video.mp4", "uploads/abc/video.mp4"},
+		{"../../etc/passwd", "uploads/abc/passwd"},
+		{`C:\Users\me\clip 1.mov`, "uploads/abc/clip_1.mov"},
+		{"", "uploads/abc/video"},
+		{"..", "uploads/abc/video"},
+	}
+	for _, tt := range tests {
+		if got := UploadKey("abc", tt.fileName); got != tt.want {
+			t.Errorf("UploadKey(%q) = %q, want %q", tt.fileName, got, tt.want)
+		}
+	}
+}
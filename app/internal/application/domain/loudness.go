@@ -0,0 +1,78 @@
+package domain
+
+import "fmt"
+
+// LoudnessOptions are the EBU R128 targets of the loudnorm output; zero values fall back to
+// DefaultLoudnessOptions
+type LoudnessOptions struct {
+	// Integrated is the target loudness in LUFS
+	Integrated float64 `json:"integrated"`
+
+	// TruePeak is the maximum true peak in dBTP
+	TruePeak float64 `json:"true_peak"`
+
+	// Range is the target loudness range in LU
+	Range float64 `json:"range"`
+}
+
+// DefaultLoudnessOptions are the EBU R128 broadcast targets
+var DefaultLoudnessOptions = LoudnessOptions{
+	Integrated: -23,
+	TruePeak:   -1,
+	Range:      7,
+}
+
+// WithDefaults fills the unset fields with DefaultLoudnessOptions
+func (o LoudnessOptions) WithDefaults() LoudnessOptions {
+	if o.Integrated == 0 {
+		o.Integrated = DefaultLoudnessOptions.Integrated
+	}
+	if o.TruePeak == 0 {
+		o.TruePeak = DefaultLoudnessOptions.TruePeak
+	}
+	if o.Range == 0 {
+		o.Range = DefaultLoudnessOptions.Range
+	}
+	return o
+}
+
+// Validate rejects targets outside the ranges the loudnorm filter accepts
+func (o LoudnessOptions) Validate() error {
+	if o.Integrated != 0 && (o.Integrated < -70 || o.Integrated > -5) {
+		return fmt.Errorf("loudness integrated target must be between -70 and -5 LUFS, got %v", o.Integrated)
+	}
+	if o.TruePeak < -9 || o.TruePeak > 0 {
+		return fmt.Errorf("loudness true peak must be between -9 and 0 dBTP, got %v", o.TruePeak)
+	}
+	if o.Range != 0 && (o.Range < 1 || o.Range > 20) {
+		return fmt.Errorf("loudness range must be between 1 and 20 LU, got %v", o.Range)
+	}
+	return nil
+}
+
+// ValidateLoudness checks the loudness fields of a request
+func (v VideoProcess) ValidateLoudness() error {
+	if v.OutputType != OutputTypeLoudnorm {
+		if v.Loudness != (LoudnessOptions{}) {
+			return fmt.Errorf("loudness options require the %s output", OutputTypeLoudnorm)
+		}
+		return nil
+	}
+	if len(v.Windows) > 0 {
+		return fmt.Errorf("time windows are not supported for %s output", OutputTypeLoudnorm)
+	}
+	return v.Loudness.Validate()
+}
+
+// LoudnessStats are the loudness of the audio measured before and after the normalization,
+// in LUFS (integrated and threshold), dBTP (true peak) and LU (range and offset)
+type LoudnessStats struct {
+	InputIntegrated  float64 `json:"input_integrated"`
+	InputTruePeak    float64 `json:"input_true_peak"`
+	InputRange       float64 `json:"input_range"`
+	InputThreshold   float64 `json:"input_threshold"`
+	OutputIntegrated float64 `json:"output_integrated"`
+	OutputTruePeak   float64 `json:"output_true_peak"`
+	OutputRange      float64 `json:"output_range"`
+	TargetOffset     float64 `json:"target_offset"`
+}
@@ -0,0 +1,38 @@
+package domain
+
+import "testing"
+
+func TestLoudnessOptions_WithDefaults(t *testing.T) {
+	options := LoudnessOptions{Integrated: -16}.WithDefaults()
+
+	if options.Integrated != -16 || options.TruePeak != -1 || options.Range != 7 {
+		t.Errorf("Expected only the unset fields filled, got %+v", options)
+	}
+}
+
+func TestVideoProcess_ValidateLoudness(t *testing.T) {
+	tests := []struct {
+		name    string
+		request VideoProcess
+		valid   bool
+	}{
+		{"not loudnorm", VideoProcess{}, true},
+		{"defaults", VideoProcess{OutputType: OutputTypeLoudnorm}, true},
+		{"streaming targets", VideoProcess{OutputType: OutputTypeLoudnorm, Loudness: LoudnessOptions{Integrated: -14, TruePeak: -1.5, Range: 11}}, true},
+		{"too loud", VideoProcess{OutputType: OutputTypeLoudnorm, Loudness: LoudnessOptions{Integrated: -3}}, false},
+		{"positive true peak", VideoProcess{OutputType: OutputTypeLoudnorm, Loudness: LoudnessOptions{TruePeak: 1}}, false},
+		{"range too wide", VideoProcess{OutputType: OutputTypeLoudnorm, Loudness: LoudnessOptions{Range: 30}}, false},
+		{"windows", VideoProcess{OutputType: OutputTypeLoudnorm, Windows: []TimeWindow{{Start: 1}}}, false},
+		{"options without loudnorm", VideoProcess{Loudness: LoudnessOptions{Integrated: -16}}, false},
+	}
+
+	for _, tt := range tests {
+		err := tt.request.ValidateLoudness()
+		if tt.valid && err != nil {
+			t.Errorf("%s: expected valid, got %v", tt.name, err)
+		}
+		if !tt.valid && err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// Message types carried by the input queue
+const (
+	MessageTypeVideoProcess   = "video.process"
+	MessageTypeVideoCancel    = "video.cancel"
+	MessageTypeVideoReprocess = "video.reprocess"
+	MessageTypePing           = "ping"
+)
+
+// Message attributes read and written by the router
+const (
+	MessageTypeAttribute     = "type"
+	RejectionReasonAttribute = "rejection_reason"
+)
+
+// ErrMessageNotHandled leaves the message in the queue, to be received again or moved to the
+// DLQ by the redrive policy
+var ErrMessageNotHandled = errors.New("message not handled")
+
+// ErrMessageRejected marks a message refused for its own content, e.g. a malformed body or an
+// invalid job; it says nothing about the health of the worker
+var ErrMessageRejected = errors.New("message rejected")
+
+// ErrMessageReturned leaves the message in the queue to be received again right away, by this
+// or another worker, instead of after its visibility timeout
+var ErrMessageReturned = fmt.Errorf("%w: returned to the queue", ErrMessageNotHandled)
+
+// ResolveMessageType reads the type from the "type" attribute, then from the "type" field of
+// the body. Messages without a type are video jobs, as sent before the queue carried others
+func ResolveMessageType(body string, attributes map[string]string) (string, error) {
+	if messageType := attributes[MessageTypeAttribute]; messageType != "" {
+		return messageType, nil
+	}
+
+	var envelope struct {
+		Type *string `json:"type"`
+	}
+	if err := json.Unmarshal([]byte(body), &envelope); err != nil {
+		return "", fmt.Errorf("message body is not a JSON object: %w", err)
+	}
+	if envelope.Type == nil {
+		return MessageTypeVideoProcess, nil
+	}
+	if *envelope.Type == "" {
+		return "", fmt.Errorf("message type is empty")
+	}
+	return *envelope.Type, nil
+}
@@ -0,0 +1,35 @@
+package domain
+
+import "testing"
+
+func TestResolveMessageType(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		attributes map[string]string
+		want       string
+		wantErr    bool
+	}{
+		{"video job without type", `{"process_id":"123"}`, nil, MessageTypeVideoProcess, false},
+		{"type field", `{"type":"video.cancel","process_id":"123"}`, nil, MessageTypeVideoCancel, false},
+		{"attribute wins", `{"type":"video.process"}`, map[string]string{"type": "ping"}, MessageTypePing, false},
+		{"attribute with plain text body", `hello`, map[string]string{"type": "ping"}, MessageTypePing, false},
+		{"unknown type is returned", `{"type":"video.resize"}`, nil, "video.resize", false},
+		{"empty type", `{"type":""}`, nil, "", true},
+		{"non string type", `{"type":7}`, nil, "", true},
+		{"not JSON", `hello`, nil, "", true},
+		{"JSON array", `[1,2]`, nil, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolveMessageType(tt.body, tt.attributes)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Expected error %v, got %v", tt.wantErr, err)
+			}
+			if got != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
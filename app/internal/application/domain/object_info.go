@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ObjectInfo describes the source video as stored in its bucket, read before the download and
+// echoed in the result as source
+type ObjectInfo struct {
+	SizeBytes   int64  `json:"size_bytes"`
+	ETag        string `json:"etag,omitempty"`
+	VersionID   string `json:"version_id,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	// Metadata is the user metadata of the object (x-amz-meta-*)
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// ObjectSummary is an object as listed under a prefix
+type ObjectSummary struct {
+	Key          string
+	SizeBytes    int64
+	LastModified time.Time
+}
+
+// ObjectCondition pins the version of the source video a job reads, from the video_etag and
+// video_version_id of the request; empty fields are not checked
+type ObjectCondition struct {
+	ETag      string
+	VersionID string
+}
+
+// IsZero reports whether nothing is pinned
+func (c ObjectCondition) IsZero() bool {
+	return c == ObjectCondition{}
+}
+
+// Matches reports whether the object is the pinned version; ETags are compared without quotes
+func (c ObjectCondition) Matches(info ObjectInfo) bool {
+	if c.ETag != "" && strings.Trim(c.ETag, `"`) != strings.Trim(info.ETag, `"`) {
+		return false
+	}
+	return c.VersionID == "" || c.VersionID == info.VersionID
+}
+
+// WithVersion pins the version of info when the condition has none, so the reads and deletes
+// that follow act on the object that was described; nil or unversioned objects change nothing
+func (c ObjectCondition) WithVersion(info *ObjectInfo) ObjectCondition {
+	if c.VersionID == "" && info != nil {
+		c.VersionID = info.VersionID
+	}
+	return c
+}
+
+// CheckVideoSize rejects a video larger than maxBytes; zero allows any size
+func CheckVideoSize(size, maxBytes int64) error {
+	if maxBytes > 0 && size > maxBytes {
+		return NewCodedError(ErrorCodeVideoTooLarge, fmt.Errorf("video has %d bytes, over the limit of %d", size, maxBytes))
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package domain
+
+import "testing"
+
+func TestCheckVideoSize(t *testing.T) {
+	if err := CheckVideoSize(1<<30, 0); err != nil {
+		t.Errorf("Expected no limit when zero, got %v", err)
+	}
+	if err := CheckVideoSize(100, 100); err != nil {
+		t.Errorf("Expected a video at the limit allowed, got %v", err)
+	}
+	if err := CheckVideoSize(101, 100); ErrorCode(err) != ErrorCodeVideoTooLarge {
+		t.Errorf("Expected %s, got %v", ErrorCodeVideoTooLarge, err)
+	}
+}
+
+func TestObjectCondition_Matches(t *testing.T) {
+	info := ObjectInfo{ETag: "abc", VersionID: "v2"}
+
+	tests := []struct {
+		condition ObjectCondition
+		want      bool
+	}{
+		{ObjectCondition{}, true},
+		{ObjectCondition{ETag: "abc"}, true},
+		{ObjectCondition{ETag: `"abc"`}, true},
+		{ObjectCondition{ETag: "abc", VersionID: "v2"}, true},
+		{ObjectCondition{ETag: "def"}, false},
+		{ObjectCondition{VersionID: "v1"}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.condition.Matches(info); got != tt.want {
+			t.Errorf("%+v.Matches() = %v, want %v", tt.condition, got, tt.want)
+		}
+	}
+}
+
+func TestObjectCondition_WithVersion(t *testing.T) {
+	info := &ObjectInfo{ETag: "abc", VersionID: "v2"}
+
+	if got := (ObjectCondition{ETag: "abc"}).WithVersion(info); got != (ObjectCondition{ETag: "abc", VersionID: "v2"}) {
+		t.Errorf("Expected the described version pinned, got %+v", got)
+	}
+	if got := (ObjectCondition{VersionID: "v1"}).WithVersion(info); got.VersionID != "v1" {
+		t.Errorf("Expected the requested version kept, got %+v", got)
+	}
+	if got := (ObjectCondition{}).WithVersion(nil); !got.IsZero() {
+		t.Errorf("Expected nothing pinned for an unknown object, got %+v", got)
+	}
+}
+
+func TestProcessResult_ToSuccessMessage_Source(t *testing.T) {
+	result := ProcessResult{ProcessID: "123", Success: true}
+	if _, ok := result.ToSuccessMessage()["source"]; ok {
+		t.Error("Expected no source when unknown")
+	}
+
+	result.Source = &ObjectInfo{SizeBytes: 2048, ETag: "abc"}
+	if source, _ := result.ToSuccessMessage()["source"].(*ObjectInfo); source == nil || source.SizeBytes != 2048 {
+		t.Errorf("Expected the source echoed, got %v", result.ToSuccessMessage()["source"])
+	}
+}
@@ -0,0 +1,100 @@
+package domain
+
+// PutOptions are the optional attributes of an object written by StoragePort.PutObject; empty
+// fields keep the bucket defaults
+type PutOptions struct {
+	StorageClass string
+	ContentType  string
+
+	// Metadata is user metadata, returned in ObjectInfo.Metadata
+	Metadata map[string]string
+
+	// ServerSideEncryption is AES256 or aws:kms, with KMSKeyID as the key for aws:kms (empty
+	// uses the AWS managed key)
+	ServerSideEncryption string
+	KMSKeyID             string
+
+	// Tags are the object tags, which the bucket lifecycle rules can filter on
+	Tags map[string]string
+}
+
+// PutOption sets an attribute of a PutObject
+type PutOption func(*PutOptions)
+
+// WithStorageClass writes the object in storageClass; empty keeps the bucket default
+func WithStorageClass(storageClass string) PutOption {
+	return func(o *PutOptions) {
+		o.StorageClass = storageClass
+	}
+}
+
+// WithContentType sets the Content-Type of the object
+func WithContentType(contentType string) PutOption {
+	return func(o *PutOptions) {
+		o.ContentType = contentType
+	}
+}
+
+// WithMetadata sets the user metadata of the object
+func WithMetadata(metadata map[string]string) PutOption {
+	return func(o *PutOptions) {
+		o.Metadata = metadata
+	}
+}
+
+// WithServerSideEncryption encrypts the object with algorithm, using kmsKeyID for aws:kms
+func WithServerSideEncryption(algorithm, kmsKeyID string) PutOption {
+	return func(o *PutOptions) {
+		o.ServerSideEncryption = algorithm
+		o.KMSKeyID = kmsKeyID
+	}
+}
+
+// WithTags sets the tags of the object
+func WithTags(tags map[string]string) PutOption {
+	return func(o *PutOptions) {
+		o.Tags = tags
+	}
+}
+
+// NewPutOptions applies options in order, the last one winning
+func NewPutOptions(options ...PutOption) PutOptions {
+	var o PutOptions
+	for _, option := range options {
+		option(&o)
+	}
+	return o
+}
+
+// GetOptions are the optional settings of a StoragePort.GetObject
+type GetOptions struct {
+	// RangeStart and RangeLength limit the read to a byte range; a zero RangeLength reads to
+	// the end of the object
+	RangeStart  int64
+	RangeLength int64
+}
+
+// GetOption sets a setting of a GetObject
+type GetOption func(*GetOptions)
+
+// WithRange reads length bytes from start; a zero length reads to the end
+func WithRange(start, length int64) GetOption {
+	return func(o *GetOptions) {
+		o.RangeStart = start
+		o.RangeLength = length
+	}
+}
+
+// NewGetOptions applies options in order, the last one winning
+func NewGetOptions(options ...GetOption) GetOptions {
+	var o GetOptions
+	for _, option := range options {
+		option(&o)
+	}
+	return o
+}
+
+// Ranged reports whether a byte range, rather than the whole object, is read
+func (o GetOptions) Ranged() bool {
+	return o.RangeStart > 0 || o.RangeLength > 0
+}
@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// MaxOCRLanguages bounds the languages of a request, as every language slows the recognition down
+const MaxOCRLanguages = 3
+
+// DefaultOCRLanguage is recognized when the request lists no language
+const DefaultOCRLanguage = "eng"
+
+// ocrLanguagePattern matches Tesseract language codes, e.g. por, eng or chi_sim
+var ocrLanguagePattern = regexp.MustCompile(`^[a-z]{3}(_[a-z]+)?$`)
+
+// OCROptions adds the text visible on each frame of the frames output to the frames manifest
+type OCROptions struct {
+	Enabled bool `json:"enabled"`
+
+	// Languages are the Tesseract codes of the languages expected on screen; empty means
+	// DefaultOCRLanguage
+	Languages []string `json:"languages"`
+}
+
+// LanguageCodes returns the languages to recognize
+func (o OCROptions) LanguageCodes() []string {
+	if len(o.Languages) == 0 {
+		return []string{DefaultOCRLanguage}
+	}
+	return o.Languages
+}
+
+func (o OCROptions) Validate() error {
+	if !o.Enabled {
+		if len(o.Languages) > 0 {
+			return fmt.Errorf("ocr.languages requires ocr.enabled")
+		}
+		return nil
+	}
+	if len(o.Languages) > MaxOCRLanguages {
+		return fmt.Errorf("ocr accepts at most %d languages, got %d", MaxOCRLanguages, len(o.Languages))
+	}
+	for _, language := range o.Languages {
+		if !ocrLanguagePattern.MatchString(language) {
+			return fmt.Errorf("invalid ocr language: %q", language)
+		}
+	}
+	return nil
+}
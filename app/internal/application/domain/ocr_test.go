@@ -0,0 +1,36 @@
+package domain
+
+import "testing"
+
+func TestOCROptions_LanguageCodes(t *testing.T) {
+	if languages := (OCROptions{Enabled: true}).LanguageCodes(); len(languages) != 1 || languages[0] != "eng" {
+		t.Errorf("Expected eng by default, got %v", languages)
+	}
+	if languages := (OCROptions{Enabled: true, Languages: []string{"por", "eng"}}).LanguageCodes(); len(languages) != 2 || languages[0] != "por" {
+		t.Errorf("Expected the requested languages kept, got %v", languages)
+	}
+}
+
+func TestOCROptions_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		options OCROptions
+		wantErr bool
+	}{
+		{"disabled", OCROptions{}, false},
+		{"default language", OCROptions{Enabled: true}, false},
+		{"languages", OCROptions{Enabled: true, Languages: []string{"por", "chi_sim"}}, false},
+		{"languages without enabled", OCROptions{Languages: []string{"por"}}, true},
+		{"too many languages", OCROptions{Enabled: true, Languages: []string{"por", "eng", "spa", "fra"}}, true},
+		{"uppercase", OCROptions{Enabled: true, Languages: []string{"POR"}}, true},
+		{"option injection", OCROptions{Enabled: true, Languages: []string{"--psm"}}, true},
+		{"combined codes", OCROptions{Enabled: true, Languages: []string{"por+eng"}}, true},
+	}
+
+	for _, tt := range tests {
+		err := tt.options.Validate()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: expected error %v, got %v", tt.name, tt.wantErr, err)
+		}
+	}
+}
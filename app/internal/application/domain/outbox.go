@@ -0,0 +1,57 @@
+package domain
+
+import "time"
+
+const (
+	// outboxBaseDelay is the wait before the first retry of a result message
+	outboxBaseDelay = 5 * time.Second
+
+	// outboxMaxDelay caps the backoff so a long SQS outage is retried at least every few minutes
+	outboxMaxDelay = 5 * time.Minute
+)
+
+// ObjectRef locates an S3 object; VersionID, when set, is the one version it refers to in a
+// versioned bucket
+type ObjectRef struct {
+	Bucket    string `json:"bucket"`
+	Key       string `json:"key"`
+	VersionID string `json:"version_id,omitempty"`
+}
+
+// OutboxEntry is a result message persisted before it is sent, holding the final body and
+// attributes (already encrypted, signed and offloaded) so a retry sends exactly the same message
+type OutboxEntry struct {
+	ID         string            `json:"id"`
+	ProcessID  string            `json:"process_id"`
+	Body       string            `json:"body"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+
+	// DeleteAfterSend is the source video, deleted only once the message has been accepted
+	DeleteAfterSend *ObjectRef `json:"delete_after_send,omitempty"`
+
+	Attempts      int       `json:"attempts"`
+	LastError     string    `json:"last_error,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+}
+
+// Due reports whether the entry should be sent at now
+func (e OutboxEntry) Due(now time.Time) bool {
+	return !now.Before(e.NextAttemptAt)
+}
+
+// Failed records a failed send and schedules the next attempt
+func (e *OutboxEntry) Failed(err error, now time.Time) {
+	e.Attempts++
+	e.LastError = err.Error()
+	e.NextAttemptAt = now.Add(OutboxRetryDelay(e.Attempts))
+}
+
+// OutboxRetryDelay doubles the wait after every failed attempt, up to outboxMaxDelay
+func OutboxRetryDelay(attempts int) time.Duration {
+	delay := outboxBaseDelay
+	for i := 1; i < attempts && delay < outboxMaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, outboxMaxDelay)
+}
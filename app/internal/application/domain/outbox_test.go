@@ -0,0 +1,49 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestOutboxRetryDelay(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{0, 5 * time.Second},
+		{1, 5 * time.Second},
+		{2, 10 * time.Second},
+		{4, 40 * time.Second},
+		{7, 5 * time.Minute},
+		{100, 5 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		if got := OutboxRetryDelay(tt.attempts); got != tt.want {
+			t.Errorf("OutboxRetryDelay(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestOutboxEntry_Failed(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	entry := OutboxEntry{ID: "1", NextAttemptAt: now}
+
+	if !entry.Due(now) {
+		t.Error("Expected a new entry to be due")
+	}
+
+	entry.Failed(errors.New("throttled"), now)
+	entry.Failed(errors.New("throttled"), now)
+
+	if entry.Attempts != 2 || entry.LastError != "throttled" {
+		t.Errorf("Unexpected entry %+v", entry)
+	}
+	if !entry.NextAttemptAt.Equal(now.Add(10 * time.Second)) {
+		t.Errorf("Expected next attempt in 10s, got %v", entry.NextAttemptAt.Sub(now))
+	}
+	if entry.Due(now.Add(9 * time.Second)) {
+		t.Error("Expected the entry not due before its next attempt")
+	}
+}
@@ -0,0 +1,81 @@
+package domain
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// DefaultOutputPrefix holds the outputs of jobs that do not pick an output_prefix
+const DefaultOutputPrefix = "processed"
+
+var bucketName = regexp.MustCompile(`^[a-z0-9][a-z0-9.-]{1,61}[a-z0-9]$`)
+
+// processIDPattern keeps a process_id usable as one segment of the keys it names
+var processIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,127}$`)
+
+// OutputLocation is the bucket and key prefix a job writes its outputs under
+type OutputLocation struct {
+	Bucket string
+	Prefix string
+}
+
+// Key joins the location prefix and elem, e.g. processed/frames_{id}.zip. Each elem is one
+// segment, so the key stays under the prefix whatever the names it is built from
+func (l OutputLocation) Key(elem ...string) string {
+	return joinKey(l.Prefix, elem...)
+}
+
+// joinKey joins prefix and the segments, turning the separators and the "." and ".." of a
+// segment into "_" so it cannot climb out of prefix
+func joinKey(prefix string, segments ...string) string {
+	elems := make([]string, 0, len(segments)+1)
+	elems = append(elems, prefix)
+	for _, segment := range segments {
+		segment = strings.NewReplacer("/", "_", `\`, "_").Replace(segment)
+		if segment == "." || segment == ".." {
+			segment = "_"
+		}
+		elems = append(elems, segment)
+	}
+	return path.Join(elems...)
+}
+
+// ValidateProcessID checks the process_id of a message, which names the keys of the job: up
+// to 128 letters, digits, ".", "_" and "-", starting with a letter or digit and without ".."
+func ValidateProcessID(processID string) error {
+	if processID == "" {
+		return fmt.Errorf("process_id is required")
+	}
+	if !processIDPattern.MatchString(processID) || strings.Contains(processID, "..") {
+		return fmt.Errorf("invalid process_id %q: only letters, digits, '.', '_' and '-' are allowed", processID)
+	}
+	return nil
+}
+
+// ValidateOutputBucket checks the bucket picked by a message; empty keeps the worker bucket
+func ValidateOutputBucket(bucket string) error {
+	if bucket != "" && !bucketName.MatchString(bucket) {
+		return fmt.Errorf("invalid output_bucket %q", bucket)
+	}
+	return nil
+}
+
+// ValidateOutputPrefix checks the key prefix picked by a message; empty keeps processed/. The
+// prefix is matched against the allowlists as a plain string, so "." and ".." segments, which
+// some S3 clients resolve, are rejected
+func ValidateOutputPrefix(prefix string) error {
+	if prefix == "" {
+		return nil
+	}
+	if strings.HasPrefix(prefix, "/") {
+		return fmt.Errorf("output_prefix must not start with /")
+	}
+	for _, segment := range strings.Split(strings.TrimSuffix(prefix, "/"), "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return fmt.Errorf("invalid output_prefix %q", prefix)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestOutputLocation_Key(t *testing.T) {
+	location := OutputLocation{Bucket: "product-outputs", Prefix: "team-a/frames"}
+	if key := location.Key("frames_123.zip"); key != "team-a/frames/frames_123.zip" {
+		t.Errorf("Expected team-a/frames/frames_123.zip, got %s", key)
+	}
+	if key := location.Key("123", "hls", "master.m3u8"); key != "team-a/frames/123/hls/master.m3u8" {
+		t.Errorf("Expected team-a/frames/123/hls/master.m3u8, got %s", key)
+	}
+	if key := location.Key("..", "vision_../../x.json"); key != "team-a/frames/_/vision_.._.._x.json" {
+		t.Errorf("Expected each element kept under the prefix, got %s", key)
+	}
+}
+
+func TestValidateProcessID(t *testing.T) {
+	for _, id := range []string{"123", "job-1", "a1b2_c3.d4", "550e8400-e29b-41d4-a716-446655440000"} {
+		if err := ValidateProcessID(id); err != nil {
+			t.Errorf("Expected %q to be valid, got %v", id, err)
+		}
+	}
+	for _, id := range []string{"", "..", "a/b", `a\b`, "job..1", ".job", "-job", "job 1", strings.Repeat("a", 129)} {
+		if err := ValidateProcessID(id); err == nil {
+			t.Errorf("Expected %q to be invalid", id)
+		}
+	}
+}
+
+func TestValidateOutputBucket(t *testing.T) {
+	for _, bucket := range []string{"", "product-outputs", "outputs.example"} {
+		if err := ValidateOutputBucket(bucket); err != nil {
+			t.Errorf("Expected %q to be valid, got %v", bucket, err)
+		}
+	}
+	for _, bucket := range []string{"Outputs", "ab", "bucket/key", "-outputs"} {
+		if err := ValidateOutputBucket(bucket); err == nil {
+			t.Errorf("Expected %q to be invalid", bucket)
+		}
+	}
+}
+
+func TestValidateOutputPrefix(t *testing.T) {
+	for _, prefix := range []string{"", "team-a", "team-a/frames/"} {
+		if err := ValidateOutputPrefix(prefix); err != nil {
+			t.Errorf("Expected %q to be valid, got %v", prefix, err)
+		}
+	}
+	for _, prefix := range []string{"/team-a", "team-a/../timelines", "team-a//frames", "./team-a"} {
+		if err := ValidateOutputPrefix(prefix); err == nil {
+			t.Errorf("Expected %q to be invalid", prefix)
+		}
+	}
+}
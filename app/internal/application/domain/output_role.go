@@ -0,0 +1,64 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// OutputRoleConfig is the IAM role the worker assumes to write to the buckets a tenant owns,
+// so its own role needs no access to them
+type OutputRoleConfig struct {
+	RoleARN string `json:"role_arn"`
+
+	// ExternalID is passed when assuming the role, as its trust policy may require
+	ExternalID string `json:"external_id,omitempty"`
+}
+
+func (c OutputRoleConfig) Validate() error {
+	if !strings.HasPrefix(c.RoleARN, "arn:") || !strings.Contains(c.RoleARN, ":role/") {
+		return fmt.Errorf("output role must be an IAM role ARN, got %q", c.RoleARN)
+	}
+	return nil
+}
+
+// OutputRoute is the output role of a bucket, assumed only for the jobs of the tenants that own it
+type OutputRoute struct {
+	OutputRoleConfig
+
+	Tenants []string
+}
+
+// OutputRoles maps each bucket in the allowed_outputs of a tenant with an output role to that
+// role and the tenants owning the bucket. A bucket is reached through a single client, so two
+// tenants mapping it to different roles is an error
+func (r TenantRegistry) OutputRoles() (map[string]OutputRoute, error) {
+	roles := map[string]OutputRoute{}
+	owners := map[string]string{}
+
+	tenantIDs := make([]string, 0, len(r))
+	for tenantID := range r {
+		tenantIDs = append(tenantIDs, tenantID)
+	}
+	slices.Sort(tenantIDs)
+
+	for _, tenantID := range tenantIDs {
+		cfg := r[tenantID]
+		if cfg.OutputRole == nil {
+			continue
+		}
+		for _, rule := range cfg.AllowedOutputs {
+			route, ok := roles[rule.Bucket]
+			if ok && route.OutputRoleConfig != *cfg.OutputRole {
+				return nil, fmt.Errorf("bucket %s is mapped to different output roles by tenants %s and %s", rule.Bucket, owners[rule.Bucket], tenantID)
+			}
+			route.OutputRoleConfig = *cfg.OutputRole
+			if !slices.Contains(route.Tenants, tenantID) {
+				route.Tenants = append(route.Tenants, tenantID)
+			}
+			roles[rule.Bucket] = route
+			owners[rule.Bucket] = tenantID
+		}
+	}
+	return roles, nil
+}
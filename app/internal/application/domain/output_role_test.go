@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseTenantRegistry_OutputRole(t *testing.T) {
+	registry, err := ParseTenantRegistry([]byte(`{
+		"acme":{"allowed_outputs":["acme-frames/processed/","acme-archive"],"output_role":{"role_arn":"arn:aws:iam::123:role/frames-writer","external_id":"acme"}},
+		"globex":{"allowed_outputs":["shared-frames/globex/"]}
+	}`))
+	if err != nil {
+		t.Fatalf("ParseTenantRegistry failed: %v", err)
+	}
+
+	roles, err := registry.OutputRoles()
+	if err != nil {
+		t.Fatalf("OutputRoles failed: %v", err)
+	}
+	want := OutputRoleConfig{RoleARN: "arn:aws:iam::123:role/frames-writer", ExternalID: "acme"}
+	if len(roles) != 2 || roles["acme-frames"].OutputRoleConfig != want || roles["acme-archive"].OutputRoleConfig != want {
+		t.Errorf("Expected both acme buckets mapped to %v, got %v", want, roles)
+	}
+	if tenants := roles["acme-frames"].Tenants; !slices.Equal(tenants, []string{"acme"}) {
+		t.Errorf("Expected the role used for acme only, got %v", tenants)
+	}
+	if _, ok := roles["shared-frames"]; ok {
+		t.Error("Expected no role for a tenant without output_role")
+	}
+}
+
+func TestParseTenantRegistry_OutputRoleInvalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+	}{
+		{"not a role", `{"acme":{"allowed_outputs":["acme-frames"],"output_role":{"role_arn":"arn:aws:iam::123:user/frames"}}}`},
+		{"empty role", `{"acme":{"allowed_outputs":["acme-frames"],"output_role":{}}}`},
+		{"no outputs", `{"acme":{"output_role":{"role_arn":"arn:aws:iam::123:role/frames-writer"}}}`},
+		{"conflicting roles", `{
+			"acme":{"allowed_outputs":["shared-frames/acme/"],"output_role":{"role_arn":"arn:aws:iam::123:role/acme"}},
+			"globex":{"allowed_outputs":["shared-frames/globex/"],"output_role":{"role_arn":"arn:aws:iam::456:role/globex"}}
+		}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseTenantRegistry([]byte(tt.config)); err == nil {
+				t.Error("Expected error for an invalid output role")
+			}
+		})
+	}
+}
+
+func TestParseTenantRegistry_SharedOutputRole(t *testing.T) {
+	registry, err := ParseTenantRegistry([]byte(`{
+		"acme":{"allowed_outputs":["shared-frames/acme/"],"output_role":{"role_arn":"arn:aws:iam::123:role/frames"}},
+		"globex":{"allowed_outputs":["shared-frames/globex/"],"output_role":{"role_arn":"arn:aws:iam::123:role/frames"}}
+	}`))
+	if err != nil {
+		t.Fatalf("Expected tenants sharing a bucket and its role to be valid, got %v", err)
+	}
+	roles, _ := registry.OutputRoles()
+	if len(roles) != 1 || !slices.Equal(roles["shared-frames"].Tenants, []string{"acme", "globex"}) {
+		t.Errorf("Expected a single routed bucket owned by both tenants, got %v", roles)
+	}
+}
@@ -0,0 +1,45 @@
+package domain
+
+import "fmt"
+
+const (
+	OutputTypeFrames      = "frames"
+	OutputTypeSprite      = "sprite"
+	OutputTypeHLS         = "hls"
+	OutputTypeDASH        = "dash"
+	OutputTypeConcat      = "concat"
+	OutputTypeTrim        = "trim"
+	OutputTypeLoudnorm    = "loudnorm"
+	OutputTypeQC          = "qc"
+	OutputTypeFingerprint = "fingerprint"
+)
+
+var supportedOutputTypes = map[string]bool{
+	OutputTypeFrames:      true,
+	OutputTypeSprite:      true,
+	OutputTypeHLS:         true,
+	OutputTypeDASH:        true,
+	OutputTypeConcat:      true,
+	OutputTypeTrim:        true,
+	OutputTypeLoudnorm:    true,
+	OutputTypeQC:          true,
+	OutputTypeFingerprint: true,
+}
+
+// ValidateOutputType checks that the output type is supported; empty means "frames"
+func ValidateOutputType(outputType string) error {
+	if outputType == "" || supportedOutputTypes[outputType] {
+		return nil
+	}
+	return fmt.Errorf("unsupported output type: %s", outputType)
+}
+
+// IsPackagingOutput reports whether the output is a streaming package (a directory tree) instead of a zip
+func IsPackagingOutput(outputType string) bool {
+	return outputType == OutputTypeHLS || outputType == OutputTypeDASH
+}
+
+// IsVideoOutput reports whether the output is a single edited video instead of a zip
+func IsVideoOutput(outputType string) bool {
+	return outputType == OutputTypeConcat || outputType == OutputTypeTrim || outputType == OutputTypeLoudnorm
+}
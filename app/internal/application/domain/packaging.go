@@ -0,0 +1,70 @@
+package domain
+
+import "fmt"
+
+const (
+	HLSMasterPlaylist = "master.m3u8"
+	DASHManifest      = "manifest.mpd"
+
+	maxRenditions     = 6
+	maxSegmentSeconds = 60
+)
+
+// Rendition is one quality level of the packaged stream; the width follows the source aspect ratio
+type Rendition struct {
+	Height      int `json:"height"`
+	BitrateKbps int `json:"bitrate_kbps"`
+}
+
+// PackagingOptions configures the HLS/DASH output; zero values fall back to DefaultPackagingOptions
+type PackagingOptions struct {
+	SegmentSeconds int         `json:"segment_seconds"`
+	Renditions     []Rendition `json:"renditions"`
+}
+
+var DefaultPackagingOptions = PackagingOptions{
+	SegmentSeconds: 6,
+	Renditions: []Rendition{
+		{Height: 360, BitrateKbps: 800},
+		{Height: 720, BitrateKbps: 2800},
+	},
+}
+
+// WithDefaults fills the unset fields with DefaultPackagingOptions
+func (o PackagingOptions) WithDefaults() PackagingOptions {
+	if o.SegmentSeconds == 0 {
+		o.SegmentSeconds = DefaultPackagingOptions.SegmentSeconds
+	}
+	if len(o.Renditions) == 0 {
+		o.Renditions = append([]Rendition(nil), DefaultPackagingOptions.Renditions...)
+	}
+	return o
+}
+
+// Validate rejects renditions ffmpeg cannot encode and ladders too large for a single job
+func (o PackagingOptions) Validate() error {
+	if o.SegmentSeconds < 0 || o.SegmentSeconds > maxSegmentSeconds {
+		return fmt.Errorf("segment duration must be between 1 and %d seconds", maxSegmentSeconds)
+	}
+	if len(o.Renditions) > maxRenditions {
+		return fmt.Errorf("at most %d renditions are supported", maxRenditions)
+	}
+	for _, rendition := range o.Renditions {
+		// libx264 requires even dimensions
+		if rendition.Height <= 0 || rendition.Height%2 != 0 {
+			return fmt.Errorf("invalid rendition height: %d", rendition.Height)
+		}
+		if rendition.BitrateKbps <= 0 {
+			return fmt.Errorf("invalid rendition bitrate: %d", rendition.BitrateKbps)
+		}
+	}
+	return nil
+}
+
+// PackagingManifest returns the entry point file name for the packaging output type
+func PackagingManifest(outputType string) string {
+	if outputType == OutputTypeDASH {
+		return DASHManifest
+	}
+	return HLSMasterPlaylist
+}
@@ -0,0 +1,56 @@
+package domain
+
+import "testing"
+
+func TestPackagingOptions_WithDefaults(t *testing.T) {
+	options := PackagingOptions{}.WithDefaults()
+
+	if options.SegmentSeconds != DefaultPackagingOptions.SegmentSeconds {
+		t.Errorf("Expected default segment duration, got %d", options.SegmentSeconds)
+	}
+	if len(options.Renditions) != len(DefaultPackagingOptions.Renditions) {
+		t.Fatalf("Expected default renditions, got %v", options.Renditions)
+	}
+
+	options.Renditions[0].Height = 1080
+	if DefaultPackagingOptions.Renditions[0].Height == 1080 {
+		t.Error("Expected defaults to be copied, not shared")
+	}
+}
+
+func TestPackagingOptions_Validate(t *testing.T) {
+	valid := PackagingOptions{SegmentSeconds: 4, Renditions: []Rendition{{Height: 480, BitrateKbps: 1200}}}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("Expected valid options, got %v", err)
+	}
+
+	invalid := []PackagingOptions{
+		{SegmentSeconds: 61},
+		{Renditions: []Rendition{{Height: 481, BitrateKbps: 1200}}},
+		{Renditions: []Rendition{{Height: 480}}},
+		{Renditions: make([]Rendition, maxRenditions+1)},
+	}
+	for _, options := range invalid {
+		if err := options.Validate(); err == nil {
+			t.Errorf("Expected error for %+v", options)
+		}
+	}
+}
+
+func TestPackagingManifest(t *testing.T) {
+	if PackagingManifest(OutputTypeHLS) != HLSMasterPlaylist {
+		t.Errorf("Expected %s for hls", HLSMasterPlaylist)
+	}
+	if PackagingManifest(OutputTypeDASH) != DASHManifest {
+		t.Errorf("Expected %s for dash", DASHManifest)
+	}
+}
+
+func TestIsPackagingOutput(t *testing.T) {
+	if !IsPackagingOutput(OutputTypeHLS) || !IsPackagingOutput(OutputTypeDASH) {
+		t.Error("Expected hls and dash to be packaging outputs")
+	}
+	if IsPackagingOutput(OutputTypeFrames) || IsPackagingOutput(OutputTypeSprite) {
+		t.Error("Expected frames and sprite not to be packaging outputs")
+	}
+}
@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// PayloadPointerClass is the marker used by the SQS extended client for payloads offloaded to S3
+const PayloadPointerClass = "software.amazon.payloadoffloading.PayloadS3Pointer"
+
+// PayloadSizeAttribute is the message attribute carrying the original payload size
+const PayloadSizeAttribute = "ExtendedPayloadSize"
+
+// PayloadPointer references a message body stored in S3
+type PayloadPointer struct {
+	Bucket string `json:"s3BucketName"`
+	Key    string `json:"s3Key"`
+}
+
+// EncodePayloadPointer serializes the pointer in the extended client format:
+// ["software.amazon.payloadoffloading.PayloadS3Pointer",{"s3BucketName":"...","s3Key":"..."}]
+func EncodePayloadPointer(pointer PayloadPointer) (string, error) {
+	body, err := json.Marshal([]interface{}{PayloadPointerClass, pointer})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal payload pointer: %w", err)
+	}
+	return string(body), nil
+}
+
+// DecodePayloadPointer returns the pointer when the body is an extended client S3 pointer
+func DecodePayloadPointer(body string) (PayloadPointer, bool) {
+	var parts []json.RawMessage
+	if err := json.Unmarshal([]byte(body), &parts); err != nil || len(parts) != 2 {
+		return PayloadPointer{}, false
+	}
+
+	var class string
+	if err := json.Unmarshal(parts[0], &class); err != nil || class != PayloadPointerClass {
+		return PayloadPointer{}, false
+	}
+
+	var pointer PayloadPointer
+	if err := json.Unmarshal(parts[1], &pointer); err != nil || pointer.Bucket == "" || pointer.Key == "" {
+		return PayloadPointer{}, false
+	}
+
+	return pointer, true
+}
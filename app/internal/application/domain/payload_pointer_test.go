@@ -0,0 +1,41 @@
+package domain
+
+import "testing"
+
+func TestEncodeDecodePayloadPointer(t *testing.T) {
+	pointer := PayloadPointer{Bucket: "payload-bucket", Key: "payloads/abc.json"}
+
+	body, err := EncodePayloadPointer(pointer)
+	if err != nil {
+		t.Fatalf("EncodePayloadPointer failed: %v", err)
+	}
+
+	expected := `["software.amazon.payloadoffloading.PayloadS3Pointer",{"s3BucketName":"payload-bucket","s3Key":"payloads/abc.json"}]`
+	if body != expected {
+		t.Errorf("Expected %s, got %s", expected, body)
+	}
+
+	decoded, ok := DecodePayloadPointer(body)
+	if !ok {
+		t.Fatal("Expected body to be recognized as a payload pointer")
+	}
+	if decoded != pointer {
+		t.Errorf("Expected %+v, got %+v", pointer, decoded)
+	}
+}
+
+func TestDecodePayloadPointer_NotAPointer(t *testing.T) {
+	bodies := []string{
+		`{"process_id":"123"}`,
+		`["other.Class",{"s3BucketName":"b","s3Key":"k"}]`,
+		`["software.amazon.payloadoffloading.PayloadS3Pointer",{"s3BucketName":"b"}]`,
+		`["software.amazon.payloadoffloading.PayloadS3Pointer"]`,
+		`not json`,
+	}
+
+	for _, body := range bodies {
+		if _, ok := DecodePayloadPointer(body); ok {
+			t.Errorf("Expected %s not to be recognized as a payload pointer", body)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package domain
+
+// Steps of the processing of a video, reported in ProcessingProgress
+const (
+	ProcessingStepExtract     = "extract"
+	ProcessingStepPostProcess = "post_process"
+	ProcessingStepArchive     = "archive"
+)
+
+// ProcessingRequest is a video handed to a VideoProcessorPort. New inputs are added as fields,
+// so the port does not change with them
+type ProcessingRequest struct {
+	// JobID keys the working files, so concurrent jobs never share frames or zips
+	JobID     string
+	VideoPath string
+	Options   FrameOptions
+
+	// Sprite lays out the sheets of GenerateSpriteSheet; ProcessVideo ignores it
+	Sprite SpriteOptions
+
+	// OnProgress, when set, is called as each step of the processing finishes; it runs on
+	// the processing goroutine and must not block
+	OnProgress func(ProcessingProgress)
+}
+
+// ProcessingProgress reports a finished step of the processing and the frames (or sprite
+// sheets) it left
+type ProcessingProgress struct {
+	Step   string
+	Frames int
+}
+
+// Report calls OnProgress, if set
+func (r ProcessingRequest) Report(step string, frames int) {
+	if r.OnProgress != nil {
+		r.OnProgress(ProcessingProgress{Step: step, Frames: frames})
+	}
+}
+
+// ProcessingResult is what a VideoProcessorPort produced
+type ProcessingResult struct {
+	// Artifacts are the local zip files, one per part, which the caller removes
+	Artifacts []string
+
+	// FrameCount is the number of frames, or sprite sheets, in the artifacts
+	FrameCount int
+
+	// Video describes the video as probed; zero when the options did not need a probe
+	Video VideoInfo
+}
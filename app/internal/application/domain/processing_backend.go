@@ -0,0 +1,19 @@
+package domain
+
+// Backends that extract the frames of a video, reported in the result as processing_backend
+const (
+	// ProcessingBackendLocal is ffmpeg in the worker pod
+	ProcessingBackendLocal = "local"
+
+	// ProcessingBackendRemote is the offload service configured with REMOTE_PROCESSING
+	ProcessingBackendRemote = "remote"
+)
+
+// Reasons for the backend chosen for a video, recorded in worker_processing_backend_total
+const (
+	RoutingReasonDefault     = "default"
+	RoutingReasonSize        = "size"
+	RoutingReasonDuration    = "duration"
+	RoutingReasonLoad        = "load"
+	RoutingReasonUnsupported = "unsupported"
+)
@@ -0,0 +1,103 @@
+package domain
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+// Message attributes identifying the producer of an input message on a shared queue
+const (
+	ProducerAttribute = "producer"
+	APIKeyAttribute   = "api_key"
+)
+
+// ProducerCredentials authenticate the messages of one producer: an HMAC-SHA256 signature of
+// the body with SigningSecret in the "signature" attribute, its APIKey in the "api_key"
+// attribute, or both when both are set
+type ProducerCredentials struct {
+	SigningSecret string `json:"signing_secret,omitempty"`
+	APIKey        string `json:"api_key,omitempty"`
+
+	// Tenants, when set, are the only tenant_id the jobs of the producer may carry
+	Tenants []string `json:"tenants,omitempty"`
+}
+
+// AllowsTenant reports whether the producer may send jobs for tenantID; a producer without
+// Tenants sends jobs for any tenant
+func (c ProducerCredentials) AllowsTenant(tenantID string) bool {
+	return len(c.Tenants) == 0 || slices.Contains(c.Tenants, tenantID)
+}
+
+// CheckTenant requires a job message of a producer bound to Tenants to carry one of them.
+// The other message types carry no tenant_id. An offloaded body is only fetched by the
+// handler, after the check, so it is refused
+func (c ProducerCredentials) CheckTenant(body string, attributes map[string]string) error {
+	if len(c.Tenants) == 0 {
+		return nil
+	}
+	if _, ok := DecodePayloadPointer(body); ok {
+		return fmt.Errorf("offloaded payloads are not accepted from a producer bound to tenants")
+	}
+
+	messageType, err := ResolveMessageType(body, attributes)
+	if err != nil {
+		return err
+	}
+	if messageType != MessageTypeVideoProcess && messageType != MessageTypeVideoReprocess {
+		return nil
+	}
+
+	var job struct {
+		TenantID string `json:"tenant_id"`
+	}
+	if err := json.Unmarshal([]byte(body), &job); err != nil {
+		return fmt.Errorf("message body is not a JSON object: %w", err)
+	}
+	if !c.AllowsTenant(job.TenantID) {
+		return fmt.Errorf("tenant %q is not allowed", job.TenantID)
+	}
+	return nil
+}
+
+// ProducerRegistry maps the producer attribute of a message to its credentials
+type ProducerRegistry map[string]ProducerCredentials
+
+// ParseProducerRegistry builds a ProducerRegistry from its JSON representation, e.g.
+// {"team-a":{"signing_secret":"..."},"team-b":{"api_key":"..."}}
+func ParseProducerRegistry(data []byte) (ProducerRegistry, error) {
+	registry := ProducerRegistry{}
+	if len(data) == 0 {
+		return registry, nil
+	}
+
+	if err := json.Unmarshal(data, &registry); err != nil {
+		return nil, fmt.Errorf("invalid producer configuration: %w", err)
+	}
+
+	for producer, credentials := range registry {
+		if producer == "" {
+			return nil, fmt.Errorf("producer name is empty")
+		}
+		if credentials.SigningSecret == "" && credentials.APIKey == "" {
+			return nil, fmt.Errorf("producer %s: signing_secret or api_key is required", producer)
+		}
+		if slices.Contains(credentials.Tenants, "") {
+			return nil, fmt.Errorf("producer %s: tenant is empty", producer)
+		}
+	}
+	return registry, nil
+}
+
+// ByAPIKey finds the producer holding apiKey, comparing every key in constant time
+func (r ProducerRegistry) ByAPIKey(apiKey string) (string, ProducerCredentials, bool) {
+	var name string
+	var found ProducerCredentials
+	for producer, credentials := range r {
+		if credentials.APIKey != "" && subtle.ConstantTimeCompare([]byte(apiKey), []byte(credentials.APIKey)) == 1 {
+			name, found = producer, credentials
+		}
+	}
+	return name, found, name != ""
+}
@@ -0,0 +1,74 @@
+package domain
+
+import "testing"
+
+func TestParseProducerRegistry(t *testing.T) {
+	registry, err := ParseProducerRegistry([]byte(`{"team-a":{"signing_secret":"secret-a"},"team-b":{"api_key":"key-b"}}`))
+	if err != nil {
+		t.Fatalf("ParseProducerRegistry failed: %v", err)
+	}
+	if registry["team-a"].SigningSecret != "secret-a" || registry["team-b"].APIKey != "key-b" {
+		t.Errorf("Unexpected producers %+v", registry)
+	}
+
+	empty, err := ParseProducerRegistry(nil)
+	if err != nil || len(empty) != 0 {
+		t.Errorf("Expected an empty registry, got %v (%v)", empty, err)
+	}
+}
+
+func TestParseProducerRegistry_Invalid(t *testing.T) {
+	for _, config := range []string{
+		`{"team-a":"secret"}`,
+		`{"team-a":{}}`,
+		`{"":{"api_key":"key"}}`,
+		`{"team-a":{"api_key":"key","tenants":[""]}}`,
+	} {
+		if _, err := ParseProducerRegistry([]byte(config)); err == nil {
+			t.Errorf("Expected %s rejected", config)
+		}
+	}
+}
+
+func TestProducerRegistry_ByAPIKey(t *testing.T) {
+	registry := ProducerRegistry{
+		"team-a": {SigningSecret: "secret-a"},
+		"team-b": {APIKey: "key-b"},
+	}
+
+	if name, credentials, ok := registry.ByAPIKey("key-b"); !ok || name != "team-b" || credentials.APIKey != "key-b" {
+		t.Errorf("Expected team-b, got %q (%v)", name, ok)
+	}
+	for _, key := range []string{"", "secret-a", "key-c"} {
+		if name, _, ok := registry.ByAPIKey(key); ok {
+			t.Errorf("Expected no producer for %q, got %s", key, name)
+		}
+	}
+}
+
+func TestProducerCredentials_CheckTenant(t *testing.T) {
+	bound := ProducerCredentials{APIKey: "key-a", Tenants: []string{"acme", "initech"}}
+
+	tests := []struct {
+		name        string
+		credentials ProducerCredentials
+		body        string
+		attributes  map[string]string
+		wantErr     bool
+	}{
+		{"allowed tenant", bound, `{"process_id":"1","tenant_id":"acme"}`, nil, false},
+		{"reprocess of an allowed tenant", bound, `{"type":"video.reprocess","process_id":"1","tenant_id":"initech"}`, nil, false},
+		{"other tenant", bound, `{"process_id":"1","tenant_id":"globex"}`, nil, true},
+		{"no tenant", bound, `{"process_id":"1"}`, nil, true},
+		{"other tenant by attribute type", bound, `{"process_id":"1","tenant_id":"globex"}`, map[string]string{"type": "video.process"}, true},
+		{"cancel", bound, `{"type":"video.cancel","process_id":"1"}`, nil, false},
+		{"offloaded payload", bound, `["software.amazon.payloadoffloading.PayloadS3Pointer",{"s3BucketName":"payloads","s3Key":"a.json"}]`, nil, true},
+		{"unbound producer", ProducerCredentials{APIKey: "key-b"}, `{"process_id":"1","tenant_id":"globex"}`, nil, false},
+	}
+	for _, tt := range tests {
+		err := tt.credentials.CheckTenant(tt.body, tt.attributes)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: expected error %v, got %v", tt.name, tt.wantErr, err)
+		}
+	}
+}
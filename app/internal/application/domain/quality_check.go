@@ -0,0 +1,84 @@
+package domain
+
+import "fmt"
+
+// MaxQCIntervals bounds the intervals of each kind in a QC report, so a flickering recording
+// cannot blow up the result message
+const MaxQCIntervals = 500
+
+// QCOptions are the thresholds of the qc output; zero values fall back to DefaultQCOptions
+type QCOptions struct {
+	// BlackMinSeconds is the shortest run of black frames reported
+	BlackMinSeconds float64 `json:"black_min_seconds"`
+
+	// BlackPixelThreshold is the luminance (0 to 1) under which a pixel counts as black
+	BlackPixelThreshold float64 `json:"black_pixel_threshold"`
+
+	// SilenceNoiseDB is the level in dB under which the audio counts as silence
+	SilenceNoiseDB float64 `json:"silence_noise_db"`
+
+	// SilenceMinSeconds is the shortest silence reported
+	SilenceMinSeconds float64 `json:"silence_min_seconds"`
+}
+
+var DefaultQCOptions = QCOptions{
+	BlackMinSeconds:     2,
+	BlackPixelThreshold: 0.1,
+	SilenceNoiseDB:      -50,
+	SilenceMinSeconds:   2,
+}
+
+// WithDefaults fills the unset fields with DefaultQCOptions
+func (o QCOptions) WithDefaults() QCOptions {
+	if o.BlackMinSeconds == 0 {
+		o.BlackMinSeconds = DefaultQCOptions.BlackMinSeconds
+	}
+	if o.BlackPixelThreshold == 0 {
+		o.BlackPixelThreshold = DefaultQCOptions.BlackPixelThreshold
+	}
+	if o.SilenceNoiseDB == 0 {
+		o.SilenceNoiseDB = DefaultQCOptions.SilenceNoiseDB
+	}
+	if o.SilenceMinSeconds == 0 {
+		o.SilenceMinSeconds = DefaultQCOptions.SilenceMinSeconds
+	}
+	return o
+}
+
+// Validate rejects thresholds the blackdetect and silencedetect filters do not accept
+func (o QCOptions) Validate() error {
+	if o.BlackMinSeconds < 0 || o.SilenceMinSeconds < 0 {
+		return fmt.Errorf("qc minimum durations must not be negative")
+	}
+	if o.BlackPixelThreshold < 0 || o.BlackPixelThreshold > 1 {
+		return fmt.Errorf("qc black pixel threshold must be between 0 and 1, got %v", o.BlackPixelThreshold)
+	}
+	if o.SilenceNoiseDB > 0 || o.SilenceNoiseDB < -100 {
+		return fmt.Errorf("qc silence noise must be between -100 and 0 dB, got %v", o.SilenceNoiseDB)
+	}
+	return nil
+}
+
+// ValidateQC checks the qc fields of a request
+func (v VideoProcess) ValidateQC() error {
+	if v.OutputType != OutputTypeQC {
+		if v.QC != (QCOptions{}) {
+			return fmt.Errorf("qc options require the %s output", OutputTypeQC)
+		}
+		return nil
+	}
+	if len(v.Windows) > 0 {
+		return fmt.Errorf("time windows are not supported for %s output", OutputTypeQC)
+	}
+	return v.QC.Validate()
+}
+
+// QCReport lists the black and silent intervals of a video, in seconds. Truncated is set when
+// either list was cut at MaxQCIntervals
+type QCReport struct {
+	DurationSeconds  float64      `json:"duration_seconds"`
+	HasAudio         bool         `json:"has_audio"`
+	BlackIntervals   []TimeWindow `json:"black_intervals"`
+	SilenceIntervals []TimeWindow `json:"silence_intervals"`
+	Truncated        bool         `json:"truncated,omitempty"`
+}
@@ -0,0 +1,38 @@
+package domain
+
+import "testing"
+
+func TestQCOptions_WithDefaults(t *testing.T) {
+	options := QCOptions{SilenceNoiseDB: -30}.WithDefaults()
+
+	if options.SilenceNoiseDB != -30 || options.BlackMinSeconds != 2 || options.BlackPixelThreshold != 0.1 || options.SilenceMinSeconds != 2 {
+		t.Errorf("Expected only the unset fields filled, got %+v", options)
+	}
+}
+
+func TestVideoProcess_ValidateQC(t *testing.T) {
+	tests := []struct {
+		name    string
+		request VideoProcess
+		valid   bool
+	}{
+		{"not qc", VideoProcess{}, true},
+		{"defaults", VideoProcess{OutputType: OutputTypeQC}, true},
+		{"custom thresholds", VideoProcess{OutputType: OutputTypeQC, QC: QCOptions{BlackMinSeconds: 0.5, BlackPixelThreshold: 0.2, SilenceNoiseDB: -35, SilenceMinSeconds: 1}}, true},
+		{"negative duration", VideoProcess{OutputType: OutputTypeQC, QC: QCOptions{BlackMinSeconds: -1}}, false},
+		{"pixel threshold above 1", VideoProcess{OutputType: OutputTypeQC, QC: QCOptions{BlackPixelThreshold: 1.5}}, false},
+		{"positive noise", VideoProcess{OutputType: OutputTypeQC, QC: QCOptions{SilenceNoiseDB: 3}}, false},
+		{"windows", VideoProcess{OutputType: OutputTypeQC, Windows: []TimeWindow{{Start: 1}}}, false},
+		{"options without qc", VideoProcess{QC: QCOptions{BlackMinSeconds: 1}}, false},
+	}
+
+	for _, tt := range tests {
+		err := tt.request.ValidateQC()
+		if tt.valid && err != nil {
+			t.Errorf("%s: expected valid, got %v", tt.name, err)
+		}
+		if !tt.valid && err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
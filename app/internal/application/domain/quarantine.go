@@ -0,0 +1,68 @@
+package domain
+
+import "errors"
+
+// QuarantineReasonTag is the tag holding the error code on an object moved to the quarantine
+const QuarantineReasonTag = "quarantine-reason"
+
+// quarantinableErrorCodes are the final failures caused by the video itself, which the next
+// job with the same object would hit again
+var quarantinableErrorCodes = []string{
+	ErrorCodeMalwareDetected,
+	ErrorCodeUnsupportedFormat,
+	ErrorCodeEmptyVideo,
+	ErrorCodeVideoTooLarge,
+	ErrorCodeUnsupportedCodec,
+}
+
+// QuarantineReason returns the code of err when it rejects the video itself for good, or false
+// for failures of the job, the worker or transient ones
+func QuarantineReason(err error) (string, bool) {
+	if err == nil || IsTransient(err) {
+		return "", false
+	}
+	code := ErrorCode(err)
+	for _, quarantinable := range quarantinableErrorCodes {
+		if code == quarantinable {
+			return code, true
+		}
+	}
+	return "", false
+}
+
+// QuarantineKey is where the video named videoName of processID is moved under prefix
+func QuarantineKey(prefix, processID, videoName string) string {
+	return joinKey(prefix, processID, videoName)
+}
+
+// QuarantineTags tag an object moved to the quarantine with the reason
+func QuarantineTags(reason string) map[string]string {
+	return map[string]string{QuarantineReasonTag: reason}
+}
+
+// QuarantinedError is the failure of a job whose video was moved to the quarantine at Location
+type QuarantinedError struct {
+	Location ObjectRef
+	Err      error
+}
+
+func NewQuarantinedError(err error, location ObjectRef) error {
+	return &QuarantinedError{Location: location, Err: err}
+}
+
+func (e *QuarantinedError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *QuarantinedError) Unwrap() error {
+	return e.Err
+}
+
+// QuarantineLocation returns where the video of the failure was moved, or nil when it was not
+func QuarantineLocation(err error) *ObjectRef {
+	var quarantined *QuarantinedError
+	if errors.As(err, &quarantined) {
+		return &quarantined.Location
+	}
+	return nil
+}
@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestQuarantineReason(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		reason string
+	}{
+		{"malware", NewCodedError(ErrorCodeMalwareDetected, errors.New("Eicar")), ErrorCodeMalwareDetected},
+		{"wrapped", fmt.Errorf("failed to download video: %w", NewCodedError(ErrorCodeEmptyVideo, errors.New("empty"))), ErrorCodeEmptyVideo},
+		{"codec", NewCodedError(ErrorCodeUnsupportedCodec, errors.New("hevc")), ErrorCodeUnsupportedCodec},
+		{"transient", NewTransientError(NewCodedError(ErrorCodeTruncatedVideo, errors.New("short"))), ""},
+		{"not the video", NewCodedError(ErrorCodeSourceNotAllowed, errors.New("denied")), ""},
+		{"uncoded", errors.New("ffmpeg crashed"), ""},
+		{"nil", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason, ok := QuarantineReason(tt.err)
+			if reason != tt.reason || ok != (tt.reason != "") {
+				t.Errorf("Expected reason %q, got %q (%v)", tt.reason, reason, ok)
+			}
+		})
+	}
+}
+
+func TestQuarantineKey(t *testing.T) {
+	if key := QuarantineKey("quarantine", "123", "video.mkv"); key != "quarantine/123/video.mkv" {
+		t.Errorf("Expected quarantine/123/video.mkv, got %s", key)
+	}
+	if key := QuarantineKey("quarantine", "../tenant-b", "video.mkv"); key != "quarantine/.._tenant-b/video.mkv" {
+		t.Errorf("Expected the key kept under the quarantine prefix, got %s", key)
+	}
+}
+
+func TestQuarantineLocation(t *testing.T) {
+	cause := NewCodedError(ErrorCodeMalwareDetected, errors.New("Eicar"))
+	err := fmt.Errorf("scan: %w", NewQuarantinedError(cause, ObjectRef{Bucket: "quarantine", Key: "q/123/video.mp4"}))
+
+	location := QuarantineLocation(err)
+	if location == nil || location.Bucket != "quarantine" || location.Key != "q/123/video.mp4" {
+		t.Errorf("Unexpected location %+v", location)
+	}
+	if ErrorCode(err) != ErrorCodeMalwareDetected || err.Error() != "scan: Eicar" {
+		t.Errorf("Expected the cause kept, got %q (%s)", err, ErrorCode(err))
+	}
+	if QuarantineLocation(cause) != nil {
+		t.Error("Expected no location for a video not quarantined")
+	}
+}
@@ -0,0 +1,50 @@
+package domain
+
+import (
+	"fmt"
+	"math"
+)
+
+// ResourceUsage is what a job consumed, reported so processing cost can be attributed to
+// tenants. CPU time is the ffmpeg runs' user and system time
+type ResourceUsage struct {
+	S3GetRequests   int64   `json:"s3_get_requests"`
+	S3PutRequests   int64   `json:"s3_put_requests"`
+	BytesDownloaded int64   `json:"bytes_downloaded"`
+	BytesUploaded   int64   `json:"bytes_uploaded"`
+	CPUSeconds      float64 `json:"cpu_seconds"`
+	// EstimatedCost prices the usage with the worker's CostModel; zero without prices
+	EstimatedCost float64 `json:"estimated_cost,omitempty"`
+}
+
+// CostModel prices the resources of a job, in whatever currency the prices are given; a zero
+// price leaves the resource out of the estimate
+type CostModel struct {
+	S3GetPer1000  float64
+	S3PutPer1000  float64
+	TransferPerGB float64
+	CPUPerHour    float64
+}
+
+func (m CostModel) Validate() error {
+	if m.S3GetPer1000 < 0 || m.S3PutPer1000 < 0 || m.TransferPerGB < 0 || m.CPUPerHour < 0 {
+		return fmt.Errorf("cost model prices cannot be negative")
+	}
+	return nil
+}
+
+// IsZero reports a model without prices, whose estimates are always zero
+func (m CostModel) IsZero() bool {
+	return m == CostModel{}
+}
+
+// Estimate prices usage; transfer counts the bytes downloaded and uploaded, in GiB
+func (m CostModel) Estimate(usage ResourceUsage) float64 {
+	gigabytes := float64(usage.BytesDownloaded+usage.BytesUploaded) / (1 << 30)
+	cost := float64(usage.S3GetRequests)/1000*m.S3GetPer1000 +
+		float64(usage.S3PutRequests)/1000*m.S3PutPer1000 +
+		gigabytes*m.TransferPerGB +
+		usage.CPUSeconds/3600*m.CPUPerHour
+	// Rounded so the result does not carry float noise to the consumer
+	return math.Round(cost*1e6) / 1e6
+}
@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestCostModel_Validate(t *testing.T) {
+	if err := (CostModel{}).Validate(); err != nil {
+		t.Errorf("Expected a model without prices to be valid, got %v", err)
+	}
+	if err := (CostModel{S3GetPer1000: 0.0004, CPUPerHour: 0.04}).Validate(); err != nil {
+		t.Errorf("Expected valid prices, got %v", err)
+	}
+	if err := (CostModel{TransferPerGB: -1}).Validate(); err == nil {
+		t.Error("Expected an error for a negative price")
+	}
+}
+
+func TestCostModel_Estimate(t *testing.T) {
+	usage := ResourceUsage{
+		S3GetRequests:   1,
+		S3PutRequests:   3,
+		BytesDownloaded: 1 << 30,
+		BytesUploaded:   1 << 29,
+		CPUSeconds:      1800,
+	}
+
+	if cost := (CostModel{}).Estimate(usage); cost != 0 {
+		t.Errorf("Expected no cost without prices, got %v", cost)
+	}
+
+	model := CostModel{S3GetPer1000: 0.4, S3PutPer1000: 5, TransferPerGB: 0.09, CPUPerHour: 0.04}
+	// 0.0004 + 0.015 + 1.5 * 0.09 + 0.5 * 0.04
+	if cost := model.Estimate(usage); cost != 0.1704 {
+		t.Errorf("Expected 0.1704, got %v", cost)
+	}
+	if !(CostModel{}).IsZero() || model.IsZero() {
+		t.Error("Expected only the model without prices to be zero")
+	}
+}
+
+func TestResourceUsage_JSON(t *testing.T) {
+	body, _ := json.Marshal(ResourceUsage{S3GetRequests: 1, CPUSeconds: 2.5})
+	if !strings.Contains(string(body), `"s3_get_requests":1`) || !strings.Contains(string(body), `"cpu_seconds":2.5`) {
+		t.Errorf("Unexpected usage %s", body)
+	}
+	if strings.Contains(string(body), "estimated_cost") {
+		t.Errorf("Expected no estimated_cost without a cost model, got %s", body)
+	}
+}
@@ -0,0 +1,149 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const (
+	ResultDestinationSQS     = "sqs"
+	ResultDestinationSNS     = "sns"
+	ResultDestinationWebhook = "webhook"
+)
+
+// MessageTypeDeliveryReport is the type attribute of the DeliveryReport messages, so consumers
+// of the output queue can tell them from results
+const MessageTypeDeliveryReport = "result.delivery_report"
+
+// ResultDestination is an additional place a result is delivered to, besides the output
+// queue: an SQS queue URL, an SNS topic ARN or an https webhook URL
+type ResultDestination struct {
+	Type   string
+	Target string
+}
+
+// ParseResultDestination reads a destination written as "type:target", e.g.
+// "sns:arn:aws:sns:us-east-1:123456789012:results" or "webhook:https://example.com/results"
+func ParseResultDestination(value string) (ResultDestination, error) {
+	destinationType, target, _ := strings.Cut(strings.TrimSpace(value), ":")
+	destination := ResultDestination{Type: destinationType, Target: target}
+	if err := destination.Validate(); err != nil {
+		return ResultDestination{}, err
+	}
+	return destination, nil
+}
+
+func (d ResultDestination) Validate() error {
+	if d.Target == "" {
+		return fmt.Errorf("invalid result destination %q: target is required", d.String())
+	}
+	switch d.Type {
+	case ResultDestinationSQS:
+		return nil
+	case ResultDestinationSNS:
+		if !strings.HasPrefix(d.Target, "arn:") {
+			return fmt.Errorf("invalid result destination %q: sns target must be a topic ARN", d.String())
+		}
+		return nil
+	case ResultDestinationWebhook:
+		target, err := url.Parse(d.Target)
+		if err != nil || target.Scheme != "https" || target.Host == "" {
+			return fmt.Errorf("invalid result destination %q: webhook target must be an https URL", d.String())
+		}
+		return nil
+	default:
+		return fmt.Errorf("invalid result destination %q: type must be sqs, sns or webhook", d.String())
+	}
+}
+
+func (d ResultDestination) String() string {
+	return d.Type + ":" + d.Target
+}
+
+// ResultDestinations lists the additional destinations of a result
+type ResultDestinations []ResultDestination
+
+// ParseResultDestinations reads a comma separated list of destinations
+func ParseResultDestinations(value string) (ResultDestinations, error) {
+	var destinations ResultDestinations
+	for _, item := range strings.Split(value, ",") {
+		if strings.TrimSpace(item) == "" {
+			continue
+		}
+		destination, err := ParseResultDestination(item)
+		if err != nil {
+			return nil, err
+		}
+		destinations = append(destinations, destination)
+	}
+	return destinations, nil
+}
+
+// UnmarshalJSON reads the destinations from a list of "type:target", as in the tenant
+// configuration and the input message
+func (d *ResultDestinations) UnmarshalJSON(data []byte) error {
+	var values []string
+	if err := json.Unmarshal(data, &values); err != nil {
+		return fmt.Errorf("result destinations must be a list of \"type:target\": %w", err)
+	}
+
+	destinations := make(ResultDestinations, 0, len(values))
+	for _, value := range values {
+		destination, err := ParseResultDestination(value)
+		if err != nil {
+			return err
+		}
+		destinations = append(destinations, destination)
+	}
+	*d = destinations
+	return nil
+}
+
+// Contains reports whether destination is in the list
+func (d ResultDestinations) Contains(destination ResultDestination) bool {
+	for _, item := range d {
+		if item == destination {
+			return true
+		}
+	}
+	return false
+}
+
+// Merge returns the destinations of d followed by those of other not already in d
+func (d ResultDestinations) Merge(other ResultDestinations) ResultDestinations {
+	merged := make(ResultDestinations, 0, len(d)+len(other))
+	for _, destination := range append(append(ResultDestinations{}, d...), other...) {
+		if !merged.Contains(destination) {
+			merged = append(merged, destination)
+		}
+	}
+	return merged
+}
+
+// DeliveryFailure is a destination the result could not be delivered to
+type DeliveryFailure struct {
+	Destination string `json:"destination"`
+	Error       string `json:"error"`
+}
+
+// DeliveryReport is sent to the output queue when a result reached the output queue but not
+// every additional destination. It reports a delivery problem, not a processing failure: the
+// result itself was already sent
+type DeliveryReport struct {
+	Type      string `json:"type"`
+	ProcessID string `json:"process_id"`
+	// ResultSuccess tells whether the result that was not delivered reported a success
+	ResultSuccess      bool              `json:"result_success"`
+	FailedDestinations []DeliveryFailure `json:"failed_destinations"`
+}
+
+func NewDeliveryReport(processID string, resultSuccess bool, failures []DeliveryFailure) DeliveryReport {
+	return DeliveryReport{
+		Type:               MessageTypeDeliveryReport,
+		ProcessID:          processID,
+		ResultSuccess:      resultSuccess,
+		FailedDestinations: failures,
+	}
+}
@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseResultDestination(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    ResultDestination
+		wantErr bool
+	}{
+		{value: "sqs:https://sqs.us-east-1.amazonaws.com/123/results", want: ResultDestination{Type: "sqs", Target: "https://sqs.us-east-1.amazonaws.com/123/results"}},
+		{value: " sns:arn:aws:sns:us-east-1:123:results ", want: ResultDestination{Type: "sns", Target: "arn:aws:sns:us-east-1:123:results"}},
+		{value: "webhook:https://example.com/results?token=abc", want: ResultDestination{Type: "webhook", Target: "https://example.com/results?token=abc"}},
+		{value: "webhook:http://example.com/results", wantErr: true},
+		{value: "sns:results", wantErr: true},
+		{value: "kafka:results", wantErr: true},
+		{value: "sqs:", wantErr: true},
+		{value: "sqs", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseResultDestination(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseResultDestination(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+		}
+		if err == nil && got != tt.want {
+			t.Errorf("ParseResultDestination(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestResultDestinations_UnmarshalJSON(t *testing.T) {
+	var destinations ResultDestinations
+	if err := json.Unmarshal([]byte(`["sns:arn:aws:sns:us-east-1:123:results","webhook:https://example.com/hook"]`), &destinations); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if len(destinations) != 2 || destinations[1].String() != "webhook:https://example.com/hook" {
+		t.Errorf("Unexpected destinations %v", destinations)
+	}
+
+	if err := json.Unmarshal([]byte(`["ftp:example.com"]`), &destinations); err == nil {
+		t.Error("Expected error for an unknown destination type")
+	}
+}
+
+func TestResultDestinations_Merge(t *testing.T) {
+	queue := ResultDestination{Type: ResultDestinationSQS, Target: "queue"}
+	topic := ResultDestination{Type: ResultDestinationSNS, Target: "arn:topic"}
+
+	merged := ResultDestinations{queue}.Merge(ResultDestinations{topic, queue})
+	if len(merged) != 2 || merged[0] != queue || merged[1] != topic {
+		t.Errorf("Expected the destinations merged without duplicates, got %v", merged)
+	}
+	if !merged.Contains(topic) || merged.Contains(ResultDestination{Type: ResultDestinationSNS, Target: "arn:other"}) {
+		t.Errorf("Unexpected Contains results for %v", merged)
+	}
+}
@@ -0,0 +1,74 @@
+package domain
+
+import "fmt"
+
+// Result message formats, selected by RESULT_FORMAT
+const (
+	ResultFormatJSON     = "json"
+	ResultFormatProtobuf = "protobuf"
+	ResultFormatAvro     = "avro"
+)
+
+// Content types announced in the content_type attribute of result messages
+const (
+	ContentTypeJSON     = "application/json"
+	ContentTypeProtobuf = "application/x-protobuf"
+	ContentTypeAvro     = "avro/binary"
+)
+
+// Trace attributes of result messages, for consumers and tooling that filter without parsing
+// the body
+const (
+	TraceparentAttribute   = "traceparent"
+	CorrelationIDAttribute = "correlation_id"
+	SchemaVersionAttribute = "schema_version"
+)
+
+// ResultSchemaVersion is the version of the result message contract, in every format. It
+// only changes when a field is removed or changes meaning; new optional fields keep it
+const ResultSchemaVersion = "1"
+
+// MaxMessageAttributes is how many attributes an SQS message can carry
+const MaxMessageAttributes = 10
+
+// Result message kinds; the JSON format tells them apart by their fields, the binary ones
+// carry the kind explicitly
+const (
+	ResultKindSuccess = "success"
+	ResultKindError   = "error"
+	ResultKindDryRun  = "dry_run"
+)
+
+// ValidateResultFormat checks the format; empty means json
+func ValidateResultFormat(format string) error {
+	switch format {
+	case "", ResultFormatJSON, ResultFormatProtobuf, ResultFormatAvro:
+		return nil
+	default:
+		return fmt.Errorf("unsupported result format: %s", format)
+	}
+}
+
+// Kind tells which message the result turns into
+func (r *ProcessResult) Kind() string {
+	switch {
+	case !r.Success:
+		return ResultKindError
+	case r.Estimate != nil:
+		return ResultKindDryRun
+	default:
+		return ResultKindSuccess
+	}
+}
+
+// ToMessage builds the JSON message of the result's kind
+func (r *ProcessResult) ToMessage() map[string]interface{} {
+	switch r.Kind() {
+	case ResultKindError:
+		return r.ToErrorMessage()
+	case ResultKindDryRun:
+		return r.ToDryRunMessage()
+	default:
+		return r.ToSuccessMessage()
+	}
+}
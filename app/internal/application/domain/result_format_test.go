@@ -0,0 +1,41 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestValidateResultFormat(t *testing.T) {
+	for _, format := range []string{"", ResultFormatJSON, ResultFormatProtobuf, ResultFormatAvro} {
+		if err := ValidateResultFormat(format); err != nil {
+			t.Errorf("Expected %q to be valid, got %v", format, err)
+		}
+	}
+	if err := ValidateResultFormat("xml"); err == nil {
+		t.Error("Expected error for an unsupported format")
+	}
+}
+
+func TestProcessResult_Kind(t *testing.T) {
+	tests := []struct {
+		name   string
+		result ProcessResult
+		want   string
+		field  string
+	}{
+		{"success", ProcessResult{Success: true, FileKey: "processed/frames_1.zip"}, ResultKindSuccess, "file_key"},
+		{"dry run", ProcessResult{Success: true, Estimate: &OutputEstimate{}}, ResultKindDryRun, "estimate"},
+		{"error", ProcessResult{Error: errors.New("boom")}, ResultKindError, "error_message"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if kind := tt.result.Kind(); kind != tt.want {
+				t.Errorf("Expected %s, got %s", tt.want, kind)
+			}
+			if _, ok := tt.result.ToMessage()[tt.field]; !ok {
+				t.Errorf("Expected %s in the %s message", tt.field, tt.name)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+package domain
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Tags written on the outputs of a tenant with a retention: lifecycle rules can expire them by
+// retention-days, and expires-at is the day each one may be deleted
+const (
+	RetentionDaysTag = "retention-days"
+	ExpiresAtTag     = "expires-at"
+)
+
+// expirationPrefix holds the outputs scheduled for deletion, one marker per job under the day
+// they expire, so that the cleanup only reads the days already past
+const expirationPrefix = "expirations/"
+
+// RetentionConfig keeps the outputs of a tenant for a number of days, e.g. short-lived previews
+type RetentionConfig struct {
+	Days int `json:"days"`
+
+	// OutputTypes limits the retention to these outputs; empty applies it to all
+	OutputTypes []string `json:"output_types,omitempty"`
+
+	// Delete schedules the deletion of the outputs by the cleanup besides tagging them
+	Delete bool `json:"delete,omitempty"`
+}
+
+func (c RetentionConfig) Validate() error {
+	if c.Days < 1 {
+		return fmt.Errorf("retention days must be at least 1, got %d", c.Days)
+	}
+	for _, outputType := range c.OutputTypes {
+		if outputType == "" {
+			return fmt.Errorf("retention output type is empty")
+		}
+		if err := ValidateOutputType(outputType); err != nil {
+			return fmt.Errorf("retention: %w", err)
+		}
+	}
+	return nil
+}
+
+// Applies reports whether the outputs of outputType are kept for the retention
+func (c RetentionConfig) Applies(outputType string) bool {
+	return len(c.OutputTypes) == 0 || slices.Contains(c.OutputTypes, outputType)
+}
+
+// ExpiresAt is when the outputs written at now expire: Days later, rounded up to the next
+// midnight UTC as S3 lifecycle rules do
+func (c RetentionConfig) ExpiresAt(now time.Time) time.Time {
+	year, month, day := now.UTC().Date()
+	return time.Date(year, month, day+c.Days+1, 0, 0, 0, 0, time.UTC)
+}
+
+// Tags are the tags of the outputs written at now
+func (c RetentionConfig) Tags(now time.Time) map[string]string {
+	return map[string]string{
+		RetentionDaysTag: strconv.Itoa(c.Days),
+		ExpiresAtTag:     c.ExpiresAt(now).Format(time.DateOnly),
+	}
+}
+
+// OutputExpiration is the marker of the outputs of a job scheduled for deletion, in the worker
+// bucket whatever the bucket of the outputs
+type OutputExpiration struct {
+	ProcessID string    `json:"process_id"`
+	TenantID  string    `json:"tenant_id,omitempty"`
+	Bucket    string    `json:"bucket"`
+	Keys      []string  `json:"keys"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// ExpirationsPrefix is where the cleanup lists the expiration markers
+func ExpirationsPrefix() string {
+	return expirationPrefix
+}
+
+// ExpirationKey keys the marker of the outputs of outputID under the day they expire, e.g.
+// expirations/2024-01-08/{process_id}.json
+func ExpirationKey(expiresAt time.Time, outputID string) string {
+	return path.Join(expirationPrefix, expiresAt.UTC().Format(time.DateOnly), outputID+".json")
+}
+
+// ExpirationDay reads the day of a marker key; false when the key is not a marker
+func ExpirationDay(key string) (time.Time, bool) {
+	rest, ok := strings.CutPrefix(key, expirationPrefix)
+	if !ok {
+		return time.Time{}, false
+	}
+	day, _, ok := strings.Cut(rest, "/")
+	if !ok {
+		return time.Time{}, false
+	}
+	expiresAt, err := time.Parse(time.DateOnly, day)
+	return expiresAt, err == nil
+}
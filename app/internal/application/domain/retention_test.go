@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRetentionConfig_Applies(t *testing.T) {
+	if !(RetentionConfig{Days: 7}).Applies(OutputTypeHLS) {
+		t.Error("Expected a retention without output types to apply to all")
+	}
+	previews := RetentionConfig{Days: 1, OutputTypes: []string{OutputTypeSprite}}
+	if !previews.Applies(OutputTypeSprite) || previews.Applies(OutputTypeFrames) {
+		t.Error("Expected the retention to apply to the sprites only")
+	}
+}
+
+func TestRetentionConfig_Tags(t *testing.T) {
+	now := time.Date(2024, 1, 1, 15, 30, 0, 0, time.FixedZone("BRT", -3*3600))
+	retention := RetentionConfig{Days: 7}
+
+	// 18:30 UTC plus 7 days, rounded up to the next midnight
+	if expiresAt := retention.ExpiresAt(now); !expiresAt.Equal(time.Date(2024, 1, 9, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected 2024-01-09, got %s", expiresAt)
+	}
+	tags := retention.Tags(now)
+	if tags[RetentionDaysTag] != "7" || tags[ExpiresAtTag] != "2024-01-09" {
+		t.Errorf("Unexpected tags %v", tags)
+	}
+}
+
+func TestExpirationKey(t *testing.T) {
+	key := ExpirationKey(time.Date(2024, 1, 9, 0, 0, 0, 0, time.UTC), "123_v2")
+	if key != "expirations/2024-01-09/123_v2.json" {
+		t.Fatalf("Unexpected key %s", key)
+	}
+	if day, ok := ExpirationDay(key); !ok || !day.Equal(time.Date(2024, 1, 9, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected the day of the key, got %s", day)
+	}
+	for _, key := range []string{"jobs/123.json", "expirations/soon/123.json", "expirations/2024-01-09"} {
+		if _, ok := ExpirationDay(key); ok {
+			t.Errorf("Expected %s not read as a marker", key)
+		}
+	}
+}
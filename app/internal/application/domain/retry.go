@@ -0,0 +1,51 @@
+package domain
+
+import "errors"
+
+// TransientError marks a failure that may not happen again, such as a storage error, as
+// opposed to an invalid request or a video that cannot be processed
+type TransientError struct {
+	Err error
+}
+
+// NewTransientError marks err as transient; the message is unchanged
+func NewTransientError(err error) error {
+	return &TransientError{Err: err}
+}
+
+func (e *TransientError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *TransientError) Unwrap() error {
+	return e.Err
+}
+
+// IsTransient reports whether a TransientError is in the chain of err
+func IsTransient(err error) bool {
+	var transient *TransientError
+	return errors.As(err, &transient)
+}
+
+// RetryInfo tells the consumer of an error result whether the job will run again, so a
+// temporary failure can be told apart from a final one
+type RetryInfo struct {
+	// Attempt counts the deliveries of the job message, starting at 1
+	Attempt int
+	// MaxAttempts is the last attempt made; 0 when the worker does not retry jobs
+	MaxAttempts int
+	WillRetry   bool
+}
+
+// NewRetryInfo returns nil when the attempt is not known
+func NewRetryInfo(attempt, maxAttempts int) *RetryInfo {
+	if attempt <= 0 {
+		return nil
+	}
+	return &RetryInfo{Attempt: attempt, MaxAttempts: maxAttempts}
+}
+
+// Failed records the failure of the attempt: only transient failures are retried, until MaxAttempts
+func (r *RetryInfo) Failed(err error) {
+	r.WillRetry = IsTransient(err) && r.Attempt < r.MaxAttempts
+}
@@ -0,0 +1,52 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestTransientError(t *testing.T) {
+	err := fmt.Errorf("failed to download video: %w", NewTransientError(errors.New("connection reset")))
+	if !IsTransient(err) {
+		t.Error("Expected a wrapped transient error to be transient")
+	}
+	if err.Error() != "failed to download video: connection reset" {
+		t.Errorf("Expected the message unchanged, got %q", err.Error())
+	}
+	if IsTransient(errors.New("invalid video")) {
+		t.Error("Expected a plain error not to be transient")
+	}
+}
+
+func TestRetryInfo_Failed(t *testing.T) {
+	transient := NewTransientError(errors.New("storage unavailable"))
+	final := errors.New("invalid video")
+
+	tests := []struct {
+		name        string
+		attempt     int
+		maxAttempts int
+		err         error
+		want        bool
+	}{
+		{"transient before the last attempt", 1, 3, transient, true},
+		{"transient on the last attempt", 3, 3, transient, false},
+		{"final failure", 1, 3, final, false},
+		{"retries disabled", 1, 0, transient, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			retry := NewRetryInfo(tt.attempt, tt.maxAttempts)
+			retry.Failed(tt.err)
+			if retry.WillRetry != tt.want {
+				t.Errorf("Expected will retry %v, got %v", tt.want, retry.WillRetry)
+			}
+		})
+	}
+
+	if NewRetryInfo(0, 3) != nil {
+		t.Error("Expected no retry info when the attempt is unknown")
+	}
+}
@@ -0,0 +1,41 @@
+package domain
+
+import "fmt"
+
+const (
+	SharpnessModeAnnotate = "annotate"
+	SharpnessModeDrop     = "drop"
+
+	// FramesManifest is the JSON file zipped with the frames when sharpness scoring or the
+	// color analysis is on
+	FramesManifest = "manifest.json"
+)
+
+// SharpnessOptions scores every extracted frame by the variance of its Laplacian; blurry
+// frames score low
+type SharpnessOptions struct {
+	// Mode is annotate (scores go to the manifest) or drop (frames below Threshold are
+	// removed); empty disables scoring
+	Mode      string  `json:"mode"`
+	Threshold float64 `json:"threshold"`
+}
+
+func (o SharpnessOptions) Enabled() bool {
+	return o.Mode != ""
+}
+
+func (o SharpnessOptions) Validate() error {
+	switch o.Mode {
+	case "", SharpnessModeAnnotate:
+	case SharpnessModeDrop:
+		if o.Threshold <= 0 {
+			return fmt.Errorf("sharpness threshold must be positive to drop frames")
+		}
+	default:
+		return fmt.Errorf("unsupported sharpness mode: %s", o.Mode)
+	}
+	if o.Threshold < 0 {
+		return fmt.Errorf("sharpness threshold must not be negative")
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package domain
+
+import "testing"
+
+func TestSharpnessOptions_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		options SharpnessOptions
+		wantErr bool
+	}{
+		{"disabled", SharpnessOptions{}, false},
+		{"annotate", SharpnessOptions{Mode: SharpnessModeAnnotate}, false},
+		{"drop", SharpnessOptions{Mode: SharpnessModeDrop, Threshold: 100}, false},
+		{"drop without threshold", SharpnessOptions{Mode: SharpnessModeDrop}, true},
+		{"negative threshold", SharpnessOptions{Mode: SharpnessModeAnnotate, Threshold: -1}, true},
+		{"unknown mode", SharpnessOptions{Mode: "blur"}, true},
+	}
+	for _, tt := range tests {
+		if err := tt.options.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("%s: expected error %v, got %v", tt.name, tt.wantErr, err)
+		}
+	}
+}
+
+func TestSharpnessOptions_Enabled(t *testing.T) {
+	if (SharpnessOptions{}).Enabled() {
+		t.Error("Expected scoring disabled without a mode")
+	}
+	if !(SharpnessOptions{Mode: SharpnessModeAnnotate}).Enabled() {
+		t.Error("Expected scoring enabled in annotate mode")
+	}
+}
@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// SourceRule allows the videos of a bucket, or only those under Prefix
+type SourceRule struct {
+	Bucket string
+	Prefix string
+}
+
+// ParseSourceRule reads a rule written as "bucket" or "bucket/prefix"
+func ParseSourceRule(value string) (SourceRule, error) {
+	bucket, prefix, _ := strings.Cut(strings.TrimSpace(value), "/")
+	if bucket == "" {
+		return SourceRule{}, fmt.Errorf("invalid source %q: bucket is required", value)
+	}
+	return SourceRule{Bucket: bucket, Prefix: prefix}, nil
+}
+
+func (r SourceRule) String() string {
+	if r.Prefix == "" {
+		return r.Bucket
+	}
+	return r.Bucket + "/" + r.Prefix
+}
+
+// SourceAllowlist limits the objects a message can make the worker read and delete. An empty
+// allowlist allows any source the IAM role can reach. It also lists the output locations
+// messages may pick, where being empty allows none
+type SourceAllowlist []SourceRule
+
+// ParseSourceAllowlist reads a comma separated list of rules, e.g. "uploads-bucket,archive/videos/"
+func ParseSourceAllowlist(value string) (SourceAllowlist, error) {
+	var allowlist SourceAllowlist
+	for _, item := range strings.Split(value, ",") {
+		if strings.TrimSpace(item) == "" {
+			continue
+		}
+		rule, err := ParseSourceRule(item)
+		if err != nil {
+			return nil, err
+		}
+		allowlist = append(allowlist, rule)
+	}
+	return allowlist, nil
+}
+
+// UnmarshalJSON reads the allowlist from a list of rules, as in the tenant configuration
+func (a *SourceAllowlist) UnmarshalJSON(data []byte) error {
+	var values []string
+	if err := json.Unmarshal(data, &values); err != nil {
+		return fmt.Errorf("allowed sources must be a list of \"bucket[/prefix]\": %w", err)
+	}
+
+	allowlist := make(SourceAllowlist, 0, len(values))
+	for _, value := range values {
+		rule, err := ParseSourceRule(value)
+		if err != nil {
+			return err
+		}
+		allowlist = append(allowlist, rule)
+	}
+	*a = allowlist
+	return nil
+}
+
+// HasBucket reports whether a rule names bucket
+func (a SourceAllowlist) HasBucket(bucket string) bool {
+	for _, rule := range a {
+		if rule.Bucket == bucket {
+			return true
+		}
+	}
+	return false
+}
+
+// Allows reports whether the object matches a rule. Keys are compared as plain strings, as S3
+// does not resolve ".." segments
+func (a SourceAllowlist) Allows(bucket, key string) bool {
+	if len(a) == 0 {
+		return true
+	}
+	for _, rule := range a {
+		if rule.Bucket == bucket && strings.HasPrefix(key, rule.Prefix) {
+			return true
+		}
+	}
+	return false
+}
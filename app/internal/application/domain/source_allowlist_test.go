@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseSourceAllowlist(t *testing.T) {
+	allowlist, err := ParseSourceAllowlist(" uploads , archive/videos/ ,")
+	if err != nil {
+		t.Fatalf("ParseSourceAllowlist failed: %v", err)
+	}
+
+	want := SourceAllowlist{{Bucket: "uploads"}, {Bucket: "archive", Prefix: "videos/"}}
+	if len(allowlist) != len(want) || allowlist[0] != want[0] || allowlist[1] != want[1] {
+		t.Errorf("Expected %v, got %v", want, allowlist)
+	}
+
+	if _, err := ParseSourceAllowlist("/videos/"); err == nil {
+		t.Error("Expected error for a rule without bucket")
+	}
+	if allowlist, err := ParseSourceAllowlist(""); err != nil || len(allowlist) != 0 {
+		t.Errorf("Expected an empty allowlist, got %v, %v", allowlist, err)
+	}
+}
+
+func TestSourceAllowlist_Allows(t *testing.T) {
+	allowlist := SourceAllowlist{{Bucket: "uploads"}, {Bucket: "archive", Prefix: "videos/"}}
+
+	tests := []struct {
+		bucket string
+		key    string
+		want   bool
+	}{
+		{"uploads", "any/video.mp4", true},
+		{"archive", "videos/a.mp4", true},
+		{"archive", "secrets/a.mp4", false},
+		{"archive", "videos-old/a.mp4", false},
+		{"other", "videos/a.mp4", false},
+	}
+
+	for _, tt := range tests {
+		if got := allowlist.Allows(tt.bucket, tt.key); got != tt.want {
+			t.Errorf("Allows(%q, %q) = %v, want %v", tt.bucket, tt.key, got, tt.want)
+		}
+	}
+
+	if !(SourceAllowlist{}).Allows("any", "key") {
+		t.Error("Expected an empty allowlist to allow any source")
+	}
+}
+
+func TestSourceAllowlist_HasBucket(t *testing.T) {
+	allowlist := SourceAllowlist{{Bucket: "uploads"}, {Bucket: "archive", Prefix: "videos/"}}
+	if !allowlist.HasBucket("archive") || allowlist.HasBucket("other") || (SourceAllowlist{}).HasBucket("uploads") {
+		t.Error("Expected only the buckets named by a rule")
+	}
+}
+
+func TestSourceAllowlist_UnmarshalJSON(t *testing.T) {
+	var allowlist SourceAllowlist
+	if err := json.Unmarshal([]byte(`["uploads","archive/videos/"]`), &allowlist); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if len(allowlist) != 2 || allowlist[1].String() != "archive/videos/" {
+		t.Errorf("Unexpected allowlist %v", allowlist)
+	}
+
+	if err := json.Unmarshal([]byte(`"uploads"`), &allowlist); err == nil {
+		t.Error("Expected error for a non-list value")
+	}
+	if err := json.Unmarshal([]byte(`[""]`), &allowlist); err == nil {
+		t.Error("Expected error for an empty rule")
+	}
+}
@@ -0,0 +1,69 @@
+package domain
+
+import "fmt"
+
+const (
+	maxSpriteGrid = 20
+	// maxSpriteThumbnail bounds each side of a thumbnail, and maxSpritePixels the whole sheet,
+	// which is built in memory (4 bytes per pixel)
+	maxSpriteThumbnail = 1280
+	maxSpritePixels    = 4096 * 4096
+)
+
+// SpriteOptions configures the sprite sheet output; zero values fall back to DefaultSpriteOptions
+type SpriteOptions struct {
+	Columns         int     `json:"columns"`
+	Rows            int     `json:"rows"`
+	IntervalSeconds float64 `json:"interval_seconds"`
+	Width           int     `json:"width"`
+	Height          int     `json:"height"`
+}
+
+var DefaultSpriteOptions = SpriteOptions{
+	Columns:         5,
+	Rows:            5,
+	IntervalSeconds: 10,
+	Width:           160,
+	Height:          90,
+}
+
+// WithDefaults fills the unset fields with DefaultSpriteOptions
+func (o SpriteOptions) WithDefaults() SpriteOptions {
+	if o.Columns == 0 {
+		o.Columns = DefaultSpriteOptions.Columns
+	}
+	if o.Rows == 0 {
+		o.Rows = DefaultSpriteOptions.Rows
+	}
+	if o.IntervalSeconds == 0 {
+		o.IntervalSeconds = DefaultSpriteOptions.IntervalSeconds
+	}
+	if o.Width == 0 {
+		o.Width = DefaultSpriteOptions.Width
+	}
+	if o.Height == 0 {
+		o.Height = DefaultSpriteOptions.Height
+	}
+	return o
+}
+
+// Validate rejects negative values, and grids and thumbnails too large to be a usable image
+func (o SpriteOptions) Validate() error {
+	if o.Columns < 0 || o.Columns > maxSpriteGrid || o.Rows < 0 || o.Rows > maxSpriteGrid {
+		return fmt.Errorf("sprite grid must be between 1x1 and %dx%d", maxSpriteGrid, maxSpriteGrid)
+	}
+	if o.IntervalSeconds < 0 {
+		return fmt.Errorf("sprite interval must be positive")
+	}
+	if o.Width < 0 || o.Height < 0 {
+		return fmt.Errorf("sprite thumbnail size must be positive")
+	}
+	if o.Width > maxSpriteThumbnail || o.Height > maxSpriteThumbnail {
+		return fmt.Errorf("sprite thumbnail size must be at most %dx%d", maxSpriteThumbnail, maxSpriteThumbnail)
+	}
+	sheet := o.WithDefaults()
+	if pixels := int64(sheet.Columns*sheet.Width) * int64(sheet.Rows*sheet.Height); pixels > maxSpritePixels {
+		return fmt.Errorf("sprite sheet of %dx%d pixels is over the limit of %d pixels", sheet.Columns*sheet.Width, sheet.Rows*sheet.Height, maxSpritePixels)
+	}
+	return nil
+}
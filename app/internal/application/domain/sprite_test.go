@@ -0,0 +1,55 @@
+package domain
+
+import "testing"
+
+func TestSpriteOptions_WithDefaults(t *testing.T) {
+	options := SpriteOptions{Columns: 10}.WithDefaults()
+
+	if options.Columns != 10 {
+		t.Errorf("Expected Columns 10, got %d", options.Columns)
+	}
+	if options.Rows != DefaultSpriteOptions.Rows {
+		t.Errorf("Expected default Rows, got %d", options.Rows)
+	}
+	if options.IntervalSeconds != DefaultSpriteOptions.IntervalSeconds {
+		t.Errorf("Expected default IntervalSeconds, got %v", options.IntervalSeconds)
+	}
+	if options.Width != DefaultSpriteOptions.Width || options.Height != DefaultSpriteOptions.Height {
+		t.Errorf("Expected default size, got %dx%d", options.Width, options.Height)
+	}
+}
+
+func TestSpriteOptions_Validate(t *testing.T) {
+	if err := (SpriteOptions{}).Validate(); err != nil {
+		t.Errorf("Expected zero options to be valid, got %v", err)
+	}
+	if err := (SpriteOptions{Columns: 21}).Validate(); err == nil {
+		t.Error("Expected error for grid larger than the limit")
+	}
+	if err := (SpriteOptions{IntervalSeconds: -1}).Validate(); err == nil {
+		t.Error("Expected error for negative interval")
+	}
+	if err := (SpriteOptions{Width: -160}).Validate(); err == nil {
+		t.Error("Expected error for negative width")
+	}
+	if err := (SpriteOptions{Width: 100000}).Validate(); err == nil {
+		t.Error("Expected error for a thumbnail larger than the limit")
+	}
+	if err := (SpriteOptions{Columns: 20, Rows: 20, Width: 1280, Height: 720}).Validate(); err == nil {
+		t.Error("Expected error for a sheet over the pixel limit")
+	}
+	if err := (SpriteOptions{Columns: 10, Rows: 10, Width: 320, Height: 180}).Validate(); err != nil {
+		t.Errorf("Expected a 3200x1800 sheet to be valid, got %v", err)
+	}
+}
+
+func TestValidateOutputType(t *testing.T) {
+	for _, outputType := range []string{"", OutputTypeFrames, OutputTypeSprite} {
+		if err := ValidateOutputType(outputType); err != nil {
+			t.Errorf("Expected %q to be valid, got %v", outputType, err)
+		}
+	}
+	if err := ValidateOutputType("gif"); err == nil {
+		t.Error("Expected error for unsupported output type")
+	}
+}
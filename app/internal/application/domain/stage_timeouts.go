@@ -0,0 +1,21 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// StageTimeouts bounds each stage of a job, each derived from the job context; a zero
+// duration leaves the stage unbounded
+type StageTimeouts struct {
+	Download   time.Duration
+	Processing time.Duration
+	Upload     time.Duration
+}
+
+func (t StageTimeouts) Validate() error {
+	if t.Download < 0 || t.Processing < 0 || t.Upload < 0 {
+		return fmt.Errorf("stage timeouts cannot be negative")
+	}
+	return nil
+}
@@ -0,0 +1,18 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStageTimeouts_Validate(t *testing.T) {
+	if err := (StageTimeouts{}).Validate(); err != nil {
+		t.Errorf("Expected unbounded stages to be valid, got %v", err)
+	}
+	if err := (StageTimeouts{Download: time.Minute, Processing: time.Hour, Upload: time.Minute}).Validate(); err != nil {
+		t.Errorf("Expected valid timeouts, got %v", err)
+	}
+	if err := (StageTimeouts{Upload: -time.Second}).Validate(); err == nil {
+		t.Error("Expected an error for a negative timeout")
+	}
+}
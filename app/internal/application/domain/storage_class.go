@@ -0,0 +1,23 @@
+package domain
+
+import "fmt"
+
+const (
+	StorageClassStandard           = "STANDARD"
+	StorageClassStandardIA         = "STANDARD_IA"
+	StorageClassIntelligentTiering = "INTELLIGENT_TIERING"
+)
+
+var supportedStorageClasses = map[string]bool{
+	StorageClassStandard:           true,
+	StorageClassStandardIA:         true,
+	StorageClassIntelligentTiering: true,
+}
+
+// ValidateStorageClass checks that the storage class is supported; empty means "use the default"
+func ValidateStorageClass(storageClass string) error {
+	if storageClass == "" || supportedStorageClasses[storageClass] {
+		return nil
+	}
+	return fmt.Errorf("unsupported storage class: %s", storageClass)
+}
@@ -0,0 +1,42 @@
+package domain
+
+import "fmt"
+
+const (
+	SubtitleFormatSRT = "srt"
+	SubtitleFormatVTT = "vtt"
+)
+
+// SubtitleOptions selects what to do with the subtitle streams embedded in the video
+type SubtitleOptions struct {
+	// Extract writes every text subtitle stream as a file next to the output
+	Extract bool   `json:"extract"`
+	Format  string `json:"format"`
+
+	// BurnTrack is the position of the subtitle stream (0 = first subtitle) drawn into the
+	// frames or renditions; nil disables burn-in
+	BurnTrack *int `json:"burn_track"`
+}
+
+// ExtractFormat returns the extraction format, defaulting to WebVTT
+func (o SubtitleOptions) ExtractFormat() string {
+	if o.Format == "" {
+		return SubtitleFormatVTT
+	}
+	return o.Format
+}
+
+// Enabled reports whether the subtitle streams need to be probed at all
+func (o SubtitleOptions) Enabled() bool {
+	return o.Extract || o.BurnTrack != nil
+}
+
+func (o SubtitleOptions) Validate() error {
+	if o.Format != "" && o.Format != SubtitleFormatSRT && o.Format != SubtitleFormatVTT {
+		return fmt.Errorf("unsupported subtitle format: %s", o.Format)
+	}
+	if o.BurnTrack != nil && *o.BurnTrack < 0 {
+		return fmt.Errorf("invalid subtitle burn track: %d", *o.BurnTrack)
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package domain
+
+import "testing"
+
+func TestSubtitleOptions_ExtractFormat(t *testing.T) {
+	if format := (SubtitleOptions{}).ExtractFormat(); format != SubtitleFormatVTT {
+		t.Errorf("Expected default format vtt, got %s", format)
+	}
+	if format := (SubtitleOptions{Format: SubtitleFormatSRT}).ExtractFormat(); format != SubtitleFormatSRT {
+		t.Errorf("Expected format srt, got %s", format)
+	}
+}
+
+func TestSubtitleOptions_Enabled(t *testing.T) {
+	track := 0
+	if (SubtitleOptions{}).Enabled() {
+		t.Error("Expected zero options to be disabled")
+	}
+	if !(SubtitleOptions{Extract: true}).Enabled() {
+		t.Error("Expected extraction to enable subtitles")
+	}
+	if !(SubtitleOptions{BurnTrack: &track}).Enabled() {
+		t.Error("Expected burn track 0 to enable subtitles")
+	}
+}
+
+func TestSubtitleOptions_Validate(t *testing.T) {
+	negative := -1
+	if err := (SubtitleOptions{Format: "ass"}).Validate(); err == nil {
+		t.Error("Expected error for unsupported format")
+	}
+	if err := (SubtitleOptions{BurnTrack: &negative}).Validate(); err == nil {
+		t.Error("Expected error for negative burn track")
+	}
+	if err := (SubtitleOptions{Extract: true, Format: SubtitleFormatSRT}).Validate(); err != nil {
+		t.Errorf("Expected valid options, got %v", err)
+	}
+}
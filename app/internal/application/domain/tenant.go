@@ -0,0 +1,98 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// TenantConfig holds the per-tenant overrides applied while processing a job
+type TenantConfig struct {
+	StorageClass string `json:"storage_class,omitempty"`
+
+	// AllowedSources narrows the worker allowlist for the tenant's jobs; it cannot widen it,
+	// since the tenant_id comes from the message itself
+	AllowedSources SourceAllowlist `json:"allowed_sources,omitempty"`
+
+	// AllowedOutputs are the "bucket[/prefix]" locations messages of the tenant may write
+	// their outputs to with output_bucket/output_prefix, besides the worker's
+	AllowedOutputs SourceAllowlist `json:"allowed_outputs,omitempty"`
+
+	// ResultDestinations are delivered every result of the tenant besides the output queue;
+	// messages of the tenant may also pick any of them
+	ResultDestinations ResultDestinations `json:"result_destinations,omitempty"`
+
+	// VideoExtensions replace the worker's VIDEO_EXTENSIONS for the tenant's videos
+	VideoExtensions VideoExtensions `json:"video_extensions,omitempty"`
+
+	// Vision sends frames sampled from the tenant's videos to a vision API; nil disables it
+	Vision *VisionConfig `json:"vision,omitempty"`
+
+	// Retention tags the tenant's outputs with their expiry date; nil keeps them indefinitely
+	Retention *RetentionConfig `json:"retention,omitempty"`
+
+	// OutputRole is assumed to write to the buckets of AllowedOutputs, which the tenant owns;
+	// nil writes to them with the worker's own role
+	OutputRole *OutputRoleConfig `json:"output_role,omitempty"`
+}
+
+// TenantRegistry maps a tenant ID to its configuration
+type TenantRegistry map[string]TenantConfig
+
+// ParseTenantRegistry builds a TenantRegistry from its JSON representation
+func ParseTenantRegistry(data []byte) (TenantRegistry, error) {
+	registry := TenantRegistry{}
+	if len(data) == 0 {
+		return registry, nil
+	}
+
+	if err := json.Unmarshal(data, &registry); err != nil {
+		return nil, fmt.Errorf("invalid tenant configuration: %w", err)
+	}
+
+	for tenantID, cfg := range registry {
+		if err := ValidateStorageClass(cfg.StorageClass); err != nil {
+			return nil, fmt.Errorf("tenant %s: %w", tenantID, err)
+		}
+		if cfg.Vision != nil {
+			if err := cfg.Vision.Validate(); err != nil {
+				return nil, fmt.Errorf("tenant %s: %w", tenantID, err)
+			}
+		}
+		if cfg.Retention != nil {
+			if err := cfg.Retention.Validate(); err != nil {
+				return nil, fmt.Errorf("tenant %s: %w", tenantID, err)
+			}
+		}
+		if cfg.OutputRole != nil {
+			if err := cfg.OutputRole.Validate(); err != nil {
+				return nil, fmt.Errorf("tenant %s: %w", tenantID, err)
+			}
+			if len(cfg.AllowedOutputs) == 0 {
+				return nil, fmt.Errorf("tenant %s: output role requires allowed_outputs", tenantID)
+			}
+		}
+	}
+	if _, err := registry.OutputRoles(); err != nil {
+		return nil, err
+	}
+
+	return registry, nil
+}
+
+// HasVision reports whether any tenant enables the vision analysis
+func (r TenantRegistry) HasVision() bool {
+	for _, cfg := range r {
+		if cfg.Vision != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// Lookup returns the configuration of a tenant, or an empty one when unknown
+func (r TenantRegistry) Lookup(tenantID string) TenantConfig {
+	if r == nil || tenantID == "" {
+		return TenantConfig{}
+	}
+	return r[tenantID]
+}
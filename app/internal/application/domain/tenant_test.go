@@ -0,0 +1,122 @@
+package domain
+
+import "testing"
+
+func TestParseTenantRegistry(t *testing.T) {
+	registry, err := ParseTenantRegistry([]byte(`{"acme":{"storage_class":"STANDARD_IA"}}`))
+	if err != nil {
+		t.Fatalf("ParseTenantRegistry failed: %v", err)
+	}
+
+	if got := registry.Lookup("acme").StorageClass; got != StorageClassStandardIA {
+		t.Errorf("Expected storage class %s, got %s", StorageClassStandardIA, got)
+	}
+	if got := registry.Lookup("unknown").StorageClass; got != "" {
+		t.Errorf("Expected empty storage class for unknown tenant, got %s", got)
+	}
+}
+
+func TestParseTenantRegistry_AllowedSources(t *testing.T) {
+	registry, err := ParseTenantRegistry([]byte(`{"acme":{"allowed_sources":["acme-uploads/videos/"]}}`))
+	if err != nil {
+		t.Fatalf("ParseTenantRegistry failed: %v", err)
+	}
+
+	sources := registry.Lookup("acme").AllowedSources
+	if !sources.Allows("acme-uploads", "videos/a.mp4") || sources.Allows("other", "videos/a.mp4") {
+		t.Errorf("Unexpected allowed sources %v", sources)
+	}
+
+	if _, err := ParseTenantRegistry([]byte(`{"acme":{"allowed_sources":["/videos/"]}}`)); err == nil {
+		t.Error("Expected error for an allowed source without bucket")
+	}
+}
+
+func TestParseTenantRegistry_Empty(t *testing.T) {
+	registry, err := ParseTenantRegistry(nil)
+	if err != nil {
+		t.Fatalf("ParseTenantRegistry failed: %v", err)
+	}
+	if len(registry) != 0 {
+		t.Errorf("Expected empty registry, got %d tenants", len(registry))
+	}
+}
+
+func TestParseTenantRegistry_Invalid(t *testing.T) {
+	if _, err := ParseTenantRegistry([]byte(`not json`)); err == nil {
+		t.Error("Expected error for invalid JSON")
+	}
+	if _, err := ParseTenantRegistry([]byte(`{"acme":{"storage_class":"GLACIER"}}`)); err == nil {
+		t.Error("Expected error for unsupported storage class")
+	}
+}
+
+func TestTenantRegistry_LookupNil(t *testing.T) {
+	var registry TenantRegistry
+	if cfg := registry.Lookup("acme"); cfg.StorageClass != "" {
+		t.Errorf("Expected empty config, got %+v", cfg)
+	}
+}
+
+func TestValidateStorageClass(t *testing.T) {
+	for _, class := range []string{"", StorageClassStandard, StorageClassStandardIA, StorageClassIntelligentTiering} {
+		if err := ValidateStorageClass(class); err != nil {
+			t.Errorf("Expected %q to be valid, got %v", class, err)
+		}
+	}
+	if err := ValidateStorageClass("DEEP_ARCHIVE"); err == nil {
+		t.Error("Expected error for unsupported storage class")
+	}
+}
+
+func TestParseTenantRegistry_ResultDestinations(t *testing.T) {
+	registry, err := ParseTenantRegistry([]byte(`{"acme":{"result_destinations":["sns:arn:aws:sns:us-east-1:123:acme"]}}`))
+	if err != nil {
+		t.Fatalf("ParseTenantRegistry failed: %v", err)
+	}
+
+	destinations := registry.Lookup("acme").ResultDestinations
+	if len(destinations) != 1 || destinations[0].Type != ResultDestinationSNS {
+		t.Errorf("Unexpected result destinations %v", destinations)
+	}
+
+	if _, err := ParseTenantRegistry([]byte(`{"acme":{"result_destinations":["webhook:http://example.com"]}}`)); err == nil {
+		t.Error("Expected error for a webhook that is not https")
+	}
+}
+
+func TestParseTenantRegistry_Vision(t *testing.T) {
+	registry, err := ParseTenantRegistry([]byte(`{"acme":{"vision":{"features":["labels","moderation"],"delivery":"artifact"}},"other":{}}`))
+	if err != nil {
+		t.Fatalf("ParseTenantRegistry failed: %v", err)
+	}
+	if vision := registry.Lookup("acme").Vision; vision == nil || !vision.HasFeature(VisionFeatureModeration) || vision.Delivery != VisionDeliveryArtifact {
+		t.Errorf("Unexpected vision config %+v", vision)
+	}
+	if registry.Lookup("other").Vision != nil || !registry.HasVision() {
+		t.Error("Expected vision enabled for acme only")
+	}
+	if (TenantRegistry{"other": {}}).HasVision() {
+		t.Error("Expected no vision without a tenant enabling it")
+	}
+
+	if _, err := ParseTenantRegistry([]byte(`{"acme":{"vision":{"features":["faces"]}}}`)); err == nil {
+		t.Error("Expected error for an unsupported vision feature")
+	}
+}
+
+func TestParseTenantRegistry_Retention(t *testing.T) {
+	registry, err := ParseTenantRegistry([]byte(`{"acme":{"retention":{"days":3,"output_types":["sprite"],"delete":true}}}`))
+	if err != nil {
+		t.Fatalf("ParseTenantRegistry failed: %v", err)
+	}
+	if retention := registry.Lookup("acme").Retention; retention == nil || retention.Days != 3 || !retention.Delete {
+		t.Errorf("Unexpected retention %+v", retention)
+	}
+
+	for _, config := range []string{`{"days":0}`, `{"days":7,"output_types":["thumbnails"]}`} {
+		if _, err := ParseTenantRegistry([]byte(`{"acme":{"retention":` + config + `}}`)); err == nil {
+			t.Errorf("Expected retention %s rejected", config)
+		}
+	}
+}
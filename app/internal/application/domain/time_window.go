@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+)
+
+const maxTimeWindows = 20
+
+// TimeWindow is a range of the video in seconds; End zero means "until the end of the video"
+type TimeWindow struct {
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+}
+
+func (w TimeWindow) openEnded() bool {
+	return w.End == 0
+}
+
+// ValidateTimeWindows rejects negative or inverted ranges and too many windows
+func ValidateTimeWindows(windows []TimeWindow) error {
+	if len(windows) > maxTimeWindows {
+		return fmt.Errorf("at most %d time windows are supported", maxTimeWindows)
+	}
+	for _, window := range windows {
+		if window.Start < 0 || window.End < 0 {
+			return fmt.Errorf("time window must not be negative: %v-%v", window.Start, window.End)
+		}
+		if !window.openEnded() && window.End <= window.Start {
+			return fmt.Errorf("time window end must be after its start: %v-%v", window.Start, window.End)
+		}
+	}
+	return nil
+}
+
+// MergeTimeWindows sorts the windows and merges the overlapping ones, so no part of the
+// video is processed twice
+func MergeTimeWindows(windows []TimeWindow) []TimeWindow {
+	if len(windows) == 0 {
+		return nil
+	}
+
+	sorted := append([]TimeWindow(nil), windows...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Start < sorted[j].Start })
+
+	merged := []TimeWindow{sorted[0]}
+	for _, window := range sorted[1:] {
+		last := &merged[len(merged)-1]
+		switch {
+		case last.openEnded():
+			// The last window already runs until the end of the video
+		case window.Start > last.End:
+			merged = append(merged, window)
+		case window.openEnded() || window.End > last.End:
+			last.End = window.End
+		}
+	}
+	return merged
+}
+
+// ProcessedSeconds is the part of the video covered by the windows, or all of it without windows
+func ProcessedSeconds(duration float64, windows []TimeWindow) float64 {
+	if len(windows) == 0 {
+		return duration
+	}
+
+	total := 0.0
+	for _, window := range windows {
+		end := window.End
+		if end == 0 || end > duration {
+			end = duration
+		}
+		if end > window.Start {
+			total += end - window.Start
+		}
+	}
+	return total
+}
@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidateTimeWindows(t *testing.T) {
+	valid := []TimeWindow{{Start: 0, End: 10}, {Start: 30}}
+	if err := ValidateTimeWindows(valid); err != nil {
+		t.Errorf("Expected valid windows, got %v", err)
+	}
+
+	invalid := [][]TimeWindow{
+		{{Start: -1, End: 10}},
+		{{Start: 10, End: 5}},
+		{{Start: 10, End: 10}},
+		make([]TimeWindow, maxTimeWindows+1),
+	}
+	for _, windows := range invalid {
+		if err := ValidateTimeWindows(windows); err == nil {
+			t.Errorf("Expected error for %v", windows)
+		}
+	}
+}
+
+func TestMergeTimeWindows(t *testing.T) {
+	tests := []struct {
+		name     string
+		windows  []TimeWindow
+		expected []TimeWindow
+	}{
+		{"empty", nil, nil},
+		{"disjoint unsorted", []TimeWindow{{40, 50}, {10, 20}}, []TimeWindow{{10, 20}, {40, 50}}},
+		{"overlapping", []TimeWindow{{10, 20}, {15, 30}, {25, 28}}, []TimeWindow{{10, 30}}},
+		{"touching", []TimeWindow{{10, 20}, {20, 30}}, []TimeWindow{{10, 30}}},
+		{"open ended absorbs later", []TimeWindow{{10, 0}, {20, 30}}, []TimeWindow{{10, 0}}},
+		{"open ended extends", []TimeWindow{{10, 20}, {15, 0}}, []TimeWindow{{10, 0}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MergeTimeWindows(tt.windows); !reflect.DeepEqual(got, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestProcessedSeconds(t *testing.T) {
+	if seconds := ProcessedSeconds(100, nil); seconds != 100 {
+		t.Errorf("Expected the whole video, got %v", seconds)
+	}
+	windows := []TimeWindow{{Start: 10, End: 20}, {Start: 90}}
+	if seconds := ProcessedSeconds(100, windows); seconds != 20 {
+		t.Errorf("Expected 20 seconds, got %v", seconds)
+	}
+}
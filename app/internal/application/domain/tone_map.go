@@ -0,0 +1,36 @@
+package domain
+
+import "fmt"
+
+const (
+	ToneMapAuto     = "auto"
+	ToneMapHable    = "hable"
+	ToneMapMobius   = "mobius"
+	ToneMapReinhard = "reinhard"
+	ToneMapClip     = "clip"
+)
+
+var supportedToneMaps = map[string]bool{
+	ToneMapAuto:     true,
+	ToneMapHable:    true,
+	ToneMapMobius:   true,
+	ToneMapReinhard: true,
+	ToneMapClip:     true,
+}
+
+// ValidateToneMap checks the HDR tone mapping option; empty disables tone mapping
+func ValidateToneMap(toneMap string) error {
+	if toneMap == "" || supportedToneMaps[toneMap] {
+		return nil
+	}
+	return fmt.Errorf("unsupported tone map: %s", toneMap)
+}
+
+// ToneMapAlgorithm resolves the option to the ffmpeg tonemap algorithm; auto uses hable,
+// which keeps highlight detail on most HDR footage
+func ToneMapAlgorithm(toneMap string) string {
+	if toneMap == ToneMapAuto {
+		return ToneMapHable
+	}
+	return toneMap
+}
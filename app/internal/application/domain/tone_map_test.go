@@ -0,0 +1,26 @@
+package domain
+
+import "testing"
+
+func TestValidateToneMap(t *testing.T) {
+	for _, toneMap := range []string{"", ToneMapAuto, ToneMapHable, ToneMapMobius, ToneMapReinhard, ToneMapClip} {
+		if err := ValidateToneMap(toneMap); err != nil {
+			t.Errorf("Expected %q to be valid, got %v", toneMap, err)
+		}
+	}
+	if err := ValidateToneMap("aces"); err == nil {
+		t.Error("Expected error for unsupported tone map")
+	}
+}
+
+func TestToneMapAlgorithm(t *testing.T) {
+	if got := ToneMapAlgorithm(ToneMapAuto); got != ToneMapHable {
+		t.Errorf("Expected auto to resolve to hable, got %s", got)
+	}
+	if got := ToneMapAlgorithm(ToneMapMobius); got != ToneMapMobius {
+		t.Errorf("Expected mobius, got %s", got)
+	}
+	if got := ToneMapAlgorithm(""); got != "" {
+		t.Errorf("Expected empty for disabled tone mapping, got %s", got)
+	}
+}
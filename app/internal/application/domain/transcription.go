@@ -0,0 +1,104 @@
+package domain
+
+import (
+	"fmt"
+	"regexp"
+)
+
+const (
+	// TranscriptionProviderTranscribe starts an AWS Transcribe job for the output
+	TranscriptionProviderTranscribe = "transcribe"
+
+	// TranscriptionProviderQueue publishes the output to a transcription queue, for a
+	// transcription service of the consumer
+	TranscriptionProviderQueue = "queue"
+
+	// maxTranscriptionJobName is the longest job name AWS Transcribe accepts
+	maxTranscriptionJobName = 200
+)
+
+// transcriptionLanguagePattern matches the language codes of AWS Transcribe, e.g. pt-BR or en-US
+var transcriptionLanguagePattern = regexp.MustCompile(`^[a-z]{2,3}-[A-Z]{2}$`)
+
+// transcriptionJobNameInvalid matches the characters AWS Transcribe rejects in job names
+var transcriptionJobNameInvalid = regexp.MustCompile(`[^0-9A-Za-z._-]`)
+
+// ValidateTranscriptionProvider checks the provider of the worker; empty disables transcriptions
+func ValidateTranscriptionProvider(provider string) error {
+	switch provider {
+	case "", TranscriptionProviderTranscribe, TranscriptionProviderQueue:
+		return nil
+	}
+	return fmt.Errorf("unsupported transcription provider: %s", provider)
+}
+
+// TranscriptionOptions starts a transcription of the video uploaded by the loudnorm or trim
+// output, so the consumer does not have to chain it
+type TranscriptionOptions struct {
+	Enabled bool `json:"enabled"`
+
+	// Language is the code of the spoken language, e.g. pt-BR; empty lets the provider identify it
+	Language string `json:"language"`
+}
+
+func (o TranscriptionOptions) Validate() error {
+	if !o.Enabled {
+		if o.Language != "" {
+			return fmt.Errorf("transcription.language requires transcription.enabled")
+		}
+		return nil
+	}
+	if o.Language != "" && !transcriptionLanguagePattern.MatchString(o.Language) {
+		return fmt.Errorf("invalid transcription language: %q", o.Language)
+	}
+	return nil
+}
+
+// ValidateTranscription checks the transcription fields of a request; only the outputs that
+// are a single video with its audio can be transcribed
+func (v VideoProcess) ValidateTranscription() error {
+	if err := v.Transcription.Validate(); err != nil {
+		return err
+	}
+	if !v.Transcription.Enabled {
+		return nil
+	}
+	if v.OutputType != OutputTypeLoudnorm && v.OutputType != OutputTypeTrim {
+		return fmt.Errorf("transcription requires the %s or %s output", OutputTypeLoudnorm, OutputTypeTrim)
+	}
+	return nil
+}
+
+// TranscriptionRequest is the transcription of an uploaded output, whose transcript is expected
+// at TranscriptBucket/TranscriptKey
+type TranscriptionRequest struct {
+	JobName          string
+	ProcessID        string
+	MediaBucket      string
+	MediaKey         string
+	Language         string
+	TranscriptBucket string
+	TranscriptKey    string
+}
+
+// TranscriptionJobName names the transcription of a job after its id, which is unique per
+// attempt, in the characters and length AWS Transcribe accepts
+func TranscriptionJobName(jobID string) string {
+	name := transcriptionJobNameInvalid.ReplaceAllString(jobID, "-")
+	if len(name) > maxTranscriptionJobName {
+		// The random suffix of the job id is kept, as it tells the attempts apart
+		name = name[len(name)-maxTranscriptionJobName:]
+	}
+	return name
+}
+
+// TranscriptionRef points the consumer to the transcription started for the output; the
+// transcript is written to TranscriptKey of the output bucket once it finishes
+type TranscriptionRef struct {
+	Provider      string `json:"provider"`
+	JobName       string `json:"job_name"`
+	TranscriptKey string `json:"transcript_key"`
+
+	// MessageID is the message published to the transcription queue; empty for Transcribe
+	MessageID string `json:"message_id,omitempty"`
+}
@@ -0,0 +1,69 @@
+package domain
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateTranscriptionProvider(t *testing.T) {
+	for _, provider := range []string{"", TranscriptionProviderTranscribe, TranscriptionProviderQueue} {
+		if err := ValidateTranscriptionProvider(provider); err != nil {
+			t.Errorf("Expected %q to be valid, got %v", provider, err)
+		}
+	}
+	if err := ValidateTranscriptionProvider("whisper"); err == nil {
+		t.Error("Expected an unknown provider to be rejected")
+	}
+}
+
+func TestVideoProcess_ValidateTranscription(t *testing.T) {
+	tests := []struct {
+		name    string
+		request VideoProcess
+		valid   bool
+	}{
+		{"disabled", VideoProcess{}, true},
+		{"loudnorm", VideoProcess{OutputType: OutputTypeLoudnorm, Transcription: TranscriptionOptions{Enabled: true}}, true},
+		{"trim with language", VideoProcess{OutputType: OutputTypeTrim, Transcription: TranscriptionOptions{Enabled: true, Language: "pt-BR"}}, true},
+		{"three letter language", VideoProcess{OutputType: OutputTypeTrim, Transcription: TranscriptionOptions{Enabled: true, Language: "haw-US"}}, true},
+		{"language without enabled", VideoProcess{OutputType: OutputTypeTrim, Transcription: TranscriptionOptions{Language: "pt-BR"}}, false},
+		{"invalid language", VideoProcess{OutputType: OutputTypeTrim, Transcription: TranscriptionOptions{Enabled: true, Language: "portuguese"}}, false},
+		{"lower case region", VideoProcess{OutputType: OutputTypeTrim, Transcription: TranscriptionOptions{Enabled: true, Language: "pt-br"}}, false},
+		{"frames", VideoProcess{Transcription: TranscriptionOptions{Enabled: true}}, false},
+		{"concat", VideoProcess{OutputType: OutputTypeConcat, Transcription: TranscriptionOptions{Enabled: true}}, false},
+	}
+
+	for _, tt := range tests {
+		err := tt.request.ValidateTranscription()
+		if tt.valid && err != nil {
+			t.Errorf("%s: expected valid, got %v", tt.name, err)
+		}
+		if !tt.valid && err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
+
+func TestTranscriptionJobName(t *testing.T) {
+	if name := TranscriptionJobName("order:42_0a1b2c3d4e5f6a7b"); name != "order-42_0a1b2c3d4e5f6a7b" {
+		t.Errorf("Expected invalid characters replaced, got %s", name)
+	}
+
+	long := TranscriptionJobName(strings.Repeat("p", 250) + "_0a1b2c3d4e5f6a7b")
+	if len(long) != 200 || !strings.HasSuffix(long, "_0a1b2c3d4e5f6a7b") {
+		t.Errorf("Expected 200 characters ending with the job suffix, got %d: %s", len(long), long)
+	}
+}
+
+func TestProcessResult_Transcription(t *testing.T) {
+	result := &ProcessResult{ProcessID: "p1", FileKey: "processed/loudnorm_p1.mp4"}
+	if _, ok := result.ToSuccessMessage()["transcription"]; ok {
+		t.Error("Expected no transcription without one started")
+	}
+
+	result.Transcription = &TranscriptionRef{Provider: TranscriptionProviderTranscribe, JobName: "p1_abc", TranscriptKey: "processed/transcript_p1.json"}
+	ref, ok := result.ToSuccessMessage()["transcription"].(*TranscriptionRef)
+	if !ok || ref.JobName != "p1_abc" {
+		t.Errorf("Expected the transcription in the message, got %v", result.ToSuccessMessage()["transcription"])
+	}
+}
@@ -0,0 +1,29 @@
+package domain
+
+const (
+	TransferStageDownload = "download"
+	TransferStageUpload   = "upload"
+)
+
+// TransferProgress is the optional message reporting how far the download or upload of a job
+// got; TotalBytes and Percent are omitted when the size is not known up front
+type TransferProgress struct {
+	ProcessID  string  `json:"process_id"`
+	Stage      string  `json:"stage"`
+	Bytes      int64   `json:"bytes"`
+	TotalBytes int64   `json:"total_bytes,omitempty"`
+	Percent    float64 `json:"percent,omitempty"`
+}
+
+func NewTransferProgress(processID, stage string, bytes, totalBytes int64) TransferProgress {
+	progress := TransferProgress{
+		ProcessID:  processID,
+		Stage:      stage,
+		Bytes:      bytes,
+		TotalBytes: totalBytes,
+	}
+	if totalBytes > 0 {
+		progress.Percent = float64(bytes) * 100 / float64(totalBytes)
+	}
+	return progress
+}
@@ -0,0 +1,19 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestNewTransferProgress(t *testing.T) {
+	progress := NewTransferProgress("123", TransferStageUpload, 25, 100)
+	if progress.Percent != 25 {
+		t.Errorf("Expected 25%%, got %v", progress.Percent)
+	}
+
+	// Downloads do not know their size, so only the bytes are reported
+	body, _ := json.Marshal(NewTransferProgress("123", TransferStageDownload, 2048, 0))
+	if string(body) != `{"process_id":"123","stage":"download","bytes":2048}` {
+		t.Errorf("Unexpected progress message %s", body)
+	}
+}
@@ -0,0 +1,44 @@
+package domain
+
+import "fmt"
+
+// TrimOptions is the range of the video kept by the trim output, in seconds; End zero keeps
+// the video until its end
+type TrimOptions struct {
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+
+	// Mode is EditModeCopy (the default), which starts the cut on the keyframe before Start, or
+	// EditModeReencode for an exact cut
+	Mode string `json:"mode"`
+}
+
+// Window is the range kept, as a time window
+func (o TrimOptions) Window() TimeWindow {
+	return TimeWindow{Start: o.Start, End: o.End}
+}
+
+// Validate rejects unknown modes, invalid ranges and a range that keeps the whole video
+func (o TrimOptions) Validate() error {
+	if err := validateEditMode(OutputTypeTrim, o.Mode); err != nil {
+		return err
+	}
+	if o.Start == 0 && o.End == 0 {
+		return fmt.Errorf("%s output requires trim.start or trim.end", OutputTypeTrim)
+	}
+	return ValidateTimeWindows([]TimeWindow{o.Window()})
+}
+
+// ValidateTrim checks the trim fields of a request
+func (v VideoProcess) ValidateTrim() error {
+	if v.OutputType != OutputTypeTrim {
+		if v.Trim != (TrimOptions{}) {
+			return fmt.Errorf("trim options require the %s output", OutputTypeTrim)
+		}
+		return nil
+	}
+	if len(v.Windows) > 0 {
+		return fmt.Errorf("time windows are not supported for %s output, use trim.start and trim.end", OutputTypeTrim)
+	}
+	return v.Trim.Validate()
+}
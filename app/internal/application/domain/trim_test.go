@@ -0,0 +1,32 @@
+package domain
+
+import "testing"
+
+func TestVideoProcess_ValidateTrim(t *testing.T) {
+	tests := []struct {
+		name    string
+		request VideoProcess
+		valid   bool
+	}{
+		{"not trim", VideoProcess{}, true},
+		{"range", VideoProcess{OutputType: OutputTypeTrim, Trim: TrimOptions{Start: 10, End: 25.5}}, true},
+		{"until the end", VideoProcess{OutputType: OutputTypeTrim, Trim: TrimOptions{Start: 10}}, true},
+		{"from the start", VideoProcess{OutputType: OutputTypeTrim, Trim: TrimOptions{End: 30, Mode: EditModeReencode}}, true},
+		{"whole video", VideoProcess{OutputType: OutputTypeTrim}, false},
+		{"inverted", VideoProcess{OutputType: OutputTypeTrim, Trim: TrimOptions{Start: 30, End: 10}}, false},
+		{"negative", VideoProcess{OutputType: OutputTypeTrim, Trim: TrimOptions{Start: -1}}, false},
+		{"unknown mode", VideoProcess{OutputType: OutputTypeTrim, Trim: TrimOptions{Start: 1, Mode: "fast"}}, false},
+		{"windows", VideoProcess{OutputType: OutputTypeTrim, Trim: TrimOptions{Start: 1}, Windows: []TimeWindow{{Start: 1}}}, false},
+		{"options without trim", VideoProcess{Trim: TrimOptions{Start: 1}}, false},
+	}
+
+	for _, tt := range tests {
+		err := tt.request.ValidateTrim()
+		if tt.valid && err != nil {
+			t.Errorf("%s: expected valid, got %v", tt.name, err)
+		}
+		if !tt.valid && err == nil {
+			t.Errorf("%s: expected an error", tt.name)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"slices"
+	"strings"
+)
+
+// DefaultVideoExtensions are the formats the worker accepts when VIDEO_EXTENSIONS is not set
+var DefaultVideoExtensions = VideoExtensions{
+	".mp4", ".m4v", ".mov", ".mkv", ".webm", ".avi", ".mpg", ".mpeg", ".ts", ".3gp",
+}
+
+// VideoExtensions are the file extensions, lowercase and with the leading dot, of the videos
+// the worker processes
+type VideoExtensions []string
+
+// ParseVideoExtensions reads a comma separated list of extensions, with or without the dot
+// (e.g. "mp4,.mov"); an empty value keeps the defaults
+func ParseVideoExtensions(value string) (VideoExtensions, error) {
+	var extensions VideoExtensions
+	for _, item := range strings.Split(value, ",") {
+		if strings.TrimSpace(item) == "" {
+			continue
+		}
+		extension, err := parseVideoExtension(item)
+		if err != nil {
+			return nil, err
+		}
+		extensions = append(extensions, extension)
+	}
+	if len(extensions) == 0 {
+		return DefaultVideoExtensions, nil
+	}
+	return extensions, nil
+}
+
+func parseVideoExtension(value string) (string, error) {
+	extension := "." + strings.TrimPrefix(strings.ToLower(strings.TrimSpace(value)), ".")
+	if extension == "." || strings.ContainsAny(extension[1:], "./\\ ") {
+		return "", fmt.Errorf("invalid video extension %q", value)
+	}
+	return extension, nil
+}
+
+// UnmarshalJSON reads the extensions from a list, as in the tenant configuration
+func (e *VideoExtensions) UnmarshalJSON(data []byte) error {
+	var values []string
+	if err := json.Unmarshal(data, &values); err != nil {
+		return fmt.Errorf("video extensions must be a list of extensions: %w", err)
+	}
+
+	extensions := make(VideoExtensions, 0, len(values))
+	for _, value := range values {
+		extension, err := parseVideoExtension(value)
+		if err != nil {
+			return err
+		}
+		extensions = append(extensions, extension)
+	}
+	*e = extensions
+	return nil
+}
+
+// Allows reports whether the file name has one of the extensions, ignoring case
+func (e VideoExtensions) Allows(name string) bool {
+	return slices.Contains(e, strings.ToLower(path.Ext(name)))
+}
+
+// IsVideoKey reports whether the object key looks like a video by its extension
+func IsVideoKey(key string) bool {
+	return DefaultVideoExtensions.Allows(key)
+}
+
+// VideoSniffLength is how many bytes from the start of a file IsVideoContent needs
+const VideoSniffLength = 3 * mpegTSPacketLength
+
+const mpegTSPacketLength = 188
+
+// isoBoxTypes are the boxes an MP4, MOV, M4V or 3GP file starts with; QuickTime files may
+// start with a box other than ftyp
+var isoBoxTypes = [][]byte{[]byte("ftyp"), []byte("moov"), []byte("mdat"), []byte("wide"), []byte("free")}
+
+// IsVideoContent reports whether the start of a file is the header of a video container the
+// worker handles: ISO base media (MP4, MOV, 3GP), Matroska/WebM, AVI, MPEG program or
+// transport stream
+func IsVideoContent(header []byte) bool {
+	switch {
+	case len(header) >= 8 && slices.ContainsFunc(isoBoxTypes, func(box []byte) bool { return bytes.Equal(header[4:8], box) }):
+		return true
+	case bytes.HasPrefix(header, []byte{0x1A, 0x45, 0xDF, 0xA3}):
+		return true
+	case len(header) >= 12 && bytes.Equal(header[:4], []byte("RIFF")) && bytes.Equal(header[8:12], []byte("AVI ")):
+		return true
+	case bytes.HasPrefix(header, []byte{0x00, 0x00, 0x01, 0xBA}):
+		return true
+	}
+
+	// A transport stream has a sync byte every packet; three in a row rule out chance
+	if len(header) < VideoSniffLength {
+		return false
+	}
+	for offset := 0; offset < VideoSniffLength; offset += mpegTSPacketLength {
+		if header[offset] != 0x47 {
+			return false
+		}
+	}
+	return true
+}
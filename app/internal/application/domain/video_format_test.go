@@ -0,0 +1,88 @@
+package domain
+
+import (
+	"bytes"
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func TestIsVideoKey(t *testing.T) {
+	for key, want := range map[string]bool{
+		"videos/a.mp4":      true,
+		"videos/B.MOV":      true,
+		"videos/c.webm":     true,
+		"videos/d.3gp":      true,
+		"videos/e.ts":       true,
+		"videos/notes.txt":  false,
+		"videos/manifest":   false,
+		"processed/f_1.zip": false,
+	} {
+		if got := IsVideoKey(key); got != want {
+			t.Errorf("IsVideoKey(%q) = %v, want %v", key, got, want)
+		}
+	}
+}
+
+func TestParseVideoExtensions(t *testing.T) {
+	extensions, err := ParseVideoExtensions(" mp4 , .MOV ,")
+	if err != nil {
+		t.Fatalf("ParseVideoExtensions failed: %v", err)
+	}
+	if !slices.Equal(extensions, VideoExtensions{".mp4", ".mov"}) {
+		t.Errorf("Expected [.mp4 .mov], got %v", extensions)
+	}
+
+	if extensions, err := ParseVideoExtensions(""); err != nil || !slices.Equal(extensions, DefaultVideoExtensions) {
+		t.Errorf("Expected the default extensions, got %v, %v", extensions, err)
+	}
+	for _, value := range []string{".", "tar.gz", "a/b"} {
+		if _, err := ParseVideoExtensions(value); err == nil {
+			t.Errorf("Expected error for %q", value)
+		}
+	}
+}
+
+func TestVideoExtensions_UnmarshalJSON(t *testing.T) {
+	var cfg TenantConfig
+	if err := json.Unmarshal([]byte(`{"video_extensions":["MXF",".mp4"]}`), &cfg); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if !cfg.VideoExtensions.Allows("clip.mxf") || cfg.VideoExtensions.Allows("clip.mov") {
+		t.Errorf("Expected only mxf and mp4 allowed, got %v", cfg.VideoExtensions)
+	}
+
+	if err := json.Unmarshal([]byte(`{"video_extensions":"mp4"}`), &cfg); err == nil {
+		t.Error("Expected error for extensions that are not a list")
+	}
+}
+
+func TestIsVideoContent(t *testing.T) {
+	transportStream := make([]byte, VideoSniffLength)
+	for offset := 0; offset < len(transportStream); offset += 188 {
+		transportStream[offset] = 0x47
+	}
+
+	tests := []struct {
+		name   string
+		header []byte
+		want   bool
+	}{
+		{"mp4", []byte("\x00\x00\x00\x18ftypisom"), true},
+		{"quicktime", []byte("\x00\x00\x00\x08wide\x00\x00"), true},
+		{"matroska", []byte{0x1A, 0x45, 0xDF, 0xA3, 0x01}, true},
+		{"avi", []byte("RIFF\x00\x00\x00\x00AVI LIST"), true},
+		{"wav", []byte("RIFF\x00\x00\x00\x00WAVEfmt "), false},
+		{"mpeg program stream", []byte{0x00, 0x00, 0x01, 0xBA, 0x44}, true},
+		{"mpeg transport stream", transportStream, true},
+		{"short transport stream", transportStream[:200], false},
+		{"text", bytes.Repeat([]byte("frame "), 100), false},
+		{"png", []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\x0dIHDR"), false},
+		{"empty", nil, false},
+	}
+	for _, tt := range tests {
+		if got := IsVideoContent(tt.header); got != tt.want {
+			t.Errorf("IsVideoContent(%s) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -1,37 +1,306 @@
 package domain
 
-import "time"
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
 
 type VideoProcess struct {
-	ProcessID   string
-	VideoBucket string
-	VideoKey    string
-	CreatedAt   time.Time
+	ProcessID         string
+	VideoBucket       string
+	VideoKey          string
+	TenantID          string
+	StorageClass      string
+	OutputType        string
+	Sprite            SpriteOptions
+	Packaging         PackagingOptions
+	Concat            ConcatOptions
+	Trim              TrimOptions
+	Loudness          LoudnessOptions
+	Transcription     TranscriptionOptions
+	QC                QCOptions
+	Fingerprint       FingerprintOptions
+	Subtitles         SubtitleOptions
+	DisableAutoRotate bool
+	ToneMap           string
+	Windows           []TimeWindow
+	FPS               float64
+	MaxFrames         int
+	Sharpness         SharpnessOptions
+	Colors            ColorOptions
+	Barcodes          BarcodeOptions
+	OCR               OCROptions
+	Anonymize         AnonymizeOptions
+	Image             ImageOptions
+	Archive           ArchiveOptions
+	FrameName         FrameNameTemplate
+	Accept            AcceptOptions
+	DryRun            bool
+	KeepOriginal      bool
+	CreatedAt         time.Time
+
+	// Source pins the version of VideoKey the job reads (video_etag, video_version_id), so a
+	// video replaced after the job was enqueued fails with source_modified
+	Source ObjectCondition
+
+	// VideoURL is a presigned or public URL read instead of VideoBucket/VideoKey; the
+	// worker does not delete videos it read from a URL
+	VideoURL string
+
+	// VideoKeys make the request a batch of videos of VideoBucket under one process_id, with
+	// either one zip per video or a combined one, as chosen by BatchOutput. With the concat
+	// output they are the clips to join, in order
+	VideoKeys   []string
+	BatchOutput string
+
+	// OutputBucket and OutputPrefix write the outputs somewhere else than the worker bucket and
+	// processed/; the location must be allowed by the worker or the tenant
+	OutputBucket string
+	OutputPrefix string
+
+	// ResultDestinations are delivered the result besides the output queue and the tenant's
+	// destinations; each must be allowed by the worker or the tenant
+	ResultDestinations ResultDestinations
+
+	// Version numbers the outputs of a reprocess, from 2, so they are written beside the
+	// previous ones (frames_{id}_v2.zip); 0 for the first run
+	Version int
+
+	// Attempt counts the deliveries of the job message, starting at 1; 0 when unknown
+	Attempt int
+
+	// EnqueuedAt is when the job was submitted, from the message created_at or the queue's
+	// sent timestamp; zero when unknown
+	EnqueuedAt time.Time
+
+	// Metadata is opaque to the worker and copied to the result as is
+	Metadata map[string]json.RawMessage
+}
+
+// OutputID names the outputs of the job: the process_id, with the version of a reprocess
+func (v VideoProcess) OutputID() string {
+	if v.Version > 1 {
+		return fmt.Sprintf("%s_v%d", v.ProcessID, v.Version)
+	}
+	return v.ProcessID
+}
+
+// Age is the time since the job was submitted; zero when EnqueuedAt is unknown or ahead of
+// now, as with a producer whose clock is ahead of the worker's
+func (v VideoProcess) Age(now time.Time) time.Duration {
+	if v.EnqueuedAt.IsZero() || now.Before(v.EnqueuedAt) {
+		return 0
+	}
+	return now.Sub(v.EnqueuedAt)
 }
 
 type ProcessResult struct {
 	ProcessID  string
 	FileBucket string
 	FileKey    string
+	FileKeys   []string
+	OutputType string
+	Worker     *WorkerIdentity
+	Estimate   *OutputEstimate
 	Success    bool
 	Error      error
+
+	// ConfirmRequired tells the consumer the original video is only deleted once it sends a
+	// DeletionConfirmation for the process
+	ConfirmRequired bool
+
+	// Retry tells an error result apart as temporary or final; nil when the attempt is unknown
+	Retry *RetryInfo
+
+	// SLABreached reports a job that took longer than the SLA from its submission to its result
+	SLABreached bool
+
+	// Usage is what the job consumed until its result; nil unless the worker reports it
+	Usage *ResourceUsage
+
+	// Negotiated is the encoding and image format picked from the accept options of the
+	// request; nil when it declared none
+	Negotiated *NegotiatedOutput
+
+	// Quarantine is where the video of a failed job was moved; nil when it was left in place
+	Quarantine *ObjectRef
+
+	// ProcessingBackend is where the frames were extracted (ProcessingBackendLocal or
+	// ProcessingBackendRemote); empty for the outputs not routed between backends
+	ProcessingBackend string
+
+	// Source describes the source video as read from its bucket before the download; nil for
+	// video_url, batches and concat outputs
+	Source *ObjectInfo
+
+	// Loudness is the audio measured by the loudnorm output; nil for the other outputs
+	Loudness *LoudnessStats
+
+	// Transcription is the transcription started for the output; nil unless one was requested
+	Transcription *TranscriptionRef
+
+	// QC is the report of the qc output, which uploads no file; nil for the other outputs
+	QC *QCReport
+
+	// Barcodes are the values found by a barcode scan; nil when no scan was requested
+	Barcodes []BarcodeDetection
+
+	// Vision is the analysis of sampled frames for tenants with vision, unless they get it as
+	// the artifact at VisionKey
+	Vision    *VisionReport
+	VisionKey string
+
+	// Items are the outcomes of the videos of a batch, aggregated in BatchStatus
+	Items       []BatchItemResult
+	BatchStatus string
+
+	// EnqueuedAt is when the job was submitted, to check the SLA; it is not part of the message
+	EnqueuedAt time.Time
+
+	// Destinations are delivered the result besides the output queue; they are not part of
+	// the message
+	Destinations ResultDestinations
+
+	Metadata map[string]json.RawMessage
 }
 
 func (r *ProcessResult) ToSuccessMessage() map[string]interface{} {
-	return map[string]interface{}{
-		"process_id":  r.ProcessID,
-		"file_bucket": r.FileBucket,
-		"file_key":    r.FileKey,
+	msg := map[string]interface{}{
+		"process_id": r.ProcessID,
+	}
+	// The qc output reports on the video without writing any file
+	if r.QC == nil {
+		msg["file_bucket"] = r.FileBucket
+		msg["file_key"] = r.FileKey
+	}
+	if len(r.FileKeys) > 0 {
+		msg["file_keys"] = r.FileKeys
+	}
+	if r.OutputType != "" {
+		msg["output_type"] = r.OutputType
+	}
+	if r.ConfirmRequired {
+		msg["confirm_required"] = true
 	}
+	if r.Source != nil {
+		msg["source"] = r.Source
+	}
+	if r.Loudness != nil {
+		msg["loudness"] = r.Loudness
+	}
+	if r.Transcription != nil {
+		msg["transcription"] = r.Transcription
+	}
+	if r.QC != nil {
+		msg["qc"] = r.QC
+	}
+	if r.Barcodes != nil {
+		msg["barcodes"] = r.Barcodes
+	}
+	if r.Vision != nil {
+		msg["vision"] = r.Vision
+	}
+	if r.VisionKey != "" {
+		msg["vision_key"] = r.VisionKey
+	}
+	if len(r.Items) > 0 {
+		msg["batch_status"] = r.BatchStatus
+		msg["items"] = r.Items
+	}
+	if r.SLABreached {
+		msg["sla_breached"] = true
+	}
+	if r.Usage != nil {
+		msg["usage"] = r.Usage
+	}
+	if r.ProcessingBackend != "" {
+		msg["processing_backend"] = r.ProcessingBackend
+	}
+	if r.Negotiated != nil {
+		msg["negotiated"] = r.Negotiated
+	}
+	if r.Worker != nil {
+		msg["worker"] = r.Worker
+	}
+	if len(r.Metadata) > 0 {
+		msg["metadata"] = r.Metadata
+	}
+	return msg
 }
 
-func (r *ProcessResult) ToErrorMessage() map[string]interface{} {
-	errorMsg := "unknown error"
-	if r.Error != nil {
-		errorMsg = r.Error.Error()
+// ToDryRunMessage reports the estimate of a dry run; there is no file to point to
+func (r *ProcessResult) ToDryRunMessage() map[string]interface{} {
+	msg := map[string]interface{}{
+		"process_id": r.ProcessID,
+		"dry_run":    true,
+		"estimate":   r.Estimate,
+	}
+	if r.OutputType != "" {
+		msg["output_type"] = r.OutputType
+	}
+	if r.Source != nil {
+		msg["source"] = r.Source
+	}
+	if r.Negotiated != nil {
+		msg["negotiated"] = r.Negotiated
 	}
-	return map[string]interface{}{
+	if r.SLABreached {
+		msg["sla_breached"] = true
+	}
+	if r.Usage != nil {
+		msg["usage"] = r.Usage
+	}
+	if r.Worker != nil {
+		msg["worker"] = r.Worker
+	}
+	if len(r.Metadata) > 0 {
+		msg["metadata"] = r.Metadata
+	}
+	return msg
+}
+
+// ErrorMessage describes the failure reported to the consumer
+func (r *ProcessResult) ErrorMessage() string {
+	if r.Error == nil {
+		return "unknown error"
+	}
+	return r.Error.Error()
+}
+
+func (r *ProcessResult) ToErrorMessage() map[string]interface{} {
+	msg := map[string]interface{}{
 		"process_id":    r.ProcessID,
-		"error_message": errorMsg,
+		"error_message": r.ErrorMessage(),
+	}
+	if code := ErrorCode(r.Error); code != "" {
+		msg["error_code"] = code
+	}
+	if r.Quarantine != nil {
+		msg["quarantine"] = r.Quarantine
+	}
+	if r.Retry != nil {
+		msg["attempt"] = r.Retry.Attempt
+		if r.Retry.MaxAttempts > 0 {
+			msg["max_attempts"] = r.Retry.MaxAttempts
+		}
+		msg["will_retry"] = r.Retry.WillRetry
+	}
+	if len(r.Items) > 0 {
+		msg["batch_status"] = r.BatchStatus
+		msg["items"] = r.Items
+	}
+	if r.SLABreached {
+		msg["sla_breached"] = true
+	}
+	if r.Usage != nil {
+		msg["usage"] = r.Usage
+	}
+	if r.Worker != nil {
+		msg["worker"] = r.Worker
+	}
+	if len(r.Metadata) > 0 {
+		msg["metadata"] = r.Metadata
 	}
+	return msg
 }
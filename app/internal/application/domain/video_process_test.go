@@ -1,7 +1,9 @@
 package domain
 
 import (
+	"encoding/json"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 )
@@ -51,6 +53,30 @@ func TestProcessResult_ToSuccessMessage(t *testing.T) {
 	}
 }
 
+func TestProcessResult_ToSuccessMessage_ConfirmRequired(t *testing.T) {
+	result := ProcessResult{ProcessID: "process-123", FileBucket: "output-bucket", FileKey: "frames.zip"}
+	if _, ok := result.ToSuccessMessage()["confirm_required"]; ok {
+		t.Error("Expected no confirm_required by default")
+	}
+
+	result.ConfirmRequired = true
+	if msg := result.ToSuccessMessage(); msg["confirm_required"] != true {
+		t.Errorf("Expected confirm_required true, got %v", msg["confirm_required"])
+	}
+}
+
+func TestProcessResult_ToSuccessMessage_ProcessingBackend(t *testing.T) {
+	result := ProcessResult{ProcessID: "process-123", FileBucket: "output-bucket", FileKey: "frames.zip"}
+	if _, ok := result.ToSuccessMessage()["processing_backend"]; ok {
+		t.Error("Expected no processing_backend for outputs not routed")
+	}
+
+	result.ProcessingBackend = ProcessingBackendRemote
+	if msg := result.ToSuccessMessage(); msg["processing_backend"] != "remote" {
+		t.Errorf("Expected processing_backend remote, got %v", msg["processing_backend"])
+	}
+}
+
 func TestProcessResult_ToErrorMessage_WithError(t *testing.T) {
 	testError := errors.New("processing failed")
 	result := ProcessResult{
@@ -166,3 +192,195 @@ func TestProcessResult_SuccessAndErrorMessages(t *testing.T) {
 		})
 	}
 }
+
+func TestProcessResult_WorkerIdentity(t *testing.T) {
+	worker := &WorkerIdentity{Hostname: "processor-7d9f", Pod: "processor-7d9f-abc", Version: "v1.2.0", Commit: "abc1234"}
+	result := ProcessResult{ProcessID: "process-123", Worker: worker, Error: errors.New("boom")}
+
+	if msg := result.ToSuccessMessage(); msg["worker"] != worker {
+		t.Errorf("Expected worker in success message, got %v", msg["worker"])
+	}
+	if msg := result.ToErrorMessage(); msg["worker"] != worker {
+		t.Errorf("Expected worker in error message, got %v", msg["worker"])
+	}
+
+	if _, ok := (&ProcessResult{}).ToSuccessMessage()["worker"]; ok {
+		t.Error("Expected no worker field without an identity")
+	}
+}
+
+func TestProcessResult_ToDryRunMessage(t *testing.T) {
+	result := &ProcessResult{
+		ProcessID:  "123",
+		OutputType: OutputTypeFrames,
+		Estimate:   &OutputEstimate{DurationSeconds: 10, Count: 10, Bytes: 2048},
+	}
+
+	msg := result.ToDryRunMessage()
+	if msg["dry_run"] != true {
+		t.Errorf("Expected dry_run true, got %v", msg["dry_run"])
+	}
+	if msg["estimate"] != result.Estimate {
+		t.Errorf("Expected the estimate, got %v", msg["estimate"])
+	}
+	if _, ok := msg["file_key"]; ok {
+		t.Error("Expected no file_key on a dry run")
+	}
+}
+
+func TestProcessResult_Metadata(t *testing.T) {
+	metadata := map[string]json.RawMessage{"order_id": json.RawMessage(`12345678901234567890`)}
+	result := &ProcessResult{ProcessID: "123", Metadata: metadata, Error: errors.New("boom")}
+
+	for name, msg := range map[string]map[string]interface{}{
+		"success": result.ToSuccessMessage(),
+		"dry run": result.ToDryRunMessage(),
+		"error":   result.ToErrorMessage(),
+	} {
+		body, err := json.Marshal(msg)
+		if err != nil {
+			t.Fatalf("Marshal failed: %v", err)
+		}
+		if !strings.Contains(string(body), `"metadata":{"order_id":12345678901234567890}`) {
+			t.Errorf("Expected metadata unchanged in the %s message, got %s", name, body)
+		}
+	}
+
+	if _, ok := (&ProcessResult{}).ToSuccessMessage()["metadata"]; ok {
+		t.Error("Expected no metadata field without metadata")
+	}
+}
+
+func TestProcessResult_SLABreached(t *testing.T) {
+	result := &ProcessResult{ProcessID: "123", SLABreached: true, Error: errors.New("boom")}
+
+	for name, msg := range map[string]map[string]interface{}{
+		"success": result.ToSuccessMessage(),
+		"dry run": result.ToDryRunMessage(),
+		"error":   result.ToErrorMessage(),
+	} {
+		if msg["sla_breached"] != true {
+			t.Errorf("Expected sla_breached in the %s message, got %v", name, msg["sla_breached"])
+		}
+	}
+
+	if _, ok := (&ProcessResult{}).ToSuccessMessage()["sla_breached"]; ok {
+		t.Error("Expected no sla_breached field within the SLA")
+	}
+}
+
+func TestVideoProcess_Age(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		enqueuedAt time.Time
+		expected   time.Duration
+	}{
+		{"unknown submission", time.Time{}, 0},
+		{"submitted before now", now.Add(-90 * time.Second), 90 * time.Second},
+		{"producer clock ahead", now.Add(time.Minute), 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			age := VideoProcess{EnqueuedAt: tt.enqueuedAt}.Age(now)
+			if age != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, age)
+			}
+		})
+	}
+}
+
+func TestProcessResult_Usage(t *testing.T) {
+	result := &ProcessResult{ProcessID: "123", Usage: &ResourceUsage{S3GetRequests: 1}, Error: errors.New("boom")}
+
+	for name, msg := range map[string]map[string]interface{}{
+		"success": result.ToSuccessMessage(),
+		"dry run": result.ToDryRunMessage(),
+		"error":   result.ToErrorMessage(),
+	} {
+		if msg["usage"] != result.Usage {
+			t.Errorf("Expected the usage in the %s message, got %v", name, msg["usage"])
+		}
+	}
+
+	if _, ok := (&ProcessResult{}).ToSuccessMessage()["usage"]; ok {
+		t.Error("Expected no usage field unless reported")
+	}
+}
+
+func TestProcessResult_BatchItems(t *testing.T) {
+	result := &ProcessResult{
+		ProcessID:   "123",
+		Items:       []BatchItemResult{{VideoKey: "a.mp4", Error: "boom"}},
+		BatchStatus: BatchStatusFailed,
+		Error:       errors.New("all 1 videos of the batch failed"),
+	}
+
+	for name, msg := range map[string]map[string]interface{}{
+		"success": result.ToSuccessMessage(),
+		"error":   result.ToErrorMessage(),
+	} {
+		if msg["batch_status"] != BatchStatusFailed || len(msg["items"].([]BatchItemResult)) != 1 {
+			t.Errorf("Expected the batch fields in the %s message, got %v", name, msg)
+		}
+	}
+
+	if _, ok := (&ProcessResult{}).ToSuccessMessage()["items"]; ok {
+		t.Error("Expected no items outside batches")
+	}
+}
+
+func TestProcessResult_Loudness(t *testing.T) {
+	stats := &LoudnessStats{InputIntegrated: -27.5, OutputIntegrated: -23}
+	msg := (&ProcessResult{ProcessID: "123", Success: true, Loudness: stats}).ToSuccessMessage()
+
+	if msg["loudness"] != stats {
+		t.Errorf("Expected the loudness in the success message, got %v", msg)
+	}
+	if _, ok := (&ProcessResult{}).ToSuccessMessage()["loudness"]; ok {
+		t.Error("Expected no loudness outside the loudnorm output")
+	}
+}
+
+func TestProcessResult_QC(t *testing.T) {
+	report := &QCReport{DurationSeconds: 60, BlackIntervals: []TimeWindow{{Start: 0, End: 2.5}}}
+	msg := (&ProcessResult{ProcessID: "123", Success: true, OutputType: OutputTypeQC, QC: report}).ToSuccessMessage()
+
+	if msg["qc"] != report {
+		t.Errorf("Expected the qc report in the success message, got %v", msg)
+	}
+	if _, ok := msg["file_key"]; ok {
+		t.Error("Expected no file fields for the qc output")
+	}
+	if _, ok := (&ProcessResult{}).ToSuccessMessage()["file_key"]; !ok {
+		t.Error("Expected the file fields outside the qc output")
+	}
+}
+
+func TestProcessResult_Barcodes(t *testing.T) {
+	msg := (&ProcessResult{ProcessID: "123", Success: true, Barcodes: []BarcodeDetection{}}).ToSuccessMessage()
+	if barcodes, ok := msg["barcodes"].([]BarcodeDetection); !ok || len(barcodes) != 0 {
+		t.Errorf("Expected an empty list when the scan found nothing, got %v", msg)
+	}
+	if _, ok := (&ProcessResult{}).ToSuccessMessage()["barcodes"]; ok {
+		t.Error("Expected no barcodes without a scan")
+	}
+}
+
+func TestProcessResult_Vision(t *testing.T) {
+	report := &VisionReport{Flagged: true, Frames: []VisionFrame{{Time: 0, Moderation: []VisionLabel{{Name: "Weapons", Confidence: 80}}}}}
+	msg := (&ProcessResult{ProcessID: "123", Success: true, Vision: report}).ToSuccessMessage()
+	if msg["vision"] != report {
+		t.Errorf("Expected the vision report in the message, got %v", msg)
+	}
+	if _, ok := msg["vision_key"]; ok {
+		t.Error("Expected no vision_key when the report is inline")
+	}
+
+	msg = (&ProcessResult{ProcessID: "123", Success: true, VisionKey: "processed/vision_123.json"}).ToSuccessMessage()
+	if msg["vision_key"] != "processed/vision_123.json" || msg["vision"] != nil {
+		t.Errorf("Expected only the vision artifact key, got %v", msg)
+	}
+}
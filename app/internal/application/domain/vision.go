@@ -0,0 +1,110 @@
+package domain
+
+import "fmt"
+
+const (
+	VisionFeatureLabels     = "labels"
+	VisionFeatureModeration = "moderation"
+
+	// VisionDeliveryResult puts the analysis in the result message and VisionDeliveryArtifact
+	// uploads it as vision_{process_id}.json next to the output
+	VisionDeliveryResult   = "result"
+	VisionDeliveryArtifact = "artifact"
+
+	DefaultVisionInterval = 10
+	DefaultVisionFrames   = 20
+
+	// MaxVisionFrames bounds the frames sent to the vision API per job, each billed per feature
+	MaxVisionFrames = 100
+
+	maxVisionInterval = 600
+)
+
+// VisionConfig enables the analysis of sampled frames by a vision API for the jobs of a tenant;
+// zero values fall back to the defaults
+type VisionConfig struct {
+	// Features are labels (objects, scenes and concepts) and moderation (unsafe content)
+	Features []string `json:"features"`
+
+	// Delivery is VisionDeliveryResult, the default, or VisionDeliveryArtifact
+	Delivery string `json:"delivery,omitempty"`
+
+	// IntervalSeconds is the time between two sampled frames, at most MaxFrames of them
+	IntervalSeconds float64 `json:"interval_seconds,omitempty"`
+	MaxFrames       int     `json:"max_frames,omitempty"`
+
+	// MinConfidence drops the labels below this confidence, in percent; zero keeps the API default
+	MinConfidence float64 `json:"min_confidence,omitempty"`
+}
+
+// WithDefaults fills the unset fields
+func (c VisionConfig) WithDefaults() VisionConfig {
+	if c.Delivery == "" {
+		c.Delivery = VisionDeliveryResult
+	}
+	if c.IntervalSeconds == 0 {
+		c.IntervalSeconds = DefaultVisionInterval
+	}
+	if c.MaxFrames == 0 {
+		c.MaxFrames = DefaultVisionFrames
+	}
+	return c
+}
+
+// HasFeature reports whether the feature was requested
+func (c VisionConfig) HasFeature(feature string) bool {
+	for _, f := range c.Features {
+		if f == feature {
+			return true
+		}
+	}
+	return false
+}
+
+func (c VisionConfig) Validate() error {
+	if len(c.Features) == 0 {
+		return fmt.Errorf("vision requires at least one feature")
+	}
+	for _, feature := range c.Features {
+		if feature != VisionFeatureLabels && feature != VisionFeatureModeration {
+			return fmt.Errorf("unsupported vision feature: %s", feature)
+		}
+	}
+	switch c.Delivery {
+	case "", VisionDeliveryResult, VisionDeliveryArtifact:
+	default:
+		return fmt.Errorf("unsupported vision delivery: %s", c.Delivery)
+	}
+	if c.IntervalSeconds < 0 || c.IntervalSeconds > maxVisionInterval {
+		return fmt.Errorf("vision interval must be between 0 and %d seconds, got %v", maxVisionInterval, c.IntervalSeconds)
+	}
+	if c.MaxFrames < 0 || c.MaxFrames > MaxVisionFrames {
+		return fmt.Errorf("vision max_frames must be between 0 (the default of %d) and %d, got %d", DefaultVisionFrames, MaxVisionFrames, c.MaxFrames)
+	}
+	if c.MinConfidence < 0 || c.MinConfidence > 100 {
+		return fmt.Errorf("vision min_confidence must be between 0 and 100")
+	}
+	return nil
+}
+
+// VisionReport is the analysis of the frames sampled from the video
+type VisionReport struct {
+	// Flagged is set when moderation found unsafe content on any frame
+	Flagged bool          `json:"flagged"`
+	Frames  []VisionFrame `json:"frames"`
+}
+
+// VisionFrame holds the labels of the frame at Time seconds; a list is absent when its feature
+// was not requested or found nothing
+type VisionFrame struct {
+	Time       float64       `json:"time"`
+	Labels     []VisionLabel `json:"labels,omitempty"`
+	Moderation []VisionLabel `json:"moderation,omitempty"`
+}
+
+// VisionLabel is a label named by the vision API, with its confidence in percent
+type VisionLabel struct {
+	Name       string  `json:"name"`
+	Parent     string  `json:"parent,omitempty"`
+	Confidence float64 `json:"confidence"`
+}
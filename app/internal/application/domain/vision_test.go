@@ -0,0 +1,50 @@
+package domain
+
+import "testing"
+
+func TestVisionConfig_WithDefaults(t *testing.T) {
+	config := VisionConfig{Features: []string{VisionFeatureLabels}}.WithDefaults()
+	if config.Delivery != VisionDeliveryResult || config.IntervalSeconds != 10 || config.MaxFrames != 20 {
+		t.Errorf("Unexpected defaults %+v", config)
+	}
+
+	config = VisionConfig{Delivery: VisionDeliveryArtifact, IntervalSeconds: 2, MaxFrames: 5}.WithDefaults()
+	if config.Delivery != VisionDeliveryArtifact || config.IntervalSeconds != 2 || config.MaxFrames != 5 {
+		t.Errorf("Expected the set fields kept, got %+v", config)
+	}
+}
+
+func TestVisionConfig_Validate(t *testing.T) {
+	labels := []string{VisionFeatureLabels}
+	tests := []struct {
+		name    string
+		config  VisionConfig
+		wantErr bool
+	}{
+		{"labels", VisionConfig{Features: labels}, false},
+		{"both features as artifact", VisionConfig{Features: []string{VisionFeatureLabels, VisionFeatureModeration}, Delivery: VisionDeliveryArtifact}, false},
+		{"tuned", VisionConfig{Features: labels, IntervalSeconds: 0.5, MaxFrames: 100, MinConfidence: 80}, false},
+		{"no feature", VisionConfig{}, true},
+		{"unknown feature", VisionConfig{Features: []string{"faces"}}, true},
+		{"unknown delivery", VisionConfig{Features: labels, Delivery: "webhook"}, true},
+		{"negative interval", VisionConfig{Features: labels, IntervalSeconds: -1}, true},
+		{"default frames", VisionConfig{Features: labels, MaxFrames: 0}, false},
+		{"negative frames", VisionConfig{Features: labels, MaxFrames: -1}, true},
+		{"too many frames", VisionConfig{Features: labels, MaxFrames: 101}, true},
+		{"confidence above 100", VisionConfig{Features: labels, MinConfidence: 101}, true},
+	}
+
+	for _, tt := range tests {
+		err := tt.config.Validate()
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: expected error %v, got %v", tt.name, tt.wantErr, err)
+		}
+	}
+}
+
+func TestVisionConfig_HasFeature(t *testing.T) {
+	config := VisionConfig{Features: []string{VisionFeatureModeration}}
+	if !config.HasFeature(VisionFeatureModeration) || config.HasFeature(VisionFeatureLabels) {
+		t.Errorf("Unexpected features of %+v", config)
+	}
+}
@@ -0,0 +1,9 @@
+package domain
+
+// WorkerIdentity tells operators which replica and build handled a job
+type WorkerIdentity struct {
+	Hostname string `json:"hostname"`
+	Pod      string `json:"pod,omitempty"`
+	Version  string `json:"version"`
+	Commit   string `json:"commit"`
+}
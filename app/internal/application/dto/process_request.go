@@ -0,0 +1,163 @@
+package dto
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+)
+
+// ProcessRequest is the message read from the input queue
+type ProcessRequest struct {
+	ProcessID    string                    `json:"process_id"`
+	VideoBucket  string                    `json:"video_bucket"`
+	VideoKey     string                    `json:"video_key"`
+	VideoKeys    []string                  `json:"video_keys"`
+	BatchOutput  string                    `json:"batch_output"`
+	VideoURL     string                    `json:"video_url"`
+	VideoETag    string                    `json:"video_etag"`
+	VideoVersion string                    `json:"video_version_id"`
+	TenantID     string                    `json:"tenant_id"`
+	StorageClass string                    `json:"storage_class"`
+	OutputType   string                    `json:"output_type"`
+	Sprite       domain.SpriteOptions      `json:"sprite"`
+	Packaging    domain.PackagingOptions   `json:"packaging"`
+	Concat       domain.ConcatOptions      `json:"concat"`
+	Trim         domain.TrimOptions        `json:"trim"`
+	Loudness     domain.LoudnessOptions    `json:"loudness"`
+	QC           domain.QCOptions          `json:"qc"`
+	Fingerprint  domain.FingerprintOptions `json:"fingerprint"`
+	Subtitles    domain.SubtitleOptions    `json:"subtitles"`
+
+	DisableAutoRotate bool                    `json:"disable_auto_rotate"`
+	ToneMap           string                  `json:"tone_map"`
+	Windows           []domain.TimeWindow     `json:"windows"`
+	MaxFrames         int                     `json:"max_frames"`
+	Sharpness         domain.SharpnessOptions `json:"sharpness"`
+	Colors            domain.ColorOptions     `json:"colors"`
+	Barcodes          domain.BarcodeOptions   `json:"barcodes"`
+	OCR               domain.OCROptions       `json:"ocr"`
+	Anonymize         domain.AnonymizeOptions `json:"anonymize"`
+	Image             domain.ImageOptions     `json:"image"`
+	Archive           domain.ArchiveOptions   `json:"archive"`
+	FrameName         string                  `json:"frame_name"`
+	Accept            domain.AcceptOptions    `json:"accept"`
+	DryRun            bool                    `json:"dry_run"`
+	KeepOriginal      bool                    `json:"keep_original"`
+
+	// Transcription starts a transcription of the loudnorm or trim output once it is uploaded
+	Transcription domain.TranscriptionOptions `json:"transcription"`
+
+	// OutputBucket and OutputPrefix override where the outputs are written
+	OutputBucket string `json:"output_bucket,omitempty"`
+	OutputPrefix string `json:"output_prefix,omitempty"`
+
+	// ResultDestinations are "type:target" destinations delivered the result besides the
+	// output queue
+	ResultDestinations domain.ResultDestinations `json:"result_destinations,omitempty"`
+
+	// CreatedAt is when the producer submitted the job; it takes precedence over the queue's
+	// sent timestamp to measure the job age
+	CreatedAt time.Time `json:"created_at,omitempty"`
+
+	// Options groups the common settings; the ones set here take precedence over the
+	// top-level fields
+	Options *ProcessOptions `json:"options,omitempty"`
+
+	// Metadata belongs to the caller and comes back untouched in the result and logs
+	Metadata map[string]json.RawMessage `json:"metadata,omitempty"`
+}
+
+// ProcessOptions are the nested "options" of a request
+type ProcessOptions struct {
+	// FPS is the frames sampling rate; zero keeps one frame per second
+	FPS float64 `json:"fps,omitempty"`
+
+	// Format is the image format of the frames, as image.format
+	Format string `json:"format,omitempty"`
+
+	Archive *domain.ArchiveOptions `json:"archive,omitempty"`
+
+	// Outputs lists the output types; a job produces a single output for now
+	Outputs []string `json:"outputs,omitempty"`
+}
+
+// ParseProcessRequest decodes a message body
+func ParseProcessRequest(body []byte) (ProcessRequest, error) {
+	var request ProcessRequest
+	if err := json.Unmarshal(body, &request); err != nil {
+		return ProcessRequest{}, err
+	}
+	return request, nil
+}
+
+// ToDomain builds the job, applying the nested options over the top-level fields. The job is
+// returned even on error, with the process_id and metadata needed to report it
+func (r ProcessRequest) ToDomain(now time.Time) (domain.VideoProcess, error) {
+	process := domain.VideoProcess{
+		ProcessID:    r.ProcessID,
+		VideoBucket:  r.VideoBucket,
+		VideoKey:     r.VideoKey,
+		VideoKeys:    r.VideoKeys,
+		BatchOutput:  r.BatchOutput,
+		VideoURL:     r.VideoURL,
+		Source:       domain.ObjectCondition{ETag: r.VideoETag, VersionID: r.VideoVersion},
+		TenantID:     r.TenantID,
+		StorageClass: r.StorageClass,
+		OutputType:   r.OutputType,
+		Sprite:       r.Sprite,
+		Packaging:    r.Packaging,
+		Concat:       r.Concat,
+		Trim:         r.Trim,
+		Loudness:     r.Loudness,
+		QC:           r.QC,
+		Fingerprint:  r.Fingerprint,
+		Subtitles:    r.Subtitles,
+
+		DisableAutoRotate: r.DisableAutoRotate,
+		ToneMap:           r.ToneMap,
+		Windows:           r.Windows,
+		MaxFrames:         r.MaxFrames,
+		Sharpness:         r.Sharpness,
+		Colors:            r.Colors,
+		Barcodes:          r.Barcodes,
+		OCR:               r.OCR,
+		Anonymize:         r.Anonymize,
+		Image:             r.Image,
+		Archive:           r.Archive,
+		FrameName:         domain.FrameNameTemplate(r.FrameName),
+		Accept:            r.Accept,
+		DryRun:            r.DryRun,
+		KeepOriginal:      r.KeepOriginal,
+		Metadata:          r.Metadata,
+		CreatedAt:         now,
+
+		OutputBucket:       r.OutputBucket,
+		OutputPrefix:       r.OutputPrefix,
+		ResultDestinations: r.ResultDestinations,
+		EnqueuedAt:         r.CreatedAt,
+		Transcription:      r.Transcription,
+	}
+
+	options := r.Options
+	if options == nil {
+		return process, nil
+	}
+
+	process.FPS = options.FPS
+	if options.Format != "" {
+		process.Image.Format = options.Format
+	}
+	if options.Archive != nil {
+		process.Archive = *options.Archive
+	}
+	switch len(options.Outputs) {
+	case 0:
+	case 1:
+		process.OutputType = options.Outputs[0]
+	default:
+		return process, fmt.Errorf("options.outputs accepts a single output type, got %d", len(options.Outputs))
+	}
+	return process, nil
+}
@@ -0,0 +1,89 @@
+package dto
+
+import (
+	"testing"
+	"time"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+)
+
+func TestParseProcessRequest_Flat(t *testing.T) {
+	request, err := ParseProcessRequest([]byte(`{"process_id":"123","video_bucket":"b","video_key":"v.mp4","video_etag":"abc","video_version_id":"v2","output_type":"sprite","image":{"format":"jpeg"},"accept":{"encodings":["tar.gz","zip"]}}`))
+	if err != nil {
+		t.Fatalf("ParseProcessRequest failed: %v", err)
+	}
+
+	now := time.Now()
+	process, err := request.ToDomain(now)
+	if err != nil {
+		t.Fatalf("ToDomain failed: %v", err)
+	}
+	if process.ProcessID != "123" || process.VideoBucket != "b" || process.VideoKey != "v.mp4" || process.Source.ETag != "abc" || process.Source.VersionID != "v2" {
+		t.Errorf("Unexpected source %+v", process)
+	}
+	if process.OutputType != domain.OutputTypeSprite || process.Image.Format != domain.ImageFormatJPEG {
+		t.Errorf("Unexpected options %+v", process)
+	}
+	if len(process.Accept.Encodings) != 2 || process.Accept.Encodings[0] != domain.ArchiveEncodingTarGz {
+		t.Errorf("Unexpected accept options %+v", process.Accept)
+	}
+	if !process.CreatedAt.Equal(now) || !process.EnqueuedAt.IsZero() || process.FPS != 0 || process.Metadata != nil {
+		t.Errorf("Unexpected defaults %+v", process)
+	}
+}
+
+func TestParseProcessRequest_NestedOptions(t *testing.T) {
+	request, err := ParseProcessRequest([]byte(`{
+		"process_id": "123",
+		"output_type": "frames",
+		"image": {"format": "png", "quality": 70},
+		"options": {"fps": 2.5, "format": "webp", "archive": {"method": "deflate", "level": 9}, "outputs": ["sprite"]},
+		"metadata": {"order_id": 42, "tags": ["a", "b"]},
+		"created_at": "2024-01-01T12:00:00Z"
+	}`))
+	if err != nil {
+		t.Fatalf("ParseProcessRequest failed: %v", err)
+	}
+
+	process, err := request.ToDomain(time.Now())
+	if err != nil {
+		t.Fatalf("ToDomain failed: %v", err)
+	}
+	if process.FPS != 2.5 {
+		t.Errorf("Expected fps 2.5, got %v", process.FPS)
+	}
+	if process.Image.Format != domain.ImageFormatWebP || process.Image.Quality != 70 {
+		t.Errorf("Expected webp keeping quality 70, got %+v", process.Image)
+	}
+	if process.Archive.Method != domain.ZipMethodDeflate || process.Archive.Level != 9 {
+		t.Errorf("Unexpected archive %+v", process.Archive)
+	}
+	if process.OutputType != domain.OutputTypeSprite {
+		t.Errorf("Expected sprite output, got %s", process.OutputType)
+	}
+	if !process.EnqueuedAt.Equal(time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected created_at as the submission time, got %v", process.EnqueuedAt)
+	}
+	if string(process.Metadata["order_id"]) != "42" || string(process.Metadata["tags"]) != `["a", "b"]` {
+		t.Errorf("Expected metadata unchanged, got %s, %s", process.Metadata["order_id"], process.Metadata["tags"])
+	}
+}
+
+func TestProcessRequest_ToDomainErrors(t *testing.T) {
+	request, err := ParseProcessRequest([]byte(`{"process_id":"123","options":{"outputs":["frames","sprite"]},"metadata":{"k":"v"}}`))
+	if err != nil {
+		t.Fatalf("ParseProcessRequest failed: %v", err)
+	}
+
+	process, err := request.ToDomain(time.Now())
+	if err == nil {
+		t.Error("Expected error for several outputs")
+	}
+	if process.ProcessID != "123" || string(process.Metadata["k"]) != `"v"` {
+		t.Errorf("Expected process_id and metadata kept for the error result, got %+v", process)
+	}
+
+	if _, err := ParseProcessRequest([]byte(`{"metadata":["not","an","object"]}`)); err == nil {
+		t.Error("Expected error for metadata that is not an object")
+	}
+}
@@ -0,0 +1,147 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/port"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/observability"
+	"go.uber.org/zap"
+)
+
+// BackfillUseCase enqueues a processing job for every video under a bucket prefix, e.g. to
+// produce a newly added output type for videos processed before it existed
+type BackfillUseCase struct {
+	storage       port.StoragePort
+	message       port.MessagePort
+	inputQueueURL string
+}
+
+func NewBackfillUseCase(
+	storage port.StoragePort,
+	message port.MessagePort,
+	inputQueueURL string,
+) *BackfillUseCase {
+	return &BackfillUseCase{
+		storage:       storage,
+		message:       message,
+		inputQueueURL: inputQueueURL,
+	}
+}
+
+// Run lists bucket/prefix a page at a time and enqueues one job per video with up to
+// concurrency sends in flight, so a large prefix is never held in memory. Every job keeps
+// its source video, which the worker would otherwise delete, and gets a process_id derived
+// from the video and the options, so rerunning a backfill yields the same ids. A failed
+// send is reported and does not stop the others
+func (uc *BackfillUseCase) Run(ctx context.Context, bucket, prefix string, options map[string]interface{}, concurrency int) (domain.BackfillReport, error) {
+	startTime := time.Now()
+	logger := observability.FromContext(ctx).With(
+		zap.String("bucket", bucket),
+		zap.String("prefix", prefix),
+	)
+	report := domain.BackfillReport{Bucket: bucket, Prefix: prefix}
+
+	if err := domain.ValidateJobOptions(options); err != nil {
+		return report, err
+	}
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	jobs := make(chan string)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for key := range jobs {
+				err := uc.enqueue(ctx, bucket, key, options)
+
+				mu.Lock()
+				if err != nil {
+					report.Failed++
+					report.Failures = append(report.Failures, domain.BackfillFailure{Key: key, Error: err.Error()})
+					logger.Warn("failed to enqueue video", zap.String("video_key", key), zap.Error(err))
+				} else {
+					report.Enqueued++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+
+	// Listed and Skipped are only written here, the workers update the other counters
+	err := uc.storage.ListObjectPages(ctx, bucket, prefix, func(page []domain.ObjectSummary) error {
+		observability.RecordS3Operation(ctx, "list", true)
+		report.Listed += len(page)
+		for _, object := range page {
+			if !domain.IsVideoKey(object.Key) {
+				report.Skipped++
+				continue
+			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			jobs <- object.Key
+		}
+		return nil
+	})
+	close(jobs)
+	wg.Wait()
+	if err != nil && ctx.Err() == nil {
+		observability.RecordS3Operation(ctx, "list", false)
+		return report, fmt.Errorf("failed to list videos: %w", err)
+	}
+
+	report.DurationSeconds = time.Since(startTime).Seconds()
+	logger.Info("backfill completed",
+		zap.Int("listed", report.Listed),
+		zap.Int("enqueued", report.Enqueued),
+		zap.Int("failed", report.Failed),
+		zap.Float64("duration_seconds", report.DurationSeconds),
+	)
+	return report, ctx.Err()
+}
+
+func (uc *BackfillUseCase) enqueue(ctx context.Context, bucket, key string, options map[string]interface{}) error {
+	processID, err := backfillProcessID(bucket, key, options)
+	if err != nil {
+		return err
+	}
+
+	body, err := jobMessage(options, map[string]interface{}{
+		"process_id":    processID,
+		"video_bucket":  bucket,
+		"video_key":     key,
+		"keep_original": true,
+	})
+	if err != nil {
+		return err
+	}
+
+	if _, err := uc.message.SendMessage(ctx, uc.inputQueueURL, body); err != nil {
+		observability.RecordSQSOperation("send", false)
+		return err
+	}
+	observability.RecordSQSOperation("send", true)
+	return nil
+}
+
+// backfillProcessID hashes the video location and the options; json.Marshal sorts map keys,
+// so equal options always hash the same
+func backfillProcessID(bucket, key string, options map[string]interface{}) (string, error) {
+	encoded, err := json.Marshal(options)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode options: %w", err)
+	}
+	sum := sha256.Sum256([]byte(bucket + "/" + key + "\n" + string(encoded)))
+	return "backfill-" + hex.EncodeToString(sum[:16]), nil
+}
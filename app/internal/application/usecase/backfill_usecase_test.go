@@ -0,0 +1,144 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/observability"
+)
+
+func TestBackfill_EnqueuesVideos(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	storagePort := &mockStoragePort{
+		listObjectsFunc: func(ctx context.Context, bucket, prefix string) ([]string, error) {
+			if bucket != "archive" || prefix != "2025/" {
+				t.Errorf("Unexpected listing of s3://%s/%s", bucket, prefix)
+			}
+			return []string{"2025/a.mp4", "2025/b.MOV", "2025/notes.txt", "2025/c.fail.mkv"}, nil
+		},
+	}
+
+	var mu sync.Mutex
+	var messages []map[string]interface{}
+	messagePort := &mockMessagePort{
+		sendMessageFunc: func(ctx context.Context, queueURL string, messageBody string) (string, error) {
+			if strings.Contains(messageBody, "c.fail.mkv") {
+				return "", errors.New("throttled")
+			}
+			var message map[string]interface{}
+			if err := json.Unmarshal([]byte(messageBody), &message); err != nil {
+				t.Errorf("Invalid job message: %v", err)
+			}
+			mu.Lock()
+			messages = append(messages, message)
+			mu.Unlock()
+			return "msg-id", nil
+		},
+	}
+
+	options := map[string]interface{}{"output_type": "sprite"}
+	report, err := NewBackfillUseCase(storagePort, messagePort, "input-queue").Run(context.Background(), "archive", "2025/", options, 2)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if report.Listed != 4 || report.Skipped != 1 || report.Enqueued != 2 || report.Failed != 1 {
+		t.Errorf("Unexpected report %+v", report)
+	}
+	if len(report.Failures) != 1 || report.Failures[0].Key != "2025/c.fail.mkv" {
+		t.Errorf("Expected the failed video in the report, got %+v", report.Failures)
+	}
+
+	sort.Slice(messages, func(i, j int) bool { return messages[i]["video_key"].(string) < messages[j]["video_key"].(string) })
+	if len(messages) != 2 || messages[0]["video_key"] != "2025/a.mp4" {
+		t.Fatalf("Unexpected messages %v", messages)
+	}
+	for _, message := range messages {
+		if message["video_bucket"] != "archive" || message["output_type"] != "sprite" || message["keep_original"] != true {
+			t.Errorf("Unexpected job message %v", message)
+		}
+		if !strings.HasPrefix(message["process_id"].(string), "backfill-") {
+			t.Errorf("Expected a backfill process_id, got %v", message["process_id"])
+		}
+	}
+}
+
+func TestBackfill_Pages(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	listErr := errors.New("throttled")
+	storagePort := &mockStoragePort{
+		listObjectPagesFunc: func(ctx context.Context, bucket, prefix string, fn func(page []domain.ObjectSummary) error) error {
+			if err := fn([]domain.ObjectSummary{{Key: "2025/a.mp4"}, {Key: "2025/notes.txt"}}); err != nil {
+				return err
+			}
+			if err := fn([]domain.ObjectSummary{{Key: "2025/b.mp4"}}); err != nil {
+				return err
+			}
+			return listErr
+		},
+	}
+	var mu sync.Mutex
+	var sent int
+	messagePort := &mockMessagePort{
+		sendMessageFunc: func(ctx context.Context, queueURL string, messageBody string) (string, error) {
+			mu.Lock()
+			sent++
+			mu.Unlock()
+			return "msg-id", nil
+		},
+	}
+
+	// The videos of the pages listed before the failure are still enqueued
+	report, err := NewBackfillUseCase(storagePort, messagePort, "input-queue").Run(context.Background(), "archive", "2025/", nil, 2)
+	if !errors.Is(err, listErr) {
+		t.Errorf("Expected the listing error, got %v", err)
+	}
+	if report.Listed != 3 || report.Skipped != 1 || report.Enqueued != 2 || sent != 2 {
+		t.Errorf("Unexpected report %+v with %d messages", report, sent)
+	}
+}
+
+func TestBackfill_StableProcessIDs(t *testing.T) {
+	first, _ := backfillProcessID("archive", "a.mp4", map[string]interface{}{"output_type": "sprite", "max_frames": 10})
+	again, _ := backfillProcessID("archive", "a.mp4", map[string]interface{}{"max_frames": 10, "output_type": "sprite"})
+	other, _ := backfillProcessID("archive", "a.mp4", map[string]interface{}{"output_type": "hls"})
+
+	if first != again {
+		t.Errorf("Expected the same id for the same video and options, got %s and %s", first, again)
+	}
+	if first == other {
+		t.Error("Expected different ids for different options")
+	}
+}
+
+func TestBackfill_Errors(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	useCase := NewBackfillUseCase(&mockStoragePort{
+		listObjectsFunc: func(ctx context.Context, bucket, prefix string) ([]string, error) {
+			return nil, errors.New("access denied")
+		},
+	}, &mockMessagePort{}, "input-queue")
+
+	if _, err := useCase.Run(context.Background(), "archive", "", nil, 1); err == nil {
+		t.Error("Expected error when listing fails")
+	}
+	_, err := useCase.Run(context.Background(), "archive", "", map[string]interface{}{"video_key": "x"}, 1)
+	if !errors.Is(err, domain.ErrInvalidJob) {
+		t.Errorf("Expected ErrInvalidJob for reserved options, got %v", err)
+	}
+}
@@ -0,0 +1,55 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/port"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/observability"
+	"go.uber.org/zap"
+)
+
+// CancelJobUseCase records the cancellation of a job that has not started yet. The worker that
+// later receives the job answers it with a cancelled error instead of processing it (see
+// ProcessVideoUseCase.WithCancellations); a job already being processed runs to the end
+type CancelJobUseCase struct {
+	storage      port.StoragePort
+	markerBucket string
+}
+
+func NewCancelJobUseCase(
+	storage port.StoragePort,
+	markerBucket string,
+) *CancelJobUseCase {
+	return &CancelJobUseCase{
+		storage:      storage,
+		markerBucket: markerBucket,
+	}
+}
+
+func (uc *CancelJobUseCase) Execute(ctx context.Context, cancellation domain.JobCancellation) error {
+	if err := cancellation.Validate(); err != nil {
+		observability.RecordError("validation")
+		return fmt.Errorf("invalid job cancellation: %w", err)
+	}
+
+	cancellation.CreatedAt = time.Now().UTC()
+	marker, err := json.Marshal(cancellation)
+	if err != nil {
+		return fmt.Errorf("failed to encode job cancellation: %w", err)
+	}
+
+	key := domain.CancellationKey(cancellation.ProcessID)
+	if _, err := uc.storage.PutObject(ctx, uc.markerBucket, key, bytes.NewReader(marker)); err != nil {
+		observability.RecordS3Operation(ctx, "put", false)
+		return fmt.Errorf("failed to put job cancellation: %w", err)
+	}
+	observability.RecordS3Operation(ctx, "put", true)
+
+	observability.FromContext(ctx).Info("job cancellation recorded", zap.String("process_id", cancellation.ProcessID))
+	return nil
+}
@@ -0,0 +1,54 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/observability"
+)
+
+func TestCancelJob_RecordsMarker(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	var putBucket, putKey string
+	var marker domain.JobCancellation
+	storagePort := &mockStoragePort{
+		putObjectFunc: func(ctx context.Context, bucket, key string, body io.Reader, options domain.PutOptions) (string, error) {
+			putBucket, putKey = bucket, key
+			return key, json.NewDecoder(body).Decode(&marker)
+		},
+	}
+
+	err := NewCancelJobUseCase(storagePort, "output-bucket").Execute(context.Background(), domain.JobCancellation{ProcessID: "process-123"})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if putBucket != "output-bucket" || putKey != "cancellations/process-123.json" {
+		t.Errorf("Unexpected marker location %s/%s", putBucket, putKey)
+	}
+	if marker.ProcessID != "process-123" || marker.CreatedAt.IsZero() {
+		t.Errorf("Unexpected marker %+v", marker)
+	}
+}
+
+func TestCancelJob_Errors(t *testing.T) {
+	useCase := NewCancelJobUseCase(&mockStoragePort{}, "output-bucket")
+	if err := useCase.Execute(context.Background(), domain.JobCancellation{}); err == nil {
+		t.Error("Expected error without process_id")
+	}
+
+	failing := NewCancelJobUseCase(&mockStoragePort{
+		putObjectFunc: func(ctx context.Context, bucket, key string, body io.Reader, options domain.PutOptions) (string, error) {
+			return "", errors.New("access denied")
+		},
+	}, "output-bucket")
+	if err := failing.Execute(context.Background(), domain.JobCancellation{ProcessID: "process-123"}); err == nil {
+		t.Error("Expected error when the marker cannot be written")
+	}
+}
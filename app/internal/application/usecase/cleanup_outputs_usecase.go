@@ -0,0 +1,188 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/port"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/observability"
+	"go.uber.org/zap"
+)
+
+// CleanupOutputsUseCase deletes old outputs under a bucket prefix. Lifecycle rules apply to a
+// whole bucket, so this is how a tenant's or a prefix's outputs get their own retention
+type CleanupOutputsUseCase struct {
+	storage port.StoragePort
+}
+
+func NewCleanupOutputsUseCase(storage port.StoragePort) *CleanupOutputsUseCase {
+	return &CleanupOutputsUseCase{
+		storage: storage,
+	}
+}
+
+// Run lists location a page at a time and deletes, in one batched request per page, the objects
+// last modified before the cutoff. The prefix is required, so that a cleanup never reaches the
+// job markers and timelines at the root of the worker bucket. A dry run only counts what would
+// be deleted. Failed deletes are reported and do not stop the others; a failed listing does
+func (uc *CleanupOutputsUseCase) Run(ctx context.Context, location domain.OutputLocation, before time.Time, dryRun bool) (domain.CleanupReport, error) {
+	startTime := time.Now()
+	logger := observability.FromContext(ctx).With(
+		zap.String("bucket", location.Bucket),
+		zap.String("prefix", location.Prefix),
+	)
+	report := domain.CleanupReport{Bucket: location.Bucket, Prefix: location.Prefix, Before: before, DryRun: dryRun}
+
+	if location.Prefix == "" {
+		return report, errors.New("a prefix is required to clean up outputs")
+	}
+	if err := domain.ValidateOutputPrefix(location.Prefix); err != nil {
+		return report, err
+	}
+
+	err := uc.storage.ListObjectPages(ctx, location.Bucket, location.Prefix, func(page []domain.ObjectSummary) error {
+		observability.RecordS3Operation(ctx, "list", true)
+		report.Listed += len(page)
+
+		var keys []string
+		sizes := map[string]int64{}
+		for _, object := range page {
+			if object.LastModified.Before(before) {
+				keys = append(keys, object.Key)
+				sizes[object.Key] = object.SizeBytes
+			}
+		}
+		report.Expired += len(keys)
+		if dryRun || len(keys) == 0 {
+			return ctx.Err()
+		}
+
+		failed, err := uc.storage.DeleteObjects(ctx, location.Bucket, keys)
+		for _, key := range keys {
+			keyErr := failed[key]
+			if keyErr == nil && err != nil {
+				keyErr = err
+			}
+			if keyErr != nil {
+				report.Failed++
+				report.Failures = append(report.Failures, domain.CleanupFailure{Key: key, Error: keyErr.Error()})
+				continue
+			}
+			report.Deleted++
+			report.DeletedBytes += sizes[key]
+		}
+		observability.RecordS3Operation(ctx, "delete", err == nil && len(failed) == 0)
+		if err != nil {
+			logger.Warn("failed to delete outputs", zap.Int("objects", len(keys)), zap.Error(err))
+		}
+		return ctx.Err()
+	})
+	if err != nil && ctx.Err() == nil {
+		observability.RecordS3Operation(ctx, "list", false)
+		err = fmt.Errorf("failed to list outputs: %w", err)
+	}
+
+	report.DurationSeconds = time.Since(startTime).Seconds()
+	logger.Info("cleanup completed",
+		zap.Bool("dry_run", dryRun),
+		zap.Int("listed", report.Listed),
+		zap.Int("expired", report.Expired),
+		zap.Int("deleted", report.Deleted),
+		zap.Int("failed", report.Failed),
+		zap.Float64("duration_seconds", report.DurationSeconds),
+	)
+	return report, err
+}
+
+// RunExpired deletes the outputs whose expiration, scheduled by the workers for tenants with a
+// retention, is past at now. The markers are read from the worker bucket, and each is removed
+// once all of its outputs are deleted, so failed ones are retried by the next run. A dry run
+// only counts what would be deleted
+func (uc *CleanupOutputsUseCase) RunExpired(ctx context.Context, markerBucket string, now time.Time, dryRun bool) (domain.CleanupReport, error) {
+	startTime := time.Now()
+	prefix := domain.ExpirationsPrefix()
+	logger := observability.FromContext(ctx).With(zap.String("bucket", markerBucket))
+	report := domain.CleanupReport{Bucket: markerBucket, Prefix: prefix, Before: now, DryRun: dryRun}
+
+	err := uc.storage.ListObjectPages(ctx, markerBucket, prefix, func(page []domain.ObjectSummary) error {
+		observability.RecordS3Operation(ctx, "list", true)
+		for _, object := range page {
+			expiresAt, ok := domain.ExpirationDay(object.Key)
+			if !ok || expiresAt.After(now) {
+				continue
+			}
+			report.Listed++
+			if err := uc.expire(ctx, markerBucket, object.Key, dryRun, &report); err != nil {
+				logger.Warn("failed to expire outputs", zap.String("marker", object.Key), zap.Error(err))
+				report.Failed++
+				report.Failures = append(report.Failures, domain.CleanupFailure{Key: object.Key, Error: err.Error()})
+			}
+		}
+		return ctx.Err()
+	})
+	if err != nil && ctx.Err() == nil {
+		observability.RecordS3Operation(ctx, "list", false)
+		err = fmt.Errorf("failed to list output expirations: %w", err)
+	}
+
+	report.DurationSeconds = time.Since(startTime).Seconds()
+	logger.Info("expired outputs cleanup completed",
+		zap.Bool("dry_run", dryRun),
+		zap.Int("markers", report.Listed),
+		zap.Int("expired", report.Expired),
+		zap.Int("deleted", report.Deleted),
+		zap.Int("failed", report.Failed),
+		zap.Float64("duration_seconds", report.DurationSeconds),
+	)
+	return report, err
+}
+
+// expire deletes the outputs of one expiration marker, then the marker
+func (uc *CleanupOutputsUseCase) expire(ctx context.Context, markerBucket, markerKey string, dryRun bool, report *domain.CleanupReport) error {
+	body, err := uc.storage.GetObject(ctx, markerBucket, markerKey)
+	if err != nil {
+		observability.RecordS3Operation(ctx, "get", false)
+		return fmt.Errorf("failed to get output expiration: %w", err)
+	}
+	var expiration domain.OutputExpiration
+	err = json.NewDecoder(body).Decode(&expiration)
+	body.Close()
+	observability.RecordS3Operation(ctx, "get", true)
+	if err != nil {
+		return fmt.Errorf("invalid output expiration: %w", err)
+	}
+
+	report.Expired += len(expiration.Keys)
+	if dryRun {
+		return nil
+	}
+
+	if len(expiration.Keys) > 0 {
+		// The outputs are deleted as the job wrote them, with the output role of its tenant
+		failed, err := uc.storage.DeleteObjects(observability.WithTenant(ctx, expiration.TenantID), expiration.Bucket, expiration.Keys)
+		observability.RecordS3Operation(ctx, "delete", err == nil && len(failed) == 0)
+		if err != nil {
+			return fmt.Errorf("failed to delete expired outputs: %w", err)
+		}
+		for _, key := range expiration.Keys {
+			if keyErr := failed[key]; keyErr != nil {
+				report.Failures = append(report.Failures, domain.CleanupFailure{Key: key, Error: keyErr.Error()})
+			}
+		}
+		report.Deleted += len(expiration.Keys) - len(failed)
+		if len(failed) > 0 {
+			return fmt.Errorf("%d of %d expired outputs not deleted", len(failed), len(expiration.Keys))
+		}
+	}
+
+	if err := uc.storage.DeleteObject(ctx, markerBucket, markerKey); err != nil {
+		observability.RecordS3Operation(ctx, "delete", false)
+		return fmt.Errorf("failed to delete output expiration: %w", err)
+	}
+	observability.RecordS3Operation(ctx, "delete", true)
+	return nil
+}
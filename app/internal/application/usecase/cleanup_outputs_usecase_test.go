@@ -0,0 +1,186 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/observability"
+)
+
+func cleanupStorage(now time.Time, deleted *[]string) *mockStoragePort {
+	old, recent := now.AddDate(0, 0, -40), now.AddDate(0, 0, -1)
+	return &mockStoragePort{
+		listObjectPagesFunc: func(ctx context.Context, bucket, prefix string, fn func(page []domain.ObjectSummary) error) error {
+			if err := fn([]domain.ObjectSummary{
+				{Key: prefix + "frames_1.zip", SizeBytes: 100, LastModified: old},
+				{Key: prefix + "frames_2.zip", SizeBytes: 200, LastModified: recent},
+			}); err != nil {
+				return err
+			}
+			return fn([]domain.ObjectSummary{
+				{Key: prefix + "frames_3.zip", SizeBytes: 300, LastModified: old},
+				{Key: prefix + "frames_4.zip", SizeBytes: 400, LastModified: old},
+			})
+		},
+		deleteObjectsFunc: func(ctx context.Context, bucket string, keys []string) (map[string]error, error) {
+			*deleted = append(*deleted, strings.Join(keys, ","))
+			failed := map[string]error{}
+			for _, key := range keys {
+				if strings.HasSuffix(key, "frames_4.zip") {
+					failed[key] = errors.New("AccessDenied: denied")
+				}
+			}
+			return failed, nil
+		},
+	}
+}
+
+func TestCleanupOutputs_DeletesOldOutputs(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	now := time.Now()
+	var deleted []string
+	useCase := NewCleanupOutputsUseCase(cleanupStorage(now, &deleted))
+	location := domain.OutputLocation{Bucket: "acme-outputs", Prefix: "frames/"}
+	report, err := useCase.Run(context.Background(), location, now.AddDate(0, 0, -30), false)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	// One batched delete per page, with only the expired objects
+	want := []string{"frames/frames_1.zip", "frames/frames_3.zip,frames/frames_4.zip"}
+	if strings.Join(deleted, " ") != strings.Join(want, " ") {
+		t.Errorf("Expected deletes %v, got %v", want, deleted)
+	}
+	if report.Listed != 4 || report.Expired != 3 || report.Deleted != 2 || report.DeletedBytes != 400 || report.Failed != 1 {
+		t.Errorf("Unexpected report %+v", report)
+	}
+	if len(report.Failures) != 1 || report.Failures[0].Key != "frames/frames_4.zip" {
+		t.Errorf("Expected the failed output in the report, got %+v", report.Failures)
+	}
+}
+
+func TestCleanupOutputs_DryRun(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	now := time.Now()
+	var deleted []string
+	useCase := NewCleanupOutputsUseCase(cleanupStorage(now, &deleted))
+	report, err := useCase.Run(context.Background(), domain.OutputLocation{Bucket: "output", Prefix: "processed/"}, now.AddDate(0, 0, -30), true)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if len(deleted) != 0 {
+		t.Errorf("Expected nothing deleted in a dry run, got %v", deleted)
+	}
+	if !report.DryRun || report.Expired != 3 || report.Deleted != 0 {
+		t.Errorf("Unexpected report %+v", report)
+	}
+}
+
+func TestCleanupOutputs_Errors(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	var deleted []string
+	storagePort := cleanupStorage(time.Now(), &deleted)
+	useCase := NewCleanupOutputsUseCase(storagePort)
+
+	// Without a prefix the cleanup would reach the markers at the root of the bucket
+	for _, prefix := range []string{"", "../processed/"} {
+		if _, err := useCase.Run(context.Background(), domain.OutputLocation{Bucket: "output", Prefix: prefix}, time.Now(), false); err == nil {
+			t.Errorf("Expected prefix %q rejected", prefix)
+		}
+	}
+
+	// A failed batch fails each of its keys, and the next pages are still cleaned up
+	storagePort.deleteObjectsFunc = func(ctx context.Context, bucket string, keys []string) (map[string]error, error) {
+		return nil, errors.New("throttled")
+	}
+	report, err := useCase.Run(context.Background(), domain.OutputLocation{Bucket: "output", Prefix: "processed/"}, time.Now(), false)
+	if err != nil || report.Failed != 4 || report.Deleted != 0 {
+		t.Errorf("Expected every expired output failed, got %+v (%v)", report, err)
+	}
+
+	storagePort.listObjectPagesFunc = func(ctx context.Context, bucket, prefix string, fn func(page []domain.ObjectSummary) error) error {
+		return errors.New("access denied")
+	}
+	if _, err := useCase.Run(context.Background(), domain.OutputLocation{Bucket: "output", Prefix: "processed/"}, time.Now(), false); err == nil {
+		t.Error("Expected a failed listing to fail the cleanup")
+	}
+}
+
+func TestCleanupOutputs_RunExpired(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	store, storagePort := newMemoryStorage()
+	store.objects["output/expirations/2024-01-08/process-1.json"] = []byte(`{"process_id":"process-1","bucket":"acme-outputs","keys":["frames/frames_1.zip","frames/frames_4.zip"]}`)
+	store.objects["output/expirations/2024-01-09/process-2.json"] = []byte(`{"process_id":"process-2","bucket":"acme-outputs","keys":["frames/frames_2.zip"]}`)
+	store.objects["output/expirations/2024-01-10/process-3.json"] = []byte(`{"process_id":"process-3","bucket":"acme-outputs","keys":["frames/frames_3.zip"]}`)
+	storagePort.listObjectPagesFunc = func(ctx context.Context, bucket, prefix string, fn func(page []domain.ObjectSummary) error) error {
+		keys, _ := storagePort.listObjectsFunc(ctx, bucket, prefix)
+		var page []domain.ObjectSummary
+		for _, key := range keys {
+			page = append(page, domain.ObjectSummary{Key: key})
+		}
+		return fn(page)
+	}
+	var deleted []string
+	storagePort.deleteObjectsFunc = func(ctx context.Context, bucket string, keys []string) (map[string]error, error) {
+		deleted = append(deleted, bucket+":"+strings.Join(keys, ","))
+		if slices.Contains(keys, "frames/frames_4.zip") {
+			return map[string]error{"frames/frames_4.zip": errors.New("AccessDenied: denied")}, nil
+		}
+		return nil, nil
+	}
+	storagePort.deleteObjectFunc = func(ctx context.Context, bucket, key string) error {
+		delete(store.objects, bucket+"/"+key)
+		return nil
+	}
+	useCase := NewCleanupOutputsUseCase(storagePort)
+	now := time.Date(2024, 1, 9, 0, 0, 0, 0, time.UTC)
+
+	report, err := useCase.RunExpired(context.Background(), "output", now, true)
+	if err != nil {
+		t.Fatalf("RunExpired failed: %v", err)
+	}
+	if len(deleted) != 0 || report.Listed != 2 || report.Expired != 3 || report.Deleted != 0 {
+		t.Errorf("Unexpected dry run %+v, deleted %v", report, deleted)
+	}
+
+	report, err = useCase.RunExpired(context.Background(), "output", now, false)
+	if err != nil {
+		t.Fatalf("RunExpired failed: %v", err)
+	}
+
+	// Markers not yet due are left alone, and a marker is kept until all of its outputs are gone
+	want := []string{"acme-outputs:frames/frames_1.zip,frames/frames_4.zip", "acme-outputs:frames/frames_2.zip"}
+	if strings.Join(deleted, " ") != strings.Join(want, " ") {
+		t.Errorf("Expected deletes %v, got %v", want, deleted)
+	}
+	if report.Expired != 3 || report.Deleted != 2 || report.Failed != 1 {
+		t.Errorf("Unexpected report %+v", report)
+	}
+	if _, ok := store.objects["output/expirations/2024-01-08/process-1.json"]; !ok {
+		t.Error("Expected the marker with a failed output kept")
+	}
+	if _, ok := store.objects["output/expirations/2024-01-09/process-2.json"]; ok {
+		t.Error("Expected the expired marker deleted")
+	}
+	if _, ok := store.objects["output/expirations/2024-01-10/process-3.json"]; !ok {
+		t.Error("Expected the future marker kept")
+	}
+}
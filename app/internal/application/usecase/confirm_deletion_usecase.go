@@ -0,0 +1,95 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/port"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/observability"
+	"go.uber.org/zap"
+)
+
+// ConfirmDeletionUseCase completes the two-phase deletion: the original video of a process is
+// only deleted when the consumer confirms it has validated the output
+type ConfirmDeletionUseCase struct {
+	storage      port.StoragePort
+	markerBucket string
+}
+
+func NewConfirmDeletionUseCase(
+	storage port.StoragePort,
+	markerBucket string,
+) *ConfirmDeletionUseCase {
+	return &ConfirmDeletionUseCase{
+		storage:      storage,
+		markerBucket: markerBucket,
+	}
+}
+
+// Execute deletes the original video on a confirmation, or keeps it on a rejection, and then
+// removes the marker. A confirmation without a marker (unknown or already handled process)
+// is ignored, so redelivered confirmations are harmless
+func (uc *ConfirmDeletionUseCase) Execute(ctx context.Context, confirmation domain.DeletionConfirmation) error {
+	logger := observability.FromContext(ctx).With(
+		zap.String("process_id", confirmation.ProcessID),
+		zap.String("status", confirmation.Status),
+	)
+
+	if err := confirmation.Validate(); err != nil {
+		observability.RecordError("validation")
+		return fmt.Errorf("invalid deletion confirmation: %w", err)
+	}
+
+	markerKey := domain.PendingDeletionKey(confirmation.ProcessID)
+	pending, err := uc.readMarker(ctx, markerKey)
+	if errors.Is(err, domain.ErrObjectNotFound) {
+		logger.Warn("no pending deletion for process, confirmation ignored")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if confirmation.Status == domain.DeletionConfirmed {
+		if err := uc.storage.DeleteObjectVersion(ctx, pending.VideoBucket, pending.VideoKey, pending.VideoVersionID); err != nil {
+			observability.RecordS3Operation(ctx, "delete", false)
+			return fmt.Errorf("failed to delete original video: %w", err)
+		}
+		observability.RecordS3Operation(ctx, "delete", true)
+		logger.Info("original video deleted after confirmation",
+			zap.String("video_bucket", pending.VideoBucket),
+			zap.String("video_key", pending.VideoKey),
+		)
+	} else {
+		logger.Info("result rejected, original video kept",
+			zap.String("video_bucket", pending.VideoBucket),
+			zap.String("video_key", pending.VideoKey),
+		)
+	}
+
+	if err := uc.storage.DeleteObject(ctx, uc.markerBucket, markerKey); err != nil {
+		observability.RecordS3Operation(ctx, "delete", false)
+		return fmt.Errorf("failed to delete pending deletion: %w", err)
+	}
+	observability.RecordS3Operation(ctx, "delete", true)
+	return nil
+}
+
+func (uc *ConfirmDeletionUseCase) readMarker(ctx context.Context, markerKey string) (domain.PendingDeletion, error) {
+	body, err := uc.storage.GetObject(ctx, uc.markerBucket, markerKey)
+	if err != nil {
+		observability.RecordS3Operation(ctx, "get", false)
+		return domain.PendingDeletion{}, fmt.Errorf("failed to get pending deletion: %w", err)
+	}
+	defer body.Close()
+	observability.RecordS3Operation(ctx, "get", true)
+
+	var pending domain.PendingDeletion
+	if err := json.NewDecoder(body).Decode(&pending); err != nil {
+		return domain.PendingDeletion{}, fmt.Errorf("invalid pending deletion %s: %w", markerKey, err)
+	}
+	return pending, nil
+}
@@ -0,0 +1,127 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/observability"
+)
+
+func pendingDeletionStorage(deleted *[]string) *mockStoragePort {
+	return &mockStoragePort{
+		getObjectFunc: func(ctx context.Context, bucket, key string, options domain.GetOptions) (io.ReadCloser, error) {
+			if bucket != "output-bucket" || key != "pending-deletions/process-123.json" {
+				return nil, fmt.Errorf("%w: %s", domain.ErrObjectNotFound, key)
+			}
+			return io.NopCloser(strings.NewReader(`{"process_id":"process-123","video_bucket":"input-bucket","video_key":"video.mp4"}`)), nil
+		},
+		deleteObjectFunc: func(ctx context.Context, bucket, key string) error {
+			*deleted = append(*deleted, bucket+"/"+key)
+			return nil
+		},
+	}
+}
+
+func TestConfirmDeletion_Confirmed(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	var deleted []string
+	useCase := NewConfirmDeletionUseCase(pendingDeletionStorage(&deleted), "output-bucket")
+	err := useCase.Execute(context.Background(), domain.DeletionConfirmation{ProcessID: "process-123", Status: domain.DeletionConfirmed})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	want := "input-bucket/video.mp4,output-bucket/pending-deletions/process-123.json"
+	if strings.Join(deleted, ",") != want {
+		t.Errorf("Expected %s, got %v", want, deleted)
+	}
+}
+
+func TestConfirmDeletion_Version(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	var deleted []string
+	storagePort := pendingDeletionStorage(&deleted)
+	storagePort.getObjectFunc = func(ctx context.Context, bucket, key string, options domain.GetOptions) (io.ReadCloser, error) {
+		return io.NopCloser(strings.NewReader(`{"process_id":"process-123","video_bucket":"input-bucket","video_key":"video.mp4","video_version_id":"v2"}`)), nil
+	}
+	storagePort.deleteObjectVersionFunc = func(ctx context.Context, bucket, key, versionID string) error {
+		deleted = append(deleted, bucket+"/"+key+"@"+versionID)
+		return nil
+	}
+
+	useCase := NewConfirmDeletionUseCase(storagePort, "output-bucket")
+	err := useCase.Execute(context.Background(), domain.DeletionConfirmation{ProcessID: "process-123", Status: domain.DeletionConfirmed})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	want := "input-bucket/video.mp4@v2,output-bucket/pending-deletions/process-123.json"
+	if strings.Join(deleted, ",") != want {
+		t.Errorf("Expected %s, got %v", want, deleted)
+	}
+}
+
+func TestConfirmDeletion_Rejected(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	var deleted []string
+	useCase := NewConfirmDeletionUseCase(pendingDeletionStorage(&deleted), "output-bucket")
+	err := useCase.Execute(context.Background(), domain.DeletionConfirmation{ProcessID: "process-123", Status: domain.DeletionRejected})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	if len(deleted) != 1 || deleted[0] != "output-bucket/pending-deletions/process-123.json" {
+		t.Errorf("Expected only the marker deleted, got %v", deleted)
+	}
+}
+
+func TestConfirmDeletion_UnknownProcessIgnored(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	var deleted []string
+	useCase := NewConfirmDeletionUseCase(pendingDeletionStorage(&deleted), "output-bucket")
+	err := useCase.Execute(context.Background(), domain.DeletionConfirmation{ProcessID: "process-999", Status: domain.DeletionConfirmed})
+	if err != nil {
+		t.Fatalf("Expected a confirmation without marker ignored, got %v", err)
+	}
+	if len(deleted) != 0 {
+		t.Errorf("Expected nothing deleted, got %v", deleted)
+	}
+}
+
+func TestConfirmDeletion_Errors(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	var deleted []string
+	storagePort := pendingDeletionStorage(&deleted)
+	useCase := NewConfirmDeletionUseCase(storagePort, "output-bucket")
+
+	if err := useCase.Execute(context.Background(), domain.DeletionConfirmation{ProcessID: "process-123", Status: "ok"}); err == nil {
+		t.Error("Expected error for an invalid status")
+	}
+
+	storagePort.deleteObjectFunc = func(ctx context.Context, bucket, key string) error {
+		return errors.New("access denied")
+	}
+	if err := useCase.Execute(context.Background(), domain.DeletionConfirmation{ProcessID: "process-123", Status: domain.DeletionConfirmed}); err == nil {
+		t.Error("Expected error when the original video cannot be deleted")
+	}
+}
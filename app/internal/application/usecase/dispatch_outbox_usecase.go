@@ -0,0 +1,150 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/port"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/observability"
+	"go.uber.org/zap"
+)
+
+// DispatchOutboxUseCase retries the result messages left in the outbox, including those
+// persisted before the worker crashed or restarted
+type DispatchOutboxUseCase struct {
+	outbox         port.OutboxPort
+	storage        port.StoragePort
+	message        port.MessagePort
+	outputQueueURL string
+}
+
+func NewDispatchOutboxUseCase(
+	outbox port.OutboxPort,
+	storage port.StoragePort,
+	message port.MessagePort,
+	outputQueueURL string,
+) *DispatchOutboxUseCase {
+	return &DispatchOutboxUseCase{
+		outbox:         outbox,
+		storage:        storage,
+		message:        message,
+		outputQueueURL: outputQueueURL,
+	}
+}
+
+// Run dispatches the outbox right away and then every interval, until ctx is done
+func (uc *DispatchOutboxUseCase) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := uc.Dispatch(ctx); err != nil {
+			observability.FromContext(ctx).Warn("outbox dispatch failed", zap.Error(err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Dispatch sends every entry that is due, deleting the delivered ones and rescheduling the
+// others with a growing delay. Entries are never dropped; it returns how many were delivered
+func (uc *DispatchOutboxUseCase) Dispatch(ctx context.Context) (int, error) {
+	entries, err := uc.outbox.Pending(ctx)
+	if err != nil {
+		observability.RecordError("outbox")
+		return 0, fmt.Errorf("failed to read outbox: %w", err)
+	}
+
+	delivered := 0
+	for _, entry := range entries {
+		if ctx.Err() != nil {
+			break
+		}
+		if !entry.Due(time.Now()) {
+			continue
+		}
+		if uc.deliver(ctx, entry) {
+			delivered++
+		}
+	}
+
+	observability.SetOutboxPending(len(entries) - delivered)
+	return delivered, nil
+}
+
+func (uc *DispatchOutboxUseCase) deliver(ctx context.Context, entry domain.OutboxEntry) bool {
+	logger := observability.FromContext(ctx).With(
+		zap.String("process_id", entry.ProcessID),
+		zap.String("outbox_id", entry.ID),
+	)
+
+	messageID, err := deliverResult(ctx, uc.storage, uc.message, uc.outputQueueURL, entry)
+	if err != nil {
+		entry.Failed(err, time.Now())
+		if err := uc.outbox.Save(ctx, entry); err != nil {
+			observability.RecordError("outbox")
+			logger.Warn("failed to record result message attempt", zap.Error(err))
+		}
+		logger.Warn("result message retry failed",
+			zap.Int("attempts", entry.Attempts),
+			zap.Time("next_attempt_at", entry.NextAttemptAt),
+			zap.Error(err),
+		)
+		return false
+	}
+
+	if err := uc.outbox.Delete(ctx, entry.ID); err != nil {
+		observability.RecordError("outbox")
+		logger.Warn("failed to remove sent result message from the outbox, it will be sent again", zap.Error(err))
+	}
+	logger.Info("result message delivered from the outbox",
+		zap.String("message_id", messageID),
+		zap.Int("previous_attempts", entry.Attempts),
+	)
+	return true
+}
+
+// deliverResult sends a prepared result message and, once it is accepted, deletes the source
+// video the entry carries. A failed delete is only logged, as the result is already out
+func deliverResult(ctx context.Context, storage port.StoragePort, message port.MessagePort, queueURL string, entry domain.OutboxEntry) (string, error) {
+	messageID, err := sendResult(ctx, message, queueURL, entry)
+	if err != nil || entry.DeleteAfterSend == nil {
+		return messageID, err
+	}
+
+	source := entry.DeleteAfterSend
+	logger := observability.FromContext(ctx).With(
+		zap.String("process_id", entry.ProcessID),
+		zap.String("video_bucket", source.Bucket),
+		zap.String("video_key", source.Key),
+	)
+	if err := storage.DeleteObjectVersion(ctx, source.Bucket, source.Key, source.VersionID); err != nil {
+		observability.RecordS3Operation(ctx, "delete", false)
+		logger.Warn("failed to delete original video", zap.Error(err))
+	} else {
+		observability.RecordS3Operation(ctx, "delete", true)
+		logger.Info("original video deleted successfully")
+	}
+	return messageID, nil
+}
+
+// sendResult sends a prepared result message to queueURL
+func sendResult(ctx context.Context, message port.MessagePort, queueURL string, entry domain.OutboxEntry) (string, error) {
+	messageID, err := sendEntry(ctx, message, queueURL, entry)
+	observability.RecordSQSOperation("send", err == nil)
+	return messageID, err
+}
+
+// sendEntry sends the body of entry to target, with its attributes when it has any
+func sendEntry(ctx context.Context, message port.MessagePort, target string, entry domain.OutboxEntry) (string, error) {
+	if len(entry.Attributes) == 0 {
+		return message.SendMessage(ctx, target, entry.Body)
+	}
+	return message.SendMessageWithAttributes(ctx, target, entry.Body, entry.Attributes)
+}
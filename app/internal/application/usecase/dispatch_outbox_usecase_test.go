@@ -0,0 +1,139 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/observability"
+)
+
+// mockOutboxPort keeps the outbox in memory
+type mockOutboxPort struct {
+	entries map[string]domain.OutboxEntry
+	saveErr error
+}
+
+func newMockOutboxPort(entries ...domain.OutboxEntry) *mockOutboxPort {
+	m := &mockOutboxPort{entries: map[string]domain.OutboxEntry{}}
+	for _, entry := range entries {
+		m.entries[entry.ID] = entry
+	}
+	return m
+}
+
+func (m *mockOutboxPort) Save(ctx context.Context, entry domain.OutboxEntry) error {
+	if m.saveErr != nil {
+		return m.saveErr
+	}
+	m.entries[entry.ID] = entry
+	return nil
+}
+
+func (m *mockOutboxPort) Pending(ctx context.Context) ([]domain.OutboxEntry, error) {
+	entries := make([]domain.OutboxEntry, 0, len(m.entries))
+	for _, entry := range m.entries {
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].CreatedAt.Before(entries[j].CreatedAt) })
+	return entries, nil
+}
+
+func (m *mockOutboxPort) Delete(ctx context.Context, id string) error {
+	delete(m.entries, id)
+	return nil
+}
+
+func TestDispatchOutbox_DeliversDueEntries(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	now := time.Now()
+	outbox := newMockOutboxPort(
+		domain.OutboxEntry{ID: "due", ProcessID: "p1", Body: `{"process_id":"p1"}`, CreatedAt: now.Add(-time.Minute), NextAttemptAt: now.Add(-time.Second)},
+		domain.OutboxEntry{ID: "signed", ProcessID: "p2", Body: `{"process_id":"p2"}`, Attributes: map[string]string{"signature": "sig"}, CreatedAt: now, NextAttemptAt: now},
+		domain.OutboxEntry{ID: "later", ProcessID: "p3", Body: `{"process_id":"p3"}`, CreatedAt: now, NextAttemptAt: now.Add(time.Hour)},
+	)
+
+	var plain, signed []string
+	messagePort := &mockMessagePort{
+		sendMessageFunc: func(ctx context.Context, queueURL string, messageBody string) (string, error) {
+			plain = append(plain, messageBody)
+			return "msg-id", nil
+		},
+		sendMessageWithAttributesFunc: func(ctx context.Context, queueURL string, messageBody string, attributes map[string]string) (string, error) {
+			if attributes["signature"] != "sig" {
+				t.Errorf("Expected the stored attributes, got %v", attributes)
+			}
+			signed = append(signed, messageBody)
+			return "msg-id", nil
+		},
+	}
+
+	delivered, err := NewDispatchOutboxUseCase(outbox, &mockStoragePort{}, messagePort, "output-queue").Dispatch(context.Background())
+	if err != nil {
+		t.Fatalf("Dispatch failed: %v", err)
+	}
+
+	if delivered != 2 || len(plain) != 1 || len(signed) != 1 {
+		t.Errorf("Expected 2 delivered, got %d (%v, %v)", delivered, plain, signed)
+	}
+	if _, ok := outbox.entries["later"]; !ok || len(outbox.entries) != 1 {
+		t.Errorf("Expected only the entry not yet due left, got %v", outbox.entries)
+	}
+}
+
+func TestDispatchOutbox_ReschedulesFailures(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	start := time.Now()
+	outbox := newMockOutboxPort(domain.OutboxEntry{ID: "1", ProcessID: "p1", Body: "{}", Attempts: 2, NextAttemptAt: start})
+	messagePort := &mockMessagePort{
+		sendMessageFunc: func(ctx context.Context, queueURL string, messageBody string) (string, error) {
+			return "", errors.New("throttled")
+		},
+	}
+
+	delivered, err := NewDispatchOutboxUseCase(outbox, &mockStoragePort{}, messagePort, "output-queue").Dispatch(context.Background())
+	if err != nil || delivered != 0 {
+		t.Fatalf("Expected nothing delivered, got %d, %v", delivered, err)
+	}
+
+	entry, ok := outbox.entries["1"]
+	if !ok {
+		t.Fatal("Expected the entry kept after a failed send")
+	}
+	if entry.Attempts != 3 || entry.LastError != "throttled" {
+		t.Errorf("Unexpected entry %+v", entry)
+	}
+	if entry.NextAttemptAt.Before(start.Add(20 * time.Second)) {
+		t.Errorf("Expected the retry delayed by at least 20s, got %v", entry.NextAttemptAt.Sub(start))
+	}
+}
+
+func TestDispatchOutbox_RunStopsWithContext(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	outbox := newMockOutboxPort(domain.OutboxEntry{ID: "1", Body: "{}"})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		NewDispatchOutboxUseCase(outbox, &mockStoragePort{}, &mockMessagePort{}, "output-queue").Run(ctx, time.Hour)
+		close(done)
+	}()
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Expected Run to return after cancel")
+	}
+}
@@ -0,0 +1,73 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/port"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/observability"
+)
+
+// GetJobTimelineUseCase reads back the stage transitions ProcessVideoUseCase records with
+// WithTimeline, from the bucket the workers write them to
+type GetJobTimelineUseCase struct {
+	storage      port.StoragePort
+	eventsBucket string
+}
+
+func NewGetJobTimelineUseCase(
+	storage port.StoragePort,
+	eventsBucket string,
+) *GetJobTimelineUseCase {
+	return &GetJobTimelineUseCase{
+		storage:      storage,
+		eventsBucket: eventsBucket,
+	}
+}
+
+// Timeline returns the events of processID and the time spent in each stage; a job without
+// events fails with domain.ErrObjectNotFound
+func (uc *GetJobTimelineUseCase) Timeline(ctx context.Context, processID string) (domain.JobTimeline, error) {
+	// No job is ever recorded under an invalid process_id
+	if err := domain.ValidateProcessID(processID); err != nil {
+		return domain.JobTimeline{}, fmt.Errorf("%w: %v", domain.ErrObjectNotFound, err)
+	}
+
+	keys, err := uc.storage.ListObjects(ctx, uc.eventsBucket, domain.TimelinePrefix(processID))
+	if err != nil {
+		observability.RecordS3Operation(ctx, "list", false)
+		return domain.JobTimeline{}, fmt.Errorf("failed to list job events: %w", err)
+	}
+	observability.RecordS3Operation(ctx, "list", true)
+	if len(keys) == 0 {
+		return domain.JobTimeline{}, fmt.Errorf("no events for process %s: %w", processID, domain.ErrObjectNotFound)
+	}
+
+	events := make([]domain.JobEvent, 0, len(keys))
+	for _, key := range keys {
+		event, err := uc.readEvent(ctx, key)
+		if err != nil {
+			return domain.JobTimeline{}, err
+		}
+		events = append(events, event)
+	}
+	return domain.NewJobTimeline(processID, events), nil
+}
+
+func (uc *GetJobTimelineUseCase) readEvent(ctx context.Context, key string) (domain.JobEvent, error) {
+	body, err := uc.storage.GetObject(ctx, uc.eventsBucket, key)
+	if err != nil {
+		observability.RecordS3Operation(ctx, "get", false)
+		return domain.JobEvent{}, fmt.Errorf("failed to get job event %s: %w", key, err)
+	}
+	defer body.Close()
+	observability.RecordS3Operation(ctx, "get", true)
+
+	var event domain.JobEvent
+	if err := json.NewDecoder(body).Decode(&event); err != nil {
+		return domain.JobEvent{}, fmt.Errorf("invalid job event %s: %w", key, err)
+	}
+	return event, nil
+}
@@ -0,0 +1,98 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+)
+
+// memoryObjects backs a mockStoragePort with a map, for use cases that read back what they write
+type memoryObjects struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func newMemoryStorage() (*memoryObjects, *mockStoragePort) {
+	store := &memoryObjects{objects: make(map[string][]byte)}
+	return store, &mockStoragePort{
+		putObjectFunc: func(ctx context.Context, bucket, key string, body io.Reader, options domain.PutOptions) (string, error) {
+			data, err := io.ReadAll(body)
+			store.mu.Lock()
+			defer store.mu.Unlock()
+			store.objects[bucket+"/"+key] = data
+			return key, err
+		},
+		getObjectFunc: func(ctx context.Context, bucket, key string, options domain.GetOptions) (io.ReadCloser, error) {
+			store.mu.Lock()
+			defer store.mu.Unlock()
+			data, ok := store.objects[bucket+"/"+key]
+			if !ok {
+				return nil, domain.ErrObjectNotFound
+			}
+			return io.NopCloser(bytes.NewReader(data)), nil
+		},
+		listObjectsFunc: func(ctx context.Context, bucket, prefix string) ([]string, error) {
+			store.mu.Lock()
+			defer store.mu.Unlock()
+			var keys []string
+			for name := range store.objects {
+				if key, ok := strings.CutPrefix(name, bucket+"/"); ok && strings.HasPrefix(key, prefix) {
+					keys = append(keys, key)
+				}
+			}
+			sort.Strings(keys)
+			return keys, nil
+		},
+	}
+}
+
+func TestGetJobTimeline(t *testing.T) {
+	store, storagePort := newMemoryStorage()
+	store.objects["output-bucket/timelines/process-123/00000000000000000002-download-completed.json"] = []byte(`{"process_id":"process-123","stage":"download","status":"completed","timestamp":"2024-01-01T12:00:05Z","bytes":2048}`)
+	store.objects["output-bucket/timelines/process-123/00000000000000000001-download-started.json"] = []byte(`{"process_id":"process-123","stage":"download","status":"started","timestamp":"2024-01-01T12:00:00Z"}`)
+	store.objects["output-bucket/timelines/process-1234/00000000000000000001-download-started.json"] = []byte(`{}`)
+
+	timeline, err := NewGetJobTimelineUseCase(storagePort, "output-bucket").Timeline(context.Background(), "process-123")
+	if err != nil {
+		t.Fatalf("Timeline failed: %v", err)
+	}
+	if len(timeline.Events) != 2 || len(timeline.Stages) != 1 {
+		t.Fatalf("Expected only the events of process-123, got %+v", timeline)
+	}
+	if span := timeline.Stages[0]; span.Stage != domain.JobStageDownload || span.DurationSeconds != 5 || span.Bytes != 2048 {
+		t.Errorf("Unexpected download span %+v", span)
+	}
+}
+
+func TestGetJobTimeline_Errors(t *testing.T) {
+	store, storagePort := newMemoryStorage()
+	useCase := NewGetJobTimelineUseCase(storagePort, "output-bucket")
+
+	if _, err := useCase.Timeline(context.Background(), ""); err == nil {
+		t.Error("Expected error without process_id")
+	}
+	if _, err := useCase.Timeline(context.Background(), "unknown"); !errors.Is(err, domain.ErrObjectNotFound) {
+		t.Errorf("Expected ErrObjectNotFound for a job without events, got %v", err)
+	}
+
+	store.objects["output-bucket/timelines/broken/1-download-started.json"] = []byte(`not json`)
+	if _, err := useCase.Timeline(context.Background(), "broken"); err == nil {
+		t.Error("Expected error for an unreadable event")
+	}
+
+	failing := NewGetJobTimelineUseCase(&mockStoragePort{
+		listObjectsFunc: func(ctx context.Context, bucket, prefix string) ([]string, error) {
+			return nil, errors.New("access denied")
+		},
+	}, "output-bucket")
+	if _, err := failing.Timeline(context.Background(), "process-123"); err == nil || errors.Is(err, domain.ErrObjectNotFound) {
+		t.Errorf("Expected the storage error, got %v", err)
+	}
+}
@@ -0,0 +1,91 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/port"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/observability"
+	"go.uber.org/zap"
+)
+
+// MessageHandler handles the messages of one type; body is the message as received, with
+// offloaded payloads already resolved
+type MessageHandler func(ctx context.Context, body string) error
+
+// MessageRouterUseCase dispatches the messages of a mixed input queue to the handler
+// registered for their type. Messages of unknown type are moved to the dead-letter queue
+// with the reason as the rejection_reason attribute
+type MessageRouterUseCase struct {
+	message            port.MessagePort
+	deadLetterQueueURL string
+	handlers           map[string]MessageHandler
+}
+
+// NewMessageRouterUseCase builds the router; without deadLetterQueueURL rejected messages
+// are left in the queue for the redrive policy to move
+func NewMessageRouterUseCase(
+	message port.MessagePort,
+	deadLetterQueueURL string,
+) *MessageRouterUseCase {
+	return &MessageRouterUseCase{
+		message:            message,
+		deadLetterQueueURL: deadLetterQueueURL,
+		handlers:           map[string]MessageHandler{},
+	}
+}
+
+// Register routes messageType to handler, replacing any handler registered before
+func (r *MessageRouterUseCase) Register(messageType string, handler MessageHandler) *MessageRouterUseCase {
+	r.handlers[messageType] = handler
+	return r
+}
+
+// Route runs the handler of the message type and returns its error. An error wrapping
+// domain.ErrMessageNotHandled means the message must stay in the queue
+func (r *MessageRouterUseCase) Route(ctx context.Context, body string, attributes map[string]string) error {
+	messageType, err := domain.ResolveMessageType(body, attributes)
+	if err != nil {
+		return r.reject(ctx, body, "", err.Error())
+	}
+
+	handler, ok := r.handlers[messageType]
+	if !ok {
+		return r.reject(ctx, body, messageType, fmt.Sprintf("unknown message type %q", messageType))
+	}
+	return handler(ctx, body)
+}
+
+// Reject moves a message stopped before routing, e.g. by authentication, to the dead-letter
+// queue with the reason, as the messages of unknown type
+func (r *MessageRouterUseCase) Reject(ctx context.Context, body, reason string) error {
+	return r.reject(ctx, body, "", reason)
+}
+
+func (r *MessageRouterUseCase) reject(ctx context.Context, body, messageType, reason string) error {
+	logger := observability.FromContext(ctx).With(
+		zap.String("message_type", messageType),
+		zap.String("reason", reason),
+	)
+	observability.RecordError("routing")
+
+	if r.deadLetterQueueURL == "" {
+		logger.Warn("message rejected, left in the queue for the redrive policy")
+		return fmt.Errorf("%w: %s", domain.ErrMessageNotHandled, reason)
+	}
+
+	attributes := map[string]string{domain.RejectionReasonAttribute: reason}
+	if messageType != "" {
+		attributes[domain.MessageTypeAttribute] = messageType
+	}
+	if _, err := r.message.SendMessageWithAttributes(ctx, r.deadLetterQueueURL, body, attributes); err != nil {
+		observability.RecordSQSOperation("send", false)
+		logger.Error("failed to move rejected message to the dead-letter queue", zap.Error(err))
+		return fmt.Errorf("%w: failed to move rejected message to the dead-letter queue: %v", domain.ErrMessageNotHandled, err)
+	}
+	observability.RecordSQSOperation("send", true)
+
+	logger.Warn("message rejected to the dead-letter queue")
+	return fmt.Errorf("message rejected: %s", reason)
+}
@@ -0,0 +1,135 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/observability"
+)
+
+func TestMessageRouter_Route(t *testing.T) {
+	var routed []string
+	handler := func(name string) MessageHandler {
+		return func(ctx context.Context, body string) error {
+			routed = append(routed, name+":"+body)
+			return nil
+		}
+	}
+
+	router := NewMessageRouterUseCase(&mockMessagePort{}, "dlq").
+		Register(domain.MessageTypeVideoProcess, handler("process")).
+		Register(domain.MessageTypePing, handler("ping"))
+
+	messages := []struct {
+		body       string
+		attributes map[string]string
+	}{
+		{`{"process_id":"123"}`, nil},
+		{`{"type":"ping"}`, nil},
+		{`{"process_id":"123"}`, map[string]string{"type": "ping"}},
+	}
+	for _, msg := range messages {
+		if err := router.Route(context.Background(), msg.body, msg.attributes); err != nil {
+			t.Errorf("Route failed: %v", err)
+		}
+	}
+
+	want := []string{`process:{"process_id":"123"}`, `ping:{"type":"ping"}`, `ping:{"process_id":"123"}`}
+	if len(routed) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, routed)
+	}
+	for i := range want {
+		if routed[i] != want[i] {
+			t.Errorf("Expected %s, got %s", want[i], routed[i])
+		}
+	}
+}
+
+func TestMessageRouter_HandlerError(t *testing.T) {
+	handlerErr := errors.New("job failed")
+	router := NewMessageRouterUseCase(&mockMessagePort{}, "dlq").
+		Register(domain.MessageTypeVideoProcess, func(ctx context.Context, body string) error { return handlerErr })
+
+	if err := router.Route(context.Background(), `{}`, nil); !errors.Is(err, handlerErr) {
+		t.Errorf("Expected the handler error, got %v", err)
+	}
+}
+
+func TestMessageRouter_RejectsToDeadLetterQueue(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	var sentQueue, sentBody string
+	var sentAttributes map[string]string
+	messagePort := &mockMessagePort{
+		sendMessageWithAttributesFunc: func(ctx context.Context, queueURL string, messageBody string, attributes map[string]string) (string, error) {
+			sentQueue, sentBody, sentAttributes = queueURL, messageBody, attributes
+			return "msg-id", nil
+		},
+	}
+	router := NewMessageRouterUseCase(messagePort, "dlq")
+
+	err := router.Route(context.Background(), `{"type":"video.resize"}`, nil)
+	if err == nil || errors.Is(err, domain.ErrMessageNotHandled) {
+		t.Errorf("Expected a rejection that lets the message be deleted, got %v", err)
+	}
+	if sentQueue != "dlq" || sentBody != `{"type":"video.resize"}` {
+		t.Errorf("Expected the original body in the DLQ, got %s %s", sentQueue, sentBody)
+	}
+	if sentAttributes[domain.RejectionReasonAttribute] != `unknown message type "video.resize"` || sentAttributes["type"] != "video.resize" {
+		t.Errorf("Unexpected attributes %v", sentAttributes)
+	}
+
+	if err := router.Route(context.Background(), `not json`, nil); err == nil {
+		t.Error("Expected a rejection for a body without type")
+	}
+	if _, ok := sentAttributes["type"]; ok || sentAttributes[domain.RejectionReasonAttribute] == "" {
+		t.Errorf("Expected only the reason for a body without type, got %v", sentAttributes)
+	}
+}
+
+func TestMessageRouter_RejectKeepsMessage(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	withoutDLQ := NewMessageRouterUseCase(&mockMessagePort{}, "")
+	if err := withoutDLQ.Route(context.Background(), `{"type":"video.resize"}`, nil); !errors.Is(err, domain.ErrMessageNotHandled) {
+		t.Errorf("Expected the message kept for the redrive policy, got %v", err)
+	}
+
+	failing := NewMessageRouterUseCase(&mockMessagePort{
+		sendMessageWithAttributesFunc: func(ctx context.Context, queueURL string, messageBody string, attributes map[string]string) (string, error) {
+			return "", errors.New("sqs unavailable")
+		},
+	}, "dlq")
+	if err := failing.Route(context.Background(), `{"type":"video.resize"}`, nil); !errors.Is(err, domain.ErrMessageNotHandled) {
+		t.Errorf("Expected the message kept when the DLQ send fails, got %v", err)
+	}
+}
+
+func TestMessageRouter_Reject(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	var sentBody string
+	var sentAttributes map[string]string
+	router := NewMessageRouterUseCase(&mockMessagePort{
+		sendMessageWithAttributesFunc: func(ctx context.Context, queueURL string, messageBody string, attributes map[string]string) (string, error) {
+			sentBody, sentAttributes = messageBody, attributes
+			return "msg-id", nil
+		},
+	}, "dlq")
+
+	err := router.Reject(context.Background(), `{"process_id":"123"}`, "invalid signature")
+	if err == nil || errors.Is(err, domain.ErrMessageNotHandled) {
+		t.Errorf("Expected a rejection that lets the message be deleted, got %v", err)
+	}
+	if sentBody != `{"process_id":"123"}` || sentAttributes[domain.RejectionReasonAttribute] != "invalid signature" {
+		t.Errorf("Expected the message in the DLQ with the reason, got %s %v", sentBody, sentAttributes)
+	}
+}
@@ -1,17 +1,30 @@
 package usecase
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
 	"github.com/SOAT-Project/hackaton-soat-processor/internal/port"
 	"github.com/SOAT-Project/hackaton-soat-processor/pkg/observability"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/transfer"
 	"go.uber.org/zap"
 )
 
@@ -21,6 +34,77 @@ type ProcessVideoUseCase struct {
 	videoProcessor port.VideoProcessorPort
 	outputBucket   string
 	outputQueueURL string
+	storageClass   string
+	tenants        domain.TenantRegistry
+	allowedSources domain.SourceAllowlist
+	allowedOutputs domain.SourceAllowlist
+	urlSource      port.URLSourcePort
+
+	videoExtensions domain.VideoExtensions
+	sniffVideos     bool
+	maxVideoBytes   int64
+	downloadResumes int
+	versioned       bool
+	workDir         port.WorkDirPort
+
+	resultTransports    map[string]port.MessagePort
+	allowedDestinations domain.ResultDestinations
+	signer              port.SignerPort
+	encryptor           port.EncryptorPort
+	serializer          port.ResultSerializerPort
+
+	payloadBucket    string
+	payloadThreshold int
+
+	compressThreshold int
+
+	timeouts domain.StageTimeouts
+
+	limiter          *transfer.Limiter
+	progressQueueURL string
+	progressInterval time.Duration
+	progress         port.ProgressPort
+
+	errorReporter port.ErrorReporter
+
+	scanner          port.ScannerPort
+	quarantineBucket string
+	quarantinePrefix string
+	quarantineInputs bool
+
+	packager      port.PackagerPort
+	merger        port.ArchiveMergerPort
+	concatenator  port.VideoConcatenatorPort
+	trimmer       port.VideoTrimmerPort
+	normalizer    port.AudioNormalizerPort
+	analyzer      port.QualityAnalyzerPort
+	fingerprinter port.FingerprinterPort
+	barcodes      port.BarcodeScannerPort
+	vision        port.VisionAnalyzerPort
+	transcription port.TranscriptionPort
+
+	worker *domain.WorkerIdentity
+
+	dryRun bool
+
+	outbox port.OutboxPort
+
+	deleteOnConfirm bool
+
+	cancellations bool
+
+	maxAttempts int
+
+	sla time.Duration
+
+	costModel   domain.CostModel
+	reportUsage bool
+
+	timeline bool
+
+	jobRecords    bool
+	archiveBucket string
+	archivePrefix string
 }
 
 func NewProcessVideoUseCase(
@@ -36,240 +120,2764 @@ func NewProcessVideoUseCase(
 		videoProcessor: videoProcessor,
 		outputBucket:   outputBucket,
 		outputQueueURL: outputQueueURL,
+
+		videoExtensions: domain.DefaultVideoExtensions,
 	}
 }
 
+// WithStorageClass sets the default S3 storage class used for uploaded zips
+func (uc *ProcessVideoUseCase) WithStorageClass(storageClass string) *ProcessVideoUseCase {
+	uc.storageClass = storageClass
+	return uc
+}
+
+// WithSigner enables signing of result messages; the signature travels as message attributes
+func (uc *ProcessVideoUseCase) WithSigner(signer port.SignerPort) *ProcessVideoUseCase {
+	uc.signer = signer
+	return uc
+}
+
+// WithEncryptor enables envelope encryption of result message bodies
+func (uc *ProcessVideoUseCase) WithEncryptor(encryptor port.EncryptorPort) *ProcessVideoUseCase {
+	uc.encryptor = encryptor
+	return uc
+}
+
+// WithResultSerializer selects the result message format; without one results are sent as JSON
+func (uc *ProcessVideoUseCase) WithResultSerializer(serializer port.ResultSerializerPort) *ProcessVideoUseCase {
+	uc.serializer = serializer
+	return uc
+}
+
+// WithPayloadOffload stores result bodies larger than threshold bytes in bucket and sends
+// an SQS extended client S3 pointer instead
+func (uc *ProcessVideoUseCase) WithPayloadOffload(bucket string, threshold int) *ProcessVideoUseCase {
+	uc.payloadBucket = bucket
+	uc.payloadThreshold = threshold
+	return uc
+}
+
+// WithCompression gzips result bodies larger than threshold bytes, sent base64 encoded with
+// the content_encoding=gzip attribute; bodies still too large are then offloaded
+func (uc *ProcessVideoUseCase) WithCompression(threshold int) *ProcessVideoUseCase {
+	uc.compressThreshold = threshold
+	return uc
+}
+
+// WithStageTimeouts bounds the download, processing and upload of each job
+func (uc *ProcessVideoUseCase) WithStageTimeouts(timeouts domain.StageTimeouts) *ProcessVideoUseCase {
+	uc.timeouts = timeouts
+	return uc
+}
+
+// WithBandwidthLimit caps the downloads and uploads of jobs; the limiter can be shared with
+// other use cases so the cap holds for the whole worker
+func (uc *ProcessVideoUseCase) WithBandwidthLimit(limiter *transfer.Limiter) *ProcessVideoUseCase {
+	uc.limiter = limiter
+	return uc
+}
+
+// WithProgress sends a TransferProgress message to queueURL at most every interval while a
+// job downloads or uploads
+func (uc *ProcessVideoUseCase) WithProgress(queueURL string, interval time.Duration) *ProcessVideoUseCase {
+	uc.progressQueueURL = queueURL
+	uc.progressInterval = interval
+	return uc
+}
+
+// WithProgressReporter hands the transfer progress of each job to reporter too, at most every
+// interval set by WithProgress, e.g. to turn it into the heartbeats of a Temporal activity
+func (uc *ProcessVideoUseCase) WithProgressReporter(reporter port.ProgressPort) *ProcessVideoUseCase {
+	uc.progress = reporter
+	return uc
+}
+
+// WithErrorReporter sends the stages that fail to an error tracker, with the job they belong to
+func (uc *ProcessVideoUseCase) WithErrorReporter(reporter port.ErrorReporter) *ProcessVideoUseCase {
+	uc.errorReporter = reporter
+	return uc
+}
+
+// WithScanner enables malware scanning of downloaded videos; infected files are moved to
+// quarantineBucket under quarantinePrefix
+func (uc *ProcessVideoUseCase) WithScanner(scanner port.ScannerPort, quarantineBucket, quarantinePrefix string) *ProcessVideoUseCase {
+	uc.scanner = scanner
+	uc.quarantineBucket = quarantineBucket
+	uc.quarantinePrefix = quarantinePrefix
+	return uc
+}
+
+// WithInputQuarantine moves the video of a job that fails for good because of the video itself
+// (see domain.QuarantineReason) to quarantineBucket under quarantinePrefix, as infected videos
+// are, instead of leaving it in place to be sent again
+func (uc *ProcessVideoUseCase) WithInputQuarantine(quarantineBucket, quarantinePrefix string) *ProcessVideoUseCase {
+	uc.quarantineInputs = true
+	uc.quarantineBucket = quarantineBucket
+	uc.quarantinePrefix = quarantinePrefix
+	return uc
+}
+
+// WithPackager enables the hls and dash output types
+func (uc *ProcessVideoUseCase) WithPackager(packager port.PackagerPort) *ProcessVideoUseCase {
+	uc.packager = packager
+	return uc
+}
+
+// WithArchiveMerger enables the combined output of batch requests
+func (uc *ProcessVideoUseCase) WithArchiveMerger(merger port.ArchiveMergerPort) *ProcessVideoUseCase {
+	uc.merger = merger
+	return uc
+}
+
+// WithConcatenator enables the concat output type
+func (uc *ProcessVideoUseCase) WithConcatenator(concatenator port.VideoConcatenatorPort) *ProcessVideoUseCase {
+	uc.concatenator = concatenator
+	return uc
+}
+
+// WithTrimmer enables the trim output type
+func (uc *ProcessVideoUseCase) WithTrimmer(trimmer port.VideoTrimmerPort) *ProcessVideoUseCase {
+	uc.trimmer = trimmer
+	return uc
+}
+
+// WithAudioNormalizer enables the loudnorm output type
+func (uc *ProcessVideoUseCase) WithAudioNormalizer(normalizer port.AudioNormalizerPort) *ProcessVideoUseCase {
+	uc.normalizer = normalizer
+	return uc
+}
+
+// WithQualityAnalyzer enables the qc output type
+func (uc *ProcessVideoUseCase) WithQualityAnalyzer(analyzer port.QualityAnalyzerPort) *ProcessVideoUseCase {
+	uc.analyzer = analyzer
+	return uc
+}
+
+// WithFingerprinter enables the fingerprint output type
+func (uc *ProcessVideoUseCase) WithFingerprinter(fingerprinter port.FingerprinterPort) *ProcessVideoUseCase {
+	uc.fingerprinter = fingerprinter
+	return uc
+}
+
+// WithVisionAnalyzer enables the vision analysis of the tenants configured with vision
+func (uc *ProcessVideoUseCase) WithVisionAnalyzer(analyzer port.VisionAnalyzerPort) *ProcessVideoUseCase {
+	uc.vision = analyzer
+	return uc
+}
+
+// WithTranscription enables the transcription of loudnorm and trim outputs
+func (uc *ProcessVideoUseCase) WithTranscription(transcription port.TranscriptionPort) *ProcessVideoUseCase {
+	uc.transcription = transcription
+	return uc
+}
+
+// WithBarcodeScanner enables barcode scanning of frames outputs
+func (uc *ProcessVideoUseCase) WithBarcodeScanner(scanner port.BarcodeScannerPort) *ProcessVideoUseCase {
+	uc.barcodes = scanner
+	return uc
+}
+
+// WithWorkerIdentity adds the worker identity to every result message
+func (uc *ProcessVideoUseCase) WithWorkerIdentity(worker domain.WorkerIdentity) *ProcessVideoUseCase {
+	uc.worker = &worker
+	return uc
+}
+
+// WithDryRun turns every job into a dry run, as if each message had dry_run set
+func (uc *ProcessVideoUseCase) WithDryRun(dryRun bool) *ProcessVideoUseCase {
+	uc.dryRun = dryRun
+	return uc
+}
+
+// WithOutbox persists every result message before sending it; messages whose send fails
+// stay in the outbox until DispatchOutboxUseCase delivers them
+func (uc *ProcessVideoUseCase) WithOutbox(outbox port.OutboxPort) *ProcessVideoUseCase {
+	uc.outbox = outbox
+	return uc
+}
+
+// WithDeleteOnConfirm keeps each original video until the consumer confirms the result on the
+// control queue (see ConfirmDeletionUseCase) instead of deleting it once the result is accepted
+func (uc *ProcessVideoUseCase) WithDeleteOnConfirm(deleteOnConfirm bool) *ProcessVideoUseCase {
+	uc.deleteOnConfirm = deleteOnConfirm
+	return uc
+}
+
+// WithSourceAllowlist limits the buckets and prefixes messages may point video_bucket and
+// video_key to, so a message cannot make the worker read and delete any object the IAM role reaches
+func (uc *ProcessVideoUseCase) WithSourceAllowlist(allowlist domain.SourceAllowlist) *ProcessVideoUseCase {
+	uc.allowedSources = allowlist
+	return uc
+}
+
+// WithOutputAllowlist lists the buckets and prefixes messages may write their outputs to with
+// output_bucket/output_prefix, besides the locations of their tenant
+func (uc *ProcessVideoUseCase) WithOutputAllowlist(allowlist domain.SourceAllowlist) *ProcessVideoUseCase {
+	uc.allowedOutputs = allowlist
+	return uc
+}
+
+// WithVideoExtensions sets the extensions of the videos the worker processes, which tenants may
+// replace. With sniff, a video with another extension is still processed when its first bytes
+// are the header of a known container
+func (uc *ProcessVideoUseCase) WithVideoExtensions(extensions domain.VideoExtensions, sniff bool) *ProcessVideoUseCase {
+	uc.videoExtensions = extensions
+	uc.sniffVideos = sniff
+	return uc
+}
+
+// WithMaxVideoSize rejects videos larger than maxBytes with video_too_large, before the download
+// when the storage reports the size; zero allows any size
+func (uc *ProcessVideoUseCase) WithMaxVideoSize(maxBytes int64) *ProcessVideoUseCase {
+	uc.maxVideoBytes = maxBytes
+	return uc
+}
+
+// WithDownloadResumes lets a video download from the bucket cut mid-stream resume from the last
+// byte received, up to resumes times per video, instead of restarting with the job; zero
+// disables it
+func (uc *ProcessVideoUseCase) WithDownloadResumes(resumes int) *ProcessVideoUseCase {
+	uc.downloadResumes = resumes
+	return uc
+}
+
+// WithWorkDir creates each downloaded video in a directory chosen by its size, so small videos
+// can be kept in memory, and releases what the job holds there once it ends. The video
+// processor must work in the directory of the video
+func (uc *ProcessVideoUseCase) WithWorkDir(workDir port.WorkDirPort) *ProcessVideoUseCase {
+	uc.workDir = workDir
+	return uc
+}
+
+// WithVersionedSources reads and deletes the version of each source video the worker described
+// before the download, so in a versioned bucket a video replaced mid-job is not the one read, and
+// the delete removes the processed version instead of adding a delete marker over a newer one.
+// It needs s3:GetObjectVersion and s3:DeleteObjectVersion
+func (uc *ProcessVideoUseCase) WithVersionedSources(versioned bool) *ProcessVideoUseCase {
+	uc.versioned = versioned
+	return uc
+}
+
+// WithURLSource accepts requests with a video_url instead of video_bucket/video_key
+func (uc *ProcessVideoUseCase) WithURLSource(source port.URLSourcePort) *ProcessVideoUseCase {
+	uc.urlSource = source
+	return uc
+}
+
+// WithResultDestinations delivers each result to the destinations of its tenant and message
+// besides the output queue, through the transport of each destination type. allowed lists the
+// destinations any message may pick, on top of those of its tenant
+func (uc *ProcessVideoUseCase) WithResultDestinations(transports map[string]port.MessagePort, allowed domain.ResultDestinations) *ProcessVideoUseCase {
+	uc.resultTransports = transports
+	uc.allowedDestinations = allowed
+	return uc
+}
+
+// WithCancellations makes each job check for a cancellation recorded by CancelJobUseCase
+// before it starts; cancelled jobs are answered with a cancelled error and the video is kept
+func (uc *ProcessVideoUseCase) WithCancellations(enabled bool) *ProcessVideoUseCase {
+	uc.cancellations = enabled
+	return uc
+}
+
+// WithRetries leaves the message of a job that failed transiently (a download or upload error)
+// in the queue to run again, until its maxAttempts-th delivery. It should not exceed the
+// maxReceiveCount of the queue redrive policy
+func (uc *ProcessVideoUseCase) WithRetries(maxAttempts int) *ProcessVideoUseCase {
+	uc.maxAttempts = maxAttempts
+	return uc
+}
+
+// WithSLA flags the results of jobs that took longer than sla from their submission; zero
+// disables the check
+func (uc *ProcessVideoUseCase) WithSLA(sla time.Duration) *ProcessVideoUseCase {
+	uc.sla = sla
+	return uc
+}
+
+// WithUsage prices the resources of each job with model for the cost metrics; report also
+// adds the usage to the results
+func (uc *ProcessVideoUseCase) WithUsage(model domain.CostModel, report bool) *ProcessVideoUseCase {
+	uc.costModel = model
+	uc.reportUsage = report
+	return uc
+}
+
+// WithTimeline records each stage transition of a job in the output bucket, under
+// timelines/{process_id}/, for GetJobTimelineUseCase
+func (uc *ProcessVideoUseCase) WithTimeline(enabled bool) *ProcessVideoUseCase {
+	uc.timeline = enabled
+	return uc
+}
+
+// WithJobRecords keeps a record of each completed video job in the output bucket, under
+// jobs/{process_id}.json, for ReprocessVideoUseCase. With archiveBucket, the original videos
+// the worker deletes are first copied there under archivePrefix, so they can still be reprocessed
+func (uc *ProcessVideoUseCase) WithJobRecords(archiveBucket, archivePrefix string) *ProcessVideoUseCase {
+	uc.jobRecords = true
+	uc.archiveBucket = archiveBucket
+	uc.archivePrefix = archivePrefix
+	return uc
+}
+
+// WithTenantRegistry sets the per-tenant overrides applied to each request
+func (uc *ProcessVideoUseCase) WithTenantRegistry(tenants domain.TenantRegistry) *ProcessVideoUseCase {
+	uc.tenants = tenants
+	return uc
+}
+
 func (uc *ProcessVideoUseCase) Execute(ctx context.Context, request domain.VideoProcess) error {
 	startTime := time.Now()
-	logger := observability.GetLogger().With(
-		zap.String("process_id", request.ProcessID),
+	// Every log of the job, including the helpers', carries its ids
+	ctx = observability.WithLoggerFields(ctx, jobLogFields(request)...)
+	logger := observability.FromContext(ctx).With(
 		zap.String("video_bucket", request.VideoBucket),
 		zap.String("video_key", request.VideoKey),
 	)
+	if request.VideoURL != "" {
+		logger = logger.With(zap.String("video_host", urlHost(request.VideoURL)))
+	}
+
+	if len(request.Metadata) > 0 {
+		logger = logger.With(zap.Any("metadata", request.Metadata))
+	}
 
 	observability.IncrementActiveMessages()
 	defer observability.DecrementActiveMessages()
 
+	// Whatever the job consumed, including the rollback after a failed result, is attributed
+	// to its tenant
+	usage := &observability.JobUsage{}
+	ctx = observability.WithJobUsage(observability.WithTenant(ctx, request.TenantID), usage)
+	defer func() {
+		observability.RecordJobUsage(request.TenantID, usage, uc.resourceUsage(usage).EstimatedCost)
+	}()
+
+	if !request.EnqueuedAt.IsZero() {
+		queueLatency := request.Age(startTime)
+		observability.RecordQueueLatency(queueLatency.Seconds())
+		logger = logger.With(zap.Duration("queue_latency", queueLatency))
+	}
+
 	logger.Info("starting video processing")
+	uc.recordEvent(ctx, domain.JobEvent{
+		ProcessID: request.ProcessID,
+		Stage:     domain.JobStageReceived,
+		Status:    domain.JobEventCompleted,
+		Attempt:   request.Attempt,
+	})
 
 	result := &domain.ProcessResult{
-		ProcessID: request.ProcessID,
-		Success:   false,
+		ProcessID:  request.ProcessID,
+		Worker:     uc.worker,
+		Success:    false,
+		Metadata:   request.Metadata,
+		Retry:      domain.NewRetryInfo(request.Attempt, uc.maxAttempts),
+		EnqueuedAt: request.EnqueuedAt,
+		// The message destinations are only trusted once the request is validated
+		Destinations: uc.tenants.Lookup(request.TenantID).ResultDestinations,
 	}
 
-	if err := uc.validateRequest(request); err != nil {
+	err := uc.validateRequest(request)
+	if err != nil {
 		logger.Error("validation failed", zap.Error(err))
 		observability.RecordError("validation")
 		result.Error = err
-		return uc.sendErrorMessage(ctx, result)
+		return rejected(uc.sendErrorMessage(ctx, result))
 	}
+	result.Destinations = result.Destinations.Merge(request.ResultDestinations)
 
-	videoPath, err := uc.downloadVideo(ctx, request)
+	request, result.Negotiated, err = negotiateOutput(request)
 	if err != nil {
-		logger.Error("video download failed", zap.Error(err))
-		observability.RecordError("download")
-		observability.RecordVideoProcessed(false, time.Since(startTime).Seconds(), 0)
-		result.Error = fmt.Errorf("failed to download video: %w", err)
-		return uc.sendErrorMessage(ctx, result)
+		logger.Error("no accepted output supported", zap.Error(err))
+		observability.RecordError("validation")
+		result.Error = err
+		return rejected(uc.sendErrorMessage(ctx, result))
 	}
-	defer os.Remove(videoPath)
 
-	// Record video file size
-	if stat, err := os.Stat(videoPath); err == nil {
-		observability.RecordFileSize("video", stat.Size())
-		logger.Info("video downloaded", zap.Int64("size_bytes", stat.Size()))
+	if uc.cancellations {
+		cancelled, err := uc.consumeCancellation(ctx, request.ProcessID)
+		if err != nil {
+			logger.Warn("failed to check job cancellation", zap.Error(err))
+		}
+		if cancelled {
+			logger.Info("job cancelled before processing")
+			result.Error = domain.NewCodedError(domain.ErrorCodeCancelled, fmt.Errorf("job cancelled"))
+			return uc.sendErrorMessage(ctx, result)
+		}
 	}
 
-	zipPath, frameCount, err := uc.videoProcessor.ProcessVideo(ctx, videoPath)
+	jobID, err := newJobID(request.ProcessID)
 	if err != nil {
-		logger.Error("video processing failed", zap.Error(err))
-		observability.RecordError("processing")
-		observability.RecordVideoProcessed(false, time.Since(startTime).Seconds(), 0)
-		result.Error = fmt.Errorf("failed to process video: %w", err)
+		logger.Error("failed to generate job id", zap.Error(err))
+		observability.RecordFailure("processing", err)
+		result.Error = err
 		return uc.sendErrorMessage(ctx, result)
 	}
-	defer os.Remove(zipPath)
 
-	logger.Info("video processed successfully", zap.Int("frames_extracted", frameCount))
+	if uc.workDir != nil {
+		defer uc.workDir.Release(jobID)
+	}
 
-	// Record zip file size
-	if stat, err := os.Stat(zipPath); err == nil {
-		observability.RecordFileSize("zip", stat.Size())
-		logger.Info("zip created", zap.Int64("size_bytes", stat.Size()))
+	if request.IsBatch() {
+		return uc.executeBatch(ctx, logger, request, jobID, result)
+	}
+	if request.IsConcat() {
+		return uc.executeConcat(ctx, logger, request, jobID, result)
 	}
 
-	outputKey := fmt.Sprintf("processed/frames_%s.zip", request.ProcessID)
-	if err := uc.uploadZip(ctx, zipPath, outputKey); err != nil {
-		logger.Error("zip upload failed", zap.Error(err))
-		observability.RecordError("upload")
-		observability.RecordVideoProcessed(false, time.Since(startTime).Seconds(), frameCount)
-		result.Error = fmt.Errorf("failed to upload zip: %w", err)
+	videoPath, videoObject, err := uc.fetchVideo(ctx, logger, request, jobID)
+	if err != nil {
+		observability.RecordVideoProcessed(ctx, false, time.Since(startTime).Seconds(), 0)
+		result.Error = uc.quarantineInput(ctx, logger, request, "", err)
+		return uc.sendErrorMessage(ctx, result)
+	}
+	defer os.Remove(videoPath)
+	result.Source = videoObject
+	request = uc.atVersion(request, videoObject)
+
+	// A dry run stops before the scan, whose quarantine moves and deletes the video
+	if uc.dryRun || request.DryRun {
+		return uc.estimateOutput(ctx, logger, request, videoPath, result)
+	}
+
+	if err := uc.checkMalware(ctx, logger, request, videoPath); err != nil {
+		observability.RecordVideoProcessed(ctx, false, time.Since(startTime).Seconds(), 0)
+		result.Error = err
 		return uc.sendErrorMessage(ctx, result)
 	}
 
-	logger.Info("zip uploaded successfully", zap.String("output_key", outputKey))
+	if request.Barcodes.Scan {
+		if err := uc.scanBarcodes(ctx, logger, request, jobID, videoPath, result); err != nil {
+			observability.RecordVideoProcessed(ctx, false, time.Since(startTime).Seconds(), 0)
+			result.Error = err
+			return uc.sendErrorMessage(ctx, result)
+		}
+	}
+
+	outputType := resolveOutputType(request)
+	if outputType == domain.OutputTypeQC {
+		return uc.analyzeVideo(ctx, logger, request, videoPath, startTime, result)
+	}
+
+	visionConfig := uc.tenants.Lookup(request.TenantID).Vision
+	var visionReport domain.VisionReport
+	if visionConfig != nil {
+		visionReport, err = uc.analyzeVision(ctx, logger, request, jobID, videoPath, *visionConfig)
+		if err != nil {
+			observability.RecordVideoProcessed(ctx, false, time.Since(startTime).Seconds(), 0)
+			result.Error = err
+			return uc.sendErrorMessage(ctx, result)
+		}
+	}
 
-	if err := uc.deleteOriginalVideo(ctx, request); err != nil {
-		logger.Warn("failed to delete original video", zap.Error(err))
+	putOptions := uc.putOptions(request, outputType)
+	var outputKey string
+	var partKeys, uploadedKeys []string
+	var frameCount int
+	if domain.IsPackagingOutput(outputType) {
+		outputKey, uploadedKeys, err = uc.packageVideo(ctx, logger, request, jobID, videoPath, outputType, putOptions)
+	} else if domain.IsVideoOutput(outputType) {
+		outputKey, err = uc.editVideo(ctx, logger, request, jobID, videoPath, outputType, result)
+		if err == nil {
+			uploadedKeys = []string{outputKey}
+		}
+	} else if outputType == domain.OutputTypeFingerprint {
+		outputKey, err = uc.fingerprintVideo(ctx, logger, request, jobID, videoPath)
+		if err == nil {
+			uploadedKeys = []string{outputKey}
+		}
 	} else {
-		logger.Info("original video deleted successfully")
+		partKeys, frameCount, err = uc.processZip(ctx, logger, request, jobID, videoPath, outputType, putOptions)
+		if err == nil {
+			outputKey = partKeys[0]
+			uploadedKeys = partKeys
+		}
+	}
+	if err == nil && visionConfig != nil {
+		var visionKey string
+		visionKey, err = uc.deliverVision(ctx, logger, request, *visionConfig, visionReport, result)
+		if err != nil {
+			location := uc.outputLocation(request)
+			uc.removeOutputs(ctx, logger, location.Bucket, outputPrefix(location, request, outputType), uploadedKeys)
+		} else if visionKey != "" {
+			uploadedKeys = append(uploadedKeys, visionKey)
+		}
+	}
+	if err == nil && request.Transcription.Enabled {
+		if err = uc.startTranscription(ctx, logger, request, jobID, outputKey, result); err != nil {
+			location := uc.outputLocation(request)
+			uc.removeOutputs(ctx, logger, location.Bucket, outputPrefix(location, request, outputType), uploadedKeys)
+		}
+	}
+	if err != nil {
+		observability.RecordVideoProcessed(ctx, false, time.Since(startTime).Seconds(), frameCount)
+		result.Error = uc.quarantineInput(ctx, logger, request, videoPath, err)
+		return uc.sendErrorMessage(ctx, result)
 	}
 
 	duration := time.Since(startTime)
-	observability.RecordVideoProcessed(true, duration.Seconds(), frameCount)
+	observability.RecordVideoProcessed(ctx, true, duration.Seconds(), frameCount)
 
+	location := uc.outputLocation(request)
 	result.Success = true
-	result.FileBucket = uc.outputBucket
+	result.FileBucket = location.Bucket
 	result.FileKey = outputKey
+	if len(partKeys) > 1 {
+		result.FileKeys = partKeys
+	}
+	result.OutputType = outputType
 
 	logger.Info("video processing completed",
 		zap.Duration("total_duration", duration),
 		zap.Int("frames", frameCount),
 	)
 
-	return uc.sendSuccessMessage(ctx, result)
-}
-
-func (uc *ProcessVideoUseCase) validateRequest(request domain.VideoProcess) error {
-	if request.ProcessID == "" {
-		return fmt.Errorf("process_id is required")
+	// The original video is deleted only after the result message is accepted, so a result
+	// that never reaches the consumer leaves the video in place to be processed again
+	var source *domain.ObjectRef
+	var markerKey string
+	switch {
+	case request.KeepOriginal:
+		logger.Info("original video kept as requested")
+	case request.VideoURL != "":
+		logger.Info("original video kept, it was read from video_url")
+	case uc.deleteOnConfirm:
+		// The marker is written before the result goes out, so the confirmation cannot
+		// arrive before it; it is rolled back with the outputs
+		markerKey, err = uc.recordPendingDeletion(ctx, request)
+		if err != nil {
+			logger.Warn("failed to record pending deletion, original video kept", zap.Error(err))
+		} else {
+			result.ConfirmRequired = true
+		}
+	default:
+		source = &domain.ObjectRef{Bucket: request.VideoBucket, Key: request.VideoKey, VersionID: request.Source.VersionID}
 	}
-	if request.VideoBucket == "" {
-		return fmt.Errorf("video_bucket is required")
+
+	// The copy is made while the original still exists, before the result releases it
+	var archive *domain.ObjectRef
+	if uc.jobRecords && uc.archiveBucket != "" && (source != nil || markerKey != "") {
+		archive, err = uc.archiveOriginal(ctx, request)
+		if err != nil {
+			logger.Warn("failed to archive original video, it cannot be reprocessed once deleted", zap.Error(err))
+		}
 	}
-	if request.VideoKey == "" {
-		return fmt.Errorf("video_key is required")
+
+	if err := uc.sendSuccessMessage(ctx, result, source); err != nil {
+		// Nothing will point the consumer to the outputs, so they are rolled back
+		uc.removeOutputs(ctx, logger, location.Bucket, outputPrefix(location, request, outputType), uploadedKeys)
+		if markerKey != "" {
+			uc.removeOutputs(ctx, logger, uc.outputBucket, markerKey, []string{markerKey})
+		}
+		return err
 	}
+	uc.scheduleExpiration(ctx, logger, request, outputType, location.Bucket, uploadedKeys)
 
+	// A reprocess updates the record of its process before running; videos read from a URL
+	// have no source to record
+	if uc.jobRecords && request.Version == 0 && request.VideoURL == "" {
+		if err := uc.recordJob(ctx, request, archive); err != nil {
+			logger.Warn("failed to record job, it cannot be reprocessed", zap.Error(err))
+		}
+	}
 	return nil
 }
 
-func (uc *ProcessVideoUseCase) downloadVideo(ctx context.Context, request domain.VideoProcess) (string, error) {
-	logger := observability.GetLogger()
-	logger.Info("downloading video from S3",
-		zap.String("bucket", request.VideoBucket),
-		zap.String("key", request.VideoKey),
-	)
+// Reject reports a request that could not be turned into a job, as Execute does for an invalid one
+func (uc *ProcessVideoUseCase) Reject(ctx context.Context, request domain.VideoProcess, err error) error {
+	ctx = observability.WithLoggerFields(ctx, jobLogFields(request)...)
+	observability.RecordError("validation")
+	return rejected(uc.sendErrorMessage(ctx, &domain.ProcessResult{
+		ProcessID:    request.ProcessID,
+		Worker:       uc.worker,
+		Metadata:     request.Metadata,
+		Destinations: uc.tenants.Lookup(request.TenantID).ResultDestinations,
+		Retry:        domain.NewRetryInfo(request.Attempt, uc.maxAttempts),
+		EnqueuedAt:   request.EnqueuedAt,
+		Error:        err,
+	}))
+}
 
-	body, err := uc.storage.GetObject(ctx, request.VideoBucket, request.VideoKey)
-	if err != nil {
-		observability.RecordS3Operation("get", false)
-		return "", fmt.Errorf("failed to get object from storage: %w", err)
+// rejected marks the error returned for an invalid request, once its result is sent, with
+// domain.ErrMessageRejected; a message left for a retry keeps its error
+func rejected(err error) error {
+	if err == nil || errors.Is(err, domain.ErrMessageNotHandled) {
+		return err
 	}
-	defer body.Close()
+	return fmt.Errorf("%w: %w", domain.ErrMessageRejected, err)
+}
 
-	observability.RecordS3Operation("get", true)
+// withStageTimeout derives the context of a job stage; a zero timeout only adds cancellation
+func withStageTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
 
-	tempDir := "/tmp/video-processor"
-	if err := os.MkdirAll(tempDir, 0777); err != nil {
-		return "", fmt.Errorf("failed to create temp directory: %w", err)
+// stageError reports a stage that ran out of its own time with ErrorCodeTimeout; errors of a
+// job context that was itself cancelled are returned as they are
+func stageError(jobCtx, stageCtx context.Context, stage string, timeout time.Duration, err error) error {
+	if jobCtx.Err() == nil && errors.Is(stageCtx.Err(), context.DeadlineExceeded) {
+		return domain.NewCodedError(domain.ErrorCodeTimeout, fmt.Errorf("%s timed out after %s: %w", stage, timeout, err))
 	}
+	return err
+}
 
-	ext := filepath.Ext(request.VideoKey)
-	tempFile := filepath.Join(tempDir, fmt.Sprintf("video_%s%s", request.ProcessID, ext))
+// jobLogFields identifies the job in the logs
+func jobLogFields(request domain.VideoProcess) []zap.Field {
+	fields := []zap.Field{zap.String("process_id", request.ProcessID)}
+	if request.TenantID != "" {
+		fields = append(fields, zap.String("tenant_id", request.TenantID))
+	}
+	return fields
+}
 
-	out, err := os.Create(tempFile)
-	if err != nil {
-		return "", fmt.Errorf("failed to create temp file: %w", err)
+// estimateOutput answers a dry run: it probes the downloaded video and reports the expected
+// output without uploading, deleting the original video or sending a file notification
+func (uc *ProcessVideoUseCase) estimateOutput(ctx context.Context, logger *zap.Logger, request domain.VideoProcess, videoPath string, result *domain.ProcessResult) error {
+	outputType := resolveOutputType(request)
+	if domain.IsPackagingOutput(outputType) && uc.packager == nil {
+		observability.RecordError("validation")
+		result.Error = fmt.Errorf("%s output is not enabled", outputType)
+		return rejected(uc.sendErrorMessage(ctx, result))
 	}
-	defer out.Close()
 
-	_, err = io.Copy(out, body)
+	info, err := uc.videoProcessor.ProbeVideo(ctx, videoPath)
 	if err != nil {
-		os.Remove(tempFile)
-		return "", fmt.Errorf("failed to save video: %w", err)
+		logger.Error("video probe failed", zap.Error(err))
+		observability.RecordFailure("processing", err)
+		result.Error = fmt.Errorf("failed to probe video: %w", err)
+		return uc.sendErrorMessage(ctx, result)
 	}
 
-	logger.Debug("video downloaded successfully", zap.String("path", tempFile))
-	return tempFile, nil
-}
+	estimate := domain.EstimateOutput(info, request)
+	result.Success = true
+	result.OutputType = outputType
+	result.Estimate = &estimate
 
-func (uc *ProcessVideoUseCase) uploadZip(ctx context.Context, zipPath, outputKey string) error {
-	logger := observability.GetLogger()
-	logger.Info("uploading ZIP to S3",
-		zap.String("bucket", uc.outputBucket),
-		zap.String("key", outputKey),
+	logger.Info("dry run completed",
+		zap.String("output_type", outputType),
+		zap.Int("estimated_count", estimate.Count),
+		zap.Int64("estimated_bytes", estimate.Bytes),
 	)
 
-	file, err := os.Open(zipPath)
-	if err != nil {
-		return fmt.Errorf("failed to open zip file: %w", err)
+	return uc.sendSuccessMessage(ctx, result, nil)
+}
+
+// executeBatch processes the videos of a batch one after the other under the job's process_id.
+// A failed video does not stop the others: it is reported in the result items, and the job only
+// fails when every video did. The original videos of a batch are kept
+func (uc *ProcessVideoUseCase) executeBatch(ctx context.Context, logger *zap.Logger, request domain.VideoProcess, jobID string, result *domain.ProcessResult) error {
+	outputType := resolveOutputType(request)
+	putOptions := uc.putOptions(request, outputType)
+	location := uc.outputLocation(request)
+	combined := request.BatchOutput == domain.BatchOutputCombined
+
+	var groups []domain.ArchiveGroup
+	defer func() {
+		for _, group := range groups {
+			for _, zipPath := range group.Zips {
+				os.Remove(zipPath)
+			}
+		}
+	}()
+
+	items := make([]domain.BatchItemResult, len(request.VideoKeys))
+	errs := make([]error, 0, len(items))
+	var uploadedKeys []string
+	var frameCount int
+	for i, item := range request.BatchItems() {
+		itemStart := time.Now()
+		itemLogger := logger.With(zap.String("video_key", item.VideoKey), zap.Int("batch_item", i+1))
+		items[i].VideoKey = item.VideoKey
+
+		zipPaths, frames, err := uc.processBatchItem(ctx, itemLogger, item, fmt.Sprintf("%s_%d", jobID, i+1), outputType)
+		if err == nil && combined {
+			groups = append(groups, domain.ArchiveGroup{Dir: domain.BatchItemDir(i, item.VideoKey), Zips: zipPaths})
+		}
+		if err == nil && !combined {
+			var keys []string
+			keys, err = uc.uploadZips(ctx, itemLogger, item, zipPaths, fmt.Sprintf("%s_%d", zipName(request, outputType), i+1), putOptions)
+			for _, zipPath := range zipPaths {
+				os.Remove(zipPath)
+			}
+			if err == nil {
+				items[i].FileKey = keys[0]
+				if len(keys) > 1 {
+					items[i].FileKeys = keys
+				}
+				uploadedKeys = append(uploadedKeys, keys...)
+			}
+		}
+		observability.RecordVideoProcessed(ctx, err == nil, time.Since(itemStart).Seconds(), frames)
+		if err != nil {
+			itemLogger.Warn("batch video failed", zap.Error(err))
+			items[i].Error = err.Error()
+			items[i].ErrorCode = domain.ErrorCode(err)
+			errs = append(errs, err)
+			continue
+		}
+		items[i].Success = true
+		items[i].Frames = frames
+		frameCount += frames
 	}
-	defer file.Close()
 
-	_, err = uc.storage.PutObject(ctx, uc.outputBucket, outputKey, file)
-	if err != nil {
-		observability.RecordS3Operation("put", false)
-		return fmt.Errorf("failed to put object to storage: %w", err)
+	result.OutputType = outputType
+	result.Items = items
+	result.BatchStatus = domain.BatchStatus(items)
+	if result.BatchStatus == domain.BatchStatusFailed {
+		result.Error = fmt.Errorf("all %d videos of the batch failed", len(items))
+		// Retrying only pays off when every video may succeed next time
+		if !slices.ContainsFunc(errs, func(err error) bool { return !domain.IsTransient(err) }) {
+			result.Error = domain.NewTransientError(result.Error)
+		}
+		return uc.sendErrorMessage(ctx, result)
+	}
+
+	if combined {
+		outputKeys, err := uc.combineBatch(ctx, logger, request, jobID, groups, outputType, putOptions)
+		if err != nil {
+			result.Error = err
+			return uc.sendErrorMessage(ctx, result)
+		}
+		result.FileKey = outputKeys[0]
+		if len(outputKeys) > 1 {
+			result.FileKeys = outputKeys
+		}
+		uploadedKeys = outputKeys
 	}
 
-	observability.RecordS3Operation("put", true)
+	result.Success = true
+	result.FileBucket = location.Bucket
+	logger.Info("batch processing completed",
+		zap.String("batch_status", result.BatchStatus),
+		zap.Int("videos", len(items)),
+		zap.Int("frames", frameCount),
+	)
+
+	if err := uc.sendSuccessMessage(ctx, result, nil); err != nil {
+		// Nothing will point the consumer to the outputs, so they are rolled back
+		if combined {
+			uc.removeOutputs(ctx, logger, location.Bucket, outputPrefix(location, request, outputType), uploadedKeys)
+		} else {
+			uc.removeOutputs(ctx, logger, location.Bucket, location.Key(zipName(request, outputType)+"_"), uploadedKeys)
+		}
+		return err
+	}
+	uc.scheduleExpiration(ctx, logger, request, outputType, location.Bucket, uploadedKeys)
 	return nil
 }
 
-func (uc *ProcessVideoUseCase) deleteOriginalVideo(ctx context.Context, request domain.VideoProcess) error {
-	logger := observability.GetLogger()
-	logger.Info("deleting original video from S3",
-		zap.String("bucket", request.VideoBucket),
-		zap.String("key", request.VideoKey),
-	)
+// processBatchItem downloads, scans and processes one video of a batch, returning its local zips
+func (uc *ProcessVideoUseCase) processBatchItem(ctx context.Context, logger *zap.Logger, item domain.VideoProcess, jobID, outputType string) ([]string, int, error) {
+	videoPath, videoObject, err := uc.fetchVideo(ctx, logger, item, jobID)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer os.Remove(videoPath)
+	item = uc.atVersion(item, videoObject)
+
+	if err := uc.checkMalware(ctx, logger, item, videoPath); err != nil {
+		return nil, 0, err
+	}
+	return uc.extractZips(ctx, logger, item, jobID, videoPath, outputType)
+}
 
-	err := uc.storage.DeleteObject(ctx, request.VideoBucket, request.VideoKey)
+// combineBatch merges the zips of the videos of a combined batch into frames_{process_id}.zip,
+// a folder per video, and uploads it
+func (uc *ProcessVideoUseCase) combineBatch(ctx context.Context, logger *zap.Logger, request domain.VideoProcess, jobID string, groups []domain.ArchiveGroup, outputType string, putOptions []domain.PutOption) ([]string, error) {
+	uc.stageStarted(ctx, request.ProcessID, domain.JobStageProcessing)
+	processCtx, cancel := withStageTimeout(ctx, uc.timeouts.Processing)
+	zipPath, err := uc.merger.MergeArchives(processCtx, jobID, groups)
+	cancel()
 	if err != nil {
-		observability.RecordS3Operation("delete", false)
-		return fmt.Errorf("failed to delete original video: %w", err)
+		err = stageError(ctx, processCtx, "processing", uc.timeouts.Processing, err)
+		uc.endStage(ctx, request.ProcessID, domain.JobStageProcessing, 0, err)
+		logger.Error("batch merge failed", zap.Error(err))
+		observability.RecordFailure("processing", err)
+		return nil, fmt.Errorf("failed to merge batch: %w", err)
 	}
+	defer os.Remove(zipPath)
 
-	observability.RecordS3Operation("delete", true)
-	return nil
+	var zipBytes int64
+	if stat, err := os.Stat(zipPath); err == nil {
+		observability.RecordFileSize("zip", stat.Size())
+		zipBytes = stat.Size()
+	}
+	uc.endStage(ctx, request.ProcessID, domain.JobStageProcessing, zipBytes, nil)
+	return uc.uploadZips(ctx, logger, request, []string{zipPath}, zipName(request, outputType), putOptions)
 }
 
-func (uc *ProcessVideoUseCase) sendSuccessMessage(ctx context.Context, result *domain.ProcessResult) error {
-	logger := observability.GetLogger()
-	logger.Info("sending success message",
-		zap.String("process_id", result.ProcessID),
-		zap.String("file_key", result.FileKey),
-	)
+// executeConcat downloads and scans the clips of a concat output, joins them and uploads
+// concat_{process_id}.mp4 to the job's output location. A failed clip fails the job, and the
+// clips are never deleted, as with a batch
+func (uc *ProcessVideoUseCase) executeConcat(ctx context.Context, logger *zap.Logger, request domain.VideoProcess, jobID string, result *domain.ProcessResult) error {
+	startTime := time.Now()
+	clips := request.BatchItems()
+	clipPaths := make([]string, 0, len(clips))
+	defer func() {
+		for _, clipPath := range clipPaths {
+			os.Remove(clipPath)
+		}
+	}()
 
-	msgData := result.ToSuccessMessage()
-	messageBody, err := json.Marshal(msgData)
-	if err != nil {
-		return fmt.Errorf("failed to marshal success message: %w", err)
+	for i, clip := range clips {
+		clipLogger := logger.With(zap.String("video_key", clip.VideoKey), zap.Int("clip", i+1))
+		clipPath, clipObject, err := uc.fetchVideo(ctx, clipLogger, clip, fmt.Sprintf("%s_%d", jobID, i+1))
+		if err == nil {
+			clipPaths = append(clipPaths, clipPath)
+			err = uc.checkMalware(ctx, clipLogger, uc.atVersion(clip, clipObject), clipPath)
+		}
+		if err != nil {
+			observability.RecordVideoProcessed(ctx, false, time.Since(startTime).Seconds(), 0)
+			result.Error = err
+			return uc.sendErrorMessage(ctx, result)
+		}
+	}
+
+	videoPath, err := uc.concatClips(ctx, logger, request, jobID, clipPaths)
+	if err == nil {
+		defer os.Remove(videoPath)
 	}
 
-	messageID, err := uc.message.SendMessage(ctx, uc.outputQueueURL, string(messageBody))
+	location := uc.outputLocation(request)
+	outputKey := location.Key(outputFileName(request, domain.OutputTypeConcat))
+	if err == nil {
+		err = uc.uploadOutput(ctx, logger, request, videoPath, outputKey)
+	}
 	if err != nil {
-		observability.RecordSQSOperation("send", false)
-		return fmt.Errorf("failed to send success message: %w", err)
+		observability.RecordVideoProcessed(ctx, false, time.Since(startTime).Seconds(), 0)
+		result.Error = err
+		return uc.sendErrorMessage(ctx, result)
 	}
 
-	observability.RecordSQSOperation("send", true)
-	logger.Debug("success message sent", zap.String("message_id", messageID))
-	return nil
-}
+	duration := time.Since(startTime)
+	observability.RecordVideoProcessed(ctx, true, duration.Seconds(), 0)
 
-func (uc *ProcessVideoUseCase) sendErrorMessage(ctx context.Context, result *domain.ProcessResult) error {
-	logger := observability.GetLogger()
-	logger.Error("sending error message",
-		zap.String("process_id", result.ProcessID),
-		zap.Error(result.Error),
+	result.Success = true
+	result.FileBucket = location.Bucket
+	result.FileKey = outputKey
+	result.OutputType = domain.OutputTypeConcat
+	logger.Info("video concat completed",
+		zap.Duration("total_duration", duration),
+		zap.Int("clips", len(clips)),
 	)
 
-	msgData := result.ToErrorMessage()
-	messageBody, err := json.Marshal(msgData)
+	if err := uc.sendSuccessMessage(ctx, result, nil); err != nil {
+		// Nothing will point the consumer to the output, so it is rolled back
+		uc.removeOutputs(ctx, logger, location.Bucket, outputPrefix(location, request, domain.OutputTypeConcat), []string{outputKey})
+		return err
+	}
+	uc.scheduleExpiration(ctx, logger, request, domain.OutputTypeConcat, location.Bucket, []string{outputKey})
+	return nil
+}
+
+// concatClips runs the processing stage of a concat output, returning the local video, which
+// the caller removes
+func (uc *ProcessVideoUseCase) concatClips(ctx context.Context, logger *zap.Logger, request domain.VideoProcess, jobID string, clipPaths []string) (string, error) {
+	uc.stageStarted(ctx, request.ProcessID, domain.JobStageProcessing)
+	processCtx, cancel := withStageTimeout(ctx, uc.timeouts.Processing)
+	videoPath, err := uc.concatenator.Concat(processCtx, jobID, clipPaths, request.Concat)
+	cancel()
 	if err != nil {
-		logger.Error("failed to marshal error message", zap.Error(err))
-		return fmt.Errorf("failed to marshal error message: %w", err)
+		err = stageError(ctx, processCtx, "processing", uc.timeouts.Processing, err)
+		uc.endStage(ctx, request.ProcessID, domain.JobStageProcessing, 0, err)
+		logger.Error("video concat failed", zap.Error(err))
+		observability.RecordFailure("processing", err)
+		return "", fmt.Errorf("failed to concat videos: %w", err)
 	}
 
-	messageID, err := uc.message.SendMessage(ctx, uc.outputQueueURL, string(messageBody))
+	var videoBytes int64
+	if stat, err := os.Stat(videoPath); err == nil {
+		videoBytes = stat.Size()
+	}
+	uc.endStage(ctx, request.ProcessID, domain.JobStageProcessing, videoBytes, nil)
+	logger.Info("videos concatenated successfully", zap.Int("clips", len(clipPaths)), zap.Int64("size_bytes", videoBytes))
+	return videoPath, nil
+}
+
+// editVideo runs the trim or loudnorm output on the video and uploads the result as
+// {type}_{process_id}.mp4, returning its key. The loudness measured by loudnorm goes to the result
+func (uc *ProcessVideoUseCase) editVideo(ctx context.Context, logger *zap.Logger, request domain.VideoProcess, jobID, videoPath, outputType string, result *domain.ProcessResult) (string, error) {
+	var editedPath string
+	var err error
+	uc.stageStarted(ctx, request.ProcessID, domain.JobStageProcessing)
+	processCtx, cancel := withStageTimeout(ctx, uc.timeouts.Processing)
+	if outputType == domain.OutputTypeLoudnorm {
+		var stats domain.LoudnessStats
+		editedPath, stats, err = uc.normalizer.Normalize(processCtx, jobID, videoPath, request.Loudness)
+		if err == nil {
+			result.Loudness = &stats
+		}
+	} else {
+		editedPath, err = uc.trimmer.Trim(processCtx, jobID, videoPath, request.Trim)
+	}
+	cancel()
 	if err != nil {
-		observability.RecordSQSOperation("send", false)
-		logger.Error("failed to send error message", zap.Error(err))
-		return fmt.Errorf("failed to send error message: %w", err)
+		err = stageError(ctx, processCtx, "processing", uc.timeouts.Processing, err)
+		uc.endStage(ctx, request.ProcessID, domain.JobStageProcessing, 0, err)
+		logger.Error("video processing failed", zap.Error(err))
+		observability.RecordFailure("processing", err)
+		return "", fmt.Errorf("failed to process video: %w", err)
 	}
+	defer os.Remove(editedPath)
 
-	observability.RecordSQSOperation("send", true)
-	logger.Debug("error message sent", zap.String("message_id", messageID))
+	var videoBytes int64
+	if stat, err := os.Stat(editedPath); err == nil {
+		videoBytes = stat.Size()
+	}
+	uc.endStage(ctx, request.ProcessID, domain.JobStageProcessing, videoBytes, nil)
+	logger.Info("video processed successfully", zap.String("output_type", outputType), zap.Int64("size_bytes", videoBytes))
+
+	outputKey := uc.outputLocation(request).Key(outputFileName(request, outputType))
+	if err := uc.uploadOutput(ctx, logger, request, editedPath, outputKey); err != nil {
+		return "", err
+	}
+	return outputKey, nil
+}
+
+// fingerprintVideo hashes sampled frames of the video and uploads the hashes as
+// fingerprint_{process_id}.json, returning its key
+func (uc *ProcessVideoUseCase) fingerprintVideo(ctx context.Context, logger *zap.Logger, request domain.VideoProcess, jobID, videoPath string) (string, error) {
+	uc.stageStarted(ctx, request.ProcessID, domain.JobStageProcessing)
+	processCtx, cancel := withStageTimeout(ctx, uc.timeouts.Processing)
+	fingerprintPath, err := uc.fingerprinter.Fingerprint(processCtx, jobID, videoPath, request.Fingerprint)
+	cancel()
+	if err != nil {
+		err = stageError(ctx, processCtx, "processing", uc.timeouts.Processing, err)
+		uc.endStage(ctx, request.ProcessID, domain.JobStageProcessing, 0, err)
+		logger.Error("video fingerprinting failed", zap.Error(err))
+		observability.RecordFailure("processing", err)
+		return "", fmt.Errorf("failed to fingerprint video: %w", err)
+	}
+	defer os.Remove(fingerprintPath)
+
+	var fingerprintBytes int64
+	if stat, err := os.Stat(fingerprintPath); err == nil {
+		fingerprintBytes = stat.Size()
+	}
+	uc.endStage(ctx, request.ProcessID, domain.JobStageProcessing, fingerprintBytes, nil)
+	logger.Info("video fingerprinted successfully", zap.Int64("size_bytes", fingerprintBytes))
+
+	outputKey := uc.outputLocation(request).Key(outputFileName(request, domain.OutputTypeFingerprint))
+	if err := uc.uploadOutput(ctx, logger, request, fingerprintPath, outputKey); err != nil {
+		return "", err
+	}
+	return outputKey, nil
+}
+
+// analyzeVision sends frames sampled from the video to the vision API, before the output is
+// produced so a failed analysis uploads nothing
+func (uc *ProcessVideoUseCase) analyzeVision(ctx context.Context, logger *zap.Logger, request domain.VideoProcess, jobID, videoPath string, config domain.VisionConfig) (domain.VisionReport, error) {
+	uc.stageStarted(ctx, request.ProcessID, domain.JobStageProcessing)
+	processCtx, cancel := withStageTimeout(ctx, uc.timeouts.Processing)
+	report, err := uc.vision.AnalyzeVision(processCtx, jobID, videoPath, config)
+	cancel()
+	if err != nil {
+		err = stageError(ctx, processCtx, "processing", uc.timeouts.Processing, err)
+		uc.endStage(ctx, request.ProcessID, domain.JobStageProcessing, 0, err)
+		logger.Error("vision analysis failed", zap.Error(err))
+		observability.RecordFailure("processing", err)
+		return domain.VisionReport{}, fmt.Errorf("failed to analyze frames: %w", err)
+	}
+
+	logger.Info("frames analyzed", zap.Int("frames", len(report.Frames)), zap.Bool("flagged", report.Flagged))
+	return report, nil
+}
+
+// deliverVision puts the vision analysis in the result, or uploads it as
+// vision_{process_id}.json next to the output when the tenant asked for an artifact, returning
+// its key
+func (uc *ProcessVideoUseCase) deliverVision(ctx context.Context, logger *zap.Logger, request domain.VideoProcess, config domain.VisionConfig, report domain.VisionReport, result *domain.ProcessResult) (string, error) {
+	if config.WithDefaults().Delivery != domain.VisionDeliveryArtifact {
+		result.Vision = &report
+		return "", nil
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode vision analysis: %w", err)
+	}
+	location := uc.outputLocation(request)
+	key := location.Key(fmt.Sprintf("vision_%s.json", request.OutputID()))
+	if _, err := uc.storage.PutObject(ctx, location.Bucket, key, bytes.NewReader(data), uc.putOptions(request, resolveOutputType(request))...); err != nil {
+		recordS3Operation(ctx, "put", false)
+		logger.Error("vision analysis upload failed", zap.Error(err))
+		return "", fmt.Errorf("failed to upload vision analysis: %w", domain.NewTransientError(err))
+	}
+	recordS3Operation(ctx, "put", true)
+
+	result.VisionKey = key
+	return key, nil
+}
+
+// startTranscription chains a transcription of the uploaded output, whose transcript is written
+// next to it as transcript_{process_id}.json. It runs once every output is uploaded, so a job
+// that fails does not leave a transcription running
+func (uc *ProcessVideoUseCase) startTranscription(ctx context.Context, logger *zap.Logger, request domain.VideoProcess, jobID, outputKey string, result *domain.ProcessResult) error {
+	location := uc.outputLocation(request)
+	ref, err := uc.transcription.StartTranscription(ctx, domain.TranscriptionRequest{
+		JobName:          domain.TranscriptionJobName(jobID),
+		ProcessID:        request.ProcessID,
+		MediaBucket:      location.Bucket,
+		MediaKey:         outputKey,
+		Language:         request.Transcription.Language,
+		TranscriptBucket: location.Bucket,
+		TranscriptKey:    location.Key(fmt.Sprintf("transcript_%s.json", request.OutputID())),
+	})
+	if err != nil {
+		logger.Error("transcription start failed", zap.Error(err))
+		observability.RecordError("transcription")
+		return fmt.Errorf("failed to start transcription: %w", domain.NewTransientError(err))
+	}
+
+	logger.Info("transcription started", zap.String("provider", ref.Provider), zap.String("transcription_job", ref.JobName))
+	result.Transcription = &ref
+	return nil
+}
+
+// scanBarcodes reads the QR codes and barcodes of sampled frames into the result, before the
+// frames are extracted so a failed scan uploads nothing
+func (uc *ProcessVideoUseCase) scanBarcodes(ctx context.Context, logger *zap.Logger, request domain.VideoProcess, jobID, videoPath string, result *domain.ProcessResult) error {
+	uc.stageStarted(ctx, request.ProcessID, domain.JobStageProcessing)
+	processCtx, cancel := withStageTimeout(ctx, uc.timeouts.Processing)
+	detections, err := uc.barcodes.ScanBarcodes(processCtx, jobID, videoPath, request.Barcodes)
+	cancel()
+	if err != nil {
+		err = stageError(ctx, processCtx, "processing", uc.timeouts.Processing, err)
+		uc.endStage(ctx, request.ProcessID, domain.JobStageProcessing, 0, err)
+		logger.Error("barcode scan failed", zap.Error(err))
+		observability.RecordFailure("processing", err)
+		return fmt.Errorf("failed to scan barcodes: %w", err)
+	}
+
+	if detections == nil {
+		detections = []domain.BarcodeDetection{}
+	}
+	logger.Info("barcodes scanned", zap.Int("barcodes", len(detections)))
+	result.Barcodes = detections
+	return nil
+}
+
+// analyzeVideo answers the qc output: it reports the black and silent intervals of the video in
+// the result, without uploading anything or deleting the original video
+func (uc *ProcessVideoUseCase) analyzeVideo(ctx context.Context, logger *zap.Logger, request domain.VideoProcess, videoPath string, startTime time.Time, result *domain.ProcessResult) error {
+	uc.stageStarted(ctx, request.ProcessID, domain.JobStageProcessing)
+	processCtx, cancel := withStageTimeout(ctx, uc.timeouts.Processing)
+	report, err := uc.analyzer.AnalyzeQuality(processCtx, videoPath, request.QC)
+	cancel()
+	if err != nil {
+		err = stageError(ctx, processCtx, "processing", uc.timeouts.Processing, err)
+		uc.endStage(ctx, request.ProcessID, domain.JobStageProcessing, 0, err)
+		logger.Error("video analysis failed", zap.Error(err))
+		observability.RecordFailure("processing", err)
+		observability.RecordVideoProcessed(ctx, false, time.Since(startTime).Seconds(), 0)
+		result.Error = fmt.Errorf("failed to analyze video: %w", err)
+		return uc.sendErrorMessage(ctx, result)
+	}
+	uc.endStage(ctx, request.ProcessID, domain.JobStageProcessing, 0, nil)
+
+	duration := time.Since(startTime)
+	observability.RecordVideoProcessed(ctx, true, duration.Seconds(), 0)
+	result.Success = true
+	result.OutputType = domain.OutputTypeQC
+	result.QC = &report
+
+	logger.Info("video analysis completed",
+		zap.Duration("total_duration", duration),
+		zap.Int("black_intervals", len(report.BlackIntervals)),
+		zap.Int("silence_intervals", len(report.SilenceIntervals)),
+		zap.Bool("truncated", report.Truncated),
+	)
+
+	return uc.sendSuccessMessage(ctx, result, nil)
+}
+
+// uploadOutput runs the upload stage of an output that is a single file
+func (uc *ProcessVideoUseCase) uploadOutput(ctx context.Context, logger *zap.Logger, request domain.VideoProcess, filePath, outputKey string) error {
+	uploadedBytes := observability.UsageFromContext(ctx).TransferBytes(domain.TransferStageUpload)
+	uc.stageStarted(ctx, request.ProcessID, domain.JobStageUpload)
+	uploadCtx, cancel := withStageTimeout(ctx, uc.timeouts.Upload)
+	err := uc.uploadFile(uploadCtx, request.ProcessID, uc.outputLocation(request).Bucket, filePath, outputKey, uc.putOptions(request, resolveOutputType(request)))
+	cancel()
+	if err != nil {
+		err = stageError(ctx, uploadCtx, "upload", uc.timeouts.Upload, err)
+		uc.endStage(ctx, request.ProcessID, domain.JobStageUpload, 0, err)
+		logger.Error("output upload failed", zap.Error(err))
+		observability.RecordError("upload")
+		return fmt.Errorf("failed to upload output: %w", domain.NewTransientError(err))
+	}
+
+	uc.endUpload(ctx, request.ProcessID, uploadedBytes)
+	logger.Info("output uploaded successfully", zap.String("output_key", outputKey))
+	return nil
+}
+
+// fetchVideo runs the download stage, returning the local copy of the video and the description
+// of the source object, nil when unknown
+func (uc *ProcessVideoUseCase) fetchVideo(ctx context.Context, logger *zap.Logger, request domain.VideoProcess, jobID string) (string, *domain.ObjectInfo, error) {
+	usage := observability.UsageFromContext(ctx)
+	downloaded := usage.TransferBytes(domain.TransferStageDownload)
+
+	supported := uc.supportsExtension(request)
+	if !supported && !uc.sniffVideos {
+		err := uc.unsupportedFormat(request)
+		logger.Error("video format not supported", zap.Error(err))
+		observability.RecordError("format")
+		return "", nil, err
+	}
+
+	uc.stageStarted(ctx, request.ProcessID, domain.JobStageDownload)
+	downloadCtx, cancel := withStageTimeout(ctx, uc.timeouts.Download)
+	videoPath, source, err := uc.downloadVideo(downloadCtx, request, jobID)
+	cancel()
+	if err != nil {
+		uc.endStage(ctx, request.ProcessID, domain.JobStageDownload, 0, err)
+		err = stageError(ctx, downloadCtx, "download", uc.timeouts.Download, err)
+		// A missing, empty, too large or modified video will be the same on the next attempt
+		if !errors.Is(err, domain.ErrObjectNotFound) && !slices.Contains(permanentDownloadErrors, domain.ErrorCode(err)) {
+			err = domain.NewTransientError(err)
+		}
+		logger.Error("video download failed", zap.Error(err))
+		observability.RecordError("download")
+		return "", nil, fmt.Errorf("failed to download video: %w", err)
+	}
+	uc.endStage(ctx, request.ProcessID, domain.JobStageDownload, usage.TransferBytes(domain.TransferStageDownload)-downloaded, nil)
+
+	// Record video file size
+	if stat, err := os.Stat(videoPath); err == nil {
+		observability.RecordFileSize("video", stat.Size())
+		logger.Info("video downloaded", zap.Int64("size_bytes", stat.Size()))
+	}
+
+	if !supported {
+		if !isVideoFile(videoPath) {
+			os.Remove(videoPath)
+			err := uc.unsupportedFormat(request)
+			logger.Error("video format not supported", zap.Error(err))
+			observability.RecordError("format")
+			return "", nil, err
+		}
+		logger.Info("video extension not supported, accepted by its content", zap.String("video_name", videoName(request)))
+	}
+	return videoPath, source, nil
+}
+
+// permanentDownloadErrors are the codes of download failures that are not retried
+var permanentDownloadErrors = []string{domain.ErrorCodeEmptyVideo, domain.ErrorCodeVideoTooLarge, domain.ErrorCodeSourceModified}
+
+// supportsExtension checks the video name against the extensions of its tenant, or the worker's
+func (uc *ProcessVideoUseCase) supportsExtension(request domain.VideoProcess) bool {
+	extensions := uc.videoExtensions
+	if tenant := uc.tenants.Lookup(request.TenantID); len(tenant.VideoExtensions) > 0 {
+		extensions = tenant.VideoExtensions
+	}
+	return extensions.Allows(videoName(request))
+}
+
+func (uc *ProcessVideoUseCase) unsupportedFormat(request domain.VideoProcess) error {
+	name := videoName(request)
+	if path.Ext(name) == "" {
+		return domain.NewCodedError(domain.ErrorCodeUnsupportedFormat, fmt.Errorf("video %s has no extension", name))
+	}
+	return domain.NewCodedError(domain.ErrorCodeUnsupportedFormat,
+		fmt.Errorf("video extension %s is not supported", path.Ext(name)))
+}
+
+// isVideoFile reports whether the file starts with the header of a video container
+func isVideoFile(videoPath string) bool {
+	file, err := os.Open(videoPath)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	header := make([]byte, domain.VideoSniffLength)
+	n, _ := io.ReadFull(file, header)
+	return domain.IsVideoContent(header[:n])
+}
+
+// checkMalware runs the scan stage when a scanner is configured
+func (uc *ProcessVideoUseCase) checkMalware(ctx context.Context, logger *zap.Logger, request domain.VideoProcess, videoPath string) error {
+	if uc.scanner == nil {
+		return nil
+	}
+	uc.stageStarted(ctx, request.ProcessID, domain.JobStageScan)
+	err := uc.scanVideo(ctx, request, videoPath)
+	uc.endStage(ctx, request.ProcessID, domain.JobStageScan, 0, err)
+	if err != nil {
+		logger.Error("malware scan failed", zap.Error(err))
+	}
+	return err
+}
+
+// processZip extracts frames (or sprite sheets) into a zip and uploads it, returning the zip
+// key, or one key per part (processed/frames_{id}.part1.zip, ...) when the zip was split. The
+// keys are under the job's output location, processed/ in the worker bucket by default
+func (uc *ProcessVideoUseCase) processZip(ctx context.Context, logger *zap.Logger, request domain.VideoProcess, jobID, videoPath, outputType string, putOptions []domain.PutOption) ([]string, int, error) {
+	zipPaths, frameCount, err := uc.extractZips(ctx, logger, request, jobID, videoPath, outputType)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() {
+		for _, zipPath := range zipPaths {
+			os.Remove(zipPath)
+		}
+	}()
+
+	outputKeys, err := uc.uploadZips(ctx, logger, request, zipPaths, zipName(request, outputType), putOptions)
+	if err != nil {
+		return nil, frameCount, err
+	}
+	return outputKeys, frameCount, nil
+}
+
+// extractZips runs the processing stage of a zip output, returning the local zip parts, which
+// the caller removes
+func (uc *ProcessVideoUseCase) extractZips(ctx context.Context, logger *zap.Logger, request domain.VideoProcess, jobID, videoPath, outputType string) ([]string, int, error) {
+	processRequest := domain.ProcessingRequest{
+		JobID:     jobID,
+		VideoPath: videoPath,
+		Options:   request.FrameOptions(),
+		Sprite:    request.Sprite,
+		OnProgress: func(progress domain.ProcessingProgress) {
+			logger.Debug("processing step finished", zap.String("step", progress.Step), zap.Int("frames", progress.Frames))
+		},
+	}
+
+	var result domain.ProcessingResult
+	var err error
+	uc.stageStarted(ctx, request.ProcessID, domain.JobStageProcessing)
+	processCtx, cancel := withStageTimeout(ctx, uc.timeouts.Processing)
+	if outputType == domain.OutputTypeSprite {
+		result, err = uc.videoProcessor.GenerateSpriteSheet(processCtx, processRequest)
+	} else {
+		result, err = uc.videoProcessor.ProcessVideo(processCtx, processRequest)
+	}
+	cancel()
+	zipPaths, frameCount := result.Artifacts, result.FrameCount
+	if err == nil && len(zipPaths) == 0 {
+		err = fmt.Errorf("no zip generated")
+	}
+	if err != nil {
+		err = stageError(ctx, processCtx, "processing", uc.timeouts.Processing, err)
+		uc.endStage(ctx, request.ProcessID, domain.JobStageProcessing, 0, err)
+		logger.Error("video processing failed", zap.Error(err))
+		if errors.Is(err, domain.ErrArchiveLimit) {
+			observability.RecordError("validation")
+		} else {
+			observability.RecordFailure("processing", err)
+		}
+		return nil, 0, fmt.Errorf("failed to process video: %w", err)
+	}
+
+	var zipBytes int64
+	for _, zipPath := range zipPaths {
+		// Record zip file size
+		if stat, err := os.Stat(zipPath); err == nil {
+			observability.RecordFileSize("zip", stat.Size())
+			logger.Info("zip created", zap.Int64("size_bytes", stat.Size()))
+			zipBytes += stat.Size()
+		}
+	}
+	uc.endStage(ctx, request.ProcessID, domain.JobStageProcessing, zipBytes, nil)
+	fields := []zap.Field{zap.Int("frames_extracted", frameCount), zap.Int("parts", len(zipPaths))}
+	if result.Video.Codec != "" {
+		fields = append(fields, zap.String("codec", result.Video.Codec), zap.Float64("duration_seconds", result.Video.DurationSeconds))
+	}
+	logger.Info("video processed successfully", fields...)
+	return zipPaths, frameCount, nil
+}
+
+// uploadZips runs the upload stage of a zip output: the zips go to the job's output location
+// as name.zip, or name.part1.zip, ... when there are several (.tar.gz with that encoding). On
+// failure the parts already uploaded are removed
+func (uc *ProcessVideoUseCase) uploadZips(ctx context.Context, logger *zap.Logger, request domain.VideoProcess, zipPaths []string, name string, putOptions []domain.PutOption) ([]string, error) {
+	// The upload stage covers every part
+	uploadedBytes := observability.UsageFromContext(ctx).TransferBytes(domain.TransferStageUpload)
+	uc.stageStarted(ctx, request.ProcessID, domain.JobStageUpload)
+	uploadCtx, cancel := withStageTimeout(ctx, uc.timeouts.Upload)
+	defer cancel()
+
+	location := uc.outputLocation(request)
+	extension := request.Archive.Extension()
+	outputKeys := make([]string, len(zipPaths))
+	for i, zipPath := range zipPaths {
+
+		outputKeys[i] = location.Key(name + extension)
+		if len(zipPaths) > 1 {
+			outputKeys[i] = location.Key(fmt.Sprintf("%s.part%d%s", name, i+1, extension))
+		}
+		if err := uc.uploadFile(uploadCtx, request.ProcessID, location.Bucket, zipPath, outputKeys[i], putOptions); err != nil {
+			err = stageError(ctx, uploadCtx, "upload", uc.timeouts.Upload, err)
+			uc.endStage(ctx, request.ProcessID, domain.JobStageUpload, 0, err)
+			logger.Error("zip upload failed", zap.Error(err))
+			observability.RecordError("upload")
+			uc.removeOutputs(ctx, logger, location.Bucket, location.Key(name+"."), outputKeys[:i])
+			return nil, fmt.Errorf("failed to upload zip: %w", domain.NewTransientError(err))
+		}
+
+		logger.Info("zip uploaded successfully", zap.String("output_key", outputKeys[i]))
+	}
+	uc.endUpload(ctx, request.ProcessID, uploadedBytes)
+	return outputKeys, nil
+}
+
+// packageVideo builds the HLS/DASH tree and uploads it under processed/{process_id}/{type}/,
+// returning the key of the master playlist (or DASH manifest) and of every uploaded file
+func (uc *ProcessVideoUseCase) packageVideo(ctx context.Context, logger *zap.Logger, request domain.VideoProcess, jobID, videoPath, outputType string, putOptions []domain.PutOption) (string, []string, error) {
+	if uc.packager == nil {
+		observability.RecordError("validation")
+		return "", nil, fmt.Errorf("%s output is not enabled", outputType)
+	}
+
+	uc.stageStarted(ctx, request.ProcessID, domain.JobStageProcessing)
+	processCtx, cancel := withStageTimeout(ctx, uc.timeouts.Processing)
+	outputDir, err := uc.packager.Package(processCtx, jobID, videoPath, outputType, request.Packaging, request.FrameOptions())
+	cancel()
+	if err != nil {
+		err = stageError(ctx, processCtx, "processing", uc.timeouts.Processing, err)
+		uc.endStage(ctx, request.ProcessID, domain.JobStageProcessing, 0, err)
+		logger.Error("video packaging failed", zap.Error(err))
+		observability.RecordFailure("processing", err)
+		return "", nil, fmt.Errorf("failed to package video: %w", err)
+	}
+	defer os.RemoveAll(outputDir)
+
+	uc.endStage(ctx, request.ProcessID, domain.JobStageProcessing, 0, nil)
+	logger.Info("video packaged successfully", zap.String("output_type", outputType))
+
+	location := uc.outputLocation(request)
+	prefix := location.Key(request.OutputID(), outputType)
+	uploadedBytes := observability.UsageFromContext(ctx).TransferBytes(domain.TransferStageUpload)
+	uc.stageStarted(ctx, request.ProcessID, domain.JobStageUpload)
+	uploadCtx, cancel := withStageTimeout(ctx, uc.timeouts.Upload)
+	uploaded, err := uc.uploadDirectory(uploadCtx, request.ProcessID, location.Bucket, outputDir, prefix, putOptions)
+	cancel()
+	if err != nil {
+		err = stageError(ctx, uploadCtx, "upload", uc.timeouts.Upload, err)
+		uc.endStage(ctx, request.ProcessID, domain.JobStageUpload, 0, err)
+		logger.Error("package upload failed", zap.Error(err))
+		observability.RecordError("upload")
+		uc.removeOutputs(ctx, logger, location.Bucket, outputPrefix(location, request, outputType), uploaded)
+		return "", nil, fmt.Errorf("failed to upload package: %w", domain.NewTransientError(err))
+	}
+
+	uc.endUpload(ctx, request.ProcessID, uploadedBytes)
+
+	outputKey := path.Join(prefix, domain.PackagingManifest(outputType))
+	logger.Info("package uploaded successfully",
+		zap.String("output_key", outputKey),
+		zap.Int("files", len(uploaded)),
+	)
+	return outputKey, uploaded, nil
+}
+
+func (uc *ProcessVideoUseCase) validateRequest(request domain.VideoProcess) error {
+	if err := domain.ValidateProcessID(request.ProcessID); err != nil {
+		return err
+	}
+	if err := request.ValidateConcat(); err != nil {
+		return err
+	}
+	if err := request.ValidateTrim(); err != nil {
+		return err
+	}
+	if err := request.ValidateLoudness(); err != nil {
+		return err
+	}
+	if err := request.ValidateQC(); err != nil {
+		return err
+	}
+	if err := request.ValidateFingerprint(); err != nil {
+		return err
+	}
+	if err := request.ValidateBarcodes(); err != nil {
+		return err
+	}
+	if err := request.ValidateTranscription(); err != nil {
+		return err
+	}
+	if request.Transcription.Enabled && uc.transcription == nil {
+		return fmt.Errorf("transcription is not enabled")
+	}
+	if request.Barcodes.Scan && uc.barcodes == nil {
+		return fmt.Errorf("barcode scanning is not enabled")
+	}
+	if uc.tenants.Lookup(request.TenantID).Vision != nil && uc.vision == nil {
+		return fmt.Errorf("vision analysis is not enabled")
+	}
+	// A dry run stops before the scan
+	if request.Barcodes.Scan && (uc.dryRun || request.DryRun) {
+		return fmt.Errorf("dry runs are not supported for barcode scanning")
+	}
+	if err := request.ValidateBatch(); err != nil {
+		return err
+	}
+	if !request.Source.IsZero() && (request.VideoURL != "" || len(request.VideoKeys) > 0) {
+		return fmt.Errorf("video_etag and video_version_id only apply to video_key")
+	}
+	if request.IsBatch() {
+		if err := uc.checkBatch(request); err != nil {
+			return err
+		}
+	} else if request.IsConcat() {
+		if err := uc.checkConcat(request); err != nil {
+			return err
+		}
+	} else if request.VideoURL != "" {
+		if request.VideoBucket != "" || request.VideoKey != "" {
+			return fmt.Errorf("video_url cannot be combined with video_bucket and video_key")
+		}
+		if err := uc.checkURLSource(request); err != nil {
+			return err
+		}
+	} else {
+		if request.VideoBucket == "" {
+			return fmt.Errorf("video_bucket is required")
+		}
+		if request.VideoKey == "" {
+			return fmt.Errorf("video_key is required")
+		}
+		if err := uc.checkSource(request); err != nil {
+			return err
+		}
+	}
+	if err := uc.checkDestinations(request); err != nil {
+		return err
+	}
+	if err := domain.ValidateOutputBucket(request.OutputBucket); err != nil {
+		return err
+	}
+	if err := domain.ValidateOutputPrefix(request.OutputPrefix); err != nil {
+		return err
+	}
+	if err := uc.checkOutput(request); err != nil {
+		return err
+	}
+	if err := domain.ValidateStorageClass(request.StorageClass); err != nil {
+		return err
+	}
+	if err := domain.ValidateOutputType(request.OutputType); err != nil {
+		return err
+	}
+	if err := request.Sprite.Validate(); err != nil {
+		return err
+	}
+	if err := request.Packaging.Validate(); err != nil {
+		return err
+	}
+	if err := request.Subtitles.Validate(); err != nil {
+		return err
+	}
+	if err := domain.ValidateToneMap(request.ToneMap); err != nil {
+		return err
+	}
+	if err := domain.ValidateTimeWindows(request.Windows); err != nil {
+		return err
+	}
+	if err := domain.ValidateFPS(request.FPS); err != nil {
+		return err
+	}
+	if err := domain.ValidateMaxFrames(request.MaxFrames); err != nil {
+		return err
+	}
+	if err := request.Sharpness.Validate(); err != nil {
+		return err
+	}
+	if err := request.Colors.Validate(); err != nil {
+		return err
+	}
+	if err := request.OCR.Validate(); err != nil {
+		return err
+	}
+	if err := request.Anonymize.Validate(); err != nil {
+		return err
+	}
+	// Only the frames are blurred; the other outputs would publish the regions in the clear
+	if request.Anonymize.Enabled() && resolveOutputType(request) != domain.OutputTypeFrames {
+		return fmt.Errorf("anonymize only applies to the %s output", domain.OutputTypeFrames)
+	}
+	if err := request.Image.Validate(); err != nil {
+		return err
+	}
+	if err := request.Archive.Validate(); err != nil {
+		return err
+	}
+	if err := request.FrameName.Validate(); err != nil {
+		return err
+	}
+	if err := checkAccept(request); err != nil {
+		return err
+	}
+	if len(request.Windows) > 0 && domain.IsPackagingOutput(request.OutputType) {
+		return fmt.Errorf("time windows are not supported for %s output", request.OutputType)
+	}
+	if (request.OutputType == domain.OutputTypeTrim && uc.trimmer == nil) ||
+		(request.OutputType == domain.OutputTypeLoudnorm && uc.normalizer == nil) ||
+		(request.OutputType == domain.OutputTypeQC && uc.analyzer == nil) ||
+		(request.OutputType == domain.OutputTypeFingerprint && uc.fingerprinter == nil) {
+		return fmt.Errorf("%s output is not enabled", request.OutputType)
+	}
+	// A qc output is already a report, and the edited videos and fingerprints cannot be
+	// estimated from a probe
+	if (domain.IsVideoOutput(request.OutputType) || request.OutputType == domain.OutputTypeQC || request.OutputType == domain.OutputTypeFingerprint) && (uc.dryRun || request.DryRun) {
+		return fmt.Errorf("dry runs are not supported for %s output", request.OutputType)
+	}
+
+	return nil
+}
+
+// checkAccept checks the accept options apply to the output and do not contradict the
+// archive encoding and image format chosen by the request
+func checkAccept(request domain.VideoProcess) error {
+	outputType := resolveOutputType(request)
+	if request.Archive.TarGz() && request.IsBatch() && request.BatchOutput == domain.BatchOutputCombined {
+		return fmt.Errorf("combined batches only support the %s encoding", domain.ArchiveEncodingZip)
+	}
+	if request.Accept.IsZero() {
+		return nil
+	}
+	if outputType != domain.OutputTypeFrames && outputType != domain.OutputTypeSprite {
+		return fmt.Errorf("accept only applies to the %s and %s outputs", domain.OutputTypeFrames, domain.OutputTypeSprite)
+	}
+	if len(request.Accept.Encodings) > 0 && request.Archive.Encoding != "" {
+		return fmt.Errorf("archive.encoding cannot be combined with accept.encodings")
+	}
+	if len(request.Accept.ImageFormats) > 0 && request.Image.Format != "" {
+		return fmt.Errorf("image.format cannot be combined with accept.image_formats")
+	}
+	return nil
+}
+
+// negotiateOutput applies the first accepted encoding and image format the output supports to
+// the request: combined batches are merged as zips, and sprite sheets are always JPEG
+func negotiateOutput(request domain.VideoProcess) (domain.VideoProcess, *domain.NegotiatedOutput, error) {
+	if request.Accept.IsZero() {
+		return request, nil, nil
+	}
+
+	encodings := domain.SupportedArchiveEncodings
+	if request.IsBatch() && request.BatchOutput == domain.BatchOutputCombined {
+		encodings = []string{domain.ArchiveEncodingZip}
+	}
+	imageFormats := domain.SupportedImageFormats
+	if resolveOutputType(request) == domain.OutputTypeSprite {
+		imageFormats = []string{domain.ImageFormatJPEG}
+	}
+
+	negotiated, err := request.Accept.Negotiate(encodings, imageFormats)
+	if err != nil {
+		return request, nil, err
+	}
+	if negotiated.Encoding != "" {
+		request.Archive.Encoding = negotiated.Encoding
+	}
+	if negotiated.ImageFormat != "" && resolveOutputType(request) == domain.OutputTypeFrames {
+		request.Image.Format = negotiated.ImageFormat
+	}
+	return request, &negotiated, nil
+}
+
+// checkBatch checks every video of a batch like a single one, and the batch options against
+// what the worker supports
+func (uc *ProcessVideoUseCase) checkBatch(request domain.VideoProcess) error {
+	if request.VideoBucket == "" {
+		return fmt.Errorf("video_bucket is required")
+	}
+	for _, item := range request.BatchItems() {
+		if err := uc.checkSource(item); err != nil {
+			return err
+		}
+	}
+	if uc.dryRun || request.DryRun {
+		return fmt.Errorf("dry runs are not supported for batches")
+	}
+	if request.BatchOutput == domain.BatchOutputCombined && uc.merger == nil {
+		return fmt.Errorf("%s batch output is not enabled", domain.BatchOutputCombined)
+	}
+	return nil
+}
+
+// checkConcat checks every clip of a concat output like a single video
+func (uc *ProcessVideoUseCase) checkConcat(request domain.VideoProcess) error {
+	if request.VideoBucket == "" {
+		return fmt.Errorf("video_bucket is required")
+	}
+	for _, clip := range request.BatchItems() {
+		if err := uc.checkSource(clip); err != nil {
+			return err
+		}
+	}
+	if uc.concatenator == nil {
+		return fmt.Errorf("%s output is not enabled", domain.OutputTypeConcat)
+	}
+	return nil
+}
+
+// checkSource requires the video to pass both the worker allowlist and the tenant's own; the
+// tenant list can only narrow the worker one, as the tenant_id comes from the message too
+func (uc *ProcessVideoUseCase) checkSource(request domain.VideoProcess) error {
+	tenant := uc.tenants.Lookup(request.TenantID)
+	if uc.allowedSources.Allows(request.VideoBucket, request.VideoKey) &&
+		tenant.AllowedSources.Allows(request.VideoBucket, request.VideoKey) {
+		return nil
+	}
+	return domain.NewCodedError(domain.ErrorCodeSourceNotAllowed,
+		fmt.Errorf("video source %s/%s is not allowed", request.VideoBucket, request.VideoKey))
+}
+
+// checkURLSource requires video_url to be enabled and on an allowed host. Tenants restricted
+// to their buckets cannot use video_url, which would bypass the restriction
+func (uc *ProcessVideoUseCase) checkURLSource(request domain.VideoProcess) error {
+	if uc.urlSource == nil {
+		return fmt.Errorf("video_url is not enabled")
+	}
+	tenant := uc.tenants.Lookup(request.TenantID)
+	if len(tenant.AllowedSources) > 0 || !uc.urlSource.Allows(request.VideoURL) {
+		return domain.NewCodedError(domain.ErrorCodeSourceNotAllowed,
+			fmt.Errorf("video source %s is not allowed", urlHost(request.VideoURL)))
+	}
+	return nil
+}
+
+// urlHost identifies a video_url in logs and errors without its query, which holds the
+// signature of a presigned URL
+func urlHost(videoURL string) string {
+	parsed, err := url.Parse(videoURL)
+	if err != nil {
+		return "invalid url"
+	}
+	return parsed.Host
+}
+
+// checkDestinations requires the destinations picked by the message to be allowed by the worker
+// or the tenant, so a message cannot send results, and the links in them, anywhere
+func (uc *ProcessVideoUseCase) checkDestinations(request domain.VideoProcess) error {
+	tenant := uc.tenants.Lookup(request.TenantID)
+	for _, destination := range request.ResultDestinations {
+		if uc.resultTransports[destination.Type] == nil {
+			return fmt.Errorf("result destination type %s is not enabled", destination.Type)
+		}
+		if !uc.allowedDestinations.Contains(destination) && !tenant.ResultDestinations.Contains(destination) {
+			return fmt.Errorf("result destination %s is not allowed", destination)
+		}
+	}
+	return nil
+}
+
+// checkOutput requires an output location picked by the message to be allowed by the worker or
+// the tenant. Unlike the sources, an empty allowlist allows nothing: the worker bucket also
+// holds the job markers and timelines
+func (uc *ProcessVideoUseCase) checkOutput(request domain.VideoProcess) error {
+	if request.OutputBucket == "" && request.OutputPrefix == "" {
+		return nil
+	}
+	location := uc.outputLocation(request)
+	tenant := uc.tenants.Lookup(request.TenantID)
+	for _, allowlist := range []domain.SourceAllowlist{uc.allowedOutputs, tenant.AllowedOutputs} {
+		if len(allowlist) > 0 && allowlist.Allows(location.Bucket, location.Prefix+"/") {
+			return nil
+		}
+	}
+	return domain.NewCodedError(domain.ErrorCodeOutputNotAllowed,
+		fmt.Errorf("output location %s/%s/ is not allowed", location.Bucket, location.Prefix))
+}
+
+// resolveStorageClass picks the storage class from the message, then the tenant, then the worker default
+func (uc *ProcessVideoUseCase) resolveStorageClass(request domain.VideoProcess) string {
+	if request.StorageClass != "" {
+		return request.StorageClass
+	}
+	if tenant := uc.tenants.Lookup(request.TenantID); tenant.StorageClass != "" {
+		return tenant.StorageClass
+	}
+	return uc.storageClass
+}
+
+// putOptions are the attributes of the outputs of the job: the storage class and, for tenants
+// with a retention, the tags with the expiry date
+func (uc *ProcessVideoUseCase) putOptions(request domain.VideoProcess, outputType string) []domain.PutOption {
+	options := []domain.PutOption{domain.WithStorageClass(uc.resolveStorageClass(request))}
+	if retention := uc.tenants.Lookup(request.TenantID).Retention; retention != nil && retention.Applies(outputType) {
+		options = append(options, domain.WithTags(retention.Tags(time.Now())))
+	}
+	return options
+}
+
+// resolveOutputType defaults to the frames zip when the message does not ask for another output
+func resolveOutputType(request domain.VideoProcess) string {
+	if request.OutputType == "" {
+		return domain.OutputTypeFrames
+	}
+	return request.OutputType
+}
+
+// outputPrefix covers every object a job can write: the zip and its parts
+// (processed/frames_{id}.zip, processed/frames_{id}.part1.zip, ...), the package tree, the
+// edited video or the fingerprint
+func outputPrefix(location domain.OutputLocation, request domain.VideoProcess, outputType string) string {
+	if domain.IsPackagingOutput(outputType) {
+		return location.Key(request.OutputID(), outputType) + "/"
+	}
+	if domain.IsVideoOutput(outputType) || outputType == domain.OutputTypeFingerprint {
+		return location.Key(outputFileName(request, outputType))
+	}
+	return location.Key(zipName(request, outputType) + ".")
+}
+
+// zipName is the name of the zip of a job before the extension, e.g. frames_{process_id}, or
+// frames_{process_id}_v2 for a reprocess
+func zipName(request domain.VideoProcess, outputType string) string {
+	return fmt.Sprintf("%s_%s", outputKeyPrefix(outputType), request.OutputID())
+}
+
+// outputFileName is the object name of an output that is a single file, e.g.
+// trim_{process_id}.mp4 or fingerprint_{process_id}.json
+func outputFileName(request domain.VideoProcess, outputType string) string {
+	if outputType == domain.OutputTypeFingerprint {
+		return fmt.Sprintf("%s_%s.json", outputType, request.OutputID())
+	}
+	return fmt.Sprintf("%s_%s.mp4", outputType, request.OutputID())
+}
+
+// outputLocation is where the job writes its outputs: the bucket and prefix of the message,
+// each defaulting to the worker's
+func (uc *ProcessVideoUseCase) outputLocation(request domain.VideoProcess) domain.OutputLocation {
+	location := domain.OutputLocation{Bucket: uc.outputBucket, Prefix: domain.DefaultOutputPrefix}
+	if request.OutputBucket != "" {
+		location.Bucket = request.OutputBucket
+	}
+	if request.OutputPrefix != "" {
+		location.Prefix = strings.TrimSuffix(request.OutputPrefix, "/")
+	}
+	return location
+}
+
+// outputKeyPrefix names the zip after its contents, keeping processed/frames_{id}.zip for frames
+func outputKeyPrefix(outputType string) string {
+	if outputType == domain.OutputTypeSprite {
+		return "sprites"
+	}
+	return "frames"
+}
+
+// newJobID keys the temporary artifacts of one execution; the random suffix keeps
+// redelivered messages with the same process_id from sharing files
+func newJobID(processID string) (string, error) {
+	suffix := make([]byte, 8)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", fmt.Errorf("failed to generate job id: %w", err)
+	}
+	return processID + "_" + hex.EncodeToString(suffix), nil
+}
+
+// downloadVideo saves the source video to a temp file, returning it with the description of the
+// object read before the download, nil when unknown
+func (uc *ProcessVideoUseCase) downloadVideo(ctx context.Context, request domain.VideoProcess, jobID string) (string, *domain.ObjectInfo, error) {
+	source, err := uc.headVideo(ctx, request)
+	if err != nil {
+		return "", nil, err
+	}
+	size := int64(-1)
+	if source != nil {
+		size = source.SizeBytes
+		if size == 0 {
+			return "", nil, emptyVideo(request)
+		}
+		if err := domain.CheckVideoSize(size, uc.maxVideoBytes); err != nil {
+			return "", nil, err
+		}
+	}
+
+	body, err := uc.openVideo(ctx, uc.atVersion(request, source))
+	if err != nil {
+		return "", nil, err
+	}
+	body = uc.resumeDownload(ctx, uc.atVersion(request, source), source, body)
+	defer body.Close()
+
+	out, err := uc.createVideoFile(jobID, fmt.Sprintf("video_%s%s", filepath.Base(jobID), filepath.Ext(videoName(request))), max(size, 0))
+	if err != nil {
+		return "", nil, err
+	}
+	defer out.Close()
+
+	// Without the size (video_url), the limit is enforced while copying and download progress
+	// has no total
+	var reader io.Reader = uc.trackTransfer(ctx, request.ProcessID, domain.TransferStageDownload, body, max(size, 0))
+	if uc.maxVideoBytes > 0 {
+		reader = io.LimitReader(reader, uc.maxVideoBytes+1)
+	}
+	written, err := io.Copy(out, reader)
+	// A video spilled from memory to the disk while copying is on the disk now
+	tempFile := out.Name()
+	if err != nil {
+		os.Remove(tempFile)
+		return "", nil, fmt.Errorf("failed to save video: %w", err)
+	}
+	if err := domain.CheckVideoSize(written, uc.maxVideoBytes); err != nil {
+		os.Remove(tempFile)
+		return "", nil, err
+	}
+	// Caught here, an empty or cut download would otherwise fail in FFmpeg with errors such
+	// as "moov atom not found"
+	if written == 0 {
+		os.Remove(tempFile)
+		return "", nil, emptyVideo(request)
+	}
+	if size > 0 && written != size {
+		os.Remove(tempFile)
+		return "", nil, domain.NewCodedError(domain.ErrorCodeTruncatedVideo,
+			fmt.Errorf("video download is truncated: got %d of %d bytes", written, size))
+	}
+
+	observability.FromContext(ctx).Debug("video downloaded successfully", zap.String("path", tempFile))
+	return tempFile, source, nil
+}
+
+// createVideoFile creates the file a video is downloaded to, in the work dir when there is one
+func (uc *ProcessVideoUseCase) createVideoFile(jobID, name string, sizeBytes int64) (port.VideoFile, error) {
+	if uc.workDir != nil {
+		return uc.workDir.CreateVideo(jobID, name, sizeBytes)
+	}
+
+	tempDir := "/tmp/video-processor"
+	if err := os.MkdirAll(tempDir, 0777); err != nil {
+		return nil, fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	out, err := os.Create(filepath.Join(tempDir, name))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create temp file: %w", err)
+	}
+	return out, nil
+}
+
+// headVideo describes the source video in the bucket. It returns nil for video_url, or when
+// the storage does not answer, in which case the download is not checked against the size
+func (uc *ProcessVideoUseCase) headVideo(ctx context.Context, request domain.VideoProcess) (*domain.ObjectInfo, error) {
+	if request.VideoURL != "" {
+		return nil, nil
+	}
+
+	source, err := uc.storage.HeadObject(ctx, request.VideoBucket, request.VideoKey)
+	recordS3Operation(ctx, "head", err == nil)
+	if errors.Is(err, domain.ErrObjectNotFound) {
+		return nil, fmt.Errorf("failed to get object from storage: %w", err)
+	}
+	if err != nil {
+		observability.FromContext(ctx).Warn("failed to describe video, download not checked", zap.Error(err))
+		return nil, nil
+	}
+	if !request.Source.Matches(source) {
+		return nil, sourceModified(request)
+	}
+	return &source, nil
+}
+
+// atVersion pins the request to the version of the video described in object, with versioned
+// sources, so the read and the deletes that follow act on that version
+func (uc *ProcessVideoUseCase) atVersion(request domain.VideoProcess, object *domain.ObjectInfo) domain.VideoProcess {
+	if uc.versioned {
+		request.Source = request.Source.WithVersion(object)
+	}
+	return request
+}
+
+// sourceModified reports a video that is no longer the version pinned by the request
+func sourceModified(request domain.VideoProcess) error {
+	return domain.NewCodedError(domain.ErrorCodeSourceModified,
+		fmt.Errorf("video %s/%s changed since the job was enqueued", request.VideoBucket, request.VideoKey))
+}
+
+func emptyVideo(request domain.VideoProcess) error {
+	return domain.NewCodedError(domain.ErrorCodeEmptyVideo, fmt.Errorf("video %s is empty", videoName(request)))
+}
+
+// openVideo reads the source video from video_url or from the bucket
+func (uc *ProcessVideoUseCase) openVideo(ctx context.Context, request domain.VideoProcess) (io.ReadCloser, error) {
+	logger := observability.FromContext(ctx)
+
+	if request.VideoURL != "" {
+		logger.Info("downloading video from url", zap.String("host", urlHost(request.VideoURL)))
+		body, err := uc.urlSource.GetURL(ctx, request.VideoURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get video_url: %w", err)
+		}
+		return body, nil
+	}
+
+	logger.Info("downloading video from S3",
+		zap.String("bucket", request.VideoBucket),
+		zap.String("key", request.VideoKey),
+	)
+	var body io.ReadCloser
+	var err error
+	if request.Source.IsZero() {
+		body, err = uc.storage.GetObject(ctx, request.VideoBucket, request.VideoKey)
+	} else {
+		// The read is conditional too, in case the video changed after headVideo
+		body, err = uc.storage.GetObjectIf(ctx, request.VideoBucket, request.VideoKey, request.Source)
+	}
+	if errors.Is(err, domain.ErrObjectModified) {
+		recordS3Operation(ctx, "get", false)
+		return nil, sourceModified(request)
+	}
+	if err != nil {
+		recordS3Operation(ctx, "get", false)
+		return nil, fmt.Errorf("failed to get object from storage: %w", err)
+	}
+	recordS3Operation(ctx, "get", true)
+	return body, nil
+}
+
+// resumeDownload lets a download cut mid-stream continue from the last byte received. The rest
+// is read only while the object keeps the ETag described before the download, so a video
+// replaced meanwhile is not stitched to the old one; videos from video_url, or not described,
+// are downloaded again by the next attempt of the job
+func (uc *ProcessVideoUseCase) resumeDownload(ctx context.Context, request domain.VideoProcess, source *domain.ObjectInfo, body io.ReadCloser) io.ReadCloser {
+	if uc.downloadResumes <= 0 || request.VideoURL != "" || source == nil || source.ETag == "" {
+		return body
+	}
+
+	condition := domain.ObjectCondition{ETag: source.ETag, VersionID: request.Source.VersionID}
+	return transfer.NewResumingReader(ctx, body, func(ctx context.Context, offset int64, cause error) (io.ReadCloser, error) {
+		observability.FromContext(ctx).Warn("video download interrupted, resuming",
+			zap.Int64("offset", offset),
+			zap.Int64("size_bytes", source.SizeBytes),
+			zap.Error(cause),
+		)
+		resumed, err := uc.storage.GetObjectIf(ctx, request.VideoBucket, request.VideoKey, condition, domain.WithRange(offset, 0))
+		if errors.Is(err, domain.ErrObjectModified) {
+			recordS3Operation(ctx, "get", false)
+			return nil, sourceModified(request)
+		}
+		if err != nil {
+			recordS3Operation(ctx, "get", false)
+			return nil, fmt.Errorf("failed to resume video download at byte %d: %w", offset, err)
+		}
+		recordS3Operation(ctx, "get", true)
+		return resumed, nil
+	}, uc.downloadResumes)
+}
+
+// videoName is the file name of the source video, from the key or the URL path
+func videoName(request domain.VideoProcess) string {
+	if request.VideoURL != "" {
+		if parsed, err := url.Parse(request.VideoURL); err == nil {
+			return path.Base(parsed.Path)
+		}
+	}
+	return path.Base(request.VideoKey)
+}
+
+// scanVideo runs the malware scanner on the downloaded file and quarantines it when infected
+func (uc *ProcessVideoUseCase) scanVideo(ctx context.Context, request domain.VideoProcess, videoPath string) error {
+	logger := observability.FromContext(ctx)
+
+	infected, signature, err := uc.scanner.ScanFile(ctx, videoPath)
+	if err != nil {
+		observability.RecordError("scan")
+		return fmt.Errorf("failed to scan video: %w", err)
+	}
+
+	if !infected {
+		logger.Debug("malware scan clean")
+		return nil
+	}
+
+	observability.RecordError("malware")
+	logger.Warn("malware detected in video", zap.String("signature", signature))
+
+	err = domain.NewCodedError(domain.ErrorCodeMalwareDetected, fmt.Errorf("malware detected: %s", signature))
+	location, quarantineErr := uc.quarantineVideo(ctx, request, videoPath, domain.ErrorCodeMalwareDetected)
+	if quarantineErr != nil {
+		logger.Error("failed to quarantine infected video", zap.Error(quarantineErr))
+		return err
+	}
+	return domain.NewQuarantinedError(err, location)
+}
+
+// quarantineInput moves the video of a single video job failing with err to the quarantine, when
+// the input quarantine is enabled and err rejects the video itself. The returned error carries
+// the quarantine location; a video that cannot be quarantined is left in place, with err as is
+func (uc *ProcessVideoUseCase) quarantineInput(ctx context.Context, logger *zap.Logger, request domain.VideoProcess, videoPath string, err error) error {
+	reason, ok := domain.QuarantineReason(err)
+	if !uc.quarantineInputs || !ok || uc.dryRun || request.DryRun || domain.QuarantineLocation(err) != nil {
+		return err
+	}
+	// A video read from a URL, or one the producer keeps, is not the worker's to move
+	if request.VideoURL != "" || request.KeepOriginal {
+		return err
+	}
+
+	location, quarantineErr := uc.quarantineVideo(ctx, request, videoPath, reason)
+	if quarantineErr != nil {
+		logger.Error("failed to quarantine invalid video", zap.Error(quarantineErr))
+		return err
+	}
+	return domain.NewQuarantinedError(err, location)
+}
+
+// quarantineVideo copies the video to the quarantine location, tagged with reason, and removes
+// the source
+func (uc *ProcessVideoUseCase) quarantineVideo(ctx context.Context, request domain.VideoProcess, videoPath, reason string) (domain.ObjectRef, error) {
+	logger := observability.FromContext(ctx)
+
+	location := domain.ObjectRef{
+		Bucket: uc.quarantineBucket,
+		Key:    domain.QuarantineKey(uc.quarantinePrefix, request.ProcessID, videoName(request)),
+	}
+	if err := uc.copyToQuarantine(ctx, request, videoPath, location.Key, reason); err != nil {
+		return domain.ObjectRef{}, err
+	}
+
+	// A video read from a URL is not the worker's to delete
+	if request.VideoURL == "" {
+		if err := uc.deleteOriginalVideo(ctx, request); err != nil {
+			return domain.ObjectRef{}, err
+		}
+	}
+
+	logger.Info("video quarantined",
+		zap.String("reason", reason),
+		zap.String("quarantine_bucket", location.Bucket),
+		zap.String("quarantine_key", location.Key),
+	)
+	return location, nil
+}
+
+// copyToQuarantine copies the source object to the quarantine on the server side. A video read
+// from a URL, or one the store cannot copy (e.g. over the 5 GB of a single copy), is uploaded
+// from the local file instead, when the video was downloaded
+func (uc *ProcessVideoUseCase) copyToQuarantine(ctx context.Context, request domain.VideoProcess, videoPath, quarantineKey, reason string) error {
+	tags := domain.WithTags(domain.QuarantineTags(reason))
+	if request.VideoURL == "" {
+		err := uc.storage.CopyObject(ctx, request.VideoBucket, request.VideoKey, request.Source.VersionID, uc.quarantineBucket, quarantineKey, tags)
+		recordS3Operation(ctx, "copy", err == nil)
+		if err == nil {
+			return nil
+		}
+		if videoPath == "" {
+			return fmt.Errorf("failed to copy video to quarantine: %w", err)
+		}
+		observability.FromContext(ctx).Warn("failed to copy video to quarantine, uploading it instead", zap.Error(err))
+	}
+
+	file, err := os.Open(videoPath)
+	if err != nil {
+		return fmt.Errorf("failed to open video file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := uc.storage.PutObject(ctx, uc.quarantineBucket, quarantineKey, file, tags); err != nil {
+		recordS3Operation(ctx, "put", false)
+		return fmt.Errorf("failed to upload to quarantine: %w", err)
+	}
+	recordS3Operation(ctx, "put", true)
+	return nil
+}
+
+func (uc *ProcessVideoUseCase) uploadFile(ctx context.Context, processID, bucket, filePath, outputKey string, putOptions []domain.PutOption) error {
+	logger := observability.FromContext(ctx)
+	logger.Info("uploading file to S3",
+		zap.String("bucket", bucket),
+		zap.String("key", outputKey),
+		zap.String("storage_class", domain.NewPutOptions(putOptions...).StorageClass),
+	)
+
+	file, err := os.Open(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to open output file: %w", err)
+	}
+	defer file.Close()
+
+	_, err = uc.storage.PutObject(ctx, bucket, outputKey, uc.trackUpload(ctx, processID, file), putOptions...)
+	if err != nil {
+		recordS3Operation(ctx, "put", false)
+		return fmt.Errorf("failed to put object to storage: %w", err)
+	}
+
+	recordS3Operation(ctx, "put", true)
+	return nil
+}
+
+// uploadDirectory uploads every file under dir keeping the relative layout, which the
+// playlists and manifests reference. It returns the keys uploaded, also when it fails midway
+func (uc *ProcessVideoUseCase) uploadDirectory(ctx context.Context, processID, bucket, dir, prefix string, putOptions []domain.PutOption) ([]string, error) {
+	var uploaded []string
+	err := filepath.WalkDir(dir, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+
+		relative, err := filepath.Rel(dir, filePath)
+		if err != nil {
+			return err
+		}
+
+		file, err := os.Open(filePath)
+		if err != nil {
+			return fmt.Errorf("failed to open %s: %w", relative, err)
+		}
+		defer file.Close()
+
+		key := path.Join(prefix, filepath.ToSlash(relative))
+		if _, err := uc.storage.PutObject(ctx, bucket, key, uc.trackUpload(ctx, processID, file), putOptions...); err != nil {
+			recordS3Operation(ctx, "put", false)
+			return fmt.Errorf("failed to put %s to storage: %w", key, err)
+		}
+
+		recordS3Operation(ctx, "put", true)
+		uploaded = append(uploaded, key)
+		return nil
+	})
+	return uploaded, err
+}
+
+// trackUpload wraps a file being uploaded with trackTransfer
+func (uc *ProcessVideoUseCase) trackUpload(ctx context.Context, processID string, file *os.File) io.Reader {
+	var size int64
+	if stat, err := file.Stat(); err == nil {
+		size = stat.Size()
+	}
+	return uc.trackTransfer(ctx, processID, domain.TransferStageUpload, file, size)
+}
+
+// trackTransfer wraps a job's S3 stream with the bandwidth cap, the transfer metrics and, with
+// a progress queue, the progress messages. A seekable stream stays seekable, as S3 needs
+func (uc *ProcessVideoUseCase) trackTransfer(ctx context.Context, processID, stage string, stream io.Reader, totalBytes int64) io.Reader {
+	var counted int64
+	lastReport := time.Now()
+	return transfer.NewReader(ctx, stream, uc.limiter, func(transferred int64) {
+		// Retried uploads seek back, so only forward movement is counted
+		if transferred > counted {
+			observability.RecordTransferBytes(stage, transferred-counted)
+			observability.UsageFromContext(ctx).AddTransferBytes(stage, transferred-counted)
+		}
+		counted = transferred
+
+		if (uc.progressQueueURL == "" && uc.progress == nil) || time.Since(lastReport) < uc.progressInterval {
+			return
+		}
+		lastReport = time.Now()
+		uc.sendProgress(ctx, domain.NewTransferProgress(processID, stage, transferred, totalBytes))
+	})
+}
+
+// sendProgress is best effort: a lost progress message does not affect the job
+func (uc *ProcessVideoUseCase) sendProgress(ctx context.Context, progress domain.TransferProgress) {
+	if uc.progress != nil {
+		uc.progress.ReportProgress(ctx, progress)
+	}
+	if uc.progressQueueURL == "" {
+		return
+	}
+
+	body, err := json.Marshal(progress)
+	if err == nil {
+		_, err = uc.message.SendMessage(ctx, uc.progressQueueURL, string(body))
+	}
+	if err != nil {
+		observability.RecordSQSOperation("send", false)
+		observability.FromContext(ctx).Debug("failed to send progress message", zap.Error(err))
+		return
+	}
+	observability.RecordSQSOperation("send", true)
+}
+
+// recordPendingDeletion writes the marker ConfirmDeletionUseCase reads to find the original video
+func (uc *ProcessVideoUseCase) recordPendingDeletion(ctx context.Context, request domain.VideoProcess) (string, error) {
+	marker, err := json.Marshal(domain.PendingDeletion{
+		ProcessID:      request.ProcessID,
+		VideoBucket:    request.VideoBucket,
+		VideoKey:       request.VideoKey,
+		VideoVersionID: request.Source.VersionID,
+		CreatedAt:      time.Now().UTC(),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode pending deletion: %w", err)
+	}
+
+	key := domain.PendingDeletionKey(request.ProcessID)
+	if _, err := uc.storage.PutObject(ctx, uc.outputBucket, key, bytes.NewReader(marker)); err != nil {
+		recordS3Operation(ctx, "put", false)
+		return "", fmt.Errorf("failed to put pending deletion: %w", err)
+	}
+	recordS3Operation(ctx, "put", true)
+	return key, nil
+}
+
+// archiveOriginal copies the source video to the archive bucket, keeping it for a reprocess
+// after the original is deleted
+func (uc *ProcessVideoUseCase) archiveOriginal(ctx context.Context, request domain.VideoProcess) (*domain.ObjectRef, error) {
+	archive := &domain.ObjectRef{
+		Bucket: uc.archiveBucket,
+		Key:    domain.ArchiveKey(uc.archivePrefix, request.ProcessID, request.VideoKey),
+	}
+	err := uc.storage.CopyObject(ctx, request.VideoBucket, request.VideoKey, request.Source.VersionID, archive.Bucket, archive.Key)
+	recordS3Operation(ctx, "copy", err == nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy original video: %w", err)
+	}
+	return archive, nil
+}
+
+// recordJob writes the record ReprocessVideoUseCase reads to find the source of the job
+func (uc *ProcessVideoUseCase) recordJob(ctx context.Context, request domain.VideoProcess, archive *domain.ObjectRef) error {
+	now := time.Now().UTC()
+	job := domain.JobRecord{
+		ProcessID: request.ProcessID,
+		TenantID:  request.TenantID,
+		Video:     domain.ObjectRef{Bucket: request.VideoBucket, Key: request.VideoKey, VersionID: request.Source.VersionID},
+		Archive:   archive,
+		Version:   1,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	// A redelivered job keeps the version reached by its reprocesses, so the next one does
+	// not overwrite their outputs
+	existing, err := readJobRecord(ctx, uc.storage, uc.outputBucket, request.ProcessID)
+	if err == nil {
+		job.Version = max(existing.Version, 1)
+		job.CreatedAt = existing.CreatedAt
+	} else if !errors.Is(err, domain.ErrObjectNotFound) {
+		return err
+	}
+
+	record, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("failed to encode job record: %w", err)
+	}
+
+	if _, err := uc.storage.PutObject(ctx, uc.outputBucket, domain.JobRecordKey(request.ProcessID), bytes.NewReader(record)); err != nil {
+		recordS3Operation(ctx, "put", false)
+		return fmt.Errorf("failed to put job record: %w", err)
+	}
+	recordS3Operation(ctx, "put", true)
+	return nil
+}
+
+// scheduleExpiration writes the marker the cleanup reads to delete the outputs once the
+// retention of the tenant is over. A failure is only logged: the outputs keep their tags for
+// the lifecycle rules
+func (uc *ProcessVideoUseCase) scheduleExpiration(ctx context.Context, logger *zap.Logger, request domain.VideoProcess, outputType, bucket string, keys []string) {
+	retention := uc.tenants.Lookup(request.TenantID).Retention
+	if retention == nil || !retention.Delete || !retention.Applies(outputType) || len(keys) == 0 {
+		return
+	}
+
+	expiration := domain.OutputExpiration{
+		ProcessID: request.ProcessID,
+		TenantID:  request.TenantID,
+		Bucket:    bucket,
+		Keys:      keys,
+		ExpiresAt: retention.ExpiresAt(time.Now()),
+	}
+	marker, err := json.Marshal(expiration)
+	if err != nil {
+		logger.Warn("failed to encode output expiration", zap.Error(err))
+		return
+	}
+
+	key := domain.ExpirationKey(expiration.ExpiresAt, request.OutputID())
+	if _, err := uc.storage.PutObject(ctx, uc.outputBucket, key, bytes.NewReader(marker)); err != nil {
+		recordS3Operation(ctx, "put", false)
+		logger.Warn("failed to schedule output expiration", zap.Error(err))
+		return
+	}
+	recordS3Operation(ctx, "put", true)
+	logger.Info("output expiration scheduled", zap.Time("expires_at", expiration.ExpiresAt), zap.Int("objects", len(keys)))
+}
+
+// consumeCancellation reports whether the job was cancelled, removing the marker so that a
+// later resubmission of the same process_id runs
+func (uc *ProcessVideoUseCase) consumeCancellation(ctx context.Context, processID string) (bool, error) {
+	key := domain.CancellationKey(processID)
+	marker, err := uc.storage.GetObject(ctx, uc.outputBucket, key)
+	if errors.Is(err, domain.ErrObjectNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		recordS3Operation(ctx, "get", false)
+		return false, fmt.Errorf("failed to get job cancellation: %w", err)
+	}
+	marker.Close()
+	recordS3Operation(ctx, "get", true)
+
+	if err := uc.storage.DeleteObject(ctx, uc.outputBucket, key); err != nil {
+		recordS3Operation(ctx, "delete", false)
+		return true, fmt.Errorf("failed to delete job cancellation: %w", err)
+	}
+	recordS3Operation(ctx, "delete", true)
+	return true, nil
+}
+
+// deleteOriginalVideo deletes the source video, only the version the job read when it is pinned
+func (uc *ProcessVideoUseCase) deleteOriginalVideo(ctx context.Context, request domain.VideoProcess) error {
+	logger := observability.FromContext(ctx)
+	logger.Info("deleting original video from S3",
+		zap.String("bucket", request.VideoBucket),
+		zap.String("key", request.VideoKey),
+		zap.String("version_id", request.Source.VersionID),
+	)
+
+	err := uc.storage.DeleteObjectVersion(ctx, request.VideoBucket, request.VideoKey, request.Source.VersionID)
+	if err != nil {
+		recordS3Operation(ctx, "delete", false)
+		return fmt.Errorf("failed to delete original video: %w", err)
+	}
+
+	recordS3Operation(ctx, "delete", true)
+	return nil
+}
+
+// removeOutputs is the compensation for a job that fails after writing to bucket: it aborts
+// the multipart uploads left under prefix and deletes the objects already uploaded. It runs
+// even when ctx is done, since that is often why the job failed
+func (uc *ProcessVideoUseCase) removeOutputs(ctx context.Context, logger *zap.Logger, bucket, prefix string, keys []string) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
+	defer cancel()
+
+	aborted, err := uc.storage.AbortMultipartUploads(ctx, bucket, prefix)
+	if err != nil {
+		recordS3Operation(ctx, "abort", false)
+		logger.Warn("failed to abort incomplete uploads", zap.String("prefix", prefix), zap.Error(err))
+	} else if aborted > 0 {
+		recordS3Operation(ctx, "abort", true)
+		logger.Info("incomplete uploads aborted", zap.String("prefix", prefix), zap.Int("uploads", aborted))
+	}
+
+	removed := 0
+	for _, key := range keys {
+		if err := uc.storage.DeleteObject(ctx, bucket, key); err != nil {
+			recordS3Operation(ctx, "delete", false)
+			logger.Warn("failed to delete partial output", zap.String("key", key), zap.Error(err))
+			continue
+		}
+		recordS3Operation(ctx, "delete", true)
+		removed++
+	}
+	if removed > 0 {
+		logger.Info("partial outputs removed", zap.Int("objects", removed))
+	}
+}
+
+func (uc *ProcessVideoUseCase) sendSuccessMessage(ctx context.Context, result *domain.ProcessResult, source *domain.ObjectRef) error {
+	logger := observability.FromContext(ctx)
+	logger.Info("sending success message", zap.String("file_key", result.FileKey))
+	uc.checkSLA(ctx, result)
+	uc.attachUsage(ctx, result)
+	result.ProcessingBackend = observability.UsageFromContext(ctx).Backend()
+
+	messageBody, err := uc.serializeResult(result)
+	if err != nil {
+		return fmt.Errorf("failed to marshal success message: %w", err)
+	}
+
+	messageID, err := uc.publishResult(ctx, result, messageBody, source)
+	if err != nil {
+		uc.endStage(ctx, result.ProcessID, domain.JobStageResult, 0, err)
+		return fmt.Errorf("failed to send success message: %w", err)
+	}
+	uc.endStage(ctx, result.ProcessID, domain.JobStageResult, 0, nil)
+
+	logger.Debug("success message sent", zap.String("message_id", messageID))
+	return nil
+}
+
+// sendErrorMessage reports the failure and returns it; when the job will be retried the error
+// wraps domain.ErrMessageNotHandled, leaving the message in the queue
+func (uc *ProcessVideoUseCase) sendErrorMessage(ctx context.Context, result *domain.ProcessResult) error {
+	logger := observability.FromContext(ctx)
+	if result.Retry != nil {
+		result.Retry.Failed(result.Error)
+	}
+	result.Quarantine = domain.QuarantineLocation(result.Error)
+	uc.checkSLA(ctx, result)
+	uc.attachUsage(ctx, result)
+	logger.Error("sending error message", zap.Error(result.Error))
+
+	messageBody, err := uc.serializeResult(result)
+	if err != nil {
+		logger.Error("failed to marshal error message", zap.Error(err))
+		return fmt.Errorf("failed to marshal error message: %w", err)
+	}
+
+	messageID, err := uc.publishResult(ctx, result, messageBody, nil)
+	if err != nil {
+		logger.Error("failed to send error message", zap.Error(err))
+		uc.endStage(ctx, result.ProcessID, domain.JobStageResult, 0, err)
+		return fmt.Errorf("failed to send error message: %w", err)
+	}
+
+	logger.Debug("error message sent", zap.String("message_id", messageID))
+	uc.recordEvent(ctx, domain.NewJobEvent(result.ProcessID, domain.JobStageResult, 0, result.Error))
+	if result.Retry != nil && result.Retry.WillRetry {
+		logger.Info("job will be retried",
+			zap.Int("attempt", result.Retry.Attempt),
+			zap.Int("max_attempts", result.Retry.MaxAttempts),
+		)
+		return fmt.Errorf("%w: %w", domain.ErrMessageNotHandled, result.Error)
+	}
 	return result.Error
 }
+
+// checkSLA flags a result going out later than the SLA after the job submission
+func (uc *ProcessVideoUseCase) checkSLA(ctx context.Context, result *domain.ProcessResult) {
+	if uc.sla <= 0 || result.EnqueuedAt.IsZero() {
+		return
+	}
+	elapsed := time.Since(result.EnqueuedAt)
+	if elapsed <= uc.sla {
+		return
+	}
+	result.SLABreached = true
+	observability.RecordSLABreach()
+	observability.FromContext(ctx).Warn("job breached the SLA",
+		zap.Duration("end_to_end", elapsed),
+		zap.Duration("sla", uc.sla),
+	)
+}
+
+// attachUsage reports in the result what the job consumed so far, when enabled
+func (uc *ProcessVideoUseCase) attachUsage(ctx context.Context, result *domain.ProcessResult) {
+	usage := observability.UsageFromContext(ctx)
+	if !uc.reportUsage || usage == nil {
+		return
+	}
+	resourceUsage := uc.resourceUsage(usage)
+	result.Usage = &resourceUsage
+}
+
+// resourceUsage reads the usage of a job, priced with the cost model
+func (uc *ProcessVideoUseCase) resourceUsage(usage *observability.JobUsage) domain.ResourceUsage {
+	resourceUsage := domain.ResourceUsage{
+		S3GetRequests:   usage.S3Requests("get"),
+		S3PutRequests:   usage.S3Requests("put"),
+		BytesDownloaded: usage.TransferBytes(domain.TransferStageDownload),
+		BytesUploaded:   usage.TransferBytes(domain.TransferStageUpload),
+		CPUSeconds:      usage.CPUTime().Seconds(),
+	}
+	resourceUsage.EstimatedCost = uc.costModel.Estimate(resourceUsage)
+	return resourceUsage
+}
+
+// stageStarted records the start of a stage in the job timeline
+func (uc *ProcessVideoUseCase) stageStarted(ctx context.Context, processID, stage string) {
+	uc.recordEvent(ctx, domain.JobEvent{ProcessID: processID, Stage: stage, Status: domain.JobEventStarted})
+}
+
+// endUpload records the completed upload with the bytes the job uploaded since it counted before
+func (uc *ProcessVideoUseCase) endUpload(ctx context.Context, processID string, before int64) {
+	uploaded := observability.UsageFromContext(ctx).TransferBytes(domain.TransferStageUpload) - before
+	uc.endStage(ctx, processID, domain.JobStageUpload, uploaded, nil)
+}
+
+// recordEvent appends a stage transition to the job timeline. It is best effort: a lost event
+// leaves a gap in the timeline but does not fail the job
+func (uc *ProcessVideoUseCase) recordEvent(ctx context.Context, event domain.JobEvent) {
+	if !uc.timeline || event.ProcessID == "" {
+		return
+	}
+	event.Timestamp = time.Now().UTC()
+	body, err := json.Marshal(event)
+	if err == nil {
+		_, err = uc.storage.PutObject(ctx, uc.outputBucket, event.Key(), bytes.NewReader(body))
+		recordS3Operation(ctx, "put", err == nil)
+	}
+	if err != nil {
+		observability.FromContext(ctx).Warn("failed to record job event",
+			zap.String("stage", event.Stage),
+			zap.String("status", event.Status),
+			zap.Error(err),
+		)
+	}
+}
+
+// endStage records the end of a stage in the timeline and reports it to the error tracker
+// when it failed. Cancelled jobs and infected videos are outcomes, not failures
+func (uc *ProcessVideoUseCase) endStage(ctx context.Context, processID, stage string, bytes int64, err error) {
+	uc.recordEvent(ctx, domain.NewJobEvent(processID, stage, bytes, err))
+	if err == nil || uc.errorReporter == nil || errors.Is(err, context.Canceled) {
+		return
+	}
+	if code := domain.ErrorCode(err); code == domain.ErrorCodeCancelled || code == domain.ErrorCodeMalwareDetected {
+		return
+	}
+	uc.errorReporter.ReportFailure(ctx, domain.JobFailure{
+		ProcessID: processID,
+		TenantID:  observability.TenantFromContext(ctx),
+		Stage:     stage,
+		Err:       err,
+	})
+}
+
+// recordS3Operation records an S3 operation, counting it in the job usage as well
+func recordS3Operation(ctx context.Context, operation string, success bool) {
+	observability.RecordS3Operation(ctx, operation, success)
+	observability.UsageFromContext(ctx).AddS3Request(operation)
+}
+
+func (uc *ProcessVideoUseCase) serializeResult(result *domain.ProcessResult) ([]byte, error) {
+	if uc.serializer == nil {
+		return json.Marshal(result.ToMessage())
+	}
+	return uc.serializer.Serialize(result)
+}
+
+// publishResult sends a result message to the output queue, encrypting and signing it when configured,
+// and deletes source once the message is accepted. With an outbox the message is persisted first,
+// and a failed send is left to the dispatcher instead of being reported, so the result is delivered
+// at least once. The same message then goes to the result destinations.
+func (uc *ProcessVideoUseCase) publishResult(ctx context.Context, result *domain.ProcessResult, messageBody []byte, source *domain.ObjectRef) (string, error) {
+	body, attributes, err := uc.prepareResult(ctx, messageBody)
+	if err != nil {
+		return "", err
+	}
+
+	entry := domain.OutboxEntry{
+		ProcessID:       result.ProcessID,
+		Body:            body,
+		Attributes:      attributes,
+		DeleteAfterSend: source,
+	}
+	var messageID string
+	if uc.outbox == nil {
+		messageID, err = deliverResult(ctx, uc.storage, uc.message, uc.outputQueueURL, entry)
+	} else {
+		messageID, err = uc.publishThroughOutbox(ctx, entry)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	// Only once the output queue has the result, or the outbox holds it for a retry after a
+	// failed send, so the destinations never see a result whose outputs are rolled back
+	uc.fanOutResult(ctx, result, entry)
+	return messageID, nil
+}
+
+// fanOutResult delivers the result to each of its destinations. A failed delivery does not fail
+// the job: it is reported to the output queue with a DeliveryReport, apart from the result
+func (uc *ProcessVideoUseCase) fanOutResult(ctx context.Context, result *domain.ProcessResult, entry domain.OutboxEntry) {
+	if len(result.Destinations) == 0 {
+		return
+	}
+	logger := observability.FromContext(ctx)
+
+	var failures []domain.DeliveryFailure
+	for _, destination := range result.Destinations {
+		transport := uc.resultTransports[destination.Type]
+		if transport == nil {
+			failures = append(failures, domain.DeliveryFailure{
+				Destination: destination.String(),
+				Error:       fmt.Sprintf("result destination type %s is not enabled", destination.Type),
+			})
+			continue
+		}
+
+		_, err := sendEntry(ctx, transport, destination.Target, entry)
+		observability.RecordResultDelivery(destination.Type, err == nil)
+		if err != nil {
+			logger.Warn("failed to deliver result to destination",
+				zap.String("destination", destination.String()),
+				zap.Error(err),
+			)
+			failures = append(failures, domain.DeliveryFailure{Destination: destination.String(), Error: err.Error()})
+		}
+	}
+	if len(failures) == 0 {
+		return
+	}
+
+	report, err := json.Marshal(domain.NewDeliveryReport(result.ProcessID, result.Success, failures))
+	if err != nil {
+		logger.Error("failed to marshal delivery report", zap.Error(err))
+		return
+	}
+	attributes := map[string]string{domain.MessageTypeAttribute: domain.MessageTypeDeliveryReport}
+	addTraceAttributes(ctx, attributes)
+	_, err = uc.message.SendMessageWithAttributes(ctx, uc.outputQueueURL, string(report), attributes)
+	observability.RecordSQSOperation("send", err == nil)
+	if err != nil {
+		logger.Error("failed to send delivery report", zap.Error(err))
+	}
+}
+
+// prepareResult returns the body and attributes to send. Encryption happens first so consumers
+// can verify the signature before decrypting.
+func (uc *ProcessVideoUseCase) prepareResult(ctx context.Context, messageBody []byte) (string, map[string]string, error) {
+	attributes := map[string]string{}
+
+	binaryBody := false
+	if uc.serializer != nil {
+		attributes["content_type"] = uc.serializer.ContentType()
+		binaryBody = uc.serializer.ContentType() != domain.ContentTypeJSON
+	}
+
+	// Compression comes before encryption, as ciphertext does not compress
+	if uc.compressThreshold > 0 && len(messageBody) > uc.compressThreshold {
+		compressed, err := gzipBody(messageBody)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to compress result message: %w", err)
+		}
+
+		// Worth it only if the encoded gzip is smaller than the body would be on the wire
+		sentSize := len(messageBody)
+		if binaryBody {
+			sentSize = base64.StdEncoding.EncodedLen(sentSize)
+		}
+		if base64.StdEncoding.EncodedLen(len(compressed)) < sentSize {
+			observability.FromContext(ctx).Debug("result message compressed",
+				zap.Int("size_bytes", len(messageBody)),
+				zap.Int("compressed_bytes", len(compressed)),
+			)
+			messageBody = compressed
+			attributes["content_encoding"] = "gzip"
+			binaryBody = true
+		}
+	}
+
+	// SQS bodies must be text, so binary formats and compressed bodies travel base64 encoded
+	if binaryBody {
+		messageBody = []byte(base64.StdEncoding.EncodeToString(messageBody))
+		attributes["content_transfer_encoding"] = "base64"
+	}
+
+	if uc.encryptor != nil {
+		encrypted, err := uc.encryptor.Encrypt(ctx, messageBody)
+		if err != nil {
+			observability.RecordError("encryption")
+			return "", nil, fmt.Errorf("failed to encrypt result message: %w", err)
+		}
+		messageBody = encrypted
+		attributes["content_encryption"] = "kms-envelope"
+	}
+
+	if uc.signer != nil {
+		signature, err := uc.signer.Sign(ctx, messageBody)
+		if err != nil {
+			observability.RecordError("signing")
+			return "", nil, fmt.Errorf("failed to sign result message: %w", err)
+		}
+
+		attributes["signature"] = signature
+		attributes["signature_algorithm"] = uc.signer.Algorithm()
+		if keyID := uc.signer.KeyID(); keyID != "" {
+			attributes["signature_key_id"] = keyID
+		}
+	}
+
+	if uc.payloadThreshold > 0 && len(messageBody) > uc.payloadThreshold {
+		pointer, err := uc.offloadPayload(ctx, messageBody)
+		if err != nil {
+			return "", nil, err
+		}
+		attributes[domain.PayloadSizeAttribute] = strconv.Itoa(len(messageBody))
+		messageBody = []byte(pointer)
+	}
+
+	addTraceAttributes(ctx, attributes)
+	return string(messageBody), attributes, nil
+}
+
+// addTraceAttributes carries the trace of the job into the result attributes. They come after
+// the attributes needed to read the body, each only while the message stays within the SQS
+// attribute limit
+func addTraceAttributes(ctx context.Context, attributes map[string]string) {
+	trace := observability.TraceFromContext(ctx)
+	for _, attribute := range []struct{ name, value string }{
+		{domain.TraceparentAttribute, trace.ChildTraceparent()},
+		{domain.CorrelationIDAttribute, trace.CorrelationID},
+		{domain.SchemaVersionAttribute, domain.ResultSchemaVersion},
+	} {
+		if attribute.value != "" && len(attributes) < domain.MaxMessageAttributes {
+			attributes[attribute.name] = attribute.value
+		}
+	}
+}
+
+func gzipBody(body []byte) ([]byte, error) {
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	if _, err := writer.Write(body); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return compressed.Bytes(), nil
+}
+
+// publishThroughOutbox saves the prepared message before sending it and removes it once SQS
+// accepts it. If the outbox itself fails the message is still sent directly
+func (uc *ProcessVideoUseCase) publishThroughOutbox(ctx context.Context, entry domain.OutboxEntry) (string, error) {
+	logger := observability.FromContext(ctx)
+
+	suffix := make([]byte, 16)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", fmt.Errorf("failed to generate outbox id: %w", err)
+	}
+
+	now := time.Now()
+	entry.ID = hex.EncodeToString(suffix)
+	entry.CreatedAt = now
+	// Scheduled as if the first attempt had failed, so the dispatcher leaves it alone while
+	// this send is in flight
+	entry.NextAttemptAt = now.Add(domain.OutboxRetryDelay(1))
+	if err := uc.outbox.Save(ctx, entry); err != nil {
+		observability.RecordError("outbox")
+		logger.Warn("failed to persist result message, sending without outbox", zap.Error(err))
+		return deliverResult(ctx, uc.storage, uc.message, uc.outputQueueURL, entry)
+	}
+
+	messageID, err := deliverResult(ctx, uc.storage, uc.message, uc.outputQueueURL, entry)
+	if err != nil {
+		entry.Failed(err, time.Now())
+		if err := uc.outbox.Save(ctx, entry); err != nil {
+			logger.Warn("failed to record result message attempt", zap.Error(err))
+		}
+		logger.Warn("failed to send result message, left in the outbox for retry",
+			zap.String("outbox_id", entry.ID),
+			zap.Error(err),
+		)
+		return "", nil
+	}
+
+	if err := uc.outbox.Delete(ctx, entry.ID); err != nil {
+		observability.RecordError("outbox")
+		logger.Warn("failed to remove sent result message from the outbox, it will be sent again", zap.Error(err))
+	}
+	return messageID, nil
+}
+
+// offloadPayload uploads an oversized message body to S3 and returns the pointer to send in its place
+func (uc *ProcessVideoUseCase) offloadPayload(ctx context.Context, messageBody []byte) (string, error) {
+	suffix := make([]byte, 16)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", fmt.Errorf("failed to generate payload key: %w", err)
+	}
+
+	pointer := domain.PayloadPointer{
+		Bucket: uc.payloadBucket,
+		Key:    fmt.Sprintf("payloads/%s.json", hex.EncodeToString(suffix)),
+	}
+
+	if _, err := uc.storage.PutObject(ctx, pointer.Bucket, pointer.Key, bytes.NewReader(messageBody)); err != nil {
+		recordS3Operation(ctx, "put", false)
+		return "", fmt.Errorf("failed to offload result payload: %w", err)
+	}
+	recordS3Operation(ctx, "put", true)
+
+	observability.FromContext(ctx).Info("result payload offloaded to S3",
+		zap.String("bucket", pointer.Bucket),
+		zap.String("key", pointer.Key),
+		zap.Int("size_bytes", len(messageBody)),
+	)
+
+	return domain.EncodePayloadPointer(pointer)
+}
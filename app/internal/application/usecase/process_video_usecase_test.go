@@ -1,35 +1,72 @@
 package usecase
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/port"
 	"github.com/SOAT-Project/hackaton-soat-processor/pkg/observability"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/transfer"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 // Mock implementations for testing
 
 type mockStoragePort struct {
-	getObjectFunc    func(ctx context.Context, bucket, key string) (io.ReadCloser, error)
-	putObjectFunc    func(ctx context.Context, bucket, key string, body io.Reader) (string, error)
+	getObjectFunc    func(ctx context.Context, bucket, key string, options domain.GetOptions) (io.ReadCloser, error)
+	headObjectFunc   func(ctx context.Context, bucket, key string) (domain.ObjectInfo, error)
+	getObjectIfFunc  func(ctx context.Context, bucket, key string, condition domain.ObjectCondition) (io.ReadCloser, error)
+	putObjectFunc    func(ctx context.Context, bucket, key string, body io.Reader, options domain.PutOptions) (string, error)
 	deleteObjectFunc func(ctx context.Context, bucket, key string) error
+	listObjectsFunc  func(ctx context.Context, bucket, prefix string) ([]string, error)
+
+	deleteObjectVersionFunc   func(ctx context.Context, bucket, key, versionID string) error
+	copyObjectFunc            func(ctx context.Context, srcBucket, srcKey, srcVersionID, dstBucket, dstKey string, options domain.PutOptions) error
+	listObjectPagesFunc       func(ctx context.Context, bucket, prefix string, fn func(page []domain.ObjectSummary) error) error
+	deleteObjectsFunc         func(ctx context.Context, bucket string, keys []string) (map[string]error, error)
+	abortMultipartUploadsFunc func(ctx context.Context, bucket, prefix string) (int, error)
 }
 
-func (m *mockStoragePort) GetObject(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
+func (m *mockStoragePort) GetObject(ctx context.Context, bucket, key string, options ...domain.GetOption) (io.ReadCloser, error) {
 	if m.getObjectFunc != nil {
-		return m.getObjectFunc(ctx, bucket, key)
+		return m.getObjectFunc(ctx, bucket, key, domain.NewGetOptions(options...))
 	}
 	return io.NopCloser(strings.NewReader("mock video data")), nil
 }
 
-func (m *mockStoragePort) PutObject(ctx context.Context, bucket, key string, body io.Reader) (string, error) {
+// HeadObject fails unless mocked, so the download is not checked against a size
+func (m *mockStoragePort) HeadObject(ctx context.Context, bucket, key string) (domain.ObjectInfo, error) {
+	if m.headObjectFunc != nil {
+		return m.headObjectFunc(ctx, bucket, key)
+	}
+	return domain.ObjectInfo{}, errors.New("head object not mocked")
+}
+
+// GetObjectIf reads the object as GetObject unless mocked
+func (m *mockStoragePort) GetObjectIf(ctx context.Context, bucket, key string, condition domain.ObjectCondition, options ...domain.GetOption) (io.ReadCloser, error) {
+	if m.getObjectIfFunc != nil {
+		return m.getObjectIfFunc(ctx, bucket, key, condition)
+	}
+	return m.GetObject(ctx, bucket, key, options...)
+}
+
+func (m *mockStoragePort) PutObject(ctx context.Context, bucket, key string, body io.Reader, options ...domain.PutOption) (string, error) {
 	if m.putObjectFunc != nil {
-		return m.putObjectFunc(ctx, bucket, key, body)
+		return m.putObjectFunc(ctx, bucket, key, body, domain.NewPutOptions(options...))
 	}
 	return key, nil
 }
@@ -41,8 +78,62 @@ func (m *mockStoragePort) DeleteObject(ctx context.Context, bucket, key string)
 	return nil
 }
 
+// CopyObject fails unless mocked, as a store without server-side copies
+func (m *mockStoragePort) CopyObject(ctx context.Context, srcBucket, srcKey, srcVersionID, dstBucket, dstKey string, options ...domain.PutOption) error {
+	if m.copyObjectFunc != nil {
+		return m.copyObjectFunc(ctx, srcBucket, srcKey, srcVersionID, dstBucket, dstKey, domain.NewPutOptions(options...))
+	}
+	return errors.New("copy object not mocked")
+}
+
+// DeleteObjectVersion deletes the object as DeleteObject unless mocked
+func (m *mockStoragePort) DeleteObjectVersion(ctx context.Context, bucket, key, versionID string) error {
+	if m.deleteObjectVersionFunc != nil {
+		return m.deleteObjectVersionFunc(ctx, bucket, key, versionID)
+	}
+	return m.DeleteObject(ctx, bucket, key)
+}
+
+func (m *mockStoragePort) ListObjects(ctx context.Context, bucket, prefix string) ([]string, error) {
+	if m.listObjectsFunc != nil {
+		return m.listObjectsFunc(ctx, bucket, prefix)
+	}
+	return nil, nil
+}
+
+// ListObjectPages lists the keys of ListObjects as a single page unless mocked
+func (m *mockStoragePort) ListObjectPages(ctx context.Context, bucket, prefix string, fn func(page []domain.ObjectSummary) error) error {
+	if m.listObjectPagesFunc != nil {
+		return m.listObjectPagesFunc(ctx, bucket, prefix, fn)
+	}
+	keys, err := m.ListObjects(ctx, bucket, prefix)
+	if err != nil || len(keys) == 0 {
+		return err
+	}
+	page := make([]domain.ObjectSummary, 0, len(keys))
+	for _, key := range keys {
+		page = append(page, domain.ObjectSummary{Key: key})
+	}
+	return fn(page)
+}
+
+func (m *mockStoragePort) DeleteObjects(ctx context.Context, bucket string, keys []string) (map[string]error, error) {
+	if m.deleteObjectsFunc != nil {
+		return m.deleteObjectsFunc(ctx, bucket, keys)
+	}
+	return nil, nil
+}
+
+func (m *mockStoragePort) AbortMultipartUploads(ctx context.Context, bucket, prefix string) (int, error) {
+	if m.abortMultipartUploadsFunc != nil {
+		return m.abortMultipartUploadsFunc(ctx, bucket, prefix)
+	}
+	return 0, nil
+}
+
 type mockMessagePort struct {
-	sendMessageFunc func(ctx context.Context, queueURL string, messageBody string) (string, error)
+	sendMessageFunc               func(ctx context.Context, queueURL string, messageBody string) (string, error)
+	sendMessageWithAttributesFunc func(ctx context.Context, queueURL string, messageBody string, attributes map[string]string) (string, error)
 }
 
 func (m *mockMessagePort) SendMessage(ctx context.Context, queueURL string, messageBody string) (string, error) {
@@ -52,15 +143,40 @@ func (m *mockMessagePort) SendMessage(ctx context.Context, queueURL string, mess
 	return "mock-message-id", nil
 }
 
+func (m *mockMessagePort) SendMessageWithAttributes(ctx context.Context, queueURL string, messageBody string, attributes map[string]string) (string, error) {
+	if m.sendMessageWithAttributesFunc != nil {
+		return m.sendMessageWithAttributesFunc(ctx, queueURL, messageBody, attributes)
+	}
+	return m.SendMessage(ctx, queueURL, messageBody)
+}
+
 type mockVideoProcessor struct {
-	processVideoFunc func(ctx context.Context, videoPath string) (string, int, error)
+	processVideoFunc        func(ctx context.Context, jobID, videoPath string, options domain.FrameOptions) ([]string, int, error)
+	generateSpriteSheetFunc func(ctx context.Context, jobID, videoPath string, sprite domain.SpriteOptions, options domain.FrameOptions) ([]string, int, error)
+	probeVideoFunc          func(ctx context.Context, videoPath string) (domain.VideoInfo, error)
 }
 
-func (m *mockVideoProcessor) ProcessVideo(ctx context.Context, videoPath string) (string, int, error) {
+func (m *mockVideoProcessor) ProcessVideo(ctx context.Context, request domain.ProcessingRequest) (domain.ProcessingResult, error) {
 	if m.processVideoFunc != nil {
-		return m.processVideoFunc(ctx, videoPath)
+		zipPaths, frameCount, err := m.processVideoFunc(ctx, request.JobID, request.VideoPath, request.Options)
+		return domain.ProcessingResult{Artifacts: zipPaths, FrameCount: frameCount}, err
+	}
+	return domain.ProcessingResult{Artifacts: []string{"/tmp/mock.zip"}, FrameCount: 10}, nil
+}
+
+func (m *mockVideoProcessor) GenerateSpriteSheet(ctx context.Context, request domain.ProcessingRequest) (domain.ProcessingResult, error) {
+	if m.generateSpriteSheetFunc != nil {
+		zipPaths, sheetCount, err := m.generateSpriteSheetFunc(ctx, request.JobID, request.VideoPath, request.Sprite, request.Options)
+		return domain.ProcessingResult{Artifacts: zipPaths, FrameCount: sheetCount}, err
+	}
+	return domain.ProcessingResult{Artifacts: []string{"/tmp/mock-sprites.zip"}, FrameCount: 1}, nil
+}
+
+func (m *mockVideoProcessor) ProbeVideo(ctx context.Context, videoPath string) (domain.VideoInfo, error) {
+	if m.probeVideoFunc != nil {
+		return m.probeVideoFunc(ctx, videoPath)
 	}
-	return "/tmp/mock.zip", 10, nil
+	return domain.VideoInfo{DurationSeconds: 10, Width: 1920, Height: 1080}, nil
 }
 
 func TestNewProcessVideoUseCase(t *testing.T) {
@@ -110,6 +226,16 @@ func TestValidateRequest(t *testing.T) {
 			wantErr: true,
 			errMsg:  "process_id is required",
 		},
+		{
+			name: "process_id with a path",
+			request: domain.VideoProcess{
+				ProcessID:   "../tenant-b/123",
+				VideoBucket: "test-bucket",
+				VideoKey:    "video.mp4",
+			},
+			wantErr: true,
+			errMsg:  `invalid process_id "../tenant-b/123": only letters, digits, '.', '_' and '-' are allowed`,
+		},
 		{
 			name: "missing video_bucket",
 			request: domain.VideoProcess{
@@ -128,6 +254,37 @@ func TestValidateRequest(t *testing.T) {
 			wantErr: true,
 			errMsg:  "video_key is required",
 		},
+		{
+			name: "video_url not enabled",
+			request: domain.VideoProcess{
+				ProcessID: "123",
+				VideoURL:  "https://videos.example.com/video.mp4",
+			},
+			wantErr: true,
+			errMsg:  "video_url is not enabled",
+		},
+		{
+			name: "video_url with bucket",
+			request: domain.VideoProcess{
+				ProcessID:   "123",
+				VideoBucket: "test-bucket",
+				VideoURL:    "https://videos.example.com/video.mp4",
+			},
+			wantErr: true,
+			errMsg:  "video_url cannot be combined",
+		},
+		{
+			name: "anonymize on a sprite",
+			request: domain.VideoProcess{
+				ProcessID:   "123",
+				VideoBucket: "test-bucket",
+				VideoKey:    "video.mp4",
+				OutputType:  domain.OutputTypeSprite,
+				Anonymize:   domain.AnonymizeOptions{Regions: []domain.BlurRegion{{Width: 0.5, Height: 0.5}}},
+			},
+			wantErr: true,
+			errMsg:  "anonymize only applies to the frames output",
+		},
 	}
 
 	for _, tt := range tests {
@@ -172,10 +329,19 @@ func TestExecute_ValidationError(t *testing.T) {
 	}
 }
 
-func TestExecute_StorageError(t *testing.T) {
+func TestExecute_SourceNotAllowed(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
 	storagePort := &mockStoragePort{
-		getObjectFunc: func(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
-			return nil, errors.New("storage error")
+		getObjectFunc: func(ctx context.Context, bucket, key string, options domain.GetOptions) (io.ReadCloser, error) {
+			t.Errorf("Expected %s/%s not to be read", bucket, key)
+			return nil, errors.New("not allowed")
+		},
+		deleteObjectFunc: func(ctx context.Context, bucket, key string) error {
+			t.Errorf("Expected %s/%s not to be deleted", bucket, key)
+			return nil
 		},
 	}
 
@@ -187,52 +353,48 @@ func TestExecute_StorageError(t *testing.T) {
 		},
 	}
 
-	useCase := NewProcessVideoUseCase(storagePort, messagePort, nil, "test-bucket", "test-queue")
-
-	request := domain.VideoProcess{
-		ProcessID:   "123",
-		VideoBucket: "test-bucket",
-		VideoKey:    "video.mp4",
+	tenants, err := domain.ParseTenantRegistry([]byte(`{"acme":{"allowed_sources":["uploads/acme/"]}}`))
+	if err != nil {
+		t.Fatalf("ParseTenantRegistry failed: %v", err)
 	}
+	useCase := NewProcessVideoUseCase(storagePort, messagePort, nil, "test-bucket", "test-queue").
+		WithSourceAllowlist(domain.SourceAllowlist{{Bucket: "uploads"}}).
+		WithTenantRegistry(tenants)
 
-	err := useCase.Execute(context.Background(), request)
-	if err == nil {
-		t.Fatal("Expected error when storage fails")
+	tests := []domain.VideoProcess{
+		{ProcessID: "123", VideoBucket: "secrets", VideoKey: "video.mp4"},
+		{ProcessID: "123", VideoBucket: "uploads", VideoKey: "other/video.mp4", TenantID: "acme"},
 	}
 
-	if !strings.Contains(sentMessage, "error_message") {
-		t.Errorf("Expected error message to be sent, got: %s", sentMessage)
+	for _, request := range tests {
+		sentMessage = ""
+		if err := useCase.Execute(context.Background(), request); err == nil {
+			t.Errorf("Expected error for %s/%s", request.VideoBucket, request.VideoKey)
+		}
+		if !strings.Contains(sentMessage, domain.ErrorCodeSourceNotAllowed) {
+			t.Errorf("Expected %s error message, got: %s", domain.ErrorCodeSourceNotAllowed, sentMessage)
+		}
 	}
 }
 
-func TestExecute_FullSuccess(t *testing.T) {
-	// Initialize logger
+func TestExecute_VideoExtensions(t *testing.T) {
 	if err := observability.InitLogger("test"); err != nil {
 		t.Fatalf("Failed to setup logger: %v", err)
 	}
 
-	tmpFile, err := os.CreateTemp("", "test-video-*.mp4")
-	if err != nil {
-		t.Fatalf("Failed to create temp file: %v", err)
-	}
-	tmpFile.WriteString("fake video content")
-	tmpFile.Close()
-	defer os.Remove(tmpFile.Name())
-
-	zipFile, err := os.CreateTemp("", "test-zip-*.zip")
-	if err != nil {
-		t.Fatalf("Failed to create zip file: %v", err)
+	zipPath := filepath.Join(t.TempDir(), "frames.zip")
+	contents := map[string]string{
+		"clip.mxf":   "fake mxf content",
+		"camera.bin": "\x00\x00\x00\x18ftypisom fake mp4 content",
+		"notes.bin":  "not a video",
 	}
-	zipFile.WriteString("fake zip content")
-	zipFile.Close()
-	defer os.Remove(zipFile.Name())
-
+	var read []string
 	storagePort := &mockStoragePort{
-		getObjectFunc: func(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
-			file, err := os.Open(tmpFile.Name())
-			return file, err
+		getObjectFunc: func(ctx context.Context, bucket, key string, options domain.GetOptions) (io.ReadCloser, error) {
+			read = append(read, key)
+			return io.NopCloser(strings.NewReader(contents[key])), nil
 		},
-		putObjectFunc: func(ctx context.Context, bucket, key string, body io.Reader) (string, error) {
+		putObjectFunc: func(ctx context.Context, bucket, key string, body io.Reader, options domain.PutOptions) (string, error) {
 			return "s3://bucket/key", nil
 		},
 		deleteObjectFunc: func(ctx context.Context, bucket, key string) error {
@@ -240,223 +402,3663 @@ func TestExecute_FullSuccess(t *testing.T) {
 		},
 	}
 
+	var sentMessage string
 	messagePort := &mockMessagePort{
 		sendMessageFunc: func(ctx context.Context, queueURL string, messageBody string) (string, error) {
-			return "msg-success", nil
+			sentMessage = messageBody
+			return "msg-id", nil
 		},
 	}
-
 	videoProcessor := &mockVideoProcessor{
-		processVideoFunc: func(ctx context.Context, videoPath string) (string, int, error) {
-			return zipFile.Name(), 30, nil
+		processVideoFunc: func(ctx context.Context, jobID, videoPath string, options domain.FrameOptions) ([]string, int, error) {
+			// The zip is removed once uploaded
+			os.WriteFile(zipPath, []byte("fake zip content"), 0644)
+			return []string{zipPath}, 1, nil
 		},
 	}
 
-	useCase := NewProcessVideoUseCase(storagePort, messagePort, videoProcessor, "output-bucket", "output-queue")
+	tenants, err := domain.ParseTenantRegistry([]byte(`{"broadcast":{"video_extensions":["mxf"]}}`))
+	if err != nil {
+		t.Fatalf("ParseTenantRegistry failed: %v", err)
+	}
+	newUseCase := func(sniff bool) *ProcessVideoUseCase {
+		return NewProcessVideoUseCase(storagePort, messagePort, videoProcessor, "output-bucket", "output-queue").
+			WithTenantRegistry(tenants).
+			WithVideoExtensions(domain.DefaultVideoExtensions, sniff)
+	}
 
-	request := domain.VideoProcess{
-		ProcessID:   "process-123",
-		VideoBucket: "input-bucket",
-		VideoKey:    "video.mp4",
+	tests := []struct {
+		name     string
+		sniff    bool
+		request  domain.VideoProcess
+		wantRead bool
+		wantErr  bool
+	}{
+		{"extension not supported", false, domain.VideoProcess{ProcessID: "1", VideoBucket: "input", VideoKey: "clip.mxf"}, false, true},
+		{"extension of the tenant", false, domain.VideoProcess{ProcessID: "2", VideoBucket: "input", VideoKey: "clip.mxf", TenantID: "broadcast"}, true, false},
+		{"sniffing disabled", false, domain.VideoProcess{ProcessID: "3", VideoBucket: "input", VideoKey: "camera.bin"}, false, true},
+		{"content is a video", true, domain.VideoProcess{ProcessID: "4", VideoBucket: "input", VideoKey: "camera.bin"}, true, false},
+		{"content is not a video", true, domain.VideoProcess{ProcessID: "5", VideoBucket: "input", VideoKey: "notes.bin"}, true, true},
 	}
 
-	err = useCase.Execute(context.Background(), request)
-	if err != nil {
-		t.Fatalf("Execute failed: %v", err)
+	for _, tt := range tests {
+		read, sentMessage = nil, ""
+		err := newUseCase(tt.sniff).Execute(context.Background(), tt.request)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: expected error %v, got %v", tt.name, tt.wantErr, err)
+		}
+		if (len(read) > 0) != tt.wantRead {
+			t.Errorf("%s: expected video read %v, got reads %v", tt.name, tt.wantRead, read)
+		}
+		if tt.wantErr && !strings.Contains(sentMessage, domain.ErrorCodeUnsupportedFormat) {
+			t.Errorf("%s: expected %s error message, got: %s", tt.name, domain.ErrorCodeUnsupportedFormat, sentMessage)
+		}
 	}
 }
 
-func TestExecute_ProcessingError(t *testing.T) {
+func TestExecute_OutputLocation(t *testing.T) {
 	if err := observability.InitLogger("test"); err != nil {
 		t.Fatalf("Failed to setup logger: %v", err)
 	}
 
-	tmpFile, err := os.CreateTemp("", "test-video-*.mp4")
-	if err != nil {
-		t.Fatalf("Failed to create temp file: %v", err)
-	}
-	tmpFile.WriteString("fake video content")
-	tmpFile.Close()
-	defer os.Remove(tmpFile.Name())
+	zipPath := filepath.Join(t.TempDir(), "frames.zip")
+	os.WriteFile(zipPath, []byte("fake zip content"), 0644)
 
+	var uploaded []string
 	storagePort := &mockStoragePort{
-		getObjectFunc: func(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
-			file, err := os.Open(tmpFile.Name())
-			return file, err
+		putObjectFunc: func(ctx context.Context, bucket, key string, body io.Reader, options domain.PutOptions) (string, error) {
+			uploaded = append(uploaded, bucket+"/"+key)
+			return "etag", nil
 		},
 	}
-
+	var sentMessage string
 	messagePort := &mockMessagePort{
 		sendMessageFunc: func(ctx context.Context, queueURL string, messageBody string) (string, error) {
+			sentMessage = messageBody
 			return "msg-id", nil
 		},
 	}
-
 	videoProcessor := &mockVideoProcessor{
-		processVideoFunc: func(ctx context.Context, videoPath string) (string, int, error) {
-			return "", 0, errors.New("processing failed")
+		processVideoFunc: func(ctx context.Context, jobID, videoPath string, options domain.FrameOptions) ([]string, int, error) {
+			return []string{zipPath}, 10, nil
 		},
 	}
 
-	useCase := NewProcessVideoUseCase(storagePort, messagePort, videoProcessor, "output-bucket", "output-queue")
+	tenants, err := domain.ParseTenantRegistry([]byte(`{"acme":{"allowed_outputs":["acme-outputs"]}}`))
+	if err != nil {
+		t.Fatalf("ParseTenantRegistry failed: %v", err)
+	}
+	useCase := NewProcessVideoUseCase(storagePort, messagePort, videoProcessor, "output-bucket", "output-queue").
+		WithOutputAllowlist(domain.SourceAllowlist{{Bucket: "shared-outputs", Prefix: "team-a/"}}).
+		WithTenantRegistry(tenants).
+		WithDeleteOnConfirm(true)
 
-	request := domain.VideoProcess{
-		ProcessID:   "process-456",
-		VideoBucket: "input-bucket",
-		VideoKey:    "video.mp4",
+	err = useCase.Execute(context.Background(), domain.VideoProcess{
+		ProcessID:    "123",
+		VideoBucket:  "input-bucket",
+		VideoKey:     "video.mp4",
+		TenantID:     "acme",
+		OutputBucket: "acme-outputs",
+		OutputPrefix: "frames/2024/",
+	})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	// The pending deletion marker stays in the worker bucket
+	want := []string{"acme-outputs/frames/2024/frames_123.zip", "output-bucket/" + domain.PendingDeletionKey("123")}
+	if strings.Join(uploaded, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected %v, got %v", want, uploaded)
+	}
+	if !strings.Contains(sentMessage, `"file_bucket":"acme-outputs"`) || !strings.Contains(sentMessage, `"file_key":"frames/2024/frames_123.zip"`) {
+		t.Errorf("Expected the result to point to the output location, got: %s", sentMessage)
 	}
 
-	err = useCase.Execute(context.Background(), request)
-	if err == nil {
-		t.Fatal("Expected error from processing")
+	tests := []domain.VideoProcess{
+		// The worker allowlist only has shared-outputs/team-a/
+		{ProcessID: "123", VideoBucket: "input-bucket", VideoKey: "video.mp4", OutputBucket: "shared-outputs", OutputPrefix: "team-b"},
+		// A prefix alone could overwrite the job markers of the worker bucket
+		{ProcessID: "123", VideoBucket: "input-bucket", VideoKey: "video.mp4", OutputPrefix: "timelines"},
+		{ProcessID: "123", VideoBucket: "input-bucket", VideoKey: "video.mp4", OutputBucket: "acme-outputs"},
+	}
+	for _, request := range tests {
+		uploaded = nil
+		if err := useCase.Execute(context.Background(), request); err == nil {
+			t.Errorf("Expected error for %s/%s", request.OutputBucket, request.OutputPrefix)
+		}
+		if !strings.Contains(sentMessage, domain.ErrorCodeOutputNotAllowed) {
+			t.Errorf("Expected %s error message, got: %s", domain.ErrorCodeOutputNotAllowed, sentMessage)
+		}
+		if len(uploaded) > 0 {
+			t.Errorf("Expected nothing uploaded, got %v", uploaded)
+		}
 	}
 }
 
-func TestExecute_UploadError(t *testing.T) {
-	if err := observability.InitLogger("test"); err != nil {
-		t.Fatalf("Failed to setup logger: %v", err)
-	}
+// mockArchiveMerger records the groups it merges into a fake zip
+type mockArchiveMerger struct {
+	dir    string
+	groups []domain.ArchiveGroup
+}
 
-	tmpFile, err := os.CreateTemp("", "test-video-*.mp4")
-	if err != nil {
-		t.Fatalf("Failed to create temp file: %v", err)
-	}
-	tmpFile.WriteString("fake video content")
-	tmpFile.Close()
-	defer os.Remove(tmpFile.Name())
+func (m *mockArchiveMerger) MergeArchives(ctx context.Context, jobID string, groups []domain.ArchiveGroup) (string, error) {
+	m.groups = groups
+	zipPath := filepath.Join(m.dir, "batch_"+jobID+".zip")
+	return zipPath, os.WriteFile(zipPath, []byte("merged zip"), 0644)
+}
 
-	zipFile, err := os.CreateTemp("", "test-zip-*.zip")
-	if err != nil {
-		t.Fatalf("Failed to create zip file: %v", err)
+func TestExecute_Batch(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
 	}
-	zipFile.WriteString("fake zip content")
-	zipFile.Close()
-	defer os.Remove(zipFile.Name())
 
+	dir := t.TempDir()
+	var uploaded, deleted []string
 	storagePort := &mockStoragePort{
-		getObjectFunc: func(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
-			file, err := os.Open(tmpFile.Name())
-			return file, err
+		getObjectFunc: func(ctx context.Context, bucket, key string, options domain.GetOptions) (io.ReadCloser, error) {
+			if key == "uploads/missing.mp4" {
+				return nil, domain.ErrObjectNotFound
+			}
+			return io.NopCloser(strings.NewReader("fake video content")), nil
 		},
-		putObjectFunc: func(ctx context.Context, bucket, key string, body io.Reader) (string, error) {
-			return "", errors.New("upload failed")
+		putObjectFunc: func(ctx context.Context, bucket, key string, body io.Reader, options domain.PutOptions) (string, error) {
+			uploaded = append(uploaded, key)
+			return "etag", nil
+		},
+		deleteObjectFunc: func(ctx context.Context, bucket, key string) error {
+			deleted = append(deleted, key)
+			return nil
 		},
 	}
-
+	var sentMessage string
 	messagePort := &mockMessagePort{
 		sendMessageFunc: func(ctx context.Context, queueURL string, messageBody string) (string, error) {
+			sentMessage = messageBody
 			return "msg-id", nil
 		},
 	}
-
 	videoProcessor := &mockVideoProcessor{
-		processVideoFunc: func(ctx context.Context, videoPath string) (string, int, error) {
-			return zipFile.Name(), 25, nil
+		processVideoFunc: func(ctx context.Context, jobID, videoPath string, options domain.FrameOptions) ([]string, int, error) {
+			zipPath := filepath.Join(dir, jobID+".zip")
+			return []string{zipPath}, 5, os.WriteFile(zipPath, []byte("fake zip content"), 0644)
 		},
 	}
-
-	useCase := NewProcessVideoUseCase(storagePort, messagePort, videoProcessor, "output-bucket", "output-queue")
+	merger := &mockArchiveMerger{dir: dir}
+	useCase := NewProcessVideoUseCase(storagePort, messagePort, videoProcessor, "output-bucket", "output-queue").
+		WithArchiveMerger(merger)
 
 	request := domain.VideoProcess{
-		ProcessID:   "process-789",
+		ProcessID:   "123",
 		VideoBucket: "input-bucket",
-		VideoKey:    "video.mp4",
+		VideoKeys:   []string{"uploads/intro.mp4", "uploads/missing.mp4", "uploads/outro.mp4"},
 	}
 
-	err = useCase.Execute(context.Background(), request)
-	if err == nil {
-		t.Fatal("Expected error from upload")
+	// Separate: a zip per video, the missing one reported apart
+	if err := useCase.Execute(context.Background(), request); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if strings.Join(uploaded, ",") != "processed/frames_123_1.zip,processed/frames_123_3.zip" {
+		t.Errorf("Expected a zip per processed video, got %v", uploaded)
+	}
+	if len(deleted) > 0 {
+		t.Errorf("Expected the original videos kept, got %v", deleted)
+	}
+	var message struct {
+		BatchStatus string                   `json:"batch_status"`
+		FileKey     string                   `json:"file_key"`
+		Items       []domain.BatchItemResult `json:"items"`
+	}
+	if err := json.Unmarshal([]byte(sentMessage), &message); err != nil {
+		t.Fatalf("Failed to parse result: %v", err)
+	}
+	if message.BatchStatus != domain.BatchStatusPartial || len(message.Items) != 3 {
+		t.Fatalf("Expected a partial batch with 3 items, got %s", sentMessage)
+	}
+	if !message.Items[0].Success || message.Items[0].FileKey != "processed/frames_123_1.zip" || message.Items[0].Frames != 5 {
+		t.Errorf("Unexpected first item %+v", message.Items[0])
+	}
+	if message.Items[1].Success || !strings.Contains(message.Items[1].Error, "object not found") {
+		t.Errorf("Expected the missing video failed, got %+v", message.Items[1])
 	}
-}
 
-func TestExecute_DeleteOriginalVideoError(t *testing.T) {
-	if err := observability.InitLogger("test"); err != nil {
-		t.Fatalf("Failed to setup logger: %v", err)
+	// Combined: one zip with a folder per video
+	uploaded = nil
+	request.BatchOutput = domain.BatchOutputCombined
+	if err := useCase.Execute(context.Background(), request); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if strings.Join(uploaded, ",") != "processed/frames_123.zip" {
+		t.Errorf("Expected a single combined zip, got %v", uploaded)
+	}
+	if len(merger.groups) != 2 || merger.groups[0].Dir != "01_intro" || merger.groups[1].Dir != "03_outro" {
+		t.Errorf("Unexpected merged groups %+v", merger.groups)
+	}
+	if err := json.Unmarshal([]byte(sentMessage), &message); err != nil || message.FileKey != "processed/frames_123.zip" {
+		t.Errorf("Expected the result to point to the combined zip, got %s", sentMessage)
 	}
 
-	tmpFile, err := os.CreateTemp("", "test-video-*.mp4")
-	if err != nil {
-		t.Fatalf("Failed to create temp file: %v", err)
+	// Every video failed
+	request.VideoKeys = []string{"uploads/missing.mp4"}
+	if err := useCase.Execute(context.Background(), request); err == nil {
+		t.Error("Expected an error when no video of the batch succeeds")
 	}
-	tmpFile.WriteString("fake video content")
-	tmpFile.Close()
-	defer os.Remove(tmpFile.Name())
+	if !strings.Contains(sentMessage, `"batch_status":"failed"`) || !strings.Contains(sentMessage, "error_message") {
+		t.Errorf("Expected a failed batch error message, got %s", sentMessage)
+	}
+}
 
-	zipFile, err := os.CreateTemp("", "test-zip-*.zip")
-	if err != nil {
-		t.Fatalf("Failed to create zip file: %v", err)
+// mockConcatenator records the clips it joins into a fake video
+type mockConcatenator struct {
+	dir       string
+	clipPaths []string
+	err       error
+}
+
+func (m *mockConcatenator) Concat(ctx context.Context, jobID string, clipPaths []string, options domain.ConcatOptions) (string, error) {
+	if m.err != nil {
+		return "", m.err
+	}
+	m.clipPaths = clipPaths
+	videoPath := filepath.Join(m.dir, "concat_"+jobID+".mp4")
+	return videoPath, os.WriteFile(videoPath, []byte("merged video"), 0644)
+}
+
+func TestExecute_Concat(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
 	}
-	zipFile.WriteString("fake zip content")
-	zipFile.Close()
-	defer os.Remove(zipFile.Name())
 
+	var fetched, uploaded, deleted []string
 	storagePort := &mockStoragePort{
-		getObjectFunc: func(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
-			file, err := os.Open(tmpFile.Name())
-			return file, err
+		getObjectFunc: func(ctx context.Context, bucket, key string, options domain.GetOptions) (io.ReadCloser, error) {
+			fetched = append(fetched, key)
+			return io.NopCloser(strings.NewReader("fake video content")), nil
 		},
-		putObjectFunc: func(ctx context.Context, bucket, key string, body io.Reader) (string, error) {
-			return "s3://bucket/key", nil
+		putObjectFunc: func(ctx context.Context, bucket, key string, body io.Reader, options domain.PutOptions) (string, error) {
+			uploaded = append(uploaded, key)
+			return "etag", nil
 		},
 		deleteObjectFunc: func(ctx context.Context, bucket, key string) error {
-			return errors.New("delete failed")
+			deleted = append(deleted, key)
+			return nil
 		},
 	}
-
+	var sentMessage string
 	messagePort := &mockMessagePort{
 		sendMessageFunc: func(ctx context.Context, queueURL string, messageBody string) (string, error) {
-			return "msg-success", nil
-		},
-	}
-
-	videoProcessor := &mockVideoProcessor{
-		processVideoFunc: func(ctx context.Context, videoPath string) (string, int, error) {
-			return zipFile.Name(), 20, nil
+			sentMessage = messageBody
+			return "msg-id", nil
 		},
 	}
-
-	useCase := NewProcessVideoUseCase(storagePort, messagePort, videoProcessor, "output-bucket", "output-queue")
+	concatenator := &mockConcatenator{dir: t.TempDir()}
+	useCase := NewProcessVideoUseCase(storagePort, messagePort, &mockVideoProcessor{}, "output-bucket", "output-queue").
+		WithConcatenator(concatenator)
 
 	request := domain.VideoProcess{
-		ProcessID:   "process-delete",
+		ProcessID:   "123",
 		VideoBucket: "input-bucket",
-		VideoKey:    "video.mp4",
+		VideoKeys:   []string{"uploads/intro.mp4", "uploads/talk.mov", "uploads/intro.mp4"},
+		OutputType:  domain.OutputTypeConcat,
 	}
-
-	// Should succeed even if delete fails
-	err = useCase.Execute(context.Background(), request)
-	if err != nil {
-		t.Fatalf("Execute should succeed even with delete error: %v", err)
+	if err := useCase.Execute(context.Background(), request); err != nil {
+		t.Fatalf("Execute failed: %v", err)
 	}
-}
 
-func TestExecute_SendSuccessMessageError(t *testing.T) {
+	if strings.Join(fetched, ",") != strings.Join(request.VideoKeys, ",") {
+		t.Errorf("Expected every clip downloaded in order, got %v", fetched)
+	}
+	if len(concatenator.clipPaths) != 3 || filepath.Ext(concatenator.clipPaths[1]) != ".mov" {
+		t.Errorf("Expected the 3 local clips joined, got %v", concatenator.clipPaths)
+	}
+	for _, clipPath := range concatenator.clipPaths {
+		if _, err := os.Stat(clipPath); !os.IsNotExist(err) {
+			t.Errorf("Expected clip %s removed", clipPath)
+		}
+	}
+	if strings.Join(uploaded, ",") != "processed/concat_123.mp4" {
+		t.Errorf("Expected the joined video uploaded, got %v", uploaded)
+	}
+	if len(deleted) > 0 {
+		t.Errorf("Expected the clips kept, got %v", deleted)
+	}
+	if !strings.Contains(sentMessage, `"file_key":"processed/concat_123.mp4"`) || !strings.Contains(sentMessage, `"output_type":"concat"`) {
+		t.Errorf("Expected the result to point to the joined video, got %s", sentMessage)
+	}
+
+	uploaded = nil
+	concatenator.err = errors.New("clip 2 does not match the first clip")
+	if err := useCase.Execute(context.Background(), request); err == nil {
+		t.Error("Expected an error when the clips cannot be joined")
+	}
+	if len(uploaded) > 0 || !strings.Contains(sentMessage, "failed to concat videos") {
+		t.Errorf("Expected an error message and nothing uploaded, got %v and %s", uploaded, sentMessage)
+	}
+}
+
+// mockTrimmer writes a fake trimmed video
+type mockTrimmer struct {
+	dir     string
+	options domain.TrimOptions
+}
+
+func (m *mockTrimmer) Trim(ctx context.Context, jobID, videoPath string, options domain.TrimOptions) (string, error) {
+	m.options = options
+	trimmedPath := filepath.Join(m.dir, "trim_"+jobID+".mp4")
+	return trimmedPath, os.WriteFile(trimmedPath, []byte("trimmed video"), 0644)
+}
+
+func TestExecute_Trim(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	var uploaded, deleted []string
+	storagePort := &mockStoragePort{
+		getObjectFunc: func(ctx context.Context, bucket, key string, options domain.GetOptions) (io.ReadCloser, error) {
+			return io.NopCloser(strings.NewReader("fake video content")), nil
+		},
+		putObjectFunc: func(ctx context.Context, bucket, key string, body io.Reader, options domain.PutOptions) (string, error) {
+			uploaded = append(uploaded, key)
+			return "etag", nil
+		},
+		deleteObjectFunc: func(ctx context.Context, bucket, key string) error {
+			deleted = append(deleted, key)
+			return nil
+		},
+	}
+	var sentMessage string
+	messagePort := &mockMessagePort{
+		sendMessageFunc: func(ctx context.Context, queueURL string, messageBody string) (string, error) {
+			sentMessage = messageBody
+			return "msg-id", nil
+		},
+	}
+	trimmer := &mockTrimmer{dir: t.TempDir()}
+	useCase := NewProcessVideoUseCase(storagePort, messagePort, &mockVideoProcessor{}, "output-bucket", "output-queue").
+		WithTrimmer(trimmer)
+
+	request := domain.VideoProcess{
+		ProcessID:   "123",
+		VideoBucket: "input-bucket",
+		VideoKey:    "uploads/talk.mp4",
+		OutputType:  domain.OutputTypeTrim,
+		Trim:        domain.TrimOptions{Start: 5, End: 20},
+	}
+	if err := useCase.Execute(context.Background(), request); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	if trimmer.options != request.Trim {
+		t.Errorf("Expected the trim options passed through, got %+v", trimmer.options)
+	}
+	if strings.Join(uploaded, ",") != "processed/trim_123.mp4" {
+		t.Errorf("Expected the trimmed video uploaded, got %v", uploaded)
+	}
+	// Like the other outputs, the original is deleted once the result is sent
+	if strings.Join(deleted, ",") != "uploads/talk.mp4" {
+		t.Errorf("Expected the original video deleted, got %v", deleted)
+	}
+	if !strings.Contains(sentMessage, `"file_key":"processed/trim_123.mp4"`) || !strings.Contains(sentMessage, `"output_type":"trim"`) {
+		t.Errorf("Expected the result to point to the trimmed video, got %s", sentMessage)
+	}
+
+	request.DryRun = true
+	if err := useCase.Execute(context.Background(), request); err == nil {
+		t.Error("Expected error for a trim dry run")
+	}
+}
+
+// mockNormalizer writes a fake normalized video with fixed stats
+type mockNormalizer struct {
+	dir string
+}
+
+func (m *mockNormalizer) Normalize(ctx context.Context, jobID, videoPath string, options domain.LoudnessOptions) (string, domain.LoudnessStats, error) {
+	normalizedPath := filepath.Join(m.dir, "loudnorm_"+jobID+".mp4")
+	stats := domain.LoudnessStats{InputIntegrated: -27.5, OutputIntegrated: options.WithDefaults().Integrated}
+	return normalizedPath, stats, os.WriteFile(normalizedPath, []byte("normalized video"), 0644)
+}
+
+func TestExecute_Loudnorm(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	var uploaded []string
+	storagePort := &mockStoragePort{
+		getObjectFunc: func(ctx context.Context, bucket, key string, options domain.GetOptions) (io.ReadCloser, error) {
+			return io.NopCloser(strings.NewReader("fake video content")), nil
+		},
+		putObjectFunc: func(ctx context.Context, bucket, key string, body io.Reader, options domain.PutOptions) (string, error) {
+			uploaded = append(uploaded, key)
+			return "etag", nil
+		},
+		deleteObjectFunc: func(ctx context.Context, bucket, key string) error {
+			return nil
+		},
+	}
+	var sentMessage string
+	messagePort := &mockMessagePort{
+		sendMessageFunc: func(ctx context.Context, queueURL string, messageBody string) (string, error) {
+			sentMessage = messageBody
+			return "msg-id", nil
+		},
+	}
+	request := domain.VideoProcess{
+		ProcessID:   "123",
+		VideoBucket: "input-bucket",
+		VideoKey:    "uploads/talk.mp4",
+		OutputType:  domain.OutputTypeLoudnorm,
+		Loudness:    domain.LoudnessOptions{Integrated: -16},
+	}
+
+	useCase := NewProcessVideoUseCase(storagePort, messagePort, &mockVideoProcessor{}, "output-bucket", "output-queue")
+	if err := useCase.Execute(context.Background(), request); err == nil {
+		t.Error("Expected error for loudnorm output without a normalizer")
+	}
+
+	useCase.WithAudioNormalizer(&mockNormalizer{dir: t.TempDir()})
+	if err := useCase.Execute(context.Background(), request); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if strings.Join(uploaded, ",") != "processed/loudnorm_123.mp4" {
+		t.Errorf("Expected the normalized video uploaded, got %v", uploaded)
+	}
+	if !strings.Contains(sentMessage, `"loudness":{"input_integrated":-27.5,`) || !strings.Contains(sentMessage, `"output_integrated":-16`) {
+		t.Errorf("Expected the measured loudness in the result, got %s", sentMessage)
+	}
+}
+
+// mockTranscription records the transcription it was asked to start
+type mockTranscription struct {
+	request domain.TranscriptionRequest
+	err     error
+}
+
+func (m *mockTranscription) StartTranscription(ctx context.Context, request domain.TranscriptionRequest) (domain.TranscriptionRef, error) {
+	m.request = request
+	if m.err != nil {
+		return domain.TranscriptionRef{}, m.err
+	}
+	return domain.TranscriptionRef{Provider: domain.TranscriptionProviderTranscribe, JobName: request.JobName, TranscriptKey: request.TranscriptKey}, nil
+}
+
+func TestExecute_Transcription(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	var deleted []string
+	storagePort := &mockStoragePort{
+		getObjectFunc: func(ctx context.Context, bucket, key string, options domain.GetOptions) (io.ReadCloser, error) {
+			return io.NopCloser(strings.NewReader("fake video content")), nil
+		},
+		putObjectFunc: func(ctx context.Context, bucket, key string, body io.Reader, options domain.PutOptions) (string, error) {
+			return "etag", nil
+		},
+		deleteObjectFunc: func(ctx context.Context, bucket, key string) error {
+			deleted = append(deleted, key)
+			return nil
+		},
+	}
+	var sentMessage string
+	messagePort := &mockMessagePort{
+		sendMessageFunc: func(ctx context.Context, queueURL string, messageBody string) (string, error) {
+			sentMessage = messageBody
+			return "msg-id", nil
+		},
+	}
+	request := domain.VideoProcess{
+		ProcessID:     "123",
+		VideoBucket:   "input-bucket",
+		VideoKey:      "uploads/talk.mp4",
+		OutputType:    domain.OutputTypeLoudnorm,
+		Transcription: domain.TranscriptionOptions{Enabled: true, Language: "pt-BR"},
+		KeepOriginal:  true,
+	}
+
+	useCase := NewProcessVideoUseCase(storagePort, messagePort, &mockVideoProcessor{}, "output-bucket", "output-queue").
+		WithAudioNormalizer(&mockNormalizer{dir: t.TempDir()})
+	if err := useCase.Execute(context.Background(), request); err == nil {
+		t.Error("Expected error for a transcription without a provider")
+	}
+
+	transcription := &mockTranscription{}
+	useCase.WithTranscription(transcription)
+	if err := useCase.Execute(context.Background(), request); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	started := transcription.request
+	if started.MediaBucket != "output-bucket" || started.MediaKey != "processed/loudnorm_123.mp4" || started.Language != "pt-BR" {
+		t.Errorf("Expected the normalized video transcribed, got %+v", started)
+	}
+	if started.TranscriptKey != "processed/transcript_123.json" || !strings.HasPrefix(started.JobName, "123_") {
+		t.Errorf("Expected the transcript next to the output, got %+v", started)
+	}
+	if !strings.Contains(sentMessage, `"transcription":{"provider":"transcribe","job_name":"`+started.JobName+`","transcript_key":"processed/transcript_123.json"}`) {
+		t.Errorf("Expected the transcription in the result, got %s", sentMessage)
+	}
+
+	transcription.err = errors.New("throttled")
+	if err := useCase.Execute(context.Background(), request); !domain.IsTransient(err) {
+		t.Errorf("Expected a transient error, got %v", err)
+	}
+	if !strings.Contains(sentMessage, "failed to start transcription") {
+		t.Errorf("Expected an error result, got %s", sentMessage)
+	}
+	if strings.Join(deleted, ",") != "processed/loudnorm_123.mp4" {
+		t.Errorf("Expected the output removed, got %v", deleted)
+	}
+}
+
+// mockFingerprinter writes a fake fingerprint
+type mockFingerprinter struct {
+	dir string
+}
+
+func (m *mockFingerprinter) Fingerprint(ctx context.Context, jobID, videoPath string, options domain.FingerprintOptions) (string, error) {
+	fingerprintPath := filepath.Join(m.dir, "fingerprint_"+jobID+".json")
+	return fingerprintPath, os.WriteFile(fingerprintPath, []byte(`{"version":1,"frames":[]}`), 0644)
+}
+
+func TestExecute_Fingerprint(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	var uploaded []string
+	var uploadedBody string
+	storagePort := &mockStoragePort{
+		getObjectFunc: func(ctx context.Context, bucket, key string, options domain.GetOptions) (io.ReadCloser, error) {
+			return io.NopCloser(strings.NewReader("fake video content")), nil
+		},
+		putObjectFunc: func(ctx context.Context, bucket, key string, body io.Reader, options domain.PutOptions) (string, error) {
+			uploaded = append(uploaded, key)
+			data, _ := io.ReadAll(body)
+			uploadedBody = string(data)
+			return "etag", nil
+		},
+		deleteObjectFunc: func(ctx context.Context, bucket, key string) error {
+			return nil
+		},
+	}
+	var sentMessage string
+	messagePort := &mockMessagePort{
+		sendMessageFunc: func(ctx context.Context, queueURL string, messageBody string) (string, error) {
+			sentMessage = messageBody
+			return "msg-id", nil
+		},
+	}
+	request := domain.VideoProcess{
+		ProcessID:   "123",
+		VideoBucket: "input-bucket",
+		VideoKey:    "uploads/talk.mp4",
+		OutputType:  domain.OutputTypeFingerprint,
+	}
+
+	useCase := NewProcessVideoUseCase(storagePort, messagePort, &mockVideoProcessor{}, "output-bucket", "output-queue")
+	if err := useCase.Execute(context.Background(), request); err == nil {
+		t.Error("Expected error for fingerprint output without a fingerprinter")
+	}
+
+	useCase.WithFingerprinter(&mockFingerprinter{dir: t.TempDir()})
+	if err := useCase.Execute(context.Background(), request); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if strings.Join(uploaded, ",") != "processed/fingerprint_123.json" || !strings.Contains(uploadedBody, `"version":1`) {
+		t.Errorf("Expected the fingerprint uploaded, got %v", uploaded)
+	}
+	if !strings.Contains(sentMessage, `"file_key":"processed/fingerprint_123.json"`) || !strings.Contains(sentMessage, `"output_type":"fingerprint"`) {
+		t.Errorf("Expected the fingerprint in the result, got %s", sentMessage)
+	}
+}
+
+// mockBarcodeScanner reports a QR code at 0s and 2s, or fails with err
+type mockBarcodeScanner struct {
+	err error
+}
+
+func (m *mockBarcodeScanner) ScanBarcodes(ctx context.Context, jobID, videoPath string, options domain.BarcodeOptions) ([]domain.BarcodeDetection, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return []domain.BarcodeDetection{{Format: "qr_code", Value: "ticket-42", Timestamps: []float64{0, 2}}}, nil
+}
+
+func TestExecute_Barcodes(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	var uploaded []string
+	storagePort := &mockStoragePort{
+		getObjectFunc: func(ctx context.Context, bucket, key string, options domain.GetOptions) (io.ReadCloser, error) {
+			return io.NopCloser(strings.NewReader("fake video content")), nil
+		},
+		putObjectFunc: func(ctx context.Context, bucket, key string, body io.Reader, options domain.PutOptions) (string, error) {
+			uploaded = append(uploaded, key)
+			return "etag", nil
+		},
+		deleteObjectFunc: func(ctx context.Context, bucket, key string) error {
+			return nil
+		},
+	}
+	var sentMessage string
+	messagePort := &mockMessagePort{
+		sendMessageFunc: func(ctx context.Context, queueURL string, messageBody string) (string, error) {
+			sentMessage = messageBody
+			return "msg-id", nil
+		},
+	}
+	dir := t.TempDir()
+	videoProcessor := &mockVideoProcessor{
+		processVideoFunc: func(ctx context.Context, jobID, videoPath string, options domain.FrameOptions) ([]string, int, error) {
+			zipPath := filepath.Join(dir, jobID+".zip")
+			return []string{zipPath}, 5, os.WriteFile(zipPath, []byte("fake zip content"), 0644)
+		},
+	}
+	request := domain.VideoProcess{
+		ProcessID:   "123",
+		VideoBucket: "input-bucket",
+		VideoKey:    "uploads/event.mp4",
+		Barcodes:    domain.BarcodeOptions{Scan: true},
+	}
+
+	useCase := NewProcessVideoUseCase(storagePort, messagePort, videoProcessor, "output-bucket", "output-queue")
+	if err := useCase.Execute(context.Background(), request); err == nil {
+		t.Error("Expected error for barcode scanning without a scanner")
+	}
+
+	useCase.WithBarcodeScanner(&mockBarcodeScanner{})
+	if err := useCase.Execute(context.Background(), request); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if len(uploaded) != 1 || !strings.HasSuffix(uploaded[0], ".zip") {
+		t.Errorf("Expected the frames uploaded, got %v", uploaded)
+	}
+	if !strings.Contains(sentMessage, `"barcodes":[{"format":"qr_code","value":"ticket-42","timestamps":[0,2]}]`) {
+		t.Errorf("Expected the barcodes in the result, got %s", sentMessage)
+	}
+
+	uploaded = nil
+	useCase.WithBarcodeScanner(&mockBarcodeScanner{err: errors.New("decoder crashed")})
+	useCase.Execute(context.Background(), request)
+	if len(uploaded) != 0 {
+		t.Errorf("Expected nothing uploaded after a failed scan, got %v", uploaded)
+	}
+	if !strings.Contains(sentMessage, "failed to scan barcodes") {
+		t.Errorf("Expected the scan error in the result, got %s", sentMessage)
+	}
+}
+
+// mockVisionAnalyzer flags the first sampled frame
+type mockVisionAnalyzer struct {
+	config domain.VisionConfig
+}
+
+func (m *mockVisionAnalyzer) AnalyzeVision(ctx context.Context, jobID, videoPath string, config domain.VisionConfig) (domain.VisionReport, error) {
+	m.config = config
+	return domain.VisionReport{
+		Flagged: true,
+		Frames:  []domain.VisionFrame{{Time: 0, Moderation: []domain.VisionLabel{{Name: "Weapons", Confidence: 80}}}},
+	}, nil
+}
+
+func TestExecute_Vision(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	uploaded := map[string]string{}
+	storagePort := &mockStoragePort{
+		getObjectFunc: func(ctx context.Context, bucket, key string, options domain.GetOptions) (io.ReadCloser, error) {
+			return io.NopCloser(strings.NewReader("fake video content")), nil
+		},
+		putObjectFunc: func(ctx context.Context, bucket, key string, body io.Reader, options domain.PutOptions) (string, error) {
+			data, _ := io.ReadAll(body)
+			uploaded[key] = string(data)
+			return "etag", nil
+		},
+		deleteObjectFunc: func(ctx context.Context, bucket, key string) error {
+			return nil
+		},
+	}
+	var sentMessage string
+	messagePort := &mockMessagePort{
+		sendMessageFunc: func(ctx context.Context, queueURL string, messageBody string) (string, error) {
+			sentMessage = messageBody
+			return "msg-id", nil
+		},
+	}
+	dir := t.TempDir()
+	videoProcessor := &mockVideoProcessor{
+		processVideoFunc: func(ctx context.Context, jobID, videoPath string, options domain.FrameOptions) ([]string, int, error) {
+			zipPath := filepath.Join(dir, jobID+".zip")
+			return []string{zipPath}, 5, os.WriteFile(zipPath, []byte("fake zip content"), 0644)
+		},
+	}
+	tenants, err := domain.ParseTenantRegistry([]byte(`{"acme":{"vision":{"features":["moderation"]}},"globex":{"vision":{"features":["labels"],"delivery":"artifact"}}}`))
+	if err != nil {
+		t.Fatalf("ParseTenantRegistry failed: %v", err)
+	}
+	request := domain.VideoProcess{
+		ProcessID:   "123",
+		TenantID:    "acme",
+		VideoBucket: "input-bucket",
+		VideoKey:    "uploads/event.mp4",
+	}
+
+	useCase := NewProcessVideoUseCase(storagePort, messagePort, videoProcessor, "output-bucket", "output-queue").WithTenantRegistry(tenants)
+	if err := useCase.Execute(context.Background(), request); err == nil {
+		t.Error("Expected error for a vision tenant without an analyzer")
+	}
+
+	analyzer := &mockVisionAnalyzer{}
+	useCase.WithVisionAnalyzer(analyzer)
+	if err := useCase.Execute(context.Background(), request); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if !analyzer.config.HasFeature(domain.VisionFeatureModeration) {
+		t.Errorf("Expected the tenant config passed to the analyzer, got %+v", analyzer.config)
+	}
+	if !strings.Contains(sentMessage, `"vision":{"flagged":true,"frames":[{"time":0,"moderation":[{"name":"Weapons","confidence":80}]}]}`) {
+		t.Errorf("Expected the vision report in the result, got %s", sentMessage)
+	}
+
+	request.TenantID = "globex"
+	if err := useCase.Execute(context.Background(), request); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if !strings.Contains(uploaded["processed/vision_123.json"], `"flagged":true`) {
+		t.Errorf("Expected the vision artifact uploaded, got %v", uploaded)
+	}
+	if !strings.Contains(sentMessage, `"vision_key":"processed/vision_123.json"`) || strings.Contains(sentMessage, `"vision":`) {
+		t.Errorf("Expected only the artifact key in the result, got %s", sentMessage)
+	}
+
+	analyzer.config = domain.VisionConfig{}
+	request.TenantID = ""
+	if err := useCase.Execute(context.Background(), request); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if analyzer.config.Features != nil || strings.Contains(sentMessage, "vision") {
+		t.Errorf("Expected no analysis for a tenant without vision, got %s", sentMessage)
+	}
+}
+
+// mockQualityAnalyzer reports a fixed black interval
+type mockQualityAnalyzer struct {
+	options domain.QCOptions
+}
+
+func (m *mockQualityAnalyzer) AnalyzeQuality(ctx context.Context, videoPath string, options domain.QCOptions) (domain.QCReport, error) {
+	m.options = options
+	return domain.QCReport{
+		DurationSeconds:  60,
+		HasAudio:         true,
+		BlackIntervals:   []domain.TimeWindow{{Start: 0, End: 2.5}},
+		SilenceIntervals: []domain.TimeWindow{},
+	}, nil
+}
+
+func TestExecute_QC(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	var uploaded, deleted []string
+	storagePort := &mockStoragePort{
+		getObjectFunc: func(ctx context.Context, bucket, key string, options domain.GetOptions) (io.ReadCloser, error) {
+			return io.NopCloser(strings.NewReader("fake video content")), nil
+		},
+		putObjectFunc: func(ctx context.Context, bucket, key string, body io.Reader, options domain.PutOptions) (string, error) {
+			uploaded = append(uploaded, key)
+			return "etag", nil
+		},
+		deleteObjectFunc: func(ctx context.Context, bucket, key string) error {
+			deleted = append(deleted, key)
+			return nil
+		},
+	}
+	var sentMessage string
+	messagePort := &mockMessagePort{
+		sendMessageFunc: func(ctx context.Context, queueURL string, messageBody string) (string, error) {
+			sentMessage = messageBody
+			return "msg-id", nil
+		},
+	}
+	request := domain.VideoProcess{
+		ProcessID:   "123",
+		VideoBucket: "input-bucket",
+		VideoKey:    "uploads/talk.mp4",
+		OutputType:  domain.OutputTypeQC,
+		QC:          domain.QCOptions{SilenceNoiseDB: -35},
+	}
+
+	useCase := NewProcessVideoUseCase(storagePort, messagePort, &mockVideoProcessor{}, "output-bucket", "output-queue")
+	if err := useCase.Execute(context.Background(), request); err == nil {
+		t.Error("Expected error for qc output without an analyzer")
+	}
+
+	analyzer := &mockQualityAnalyzer{}
+	useCase.WithQualityAnalyzer(analyzer)
+	if err := useCase.Execute(context.Background(), request); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if analyzer.options.SilenceNoiseDB != -35 {
+		t.Errorf("Expected the request thresholds, got %+v", analyzer.options)
+	}
+	if len(uploaded) != 0 || len(deleted) != 0 {
+		t.Errorf("Expected no upload and the original kept, got %v and %v", uploaded, deleted)
+	}
+	if !strings.Contains(sentMessage, `"qc":{"duration_seconds":60,"has_audio":true,"black_intervals":[{"start":0,"end":2.5}],"silence_intervals":[]}`) {
+		t.Errorf("Expected the qc report in the result, got %s", sentMessage)
+	}
+	if strings.Contains(sentMessage, "file_key") {
+		t.Errorf("Expected no file in the result, got %s", sentMessage)
+	}
+
+	request.DryRun = true
+	if err := useCase.Execute(context.Background(), request); err == nil {
+		t.Error("Expected error for a qc dry run")
+	}
+}
+
+// mockURLSource serves body for the URLs on host
+type mockURLSource struct {
+	host string
+	body string
+	urls []string
+}
+
+func (m *mockURLSource) Allows(videoURL string) bool {
+	return strings.HasPrefix(videoURL, "https://"+m.host+"/")
+}
+
+func (m *mockURLSource) GetURL(ctx context.Context, videoURL string) (io.ReadCloser, error) {
+	m.urls = append(m.urls, videoURL)
+	return io.NopCloser(strings.NewReader(m.body)), nil
+}
+
+func TestExecute_VideoURL(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	zipFile, err := os.CreateTemp("", "test-zip-*.zip")
+	if err != nil {
+		t.Fatalf("Failed to create zip file: %v", err)
+	}
+	zipFile.WriteString("fake zip content")
+	zipFile.Close()
+	defer os.Remove(zipFile.Name())
+
+	storagePort := &mockStoragePort{
+		getObjectFunc: func(ctx context.Context, bucket, key string, options domain.GetOptions) (io.ReadCloser, error) {
+			t.Errorf("Expected the video read from the url, got get of %s/%s", bucket, key)
+			return nil, errors.New("unexpected get")
+		},
+		deleteObjectFunc: func(ctx context.Context, bucket, key string) error {
+			t.Errorf("Expected the video read from the url kept, got delete of %s/%s", bucket, key)
+			return nil
+		},
+	}
+
+	var downloaded, videoExt string
+	videoProcessor := &mockVideoProcessor{
+		processVideoFunc: func(ctx context.Context, jobID, videoPath string, options domain.FrameOptions) ([]string, int, error) {
+			content, _ := os.ReadFile(videoPath)
+			downloaded = string(content)
+			videoExt = filepath.Ext(videoPath)
+			return []string{zipFile.Name()}, 20, nil
+		},
+	}
+
+	var sentMessages []string
+	messagePort := &mockMessagePort{
+		sendMessageFunc: func(ctx context.Context, queueURL string, messageBody string) (string, error) {
+			sentMessages = append(sentMessages, messageBody)
+			return "msg-id", nil
+		},
+	}
+
+	tenants, err := domain.ParseTenantRegistry([]byte(`{"acme":{"allowed_sources":["uploads/acme/"]}}`))
+	if err != nil {
+		t.Fatalf("ParseTenantRegistry failed: %v", err)
+	}
+	urlSource := &mockURLSource{host: "videos.example.com", body: "video from url"}
+	useCase := NewProcessVideoUseCase(storagePort, messagePort, videoProcessor, "output-bucket", "output-queue").
+		WithURLSource(urlSource).
+		WithTenantRegistry(tenants)
+
+	videoURL := "https://videos.example.com/uploads/video.mov?X-Amz-Signature=secret"
+	if err := useCase.Execute(context.Background(), domain.VideoProcess{ProcessID: "process-url", VideoURL: videoURL}); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if len(urlSource.urls) != 1 || urlSource.urls[0] != videoURL {
+		t.Errorf("Expected the video read from %s, got %v", videoURL, urlSource.urls)
+	}
+	if downloaded != "video from url" || videoExt != ".mov" {
+		t.Errorf("Expected the url body saved as a .mov file, got %q as %q", downloaded, videoExt)
+	}
+
+	rejected := []domain.VideoProcess{
+		{ProcessID: "process-host", VideoURL: "https://internal.local/video.mp4"},
+		{ProcessID: "process-tenant", VideoURL: videoURL, TenantID: "acme"},
+	}
+	for _, request := range rejected {
+		sentMessages = nil
+		if err := useCase.Execute(context.Background(), request); err == nil {
+			t.Errorf("Expected error for %s", request.ProcessID)
+		}
+		if len(sentMessages) != 1 || !strings.Contains(sentMessages[0], domain.ErrorCodeSourceNotAllowed) {
+			t.Fatalf("Expected %s error message, got: %v", domain.ErrorCodeSourceNotAllowed, sentMessages)
+		}
+		if strings.Contains(sentMessages[0], "secret") {
+			t.Errorf("Expected the url signature kept out of the result, got: %s", sentMessages[0])
+		}
+	}
+	if len(urlSource.urls) != 1 {
+		t.Errorf("Expected rejected urls not to be read, got %v", urlSource.urls)
+	}
+}
+
+func TestReject(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	var sentMessage string
+	messagePort := &mockMessagePort{
+		sendMessageFunc: func(ctx context.Context, queueURL string, messageBody string) (string, error) {
+			sentMessage = messageBody
+			return "msg-id", nil
+		},
+	}
+
+	useCase := NewProcessVideoUseCase(nil, messagePort, nil, "test-bucket", "test-queue")
+	request := domain.VideoProcess{
+		ProcessID: "123",
+		Metadata:  map[string]json.RawMessage{"order": json.RawMessage(`"A-1"`)},
+	}
+
+	if err := useCase.Reject(context.Background(), request, errors.New("unsupported outputs")); err == nil {
+		t.Error("Expected the rejection error")
+	}
+	if !strings.Contains(sentMessage, `"error_message":"unsupported outputs"`) || !strings.Contains(sentMessage, `"metadata":{"order":"A-1"}`) {
+		t.Errorf("Expected error message with metadata, got: %s", sentMessage)
+	}
+}
+
+func TestExecute_Cancelled(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	var deleted []string
+	storagePort := &mockStoragePort{
+		getObjectFunc: func(ctx context.Context, bucket, key string, options domain.GetOptions) (io.ReadCloser, error) {
+			if bucket != "test-bucket" || key != "cancellations/123.json" {
+				t.Errorf("Expected only the cancellation marker read, got %s/%s", bucket, key)
+			}
+			return io.NopCloser(strings.NewReader(`{"process_id":"123"}`)), nil
+		},
+		deleteObjectFunc: func(ctx context.Context, bucket, key string) error {
+			deleted = append(deleted, bucket+"/"+key)
+			return nil
+		},
+	}
+
+	var sentMessage string
+	messagePort := &mockMessagePort{
+		sendMessageFunc: func(ctx context.Context, queueURL string, messageBody string) (string, error) {
+			sentMessage = messageBody
+			return "msg-id", nil
+		},
+	}
+
+	useCase := NewProcessVideoUseCase(storagePort, messagePort, nil, "test-bucket", "test-queue").WithCancellations(true)
+	err := useCase.Execute(context.Background(), domain.VideoProcess{ProcessID: "123", VideoBucket: "input", VideoKey: "video.mp4"})
+	if domain.ErrorCode(err) != domain.ErrorCodeCancelled {
+		t.Errorf("Expected the cancelled error, got %v", err)
+	}
+	if !strings.Contains(sentMessage, `"error_code":"cancelled"`) {
+		t.Errorf("Expected a cancelled error message, got: %s", sentMessage)
+	}
+	if len(deleted) != 1 || deleted[0] != "test-bucket/cancellations/123.json" {
+		t.Errorf("Expected only the marker deleted, got %v", deleted)
+	}
+}
+
+func TestExecute_RetriesTransientFailures(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	storagePort := &mockStoragePort{
+		getObjectFunc: func(ctx context.Context, bucket, key string, options domain.GetOptions) (io.ReadCloser, error) {
+			if key == "missing.mp4" {
+				return nil, domain.ErrObjectNotFound
+			}
+			return nil, errors.New("connection reset")
+		},
+	}
+
+	var sentMessage map[string]interface{}
+	messagePort := &mockMessagePort{
+		sendMessageFunc: func(ctx context.Context, queueURL string, messageBody string) (string, error) {
+			sentMessage = nil
+			json.Unmarshal([]byte(messageBody), &sentMessage)
+			return "msg-id", nil
+		},
+	}
+
+	useCase := NewProcessVideoUseCase(storagePort, messagePort, nil, "test-bucket", "test-queue").WithRetries(3)
+
+	tests := []struct {
+		name      string
+		key       string
+		attempt   int
+		willRetry bool
+	}{
+		{"transient failure before the last attempt", "video.mp4", 1, true},
+		{"transient failure on the last attempt", "video.mp4", 3, false},
+		{"missing video", "missing.mp4", 1, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := useCase.Execute(context.Background(), domain.VideoProcess{
+				ProcessID:   "123",
+				VideoBucket: "input",
+				VideoKey:    tt.key,
+				Attempt:     tt.attempt,
+			})
+			if err == nil {
+				t.Fatal("Expected the download error")
+			}
+			if errors.Is(err, domain.ErrMessageNotHandled) != tt.willRetry {
+				t.Errorf("Expected the message left in the queue: %v, got %v", tt.willRetry, err)
+			}
+			if sentMessage["attempt"] != float64(tt.attempt) || sentMessage["max_attempts"] != float64(3) || sentMessage["will_retry"] != tt.willRetry {
+				t.Errorf("Unexpected retry fields in %v", sentMessage)
+			}
+		})
+	}
+
+	// Without the delivery count the result carries no retry fields
+	useCase.Execute(context.Background(), domain.VideoProcess{ProcessID: "123", VideoBucket: "input", VideoKey: "video.mp4"})
+	if _, ok := sentMessage["will_retry"]; ok {
+		t.Errorf("Expected no retry fields without the attempt, got %v", sentMessage)
+	}
+}
+
+func TestExecute_SLABreached(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	storagePort := &mockStoragePort{
+		getObjectFunc: func(ctx context.Context, bucket, key string, options domain.GetOptions) (io.ReadCloser, error) {
+			return nil, domain.ErrObjectNotFound
+		},
+	}
+
+	var sentMessage map[string]interface{}
+	messagePort := &mockMessagePort{
+		sendMessageFunc: func(ctx context.Context, queueURL string, messageBody string) (string, error) {
+			sentMessage = nil
+			json.Unmarshal([]byte(messageBody), &sentMessage)
+			return "msg-id", nil
+		},
+	}
+
+	useCase := NewProcessVideoUseCase(storagePort, messagePort, nil, "test-bucket", "test-queue").WithSLA(time.Hour)
+
+	tests := []struct {
+		name       string
+		enqueuedAt time.Time
+		breached   bool
+	}{
+		{"within the SLA", time.Now().Add(-time.Minute), false},
+		{"past the SLA", time.Now().Add(-2 * time.Hour), true},
+		{"unknown submission", time.Time{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useCase.Execute(context.Background(), domain.VideoProcess{
+				ProcessID:   "123",
+				VideoBucket: "input",
+				VideoKey:    "video.mp4",
+				EnqueuedAt:  tt.enqueuedAt,
+			})
+			if _, ok := sentMessage["sla_breached"]; ok != tt.breached {
+				t.Errorf("Expected sla_breached %v, got %v", tt.breached, sentMessage)
+			}
+		})
+	}
+}
+
+func TestExecute_ReportsUsage(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	storagePort := &mockStoragePort{
+		getObjectFunc: func(ctx context.Context, bucket, key string, options domain.GetOptions) (io.ReadCloser, error) {
+			return nil, domain.ErrObjectNotFound
+		},
+	}
+
+	var sentMessage map[string]interface{}
+	messagePort := &mockMessagePort{
+		sendMessageFunc: func(ctx context.Context, queueURL string, messageBody string) (string, error) {
+			sentMessage = nil
+			json.Unmarshal([]byte(messageBody), &sentMessage)
+			return "msg-id", nil
+		},
+	}
+
+	request := domain.VideoProcess{ProcessID: "123", VideoBucket: "input", VideoKey: "video.mp4"}
+	useCase := NewProcessVideoUseCase(storagePort, messagePort, nil, "test-bucket", "test-queue")
+
+	useCase.WithUsage(domain.CostModel{S3GetPer1000: 1}, false).Execute(context.Background(), request)
+	if _, ok := sentMessage["usage"]; ok {
+		t.Errorf("Expected no usage unless reported, got %v", sentMessage)
+	}
+
+	useCase.WithUsage(domain.CostModel{S3GetPer1000: 1}, true).Execute(context.Background(), request)
+	usage, ok := sentMessage["usage"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected the usage in the result, got %v", sentMessage)
+	}
+	// The failed get is billed as well
+	if usage["s3_get_requests"] != float64(1) || usage["s3_put_requests"] != float64(0) || usage["estimated_cost"] != 0.001 {
+		t.Errorf("Unexpected usage %v", usage)
+	}
+}
+
+// hungReader stands for an S3 body whose connection stalled: reads block until the request
+// context ends
+type hungReader struct {
+	ctx context.Context
+}
+
+func (r hungReader) Read(p []byte) (int, error) {
+	<-r.ctx.Done()
+	return 0, r.ctx.Err()
+}
+
+func TestExecute_StageTimeouts(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	var sentMessage string
+	messagePort := &mockMessagePort{
+		sendMessageFunc: func(ctx context.Context, queueURL string, messageBody string) (string, error) {
+			sentMessage = messageBody
+			return "msg-id", nil
+		},
+	}
+	request := domain.VideoProcess{ProcessID: "123", VideoBucket: "input", VideoKey: "video.mp4"}
+
+	hungDownload := &mockStoragePort{
+		getObjectFunc: func(ctx context.Context, bucket, key string, options domain.GetOptions) (io.ReadCloser, error) {
+			return io.NopCloser(hungReader{ctx}), nil
+		},
+	}
+	useCase := NewProcessVideoUseCase(hungDownload, messagePort, nil, "output-bucket", "output-queue").
+		WithStageTimeouts(domain.StageTimeouts{Download: 10 * time.Millisecond})
+	err := useCase.Execute(context.Background(), request)
+	if domain.ErrorCode(err) != domain.ErrorCodeTimeout || !strings.Contains(err.Error(), "download timed out") {
+		t.Errorf("Expected a download timeout, got %v", err)
+	}
+	if !strings.Contains(sentMessage, `"error_code":"timeout"`) {
+		t.Errorf("Expected a timeout error message, got: %s", sentMessage)
+	}
+
+	download := &mockStoragePort{
+		getObjectFunc: func(ctx context.Context, bucket, key string, options domain.GetOptions) (io.ReadCloser, error) {
+			return io.NopCloser(strings.NewReader("fake video content")), nil
+		},
+	}
+	hungProcessor := &mockVideoProcessor{
+		processVideoFunc: func(ctx context.Context, jobID, videoPath string, options domain.FrameOptions) ([]string, int, error) {
+			<-ctx.Done()
+			return nil, 0, ctx.Err()
+		},
+	}
+	useCase = NewProcessVideoUseCase(download, messagePort, hungProcessor, "output-bucket", "output-queue").
+		WithStageTimeouts(domain.StageTimeouts{Download: time.Minute, Processing: 10 * time.Millisecond})
+	err = useCase.Execute(context.Background(), request)
+	if domain.ErrorCode(err) != domain.ErrorCodeTimeout || !strings.Contains(err.Error(), "processing timed out") {
+		t.Errorf("Expected a processing timeout, got %v", err)
+	}
+
+	// A job context cancelled from outside is not reported as a stage timeout
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	useCase = NewProcessVideoUseCase(hungDownload, messagePort, nil, "output-bucket", "output-queue").
+		WithStageTimeouts(domain.StageTimeouts{Download: time.Minute})
+	if err := useCase.Execute(ctx, request); domain.ErrorCode(err) == domain.ErrorCodeTimeout {
+		t.Errorf("Expected the cancellation reported as is, got %v", err)
+	}
+}
+
+func TestExecute_StorageError(t *testing.T) {
+	storagePort := &mockStoragePort{
+		getObjectFunc: func(ctx context.Context, bucket, key string, options domain.GetOptions) (io.ReadCloser, error) {
+			return nil, errors.New("storage error")
+		},
+	}
+
+	var sentMessage string
+	messagePort := &mockMessagePort{
+		sendMessageFunc: func(ctx context.Context, queueURL string, messageBody string) (string, error) {
+			sentMessage = messageBody
+			return "msg-id", nil
+		},
+	}
+
+	useCase := NewProcessVideoUseCase(storagePort, messagePort, nil, "test-bucket", "test-queue")
+
+	request := domain.VideoProcess{
+		ProcessID:   "123",
+		VideoBucket: "test-bucket",
+		VideoKey:    "video.mp4",
+	}
+
+	err := useCase.Execute(context.Background(), request)
+	if err == nil {
+		t.Fatal("Expected error when storage fails")
+	}
+
+	if !strings.Contains(sentMessage, "error_message") {
+		t.Errorf("Expected error message to be sent, got: %s", sentMessage)
+	}
+}
+
+func TestExecute_FullSuccess(t *testing.T) {
+	// Initialize logger
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	tmpFile, err := os.CreateTemp("", "test-video-*.mp4")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	tmpFile.WriteString("fake video content")
+	tmpFile.Close()
+	defer os.Remove(tmpFile.Name())
+
+	zipFile, err := os.CreateTemp("", "test-zip-*.zip")
+	if err != nil {
+		t.Fatalf("Failed to create zip file: %v", err)
+	}
+	zipFile.WriteString("fake zip content")
+	zipFile.Close()
+	defer os.Remove(zipFile.Name())
+
+	storagePort := &mockStoragePort{
+		getObjectFunc: func(ctx context.Context, bucket, key string, options domain.GetOptions) (io.ReadCloser, error) {
+			file, err := os.Open(tmpFile.Name())
+			return file, err
+		},
+		putObjectFunc: func(ctx context.Context, bucket, key string, body io.Reader, options domain.PutOptions) (string, error) {
+			return "s3://bucket/key", nil
+		},
+		deleteObjectFunc: func(ctx context.Context, bucket, key string) error {
+			return nil
+		},
+	}
+
+	messagePort := &mockMessagePort{
+		sendMessageFunc: func(ctx context.Context, queueURL string, messageBody string) (string, error) {
+			return "msg-success", nil
+		},
+	}
+
+	videoProcessor := &mockVideoProcessor{
+		processVideoFunc: func(ctx context.Context, jobID, videoPath string, options domain.FrameOptions) ([]string, int, error) {
+			return []string{zipFile.Name()}, 30, nil
+		},
+	}
+
+	useCase := NewProcessVideoUseCase(storagePort, messagePort, videoProcessor, "output-bucket", "output-queue")
+
+	request := domain.VideoProcess{
+		ProcessID:   "process-123",
+		VideoBucket: "input-bucket",
+		VideoKey:    "video.mp4",
+	}
+
+	err = useCase.Execute(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+}
+
+func TestExecute_RecordsTimeline(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	zipPath := filepath.Join(t.TempDir(), "frames.zip")
+	os.WriteFile(zipPath, []byte("fake zip content"), 0o644)
+
+	store, storagePort := newMemoryStorage()
+	store.objects["input-bucket/video.mp4"] = []byte("fake video content")
+
+	videoProcessor := &mockVideoProcessor{
+		processVideoFunc: func(ctx context.Context, jobID, videoPath string, options domain.FrameOptions) ([]string, int, error) {
+			return []string{zipPath}, 30, nil
+		},
+	}
+	messagePort := &mockMessagePort{
+		sendMessageFunc: func(ctx context.Context, queueURL string, messageBody string) (string, error) {
+			return "msg-id", nil
+		},
+	}
+
+	useCase := NewProcessVideoUseCase(storagePort, messagePort, videoProcessor, "output-bucket", "output-queue").WithTimeline(true)
+	request := domain.VideoProcess{ProcessID: "process-123", VideoBucket: "input-bucket", VideoKey: "video.mp4", KeepOriginal: true, Attempt: 1}
+	if err := useCase.Execute(context.Background(), request); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	timeline, err := NewGetJobTimelineUseCase(storagePort, "output-bucket").Timeline(context.Background(), "process-123")
+	if err != nil {
+		t.Fatalf("Timeline failed: %v", err)
+	}
+	var stages []string
+	for _, event := range timeline.Events {
+		stages = append(stages, event.Stage+":"+event.Status)
+	}
+	expected := "received:completed download:started download:completed processing:started processing:completed upload:started upload:completed result:completed"
+	if strings.Join(stages, " ") != expected {
+		t.Errorf("Expected events %s, got %s", expected, strings.Join(stages, " "))
+	}
+	if timeline.Events[0].Attempt != 1 {
+		t.Errorf("Expected the attempt on the received event, got %+v", timeline.Events[0])
+	}
+	if len(timeline.Stages) != 3 {
+		t.Fatalf("Expected download, processing and upload spans, got %+v", timeline.Stages)
+	}
+	for i, size := range []int64{18, 16, 16} {
+		if span := timeline.Stages[i]; span.Status != domain.JobEventCompleted || span.Bytes != size {
+			t.Errorf("Expected %s completed with %d bytes, got %+v", span.Stage, size, span)
+		}
+	}
+}
+
+func TestExecute_TransferProgress(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	zipFile, err := os.CreateTemp("", "test-zip-*.zip")
+	if err != nil {
+		t.Fatalf("Failed to create zip file: %v", err)
+	}
+	zipFile.WriteString("fake zip content")
+	zipFile.Close()
+	defer os.Remove(zipFile.Name())
+
+	var uploaded string
+	storagePort := &mockStoragePort{
+		getObjectFunc: func(ctx context.Context, bucket, key string, options domain.GetOptions) (io.ReadCloser, error) {
+			return io.NopCloser(strings.NewReader("fake video content")), nil
+		},
+		putObjectFunc: func(ctx context.Context, bucket, key string, body io.Reader, options domain.PutOptions) (string, error) {
+			// S3 needs to seek the body to find its length
+			if _, ok := body.(io.Seeker); !ok {
+				t.Error("Expected a seekable upload body")
+			}
+			data, _ := io.ReadAll(body)
+			uploaded = string(data)
+			return key, nil
+		},
+	}
+
+	var progress []domain.TransferProgress
+	messagePort := &mockMessagePort{
+		sendMessageFunc: func(ctx context.Context, queueURL string, messageBody string) (string, error) {
+			if queueURL == "progress-queue" {
+				var message domain.TransferProgress
+				json.Unmarshal([]byte(messageBody), &message)
+				progress = append(progress, message)
+			}
+			return "msg-id", nil
+		},
+	}
+
+	videoProcessor := &mockVideoProcessor{
+		processVideoFunc: func(ctx context.Context, jobID, videoPath string, options domain.FrameOptions) ([]string, int, error) {
+			return []string{zipFile.Name()}, 30, nil
+		},
+	}
+
+	useCase := NewProcessVideoUseCase(storagePort, messagePort, videoProcessor, "output-bucket", "output-queue").
+		WithBandwidthLimit(transfer.NewLimiter(1<<30)).
+		WithProgress("progress-queue", 0)
+	if err := useCase.Execute(context.Background(), domain.VideoProcess{ProcessID: "123", VideoBucket: "input", VideoKey: "video.mp4"}); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	if uploaded != "fake zip content" {
+		t.Errorf("Expected the zip uploaded through the wrapper, got %q", uploaded)
+	}
+	if len(progress) != 2 {
+		t.Fatalf("Expected a download and an upload progress message, got %+v", progress)
+	}
+	if progress[0].Stage != domain.TransferStageDownload || progress[0].Bytes != 18 || progress[0].TotalBytes != 0 {
+		t.Errorf("Unexpected download progress %+v", progress[0])
+	}
+	if progress[1].Stage != domain.TransferStageUpload || progress[1].Bytes != 16 || progress[1].Percent != 100 {
+		t.Errorf("Unexpected upload progress %+v", progress[1])
+	}
+}
+
+// mockProgressReporter records the progress it was handed
+type mockProgressReporter struct {
+	progress []domain.TransferProgress
+}
+
+func (m *mockProgressReporter) ReportProgress(ctx context.Context, progress domain.TransferProgress) {
+	m.progress = append(m.progress, progress)
+}
+
+func TestExecute_ProgressReporter(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	dir := t.TempDir()
+	storagePort := &mockStoragePort{
+		getObjectFunc: func(ctx context.Context, bucket, key string, options domain.GetOptions) (io.ReadCloser, error) {
+			return io.NopCloser(strings.NewReader("fake video content")), nil
+		},
+		putObjectFunc: func(ctx context.Context, bucket, key string, body io.Reader, options domain.PutOptions) (string, error) {
+			io.ReadAll(body)
+			return key, nil
+		},
+	}
+	var queues []string
+	messagePort := &mockMessagePort{
+		sendMessageFunc: func(ctx context.Context, queueURL string, messageBody string) (string, error) {
+			queues = append(queues, queueURL)
+			return "msg-id", nil
+		},
+	}
+	videoProcessor := &mockVideoProcessor{
+		processVideoFunc: func(ctx context.Context, jobID, videoPath string, options domain.FrameOptions) ([]string, int, error) {
+			zipPath := filepath.Join(dir, jobID+".zip")
+			return []string{zipPath}, 30, os.WriteFile(zipPath, []byte("fake zip content"), 0644)
+		},
+	}
+
+	reporter := &mockProgressReporter{}
+	useCase := NewProcessVideoUseCase(storagePort, messagePort, videoProcessor, "output-bucket", "output-queue").
+		WithProgressReporter(reporter)
+	if err := useCase.Execute(context.Background(), domain.VideoProcess{ProcessID: "123", VideoBucket: "input", VideoKey: "video.mp4"}); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	if len(reporter.progress) != 2 || reporter.progress[0].Stage != domain.TransferStageDownload || reporter.progress[1].Stage != domain.TransferStageUpload {
+		t.Errorf("Expected a download and an upload progress, got %+v", reporter.progress)
+	}
+	// Without a progress queue only the result is sent
+	if strings.Join(queues, ",") != "output-queue" {
+		t.Errorf("Expected no progress messages, got %v", queues)
+	}
+}
+
+func TestExecute_ProcessingError(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	tmpFile, err := os.CreateTemp("", "test-video-*.mp4")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	tmpFile.WriteString("fake video content")
+	tmpFile.Close()
+	defer os.Remove(tmpFile.Name())
+
+	storagePort := &mockStoragePort{
+		getObjectFunc: func(ctx context.Context, bucket, key string, options domain.GetOptions) (io.ReadCloser, error) {
+			file, err := os.Open(tmpFile.Name())
+			return file, err
+		},
+	}
+
+	messagePort := &mockMessagePort{
+		sendMessageFunc: func(ctx context.Context, queueURL string, messageBody string) (string, error) {
+			return "msg-id", nil
+		},
+	}
+
+	videoProcessor := &mockVideoProcessor{
+		processVideoFunc: func(ctx context.Context, jobID, videoPath string, options domain.FrameOptions) ([]string, int, error) {
+			return nil, 0, errors.New("processing failed")
+		},
+	}
+
+	useCase := NewProcessVideoUseCase(storagePort, messagePort, videoProcessor, "output-bucket", "output-queue")
+
+	request := domain.VideoProcess{
+		ProcessID:   "process-456",
+		VideoBucket: "input-bucket",
+		VideoKey:    "video.mp4",
+	}
+
+	err = useCase.Execute(context.Background(), request)
+	if err == nil {
+		t.Fatal("Expected error from processing")
+	}
+}
+
+func TestExecute_TraceAttributes(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	zipFile, err := os.CreateTemp("", "test-zip-*.zip")
+	if err != nil {
+		t.Fatalf("Failed to create zip file: %v", err)
+	}
+	zipFile.WriteString("fake zip content")
+	zipFile.Close()
+	defer os.Remove(zipFile.Name())
+
+	var sent map[string]string
+	messagePort := &mockMessagePort{
+		sendMessageWithAttributesFunc: func(ctx context.Context, queueURL string, messageBody string, attributes map[string]string) (string, error) {
+			sent = attributes
+			return "msg-id", nil
+		},
+	}
+	videoProcessor := &mockVideoProcessor{
+		processVideoFunc: func(ctx context.Context, jobID, videoPath string, options domain.FrameOptions) ([]string, int, error) {
+			return []string{zipFile.Name()}, 20, nil
+		},
+	}
+	useCase := NewProcessVideoUseCase(&mockStoragePort{}, messagePort, videoProcessor, "output-bucket", "output-queue")
+
+	ctx := observability.WithTrace(context.Background(), observability.TraceContext{
+		CorrelationID: "4bf92f3577b34da6a3ce929d0e0e4736",
+		Parent:        "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+	})
+	if err := useCase.Execute(ctx, domain.VideoProcess{ProcessID: "process-trace", VideoBucket: "input-bucket", VideoKey: "video.mp4"}); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	if traceID, _, ok := observability.ParseTraceparent(sent[domain.TraceparentAttribute]); !ok || traceID != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("Expected a traceparent of the job trace, got %q", sent[domain.TraceparentAttribute])
+	}
+	if sent[domain.CorrelationIDAttribute] != "4bf92f3577b34da6a3ce929d0e0e4736" || sent[domain.SchemaVersionAttribute] != domain.ResultSchemaVersion {
+		t.Errorf("Unexpected attributes %v", sent)
+	}
+}
+
+func TestAddTraceAttributes_Limit(t *testing.T) {
+	attributes := map[string]string{}
+	for i := 0; i < domain.MaxMessageAttributes-1; i++ {
+		attributes[fmt.Sprintf("attribute_%d", i)] = "value"
+	}
+
+	ctx := observability.WithTrace(context.Background(), observability.TraceContext{CorrelationID: "trace-1"})
+	addTraceAttributes(ctx, attributes)
+
+	if len(attributes) != domain.MaxMessageAttributes || attributes[domain.TraceparentAttribute] == "" {
+		t.Errorf("Expected only the traceparent to fit, got %v", attributes)
+	}
+}
+
+type mockErrorReporter struct {
+	failures []domain.JobFailure
+}
+
+func (m *mockErrorReporter) ReportFailure(ctx context.Context, failure domain.JobFailure) {
+	m.failures = append(m.failures, failure)
+}
+
+func (m *mockErrorReporter) ReportPanic(ctx context.Context, recovered any, stack []byte, messageID string) {
+}
+
+func TestExecute_ReportsFailures(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	storagePort := &mockStoragePort{
+		getObjectFunc: func(ctx context.Context, bucket, key string, options domain.GetOptions) (io.ReadCloser, error) {
+			return io.NopCloser(strings.NewReader("fake video content")), nil
+		},
+	}
+	messagePort := &mockMessagePort{
+		sendMessageFunc: func(ctx context.Context, queueURL string, messageBody string) (string, error) {
+			return "msg-id", nil
+		},
+	}
+	processErr := errors.New("processing failed")
+	videoProcessor := &mockVideoProcessor{
+		processVideoFunc: func(ctx context.Context, jobID, videoPath string, options domain.FrameOptions) ([]string, int, error) {
+			return nil, 0, processErr
+		},
+	}
+	reporter := &mockErrorReporter{}
+	useCase := NewProcessVideoUseCase(storagePort, messagePort, videoProcessor, "output-bucket", "output-queue").WithErrorReporter(reporter)
+
+	request := domain.VideoProcess{ProcessID: "process-456", VideoBucket: "input-bucket", VideoKey: "video.mp4"}
+	if err := useCase.Execute(context.Background(), request); err == nil {
+		t.Fatal("Expected error from processing")
+	}
+	if len(reporter.failures) != 1 {
+		t.Fatalf("Expected 1 failure reported, got %d", len(reporter.failures))
+	}
+	failure := reporter.failures[0]
+	if failure.ProcessID != "process-456" || failure.Stage != domain.JobStageProcessing || !errors.Is(failure.Err, processErr) {
+		t.Errorf("Unexpected failure %+v", failure)
+	}
+
+	// A cancelled job is not a failure
+	reporter.failures = nil
+	processErr = domain.NewCodedError(domain.ErrorCodeCancelled, errors.New("job cancelled"))
+	useCase.Execute(context.Background(), request)
+	if len(reporter.failures) != 0 {
+		t.Errorf("Expected no failure reported for a cancelled job, got %+v", reporter.failures)
+	}
+}
+
+func TestExecute_UploadError(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	tmpFile, err := os.CreateTemp("", "test-video-*.mp4")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	tmpFile.WriteString("fake video content")
+	tmpFile.Close()
+	defer os.Remove(tmpFile.Name())
+
+	zipFile, err := os.CreateTemp("", "test-zip-*.zip")
+	if err != nil {
+		t.Fatalf("Failed to create zip file: %v", err)
+	}
+	zipFile.WriteString("fake zip content")
+	zipFile.Close()
+	defer os.Remove(zipFile.Name())
+
+	storagePort := &mockStoragePort{
+		getObjectFunc: func(ctx context.Context, bucket, key string, options domain.GetOptions) (io.ReadCloser, error) {
+			file, err := os.Open(tmpFile.Name())
+			return file, err
+		},
+		putObjectFunc: func(ctx context.Context, bucket, key string, body io.Reader, options domain.PutOptions) (string, error) {
+			return "", errors.New("upload failed")
+		},
+	}
+
+	messagePort := &mockMessagePort{
+		sendMessageFunc: func(ctx context.Context, queueURL string, messageBody string) (string, error) {
+			return "msg-id", nil
+		},
+	}
+
+	videoProcessor := &mockVideoProcessor{
+		processVideoFunc: func(ctx context.Context, jobID, videoPath string, options domain.FrameOptions) ([]string, int, error) {
+			return []string{zipFile.Name()}, 25, nil
+		},
+	}
+
+	useCase := NewProcessVideoUseCase(storagePort, messagePort, videoProcessor, "output-bucket", "output-queue")
+
+	request := domain.VideoProcess{
+		ProcessID:   "process-789",
+		VideoBucket: "input-bucket",
+		VideoKey:    "video.mp4",
+	}
+
+	err = useCase.Execute(context.Background(), request)
+	if err == nil {
+		t.Fatal("Expected error from upload")
+	}
+}
+
+func TestExecute_DeleteOriginalVideoError(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	tmpFile, err := os.CreateTemp("", "test-video-*.mp4")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	tmpFile.WriteString("fake video content")
+	tmpFile.Close()
+	defer os.Remove(tmpFile.Name())
+
+	zipFile, err := os.CreateTemp("", "test-zip-*.zip")
+	if err != nil {
+		t.Fatalf("Failed to create zip file: %v", err)
+	}
+	zipFile.WriteString("fake zip content")
+	zipFile.Close()
+	defer os.Remove(zipFile.Name())
+
+	storagePort := &mockStoragePort{
+		getObjectFunc: func(ctx context.Context, bucket, key string, options domain.GetOptions) (io.ReadCloser, error) {
+			file, err := os.Open(tmpFile.Name())
+			return file, err
+		},
+		putObjectFunc: func(ctx context.Context, bucket, key string, body io.Reader, options domain.PutOptions) (string, error) {
+			return "s3://bucket/key", nil
+		},
+		deleteObjectFunc: func(ctx context.Context, bucket, key string) error {
+			return errors.New("delete failed")
+		},
+	}
+
+	messagePort := &mockMessagePort{
+		sendMessageFunc: func(ctx context.Context, queueURL string, messageBody string) (string, error) {
+			return "msg-success", nil
+		},
+	}
+
+	videoProcessor := &mockVideoProcessor{
+		processVideoFunc: func(ctx context.Context, jobID, videoPath string, options domain.FrameOptions) ([]string, int, error) {
+			return []string{zipFile.Name()}, 20, nil
+		},
+	}
+
+	useCase := NewProcessVideoUseCase(storagePort, messagePort, videoProcessor, "output-bucket", "output-queue")
+
+	request := domain.VideoProcess{
+		ProcessID:   "process-delete",
+		VideoBucket: "input-bucket",
+		VideoKey:    "video.mp4",
+	}
+
+	// Should succeed even if delete fails
+	err = useCase.Execute(context.Background(), request)
+	if err != nil {
+		t.Fatalf("Execute should succeed even with delete error: %v", err)
+	}
+}
+
+func TestExecute_KeepOriginal(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	zipFile, err := os.CreateTemp("", "test-zip-*.zip")
+	if err != nil {
+		t.Fatalf("Failed to create zip file: %v", err)
+	}
+	zipFile.WriteString("fake zip content")
+	zipFile.Close()
+	defer os.Remove(zipFile.Name())
+
+	storagePort := &mockStoragePort{
+		deleteObjectFunc: func(ctx context.Context, bucket, key string) error {
+			t.Errorf("Expected the original video kept, got delete of %s/%s", bucket, key)
+			return nil
+		},
+	}
+
+	videoProcessor := &mockVideoProcessor{
+		processVideoFunc: func(ctx context.Context, jobID, videoPath string, options domain.FrameOptions) ([]string, int, error) {
+			return []string{zipFile.Name()}, 20, nil
+		},
+	}
+
+	useCase := NewProcessVideoUseCase(storagePort, &mockMessagePort{}, videoProcessor, "output-bucket", "output-queue")
+
+	err = useCase.Execute(context.Background(), domain.VideoProcess{
+		ProcessID:    "process-keep",
+		VideoBucket:  "input-bucket",
+		VideoKey:     "video.mp4",
+		KeepOriginal: true,
+	})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+}
+
+func TestExecute_SendSuccessMessageError(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	tmpFile, err := os.CreateTemp("", "test-video-*.mp4")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	tmpFile.WriteString("fake video content")
+	tmpFile.Close()
+	defer os.Remove(tmpFile.Name())
+
+	zipFile, err := os.CreateTemp("", "test-zip-*.zip")
+	if err != nil {
+		t.Fatalf("Failed to create zip file: %v", err)
+	}
+	zipFile.WriteString("fake zip content")
+	zipFile.Close()
+	defer os.Remove(zipFile.Name())
+
+	storagePort := &mockStoragePort{
+		getObjectFunc: func(ctx context.Context, bucket, key string, options domain.GetOptions) (io.ReadCloser, error) {
+			file, err := os.Open(tmpFile.Name())
+			return file, err
+		},
+		putObjectFunc: func(ctx context.Context, bucket, key string, body io.Reader, options domain.PutOptions) (string, error) {
+			return "s3://bucket/key", nil
+		},
+		deleteObjectFunc: func(ctx context.Context, bucket, key string) error {
+			return nil
+		},
+	}
+
+	messagePort := &mockMessagePort{
+		sendMessageFunc: func(ctx context.Context, queueURL string, messageBody string) (string, error) {
+			return "", errors.New("send message failed")
+		},
+	}
+
+	videoProcessor := &mockVideoProcessor{
+		processVideoFunc: func(ctx context.Context, jobID, videoPath string, options domain.FrameOptions) ([]string, int, error) {
+			return []string{zipFile.Name()}, 15, nil
+		},
+	}
+
+	useCase := NewProcessVideoUseCase(storagePort, messagePort, videoProcessor, "output-bucket", "output-queue")
+
+	request := domain.VideoProcess{
+		ProcessID:   "process-msg-error",
+		VideoBucket: "input-bucket",
+		VideoKey:    "video.mp4",
+	}
+
+	err = useCase.Execute(context.Background(), request)
+	if err == nil {
+		t.Fatal("Expected error from send message")
+	}
+}
+
+func TestExecute_DeletesOriginalAfterResultAccepted(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	zipPath := filepath.Join(t.TempDir(), "frames.zip")
+	os.WriteFile(zipPath, []byte("fake zip content"), 0644)
+
+	var events []string
+	storagePort := &mockStoragePort{
+		deleteObjectFunc: func(ctx context.Context, bucket, key string) error {
+			events = append(events, "delete "+bucket+"/"+key)
+			return nil
+		},
+	}
+	messagePort := &mockMessagePort{
+		sendMessageFunc: func(ctx context.Context, queueURL string, messageBody string) (string, error) {
+			events = append(events, "send")
+			return "msg-id", nil
+		},
+	}
+	videoProcessor := &mockVideoProcessor{
+		processVideoFunc: func(ctx context.Context, jobID, videoPath string, options domain.FrameOptions) ([]string, int, error) {
+			return []string{zipPath}, 10, nil
+		},
+	}
+
+	useCase := NewProcessVideoUseCase(storagePort, messagePort, videoProcessor, "output-bucket", "output-queue")
+	err := useCase.Execute(context.Background(), domain.VideoProcess{
+		ProcessID:   "process-order",
+		VideoBucket: "input-bucket",
+		VideoKey:    "video.mp4",
+	})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	if strings.Join(events, ",") != "send,delete input-bucket/video.mp4" {
+		t.Errorf("Expected the result sent before deleting the original, got %v", events)
+	}
+}
+
+func TestExecute_ResultNotAcceptedRollsBackOutputs(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	dir := t.TempDir()
+	zipPaths := []string{filepath.Join(dir, "part1.zip"), filepath.Join(dir, "part2.zip")}
+	for _, zipPath := range zipPaths {
+		os.WriteFile(zipPath, []byte("fake zip content"), 0644)
+	}
+
+	var deleted []string
+	var abortedPrefix string
+	storagePort := &mockStoragePort{
+		deleteObjectFunc: func(ctx context.Context, bucket, key string) error {
+			deleted = append(deleted, bucket+"/"+key)
+			return nil
+		},
+		abortMultipartUploadsFunc: func(ctx context.Context, bucket, prefix string) (int, error) {
+			abortedPrefix = bucket + "/" + prefix
+			return 0, nil
+		},
+	}
+	messagePort := &mockMessagePort{
+		sendMessageFunc: func(ctx context.Context, queueURL string, messageBody string) (string, error) {
+			return "", errors.New("send message failed")
+		},
+	}
+	videoProcessor := &mockVideoProcessor{
+		processVideoFunc: func(ctx context.Context, jobID, videoPath string, options domain.FrameOptions) ([]string, int, error) {
+			return zipPaths, 10, nil
+		},
+	}
+
+	useCase := NewProcessVideoUseCase(storagePort, messagePort, videoProcessor, "output-bucket", "output-queue")
+	err := useCase.Execute(context.Background(), domain.VideoProcess{
+		ProcessID:   "process-rollback",
+		VideoBucket: "input-bucket",
+		VideoKey:    "video.mp4",
+	})
+	if err == nil {
+		t.Fatal("Expected error from send message")
+	}
+
+	want := []string{
+		"output-bucket/processed/frames_process-rollback.part1.zip",
+		"output-bucket/processed/frames_process-rollback.part2.zip",
+	}
+	if strings.Join(deleted, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected only the outputs deleted and the original kept, got %v", deleted)
+	}
+	if abortedPrefix != "output-bucket/processed/frames_process-rollback." {
+		t.Errorf("Unexpected multipart abort prefix %q", abortedPrefix)
+	}
+}
+
+func TestExecute_PartialUploadRemovesUploadedParts(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	dir := t.TempDir()
+	zipPaths := []string{filepath.Join(dir, "part1.zip"), filepath.Join(dir, "part2.zip")}
+	for _, zipPath := range zipPaths {
+		os.WriteFile(zipPath, []byte("fake zip content"), 0644)
+	}
+
+	var deleted []string
+	storagePort := &mockStoragePort{
+		putObjectFunc: func(ctx context.Context, bucket, key string, body io.Reader, options domain.PutOptions) (string, error) {
+			if strings.HasSuffix(key, ".part2.zip") {
+				return "", errors.New("connection reset")
+			}
+			return key, nil
+		},
+		deleteObjectFunc: func(ctx context.Context, bucket, key string) error {
+			deleted = append(deleted, key)
+			return nil
+		},
+	}
+	videoProcessor := &mockVideoProcessor{
+		processVideoFunc: func(ctx context.Context, jobID, videoPath string, options domain.FrameOptions) ([]string, int, error) {
+			return zipPaths, 10, nil
+		},
+	}
+
+	useCase := NewProcessVideoUseCase(storagePort, &mockMessagePort{}, videoProcessor, "output-bucket", "output-queue")
+	err := useCase.Execute(context.Background(), domain.VideoProcess{
+		ProcessID:   "process-partial",
+		VideoBucket: "input-bucket",
+		VideoKey:    "video.mp4",
+	})
+	if err == nil {
+		t.Fatal("Expected error from the failed upload")
+	}
+
+	if len(deleted) != 1 || deleted[0] != "processed/frames_process-partial.part1.zip" {
+		t.Errorf("Expected the uploaded part removed and the original kept, got %v", deleted)
+	}
+}
+
+func TestExecute_OutboxDefersOriginalDeletion(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	zipPath := filepath.Join(t.TempDir(), "frames.zip")
+	os.WriteFile(zipPath, []byte("fake zip content"), 0644)
+
+	var deleted []string
+	storagePort := &mockStoragePort{
+		deleteObjectFunc: func(ctx context.Context, bucket, key string) error {
+			deleted = append(deleted, bucket+"/"+key)
+			return nil
+		},
+	}
+	messagePort := &mockMessagePort{
+		sendMessageFunc: func(ctx context.Context, queueURL string, messageBody string) (string, error) {
+			return "", errors.New("throttled")
+		},
+	}
+	videoProcessor := &mockVideoProcessor{
+		processVideoFunc: func(ctx context.Context, jobID, videoPath string, options domain.FrameOptions) ([]string, int, error) {
+			return []string{zipPath}, 10, nil
+		},
+	}
+
+	outbox := newMockOutboxPort()
+	useCase := NewProcessVideoUseCase(storagePort, messagePort, videoProcessor, "output-bucket", "output-queue").WithOutbox(outbox)
+	err := useCase.Execute(context.Background(), domain.VideoProcess{
+		ProcessID:   "process-outbox",
+		VideoBucket: "input-bucket",
+		VideoKey:    "video.mp4",
+	})
+	if err != nil {
+		t.Fatalf("Expected the result left to the outbox, got %v", err)
+	}
+	if len(deleted) != 0 {
+		t.Fatalf("Expected nothing deleted before the result is accepted, got %v", deleted)
+	}
+
+	entries, _ := outbox.Pending(context.Background())
+	if len(entries) != 1 || entries[0].DeleteAfterSend == nil || entries[0].DeleteAfterSend.Key != "video.mp4" {
+		t.Fatalf("Expected the original video recorded on the outbox entry, got %+v", entries)
+	}
+
+	messagePort.sendMessageFunc = func(ctx context.Context, queueURL string, messageBody string) (string, error) {
+		return "msg-id", nil
+	}
+	entries[0].NextAttemptAt = time.Now()
+	outbox.Save(context.Background(), entries[0])
+	if _, err := NewDispatchOutboxUseCase(outbox, storagePort, messagePort, "output-queue").Dispatch(context.Background()); err != nil {
+		t.Fatalf("Dispatch failed: %v", err)
+	}
+	if len(deleted) != 1 || deleted[0] != "input-bucket/video.mp4" {
+		t.Errorf("Expected the original deleted after delivery, got %v", deleted)
+	}
+}
+
+func TestExecute_DeleteOnConfirm(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	zipPath := filepath.Join(t.TempDir(), "frames.zip")
+	os.WriteFile(zipPath, []byte("fake zip content"), 0644)
+
+	var marker string
+	var deleted []string
+	storagePort := &mockStoragePort{
+		putObjectFunc: func(ctx context.Context, bucket, key string, body io.Reader, options domain.PutOptions) (string, error) {
+			if strings.HasPrefix(key, "pending-deletions/") {
+				data, _ := io.ReadAll(body)
+				marker = bucket + "/" + key + " " + string(data)
+			}
+			return key, nil
+		},
+		deleteObjectFunc: func(ctx context.Context, bucket, key string) error {
+			deleted = append(deleted, bucket+"/"+key)
+			return nil
+		},
+	}
+
+	sendErr := errors.New("send message failed")
+	var sentBody string
+	messagePort := &mockMessagePort{
+		sendMessageFunc: func(ctx context.Context, queueURL string, messageBody string) (string, error) {
+			sentBody = messageBody
+			return "msg-id", sendErr
+		},
+	}
+	videoProcessor := &mockVideoProcessor{
+		processVideoFunc: func(ctx context.Context, jobID, videoPath string, options domain.FrameOptions) ([]string, int, error) {
+			return []string{zipPath}, 10, nil
+		},
+	}
+
+	useCase := NewProcessVideoUseCase(storagePort, messagePort, videoProcessor, "output-bucket", "output-queue").WithDeleteOnConfirm(true)
+	request := domain.VideoProcess{
+		ProcessID:   "process-confirm",
+		VideoBucket: "input-bucket",
+		VideoKey:    "video.mp4",
+	}
+
+	// A result that is not accepted rolls back the marker with the outputs
+	if err := useCase.Execute(context.Background(), request); err == nil {
+		t.Fatal("Expected error from send message")
+	}
+	if !slices.Contains(deleted, "output-bucket/pending-deletions/process-confirm.json") || slices.Contains(deleted, "input-bucket/video.mp4") {
+		t.Errorf("Expected the marker rolled back and the original kept, got %v", deleted)
+	}
+
+	deleted, sendErr = nil, nil
+	os.WriteFile(zipPath, []byte("fake zip content"), 0644)
+	if err := useCase.Execute(context.Background(), request); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if len(deleted) != 0 {
+		t.Errorf("Expected nothing deleted before the confirmation, got %v", deleted)
+	}
+	if !strings.HasPrefix(marker, "output-bucket/pending-deletions/process-confirm.json ") || !strings.Contains(marker, `"video_key":"video.mp4"`) {
+		t.Errorf("Unexpected marker %s", marker)
+	}
+	if !strings.Contains(sentBody, `"confirm_required":true`) {
+		t.Errorf("Expected confirm_required in the result, got %s", sentBody)
+	}
+}
+
+func TestExecute_SendErrorMessage_ValidationError(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	messagePort := &mockMessagePort{
+		sendMessageFunc: func(ctx context.Context, queueURL string, messageBody string) (string, error) {
+			return "msg-id", nil
+		},
+	}
+
+	useCase := NewProcessVideoUseCase(nil, messagePort, nil, "output-bucket", "output-queue")
+
+	// Test with empty request to trigger validation error
+	request := domain.VideoProcess{
+		ProcessID:   "",
+		VideoBucket: "",
+		VideoKey:    "",
+	}
+
+	err := useCase.Execute(context.Background(), request)
+	if err == nil {
+		t.Fatal("Expected validation error")
+	}
+}
+
+func TestDownloadVideo_ReadError(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	// Create a reader that will fail on read
+	failingReader := &failingReadCloser{}
+
+	storagePort := &mockStoragePort{
+		getObjectFunc: func(ctx context.Context, bucket, key string, options domain.GetOptions) (io.ReadCloser, error) {
+			return failingReader, nil
+		},
+	}
+
+	messagePort := &mockMessagePort{
+		sendMessageFunc: func(ctx context.Context, queueURL string, messageBody string) (string, error) {
+			return "msg-id", nil
+		},
+	}
+
+	useCase := NewProcessVideoUseCase(storagePort, messagePort, nil, "output-bucket", "output-queue")
+
+	request := domain.VideoProcess{
+		ProcessID:   "test-fail-read",
+		VideoBucket: "bucket",
+		VideoKey:    "video.mp4",
+	}
+
+	err := useCase.Execute(context.Background(), request)
+	if err == nil {
+		t.Fatal("Expected error from read failure")
+	}
+}
+
+// Helper type for testing read failures
+type failingReadCloser struct{}
+
+func (f *failingReadCloser) Read(p []byte) (n int, err error) {
+	return 0, errors.New("read error")
+}
+
+func (f *failingReadCloser) Close() error {
+	return nil
+}
+
+func TestExecute_EmptyOrTruncatedVideo(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		size      int64
+		content   string
+		wantCode  string
+		wantRetry bool
+	}{
+		{"empty object", 0, "", domain.ErrorCodeEmptyVideo, false},
+		{"empty download", 100, "", domain.ErrorCodeEmptyVideo, false},
+		{"truncated download", 100, "partial video", domain.ErrorCodeTruncatedVideo, true},
+	}
+
+	for _, tt := range tests {
+		var reads int
+		storagePort := &mockStoragePort{
+			headObjectFunc: func(ctx context.Context, bucket, key string) (domain.ObjectInfo, error) {
+				return domain.ObjectInfo{SizeBytes: tt.size}, nil
+			},
+			getObjectFunc: func(ctx context.Context, bucket, key string, options domain.GetOptions) (io.ReadCloser, error) {
+				reads++
+				return io.NopCloser(strings.NewReader(tt.content)), nil
+			},
+			deleteObjectFunc: func(ctx context.Context, bucket, key string) error {
+				t.Errorf("%s: expected the video not to be deleted", tt.name)
+				return nil
+			},
+		}
+		var sentMessage string
+		messagePort := &mockMessagePort{
+			sendMessageFunc: func(ctx context.Context, queueURL string, messageBody string) (string, error) {
+				sentMessage = messageBody
+				return "msg-id", nil
+			},
+		}
+		videoProcessor := &mockVideoProcessor{
+			processVideoFunc: func(ctx context.Context, jobID, videoPath string, options domain.FrameOptions) ([]string, int, error) {
+				t.Errorf("%s: expected the video not to be processed", tt.name)
+				return nil, 0, nil
+			},
+		}
+
+		useCase := NewProcessVideoUseCase(storagePort, messagePort, videoProcessor, "output-bucket", "output-queue")
+		err := useCase.Execute(context.Background(), domain.VideoProcess{ProcessID: "p1", VideoBucket: "input", VideoKey: "video.mp4"})
+		if err == nil {
+			t.Errorf("%s: expected error", tt.name)
+		}
+		if domain.ErrorCode(err) != tt.wantCode || !strings.Contains(sentMessage, tt.wantCode) {
+			t.Errorf("%s: expected %s, got %v and message %s", tt.name, tt.wantCode, err, sentMessage)
+		}
+		if domain.IsTransient(err) != tt.wantRetry {
+			t.Errorf("%s: expected transient %v, got %v", tt.name, tt.wantRetry, err)
+		}
+		if tt.size == 0 && reads != 0 {
+			t.Errorf("%s: expected an empty object not to be downloaded", tt.name)
+		}
+	}
+}
+
+func TestExecute_SourceObject(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	zipPath := filepath.Join(t.TempDir(), "frames.zip")
+	storagePort := &mockStoragePort{
+		headObjectFunc: func(ctx context.Context, bucket, key string) (domain.ObjectInfo, error) {
+			return domain.ObjectInfo{SizeBytes: 10, ETag: "abc", ContentType: "video/mp4", Metadata: map[string]string{"camera": "a7"}}, nil
+		},
+		getObjectFunc: func(ctx context.Context, bucket, key string, options domain.GetOptions) (io.ReadCloser, error) {
+			return io.NopCloser(strings.NewReader("0123456789")), nil
+		},
+	}
+	var sentMessage string
+	messagePort := &mockMessagePort{
+		sendMessageFunc: func(ctx context.Context, queueURL string, messageBody string) (string, error) {
+			sentMessage = messageBody
+			return "msg-id", nil
+		},
+	}
+	videoProcessor := &mockVideoProcessor{
+		processVideoFunc: func(ctx context.Context, jobID, videoPath string, options domain.FrameOptions) ([]string, int, error) {
+			os.WriteFile(zipPath, []byte("fake zip content"), 0644)
+			return []string{zipPath}, 1, nil
+		},
+	}
+	request := domain.VideoProcess{ProcessID: "p1", VideoBucket: "input", VideoKey: "video.mp4"}
+
+	useCase := NewProcessVideoUseCase(storagePort, messagePort, videoProcessor, "output-bucket", "output-queue")
+	if err := useCase.Execute(context.Background(), request); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	var result struct {
+		Source domain.ObjectInfo `json:"source"`
+	}
+	json.Unmarshal([]byte(sentMessage), &result)
+	if result.Source.SizeBytes != 10 || result.Source.ETag != "abc" || result.Source.ContentType != "video/mp4" || result.Source.Metadata["camera"] != "a7" {
+		t.Errorf("Expected the source object echoed, got %s", sentMessage)
+	}
+
+	// Over the limit, the video is not downloaded
+	storagePort.getObjectFunc = func(ctx context.Context, bucket, key string, options domain.GetOptions) (io.ReadCloser, error) {
+		t.Error("Expected a video over the limit not to be downloaded")
+		return nil, errors.New("not expected")
+	}
+	err := useCase.WithMaxVideoSize(5).Execute(context.Background(), request)
+	if domain.ErrorCode(err) != domain.ErrorCodeVideoTooLarge || domain.IsTransient(err) {
+		t.Errorf("Expected a final %s error, got %v", domain.ErrorCodeVideoTooLarge, err)
+	}
+
+	// Without the size, the limit is enforced while downloading
+	storagePort.headObjectFunc = nil
+	storagePort.getObjectFunc = func(ctx context.Context, bucket, key string, options domain.GetOptions) (io.ReadCloser, error) {
+		return io.NopCloser(strings.NewReader("0123456789")), nil
+	}
+	if err := useCase.Execute(context.Background(), request); domain.ErrorCode(err) != domain.ErrorCodeVideoTooLarge {
+		t.Errorf("Expected %s while downloading, got %v", domain.ErrorCodeVideoTooLarge, err)
+	}
+}
+
+func TestExecute_PinnedSource(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	current := domain.ObjectInfo{SizeBytes: 10, ETag: "new", VersionID: "v2"}
+	var conditions []domain.ObjectCondition
+	storagePort := &mockStoragePort{
+		headObjectFunc: func(ctx context.Context, bucket, key string) (domain.ObjectInfo, error) {
+			return current, nil
+		},
+		getObjectFunc: func(ctx context.Context, bucket, key string, options domain.GetOptions) (io.ReadCloser, error) {
+			t.Error("Expected a conditional read of a pinned video")
+			return nil, errors.New("not expected")
+		},
+		getObjectIfFunc: func(ctx context.Context, bucket, key string, condition domain.ObjectCondition) (io.ReadCloser, error) {
+			conditions = append(conditions, condition)
+			// The video changed between the HeadObject and the read
+			return nil, fmt.Errorf("%w: %s", domain.ErrObjectModified, key)
+		},
+		deleteObjectFunc: func(ctx context.Context, bucket, key string) error {
+			t.Errorf("Expected %s not to be deleted", key)
+			return nil
+		},
+	}
+	var sentMessage string
+	messagePort := &mockMessagePort{
+		sendMessageFunc: func(ctx context.Context, queueURL string, messageBody string) (string, error) {
+			sentMessage = messageBody
+			return "msg-id", nil
+		},
+	}
+	useCase := NewProcessVideoUseCase(storagePort, messagePort, nil, "output-bucket", "output-queue")
+
+	tests := []struct {
+		name     string
+		pin      domain.ObjectCondition
+		wantRead bool
+	}{
+		{"etag changed", domain.ObjectCondition{ETag: "old"}, false},
+		{"version changed", domain.ObjectCondition{VersionID: "v1"}, false},
+		{"changed while reading", domain.ObjectCondition{ETag: "new", VersionID: "v2"}, true},
+	}
+	for _, tt := range tests {
+		conditions, sentMessage = nil, ""
+		err := useCase.Execute(context.Background(), domain.VideoProcess{ProcessID: "p1", VideoBucket: "input", VideoKey: "video.mp4", Source: tt.pin})
+		if domain.ErrorCode(err) != domain.ErrorCodeSourceModified || domain.IsTransient(err) {
+			t.Errorf("%s: expected a final %s error, got %v", tt.name, domain.ErrorCodeSourceModified, err)
+		}
+		if !strings.Contains(sentMessage, domain.ErrorCodeSourceModified) {
+			t.Errorf("%s: expected %s error message, got: %s", tt.name, domain.ErrorCodeSourceModified, sentMessage)
+		}
+		if (len(conditions) > 0) != tt.wantRead {
+			t.Errorf("%s: expected read %v, got conditions %v", tt.name, tt.wantRead, conditions)
+		} else if tt.wantRead && conditions[0] != tt.pin {
+			t.Errorf("%s: expected the read pinned to %+v, got %+v", tt.name, tt.pin, conditions[0])
+		}
+	}
+
+	// Only a video_key can be pinned
+	request := domain.VideoProcess{ProcessID: "p1", VideoBucket: "input", VideoKeys: []string{"a.mp4", "b.mp4"}, Source: domain.ObjectCondition{ETag: "abc"}}
+	if err := useCase.validateRequest(request); err == nil {
+		t.Error("Expected a pinned batch rejected")
+	}
+}
+
+func TestExecute_VersionedSources(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	zipPath := filepath.Join(t.TempDir(), "frames.zip")
+	var read, deleted []string
+	storagePort := &mockStoragePort{
+		headObjectFunc: func(ctx context.Context, bucket, key string) (domain.ObjectInfo, error) {
+			return domain.ObjectInfo{SizeBytes: 10, ETag: "abc", VersionID: "v2"}, nil
+		},
+		getObjectFunc: func(ctx context.Context, bucket, key string, options domain.GetOptions) (io.ReadCloser, error) {
+			read = append(read, "latest")
+			return io.NopCloser(strings.NewReader("0123456789")), nil
+		},
+		getObjectIfFunc: func(ctx context.Context, bucket, key string, condition domain.ObjectCondition) (io.ReadCloser, error) {
+			read = append(read, condition.VersionID)
+			return io.NopCloser(strings.NewReader("0123456789")), nil
+		},
+		deleteObjectVersionFunc: func(ctx context.Context, bucket, key, versionID string) error {
+			deleted = append(deleted, key+"@"+versionID)
+			return nil
+		},
+	}
+	videoProcessor := &mockVideoProcessor{
+		processVideoFunc: func(ctx context.Context, jobID, videoPath string, options domain.FrameOptions) ([]string, int, error) {
+			os.WriteFile(zipPath, []byte("fake zip content"), 0644)
+			return []string{zipPath}, 1, nil
+		},
+	}
+	request := domain.VideoProcess{ProcessID: "p1", VideoBucket: "input", VideoKey: "video.mp4"}
+	useCase := NewProcessVideoUseCase(storagePort, &mockMessagePort{}, videoProcessor, "output-bucket", "output-queue")
+
+	if err := useCase.Execute(context.Background(), request); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if strings.Join(read, ",") != "latest" || strings.Join(deleted, ",") != "video.mp4@" {
+		t.Errorf("Expected the latest version read and deleted, got reads %v and deletes %v", read, deleted)
+	}
+
+	read, deleted = nil, nil
+	if err := useCase.WithVersionedSources(true).Execute(context.Background(), request); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if strings.Join(read, ",") != "v2" || strings.Join(deleted, ",") != "video.mp4@v2" {
+		t.Errorf("Expected the described version read and deleted, got reads %v and deletes %v", read, deleted)
+	}
+}
+
+type fixedWorkDir struct {
+	dir      string
+	sizes    []int64
+	released []string
+}
+
+func (w *fixedWorkDir) CreateVideo(jobID, name string, sizeBytes int64) (port.VideoFile, error) {
+	w.sizes = append(w.sizes, sizeBytes)
+	if err := os.MkdirAll(w.dir, 0777); err != nil {
+		return nil, err
+	}
+	return os.Create(filepath.Join(w.dir, name))
+}
+
+func (w *fixedWorkDir) Release(jobID string) {
+	w.released = append(w.released, jobID)
+}
+
+func TestExecute_WorkDir(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	workDir := &fixedWorkDir{dir: filepath.Join(t.TempDir(), "memory")}
+	zipPath := filepath.Join(t.TempDir(), "frames.zip")
+	storagePort := &mockStoragePort{
+		headObjectFunc: func(ctx context.Context, bucket, key string) (domain.ObjectInfo, error) {
+			return domain.ObjectInfo{SizeBytes: 10}, nil
+		},
+		getObjectFunc: func(ctx context.Context, bucket, key string, options domain.GetOptions) (io.ReadCloser, error) {
+			return io.NopCloser(strings.NewReader("0123456789")), nil
+		},
+	}
+	var processed string
+	videoProcessor := &mockVideoProcessor{
+		processVideoFunc: func(ctx context.Context, jobID, videoPath string, options domain.FrameOptions) ([]string, int, error) {
+			processed = videoPath
+			os.WriteFile(zipPath, []byte("fake zip content"), 0644)
+			return []string{zipPath}, 1, nil
+		},
+	}
+	useCase := NewProcessVideoUseCase(storagePort, &mockMessagePort{}, videoProcessor, "output-bucket", "output-queue").WithWorkDir(workDir)
+
+	if err := useCase.Execute(context.Background(), domain.VideoProcess{ProcessID: "p1", VideoBucket: "input", VideoKey: "video.mp4"}); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if len(workDir.sizes) != 1 || workDir.sizes[0] != 10 {
+		t.Errorf("Expected the work dir chosen by the video size, got sizes %v", workDir.sizes)
+	}
+	if filepath.Dir(processed) != workDir.dir {
+		t.Errorf("Expected video downloaded to %s, got %s", workDir.dir, processed)
+	}
+	if _, err := os.Stat(processed); !os.IsNotExist(err) {
+		t.Errorf("Expected downloaded video removed, got %v", err)
+	}
+	if len(workDir.released) != 1 || !strings.HasPrefix(workDir.released[0], "p1_") {
+		t.Errorf("Expected the job released from the work dir, got %v", workDir.released)
+	}
+}
+
+func TestUploadZip_OpenFileError(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	tmpFile, err := os.CreateTemp("", "test-video-*.mp4")
+	if err != nil {
+		t.Fatalf("Failed to create temp file: %v", err)
+	}
+	tmpFile.WriteString("fake video content")
+	tmpFile.Close()
+	defer os.Remove(tmpFile.Name())
+
+	zipFile, err := os.CreateTemp("", "test-zip-*.zip")
+	if err != nil {
+		t.Fatalf("Failed to create zip file: %v", err)
+	}
+	zipFile.WriteString("fake zip content")
+	zipFile.Close()
+	zipPath := zipFile.Name()
+	// Remove it to cause open error
+	os.Remove(zipPath)
+
+	storagePort := &mockStoragePort{
+		getObjectFunc: func(ctx context.Context, bucket, key string, options domain.GetOptions) (io.ReadCloser, error) {
+			file, err := os.Open(tmpFile.Name())
+			return file, err
+		},
+		putObjectFunc: func(ctx context.Context, bucket, key string, body io.Reader, options domain.PutOptions) (string, error) {
+			return "s3://bucket/key", nil
+		},
+		deleteObjectFunc: func(ctx context.Context, bucket, key string) error {
+			return nil
+		},
+	}
+
+	messagePort := &mockMessagePort{
+		sendMessageFunc: func(ctx context.Context, queueURL string, messageBody string) (string, error) {
+			return "msg-success", nil
+		},
+	}
+
+	videoProcessor := &mockVideoProcessor{
+		processVideoFunc: func(ctx context.Context, jobID, videoPath string, options domain.FrameOptions) ([]string, int, error) {
+			// Return the removed zip path to trigger open error
+			return []string{zipPath}, 10, nil
+		},
+	}
+
+	useCase := NewProcessVideoUseCase(storagePort, messagePort, videoProcessor, "output-bucket", "output-queue")
+
+	request := domain.VideoProcess{
+		ProcessID:   "test-open-error",
+		VideoBucket: "input-bucket",
+		VideoKey:    "video.mp4",
+	}
+
+	err = useCase.Execute(context.Background(), request)
+	if err == nil {
+		t.Fatal("Expected error from file open")
+	}
+}
+
+func TestResolveStorageClass(t *testing.T) {
+	tenants := domain.TenantRegistry{
+		"acme": {StorageClass: domain.StorageClassIntelligentTiering},
+	}
+	useCase := NewProcessVideoUseCase(nil, nil, nil, "", "").
+		WithStorageClass(domain.StorageClassStandard).
+		WithTenantRegistry(tenants)
+
+	tests := []struct {
+		name    string
+		request domain.VideoProcess
+		want    string
+	}{
+		{"worker default", domain.VideoProcess{}, domain.StorageClassStandard},
+		{"tenant override", domain.VideoProcess{TenantID: "acme"}, domain.StorageClassIntelligentTiering},
+		{"message override", domain.VideoProcess{TenantID: "acme", StorageClass: domain.StorageClassStandardIA}, domain.StorageClassStandardIA},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := useCase.resolveStorageClass(tt.request); got != tt.want {
+				t.Errorf("resolveStorageClass() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExecute_UsesResolvedStorageClass(t *testing.T) {
+	zipFile, err := os.CreateTemp("", "test-zip-*.zip")
+	if err != nil {
+		t.Fatalf("Failed to create zip file: %v", err)
+	}
+	zipFile.Close()
+	defer os.Remove(zipFile.Name())
+
+	var usedClass string
+	storagePort := &mockStoragePort{
+		putObjectFunc: func(ctx context.Context, bucket, key string, body io.Reader, options domain.PutOptions) (string, error) {
+			usedClass = options.StorageClass
+			return key, nil
+		},
+	}
+	videoProcessor := &mockVideoProcessor{
+		processVideoFunc: func(ctx context.Context, jobID, videoPath string, options domain.FrameOptions) ([]string, int, error) {
+			return []string{zipFile.Name()}, 1, nil
+		},
+	}
+
+	useCase := NewProcessVideoUseCase(storagePort, &mockMessagePort{}, videoProcessor, "output-bucket", "output-queue").
+		WithStorageClass(domain.StorageClassStandardIA)
+
+	request := domain.VideoProcess{
+		ProcessID:   "process-class",
+		VideoBucket: "input-bucket",
+		VideoKey:    "video.mp4",
+	}
+
+	if err := useCase.Execute(context.Background(), request); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if usedClass != domain.StorageClassStandardIA {
+		t.Errorf("Expected storage class %s, got %s", domain.StorageClassStandardIA, usedClass)
+	}
+}
+
+func TestExecute_Retention(t *testing.T) {
+	zipFile, err := os.CreateTemp("", "test-zip-*.zip")
+	if err != nil {
+		t.Fatalf("Failed to create zip file: %v", err)
+	}
+	zipFile.Close()
+	defer os.Remove(zipFile.Name())
+
+	puts := make(map[string]domain.PutOptions)
+	var marker []byte
+	storagePort := &mockStoragePort{
+		putObjectFunc: func(ctx context.Context, bucket, key string, body io.Reader, options domain.PutOptions) (string, error) {
+			puts[key] = options
+			if strings.HasPrefix(key, domain.ExpirationsPrefix()) {
+				marker, _ = io.ReadAll(body)
+			}
+			return key, nil
+		},
+	}
+	videoProcessor := &mockVideoProcessor{
+		processVideoFunc: func(ctx context.Context, jobID, videoPath string, options domain.FrameOptions) ([]string, int, error) {
+			return []string{zipFile.Name()}, 1, nil
+		},
+	}
+	tenants := domain.TenantRegistry{
+		"acme": {Retention: &domain.RetentionConfig{Days: 7, Delete: true}},
+	}
+
+	useCase := NewProcessVideoUseCase(storagePort, &mockMessagePort{}, videoProcessor, "output-bucket", "output-queue").
+		WithTenantRegistry(tenants)
+
+	request := domain.VideoProcess{
+		ProcessID:   "process-retention",
+		TenantID:    "acme",
+		VideoBucket: "input-bucket",
+		VideoKey:    "video.mp4",
+	}
+
+	if err := useCase.Execute(context.Background(), request); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	options, ok := puts["processed/frames_process-retention.zip"]
+	if !ok {
+		t.Fatalf("Expected the zip uploaded, got %v", puts)
+	}
+	if options.Tags[domain.RetentionDaysTag] != "7" || options.Tags[domain.ExpiresAtTag] == "" {
+		t.Errorf("Expected retention tags on the zip, got %v", options.Tags)
+	}
+
+	var expiration domain.OutputExpiration
+	if err := json.Unmarshal(marker, &expiration); err != nil {
+		t.Fatalf("Expected an expiration marker: %v", err)
+	}
+	if expiration.Bucket != "output-bucket" || len(expiration.Keys) != 1 || expiration.Keys[0] != "processed/frames_process-retention.zip" {
+		t.Errorf("Unexpected expiration %+v", expiration)
+	}
+	if _, ok := puts[domain.ExpirationKey(expiration.ExpiresAt, "process-retention")]; !ok {
+		t.Errorf("Expected the marker under the expiry day, got %v", puts)
+	}
+}
+
+func TestExecute_InvalidStorageClass(t *testing.T) {
+	useCase := NewProcessVideoUseCase(nil, &mockMessagePort{}, nil, "output-bucket", "output-queue")
+
+	request := domain.VideoProcess{
+		ProcessID:    "process-bad-class",
+		VideoBucket:  "input-bucket",
+		VideoKey:     "video.mp4",
+		StorageClass: "GLACIER",
+	}
+
+	if err := useCase.Execute(context.Background(), request); err == nil {
+		t.Fatal("Expected validation error for unsupported storage class")
+	}
+}
+
+type mockSignerPort struct {
+	signFunc func(ctx context.Context, payload []byte) (string, error)
+}
+
+func (m *mockSignerPort) Sign(ctx context.Context, payload []byte) (string, error) {
+	if m.signFunc != nil {
+		return m.signFunc(ctx, payload)
+	}
+	return "mock-signature", nil
+}
+
+func (m *mockSignerPort) Algorithm() string {
+	return "HMAC-SHA256"
+}
+
+func (m *mockSignerPort) KeyID() string {
+	return "key-1"
+}
+
+func TestExecute_SignsResultMessage(t *testing.T) {
+	var receivedAttributes map[string]string
+	messagePort := &mockMessagePort{
+		sendMessageWithAttributesFunc: func(ctx context.Context, queueURL string, messageBody string, attributes map[string]string) (string, error) {
+			receivedAttributes = attributes
+			return "msg-signed", nil
+		},
+	}
+
+	useCase := NewProcessVideoUseCase(nil, messagePort, nil, "output-bucket", "output-queue").
+		WithSigner(&mockSignerPort{})
+
+	// Validation errors still publish a (signed) error result
+	_ = useCase.Execute(context.Background(), domain.VideoProcess{})
+
+	if receivedAttributes["signature"] != "mock-signature" {
+		t.Errorf("Expected signature attribute, got %v", receivedAttributes)
+	}
+	if receivedAttributes["signature_algorithm"] != "HMAC-SHA256" {
+		t.Errorf("Expected signature_algorithm attribute, got %v", receivedAttributes)
+	}
+	if receivedAttributes["signature_key_id"] != "key-1" {
+		t.Errorf("Expected signature_key_id attribute, got %v", receivedAttributes)
+	}
+}
+
+func TestExecute_SigningError(t *testing.T) {
+	sent := false
+	messagePort := &mockMessagePort{
+		sendMessageFunc: func(ctx context.Context, queueURL string, messageBody string) (string, error) {
+			sent = true
+			return "msg-id", nil
+		},
+	}
+
+	signer := &mockSignerPort{
+		signFunc: func(ctx context.Context, payload []byte) (string, error) {
+			return "", errors.New("kms unavailable")
+		},
+	}
+
+	useCase := NewProcessVideoUseCase(nil, messagePort, nil, "output-bucket", "output-queue").
+		WithSigner(signer)
+
+	if err := useCase.Execute(context.Background(), domain.VideoProcess{}); err == nil {
+		t.Fatal("Expected error when signing fails")
+	}
+	if sent {
+		t.Error("Unsigned message must not be sent when signing fails")
+	}
+}
+
+type mockResultSerializer struct {
+	contentType string
+}
+
+func (m *mockResultSerializer) Serialize(result *domain.ProcessResult) ([]byte, error) {
+	return []byte{0x0a, 0x00, 0xff}, nil
+}
+
+func (m *mockResultSerializer) ContentType() string {
+	return m.contentType
+}
+
+func TestExecute_ResultFormat(t *testing.T) {
+	var receivedBody string
+	var receivedAttributes map[string]string
+	messagePort := &mockMessagePort{
+		sendMessageWithAttributesFunc: func(ctx context.Context, queueURL string, messageBody string, attributes map[string]string) (string, error) {
+			receivedBody = messageBody
+			receivedAttributes = attributes
+			return "msg-id", nil
+		},
+	}
+
+	useCase := NewProcessVideoUseCase(nil, messagePort, nil, "output-bucket", "output-queue").
+		WithResultSerializer(&mockResultSerializer{contentType: domain.ContentTypeProtobuf})
+	_ = useCase.Execute(context.Background(), domain.VideoProcess{})
+
+	// Binary bodies are not valid SQS text, so they are sent base64 encoded
+	if receivedBody != "CgD/" {
+		t.Errorf("Expected the base64 encoded body, got %q", receivedBody)
+	}
+	if receivedAttributes["content_type"] != domain.ContentTypeProtobuf || receivedAttributes["content_transfer_encoding"] != "base64" {
+		t.Errorf("Expected content type attributes, got %v", receivedAttributes)
+	}
+
+	useCase.WithResultSerializer(&mockResultSerializer{contentType: domain.ContentTypeJSON})
+	_ = useCase.Execute(context.Background(), domain.VideoProcess{})
+	if receivedBody != "\x0a\x00\xff" {
+		t.Errorf("Expected a JSON body sent as is, got %q", receivedBody)
+	}
+	if _, ok := receivedAttributes["content_transfer_encoding"]; ok {
+		t.Errorf("Expected no transfer encoding for JSON, got %v", receivedAttributes)
+	}
+}
+
+func TestExecute_CompressesLargeResults(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	var receivedBody string
+	var receivedAttributes map[string]string
+	messagePort := &mockMessagePort{
+		sendMessageWithAttributesFunc: func(ctx context.Context, queueURL string, messageBody string, attributes map[string]string) (string, error) {
+			receivedBody = messageBody
+			receivedAttributes = attributes
+			return "msg-id", nil
+		},
+	}
+
+	useCase := NewProcessVideoUseCase(nil, messagePort, nil, "output-bucket", "output-queue").
+		WithCompression(1024)
+
+	notes := json.RawMessage(`"` + strings.Repeat("frame ", 1000) + `"`)
+	_ = useCase.Execute(context.Background(), domain.VideoProcess{Metadata: map[string]json.RawMessage{"notes": notes}})
+
+	if receivedAttributes["content_encoding"] != "gzip" || receivedAttributes["content_transfer_encoding"] != "base64" {
+		t.Fatalf("Expected gzip and base64 attributes, got %v", receivedAttributes)
+	}
+	compressed, err := base64.StdEncoding.DecodeString(receivedBody)
+	if err != nil {
+		t.Fatalf("Expected a base64 body, got %v", err)
+	}
+	reader, err := gzip.NewReader(bytes.NewReader(compressed))
+	if err != nil {
+		t.Fatalf("Expected a gzip body, got %v", err)
+	}
+	body, _ := io.ReadAll(reader)
+	var result domain.ProcessResult
+	if err := json.Unmarshal(body, &result); err != nil || string(result.Metadata["notes"]) != string(notes) {
+		t.Errorf("Expected the original result after decompressing, got %s", body)
+	}
+
+	// Small results are sent as is
+	messagePort.sendMessageFunc = func(ctx context.Context, queueURL string, messageBody string) (string, error) {
+		receivedBody = messageBody
+		receivedAttributes = nil
+		return "msg-id", nil
+	}
+	_ = useCase.Execute(context.Background(), domain.VideoProcess{})
+	if _, ok := receivedAttributes["content_encoding"]; ok {
+		t.Errorf("Expected no content encoding below the threshold, got %v", receivedAttributes)
+	}
+	if !strings.HasPrefix(receivedBody, "{") {
+		t.Errorf("Expected a plain JSON body, got %q", receivedBody)
+	}
+}
+
+func TestExecute_ResultDestinations(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	zipFile, err := os.CreateTemp("", "test-zip-*.zip")
+	if err != nil {
+		t.Fatalf("Failed to create zip file: %v", err)
+	}
+	zipFile.WriteString("fake zip content")
+	zipFile.Close()
+	defer os.Remove(zipFile.Name())
+
+	videoProcessor := &mockVideoProcessor{
+		processVideoFunc: func(ctx context.Context, jobID, videoPath string, options domain.FrameOptions) ([]string, int, error) {
+			return []string{zipFile.Name()}, 20, nil
+		},
+	}
+
+	var results, reports []string
+	messagePort := &mockMessagePort{
+		sendMessageFunc: func(ctx context.Context, queueURL string, messageBody string) (string, error) {
+			results = append(results, messageBody)
+			return "msg-id", nil
+		},
+		sendMessageWithAttributesFunc: func(ctx context.Context, queueURL string, messageBody string, attributes map[string]string) (string, error) {
+			if attributes[domain.MessageTypeAttribute] == domain.MessageTypeDeliveryReport {
+				reports = append(reports, messageBody)
+			} else {
+				results = append(results, messageBody)
+			}
+			return "msg-id", nil
+		},
+	}
+
+	var published []string
+	sns := &mockMessagePort{
+		sendMessageFunc: func(ctx context.Context, topicARN string, messageBody string) (string, error) {
+			published = append(published, topicARN+" "+messageBody)
+			return "sns-id", nil
+		},
+	}
+	webhook := &mockMessagePort{
+		sendMessageFunc: func(ctx context.Context, webhookURL string, messageBody string) (string, error) {
+			return "", errors.New("webhook returned status 503")
+		},
+	}
+
+	tenants, err := domain.ParseTenantRegistry([]byte(`{"acme":{"result_destinations":["sns:arn:aws:sns:us-east-1:123:acme"]}}`))
+	if err != nil {
+		t.Fatalf("ParseTenantRegistry failed: %v", err)
+	}
+	hook := domain.ResultDestination{Type: domain.ResultDestinationWebhook, Target: "https://hooks.example.com/results"}
+	useCase := NewProcessVideoUseCase(&mockStoragePort{}, messagePort, videoProcessor, "output-bucket", "output-queue").
+		WithTenantRegistry(tenants).
+		WithResultDestinations(map[string]port.MessagePort{
+			domain.ResultDestinationSQS:     messagePort,
+			domain.ResultDestinationSNS:     sns,
+			domain.ResultDestinationWebhook: webhook,
+		}, domain.ResultDestinations{hook})
+
+	err = useCase.Execute(context.Background(), domain.VideoProcess{
+		ProcessID:          "process-fanout",
+		VideoBucket:        "input-bucket",
+		VideoKey:           "video.mp4",
+		TenantID:           "acme",
+		ResultDestinations: domain.ResultDestinations{hook},
+	})
+	if err != nil {
+		t.Fatalf("Expected a failed delivery not to fail the job, got %v", err)
+	}
+
+	if len(results) != 1 || len(published) != 1 || published[0] != "arn:aws:sns:us-east-1:123:acme "+results[0] {
+		t.Errorf("Expected the same result on the output queue and the tenant topic, got %v and %v", results, published)
+	}
+	if len(reports) != 1 {
+		t.Fatalf("Expected a delivery report for the failed webhook, got %v", reports)
+	}
+	var report domain.DeliveryReport
+	if err := json.Unmarshal([]byte(reports[0]), &report); err != nil {
+		t.Fatalf("Failed to decode delivery report: %v", err)
+	}
+	if report.ProcessID != "process-fanout" || !report.ResultSuccess || len(report.FailedDestinations) != 1 ||
+		report.FailedDestinations[0].Destination != hook.String() {
+		t.Errorf("Unexpected delivery report %+v", report)
+	}
+
+	// A destination neither the worker nor the tenant allows fails validation; the error
+	// still reaches the tenant destinations
+	results, published, reports = nil, nil, nil
+	err = useCase.Execute(context.Background(), domain.VideoProcess{
+		ProcessID:          "process-forbidden",
+		VideoBucket:        "input-bucket",
+		VideoKey:           "video.mp4",
+		TenantID:           "acme",
+		ResultDestinations: domain.ResultDestinations{{Type: domain.ResultDestinationSQS, Target: "https://sqs.example.com/other"}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "is not allowed") {
+		t.Errorf("Expected the destination rejected, got %v", err)
+	}
+	if len(results) != 1 || len(published) != 1 || len(reports) != 0 {
+		t.Errorf("Expected the error on the output queue and the tenant topic only, got %v, %v, %v", results, published, reports)
+	}
+}
+
+func TestExecute_LogsCarryJobFields(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+	ctx := observability.WithLogger(context.Background(), zap.New(core).With(zap.String("message_id", "msg-1")))
+
+	useCase := NewProcessVideoUseCase(nil, &mockMessagePort{}, nil, "output-bucket", "output-queue")
+	_ = useCase.Execute(ctx, domain.VideoProcess{ProcessID: "123", TenantID: "acme"})
+
+	// The error result is logged by a helper that only receives the context
+	entries := logs.FilterMessage("sending error message").All()
+	if len(entries) != 1 {
+		t.Fatalf("Expected the error message log, got %d entries", len(entries))
+	}
+	fields := entries[0].ContextMap()
+	if fields["process_id"] != "123" || fields["tenant_id"] != "acme" || fields["message_id"] != "msg-1" {
+		t.Errorf("Expected the job and message fields, got %v", fields)
+	}
+}
+
+type mockEncryptorPort struct {
+	encryptFunc func(ctx context.Context, plaintext []byte) ([]byte, error)
+}
+
+func (m *mockEncryptorPort) Encrypt(ctx context.Context, plaintext []byte) ([]byte, error) {
+	if m.encryptFunc != nil {
+		return m.encryptFunc(ctx, plaintext)
+	}
+	return []byte("encrypted"), nil
+}
+
+func TestExecute_EncryptsThenSignsResultMessage(t *testing.T) {
+	var receivedBody string
+	var receivedAttributes map[string]string
+	messagePort := &mockMessagePort{
+		sendMessageWithAttributesFunc: func(ctx context.Context, queueURL string, messageBody string, attributes map[string]string) (string, error) {
+			receivedBody = messageBody
+			receivedAttributes = attributes
+			return "msg-encrypted", nil
+		},
+	}
+
+	var signedPayload string
+	signer := &mockSignerPort{
+		signFunc: func(ctx context.Context, payload []byte) (string, error) {
+			signedPayload = string(payload)
+			return "sig", nil
+		},
+	}
+
+	useCase := NewProcessVideoUseCase(nil, messagePort, nil, "output-bucket", "output-queue").
+		WithEncryptor(&mockEncryptorPort{}).
+		WithSigner(signer)
+
+	_ = useCase.Execute(context.Background(), domain.VideoProcess{})
+
+	if receivedBody != "encrypted" {
+		t.Errorf("Expected encrypted body, got %s", receivedBody)
+	}
+	if signedPayload != "encrypted" {
+		t.Errorf("Expected signature over the encrypted body, got %s", signedPayload)
+	}
+	if receivedAttributes["content_encryption"] != "kms-envelope" {
+		t.Errorf("Expected content_encryption attribute, got %v", receivedAttributes)
+	}
+}
+
+func TestExecute_EncryptionError(t *testing.T) {
+	sent := false
+	messagePort := &mockMessagePort{
+		sendMessageFunc: func(ctx context.Context, queueURL string, messageBody string) (string, error) {
+			sent = true
+			return "msg-id", nil
+		},
+	}
+
+	encryptor := &mockEncryptorPort{
+		encryptFunc: func(ctx context.Context, plaintext []byte) ([]byte, error) {
+			return nil, errors.New("kms unavailable")
+		},
+	}
+
+	useCase := NewProcessVideoUseCase(nil, messagePort, nil, "output-bucket", "output-queue").
+		WithEncryptor(encryptor)
+
+	if err := useCase.Execute(context.Background(), domain.VideoProcess{}); err == nil {
+		t.Fatal("Expected error when encryption fails")
+	}
+	if sent {
+		t.Error("Plaintext message must not be sent when encryption fails")
+	}
+}
+
+func TestPublishResult_OffloadsLargePayload(t *testing.T) {
+	var uploadedBucket, uploadedKey string
+	var uploadedBody []byte
+	storagePort := &mockStoragePort{
+		putObjectFunc: func(ctx context.Context, bucket, key string, body io.Reader, options domain.PutOptions) (string, error) {
+			uploadedBucket = bucket
+			uploadedKey = key
+			uploadedBody, _ = io.ReadAll(body)
+			return key, nil
+		},
+	}
+
+	var receivedBody string
+	var receivedAttributes map[string]string
+	messagePort := &mockMessagePort{
+		sendMessageWithAttributesFunc: func(ctx context.Context, queueURL string, messageBody string, attributes map[string]string) (string, error) {
+			receivedBody = messageBody
+			receivedAttributes = attributes
+			return "msg-pointer", nil
+		},
+	}
+
+	useCase := NewProcessVideoUseCase(storagePort, messagePort, nil, "output-bucket", "output-queue").
+		WithPayloadOffload("payload-bucket", 10)
+
+	payload := []byte(strings.Repeat("x", 32))
+	if _, err := useCase.publishResult(context.Background(), &domain.ProcessResult{ProcessID: "process-123"}, payload, nil); err != nil {
+		t.Fatalf("publishResult failed: %v", err)
+	}
+
+	if uploadedBucket != "payload-bucket" || !strings.HasPrefix(uploadedKey, "payloads/") {
+		t.Errorf("Unexpected offload location s3://%s/%s", uploadedBucket, uploadedKey)
+	}
+	if string(uploadedBody) != string(payload) {
+		t.Errorf("Expected original payload to be uploaded, got %s", uploadedBody)
+	}
+
+	pointer, ok := domain.DecodePayloadPointer(receivedBody)
+	if !ok {
+		t.Fatalf("Expected pointer body, got %s", receivedBody)
+	}
+	if pointer.Key != uploadedKey {
+		t.Errorf("Expected pointer to %s, got %s", uploadedKey, pointer.Key)
+	}
+	if receivedAttributes[domain.PayloadSizeAttribute] != "32" {
+		t.Errorf("Expected payload size attribute 32, got %v", receivedAttributes)
+	}
+}
+
+func TestPublishResult_SmallPayloadNotOffloaded(t *testing.T) {
+	storagePort := &mockStoragePort{
+		putObjectFunc: func(ctx context.Context, bucket, key string, body io.Reader, options domain.PutOptions) (string, error) {
+			t.Error("Small payload must not be offloaded")
+			return key, nil
+		},
+	}
+
+	var receivedBody string
+	messagePort := &mockMessagePort{
+		sendMessageFunc: func(ctx context.Context, queueURL string, messageBody string) (string, error) {
+			receivedBody = messageBody
+			return "msg-inline", nil
+		},
+	}
+
+	useCase := NewProcessVideoUseCase(storagePort, messagePort, nil, "output-bucket", "output-queue").
+		WithPayloadOffload("payload-bucket", 1024)
+
+	if _, err := useCase.publishResult(context.Background(), &domain.ProcessResult{ProcessID: "process-123"}, []byte(`{"ok":true}`), nil); err != nil {
+		t.Fatalf("publishResult failed: %v", err)
+	}
+	if receivedBody != `{"ok":true}` {
+		t.Errorf("Expected inline body, got %s", receivedBody)
+	}
+}
+
+func TestPublishResult_OffloadError(t *testing.T) {
+	storagePort := &mockStoragePort{
+		putObjectFunc: func(ctx context.Context, bucket, key string, body io.Reader, options domain.PutOptions) (string, error) {
+			return "", errors.New("access denied")
+		},
+	}
+
+	useCase := NewProcessVideoUseCase(storagePort, &mockMessagePort{}, nil, "output-bucket", "output-queue").
+		WithPayloadOffload("payload-bucket", 1)
+
+	if _, err := useCase.publishResult(context.Background(), &domain.ProcessResult{ProcessID: "process-123"}, []byte("payload"), nil); err == nil {
+		t.Fatal("Expected error when offload fails")
+	}
+}
+
+func TestPublishResult_Outbox(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	outbox := newMockOutboxPort()
+	var savedBeforeSend bool
+	messagePort := &mockMessagePort{
+		sendMessageFunc: func(ctx context.Context, queueURL string, messageBody string) (string, error) {
+			savedBeforeSend = len(outbox.entries) == 1
+			return "msg-id", nil
+		},
+	}
+
+	useCase := NewProcessVideoUseCase(&mockStoragePort{}, messagePort, nil, "output-bucket", "output-queue").WithOutbox(outbox)
+	messageID, err := useCase.publishResult(context.Background(), &domain.ProcessResult{ProcessID: "process-123"}, []byte(`{"ok":true}`), nil)
+	if err != nil || messageID != "msg-id" {
+		t.Fatalf("Expected msg-id, got %q, %v", messageID, err)
+	}
+
+	if !savedBeforeSend {
+		t.Error("Expected the message persisted before sending")
+	}
+	if len(outbox.entries) != 0 {
+		t.Errorf("Expected the sent message removed from the outbox, got %v", outbox.entries)
+	}
+}
+
+func TestPublishResult_OutboxKeepsFailedSend(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	outbox := newMockOutboxPort()
+	messagePort := &mockMessagePort{
+		sendMessageFunc: func(ctx context.Context, queueURL string, messageBody string) (string, error) {
+			return "", errors.New("throttled")
+		},
+	}
+
+	useCase := NewProcessVideoUseCase(&mockStoragePort{}, messagePort, nil, "output-bucket", "output-queue").WithOutbox(outbox)
+	if _, err := useCase.publishResult(context.Background(), &domain.ProcessResult{ProcessID: "process-123"}, []byte(`{"ok":true}`), nil); err != nil {
+		t.Fatalf("Expected the failed send left to the outbox, got %v", err)
+	}
+
+	entries, _ := outbox.Pending(context.Background())
+	if len(entries) != 1 {
+		t.Fatalf("Expected 1 entry in the outbox, got %d", len(entries))
+	}
+	if entries[0].ProcessID != "process-123" || entries[0].Body != `{"ok":true}` || entries[0].Attempts != 1 {
+		t.Errorf("Unexpected entry %+v", entries[0])
+	}
+
+	// Once SQS recovers the dispatcher delivers it
+	messagePort.sendMessageFunc = func(ctx context.Context, queueURL string, messageBody string) (string, error) {
+		return "msg-id", nil
+	}
+	entries[0].NextAttemptAt = time.Now()
+	outbox.Save(context.Background(), entries[0])
+	if delivered, _ := NewDispatchOutboxUseCase(outbox, &mockStoragePort{}, messagePort, "output-queue").Dispatch(context.Background()); delivered != 1 {
+		t.Errorf("Expected the entry delivered, got %d", delivered)
+	}
+}
+
+func TestPublishResult_OutboxSaveErrorSendsDirectly(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	outbox := newMockOutboxPort()
+	outbox.saveErr = errors.New("disk full")
+
+	useCase := NewProcessVideoUseCase(&mockStoragePort{}, &mockMessagePort{}, nil, "output-bucket", "output-queue").WithOutbox(outbox)
+	messageID, err := useCase.publishResult(context.Background(), &domain.ProcessResult{ProcessID: "process-123"}, []byte(`{"ok":true}`), nil)
+	if err != nil || messageID != "mock-message-id" {
+		t.Errorf("Expected the message sent without the outbox, got %q, %v", messageID, err)
+	}
+}
+
+type mockScannerPort struct {
+	scanFileFunc func(ctx context.Context, path string) (bool, string, error)
+}
+
+func (m *mockScannerPort) ScanFile(ctx context.Context, path string) (bool, string, error) {
+	if m.scanFileFunc != nil {
+		return m.scanFileFunc(ctx, path)
+	}
+	return false, "", nil
+}
+
+func TestExecute_MalwareDetected(t *testing.T) {
+	var quarantineBucket, quarantineKey string
+	deleted := false
+	storagePort := &mockStoragePort{
+		putObjectFunc: func(ctx context.Context, bucket, key string, body io.Reader, options domain.PutOptions) (string, error) {
+			quarantineBucket = bucket
+			quarantineKey = key
+			return key, nil
+		},
+		deleteObjectFunc: func(ctx context.Context, bucket, key string) error {
+			deleted = true
+			return nil
+		},
+	}
+
+	var sentMessage string
+	messagePort := &mockMessagePort{
+		sendMessageFunc: func(ctx context.Context, queueURL string, messageBody string) (string, error) {
+			sentMessage = messageBody
+			return "msg-id", nil
+		},
+	}
+
+	videoProcessor := &mockVideoProcessor{
+		processVideoFunc: func(ctx context.Context, jobID, videoPath string, options domain.FrameOptions) ([]string, int, error) {
+			t.Error("Infected video must not be processed")
+			return nil, 0, nil
+		},
+	}
+
+	scanner := &mockScannerPort{
+		scanFileFunc: func(ctx context.Context, path string) (bool, string, error) {
+			return true, "Eicar-Test-Signature", nil
+		},
+	}
+
+	useCase := NewProcessVideoUseCase(storagePort, messagePort, videoProcessor, "output-bucket", "output-queue").
+		WithScanner(scanner, "quarantine-bucket", "quarantine")
+
+	request := domain.VideoProcess{
+		ProcessID:   "process-infected",
+		VideoBucket: "input-bucket",
+		VideoKey:    "uploads/video.mp4",
+	}
+
+	err := useCase.Execute(context.Background(), request)
+	if domain.ErrorCode(err) != domain.ErrorCodeMalwareDetected {
+		t.Fatalf("Expected malware_detected error, got %v", err)
+	}
+	if !strings.Contains(sentMessage, `"error_code":"malware_detected"`) {
+		t.Errorf("Expected malware_detected error code in result, got %s", sentMessage)
+	}
+	if quarantineBucket != "quarantine-bucket" || quarantineKey != "quarantine/process-infected/video.mp4" {
+		t.Errorf("Unexpected quarantine location s3://%s/%s", quarantineBucket, quarantineKey)
+	}
+	if !deleted {
+		t.Error("Expected source video to be removed after quarantine")
+	}
+}
+
+func TestExecute_MalwareQuarantineCopy(t *testing.T) {
+	var copied string
+	deleted := false
+	storagePort := &mockStoragePort{
+		copyObjectFunc: func(ctx context.Context, srcBucket, srcKey, srcVersionID, dstBucket, dstKey string, options domain.PutOptions) error {
+			copied = srcBucket + "/" + srcKey + " -> " + dstBucket + "/" + dstKey + " #" + options.Tags[domain.QuarantineReasonTag]
+			return nil
+		},
+		putObjectFunc: func(ctx context.Context, bucket, key string, body io.Reader, options domain.PutOptions) (string, error) {
+			t.Errorf("Expected a server-side copy instead of uploading %s", key)
+			return key, nil
+		},
+		deleteObjectFunc: func(ctx context.Context, bucket, key string) error {
+			deleted = true
+			return nil
+		},
+	}
+	scanner := &mockScannerPort{
+		scanFileFunc: func(ctx context.Context, path string) (bool, string, error) {
+			return true, "Eicar-Test-Signature", nil
+		},
+	}
+
+	var sentMessage string
+	messagePort := &mockMessagePort{
+		sendMessageFunc: func(ctx context.Context, queueURL string, messageBody string) (string, error) {
+			sentMessage = messageBody
+			return "msg-id", nil
+		},
+	}
+
+	useCase := NewProcessVideoUseCase(storagePort, messagePort, &mockVideoProcessor{}, "output-bucket", "output-queue").
+		WithScanner(scanner, "quarantine-bucket", "quarantine")
+
+	request := domain.VideoProcess{ProcessID: "process-infected", VideoBucket: "input-bucket", VideoKey: "uploads/video.mp4"}
+	if err := useCase.Execute(context.Background(), request); domain.ErrorCode(err) != domain.ErrorCodeMalwareDetected {
+		t.Fatalf("Expected malware_detected error, got %v", err)
+	}
+	if copied != "input-bucket/uploads/video.mp4 -> quarantine-bucket/quarantine/process-infected/video.mp4 #malware_detected" {
+		t.Errorf("Unexpected quarantine copy %q", copied)
+	}
+	if !deleted {
+		t.Error("Expected source video to be removed after quarantine")
+	}
+	if !strings.Contains(sentMessage, `"quarantine":{"bucket":"quarantine-bucket","key":"quarantine/process-infected/video.mp4"}`) {
+		t.Errorf("Expected the quarantine location in the result, got %s", sentMessage)
+	}
+}
+
+func TestExecute_QuarantinesInvalidInputs(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		request    domain.VideoProcess
+		size       int64
+		processErr error
+		wantCopy   string
+	}{
+		{
+			name:     "unsupported format",
+			request:  domain.VideoProcess{VideoKey: "uploads/notes.txt"},
+			size:     10,
+			wantCopy: "input/uploads/notes.txt -> quarantine-bucket/invalid/p1/notes.txt #unsupported_format",
+		},
+		{
+			name:     "empty video",
+			request:  domain.VideoProcess{VideoKey: "uploads/video.mp4"},
+			wantCopy: "input/uploads/video.mp4 -> quarantine-bucket/invalid/p1/video.mp4 #empty_video",
+		},
+		{
+			name:       "unsupported codec",
+			request:    domain.VideoProcess{VideoKey: "uploads/video.mp4"},
+			size:       10,
+			processErr: domain.NewCodedError(domain.ErrorCodeUnsupportedCodec, errors.New("video codec prores is not supported")),
+			wantCopy:   "input/uploads/video.mp4 -> quarantine-bucket/invalid/p1/video.mp4 #unsupported_codec",
+		},
+		{
+			name:       "failure of the worker",
+			request:    domain.VideoProcess{VideoKey: "uploads/video.mp4"},
+			size:       10,
+			processErr: errors.New("ffmpeg crashed"),
+		},
+		{
+			name:    "video kept by the producer",
+			request: domain.VideoProcess{VideoKey: "uploads/video.mp4", KeepOriginal: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var copied, deleted, sentMessage string
+			storagePort := &mockStoragePort{
+				headObjectFunc: func(ctx context.Context, bucket, key string) (domain.ObjectInfo, error) {
+					return domain.ObjectInfo{SizeBytes: tt.size}, nil
+				},
+				getObjectFunc: func(ctx context.Context, bucket, key string, options domain.GetOptions) (io.ReadCloser, error) {
+					return io.NopCloser(strings.NewReader("0123456789")), nil
+				},
+				copyObjectFunc: func(ctx context.Context, srcBucket, srcKey, srcVersionID, dstBucket, dstKey string, options domain.PutOptions) error {
+					copied = srcBucket + "/" + srcKey + " -> " + dstBucket + "/" + dstKey + " #" + options.Tags[domain.QuarantineReasonTag]
+					return nil
+				},
+				deleteObjectFunc: func(ctx context.Context, bucket, key string) error {
+					deleted = bucket + "/" + key
+					return nil
+				},
+			}
+			messagePort := &mockMessagePort{
+				sendMessageFunc: func(ctx context.Context, queueURL string, messageBody string) (string, error) {
+					sentMessage = messageBody
+					return "msg-id", nil
+				},
+			}
+			videoProcessor := &mockVideoProcessor{
+				processVideoFunc: func(ctx context.Context, jobID, videoPath string, options domain.FrameOptions) ([]string, int, error) {
+					return nil, 0, tt.processErr
+				},
+			}
+
+			useCase := NewProcessVideoUseCase(storagePort, messagePort, videoProcessor, "output-bucket", "output-queue").
+				WithInputQuarantine("quarantine-bucket", "invalid")
+
+			request := tt.request
+			request.ProcessID = "p1"
+			request.VideoBucket = "input"
+			if err := useCase.Execute(context.Background(), request); err == nil {
+				t.Fatal("Expected the job to fail")
+			}
+
+			if copied != tt.wantCopy {
+				t.Errorf("Expected copy %q, got %q", tt.wantCopy, copied)
+			}
+			if tt.wantCopy == "" {
+				if deleted != "" || strings.Contains(sentMessage, "quarantine") {
+					t.Errorf("Expected the video left in place, deleted %q and sent %s", deleted, sentMessage)
+				}
+				return
+			}
+			if deleted != "input/"+request.VideoKey {
+				t.Errorf("Expected the source removed, got %q", deleted)
+			}
+			if !strings.Contains(sentMessage, `"quarantine":{"bucket":"quarantine-bucket","key":"invalid/p1/`) {
+				t.Errorf("Expected the quarantine location in the result, got %s", sentMessage)
+			}
+		})
+	}
+}
+
+func TestExecute_ScanError(t *testing.T) {
+	scanner := &mockScannerPort{
+		scanFileFunc: func(ctx context.Context, path string) (bool, string, error) {
+			return false, "", errors.New("clamd unavailable")
+		},
+	}
+
+	useCase := NewProcessVideoUseCase(&mockStoragePort{}, &mockMessagePort{}, &mockVideoProcessor{}, "output-bucket", "output-queue").
+		WithScanner(scanner, "quarantine-bucket", "quarantine")
+
+	request := domain.VideoProcess{
+		ProcessID:   "process-scan-error",
+		VideoBucket: "input-bucket",
+		VideoKey:    "video.mp4",
+	}
+
+	err := useCase.Execute(context.Background(), request)
+	if err == nil {
+		t.Fatal("Expected error when the scanner fails")
+	}
+	if domain.ErrorCode(err) == domain.ErrorCodeMalwareDetected {
+		t.Error("Scanner failures must not be reported as malware")
+	}
+}
+
+func TestNewJobID(t *testing.T) {
+	first, err := newJobID("process-123")
+	if err != nil {
+		t.Fatalf("newJobID failed: %v", err)
+	}
+	second, _ := newJobID("process-123")
+
+	if !strings.HasPrefix(first, "process-123_") {
+		t.Errorf("Expected job id prefixed by process id, got %s", first)
+	}
+	if first == second {
+		t.Errorf("Expected unique job ids for the same process id, got %s twice", first)
+	}
+}
+
+func TestExecute_SpriteOutput(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	zipFile, err := os.CreateTemp("", "test-sprites-*.zip")
+	if err != nil {
+		t.Fatalf("Failed to create zip file: %v", err)
+	}
+	zipFile.Close()
+	defer os.Remove(zipFile.Name())
+
+	var uploadedKey string
+	storagePort := &mockStoragePort{
+		getObjectFunc: func(ctx context.Context, bucket, key string, options domain.GetOptions) (io.ReadCloser, error) {
+			return io.NopCloser(strings.NewReader("fake video content")), nil
+		},
+		putObjectFunc: func(ctx context.Context, bucket, key string, body io.Reader, options domain.PutOptions) (string, error) {
+			uploadedKey = key
+			return "s3://bucket/key", nil
+		},
+		deleteObjectFunc: func(ctx context.Context, bucket, key string) error {
+			return nil
+		},
+	}
+
+	var sentBody string
+	messagePort := &mockMessagePort{
+		sendMessageFunc: func(ctx context.Context, queueURL string, messageBody string) (string, error) {
+			sentBody = messageBody
+			return "msg-id", nil
+		},
+	}
+
+	var receivedOptions domain.SpriteOptions
+	videoProcessor := &mockVideoProcessor{
+		processVideoFunc: func(ctx context.Context, jobID, videoPath string, options domain.FrameOptions) ([]string, int, error) {
+			t.Error("Expected frames extraction not to run for sprite output")
+			return nil, 0, nil
+		},
+		generateSpriteSheetFunc: func(ctx context.Context, jobID, videoPath string, sprite domain.SpriteOptions, options domain.FrameOptions) ([]string, int, error) {
+			receivedOptions = sprite
+			return []string{zipFile.Name()}, 2, nil
+		},
+	}
+
+	useCase := NewProcessVideoUseCase(storagePort, messagePort, videoProcessor, "output-bucket", "output-queue")
+
+	err = useCase.Execute(context.Background(), domain.VideoProcess{
+		ProcessID:   "process-123",
+		VideoBucket: "input-bucket",
+		VideoKey:    "video.mp4",
+		OutputType:  domain.OutputTypeSprite,
+		Sprite:      domain.SpriteOptions{Columns: 4, Rows: 3},
+	})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+
+	if receivedOptions.Columns != 4 || receivedOptions.Rows != 3 {
+		t.Errorf("Expected grid 4x3, got %dx%d", receivedOptions.Columns, receivedOptions.Rows)
+	}
+	if uploadedKey != "processed/sprites_process-123.zip" {
+		t.Errorf("Expected sprites output key, got %s", uploadedKey)
+	}
+	if !strings.Contains(sentBody, `"output_type":"sprite"`) {
+		t.Errorf("Expected output_type in success message, got %s", sentBody)
+	}
+}
+
+func TestExecute_InvalidOutputType(t *testing.T) {
 	if err := observability.InitLogger("test"); err != nil {
 		t.Fatalf("Failed to setup logger: %v", err)
 	}
 
-	tmpFile, err := os.CreateTemp("", "test-video-*.mp4")
-	if err != nil {
-		t.Fatalf("Failed to create temp file: %v", err)
+	var sentBody string
+	messagePort := &mockMessagePort{
+		sendMessageFunc: func(ctx context.Context, queueURL string, messageBody string) (string, error) {
+			sentBody = messageBody
+			return "msg-id", nil
+		},
+	}
+
+	useCase := NewProcessVideoUseCase(nil, messagePort, nil, "output-bucket", "output-queue")
+
+	err := useCase.Execute(context.Background(), domain.VideoProcess{
+		ProcessID:   "process-123",
+		VideoBucket: "input-bucket",
+		VideoKey:    "video.mp4",
+		OutputType:  "gif",
+	})
+	if err == nil {
+		t.Fatal("Expected error for unsupported output type")
+	}
+
+	if !strings.Contains(sentBody, "unsupported output type") {
+		t.Errorf("Expected validation error message, got %s", sentBody)
 	}
-	tmpFile.WriteString("fake video content")
-	tmpFile.Close()
-	defer os.Remove(tmpFile.Name())
+}
 
-	zipFile, err := os.CreateTemp("", "test-zip-*.zip")
-	if err != nil {
-		t.Fatalf("Failed to create zip file: %v", err)
+type mockPackager struct {
+	packageFunc func(ctx context.Context, jobID, videoPath, outputType string, packaging domain.PackagingOptions, options domain.FrameOptions) (string, error)
+}
+
+func (m *mockPackager) Package(ctx context.Context, jobID, videoPath, outputType string, packaging domain.PackagingOptions, options domain.FrameOptions) (string, error) {
+	return m.packageFunc(ctx, jobID, videoPath, outputType, packaging, options)
+}
+
+func TestExecute_HLSOutput(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
 	}
-	zipFile.WriteString("fake zip content")
-	zipFile.Close()
-	defer os.Remove(zipFile.Name())
 
+	packageDir := t.TempDir()
+	os.MkdirAll(filepath.Join(packageDir, "stream_0"), 0777)
+	os.WriteFile(filepath.Join(packageDir, "master.m3u8"), []byte("#EXTM3U"), 0644)
+	os.WriteFile(filepath.Join(packageDir, "stream_0", "playlist.m3u8"), []byte("#EXTM3U"), 0644)
+	os.WriteFile(filepath.Join(packageDir, "stream_0", "segment_000.ts"), []byte("ts"), 0644)
+
+	var uploadedKeys []string
 	storagePort := &mockStoragePort{
-		getObjectFunc: func(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
-			file, err := os.Open(tmpFile.Name())
-			return file, err
+		getObjectFunc: func(ctx context.Context, bucket, key string, options domain.GetOptions) (io.ReadCloser, error) {
+			return io.NopCloser(strings.NewReader("fake video content")), nil
 		},
-		putObjectFunc: func(ctx context.Context, bucket, key string, body io.Reader) (string, error) {
+		putObjectFunc: func(ctx context.Context, bucket, key string, body io.Reader, options domain.PutOptions) (string, error) {
+			uploadedKeys = append(uploadedKeys, key)
 			return "s3://bucket/key", nil
 		},
 		deleteObjectFunc: func(ctx context.Context, bucket, key string) error {
@@ -464,132 +4066,202 @@ func TestExecute_SendSuccessMessageError(t *testing.T) {
 		},
 	}
 
+	var sentBody string
 	messagePort := &mockMessagePort{
 		sendMessageFunc: func(ctx context.Context, queueURL string, messageBody string) (string, error) {
-			return "", errors.New("send message failed")
+			sentBody = messageBody
+			return "msg-id", nil
 		},
 	}
 
-	videoProcessor := &mockVideoProcessor{
-		processVideoFunc: func(ctx context.Context, videoPath string) (string, int, error) {
-			return zipFile.Name(), 15, nil
+	packager := &mockPackager{
+		packageFunc: func(ctx context.Context, jobID, videoPath, outputType string, packaging domain.PackagingOptions, options domain.FrameOptions) (string, error) {
+			if outputType != domain.OutputTypeHLS {
+				t.Errorf("Expected hls output type, got %s", outputType)
+			}
+			return packageDir, nil
 		},
 	}
 
-	useCase := NewProcessVideoUseCase(storagePort, messagePort, videoProcessor, "output-bucket", "output-queue")
+	useCase := NewProcessVideoUseCase(storagePort, messagePort, &mockVideoProcessor{}, "output-bucket", "output-queue").
+		WithPackager(packager)
 
-	request := domain.VideoProcess{
-		ProcessID:   "process-msg-error",
+	err := useCase.Execute(context.Background(), domain.VideoProcess{
+		ProcessID:   "process-123",
 		VideoBucket: "input-bucket",
 		VideoKey:    "video.mp4",
+		OutputType:  domain.OutputTypeHLS,
+	})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
 	}
 
-	err = useCase.Execute(context.Background(), request)
-	if err == nil {
-		t.Fatal("Expected error from send message")
+	expected := map[string]bool{
+		"processed/process-123/hls/master.m3u8":             true,
+		"processed/process-123/hls/stream_0/playlist.m3u8":  true,
+		"processed/process-123/hls/stream_0/segment_000.ts": true,
+	}
+	if len(uploadedKeys) != len(expected) {
+		t.Fatalf("Expected %d uploads, got %v", len(expected), uploadedKeys)
+	}
+	for _, key := range uploadedKeys {
+		if !expected[key] {
+			t.Errorf("Unexpected uploaded key %s", key)
+		}
+	}
+	if !strings.Contains(sentBody, `"file_key":"processed/process-123/hls/master.m3u8"`) {
+		t.Errorf("Expected master playlist key in success message, got %s", sentBody)
+	}
+	if _, err := os.Stat(packageDir); !os.IsNotExist(err) {
+		t.Error("Expected package directory to be removed after upload")
 	}
 }
 
-func TestExecute_SendErrorMessage_ValidationError(t *testing.T) {
+func TestExecute_PackagingNotEnabled(t *testing.T) {
 	if err := observability.InitLogger("test"); err != nil {
 		t.Fatalf("Failed to setup logger: %v", err)
 	}
 
+	storagePort := &mockStoragePort{
+		getObjectFunc: func(ctx context.Context, bucket, key string, options domain.GetOptions) (io.ReadCloser, error) {
+			return io.NopCloser(strings.NewReader("fake video content")), nil
+		},
+	}
+
+	var sentBody string
 	messagePort := &mockMessagePort{
 		sendMessageFunc: func(ctx context.Context, queueURL string, messageBody string) (string, error) {
+			sentBody = messageBody
 			return "msg-id", nil
 		},
 	}
 
-	useCase := NewProcessVideoUseCase(nil, messagePort, nil, "output-bucket", "output-queue")
+	useCase := NewProcessVideoUseCase(storagePort, messagePort, &mockVideoProcessor{}, "output-bucket", "output-queue")
 
-	// Test with empty request to trigger validation error
+	err := useCase.Execute(context.Background(), domain.VideoProcess{
+		ProcessID:   "process-123",
+		VideoBucket: "input-bucket",
+		VideoKey:    "video.mp4",
+		OutputType:  domain.OutputTypeDASH,
+	})
+	if err == nil {
+		t.Fatal("Expected error when packaging is not enabled")
+	}
+	if !strings.Contains(sentBody, "dash output is not enabled") {
+		t.Errorf("Expected not enabled error message, got %s", sentBody)
+	}
+}
+
+func TestValidateRequest_TimeWindows(t *testing.T) {
+	useCase := NewProcessVideoUseCase(nil, nil, nil, "output-bucket", "output-queue")
 	request := domain.VideoProcess{
-		ProcessID:   "",
-		VideoBucket: "",
-		VideoKey:    "",
+		ProcessID:   "process-123",
+		VideoBucket: "input-bucket",
+		VideoKey:    "video.mp4",
+		Windows:     []domain.TimeWindow{{Start: 10, End: 20}},
 	}
 
-	err := useCase.Execute(context.Background(), request)
-	if err == nil {
-		t.Fatal("Expected validation error")
+	if err := useCase.validateRequest(request); err != nil {
+		t.Errorf("Expected windows to be valid for frames, got %v", err)
+	}
+
+	request.OutputType = domain.OutputTypeHLS
+	if err := useCase.validateRequest(request); err == nil {
+		t.Error("Expected error for windows with hls output")
+	}
+
+	request.OutputType = ""
+	request.Windows = []domain.TimeWindow{{Start: 20, End: 10}}
+	if err := useCase.validateRequest(request); err == nil {
+		t.Error("Expected error for inverted window")
 	}
 }
 
-func TestDownloadVideo_ReadError(t *testing.T) {
+func TestExecute_SplitZipParts(t *testing.T) {
 	if err := observability.InitLogger("test"); err != nil {
 		t.Fatalf("Failed to setup logger: %v", err)
 	}
 
-	// Create a reader that will fail on read
-	failingReader := &failingReadCloser{}
+	dir := t.TempDir()
+	parts := []string{filepath.Join(dir, "frames.part1.zip"), filepath.Join(dir, "frames.part2.zip")}
+	for _, part := range parts {
+		if err := os.WriteFile(part, []byte("zip"), 0644); err != nil {
+			t.Fatalf("Failed to write part: %v", err)
+		}
+	}
 
+	var uploadedKeys []string
 	storagePort := &mockStoragePort{
-		getObjectFunc: func(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
-			return failingReader, nil
+		getObjectFunc: func(ctx context.Context, bucket, key string, options domain.GetOptions) (io.ReadCloser, error) {
+			return io.NopCloser(strings.NewReader("fake video content")), nil
+		},
+		putObjectFunc: func(ctx context.Context, bucket, key string, body io.Reader, options domain.PutOptions) (string, error) {
+			uploadedKeys = append(uploadedKeys, key)
+			return "s3://bucket/key", nil
+		},
+		deleteObjectFunc: func(ctx context.Context, bucket, key string) error {
+			return nil
 		},
 	}
 
+	var sentBody string
 	messagePort := &mockMessagePort{
 		sendMessageFunc: func(ctx context.Context, queueURL string, messageBody string) (string, error) {
+			sentBody = messageBody
 			return "msg-id", nil
 		},
 	}
 
-	useCase := NewProcessVideoUseCase(storagePort, messagePort, nil, "output-bucket", "output-queue")
+	videoProcessor := &mockVideoProcessor{
+		processVideoFunc: func(ctx context.Context, jobID, videoPath string, options domain.FrameOptions) ([]string, int, error) {
+			return parts, 100, nil
+		},
+	}
 
-	request := domain.VideoProcess{
-		ProcessID:   "test-fail-read",
-		VideoBucket: "bucket",
+	useCase := NewProcessVideoUseCase(storagePort, messagePort, videoProcessor, "output-bucket", "output-queue")
+	err := useCase.Execute(context.Background(), domain.VideoProcess{
+		ProcessID:   "process-123",
+		VideoBucket: "input-bucket",
 		VideoKey:    "video.mp4",
+	})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
 	}
 
-	err := useCase.Execute(context.Background(), request)
-	if err == nil {
-		t.Fatal("Expected error from read failure")
+	want := []string{"processed/frames_process-123.part1.zip", "processed/frames_process-123.part2.zip"}
+	if strings.Join(uploadedKeys, ",") != strings.Join(want, ",") {
+		t.Errorf("Expected parts uploaded as %v, got %v", want, uploadedKeys)
+	}
+	if !strings.Contains(sentBody, `"file_key":"processed/frames_process-123.part1.zip"`) {
+		t.Errorf("Expected file_key to point to the first part, got %s", sentBody)
+	}
+	if !strings.Contains(sentBody, `"file_keys":["processed/frames_process-123.part1.zip","processed/frames_process-123.part2.zip"]`) {
+		t.Errorf("Expected every part listed in file_keys, got %s", sentBody)
+	}
+	for _, part := range parts {
+		if _, err := os.Stat(part); !os.IsNotExist(err) {
+			t.Errorf("Expected part %s to be removed after upload", part)
+		}
 	}
 }
 
-// Helper type for testing read failures
-type failingReadCloser struct{}
-
-func (f *failingReadCloser) Read(p []byte) (n int, err error) {
-	return 0, errors.New("read error")
-}
-
-func (f *failingReadCloser) Close() error {
-	return nil
-}
-
-func TestUploadZip_OpenFileError(t *testing.T) {
+func TestExecute_AcceptNegotiation(t *testing.T) {
 	if err := observability.InitLogger("test"); err != nil {
 		t.Fatalf("Failed to setup logger: %v", err)
 	}
 
-	tmpFile, err := os.CreateTemp("", "test-video-*.mp4")
-	if err != nil {
-		t.Fatalf("Failed to create temp file: %v", err)
-	}
-	tmpFile.WriteString("fake video content")
-	tmpFile.Close()
-	defer os.Remove(tmpFile.Name())
-
-	zipFile, err := os.CreateTemp("", "test-zip-*.zip")
-	if err != nil {
-		t.Fatalf("Failed to create zip file: %v", err)
+	tarball := filepath.Join(t.TempDir(), "frames.tar.gz")
+	if err := os.WriteFile(tarball, []byte("tar"), 0644); err != nil {
+		t.Fatalf("Failed to write tarball: %v", err)
 	}
-	zipFile.WriteString("fake zip content")
-	zipFile.Close()
-	zipPath := zipFile.Name()
-	// Remove it to cause open error
-	os.Remove(zipPath)
 
+	var uploadedKeys []string
 	storagePort := &mockStoragePort{
-		getObjectFunc: func(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
-			file, err := os.Open(tmpFile.Name())
-			return file, err
+		getObjectFunc: func(ctx context.Context, bucket, key string, options domain.GetOptions) (io.ReadCloser, error) {
+			return io.NopCloser(strings.NewReader("fake video content")), nil
 		},
-		putObjectFunc: func(ctx context.Context, bucket, key string, body io.Reader) (string, error) {
+		putObjectFunc: func(ctx context.Context, bucket, key string, body io.Reader, options domain.PutOptions) (string, error) {
+			uploadedKeys = append(uploadedKeys, key)
 			return "s3://bucket/key", nil
 		},
 		deleteObjectFunc: func(ctx context.Context, bucket, key string) error {
@@ -597,29 +4269,321 @@ func TestUploadZip_OpenFileError(t *testing.T) {
 		},
 	}
 
+	var sentBody string
 	messagePort := &mockMessagePort{
 		sendMessageFunc: func(ctx context.Context, queueURL string, messageBody string) (string, error) {
-			return "msg-success", nil
+			sentBody = messageBody
+			return "msg-id", nil
 		},
 	}
 
+	var processed domain.FrameOptions
 	videoProcessor := &mockVideoProcessor{
-		processVideoFunc: func(ctx context.Context, videoPath string) (string, int, error) {
-			// Return the removed zip path to trigger open error
-			return zipPath, 10, nil
+		processVideoFunc: func(ctx context.Context, jobID, videoPath string, options domain.FrameOptions) ([]string, int, error) {
+			processed = options
+			return []string{tarball}, 10, nil
 		},
 	}
 
 	useCase := NewProcessVideoUseCase(storagePort, messagePort, videoProcessor, "output-bucket", "output-queue")
-
-	request := domain.VideoProcess{
-		ProcessID:   "test-open-error",
+	err := useCase.Execute(context.Background(), domain.VideoProcess{
+		ProcessID:   "process-123",
 		VideoBucket: "input-bucket",
 		VideoKey:    "video.mp4",
+		Accept:      domain.AcceptOptions{Encodings: []string{"none", "tar.gz", "zip"}, ImageFormats: []string{"avif", "jpeg"}},
+	})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
 	}
 
-	err = useCase.Execute(context.Background(), request)
+	if processed.Archive.Encoding != domain.ArchiveEncodingTarGz || processed.Image.Format != domain.ImageFormatJPEG {
+		t.Errorf("Expected the negotiated encoding and image format processed, got %+v and %+v", processed.Archive, processed.Image)
+	}
+	if len(uploadedKeys) != 1 || uploadedKeys[0] != "processed/frames_process-123.tar.gz" {
+		t.Errorf("Expected the tarball uploaded, got %v", uploadedKeys)
+	}
+	if !strings.Contains(sentBody, `"negotiated":{"encoding":"tar.gz","image_format":"jpeg"}`) {
+		t.Errorf("Expected the negotiated output in the result, got %s", sentBody)
+	}
+}
+
+func TestExecute_AcceptNotAcceptable(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	tests := map[string]domain.VideoProcess{
+		"no supported encoding": {Accept: domain.AcceptOptions{Encodings: []string{"none"}}},
+		"sprites are jpeg":      {OutputType: domain.OutputTypeSprite, Accept: domain.AcceptOptions{ImageFormats: []string{"png"}}},
+		"combined batches are zips": {
+			VideoKeys:   []string{"a.mp4", "b.mp4"},
+			BatchOutput: domain.BatchOutputCombined,
+			Accept:      domain.AcceptOptions{Encodings: []string{"tar.gz"}},
+		},
+	}
+	for name, request := range tests {
+		t.Run(name, func(t *testing.T) {
+			var sentMessage string
+			messagePort := &mockMessagePort{
+				sendMessageFunc: func(ctx context.Context, queueURL string, messageBody string) (string, error) {
+					sentMessage = messageBody
+					return "msg-id", nil
+				},
+			}
+			videoProcessor := &mockVideoProcessor{
+				processVideoFunc: func(ctx context.Context, jobID, videoPath string, options domain.FrameOptions) ([]string, int, error) {
+					t.Error("Expected nothing processed")
+					return nil, 0, nil
+				},
+			}
+
+			request.ProcessID, request.VideoBucket = "process-123", "input-bucket"
+			if len(request.VideoKeys) == 0 {
+				request.VideoKey = "video.mp4"
+			}
+			useCase := NewProcessVideoUseCase(&mockStoragePort{}, messagePort, videoProcessor, "output-bucket", "output-queue").WithArchiveMerger(&mockArchiveMerger{})
+			if err := useCase.Execute(context.Background(), request); domain.ErrorCode(err) != domain.ErrorCodeNotAcceptable {
+				t.Fatalf("Expected not_acceptable error, got %v", err)
+			}
+			if !strings.Contains(sentMessage, `"error_code":"not_acceptable"`) {
+				t.Errorf("Expected a not_acceptable error, got %s", sentMessage)
+			}
+		})
+	}
+}
+
+func TestExecute_DryRun(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	for _, tc := range []struct {
+		name     string
+		workerOn bool
+		request  bool
+	}{
+		{"message option", false, true},
+		{"worker flag", true, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var puts, deletes int
+			storagePort := &mockStoragePort{
+				putObjectFunc: func(ctx context.Context, bucket, key string, body io.Reader, options domain.PutOptions) (string, error) {
+					puts++
+					return key, nil
+				},
+				deleteObjectFunc: func(ctx context.Context, bucket, key string) error {
+					deletes++
+					return nil
+				},
+			}
+
+			var sentBody string
+			messagePort := &mockMessagePort{
+				sendMessageFunc: func(ctx context.Context, queueURL string, messageBody string) (string, error) {
+					sentBody = messageBody
+					return "msg-id", nil
+				},
+			}
+
+			videoProcessor := &mockVideoProcessor{
+				processVideoFunc: func(ctx context.Context, jobID, videoPath string, options domain.FrameOptions) ([]string, int, error) {
+					t.Error("Expected no frames extracted on a dry run")
+					return nil, 0, nil
+				},
+			}
+
+			useCase := NewProcessVideoUseCase(storagePort, messagePort, videoProcessor, "output-bucket", "output-queue").WithDryRun(tc.workerOn)
+			err := useCase.Execute(context.Background(), domain.VideoProcess{
+				ProcessID:   "process-123",
+				VideoBucket: "input-bucket",
+				VideoKey:    "video.mp4",
+				DryRun:      tc.request,
+			})
+			if err != nil {
+				t.Fatalf("Execute failed: %v", err)
+			}
+
+			if puts != 0 || deletes != 0 {
+				t.Errorf("Expected no uploads or deletes, got %d puts and %d deletes", puts, deletes)
+			}
+			if !strings.Contains(sentBody, `"dry_run":true`) {
+				t.Errorf("Expected dry_run in the result, got %s", sentBody)
+			}
+			if !strings.Contains(sentBody, `"count":10`) {
+				t.Errorf("Expected 10 estimated frames, got %s", sentBody)
+			}
+			if strings.Contains(sentBody, "file_key") {
+				t.Errorf("Expected no file_key on a dry run, got %s", sentBody)
+			}
+		})
+	}
+}
+
+func TestExecute_DryRunProbeFailure(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	var sentBody string
+	messagePort := &mockMessagePort{
+		sendMessageFunc: func(ctx context.Context, queueURL string, messageBody string) (string, error) {
+			sentBody = messageBody
+			return "msg-id", nil
+		},
+	}
+	videoProcessor := &mockVideoProcessor{
+		probeVideoFunc: func(ctx context.Context, videoPath string) (domain.VideoInfo, error) {
+			return domain.VideoInfo{}, errors.New("invalid data found")
+		},
+	}
+
+	useCase := NewProcessVideoUseCase(&mockStoragePort{}, messagePort, videoProcessor, "output-bucket", "output-queue")
+	err := useCase.Execute(context.Background(), domain.VideoProcess{
+		ProcessID:   "process-123",
+		VideoBucket: "input-bucket",
+		VideoKey:    "video.mp4",
+		DryRun:      true,
+	})
 	if err == nil {
-		t.Fatal("Expected error from file open")
+		t.Fatal("Expected error when the probe fails")
+	}
+	if !strings.Contains(sentBody, "failed to probe video") {
+		t.Errorf("Expected the probe failure in the error message, got %s", sentBody)
+	}
+}
+
+func TestValidateRequest_Batch(t *testing.T) {
+	useCase := NewProcessVideoUseCase(nil, nil, nil, "output-bucket", "output-queue").
+		WithSourceAllowlist(domain.SourceAllowlist{{Bucket: "input-bucket", Prefix: "uploads/"}})
+	request := domain.VideoProcess{
+		ProcessID:   "process-123",
+		VideoBucket: "input-bucket",
+		VideoKeys:   []string{"uploads/a.mp4", "uploads/b.mp4"},
+	}
+
+	if err := useCase.validateRequest(request); err != nil {
+		t.Errorf("Expected a valid batch, got %v", err)
+	}
+
+	request.VideoKeys = []string{"uploads/a.mp4", "private/b.mp4"}
+	if err := useCase.validateRequest(request); domain.ErrorCode(err) != domain.ErrorCodeSourceNotAllowed {
+		t.Errorf("Expected every video checked against the allowlist, got %v", err)
+	}
+
+	request.VideoKeys = []string{"uploads/a.mp4"}
+	request.BatchOutput = domain.BatchOutputCombined
+	if err := useCase.validateRequest(request); err == nil {
+		t.Error("Expected error for combined output without an archive merger")
+	}
+
+	request.BatchOutput = ""
+	request.DryRun = true
+	if err := useCase.validateRequest(request); err == nil {
+		t.Error("Expected error for a batch dry run")
+	}
+}
+
+func TestValidateRequest_Concat(t *testing.T) {
+	useCase := NewProcessVideoUseCase(nil, nil, nil, "output-bucket", "output-queue").
+		WithSourceAllowlist(domain.SourceAllowlist{{Bucket: "input-bucket", Prefix: "uploads/"}})
+	request := domain.VideoProcess{
+		ProcessID:   "process-123",
+		VideoBucket: "input-bucket",
+		VideoKeys:   []string{"uploads/a.mp4", "uploads/b.mp4"},
+		OutputType:  domain.OutputTypeConcat,
+	}
+
+	if err := useCase.validateRequest(request); err == nil {
+		t.Error("Expected error for concat output without a concatenator")
+	}
+
+	useCase.WithConcatenator(&mockConcatenator{})
+	if err := useCase.validateRequest(request); err != nil {
+		t.Errorf("Expected a valid concat, got %v", err)
+	}
+
+	request.VideoKeys = []string{"uploads/a.mp4", "private/b.mp4"}
+	if err := useCase.validateRequest(request); domain.ErrorCode(err) != domain.ErrorCodeSourceNotAllowed {
+		t.Errorf("Expected every clip checked against the allowlist, got %v", err)
+	}
+
+	request.VideoKeys = []string{"uploads/a.mp4", "uploads/b.mp4"}
+	request.DryRun = true
+	if err := useCase.validateRequest(request); err == nil {
+		t.Error("Expected error for a concat dry run")
+	}
+}
+
+// cutReader serves data and then fails as a dropped connection
+type cutReader struct {
+	data io.Reader
+}
+
+func (r *cutReader) Read(p []byte) (int, error) {
+	n, err := r.data.Read(p)
+	if err == io.EOF {
+		return n, io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+func TestExecute_ResumesDownload(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	zipPath := filepath.Join(t.TempDir(), "frames.zip")
+	var conditions []domain.ObjectCondition
+	modified := false
+	storagePort := &mockStoragePort{
+		headObjectFunc: func(ctx context.Context, bucket, key string) (domain.ObjectInfo, error) {
+			return domain.ObjectInfo{SizeBytes: 10, ETag: "abc"}, nil
+		},
+		getObjectFunc: func(ctx context.Context, bucket, key string, options domain.GetOptions) (io.ReadCloser, error) {
+			return io.NopCloser(&cutReader{data: strings.NewReader("01234")}), nil
+		},
+		getObjectIfFunc: func(ctx context.Context, bucket, key string, condition domain.ObjectCondition) (io.ReadCloser, error) {
+			conditions = append(conditions, condition)
+			if modified {
+				return nil, domain.ErrObjectModified
+			}
+			return io.NopCloser(strings.NewReader("56789")), nil
+		},
+	}
+	var video string
+	videoProcessor := &mockVideoProcessor{
+		processVideoFunc: func(ctx context.Context, jobID, videoPath string, options domain.FrameOptions) ([]string, int, error) {
+			data, _ := os.ReadFile(videoPath)
+			video = string(data)
+			os.WriteFile(zipPath, []byte("fake zip content"), 0644)
+			return []string{zipPath}, 1, nil
+		},
+	}
+	var results []string
+	messagePort := &mockMessagePort{
+		sendMessageFunc: func(ctx context.Context, queueURL string, messageBody string) (string, error) {
+			results = append(results, messageBody)
+			return "id", nil
+		},
+	}
+	request := domain.VideoProcess{ProcessID: "p1", VideoBucket: "input", VideoKey: "video.mp4"}
+	useCase := NewProcessVideoUseCase(storagePort, messagePort, videoProcessor, "output-bucket", "output-queue").WithDownloadResumes(1)
+
+	if err := useCase.Execute(context.Background(), request); err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if video != "0123456789" {
+		t.Errorf("Expected the video resumed after the cut, got %q", video)
+	}
+	if len(conditions) != 1 || conditions[0].ETag != "abc" {
+		t.Errorf("Expected one resume pinned to the described ETag, got %v", conditions)
+	}
+
+	// A video replaced during the download is not stitched to the old one
+	modified, video, results = true, "", nil
+	useCase.Execute(context.Background(), request)
+	if video != "" || len(results) != 1 || !strings.Contains(results[0], domain.ErrorCodeSourceModified) {
+		t.Errorf("Expected the job failed with %s, got video %q and results %v", domain.ErrorCodeSourceModified, video, results)
 	}
 }
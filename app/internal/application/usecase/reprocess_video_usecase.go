@@ -0,0 +1,111 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/port"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/observability"
+	"go.uber.org/zap"
+)
+
+// ReprocessVideoUseCase runs a completed job again with new options. The source comes from the
+// record ProcessVideoUseCase keeps with WithJobRecords (the archived copy once the original is
+// deleted) and the outputs are written beside the previous ones, as frames_{id}_v2.zip, ...
+type ReprocessVideoUseCase struct {
+	storage      port.StoragePort
+	recordBucket string
+	process      *ProcessVideoUseCase
+}
+
+func NewReprocessVideoUseCase(
+	storage port.StoragePort,
+	recordBucket string,
+	process *ProcessVideoUseCase,
+) *ReprocessVideoUseCase {
+	return &ReprocessVideoUseCase{
+		storage:      storage,
+		recordBucket: recordBucket,
+		process:      process,
+	}
+}
+
+// Execute resolves the source of request.ProcessID and runs the job with the options of
+// request. A process without a record, or recorded for another tenant, fails with
+// job_not_found; a record that cannot be read or updated leaves the message in the queue
+func (uc *ReprocessVideoUseCase) Execute(ctx context.Context, request domain.VideoProcess) error {
+	if err := domain.ValidateReprocess(request); err != nil {
+		return uc.process.Reject(ctx, request, err)
+	}
+
+	record, err := readJobRecord(ctx, uc.storage, uc.recordBucket, request.ProcessID)
+	if errors.Is(err, domain.ErrObjectNotFound) || (err == nil && record.TenantID != request.TenantID) {
+		return uc.process.Reject(ctx, request, domain.NewCodedError(domain.ErrorCodeJobNotFound,
+			fmt.Errorf("no completed job %s to reprocess", request.ProcessID)))
+	}
+	if err != nil {
+		return fmt.Errorf("%w: %v", domain.ErrMessageNotHandled, err)
+	}
+
+	// The version is taken before the run, so a reprocess never overwrites the outputs of
+	// another; a failed run leaves its number unused. Two reprocesses of the same process at
+	// the same time may still get the same one
+	record.Version = max(record.Version, 1) + 1
+	record.UpdatedAt = time.Now().UTC()
+	if err := uc.writeRecord(ctx, record); err != nil {
+		return fmt.Errorf("%w: %v", domain.ErrMessageNotHandled, err)
+	}
+
+	source := record.Source()
+	request.VideoBucket = source.Bucket
+	request.VideoKey = source.Key
+	request.Source = domain.ObjectCondition{VersionID: source.VersionID}
+	request.Version = record.Version
+	// The source stays for the next reprocess
+	request.KeepOriginal = true
+
+	observability.FromContext(ctx).Info("reprocessing job",
+		zap.String("process_id", request.ProcessID),
+		zap.Int("version", request.Version),
+		zap.Bool("archived", record.Archive != nil),
+	)
+	return uc.process.Execute(ctx, request)
+}
+
+// readJobRecord returns the record of processID, or domain.ErrObjectNotFound when there is none
+func readJobRecord(ctx context.Context, storage port.StoragePort, bucket, processID string) (domain.JobRecord, error) {
+	body, err := storage.GetObject(ctx, bucket, domain.JobRecordKey(processID))
+	if errors.Is(err, domain.ErrObjectNotFound) {
+		return domain.JobRecord{}, err
+	}
+	if err != nil {
+		observability.RecordS3Operation(ctx, "get", false)
+		return domain.JobRecord{}, fmt.Errorf("failed to get job record: %w", err)
+	}
+	defer body.Close()
+	observability.RecordS3Operation(ctx, "get", true)
+
+	var record domain.JobRecord
+	if err := json.NewDecoder(body).Decode(&record); err != nil {
+		return domain.JobRecord{}, fmt.Errorf("invalid job record of %s: %w", processID, err)
+	}
+	return record, nil
+}
+
+func (uc *ReprocessVideoUseCase) writeRecord(ctx context.Context, record domain.JobRecord) error {
+	body, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to encode job record: %w", err)
+	}
+	if _, err := uc.storage.PutObject(ctx, uc.recordBucket, domain.JobRecordKey(record.ProcessID), bytes.NewReader(body)); err != nil {
+		observability.RecordS3Operation(ctx, "put", false)
+		return fmt.Errorf("failed to put job record: %w", err)
+	}
+	observability.RecordS3Operation(ctx, "put", true)
+	return nil
+}
@@ -0,0 +1,157 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/observability"
+)
+
+func TestReprocessVideo(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	store, storagePort := newMemoryStorage()
+	store.objects["input-bucket/uploads/video.mp4"] = []byte("mock video data")
+	storagePort.copyObjectFunc = func(ctx context.Context, srcBucket, srcKey, srcVersionID, dstBucket, dstKey string, options domain.PutOptions) error {
+		store.objects[dstBucket+"/"+dstKey] = store.objects[srcBucket+"/"+srcKey]
+		return nil
+	}
+	storagePort.deleteObjectFunc = func(ctx context.Context, bucket, key string) error {
+		delete(store.objects, bucket+"/"+key)
+		return nil
+	}
+
+	var maxFrames []int
+	videoProcessor := &mockVideoProcessor{
+		processVideoFunc: func(ctx context.Context, jobID, videoPath string, options domain.FrameOptions) ([]string, int, error) {
+			maxFrames = append(maxFrames, options.MaxFrames)
+			zipPath := filepath.Join(t.TempDir(), "frames.zip")
+			os.WriteFile(zipPath, []byte("fake zip content"), 0644)
+			return []string{zipPath}, 10, nil
+		},
+	}
+	var results []string
+	messagePort := &mockMessagePort{
+		sendMessageFunc: func(ctx context.Context, queueURL string, messageBody string) (string, error) {
+			results = append(results, messageBody)
+			return "message-id", nil
+		},
+	}
+	process := NewProcessVideoUseCase(storagePort, messagePort, videoProcessor, "output-bucket", "output-queue").
+		WithJobRecords("archive-bucket", "originals")
+
+	err := process.Execute(context.Background(), domain.VideoProcess{
+		ProcessID:   "process-123",
+		VideoBucket: "input-bucket",
+		VideoKey:    "uploads/video.mp4",
+		TenantID:    "acme",
+	})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if _, ok := store.objects["input-bucket/uploads/video.mp4"]; ok {
+		t.Fatal("Expected the original video deleted")
+	}
+	if _, ok := store.objects["archive-bucket/originals/process-123/video.mp4"]; !ok {
+		t.Fatal("Expected the original video archived before its deletion")
+	}
+
+	reprocess := NewReprocessVideoUseCase(storagePort, "output-bucket", process)
+	for _, version := range []string{"v2", "v3"} {
+		err = reprocess.Execute(context.Background(), domain.VideoProcess{ProcessID: "process-123", TenantID: "acme", MaxFrames: 5})
+		if err != nil {
+			t.Fatalf("Reprocess failed: %v", err)
+		}
+		if _, ok := store.objects["output-bucket/processed/frames_process-123_"+version+".zip"]; !ok {
+			t.Errorf("Expected the %s outputs beside the first ones", version)
+		}
+		if !strings.Contains(results[len(results)-1], `"file_key":"processed/frames_process-123_`+version+`.zip"`) {
+			t.Errorf("Expected the %s output in the result, got %s", version, results[len(results)-1])
+		}
+	}
+	if _, ok := store.objects["output-bucket/processed/frames_process-123.zip"]; !ok {
+		t.Error("Expected the first outputs kept")
+	}
+	if _, ok := store.objects["archive-bucket/originals/process-123/video.mp4"]; !ok {
+		t.Error("Expected the archived video kept for the next reprocess")
+	}
+	if len(maxFrames) != 3 || maxFrames[1] != 5 {
+		t.Errorf("Expected the reprocess run with its own options, got max frames %v", maxFrames)
+	}
+
+	var record domain.JobRecord
+	if err := json.Unmarshal(store.objects["output-bucket/jobs/process-123.json"], &record); err != nil {
+		t.Fatalf("Invalid job record: %v", err)
+	}
+	if record.Version != 3 || record.Video.Key != "uploads/video.mp4" || record.Archive == nil || record.Archive.Bucket != "archive-bucket" {
+		t.Errorf("Unexpected job record %+v", record)
+	}
+
+	// A redelivery of the first job keeps the version reached by the reprocesses
+	store.objects["input-bucket/uploads/video.mp4"] = []byte("mock video data")
+	err = process.Execute(context.Background(), domain.VideoProcess{
+		ProcessID:   "process-123",
+		VideoBucket: "input-bucket",
+		VideoKey:    "uploads/video.mp4",
+		TenantID:    "acme",
+	})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	record = domain.JobRecord{}
+	if err := json.Unmarshal(store.objects["output-bucket/jobs/process-123.json"], &record); err != nil {
+		t.Fatalf("Invalid job record: %v", err)
+	}
+	if record.Version != 3 {
+		t.Errorf("Expected version 3 kept by the redelivered job, got %d", record.Version)
+	}
+}
+
+func TestReprocessVideo_Rejected(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	store, storagePort := newMemoryStorage()
+	store.objects["output-bucket/jobs/process-123.json"] = []byte(`{"process_id":"process-123","tenant_id":"acme","video":{"bucket":"input-bucket","key":"video.mp4"},"version":1}`)
+
+	var results []string
+	messagePort := &mockMessagePort{
+		sendMessageFunc: func(ctx context.Context, queueURL string, messageBody string) (string, error) {
+			results = append(results, messageBody)
+			return "message-id", nil
+		},
+	}
+	process := NewProcessVideoUseCase(storagePort, messagePort, &mockVideoProcessor{}, "output-bucket", "output-queue")
+	reprocess := NewReprocessVideoUseCase(storagePort, "output-bucket", process)
+
+	tests := map[string]struct {
+		request domain.VideoProcess
+		code    string
+	}{
+		"unknown process": {domain.VideoProcess{ProcessID: "process-456", TenantID: "acme"}, domain.ErrorCodeJobNotFound},
+		"other tenant":    {domain.VideoProcess{ProcessID: "process-123", TenantID: "globex"}, domain.ErrorCodeJobNotFound},
+		"source set":      {domain.VideoProcess{ProcessID: "process-123", TenantID: "acme", VideoKey: "other.mp4"}, ""},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			results = nil
+			if err := reprocess.Execute(context.Background(), test.request); err == nil || domain.ErrorCode(err) != test.code {
+				t.Errorf("Expected a %q error, got %v", test.code, err)
+			}
+			if len(results) != 1 || !strings.Contains(results[0], `"error_message"`) {
+				t.Errorf("Expected an error result, got %v", results)
+			}
+		})
+	}
+	if !strings.Contains(string(store.objects["output-bucket/jobs/process-123.json"]), `"version":1`) {
+		t.Error("Expected the record untouched by rejected reprocesses")
+	}
+}
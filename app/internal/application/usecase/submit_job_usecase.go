@@ -0,0 +1,189 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/port"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/observability"
+	"go.uber.org/zap"
+)
+
+// SubmitJobUseCase turns an HTTP upload into a regular job: the video is staged in the input
+// bucket and the same message an upstream producer would send is enqueued on the input queue
+type SubmitJobUseCase struct {
+	storage       port.StoragePort
+	message       port.MessagePort
+	inputBucket   string
+	inputQueueURL string
+
+	// producers authorize the jobs; nil accepts anonymous jobs. signers holds the signer of
+	// each producer with a signing secret
+	producers domain.ProducerRegistry
+	signers   map[string]port.SignerPort
+}
+
+func NewSubmitJobUseCase(
+	storage port.StoragePort,
+	message port.MessagePort,
+	inputBucket string,
+	inputQueueURL string,
+) *SubmitJobUseCase {
+	return &SubmitJobUseCase{
+		storage:       storage,
+		message:       message,
+		inputBucket:   inputBucket,
+		inputQueueURL: inputQueueURL,
+	}
+}
+
+// WithProducers requires the api_key of one of producers for every job, for workers that
+// authenticate their input queue. The job is enqueued as that producer, signed with its own
+// signer when it has one, never with a secret shared by other producers
+func (uc *SubmitJobUseCase) WithProducers(producers domain.ProducerRegistry, signers map[string]port.SignerPort) *SubmitJobUseCase {
+	uc.producers = producers
+	uc.signers = signers
+	return uc
+}
+
+// Authorize resolves the producer and tenant a job is submitted as, before its video is read.
+// A producer bound to a single tenant submits for it when the credentials name none
+func (uc *SubmitJobUseCase) Authorize(credentials domain.JobCredentials) (domain.JobProducer, error) {
+	if uc.producers == nil {
+		if credentials.TenantID != "" {
+			return domain.JobProducer{}, fmt.Errorf("%w: a tenant requires a producer api key", domain.ErrUnauthorizedJob)
+		}
+		return domain.JobProducer{}, nil
+	}
+
+	name, producer, ok := uc.producers.ByAPIKey(credentials.APIKey)
+	if !ok {
+		return domain.JobProducer{}, fmt.Errorf("%w: a producer api key is required", domain.ErrUnauthorizedJob)
+	}
+
+	tenantID := credentials.TenantID
+	if tenantID == "" && len(producer.Tenants) == 1 {
+		tenantID = producer.Tenants[0]
+	}
+	if tenantID == "" && len(producer.Tenants) > 1 {
+		return domain.JobProducer{}, fmt.Errorf("%w: producer %s sends jobs for several tenants and must name one", domain.ErrInvalidJob, name)
+	}
+	if tenantID != "" && !producer.AllowsTenant(tenantID) {
+		return domain.JobProducer{}, fmt.Errorf("%w: producer %s may not send jobs for tenant %s", domain.ErrForbiddenJob, name, tenantID)
+	}
+	return domain.JobProducer{Name: name, APIKey: producer.APIKey, TenantID: tenantID}, nil
+}
+
+// Submit stages the video and enqueues its processing message; options carries the optional
+// message fields (output_type, max_frames, ...) and is validated by the worker like any message
+func (uc *SubmitJobUseCase) Submit(ctx context.Context, producer domain.JobProducer, video io.Reader, fileName string, options map[string]interface{}) (domain.JobSubmission, error) {
+	if uc.producers != nil && producer.Name == "" {
+		return domain.JobSubmission{}, fmt.Errorf("%w: a producer api key is required", domain.ErrUnauthorizedJob)
+	}
+	if err := domain.ValidateJobOptions(options); err != nil {
+		return domain.JobSubmission{}, err
+	}
+
+	processID, err := newProcessID()
+	if err != nil {
+		return domain.JobSubmission{}, err
+	}
+
+	job := domain.JobSubmission{
+		ProcessID:   processID,
+		VideoBucket: uc.inputBucket,
+		VideoKey:    domain.UploadKey(processID, fileName),
+	}
+	logger := observability.FromContext(ctx).With(
+		zap.String("process_id", job.ProcessID),
+		zap.String("video_key", job.VideoKey),
+		zap.String("producer", producer.Name),
+		zap.String("tenant_id", producer.TenantID),
+	)
+
+	if _, err := uc.storage.PutObject(ctx, job.VideoBucket, job.VideoKey, video); err != nil {
+		observability.RecordS3Operation(ctx, "put", false)
+		return domain.JobSubmission{}, fmt.Errorf("failed to stage video: %w", err)
+	}
+	observability.RecordS3Operation(ctx, "put", true)
+
+	fields := map[string]interface{}{
+		"process_id":   job.ProcessID,
+		"video_bucket": job.VideoBucket,
+		"video_key":    job.VideoKey,
+	}
+	if producer.TenantID != "" {
+		fields["tenant_id"] = producer.TenantID
+	}
+	body, err := jobMessage(options, fields)
+	if err != nil {
+		return domain.JobSubmission{}, err
+	}
+
+	if err := uc.enqueue(ctx, producer, body); err != nil {
+		observability.RecordSQSOperation("send", false)
+		// Without the message nobody would process or delete the staged video
+		if deleteErr := uc.storage.DeleteObject(ctx, job.VideoBucket, job.VideoKey); deleteErr != nil {
+			logger.Warn("failed to remove staged video", zap.Error(deleteErr))
+		}
+		return domain.JobSubmission{}, fmt.Errorf("failed to enqueue job: %w", err)
+	}
+	observability.RecordSQSOperation("send", true)
+
+	logger.Info("job submitted")
+	return job, nil
+}
+
+// enqueue sends the message as producer, with the attributes its authentication checks
+func (uc *SubmitJobUseCase) enqueue(ctx context.Context, producer domain.JobProducer, body string) error {
+	if producer.Name == "" {
+		_, err := uc.message.SendMessage(ctx, uc.inputQueueURL, body)
+		return err
+	}
+
+	attributes := map[string]string{
+		domain.ProducerAttribute: producer.Name,
+		domain.APIKeyAttribute:   producer.APIKey,
+	}
+	if signer, ok := uc.signers[producer.Name]; ok {
+		signature, err := signer.Sign(ctx, []byte(body))
+		if err != nil {
+			return fmt.Errorf("failed to sign job message: %w", err)
+		}
+		attributes["signature"] = signature
+	}
+	_, err := uc.message.SendMessageWithAttributes(ctx, uc.inputQueueURL, body, attributes)
+	return err
+}
+
+// jobMessage builds an input queue message from the client options and the fields set by
+// the submitter, which win over the options
+func jobMessage(options, fields map[string]interface{}) (string, error) {
+	message := make(map[string]interface{}, len(options)+len(fields))
+	for key, value := range options {
+		message[key] = value
+	}
+	for key, value := range fields {
+		message[key] = value
+	}
+
+	body, err := json.Marshal(message)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal job message: %w", err)
+	}
+	return string(body), nil
+}
+
+// newProcessID generates a random id for jobs submitted over HTTP
+func newProcessID() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", fmt.Errorf("failed to generate process id: %w", err)
+	}
+	return hex.EncodeToString(id), nil
+}
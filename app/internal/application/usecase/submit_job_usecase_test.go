@@ -0,0 +1,218 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/port"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/observability"
+)
+
+func TestSubmitJob_StagesAndEnqueues(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	var stagedBucket, stagedKey, stagedBody string
+	storagePort := &mockStoragePort{
+		putObjectFunc: func(ctx context.Context, bucket, key string, body io.Reader, options domain.PutOptions) (string, error) {
+			data, _ := io.ReadAll(body)
+			stagedBucket, stagedKey, stagedBody = bucket, key, string(data)
+			return key, nil
+		},
+	}
+
+	var queueURL, sentBody string
+	messagePort := &mockMessagePort{
+		sendMessageFunc: func(ctx context.Context, url string, messageBody string) (string, error) {
+			queueURL, sentBody = url, messageBody
+			return "msg-id", nil
+		},
+	}
+
+	useCase := NewSubmitJobUseCase(storagePort, messagePort, "input-bucket", "input-queue")
+	job, err := useCase.Submit(context.Background(), domain.JobProducer{}, strings.NewReader("video bytes"), "clip.mp4", map[string]interface{}{"output_type": "sprite"})
+	if err != nil {
+		t.Fatalf("Submit failed: %v", err)
+	}
+
+	if job.ProcessID == "" || job.VideoBucket != "input-bucket" || job.VideoKey != "uploads/"+job.ProcessID+"/clip.mp4" {
+		t.Errorf("Unexpected job %+v", job)
+	}
+	if stagedBucket != job.VideoBucket || stagedKey != job.VideoKey || stagedBody != "video bytes" {
+		t.Errorf("Expected the video staged at the job location, got s3://%s/%s", stagedBucket, stagedKey)
+	}
+
+	var message map[string]interface{}
+	if err := json.Unmarshal([]byte(sentBody), &message); err != nil {
+		t.Fatalf("Invalid job message: %v", err)
+	}
+	if queueURL != "input-queue" {
+		t.Errorf("Expected message on input-queue, got %s", queueURL)
+	}
+	if message["process_id"] != job.ProcessID || message["video_key"] != job.VideoKey || message["output_type"] != "sprite" {
+		t.Errorf("Unexpected job message %v", message)
+	}
+}
+
+func TestSubmitJob_EnqueuesAsProducer(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	var sentBody, signedBody string
+	var sentAttributes map[string]string
+	messagePort := &mockMessagePort{
+		sendMessageWithAttributesFunc: func(ctx context.Context, url string, messageBody string, attributes map[string]string) (string, error) {
+			sentBody, sentAttributes = messageBody, attributes
+			return "msg-id", nil
+		},
+	}
+	signer := &mockSignerPort{
+		signFunc: func(ctx context.Context, payload []byte) (string, error) {
+			signedBody = string(payload)
+			return "sig", nil
+		},
+	}
+	producers := domain.ProducerRegistry{
+		"team-a": {SigningSecret: "secret-a", APIKey: "key-a"},
+		"team-b": {APIKey: "key-b"},
+	}
+
+	useCase := NewSubmitJobUseCase(&mockStoragePort{}, messagePort, "input-bucket", "input-queue").
+		WithProducers(producers, map[string]port.SignerPort{"team-a": signer})
+	producer, err := useCase.Authorize(domain.JobCredentials{APIKey: "key-a"})
+	if err != nil {
+		t.Fatalf("Authorize failed: %v", err)
+	}
+	if _, err := useCase.Submit(context.Background(), producer, strings.NewReader("video bytes"), "clip.mp4", nil); err != nil {
+		t.Fatalf("Submit failed: %v", err)
+	}
+
+	if sentAttributes[domain.ProducerAttribute] != "team-a" || sentAttributes[domain.APIKeyAttribute] != "key-a" {
+		t.Errorf("Expected the job enqueued as team-a, got %v", sentAttributes)
+	}
+	if sentAttributes["signature"] != "sig" || signedBody != sentBody {
+		t.Errorf("Expected the message body signed by the producer, got %v over %q", sentAttributes, signedBody)
+	}
+
+	// A producer without a signing secret is only identified by its key
+	producer, _ = useCase.Authorize(domain.JobCredentials{APIKey: "key-b"})
+	if _, err := useCase.Submit(context.Background(), producer, strings.NewReader("video bytes"), "clip.mp4", nil); err != nil {
+		t.Fatalf("Submit failed: %v", err)
+	}
+	if _, signed := sentAttributes["signature"]; signed || sentAttributes[domain.ProducerAttribute] != "team-b" {
+		t.Errorf("Expected team-b identified by its api key only, got %v", sentAttributes)
+	}
+}
+
+func TestSubmitJob_RequiresProducer(t *testing.T) {
+	useCase := NewSubmitJobUseCase(&mockStoragePort{
+		putObjectFunc: func(ctx context.Context, bucket, key string, body io.Reader, options domain.PutOptions) (string, error) {
+			t.Error("Expected nothing staged for an unauthorized job")
+			return key, nil
+		},
+	}, &mockMessagePort{}, "input-bucket", "input-queue").WithProducers(domain.ProducerRegistry{"team-a": {APIKey: "key-a"}}, nil)
+
+	for _, key := range []string{"", "key-b"} {
+		if _, err := useCase.Authorize(domain.JobCredentials{APIKey: key}); !errors.Is(err, domain.ErrUnauthorizedJob) {
+			t.Errorf("Expected ErrUnauthorizedJob for key %q, got %v", key, err)
+		}
+	}
+	if _, err := useCase.Submit(context.Background(), domain.JobProducer{}, strings.NewReader("video"), "clip.mp4", nil); !errors.Is(err, domain.ErrUnauthorizedJob) {
+		t.Errorf("Expected an anonymous job refused, got %v", err)
+	}
+
+	// Without producers no job may claim a tenant
+	anonymous := NewSubmitJobUseCase(&mockStoragePort{}, &mockMessagePort{}, "input-bucket", "input-queue")
+	if _, err := anonymous.Authorize(domain.JobCredentials{TenantID: "acme"}); !errors.Is(err, domain.ErrUnauthorizedJob) {
+		t.Errorf("Expected ErrUnauthorizedJob for a tenant without producers, got %v", err)
+	}
+}
+
+func TestSubmitJob_ProducerTenants(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	var sentBody string
+	messagePort := &mockMessagePort{
+		sendMessageWithAttributesFunc: func(ctx context.Context, url string, messageBody string, attributes map[string]string) (string, error) {
+			sentBody = messageBody
+			return "msg-id", nil
+		},
+	}
+	useCase := NewSubmitJobUseCase(&mockStoragePort{}, messagePort, "input-bucket", "input-queue").
+		WithProducers(domain.ProducerRegistry{
+			"team-a": {APIKey: "key-a", Tenants: []string{"acme"}},
+			"team-b": {APIKey: "key-b", Tenants: []string{"acme", "globex"}},
+		}, nil)
+
+	// A producer with a single tenant submits for it by default
+	producer, err := useCase.Authorize(domain.JobCredentials{APIKey: "key-a"})
+	if err != nil || producer.TenantID != "acme" {
+		t.Fatalf("Expected team-a submitting for acme, got %+v (%v)", producer, err)
+	}
+	if _, err := useCase.Submit(context.Background(), producer, strings.NewReader("video bytes"), "clip.mp4", nil); err != nil {
+		t.Fatalf("Submit failed: %v", err)
+	}
+	if !strings.Contains(sentBody, `"tenant_id":"acme"`) {
+		t.Errorf("Expected the tenant in the message, got %s", sentBody)
+	}
+
+	if producer, err := useCase.Authorize(domain.JobCredentials{APIKey: "key-b", TenantID: "globex"}); err != nil || producer.TenantID != "globex" {
+		t.Errorf("Expected team-b submitting for globex, got %+v (%v)", producer, err)
+	}
+	if _, err := useCase.Authorize(domain.JobCredentials{APIKey: "key-b"}); !errors.Is(err, domain.ErrInvalidJob) {
+		t.Errorf("Expected ErrInvalidJob without a tenant for several, got %v", err)
+	}
+	if _, err := useCase.Authorize(domain.JobCredentials{APIKey: "key-a", TenantID: "globex"}); !errors.Is(err, domain.ErrForbiddenJob) {
+		t.Errorf("Expected ErrForbiddenJob for another tenant, got %v", err)
+	}
+}
+
+func TestSubmitJob_ReservedOptions(t *testing.T) {
+	useCase := NewSubmitJobUseCase(&mockStoragePort{
+		putObjectFunc: func(ctx context.Context, bucket, key string, body io.Reader, options domain.PutOptions) (string, error) {
+			t.Error("Expected nothing staged for invalid options")
+			return key, nil
+		},
+	}, &mockMessagePort{}, "input-bucket", "input-queue")
+
+	_, err := useCase.Submit(context.Background(), domain.JobProducer{}, strings.NewReader("video"), "clip.mp4", map[string]interface{}{"video_bucket": "other"})
+	if !errors.Is(err, domain.ErrInvalidJob) {
+		t.Errorf("Expected ErrInvalidJob, got %v", err)
+	}
+}
+
+func TestSubmitJob_EnqueueFailureRemovesVideo(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	var deletedKey string
+	storagePort := &mockStoragePort{
+		deleteObjectFunc: func(ctx context.Context, bucket, key string) error {
+			deletedKey = key
+			return nil
+		},
+	}
+	messagePort := &mockMessagePort{
+		sendMessageFunc: func(ctx context.Context, url string, messageBody string) (string, error) {
+			return "", errors.New("queue unavailable")
+		},
+	}
+
+	useCase := NewSubmitJobUseCase(storagePort, messagePort, "input-bucket", "input-queue")
+	if _, err := useCase.Submit(context.Background(), domain.JobProducer{}, strings.NewReader("video"), "clip.mp4", nil); err == nil {
+		t.Fatal("Expected error when the message cannot be sent")
+	}
+	if !strings.HasPrefix(deletedKey, "uploads/") {
+		t.Errorf("Expected the staged video removed, got %q", deletedKey)
+	}
+}
@@ -0,0 +1,62 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrAlreadyStarted is returned when Start is called on a running consumer
+var ErrAlreadyStarted = errors.New("consumer already started")
+
+// Consumer pulls messages from a transport and hands them to a Handler until stopped
+type Consumer interface {
+	// Start begins consuming in the background; ctx is the context given to the handler
+	Start(ctx context.Context) error
+
+	// Stop stops receiving, waits for the messages in flight and returns; the consumer may
+	// then be started again
+	Stop()
+}
+
+// Message is a received message, independent of the transport it came from
+type Message struct {
+	ID         string
+	Body       string
+	Attributes map[string]string
+	// Attempt counts the deliveries of the message, starting at 1; 0 when the transport does not tell
+	Attempt int
+	// SentAt is when the message was sent to the transport; zero when the transport does not tell
+	SentAt time.Time
+}
+
+type attemptKey struct{}
+
+type sentAtKey struct{}
+
+// Attempt returns the delivery count of the message being handled, starting at 1; 0 when the
+// transport does not tell or outside a handler
+func Attempt(ctx context.Context) int {
+	attempt, _ := ctx.Value(attemptKey{}).(int)
+	return attempt
+}
+
+// SentAt returns when the message being handled was sent; zero when the transport does not
+// tell or outside a handler
+func SentAt(ctx context.Context) time.Time {
+	sentAt, _ := ctx.Value(sentAtKey{}).(time.Time)
+	return sentAt
+}
+
+// dispatch hands msg to handler, with its attempt and sent time in the context for code that
+// only gets the body
+func dispatch(ctx context.Context, handler Handler, msg Message) error {
+	ctx = context.WithValue(ctx, attemptKey{}, msg.Attempt)
+	ctx = context.WithValue(ctx, sentAtKey{}, msg.SentAt)
+	return handler(ctx, msg)
+}
+
+// Handler processes one message. The message is acknowledged (removed from the transport)
+// unless the returned error wraps domain.ErrMessageNotHandled, in which case it is redelivered,
+// right away when it wraps domain.ErrMessageReturned
+type Handler func(ctx context.Context, msg Message) error
@@ -0,0 +1,179 @@
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/dto"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/usecase"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/port"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/observability"
+	"go.uber.org/zap"
+)
+
+// Route resolves the body and routes the message. It is acknowledged once handled whatever
+// the outcome, since jobs publish their own error results
+func Route(router *usecase.MessageRouterUseCase, storage port.StoragePort) Handler {
+	return func(ctx context.Context, msg Message) error {
+		body, err := ResolvePayload(ctx, storage, msg.Body)
+		if err != nil {
+			// Keep the message so it is retried (or moved to the DLQ) instead of dropping the job
+			return fmt.Errorf("%w: %v", domain.ErrMessageNotHandled, err)
+		}
+
+		return router.Route(ctx, body, msg.Attributes)
+	}
+}
+
+// ProcessMessage runs a video.process job
+func ProcessMessage(useCase *usecase.ProcessVideoUseCase) usecase.MessageHandler {
+	return func(ctx context.Context, body string) error {
+		logger := observability.FromContext(ctx)
+
+		request, err := dto.ParseProcessRequest([]byte(body))
+		if err != nil {
+			logger.Error("failed to parse message", zap.Error(err))
+			return fmt.Errorf("%w: %w", domain.ErrMessageRejected, err)
+		}
+
+		logger.Info("message parsed successfully",
+			zap.String("process_id", request.ProcessID),
+			zap.String("video_bucket", request.VideoBucket),
+			zap.String("video_key", request.VideoKey),
+			zap.String("tenant_id", request.TenantID),
+			zap.String("output_type", request.OutputType),
+			zap.Any("metadata", request.Metadata),
+		)
+
+		videoProcess, err := jobFromRequest(ctx, request)
+		if err != nil {
+			logger.Error("invalid request", zap.Error(err))
+			return useCase.Reject(ctx, videoProcess, err)
+		}
+
+		return useCase.Execute(ctx, videoProcess)
+	}
+}
+
+// ReprocessMessage runs a video.reprocess, a job message naming a completed process_id in
+// place of the source video
+func ReprocessMessage(useCase *usecase.ProcessVideoUseCase, reprocess *usecase.ReprocessVideoUseCase) usecase.MessageHandler {
+	return func(ctx context.Context, body string) error {
+		request, err := dto.ParseProcessRequest([]byte(body))
+		if err != nil {
+			observability.FromContext(ctx).Error("failed to parse message", zap.Error(err))
+			return fmt.Errorf("%w: %w", domain.ErrMessageRejected, err)
+		}
+
+		videoProcess, err := jobFromRequest(ctx, request)
+		if err != nil {
+			observability.FromContext(ctx).Error("invalid request", zap.Error(err))
+			return useCase.Reject(ctx, videoProcess, err)
+		}
+
+		return reprocess.Execute(ctx, videoProcess)
+	}
+}
+
+// jobFromRequest converts the request with the attempt and sent time of its delivery; the
+// job is returned along with a validation error, so it can be rejected with a result
+func jobFromRequest(ctx context.Context, request dto.ProcessRequest) (domain.VideoProcess, error) {
+	videoProcess, err := request.ToDomain(time.Now())
+	videoProcess.Attempt = Attempt(ctx)
+	if videoProcess.EnqueuedAt.IsZero() {
+		videoProcess.EnqueuedAt = SentAt(ctx)
+	}
+	return videoProcess, err
+}
+
+// CancelMessage records a video.cancel; a malformed one is dropped, a failed one stays in the
+// queue since losing it would let the job run
+func CancelMessage(cancelJob *usecase.CancelJobUseCase) usecase.MessageHandler {
+	return func(ctx context.Context, body string) error {
+		var cancellation domain.JobCancellation
+		if err := json.Unmarshal([]byte(body), &cancellation); err != nil {
+			return fmt.Errorf("%w: invalid job cancellation: %w", domain.ErrMessageRejected, err)
+		}
+		if err := cancellation.Validate(); err != nil {
+			return fmt.Errorf("%w: invalid job cancellation: %w", domain.ErrMessageRejected, err)
+		}
+		if err := cancelJob.Execute(ctx, cancellation); err != nil {
+			return fmt.Errorf("%w: %v", domain.ErrMessageNotHandled, err)
+		}
+		return nil
+	}
+}
+
+// PingMessage acknowledges a ping, which checks end to end that the workers consume the
+// queue; a malformed one is dropped
+func PingMessage(ctx context.Context, body string) error {
+	logger := observability.FromContext(ctx)
+
+	var ping struct {
+		PingID string `json:"ping_id"`
+	}
+	if err := json.Unmarshal([]byte(body), &ping); err != nil {
+		logger.Error("invalid ping message", zap.Error(err))
+		return fmt.Errorf("%w: invalid ping: %w", domain.ErrMessageRejected, err)
+	}
+	logger.Info("ping received", zap.String("ping_id", ping.PingID))
+	return nil
+}
+
+// ConfirmMessage applies a deletion confirmation; a malformed one is dropped, a failed one
+// stays in the queue and is retried after its visibility timeout
+func ConfirmMessage(confirmDeletion *usecase.ConfirmDeletionUseCase) Handler {
+	return func(ctx context.Context, msg Message) error {
+		logger := observability.FromContext(ctx)
+
+		var confirmation domain.DeletionConfirmation
+		err := json.Unmarshal([]byte(msg.Body), &confirmation)
+		if err == nil {
+			err = confirmation.Validate()
+		}
+		if err != nil {
+			// A malformed confirmation never becomes valid; drop it
+			logger.Error("invalid confirmation message", zap.Error(err))
+			return fmt.Errorf("%w: %w", domain.ErrMessageRejected, err)
+		}
+
+		if err := confirmDeletion.Execute(ctx, confirmation); err != nil {
+			logger.Error("failed to handle confirmation", zap.String("process_id", confirmation.ProcessID), zap.Error(err))
+			return fmt.Errorf("%w: %v", domain.ErrMessageNotHandled, err)
+		}
+		return nil
+	}
+}
+
+// ResolvePayload fetches the real message body from S3 when the message is an extended client pointer
+func ResolvePayload(ctx context.Context, storage port.StoragePort, body string) (string, error) {
+	pointer, ok := domain.DecodePayloadPointer(body)
+	if !ok {
+		return body, nil
+	}
+
+	observability.FromContext(ctx).Info("fetching offloaded payload from S3",
+		zap.String("bucket", pointer.Bucket),
+		zap.String("key", pointer.Key),
+	)
+
+	reader, err := storage.GetObject(ctx, pointer.Bucket, pointer.Key)
+	if err != nil {
+		observability.RecordS3Operation(ctx, "get", false)
+		return "", fmt.Errorf("failed to get offloaded payload: %w", err)
+	}
+	defer reader.Close()
+
+	payload, err := io.ReadAll(reader)
+	if err != nil {
+		observability.RecordS3Operation(ctx, "get", false)
+		return "", fmt.Errorf("failed to read offloaded payload: %w", err)
+	}
+
+	observability.RecordS3Operation(ctx, "get", true)
+	return string(payload), nil
+}
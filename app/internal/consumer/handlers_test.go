@@ -0,0 +1,103 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/adapter"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/usecase"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/observability"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/storage"
+)
+
+func TestResolvePayload_InlineBody(t *testing.T) {
+	storagePort := adapter.NewStorageAdapter(&storage.MockS3Service{})
+
+	body, err := ResolvePayload(context.Background(), storagePort, `{"process_id":"123"}`)
+	if err != nil {
+		t.Fatalf("ResolvePayload failed: %v", err)
+	}
+	if body != `{"process_id":"123"}` {
+		t.Errorf("Expected inline body unchanged, got %s", body)
+	}
+}
+
+func TestResolvePayload_S3Pointer(t *testing.T) {
+	storagePort := adapter.NewStorageAdapter(&storage.MockS3Service{
+		GetObjectFunc: func(ctx context.Context, bucket, key string, options storage.GetOptions) (io.ReadCloser, error) {
+			if bucket != "payload-bucket" || key != "payloads/abc.json" {
+				t.Errorf("Unexpected pointer location s3://%s/%s", bucket, key)
+			}
+			return io.NopCloser(strings.NewReader(`{"process_id":"big"}`)), nil
+		},
+	})
+
+	pointer := `["software.amazon.payloadoffloading.PayloadS3Pointer",{"s3BucketName":"payload-bucket","s3Key":"payloads/abc.json"}]`
+	body, err := ResolvePayload(context.Background(), storagePort, pointer)
+	if err != nil {
+		t.Fatalf("ResolvePayload failed: %v", err)
+	}
+	if body != `{"process_id":"big"}` {
+		t.Errorf("Expected offloaded body, got %s", body)
+	}
+}
+
+func TestResolvePayload_S3Error(t *testing.T) {
+	storagePort := adapter.NewStorageAdapter(&storage.MockS3Service{
+		GetObjectFunc: func(ctx context.Context, bucket, key string, options storage.GetOptions) (io.ReadCloser, error) {
+			return nil, errors.New("no such key")
+		},
+	})
+
+	pointer := `["software.amazon.payloadoffloading.PayloadS3Pointer",{"s3BucketName":"payload-bucket","s3Key":"missing"}]`
+	if _, err := ResolvePayload(context.Background(), storagePort, pointer); err == nil {
+		t.Error("Expected error when the offloaded payload cannot be fetched")
+	}
+}
+
+func TestRoute_UnresolvedPayloadStaysInQueue(t *testing.T) {
+	storagePort := adapter.NewStorageAdapter(&storage.MockS3Service{
+		GetObjectFunc: func(ctx context.Context, bucket, key string, options storage.GetOptions) (io.ReadCloser, error) {
+			return nil, errors.New("throttled")
+		},
+	})
+	router := usecase.NewMessageRouterUseCase(nil, "")
+
+	pointer := `["software.amazon.payloadoffloading.PayloadS3Pointer",{"s3BucketName":"payload-bucket","s3Key":"payloads/abc.json"}]`
+	err := Route(router, storagePort)(context.Background(), Message{ID: "msg-1", Body: pointer})
+	if !errors.Is(err, domain.ErrMessageNotHandled) {
+		t.Errorf("Expected the message kept for a retry, got %v", err)
+	}
+}
+
+func TestCancelMessage(t *testing.T) {
+	cancelJob := usecase.NewCancelJobUseCase(adapter.NewStorageAdapter(&storage.MockS3Service{
+		PutObjectFunc: func(ctx context.Context, bucket, key string, body io.Reader, options storage.PutOptions) (string, error) {
+			return "", errors.New("access denied")
+		},
+	}), "output-bucket")
+
+	if err := CancelMessage(cancelJob)(context.Background(), `{"type":"video.cancel"}`); err == nil || errors.Is(err, domain.ErrMessageNotHandled) {
+		t.Errorf("Expected an invalid cancellation to be dropped, got %v", err)
+	}
+	if err := CancelMessage(cancelJob)(context.Background(), `{"type":"video.cancel","process_id":"123"}`); !errors.Is(err, domain.ErrMessageNotHandled) {
+		t.Errorf("Expected a failed cancellation to stay in the queue, got %v", err)
+	}
+}
+
+func TestPingMessage(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	if err := PingMessage(context.Background(), `{"type":"ping","ping_id":"p-1"}`); err != nil {
+		t.Errorf("Expected a ping acknowledged, got %v", err)
+	}
+	if err := PingMessage(context.Background(), `{"type":"ping","ping_id":1}`); err == nil || errors.Is(err, domain.ErrMessageNotHandled) {
+		t.Errorf("Expected a malformed ping to be dropped, got %v", err)
+	}
+}
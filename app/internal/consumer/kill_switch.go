@@ -0,0 +1,123 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/observability"
+	"go.uber.org/zap"
+)
+
+// ErrKillSwitchTripped is returned for the messages received after the kill switch tripped,
+// which are left in the queue unhandled
+var ErrKillSwitchTripped = errors.New("kill switch tripped")
+
+// KillSwitchConfig is when the kill switch trips: once at least MinMessages were handled within
+// Window and FailureRate of them failed
+type KillSwitchConfig struct {
+	FailureRate float64
+	Window      time.Duration
+	MinMessages int
+}
+
+// KillSwitch watches the outcome of the handled messages and trips when too many of them fail,
+// e.g. after a bad deploy, so the worker stops before burning through the whole queue. Once
+// tripped it stays tripped: the messages are no longer handled and Tripped is closed for the
+// worker to stop consuming
+type KillSwitch struct {
+	config KillSwitchConfig
+	now    func() time.Time
+
+	mu       sync.Mutex
+	outcomes []killSwitchOutcome
+	tripped  chan struct{}
+	once     sync.Once
+}
+
+type killSwitchOutcome struct {
+	at     time.Time
+	failed bool
+}
+
+func NewKillSwitch(config KillSwitchConfig) *KillSwitch {
+	return &KillSwitch{
+		config:  config,
+		now:     time.Now,
+		tripped: make(chan struct{}),
+	}
+}
+
+// Tripped is closed when the kill switch trips
+func (k *KillSwitch) Tripped() <-chan struct{} {
+	return k.tripped
+}
+
+// Middleware counts the messages whose handler fails as failures. It belongs after
+// authentication and deduplication, so rejected and duplicate messages do not count
+func (k *KillSwitch) Middleware() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, msg Message) error {
+			select {
+			case <-k.tripped:
+				return fmt.Errorf("%w: %w", domain.ErrMessageNotHandled, ErrKillSwitchTripped)
+			default:
+			}
+
+			err := next(ctx, msg)
+			k.record(ctx, countsAsFailure(err))
+			return err
+		}
+	}
+}
+
+// countsAsFailure reports whether err may come from the worker itself. Messages rejected for
+// their content, coded failures of the video or the request (unsupported, invalid, not
+// allowed...) and messages returned to the queue are the doing of a producer, so a burst of
+// bad payloads does not trip the switch; stage timeouts still count
+func countsAsFailure(err error) bool {
+	if err == nil || errors.Is(err, domain.ErrMessageRejected) || errors.Is(err, domain.ErrMessageReturned) {
+		return false
+	}
+	code := domain.ErrorCode(err)
+	return code == "" || code == domain.ErrorCodeTimeout
+}
+
+// record adds an outcome, forgets the ones older than the window and trips on the rate
+func (k *KillSwitch) record(ctx context.Context, failed bool) {
+	k.mu.Lock()
+	now := k.now()
+	kept := k.outcomes[:0]
+	for _, outcome := range k.outcomes {
+		if now.Sub(outcome.at) <= k.config.Window {
+			kept = append(kept, outcome)
+		}
+	}
+	k.outcomes = append(kept, killSwitchOutcome{at: now, failed: failed})
+
+	failures := 0
+	for _, outcome := range k.outcomes {
+		if outcome.failed {
+			failures++
+		}
+	}
+	total := len(k.outcomes)
+	k.mu.Unlock()
+
+	if total < k.config.MinMessages || float64(failures) < k.config.FailureRate*float64(total) {
+		return
+	}
+	k.once.Do(func() {
+		observability.SetKillSwitchTripped(true)
+		observability.FromContext(ctx).Error("kill switch tripped, pausing the input consumption",
+			zap.Int("failed", failures),
+			zap.Int("handled", total),
+			zap.Duration("window", k.config.Window),
+			zap.Float64("threshold", k.config.FailureRate),
+		)
+		close(k.tripped)
+	})
+}
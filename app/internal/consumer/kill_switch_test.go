@@ -0,0 +1,131 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/observability"
+)
+
+func TestKillSwitch(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	killSwitch := NewKillSwitch(KillSwitchConfig{FailureRate: 0.8, Window: 5 * time.Minute, MinMessages: 5})
+	killSwitch.now = func() time.Time { return now }
+
+	handled := 0
+	handler := Chain(func(ctx context.Context, msg Message) error {
+		handled++
+		if msg.ID == "fail" {
+			return errors.New("job failed")
+		}
+		return nil
+	}, killSwitch.Middleware())
+
+	send := func(id string, count int) {
+		for i := 0; i < count; i++ {
+			handler(context.Background(), Message{ID: id})
+			now = now.Add(time.Second)
+		}
+	}
+	tripped := func() bool {
+		select {
+		case <-killSwitch.Tripped():
+			return true
+		default:
+			return false
+		}
+	}
+
+	// A rate under the threshold
+	send("ok", 2)
+	send("fail", 4)
+	if tripped() {
+		t.Fatal("Expected no trip with 4 failures in 6 messages")
+	}
+
+	// The old outcomes leave the window, and only failures remain
+	now = now.Add(10 * time.Minute)
+	send("fail", 4)
+	if tripped() {
+		t.Fatal("Expected no trip under the minimum of messages once the old outcomes are forgotten")
+	}
+	send("fail", 1)
+	if !tripped() {
+		t.Fatal("Expected a trip with 5 failures in 5 messages")
+	}
+
+	before := handled
+	err := handler(context.Background(), Message{ID: "ok"})
+	if !errors.Is(err, ErrKillSwitchTripped) || !errors.Is(err, domain.ErrMessageNotHandled) || handled != before {
+		t.Errorf("Expected the message left in the queue unhandled, got %v", err)
+	}
+}
+
+func TestKillSwitch_CountsRetries(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	killSwitch := NewKillSwitch(KillSwitchConfig{FailureRate: 0.5, Window: time.Minute, MinMessages: 2})
+	handler := Chain(func(ctx context.Context, msg Message) error {
+		return fmt.Errorf("%w: storage unavailable", domain.ErrMessageNotHandled)
+	}, killSwitch.Middleware())
+
+	handler(context.Background(), Message{ID: "1"})
+	handler(context.Background(), Message{ID: "2"})
+	select {
+	case <-killSwitch.Tripped():
+	default:
+		t.Error("Expected messages left for a retry counted as failures")
+	}
+}
+
+func TestKillSwitch_IgnoresRejections(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	killSwitch := NewKillSwitch(KillSwitchConfig{FailureRate: 0.5, Window: time.Minute, MinMessages: 2})
+	rejections := []error{
+		domain.NewCodedError(domain.ErrorCodeUnsupportedFormat, errors.New("unsupported video format")),
+		fmt.Errorf("%w: %w", domain.ErrMessageNotHandled, domain.NewCodedError(domain.ErrorCodeUnsupportedCodec, errors.New("unsupported codec"))),
+		fmt.Errorf("%w: process_id is required", domain.ErrMessageRejected),
+		domain.ErrMessageReturned,
+	}
+	var i int
+	handler := Chain(func(ctx context.Context, msg Message) error {
+		err := rejections[i%len(rejections)]
+		i++
+		return err
+	}, killSwitch.Middleware())
+
+	for n := 0; n < 20; n++ {
+		handler(context.Background(), Message{ID: fmt.Sprint(n)})
+	}
+	select {
+	case <-killSwitch.Tripped():
+		t.Fatal("Expected a run of rejected payloads not to trip the switch")
+	default:
+	}
+
+	// A stage timeout may come from the worker and still counts
+	timeout := Chain(func(ctx context.Context, msg Message) error {
+		return domain.NewCodedError(domain.ErrorCodeTimeout, errors.New("processing timed out"))
+	}, killSwitch.Middleware())
+	for n := 0; n < 30; n++ {
+		timeout(context.Background(), Message{ID: fmt.Sprint(n)})
+	}
+	select {
+	case <-killSwitch.Tripped():
+	default:
+		t.Error("Expected timeouts counted as failures")
+	}
+}
@@ -0,0 +1,41 @@
+package consumer
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/observability"
+)
+
+// Maintenance is the flag that hands the queue over to another fleet of workers: while it is
+// set, the worker stops consuming and returns the messages it already received without
+// handling them. It is safe for concurrent use
+type Maintenance struct {
+	active atomic.Bool
+}
+
+// Set turns the maintenance on or off, reporting whether that changed it
+func (m *Maintenance) Set(active bool) bool {
+	return m.active.Swap(active) != active
+}
+
+// Active reports whether the maintenance is on
+func (m *Maintenance) Active() bool {
+	return m.active.Load()
+}
+
+// Middleware returns the messages received during the maintenance to the queue right away,
+// e.g. those prefetched before the consumers stopped. It goes first in the chain, so the
+// returned messages are neither authenticated nor counted as handled
+func (m *Maintenance) Middleware() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, msg Message) error {
+			if m.Active() {
+				observability.FromContext(ctx).Debug("maintenance, returning message to the queue")
+				return domain.ErrMessageReturned
+			}
+			return next(ctx, msg)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+)
+
+func TestMaintenance(t *testing.T) {
+	var maintenance Maintenance
+	handled := 0
+	handler := Chain(func(ctx context.Context, msg Message) error {
+		handled++
+		return nil
+	}, maintenance.Middleware())
+
+	if err := handler(context.Background(), Message{ID: "1"}); err != nil || handled != 1 {
+		t.Errorf("Expected the message handled outside the maintenance, got %v", err)
+	}
+
+	if !maintenance.Set(true) || maintenance.Set(true) {
+		t.Error("Expected only the first Set to change the maintenance")
+	}
+	err := handler(context.Background(), Message{ID: "2"})
+	if !errors.Is(err, domain.ErrMessageReturned) || !errors.Is(err, domain.ErrMessageNotHandled) || handled != 1 {
+		t.Errorf("Expected the message returned unhandled, got %v", err)
+	}
+
+	if !maintenance.Set(false) || maintenance.Active() {
+		t.Error("Expected the maintenance turned off")
+	}
+	if err := handler(context.Background(), Message{ID: "3"}); err != nil || handled != 2 {
+		t.Errorf("Expected the message handled after the maintenance, got %v", err)
+	}
+}
@@ -0,0 +1,111 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/message"
+)
+
+// MemoryAPI is the part of the in-memory queue the consumer uses
+type MemoryAPI interface {
+	Receive(ctx context.Context, queue string) (message.MemoryMessage, error)
+	Requeue(queue string, msg message.MemoryMessage)
+}
+
+// MemoryConfig describes the in-memory queue and how unhandled messages come back
+type MemoryConfig struct {
+	Queue string
+	// RetryDelay is how long an unhandled message waits before it is delivered again
+	RetryDelay time.Duration
+}
+
+// MemoryConsumer handles the messages of an in-memory queue one at a time, for the demo mode
+// that runs the worker without a cloud queue
+type MemoryConsumer struct {
+	queue   MemoryAPI
+	config  MemoryConfig
+	handler Handler
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewMemoryConsumer creates a consumer for the queue in config; unhandled messages are
+// delivered again after 1s unless RetryDelay is set, and returned ones right away
+func NewMemoryConsumer(queue MemoryAPI, config MemoryConfig, handler Handler) Consumer {
+	if config.RetryDelay <= 0 {
+		config.RetryDelay = time.Second
+	}
+	return &MemoryConsumer{
+		queue:   queue,
+		config:  config,
+		handler: handler,
+	}
+}
+
+// Start begins receiving in the background
+func (c *MemoryConsumer) Start(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.done != nil {
+		return ErrAlreadyStarted
+	}
+
+	// Stop only interrupts the receive; a message already received is handled with ctx
+	receiveCtx, cancel := context.WithCancel(ctx)
+	c.cancel = cancel
+	c.done = make(chan struct{})
+
+	go func() {
+		defer close(c.done)
+		c.run(ctx, receiveCtx)
+	}()
+	return nil
+}
+
+// Stop stops receiving and waits for the message in flight to be handled
+func (c *MemoryConsumer) Stop() {
+	c.mu.Lock()
+	cancel, done := c.cancel, c.done
+	c.mu.Unlock()
+	if done == nil {
+		return
+	}
+	cancel()
+	<-done
+
+	c.mu.Lock()
+	c.cancel, c.done = nil, nil
+	c.mu.Unlock()
+}
+
+func (c *MemoryConsumer) run(ctx, receiveCtx context.Context) {
+	for {
+		msg, err := c.queue.Receive(receiveCtx, c.config.Queue)
+		if err != nil {
+			return
+		}
+
+		err = dispatch(ctx, c.handler, Message{
+			ID:         msg.ID,
+			Body:       msg.Body,
+			Attributes: msg.Attributes,
+			Attempt:    msg.Attempt,
+			SentAt:     msg.SentAt,
+		})
+		if errors.Is(err, domain.ErrMessageReturned) {
+			c.queue.Requeue(c.config.Queue, msg)
+			continue
+		}
+		if errors.Is(err, domain.ErrMessageNotHandled) {
+			time.AfterFunc(c.config.RetryDelay, func() {
+				c.queue.Requeue(c.config.Queue, msg)
+			})
+		}
+	}
+}
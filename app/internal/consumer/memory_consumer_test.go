@@ -0,0 +1,83 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/message"
+)
+
+func TestMemoryConsumer_HandlesAndRedelivers(t *testing.T) {
+	queue := message.NewMemoryQueue()
+	queue.SendMessageWithAttributes(context.Background(), "input", `{"process_id":"1"}`, map[string]string{"type": "video.process"})
+
+	handled := make(chan Message, 4)
+	consumer := NewMemoryConsumer(queue, MemoryConfig{Queue: "input", RetryDelay: time.Millisecond}, func(ctx context.Context, msg Message) error {
+		handled <- msg
+		if Attempt(ctx) == 1 {
+			return fmt.Errorf("%w: storage unavailable", domain.ErrMessageNotHandled)
+		}
+		return nil
+	})
+
+	if err := consumer.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	var attempts []int
+	for len(attempts) < 2 {
+		select {
+		case msg := <-handled:
+			if msg.Body != `{"process_id":"1"}` || msg.Attributes["type"] != "video.process" {
+				t.Errorf("Unexpected message %+v", msg)
+			}
+			attempts = append(attempts, msg.Attempt)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Expected the unhandled message delivered again, got attempts %v", attempts)
+		}
+	}
+	if err := consumer.Start(context.Background()); !errors.Is(err, ErrAlreadyStarted) {
+		t.Errorf("Expected ErrAlreadyStarted, got %v", err)
+	}
+	consumer.Stop()
+
+	if attempts[0] != 1 || attempts[1] != 2 {
+		t.Errorf("Expected attempts 1 and 2, got %v", attempts)
+	}
+	if err := consumer.Start(context.Background()); err != nil {
+		t.Errorf("Expected a stopped consumer started again, got %v", err)
+	}
+	consumer.Stop()
+}
+
+func TestMemoryConsumer_ReturnsRightAway(t *testing.T) {
+	queue := message.NewMemoryQueue()
+	queue.SendMessageWithAttributes(context.Background(), "input", `{"process_id":"1"}`, nil)
+
+	handled := make(chan int, 4)
+	consumer := NewMemoryConsumer(queue, MemoryConfig{Queue: "input", RetryDelay: time.Hour}, func(ctx context.Context, msg Message) error {
+		handled <- msg.Attempt
+		if msg.Attempt == 1 {
+			return domain.ErrMessageReturned
+		}
+		return nil
+	})
+
+	if err := consumer.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer consumer.Stop()
+	for want := 1; want <= 2; want++ {
+		select {
+		case attempt := <-handled:
+			if attempt != want {
+				t.Errorf("Expected attempt %d, got %d", want, attempt)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatal("Expected the returned message delivered again without the retry delay")
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/port"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/observability"
+	"go.uber.org/zap"
+)
+
+// TraceIDAttribute is the message attribute carrying the trace id set by the producer
+const TraceIDAttribute = "trace_id"
+
+// TraceparentAttribute is the message attribute carrying the producer's W3C trace context
+const TraceparentAttribute = "traceparent"
+
+// ErrUnauthenticated is returned by Auth for messages that fail authentication
+var ErrUnauthenticated = errors.New("message not authenticated")
+
+// Middleware wraps a Handler with a cross-cutting concern
+type Middleware func(next Handler) Handler
+
+// Chain applies the middlewares to handler; the first one is the outermost
+func Chain(handler Handler, middlewares ...Middleware) Handler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+// TraceID returns the trace id stored by Tracing, or "" outside a traced handler
+func TraceID(ctx context.Context) string {
+	return observability.TraceFromContext(ctx).CorrelationID
+}
+
+// Tracing stores the producer's trace in the context: its trace id (or the one of its
+// traceparent, or else the message id) and its traceparent, which the results carry on
+func Tracing() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, msg Message) error {
+			trace := observability.TraceContext{CorrelationID: msg.Attributes[TraceIDAttribute]}
+			if traceID, _, ok := observability.ParseTraceparent(msg.Attributes[TraceparentAttribute]); ok {
+				trace.Parent = msg.Attributes[TraceparentAttribute]
+				if trace.CorrelationID == "" {
+					trace.CorrelationID = traceID
+				}
+			}
+			if trace.CorrelationID == "" {
+				trace.CorrelationID = msg.ID
+			}
+			return next(observability.WithTrace(ctx, trace), msg)
+		}
+	}
+}
+
+// Logging stores a logger scoped to the message (message_id, correlation_id, attempt) in the
+// context, for observability.FromContext, and logs the handler outcome
+func Logging() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, msg Message) error {
+			fields := []zap.Field{zap.String("message_id", msg.ID)}
+			if traceID := TraceID(ctx); traceID != "" {
+				fields = append(fields, zap.String("correlation_id", traceID))
+			}
+			if msg.Attempt > 0 {
+				fields = append(fields, zap.Int("attempt", msg.Attempt))
+			}
+			ctx = observability.WithLoggerFields(ctx, fields...)
+			logger := observability.FromContext(ctx)
+			logger.Info("received message from queue")
+
+			start := time.Now()
+			err := next(ctx, msg)
+			if err != nil {
+				logger.Error("error processing message", zap.Duration("duration", time.Since(start)), zap.Error(err))
+			} else {
+				logger.Debug("message handled", zap.Duration("duration", time.Since(start)))
+			}
+			return err
+		}
+	}
+}
+
+// Metrics records the handler outcome in worker_messages_processed_total
+func Metrics() Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, msg Message) error {
+			err := next(ctx, msg)
+			observability.RecordMessageProcessed(err == nil)
+			return err
+		}
+	}
+}
+
+// Recovery turns a handler panic into an error. The message is left in the queue so a poison
+// message reaches the queue's DLQ through its redrive policy instead of crashing the worker.
+// The panic also goes to reporter, when not nil
+func Recovery(reporter port.ErrorReporter) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, msg Message) (err error) {
+			defer func() {
+				if recovered := recover(); recovered != nil {
+					observability.RecordError("panic")
+					stack := debug.Stack()
+					observability.FromContext(ctx).Error("handler panicked",
+						zap.String("message_id", msg.ID),
+						zap.Any("panic", recovered),
+						zap.ByteString("stack", stack),
+					)
+					if reporter != nil {
+						reporter.ReportPanic(ctx, recovered, stack, msg.ID)
+					}
+					err = fmt.Errorf("%w: handler panicked: %v", domain.ErrMessageNotHandled, recovered)
+				}
+			}()
+			return next(ctx, msg)
+		}
+	}
+}
+
+// Auth stops the messages authenticate rejects before they reach the handler. They are handed
+// to reject with the reason, e.g. to move them to the dead-letter queue, whose error decides
+// whether they are acknowledged; without reject they are dropped
+func Auth(authenticate func(ctx context.Context, msg Message) error, reject func(ctx context.Context, msg Message, reason string) error) Middleware {
+	return func(next Handler) Handler {
+		return func(ctx context.Context, msg Message) error {
+			if err := authenticate(ctx, msg); err != nil {
+				observability.RecordError("authentication")
+				if reject != nil {
+					return fmt.Errorf("%w: %w", ErrUnauthenticated, reject(ctx, msg, err.Error()))
+				}
+				return fmt.Errorf("%w: %v", ErrUnauthenticated, err)
+			}
+			return next(ctx, msg)
+		}
+	}
+}
+
+// Idempotency acknowledges without handling the messages already handled within ttl, which
+// SQS may deliver more than once. Messages left in the queue are not remembered
+func Idempotency(ttl time.Duration) Middleware {
+	var mu sync.Mutex
+	handled := make(map[string]time.Time)
+
+	return func(next Handler) Handler {
+		return func(ctx context.Context, msg Message) error {
+			now := time.Now()
+
+			mu.Lock()
+			for id, at := range handled {
+				if now.Sub(at) > ttl {
+					delete(handled, id)
+				}
+			}
+			_, duplicate := handled[msg.ID]
+			mu.Unlock()
+
+			if duplicate {
+				observability.FromContext(ctx).Info("skipping duplicate message")
+				return nil
+			}
+
+			err := next(ctx, msg)
+			if !errors.Is(err, domain.ErrMessageNotHandled) {
+				mu.Lock()
+				handled[msg.ID] = now
+				mu.Unlock()
+			}
+			return err
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/observability"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestChain_Order(t *testing.T) {
+	var calls []string
+	record := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(ctx context.Context, msg Message) error {
+				calls = append(calls, name)
+				return next(ctx, msg)
+			}
+		}
+	}
+
+	handler := Chain(func(ctx context.Context, msg Message) error {
+		calls = append(calls, "handler")
+		return nil
+	}, record("outer"), record("inner"))
+
+	if err := handler(context.Background(), Message{}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if fmt.Sprint(calls) != "[outer inner handler]" {
+		t.Errorf("Expected the first middleware outermost, got %v", calls)
+	}
+}
+
+func TestTracing(t *testing.T) {
+	var traceID string
+	handler := Chain(func(ctx context.Context, msg Message) error {
+		traceID = TraceID(ctx)
+		return nil
+	}, Tracing())
+
+	handler(context.Background(), Message{ID: "msg-1", Attributes: map[string]string{TraceIDAttribute: "trace-1"}})
+	if traceID != "trace-1" {
+		t.Errorf("Expected the producer trace id, got %q", traceID)
+	}
+
+	handler(context.Background(), Message{ID: "msg-1"})
+	if traceID != "msg-1" {
+		t.Errorf("Expected the message id as trace id, got %q", traceID)
+	}
+}
+
+func TestTracing_Traceparent(t *testing.T) {
+	const traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	var trace observability.TraceContext
+	handler := Chain(func(ctx context.Context, msg Message) error {
+		trace = observability.TraceFromContext(ctx)
+		return nil
+	}, Tracing())
+
+	handler(context.Background(), Message{ID: "msg-1", Attributes: map[string]string{TraceparentAttribute: traceparent}})
+	if trace.CorrelationID != "4bf92f3577b34da6a3ce929d0e0e4736" || trace.Parent != traceparent {
+		t.Errorf("Expected the trace id of the traceparent, got %+v", trace)
+	}
+
+	handler(context.Background(), Message{ID: "msg-1", Attributes: map[string]string{TraceIDAttribute: "trace-1", TraceparentAttribute: "invalid"}})
+	if trace.CorrelationID != "trace-1" || trace.Parent != "" {
+		t.Errorf("Expected an invalid traceparent ignored, got %+v", trace)
+	}
+}
+
+func TestLogging_ScopesLoggerToMessage(t *testing.T) {
+	core, logs := observer.New(zap.DebugLevel)
+	ctx := observability.WithLogger(context.Background(), zap.New(core))
+
+	handler := Chain(func(ctx context.Context, msg Message) error {
+		observability.FromContext(ctx).Info("inside handler")
+		return nil
+	}, Tracing(), Logging())
+	handler(ctx, Message{ID: "msg-1", Attempt: 2, Attributes: map[string]string{TraceIDAttribute: "trace-1"}})
+
+	entries := logs.FilterMessage("inside handler").All()
+	if len(entries) != 1 {
+		t.Fatalf("Expected the handler log, got %d entries", len(entries))
+	}
+	fields := entries[0].ContextMap()
+	if fields["message_id"] != "msg-1" || fields["correlation_id"] != "trace-1" || fields["attempt"] != int64(2) {
+		t.Errorf("Expected the message fields on the handler logger, got %v", fields)
+	}
+}
+
+func TestRecovery(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	handler := Chain(func(ctx context.Context, msg Message) error {
+		panic("nil frame")
+	}, Recovery(nil))
+
+	err := handler(context.Background(), Message{ID: "msg-1"})
+	if !errors.Is(err, domain.ErrMessageNotHandled) {
+		t.Errorf("Expected the panicking message kept in the queue, got %v", err)
+	}
+}
+
+type panicReporter struct {
+	recovered any
+	messageID string
+	stack     []byte
+}
+
+func (r *panicReporter) ReportFailure(ctx context.Context, failure domain.JobFailure) {}
+
+func (r *panicReporter) ReportPanic(ctx context.Context, recovered any, stack []byte, messageID string) {
+	r.recovered, r.stack, r.messageID = recovered, stack, messageID
+}
+
+func TestRecovery_ReportsPanic(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	reporter := &panicReporter{}
+	handler := Chain(func(ctx context.Context, msg Message) error {
+		panic("nil frame")
+	}, Recovery(reporter))
+
+	handler(context.Background(), Message{ID: "msg-1"})
+	if reporter.recovered != "nil frame" || reporter.messageID != "msg-1" || len(reporter.stack) == 0 {
+		t.Errorf("Expected the panic reported with its message and stack, got %+v", reporter)
+	}
+}
+
+func TestAuth(t *testing.T) {
+	handled := false
+	handler := Chain(func(ctx context.Context, msg Message) error {
+		handled = true
+		return nil
+	}, Auth(func(ctx context.Context, msg Message) error {
+		if msg.Attributes["signature"] != "valid" {
+			return errors.New("invalid signature")
+		}
+		return nil
+	}, nil))
+
+	err := handler(context.Background(), Message{Attributes: map[string]string{"signature": "forged"}})
+	if !errors.Is(err, ErrUnauthenticated) || handled {
+		t.Errorf("Expected the message rejected before the handler, got %v", err)
+	}
+
+	if err := handler(context.Background(), Message{Attributes: map[string]string{"signature": "valid"}}); err != nil || !handled {
+		t.Errorf("Expected an authenticated message handled, got %v", err)
+	}
+}
+
+func TestAuth_Reject(t *testing.T) {
+	var rejected []string
+	handler := Chain(func(ctx context.Context, msg Message) error {
+		t.Error("Expected the handler not called")
+		return nil
+	}, Auth(func(ctx context.Context, msg Message) error {
+		return errors.New("invalid signature")
+	}, func(ctx context.Context, msg Message, reason string) error {
+		rejected = append(rejected, msg.ID+": "+reason)
+		if msg.ID == "retry" {
+			return fmt.Errorf("%w: dead-letter queue unavailable", domain.ErrMessageNotHandled)
+		}
+		return errors.New("message rejected")
+	}))
+
+	err := handler(context.Background(), Message{ID: "forged"})
+	if !errors.Is(err, ErrUnauthenticated) || errors.Is(err, domain.ErrMessageNotHandled) {
+		t.Errorf("Expected a rejected message acknowledged, got %v", err)
+	}
+	err = handler(context.Background(), Message{ID: "retry"})
+	if !errors.Is(err, ErrUnauthenticated) || !errors.Is(err, domain.ErrMessageNotHandled) {
+		t.Errorf("Expected a message that could not be rejected left in the queue, got %v", err)
+	}
+	if len(rejected) != 2 || rejected[0] != "forged: invalid signature" {
+		t.Errorf("Expected both messages rejected with the reason, got %v", rejected)
+	}
+}
+
+func TestIdempotency(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	calls := map[string]int{}
+	handler := Chain(func(ctx context.Context, msg Message) error {
+		calls[msg.ID]++
+		if msg.ID == "retry" {
+			return fmt.Errorf("%w: storage unavailable", domain.ErrMessageNotHandled)
+		}
+		return errors.New("job failed")
+	}, Idempotency(time.Hour))
+
+	for i := 0; i < 2; i++ {
+		handler(context.Background(), Message{ID: "done"})
+		handler(context.Background(), Message{ID: "retry"})
+	}
+
+	if calls["done"] != 1 {
+		t.Errorf("Expected a redelivered message skipped, got %d calls", calls["done"])
+	}
+	if calls["retry"] != 2 {
+		t.Errorf("Expected a message left in the queue handled again, got %d calls", calls["retry"])
+	}
+
+	expiring := Chain(func(ctx context.Context, msg Message) error {
+		calls[msg.ID]++
+		return nil
+	}, Idempotency(time.Nanosecond))
+	expiring(context.Background(), Message{ID: "expired"})
+	time.Sleep(time.Millisecond)
+	expiring(context.Background(), Message{ID: "expired"})
+	if calls["expired"] != 2 {
+		t.Errorf("Expected the message handled again after the ttl, got %d calls", calls["expired"])
+	}
+}
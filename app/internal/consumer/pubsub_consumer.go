@@ -0,0 +1,144 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"cloud.google.com/go/pubsub/v2"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/observability"
+	"go.uber.org/zap"
+)
+
+// PubSubAPI is the part of the Pub/Sub subscriber the consumer uses, so tests can replace it
+type PubSubAPI interface {
+	Receive(ctx context.Context, f func(context.Context, *pubsub.Message)) error
+}
+
+// PubSubConfig describes the subscription and how it is pulled
+type PubSubConfig struct {
+	Subscription string
+	// MaxOutstandingMessages is how many messages are handled at once
+	MaxOutstandingMessages int
+	// MaxExtension bounds how long the ack deadline of a message in flight keeps being
+	// extended; a job running longer is redelivered
+	MaxExtension time.Duration
+	// ErrorBackoff is the pause after the pull fails
+	ErrorBackoff time.Duration
+}
+
+// PubSubConsumer pulls a Google Pub/Sub subscription with streaming pull. The client extends
+// the ack deadline of the messages in flight, so a long job is not redelivered while it runs
+type PubSubConsumer struct {
+	subscriber PubSubAPI
+	config     PubSubConfig
+	handler    Handler
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewPubSubConsumer creates a consumer for the subscription in config; unset fields get the
+// worker defaults (one message, 1h deadline extension, 5s backoff)
+func NewPubSubConsumer(subscriber PubSubAPI, config PubSubConfig, handler Handler) Consumer {
+	if config.MaxOutstandingMessages <= 0 {
+		config.MaxOutstandingMessages = 1
+	}
+	if config.MaxExtension <= 0 {
+		config.MaxExtension = time.Hour
+	}
+	if config.ErrorBackoff <= 0 {
+		config.ErrorBackoff = 5 * time.Second
+	}
+	if s, ok := subscriber.(*pubsub.Subscriber); ok {
+		s.ReceiveSettings.MaxOutstandingMessages = config.MaxOutstandingMessages
+		s.ReceiveSettings.MaxExtension = config.MaxExtension
+		// A single stream is enough for the few messages in flight
+		s.ReceiveSettings.NumGoroutines = 1
+	}
+	return &PubSubConsumer{
+		subscriber: subscriber,
+		config:     config,
+		handler:    handler,
+	}
+}
+
+// Start begins pulling in the background
+func (c *PubSubConsumer) Start(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.done != nil {
+		return ErrAlreadyStarted
+	}
+
+	// Stop only interrupts the pull; messages already received are handled with ctx
+	receiveCtx, cancel := context.WithCancel(ctx)
+	c.cancel = cancel
+	c.done = make(chan struct{})
+
+	go func() {
+		defer close(c.done)
+		c.run(ctx, receiveCtx)
+	}()
+	return nil
+}
+
+// Stop stops pulling and waits for the messages in flight to be handled
+func (c *PubSubConsumer) Stop() {
+	c.mu.Lock()
+	cancel, done := c.cancel, c.done
+	c.mu.Unlock()
+	if done == nil {
+		return
+	}
+	cancel()
+	<-done
+
+	c.mu.Lock()
+	c.cancel, c.done = nil, nil
+	c.mu.Unlock()
+}
+
+func (c *PubSubConsumer) run(ctx, receiveCtx context.Context) {
+	logger := observability.GetLogger().With(zap.String("subscription", c.config.Subscription))
+
+	for receiveCtx.Err() == nil {
+		// Receive returns once receiveCtx is done and the callbacks in flight have returned
+		err := c.subscriber.Receive(receiveCtx, func(_ context.Context, msg *pubsub.Message) {
+			err := dispatch(ctx, c.handler, toPubSubMessage(msg))
+			// Unhandled messages are nacked and redelivered, with the subscription retry policy
+			if errors.Is(err, domain.ErrMessageNotHandled) {
+				msg.Nack()
+				return
+			}
+			msg.Ack()
+		})
+		if err == nil || receiveCtx.Err() != nil {
+			continue
+		}
+		logger.Warn("error receiving messages", zap.Error(err))
+		select {
+		case <-receiveCtx.Done():
+		case <-time.After(c.config.ErrorBackoff):
+		}
+	}
+}
+
+// toPubSubMessage keeps the attributes of the Pub/Sub message. The delivery attempt is only
+// counted by subscriptions with a dead letter policy
+func toPubSubMessage(msg *pubsub.Message) Message {
+	var attempt int
+	if msg.DeliveryAttempt != nil {
+		attempt = *msg.DeliveryAttempt
+	}
+	return Message{
+		ID:         msg.ID,
+		Body:       string(msg.Data),
+		Attributes: msg.Attributes,
+		Attempt:    attempt,
+		SentAt:     msg.PublishTime,
+	}
+}
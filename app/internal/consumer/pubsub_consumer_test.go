@@ -0,0 +1,181 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/pubsub/v2"
+	"cloud.google.com/go/pubsub/v2/apiv1/pubsubpb"
+	"cloud.google.com/go/pubsub/v2/pstest"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/observability"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// newTestSubscription creates the "input" topic and subscription in an in-memory Pub/Sub server
+func newTestSubscription(t *testing.T) (*pstest.Server, *pubsub.Client) {
+	t.Helper()
+	server := pstest.NewServer()
+	t.Cleanup(func() { server.Close() })
+
+	conn, err := grpc.NewClient(server.Addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Failed to connect to the fake server: %v", err)
+	}
+	client, err := pubsub.NewClient(context.Background(), "project", option.WithGRPCConn(conn))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	ctx := context.Background()
+	if _, err := client.TopicAdminClient.CreateTopic(ctx, &pubsubpb.Topic{Name: "projects/project/topics/input"}); err != nil {
+		t.Fatalf("Failed to create topic: %v", err)
+	}
+	if _, err := client.SubscriptionAdminClient.CreateSubscription(ctx, &pubsubpb.Subscription{
+		Name:               "projects/project/subscriptions/input",
+		Topic:              "projects/project/topics/input",
+		AckDeadlineSeconds: 10,
+	}); err != nil {
+		t.Fatalf("Failed to create subscription: %v", err)
+	}
+	return server, client
+}
+
+func TestPubSubConsumer_HandlesAndAcks(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	server, client := newTestSubscription(t)
+	for _, id := range []string{"1", "2", "3"} {
+		server.Publish("projects/project/topics/input", []byte(`{"process_id":"`+id+`"}`), map[string]string{"type": "video.process", "id": id})
+	}
+
+	var mu sync.Mutex
+	handled := map[string]int{}
+	var sentAt time.Time
+	done := make(chan struct{})
+	consumer := NewPubSubConsumer(client.Subscriber("input"), PubSubConfig{Subscription: "input"}, func(ctx context.Context, msg Message) error {
+		mu.Lock()
+		defer mu.Unlock()
+		id := msg.Attributes["id"]
+		handled[id]++
+		sentAt = SentAt(ctx)
+		if len(handled) == 3 && handled[id] == 1 {
+			close(done)
+		}
+		switch id {
+		case "2":
+			return errors.New("job failed")
+		case "3":
+			return fmt.Errorf("%w: storage unavailable", domain.ErrMessageNotHandled)
+		}
+		return nil
+	})
+
+	if err := consumer.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Expected every message handled, got %v", handled)
+	}
+	consumer.Stop()
+
+	if sentAt.IsZero() {
+		t.Error("Expected the publish time in the context")
+	}
+	// Failed jobs are acknowledged, unhandled messages are nacked (a zero deadline) for redelivery
+	for _, msg := range server.Messages() {
+		nacked := false
+		for _, modack := range msg.Modacks {
+			nacked = nacked || modack.AckDeadline == 0
+		}
+		if msg.Attributes["id"] == "3" {
+			if msg.Acks != 0 || !nacked {
+				t.Errorf("Expected the unhandled message nacked, got %d acks and modacks %v", msg.Acks, msg.Modacks)
+			}
+			continue
+		}
+		if msg.Acks != 1 || nacked {
+			t.Errorf("Expected message %s acknowledged once, got %d acks and modacks %v", msg.Attributes["id"], msg.Acks, msg.Modacks)
+		}
+	}
+}
+
+// mockSubscriber fails the first pulls, then blocks like an idle subscription
+type mockSubscriber struct {
+	mu       sync.Mutex
+	errs     []error
+	calls    int
+	received chan struct{}
+}
+
+func (m *mockSubscriber) Receive(ctx context.Context, f func(context.Context, *pubsub.Message)) error {
+	m.mu.Lock()
+	m.calls++
+	if len(m.errs) > 0 {
+		err := m.errs[0]
+		m.errs = m.errs[1:]
+		m.mu.Unlock()
+		return err
+	}
+	m.mu.Unlock()
+
+	m.received <- struct{}{}
+	<-ctx.Done()
+	return nil
+}
+
+func TestPubSubConsumer_RetriesAfterReceiveError(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	subscriber := &mockSubscriber{errs: []error{errors.New("permission denied")}, received: make(chan struct{}, 1)}
+	consumer := NewPubSubConsumer(subscriber, PubSubConfig{Subscription: "input", ErrorBackoff: time.Millisecond}, nil)
+
+	if err := consumer.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	select {
+	case <-subscriber.received:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the consumer to pull again after the error")
+	}
+	if err := consumer.Start(context.Background()); !errors.Is(err, ErrAlreadyStarted) {
+		t.Errorf("Expected ErrAlreadyStarted, got %v", err)
+	}
+	consumer.Stop()
+
+	if subscriber.calls != 2 {
+		t.Errorf("Expected 2 pulls, got %d", subscriber.calls)
+	}
+}
+
+func TestToPubSubMessage(t *testing.T) {
+	attempt := 3
+	publishTime := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	msg := toPubSubMessage(&pubsub.Message{
+		ID:              "m1",
+		Data:            []byte("body"),
+		Attributes:      map[string]string{"type": "video.process"},
+		PublishTime:     publishTime,
+		DeliveryAttempt: &attempt,
+	})
+
+	if msg.ID != "m1" || msg.Body != "body" || msg.Attributes["type"] != "video.process" || msg.Attempt != 3 || !msg.SentAt.Equal(publishTime) {
+		t.Errorf("Unexpected message %+v", msg)
+	}
+	if toPubSubMessage(&pubsub.Message{}).Attempt != 0 {
+		t.Error("Expected no attempt without a dead letter policy")
+	}
+}
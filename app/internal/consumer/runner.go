@@ -0,0 +1,204 @@
+package consumer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/observability"
+)
+
+// Elector lets a single replica at a time run a function, such as lease.Elector
+type Elector interface {
+	Run(ctx context.Context, lead func(ctx context.Context)) error
+}
+
+// Readiness is told how the consumption goes, such as the readiness probe of
+// observability.MetricsServer
+type Readiness interface {
+	SetReady(ready bool)
+	SetMaintenance(maintenance bool)
+}
+
+// RunConfig is what Run pauses and resumes the consumption on
+type RunConfig struct {
+	// Elector, when set, only consumes while this replica holds the lease; the other
+	// replicas stay on standby, ready to take over
+	Elector Elector
+
+	// KillSwitch, when set, pauses the consumption once it trips, until the worker restarts
+	KillSwitch *KillSwitch
+
+	// Maintenance is turned on and off by MaintenanceActive, read at start and then every
+	// MaintenanceInterval; a zero interval only reads it at start
+	Maintenance         *Maintenance
+	MaintenanceActive   func() bool
+	MaintenanceInterval time.Duration
+
+	// Readiness, when set, is told when the kill switch trips and when the maintenance changes
+	Readiness Readiness
+}
+
+// Run consumes with the consumers until ctx is done, letting the messages in flight finish:
+// they are handled with a context that outlives ctx. It returns the error of a consumer that
+// does not start or, with an elector, of the lost lease; the replica should then exit, and
+// its restart comes back on standby
+func Run(ctx context.Context, consumers []Consumer, config RunConfig) error {
+	r := &runner{
+		ctx:       context.WithoutCancel(ctx),
+		consumers: consumers,
+		config:    config,
+		failed:    make(chan error, 1),
+		leaseDone: make(chan error, 1),
+	}
+	logger := observability.GetLogger()
+
+	// A nil channel never trips
+	var tripped <-chan struct{}
+	if config.KillSwitch != nil {
+		tripped = config.KillSwitch.Tripped()
+	}
+	var checks <-chan time.Time
+	if config.Maintenance != nil && config.MaintenanceInterval > 0 {
+		ticker := time.NewTicker(config.MaintenanceInterval)
+		defer ticker.Stop()
+		checks = ticker.C
+	}
+
+	// A worker started in maintenance waits for it to end before consuming
+	if r.setMaintenance() {
+		logger.Warn("maintenance mode, not consuming messages")
+	} else if err := r.resume(); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			// The job in progress finishes before Run returns
+			r.pause()
+			return nil
+		case err := <-r.failed:
+			r.pause()
+			return err
+		case err := <-r.leaseDone:
+			// The consumers already stopped
+			return fmt.Errorf("input lease lost: %w", err)
+		case <-tripped:
+			// The replica stays alive but not ready, without consuming, until it is restarted
+			// (e.g. by a rollback), so the failures can be investigated
+			if config.Readiness != nil {
+				config.Readiness.SetReady(false)
+			}
+			r.pause()
+			logger.Error("input consumption paused by the kill switch, waiting for a restart")
+			<-ctx.Done()
+			return nil
+		case <-checks:
+			if !r.setMaintenance() {
+				continue
+			}
+			if config.Maintenance.Active() {
+				logger.Warn("maintenance mode on, returning messages and pausing the consumption")
+				r.pause()
+			} else {
+				logger.Info("maintenance mode off, resuming the consumption")
+				if err := r.resume(); err != nil {
+					return err
+				}
+			}
+		}
+	}
+}
+
+type runner struct {
+	// ctx is given to the consumers and the elector, apart from the cancellation of Run
+	ctx       context.Context
+	consumers []Consumer
+	config    RunConfig
+
+	consuming bool
+	// failed receives the error of the consumers started on a lease
+	failed    chan error
+	leaseDone chan error
+	stopLease func()
+}
+
+// setMaintenance reads the maintenance flag, reporting whether that changed it
+func (r *runner) setMaintenance() bool {
+	if r.config.Maintenance == nil || r.config.MaintenanceActive == nil {
+		return false
+	}
+	active := r.config.MaintenanceActive()
+	if !r.config.Maintenance.Set(active) {
+		return false
+	}
+	if r.config.Readiness != nil {
+		r.config.Readiness.SetMaintenance(active)
+	}
+	return true
+}
+
+// resume starts the consumers or, with an elector, waits for the lease to start them
+func (r *runner) resume() error {
+	r.consuming = true
+	if r.config.Elector == nil {
+		return r.start()
+	}
+
+	logger := observability.GetLogger()
+	leaseCtx, cancel := context.WithCancel(r.ctx)
+	r.stopLease = func() {
+		cancel()
+		<-r.leaseDone
+	}
+	go func() {
+		// The lease ends the consumption without cancelling the job in progress
+		r.leaseDone <- r.config.Elector.Run(leaseCtx, func(leadCtx context.Context) {
+			if err := r.start(); err != nil {
+				r.failed <- err
+				<-leadCtx.Done()
+				return
+			}
+			logger.Info("input lease acquired, consuming messages")
+			observability.SetLeaseLeader(true)
+			<-leadCtx.Done()
+			r.stop()
+			observability.SetLeaseLeader(false)
+		})
+	}()
+	logger.Info("input lease enabled, waiting for the lease")
+	return nil
+}
+
+// pause finishes the job in progress and, with an elector, releases the lease
+func (r *runner) pause() {
+	if !r.consuming {
+		return
+	}
+	r.consuming = false
+	if r.config.Elector != nil {
+		r.stopLease()
+	} else {
+		r.stop()
+	}
+}
+
+// start starts every consumer, stopping the ones already started when one fails
+func (r *runner) start() error {
+	for i, consumer := range r.consumers {
+		if err := consumer.Start(r.ctx); err != nil {
+			for _, started := range r.consumers[:i] {
+				started.Stop()
+			}
+			return fmt.Errorf("failed to start input consumer: %w", err)
+		}
+	}
+	return nil
+}
+
+func (r *runner) stop() {
+	for _, consumer := range r.consumers {
+		consumer.Stop()
+	}
+}
@@ -0,0 +1,193 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/observability"
+)
+
+// recordingConsumer reports each Start and Stop on events
+type recordingConsumer struct {
+	events   chan string
+	startErr error
+}
+
+func (c *recordingConsumer) Start(ctx context.Context) error {
+	if c.startErr != nil {
+		return c.startErr
+	}
+	c.events <- "start"
+	return nil
+}
+
+func (c *recordingConsumer) Stop() {
+	c.events <- "stop"
+}
+
+// recordingReadiness records what Run reports
+type recordingReadiness struct {
+	ready       atomic.Bool
+	maintenance atomic.Bool
+}
+
+func (r *recordingReadiness) SetReady(ready bool)             { r.ready.Store(ready) }
+func (r *recordingReadiness) SetMaintenance(maintenance bool) { r.maintenance.Store(maintenance) }
+
+// leadingElector grants the lease right away and loses it when lost is closed
+type leadingElector struct {
+	lost chan struct{}
+}
+
+func (e leadingElector) Run(ctx context.Context, lead func(ctx context.Context)) error {
+	leadCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		lead(leadCtx)
+	}()
+	select {
+	case <-e.lost:
+		cancel()
+		<-done
+		return errors.New("lease lost")
+	case <-ctx.Done():
+		cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+func expectEvent(t *testing.T, events chan string, want string) {
+	t.Helper()
+	select {
+	case got := <-events:
+		if got != want {
+			t.Fatalf("Expected %s, got %s", want, got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("Expected %s, got nothing", want)
+	}
+}
+
+func runAsync(ctx context.Context, consumers []Consumer, config RunConfig) chan error {
+	done := make(chan error, 1)
+	go func() { done <- Run(ctx, consumers, config) }()
+	return done
+}
+
+func TestRun_StopsWhenCancelled(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	input := &recordingConsumer{events: make(chan string, 4)}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := runAsync(ctx, []Consumer{input}, RunConfig{})
+
+	expectEvent(t, input.events, "start")
+	cancel()
+	expectEvent(t, input.events, "stop")
+	if err := <-done; err != nil {
+		t.Errorf("Expected no error on shutdown, got %v", err)
+	}
+}
+
+func TestRun_Maintenance(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	input := &recordingConsumer{events: make(chan string, 4)}
+	var active atomic.Bool
+	active.Store(true)
+	readiness := &recordingReadiness{}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := runAsync(ctx, []Consumer{input}, RunConfig{
+		Maintenance:         &Maintenance{},
+		MaintenanceActive:   active.Load,
+		MaintenanceInterval: time.Millisecond,
+		Readiness:           readiness,
+	})
+
+	// Started in maintenance, the worker waits for it to end
+	select {
+	case event := <-input.events:
+		t.Fatalf("Expected no consumption during the maintenance, got %s", event)
+	case <-time.After(20 * time.Millisecond):
+	}
+	if !readiness.maintenance.Load() {
+		t.Error("Expected the maintenance reported")
+	}
+
+	active.Store(false)
+	expectEvent(t, input.events, "start")
+	active.Store(true)
+	expectEvent(t, input.events, "stop")
+
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Expected no error on shutdown, got %v", err)
+	}
+}
+
+func TestRun_KillSwitch(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	input := &recordingConsumer{events: make(chan string, 4)}
+	killSwitch := NewKillSwitch(KillSwitchConfig{FailureRate: 0.5, Window: time.Minute, MinMessages: 1})
+	readiness := &recordingReadiness{}
+	readiness.ready.Store(true)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := runAsync(ctx, []Consumer{input}, RunConfig{KillSwitch: killSwitch, Readiness: readiness})
+
+	expectEvent(t, input.events, "start")
+	Chain(func(ctx context.Context, msg Message) error {
+		return errors.New("storage unavailable")
+	}, killSwitch.Middleware())(context.Background(), Message{ID: "1"})
+	expectEvent(t, input.events, "stop")
+
+	// The replica waits for its restart
+	select {
+	case err := <-done:
+		t.Fatalf("Expected Run to wait for the shutdown, got %v", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+	if readiness.ready.Load() {
+		t.Error("Expected the worker not ready once tripped")
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Errorf("Expected no error on shutdown, got %v", err)
+	}
+}
+
+func TestRun_Lease(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	input := &recordingConsumer{events: make(chan string, 4)}
+	elector := leadingElector{lost: make(chan struct{})}
+	done := runAsync(context.Background(), []Consumer{input}, RunConfig{Elector: elector})
+
+	expectEvent(t, input.events, "start")
+	close(elector.lost)
+	expectEvent(t, input.events, "stop")
+	if err := <-done; err == nil {
+		t.Error("Expected the lost lease returned")
+	}
+
+	// A consumer that does not start on the lease ends the run
+	failing := &recordingConsumer{events: make(chan string, 4), startErr: errors.New("queue not found")}
+	err := Run(context.Background(), []Consumer{failing}, RunConfig{Elector: leadingElector{lost: make(chan struct{})}})
+	if err == nil {
+		t.Error("Expected the start error returned")
+	}
+}
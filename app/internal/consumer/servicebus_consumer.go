@@ -0,0 +1,184 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/messaging/azservicebus"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/observability"
+	"go.uber.org/zap"
+)
+
+// ServiceBusAPI is the part of the Service Bus receiver the consumer uses, so tests can replace it
+type ServiceBusAPI interface {
+	ReceiveMessages(ctx context.Context, maxMessages int, options *azservicebus.ReceiveMessagesOptions) ([]*azservicebus.ReceivedMessage, error)
+	CompleteMessage(ctx context.Context, message *azservicebus.ReceivedMessage, options *azservicebus.CompleteMessageOptions) error
+	AbandonMessage(ctx context.Context, message *azservicebus.ReceivedMessage, options *azservicebus.AbandonMessageOptions) error
+	RenewMessageLock(ctx context.Context, message *azservicebus.ReceivedMessage, options *azservicebus.RenewMessageLockOptions) error
+}
+
+// ServiceBusConfig describes the queue (or topic subscription) and how it is received
+type ServiceBusConfig struct {
+	// Entity names the queue or subscription in the logs
+	Entity      string
+	MaxMessages int
+	// LockRenewInterval is how often the lock of a message in flight is renewed; keep it below
+	// the lock duration of the entity
+	LockRenewInterval time.Duration
+	// ErrorBackoff is the pause after a failed receive
+	ErrorBackoff time.Duration
+}
+
+// ServiceBusConsumer receives messages from an Azure Service Bus queue or subscription in
+// peek-lock mode, renewing their locks while they are handled so a long job is not redelivered
+type ServiceBusConsumer struct {
+	receiver ServiceBusAPI
+	config   ServiceBusConfig
+	handler  Handler
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewServiceBusConsumer creates a consumer for the receiver; unset fields get the worker
+// defaults (one message, lock renewed every 30s, 5s backoff)
+func NewServiceBusConsumer(receiver ServiceBusAPI, config ServiceBusConfig, handler Handler) Consumer {
+	if config.MaxMessages <= 0 {
+		config.MaxMessages = 1
+	}
+	if config.LockRenewInterval <= 0 {
+		config.LockRenewInterval = 30 * time.Second
+	}
+	if config.ErrorBackoff <= 0 {
+		config.ErrorBackoff = 5 * time.Second
+	}
+	return &ServiceBusConsumer{
+		receiver: receiver,
+		config:   config,
+		handler:  handler,
+	}
+}
+
+// Start begins receiving in the background
+func (c *ServiceBusConsumer) Start(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.done != nil {
+		return ErrAlreadyStarted
+	}
+
+	// Stop only interrupts the receive; messages already received are handled with ctx
+	receiveCtx, cancel := context.WithCancel(ctx)
+	c.cancel = cancel
+	c.done = make(chan struct{})
+
+	go func() {
+		defer close(c.done)
+		c.run(ctx, receiveCtx)
+	}()
+	return nil
+}
+
+// Stop stops receiving and waits for the current batch to be handled
+func (c *ServiceBusConsumer) Stop() {
+	c.mu.Lock()
+	cancel, done := c.cancel, c.done
+	c.mu.Unlock()
+	if done == nil {
+		return
+	}
+	cancel()
+	<-done
+
+	c.mu.Lock()
+	c.cancel, c.done = nil, nil
+	c.mu.Unlock()
+}
+
+func (c *ServiceBusConsumer) run(ctx, receiveCtx context.Context) {
+	logger := observability.GetLogger().With(zap.String("entity", c.config.Entity))
+
+	for receiveCtx.Err() == nil {
+		// Waits for at least one message
+		messages, err := c.receiver.ReceiveMessages(receiveCtx, c.config.MaxMessages, nil)
+		if err != nil {
+			if receiveCtx.Err() != nil {
+				return
+			}
+			logger.Warn("error receiving messages", zap.Error(err))
+			select {
+			case <-receiveCtx.Done():
+			case <-time.After(c.config.ErrorBackoff):
+			}
+			continue
+		}
+
+		for _, msg := range messages {
+			c.handle(ctx, msg)
+		}
+	}
+}
+
+// handle dispatches msg while its lock is renewed, then settles it
+func (c *ServiceBusConsumer) handle(ctx context.Context, msg *azservicebus.ReceivedMessage) {
+	logger := observability.GetLogger().With(zap.String("message_id", msg.MessageID))
+
+	done := make(chan struct{})
+	renewed := make(chan struct{})
+	go func() {
+		defer close(renewed)
+		ticker := time.NewTicker(c.config.LockRenewInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := c.receiver.RenewMessageLock(ctx, msg, nil); err != nil {
+					logger.Warn("failed to renew message lock", zap.Error(err))
+				}
+			}
+		}
+	}()
+	err := dispatch(ctx, c.handler, toServiceBusMessage(msg))
+	close(done)
+	<-renewed
+
+	// Unhandled messages are abandoned and redelivered until the entity's max delivery count
+	if errors.Is(err, domain.ErrMessageNotHandled) {
+		if err := c.receiver.AbandonMessage(ctx, msg, nil); err != nil {
+			logger.Warn("failed to abandon message", zap.Error(err))
+		}
+		return
+	}
+	if err := c.receiver.CompleteMessage(ctx, msg, nil); err != nil {
+		logger.Warn("failed to complete message", zap.Error(err))
+		return
+	}
+	logger.Debug("message completed")
+}
+
+// toServiceBusMessage keeps the string application properties of the message
+func toServiceBusMessage(msg *azservicebus.ReceivedMessage) Message {
+	attributes := make(map[string]string, len(msg.ApplicationProperties))
+	for name, value := range msg.ApplicationProperties {
+		if s, ok := value.(string); ok {
+			attributes[name] = s
+		}
+	}
+	var sentAt time.Time
+	if msg.EnqueuedTime != nil {
+		sentAt = *msg.EnqueuedTime
+	}
+	return Message{
+		ID:         msg.MessageID,
+		Body:       string(msg.Body),
+		Attributes: attributes,
+		Attempt:    int(msg.DeliveryCount),
+		SentAt:     sentAt,
+	}
+}
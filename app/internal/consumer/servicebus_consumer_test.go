@@ -0,0 +1,192 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/messaging/azservicebus"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/observability"
+)
+
+// mockServiceBus serves the queued batches once, then blocks like an idle queue
+type mockServiceBus struct {
+	mu        sync.Mutex
+	batches   [][]*azservicebus.ReceivedMessage
+	errs      []error
+	completed []string
+	abandoned []string
+	renewed   int
+	received  chan struct{}
+}
+
+func newMockServiceBus(batches ...[]*azservicebus.ReceivedMessage) *mockServiceBus {
+	return &mockServiceBus{batches: batches, received: make(chan struct{}, 16)}
+}
+
+func (m *mockServiceBus) ReceiveMessages(ctx context.Context, maxMessages int, options *azservicebus.ReceiveMessagesOptions) ([]*azservicebus.ReceivedMessage, error) {
+	m.mu.Lock()
+	if len(m.errs) > 0 {
+		err := m.errs[0]
+		m.errs = m.errs[1:]
+		m.mu.Unlock()
+		return nil, err
+	}
+	if len(m.batches) > 0 {
+		batch := m.batches[0]
+		m.batches = m.batches[1:]
+		m.mu.Unlock()
+		return batch, nil
+	}
+	m.mu.Unlock()
+
+	m.received <- struct{}{}
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (m *mockServiceBus) CompleteMessage(ctx context.Context, message *azservicebus.ReceivedMessage, options *azservicebus.CompleteMessageOptions) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.completed = append(m.completed, message.MessageID)
+	return nil
+}
+
+func (m *mockServiceBus) AbandonMessage(ctx context.Context, message *azservicebus.ReceivedMessage, options *azservicebus.AbandonMessageOptions) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.abandoned = append(m.abandoned, message.MessageID)
+	return nil
+}
+
+func (m *mockServiceBus) RenewMessageLock(ctx context.Context, message *azservicebus.ReceivedMessage, options *azservicebus.RenewMessageLockOptions) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.renewed++
+	return nil
+}
+
+// waitIdle waits until every queued batch was consumed and the consumer receives again
+func (m *mockServiceBus) waitIdle(t *testing.T) {
+	t.Helper()
+	select {
+	case <-m.received:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the consumer to receive from the queue")
+	}
+}
+
+func serviceBusMessage(id string) *azservicebus.ReceivedMessage {
+	enqueuedTime := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	return &azservicebus.ReceivedMessage{
+		MessageID:             id,
+		Body:                  []byte(`{"process_id":"` + id + `"}`),
+		ApplicationProperties: map[string]any{"type": "video.process", "priority": int64(1)},
+		DeliveryCount:         2,
+		EnqueuedTime:          &enqueuedTime,
+	}
+}
+
+func TestServiceBusConsumer_HandlesAndSettles(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	receiver := newMockServiceBus([]*azservicebus.ReceivedMessage{serviceBusMessage("1"), serviceBusMessage("2")}, []*azservicebus.ReceivedMessage{serviceBusMessage("3")})
+
+	var handled []Message
+	var attempts []int
+	consumer := NewServiceBusConsumer(receiver, ServiceBusConfig{Entity: "input"}, func(ctx context.Context, msg Message) error {
+		handled = append(handled, msg)
+		attempts = append(attempts, Attempt(ctx))
+		switch msg.ID {
+		case "2":
+			return errors.New("job failed")
+		case "3":
+			return fmt.Errorf("%w: storage unavailable", domain.ErrMessageNotHandled)
+		}
+		return nil
+	})
+
+	if err := consumer.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	receiver.waitIdle(t)
+	consumer.Stop()
+
+	if len(handled) != 3 {
+		t.Fatalf("Expected 3 handled messages, got %d", len(handled))
+	}
+	if handled[0].Body != `{"process_id":"1"}` || len(handled[0].Attributes) != 1 || handled[0].Attributes["type"] != "video.process" {
+		t.Errorf("Unexpected message %+v", handled[0])
+	}
+	if attempts[0] != 2 || handled[0].SentAt.IsZero() {
+		t.Errorf("Expected the delivery count and enqueued time, got %d and %v", attempts[0], handled[0].SentAt)
+	}
+	// Failed jobs are completed, unhandled messages are abandoned for redelivery
+	if len(receiver.completed) != 2 || receiver.completed[0] != "1" || receiver.completed[1] != "2" {
+		t.Errorf("Expected messages 1 and 2 completed, got %v", receiver.completed)
+	}
+	if len(receiver.abandoned) != 1 || receiver.abandoned[0] != "3" {
+		t.Errorf("Expected message 3 abandoned, got %v", receiver.abandoned)
+	}
+}
+
+func TestServiceBusConsumer_RenewsLock(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	receiver := newMockServiceBus([]*azservicebus.ReceivedMessage{serviceBusMessage("1")})
+	consumer := NewServiceBusConsumer(receiver, ServiceBusConfig{Entity: "input", LockRenewInterval: 5 * time.Millisecond}, func(ctx context.Context, msg Message) error {
+		time.Sleep(30 * time.Millisecond)
+		return nil
+	})
+
+	if err := consumer.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	receiver.waitIdle(t)
+	consumer.Stop()
+
+	receiver.mu.Lock()
+	defer receiver.mu.Unlock()
+	if receiver.renewed == 0 {
+		t.Error("Expected the lock to be renewed while the message is handled")
+	}
+	if len(receiver.completed) != 1 {
+		t.Errorf("Expected the message completed, got %v", receiver.completed)
+	}
+}
+
+func TestServiceBusConsumer_BacksOffOnError(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	receiver := newMockServiceBus([]*azservicebus.ReceivedMessage{serviceBusMessage("1")})
+	receiver.errs = []error{errors.New("connection lost")}
+
+	var handled int
+	consumer := NewServiceBusConsumer(receiver, ServiceBusConfig{Entity: "input", ErrorBackoff: time.Millisecond}, func(ctx context.Context, msg Message) error {
+		handled++
+		return nil
+	})
+
+	if err := consumer.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	receiver.waitIdle(t)
+	if err := consumer.Start(context.Background()); !errors.Is(err, ErrAlreadyStarted) {
+		t.Errorf("Expected ErrAlreadyStarted, got %v", err)
+	}
+	consumer.Stop()
+
+	if handled != 1 {
+		t.Errorf("Expected the message handled after the error, got %d", handled)
+	}
+}
@@ -0,0 +1,202 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/observability"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+	"go.uber.org/zap"
+)
+
+// SQSAPI is the part of the SQS client the consumer uses, so tests can replace it
+type SQSAPI interface {
+	ReceiveMessage(ctx context.Context, params *sqs.ReceiveMessageInput, optFns ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error)
+	DeleteMessage(ctx context.Context, params *sqs.DeleteMessageInput, optFns ...func(*sqs.Options)) (*sqs.DeleteMessageOutput, error)
+	ChangeMessageVisibility(ctx context.Context, params *sqs.ChangeMessageVisibilityInput, optFns ...func(*sqs.Options)) (*sqs.ChangeMessageVisibilityOutput, error)
+}
+
+// SQSConfig describes the queue and how it is polled
+type SQSConfig struct {
+	QueueURL            string
+	MaxNumberOfMessages int32
+	WaitTimeSeconds     int32
+	// VisibilityTimeout in seconds; zero keeps the queue default
+	VisibilityTimeout int32
+	// AttributeNames are the message attributes requested with each receive
+	AttributeNames []string
+	// ErrorBackoff is the pause after a failed receive
+	ErrorBackoff time.Duration
+}
+
+// SQSConsumer long polls an SQS queue and handles the messages one at a time
+type SQSConsumer struct {
+	client  SQSAPI
+	config  SQSConfig
+	handler Handler
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewSQSConsumer creates a consumer for the queue in config; unset polling fields get the
+// worker defaults (one message, 10s long poll, 5s backoff)
+func NewSQSConsumer(client SQSAPI, config SQSConfig, handler Handler) Consumer {
+	if config.MaxNumberOfMessages <= 0 {
+		config.MaxNumberOfMessages = 1
+	}
+	if config.WaitTimeSeconds <= 0 {
+		config.WaitTimeSeconds = 10
+	}
+	if config.ErrorBackoff <= 0 {
+		config.ErrorBackoff = 5 * time.Second
+	}
+	return &SQSConsumer{
+		client:  client,
+		config:  config,
+		handler: handler,
+	}
+}
+
+// Start begins polling in the background
+func (c *SQSConsumer) Start(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.done != nil {
+		return ErrAlreadyStarted
+	}
+
+	// Stop only interrupts the receive; messages already received are handled with ctx
+	receiveCtx, cancel := context.WithCancel(ctx)
+	c.cancel = cancel
+	c.done = make(chan struct{})
+
+	go func() {
+		defer close(c.done)
+		c.run(ctx, receiveCtx)
+	}()
+	return nil
+}
+
+// Stop stops polling and waits for the current batch to be handled
+func (c *SQSConsumer) Stop() {
+	c.mu.Lock()
+	cancel, done := c.cancel, c.done
+	c.mu.Unlock()
+	if done == nil {
+		return
+	}
+	cancel()
+	<-done
+
+	c.mu.Lock()
+	c.cancel, c.done = nil, nil
+	c.mu.Unlock()
+}
+
+func (c *SQSConsumer) run(ctx, receiveCtx context.Context) {
+	logger := observability.GetLogger().With(zap.String("queue", c.config.QueueURL))
+
+	for receiveCtx.Err() == nil {
+		input := &sqs.ReceiveMessageInput{
+			QueueUrl:              aws.String(c.config.QueueURL),
+			MaxNumberOfMessages:   c.config.MaxNumberOfMessages,
+			WaitTimeSeconds:       c.config.WaitTimeSeconds,
+			VisibilityTimeout:     c.config.VisibilityTimeout,
+			MessageAttributeNames: c.config.AttributeNames,
+			MessageSystemAttributeNames: []types.MessageSystemAttributeName{
+				types.MessageSystemAttributeNameApproximateReceiveCount,
+				types.MessageSystemAttributeNameSentTimestamp,
+			},
+		}
+		res, err := c.client.ReceiveMessage(receiveCtx, input)
+		if err != nil {
+			if receiveCtx.Err() != nil {
+				return
+			}
+			logger.Warn("error receiving message", zap.Error(err))
+			observability.RecordSQSOperation("receive", false)
+			select {
+			case <-receiveCtx.Done():
+			case <-time.After(c.config.ErrorBackoff):
+			}
+			continue
+		}
+		observability.RecordSQSOperation("receive", true)
+
+		for _, msg := range res.Messages {
+			err := dispatch(ctx, c.handler, toMessage(msg))
+			// Unhandled messages stay in the queue and come back after their visibility timeout,
+			// or right away when returned
+			if errors.Is(err, domain.ErrMessageReturned) {
+				c.release(ctx, msg)
+				continue
+			}
+			if errors.Is(err, domain.ErrMessageNotHandled) {
+				continue
+			}
+			c.delete(ctx, msg)
+		}
+	}
+}
+
+func (c *SQSConsumer) delete(ctx context.Context, msg types.Message) {
+	logger := observability.GetLogger().With(zap.String("message_id", aws.ToString(msg.MessageId)))
+
+	_, err := c.client.DeleteMessage(ctx, &sqs.DeleteMessageInput{
+		QueueUrl:      aws.String(c.config.QueueURL),
+		ReceiptHandle: msg.ReceiptHandle,
+	})
+	if err != nil {
+		logger.Warn("failed to delete message from queue", zap.Error(err))
+		observability.RecordSQSOperation("delete", false)
+		return
+	}
+	logger.Debug("message deleted from queue")
+	observability.RecordSQSOperation("delete", true)
+}
+
+// release makes the message visible again at once
+func (c *SQSConsumer) release(ctx context.Context, msg types.Message) {
+	_, err := c.client.ChangeMessageVisibility(ctx, &sqs.ChangeMessageVisibilityInput{
+		QueueUrl:          aws.String(c.config.QueueURL),
+		ReceiptHandle:     msg.ReceiptHandle,
+		VisibilityTimeout: 0,
+	})
+	if err != nil {
+		observability.GetLogger().Warn("failed to return message to the queue", zap.String("message_id", aws.ToString(msg.MessageId)), zap.Error(err))
+		observability.RecordSQSOperation("change_visibility", false)
+		return
+	}
+	observability.RecordSQSOperation("change_visibility", true)
+}
+
+// toMessage keeps the string attributes of the SQS message
+func toMessage(msg types.Message) Message {
+	attributes := make(map[string]string, len(msg.MessageAttributes))
+	for name, value := range msg.MessageAttributes {
+		if value.StringValue != nil {
+			attributes[name] = *value.StringValue
+		}
+	}
+	attempt, _ := strconv.Atoi(msg.Attributes[string(types.MessageSystemAttributeNameApproximateReceiveCount)])
+	var sentAt time.Time
+	// SentTimestamp is in epoch milliseconds
+	if millis, err := strconv.ParseInt(msg.Attributes[string(types.MessageSystemAttributeNameSentTimestamp)], 10, 64); err == nil {
+		sentAt = time.UnixMilli(millis)
+	}
+	return Message{
+		ID:         aws.ToString(msg.MessageId),
+		Body:       aws.ToString(msg.Body),
+		Attributes: attributes,
+		Attempt:    attempt,
+		SentAt:     sentAt,
+	}
+}
@@ -0,0 +1,266 @@
+package consumer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/observability"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+// mockSQS serves the queued batches once, then blocks like an empty long poll
+type mockSQS struct {
+	mu       sync.Mutex
+	batches  [][]types.Message
+	errs     []error
+	inputs   []*sqs.ReceiveMessageInput
+	deleted  []string
+	released []string
+	received chan struct{}
+}
+
+func newMockSQS(batches ...[]types.Message) *mockSQS {
+	return &mockSQS{batches: batches, received: make(chan struct{}, 16)}
+}
+
+func (m *mockSQS) ReceiveMessage(ctx context.Context, params *sqs.ReceiveMessageInput, optFns ...func(*sqs.Options)) (*sqs.ReceiveMessageOutput, error) {
+	m.mu.Lock()
+	m.inputs = append(m.inputs, params)
+	if len(m.errs) > 0 {
+		err := m.errs[0]
+		m.errs = m.errs[1:]
+		m.mu.Unlock()
+		return nil, err
+	}
+	if len(m.batches) > 0 {
+		batch := m.batches[0]
+		m.batches = m.batches[1:]
+		m.mu.Unlock()
+		return &sqs.ReceiveMessageOutput{Messages: batch}, nil
+	}
+	m.mu.Unlock()
+
+	m.received <- struct{}{}
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (m *mockSQS) DeleteMessage(ctx context.Context, params *sqs.DeleteMessageInput, optFns ...func(*sqs.Options)) (*sqs.DeleteMessageOutput, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.deleted = append(m.deleted, aws.ToString(params.ReceiptHandle))
+	return &sqs.DeleteMessageOutput{}, nil
+}
+
+func (m *mockSQS) ChangeMessageVisibility(ctx context.Context, params *sqs.ChangeMessageVisibilityInput, optFns ...func(*sqs.Options)) (*sqs.ChangeMessageVisibilityOutput, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.released = append(m.released, aws.ToString(params.ReceiptHandle))
+	return &sqs.ChangeMessageVisibilityOutput{}, nil
+}
+
+// waitIdle waits until every queued batch was consumed and the consumer polls again
+func (m *mockSQS) waitIdle(t *testing.T) {
+	t.Helper()
+	select {
+	case <-m.received:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the consumer to poll the queue")
+	}
+}
+
+func sqsMessage(id string) types.Message {
+	return types.Message{
+		MessageId:     aws.String(id),
+		ReceiptHandle: aws.String("receipt-" + id),
+		Body:          aws.String(`{"process_id":"` + id + `"}`),
+		Attributes:    map[string]string{"ApproximateReceiveCount": "3", "SentTimestamp": "1704110400000"},
+		MessageAttributes: map[string]types.MessageAttributeValue{
+			"type":  {DataType: aws.String("String"), StringValue: aws.String("video.process")},
+			"image": {DataType: aws.String("Binary"), BinaryValue: []byte{1}},
+		},
+	}
+}
+
+func TestSQSConsumer_HandlesAndDeletes(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	client := newMockSQS([]types.Message{sqsMessage("1"), sqsMessage("2")}, []types.Message{sqsMessage("3")})
+
+	var handled []Message
+	var attempts []int
+	var sentAts []time.Time
+	consumer := NewSQSConsumer(client, SQSConfig{
+		QueueURL:          "input-queue",
+		VisibilityTimeout: 300,
+		AttributeNames:    []string{"type"},
+	}, func(ctx context.Context, msg Message) error {
+		handled = append(handled, msg)
+		attempts = append(attempts, Attempt(ctx))
+		sentAts = append(sentAts, SentAt(ctx))
+		switch msg.ID {
+		case "2":
+			return errors.New("job failed")
+		case "3":
+			return fmt.Errorf("%w: storage unavailable", domain.ErrMessageNotHandled)
+		}
+		return nil
+	})
+
+	if err := consumer.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	client.waitIdle(t)
+	consumer.Stop()
+
+	if len(handled) != 3 {
+		t.Fatalf("Expected 3 handled messages, got %d", len(handled))
+	}
+	if handled[0].Body != `{"process_id":"1"}` || len(handled[0].Attributes) != 1 || handled[0].Attributes["type"] != "video.process" {
+		t.Errorf("Expected the body and string attributes, got %+v", handled[0])
+	}
+	if handled[0].Attempt != 3 || attempts[0] != 3 {
+		t.Errorf("Expected attempt 3 from the receive count in the message and context, got %d and %d", handled[0].Attempt, attempts[0])
+	}
+	sentAt := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	if !handled[0].SentAt.Equal(sentAt) || !sentAts[0].Equal(sentAt) {
+		t.Errorf("Expected the sent timestamp in the message and context, got %v and %v", handled[0].SentAt, sentAts[0])
+	}
+
+	// Failed messages are deleted too, only unhandled ones stay in the queue
+	if len(client.deleted) != 2 || client.deleted[0] != "receipt-1" || client.deleted[1] != "receipt-2" {
+		t.Errorf("Expected messages 1 and 2 deleted, got %v", client.deleted)
+	}
+
+	input := client.inputs[0]
+	if aws.ToString(input.QueueUrl) != "input-queue" || input.MaxNumberOfMessages != 1 || input.WaitTimeSeconds != 10 || input.VisibilityTimeout != 300 {
+		t.Errorf("Unexpected receive input %+v", input)
+	}
+	if len(input.MessageAttributeNames) != 1 || input.MessageAttributeNames[0] != "type" {
+		t.Errorf("Expected the type attribute requested, got %v", input.MessageAttributeNames)
+	}
+}
+
+func TestSQSConsumer_ReceiveErrorBacksOff(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	client := newMockSQS([]types.Message{sqsMessage("1")})
+	client.errs = []error{errors.New("throttled")}
+
+	handled := 0
+	consumer := NewSQSConsumer(client, SQSConfig{QueueURL: "input-queue", ErrorBackoff: time.Millisecond},
+		func(ctx context.Context, msg Message) error {
+			handled++
+			return nil
+		})
+
+	if err := consumer.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	client.waitIdle(t)
+	consumer.Stop()
+
+	if handled != 1 {
+		t.Errorf("Expected the message handled after the failed receive, got %d", handled)
+	}
+}
+
+func TestSQSConsumer_StopFinishesInFlightMessage(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	client := newMockSQS([]types.Message{sqsMessage("1")})
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var handlerErr error
+	consumer := NewSQSConsumer(client, SQSConfig{QueueURL: "input-queue"}, func(ctx context.Context, msg Message) error {
+		close(started)
+		<-release
+		handlerErr = ctx.Err()
+		return nil
+	})
+
+	if err := consumer.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	if err := consumer.Start(context.Background()); !errors.Is(err, ErrAlreadyStarted) {
+		t.Errorf("Expected ErrAlreadyStarted, got %v", err)
+	}
+
+	<-started
+	stopped := make(chan struct{})
+	go func() {
+		consumer.Stop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		t.Fatal("Expected Stop to wait for the message in flight")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+	<-stopped
+
+	if handlerErr != nil {
+		t.Errorf("Expected the handler context to outlive Stop, got %v", handlerErr)
+	}
+	if len(client.deleted) != 1 {
+		t.Errorf("Expected the in-flight message deleted, got %v", client.deleted)
+	}
+}
+
+func TestSQSConsumer_ReturnsAndRestarts(t *testing.T) {
+	if err := observability.InitLogger("test"); err != nil {
+		t.Fatalf("Failed to setup logger: %v", err)
+	}
+
+	client := newMockSQS([]types.Message{sqsMessage("1")})
+	returning := true
+	var handled []string
+	consumer := NewSQSConsumer(client, SQSConfig{QueueURL: "input-queue"}, func(ctx context.Context, msg Message) error {
+		handled = append(handled, msg.ID)
+		if returning {
+			return domain.ErrMessageReturned
+		}
+		return nil
+	})
+
+	if err := consumer.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	client.waitIdle(t)
+	consumer.Stop()
+
+	if len(client.released) != 1 || client.released[0] != "receipt-1" || len(client.deleted) != 0 {
+		t.Errorf("Expected the returned message made visible and kept, got released %v deleted %v", client.released, client.deleted)
+	}
+
+	// A stopped consumer starts again
+	returning = false
+	client.mu.Lock()
+	client.batches = [][]types.Message{{sqsMessage("1")}}
+	client.mu.Unlock()
+	if err := consumer.Start(context.Background()); err != nil {
+		t.Fatalf("Expected the consumer restarted, got %v", err)
+	}
+	client.waitIdle(t)
+	consumer.Stop()
+
+	if len(handled) != 2 || len(client.deleted) != 1 {
+		t.Errorf("Expected the message handled again after the restart, got handled %v deleted %v", handled, client.deleted)
+	}
+}
@@ -0,0 +1,172 @@
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/port"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/client"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/worker"
+)
+
+// DefaultTemporalActivity is the name workflows schedule the processing activity with
+const DefaultTemporalActivity = "ProcessVideo"
+
+// TemporalJobFailed is the type of the non-retryable error of an activity whose job failed;
+// the failure is also published as an error result
+const TemporalJobFailed = "JobFailed"
+
+// TemporalConfig describes the task queue the worker polls and the activity it serves
+type TemporalConfig struct {
+	TaskQueue string
+	// ActivityName is the name workflows schedule the activity with; empty means DefaultTemporalActivity
+	ActivityName string
+	// HeartbeatInterval is how often a running activity heartbeats, with the last progress it
+	// reported, so a long processing stage does not exceed the heartbeat timeout
+	HeartbeatInterval time.Duration
+	// StopTimeout bounds the wait for the activities in flight when the consumer stops
+	StopTimeout time.Duration
+}
+
+// TemporalConsumer serves processing requests as a Temporal activity, for workflows that
+// orchestrate the jobs instead of an input queue. The activity takes the request as it would
+// be sent to the queue, and each execution goes through the handler as a Message
+type TemporalConsumer struct {
+	client  client.Client
+	config  TemporalConfig
+	handler Handler
+
+	mu     sync.Mutex
+	worker worker.Worker
+}
+
+// NewTemporalConsumer creates a consumer for the task queue in config; unset fields get the
+// worker defaults (DefaultTemporalActivity, 10s heartbeats, 5min stop timeout)
+func NewTemporalConsumer(client client.Client, config TemporalConfig, handler Handler) Consumer {
+	if config.ActivityName == "" {
+		config.ActivityName = DefaultTemporalActivity
+	}
+	if config.HeartbeatInterval <= 0 {
+		config.HeartbeatInterval = 10 * time.Second
+	}
+	if config.StopTimeout <= 0 {
+		config.StopTimeout = 5 * time.Minute
+	}
+	return &TemporalConsumer{
+		client:  client,
+		config:  config,
+		handler: handler,
+	}
+}
+
+// Start registers the activity and starts polling the task queue in the background
+func (c *TemporalConsumer) Start(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.worker != nil {
+		return ErrAlreadyStarted
+	}
+
+	w := worker.New(c.client, c.config.TaskQueue, worker.Options{
+		BackgroundActivityContext: ctx,
+		WorkerStopTimeout:         c.config.StopTimeout,
+	})
+	w.RegisterActivityWithOptions(c.process, activity.RegisterOptions{Name: c.config.ActivityName})
+	if err := w.Start(); err != nil {
+		return fmt.Errorf("failed to start Temporal worker: %w", err)
+	}
+	c.worker = w
+	return nil
+}
+
+// Stop stops polling and waits up to StopTimeout for the activities in flight
+func (c *TemporalConsumer) Stop() {
+	c.mu.Lock()
+	w := c.worker
+	c.worker = nil
+	c.mu.Unlock()
+
+	if w != nil {
+		w.Stop()
+	}
+}
+
+// process is the activity. A job that will be retried fails it with a retryable error, so the
+// retry policy of the workflow schedules it again; any other failure is final
+func (c *TemporalConsumer) process(ctx context.Context, request json.RawMessage) error {
+	info := activity.GetInfo(ctx)
+	msg := Message{
+		ID:      info.WorkflowExecution.ID + "/" + info.ActivityID,
+		Body:    string(request),
+		Attempt: int(info.Attempt),
+		SentAt:  info.ScheduledTime,
+	}
+
+	state := &heartbeatState{}
+	ctx = context.WithValue(ctx, heartbeatKey{}, state)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(c.config.HeartbeatInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				state.heartbeat(ctx, nil)
+			}
+		}
+	}()
+
+	err := dispatch(ctx, c.handler, msg)
+	if err == nil || errors.Is(err, domain.ErrMessageNotHandled) {
+		return err
+	}
+	return temporal.NewNonRetryableApplicationError(err.Error(), TemporalJobFailed, err)
+}
+
+type heartbeatKey struct{}
+
+// heartbeatState keeps the last progress of an activity, sent again with every heartbeat
+type heartbeatState struct {
+	mu       sync.Mutex
+	progress *domain.TransferProgress
+}
+
+// heartbeat records a heartbeat with progress, or with the last progress when it is nil
+func (s *heartbeatState) heartbeat(ctx context.Context, progress *domain.TransferProgress) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if progress != nil {
+		s.progress = progress
+	}
+	if s.progress == nil {
+		activity.RecordHeartbeat(ctx)
+		return
+	}
+	activity.RecordHeartbeat(ctx, *s.progress)
+}
+
+// TemporalProgress reports the transfer progress of a job as the heartbeat details of the
+// activity running it; outside a TemporalConsumer activity it does nothing
+type TemporalProgress struct{}
+
+func NewTemporalProgress() port.ProgressPort {
+	return TemporalProgress{}
+}
+
+func (TemporalProgress) ReportProgress(ctx context.Context, progress domain.TransferProgress) {
+	state, ok := ctx.Value(heartbeatKey{}).(*heartbeatState)
+	if !ok {
+		return
+	}
+	state.heartbeat(ctx, &progress)
+}
@@ -0,0 +1,144 @@
+package consumer
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+	"go.temporal.io/sdk/activity"
+	"go.temporal.io/sdk/converter"
+	"go.temporal.io/sdk/temporal"
+	"go.temporal.io/sdk/testsuite"
+)
+
+// newTestActivity registers the activity of a consumer with handler in a test environment
+func newTestActivity(handler Handler, config TemporalConfig) *testsuite.TestActivityEnvironment {
+	c := NewTemporalConsumer(nil, config, handler).(*TemporalConsumer)
+	var suite testsuite.WorkflowTestSuite
+	env := suite.NewTestActivityEnvironment()
+	env.RegisterActivityWithOptions(c.process, activity.RegisterOptions{Name: c.config.ActivityName})
+	return env
+}
+
+func TestNewTemporalConsumer_Defaults(t *testing.T) {
+	c := NewTemporalConsumer(nil, TemporalConfig{TaskQueue: "video"}, nil).(*TemporalConsumer)
+
+	if c.config.ActivityName != DefaultTemporalActivity || c.config.HeartbeatInterval != 10*time.Second || c.config.StopTimeout != 5*time.Minute {
+		t.Errorf("Unexpected defaults %+v", c.config)
+	}
+}
+
+func TestTemporalConsumer_Process(t *testing.T) {
+	var handled Message
+	var attempt int
+	env := newTestActivity(func(ctx context.Context, msg Message) error {
+		handled = msg
+		attempt = Attempt(ctx)
+		return nil
+	}, TemporalConfig{TaskQueue: "video"})
+
+	request := json.RawMessage(`{"process_id":"123","video_bucket":"input","video_key":"video.mp4"}`)
+	if _, err := env.ExecuteActivity(DefaultTemporalActivity, request); err != nil {
+		t.Fatalf("ExecuteActivity failed: %v", err)
+	}
+
+	if handled.Body != string(request) {
+		t.Errorf("Expected the request as the message body, got %s", handled.Body)
+	}
+	if handled.ID == "" || handled.Attempt != 1 || attempt != 1 {
+		t.Errorf("Expected the activity id and first attempt, got %+v (attempt %d)", handled, attempt)
+	}
+}
+
+func TestTemporalConsumer_Process_Errors(t *testing.T) {
+	tests := []struct {
+		name         string
+		err          error
+		nonRetryable bool
+	}{
+		{"retried job", fmt.Errorf("%w: download failed", domain.ErrMessageNotHandled), false},
+		{"failed job", errors.New("invalid video"), true},
+	}
+
+	for _, tt := range tests {
+		env := newTestActivity(func(ctx context.Context, msg Message) error {
+			return tt.err
+		}, TemporalConfig{TaskQueue: "video", ActivityName: "Process"})
+
+		_, err := env.ExecuteActivity("Process", json.RawMessage(`{}`))
+		var applicationErr *temporal.ApplicationError
+		if !errors.As(err, &applicationErr) {
+			t.Fatalf("%s: expected an application error, got %v", tt.name, err)
+		}
+		if applicationErr.NonRetryable() != tt.nonRetryable {
+			t.Errorf("%s: expected non-retryable %v, got %v", tt.name, tt.nonRetryable, applicationErr)
+		}
+		if tt.nonRetryable && applicationErr.Type() != TemporalJobFailed {
+			t.Errorf("%s: expected the %s type, got %s", tt.name, TemporalJobFailed, applicationErr.Type())
+		}
+	}
+}
+
+// recordHeartbeats collects the progress of the heartbeats the activity sends; the SDK
+// throttles them, so only the first one of a short activity goes out
+func recordHeartbeats(env *testsuite.TestActivityEnvironment) func() []*domain.TransferProgress {
+	var mu sync.Mutex
+	var heartbeats []*domain.TransferProgress
+	env.SetOnActivityHeartbeatListener(func(info *activity.Info, details converter.EncodedValues) {
+		var progress *domain.TransferProgress
+		if details.HasValues() {
+			progress = &domain.TransferProgress{}
+			details.Get(progress)
+		}
+		mu.Lock()
+		heartbeats = append(heartbeats, progress)
+		mu.Unlock()
+	})
+	return func() []*domain.TransferProgress {
+		mu.Lock()
+		defer mu.Unlock()
+		return heartbeats
+	}
+}
+
+func TestTemporalConsumer_Heartbeats(t *testing.T) {
+	env := newTestActivity(func(ctx context.Context, msg Message) error {
+		// A processing stage reports no progress
+		time.Sleep(30 * time.Millisecond)
+		return nil
+	}, TemporalConfig{TaskQueue: "video", HeartbeatInterval: 10 * time.Millisecond})
+	heartbeats := recordHeartbeats(env)
+
+	if _, err := env.ExecuteActivity(DefaultTemporalActivity, json.RawMessage(`{}`)); err != nil {
+		t.Fatalf("ExecuteActivity failed: %v", err)
+	}
+	if sent := heartbeats(); len(sent) == 0 || sent[0] != nil {
+		t.Errorf("Expected periodic heartbeats without progress, got %v", sent)
+	}
+}
+
+func TestTemporalProgress_Heartbeat(t *testing.T) {
+	env := newTestActivity(func(ctx context.Context, msg Message) error {
+		NewTemporalProgress().ReportProgress(ctx, domain.NewTransferProgress("123", domain.TransferStageDownload, 50, 100))
+		return nil
+	}, TemporalConfig{TaskQueue: "video"})
+	heartbeats := recordHeartbeats(env)
+
+	if _, err := env.ExecuteActivity(DefaultTemporalActivity, json.RawMessage(`{}`)); err != nil {
+		t.Fatalf("ExecuteActivity failed: %v", err)
+	}
+	sent := heartbeats()
+	if len(sent) != 1 || sent[0] == nil || sent[0].Stage != domain.TransferStageDownload || sent[0].Percent != 50 {
+		t.Fatalf("Expected a heartbeat with the progress, got %v", sent)
+	}
+}
+
+func TestTemporalProgress_OutsideActivity(t *testing.T) {
+	// Does not panic without a running activity
+	NewTemporalProgress().ReportProgress(context.Background(), domain.TransferProgress{ProcessID: "123"})
+}
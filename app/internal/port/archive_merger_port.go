@@ -0,0 +1,11 @@
+package port
+
+import (
+	"context"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+)
+
+type ArchiveMergerPort interface {
+	MergeArchives(ctx context.Context, jobID string, groups []domain.ArchiveGroup) (zipPath string, err error)
+}
@@ -0,0 +1,11 @@
+package port
+
+import (
+	"context"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+)
+
+type AudioNormalizerPort interface {
+	Normalize(ctx context.Context, jobID, videoPath string, options domain.LoudnessOptions) (normalizedPath string, stats domain.LoudnessStats, err error)
+}
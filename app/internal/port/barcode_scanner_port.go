@@ -0,0 +1,11 @@
+package port
+
+import (
+	"context"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+)
+
+type BarcodeScannerPort interface {
+	ScanBarcodes(ctx context.Context, jobID, videoPath string, options domain.BarcodeOptions) ([]domain.BarcodeDetection, error)
+}
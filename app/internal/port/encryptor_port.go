@@ -0,0 +1,7 @@
+package port
+
+import "context"
+
+type EncryptorPort interface {
+	Encrypt(ctx context.Context, plaintext []byte) ([]byte, error)
+}
@@ -0,0 +1,12 @@
+package port
+
+import (
+	"context"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+)
+
+type ErrorReporter interface {
+	ReportFailure(ctx context.Context, failure domain.JobFailure)
+	ReportPanic(ctx context.Context, recovered any, stack []byte, messageID string)
+}
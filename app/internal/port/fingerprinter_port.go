@@ -0,0 +1,11 @@
+package port
+
+import (
+	"context"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+)
+
+type FingerprinterPort interface {
+	Fingerprint(ctx context.Context, jobID, videoPath string, options domain.FingerprintOptions) (fingerprintPath string, err error)
+}
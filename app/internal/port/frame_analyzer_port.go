@@ -0,0 +1,11 @@
+package port
+
+import (
+	"context"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+)
+
+type FrameAnalyzerPort interface {
+	ExtractText(ctx context.Context, framePath string, options domain.OCROptions) (string, error)
+}
@@ -0,0 +1,11 @@
+package port
+
+import (
+	"context"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+)
+
+type FramePostProcessorPort interface {
+	PostProcess(ctx context.Context, framePaths []string, options domain.FrameOptions) error
+}
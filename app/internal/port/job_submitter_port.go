@@ -0,0 +1,14 @@
+package port
+
+import (
+	"context"
+	"io"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+)
+
+type JobSubmitterPort interface {
+	Authorize(credentials domain.JobCredentials) (domain.JobProducer, error)
+
+	Submit(ctx context.Context, producer domain.JobProducer, video io.Reader, fileName string, options map[string]interface{}) (domain.JobSubmission, error)
+}
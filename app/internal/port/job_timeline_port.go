@@ -0,0 +1,11 @@
+package port
+
+import (
+	"context"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+)
+
+type JobTimelinePort interface {
+	Timeline(ctx context.Context, processID string) (domain.JobTimeline, error)
+}
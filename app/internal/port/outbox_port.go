@@ -0,0 +1,15 @@
+package port
+
+import (
+	"context"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+)
+
+type OutboxPort interface {
+	Save(ctx context.Context, entry domain.OutboxEntry) error
+
+	Pending(ctx context.Context) ([]domain.OutboxEntry, error)
+
+	Delete(ctx context.Context, id string) error
+}
@@ -0,0 +1,11 @@
+package port
+
+import (
+	"context"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+)
+
+type PackagerPort interface {
+	Package(ctx context.Context, jobID, videoPath, outputType string, packaging domain.PackagingOptions, options domain.FrameOptions) (outputDir string, err error)
+}
@@ -0,0 +1,11 @@
+package port
+
+import (
+	"context"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+)
+
+type ProgressPort interface {
+	ReportProgress(ctx context.Context, progress domain.TransferProgress)
+}
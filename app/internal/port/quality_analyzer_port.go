@@ -0,0 +1,11 @@
+package port
+
+import (
+	"context"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+)
+
+type QualityAnalyzerPort interface {
+	AnalyzeQuality(ctx context.Context, videoPath string, options domain.QCOptions) (domain.QCReport, error)
+}
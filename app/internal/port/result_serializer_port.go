@@ -0,0 +1,9 @@
+package port
+
+import "github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+
+type ResultSerializerPort interface {
+	Serialize(result *domain.ProcessResult) ([]byte, error)
+
+	ContentType() string
+}
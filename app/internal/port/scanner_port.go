@@ -0,0 +1,7 @@
+package port
+
+import "context"
+
+type ScannerPort interface {
+	ScanFile(ctx context.Context, path string) (infected bool, signature string, err error)
+}
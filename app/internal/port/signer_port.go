@@ -0,0 +1,11 @@
+package port
+
+import "context"
+
+type SignerPort interface {
+	Sign(ctx context.Context, payload []byte) (string, error)
+
+	Algorithm() string
+
+	KeyID() string
+}
@@ -3,13 +3,30 @@ package port
 import (
 	"context"
 	"io"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
 )
 
 type StoragePort interface {
-	GetObject(ctx context.Context, bucket, key string) (io.ReadCloser, error)
+	GetObject(ctx context.Context, bucket, key string, options ...domain.GetOption) (io.ReadCloser, error)
+
+	HeadObject(ctx context.Context, bucket, key string) (domain.ObjectInfo, error)
+
+	GetObjectIf(ctx context.Context, bucket, key string, condition domain.ObjectCondition, options ...domain.GetOption) (io.ReadCloser, error)
 
-	PutObject(ctx context.Context, bucket, key string, body io.Reader) (string, error)
+	PutObject(ctx context.Context, bucket, key string, body io.Reader, options ...domain.PutOption) (string, error)
+
+	CopyObject(ctx context.Context, srcBucket, srcKey, srcVersionID, dstBucket, dstKey string, options ...domain.PutOption) error
 
-	
 	DeleteObject(ctx context.Context, bucket, key string) error
+
+	DeleteObjectVersion(ctx context.Context, bucket, key, versionID string) error
+
+	ListObjects(ctx context.Context, bucket, prefix string) ([]string, error)
+
+	ListObjectPages(ctx context.Context, bucket, prefix string, fn func(page []domain.ObjectSummary) error) error
+
+	DeleteObjects(ctx context.Context, bucket string, keys []string) (map[string]error, error)
+
+	AbortMultipartUploads(ctx context.Context, bucket, prefix string) (int, error)
 }
@@ -0,0 +1,11 @@
+package port
+
+import (
+	"context"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+)
+
+type TranscriptionPort interface {
+	StartTranscription(ctx context.Context, request domain.TranscriptionRequest) (domain.TranscriptionRef, error)
+}
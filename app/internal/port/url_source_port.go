@@ -0,0 +1,12 @@
+package port
+
+import (
+	"context"
+	"io"
+)
+
+type URLSourcePort interface {
+	Allows(videoURL string) bool
+
+	GetURL(ctx context.Context, videoURL string) (io.ReadCloser, error)
+}
@@ -0,0 +1,11 @@
+package port
+
+import (
+	"context"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+)
+
+type VideoConcatenatorPort interface {
+	Concat(ctx context.Context, jobID string, clipPaths []string, options domain.ConcatOptions) (videoPath string, err error)
+}
@@ -1,7 +1,15 @@
 package port
 
-import "context"
+import (
+	"context"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+)
 
 type VideoProcessorPort interface {
-	ProcessVideo(ctx context.Context, videoPath string) (zipPath string, frameCount int, err error)
+	ProcessVideo(ctx context.Context, request domain.ProcessingRequest) (domain.ProcessingResult, error)
+
+	GenerateSpriteSheet(ctx context.Context, request domain.ProcessingRequest) (domain.ProcessingResult, error)
+
+	ProbeVideo(ctx context.Context, videoPath string) (domain.VideoInfo, error)
 }
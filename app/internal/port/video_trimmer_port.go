@@ -0,0 +1,11 @@
+package port
+
+import (
+	"context"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+)
+
+type VideoTrimmerPort interface {
+	Trim(ctx context.Context, jobID, videoPath string, options domain.TrimOptions) (trimmedPath string, err error)
+}
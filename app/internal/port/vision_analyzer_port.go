@@ -0,0 +1,11 @@
+package port
+
+import (
+	"context"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/internal/application/domain"
+)
+
+type VisionAnalyzerPort interface {
+	AnalyzeVision(ctx context.Context, jobID, videoPath string, config domain.VisionConfig) (domain.VisionReport, error)
+}
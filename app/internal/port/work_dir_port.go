@@ -0,0 +1,13 @@
+package port
+
+import "io"
+
+type VideoFile interface {
+	io.WriteCloser
+	Name() string
+}
+
+type WorkDirPort interface {
+	CreateVideo(jobID, name string, sizeBytes int64) (VideoFile, error)
+	Release(jobID string)
+}
@@ -0,0 +1,63 @@
+package buildinfo
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+)
+
+// Preenchidos no build via -ldflags "-X github.com/SOAT-Project/hackaton-soat-processor/pkg/buildinfo.Version=..."
+var (
+	Version   = "dev"
+	Commit    = ""
+	BuildDate = ""
+)
+
+// Info reúne os dados de build do worker e a versão do ffmpeg encontrada em runtime
+type Info struct {
+	Version       string `json:"version"`
+	Commit        string `json:"commit"`
+	BuildDate     string `json:"build_date"`
+	GoVersion     string `json:"go_version"`
+	FFmpegVersion string `json:"ffmpeg_version"`
+}
+
+// Get monta as informações de build; ffmpegVersion vazia indica ffmpeg não encontrado
+func Get(ffmpegVersion string) Info {
+	buildDate := BuildDate
+	if buildDate == "" {
+		buildDate = "unknown"
+	}
+	if ffmpegVersion == "" {
+		ffmpegVersion = "unavailable"
+	}
+	return Info{
+		Version:       Version,
+		Commit:        GitCommit(),
+		BuildDate:     buildDate,
+		GoVersion:     runtime.Version(),
+		FFmpegVersion: ffmpegVersion,
+	}
+}
+
+// String formata as informações para a saída de "worker --version"
+func (i Info) String() string {
+	return fmt.Sprintf("worker %s\ncommit: %s\nbuild date: %s\ngo: %s\nffmpeg: %s",
+		i.Version, i.Commit, i.BuildDate, i.GoVersion, i.FFmpegVersion)
+}
+
+// GitCommit retorna o commit injetado no build ou, na falta dele, a revisão que o Go
+// registra no binário quando compilado dentro de um repositório git
+func GitCommit() string {
+	if Commit != "" {
+		return Commit
+	}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			if setting.Key == "vcs.revision" {
+				return setting.Value
+			}
+		}
+	}
+	return "unknown"
+}
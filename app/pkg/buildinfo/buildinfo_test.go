@@ -0,0 +1,44 @@
+package buildinfo
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestGitCommit(t *testing.T) {
+	original := Commit
+	defer func() { Commit = original }()
+
+	Commit = "abc1234"
+	if commit := GitCommit(); commit != "abc1234" {
+		t.Errorf("Expected injected commit, got %s", commit)
+	}
+
+	Commit = ""
+	if commit := GitCommit(); commit == "" {
+		t.Error("Expected a fallback commit")
+	}
+}
+
+func TestGet(t *testing.T) {
+	originalVersion, originalDate := Version, BuildDate
+	defer func() { Version, BuildDate = originalVersion, originalDate }()
+
+	Version, BuildDate = "v1.2.0", "2024-05-01T12:00:00Z"
+	info := Get("6.1.1")
+	if info.Version != "v1.2.0" || info.BuildDate != "2024-05-01T12:00:00Z" || info.FFmpegVersion != "6.1.1" {
+		t.Errorf("Unexpected info: %+v", info)
+	}
+	if info.GoVersion != runtime.Version() {
+		t.Errorf("Expected go version %s, got %s", runtime.Version(), info.GoVersion)
+	}
+	if !strings.HasPrefix(info.String(), "worker v1.2.0\n") {
+		t.Errorf("Unexpected version output: %s", info.String())
+	}
+
+	BuildDate = ""
+	if info := Get(""); info.BuildDate != "unknown" || info.FFmpegVersion != "unavailable" {
+		t.Errorf("Expected placeholders for missing data, got %+v", info)
+	}
+}
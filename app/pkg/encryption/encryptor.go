@@ -0,0 +1,22 @@
+package encryption
+
+import "context"
+
+// AlgorithmAES256GCM identifica envelopes cifrados com AES-256-GCM
+const AlgorithmAES256GCM = "AES-256-GCM"
+
+// Envelope representa um payload cifrado junto com a chave de dados cifrada que o protege
+type Envelope struct {
+	Algorithm    string `json:"algorithm"`
+	KeyID        string `json:"key_id"`
+	EncryptedKey []byte `json:"encrypted_key"`
+	Nonce        []byte `json:"nonce"`
+	Ciphertext   []byte `json:"ciphertext"`
+}
+
+// Encryptor cifra e decifra payloads usando envelope encryption
+type Encryptor interface {
+	Encrypt(ctx context.Context, plaintext []byte) (*Envelope, error)
+
+	Decrypt(ctx context.Context, envelope *Envelope) ([]byte, error)
+}
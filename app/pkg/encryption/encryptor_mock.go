@@ -0,0 +1,25 @@
+package encryption
+
+import "context"
+
+// MockEncryptor é um mock da interface Encryptor para testes
+type MockEncryptor struct {
+	EncryptFunc func(ctx context.Context, plaintext []byte) (*Envelope, error)
+	DecryptFunc func(ctx context.Context, envelope *Envelope) ([]byte, error)
+}
+
+// Encrypt implementa Encryptor.Encrypt usando a função mock configurada
+func (m *MockEncryptor) Encrypt(ctx context.Context, plaintext []byte) (*Envelope, error) {
+	if m.EncryptFunc != nil {
+		return m.EncryptFunc(ctx, plaintext)
+	}
+	return &Envelope{Algorithm: AlgorithmAES256GCM, Ciphertext: plaintext}, nil
+}
+
+// Decrypt implementa Encryptor.Decrypt usando a função mock configurada
+func (m *MockEncryptor) Decrypt(ctx context.Context, envelope *Envelope) ([]byte, error) {
+	if m.DecryptFunc != nil {
+		return m.DecryptFunc(ctx, envelope)
+	}
+	return envelope.Ciphertext, nil
+}
@@ -0,0 +1,108 @@
+package encryption
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+)
+
+// KMSDataKeyAPI representa as operações do KMS utilizadas pelo KMSEnvelopeEncryptor
+type KMSDataKeyAPI interface {
+	GenerateDataKey(ctx context.Context, params *kms.GenerateDataKeyInput, optFns ...func(*kms.Options)) (*kms.GenerateDataKeyOutput, error)
+	Decrypt(ctx context.Context, params *kms.DecryptInput, optFns ...func(*kms.Options)) (*kms.DecryptOutput, error)
+}
+
+// KMSEnvelopeEncryptor implementa a interface Encryptor gerando uma chave de dados no KMS por payload
+type KMSEnvelopeEncryptor struct {
+	client KMSDataKeyAPI
+	keyID  string
+}
+
+// NewKMSEnvelopeEncryptor cria uma nova instância do KMSEnvelopeEncryptor
+func NewKMSEnvelopeEncryptor(cfg aws.Config, keyID string) *KMSEnvelopeEncryptor {
+	return NewKMSEnvelopeEncryptorWithClient(kms.NewFromConfig(cfg), keyID)
+}
+
+// NewKMSEnvelopeEncryptorWithClient cria um KMSEnvelopeEncryptor a partir de um cliente já configurado
+func NewKMSEnvelopeEncryptorWithClient(client KMSDataKeyAPI, keyID string) *KMSEnvelopeEncryptor {
+	return &KMSEnvelopeEncryptor{
+		client: client,
+		keyID:  keyID,
+	}
+}
+
+// Encrypt cifra o payload com uma chave de dados nova, devolvendo-a cifrada pela chave KMS
+func (e *KMSEnvelopeEncryptor) Encrypt(ctx context.Context, plaintext []byte) (*Envelope, error) {
+	dataKey, err := e.client.GenerateDataKey(ctx, &kms.GenerateDataKeyInput{
+		KeyId:   aws.String(e.keyID),
+		KeySpec: types.DataKeySpecAes256,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate data key with KMS: %w", err)
+	}
+
+	gcm, err := newGCM(dataKey.Plaintext)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	return &Envelope{
+		Algorithm:    AlgorithmAES256GCM,
+		KeyID:        aws.ToString(dataKey.KeyId),
+		EncryptedKey: dataKey.CiphertextBlob,
+		Nonce:        nonce,
+		Ciphertext:   gcm.Seal(nil, nonce, plaintext, nil),
+	}, nil
+}
+
+// Decrypt recupera a chave de dados no KMS e decifra o payload
+func (e *KMSEnvelopeEncryptor) Decrypt(ctx context.Context, envelope *Envelope) ([]byte, error) {
+	if envelope.Algorithm != AlgorithmAES256GCM {
+		return nil, fmt.Errorf("unsupported envelope algorithm: %s", envelope.Algorithm)
+	}
+
+	dataKey, err := e.client.Decrypt(ctx, &kms.DecryptInput{
+		CiphertextBlob: envelope.EncryptedKey,
+		KeyId:          aws.String(e.keyID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt data key with KMS: %w", err)
+	}
+
+	gcm, err := newGCM(dataKey.Plaintext)
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := gcm.Open(nil, envelope.Nonce, envelope.Ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt payload: %w", err)
+	}
+
+	return plaintext, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid data key: %w", err)
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize AES-GCM: %w", err)
+	}
+
+	return gcm, nil
+}
@@ -0,0 +1,140 @@
+package encryption
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+)
+
+// mockKMSDataKeyAPI simula o KMS "cifrando" a chave de dados com um prefixo fixo
+type mockKMSDataKeyAPI struct {
+	dataKey          []byte
+	generateErr      error
+	decryptErr       error
+	generateRequests int
+}
+
+func (m *mockKMSDataKeyAPI) GenerateDataKey(ctx context.Context, params *kms.GenerateDataKeyInput, optFns ...func(*kms.Options)) (*kms.GenerateDataKeyOutput, error) {
+	m.generateRequests++
+	if m.generateErr != nil {
+		return nil, m.generateErr
+	}
+	return &kms.GenerateDataKeyOutput{
+		KeyId:          params.KeyId,
+		Plaintext:      m.dataKey,
+		CiphertextBlob: append([]byte("wrapped:"), m.dataKey...),
+	}, nil
+}
+
+func (m *mockKMSDataKeyAPI) Decrypt(ctx context.Context, params *kms.DecryptInput, optFns ...func(*kms.Options)) (*kms.DecryptOutput, error) {
+	if m.decryptErr != nil {
+		return nil, m.decryptErr
+	}
+	return &kms.DecryptOutput{
+		Plaintext: bytes.TrimPrefix(params.CiphertextBlob, []byte("wrapped:")),
+	}, nil
+}
+
+func newMockKMS() *mockKMSDataKeyAPI {
+	return &mockKMSDataKeyAPI{dataKey: bytes.Repeat([]byte{0x42}, 32)}
+}
+
+func TestKMSEnvelopeEncryptor_Implementation(t *testing.T) {
+	// Verifica se KMSEnvelopeEncryptor implementa a interface Encryptor
+	var _ Encryptor = (*KMSEnvelopeEncryptor)(nil)
+}
+
+func TestNewKMSEnvelopeEncryptor(t *testing.T) {
+	encryptor := NewKMSEnvelopeEncryptor(aws.Config{Region: "us-east-1"}, "alias/results")
+
+	if encryptor == nil {
+		t.Fatal("NewKMSEnvelopeEncryptor returned nil")
+	}
+	if encryptor.client == nil {
+		t.Error("KMSEnvelopeEncryptor.client is nil")
+	}
+}
+
+func TestKMSEnvelopeEncryptor_RoundTrip(t *testing.T) {
+	encryptor := NewKMSEnvelopeEncryptorWithClient(newMockKMS(), "alias/results")
+	ctx := context.Background()
+	plaintext := []byte(`{"process_id":"123","file_key":"processed/frames_123.zip"}`)
+
+	envelope, err := encryptor.Encrypt(ctx, plaintext)
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v", err)
+	}
+
+	if envelope.Algorithm != AlgorithmAES256GCM {
+		t.Errorf("Expected algorithm %s, got %s", AlgorithmAES256GCM, envelope.Algorithm)
+	}
+	if envelope.KeyID != "alias/results" {
+		t.Errorf("Expected key ID alias/results, got %s", envelope.KeyID)
+	}
+	if bytes.Contains(envelope.Ciphertext, []byte("process_id")) {
+		t.Error("Ciphertext must not contain plaintext")
+	}
+
+	decrypted, err := encryptor.Decrypt(ctx, envelope)
+	if err != nil {
+		t.Fatalf("Decrypt failed: %v", err)
+	}
+	if !bytes.Equal(decrypted, plaintext) {
+		t.Errorf("Expected %s, got %s", plaintext, decrypted)
+	}
+}
+
+func TestKMSEnvelopeEncryptor_UniqueNonces(t *testing.T) {
+	encryptor := NewKMSEnvelopeEncryptorWithClient(newMockKMS(), "alias/results")
+	ctx := context.Background()
+
+	first, _ := encryptor.Encrypt(ctx, []byte("payload"))
+	second, _ := encryptor.Encrypt(ctx, []byte("payload"))
+
+	if bytes.Equal(first.Nonce, second.Nonce) {
+		t.Error("Expected a fresh nonce for each envelope")
+	}
+}
+
+func TestKMSEnvelopeEncryptor_GenerateDataKeyError(t *testing.T) {
+	mock := newMockKMS()
+	mock.generateErr = errors.New("access denied")
+	encryptor := NewKMSEnvelopeEncryptorWithClient(mock, "alias/results")
+
+	if _, err := encryptor.Encrypt(context.Background(), []byte("payload")); err == nil {
+		t.Error("Expected error when KMS fails")
+	}
+}
+
+func TestKMSEnvelopeEncryptor_DecryptErrors(t *testing.T) {
+	mock := newMockKMS()
+	encryptor := NewKMSEnvelopeEncryptorWithClient(mock, "alias/results")
+	ctx := context.Background()
+
+	envelope, err := encryptor.Encrypt(ctx, []byte("payload"))
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v", err)
+	}
+
+	tampered := *envelope
+	tampered.Ciphertext = append([]byte{}, envelope.Ciphertext...)
+	tampered.Ciphertext[0] ^= 0xFF
+	if _, err := encryptor.Decrypt(ctx, &tampered); err == nil {
+		t.Error("Expected error for tampered ciphertext")
+	}
+
+	unsupported := *envelope
+	unsupported.Algorithm = "ROT13"
+	if _, err := encryptor.Decrypt(ctx, &unsupported); err == nil {
+		t.Error("Expected error for unsupported algorithm")
+	}
+
+	mock.decryptErr = errors.New("access denied")
+	if _, err := encryptor.Decrypt(ctx, envelope); err == nil {
+		t.Error("Expected error when KMS decrypt fails")
+	}
+}
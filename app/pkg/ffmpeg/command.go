@@ -0,0 +1,67 @@
+package ffmpeg
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"time"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/observability"
+)
+
+// DefaultKillGrace é o tempo entre o SIGTERM e o SIGKILL enviados ao grupo do processo
+const DefaultKillGrace = 5 * time.Second
+
+// CombinedOutput executa o binário em seu próprio grupo de processos e devolve stdout+stderr.
+// Quando o contexto é cancelado, o grupo inteiro recebe SIGTERM e, após grace, SIGKILL,
+// evitando processos filhos órfãos; o processo é sempre aguardado para não deixar zumbis.
+// O tempo de CPU do processo é somado ao observability.JobUsage do contexto, quando houver.
+// Os erros de execução são *Failure, com o motivo classificado pela saída.
+func CombinedOutput(ctx context.Context, grace time.Duration, name string, args ...string) ([]byte, error) {
+	if grace <= 0 {
+		grace = DefaultKillGrace
+	}
+
+	var output bytes.Buffer
+	cmd := exec.Command(name, args...)
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	setProcessGroup(cmd)
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+			terminateGroup(cmd)
+			select {
+			case <-time.After(grace):
+				killGroup(cmd)
+			case <-done:
+			}
+		case <-done:
+		}
+	}()
+
+	err := cmd.Wait()
+	close(done)
+	if state := cmd.ProcessState; state != nil {
+		observability.UsageFromContext(ctx).AddCPUTime(state.UserTime() + state.SystemTime())
+	}
+
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		err = fmt.Errorf("%s interrupted: %w", name, ctxErr)
+	}
+	if err != nil {
+		return output.Bytes(), &Failure{Reason: ClassifyFailure(output.Bytes(), err), Output: output.Bytes(), Err: err}
+	}
+	return output.Bytes(), nil
+}
@@ -0,0 +1,93 @@
+package ffmpeg
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// processAlive informa se o pid existe e não é um zumbi
+func processAlive(pid int) bool {
+	stat, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return false
+	}
+	fields := strings.Fields(string(stat[strings.LastIndexByte(string(stat), ')')+1:]))
+	return len(fields) > 0 && fields[0] != "Z"
+}
+
+// waitForPID lê o pid gravado pelo processo filho no arquivo
+func waitForPID(t *testing.T, path string) int {
+	t.Helper()
+	for i := 0; i < 100; i++ {
+		if data, err := os.ReadFile(path); err == nil && len(strings.TrimSpace(string(data))) > 0 {
+			pid, _ := strconv.Atoi(strings.TrimSpace(string(data)))
+			return pid
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("Child process did not report its pid")
+	return 0
+}
+
+func waitForExit(pid int, timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		if !processAlive(pid) {
+			return true
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return false
+}
+
+func TestCombinedOutput_CancelTerminatesProcessGroup(t *testing.T) {
+	pidFile := filepath.Join(t.TempDir(), "child.pid")
+	ctx, cancel := context.WithCancel(context.Background())
+
+	go func() {
+		waitForPID(t, pidFile)
+		cancel()
+	}()
+
+	start := time.Now()
+	_, err := CombinedOutput(ctx, 5*time.Second, "sh", "-c", "sleep 30 & echo $! > "+pidFile+"; wait")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	if time.Since(start) > 3*time.Second {
+		t.Errorf("Expected SIGTERM to stop the group promptly, took %v", time.Since(start))
+	}
+
+	if child := waitForPID(t, pidFile); !waitForExit(child, 2*time.Second) {
+		t.Errorf("Grandchild process %d survived cancellation", child)
+	}
+}
+
+func TestCombinedOutput_CancelEscalatesToSIGKILL(t *testing.T) {
+	pidFile := filepath.Join(t.TempDir(), "child.pid")
+	ctx, cancel := context.WithCancel(context.Background())
+
+	go func() {
+		waitForPID(t, pidFile)
+		cancel()
+	}()
+
+	start := time.Now()
+	_, err := CombinedOutput(ctx, 200*time.Millisecond, "sh", "-c", "trap '' TERM; sleep 30 & echo $! > "+pidFile+"; wait")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Expected SIGKILL after the grace period, took %v", elapsed)
+	}
+
+	if child := waitForPID(t, pidFile); !waitForExit(child, 2*time.Second) {
+		t.Errorf("Grandchild process %d ignoring SIGTERM survived", child)
+	}
+}
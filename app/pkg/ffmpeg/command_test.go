@@ -0,0 +1,60 @@
+package ffmpeg
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/observability"
+)
+
+func TestCombinedOutput_Success(t *testing.T) {
+	output, err := CombinedOutput(context.Background(), time.Second, "sh", "-c", "echo out; echo err >&2")
+	if err != nil {
+		t.Fatalf("CombinedOutput failed: %v", err)
+	}
+
+	if !strings.Contains(string(output), "out") || !strings.Contains(string(output), "err") {
+		t.Errorf("Expected stdout and stderr, got %q", output)
+	}
+}
+
+func TestCombinedOutput_CountsCPUTime(t *testing.T) {
+	usage := &observability.JobUsage{}
+	ctx := observability.WithJobUsage(context.Background(), usage)
+
+	// Um laço curto para que o processo use CPU mensurável
+	if _, err := CombinedOutput(ctx, time.Second, "sh", "-c", "i=0; while [ $i -lt 200000 ]; do i=$((i+1)); done"); err != nil {
+		t.Fatalf("CombinedOutput failed: %v", err)
+	}
+	if usage.CPUTime() <= 0 {
+		t.Errorf("Expected the process CPU time counted, got %v", usage.CPUTime())
+	}
+}
+
+func TestCombinedOutput_ExitError(t *testing.T) {
+	output, err := CombinedOutput(context.Background(), time.Second, "sh", "-c", "echo failing; exit 3")
+	if err == nil {
+		t.Fatal("Expected error for non-zero exit")
+	}
+	if !strings.Contains(string(output), "failing") {
+		t.Errorf("Expected output to be returned on failure, got %q", output)
+	}
+}
+
+func TestCombinedOutput_BinaryNotFound(t *testing.T) {
+	if _, err := CombinedOutput(context.Background(), time.Second, "/nonexistent/ffmpeg"); err == nil {
+		t.Error("Expected error for missing binary")
+	}
+}
+
+func TestCombinedOutput_AlreadyCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := CombinedOutput(ctx, time.Second, "sh", "-c", "echo never"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
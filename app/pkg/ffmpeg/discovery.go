@@ -0,0 +1,190 @@
+package ffmpeg
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// Requirements descreve o que a instalação do ffmpeg precisa oferecer
+type Requirements struct {
+	// FFmpegPath e FFprobePath sobrepõem a busca no PATH quando informados
+	FFmpegPath  string
+	FFprobePath string
+
+	// MinVersion é obrigatória; MaxVersion vazia significa sem limite superior
+	MinVersion Version
+	MaxVersion *Version
+
+	Encoders []string
+	Filters  []string
+}
+
+// Installation descreve os binários encontrados e suas capacidades
+type Installation struct {
+	FFmpegPath  string
+	FFprobePath string
+	Version     Version
+	Encoders    map[string]bool
+	Filters     map[string]bool
+	// Decoders são os codecs que o ffmpeg decodifica, pelos mesmos nomes do codec_name do
+	// ffprobe (h264, hevc, av1...), e não pelos nomes dos decoders (libdav1d)
+	Decoders map[string]bool
+}
+
+// Discover localiza ffmpeg/ffprobe, valida a versão e verifica encoders e filtros exigidos
+func Discover(ctx context.Context, req Requirements) (*Installation, error) {
+	ffmpegPath, err := lookBinary(req.FFmpegPath, "ffmpeg")
+	if err != nil {
+		return nil, err
+	}
+
+	ffprobePath, err := lookBinary(req.FFprobePath, "ffprobe")
+	if err != nil {
+		return nil, err
+	}
+
+	versionOutput, err := run(ctx, ffmpegPath, "-hide_banner", "-version")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ffmpeg version: %w", err)
+	}
+
+	version, err := ParseVersion(versionOutput)
+	if err != nil {
+		return nil, err
+	}
+
+	if version.Compare(req.MinVersion) < 0 {
+		return nil, fmt.Errorf("ffmpeg %s is older than the minimum supported %s", version, req.MinVersion)
+	}
+	if req.MaxVersion != nil && version.Compare(*req.MaxVersion) > 0 {
+		return nil, fmt.Errorf("ffmpeg %s is newer than the maximum supported %s", version, *req.MaxVersion)
+	}
+
+	encodersOutput, err := run(ctx, ffmpegPath, "-hide_banner", "-encoders")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ffmpeg encoders: %w", err)
+	}
+
+	filtersOutput, err := run(ctx, ffmpegPath, "-hide_banner", "-filters")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ffmpeg filters: %w", err)
+	}
+
+	codecsOutput, err := run(ctx, ffmpegPath, "-hide_banner", "-codecs")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ffmpeg codecs: %w", err)
+	}
+
+	installation := &Installation{
+		FFmpegPath:  ffmpegPath,
+		FFprobePath: ffprobePath,
+		Version:     version,
+		Encoders:    parseCapabilities(encodersOutput),
+		Filters:     parseCapabilities(filtersOutput),
+		Decoders:    parseDecodableCodecs(codecsOutput),
+	}
+
+	if missing := missingNames(installation.Encoders, req.Encoders); len(missing) > 0 {
+		return installation, fmt.Errorf("ffmpeg is missing required encoders: %s", strings.Join(missing, ", "))
+	}
+	if missing := missingNames(installation.Filters, req.Filters); len(missing) > 0 {
+		return installation, fmt.Errorf("ffmpeg is missing required filters: %s", strings.Join(missing, ", "))
+	}
+
+	return installation, nil
+}
+
+// InstalledVersion retorna a versão do ffmpeg em ffmpegPath (ou no PATH), sem validar requisitos
+func InstalledVersion(ctx context.Context, ffmpegPath string) (Version, error) {
+	binary, err := lookBinary(ffmpegPath, "ffmpeg")
+	if err != nil {
+		return Version{}, err
+	}
+
+	output, err := run(ctx, binary, "-hide_banner", "-version")
+	if err != nil {
+		return Version{}, fmt.Errorf("failed to read ffmpeg version: %w", err)
+	}
+	return ParseVersion(output)
+}
+
+func lookBinary(configured, name string) (string, error) {
+	if configured == "" {
+		configured = name
+	}
+
+	path, err := exec.LookPath(configured)
+	if err != nil {
+		return "", fmt.Errorf("%s not found: %w", name, err)
+	}
+
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	return path, nil
+}
+
+func run(ctx context.Context, binary string, args ...string) (string, error) {
+	output, err := CombinedOutput(ctx, DefaultKillGrace, binary, args...)
+	if err != nil {
+		return "", fmt.Errorf("%w, output: %s", err, firstLine(string(output)))
+	}
+	return string(output), nil
+}
+
+// parseCapabilities lê a saída de "-encoders"/"-filters": cada linha útil é um bloco de flags
+// (ex.: "V....D" ou "TSC") seguido do nome; linhas de legenda ("V..... = Video") são ignoradas
+func parseCapabilities(output string) map[string]bool {
+	names := map[string]bool{}
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || fields[1] == "=" {
+			continue
+		}
+		if strings.Trim(fields[0], "VASFXBDTC.") != "" {
+			continue
+		}
+		names[fields[1]] = true
+	}
+	return names
+}
+
+// parseDecodableCodecs lê a saída de "-codecs": cada codec vem após seis flags (ex.: "DEV.LS
+// h264"), a primeira "D" quando ele pode ser decodificado
+func parseDecodableCodecs(output string) map[string]bool {
+	codecs := map[string]bool{}
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || fields[1] == "=" || len(fields[0]) != 6 {
+			continue
+		}
+		if strings.Trim(fields[0], "DEVASTIL.") != "" || fields[0][0] != 'D' {
+			continue
+		}
+		codecs[fields[1]] = true
+	}
+	return codecs
+}
+
+func missingNames(available map[string]bool, required []string) []string {
+	var missing []string
+	for _, name := range required {
+		if !available[name] {
+			missing = append(missing, name)
+		}
+	}
+	return missing
+}
+
+func firstLine(output string) string {
+	if i := strings.IndexByte(output, '\n'); i >= 0 {
+		return output[:i]
+	}
+	return output
+}
@@ -0,0 +1,173 @@
+package ffmpeg
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const fakeEncoders = `Encoders:
+ V..... = Video
+ A..... = Audio
+ ------
+ V....D mjpeg                MJPEG (Motion JPEG)
+ V....D png                  PNG (Portable Network Graphics) image
+`
+
+const fakeFilters = `Filters:
+  T.. = Timeline support
+  | = Source or sink filter
+ ... fps               V->V       Force constant framerate.
+ TSC scale             V->V       Scale the input video size.
+`
+
+const fakeCodecs = `Codecs:
+ D..... = Decoding supported
+ .E.... = Encoding supported
+ -------
+ DEV.LS h264                 H.264 / AVC / MPEG-4 AVC / MPEG-4 part 10
+ D.V.L. av1                  Alliance for Open Media AV1 (decoders: libdav1d av1)
+ .EV.L. libtheora_only       Encoder only
+ DEVIL. png                  PNG (Portable Network Graphics) image
+`
+
+// writeFakeFFmpeg cria scripts que imitam ffmpeg/ffprobe respondendo -version, -encoders,
+// -filters e -codecs
+func writeFakeFFmpeg(t *testing.T, version string) (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+
+	script := "#!/bin/sh\n" +
+		"case \"$2\" in\n" +
+		"  -version) echo 'ffmpeg version " + version + " Copyright (c) 2000-2023' ;;\n" +
+		"  -encoders) cat <<'EOF'\n" + fakeEncoders + "EOF\n ;;\n" +
+		"  -filters) cat <<'EOF'\n" + fakeFilters + "EOF\n ;;\n" +
+		"  -codecs) cat <<'EOF'\n" + fakeCodecs + "EOF\n ;;\n" +
+		"esac\n"
+
+	ffmpegPath := filepath.Join(dir, "ffmpeg")
+	ffprobePath := filepath.Join(dir, "ffprobe")
+	if err := os.WriteFile(ffmpegPath, []byte(script), 0755); err != nil {
+		t.Fatalf("Failed to write fake ffmpeg: %v", err)
+	}
+	if err := os.WriteFile(ffprobePath, []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatalf("Failed to write fake ffprobe: %v", err)
+	}
+	return ffmpegPath, ffprobePath
+}
+
+func TestDiscover_Success(t *testing.T) {
+	ffmpegPath, ffprobePath := writeFakeFFmpeg(t, "6.1.1")
+
+	installation, err := Discover(context.Background(), Requirements{
+		FFmpegPath:  ffmpegPath,
+		FFprobePath: ffprobePath,
+		MinVersion:  Version{4, 0, 0},
+		Encoders:    []string{"png", "mjpeg"},
+		Filters:     []string{"fps", "scale"},
+	})
+	if err != nil {
+		t.Fatalf("Discover failed: %v", err)
+	}
+
+	if installation.FFmpegPath != ffmpegPath || installation.FFprobePath != ffprobePath {
+		t.Errorf("Unexpected paths %s, %s", installation.FFmpegPath, installation.FFprobePath)
+	}
+	if installation.Version != (Version{6, 1, 1}) {
+		t.Errorf("Expected version 6.1.1, got %s", installation.Version)
+	}
+	if installation.Encoders["Video"] || installation.Encoders["="] {
+		t.Error("Legend lines must not be parsed as encoders")
+	}
+	if !installation.Decoders["h264"] || !installation.Decoders["av1"] || !installation.Decoders["png"] {
+		t.Errorf("Expected h264, av1 and png decodable, got %v", installation.Decoders)
+	}
+	if installation.Decoders["libtheora_only"] || installation.Decoders["Decoding"] || len(installation.Decoders) != 3 {
+		t.Errorf("Expected only decodable codecs, got %v", installation.Decoders)
+	}
+}
+
+func TestDiscover_VersionOutOfRange(t *testing.T) {
+	ffmpegPath, ffprobePath := writeFakeFFmpeg(t, "3.4.8")
+
+	_, err := Discover(context.Background(), Requirements{
+		FFmpegPath:  ffmpegPath,
+		FFprobePath: ffprobePath,
+		MinVersion:  Version{4, 0, 0},
+	})
+	if err == nil || !strings.Contains(err.Error(), "older") {
+		t.Errorf("Expected minimum version error, got %v", err)
+	}
+
+	ffmpegPath, ffprobePath = writeFakeFFmpeg(t, "7.1")
+	maxVersion := Version{6, 99, 99}
+	_, err = Discover(context.Background(), Requirements{
+		FFmpegPath:  ffmpegPath,
+		FFprobePath: ffprobePath,
+		MaxVersion:  &maxVersion,
+	})
+	if err == nil || !strings.Contains(err.Error(), "newer") {
+		t.Errorf("Expected maximum version error, got %v", err)
+	}
+}
+
+func TestDiscover_MissingCapabilities(t *testing.T) {
+	ffmpegPath, ffprobePath := writeFakeFFmpeg(t, "6.1.1")
+
+	_, err := Discover(context.Background(), Requirements{
+		FFmpegPath:  ffmpegPath,
+		FFprobePath: ffprobePath,
+		Encoders:    []string{"libwebp"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "libwebp") {
+		t.Errorf("Expected missing encoder error, got %v", err)
+	}
+
+	installation, err := Discover(context.Background(), Requirements{
+		FFmpegPath:  ffmpegPath,
+		FFprobePath: ffprobePath,
+		Filters:     []string{"zscale"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "zscale") {
+		t.Errorf("Expected missing filter error, got %v", err)
+	}
+	if installation == nil {
+		t.Error("Expected installation details even when capabilities are missing")
+	}
+}
+
+func TestDiscover_BinaryNotFound(t *testing.T) {
+	_, err := Discover(context.Background(), Requirements{
+		FFmpegPath: "/nonexistent/ffmpeg",
+	})
+	if err == nil {
+		t.Error("Expected error for missing ffmpeg binary")
+	}
+
+	ffmpegPath, _ := writeFakeFFmpeg(t, "6.1.1")
+	_, err = Discover(context.Background(), Requirements{
+		FFmpegPath:  ffmpegPath,
+		FFprobePath: "/nonexistent/ffprobe",
+	})
+	if err == nil {
+		t.Error("Expected error for missing ffprobe binary")
+	}
+}
+
+func TestInstalledVersion(t *testing.T) {
+	ffmpegPath, _ := writeFakeFFmpeg(t, "6.1.1-3ubuntu5")
+
+	version, err := InstalledVersion(context.Background(), ffmpegPath)
+	if err != nil {
+		t.Fatalf("InstalledVersion failed: %v", err)
+	}
+	if version.String() != "6.1.1" {
+		t.Errorf("Expected 6.1.1, got %s", version)
+	}
+
+	if _, err := InstalledVersion(context.Background(), filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("Expected error when ffmpeg is missing")
+	}
+}
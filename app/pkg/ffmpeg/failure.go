@@ -0,0 +1,84 @@
+package ffmpeg
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+)
+
+// Motivos normalizados das falhas do ffmpeg, usados no label reason de worker_errors_total
+const (
+	FailureUnsupportedCodec = "unsupported_codec"
+	FailureCorruptFile      = "corrupt_file"
+	FailureOutOfMemory      = "out_of_memory"
+	// FailureKilled é um processo encerrado por um sinal externo, como o OOM killer
+	FailureKilled    = "killed"
+	FailureTimeout   = "timeout"
+	FailureCancelled = "cancelled"
+	FailureUnknown   = "unknown"
+)
+
+// failurePatterns associa trechos comuns da saída do ffmpeg/ffprobe (em minúsculas) ao
+// motivo da falha; a memória vem antes porque um arquivo corrompido pode esgotá-la
+var failurePatterns = []struct {
+	reason   string
+	patterns []string
+}{
+	{FailureOutOfMemory, []string{"cannot allocate memory", "out of memory", "enomem"}},
+	{FailureUnsupportedCodec, []string{
+		"decoder (codec", "encoder (codec", "unknown decoder", "unknown encoder",
+		"unsupported codec", "could not find codec parameters", "no decoder for",
+	}},
+	{FailureCorruptFile, []string{
+		"invalid data found when processing input", "moov atom not found", "corrupt",
+		"error while decoding", "invalid nal unit", "header missing", "end of file",
+	}},
+}
+
+// Failure é o erro de uma execução que falhou, com o motivo normalizado a partir da saída
+type Failure struct {
+	Reason string
+	// Output é a saída combinada da execução, anexada aos relatórios de erro
+	Output []byte
+	Err    error
+}
+
+func (f *Failure) Error() string {
+	return f.Err.Error()
+}
+
+func (f *Failure) Unwrap() error {
+	return f.Err
+}
+
+// FailureReason implementa a interface lida por observability.RecordFailure
+func (f *Failure) FailureReason() string {
+	return f.Reason
+}
+
+// ClassifyFailure normaliza o motivo da falha de uma execução pela sua saída e erro
+func ClassifyFailure(output []byte, err error) string {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return FailureTimeout
+	case errors.Is(err, context.Canceled):
+		return FailureCancelled
+	}
+
+	text := strings.ToLower(string(output))
+	for _, failure := range failurePatterns {
+		for _, pattern := range failure.patterns {
+			if strings.Contains(text, pattern) {
+				return failure.reason
+			}
+		}
+	}
+
+	// ExitCode é -1 quando o processo terminou por um sinal
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == -1 {
+		return FailureKilled
+	}
+	return FailureUnknown
+}
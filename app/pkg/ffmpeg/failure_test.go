@@ -0,0 +1,49 @@
+package ffmpeg
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestClassifyFailure(t *testing.T) {
+	exitErr := errors.New("exit status 1")
+	tests := []struct {
+		name   string
+		output string
+		err    error
+		want   string
+	}{
+		{"unknown decoder", "Decoder (codec av1) not found for input stream #0:0", exitErr, FailureUnsupportedCodec},
+		{"codec parameters", "Could not find codec parameters for stream 0", exitErr, FailureUnsupportedCodec},
+		{"invalid data", "input.mp4: Invalid data found when processing input", exitErr, FailureCorruptFile},
+		{"moov atom", "[mov,mp4] moov atom not found", exitErr, FailureCorruptFile},
+		{"out of memory", "Error while filtering: Cannot allocate memory", exitErr, FailureOutOfMemory},
+		{"timeout", "", fmt.Errorf("ffmpeg interrupted: %w", context.DeadlineExceeded), FailureTimeout},
+		{"cancelled", "", fmt.Errorf("ffmpeg interrupted: %w", context.Canceled), FailureCancelled},
+		{"unknown", "something else", exitErr, FailureUnknown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClassifyFailure([]byte(tt.output), tt.err); got != tt.want {
+				t.Errorf("ClassifyFailure() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCombinedOutput_Failure(t *testing.T) {
+	_, err := CombinedOutput(context.Background(), time.Second, "sh", "-c", "echo 'moov atom not found' >&2; exit 1")
+	var failure *Failure
+	if !errors.As(err, &failure) || failure.FailureReason() != FailureCorruptFile {
+		t.Errorf("Expected a corrupt_file failure, got %v", err)
+	}
+
+	// Um processo morto por sinal, como pelo OOM killer
+	_, err = CombinedOutput(context.Background(), time.Second, "sh", "-c", "kill -9 $$")
+	if !errors.As(err, &failure) || failure.Reason != FailureKilled {
+		t.Errorf("Expected a killed failure, got %v", err)
+	}
+}
@@ -0,0 +1,136 @@
+package ffmpeg
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// Características de transferência HDR: PQ (HDR10/Dolby Vision) e HLG
+const (
+	ColorTransferPQ  = "smpte2084"
+	ColorTransferHLG = "arib-std-b67"
+)
+
+const (
+	CodecTypeVideo    = "video"
+	CodecTypeAudio    = "audio"
+	CodecTypeSubtitle = "subtitle"
+)
+
+// Stream é o subconjunto dos campos de stream do ffprobe usados pelo processor
+type Stream struct {
+	Index          int               `json:"index"`
+	CodecType      string            `json:"codec_type"`
+	CodecName      string            `json:"codec_name"`
+	Width          int               `json:"width"`
+	Height         int               `json:"height"`
+	ColorTransfer  string            `json:"color_transfer"`
+	ColorPrimaries string            `json:"color_primaries"`
+	Tags           map[string]string `json:"tags"`
+	SideDataList   []SideData        `json:"side_data_list"`
+}
+
+// SideData carrega os metadados extras do stream, como a matriz de exibição (rotação)
+type SideData struct {
+	SideDataType string  `json:"side_data_type"`
+	Rotation     float64 `json:"rotation"`
+}
+
+// Format é o subconjunto dos dados do container usados pelo processor
+type Format struct {
+	Duration string `json:"duration"`
+}
+
+// ProbeResult é a saída de ffprobe -show_streams -show_format
+type ProbeResult struct {
+	Streams []Stream `json:"streams"`
+	Format  Format   `json:"format"`
+}
+
+// Probe executa o ffprobe no arquivo e retorna seus streams
+func Probe(ctx context.Context, ffprobePath, videoPath string) (*ProbeResult, error) {
+	output, err := CombinedOutput(ctx, DefaultKillGrace, ffprobePath,
+		"-v", "error",
+		"-show_streams",
+		"-show_format",
+		"-of", "json",
+		videoPath,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ffprobe error: %w, output: %s", err, string(output))
+	}
+
+	return ParseProbe(output)
+}
+
+// ParseProbe interpreta a saída JSON do ffprobe
+func ParseProbe(output []byte) (*ProbeResult, error) {
+	var result ProbeResult
+	if err := json.Unmarshal(output, &result); err != nil {
+		return nil, fmt.Errorf("failed to parse ffprobe output: %w", err)
+	}
+	return &result, nil
+}
+
+// StreamsOfType retorna os streams do tipo informado, na ordem do arquivo
+func (r *ProbeResult) StreamsOfType(codecType string) []Stream {
+	var streams []Stream
+	for _, stream := range r.Streams {
+		if stream.CodecType == codecType {
+			streams = append(streams, stream)
+		}
+	}
+	return streams
+}
+
+// Duration retorna a duração do vídeo em segundos, ou zero quando desconhecida
+func (r *ProbeResult) Duration() float64 {
+	duration, err := strconv.ParseFloat(r.Format.Duration, 64)
+	if err != nil || duration < 0 {
+		return 0
+	}
+	return duration
+}
+
+// VideoStream retorna o primeiro stream de vídeo
+func (r *ProbeResult) VideoStream() (Stream, bool) {
+	streams := r.StreamsOfType(CodecTypeVideo)
+	if len(streams) == 0 {
+		return Stream{}, false
+	}
+	return streams[0], true
+}
+
+// Rotation retorna quantos graus (0, 90, 180 ou 270, sentido horário) o vídeo precisa
+// girar para ficar em pé. O ffmpeg antigo usa a tag rotate; o atual usa a matriz de
+// exibição, cujo ângulo é anti-horário
+func (s Stream) Rotation() int {
+	if tag, ok := s.Tags["rotate"]; ok {
+		if degrees, err := strconv.Atoi(tag); err == nil {
+			return normalizeRotation(degrees)
+		}
+	}
+	for _, sideData := range s.SideDataList {
+		if sideData.SideDataType == "Display Matrix" {
+			return normalizeRotation(-int(math.Round(sideData.Rotation)))
+		}
+	}
+	return 0
+}
+
+// IsHDR informa se o stream usa uma curva de transferência HDR (PQ ou HLG)
+func (s Stream) IsHDR() bool {
+	return s.ColorTransfer == ColorTransferPQ || s.ColorTransfer == ColorTransferHLG
+}
+
+func normalizeRotation(degrees int) int {
+	return ((degrees % 360) + 360) % 360
+}
+
+// Language retorna a tag de idioma do stream, se houver
+func (s Stream) Language() string {
+	return s.Tags["language"]
+}
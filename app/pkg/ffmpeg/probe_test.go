@@ -0,0 +1,156 @@
+package ffmpeg
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const sampleProbeOutput = `{
+  "streams": [
+    {"index": 0, "codec_type": "video", "codec_name": "h264", "width": 1920, "height": 1080},
+    {"index": 1, "codec_type": "audio", "codec_name": "aac", "tags": {"language": "por"}},
+    {"index": 2, "codec_type": "subtitle", "codec_name": "subrip", "tags": {"language": "eng"}},
+    {"index": 3, "codec_type": "subtitle", "codec_name": "hdmv_pgs_subtitle"}
+  ]
+}`
+
+// writeFakeFFprobe cria um script de ffprobe com o conteúdo informado
+func writeFakeFFprobe(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "ffprobe")
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+content), 0755); err != nil {
+		t.Fatalf("Failed to write fake ffprobe: %v", err)
+	}
+	return path
+}
+
+func TestParseProbe(t *testing.T) {
+	result, err := ParseProbe([]byte(sampleProbeOutput))
+	if err != nil {
+		t.Fatalf("ParseProbe failed: %v", err)
+	}
+
+	if len(result.Streams) != 4 {
+		t.Fatalf("Expected 4 streams, got %d", len(result.Streams))
+	}
+
+	video, ok := result.VideoStream()
+	if !ok || video.Width != 1920 || video.Height != 1080 {
+		t.Errorf("Expected a 1920x1080 video stream, got %+v", video)
+	}
+
+	subtitles := result.StreamsOfType(CodecTypeSubtitle)
+	if len(subtitles) != 2 {
+		t.Fatalf("Expected 2 subtitle streams, got %d", len(subtitles))
+	}
+	if subtitles[0].Index != 2 || subtitles[0].CodecName != "subrip" {
+		t.Errorf("Unexpected first subtitle stream: %+v", subtitles[0])
+	}
+	if subtitles[0].Language() != "eng" {
+		t.Errorf("Expected language eng, got %s", subtitles[0].Language())
+	}
+	if subtitles[1].Language() != "" {
+		t.Errorf("Expected empty language, got %s", subtitles[1].Language())
+	}
+}
+
+func TestParseProbe_InvalidJSON(t *testing.T) {
+	if _, err := ParseProbe([]byte("not json")); err == nil {
+		t.Error("Expected error for invalid ffprobe output")
+	}
+}
+
+func TestProbe(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "probe.json")
+	os.WriteFile(output, []byte(sampleProbeOutput), 0644)
+	ffprobe := writeFakeFFprobe(t, "cat "+output+"\n")
+
+	result, err := Probe(context.Background(), ffprobe, "video.mp4")
+	if err != nil {
+		t.Fatalf("Probe failed: %v", err)
+	}
+	if len(result.StreamsOfType(CodecTypeAudio)) != 1 {
+		t.Errorf("Expected 1 audio stream, got %+v", result.Streams)
+	}
+}
+
+func TestProbe_Error(t *testing.T) {
+	ffprobe := writeFakeFFprobe(t, "echo 'Invalid data' >&2\nexit 1\n")
+
+	if _, err := Probe(context.Background(), ffprobe, "video.mp4"); err == nil {
+		t.Error("Expected error when ffprobe fails")
+	}
+}
+
+func TestStream_Rotation(t *testing.T) {
+	tests := []struct {
+		name     string
+		stream   Stream
+		expected int
+	}{
+		{"no metadata", Stream{}, 0},
+		{"rotate tag", Stream{Tags: map[string]string{"rotate": "90"}}, 90},
+		{"negative tag", Stream{Tags: map[string]string{"rotate": "-90"}}, 270},
+		{"display matrix", Stream{SideDataList: []SideData{{SideDataType: "Display Matrix", Rotation: -90}}}, 90},
+		{"display matrix upside down", Stream{SideDataList: []SideData{{SideDataType: "Display Matrix", Rotation: 180}}}, 180},
+		{"other side data", Stream{SideDataList: []SideData{{SideDataType: "Stereo 3D"}}}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.stream.Rotation(); got != tt.expected {
+				t.Errorf("Expected rotation %d, got %d", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestProbeResult_VideoStream(t *testing.T) {
+	result, _ := ParseProbe([]byte(`{"streams":[
+		{"index":0,"codec_type":"audio"},
+		{"index":1,"codec_type":"video","side_data_list":[{"side_data_type":"Display Matrix","rotation":90}]}
+	]}`))
+
+	stream, ok := result.VideoStream()
+	if !ok {
+		t.Fatal("Expected video stream")
+	}
+	if stream.Index != 1 || stream.Rotation() != 270 {
+		t.Errorf("Unexpected video stream: %+v", stream)
+	}
+
+	if _, ok := (&ProbeResult{}).VideoStream(); ok {
+		t.Error("Expected no video stream for empty probe")
+	}
+}
+
+func TestStream_IsHDR(t *testing.T) {
+	if !(Stream{ColorTransfer: ColorTransferPQ}).IsHDR() {
+		t.Error("Expected PQ to be HDR")
+	}
+	if !(Stream{ColorTransfer: ColorTransferHLG}).IsHDR() {
+		t.Error("Expected HLG to be HDR")
+	}
+	if (Stream{ColorTransfer: "bt709"}).IsHDR() {
+		t.Error("Expected bt709 not to be HDR")
+	}
+}
+
+func TestProbeResult_Duration(t *testing.T) {
+	result, err := ParseProbe([]byte(`{"streams":[],"format":{"duration":"125.480000"}}`))
+	if err != nil {
+		t.Fatalf("ParseProbe failed: %v", err)
+	}
+	if result.Duration() != 125.48 {
+		t.Errorf("Expected duration 125.48, got %v", result.Duration())
+	}
+
+	if (&ProbeResult{}).Duration() != 0 {
+		t.Error("Expected zero duration when unknown")
+	}
+	if (&ProbeResult{Format: Format{Duration: "N/A"}}).Duration() != 0 {
+		t.Error("Expected zero duration for N/A")
+	}
+}
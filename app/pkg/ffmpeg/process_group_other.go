@@ -0,0 +1,16 @@
+//go:build !unix
+
+package ffmpeg
+
+import "os/exec"
+
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// terminateGroup encerra apenas o processo, pois grupos de processos não estão disponíveis
+func terminateGroup(cmd *exec.Cmd) {
+	cmd.Process.Kill()
+}
+
+func killGroup(cmd *exec.Cmd) {
+	cmd.Process.Kill()
+}
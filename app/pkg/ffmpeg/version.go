@@ -0,0 +1,66 @@
+package ffmpeg
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// Version representa a versão semântica de um binário do ffmpeg
+type Version struct {
+	Major int
+	Minor int
+	Patch int
+}
+
+var versionPattern = regexp.MustCompile(`version n?(\d+)(?:\.(\d+))?(?:\.(\d+))?`)
+
+// ParseVersion extrai a versão da primeira linha de "ffmpeg -version" (ex.: "ffmpeg version 6.1.1-3ubuntu5")
+func ParseVersion(output string) (Version, error) {
+	match := versionPattern.FindStringSubmatch(output)
+	if match == nil {
+		return Version{}, fmt.Errorf("unable to parse ffmpeg version from %q", firstLine(output))
+	}
+
+	var v Version
+	v.Major, _ = strconv.Atoi(match[1])
+	if match[2] != "" {
+		v.Minor, _ = strconv.Atoi(match[2])
+	}
+	if match[3] != "" {
+		v.Patch, _ = strconv.Atoi(match[3])
+	}
+	return v, nil
+}
+
+// ParseVersionString interpreta versões no formato "major[.minor[.patch]]"
+func ParseVersionString(value string) (Version, error) {
+	return ParseVersion("version " + value)
+}
+
+// Compare retorna -1, 0 ou 1 conforme v é menor, igual ou maior que other
+func (v Version) Compare(other Version) int {
+	switch {
+	case v.Major != other.Major:
+		return compareInt(v.Major, other.Major)
+	case v.Minor != other.Minor:
+		return compareInt(v.Minor, other.Minor)
+	default:
+		return compareInt(v.Patch, other.Patch)
+	}
+}
+
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+func compareInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
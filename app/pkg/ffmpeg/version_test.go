@@ -0,0 +1,59 @@
+package ffmpeg
+
+import "testing"
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		output string
+		want   Version
+	}{
+		{"ffmpeg version 6.1.1-3ubuntu5 Copyright (c) 2000-2023", Version{6, 1, 1}},
+		{"ffmpeg version 4.4 Copyright (c) 2000-2021", Version{4, 4, 0}},
+		{"ffmpeg version n7.0 Copyright (c) 2000-2024", Version{7, 0, 0}},
+		{"ffmpeg version 5", Version{5, 0, 0}},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseVersion(tt.output)
+		if err != nil {
+			t.Errorf("ParseVersion(%q) failed: %v", tt.output, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseVersion(%q) = %v, want %v", tt.output, got, tt.want)
+		}
+	}
+}
+
+func TestParseVersion_Invalid(t *testing.T) {
+	if _, err := ParseVersion("ffmpeg version N-112345-gabcdef"); err == nil {
+		t.Error("Expected error for git snapshot version")
+	}
+	if _, err := ParseVersionString("abc"); err == nil {
+		t.Error("Expected error for invalid version string")
+	}
+}
+
+func TestVersion_Compare(t *testing.T) {
+	tests := []struct {
+		a, b Version
+		want int
+	}{
+		{Version{6, 1, 1}, Version{6, 1, 1}, 0},
+		{Version{6, 1, 0}, Version{6, 1, 1}, -1},
+		{Version{6, 2, 0}, Version{6, 1, 9}, 1},
+		{Version{4, 9, 9}, Version{5, 0, 0}, -1},
+	}
+
+	for _, tt := range tests {
+		if got := tt.a.Compare(tt.b); got != tt.want {
+			t.Errorf("%v.Compare(%v) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestVersion_String(t *testing.T) {
+	if got := (Version{6, 1, 1}).String(); got != "6.1.1" {
+		t.Errorf("Expected 6.1.1, got %s", got)
+	}
+}
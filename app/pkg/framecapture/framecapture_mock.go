@@ -0,0 +1,34 @@
+package framecapture
+
+import "context"
+
+// MockFrameCaptureService é um mock da interface FrameCaptureService para testes
+type MockFrameCaptureService struct {
+	SubmitFunc func(ctx context.Context, job Job) (string, error)
+	StatusFunc func(ctx context.Context, jobID string) (Status, error)
+	CancelFunc func(ctx context.Context, jobID string) error
+}
+
+// Submit implementa FrameCaptureService.Submit usando a função mock configurada
+func (m *MockFrameCaptureService) Submit(ctx context.Context, job Job) (string, error) {
+	if m.SubmitFunc != nil {
+		return m.SubmitFunc(ctx, job)
+	}
+	return "job-id", nil
+}
+
+// Status implementa FrameCaptureService.Status usando a função mock configurada
+func (m *MockFrameCaptureService) Status(ctx context.Context, jobID string) (Status, error) {
+	if m.StatusFunc != nil {
+		return m.StatusFunc(ctx, jobID)
+	}
+	return Status{State: StateComplete}, nil
+}
+
+// Cancel implementa FrameCaptureService.Cancel usando a função mock configurada
+func (m *MockFrameCaptureService) Cancel(ctx context.Context, jobID string) error {
+	if m.CancelFunc != nil {
+		return m.CancelFunc(ctx, jobID)
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package framecapture
+
+import "context"
+
+// Estados de um job de captura
+const (
+	StateRunning   = "running"
+	StateComplete  = "complete"
+	StateFailed    = "failed"
+	StateCancelled = "cancelled"
+)
+
+// Job é uma captura de frames de um vídeo no S3, gravados como JPEG sob
+// OutputBucket/OutputPrefix, numerados na ordem do vídeo
+type Job struct {
+	Name        string
+	InputBucket string
+	InputKey    string
+	// OutputPrefix termina em "/"
+	OutputBucket string
+	OutputPrefix string
+	// A taxa de captura é RateNumerator/RateDenominator frames por segundo
+	RateNumerator   int32
+	RateDenominator int32
+	// MaxCaptures limita os frames capturados; zero não limita
+	MaxCaptures int
+	// AutoRotate aplica a rotação dos metadados do vídeo
+	AutoRotate bool
+}
+
+// Status é o estado de um job, com a mensagem do serviço quando ele falhou
+type Status struct {
+	State   string
+	Message string
+}
+
+type FrameCaptureService interface {
+	Submit(ctx context.Context, job Job) (string, error)
+
+	Status(ctx context.Context, jobID string) (Status, error)
+
+	Cancel(ctx context.Context, jobID string) error
+}
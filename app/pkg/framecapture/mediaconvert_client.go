@@ -0,0 +1,118 @@
+package framecapture
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/mediaconvert"
+	"github.com/aws/aws-sdk-go-v2/service/mediaconvert/types"
+)
+
+// mediaConvertQuality é a qualidade JPEG dos frames capturados (1 a 100)
+const mediaConvertQuality = 90
+
+// MediaConvertAPI é o subconjunto do cliente MediaConvert usado pelo MediaConvertClient
+type MediaConvertAPI interface {
+	CreateJob(ctx context.Context, params *mediaconvert.CreateJobInput, optFns ...func(*mediaconvert.Options)) (*mediaconvert.CreateJobOutput, error)
+	GetJob(ctx context.Context, params *mediaconvert.GetJobInput, optFns ...func(*mediaconvert.Options)) (*mediaconvert.GetJobOutput, error)
+	CancelJob(ctx context.Context, params *mediaconvert.CancelJobInput, optFns ...func(*mediaconvert.Options)) (*mediaconvert.CancelJobOutput, error)
+}
+
+// MediaConvertClient implementa FrameCaptureService com o AWS Elemental MediaConvert, que lê
+// o vídeo e grava os frames direto no S3
+type MediaConvertClient struct {
+	client MediaConvertAPI
+	// role é a role IAM que o MediaConvert assume para ler e gravar nos buckets
+	role  string
+	queue string
+}
+
+// NewMediaConvertClient cria o cliente; queue vazia usa a fila padrão da conta
+func NewMediaConvertClient(cfg aws.Config, role, queue string) *MediaConvertClient {
+	return &MediaConvertClient{
+		client: mediaconvert.NewFromConfig(cfg),
+		role:   role,
+		queue:  queue,
+	}
+}
+
+// Submit cria um job com uma única saída FRAME_CAPTURE
+func (c *MediaConvertClient) Submit(ctx context.Context, job Job) (string, error) {
+	capture := &types.FrameCaptureSettings{
+		FramerateNumerator:   aws.Int32(job.RateNumerator),
+		FramerateDenominator: aws.Int32(job.RateDenominator),
+		Quality:              aws.Int32(mediaConvertQuality),
+	}
+	if job.MaxCaptures > 0 {
+		capture.MaxCaptures = aws.Int32(int32(job.MaxCaptures))
+	}
+	rotate := types.InputRotateDegree0
+	if job.AutoRotate {
+		rotate = types.InputRotateAuto
+	}
+
+	input := &mediaconvert.CreateJobInput{
+		Role: aws.String(c.role),
+		Tags: map[string]string{"job": job.Name},
+		Settings: &types.JobSettings{
+			Inputs: []types.Input{{
+				FileInput:     aws.String(fmt.Sprintf("s3://%s/%s", job.InputBucket, job.InputKey)),
+				VideoSelector: &types.VideoSelector{Rotate: rotate},
+			}},
+			OutputGroups: []types.OutputGroup{{
+				OutputGroupSettings: &types.OutputGroupSettings{
+					Type: types.OutputGroupTypeFileGroupSettings,
+					FileGroupSettings: &types.FileGroupSettings{
+						Destination: aws.String(fmt.Sprintf("s3://%s/%sframe", job.OutputBucket, job.OutputPrefix)),
+					},
+				},
+				Outputs: []types.Output{{
+					ContainerSettings: &types.ContainerSettings{Container: types.ContainerTypeRaw},
+					VideoDescription: &types.VideoDescription{
+						CodecSettings: &types.VideoCodecSettings{
+							Codec:                types.VideoCodecFrameCapture,
+							FrameCaptureSettings: capture,
+						},
+					},
+				}},
+			}},
+		},
+	}
+	if c.queue != "" {
+		input.Queue = aws.String(c.queue)
+	}
+
+	output, err := c.client.CreateJob(ctx, input)
+	if err != nil {
+		return "", fmt.Errorf("failed to create MediaConvert job: %w", err)
+	}
+	return aws.ToString(output.Job.Id), nil
+}
+
+// Status lê o estado do job
+func (c *MediaConvertClient) Status(ctx context.Context, jobID string) (Status, error) {
+	output, err := c.client.GetJob(ctx, &mediaconvert.GetJobInput{Id: aws.String(jobID)})
+	if err != nil {
+		return Status{}, fmt.Errorf("failed to get MediaConvert job: %w", err)
+	}
+
+	switch output.Job.Status {
+	case types.JobStatusComplete:
+		return Status{State: StateComplete}, nil
+	case types.JobStatusError:
+		return Status{State: StateFailed, Message: aws.ToString(output.Job.ErrorMessage)}, nil
+	case types.JobStatusCanceled:
+		return Status{State: StateCancelled}, nil
+	default:
+		return Status{State: StateRunning}, nil
+	}
+}
+
+// Cancel cancela um job ainda na fila ou em andamento
+func (c *MediaConvertClient) Cancel(ctx context.Context, jobID string) error {
+	if _, err := c.client.CancelJob(ctx, &mediaconvert.CancelJobInput{Id: aws.String(jobID)}); err != nil {
+		return fmt.Errorf("failed to cancel MediaConvert job: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package framecapture
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/mediaconvert"
+	"github.com/aws/aws-sdk-go-v2/service/mediaconvert/types"
+)
+
+type mockMediaConvert struct {
+	input     *mediaconvert.CreateJobInput
+	status    types.JobStatus
+	message   string
+	cancelled string
+	err       error
+}
+
+func (m *mockMediaConvert) CreateJob(ctx context.Context, params *mediaconvert.CreateJobInput, optFns ...func(*mediaconvert.Options)) (*mediaconvert.CreateJobOutput, error) {
+	m.input = params
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &mediaconvert.CreateJobOutput{Job: &types.Job{Id: aws.String("1700000000000-abc")}}, nil
+}
+
+func (m *mockMediaConvert) GetJob(ctx context.Context, params *mediaconvert.GetJobInput, optFns ...func(*mediaconvert.Options)) (*mediaconvert.GetJobOutput, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &mediaconvert.GetJobOutput{Job: &types.Job{Status: m.status, ErrorMessage: aws.String(m.message)}}, nil
+}
+
+func (m *mockMediaConvert) CancelJob(ctx context.Context, params *mediaconvert.CancelJobInput, optFns ...func(*mediaconvert.Options)) (*mediaconvert.CancelJobOutput, error) {
+	m.cancelled = aws.ToString(params.Id)
+	return &mediaconvert.CancelJobOutput{}, m.err
+}
+
+func TestMediaConvertClient_Implementation(t *testing.T) {
+	// Verifica se MediaConvertClient implementa a interface FrameCaptureService
+	var _ FrameCaptureService = (*MediaConvertClient)(nil)
+}
+
+func TestMediaConvertClient_Submit(t *testing.T) {
+	mock := &mockMediaConvert{}
+	client := &MediaConvertClient{client: mock, role: "arn:aws:iam::123:role/mediaconvert", queue: "frames"}
+
+	id, err := client.Submit(context.Background(), Job{
+		Name:            "job-1",
+		InputBucket:     "staging",
+		InputKey:        "remote/job-1/input.mp4",
+		OutputBucket:    "staging",
+		OutputPrefix:    "remote/job-1/frames/",
+		RateNumerator:   1,
+		RateDenominator: 2,
+		MaxCaptures:     100,
+		AutoRotate:      true,
+	})
+	if err != nil {
+		t.Fatalf("Submit failed: %v", err)
+	}
+	if id != "1700000000000-abc" {
+		t.Errorf("Expected the MediaConvert job id, got %s", id)
+	}
+
+	input := mock.input
+	if aws.ToString(input.Role) != "arn:aws:iam::123:role/mediaconvert" || aws.ToString(input.Queue) != "frames" {
+		t.Errorf("Unexpected role %s or queue %s", aws.ToString(input.Role), aws.ToString(input.Queue))
+	}
+	source := input.Settings.Inputs[0]
+	if aws.ToString(source.FileInput) != "s3://staging/remote/job-1/input.mp4" || source.VideoSelector.Rotate != types.InputRotateAuto {
+		t.Errorf("Unexpected input %s with rotation %s", aws.ToString(source.FileInput), source.VideoSelector.Rotate)
+	}
+	group := input.Settings.OutputGroups[0]
+	if destination := aws.ToString(group.OutputGroupSettings.FileGroupSettings.Destination); destination != "s3://staging/remote/job-1/frames/frame" {
+		t.Errorf("Unexpected destination %s", destination)
+	}
+	codec := group.Outputs[0].VideoDescription.CodecSettings
+	capture := codec.FrameCaptureSettings
+	if codec.Codec != types.VideoCodecFrameCapture || aws.ToInt32(capture.FramerateNumerator) != 1 || aws.ToInt32(capture.FramerateDenominator) != 2 || aws.ToInt32(capture.MaxCaptures) != 100 {
+		t.Errorf("Expected frame capture at 1/2 fps up to 100 frames, got %s %+v", codec.Codec, capture)
+	}
+}
+
+func TestMediaConvertClient_Submit_DefaultQueue(t *testing.T) {
+	mock := &mockMediaConvert{}
+	client := &MediaConvertClient{client: mock}
+
+	if _, err := client.Submit(context.Background(), Job{RateNumerator: 1, RateDenominator: 1}); err != nil {
+		t.Fatalf("Submit failed: %v", err)
+	}
+	if mock.input.Queue != nil || mock.input.Settings.OutputGroups[0].Outputs[0].VideoDescription.CodecSettings.FrameCaptureSettings.MaxCaptures != nil {
+		t.Errorf("Expected default queue and no capture limit, got %+v", mock.input)
+	}
+	if rotate := mock.input.Settings.Inputs[0].VideoSelector.Rotate; rotate != types.InputRotateDegree0 {
+		t.Errorf("Expected rotation metadata ignored, got %s", rotate)
+	}
+}
+
+func TestMediaConvertClient_Status(t *testing.T) {
+	tests := []struct {
+		status types.JobStatus
+		want   Status
+	}{
+		{types.JobStatusSubmitted, Status{State: StateRunning}},
+		{types.JobStatusProgressing, Status{State: StateRunning}},
+		{types.JobStatusComplete, Status{State: StateComplete}},
+		{types.JobStatusCanceled, Status{State: StateCancelled}},
+		{types.JobStatusError, Status{State: StateFailed, Message: "input not found"}},
+	}
+	for _, tt := range tests {
+		client := &MediaConvertClient{client: &mockMediaConvert{status: tt.status, message: "input not found"}}
+		got, err := client.Status(context.Background(), "job")
+		if err != nil {
+			t.Fatalf("Status failed: %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("%s: expected %+v, got %+v", tt.status, tt.want, got)
+		}
+	}
+}
+
+func TestMediaConvertClient_Cancel(t *testing.T) {
+	mock := &mockMediaConvert{}
+	client := &MediaConvertClient{client: mock}
+
+	if err := client.Cancel(context.Background(), "job"); err != nil || mock.cancelled != "job" {
+		t.Errorf("Expected job cancelled, got %q and error %v", mock.cancelled, err)
+	}
+}
+
+func TestMediaConvertClient_Error(t *testing.T) {
+	client := &MediaConvertClient{client: &mockMediaConvert{err: errors.New("throttled")}}
+
+	if _, err := client.Submit(context.Background(), Job{}); err == nil {
+		t.Error("Expected error when MediaConvert fails")
+	}
+	if _, err := client.Status(context.Background(), "job"); err == nil {
+		t.Error("Expected error when MediaConvert fails")
+	}
+}
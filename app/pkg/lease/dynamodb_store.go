@@ -0,0 +1,103 @@
+package lease
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// Atributos do item do lease na tabela do DynamoDB
+const (
+	// DynamoDBKeyAttribute é a chave de partição (string) que a tabela precisa ter
+	DynamoDBKeyAttribute   = "lease_name"
+	dynamoHolderAttribute  = "holder"
+	dynamoExpiresAttribute = "expires_at"
+)
+
+// DynamoDBAPI é o subconjunto do client do DynamoDB usado pelo store
+type DynamoDBAPI interface {
+	PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error)
+	DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error)
+}
+
+// DynamoDBStore guarda o lease como um item de uma tabela do DynamoDB, gravado com escrita
+// condicional. A validade é o relógio de quem grava, em milissegundos Unix, então os relógios
+// das réplicas precisam estar sincronizados (NTP) com folga bem menor que a duração
+type DynamoDBStore struct {
+	client DynamoDBAPI
+	table  string
+	name   string
+	now    func() time.Time
+}
+
+// NewDynamoDBStore cria um store para o lease name na tabela, cuja chave de partição é
+// DynamoDBKeyAttribute
+func NewDynamoDBStore(client DynamoDBAPI, table, name string) *DynamoDBStore {
+	return &DynamoDBStore{
+		client: client,
+		table:  table,
+		name:   name,
+		now:    time.Now,
+	}
+}
+
+// Acquire grava o item com holder e a nova validade se ele não existe, já é de holder ou expirou
+func (s *DynamoDBStore) Acquire(ctx context.Context, holder string, duration time.Duration) (bool, error) {
+	now := s.now()
+	_, err := s.client.PutItem(ctx, &dynamodb.PutItemInput{
+		TableName: aws.String(s.table),
+		Item: map[string]types.AttributeValue{
+			DynamoDBKeyAttribute:   &types.AttributeValueMemberS{Value: s.name},
+			dynamoHolderAttribute:  &types.AttributeValueMemberS{Value: holder},
+			dynamoExpiresAttribute: millis(now.Add(duration)),
+		},
+		ConditionExpression: aws.String("attribute_not_exists(#key) OR #holder = :holder OR #expires < :now"),
+		ExpressionAttributeNames: map[string]string{
+			"#key":     DynamoDBKeyAttribute,
+			"#holder":  dynamoHolderAttribute,
+			"#expires": dynamoExpiresAttribute,
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":holder": &types.AttributeValueMemberS{Value: holder},
+			":now":    millis(now),
+		},
+	})
+	var conflict *types.ConditionalCheckFailedException
+	if errors.As(err, &conflict) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire DynamoDB lease: %w", err)
+	}
+	return true, nil
+}
+
+// Release apaga o item se ele ainda for de holder
+func (s *DynamoDBStore) Release(ctx context.Context, holder string) error {
+	_, err := s.client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(s.table),
+		Key: map[string]types.AttributeValue{
+			DynamoDBKeyAttribute: &types.AttributeValueMemberS{Value: s.name},
+		},
+		ConditionExpression:      aws.String("#holder = :holder"),
+		ExpressionAttributeNames: map[string]string{"#holder": dynamoHolderAttribute},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":holder": &types.AttributeValueMemberS{Value: holder},
+		},
+	})
+	var conflict *types.ConditionalCheckFailedException
+	if err != nil && !errors.As(err, &conflict) {
+		return fmt.Errorf("failed to release DynamoDB lease: %w", err)
+	}
+	return nil
+}
+
+func millis(t time.Time) types.AttributeValue {
+	return &types.AttributeValueMemberN{Value: strconv.FormatInt(t.UnixMilli(), 10)}
+}
@@ -0,0 +1,88 @@
+package lease
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// fakeDynamoDB keeps one lease item and evaluates the store's conditions on it
+type fakeDynamoDB struct {
+	holder  string
+	expires int64
+	err     error
+}
+
+func (f *fakeDynamoDB) PutItem(ctx context.Context, params *dynamodb.PutItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.PutItemOutput, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	holder := params.ExpressionAttributeValues[":holder"].(*types.AttributeValueMemberS).Value
+	now, _ := strconv.ParseInt(params.ExpressionAttributeValues[":now"].(*types.AttributeValueMemberN).Value, 10, 64)
+	if f.holder != "" && f.holder != holder && f.expires >= now {
+		return nil, &types.ConditionalCheckFailedException{Message: aws.String("held")}
+	}
+	f.holder = params.Item[dynamoHolderAttribute].(*types.AttributeValueMemberS).Value
+	f.expires, _ = strconv.ParseInt(params.Item[dynamoExpiresAttribute].(*types.AttributeValueMemberN).Value, 10, 64)
+	return &dynamodb.PutItemOutput{}, nil
+}
+
+func (f *fakeDynamoDB) DeleteItem(ctx context.Context, params *dynamodb.DeleteItemInput, optFns ...func(*dynamodb.Options)) (*dynamodb.DeleteItemOutput, error) {
+	if f.holder != params.ExpressionAttributeValues[":holder"].(*types.AttributeValueMemberS).Value {
+		return nil, &types.ConditionalCheckFailedException{Message: aws.String("not holder")}
+	}
+	f.holder, f.expires = "", 0
+	return &dynamodb.DeleteItemOutput{}, nil
+}
+
+func TestDynamoDBStore(t *testing.T) {
+	client := &fakeDynamoDB{}
+	store := NewDynamoDBStore(client, "leases", "processor")
+	clock := time.Unix(1700000000, 0)
+	store.now = func() time.Time { return clock }
+	ctx := context.Background()
+
+	steps := []struct {
+		name    string
+		holder  string
+		advance time.Duration
+		want    bool
+	}{
+		{"free", "pod-a", 0, true},
+		{"held by another", "pod-b", 5 * time.Second, false},
+		{"renewed", "pod-a", 5 * time.Second, true},
+		{"still held", "pod-b", 14 * time.Second, false},
+		{"expired", "pod-b", 2 * time.Second, true},
+	}
+	for _, step := range steps {
+		clock = clock.Add(step.advance)
+		acquired, err := store.Acquire(ctx, step.holder, 15*time.Second)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", step.name, err)
+		}
+		if acquired != step.want {
+			t.Errorf("%s: expected acquired %v, got %v", step.name, step.want, acquired)
+		}
+	}
+
+	if err := store.Release(ctx, "pod-a"); err != nil {
+		t.Errorf("Expected release by a former holder to be ignored, got %v", err)
+	}
+	if client.holder != "pod-b" {
+		t.Errorf("Expected pod-b to keep the lease, got %q", client.holder)
+	}
+	if err := store.Release(ctx, "pod-b"); err != nil || client.holder != "" {
+		t.Errorf("Expected lease released, got holder %q and error %v", client.holder, err)
+	}
+
+	client.err = errors.New("throttled")
+	if _, err := store.Acquire(ctx, "pod-a", 15*time.Second); err == nil {
+		t.Error("Expected error when DynamoDB fails, got nil")
+	}
+}
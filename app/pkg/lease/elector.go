@@ -0,0 +1,164 @@
+package lease
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Valores padrão do Elector
+const (
+	DefaultDuration = 15 * time.Second
+	// releaseTimeout limita a liberação do lease ao sair, que não tem mais o ctx do Run
+	releaseTimeout = 5 * time.Second
+)
+
+// ErrLeaseLost é retornado pelo Run quando o lease passa para outro holder ou não pôde ser
+// renovado antes de expirar
+var ErrLeaseLost = errors.New("lease lost")
+
+// Store guarda um lease nomeado, disputado pelas réplicas
+type Store interface {
+	// Acquire obtém o lease para holder por duration quando ele está livre, expirado ou já é
+	// de holder (renovação); false quando outro holder o detém
+	Acquire(ctx context.Context, holder string, duration time.Duration) (bool, error)
+
+	// Release libera o lease se ele ainda for de holder, para que outra réplica não espere
+	// a expiração
+	Release(ctx context.Context, holder string) error
+}
+
+// Config controla a disputa do lease; campos zerados usam os valores padrão
+type Config struct {
+	// Duration é a validade de cada aquisição ou renovação
+	Duration time.Duration
+	// RenewInterval é o intervalo das renovações do líder, Duration/3 por padrão
+	RenewInterval time.Duration
+	// RetryInterval é o intervalo das tentativas das réplicas em espera, Duration/3 por padrão
+	RetryInterval time.Duration
+	// OnError recebe os erros do Store, que não interrompem a disputa
+	OnError func(err error)
+}
+
+func (c Config) withDefaults() Config {
+	if c.Duration <= 0 {
+		c.Duration = DefaultDuration
+	}
+	if c.RenewInterval <= 0 || c.RenewInterval >= c.Duration {
+		c.RenewInterval = c.Duration / 3
+	}
+	if c.RetryInterval <= 0 {
+		c.RetryInterval = c.Duration / 3
+	}
+	if c.OnError == nil {
+		c.OnError = func(error) {}
+	}
+	return c
+}
+
+// Elector faz com que apenas uma réplica por vez execute uma função, enquanto detém o lease
+type Elector struct {
+	store  Store
+	holder string
+	config Config
+	now    func() time.Time
+}
+
+// NewElector cria um Elector que disputa o lease do store como holder, que deve ser único
+// entre as réplicas (o nome do pod, por exemplo)
+func NewElector(store Store, holder string, config Config) *Elector {
+	return &Elector{
+		store:  store,
+		holder: holder,
+		config: config.withDefaults(),
+		now:    time.Now,
+	}
+}
+
+// Run espera até obter o lease e então executa lead, renovando o lease enquanto lead roda.
+// O ctx de lead é cancelado quando o lease é perdido ou ctx termina; Run aguarda lead
+// retornar, libera o lease e retorna ErrLeaseLost ou o erro de ctx. Um mandato só acontece uma
+// vez: quem perde o lease deve encerrar, já que lead pode não ser reiniciável
+func (e *Elector) Run(ctx context.Context, lead func(ctx context.Context)) error {
+	renewedAt, err := e.acquire(ctx)
+	if err != nil {
+		return err
+	}
+
+	leadCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		lead(leadCtx)
+	}()
+
+	lost := e.renew(leadCtx, renewedAt, done)
+	cancel()
+	<-done
+
+	releaseCtx, cancelRelease := context.WithTimeout(context.Background(), releaseTimeout)
+	defer cancelRelease()
+	if err := e.store.Release(releaseCtx, e.holder); err != nil {
+		e.config.OnError(err)
+	}
+
+	if lost {
+		return ErrLeaseLost
+	}
+	return ctx.Err()
+}
+
+// acquire tenta obter o lease a cada RetryInterval, retornando o instante da aquisição
+func (e *Elector) acquire(ctx context.Context) (time.Time, error) {
+	for {
+		attemptedAt := e.now()
+		acquired, err := e.store.Acquire(ctx, e.holder, e.config.Duration)
+		if err != nil {
+			e.config.OnError(err)
+		} else if acquired {
+			return attemptedAt, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return time.Time{}, ctx.Err()
+		case <-time.After(e.config.RetryInterval):
+		}
+	}
+}
+
+// renew renova o lease a cada RenewInterval até ctx terminar ou lead retornar. Retorna true
+// quando o lease é perdido: outro holder o obteve, ou as renovações falharam e a próxima já
+// seria depois da validade da última. A validade conta do início da tentativa, antes do Store
+// gravá-la, para que o líder desista antes de outra réplica poder assumir
+func (e *Elector) renew(ctx context.Context, renewedAt time.Time, done <-chan struct{}) bool {
+	ticker := time.NewTicker(e.config.RenewInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-done:
+			return false
+		case <-ticker.C:
+		}
+
+		attemptedAt := e.now()
+		acquired, err := e.store.Acquire(ctx, e.holder, e.config.Duration)
+		switch {
+		case err != nil:
+			if ctx.Err() != nil {
+				return false
+			}
+			e.config.OnError(err)
+			if e.now().Add(e.config.RenewInterval).Sub(renewedAt) >= e.config.Duration {
+				return true
+			}
+		case !acquired:
+			return true
+		default:
+			renewedAt = attemptedAt
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package lease
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeStore answers Acquire with the next result of acquire, repeating the last one
+type fakeStore struct {
+	mu       sync.Mutex
+	results  []acquireResult
+	calls    int
+	released []string
+}
+
+type acquireResult struct {
+	acquired bool
+	err      error
+}
+
+func (s *fakeStore) Acquire(ctx context.Context, holder string, duration time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	result := s.results[min(s.calls, len(s.results)-1)]
+	s.calls++
+	return result.acquired, result.err
+}
+
+func (s *fakeStore) Release(ctx context.Context, holder string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.released = append(s.released, holder)
+	return nil
+}
+
+var testConfig = Config{Duration: 30 * time.Millisecond, RenewInterval: 10 * time.Millisecond, RetryInterval: 5 * time.Millisecond}
+
+func TestElector_WaitsAndLeads(t *testing.T) {
+	store := &fakeStore{results: []acquireResult{{false, nil}, {false, errors.New("throttled")}, {true, nil}}}
+	var errs []error
+	config := testConfig
+	config.OnError = func(err error) { errs = append(errs, err) }
+	elector := NewElector(store, "pod-a", config)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	led := make(chan struct{})
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}()
+	err := elector.Run(ctx, func(ctx context.Context) {
+		close(led)
+		<-ctx.Done()
+	})
+
+	select {
+	case <-led:
+	default:
+		t.Fatal("Expected lead to run after acquiring the lease")
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if len(errs) != 1 {
+		t.Errorf("Expected 1 store error reported, got %d", len(errs))
+	}
+	if len(store.released) != 1 || store.released[0] != "pod-a" {
+		t.Errorf("Expected lease released by pod-a, got %v", store.released)
+	}
+}
+
+func TestElector_Lost(t *testing.T) {
+	tests := []struct {
+		name  string
+		renew acquireResult
+	}{
+		{"taken by another holder", acquireResult{false, nil}},
+		{"renewals failing", acquireResult{false, errors.New("unavailable")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &fakeStore{results: []acquireResult{{true, nil}, tt.renew}}
+			elector := NewElector(store, "pod-a", testConfig)
+
+			stopped := false
+			err := elector.Run(context.Background(), func(ctx context.Context) {
+				<-ctx.Done()
+				stopped = true
+			})
+			if !errors.Is(err, ErrLeaseLost) {
+				t.Errorf("Expected ErrLeaseLost, got %v", err)
+			}
+			if !stopped {
+				t.Error("Expected lead to stop before Run returned")
+			}
+		})
+	}
+}
+
+func TestElector_StepsDownBeforeExpiry(t *testing.T) {
+	store := &fakeStore{results: []acquireResult{{true, nil}, {false, errors.New("unavailable")}}}
+	clock := time.Now()
+	// Each call takes 10ms of the 30ms lease: after the renewal that fails at 10ms, the next
+	// one would only start at the expiry
+	elector := NewElector(&clockStore{fakeStore: store, advance: func() { clock = clock.Add(10 * time.Millisecond) }}, "pod-a", testConfig)
+	elector.now = func() time.Time { return clock }
+
+	if err := elector.Run(context.Background(), func(ctx context.Context) { <-ctx.Done() }); !errors.Is(err, ErrLeaseLost) {
+		t.Fatalf("Expected ErrLeaseLost, got %v", err)
+	}
+	if store.calls != 2 {
+		t.Errorf("Expected to step down after 1 failed renewal, got %d attempts", store.calls)
+	}
+}
+
+// clockStore advances the test clock on each renewal attempt
+type clockStore struct {
+	*fakeStore
+	advance func()
+}
+
+func (s *clockStore) Acquire(ctx context.Context, holder string, duration time.Duration) (bool, error) {
+	acquired, err := s.fakeStore.Acquire(ctx, holder, duration)
+	s.advance()
+	return acquired, err
+}
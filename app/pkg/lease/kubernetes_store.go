@@ -0,0 +1,244 @@
+package lease
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// Arquivos da service account montados nos pods
+const (
+	serviceAccountDir       = "/var/run/secrets/kubernetes.io/serviceaccount"
+	serviceAccountToken     = serviceAccountDir + "/token"
+	serviceAccountCA        = serviceAccountDir + "/ca.crt"
+	serviceAccountNamespace = serviceAccountDir + "/namespace"
+)
+
+// microTimeFormat é o formato dos campos MicroTime da API do Kubernetes
+const microTimeFormat = "2006-01-02T15:04:05.000000Z07:00"
+
+// KubernetesStore guarda o lease como um objeto Lease (coordination.k8s.io/v1), o mesmo
+// usado pelo client-go, atualizado com o resourceVersion lido para que duas réplicas não o
+// obtenham ao mesmo tempo. A service account precisa de get, create e update em leases
+type KubernetesStore struct {
+	client    *http.Client
+	url       string
+	tokenPath string
+	namespace string
+	name      string
+	now       func() time.Time
+}
+
+// NewKubernetesStore cria um store para o Lease name do namespace na API em apiURL. O token
+// é relido de tokenPath a cada requisição, já que os tokens projetados são renovados; sem
+// tokenPath as requisições vão sem autenticação
+func NewKubernetesStore(client *http.Client, apiURL, tokenPath, namespace, name string) *KubernetesStore {
+	return &KubernetesStore{
+		client:    client,
+		url:       strings.TrimRight(apiURL, "/") + "/apis/coordination.k8s.io/v1/namespaces/" + namespace + "/leases",
+		tokenPath: tokenPath,
+		namespace: namespace,
+		name:      name,
+		now:       time.Now,
+	}
+}
+
+// NewInClusterKubernetesStore cria um store com a service account do pod, no namespace do pod
+func NewInClusterKubernetesStore(name string) (*KubernetesStore, error) {
+	host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || port == "" {
+		return nil, fmt.Errorf("not running in a Kubernetes cluster")
+	}
+	namespace, err := os.ReadFile(serviceAccountNamespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pod namespace: %w", err)
+	}
+	ca, err := os.ReadFile(serviceAccountCA)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cluster CA: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, fmt.Errorf("invalid cluster CA")
+	}
+
+	client := &http.Client{
+		Timeout:   10 * time.Second,
+		Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}},
+	}
+	apiURL := "https://" + net.JoinHostPort(host, port)
+	return NewKubernetesStore(client, apiURL, serviceAccountToken, strings.TrimSpace(string(namespace)), name), nil
+}
+
+type kubernetesLease struct {
+	APIVersion string              `json:"apiVersion"`
+	Kind       string              `json:"kind"`
+	Metadata   kubernetesLeaseMeta `json:"metadata"`
+	Spec       kubernetesLeaseSpec `json:"spec"`
+}
+
+type kubernetesLeaseMeta struct {
+	Name            string `json:"name"`
+	Namespace       string `json:"namespace,omitempty"`
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+}
+
+type kubernetesLeaseSpec struct {
+	HolderIdentity       *string `json:"holderIdentity,omitempty"`
+	LeaseDurationSeconds *int32  `json:"leaseDurationSeconds,omitempty"`
+	AcquireTime          *string `json:"acquireTime,omitempty"`
+	RenewTime            *string `json:"renewTime,omitempty"`
+	LeaseTransitions     *int32  `json:"leaseTransitions,omitempty"`
+}
+
+// Acquire lê o Lease e o atualiza para holder quando ele está livre, expirado ou já é de
+// holder, criando-o se não existe. Um conflito de escrita (outra réplica gravou antes) é false
+func (s *KubernetesStore) Acquire(ctx context.Context, holder string, duration time.Duration) (bool, error) {
+	now := s.now()
+	lease, found, err := s.get(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	if !found {
+		lease = &kubernetesLease{Metadata: kubernetesLeaseMeta{Name: s.name, Namespace: s.namespace}}
+	} else if current := lease.holder(); current != "" && current != holder && !lease.expired(now) {
+		return false, nil
+	}
+
+	if lease.holder() != holder {
+		transitions := int32(0)
+		if lease.Spec.LeaseTransitions != nil {
+			transitions = *lease.Spec.LeaseTransitions
+		}
+		if found && lease.holder() != "" {
+			transitions++
+		}
+		acquireTime := now.UTC().Format(microTimeFormat)
+		lease.Spec.HolderIdentity = &holder
+		lease.Spec.AcquireTime = &acquireTime
+		lease.Spec.LeaseTransitions = &transitions
+	}
+	renewTime := now.UTC().Format(microTimeFormat)
+	seconds := int32(math.Ceil(duration.Seconds()))
+	lease.Spec.RenewTime = &renewTime
+	lease.Spec.LeaseDurationSeconds = &seconds
+
+	if found {
+		return s.write(ctx, http.MethodPut, s.url+"/"+s.name, lease)
+	}
+	return s.write(ctx, http.MethodPost, s.url, lease)
+}
+
+// Release esvazia o holder do Lease se ele ainda for de holder
+func (s *KubernetesStore) Release(ctx context.Context, holder string) error {
+	lease, found, err := s.get(ctx)
+	if err != nil || !found || lease.holder() != holder {
+		return err
+	}
+	lease.Spec.HolderIdentity = nil
+	_, err = s.write(ctx, http.MethodPut, s.url+"/"+s.name, lease)
+	return err
+}
+
+func (l *kubernetesLease) holder() string {
+	if l.Spec.HolderIdentity == nil {
+		return ""
+	}
+	return *l.Spec.HolderIdentity
+}
+
+// expired é true quando a última renovação mais a duração já passou; um Lease sem esses
+// campos nunca foi renovado e está livre
+func (l *kubernetesLease) expired(now time.Time) bool {
+	if l.Spec.RenewTime == nil || l.Spec.LeaseDurationSeconds == nil {
+		return true
+	}
+	renewed, err := time.Parse(time.RFC3339Nano, *l.Spec.RenewTime)
+	if err != nil {
+		return true
+	}
+	return now.After(renewed.Add(time.Duration(*l.Spec.LeaseDurationSeconds) * time.Second))
+}
+
+// get lê o Lease; found é false quando ele ainda não existe
+func (s *KubernetesStore) get(ctx context.Context) (*kubernetesLease, bool, error) {
+	resp, err := s.do(ctx, http.MethodGet, s.url+"/"+s.name, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, false, statusError(resp)
+	}
+	var lease kubernetesLease
+	if err := json.NewDecoder(resp.Body).Decode(&lease); err != nil {
+		return nil, false, fmt.Errorf("failed to decode Kubernetes lease: %w", err)
+	}
+	return &lease, true, nil
+}
+
+// write grava o Lease; 409 significa que outra réplica o criou ou alterou depois da leitura
+func (s *KubernetesStore) write(ctx context.Context, method, url string, lease *kubernetesLease) (bool, error) {
+	lease.APIVersion, lease.Kind = "coordination.k8s.io/v1", "Lease"
+	body, err := json.Marshal(lease)
+	if err != nil {
+		return false, fmt.Errorf("failed to encode Kubernetes lease: %w", err)
+	}
+	resp, err := s.do(ctx, method, url, body)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusConflict:
+		return false, nil
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		return false, statusError(resp)
+	}
+	return true, nil
+}
+
+func (s *KubernetesStore) do(ctx context.Context, method, url string, body []byte) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build Kubernetes request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if s.tokenPath != "" {
+		token, err := os.ReadFile(s.tokenPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read service account token: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call Kubernetes API: %w", err)
+	}
+	return resp, nil
+}
+
+func statusError(resp *http.Response) error {
+	message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	return fmt.Errorf("kubernetes API returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+}
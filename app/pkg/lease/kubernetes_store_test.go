@@ -0,0 +1,142 @@
+package lease
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeLeaseAPI serves one Lease, rejecting writes with a stale resourceVersion like the API server
+type fakeLeaseAPI struct {
+	mu      sync.Mutex
+	lease   *kubernetesLease
+	version int
+	token   string
+}
+
+func (f *fakeLeaseAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.token = r.Header.Get("Authorization")
+
+	const path = "/apis/coordination.k8s.io/v1/namespaces/processor/leases"
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == path+"/worker":
+		if f.lease == nil {
+			http.Error(w, "not found", http.StatusNotFound)
+			return
+		}
+		json.NewEncoder(w).Encode(f.lease)
+	case (r.Method == http.MethodPost && r.URL.Path == path) || (r.Method == http.MethodPut && r.URL.Path == path+"/worker"):
+		var lease kubernetesLease
+		json.NewDecoder(r.Body).Decode(&lease)
+		if (r.Method == http.MethodPost && f.lease != nil) || (r.Method == http.MethodPut && lease.Metadata.ResourceVersion != strconv.Itoa(f.version)) {
+			http.Error(w, "conflict", http.StatusConflict)
+			return
+		}
+		f.version++
+		lease.Metadata.ResourceVersion = strconv.Itoa(f.version)
+		f.lease = &lease
+		json.NewEncoder(w).Encode(f.lease)
+	default:
+		http.Error(w, "unexpected request", http.StatusBadRequest)
+	}
+}
+
+func TestKubernetesStore(t *testing.T) {
+	api := &fakeLeaseAPI{}
+	server := httptest.NewServer(api)
+	defer server.Close()
+
+	tokenPath := filepath.Join(t.TempDir(), "token")
+	os.WriteFile(tokenPath, []byte("secret\n"), 0600)
+	store := NewKubernetesStore(server.Client(), server.URL, tokenPath, "processor", "worker")
+	clock := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return clock }
+	ctx := context.Background()
+
+	steps := []struct {
+		name    string
+		holder  string
+		advance time.Duration
+		want    bool
+	}{
+		{"created", "pod-a", 0, true},
+		{"held by another", "pod-b", 5 * time.Second, false},
+		{"renewed", "pod-a", 5 * time.Second, true},
+		{"expired", "pod-b", 16 * time.Second, true},
+	}
+	for _, step := range steps {
+		clock = clock.Add(step.advance)
+		acquired, err := store.Acquire(ctx, step.holder, 15*time.Second)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", step.name, err)
+		}
+		if acquired != step.want {
+			t.Errorf("%s: expected acquired %v, got %v", step.name, step.want, acquired)
+		}
+	}
+
+	if api.token != "Bearer secret" {
+		t.Errorf("Expected bearer token from the token file, got %q", api.token)
+	}
+	spec := api.lease.Spec
+	if *spec.HolderIdentity != "pod-b" || *spec.LeaseDurationSeconds != 15 || *spec.LeaseTransitions != 1 {
+		t.Errorf("Expected lease of pod-b for 15s after 1 transition, got %+v", spec)
+	}
+	if *spec.RenewTime != "2026-01-01T00:00:26.000000Z" {
+		t.Errorf("Expected renew time in MicroTime format, got %s", *spec.RenewTime)
+	}
+
+	if err := store.Release(ctx, "pod-a"); err != nil || api.lease.Spec.HolderIdentity == nil {
+		t.Errorf("Expected release by a former holder to be ignored, got error %v", err)
+	}
+	if err := store.Release(ctx, "pod-b"); err != nil || api.lease.Spec.HolderIdentity != nil {
+		t.Errorf("Expected lease released, got error %v", err)
+	}
+	if acquired, _ := store.Acquire(ctx, "pod-a", 15*time.Second); !acquired {
+		t.Error("Expected released lease to be acquired at once")
+	}
+}
+
+func TestKubernetesStore_Conflict(t *testing.T) {
+	api := &fakeLeaseAPI{}
+	server := httptest.NewServer(api)
+	defer server.Close()
+	store := NewKubernetesStore(server.Client(), server.URL, "", "processor", "worker")
+	ctx := context.Background()
+
+	if acquired, err := store.Acquire(ctx, "pod-a", 15*time.Second); !acquired || err != nil {
+		t.Fatalf("Expected lease acquired, got %v, %v", acquired, err)
+	}
+	// Another replica writes between the read and the write of the renewal
+	store.client = &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		if r.Method == http.MethodPut {
+			api.mu.Lock()
+			api.version++
+			api.mu.Unlock()
+		}
+		return http.DefaultTransport.RoundTrip(r)
+	})}
+
+	acquired, err := store.Acquire(ctx, "pod-a", 15*time.Second)
+	if err != nil {
+		t.Fatalf("Expected conflict to be reported as not acquired, got %v", err)
+	}
+	if acquired {
+		t.Error("Expected renewal with a stale resourceVersion to fail")
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
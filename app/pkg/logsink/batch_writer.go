@@ -0,0 +1,205 @@
+package logsink
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Valores padrão do BatchWriter
+const (
+	DefaultBatchSize     = 500
+	DefaultFlushInterval = 5 * time.Second
+	DefaultMaxBuffered   = 10000
+	DefaultMaxRetries    = 3
+	DefaultRetryDelay    = time.Second
+	// pushTimeout limita cada envio, para que um destino travado não segure o Sync
+	pushTimeout = 10 * time.Second
+)
+
+// Entry é uma linha de log com o instante em que foi escrita
+type Entry struct {
+	Time time.Time
+	Line string
+}
+
+// Sink recebe lotes de linhas de log em ordem cronológica
+type Sink interface {
+	Push(ctx context.Context, entries []Entry) error
+}
+
+// Config controla o agrupamento e as novas tentativas do BatchWriter; campos zerados usam os
+// valores padrão
+type Config struct {
+	// BatchSize é quantas linhas disparam um envio antes do FlushInterval
+	BatchSize     int
+	FlushInterval time.Duration
+	// MaxBuffered é quantas linhas aguardam envio; acima disso as mais antigas são descartadas,
+	// para que um destino fora do ar não esgote a memória do worker
+	MaxBuffered int
+	MaxRetries  int
+	// RetryDelay é a espera antes da primeira nova tentativa, dobrada a cada uma
+	RetryDelay time.Duration
+}
+
+func (c Config) withDefaults() Config {
+	if c.BatchSize <= 0 {
+		c.BatchSize = DefaultBatchSize
+	}
+	if c.FlushInterval <= 0 {
+		c.FlushInterval = DefaultFlushInterval
+	}
+	if c.MaxBuffered <= 0 {
+		c.MaxBuffered = DefaultMaxBuffered
+	}
+	if c.MaxBuffered < c.BatchSize {
+		c.MaxBuffered = c.BatchSize
+	}
+	if c.MaxRetries < 0 {
+		c.MaxRetries = 0
+	} else if c.MaxRetries == 0 {
+		c.MaxRetries = DefaultMaxRetries
+	}
+	if c.RetryDelay <= 0 {
+		c.RetryDelay = DefaultRetryDelay
+	}
+	return c
+}
+
+// BatchWriter implementa zapcore.WriteSyncer acumulando as linhas escritas e enviando-as ao
+// Sink em lotes, a cada BatchSize linhas ou FlushInterval. Um lote que falha é reenviado com
+// backoff exponencial e descartado após MaxRetries tentativas, com um aviso em stderr, já que
+// o próprio log não pode relatar a falha do seu destino
+type BatchWriter struct {
+	sink   Sink
+	config Config
+	// errorOutput recebe os avisos de lotes descartados
+	errorOutput io.Writer
+
+	mu      sync.Mutex
+	pending []Entry
+	dropped int
+
+	// pushMu serializa os envios, mantendo os lotes em ordem
+	pushMu sync.Mutex
+	wake   chan struct{}
+	stop   chan struct{}
+	done   chan struct{}
+	once   sync.Once
+}
+
+// NewBatchWriter cria o writer e inicia o envio periódico; chame Close para parar
+func NewBatchWriter(sink Sink, config Config) *BatchWriter {
+	w := &BatchWriter{
+		sink:        sink,
+		config:      config.withDefaults(),
+		errorOutput: os.Stderr,
+		wake:        make(chan struct{}, 1),
+		stop:        make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+// Write guarda uma linha de log; o encoder do zap escreve uma entrada por chamada
+func (w *BatchWriter) Write(p []byte) (int, error) {
+	line := string(bytes.TrimRight(p, "\n"))
+	if line == "" {
+		return len(p), nil
+	}
+
+	w.mu.Lock()
+	w.pending = append(w.pending, Entry{Time: time.Now(), Line: line})
+	if excess := len(w.pending) - w.config.MaxBuffered; excess > 0 {
+		w.pending = append(w.pending[:0], w.pending[excess:]...)
+		w.dropped += excess
+	}
+	full := len(w.pending) >= w.config.BatchSize
+	w.mu.Unlock()
+
+	if full {
+		select {
+		case w.wake <- struct{}{}:
+		default:
+		}
+	}
+	return len(p), nil
+}
+
+// Sync envia tudo o que está pendente antes de retornar
+func (w *BatchWriter) Sync() error {
+	return w.flush()
+}
+
+// Close para o envio periódico e envia o que está pendente
+func (w *BatchWriter) Close() error {
+	w.once.Do(func() { close(w.stop) })
+	<-w.done
+	return w.flush()
+}
+
+func (w *BatchWriter) run() {
+	defer close(w.done)
+	ticker := time.NewTicker(w.config.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+		case <-w.wake:
+		}
+		w.flush()
+	}
+}
+
+// flush envia as linhas pendentes em lotes de até BatchSize
+func (w *BatchWriter) flush() error {
+	w.pushMu.Lock()
+	defer w.pushMu.Unlock()
+
+	var lastErr error
+	for {
+		w.mu.Lock()
+		if dropped := w.dropped; dropped > 0 {
+			w.dropped = 0
+			fmt.Fprintf(w.errorOutput, "logsink: dropped %d log lines over the buffer limit\n", dropped)
+		}
+		count := min(len(w.pending), w.config.BatchSize)
+		batch := append([]Entry(nil), w.pending[:count]...)
+		w.pending = w.pending[count:]
+		w.mu.Unlock()
+
+		if len(batch) == 0 {
+			return lastErr
+		}
+		if err := w.push(batch); err != nil {
+			fmt.Fprintf(w.errorOutput, "logsink: dropped %d log lines: %v\n", len(batch), err)
+			lastErr = err
+		}
+	}
+}
+
+// push envia o lote, tentando de novo até MaxRetries vezes
+func (w *BatchWriter) push(batch []Entry) error {
+	delay := w.config.RetryDelay
+	var err error
+	for attempt := 0; attempt <= w.config.MaxRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(delay)
+			delay *= 2
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), pushTimeout)
+		err = w.sink.Push(ctx, batch)
+		cancel()
+		if err == nil {
+			return nil
+		}
+	}
+	return err
+}
@@ -0,0 +1,140 @@
+package logsink
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordingSink struct {
+	mu      sync.Mutex
+	batches [][]Entry
+	fail    int
+}
+
+func (s *recordingSink) Push(ctx context.Context, entries []Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fail > 0 {
+		s.fail--
+		return errors.New("unavailable")
+	}
+	s.batches = append(s.batches, entries)
+	return nil
+}
+
+func (s *recordingSink) lines() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var lines []string
+	for _, batch := range s.batches {
+		for _, entry := range batch {
+			lines = append(lines, entry.Line)
+		}
+	}
+	return lines
+}
+
+func TestBatchWriter_SyncSendsInBatches(t *testing.T) {
+	sink := &recordingSink{}
+	writer := NewBatchWriter(sink, Config{BatchSize: 2, FlushInterval: time.Hour})
+	defer writer.Close()
+
+	for _, line := range []string{"a\n", "b\n", "c\n"} {
+		writer.Write([]byte(line))
+	}
+	if err := writer.Sync(); err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+
+	if got := strings.Join(sink.lines(), ","); got != "a,b,c" {
+		t.Errorf("Expected the lines in order, got %s", got)
+	}
+	for _, batch := range sink.batches {
+		if len(batch) > 2 {
+			t.Errorf("Expected batches of at most 2 lines, got %d", len(batch))
+		}
+	}
+}
+
+func TestBatchWriter_FlushesFullBatch(t *testing.T) {
+	sink := &recordingSink{}
+	writer := NewBatchWriter(sink, Config{BatchSize: 2, FlushInterval: time.Hour})
+	defer writer.Close()
+
+	writer.Write([]byte("a\n"))
+	writer.Write([]byte("b\n"))
+
+	// Um lote cheio é enviado sem esperar o FlushInterval
+	deadline := time.Now().Add(2 * time.Second)
+	for len(sink.lines()) < 2 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if len(sink.lines()) != 2 {
+		t.Errorf("Expected the full batch sent, got %v", sink.lines())
+	}
+}
+
+func TestBatchWriter_Retries(t *testing.T) {
+	sink := &recordingSink{fail: 2}
+	writer := NewBatchWriter(sink, Config{FlushInterval: time.Hour, MaxRetries: 2, RetryDelay: time.Millisecond})
+	defer writer.Close()
+
+	writer.Write([]byte("a\n"))
+	if err := writer.Sync(); err != nil {
+		t.Fatalf("Expected the batch sent on the third attempt, got %v", err)
+	}
+	if len(sink.lines()) != 1 {
+		t.Errorf("Expected 1 line sent, got %v", sink.lines())
+	}
+}
+
+func TestBatchWriter_DropsAfterRetries(t *testing.T) {
+	sink := &recordingSink{fail: 10}
+	var stderr bytes.Buffer
+	writer := NewBatchWriter(sink, Config{FlushInterval: time.Hour, MaxRetries: 1, RetryDelay: time.Millisecond})
+	writer.errorOutput = &stderr
+	defer writer.Close()
+
+	writer.Write([]byte("a\n"))
+	if err := writer.Sync(); err == nil {
+		t.Error("Expected the push error")
+	}
+	if !strings.Contains(stderr.String(), "dropped 1 log lines") {
+		t.Errorf("Expected a warning about the dropped lines, got %q", stderr.String())
+	}
+
+	// O lote descartado não volta no próximo envio
+	sink.fail = 0
+	writer.Write([]byte("b\n"))
+	writer.Sync()
+	if got := strings.Join(sink.lines(), ","); got != "b" {
+		t.Errorf("Expected only the new line, got %s", got)
+	}
+}
+
+func TestBatchWriter_BufferLimit(t *testing.T) {
+	sink := &recordingSink{}
+	var stderr bytes.Buffer
+	writer := NewBatchWriter(sink, Config{BatchSize: 10, MaxBuffered: 10, FlushInterval: time.Hour})
+	writer.errorOutput = &stderr
+	// Para o envio periódico, para que as linhas fiquem acumuladas
+	writer.once.Do(func() { close(writer.stop) })
+	<-writer.done
+
+	for i := 0; i < 12; i++ {
+		writer.Write([]byte{byte('a' + i), '\n'})
+	}
+	writer.Sync()
+
+	if got := strings.Join(sink.lines(), ""); got != "cdefghijkl" {
+		t.Errorf("Expected the oldest lines dropped, got %s", got)
+	}
+	if !strings.Contains(stderr.String(), "dropped 2 log lines over the buffer limit") {
+		t.Errorf("Expected a warning about the buffer limit, got %q", stderr.String())
+	}
+}
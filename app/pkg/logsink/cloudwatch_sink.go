@@ -0,0 +1,84 @@
+package logsink
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+)
+
+// cloudWatchMaxEventSize é o tamanho máximo de um evento do CloudWatch Logs, descontados os
+// 26 bytes que o serviço soma a cada evento
+const cloudWatchMaxEventSize = 256*1024 - 26
+
+// CloudWatchAPI é o subconjunto do client do CloudWatch Logs usado pelo sink
+type CloudWatchAPI interface {
+	PutLogEvents(ctx context.Context, params *cloudwatchlogs.PutLogEventsInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.PutLogEventsOutput, error)
+	CreateLogStream(ctx context.Context, params *cloudwatchlogs.CreateLogStreamInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.CreateLogStreamOutput, error)
+}
+
+// CloudWatchSink envia as linhas de log a um stream do CloudWatch Logs. O grupo precisa
+// existir; o stream é criado no primeiro envio que não o encontra
+type CloudWatchSink struct {
+	client CloudWatchAPI
+	group  string
+	stream string
+}
+
+// NewCloudWatchSink cria um sink para o stream do grupo
+func NewCloudWatchSink(client CloudWatchAPI, group, stream string) *CloudWatchSink {
+	return &CloudWatchSink{
+		client: client,
+		group:  group,
+		stream: stream,
+	}
+}
+
+// Push envia o lote como eventos do stream
+func (s *CloudWatchSink) Push(ctx context.Context, entries []Entry) error {
+	events := make([]types.InputLogEvent, 0, len(entries))
+	for _, entry := range entries {
+		line := entry.Line
+		if len(line) > cloudWatchMaxEventSize {
+			line = line[:cloudWatchMaxEventSize]
+		}
+		events = append(events, types.InputLogEvent{
+			Message:   aws.String(line),
+			Timestamp: aws.Int64(entry.Time.UnixMilli()),
+		})
+	}
+
+	input := &cloudwatchlogs.PutLogEventsInput{
+		LogGroupName:  aws.String(s.group),
+		LogStreamName: aws.String(s.stream),
+		LogEvents:     events,
+	}
+	_, err := s.client.PutLogEvents(ctx, input)
+	var notFound *types.ResourceNotFoundException
+	if errors.As(err, &notFound) {
+		if err := s.createStream(ctx); err != nil {
+			return err
+		}
+		_, err = s.client.PutLogEvents(ctx, input)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to put log events to CloudWatch: %w", err)
+	}
+	return nil
+}
+
+// createStream cria o stream, aceitando que outro processo o tenha criado antes
+func (s *CloudWatchSink) createStream(ctx context.Context) error {
+	_, err := s.client.CreateLogStream(ctx, &cloudwatchlogs.CreateLogStreamInput{
+		LogGroupName:  aws.String(s.group),
+		LogStreamName: aws.String(s.stream),
+	})
+	var exists *types.ResourceAlreadyExistsException
+	if err != nil && !errors.As(err, &exists) {
+		return fmt.Errorf("failed to create CloudWatch log stream: %w", err)
+	}
+	return nil
+}
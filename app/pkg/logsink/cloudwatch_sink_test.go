@@ -0,0 +1,46 @@
+package logsink
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+)
+
+type mockCloudWatch struct {
+	streams map[string]bool
+	events  []types.InputLogEvent
+}
+
+func (m *mockCloudWatch) PutLogEvents(ctx context.Context, params *cloudwatchlogs.PutLogEventsInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.PutLogEventsOutput, error) {
+	if !m.streams[aws.ToString(params.LogStreamName)] {
+		return nil, &types.ResourceNotFoundException{Message: aws.String("The specified log stream does not exist.")}
+	}
+	m.events = append(m.events, params.LogEvents...)
+	return &cloudwatchlogs.PutLogEventsOutput{}, nil
+}
+
+func (m *mockCloudWatch) CreateLogStream(ctx context.Context, params *cloudwatchlogs.CreateLogStreamInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.CreateLogStreamOutput, error) {
+	m.streams[aws.ToString(params.LogStreamName)] = true
+	return &cloudwatchlogs.CreateLogStreamOutput{}, nil
+}
+
+func TestCloudWatchSink_CreatesStream(t *testing.T) {
+	client := &mockCloudWatch{streams: map[string]bool{}}
+	sink := NewCloudWatchSink(client, "/video-processor", "worker-1")
+	at := time.UnixMilli(1700000000000)
+
+	if err := sink.Push(context.Background(), []Entry{{Time: at, Line: `{"msg":"started"}`}}); err != nil {
+		t.Fatalf("Push failed: %v", err)
+	}
+
+	if !client.streams["worker-1"] {
+		t.Error("Expected the missing stream to be created")
+	}
+	if len(client.events) != 1 || aws.ToString(client.events[0].Message) != `{"msg":"started"}` || aws.ToInt64(client.events[0].Timestamp) != at.UnixMilli() {
+		t.Errorf("Unexpected events %+v", client.events)
+	}
+}
@@ -0,0 +1,66 @@
+package logsink
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestLokiSink_Push(t *testing.T) {
+	var received lokiPush
+	var tenant string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != LokiPushPath {
+			t.Errorf("Expected the push path, got %s", r.URL.Path)
+		}
+		tenant = r.Header.Get("X-Scope-OrgID")
+		json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	sink := NewLokiSink(server.Client(), server.URL+"/", map[string]string{"job": "video-processor"}, "acme")
+	at := time.Unix(1700000000, 5)
+	if err := sink.Push(context.Background(), []Entry{{Time: at, Line: `{"msg":"started"}`}}); err != nil {
+		t.Fatalf("Push failed: %v", err)
+	}
+
+	if tenant != "acme" {
+		t.Errorf("Expected tenant acme, got %q", tenant)
+	}
+	if len(received.Streams) != 1 || received.Streams[0].Stream["job"] != "video-processor" {
+		t.Fatalf("Unexpected streams %+v", received.Streams)
+	}
+	if values := received.Streams[0].Values; len(values) != 1 || values[0][0] != "1700000000000000005" || values[0][1] != `{"msg":"started"}` {
+		t.Errorf("Unexpected values %v", values)
+	}
+}
+
+func TestLokiSink_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "rate limited", http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	sink := NewLokiSink(server.Client(), server.URL, nil, "")
+	if err := sink.Push(context.Background(), []Entry{{Time: time.Now(), Line: "x"}}); err == nil {
+		t.Error("Expected error for status 429")
+	}
+}
+
+func TestParseLabels(t *testing.T) {
+	labels, err := ParseLabels("job=video-processor, env = prod")
+	if err != nil {
+		t.Fatalf("ParseLabels failed: %v", err)
+	}
+	if len(labels) != 2 || labels["job"] != "video-processor" || labels["env"] != "prod" {
+		t.Errorf("Unexpected labels %v", labels)
+	}
+
+	if _, err := ParseLabels("job"); err == nil {
+		t.Error("Expected error for a label without value")
+	}
+}
@@ -0,0 +1,101 @@
+package message
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// MemoryMessage é uma mensagem guardada pela MemoryQueue
+type MemoryMessage struct {
+	ID         string
+	Body       string
+	Attributes map[string]string
+	// Attempt conta as entregas da mensagem, começando em 1
+	Attempt int
+	SentAt  time.Time
+}
+
+// MemoryQueue implementa a interface MessageService com filas em memória, para rodar o worker
+// sem nuvem; o queueURL recebido pelos métodos é o nome da fila. As mensagens se perdem quando
+// o processo termina
+type MemoryQueue struct {
+	mu       sync.Mutex
+	queues   map[string][]MemoryMessage
+	notify   map[string]chan struct{}
+	sequence int
+}
+
+// NewMemoryQueue cria uma nova instância da MemoryQueue; as filas são criadas sob demanda
+func NewMemoryQueue() *MemoryQueue {
+	return &MemoryQueue{
+		queues: make(map[string][]MemoryMessage),
+		notify: make(map[string]chan struct{}),
+	}
+}
+
+// SendMessage enfileira uma mensagem
+func (q *MemoryQueue) SendMessage(ctx context.Context, queue string, messageBody string) (string, error) {
+	return q.SendMessageWithAttributes(ctx, queue, messageBody, nil)
+}
+
+// SendMessageWithAttributes enfileira uma mensagem com atributos e retorna seu ID sequencial
+func (q *MemoryQueue) SendMessageWithAttributes(ctx context.Context, queue string, messageBody string, attributes map[string]string) (string, error) {
+	q.mu.Lock()
+	q.sequence++
+	id := strconv.Itoa(q.sequence)
+	q.mu.Unlock()
+
+	q.Requeue(queue, MemoryMessage{
+		ID:         id,
+		Body:       messageBody,
+		Attributes: attributes,
+		SentAt:     time.Now(),
+	})
+	return id, nil
+}
+
+// Requeue devolve uma mensagem recebida ao fim da fila, para ser entregue de novo
+func (q *MemoryQueue) Requeue(queue string, msg MemoryMessage) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.queues[queue] = append(q.queues[queue], msg)
+	if notify, ok := q.notify[queue]; ok {
+		close(notify)
+		delete(q.notify, queue)
+	}
+}
+
+// Len retorna quantas mensagens aguardam na fila
+func (q *MemoryQueue) Len(queue string) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.queues[queue])
+}
+
+// Receive remove e retorna a primeira mensagem da fila, aguardando uma chegar ou ctx terminar
+func (q *MemoryQueue) Receive(ctx context.Context, queue string) (MemoryMessage, error) {
+	for {
+		q.mu.Lock()
+		if messages := q.queues[queue]; len(messages) > 0 {
+			msg := messages[0]
+			q.queues[queue] = messages[1:]
+			q.mu.Unlock()
+			msg.Attempt++
+			return msg, nil
+		}
+		notify, ok := q.notify[queue]
+		if !ok {
+			notify = make(chan struct{})
+			q.notify[queue] = notify
+		}
+		q.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return MemoryMessage{}, ctx.Err()
+		case <-notify:
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package message
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMemoryQueue_Implementation(t *testing.T) {
+	// Verifica se MemoryQueue implementa a interface MessageService
+	var _ MessageService = (*MemoryQueue)(nil)
+}
+
+func TestMemoryQueue_SendAndReceive(t *testing.T) {
+	queue := NewMemoryQueue()
+	ctx := context.Background()
+
+	first, _ := queue.SendMessageWithAttributes(ctx, "input", "a", map[string]string{"type": "video.process"})
+	second, _ := queue.SendMessage(ctx, "input", "b")
+	queue.SendMessage(ctx, "results", "c")
+	if queue.Len("input") != 2 || queue.Len("missing") != 0 {
+		t.Errorf("Expected 2 waiting messages, got %d", queue.Len("input"))
+	}
+	if first == second {
+		t.Errorf("Expected distinct message IDs, got %s twice", first)
+	}
+
+	msg, err := queue.Receive(ctx, "input")
+	if err != nil {
+		t.Fatalf("Receive failed: %v", err)
+	}
+	if msg.ID != first || msg.Body != "a" || msg.Attributes["type"] != "video.process" || msg.Attempt != 1 || msg.SentAt.IsZero() {
+		t.Errorf("Unexpected message %+v", msg)
+	}
+
+	// Uma mensagem devolvida volta depois das que já esperavam, com a tentativa contada
+	queue.Requeue("input", msg)
+	if next, _ := queue.Receive(ctx, "input"); next.Body != "b" {
+		t.Errorf("Expected the second message, got %+v", next)
+	}
+	if again, _ := queue.Receive(ctx, "input"); again.ID != first || again.Attempt != 2 {
+		t.Errorf("Expected the requeued message on its second attempt, got %+v", again)
+	}
+}
+
+func TestMemoryQueue_ReceiveWaits(t *testing.T) {
+	queue := NewMemoryQueue()
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		queue.SendMessage(context.Background(), "input", "late")
+	}()
+	msg, err := queue.Receive(context.Background(), "input")
+	if err != nil || msg.Body != "late" {
+		t.Errorf("Expected the message sent while waiting, got %+v (%v)", msg, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := queue.Receive(ctx, "input"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the context error on an empty queue, got %v", err)
+	}
+}
@@ -4,4 +4,6 @@ import "context"
 
 type MessageService interface {
 	SendMessage(ctx context.Context, queueURL string, messageBody string) (string, error)
+
+	SendMessageWithAttributes(ctx context.Context, queueURL string, messageBody string, attributes map[string]string) (string, error)
 }
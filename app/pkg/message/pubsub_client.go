@@ -0,0 +1,69 @@
+package message
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"cloud.google.com/go/pubsub/v2"
+)
+
+// PubSubClient implementa a interface MessageService publicando em tópicos do Google Pub/Sub;
+// o queueURL recebido pelos métodos é o ID do tópico ou o nome completo
+// (projects/{projeto}/topics/{tópico})
+type PubSubClient struct {
+	client *pubsub.Client
+
+	mu         sync.Mutex
+	publishers map[string]*pubsub.Publisher
+}
+
+// NewPubSubClient cria uma nova instância do PubSubClient sobre um cliente já conectado
+func NewPubSubClient(client *pubsub.Client) *PubSubClient {
+	return &PubSubClient{
+		client:     client,
+		publishers: make(map[string]*pubsub.Publisher),
+	}
+}
+
+// SendMessage publica uma mensagem no tópico
+func (p *PubSubClient) SendMessage(ctx context.Context, topic string, messageBody string) (string, error) {
+	return p.SendMessageWithAttributes(ctx, topic, messageBody, nil)
+}
+
+// SendMessageWithAttributes publica uma mensagem no tópico com os atributos e aguarda a
+// confirmação do Pub/Sub
+func (p *PubSubClient) SendMessageWithAttributes(ctx context.Context, topic string, messageBody string, attributes map[string]string) (string, error) {
+	result := p.publisher(topic).Publish(ctx, &pubsub.Message{
+		Data:       []byte(messageBody),
+		Attributes: attributes,
+	})
+
+	messageID, err := result.Get(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to publish message to Pub/Sub: %w", err)
+	}
+	return messageID, nil
+}
+
+// Stop envia as mensagens pendentes e encerra os publishers; o cliente continua aberto
+func (p *PubSubClient) Stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for topic, publisher := range p.publishers {
+		publisher.Stop()
+		delete(p.publishers, topic)
+	}
+}
+
+// publisher reaproveita um publisher por tópico, já que cada um mantém seus próprios lotes
+func (p *PubSubClient) publisher(topic string) *pubsub.Publisher {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	publisher, ok := p.publishers[topic]
+	if !ok {
+		publisher = p.client.Publisher(topic)
+		p.publishers[topic] = publisher
+	}
+	return publisher
+}
@@ -0,0 +1,77 @@
+package message
+
+import (
+	"context"
+	"testing"
+
+	"cloud.google.com/go/pubsub/v2"
+	"cloud.google.com/go/pubsub/v2/apiv1/pubsubpb"
+	"cloud.google.com/go/pubsub/v2/pstest"
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// newTestPubSub conecta um cliente a um servidor Pub/Sub em memória com o tópico "results"
+func newTestPubSub(t *testing.T) (*pstest.Server, *pubsub.Client) {
+	t.Helper()
+	server := pstest.NewServer()
+	t.Cleanup(func() { server.Close() })
+
+	conn, err := grpc.NewClient(server.Addr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Failed to connect to the fake server: %v", err)
+	}
+	client, err := pubsub.NewClient(context.Background(), "project", option.WithGRPCConn(conn))
+	if err != nil {
+		t.Fatalf("Failed to create client: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+
+	if _, err := client.TopicAdminClient.CreateTopic(context.Background(), &pubsubpb.Topic{Name: "projects/project/topics/results"}); err != nil {
+		t.Fatalf("Failed to create topic: %v", err)
+	}
+	return server, client
+}
+
+func TestPubSubClient_Implementation(t *testing.T) {
+	// Verifica se PubSubClient implementa a interface MessageService
+	var _ MessageService = (*PubSubClient)(nil)
+}
+
+func TestPubSubClient_SendMessageWithAttributes(t *testing.T) {
+	server, client := newTestPubSub(t)
+	publisher := NewPubSubClient(client)
+	defer publisher.Stop()
+
+	messageID, err := publisher.SendMessageWithAttributes(context.Background(), "results", `{"process_id":"1"}`, map[string]string{"signature": "abc"})
+	if err != nil {
+		t.Fatalf("SendMessageWithAttributes failed: %v", err)
+	}
+
+	messages := server.Messages()
+	if len(messages) != 1 {
+		t.Fatalf("Expected 1 published message, got %d", len(messages))
+	}
+	if messages[0].ID != messageID {
+		t.Errorf("Expected message ID %s, got %s", messages[0].ID, messageID)
+	}
+	if string(messages[0].Data) != `{"process_id":"1"}` || messages[0].Attributes["signature"] != "abc" {
+		t.Errorf("Unexpected message %+v", messages[0])
+	}
+
+	// O nome completo do tópico também é aceito
+	if _, err := publisher.SendMessage(context.Background(), "projects/project/topics/results", "body"); err != nil {
+		t.Errorf("SendMessage with the topic name failed: %v", err)
+	}
+}
+
+func TestPubSubClient_SendMessage_UnknownTopic(t *testing.T) {
+	_, client := newTestPubSub(t)
+	publisher := NewPubSubClient(client)
+	defer publisher.Stop()
+
+	if _, err := publisher.SendMessage(context.Background(), "missing", "body"); err == nil {
+		t.Error("Expected error when the topic does not exist")
+	}
+}
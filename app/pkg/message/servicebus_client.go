@@ -0,0 +1,94 @@
+package message
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/messaging/azservicebus"
+)
+
+// ServiceBusAPI é o subconjunto do sender do Azure Service Bus usado pelo ServiceBusClient
+type ServiceBusAPI interface {
+	SendMessage(ctx context.Context, message *azservicebus.Message, options *azservicebus.SendMessageOptions) error
+}
+
+// ServiceBusClient implementa a interface MessageService enviando para filas ou tópicos do
+// Azure Service Bus; o queueURL recebido pelos métodos é o nome da fila ou do tópico
+type ServiceBusClient struct {
+	newSender func(queueOrTopic string) (ServiceBusAPI, error)
+
+	mu      sync.Mutex
+	senders map[string]ServiceBusAPI
+}
+
+// NewServiceBusClient cria uma nova instância do ServiceBusClient sobre um cliente já
+// conectado ao namespace; os senders são fechados junto com o cliente
+func NewServiceBusClient(client *azservicebus.Client) *ServiceBusClient {
+	return &ServiceBusClient{
+		newSender: func(queueOrTopic string) (ServiceBusAPI, error) {
+			return client.NewSender(queueOrTopic, nil)
+		},
+		senders: make(map[string]ServiceBusAPI),
+	}
+}
+
+// SendMessage envia uma mensagem para a fila ou tópico
+func (s *ServiceBusClient) SendMessage(ctx context.Context, queueOrTopic string, messageBody string) (string, error) {
+	return s.SendMessageWithAttributes(ctx, queueOrTopic, messageBody, nil)
+}
+
+// SendMessageWithAttributes envia uma mensagem com os atributos como application properties.
+// O Service Bus não devolve um ID, então o MessageID é gerado aqui e retornado
+func (s *ServiceBusClient) SendMessageWithAttributes(ctx context.Context, queueOrTopic string, messageBody string, attributes map[string]string) (string, error) {
+	sender, err := s.sender(queueOrTopic)
+	if err != nil {
+		return "", err
+	}
+
+	messageID, err := newMessageID()
+	if err != nil {
+		return "", err
+	}
+	message := &azservicebus.Message{
+		MessageID: &messageID,
+		Body:      []byte(messageBody),
+	}
+	if len(attributes) > 0 {
+		message.ApplicationProperties = make(map[string]any, len(attributes))
+		for name, value := range attributes {
+			message.ApplicationProperties[name] = value
+		}
+	}
+
+	if err := sender.SendMessage(ctx, message, nil); err != nil {
+		return "", fmt.Errorf("failed to send message to Service Bus: %w", err)
+	}
+	return messageID, nil
+}
+
+// sender reaproveita um sender por fila ou tópico, já que cada um mantém seu próprio link AMQP
+func (s *ServiceBusClient) sender(queueOrTopic string) (ServiceBusAPI, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if sender, ok := s.senders[queueOrTopic]; ok {
+		return sender, nil
+	}
+	sender, err := s.newSender(queueOrTopic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Service Bus sender: %w", err)
+	}
+	s.senders[queueOrTopic] = sender
+	return sender, nil
+}
+
+// newMessageID gera um ID aleatório, usado também pela detecção de duplicadas do Service Bus
+func newMessageID() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", fmt.Errorf("failed to generate message id: %w", err)
+	}
+	return hex.EncodeToString(id), nil
+}
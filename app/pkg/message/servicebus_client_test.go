@@ -0,0 +1,83 @@
+package message
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/messaging/azservicebus"
+)
+
+type mockServiceBus struct {
+	messages []*azservicebus.Message
+	err      error
+}
+
+func (m *mockServiceBus) SendMessage(ctx context.Context, message *azservicebus.Message, options *azservicebus.SendMessageOptions) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.messages = append(m.messages, message)
+	return nil
+}
+
+func newTestServiceBusClient(mock *mockServiceBus, created *[]string) *ServiceBusClient {
+	return &ServiceBusClient{
+		newSender: func(queueOrTopic string) (ServiceBusAPI, error) {
+			*created = append(*created, queueOrTopic)
+			return mock, nil
+		},
+		senders: make(map[string]ServiceBusAPI),
+	}
+}
+
+func TestServiceBusClient_Implementation(t *testing.T) {
+	// Verifica se ServiceBusClient implementa a interface MessageService
+	var _ MessageService = (*ServiceBusClient)(nil)
+}
+
+func TestServiceBusClient_SendMessageWithAttributes(t *testing.T) {
+	mock := &mockServiceBus{}
+	var created []string
+	client := newTestServiceBusClient(mock, &created)
+
+	messageID, err := client.SendMessageWithAttributes(context.Background(), "results", `{"process_id":"1"}`, map[string]string{"signature": "abc"})
+	if err != nil {
+		t.Fatalf("SendMessageWithAttributes failed: %v", err)
+	}
+	if _, err := client.SendMessage(context.Background(), "results", "body"); err != nil {
+		t.Fatalf("SendMessage failed: %v", err)
+	}
+
+	if len(created) != 1 || created[0] != "results" {
+		t.Errorf("Expected one sender for the queue, got %v", created)
+	}
+	if len(mock.messages) != 2 {
+		t.Fatalf("Expected 2 sent messages, got %d", len(mock.messages))
+	}
+	sent := mock.messages[0]
+	if len(messageID) != 32 || *sent.MessageID != messageID {
+		t.Errorf("Expected the generated message ID %q, got %q", messageID, *sent.MessageID)
+	}
+	if string(sent.Body) != `{"process_id":"1"}` || sent.ApplicationProperties["signature"] != "abc" {
+		t.Errorf("Unexpected message %+v", sent)
+	}
+	if *mock.messages[1].MessageID == messageID || mock.messages[1].ApplicationProperties != nil {
+		t.Errorf("Expected a new ID and no properties, got %+v", mock.messages[1])
+	}
+}
+
+func TestServiceBusClient_SendMessage_Errors(t *testing.T) {
+	var created []string
+	client := newTestServiceBusClient(&mockServiceBus{err: errors.New("unauthorized")}, &created)
+	if _, err := client.SendMessage(context.Background(), "results", "body"); err == nil {
+		t.Error("Expected error when the send fails")
+	}
+
+	client.newSender = func(queueOrTopic string) (ServiceBusAPI, error) {
+		return nil, errors.New("invalid entity")
+	}
+	if _, err := client.SendMessage(context.Background(), "other", "body"); err == nil {
+		t.Error("Expected error when the sender cannot be created")
+	}
+}
@@ -0,0 +1,63 @@
+package message
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+	"github.com/aws/aws-sdk-go-v2/service/sns/types"
+)
+
+// SNSAPI é o subconjunto do cliente SNS usado pelo SNSClient
+type SNSAPI interface {
+	Publish(ctx context.Context, params *sns.PublishInput, optFns ...func(*sns.Options)) (*sns.PublishOutput, error)
+}
+
+// SNSClient implementa a interface MessageService publicando em tópicos SNS; o queueURL
+// recebido pelos métodos é o ARN do tópico
+type SNSClient struct {
+	client SNSAPI
+}
+
+// NewSNSClient cria uma nova instância do SNSClient
+func NewSNSClient(cfg aws.Config) *SNSClient {
+	return &SNSClient{
+		client: sns.NewFromConfig(cfg),
+	}
+}
+
+// SendMessage publica uma mensagem no tópico SNS
+func (s *SNSClient) SendMessage(ctx context.Context, topicARN string, messageBody string) (string, error) {
+	return s.SendMessageWithAttributes(ctx, topicARN, messageBody, nil)
+}
+
+// SendMessageWithAttributes publica uma mensagem no tópico SNS com atributos do tipo String,
+// repassados às filas inscritas com raw message delivery
+func (s *SNSClient) SendMessageWithAttributes(ctx context.Context, topicARN string, messageBody string, attributes map[string]string) (string, error) {
+	input := &sns.PublishInput{
+		TopicArn: aws.String(topicARN),
+		Message:  aws.String(messageBody),
+	}
+
+	if len(attributes) > 0 {
+		input.MessageAttributes = make(map[string]types.MessageAttributeValue, len(attributes))
+		for name, value := range attributes {
+			input.MessageAttributes[name] = types.MessageAttributeValue{
+				DataType:    aws.String("String"),
+				StringValue: aws.String(value),
+			}
+		}
+	}
+
+	result, err := s.client.Publish(ctx, input)
+	if err != nil {
+		return "", fmt.Errorf("failed to publish message to SNS: %w", err)
+	}
+
+	if result.MessageId == nil {
+		return "", fmt.Errorf("message published but no message ID returned")
+	}
+
+	return *result.MessageId, nil
+}
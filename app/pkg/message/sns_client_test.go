@@ -0,0 +1,53 @@
+package message
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sns"
+)
+
+type mockSNS struct {
+	input *sns.PublishInput
+	err   error
+}
+
+func (m *mockSNS) Publish(ctx context.Context, params *sns.PublishInput, optFns ...func(*sns.Options)) (*sns.PublishOutput, error) {
+	m.input = params
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &sns.PublishOutput{MessageId: aws.String("sns-message-id")}, nil
+}
+
+func TestSNSClient_Implementation(t *testing.T) {
+	// Verifica se SNSClient implementa a interface MessageService
+	var _ MessageService = (*SNSClient)(nil)
+}
+
+func TestSNSClient_SendMessageWithAttributes(t *testing.T) {
+	mock := &mockSNS{}
+	client := &SNSClient{client: mock}
+
+	topicARN := "arn:aws:sns:us-east-1:123456789:results"
+	messageID, err := client.SendMessageWithAttributes(context.Background(), topicARN, `{"process_id":"1"}`, map[string]string{"signature": "abc"})
+	if err != nil {
+		t.Fatalf("SendMessageWithAttributes failed: %v", err)
+	}
+	if messageID != "sns-message-id" {
+		t.Errorf("Expected message ID sns-message-id, got %q", messageID)
+	}
+	if aws.ToString(mock.input.TopicArn) != topicARN || aws.ToString(mock.input.Message) != `{"process_id":"1"}` {
+		t.Errorf("Unexpected publish input %+v", mock.input)
+	}
+	if aws.ToString(mock.input.MessageAttributes["signature"].StringValue) != "abc" {
+		t.Errorf("Expected the signature attribute, got %+v", mock.input.MessageAttributes)
+	}
+
+	mock.err = errors.New("throttled")
+	if _, err := client.SendMessage(context.Background(), topicARN, "body"); err == nil {
+		t.Error("Expected error when publish fails")
+	}
+}
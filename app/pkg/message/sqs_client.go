@@ -6,6 +6,7 @@ import (
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	"github.com/aws/aws-sdk-go-v2/service/sqs/types"
 )
 
 // SQSClient implementa a interface MessageService usando o AWS SQS
@@ -22,11 +23,26 @@ func NewSQSClient(cfg aws.Config) *SQSClient {
 
 // SendMessage envia uma mensagem para uma fila SQS
 func (s *SQSClient) SendMessage(ctx context.Context, queueURL string, messageBody string) (string, error) {
+	return s.SendMessageWithAttributes(ctx, queueURL, messageBody, nil)
+}
+
+// SendMessageWithAttributes envia uma mensagem para uma fila SQS com atributos do tipo String
+func (s *SQSClient) SendMessageWithAttributes(ctx context.Context, queueURL string, messageBody string, attributes map[string]string) (string, error) {
 	input := &sqs.SendMessageInput{
 		QueueUrl:    aws.String(queueURL),
 		MessageBody: aws.String(messageBody),
 	}
 
+	if len(attributes) > 0 {
+		input.MessageAttributes = make(map[string]types.MessageAttributeValue, len(attributes))
+		for name, value := range attributes {
+			input.MessageAttributes[name] = types.MessageAttributeValue{
+				DataType:    aws.String("String"),
+				StringValue: aws.String(value),
+			}
+		}
+	}
+
 	result, err := s.client.SendMessage(ctx, input)
 	if err != nil {
 		return "", fmt.Errorf("failed to send message to SQS: %w", err)
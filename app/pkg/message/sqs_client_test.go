@@ -73,6 +73,30 @@ func TestMockMessageService_DefaultBehavior(t *testing.T) {
 	}
 }
 
+func TestMockMessageService_SendMessageWithAttributes(t *testing.T) {
+	ctx := context.Background()
+
+	var receivedAttributes map[string]string
+	mock := &MockMessageService{
+		SendMessageWithAttributesFunc: func(ctx context.Context, queueURL string, messageBody string, attributes map[string]string) (string, error) {
+			receivedAttributes = attributes
+			return "msg-with-attributes", nil
+		},
+	}
+
+	messageID, err := mock.SendMessageWithAttributes(ctx, "test-queue", "test message", map[string]string{"signature": "abc"})
+	if err != nil {
+		t.Fatalf("SendMessageWithAttributes failed: %v", err)
+	}
+
+	if messageID != "msg-with-attributes" {
+		t.Errorf("Expected message ID 'msg-with-attributes', got %q", messageID)
+	}
+	if receivedAttributes["signature"] != "abc" {
+		t.Errorf("Expected signature attribute, got %v", receivedAttributes)
+	}
+}
+
 // Teste de integração básico (requer configuração AWS válida)
 func TestSQSClient_Integration(t *testing.T) {
 	if testing.Short() {
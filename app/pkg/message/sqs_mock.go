@@ -4,7 +4,8 @@ import "context"
 
 // MockMessageService é um mock da interface MessageService para testes
 type MockMessageService struct {
-	SendMessageFunc func(ctx context.Context, queueURL string, messageBody string) (string, error)
+	SendMessageFunc               func(ctx context.Context, queueURL string, messageBody string) (string, error)
+	SendMessageWithAttributesFunc func(ctx context.Context, queueURL string, messageBody string, attributes map[string]string) (string, error)
 }
 
 // SendMessage implementa MessageService.SendMessage usando a função mock configurada
@@ -14,3 +15,11 @@ func (m *MockMessageService) SendMessage(ctx context.Context, queueURL string, m
 	}
 	return "mock-message-id", nil
 }
+
+// SendMessageWithAttributes implementa MessageService.SendMessageWithAttributes usando a função mock configurada
+func (m *MockMessageService) SendMessageWithAttributes(ctx context.Context, queueURL string, messageBody string, attributes map[string]string) (string, error) {
+	if m.SendMessageWithAttributesFunc != nil {
+		return m.SendMessageWithAttributesFunc(ctx, queueURL, messageBody, attributes)
+	}
+	return "mock-message-id", nil
+}
@@ -0,0 +1,62 @@
+package message
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// WebhookAttributeHeader prefixa os headers que levam os atributos da mensagem, ex.:
+// X-Message-Attribute-Signature
+const WebhookAttributeHeader = "X-Message-Attribute-"
+
+// WebhookClient implementa a interface MessageService enviando a mensagem como o corpo de um
+// POST; o queueURL recebido pelos métodos é a URL do webhook
+type WebhookClient struct {
+	client *http.Client
+}
+
+// NewWebhookClient cria uma nova instância do WebhookClient; o client define o timeout
+func NewWebhookClient(client *http.Client) *WebhookClient {
+	return &WebhookClient{
+		client: client,
+	}
+}
+
+// SendMessage envia uma mensagem ao webhook
+func (w *WebhookClient) SendMessage(ctx context.Context, webhookURL string, messageBody string) (string, error) {
+	return w.SendMessageWithAttributes(ctx, webhookURL, messageBody, nil)
+}
+
+// SendMessageWithAttributes envia uma mensagem ao webhook com os atributos como headers.
+// Qualquer resposta 2xx confirma a entrega; o ID retornado é o header X-Request-Id, quando houver
+func (w *WebhookClient) SendMessageWithAttributes(ctx context.Context, webhookURL string, messageBody string, attributes map[string]string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, strings.NewReader(messageBody))
+	if err != nil {
+		return "", fmt.Errorf("failed to build webhook request: %w", err)
+	}
+	// Corpos binários (Avro, Protobuf ou comprimidos) viajam em base64, como no SQS
+	contentType := "application/json"
+	if attributes["content_transfer_encoding"] == "base64" {
+		contentType = "text/plain"
+	}
+	req.Header.Set("Content-Type", contentType)
+	for name, value := range attributes {
+		req.Header.Set(WebhookAttributeHeader+name, value)
+	}
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send message to webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return "", fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+
+	return resp.Header.Get("X-Request-Id"), nil
+}
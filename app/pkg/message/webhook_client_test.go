@@ -0,0 +1,51 @@
+package message
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWebhookClient_Implementation(t *testing.T) {
+	// Verifica se WebhookClient implementa a interface MessageService
+	var _ MessageService = (*WebhookClient)(nil)
+}
+
+func TestWebhookClient_SendMessageWithAttributes(t *testing.T) {
+	var body, signature, contentType string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		content, _ := io.ReadAll(r.Body)
+		body = string(content)
+		signature = r.Header.Get("X-Message-Attribute-Signature")
+		contentType = r.Header.Get("Content-Type")
+		w.Header().Set("X-Request-Id", "request-1")
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	client := NewWebhookClient(server.Client())
+	messageID, err := client.SendMessageWithAttributes(context.Background(), server.URL, `{"process_id":"1"}`, map[string]string{"signature": "abc"})
+	if err != nil {
+		t.Fatalf("SendMessageWithAttributes failed: %v", err)
+	}
+	if messageID != "request-1" {
+		t.Errorf("Expected message ID request-1, got %q", messageID)
+	}
+	if body != `{"process_id":"1"}` || signature != "abc" || contentType != "application/json" {
+		t.Errorf("Unexpected request: body %q, signature %q, content type %q", body, signature, contentType)
+	}
+}
+
+func TestWebhookClient_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := NewWebhookClient(server.Client())
+	if _, err := client.SendMessage(context.Background(), server.URL, "body"); err == nil {
+		t.Error("Expected error for a non 2xx response")
+	}
+}
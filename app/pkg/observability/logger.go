@@ -43,6 +43,27 @@ func InitLogger(environment string) error {
 	return nil
 }
 
+// AddLogSink also writes every entry of the global logger, JSON encoded, to sink, e.g. a
+// remote log service. Call it before WithFields so the sink gets those fields too
+func AddLogSink(sink zapcore.WriteSyncer) {
+	encoderConfig := zap.NewProductionEncoderConfig()
+	encoderConfig.TimeKey = "timestamp"
+	encoderConfig.EncodeTime = zapcore.ISO8601TimeEncoder
+	logger := GetLogger()
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(encoderConfig), sink, logger.Level())
+
+	GlobalLogger = logger.WithOptions(zap.WrapCore(func(existing zapcore.Core) zapcore.Core {
+		return zapcore.NewTee(existing, core)
+	}))
+	zap.ReplaceGlobals(GlobalLogger)
+}
+
+// WithFields adds fields to every entry of the global logger, e.g. the worker identity
+func WithFields(fields ...zap.Field) {
+	GlobalLogger = GetLogger().With(fields...)
+	zap.ReplaceGlobals(GlobalLogger)
+}
+
 // Sync flushes any buffered log entries
 func Sync() {
 	if GlobalLogger != nil {
@@ -1,6 +1,9 @@
 package observability
 
 import (
+	"context"
+	"errors"
+
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 )
@@ -15,23 +18,76 @@ var (
 		[]string{"status"},
 	)
 
-	// ProcessedVideos tracks total videos processed by status
+	// ProcessedVideos tracks total videos processed by status and tenant
 	ProcessedVideos = promauto.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: "worker_videos_processed_total",
 			Help: "Total number of videos processed by the worker",
 		},
-		[]string{"status"},
+		[]string{"status", "tenant"},
 	)
 
-	// ProcessingDuration tracks video processing duration
+	// ProcessingDuration tracks video processing duration by status and tenant
 	ProcessingDuration = promauto.NewHistogramVec(
 		prometheus.HistogramOpts{
 			Name:    "worker_processing_duration_seconds",
 			Help:    "Video processing duration in seconds",
 			Buckets: []float64{1, 5, 10, 30, 60, 120, 300, 600, 1200},
 		},
-		[]string{"status"},
+		[]string{"status", "tenant"},
+	)
+
+	// QueueLatency tracks how long jobs waited from their submission until a worker picked them up
+	QueueLatency = promauto.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "worker_queue_latency_seconds",
+			Help:    "Time from the job submission until a worker starts processing it",
+			Buckets: []float64{1, 5, 15, 30, 60, 120, 300, 600, 1800, 3600},
+		},
+	)
+
+	// SLABreaches tracks jobs whose result came later than the SLA after their submission
+	SLABreaches = promauto.NewCounter(
+		prometheus.CounterOpts{
+			Name: "worker_sla_breaches_total",
+			Help: "Total number of jobs that exceeded the end-to-end SLA",
+		},
+	)
+
+	// JobS3Requests tracks the S3 requests of jobs by tenant, for cost attribution
+	JobS3Requests = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "worker_job_s3_requests_total",
+			Help: "Total number of S3 get and put requests made by jobs, by tenant",
+		},
+		[]string{"tenant", "operation"},
+	)
+
+	// JobTransferBytes tracks the bytes jobs moved to and from S3 by tenant
+	JobTransferBytes = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "worker_job_transfer_bytes_total",
+			Help: "Total bytes transferred by jobs, by tenant",
+		},
+		[]string{"tenant", "direction"},
+	)
+
+	// JobCPUSeconds tracks the CPU time of the jobs' ffmpeg runs by tenant
+	JobCPUSeconds = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "worker_job_cpu_seconds_total",
+			Help: "Total CPU seconds used by the ffmpeg runs of jobs, by tenant",
+		},
+		[]string{"tenant"},
+	)
+
+	// JobCost tracks the estimated cost of jobs by tenant, with the worker's cost model
+	JobCost = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "worker_job_cost_total",
+			Help: "Total estimated cost of jobs, by tenant",
+		},
+		[]string{"tenant"},
 	)
 
 	// ExtractedFrames tracks frames extracted from last video
@@ -42,13 +98,13 @@ var (
 		},
 	)
 
-	// ErrorsByType tracks errors by type
+	// ErrorsByType tracks errors by type and normalized failure reason
 	ErrorsByType = promauto.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: "worker_errors_total",
-			Help: "Total number of errors by type",
+			Help: "Total number of errors by type and failure reason",
 		},
-		[]string{"type"},
+		[]string{"type", "reason"},
 	)
 
 	// ActiveMessages tracks messages currently being processed
@@ -69,13 +125,80 @@ var (
 		[]string{"type"},
 	)
 
-	// S3Operations tracks S3 operations
+	// FrameBytes tracks the total size of the frames before and after image optimization
+	FrameBytes = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "worker_frame_bytes_total",
+			Help: "Total size of the frames in bytes before and after optimization",
+		},
+		[]string{"format", "stage"},
+	)
+
+	// S3Operations tracks S3 operations by tenant
 	S3Operations = promauto.NewCounterVec(
 		prometheus.CounterOpts{
 			Name: "worker_s3_operations_total",
 			Help: "Total number of S3 operations",
 		},
-		[]string{"operation", "status"},
+		[]string{"operation", "status", "tenant"},
+	)
+
+	// OutboxPending tracks result messages waiting in the outbox for a retry
+	OutboxPending = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "worker_outbox_pending",
+			Help: "Number of result messages waiting in the outbox",
+		},
+	)
+
+	// LeaseLeader tells whether this replica holds the input lease (LEASE_BACKEND)
+	LeaseLeader = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "worker_lease_leader",
+			Help: "Whether this worker holds the lease to consume the input queue (1) or is on standby (0)",
+		},
+	)
+
+	// KillSwitchTripped tells whether the failure rate paused the consumption (KILL_SWITCH_FAILURE_RATE)
+	KillSwitchTripped = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "worker_kill_switch_tripped",
+			Help: "Whether the failure rate over the kill switch window paused the input consumption (1) or not (0)",
+		},
+	)
+
+	// MemoryBufferBytes tracks the bytes the files in the memory directory take (MEMORY_DIR)
+	MemoryBufferBytes = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "worker_memory_buffer_bytes",
+			Help: "Bytes the videos, frames and archives of the jobs in progress take in the memory directory",
+		},
+	)
+
+	// MemoryBufferReservedBytes tracks the memory directory reserved by the jobs in progress
+	MemoryBufferReservedBytes = promauto.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "worker_memory_buffer_reserved_bytes",
+			Help: "Bytes of the memory directory reserved by the jobs in progress for their videos, frames and archives",
+		},
+	)
+
+	// ProcessingBackends tracks where the frames of the videos were extracted and why
+	ProcessingBackends = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "worker_processing_backend_total",
+			Help: "Total videos processed by backend (local or remote) and routing reason",
+		},
+		[]string{"backend", "reason"},
+	)
+
+	// TransferredBytes tracks the bytes downloaded and uploaded by jobs
+	TransferredBytes = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "worker_transfer_bytes_total",
+			Help: "Total bytes transferred to and from S3 by jobs",
+		},
+		[]string{"direction"},
 	)
 
 	// SQSOperations tracks SQS operations
@@ -86,6 +209,15 @@ var (
 		},
 		[]string{"operation", "status"},
 	)
+
+	// ResultDeliveries tracks the deliveries of results to additional destinations
+	ResultDeliveries = promauto.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "worker_result_deliveries_total",
+			Help: "Total number of result deliveries to additional destinations",
+		},
+		[]string{"destination", "status"},
+	)
 )
 
 // RecordMessageProcessed records a processed message
@@ -97,33 +229,83 @@ func RecordMessageProcessed(success bool) {
 	ProcessedMessages.WithLabelValues(status).Inc()
 }
 
-// RecordVideoProcessed records a processed video with duration and frame count
-func RecordVideoProcessed(success bool, duration float64, frames int) {
+// RecordVideoProcessed records a processed video with duration and frame count, labeled with
+// the tenant of the job in ctx
+func RecordVideoProcessed(ctx context.Context, success bool, duration float64, frames int) {
 	status := "success"
 	if !success {
 		status = "error"
 	}
 
-	ProcessedVideos.WithLabelValues(status).Inc()
-	ProcessingDuration.WithLabelValues(status).Observe(duration)
+	tenant := TenantLabel(TenantFromContext(ctx))
+	ProcessedVideos.WithLabelValues(status, tenant).Inc()
+	ProcessingDuration.WithLabelValues(status, tenant).Observe(duration)
 
 	if success && frames > 0 {
 		ExtractedFrames.Set(float64(frames))
 	}
+	jobStats.Record(success, duration)
+	slo.Record(success)
+}
+
+// RecordQueueLatency records the time a job waited in the queue
+func RecordQueueLatency(latency float64) {
+	QueueLatency.Observe(latency)
+}
+
+// RecordSLABreach records a job that exceeded the SLA
+func RecordSLABreach() {
+	SLABreaches.Inc()
+}
+
+// RecordJobUsage records the resources a job of tenant consumed and their estimated cost
+func RecordJobUsage(tenant string, usage *JobUsage, cost float64) {
+	tenant = TenantLabel(tenant)
+	JobS3Requests.WithLabelValues(tenant, "get").Add(float64(usage.S3Requests("get")))
+	JobS3Requests.WithLabelValues(tenant, "put").Add(float64(usage.S3Requests("put")))
+	JobTransferBytes.WithLabelValues(tenant, "download").Add(float64(usage.TransferBytes("download")))
+	JobTransferBytes.WithLabelValues(tenant, "upload").Add(float64(usage.TransferBytes("upload")))
+	JobCPUSeconds.WithLabelValues(tenant).Add(usage.CPUTime().Seconds())
+	JobCost.WithLabelValues(tenant).Add(cost)
+}
+
+// UnknownFailureReason is the reason label of errors that carry no failure reason
+const UnknownFailureReason = "unknown"
+
+// failureReasoner is implemented by errors that know why they happened, like the ffmpeg ones
+type failureReasoner interface {
+	FailureReason() string
 }
 
-// RecordError records an error by type
+// RecordError records an error by type, without a failure reason
 func RecordError(errorType string) {
-	ErrorsByType.WithLabelValues(errorType).Inc()
+	RecordFailure(errorType, nil)
+}
+
+// RecordFailure records an error by type, labeled with the reason of the first error in the
+// chain of err that reports one
+func RecordFailure(errorType string, err error) {
+	reason := UnknownFailureReason
+	var reasoner failureReasoner
+	if errors.As(err, &reasoner) && reasoner.FailureReason() != "" {
+		reason = reasoner.FailureReason()
+	}
+	ErrorsByType.WithLabelValues(errorType, reason).Inc()
+}
+
+// RecordFrameOptimization records the frames size before and after optimizing to format
+func RecordFrameOptimization(format string, before, after int64) {
+	FrameBytes.WithLabelValues(format, "original").Add(float64(before))
+	FrameBytes.WithLabelValues(format, "optimized").Add(float64(after))
 }
 
-// RecordS3Operation records an S3 operation
-func RecordS3Operation(operation string, success bool) {
+// RecordS3Operation records an S3 operation, labeled with the tenant of the job in ctx
+func RecordS3Operation(ctx context.Context, operation string, success bool) {
 	status := "success"
 	if !success {
 		status = "error"
 	}
-	S3Operations.WithLabelValues(operation, status).Inc()
+	S3Operations.WithLabelValues(operation, status, TenantLabel(TenantFromContext(ctx))).Inc()
 }
 
 // RecordSQSOperation records an SQS operation
@@ -135,6 +317,58 @@ func RecordSQSOperation(operation string, success bool) {
 	SQSOperations.WithLabelValues(operation, status).Inc()
 }
 
+// RecordResultDelivery records the delivery of a result to a destination type (sqs, sns or webhook)
+func RecordResultDelivery(destination string, success bool) {
+	status := "success"
+	if !success {
+		status = "error"
+	}
+	ResultDeliveries.WithLabelValues(destination, status).Inc()
+}
+
+// RecordTransferBytes records bytes moved in direction (download or upload)
+func RecordTransferBytes(direction string, bytes int64) {
+	TransferredBytes.WithLabelValues(direction).Add(float64(bytes))
+}
+
+// SetOutboxPending records how many result messages are waiting in the outbox
+func SetOutboxPending(count int) {
+	OutboxPending.Set(float64(count))
+}
+
+// SetLeaseLeader records whether this worker holds the input lease
+func SetLeaseLeader(leader bool) {
+	if leader {
+		LeaseLeader.Set(1)
+	} else {
+		LeaseLeader.Set(0)
+	}
+}
+
+// SetKillSwitchTripped records whether the kill switch paused the input consumption
+func SetKillSwitchTripped(tripped bool) {
+	if tripped {
+		KillSwitchTripped.Set(1)
+	} else {
+		KillSwitchTripped.Set(0)
+	}
+}
+
+// SetMemoryBufferBytes records the bytes the files in the memory directory take
+func SetMemoryBufferBytes(bytes int64) {
+	MemoryBufferBytes.Set(float64(bytes))
+}
+
+// SetMemoryBufferReservedBytes records the bytes of the memory directory reserved by the jobs
+func SetMemoryBufferReservedBytes(bytes int64) {
+	MemoryBufferReservedBytes.Set(float64(bytes))
+}
+
+// RecordProcessingBackend records the backend chosen for a video and the reason
+func RecordProcessingBackend(backend, reason string) {
+	ProcessingBackends.WithLabelValues(backend, reason).Inc()
+}
+
 // RecordFileSize records a file size
 func RecordFileSize(fileType string, size int64) {
 	FileSizes.WithLabelValues(fileType).Observe(float64(size))
@@ -143,9 +377,11 @@ func RecordFileSize(fileType string, size int64) {
 // IncrementActiveMessages increments active messages counter
 func IncrementActiveMessages() {
 	ActiveMessages.Inc()
+	jobStats.active.Add(1)
 }
 
 // DecrementActiveMessages decrements active messages counter
 func DecrementActiveMessages() {
 	ActiveMessages.Dec()
+	jobStats.active.Add(-1)
 }
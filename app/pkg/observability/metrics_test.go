@@ -0,0 +1,29 @@
+package observability
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+type reasonError struct{ reason string }
+
+func (e reasonError) Error() string         { return "failed" }
+func (e reasonError) FailureReason() string { return e.reason }
+
+func TestRecordFailure(t *testing.T) {
+	before := testutil.ToFloat64(ErrorsByType.WithLabelValues("processing", "corrupt_file"))
+	RecordFailure("processing", fmt.Errorf("ffmpeg error: %w", reasonError{"corrupt_file"}))
+	if got := testutil.ToFloat64(ErrorsByType.WithLabelValues("processing", "corrupt_file")); got != before+1 {
+		t.Errorf("Expected the reason of the wrapped error counted, got %v", got-before)
+	}
+
+	before = testutil.ToFloat64(ErrorsByType.WithLabelValues("processing", UnknownFailureReason))
+	RecordFailure("processing", errors.New("plain"))
+	RecordError("processing")
+	if got := testutil.ToFloat64(ErrorsByType.WithLabelValues("processing", UnknownFailureReason)); got != before+2 {
+		t.Errorf("Expected 2 errors without a reason, got %v", got-before)
+	}
+}
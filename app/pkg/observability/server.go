@@ -2,11 +2,13 @@ package observability
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"sync"
 	"time"
 
+	"github.com/SOAT-Project/hackaton-soat-processor/pkg/buildinfo"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.uber.org/zap"
 )
@@ -17,13 +19,21 @@ type MetricsServer struct {
 	port   int
 	ready  bool
 	mu     sync.RWMutex
+
+	// maintenance reports the worker as not ready, with the maintenance status, while it hands
+	// the queue over to another fleet
+	maintenance bool
+
+	version    buildinfo.Info
+	queueDepth func(ctx context.Context) (int64, error)
 }
 
 // NewMetricsServer creates a new metrics server
 func NewMetricsServer(port int) *MetricsServer {
 	ms := &MetricsServer{
-		port:  port,
-		ready: false,
+		port:    port,
+		ready:   false,
+		version: buildinfo.Get(""),
 	}
 
 	mux := http.NewServeMux()
@@ -39,6 +49,12 @@ func NewMetricsServer(port int) *MetricsServer {
 	mux.HandleFunc("/processor/health/liveness", ms.handleLiveness)
 	mux.HandleFunc("/processor/health/readiness", ms.handleReadiness)
 
+	// Build information
+	mux.HandleFunc("/processor/version", ms.handleVersion)
+
+	// Rolling job summary, for dashboards without PromQL
+	mux.HandleFunc("/processor/stats", ms.handleStats)
+
 	ms.server = &http.Server{
 		Addr:         fmt.Sprintf(":%d", port),
 		Handler:      mux,
@@ -59,10 +75,13 @@ func (s *MetricsServer) handleHealth(w http.ResponseWriter, r *http.Request) {
 // handleReady handles simple readiness check
 func (s *MetricsServer) handleReady(w http.ResponseWriter, r *http.Request) {
 	s.mu.RLock()
-	ready := s.ready
+	ready, maintenance := s.ready, s.maintenance
 	s.mu.RUnlock()
 
-	if ready {
+	if maintenance {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte("MAINTENANCE"))
+	} else if ready {
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte("READY"))
 	} else {
@@ -84,11 +103,14 @@ func (s *MetricsServer) handleLiveness(w http.ResponseWriter, r *http.Request) {
 func (s *MetricsServer) handleReadiness(w http.ResponseWriter, r *http.Request) {
 	// Readiness probe: checks if the application is ready to receive traffic
 	s.mu.RLock()
-	ready := s.ready
+	ready, maintenance := s.ready, s.maintenance
 	s.mu.RUnlock()
 
 	w.Header().Set("Content-Type", "application/json")
-	if ready {
+	if maintenance {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`{"status":"maintenance"}`))
+	} else if ready {
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte(`{"status":"ready"}`))
 	} else {
@@ -97,6 +119,56 @@ func (s *MetricsServer) handleReadiness(w http.ResponseWriter, r *http.Request)
 	}
 }
 
+// handleVersion returns the build information and the ffmpeg version
+func (s *MetricsServer) handleVersion(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	version := s.version
+	s.mu.RUnlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(version)
+}
+
+// handleStats returns the jobs of the last hour, the running ones and the input queue depth
+func (s *MetricsServer) handleStats(w http.ResponseWriter, r *http.Request) {
+	s.mu.RLock()
+	queueDepth := s.queueDepth
+	s.mu.RUnlock()
+
+	stats := Stats()
+	if queueDepth != nil {
+		ctx, cancel := context.WithTimeout(r.Context(), 2*time.Second)
+		depth, err := queueDepth(ctx)
+		cancel()
+		if err != nil {
+			GetLogger().Warn("failed to read the input queue depth", zap.Error(err))
+		} else {
+			stats.QueueDepth = &depth
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(stats)
+}
+
+// SetQueueDepth sets how /processor/stats reads the depth of the input queue; without it the
+// depth is left out
+func (s *MetricsServer) SetQueueDepth(queueDepth func(ctx context.Context) (int64, error)) {
+	s.mu.Lock()
+	s.queueDepth = queueDepth
+	s.mu.Unlock()
+}
+
+// SetVersion updates the build information served by /processor/version, e.g. once the
+// ffmpeg version is known
+func (s *MetricsServer) SetVersion(version buildinfo.Info) {
+	s.mu.Lock()
+	s.version = version
+	s.mu.Unlock()
+}
+
 // SetReady marks the server as ready to receive traffic
 func (s *MetricsServer) SetReady(ready bool) {
 	s.mu.Lock()
@@ -111,6 +183,13 @@ func (s *MetricsServer) SetReady(ready bool) {
 	}
 }
 
+// SetMaintenance reports the maintenance in the readiness checks, whatever SetReady set
+func (s *MetricsServer) SetMaintenance(maintenance bool) {
+	s.mu.Lock()
+	s.maintenance = maintenance
+	s.mu.Unlock()
+}
+
 // Start starts the metrics server
 func (s *MetricsServer) Start() error {
 	logger := GetLogger()
@@ -121,6 +200,8 @@ func (s *MetricsServer) Start() error {
 		zap.String("ready_endpoint", "/ready"),
 		zap.String("liveness_endpoint", "/processor/health/liveness"),
 		zap.String("readiness_endpoint", "/processor/health/readiness"),
+		zap.String("version_endpoint", "/processor/version"),
+		zap.String("stats_endpoint", "/processor/stats"),
 	)
 
 	go func() {
@@ -0,0 +1,38 @@
+package observability
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMetricsServer_Maintenance(t *testing.T) {
+	InitLogger("test")
+	server := NewMetricsServer(0)
+	server.SetReady(true)
+
+	readiness := func() (int, string) {
+		recorder := httptest.NewRecorder()
+		server.handleReadiness(recorder, httptest.NewRequest(http.MethodGet, "/processor/health/readiness", nil))
+		return recorder.Code, recorder.Body.String()
+	}
+
+	if code, body := readiness(); code != http.StatusOK || body != `{"status":"ready"}` {
+		t.Errorf("Expected ready, got %d %s", code, body)
+	}
+
+	server.SetMaintenance(true)
+	if code, body := readiness(); code != http.StatusServiceUnavailable || body != `{"status":"maintenance"}` {
+		t.Errorf("Expected maintenance, got %d %s", code, body)
+	}
+	recorder := httptest.NewRecorder()
+	server.handleReady(recorder, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	if recorder.Code != http.StatusServiceUnavailable || recorder.Body.String() != "MAINTENANCE" {
+		t.Errorf("Expected maintenance on /ready, got %d %s", recorder.Code, recorder.Body.String())
+	}
+
+	server.SetMaintenance(false)
+	if code, _ := readiness(); code != http.StatusOK {
+		t.Errorf("Expected ready after the maintenance, got %d", code)
+	}
+}
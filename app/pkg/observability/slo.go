@@ -0,0 +1,145 @@
+package observability
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+const (
+	// DefaultSLOObjective is the share of jobs expected to succeed
+	DefaultSLOObjective = 0.99
+
+	// SLOFastWindow is the window of the fast burn rate, which pages on sudden outages
+	SLOFastWindow = time.Hour
+
+	// SLOSlowWindow is the window of the slow burn rate, which catches a steady leak of the
+	// error budget
+	SLOSlowWindow = 6 * time.Hour
+
+	// sloResolution is the width of the buckets the windows are counted in
+	sloResolution = time.Minute
+)
+
+type sloBucket struct {
+	start     time.Time
+	succeeded int
+	failed    int
+}
+
+// SLO counts job outcomes over sliding windows, in one-minute buckets covering
+// SLOSlowWindow. All methods are safe for concurrent use
+type SLO struct {
+	mu        sync.Mutex
+	objective float64
+	buckets   []sloBucket
+	now       func() time.Time
+}
+
+// NewSLO creates an SLO expecting objective (between 0 and 1) of the jobs to succeed
+func NewSLO(objective float64) *SLO {
+	return &SLO{
+		objective: objective,
+		buckets:   make([]sloBucket, int(SLOSlowWindow/sloResolution)),
+		now:       time.Now,
+	}
+}
+
+// Objective is the share of jobs expected to succeed
+func (s *SLO) Objective() float64 {
+	return s.objective
+}
+
+// Record counts a finished job
+func (s *SLO) Record(success bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	start := s.now().Truncate(sloResolution)
+	bucket := &s.buckets[int(start.Unix()/int64(sloResolution.Seconds()))%len(s.buckets)]
+	if !bucket.start.Equal(start) {
+		*bucket = sloBucket{start: start}
+	}
+	if success {
+		bucket.succeeded++
+	} else {
+		bucket.failed++
+	}
+}
+
+// counts sums the buckets of the last window, up to SLOSlowWindow
+func (s *SLO) counts(window time.Duration) (succeeded, failed int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	cutoff := s.now().Truncate(sloResolution).Add(-window)
+	for _, bucket := range s.buckets {
+		if bucket.start.After(cutoff) {
+			succeeded += bucket.succeeded
+			failed += bucket.failed
+		}
+	}
+	return succeeded, failed
+}
+
+// SuccessRate is the share of the jobs of the last window that succeeded; a window without
+// jobs counts as fully successful
+func (s *SLO) SuccessRate(window time.Duration) float64 {
+	succeeded, failed := s.counts(window)
+	if succeeded+failed == 0 {
+		return 1
+	}
+	return float64(succeeded) / float64(succeeded+failed)
+}
+
+// BurnRate is how fast the last window spent the error budget: 1 spends exactly the budget
+// over the SLO period, 14.4 spends 2% of a 30-day budget in an hour
+func (s *SLO) BurnRate(window time.Duration) float64 {
+	budget := 1 - s.objective
+	if budget <= 0 {
+		return 0
+	}
+	return (1 - s.SuccessRate(window)) / budget
+}
+
+var slo = NewSLO(DefaultSLOObjective)
+
+// ConfigureSLO replaces the SLO with one expecting objective of the jobs to succeed; call it
+// at startup, before any job finishes
+func ConfigureSLO(objective float64) {
+	slo = NewSLO(objective)
+}
+
+// sloWindows labels the windows of the SLO gauges
+var sloWindows = map[string]time.Duration{
+	"1h": SLOFastWindow,
+	"6h": SLOSlowWindow,
+}
+
+func init() {
+	promauto.NewGaugeFunc(
+		prometheus.GaugeOpts{
+			Name: "worker_slo_objective",
+			Help: "Share of jobs expected to succeed",
+		},
+		func() float64 { return slo.Objective() },
+	)
+	for label, window := range sloWindows {
+		promauto.NewGaugeFunc(
+			prometheus.GaugeOpts{
+				Name:        "worker_slo_success_rate",
+				Help:        "Share of the jobs of the window that succeeded",
+				ConstLabels: prometheus.Labels{"window": label},
+			},
+			func() float64 { return slo.SuccessRate(window) },
+		)
+		promauto.NewGaugeFunc(
+			prometheus.GaugeOpts{
+				Name:        "worker_slo_burn_rate",
+				Help:        "Rate the jobs of the window spend the error budget at, 1 being the sustainable rate",
+				ConstLabels: prometheus.Labels{"window": label},
+			},
+			func() float64 { return slo.BurnRate(window) },
+		)
+	}
+}
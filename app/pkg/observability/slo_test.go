@@ -0,0 +1,47 @@
+package observability
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestSLO_BurnRate(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	slo := NewSLO(0.99)
+	slo.now = func() time.Time { return now }
+
+	if rate := slo.SuccessRate(SLOFastWindow); rate != 1 {
+		t.Errorf("Expected an empty window to be fully successful, got %v", rate)
+	}
+	if burn := slo.BurnRate(SLOSlowWindow); burn != 0 {
+		t.Errorf("Expected no burn without jobs, got %v", burn)
+	}
+
+	// 2 failures out of 100 jobs three hours ago, only seen by the slow window
+	for i := 0; i < 100; i++ {
+		slo.Record(i >= 2)
+	}
+	now = now.Add(3 * time.Hour)
+	// 10 failures out of 100 jobs now, seen by both windows
+	for i := 0; i < 100; i++ {
+		slo.Record(i >= 10)
+	}
+
+	if rate := slo.SuccessRate(SLOFastWindow); rate != 0.9 {
+		t.Errorf("Expected fast success rate 0.9, got %v", rate)
+	}
+	if burn := slo.BurnRate(SLOFastWindow); math.Abs(burn-10) > 1e-9 {
+		t.Errorf("Expected fast burn rate 10, got %v", burn)
+	}
+	if burn := slo.BurnRate(SLOSlowWindow); math.Abs(burn-6) > 1e-9 {
+		t.Errorf("Expected slow burn rate 6, got %v", burn)
+	}
+
+	// The buckets of the older jobs are reused once they leave the slow window
+	now = now.Add(SLOSlowWindow)
+	slo.Record(true)
+	if rate := slo.SuccessRate(SLOSlowWindow); rate != 1 {
+		t.Errorf("Expected the old failures to leave the window, got %v", rate)
+	}
+}
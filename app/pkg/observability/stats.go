@@ -0,0 +1,112 @@
+package observability
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// StatsWindow is how far back /processor/stats looks
+const StatsWindow = time.Hour
+
+// StatsSummary is the rolling summary served by /processor/stats, for dashboards that do not
+// query Prometheus
+type StatsSummary struct {
+	WindowSeconds float64 `json:"window_seconds"`
+	Jobs          int     `json:"jobs"`
+	Succeeded     int     `json:"succeeded"`
+	Failed        int     `json:"failed"`
+	// SuccessRate is between 0 and 1; it is 0 when no job finished in the window
+	SuccessRate        float64 `json:"success_rate"`
+	DurationP50Seconds float64 `json:"duration_p50_seconds"`
+	DurationP95Seconds float64 `json:"duration_p95_seconds"`
+	ActiveJobs         int64   `json:"active_jobs"`
+	// QueueDepth is the approximate number of messages waiting in the input queue; nil when
+	// the transport does not report it
+	QueueDepth  *int64    `json:"queue_depth,omitempty"`
+	GeneratedAt time.Time `json:"generated_at"`
+}
+
+type finishedJob struct {
+	at       time.Time
+	success  bool
+	duration float64
+}
+
+// JobStats keeps the jobs finished within a rolling window. All methods are safe for
+// concurrent use
+type JobStats struct {
+	mu     sync.Mutex
+	window time.Duration
+	jobs   []finishedJob
+	active atomic.Int64
+	now    func() time.Time
+}
+
+// NewJobStats creates stats over the jobs finished in the last window
+func NewJobStats(window time.Duration) *JobStats {
+	return &JobStats{window: window, now: time.Now}
+}
+
+// Record adds a finished job and its duration in seconds
+func (s *JobStats) Record(success bool, duration float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	s.prune(now)
+	s.jobs = append(s.jobs, finishedJob{at: now, success: success, duration: duration})
+}
+
+// prune drops the jobs that left the window; jobs are appended in time order
+func (s *JobStats) prune(now time.Time) {
+	cutoff := now.Add(-s.window)
+	expired := sort.Search(len(s.jobs), func(i int) bool { return s.jobs[i].at.After(cutoff) })
+	if expired > 0 {
+		s.jobs = append(s.jobs[:0], s.jobs[expired:]...)
+	}
+}
+
+// Summary counts the jobs of the window and the jobs still running
+func (s *JobStats) Summary() StatsSummary {
+	s.mu.Lock()
+	now := s.now()
+	s.prune(now)
+	durations := make([]float64, 0, len(s.jobs))
+	summary := StatsSummary{WindowSeconds: s.window.Seconds(), Jobs: len(s.jobs), GeneratedAt: now.UTC()}
+	for _, job := range s.jobs {
+		if job.success {
+			summary.Succeeded++
+		} else {
+			summary.Failed++
+		}
+		durations = append(durations, job.duration)
+	}
+	s.mu.Unlock()
+
+	summary.ActiveJobs = s.active.Load()
+	if summary.Jobs > 0 {
+		summary.SuccessRate = float64(summary.Succeeded) / float64(summary.Jobs)
+	}
+	sort.Float64s(durations)
+	summary.DurationP50Seconds = percentile(durations, 0.50)
+	summary.DurationP95Seconds = percentile(durations, 0.95)
+	return summary
+}
+
+// percentile is the nearest-rank percentile of sorted values, 0 for none
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	return sorted[max(rank, 0)]
+}
+
+var jobStats = NewJobStats(StatsWindow)
+
+// Stats summarizes the jobs of the process over the last StatsWindow
+func Stats() StatsSummary {
+	return jobStats.Summary()
+}
@@ -0,0 +1,81 @@
+package observability
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestJobStats_Summary(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	stats := NewJobStats(time.Hour)
+	stats.now = func() time.Time { return now }
+
+	if summary := stats.Summary(); summary.Jobs != 0 || summary.SuccessRate != 0 || summary.DurationP95Seconds != 0 {
+		t.Errorf("Expected an empty summary, got %+v", summary)
+	}
+
+	// A job outside the window is not counted
+	stats.Record(false, 500)
+	now = now.Add(2 * time.Hour)
+	for i := 1; i <= 19; i++ {
+		stats.Record(true, float64(i))
+	}
+	stats.Record(false, 100)
+	stats.active.Add(2)
+
+	summary := stats.Summary()
+	if summary.Jobs != 20 || summary.Succeeded != 19 || summary.Failed != 1 {
+		t.Errorf("Expected 20 jobs with 1 failure, got %+v", summary)
+	}
+	if summary.SuccessRate != 0.95 {
+		t.Errorf("Expected success rate 0.95, got %v", summary.SuccessRate)
+	}
+	if summary.DurationP50Seconds != 10 || summary.DurationP95Seconds != 19 {
+		t.Errorf("Expected p50 10 and p95 19, got %v and %v", summary.DurationP50Seconds, summary.DurationP95Seconds)
+	}
+	if summary.ActiveJobs != 2 || summary.WindowSeconds != 3600 || !summary.GeneratedAt.Equal(now) {
+		t.Errorf("Unexpected summary %+v", summary)
+	}
+
+	now = now.Add(time.Hour)
+	if summary := stats.Summary(); summary.Jobs != 0 {
+		t.Errorf("Expected the jobs to leave the window, got %d", summary.Jobs)
+	}
+}
+
+func TestMetricsServer_Stats(t *testing.T) {
+	InitLogger("test")
+	server := NewMetricsServer(0)
+
+	get := func() map[string]any {
+		recorder := httptest.NewRecorder()
+		server.handleStats(recorder, httptest.NewRequest(http.MethodGet, "/processor/stats", nil))
+		if recorder.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", recorder.Code)
+		}
+		var body map[string]any
+		if err := json.NewDecoder(recorder.Body).Decode(&body); err != nil {
+			t.Fatalf("Failed to decode the stats: %v", err)
+		}
+		return body
+	}
+
+	if body := get(); body["queue_depth"] != nil || body["success_rate"] == nil {
+		t.Errorf("Expected no queue depth without a reader, got %v", body)
+	}
+
+	server.SetQueueDepth(func(ctx context.Context) (int64, error) { return 7, nil })
+	if body := get(); body["queue_depth"] != float64(7) {
+		t.Errorf("Expected queue depth 7, got %v", body["queue_depth"])
+	}
+
+	server.SetQueueDepth(func(ctx context.Context) (int64, error) { return 0, errors.New("denied") })
+	if body := get(); body["queue_depth"] != nil {
+		t.Errorf("Expected no queue depth when it fails, got %v", body["queue_depth"])
+	}
+}
@@ -0,0 +1,90 @@
+package observability
+
+import (
+	"context"
+	"sync"
+)
+
+const (
+	// DefaultMaxTenantLabels is how many tenants get their own series before the others are
+	// folded into OtherTenantLabel
+	DefaultMaxTenantLabels = 20
+
+	// OtherTenantLabel is the tenant label of the tenants over the limit
+	OtherTenantLabel = "other"
+
+	// NoTenantLabel is the tenant label of jobs without a tenant and of work outside a job
+	NoTenantLabel = "none"
+)
+
+// TenantLabels bounds the cardinality of the tenant label: the pinned tenants and the first
+// maxTenants others seen keep their own label, every other tenant shares OtherTenantLabel.
+// A tenant keeps its label for the life of the process, so its series never move
+type TenantLabels struct {
+	mu         sync.Mutex
+	maxTenants int
+	pinned     map[string]bool
+	admitted   map[string]bool
+}
+
+// NewTenantLabels creates a guard admitting the pinned tenants and up to maxTenants others
+func NewTenantLabels(maxTenants int, pinned []string) *TenantLabels {
+	labels := &TenantLabels{
+		maxTenants: maxTenants,
+		pinned:     map[string]bool{},
+		admitted:   map[string]bool{},
+	}
+	for _, tenant := range pinned {
+		if tenant != "" {
+			labels.pinned[tenant] = true
+		}
+	}
+	return labels
+}
+
+// Label is the value of the tenant label for tenant
+func (l *TenantLabels) Label(tenant string) string {
+	if tenant == "" {
+		return NoTenantLabel
+	}
+	if l.pinned[tenant] {
+		return tenant
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.admitted[tenant] {
+		return tenant
+	}
+	if len(l.admitted) < l.maxTenants {
+		l.admitted[tenant] = true
+		return tenant
+	}
+	return OtherTenantLabel
+}
+
+var tenantLabels = NewTenantLabels(DefaultMaxTenantLabels, nil)
+
+// ConfigureTenantLabels replaces the guard of the tenant label; call it at startup, before
+// any metric is recorded
+func ConfigureTenantLabels(maxTenants int, pinned []string) {
+	tenantLabels = NewTenantLabels(maxTenants, pinned)
+}
+
+// TenantLabel is the value of the tenant label for tenant under the configured guard
+func TenantLabel(tenant string) string {
+	return tenantLabels.Label(tenant)
+}
+
+type tenantKey struct{}
+
+// WithTenant returns a copy of ctx carrying the tenant of the job, used to label its metrics
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// TenantFromContext returns the tenant stored in ctx, or "" outside a job
+func TenantFromContext(ctx context.Context) string {
+	tenant, _ := ctx.Value(tenantKey{}).(string)
+	return tenant
+}
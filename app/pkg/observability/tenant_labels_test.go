@@ -0,0 +1,37 @@
+package observability
+
+import (
+	"context"
+	"testing"
+)
+
+func TestTenantLabels(t *testing.T) {
+	labels := NewTenantLabels(2, []string{"vip"})
+
+	tests := []struct {
+		tenant string
+		want   string
+	}{
+		{"", NoTenantLabel},
+		{"acme", "acme"},
+		{"vip", "vip"},
+		{"globex", "globex"},
+		{"initech", OtherTenantLabel},
+		{"acme", "acme"},
+		{"vip", "vip"},
+	}
+	for _, tt := range tests {
+		if got := labels.Label(tt.tenant); got != tt.want {
+			t.Errorf("Label(%q) = %q, want %q", tt.tenant, got, tt.want)
+		}
+	}
+}
+
+func TestTenantFromContext(t *testing.T) {
+	if tenant := TenantFromContext(context.Background()); tenant != "" {
+		t.Errorf("Expected no tenant outside a job, got %q", tenant)
+	}
+	if tenant := TenantFromContext(WithTenant(context.Background(), "acme")); tenant != "acme" {
+		t.Errorf("Expected the job tenant, got %q", tenant)
+	}
+}
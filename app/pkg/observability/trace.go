@@ -0,0 +1,74 @@
+package observability
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// TraceContext identifies the trace a message belongs to across the queues
+type TraceContext struct {
+	// CorrelationID is the producer's trace id, or the id of the message without one
+	CorrelationID string
+	// Parent is the W3C traceparent received with the message, "" when there was none or it
+	// was invalid
+	Parent string
+}
+
+type traceKey struct{}
+
+// WithTrace returns a copy of ctx carrying the trace of the message being handled
+func WithTrace(ctx context.Context, trace TraceContext) context.Context {
+	return context.WithValue(ctx, traceKey{}, trace)
+}
+
+// TraceFromContext returns the trace stored in ctx, or the zero TraceContext outside a message
+func TraceFromContext(ctx context.Context) TraceContext {
+	trace, _ := ctx.Value(traceKey{}).(TraceContext)
+	return trace
+}
+
+// ChildTraceparent is the traceparent of a message sent on behalf of the trace: the trace id
+// and flags of Parent with a new span id. Without a Parent the trace id is derived from the
+// correlation id, so every message of a job shares it; "" when there is neither
+func (t TraceContext) ChildTraceparent() string {
+	traceID, flags, ok := ParseTraceparent(t.Parent)
+	if !ok {
+		if t.CorrelationID == "" {
+			return ""
+		}
+		sum := sha256.Sum256([]byte(t.CorrelationID))
+		traceID, flags = hex.EncodeToString(sum[:16]), "01"
+	}
+	span := make([]byte, 8)
+	rand.Read(span)
+	return "00-" + traceID + "-" + hex.EncodeToString(span) + "-" + flags
+}
+
+// ParseTraceparent returns the trace id and flags of a version 00 W3C traceparent
+func ParseTraceparent(value string) (traceID, flags string, ok bool) {
+	parts := strings.Split(value, "-")
+	if len(parts) != 4 || parts[0] != "00" || !isHex(parts[1], 32) || !isHex(parts[2], 16) || !isHex(parts[3], 2) {
+		return "", "", false
+	}
+	// All-zero trace and span ids are invalid
+	if strings.Trim(parts[1], "0") == "" || strings.Trim(parts[2], "0") == "" {
+		return "", "", false
+	}
+	return parts[1], parts[3], true
+}
+
+// isHex reports whether value is length lowercase hex digits
+func isHex(value string, length int) bool {
+	if len(value) != length {
+		return false
+	}
+	for _, r := range value {
+		if (r < '0' || r > '9') && (r < 'a' || r > 'f') {
+			return false
+		}
+	}
+	return true
+}
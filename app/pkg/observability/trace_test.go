@@ -0,0 +1,47 @@
+package observability
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	traceID, flags, ok := ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	if !ok || traceID != "4bf92f3577b34da6a3ce929d0e0e4736" || flags != "01" {
+		t.Errorf("Unexpected parse %q %q %v", traceID, flags, ok)
+	}
+
+	for _, invalid := range []string{
+		"",
+		"01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+	} {
+		if _, _, ok := ParseTraceparent(invalid); ok {
+			t.Errorf("Expected %q to be invalid", invalid)
+		}
+	}
+}
+
+func TestTraceContext_ChildTraceparent(t *testing.T) {
+	parent := TraceContext{CorrelationID: "trace-1", Parent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00"}
+	child := parent.ChildTraceparent()
+	traceID, flags, ok := ParseTraceparent(child)
+	if !ok || traceID != "4bf92f3577b34da6a3ce929d0e0e4736" || flags != "00" || strings.Contains(child, "00f067aa0ba902b7") {
+		t.Errorf("Expected a new span of the parent trace, got %q", child)
+	}
+
+	// Without a traceparent every message of the job shares the trace derived from its id
+	derived := TraceContext{CorrelationID: "trace-1"}
+	first, _, ok := ParseTraceparent(derived.ChildTraceparent())
+	second, _, _ := ParseTraceparent(derived.ChildTraceparent())
+	if !ok || first != second {
+		t.Errorf("Expected a stable derived trace id, got %q and %q", first, second)
+	}
+
+	if got := (TraceContext{}).ChildTraceparent(); got != "" {
+		t.Errorf("Expected no traceparent outside a trace, got %q", got)
+	}
+}
@@ -0,0 +1,120 @@
+package observability
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// JobUsage accumulates the resources a job consumes, for cost attribution. It travels in the
+// job context so the code doing the work, down to the ffmpeg runs, can add to it; all methods
+// are safe for concurrent use and do nothing on a nil JobUsage
+type JobUsage struct {
+	s3Gets          atomic.Int64
+	s3Puts          atomic.Int64
+	bytesDownloaded atomic.Int64
+	bytesUploaded   atomic.Int64
+	cpuTime         atomic.Int64
+	backend         atomic.Value
+}
+
+type usageKey struct{}
+
+// WithJobUsage returns a copy of ctx carrying usage, retrieved with UsageFromContext
+func WithJobUsage(ctx context.Context, usage *JobUsage) context.Context {
+	return context.WithValue(ctx, usageKey{}, usage)
+}
+
+// UsageFromContext returns the usage stored in ctx, or nil outside a job
+func UsageFromContext(ctx context.Context) *JobUsage {
+	usage, _ := ctx.Value(usageKey{}).(*JobUsage)
+	return usage
+}
+
+// AddS3Request counts a get or put request, a copy being billed as a put; other operations are
+// not billed per request
+func (u *JobUsage) AddS3Request(operation string) {
+	if u == nil {
+		return
+	}
+	switch operation {
+	case "get":
+		u.s3Gets.Add(1)
+	case "put", "copy":
+		u.s3Puts.Add(1)
+	}
+}
+
+// AddTransferBytes counts bytes moved in direction (download or upload)
+func (u *JobUsage) AddTransferBytes(direction string, bytes int64) {
+	if u == nil {
+		return
+	}
+	switch direction {
+	case "download":
+		u.bytesDownloaded.Add(bytes)
+	case "upload":
+		u.bytesUploaded.Add(bytes)
+	}
+}
+
+// AddCPUTime counts the user and system CPU time of a process run for the job
+func (u *JobUsage) AddCPUTime(cpu time.Duration) {
+	if u == nil {
+		return
+	}
+	u.cpuTime.Add(int64(cpu))
+}
+
+// S3Requests returns the get or put requests counted
+func (u *JobUsage) S3Requests(operation string) int64 {
+	if u == nil {
+		return 0
+	}
+	switch operation {
+	case "get":
+		return u.s3Gets.Load()
+	case "put":
+		return u.s3Puts.Load()
+	}
+	return 0
+}
+
+// TransferBytes returns the bytes counted in direction
+func (u *JobUsage) TransferBytes(direction string) int64 {
+	if u == nil {
+		return 0
+	}
+	switch direction {
+	case "download":
+		return u.bytesDownloaded.Load()
+	case "upload":
+		return u.bytesUploaded.Load()
+	}
+	return 0
+}
+
+// CPUTime returns the CPU time counted
+func (u *JobUsage) CPUTime() time.Duration {
+	if u == nil {
+		return 0
+	}
+	return time.Duration(u.cpuTime.Load())
+}
+
+// SetBackend records where the job's video was processed (local or remote), whose costs differ
+func (u *JobUsage) SetBackend(backend string) {
+	if u == nil {
+		return
+	}
+	u.backend.Store(backend)
+}
+
+// Backend returns where the job's video was processed, or "" when it was not processed
+func (u *JobUsage) Backend() string {
+	if u == nil {
+		return ""
+	}
+	backend, _ := u.backend.Load().(string)
+	return backend
+}
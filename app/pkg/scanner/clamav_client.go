@@ -0,0 +1,111 @@
+package scanner
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+const clamavChunkSize = 64 * 1024
+
+// ClamAVClient implementa a interface ScannerService usando o protocolo INSTREAM do clamd
+type ClamAVClient struct {
+	address string
+	timeout time.Duration
+}
+
+// NewClamAVClient cria uma nova instância do ClamAVClient para o endereço host:porta do clamd
+func NewClamAVClient(address string, timeout time.Duration) *ClamAVClient {
+	if timeout <= 0 {
+		timeout = 2 * time.Minute
+	}
+	return &ClamAVClient{
+		address: address,
+		timeout: timeout,
+	}
+}
+
+// Scan envia o conteúdo ao clamd em blocos e interpreta a resposta
+func (c *ClamAVClient) Scan(ctx context.Context, content io.Reader) (ScanResult, error) {
+	dialer := net.Dialer{Timeout: c.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", c.address)
+	if err != nil {
+		return ScanResult{}, fmt.Errorf("failed to connect to clamd: %w", err)
+	}
+	defer conn.Close()
+
+	deadline := time.Now().Add(c.timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	conn.SetDeadline(deadline)
+
+	if _, err := conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return ScanResult{}, fmt.Errorf("failed to start clamd stream: %w", err)
+	}
+
+	if err := streamChunks(conn, content); err != nil {
+		return ScanResult{}, err
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString('\x00')
+	if err != nil && err != io.EOF {
+		return ScanResult{}, fmt.Errorf("failed to read clamd reply: %w", err)
+	}
+
+	return parseReply(reply)
+}
+
+// streamChunks envia o conteúdo como blocos prefixados pelo tamanho, finalizando com um bloco vazio
+func streamChunks(w io.Writer, content io.Reader) error {
+	buf := make([]byte, clamavChunkSize)
+	size := make([]byte, 4)
+
+	for {
+		n, readErr := content.Read(buf)
+		if n > 0 {
+			binary.BigEndian.PutUint32(size, uint32(n))
+			if _, err := w.Write(size); err != nil {
+				return fmt.Errorf("failed to send chunk to clamd: %w", err)
+			}
+			if _, err := w.Write(buf[:n]); err != nil {
+				return fmt.Errorf("failed to send chunk to clamd: %w", err)
+			}
+		}
+		if readErr == io.EOF {
+			break
+		}
+		if readErr != nil {
+			return fmt.Errorf("failed to read content to scan: %w", readErr)
+		}
+	}
+
+	binary.BigEndian.PutUint32(size, 0)
+	if _, err := w.Write(size); err != nil {
+		return fmt.Errorf("failed to finish clamd stream: %w", err)
+	}
+	return nil
+}
+
+// parseReply interpreta respostas como "stream: OK" e "stream: Eicar-Test-Signature FOUND"
+func parseReply(reply string) (ScanResult, error) {
+	reply = strings.TrimSpace(strings.TrimRight(reply, "\x00"))
+	reply = strings.TrimPrefix(reply, "stream: ")
+
+	switch {
+	case reply == "OK":
+		return ScanResult{}, nil
+	case strings.HasSuffix(reply, " FOUND"):
+		return ScanResult{
+			Infected:  true,
+			Signature: strings.TrimSuffix(reply, " FOUND"),
+		}, nil
+	default:
+		return ScanResult{}, fmt.Errorf("clamd error: %s", reply)
+	}
+}
@@ -0,0 +1,148 @@
+package scanner
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+// startFakeClamd sobe um servidor TCP que lê um INSTREAM e responde com reply(conteúdo)
+func startFakeClamd(t *testing.T, reply func(content []byte) string) string {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to start fake clamd: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		reader := bufio.NewReader(conn)
+		command, _ := reader.ReadString('\x00')
+		if command != "zINSTREAM\x00" {
+			conn.Write([]byte("UNKNOWN COMMAND\x00"))
+			return
+		}
+
+		var content bytes.Buffer
+		size := make([]byte, 4)
+		for {
+			if _, err := io.ReadFull(reader, size); err != nil {
+				return
+			}
+			n := binary.BigEndian.Uint32(size)
+			if n == 0 {
+				break
+			}
+			if _, err := io.CopyN(&content, reader, int64(n)); err != nil {
+				return
+			}
+		}
+
+		conn.Write([]byte(reply(content.Bytes()) + "\x00"))
+	}()
+
+	return listener.Addr().String()
+}
+
+func TestClamAVClient_Implementation(t *testing.T) {
+	// Verifica se ClamAVClient implementa a interface ScannerService
+	var _ ScannerService = (*ClamAVClient)(nil)
+}
+
+func TestNewClamAVClient_DefaultTimeout(t *testing.T) {
+	client := NewClamAVClient("localhost:3310", 0)
+
+	if client.timeout != 2*time.Minute {
+		t.Errorf("Expected default timeout of 2m, got %v", client.timeout)
+	}
+}
+
+func TestClamAVClient_ScanClean(t *testing.T) {
+	var received []byte
+	address := startFakeClamd(t, func(content []byte) string {
+		received = content
+		return "stream: OK"
+	})
+
+	client := NewClamAVClient(address, time.Second)
+	result, err := client.Scan(context.Background(), strings.NewReader("clean video"))
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+
+	if result.Infected {
+		t.Error("Expected clean result")
+	}
+	if string(received) != "clean video" {
+		t.Errorf("Expected clamd to receive the content, got %q", received)
+	}
+}
+
+func TestClamAVClient_ScanInfected(t *testing.T) {
+	address := startFakeClamd(t, func(content []byte) string {
+		return "stream: Eicar-Test-Signature FOUND"
+	})
+
+	client := NewClamAVClient(address, time.Second)
+	result, err := client.Scan(context.Background(), strings.NewReader("X5O!P%@AP"))
+	if err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+
+	if !result.Infected {
+		t.Fatal("Expected infected result")
+	}
+	if result.Signature != "Eicar-Test-Signature" {
+		t.Errorf("Expected signature Eicar-Test-Signature, got %s", result.Signature)
+	}
+}
+
+func TestClamAVClient_ScanLargeContent(t *testing.T) {
+	var received int
+	address := startFakeClamd(t, func(content []byte) string {
+		received = len(content)
+		return "stream: OK"
+	})
+
+	content := bytes.Repeat([]byte("a"), clamavChunkSize*3+17)
+	client := NewClamAVClient(address, time.Second)
+	if _, err := client.Scan(context.Background(), bytes.NewReader(content)); err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+
+	if received != len(content) {
+		t.Errorf("Expected %d bytes streamed, got %d", len(content), received)
+	}
+}
+
+func TestClamAVClient_ScanErrorReply(t *testing.T) {
+	address := startFakeClamd(t, func(content []byte) string {
+		return "INSTREAM size limit exceeded. ERROR"
+	})
+
+	client := NewClamAVClient(address, time.Second)
+	if _, err := client.Scan(context.Background(), strings.NewReader("video")); err == nil {
+		t.Error("Expected error for clamd error reply")
+	}
+}
+
+func TestClamAVClient_ConnectionError(t *testing.T) {
+	client := NewClamAVClient("127.0.0.1:1", time.Second)
+
+	if _, err := client.Scan(context.Background(), strings.NewReader("video")); err == nil {
+		t.Error("Expected error when clamd is unreachable")
+	}
+}
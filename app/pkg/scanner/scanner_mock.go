@@ -0,0 +1,19 @@
+package scanner
+
+import (
+	"context"
+	"io"
+)
+
+// MockScannerService é um mock da interface ScannerService para testes
+type MockScannerService struct {
+	ScanFunc func(ctx context.Context, content io.Reader) (ScanResult, error)
+}
+
+// Scan implementa ScannerService.Scan usando a função mock configurada
+func (m *MockScannerService) Scan(ctx context.Context, content io.Reader) (ScanResult, error) {
+	if m.ScanFunc != nil {
+		return m.ScanFunc(ctx, content)
+	}
+	return ScanResult{}, nil
+}
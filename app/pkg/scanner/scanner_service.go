@@ -0,0 +1,16 @@
+package scanner
+
+import (
+	"context"
+	"io"
+)
+
+// ScanResult descreve o resultado da verificação de um conteúdo
+type ScanResult struct {
+	Infected  bool
+	Signature string
+}
+
+type ScannerService interface {
+	Scan(ctx context.Context, content io.Reader) (ScanResult, error)
+}
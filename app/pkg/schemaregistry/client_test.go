@@ -0,0 +1,65 @@
+package schemaregistry
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestClient_Register(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.EscapedPath() != "/subjects/results%2Fvalue/versions" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.EscapedPath())
+		}
+		if r.Header.Get("Content-Type") != contentType {
+			t.Errorf("Expected content type %s, got %s", contentType, r.Header.Get("Content-Type"))
+		}
+		if user, pass, ok := r.BasicAuth(); !ok || user != "key" || pass != "secret" {
+			t.Errorf("Expected basic auth from the URL, got %q %q", user, pass)
+		}
+
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["schema"] != `{"type":"string"}` {
+			t.Errorf("Unexpected schema %q", body["schema"])
+		}
+		w.Write([]byte(`{"id":42}`))
+	}))
+	defer server.Close()
+
+	baseURL := strings.Replace(server.URL, "http://", "http://key:secret@", 1) + "/"
+	id, err := NewClient(baseURL).Register(context.Background(), "results/value", `{"type":"string"}`)
+	if err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if id != 42 {
+		t.Errorf("Expected id 42, got %d", id)
+	}
+}
+
+func TestClient_RegisterErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/subjects/incompatible/versions":
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(`{"error_code":409,"message":"Schema being registered is incompatible"}`))
+		default:
+			w.Write([]byte(`{}`))
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL)
+	if _, err := client.Register(context.Background(), "incompatible", "{}"); err == nil || !strings.Contains(err.Error(), "incompatible") {
+		t.Errorf("Expected the registry error, got %v", err)
+	}
+	if _, err := client.Register(context.Background(), "empty", "{}"); err == nil {
+		t.Error("Expected error for a reply without id")
+	}
+	if _, err := NewClient("http://127.0.0.1:1").Register(context.Background(), "s", "{}"); err == nil {
+		t.Error("Expected error for an unreachable registry")
+	}
+}
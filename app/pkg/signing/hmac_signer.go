@@ -0,0 +1,55 @@
+package signing
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+)
+
+// AlgorithmHMACSHA256 identifica assinaturas HMAC-SHA256 com segredo compartilhado
+const AlgorithmHMACSHA256 = "HMAC-SHA256"
+
+// HMACSigner implementa a interface Signer usando HMAC-SHA256 com um segredo local
+type HMACSigner struct {
+	secret []byte
+	keyID  string
+}
+
+// NewHMACSigner cria uma nova instância do HMACSigner
+func NewHMACSigner(secret []byte, keyID string) (*HMACSigner, error) {
+	if len(secret) == 0 {
+		return nil, fmt.Errorf("hmac secret is required")
+	}
+	return &HMACSigner{
+		secret: secret,
+		keyID:  keyID,
+	}, nil
+}
+
+// Sign calcula o HMAC-SHA256 do payload
+func (s *HMACSigner) Sign(ctx context.Context, payload []byte) (string, error) {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write(payload)
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil)), nil
+}
+
+// Verify confere se a assinatura corresponde ao payload
+func (s *HMACSigner) Verify(payload []byte, signature string) bool {
+	expected, err := s.Sign(context.Background(), payload)
+	if err != nil {
+		return false
+	}
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
+
+// Algorithm retorna o algoritmo utilizado pelo HMACSigner
+func (s *HMACSigner) Algorithm() string {
+	return AlgorithmHMACSHA256
+}
+
+// KeyID retorna o identificador configurado para o segredo
+func (s *HMACSigner) KeyID() string {
+	return s.keyID
+}
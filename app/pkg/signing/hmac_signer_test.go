@@ -0,0 +1,62 @@
+package signing
+
+import (
+	"context"
+	"testing"
+)
+
+func TestHMACSigner_Implementation(t *testing.T) {
+	// Verifica se HMACSigner implementa a interface Signer
+	var _ Signer = (*HMACSigner)(nil)
+}
+
+func TestNewHMACSigner_EmptySecret(t *testing.T) {
+	if _, err := NewHMACSigner(nil, "key-1"); err == nil {
+		t.Error("Expected error for empty secret")
+	}
+}
+
+func TestHMACSigner_SignAndVerify(t *testing.T) {
+	signer, err := NewHMACSigner([]byte("super-secret"), "key-1")
+	if err != nil {
+		t.Fatalf("NewHMACSigner failed: %v", err)
+	}
+
+	payload := []byte(`{"process_id":"123"}`)
+	signature, err := signer.Sign(context.Background(), payload)
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+
+	if signature == "" {
+		t.Fatal("Expected non-empty signature")
+	}
+	if !signer.Verify(payload, signature) {
+		t.Error("Expected signature to be valid")
+	}
+	if signer.Verify([]byte(`{"process_id":"456"}`), signature) {
+		t.Error("Expected signature to be invalid for a different payload")
+	}
+}
+
+func TestHMACSigner_DeterministicSignature(t *testing.T) {
+	signer, _ := NewHMACSigner([]byte("super-secret"), "")
+	ctx := context.Background()
+
+	first, _ := signer.Sign(ctx, []byte("payload"))
+	second, _ := signer.Sign(ctx, []byte("payload"))
+	if first != second {
+		t.Errorf("Expected deterministic signatures, got %s and %s", first, second)
+	}
+}
+
+func TestHMACSigner_Metadata(t *testing.T) {
+	signer, _ := NewHMACSigner([]byte("super-secret"), "key-1")
+
+	if signer.Algorithm() != AlgorithmHMACSHA256 {
+		t.Errorf("Expected algorithm %s, got %s", AlgorithmHMACSHA256, signer.Algorithm())
+	}
+	if signer.KeyID() != "key-1" {
+		t.Errorf("Expected key ID key-1, got %s", signer.KeyID())
+	}
+}
@@ -0,0 +1,62 @@
+package signing
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+)
+
+// AlgorithmKMSHMACSHA256 identifica assinaturas geradas por uma chave HMAC do KMS
+const AlgorithmKMSHMACSHA256 = "KMS-HMAC-SHA256"
+
+// KMSMacAPI representa as operações do KMS utilizadas pelo KMSSigner
+type KMSMacAPI interface {
+	GenerateMac(ctx context.Context, params *kms.GenerateMacInput, optFns ...func(*kms.Options)) (*kms.GenerateMacOutput, error)
+}
+
+// KMSSigner implementa a interface Signer usando uma chave HMAC gerenciada pelo AWS KMS
+type KMSSigner struct {
+	client KMSMacAPI
+	keyID  string
+}
+
+// NewKMSSigner cria uma nova instância do KMSSigner
+func NewKMSSigner(cfg aws.Config, keyID string) *KMSSigner {
+	return NewKMSSignerWithClient(kms.NewFromConfig(cfg), keyID)
+}
+
+// NewKMSSignerWithClient cria um KMSSigner a partir de um cliente já configurado
+func NewKMSSignerWithClient(client KMSMacAPI, keyID string) *KMSSigner {
+	return &KMSSigner{
+		client: client,
+		keyID:  keyID,
+	}
+}
+
+// Sign solicita ao KMS o MAC do payload
+func (s *KMSSigner) Sign(ctx context.Context, payload []byte) (string, error) {
+	result, err := s.client.GenerateMac(ctx, &kms.GenerateMacInput{
+		KeyId:        aws.String(s.keyID),
+		Message:      payload,
+		MacAlgorithm: types.MacAlgorithmSpecHmacSha256,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to generate mac with KMS: %w", err)
+	}
+
+	return base64.StdEncoding.EncodeToString(result.Mac), nil
+}
+
+// Algorithm retorna o algoritmo utilizado pelo KMSSigner
+func (s *KMSSigner) Algorithm() string {
+	return AlgorithmKMSHMACSHA256
+}
+
+// KeyID retorna o ID da chave KMS
+func (s *KMSSigner) KeyID() string {
+	return s.keyID
+}
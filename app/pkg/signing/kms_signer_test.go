@@ -0,0 +1,76 @@
+package signing
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/aws/aws-sdk-go-v2/service/kms/types"
+)
+
+type mockKMSMacAPI struct {
+	generateMacFunc func(ctx context.Context, params *kms.GenerateMacInput) (*kms.GenerateMacOutput, error)
+}
+
+func (m *mockKMSMacAPI) GenerateMac(ctx context.Context, params *kms.GenerateMacInput, optFns ...func(*kms.Options)) (*kms.GenerateMacOutput, error) {
+	return m.generateMacFunc(ctx, params)
+}
+
+func TestKMSSigner_Implementation(t *testing.T) {
+	// Verifica se KMSSigner implementa a interface Signer
+	var _ Signer = (*KMSSigner)(nil)
+}
+
+func TestNewKMSSigner(t *testing.T) {
+	signer := NewKMSSigner(aws.Config{Region: "us-east-1"}, "alias/results")
+
+	if signer == nil {
+		t.Fatal("NewKMSSigner returned nil")
+	}
+	if signer.KeyID() != "alias/results" {
+		t.Errorf("Expected key ID alias/results, got %s", signer.KeyID())
+	}
+	if signer.Algorithm() != AlgorithmKMSHMACSHA256 {
+		t.Errorf("Expected algorithm %s, got %s", AlgorithmKMSHMACSHA256, signer.Algorithm())
+	}
+}
+
+func TestKMSSigner_Sign(t *testing.T) {
+	mock := &mockKMSMacAPI{
+		generateMacFunc: func(ctx context.Context, params *kms.GenerateMacInput) (*kms.GenerateMacOutput, error) {
+			if aws.ToString(params.KeyId) != "alias/results" {
+				t.Errorf("Expected key alias/results, got %s", aws.ToString(params.KeyId))
+			}
+			if params.MacAlgorithm != types.MacAlgorithmSpecHmacSha256 {
+				t.Errorf("Expected HMAC_SHA_256, got %s", params.MacAlgorithm)
+			}
+			return &kms.GenerateMacOutput{Mac: []byte("raw-mac")}, nil
+		},
+	}
+
+	signer := NewKMSSignerWithClient(mock, "alias/results")
+	signature, err := signer.Sign(context.Background(), []byte("payload"))
+	if err != nil {
+		t.Fatalf("Sign failed: %v", err)
+	}
+
+	if signature != base64.StdEncoding.EncodeToString([]byte("raw-mac")) {
+		t.Errorf("Unexpected signature %s", signature)
+	}
+}
+
+func TestKMSSigner_SignError(t *testing.T) {
+	mock := &mockKMSMacAPI{
+		generateMacFunc: func(ctx context.Context, params *kms.GenerateMacInput) (*kms.GenerateMacOutput, error) {
+			return nil, errors.New("access denied")
+		},
+	}
+
+	signer := NewKMSSignerWithClient(mock, "alias/results")
+	if _, err := signer.Sign(context.Background(), []byte("payload")); err == nil {
+		t.Error("Expected error when KMS fails")
+	}
+}
@@ -0,0 +1,15 @@
+package signing
+
+import "context"
+
+// Signer assina payloads para que consumidores possam verificar sua origem
+type Signer interface {
+	// Sign retorna a assinatura do payload codificada em base64
+	Sign(ctx context.Context, payload []byte) (string, error)
+
+	// Algorithm retorna o identificador do algoritmo de assinatura
+	Algorithm() string
+
+	// KeyID retorna o identificador da chave utilizada
+	KeyID() string
+}
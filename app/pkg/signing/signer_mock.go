@@ -0,0 +1,28 @@
+package signing
+
+import "context"
+
+// MockSigner é um mock da interface Signer para testes
+type MockSigner struct {
+	SignFunc       func(ctx context.Context, payload []byte) (string, error)
+	AlgorithmValue string
+	KeyIDValue     string
+}
+
+// Sign implementa Signer.Sign usando a função mock configurada
+func (m *MockSigner) Sign(ctx context.Context, payload []byte) (string, error) {
+	if m.SignFunc != nil {
+		return m.SignFunc(ctx, payload)
+	}
+	return "mock-signature", nil
+}
+
+// Algorithm implementa Signer.Algorithm
+func (m *MockSigner) Algorithm() string {
+	return m.AlgorithmValue
+}
+
+// KeyID implementa Signer.KeyID
+func (m *MockSigner) KeyID() string {
+	return m.KeyIDValue
+}
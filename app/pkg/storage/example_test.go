@@ -78,12 +78,12 @@ func ExampleMockS3Service() {
 
 	// Cria um mock do serviço S3
 	mockS3 := &storage.MockS3Service{
-		GetObjectFunc: func(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
+		GetObjectFunc: func(ctx context.Context, bucket, key string, options storage.GetOptions) (io.ReadCloser, error) {
 			// Simula a leitura de um arquivo
 			content := "mocked content"
 			return io.NopCloser(bytes.NewReader([]byte(content))), nil
 		},
-		PutObjectFunc: func(ctx context.Context, bucket, key string, body io.Reader) (string, error) {
+		PutObjectFunc: func(ctx context.Context, bucket, key string, body io.Reader, options storage.PutOptions) (string, error) {
 			// Simula o upload bem-sucedido
 			return key, nil
 		},
@@ -0,0 +1,301 @@
+package storage
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// tempObjectPrefix marca os arquivos ainda sendo gravados, que não aparecem nas listagens
+const tempObjectPrefix = ".object_"
+
+// fileListPageSize é o tamanho das páginas de ListObjectPages, o mesmo do S3
+const fileListPageSize = 1000
+
+// FileClient implementa a interface StorageService no sistema de arquivos, para rodar o worker
+// sem nuvem: cada bucket é um diretório sob root e cada key um caminho relativo dentro dele
+type FileClient struct {
+	root string
+}
+
+// NewFileClient cria uma nova instância do FileClient; os buckets são criados sob demanda
+func NewFileClient(root string) *FileClient {
+	return &FileClient{
+		root: root,
+	}
+}
+
+// GetObject abre o arquivo do objeto, posicionado no início da faixa pedida com WithRange
+func (f *FileClient) GetObject(ctx context.Context, bucket, key string, options ...GetOption) (io.ReadCloser, error) {
+	objectPath, err := f.objectPath(bucket, key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(objectPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", ErrObjectNotFound, key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open object: %w", err)
+	}
+
+	o := NewGetOptions(options...)
+	if !o.Ranged() {
+		return file, nil
+	}
+	return readRange(file, o)
+}
+
+// readRange posiciona o arquivo no início da faixa e limita a leitura ao seu tamanho
+func readRange(file *os.File, o GetOptions) (io.ReadCloser, error) {
+	if _, err := file.Seek(o.RangeStart, io.SeekStart); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to read object range: %w", err)
+	}
+	if o.RangeLength == 0 {
+		return file, nil
+	}
+	return rangeReader{Reader: io.LimitReader(file, o.RangeLength), Closer: file}, nil
+}
+
+// rangeReader lê a faixa de um arquivo e o fecha
+type rangeReader struct {
+	io.Reader
+	io.Closer
+}
+
+// PutObject grava o objeto em um arquivo temporário e o renomeia sobre a key, como um PUT do
+// S3 que nunca expõe um objeto pela metade; as opções são ignoradas, pois os arquivos não têm
+// classe, metadados, tags nem criptografia
+func (f *FileClient) PutObject(ctx context.Context, bucket, key string, body io.Reader, options ...PutOption) (string, error) {
+	objectPath, err := f.objectPath(bucket, key)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(filepath.Dir(objectPath), 0755); err != nil {
+		return "", fmt.Errorf("failed to create object directory: %w", err)
+	}
+
+	file, err := os.CreateTemp(filepath.Dir(objectPath), tempObjectPrefix+"*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create object: %w", err)
+	}
+	defer os.Remove(file.Name())
+
+	if _, err := io.Copy(file, body); err != nil {
+		file.Close()
+		return "", fmt.Errorf("failed to write object: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return "", fmt.Errorf("failed to write object: %w", err)
+	}
+	if err := os.Rename(file.Name(), objectPath); err != nil {
+		return "", fmt.Errorf("failed to save object: %w", err)
+	}
+	return key, nil
+}
+
+// GetObjectIf abre o arquivo do objeto se o seu ETag for o esperado, posicionado na faixa pedida
+// com WithRange; como os arquivos não têm versões, pedir um version id sempre falha
+func (f *FileClient) GetObjectIf(ctx context.Context, bucket, key string, condition ObjectCondition, options ...GetOption) (io.ReadCloser, error) {
+	if condition.VersionID != "" {
+		return nil, fmt.Errorf("%w: %s has no versions", ErrObjectModified, key)
+	}
+	if condition.ETag == "" {
+		return f.GetObject(ctx, bucket, key, options...)
+	}
+	body, err := f.GetObject(ctx, bucket, key)
+	if err != nil {
+		return nil, err
+	}
+
+	file := body.(*os.File)
+	etag, err := fileETag(file)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to read object: %w", err)
+	}
+	if etag != strings.Trim(condition.ETag, `"`) {
+		file.Close()
+		return nil, fmt.Errorf("%w: %s", ErrObjectModified, key)
+	}
+	return readRange(file, NewGetOptions(options...))
+}
+
+// fileETag é o MD5 do conteúdo em hexadecimal, como o ETag de um objeto enviado ao S3 em uma
+// única parte
+func fileETag(file *os.File) (string, error) {
+	hash := md5.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// HeadObject retorna o tamanho do arquivo do objeto, o ETag calculado pelo conteúdo e o
+// content-type pela extensão; os arquivos não têm versões nem metadados
+func (f *FileClient) HeadObject(ctx context.Context, bucket, key string) (ObjectInfo, error) {
+	objectPath, err := f.objectPath(bucket, key)
+	if err != nil {
+		return ObjectInfo{}, err
+	}
+	info, err := os.Stat(objectPath)
+	if errors.Is(err, fs.ErrNotExist) || (err == nil && info.IsDir()) {
+		return ObjectInfo{}, fmt.Errorf("%w: %s", ErrObjectNotFound, key)
+	}
+	if err != nil {
+		return ObjectInfo{}, fmt.Errorf("failed to stat object: %w", err)
+	}
+
+	file, err := os.Open(objectPath)
+	if err != nil {
+		return ObjectInfo{}, fmt.Errorf("failed to open object: %w", err)
+	}
+	defer file.Close()
+	etag, err := fileETag(file)
+	if err != nil {
+		return ObjectInfo{}, fmt.Errorf("failed to read object: %w", err)
+	}
+	return ObjectInfo{Size: info.Size(), ETag: etag, ContentType: mime.TypeByExtension(filepath.Ext(key))}, nil
+}
+
+// CopyObject grava uma cópia do arquivo do objeto em outra key, ignorando as opções como
+// PutObject; como os arquivos não têm versões, copiar uma versão específica sempre falha
+func (f *FileClient) CopyObject(ctx context.Context, srcBucket, srcKey, srcVersionID, dstBucket, dstKey string, options ...PutOption) error {
+	if srcVersionID != "" {
+		return fmt.Errorf("failed to copy object version: %s has no versions", srcKey)
+	}
+	body, err := f.GetObject(ctx, srcBucket, srcKey)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+	_, err = f.PutObject(ctx, dstBucket, dstKey, body)
+	return err
+}
+
+// DeleteObject remove o arquivo do objeto; como no S3, remover um objeto inexistente não é erro
+func (f *FileClient) DeleteObject(ctx context.Context, bucket, key string) error {
+	objectPath, err := f.objectPath(bucket, key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(objectPath); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("failed to delete object: %w", err)
+	}
+	return nil
+}
+
+// DeleteObjectVersion remove o arquivo do objeto quando versionID é vazio; como os arquivos não
+// têm versões, remover uma versão específica sempre falha
+func (f *FileClient) DeleteObjectVersion(ctx context.Context, bucket, key, versionID string) error {
+	if versionID != "" {
+		return fmt.Errorf("failed to delete object version: %s has no versions", key)
+	}
+	return f.DeleteObject(ctx, bucket, key)
+}
+
+// ListObjects retorna as keys de todos os objetos sob o prefixo, em ordem lexicográfica
+func (f *FileClient) ListObjects(ctx context.Context, bucket, prefix string) ([]string, error) {
+	bucketPath, err := f.objectPath(bucket, "")
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	err = filepath.WalkDir(bucketPath, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), tempObjectPrefix) {
+			return nil
+		}
+		relative, err := filepath.Rel(bucketPath, path)
+		if err != nil {
+			return err
+		}
+		if key := filepath.ToSlash(relative); strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list objects: %w", err)
+	}
+	return keys, nil
+}
+
+// ListObjectPages percorre os objetos sob o prefixo em páginas de até 1000, como o S3
+func (f *FileClient) ListObjectPages(ctx context.Context, bucket, prefix string, fn func(page []ObjectSummary) error) error {
+	keys, err := f.ListObjects(ctx, bucket, prefix)
+	if err != nil {
+		return err
+	}
+
+	for start := 0; start < len(keys); start += fileListPageSize {
+		page := make([]ObjectSummary, 0, fileListPageSize)
+		for _, key := range keys[start:min(start+fileListPageSize, len(keys))] {
+			objectPath, err := f.objectPath(bucket, key)
+			if err != nil {
+				return err
+			}
+			info, err := os.Stat(objectPath)
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("failed to stat object: %w", err)
+			}
+			page = append(page, ObjectSummary{Key: key, Size: info.Size(), LastModified: info.ModTime()})
+		}
+		if err := fn(page); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteObjects remove os arquivos das keys um a um, retornando o erro de cada um que falhou
+func (f *FileClient) DeleteObjects(ctx context.Context, bucket string, keys []string) (map[string]error, error) {
+	failed := map[string]error{}
+	for _, key := range keys {
+		if err := f.DeleteObject(ctx, bucket, key); err != nil {
+			failed[key] = err
+		}
+	}
+	return failed, nil
+}
+
+// AbortMultipartUploads não tem o que cancelar, já que os objetos são gravados de uma vez
+func (f *FileClient) AbortMultipartUploads(ctx context.Context, bucket, prefix string) (int, error) {
+	return 0, nil
+}
+
+// objectPath resolve a key dentro do diretório do bucket, recusando caminhos que saiam dele
+func (f *FileClient) objectPath(bucket, key string) (string, error) {
+	if bucket == "" || strings.ContainsAny(bucket, `/\`) || bucket == "." || bucket == ".." {
+		return "", fmt.Errorf("invalid bucket: %q", bucket)
+	}
+	bucketPath := filepath.Join(f.root, bucket)
+	if key == "" {
+		return bucketPath, nil
+	}
+
+	objectPath := filepath.Join(bucketPath, filepath.FromSlash(key))
+	if !strings.HasPrefix(objectPath, bucketPath+string(filepath.Separator)) {
+		return "", fmt.Errorf("invalid key: %q", key)
+	}
+	return objectPath, nil
+}
@@ -0,0 +1,161 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestFileClient_Implementation(t *testing.T) {
+	// Verifica se FileClient implementa a interface StorageService
+	var _ StorageService = (*FileClient)(nil)
+}
+
+func TestFileClient_PutGetDelete(t *testing.T) {
+	client := NewFileClient(t.TempDir())
+	ctx := context.Background()
+
+	key, err := client.PutObject(ctx, "output", "processed/frames_1.zip", strings.NewReader("zip"), WithStorageClass("STANDARD_IA"))
+	if err != nil {
+		t.Fatalf("PutObject failed: %v", err)
+	}
+	if key != "processed/frames_1.zip" {
+		t.Errorf("Expected the key, got %s", key)
+	}
+
+	info, err := client.HeadObject(ctx, "output", key)
+	if err != nil || info.Size != 3 || info.ContentType != "application/zip" || info.ETag == "" {
+		t.Errorf("Expected the object size, content type and ETag, got %+v (%v)", info, err)
+	}
+	if body, err := client.GetObjectIf(ctx, "output", key, ObjectCondition{ETag: `"` + info.ETag + `"`}); err != nil {
+		t.Errorf("Expected the object read with its ETag, got %v", err)
+	} else if data, _ := io.ReadAll(body); string(data) != "zip" {
+		t.Errorf("Expected the whole object read with its ETag, got %q", data)
+	} else {
+		body.Close()
+	}
+	if body, err := client.GetObjectIf(ctx, "output", key, ObjectCondition{ETag: info.ETag}, WithRange(1, 0)); err != nil {
+		t.Errorf("Expected the range read with its ETag, got %v", err)
+	} else if data, _ := io.ReadAll(body); string(data) != "ip" {
+		t.Errorf("Expected the object from byte 1, got %q", data)
+	} else {
+		body.Close()
+	}
+	for _, condition := range []ObjectCondition{{ETag: "other"}, {VersionID: "v1"}} {
+		if _, err := client.GetObjectIf(ctx, "output", key, condition); !errors.Is(err, ErrObjectModified) {
+			t.Errorf("Expected ErrObjectModified for %+v, got %v", condition, err)
+		}
+	}
+
+	body, err := client.GetObject(ctx, "output", key)
+	if err != nil {
+		t.Fatalf("GetObject failed: %v", err)
+	}
+	data, _ := io.ReadAll(body)
+	body.Close()
+	if string(data) != "zip" {
+		t.Errorf("Expected the object content, got %q", data)
+	}
+
+	for _, tt := range []struct {
+		option GetOption
+		want   string
+	}{{WithRange(1, 1), "i"}, {WithRange(1, 0), "ip"}} {
+		body, err := client.GetObject(ctx, "output", key, tt.option)
+		if err != nil {
+			t.Fatalf("GetObject with range failed: %v", err)
+		}
+		data, _ := io.ReadAll(body)
+		body.Close()
+		if string(data) != tt.want {
+			t.Errorf("Expected %q, got %q", tt.want, data)
+		}
+	}
+
+	if err := client.CopyObject(ctx, "output", key, "", "quarantine", "1/frames.zip"); err != nil {
+		t.Fatalf("CopyObject failed: %v", err)
+	}
+	if copied, err := client.HeadObject(ctx, "quarantine", "1/frames.zip"); err != nil || copied.ETag != info.ETag {
+		t.Errorf("Expected an identical copy, got %+v (%v)", copied, err)
+	}
+	if err := client.CopyObject(ctx, "output", "missing.zip", "", "quarantine", "missing.zip"); !errors.Is(err, ErrObjectNotFound) {
+		t.Errorf("Expected ErrObjectNotFound copying a missing object, got %v", err)
+	}
+
+	if err := client.DeleteObjectVersion(ctx, "output", key, "v1"); err == nil {
+		t.Error("Expected deleting a version to fail, files have no versions")
+	}
+	if _, err := client.HeadObject(ctx, "output", key); err != nil {
+		t.Errorf("Expected the object kept after a failed version delete, got %v", err)
+	}
+
+	if err := client.DeleteObject(ctx, "output", key); err != nil {
+		t.Fatalf("DeleteObject failed: %v", err)
+	}
+	if _, err := client.GetObject(ctx, "output", key); !errors.Is(err, ErrObjectNotFound) {
+		t.Errorf("Expected ErrObjectNotFound after delete, got %v", err)
+	}
+	if _, err := client.HeadObject(ctx, "output", key); !errors.Is(err, ErrObjectNotFound) {
+		t.Errorf("Expected ErrObjectNotFound from HeadObject after delete, got %v", err)
+	}
+	if err := client.DeleteObject(ctx, "output", key); err != nil {
+		t.Errorf("Expected deleting a missing object to succeed, got %v", err)
+	}
+}
+
+func TestFileClient_ListObjects(t *testing.T) {
+	client := NewFileClient(t.TempDir())
+	ctx := context.Background()
+
+	if keys, err := client.ListObjects(ctx, "output", ""); err != nil || len(keys) != 0 {
+		t.Errorf("Expected no keys in a missing bucket, got %v (%v)", keys, err)
+	}
+
+	for _, key := range []string{"timelines/2/b.json", "timelines/1/a.json", "processed/frames_1.zip"} {
+		if _, err := client.PutObject(ctx, "output", key, strings.NewReader("{}")); err != nil {
+			t.Fatalf("PutObject failed: %v", err)
+		}
+	}
+
+	keys, err := client.ListObjects(ctx, "output", "timelines/")
+	if err != nil {
+		t.Fatalf("ListObjects failed: %v", err)
+	}
+	if len(keys) != 2 || keys[0] != "timelines/1/a.json" || keys[1] != "timelines/2/b.json" {
+		t.Errorf("Expected the timeline keys in order, got %v", keys)
+	}
+
+	var pages [][]ObjectSummary
+	err = client.ListObjectPages(ctx, "output", "timelines/", func(page []ObjectSummary) error {
+		pages = append(pages, page)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ListObjectPages failed: %v", err)
+	}
+	if len(pages) != 1 || len(pages[0]) != 2 || pages[0][0].Size != 2 || pages[0][0].LastModified.IsZero() {
+		t.Errorf("Expected one page with the size and modification time, got %+v", pages)
+	}
+
+	failed, err := client.DeleteObjects(ctx, "output", []string{"timelines/1/a.json", "timelines/2/b.json", "../escape"})
+	if err != nil || len(failed) != 1 || failed["../escape"] == nil {
+		t.Errorf("Expected only the key outside the bucket to fail, got %v (%v)", failed, err)
+	}
+	if keys, _ := client.ListObjects(ctx, "output", ""); len(keys) != 1 || keys[0] != "processed/frames_1.zip" {
+		t.Errorf("Expected the deleted keys gone, got %v", keys)
+	}
+}
+
+func TestFileClient_RejectsPathsOutsideBucket(t *testing.T) {
+	client := NewFileClient(t.TempDir())
+	ctx := context.Background()
+
+	if _, err := client.PutObject(ctx, "output", "../input/video.mp4", strings.NewReader("x")); err == nil {
+		t.Error("Expected a key escaping the bucket to be rejected")
+	}
+	if _, err := client.GetObject(ctx, "../output", "video.mp4"); err == nil {
+		t.Error("Expected an invalid bucket to be rejected")
+	}
+}
@@ -2,18 +2,48 @@ package storage
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
 	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
 	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
 )
 
+// s3DeleteBatchSize é o máximo de keys de uma chamada DeleteObjects
+const s3DeleteBatchSize = 1000
+
 // S3Client implementa a interface StorageService usando o AWS SDK para S3
 type S3Client struct {
 	client *s3.Client
 }
 
+// S3Options ajusta o cliente para object stores compatíveis com S3 (MinIO, on-prem)
+type S3Options struct {
+	// Endpoint substitui o endpoint da AWS, ex. https://minio.internal:9000
+	Endpoint string
+
+	// UsePathStyle usa URLs https://host/bucket/key em vez de https://bucket.host/key,
+	// necessário quando o DNS não resolve subdomínios por bucket
+	UsePathStyle bool
+
+	// CABundle é um arquivo PEM com CAs aceitas além das do sistema (certificados internos)
+	CABundle string
+
+	// InsecureSkipVerify desativa a verificação do certificado; apenas para desenvolvimento
+	InsecureSkipVerify bool
+}
+
 // NewS3Client cria uma nova instância do S3Client
 func NewS3Client(cfg aws.Config) *S3Client {
 	return &S3Client{
@@ -21,14 +51,87 @@ func NewS3Client(cfg aws.Config) *S3Client {
 	}
 }
 
-// GetObject recupera um objeto do S3 a partir de sua key
-func (s *S3Client) GetObject(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
+// NewS3ClientWithOptions cria um S3Client com endpoint, estilo de URL e TLS customizados
+func NewS3ClientWithOptions(cfg aws.Config, options S3Options) (*S3Client, error) {
+	tlsConfig, err := options.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
+		if options.Endpoint != "" {
+			o.BaseEndpoint = aws.String(options.Endpoint)
+		}
+		o.UsePathStyle = options.UsePathStyle
+		if tlsConfig != nil {
+			o.HTTPClient = awshttp.NewBuildableClient().WithTransportOptions(func(tr *http.Transport) {
+				tr.TLSClientConfig = tlsConfig
+			})
+		}
+	})
+	return &S3Client{client: client}, nil
+}
+
+// roleSessionName identifica nos logs do CloudTrail as sessões abertas por NewS3ClientWithRole
+const roleSessionName = "video-processor"
+
+// NewS3ClientWithRole cria um S3Client que assume roleARN, ex. a role de um bucket de outra
+// conta; as credenciais da role são renovadas antes de expirar. externalID é enviado quando não
+// vazio, conforme exigido pela trust policy da role
+func NewS3ClientWithRole(cfg aws.Config, options S3Options, roleARN, externalID string) (*S3Client, error) {
+	provider := stscreds.NewAssumeRoleProvider(sts.NewFromConfig(cfg), roleARN, func(o *stscreds.AssumeRoleOptions) {
+		o.RoleSessionName = roleSessionName
+		if externalID != "" {
+			o.ExternalID = aws.String(externalID)
+		}
+	})
+	roleConfig := cfg.Copy()
+	roleConfig.Credentials = aws.NewCredentialsCache(provider)
+	return NewS3ClientWithOptions(roleConfig, options)
+}
+
+// tlsConfig devolve nil quando o TLS padrão do SDK atende
+func (o S3Options) tlsConfig() (*tls.Config, error) {
+	if o.CABundle == "" && !o.InsecureSkipVerify {
+		return nil, nil
+	}
+
+	config := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: o.InsecureSkipVerify,
+	}
+	if o.CABundle != "" {
+		pem, err := os.ReadFile(o.CABundle)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in CA bundle %s", o.CABundle)
+		}
+		config.RootCAs = pool
+	}
+	return config, nil
+}
+
+// GetObject recupera um objeto do S3 a partir de sua key, ou apenas a faixa pedida com WithRange
+func (s *S3Client) GetObject(ctx context.Context, bucket, key string, options ...GetOption) (io.ReadCloser, error) {
 	input := &s3.GetObjectInput{
 		Bucket: aws.String(bucket),
 		Key:    aws.String(key),
 	}
+	if o := NewGetOptions(options...); o.Ranged() {
+		input.Range = aws.String(o.rangeHeader())
+	}
 
 	result, err := s.client.GetObject(ctx, input)
+	var noSuchKey *types.NoSuchKey
+	if errors.As(err, &noSuchKey) {
+		return nil, fmt.Errorf("%w: %s", ErrObjectNotFound, key)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get object from S3: %w", err)
 	}
@@ -36,12 +139,86 @@ func (s *S3Client) GetObject(ctx context.Context, bucket, key string) (io.ReadCl
 	return result.Body, nil
 }
 
-// PutObject persiste um objeto no S3 e retorna sua key
-func (s *S3Client) PutObject(ctx context.Context, bucket, key string, body io.Reader) (string, error) {
-	input := &s3.PutObjectInput{
+// GetObjectIf recupera o objeto somente se ele ainda tiver o ETag esperado (If-Match), lendo a
+// versão pedida quando condition.VersionID é informado e apenas a faixa pedida com WithRange
+func (s *S3Client) GetObjectIf(ctx context.Context, bucket, key string, condition ObjectCondition, options ...GetOption) (io.ReadCloser, error) {
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}
+	if o := NewGetOptions(options...); o.Ranged() {
+		input.Range = aws.String(o.rangeHeader())
+	}
+	if condition.ETag != "" {
+		input.IfMatch = aws.String(`"` + strings.Trim(condition.ETag, `"`) + `"`)
+	}
+	if condition.VersionID != "" {
+		input.VersionId = aws.String(condition.VersionID)
+	}
+
+	result, err := s.client.GetObject(ctx, input)
+	var noSuchKey *types.NoSuchKey
+	if errors.As(err, &noSuchKey) {
+		return nil, fmt.Errorf("%w: %s", ErrObjectNotFound, key)
+	}
+	var response *awshttp.ResponseError
+	if errors.As(err, &response) && response.HTTPStatusCode() == http.StatusPreconditionFailed {
+		return nil, fmt.Errorf("%w: %s", ErrObjectModified, key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object from S3: %w", err)
+	}
+
+	return result.Body, nil
+}
+
+// HeadObject retorna o tamanho, o ETag, o content-type e os metadados de um objeto do S3 sem baixá-lo
+func (s *S3Client) HeadObject(ctx context.Context, bucket, key string) (ObjectInfo, error) {
+	result, err := s.client.HeadObject(ctx, &s3.HeadObjectInput{
 		Bucket: aws.String(bucket),
 		Key:    aws.String(key),
-		Body:   body,
+	})
+	var notFound *types.NotFound
+	if errors.As(err, &notFound) {
+		return ObjectInfo{}, fmt.Errorf("%w: %s", ErrObjectNotFound, key)
+	}
+	if err != nil {
+		return ObjectInfo{}, fmt.Errorf("failed to head object in S3: %w", err)
+	}
+
+	return ObjectInfo{
+		Size:        aws.ToInt64(result.ContentLength),
+		ETag:        strings.Trim(aws.ToString(result.ETag), `"`),
+		VersionID:   aws.ToString(result.VersionId),
+		ContentType: aws.ToString(result.ContentType),
+		Metadata:    result.Metadata,
+	}, nil
+}
+
+// PutObject persiste um objeto no S3 e retorna sua key.
+// Sem opções, a classe, a criptografia e o content-type padrão do bucket são utilizados
+func (s *S3Client) PutObject(ctx context.Context, bucket, key string, body io.Reader, options ...PutOption) (string, error) {
+	o := NewPutOptions(options...)
+	input := &s3.PutObjectInput{
+		Bucket:   aws.String(bucket),
+		Key:      aws.String(key),
+		Body:     body,
+		Metadata: o.Metadata,
+	}
+	if o.StorageClass != "" {
+		input.StorageClass = types.StorageClass(o.StorageClass)
+	}
+	if o.ContentType != "" {
+		input.ContentType = aws.String(o.ContentType)
+	}
+	if o.ServerSideEncryption != "" {
+		input.ServerSideEncryption = types.ServerSideEncryption(o.ServerSideEncryption)
+	}
+	if o.KMSKeyID != "" {
+		input.SSEKMSKeyId = aws.String(o.KMSKeyID)
+	}
+	if len(o.Tags) > 0 {
+		input.Tagging = aws.String(encodeTags(o.Tags))
 	}
 
 	_, err := s.client.PutObject(ctx, input)
@@ -52,6 +229,48 @@ func (s *S3Client) PutObject(ctx context.Context, bucket, key string, body io.Re
 	return key, nil
 }
 
+// CopyObject copia um objeto dentro do S3, sem que o conteúdo passe pelo worker; com
+// srcVersionID, copia essa versão. Uma única cópia vai até 5 GB, o limite do CopyObject.
+// Das opções valem a classe, a criptografia e as tags, que substituem as da origem; o
+// content-type e os metadados são sempre copiados da origem
+func (s *S3Client) CopyObject(ctx context.Context, srcBucket, srcKey, srcVersionID, dstBucket, dstKey string, options ...PutOption) error {
+	source := url.PathEscape(srcBucket + "/" + srcKey)
+	if srcVersionID != "" {
+		source += "?versionId=" + url.QueryEscape(srcVersionID)
+	}
+
+	o := NewPutOptions(options...)
+	input := &s3.CopyObjectInput{
+		Bucket:     aws.String(dstBucket),
+		Key:        aws.String(dstKey),
+		CopySource: aws.String(source),
+	}
+	if o.StorageClass != "" {
+		input.StorageClass = types.StorageClass(o.StorageClass)
+	}
+	if o.ServerSideEncryption != "" {
+		input.ServerSideEncryption = types.ServerSideEncryption(o.ServerSideEncryption)
+	}
+	if o.KMSKeyID != "" {
+		input.SSEKMSKeyId = aws.String(o.KMSKeyID)
+	}
+	if len(o.Tags) > 0 {
+		input.Tagging = aws.String(encodeTags(o.Tags))
+		input.TaggingDirective = types.TaggingDirectiveReplace
+	}
+
+	_, err := s.client.CopyObject(ctx, input)
+	var noSuchKey *types.NoSuchKey
+	if errors.As(err, &noSuchKey) {
+		return fmt.Errorf("%w: %s", ErrObjectNotFound, srcKey)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to copy object in S3: %w", err)
+	}
+
+	return nil
+}
+
 // DeleteObject remove um objeto do S3
 func (s *S3Client) DeleteObject(ctx context.Context, bucket, key string) error {
 	input := &s3.DeleteObjectInput{
@@ -66,3 +285,139 @@ func (s *S3Client) DeleteObject(ctx context.Context, bucket, key string) error {
 
 	return nil
 }
+
+// DeleteObjectVersion remove definitivamente uma versão de um objeto do S3. Em um bucket com
+// versionamento, DeleteObject apenas cria um delete marker sobre a versão mais recente, que pode
+// nem ser a lida; sem versionID, o comportamento é o de DeleteObject
+func (s *S3Client) DeleteObjectVersion(ctx context.Context, bucket, key, versionID string) error {
+	input := &s3.DeleteObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}
+	if versionID != "" {
+		input.VersionId = aws.String(versionID)
+	}
+
+	_, err := s.client.DeleteObject(ctx, input)
+	if err != nil {
+		return fmt.Errorf("failed to delete object version from S3: %w", err)
+	}
+
+	return nil
+}
+
+// ListObjects retorna as keys de todos os objetos sob o prefixo, percorrendo todas as páginas
+func (s *S3Client) ListObjects(ctx context.Context, bucket, prefix string) ([]string, error) {
+	paginator := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(bucket),
+		Prefix: aws.String(prefix),
+	})
+
+	var keys []string
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list objects from S3: %w", err)
+		}
+		for _, object := range page.Contents {
+			keys = append(keys, aws.ToString(object.Key))
+		}
+	}
+
+	return keys, nil
+}
+
+// ListObjectPages percorre os objetos sob o prefixo uma página (até 1000 objetos) por vez,
+// chamando fn para cada uma; um erro de fn interrompe a listagem e é retornado
+func (s *S3Client) ListObjectPages(ctx context.Context, bucket, prefix string, fn func(page []ObjectSummary) error) error {
+	paginator := s3.NewListObjectsV2Paginator(s.client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(bucket),
+		Prefix: aws.String(prefix),
+	})
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to list objects from S3: %w", err)
+		}
+		summaries := make([]ObjectSummary, 0, len(page.Contents))
+		for _, object := range page.Contents {
+			summaries = append(summaries, ObjectSummary{
+				Key:          aws.ToString(object.Key),
+				Size:         aws.ToInt64(object.Size),
+				LastModified: aws.ToTime(object.LastModified),
+			})
+		}
+		if err := fn(summaries); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DeleteObjects remove as keys com DeleteObjects, em lotes de até 1000 (o limite do S3). Retorna
+// o erro de cada key que o S3 não removeu; o erro retornado é o de um lote inteiro, quando as
+// keys dos lotes seguintes nem chegam a ser enviadas
+func (s *S3Client) DeleteObjects(ctx context.Context, bucket string, keys []string) (map[string]error, error) {
+	failed := map[string]error{}
+	for start := 0; start < len(keys); start += s3DeleteBatchSize {
+		batch := keys[start:min(start+s3DeleteBatchSize, len(keys))]
+		objects := make([]types.ObjectIdentifier, 0, len(batch))
+		for _, key := range batch {
+			objects = append(objects, types.ObjectIdentifier{Key: aws.String(key)})
+		}
+
+		result, err := s.client.DeleteObjects(ctx, &s3.DeleteObjectsInput{
+			Bucket: aws.String(bucket),
+			Delete: &types.Delete{Objects: objects, Quiet: aws.Bool(true)},
+		})
+		if err != nil {
+			return failed, fmt.Errorf("failed to delete objects from S3: %w", err)
+		}
+		for _, objectErr := range result.Errors {
+			failed[aws.ToString(objectErr.Key)] = fmt.Errorf("%s: %s", aws.ToString(objectErr.Code), aws.ToString(objectErr.Message))
+		}
+	}
+
+	return failed, nil
+}
+
+// AbortMultipartUploads cancela os uploads multipart incompletos sob o prefixo, liberando as
+// partes já enviadas, e retorna quantos foram cancelados
+func (s *S3Client) AbortMultipartUploads(ctx context.Context, bucket, prefix string) (int, error) {
+	paginator := s3.NewListMultipartUploadsPaginator(s.client, &s3.ListMultipartUploadsInput{
+		Bucket: aws.String(bucket),
+		Prefix: aws.String(prefix),
+	})
+
+	aborted := 0
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return aborted, fmt.Errorf("failed to list multipart uploads from S3: %w", err)
+		}
+		for _, upload := range page.Uploads {
+			_, err := s.client.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+				Bucket:   aws.String(bucket),
+				Key:      upload.Key,
+				UploadId: upload.UploadId,
+			})
+			if err != nil {
+				return aborted, fmt.Errorf("failed to abort multipart upload on S3: %w", err)
+			}
+			aborted++
+		}
+	}
+
+	return aborted, nil
+}
+
+// encodeTags escreve as tags no formato de query string do cabeçalho x-amz-tagging
+func encodeTags(tags map[string]string) string {
+	values := url.Values{}
+	for key, value := range tags {
+		values.Set(key, value)
+	}
+	return values.Encode()
+}
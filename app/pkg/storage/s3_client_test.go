@@ -3,7 +3,12 @@ package storage
 import (
 	"bytes"
 	"context"
+	"encoding/pem"
 	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -37,7 +42,7 @@ func TestMockS3Service_GetObject(t *testing.T) {
 	expectedContent := "test content"
 
 	mock := &MockS3Service{
-		GetObjectFunc: func(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
+		GetObjectFunc: func(ctx context.Context, bucket, key string, options GetOptions) (io.ReadCloser, error) {
 			return io.NopCloser(strings.NewReader(expectedContent)), nil
 		},
 	}
@@ -63,7 +68,7 @@ func TestMockS3Service_PutObject(t *testing.T) {
 	expectedKey := "test-key"
 
 	mock := &MockS3Service{
-		PutObjectFunc: func(ctx context.Context, bucket, key string, body io.Reader) (string, error) {
+		PutObjectFunc: func(ctx context.Context, bucket, key string, body io.Reader, options PutOptions) (string, error) {
 			return key, nil
 		},
 	}
@@ -103,3 +108,182 @@ func TestS3Client_Integration(t *testing.T) {
 	// 3. Implementar os testes de PutObject e GetObject reais
 	t.Log("S3Client created successfully for integration testing")
 }
+
+func TestNewS3ClientWithOptions_PathStyleAndCABundle(t *testing.T) {
+	var requestedPath string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestedPath = r.URL.Path
+		w.Write([]byte("video"))
+	}))
+	defer server.Close()
+
+	// O certificado do servidor de teste só é aceito através do CA bundle
+	caBundle := filepath.Join(t.TempDir(), "ca.pem")
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caBundle, certPEM, 0600); err != nil {
+		t.Fatalf("Failed to write CA bundle: %v", err)
+	}
+
+	cfg := aws.Config{
+		Region: "us-east-1",
+		Credentials: aws.CredentialsProviderFunc(func(ctx context.Context) (aws.Credentials, error) {
+			return aws.Credentials{AccessKeyID: "minio", SecretAccessKey: "minio123"}, nil
+		}),
+	}
+	client, err := NewS3ClientWithOptions(cfg, S3Options{
+		Endpoint:     server.URL,
+		UsePathStyle: true,
+		CABundle:     caBundle,
+	})
+	if err != nil {
+		t.Fatalf("NewS3ClientWithOptions failed: %v", err)
+	}
+
+	body, err := client.GetObject(context.Background(), "videos", "uploads/a.mp4")
+	if err != nil {
+		t.Fatalf("GetObject failed: %v", err)
+	}
+	body.Close()
+
+	if requestedPath != "/videos/uploads/a.mp4" {
+		t.Errorf("Expected a path-style request, got %s", requestedPath)
+	}
+}
+
+func TestS3Client_Options(t *testing.T) {
+	headers := make(map[string]http.Header)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers[r.Method] = r.Header.Clone()
+		io.Copy(io.Discard, r.Body)
+		w.Write([]byte("video"))
+	}))
+	defer server.Close()
+
+	cfg := aws.Config{
+		Region: "us-east-1",
+		Credentials: aws.CredentialsProviderFunc(func(ctx context.Context) (aws.Credentials, error) {
+			return aws.Credentials{AccessKeyID: "minio", SecretAccessKey: "minio123"}, nil
+		}),
+	}
+	client, err := NewS3ClientWithOptions(cfg, S3Options{Endpoint: server.URL, UsePathStyle: true})
+	if err != nil {
+		t.Fatalf("NewS3ClientWithOptions failed: %v", err)
+	}
+
+	body, err := client.GetObject(context.Background(), "videos", "a.mp4", WithRange(100, 50))
+	if err != nil {
+		t.Fatalf("GetObject failed: %v", err)
+	}
+	body.Close()
+	if got := headers[http.MethodGet].Get("Range"); got != "bytes=100-149" {
+		t.Errorf("Expected bytes=100-149, got %q", got)
+	}
+
+	body, err = client.GetObjectIf(context.Background(), "videos", "a.mp4", ObjectCondition{ETag: "abc"}, WithRange(100, 0))
+	if err != nil {
+		t.Fatalf("GetObjectIf failed: %v", err)
+	}
+	body.Close()
+	if got := headers[http.MethodGet]; got.Get("Range") != "bytes=100-" || got.Get("If-Match") != `"abc"` {
+		t.Errorf("Expected the conditional range read, got Range %q and If-Match %q", got.Get("Range"), got.Get("If-Match"))
+	}
+
+	_, err = client.PutObject(context.Background(), "output", "frames.zip", strings.NewReader("zip"),
+		WithStorageClass("STANDARD_IA"),
+		WithContentType("application/zip"),
+		WithMetadata(map[string]string{"process-id": "123"}),
+		WithServerSideEncryption("aws:kms", "key-1"),
+		WithTags(map[string]string{"expires-at": "2024-01-08", "retention-days": "7"}),
+	)
+	if err != nil {
+		t.Fatalf("PutObject failed: %v", err)
+	}
+	put := headers[http.MethodPut]
+	expected := map[string]string{
+		"X-Amz-Storage-Class":                         "STANDARD_IA",
+		"Content-Type":                                "application/zip",
+		"X-Amz-Meta-Process-Id":                       "123",
+		"X-Amz-Server-Side-Encryption":                "aws:kms",
+		"X-Amz-Server-Side-Encryption-Aws-Kms-Key-Id": "key-1",
+		"X-Amz-Tagging":                               "expires-at=2024-01-08&retention-days=7",
+	}
+	for header, value := range expected {
+		if got := put.Get(header); got != value {
+			t.Errorf("Expected %s %q, got %q", header, value, got)
+		}
+	}
+}
+
+func TestS3Client_CopyObjectOptions(t *testing.T) {
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+		w.Write([]byte(`<CopyObjectResult><ETag>"abc"</ETag></CopyObjectResult>`))
+	}))
+	defer server.Close()
+
+	cfg := aws.Config{
+		Region: "us-east-1",
+		Credentials: aws.CredentialsProviderFunc(func(ctx context.Context) (aws.Credentials, error) {
+			return aws.Credentials{AccessKeyID: "minio", SecretAccessKey: "minio123"}, nil
+		}),
+	}
+	client, err := NewS3ClientWithOptions(cfg, S3Options{Endpoint: server.URL, UsePathStyle: true})
+	if err != nil {
+		t.Fatalf("NewS3ClientWithOptions failed: %v", err)
+	}
+
+	err = client.CopyObject(context.Background(), "input", "video.mp4", "", "quarantine", "q/1/video.mp4",
+		WithTags(map[string]string{"quarantine-reason": "empty_video"}))
+	if err != nil {
+		t.Fatalf("CopyObject failed: %v", err)
+	}
+	expected := map[string]string{
+		"X-Amz-Copy-Source":       "input%2Fvideo.mp4",
+		"X-Amz-Tagging":           "quarantine-reason=empty_video",
+		"X-Amz-Tagging-Directive": "REPLACE",
+	}
+	for name, value := range expected {
+		if got := header.Get(name); got != value {
+			t.Errorf("Expected %s %q, got %q", name, value, got)
+		}
+	}
+
+	// Without tags, the copy keeps the tags of the source
+	if err := client.CopyObject(context.Background(), "input", "video.mp4", "", "archive", "video.mp4"); err != nil {
+		t.Fatalf("CopyObject failed: %v", err)
+	}
+	if got := header.Get("X-Amz-Tagging-Directive"); got != "" {
+		t.Errorf("Expected no tagging directive, got %q", got)
+	}
+}
+
+func TestGetOptions_RangeHeader(t *testing.T) {
+	tests := []struct {
+		options GetOptions
+		want    string
+	}{
+		{NewGetOptions(WithRange(0, 10)), "bytes=0-9"},
+		{NewGetOptions(WithRange(10, 0)), "bytes=10-"},
+	}
+	for _, tt := range tests {
+		if got := tt.options.rangeHeader(); got != tt.want {
+			t.Errorf("Expected %s, got %s", tt.want, got)
+		}
+	}
+	if NewGetOptions().Ranged() {
+		t.Error("Expected no range without options")
+	}
+}
+
+func TestNewS3ClientWithOptions_InvalidCABundle(t *testing.T) {
+	caBundle := filepath.Join(t.TempDir(), "ca.pem")
+	os.WriteFile(caBundle, []byte("not a certificate"), 0600)
+
+	if _, err := NewS3ClientWithOptions(aws.Config{}, S3Options{CABundle: caBundle}); err == nil {
+		t.Error("Expected an error for a CA bundle without certificates")
+	}
+	if _, err := NewS3ClientWithOptions(aws.Config{}, S3Options{CABundle: filepath.Join(t.TempDir(), "missing.pem")}); err == nil {
+		t.Error("Expected an error for a missing CA bundle")
+	}
+}
@@ -7,27 +7,63 @@ import (
 
 // MockS3Service é um mock da interface StorageService para testes
 type MockS3Service struct {
-	GetObjectFunc    func(ctx context.Context, bucket, key string) (io.ReadCloser, error)
-	PutObjectFunc    func(ctx context.Context, bucket, key string, body io.Reader) (string, error)
+	GetObjectFunc  func(ctx context.Context, bucket, key string, options GetOptions) (io.ReadCloser, error)
+	HeadObjectFunc func(ctx context.Context, bucket, key string) (ObjectInfo, error)
+
+	GetObjectIfFunc  func(ctx context.Context, bucket, key string, condition ObjectCondition) (io.ReadCloser, error)
+	PutObjectFunc    func(ctx context.Context, bucket, key string, body io.Reader, options PutOptions) (string, error)
 	DeleteObjectFunc func(ctx context.Context, bucket, key string) error
+	ListObjectsFunc  func(ctx context.Context, bucket, prefix string) ([]string, error)
+
+	DeleteObjectVersionFunc func(ctx context.Context, bucket, key, versionID string) error
+	CopyObjectFunc          func(ctx context.Context, srcBucket, srcKey, srcVersionID, dstBucket, dstKey string, options PutOptions) error
+	ListObjectPagesFunc     func(ctx context.Context, bucket, prefix string, fn func(page []ObjectSummary) error) error
+	DeleteObjectsFunc       func(ctx context.Context, bucket string, keys []string) (map[string]error, error)
+
+	AbortMultipartUploadsFunc func(ctx context.Context, bucket, prefix string) (int, error)
 }
 
 // GetObject implementa StorageService.GetObject usando a função mock configurada
-func (m *MockS3Service) GetObject(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
+func (m *MockS3Service) GetObject(ctx context.Context, bucket, key string, options ...GetOption) (io.ReadCloser, error) {
 	if m.GetObjectFunc != nil {
-		return m.GetObjectFunc(ctx, bucket, key)
+		return m.GetObjectFunc(ctx, bucket, key, NewGetOptions(options...))
+	}
+	return nil, nil
+}
+
+// HeadObject implementa StorageService.HeadObject usando a função mock configurada
+func (m *MockS3Service) HeadObject(ctx context.Context, bucket, key string) (ObjectInfo, error) {
+	if m.HeadObjectFunc != nil {
+		return m.HeadObjectFunc(ctx, bucket, key)
+	}
+	return ObjectInfo{}, nil
+}
+
+// GetObjectIf implementa StorageService.GetObjectIf usando a função mock configurada; as
+// opções são ignoradas
+func (m *MockS3Service) GetObjectIf(ctx context.Context, bucket, key string, condition ObjectCondition, options ...GetOption) (io.ReadCloser, error) {
+	if m.GetObjectIfFunc != nil {
+		return m.GetObjectIfFunc(ctx, bucket, key, condition)
 	}
 	return nil, nil
 }
 
 // PutObject implementa StorageService.PutObject usando a função mock configurada
-func (m *MockS3Service) PutObject(ctx context.Context, bucket, key string, body io.Reader) (string, error) {
+func (m *MockS3Service) PutObject(ctx context.Context, bucket, key string, body io.Reader, options ...PutOption) (string, error) {
 	if m.PutObjectFunc != nil {
-		return m.PutObjectFunc(ctx, bucket, key, body)
+		return m.PutObjectFunc(ctx, bucket, key, body, NewPutOptions(options...))
 	}
 	return key, nil
 }
 
+// CopyObject implementa StorageService.CopyObject usando a função mock configurada
+func (m *MockS3Service) CopyObject(ctx context.Context, srcBucket, srcKey, srcVersionID, dstBucket, dstKey string, options ...PutOption) error {
+	if m.CopyObjectFunc != nil {
+		return m.CopyObjectFunc(ctx, srcBucket, srcKey, srcVersionID, dstBucket, dstKey, NewPutOptions(options...))
+	}
+	return nil
+}
+
 // DeleteObject implementa StorageService.DeleteObject usando a função mock configurada
 func (m *MockS3Service) DeleteObject(ctx context.Context, bucket, key string) error {
 	if m.DeleteObjectFunc != nil {
@@ -35,3 +71,43 @@ func (m *MockS3Service) DeleteObject(ctx context.Context, bucket, key string) er
 	}
 	return nil
 }
+
+// DeleteObjectVersion implementa StorageService.DeleteObjectVersion usando a função mock configurada
+func (m *MockS3Service) DeleteObjectVersion(ctx context.Context, bucket, key, versionID string) error {
+	if m.DeleteObjectVersionFunc != nil {
+		return m.DeleteObjectVersionFunc(ctx, bucket, key, versionID)
+	}
+	return nil
+}
+
+// ListObjects implementa StorageService.ListObjects usando a função mock configurada
+func (m *MockS3Service) ListObjects(ctx context.Context, bucket, prefix string) ([]string, error) {
+	if m.ListObjectsFunc != nil {
+		return m.ListObjectsFunc(ctx, bucket, prefix)
+	}
+	return nil, nil
+}
+
+// ListObjectPages implementa StorageService.ListObjectPages usando a função mock configurada
+func (m *MockS3Service) ListObjectPages(ctx context.Context, bucket, prefix string, fn func(page []ObjectSummary) error) error {
+	if m.ListObjectPagesFunc != nil {
+		return m.ListObjectPagesFunc(ctx, bucket, prefix, fn)
+	}
+	return nil
+}
+
+// DeleteObjects implementa StorageService.DeleteObjects usando a função mock configurada
+func (m *MockS3Service) DeleteObjects(ctx context.Context, bucket string, keys []string) (map[string]error, error) {
+	if m.DeleteObjectsFunc != nil {
+		return m.DeleteObjectsFunc(ctx, bucket, keys)
+	}
+	return nil, nil
+}
+
+// AbortMultipartUploads implementa StorageService.AbortMultipartUploads usando a função mock configurada
+func (m *MockS3Service) AbortMultipartUploads(ctx context.Context, bucket, prefix string) (int, error) {
+	if m.AbortMultipartUploadsFunc != nil {
+		return m.AbortMultipartUploadsFunc(ctx, bucket, prefix)
+	}
+	return 0, nil
+}
@@ -2,13 +2,63 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"io"
+	"time"
 )
 
+// ErrObjectNotFound indica que o objeto solicitado não existe no bucket
+var ErrObjectNotFound = errors.New("object not found")
+
+// ErrObjectModified indica que o objeto não é mais a versão pedida em GetObjectIf
+var ErrObjectModified = errors.New("object modified")
+
+// ObjectInfo descreve um objeto sem o seu conteúdo
+type ObjectInfo struct {
+	Size int64
+	// ETag vem sem aspas
+	ETag string
+	// VersionID é vazio em buckets sem versionamento
+	VersionID   string
+	ContentType string
+	// Metadata são os metadados definidos por quem enviou o objeto (x-amz-meta-*)
+	Metadata map[string]string
+}
+
+// ObjectCondition fixa a versão de um objeto lida por GetObjectIf: o ETag esperado e/ou o
+// version id; campos vazios não são verificados
+type ObjectCondition struct {
+	ETag      string
+	VersionID string
+}
+
+// ObjectSummary é um objeto como aparece na listagem de um prefixo
+type ObjectSummary struct {
+	Key          string
+	Size         int64
+	LastModified time.Time
+}
+
 type StorageService interface {
-	GetObject(ctx context.Context, bucket, key string) (io.ReadCloser, error)
+	GetObject(ctx context.Context, bucket, key string, options ...GetOption) (io.ReadCloser, error)
 
-	PutObject(ctx context.Context, bucket, key string, body io.Reader) (string, error)
+	HeadObject(ctx context.Context, bucket, key string) (ObjectInfo, error)
+
+	GetObjectIf(ctx context.Context, bucket, key string, condition ObjectCondition, options ...GetOption) (io.ReadCloser, error)
+
+	PutObject(ctx context.Context, bucket, key string, body io.Reader, options ...PutOption) (string, error)
+
+	CopyObject(ctx context.Context, srcBucket, srcKey, srcVersionID, dstBucket, dstKey string, options ...PutOption) error
 
 	DeleteObject(ctx context.Context, bucket, key string) error
+
+	DeleteObjectVersion(ctx context.Context, bucket, key, versionID string) error
+
+	ListObjects(ctx context.Context, bucket, prefix string) ([]string, error)
+
+	ListObjectPages(ctx context.Context, bucket, prefix string, fn func(page []ObjectSummary) error) error
+
+	DeleteObjects(ctx context.Context, bucket string, keys []string) (map[string]error, error)
+
+	AbortMultipartUploads(ctx context.Context, bucket, prefix string) (int, error)
 }
@@ -0,0 +1,48 @@
+package transcription
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/transcribe"
+	"github.com/aws/aws-sdk-go-v2/service/transcribe/types"
+)
+
+// TranscribeAPI é o subconjunto do cliente Transcribe usado pelo TranscribeClient
+type TranscribeAPI interface {
+	StartTranscriptionJob(ctx context.Context, params *transcribe.StartTranscriptionJobInput, optFns ...func(*transcribe.Options)) (*transcribe.StartTranscriptionJobOutput, error)
+}
+
+// TranscribeClient implementa a interface TranscriptionService com o AWS Transcribe; o job
+// roda de forma assíncrona e lê a mídia direto do S3
+type TranscribeClient struct {
+	client TranscribeAPI
+}
+
+// NewTranscribeClient cria uma nova instância do TranscribeClient
+func NewTranscribeClient(cfg aws.Config) *TranscribeClient {
+	return &TranscribeClient{
+		client: transcribe.NewFromConfig(cfg),
+	}
+}
+
+// StartJob inicia o job de transcrição; sem LanguageCode o Transcribe identifica o idioma
+func (c *TranscribeClient) StartJob(ctx context.Context, job Job) error {
+	input := &transcribe.StartTranscriptionJobInput{
+		TranscriptionJobName: aws.String(job.Name),
+		Media:                &types.Media{MediaFileUri: aws.String(fmt.Sprintf("s3://%s/%s", job.MediaBucket, job.MediaKey))},
+		OutputBucketName:     aws.String(job.OutputBucket),
+		OutputKey:            aws.String(job.OutputKey),
+	}
+	if job.LanguageCode != "" {
+		input.LanguageCode = types.LanguageCode(job.LanguageCode)
+	} else {
+		input.IdentifyLanguage = aws.Bool(true)
+	}
+
+	if _, err := c.client.StartTranscriptionJob(ctx, input); err != nil {
+		return fmt.Errorf("failed to start Transcribe job: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package transcription
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/transcribe"
+)
+
+type mockTranscribe struct {
+	input *transcribe.StartTranscriptionJobInput
+	err   error
+}
+
+func (m *mockTranscribe) StartTranscriptionJob(ctx context.Context, params *transcribe.StartTranscriptionJobInput, optFns ...func(*transcribe.Options)) (*transcribe.StartTranscriptionJobOutput, error) {
+	m.input = params
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &transcribe.StartTranscriptionJobOutput{}, nil
+}
+
+func TestTranscribeClient_Implementation(t *testing.T) {
+	// Verifica se TranscribeClient implementa a interface TranscriptionService
+	var _ TranscriptionService = (*TranscribeClient)(nil)
+}
+
+func TestTranscribeClient_StartJob(t *testing.T) {
+	mock := &mockTranscribe{}
+	client := &TranscribeClient{client: mock}
+
+	job := Job{
+		Name:         "p1_abc",
+		MediaBucket:  "outputs",
+		MediaKey:     "processed/loudnorm_p1.mp4",
+		LanguageCode: "pt-BR",
+		OutputBucket: "outputs",
+		OutputKey:    "processed/transcript_p1.json",
+	}
+	if err := client.StartJob(context.Background(), job); err != nil {
+		t.Fatalf("StartJob failed: %v", err)
+	}
+
+	if aws.ToString(mock.input.TranscriptionJobName) != "p1_abc" {
+		t.Errorf("Expected job name p1_abc, got %s", aws.ToString(mock.input.TranscriptionJobName))
+	}
+	if uri := aws.ToString(mock.input.Media.MediaFileUri); uri != "s3://outputs/processed/loudnorm_p1.mp4" {
+		t.Errorf("Expected the media S3 URI, got %s", uri)
+	}
+	if aws.ToString(mock.input.OutputBucketName) != "outputs" || aws.ToString(mock.input.OutputKey) != "processed/transcript_p1.json" {
+		t.Errorf("Unexpected output %s/%s", aws.ToString(mock.input.OutputBucketName), aws.ToString(mock.input.OutputKey))
+	}
+	if mock.input.LanguageCode != "pt-BR" || mock.input.IdentifyLanguage != nil {
+		t.Errorf("Expected language pt-BR, got %s (identify %v)", mock.input.LanguageCode, mock.input.IdentifyLanguage)
+	}
+}
+
+func TestTranscribeClient_StartJob_IdentifyLanguage(t *testing.T) {
+	mock := &mockTranscribe{}
+	client := &TranscribeClient{client: mock}
+
+	if err := client.StartJob(context.Background(), Job{Name: "p1_abc", MediaBucket: "b", MediaKey: "k"}); err != nil {
+		t.Fatalf("StartJob failed: %v", err)
+	}
+	if mock.input.LanguageCode != "" || !aws.ToBool(mock.input.IdentifyLanguage) {
+		t.Errorf("Expected language identification without a language, got %+v", mock.input)
+	}
+}
+
+func TestTranscribeClient_StartJob_Error(t *testing.T) {
+	client := &TranscribeClient{client: &mockTranscribe{err: errors.New("throttled")}}
+
+	if err := client.StartJob(context.Background(), Job{Name: "p1_abc"}); err == nil {
+		t.Error("Expected error when Transcribe fails")
+	}
+}
@@ -0,0 +1,16 @@
+package transcription
+
+import "context"
+
+// MockTranscriptionService é um mock da interface TranscriptionService para testes
+type MockTranscriptionService struct {
+	StartJobFunc func(ctx context.Context, job Job) error
+}
+
+// StartJob implementa TranscriptionService.StartJob usando a função mock configurada
+func (m *MockTranscriptionService) StartJob(ctx context.Context, job Job) error {
+	if m.StartJobFunc != nil {
+		return m.StartJobFunc(ctx, job)
+	}
+	return nil
+}
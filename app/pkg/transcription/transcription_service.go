@@ -0,0 +1,18 @@
+package transcription
+
+import "context"
+
+// Job é uma transcrição do áudio de uma mídia no S3, gravada como JSON em
+// OutputBucket/OutputKey ao terminar
+type Job struct {
+	Name         string
+	MediaBucket  string
+	MediaKey     string
+	LanguageCode string
+	OutputBucket string
+	OutputKey    string
+}
+
+type TranscriptionService interface {
+	StartJob(ctx context.Context, job Job) error
+}
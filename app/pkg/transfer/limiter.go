@@ -0,0 +1,58 @@
+package transfer
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Limiter limita a banda somada de todas as transferências que o compartilham, para que um
+// download grande não esgote a rede do nó. Um Limiter nil não impõe limite
+type Limiter struct {
+	bytesPerSecond int64
+
+	mu sync.Mutex
+	// next é o instante em que a banda já reservada termina de ser consumida
+	next time.Time
+}
+
+// NewLimiter cria um Limiter de bytesPerSecond; valores <= 0 devolvem nil (sem limite)
+func NewLimiter(bytesPerSecond int64) *Limiter {
+	if bytesPerSecond <= 0 {
+		return nil
+	}
+	return &Limiter{bytesPerSecond: bytesPerSecond}
+}
+
+// Wait reserva n bytes e espera até que as reservas anteriores tenham sido consumidas, de
+// forma que a taxa somada não passe do limite
+func (l *Limiter) Wait(ctx context.Context, n int) error {
+	if l == nil || n <= 0 {
+		return nil
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	start := l.next
+	if start.Before(now) {
+		start = now
+	}
+	l.next = start.Add(time.Duration(int64(n) * int64(time.Second) / l.bytesPerSecond))
+	l.mu.Unlock()
+
+	// Os n bytes começam depois das reservas anteriores
+	wait := start.Sub(now)
+
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,45 @@
+package transfer
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLimiter_Paces(t *testing.T) {
+	limiter := NewLimiter(100 * 1024)
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		if err := limiter.Wait(context.Background(), 10*1024); err != nil {
+			t.Fatalf("Wait failed: %v", err)
+		}
+	}
+
+	// The first 10KB go right away, the next 20KB take 200ms at 100KB/s
+	if elapsed := time.Since(start); elapsed < 180*time.Millisecond {
+		t.Errorf("Expected the transfers paced to the limit, took %s", elapsed)
+	}
+}
+
+func TestLimiter_Nil(t *testing.T) {
+	if limiter := NewLimiter(0); limiter != nil {
+		t.Fatalf("Expected no limiter for 0, got %+v", limiter)
+	}
+
+	var limiter *Limiter
+	if err := limiter.Wait(context.Background(), 1<<30); err != nil {
+		t.Errorf("Expected a nil limiter not to wait, got %v", err)
+	}
+}
+
+func TestLimiter_Cancelled(t *testing.T) {
+	limiter := NewLimiter(1)
+	limiter.Wait(context.Background(), 1024)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := limiter.Wait(ctx, 1024); err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
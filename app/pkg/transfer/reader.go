@@ -0,0 +1,64 @@
+package transfer
+
+import (
+	"context"
+	"io"
+)
+
+// maxChunk limita cada leitura, para que o Limiter espace a transferência em passos pequenos
+const maxChunk = 32 * 1024
+
+// ProgressFunc recebe o total de bytes lidos até o momento
+type ProgressFunc func(transferred int64)
+
+type reader struct {
+	ctx         context.Context
+	source      io.Reader
+	limiter     *Limiter
+	onProgress  ProgressFunc
+	transferred int64
+}
+
+// readSeeker mantém o Seek do stream original, que o SDK da AWS usa para calcular o tamanho
+// e repetir uploads
+type readSeeker struct {
+	*reader
+	seeker io.Seeker
+}
+
+// NewReader envolve source aplicando o limite de banda de limiter (pode ser nil) e chamando
+// onProgress (pode ser nil) a cada leitura. O resultado implementa io.Seeker quando source implementa
+func NewReader(ctx context.Context, source io.Reader, limiter *Limiter, onProgress ProgressFunc) io.Reader {
+	r := &reader{ctx: ctx, source: source, limiter: limiter, onProgress: onProgress}
+	if seeker, ok := source.(io.Seeker); ok {
+		return &readSeeker{reader: r, seeker: seeker}
+	}
+	return r
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	if r.limiter != nil && len(p) > maxChunk {
+		p = p[:maxChunk]
+	}
+
+	n, err := r.source.Read(p)
+	if n > 0 {
+		r.transferred += int64(n)
+		if r.onProgress != nil {
+			r.onProgress(r.transferred)
+		}
+		if waitErr := r.limiter.Wait(r.ctx, n); waitErr != nil && err == nil {
+			err = waitErr
+		}
+	}
+	return n, err
+}
+
+// Seek reposiciona o stream; o progresso passa a contar a partir da nova posição
+func (r *readSeeker) Seek(offset int64, whence int) (int64, error) {
+	position, err := r.seeker.Seek(offset, whence)
+	if err == nil {
+		r.transferred = position
+	}
+	return position, err
+}
@@ -0,0 +1,51 @@
+package transfer
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestReader_ReportsProgress(t *testing.T) {
+	var progress []int64
+	reader := NewReader(context.Background(), strings.NewReader(strings.Repeat("x", 100*1024)), NewLimiter(1<<30), func(transferred int64) {
+		progress = append(progress, transferred)
+	})
+
+	data, err := io.ReadAll(reader)
+	if err != nil || len(data) != 100*1024 {
+		t.Fatalf("Expected 100KB read, got %d bytes, %v", len(data), err)
+	}
+	// The limiter splits the reads in 32KB chunks
+	if len(progress) < 4 || progress[len(progress)-1] != 100*1024 {
+		t.Errorf("Expected progress up to 100KB in chunks, got %v", progress)
+	}
+}
+
+func TestReader_KeepsSeeker(t *testing.T) {
+	var last int64
+	reader := NewReader(context.Background(), bytes.NewReader([]byte("0123456789")), nil, func(transferred int64) {
+		last = transferred
+	})
+
+	seeker, ok := reader.(io.Seeker)
+	if !ok {
+		t.Fatal("Expected a seekable reader for a seekable source")
+	}
+	if size, err := seeker.Seek(0, io.SeekEnd); err != nil || size != 10 {
+		t.Fatalf("Expected size 10, got %d, %v", size, err)
+	}
+	seeker.Seek(4, io.SeekStart)
+
+	buf := make([]byte, 2)
+	reader.Read(buf)
+	if string(buf) != "45" || last != 6 {
+		t.Errorf("Expected progress counted from the new position, got %q at %d", buf, last)
+	}
+
+	if _, ok := NewReader(context.Background(), io.MultiReader(), nil, nil).(io.Seeker); ok {
+		t.Error("Expected a plain reader for a source that cannot seek")
+	}
+}
@@ -0,0 +1,65 @@
+package transfer
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+// ReopenFunc abre novamente o stream a partir de offset, o primeiro byte ainda não recebido
+type ReopenFunc func(ctx context.Context, offset int64, cause error) (io.ReadCloser, error)
+
+type resumingReader struct {
+	ctx        context.Context
+	body       io.ReadCloser
+	reopen     ReopenFunc
+	maxResumes int
+	resumes    int
+	offset     int64
+	// err é o erro da retomada que falhou, devolvido pelas leituras seguintes
+	err error
+}
+
+// NewResumingReader envolve body para que um erro no meio da leitura retome o stream do último
+// byte recebido com reopen, em vez de recomeçar a transferência, até maxResumes vezes. O fim do
+// stream, o cancelamento de ctx e os erros de reopen encerram a leitura. Fechar o resultado fecha
+// o stream aberto no momento
+func NewResumingReader(ctx context.Context, body io.ReadCloser, reopen ReopenFunc, maxResumes int) io.ReadCloser {
+	if maxResumes <= 0 || reopen == nil {
+		return body
+	}
+	return &resumingReader{ctx: ctx, body: body, reopen: reopen, maxResumes: maxResumes}
+}
+
+func (r *resumingReader) Read(p []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	for {
+		n, err := r.body.Read(p)
+		r.offset += int64(n)
+		if err == nil || errors.Is(err, io.EOF) || r.ctx.Err() != nil || r.resumes >= r.maxResumes {
+			return n, err
+		}
+		// Os bytes já lidos são entregues; a retomada fica para a próxima leitura
+		if n > 0 {
+			return n, nil
+		}
+
+		r.resumes++
+		r.body.Close()
+		body, reopenErr := r.reopen(r.ctx, r.offset, err)
+		if reopenErr != nil {
+			r.body, r.err = nil, reopenErr
+			return 0, reopenErr
+		}
+		r.body = body
+	}
+}
+
+func (r *resumingReader) Close() error {
+	if r.body == nil {
+		return nil
+	}
+	return r.body.Close()
+}
@@ -0,0 +1,123 @@
+package transfer
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+// flakyBody serves data and fails after failAfter bytes
+type flakyBody struct {
+	data      string
+	failAfter int
+	read      int
+	closed    bool
+}
+
+func (b *flakyBody) Read(p []byte) (int, error) {
+	if b.read >= len(b.data) {
+		return 0, io.EOF
+	}
+	if b.read >= b.failAfter {
+		return 0, errors.New("connection reset by peer")
+	}
+	end := min(len(b.data), b.failAfter, b.read+len(p))
+	n := copy(p, b.data[b.read:end])
+	b.read += n
+	return n, nil
+}
+
+func (b *flakyBody) Close() error {
+	b.closed = true
+	return nil
+}
+
+func TestResumingReader_Resumes(t *testing.T) {
+	const data = "0123456789abcdef"
+	first := &flakyBody{data: data, failAfter: 5}
+	var offsets []int64
+	var bodies []*flakyBody
+	reader := NewResumingReader(context.Background(), first, func(ctx context.Context, offset int64, cause error) (io.ReadCloser, error) {
+		offsets = append(offsets, offset)
+		body := &flakyBody{data: data[offset:], failAfter: 6}
+		bodies = append(bodies, body)
+		return body, nil
+	}, 3)
+
+	got, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("ReadAll failed: %v", err)
+	}
+	reader.Close()
+
+	if string(got) != data {
+		t.Errorf("Expected the whole stream, got %q", got)
+	}
+	if len(offsets) != 2 || offsets[0] != 5 || offsets[1] != 11 {
+		t.Errorf("Expected resumes from bytes 5 and 11, got %v", offsets)
+	}
+	if !first.closed || !bodies[0].closed || !bodies[1].closed {
+		t.Error("Expected every stream closed")
+	}
+}
+
+func TestResumingReader_Cap(t *testing.T) {
+	resumes := 0
+	reader := NewResumingReader(context.Background(), &flakyBody{data: "0123456789", failAfter: 2}, func(ctx context.Context, offset int64, cause error) (io.ReadCloser, error) {
+		resumes++
+		return &flakyBody{data: "0123456789"[offset:], failAfter: 1}, nil
+	}, 2)
+
+	if _, err := io.ReadAll(reader); err == nil || !strings.Contains(err.Error(), "connection reset") {
+		t.Errorf("Expected the read error once the resumes are spent, got %v", err)
+	}
+	if resumes != 2 {
+		t.Errorf("Expected 2 resumes, got %d", resumes)
+	}
+}
+
+func TestResumingReader_ReopenFails(t *testing.T) {
+	modified := errors.New("object modified")
+	resumes := 0
+	reader := NewResumingReader(context.Background(), &flakyBody{data: "0123456789", failAfter: 2}, func(ctx context.Context, offset int64, cause error) (io.ReadCloser, error) {
+		resumes++
+		return nil, modified
+	}, 3)
+
+	if _, err := io.ReadAll(reader); !errors.Is(err, modified) {
+		t.Errorf("Expected the reopen error, got %v", err)
+	}
+	if _, err := reader.Read(make([]byte, 1)); !errors.Is(err, modified) || resumes != 1 {
+		t.Errorf("Expected the failed resume not retried, got %v after %d resumes", err, resumes)
+	}
+	if err := reader.Close(); err != nil {
+		t.Errorf("Close failed: %v", err)
+	}
+}
+
+func TestResumingReader_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	reader := NewResumingReader(ctx, &flakyBody{data: "0123456789", failAfter: 2}, func(ctx context.Context, offset int64, cause error) (io.ReadCloser, error) {
+		t.Fatal("Expected no resume after the cancellation")
+		return nil, nil
+	}, 3)
+
+	if _, err := io.ReadAll(reader); err == nil {
+		t.Error("Expected the read error")
+	}
+}
+
+func TestResumingReader_Disabled(t *testing.T) {
+	body := &flakyBody{data: "0123"}
+	if reader := NewResumingReader(context.Background(), body, nil, 3); reader != body {
+		t.Error("Expected the body itself without reopen")
+	}
+	if reader := NewResumingReader(context.Background(), body, func(ctx context.Context, offset int64, cause error) (io.ReadCloser, error) {
+		return nil, nil
+	}, 0); reader != body {
+		t.Error("Expected the body itself without resumes")
+	}
+}
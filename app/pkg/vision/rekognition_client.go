@@ -0,0 +1,89 @@
+package vision
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/rekognition"
+	"github.com/aws/aws-sdk-go-v2/service/rekognition/types"
+)
+
+// MaxLabels limita os rótulos devolvidos por imagem, do mais para o menos confiável
+const MaxLabels = 20
+
+// RekognitionAPI é o subconjunto do cliente Rekognition usado pelo RekognitionClient
+type RekognitionAPI interface {
+	DetectLabels(ctx context.Context, params *rekognition.DetectLabelsInput, optFns ...func(*rekognition.Options)) (*rekognition.DetectLabelsOutput, error)
+	DetectModerationLabels(ctx context.Context, params *rekognition.DetectModerationLabelsInput, optFns ...func(*rekognition.Options)) (*rekognition.DetectModerationLabelsOutput, error)
+}
+
+// RekognitionClient implementa a interface VisionService com o AWS Rekognition, enviando as
+// imagens no corpo da requisição (até 5MB cada)
+type RekognitionClient struct {
+	client RekognitionAPI
+}
+
+// NewRekognitionClient cria uma nova instância do RekognitionClient
+func NewRekognitionClient(cfg aws.Config) *RekognitionClient {
+	return &RekognitionClient{
+		client: rekognition.NewFromConfig(cfg),
+	}
+}
+
+// DetectLabels devolve os objetos, cenas e conceitos da imagem; minConfidence zero usa o
+// padrão do Rekognition
+func (c *RekognitionClient) DetectLabels(ctx context.Context, image []byte, minConfidence float64) ([]Label, error) {
+	input := &rekognition.DetectLabelsInput{
+		Image:     &types.Image{Bytes: image},
+		MaxLabels: aws.Int32(MaxLabels),
+	}
+	if minConfidence > 0 {
+		input.MinConfidence = aws.Float32(float32(minConfidence))
+	}
+
+	output, err := c.client.DetectLabels(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect labels with Rekognition: %w", err)
+	}
+
+	labels := make([]Label, 0, len(output.Labels))
+	for _, label := range output.Labels {
+		var parent string
+		if len(label.Parents) > 0 {
+			parent = aws.ToString(label.Parents[0].Name)
+		}
+		labels = append(labels, Label{
+			Name:       aws.ToString(label.Name),
+			Parent:     parent,
+			Confidence: float64(aws.ToFloat32(label.Confidence)),
+		})
+	}
+	return labels, nil
+}
+
+// DetectModeration devolve os rótulos de conteúdo impróprio da imagem; minConfidence zero usa
+// o padrão do Rekognition
+func (c *RekognitionClient) DetectModeration(ctx context.Context, image []byte, minConfidence float64) ([]Label, error) {
+	input := &rekognition.DetectModerationLabelsInput{
+		Image: &types.Image{Bytes: image},
+	}
+	if minConfidence > 0 {
+		input.MinConfidence = aws.Float32(float32(minConfidence))
+	}
+
+	output, err := c.client.DetectModerationLabels(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to detect moderation labels with Rekognition: %w", err)
+	}
+
+	labels := make([]Label, 0, len(output.ModerationLabels))
+	for _, label := range output.ModerationLabels {
+		labels = append(labels, Label{
+			Name:       aws.ToString(label.Name),
+			Parent:     aws.ToString(label.ParentName),
+			Confidence: float64(aws.ToFloat32(label.Confidence)),
+		})
+	}
+	return labels, nil
+}
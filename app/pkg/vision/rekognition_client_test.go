@@ -0,0 +1,81 @@
+package vision
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/rekognition"
+	"github.com/aws/aws-sdk-go-v2/service/rekognition/types"
+)
+
+type mockRekognition struct {
+	labelsInput     *rekognition.DetectLabelsInput
+	moderationInput *rekognition.DetectModerationLabelsInput
+	err             error
+}
+
+func (m *mockRekognition) DetectLabels(ctx context.Context, params *rekognition.DetectLabelsInput, optFns ...func(*rekognition.Options)) (*rekognition.DetectLabelsOutput, error) {
+	m.labelsInput = params
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &rekognition.DetectLabelsOutput{Labels: []types.Label{
+		{Name: aws.String("Car"), Confidence: aws.Float32(98.5), Parents: []types.Parent{{Name: aws.String("Vehicle")}}},
+		{Name: aws.String("Road"), Confidence: aws.Float32(90)},
+	}}, nil
+}
+
+func (m *mockRekognition) DetectModerationLabels(ctx context.Context, params *rekognition.DetectModerationLabelsInput, optFns ...func(*rekognition.Options)) (*rekognition.DetectModerationLabelsOutput, error) {
+	m.moderationInput = params
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &rekognition.DetectModerationLabelsOutput{ModerationLabels: []types.ModerationLabel{
+		{Name: aws.String("Weapons"), ParentName: aws.String("Violence"), Confidence: aws.Float32(75)},
+	}}, nil
+}
+
+func TestRekognitionClient_Implementation(t *testing.T) {
+	// Verifica se RekognitionClient implementa a interface VisionService
+	var _ VisionService = (*RekognitionClient)(nil)
+}
+
+func TestRekognitionClient_DetectLabels(t *testing.T) {
+	mock := &mockRekognition{}
+	client := &RekognitionClient{client: mock}
+
+	labels, err := client.DetectLabels(context.Background(), []byte("jpeg"), 80)
+	if err != nil {
+		t.Fatalf("DetectLabels failed: %v", err)
+	}
+	if len(labels) != 2 || labels[0] != (Label{Name: "Car", Parent: "Vehicle", Confidence: 98.5}) {
+		t.Errorf("Unexpected labels %+v", labels)
+	}
+	if string(mock.labelsInput.Image.Bytes) != "jpeg" || aws.ToInt32(mock.labelsInput.MaxLabels) != MaxLabels || aws.ToFloat32(mock.labelsInput.MinConfidence) != 80 {
+		t.Errorf("Unexpected input %+v", mock.labelsInput)
+	}
+
+	if _, err := client.DetectLabels(context.Background(), []byte("jpeg"), 0); err != nil || mock.labelsInput.MinConfidence != nil {
+		t.Errorf("Expected the Rekognition default confidence, got %v", mock.labelsInput.MinConfidence)
+	}
+}
+
+func TestRekognitionClient_DetectModeration(t *testing.T) {
+	mock := &mockRekognition{}
+	client := &RekognitionClient{client: mock}
+
+	labels, err := client.DetectModeration(context.Background(), []byte("jpeg"), 0)
+	if err != nil {
+		t.Fatalf("DetectModeration failed: %v", err)
+	}
+	if len(labels) != 1 || labels[0] != (Label{Name: "Weapons", Parent: "Violence", Confidence: 75}) {
+		t.Errorf("Unexpected moderation labels %+v", labels)
+	}
+
+	mock.err = errors.New("throttled")
+	if _, err := client.DetectModeration(context.Background(), []byte("jpeg"), 0); err == nil {
+		t.Error("Expected error when Rekognition fails")
+	}
+}
@@ -0,0 +1,25 @@
+package vision
+
+import "context"
+
+// MockVisionService é um mock da interface VisionService para testes
+type MockVisionService struct {
+	DetectLabelsFunc     func(ctx context.Context, image []byte, minConfidence float64) ([]Label, error)
+	DetectModerationFunc func(ctx context.Context, image []byte, minConfidence float64) ([]Label, error)
+}
+
+// DetectLabels implementa VisionService.DetectLabels usando a função mock configurada
+func (m *MockVisionService) DetectLabels(ctx context.Context, image []byte, minConfidence float64) ([]Label, error) {
+	if m.DetectLabelsFunc != nil {
+		return m.DetectLabelsFunc(ctx, image, minConfidence)
+	}
+	return nil, nil
+}
+
+// DetectModeration implementa VisionService.DetectModeration usando a função mock configurada
+func (m *MockVisionService) DetectModeration(ctx context.Context, image []byte, minConfidence float64) ([]Label, error) {
+	if m.DetectModerationFunc != nil {
+		return m.DetectModerationFunc(ctx, image, minConfidence)
+	}
+	return nil, nil
+}
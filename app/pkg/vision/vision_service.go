@@ -0,0 +1,16 @@
+package vision
+
+import "context"
+
+// Label é um rótulo atribuído a uma imagem, com a confiança em porcentagem
+type Label struct {
+	Name       string
+	Parent     string
+	Confidence float64
+}
+
+type VisionService interface {
+	DetectLabels(ctx context.Context, image []byte, minConfidence float64) ([]Label, error)
+
+	DetectModeration(ctx context.Context, image []byte, minConfidence float64) ([]Label, error)
+}